@@ -17,8 +17,11 @@ limitations under the License.
 package extension
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -32,8 +35,60 @@ const (
 	// If a batch of Pods to be evicted have the same priority, they will be sorted by cost,
 	// and the Pod with the smallest cost will be evicted.
 	AnnotationEvictionCost = SchedulingDomainPrefix + "/eviction-cost"
+
+	// AnnotationNodeMaintenanceWindow declares the planned maintenance window of the node in JSON,
+	// e.g. `{"start":"2022-10-01T02:00:00Z","end":"2022-10-01T06:00:00Z"}`.
+	// The descheduler migrates the pods out of the node gradually before the window starts,
+	// and stops once the annotation is removed.
+	AnnotationNodeMaintenanceWindow = SchedulingDomainPrefix + "/maintenance-window"
+
+	// AnnotationDeschedulingHistory records the nodes the pods of a workload were recently descheduled from in JSON,
+	// e.g. `[{"nodeName":"node-1","time":"2022-10-01T02:00:00Z"}]`. The descheduler records it on the controller
+	// of the evicted pod, e.g. the ReplicaSet of the pod template hash, and the scheduler avoids placing
+	// the replacements back on the recorded nodes for a while.
+	AnnotationDeschedulingHistory = SchedulingDomainPrefix + "/descheduling-history"
 )
 
+type DeschedulingRecord struct {
+	NodeName string      `json:"nodeName"`
+	Time     metav1.Time `json:"time"`
+}
+
+func GetDeschedulingHistory(annotations map[string]string) ([]DeschedulingRecord, error) {
+	data, ok := annotations[AnnotationDeschedulingHistory]
+	if !ok {
+		return nil, nil
+	}
+	var history []DeschedulingRecord
+	if err := json.Unmarshal([]byte(data), &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+type NodeMaintenanceWindow struct {
+	Start metav1.Time  `json:"start"`
+	End   *metav1.Time `json:"end,omitempty"`
+}
+
+func GetNodeMaintenanceWindow(annotations map[string]string) (*NodeMaintenanceWindow, error) {
+	data, ok := annotations[AnnotationNodeMaintenanceWindow]
+	if !ok {
+		return nil, nil
+	}
+	window := &NodeMaintenanceWindow{}
+	if err := json.Unmarshal([]byte(data), window); err != nil {
+		return nil, err
+	}
+	if window.Start.IsZero() {
+		return nil, fmt.Errorf("missing start of maintenance window")
+	}
+	if window.End != nil && window.End.Before(&window.Start) {
+		return nil, fmt.Errorf("end of maintenance window is before the start")
+	}
+	return window, nil
+}
+
 func GetEvictionCost(annotations map[string]string) (int32, error) {
 	if value, exist := annotations[AnnotationEvictionCost]; exist {
 		// values that start with plus sign (e.g, "+10") or leading zeros (e.g., "008") are not valid.
@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// LabelNamespaceColocation set to NamespaceColocationDisabled opts the namespace out of colocation.
+	// The pods in such namespace are never mutated by ClusterColocationProfiles, and their usage is never
+	// reclaimed as batch resources.
+	LabelNamespaceColocation = DomainPrefix + "colocation"
+	// AnnotationColocationProfileSkipped records the ClusterColocationProfiles which matched the pod
+	// but were skipped since the namespace has opted out of colocation.
+	AnnotationColocationProfileSkipped = DomainPrefix + "colocation-profile-skipped"
+)
+
+const (
+	NamespaceColocationDisabled = "disabled"
+)
+
+// IsNamespaceColocationDisabled checks if the namespace has opted out of colocation.
+func IsNamespaceColocationDisabled(namespace *corev1.Namespace) bool {
+	return namespace != nil && namespace.Labels[LabelNamespaceColocation] == NamespaceColocationDisabled
+}
@@ -18,6 +18,7 @@ package extension
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -62,6 +63,55 @@ const (
 	KubeletCPUManagerPolicyDistributeCPUsAcrossNUMAOption = "distribute-cpus-across-numa"
 )
 
+const (
+	// AnnotationNodeBatchResourceOrigin records which controller manages the batch resources of the node.
+	// The noderesource controller only updates batch resources carrying its own origin or no origin at all.
+	AnnotationNodeBatchResourceOrigin = NodeDomainPrefix + "/batch-resource-origin"
+	// LabelNodeBatchResourceOptOut indicates the batch resources of the node should not be managed by Koordinator.
+	LabelNodeBatchResourceOptOut = NodeDomainPrefix + "/batch-resource-opt-out"
+	// AnnotationNodeResourceWriterGeneration records the leadership generation of the koord-manager who last wrote
+	// the node resources. A writer holding an older generation is fenced and must not overwrite the node resources.
+	AnnotationNodeResourceWriterGeneration = NodeDomainPrefix + "/resource-writer-generation"
+
+	// NodeBatchResourceOriginKoordinator is the origin of batch resources managed by koord-manager.
+	NodeBatchResourceOriginKoordinator = "koordinator"
+)
+
+// GetNodeBatchResourceOrigin returns the origin of the batch resources of the node.
+func GetNodeBatchResourceOrigin(annotations map[string]string) string {
+	return annotations[AnnotationNodeBatchResourceOrigin]
+}
+
+// GetNodeResourceWriterGeneration returns the leadership generation of the last writer of the node resources.
+// It returns false if the generation is missing or invalid.
+func GetNodeResourceWriterGeneration(annotations map[string]string) (int64, bool) {
+	s, ok := annotations[AnnotationNodeResourceWriterGeneration]
+	if !ok {
+		return 0, false
+	}
+	generation, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || generation < 0 {
+		return 0, false
+	}
+	return generation, true
+}
+
+// IsNodeBatchResourceOptOut checks if the node opts out the batch resources management of Koordinator.
+func IsNodeBatchResourceOptOut(labels map[string]string) bool {
+	return labels[LabelNodeBatchResourceOptOut] == "true"
+}
+
+const (
+	// LabelNodeGPUWholeOnly indicates the GPUs of the node cannot be shared, e.g. the older cards without MPS,
+	// so the node only accepts the pods requesting whole GPUs.
+	LabelNodeGPUWholeOnly = NodeDomainPrefix + "/gpu-whole-only"
+)
+
+// IsNodeGPUWholeOnly checks if the node only supports allocating whole GPUs.
+func IsNodeGPUWholeOnly(labels map[string]string) bool {
+	return labels[LabelNodeGPUWholeOnly] == "true"
+}
+
 type CPUTopology struct {
 	Detail []CPUInfo `json:"detail,omitempty"`
 }
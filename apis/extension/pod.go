@@ -30,6 +30,21 @@ const (
 	AnnotationPodMemoryQoS = DomainPrefix + "memoryQOS"
 )
 
+// The container envs let developers hint the qos strategies in the pod spec. The hints have lower priority than both
+// the pod annotations and the NodeSLO, i.e. they only take effect on the fields not specified by the other two.
+const (
+	// EnvPodCPUBurst hints the CPUBurstPolicy, e.g. KOORD_QOS_CPU_BURST=auto
+	EnvPodCPUBurst = "KOORD_QOS_CPU_BURST"
+	// EnvPodCPUBurstPercent hints the CPUBurstPercent, e.g. KOORD_QOS_CPU_BURST_PERCENT=500
+	EnvPodCPUBurstPercent = "KOORD_QOS_CPU_BURST_PERCENT"
+	// EnvPodMemoryMinRatio hints the MinLimitPercent in ratio, e.g. KOORD_MEM_MIN_RATIO=0.5
+	EnvPodMemoryMinRatio = "KOORD_MEM_MIN_RATIO"
+	// EnvPodMemoryLowRatio hints the LowLimitPercent in ratio, e.g. KOORD_MEM_LOW_RATIO=0.8
+	EnvPodMemoryLowRatio = "KOORD_MEM_LOW_RATIO"
+	// EnvPodMemoryHighRatio hints the ThrottlingPercent in ratio, e.g. KOORD_MEM_HIGH_RATIO=0.9
+	EnvPodMemoryHighRatio = "KOORD_MEM_HIGH_RATIO"
+)
+
 func GetPodCPUBurstConfig(pod *corev1.Pod) (*slov1alpha1.CPUBurstConfig, error) {
 	if pod == nil || pod.Annotations == nil {
 		return nil, nil
@@ -18,6 +18,7 @@ package extension
 
 import (
 	"encoding/json"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -36,10 +37,14 @@ const (
 
 	KoordGPU  corev1.ResourceName = ResourceDomainPrefix + "gpu"
 	NvidiaGPU corev1.ResourceName = "nvidia.com/gpu"
+	// NvidiaMIGPrefix is the prefix of the resources of the NVIDIA MIG instances, e.g. nvidia.com/mig-1g.5gb.
+	NvidiaMIGPrefix = "nvidia.com/mig-"
 
 	GPUCore        corev1.ResourceName = ResourceDomainPrefix + "gpu-core"
 	GPUMemory      corev1.ResourceName = ResourceDomainPrefix + "gpu-memory"
 	GPUMemoryRatio corev1.ResourceName = ResourceDomainPrefix + "gpu-memory-ratio"
+	// GPUSharedSlots is the time-slicing slots of the GPUs shared by the pods, each pod taking one slot of a GPU.
+	GPUSharedSlots corev1.ResourceName = ResourceDomainPrefix + "gpu-shared-slots"
 
 	GPUDriver string = ResourceDomainPrefix + "gpu-driver"
 	GPUModel  string = ResourceDomainPrefix + "gpu-model"
@@ -68,12 +73,24 @@ var (
 	}
 )
 
+// NvidiaMIGResourceName returns the resource name of the MIG instances of the profile, e.g. 1g.5gb.
+func NvidiaMIGResourceName(profile string) corev1.ResourceName {
+	return corev1.ResourceName(NvidiaMIGPrefix + profile)
+}
+
+// IsNvidiaMIGResource checks if the resource is the MIG instances of a profile.
+func IsNvidiaMIGResource(resourceName corev1.ResourceName) bool {
+	return strings.HasPrefix(string(resourceName), NvidiaMIGPrefix) && len(resourceName) > len(NvidiaMIGPrefix)
+}
+
 // ResourceSpec describes extra attributes of the resource requirements.
 type ResourceSpec struct {
 	// PreferredCPUBindPolicy represents best-effort CPU bind policy.
 	PreferredCPUBindPolicy CPUBindPolicy `json:"preferredCPUBindPolicy,omitempty"`
 	// PreferredCPUExclusivePolicy represents best-effort CPU exclusive policy.
 	PreferredCPUExclusivePolicy CPUExclusivePolicy `json:"preferredCPUExclusivePolicy,omitempty"`
+	// NUMATopologyPolicy represents how to align the CPUs with the NUMA nodes of the allocated devices.
+	NUMATopologyPolicy NUMATopologyPolicy `json:"numaTopologyPolicy,omitempty"`
 }
 
 // ResourceStatus describes resource allocation result, such as how to bind CPU.
@@ -122,6 +139,21 @@ const (
 	NUMADistributeEvenly NUMAAllocateStrategy = "DistributeEvenly"
 )
 
+// NUMATopologyPolicy defines how to align the CPUs of the pod with the NUMA nodes of the devices allocated to it.
+type NUMATopologyPolicy string
+
+const (
+	// NUMATopologyPolicyDefault prefers the CPUs in the NUMA nodes of the allocated devices, the same as BestEffort.
+	NUMATopologyPolicyDefault NUMATopologyPolicy = ""
+	// NUMATopologyPolicyNone does not align the CPUs with the allocated devices.
+	NUMATopologyPolicyNone NUMATopologyPolicy = "None"
+	// NUMATopologyPolicyBestEffort prefers the CPUs in the NUMA nodes of the allocated devices,
+	// and allocates the other CPUs if the NUMA nodes do not have enough.
+	NUMATopologyPolicyBestEffort NUMATopologyPolicy = "BestEffort"
+	// NUMATopologyPolicyRestricted allocates only the CPUs in the NUMA nodes of the allocated devices.
+	NUMATopologyPolicyRestricted NUMATopologyPolicy = "Restricted"
+)
+
 type NUMACPUSharedPools []CPUSharedPool
 
 type CPUSharedPool struct {
@@ -18,7 +18,10 @@ package extension
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,6 +48,40 @@ const (
 
 	// AnnotationDeviceAllocated represents the device allocated by the pod
 	AnnotationDeviceAllocated = SchedulingDomainPrefix + "/device-allocated"
+
+	// AnnotationDeviceOrderingHint specifies the expected order of the devices allocated by the pod.
+	// For specific value definitions, see DeviceOrderingHint
+	AnnotationDeviceOrderingHint = SchedulingDomainPrefix + "/device-ordering-hint"
+
+	// AnnotationGPUTargetUUID specifies the UUID of the GPU expected by the pod.
+	// The pod can only be placed on the node which reports the GPU and the GPU must be available.
+	AnnotationGPUTargetUUID = SchedulingDomainPrefix + "/gpu-uuid"
+
+	// AnnotationDeviceReuseHint specifies the devices allocated to the pod before it restarted, in the same form as
+	// AnnotationDeviceAllocated. The restarted pod is allocated the same GPUs and RDMA NICs all together if they are
+	// still free, so that the pairs of GPU and NIC are kept, otherwise the devices are allocated as usual.
+	AnnotationDeviceReuseHint = SchedulingDomainPrefix + "/device-reuse-hint"
+
+	// AnnotationGPUMinComputeCapability specifies the minimum compute capability of the GPUs allocated to the pod,
+	// e.g. "8.0". The GPUs not reporting the compute capability in the Device are not allocated to the pod.
+	AnnotationGPUMinComputeCapability = SchedulingDomainPrefix + "/gpu-min-compute-capability"
+
+	// AnnotationGPUModel specifies the models of the GPUs allocated to the pod in a comma-separated allow list,
+	// e.g. "A100,A800". A model matches the GPUs of the same model or its variants, e.g. "A100" matches
+	// "A100-SXM4-80GB" but not "A10". The GPUs not reporting the model in the Device are not allocated to the pod.
+	AnnotationGPUModel = SchedulingDomainPrefix + "/gpu-model"
+
+	// AnnotationGPUExclusive specifies the GPUs allocated to the pod are not shared with the other pods if "true",
+	// even if the pod requests a part of a GPU, e.g. gpu-core: 50. Only the GPUs used by no pod are allocated.
+	AnnotationGPUExclusive = SchedulingDomainPrefix + "/gpu-exclusive"
+
+	// AnnotationDeviceAllocatedTopology records the topology of the devices allocated by the pod. The scheduler always
+	// allocates the requested count of devices, and the topology is only a preference of which devices to allocate.
+	AnnotationDeviceAllocatedTopology = SchedulingDomainPrefix + "/device-allocated-topology"
+
+	// AnnotationDeviceJointAllocate specifies the device types allocated jointly under the same PCIe switch,
+	// e.g. the GPUs and RDMA NICs for GPUDirect RDMA. For specific value definitions, see DeviceJointAllocate
+	AnnotationDeviceJointAllocate = SchedulingDomainPrefix + "/device-joint-allocate"
 )
 
 const (
@@ -190,6 +227,20 @@ type DeviceAllocations map[schedulingv1alpha1.DeviceType][]*DeviceAllocation
 type DeviceAllocation struct {
 	Minor     int32               `json:"minor"`
 	Resources corev1.ResourceList `json:"resources"`
+	// MIGPartitions is the MIG partitions of the GPU allocated if the GPU reports them,
+	// so that the node agent can bind the partitions by the GPU instance and compute instance IDs.
+	MIGPartitions []schedulingv1alpha1.MIGPartition `json:"migPartitions,omitempty"`
+	// VirtualFunctions is the SR-IOV virtual functions of the RDMA device allocated if the device reports them,
+	// so that the node agent can attach the virtual functions by the bus addresses.
+	VirtualFunctions []schedulingv1alpha1.VirtualFunction `json:"virtualFunctions,omitempty"`
+	// SharedSlots is the time-slicing slots of the GPU allocated if the GPU is shared by the slots,
+	// so that the node agent can tell the pods sharing the GPU apart by the slot indexes.
+	SharedSlots []int32 `json:"sharedSlots,omitempty"`
+	// Exclusive indicates the device is not shared with the other pods even if only a part of it is allocated.
+	Exclusive bool `json:"exclusive,omitempty"`
+	// PCIeSwitchID is the PCIe switch the device attached to if the device is allocated jointly with the devices
+	// of the other types, so that the node agent can pair the devices under the same PCIe switch.
+	PCIeSwitchID string `json:"pcieSwitchID,omitempty"`
 }
 
 func GetDeviceAllocations(podAnnotations map[string]string) (DeviceAllocations, error) {
@@ -219,6 +270,295 @@ func SetDeviceAllocations(pod *corev1.Pod, allocations DeviceAllocations) error
 	return nil
 }
 
+// DeviceAllocatedTopology describes the topology of the devices allocated by the pod.
+//
+// An example, the 4 GPUs of the pod span 2 NUMA nodes while a NUMA node could hold them all:
+//
+//	{
+//	  "gpu": {
+//	    "numaNodes": [0, 1],
+//	    "degraded": true,
+//	    "level": "Node"
+//	  }
+//	}
+type DeviceAllocatedTopology map[schedulingv1alpha1.DeviceType]*DeviceTopologyResult
+
+type DeviceTopologyResult struct {
+	// NUMANodes is the NUMA nodes the allocated devices attached to.
+	NUMANodes []int32 `json:"numaNodes,omitempty"`
+	// Degraded means the allocated devices span more NUMA nodes than the node could place them on.
+	Degraded bool `json:"degraded,omitempty"`
+	// Level is the closest topology level connecting all the allocated GPUs, empty if a single GPU is allocated
+	// or the topology is not reported.
+	Level DeviceTopologyLevel `json:"level,omitempty"`
+}
+
+// DeviceTopologyLevel is the level of the topology connecting a set of devices, from the closest to the farthest.
+type DeviceTopologyLevel string
+
+const (
+	// DeviceTopologyLevelNVLink means the devices are in the same NVLink group.
+	DeviceTopologyLevelNVLink DeviceTopologyLevel = "NVLink"
+	// DeviceTopologyLevelPCIeSwitch means the devices are attached to the same PCIe switch.
+	DeviceTopologyLevelPCIeSwitch DeviceTopologyLevel = "PCIeSwitch"
+	// DeviceTopologyLevelNUMANode means the devices are attached to the same NUMA node.
+	DeviceTopologyLevelNUMANode DeviceTopologyLevel = "NUMANode"
+	// DeviceTopologyLevelNode means the devices are only on the same node.
+	DeviceTopologyLevelNode DeviceTopologyLevel = "Node"
+)
+
+func GetDeviceAllocatedTopology(podAnnotations map[string]string) (DeviceAllocatedTopology, error) {
+	data, ok := podAnnotations[AnnotationDeviceAllocatedTopology]
+	if !ok {
+		return nil, nil
+	}
+	topology := DeviceAllocatedTopology{}
+	if err := json.Unmarshal([]byte(data), &topology); err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+func SetDeviceAllocatedTopology(pod *corev1.Pod, topology DeviceAllocatedTopology) error {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+
+	data, err := json.Marshal(topology)
+	if err != nil {
+		return err
+	}
+
+	pod.Annotations[AnnotationDeviceAllocatedTopology] = string(data)
+	return nil
+}
+
+// DeviceJointAllocate describes the device types allocated jointly, the devices of which are allocated under the
+// same PCIe switch, or paired under several PCIe switches each holding the devices of all the joint types if the
+// request exceeds any PCIe switch. The pod fails to schedule on the node if the devices cannot be paired.
+//
+//	{
+//	  "deviceTypes": ["gpu", "rdma"]
+//	}
+type DeviceJointAllocate struct {
+	DeviceTypes []schedulingv1alpha1.DeviceType `json:"deviceTypes"`
+}
+
+func GetDeviceJointAllocate(podAnnotations map[string]string) (*DeviceJointAllocate, error) {
+	data, ok := podAnnotations[AnnotationDeviceJointAllocate]
+	if !ok {
+		return nil, nil
+	}
+	jointAllocate := &DeviceJointAllocate{}
+	if err := json.Unmarshal([]byte(data), jointAllocate); err != nil {
+		return nil, err
+	}
+	return jointAllocate, nil
+}
+
+// DeviceOrderingHint describes the logical order of the devices allocated by the pod, e.g. the pod restored from a
+// checkpoint expects the same device order as it was checkpointed. The i-th allocated device is the device with
+// the relative index hint[i] among the allocated devices sorted by minor.
+//
+// An example, the pod expects its logical device 0 to be the allocated card with the largest minor:
+//
+//	{
+//	  "gpu": [2, 0, 1]
+//	}
+type DeviceOrderingHint map[schedulingv1alpha1.DeviceType][]int
+
+func GetDeviceOrderingHint(podAnnotations map[string]string) (DeviceOrderingHint, error) {
+	hint := DeviceOrderingHint{}
+	data, ok := podAnnotations[AnnotationDeviceOrderingHint]
+	if !ok {
+		return nil, nil
+	}
+	err := json.Unmarshal([]byte(data), &hint)
+	if err != nil {
+		return nil, err
+	}
+	return hint, nil
+}
+
+func GetDeviceReuseHint(podAnnotations map[string]string) (DeviceAllocations, error) {
+	data, ok := podAnnotations[AnnotationDeviceReuseHint]
+	if !ok {
+		return nil, nil
+	}
+	hint := DeviceAllocations{}
+	if err := json.Unmarshal([]byte(data), &hint); err != nil {
+		return nil, err
+	}
+	return hint, nil
+}
+
+// GetDeviceAllocationsSummary summarizes the devices allocated to the pod in human-readable form, e.g.
+// "allocated GPU 0,3 (A100-80G) on node node-1, 50% gpu-core each". The GPU model is omitted if empty.
+func GetDeviceAllocationsSummary(allocations DeviceAllocations, gpuModel string, nodeName string) string {
+	var devices []string
+	for _, deviceType := range []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU, schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA} {
+		deviceAllocations := sortedDeviceAllocations(allocations[deviceType])
+		if len(deviceAllocations) == 0 {
+			continue
+		}
+		minors := make([]string, 0, len(deviceAllocations))
+		for _, allocation := range deviceAllocations {
+			minors = append(minors, strconv.Itoa(int(allocation.Minor)))
+		}
+		device := fmt.Sprintf("%s %s", strings.ToUpper(string(deviceType)), strings.Join(minors, ","))
+		if deviceType == schedulingv1alpha1.GPU && gpuModel != "" {
+			device += fmt.Sprintf(" (%s)", gpuModel)
+		}
+		devices = append(devices, device)
+	}
+	summary := fmt.Sprintf("allocated %s on node %s", strings.Join(devices, ", "), nodeName)
+	if share := gpuCoreShareSummary(sortedDeviceAllocations(allocations[schedulingv1alpha1.GPU])); share != "" {
+		summary += ", " + share
+	}
+	return summary
+}
+
+func sortedDeviceAllocations(allocations []*DeviceAllocation) []*DeviceAllocation {
+	sorted := make([]*DeviceAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		if allocation != nil {
+			sorted = append(sorted, allocation)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Minor < sorted[j].Minor
+	})
+	return sorted
+}
+
+// gpuCoreShareSummary describes the gpu-core shares of the fractional GPUs, empty if all the GPUs are allocated wholly
+// or the gpu-core is unknown.
+func gpuCoreShareSummary(allocations []*DeviceAllocation) string {
+	fractional := false
+	shares := make([]string, 0, len(allocations))
+	for _, allocation := range allocations {
+		quantity, ok := allocation.Resources[GPUCore]
+		if !ok {
+			return ""
+		}
+		gpuCore := quantity.Value()
+		if gpuCore < 100 {
+			fractional = true
+		}
+		shares = append(shares, fmt.Sprintf("%d%%", gpuCore))
+	}
+	if !fractional {
+		return ""
+	}
+	for _, share := range shares {
+		if share != shares[0] {
+			return fmt.Sprintf("%s gpu-core", strings.Join(shares, ","))
+		}
+	}
+	if len(shares) > 1 {
+		return fmt.Sprintf("%s gpu-core each", shares[0])
+	}
+	return fmt.Sprintf("%s gpu-core", shares[0])
+}
+
+func GetGPUTargetUUID(podAnnotations map[string]string) string {
+	return podAnnotations[AnnotationGPUTargetUUID]
+}
+
+// GPUComputeCapability is the compute capability of a GPU in the form of "<major>.<minor>", e.g. "8.0".
+type GPUComputeCapability struct {
+	Major int
+	Minor int
+}
+
+func ParseGPUComputeCapability(value string) (GPUComputeCapability, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return GPUComputeCapability{}, fmt.Errorf("invalid compute capability %q, expected <major>.<minor>", value)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major < 0 {
+		return GPUComputeCapability{}, fmt.Errorf("invalid compute capability %q, expected <major>.<minor>", value)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return GPUComputeCapability{}, fmt.Errorf("invalid compute capability %q, expected <major>.<minor>", value)
+	}
+	return GPUComputeCapability{Major: major, Minor: minor}, nil
+}
+
+// AtLeast returns whether the compute capability is not lower than the given one.
+func (c GPUComputeCapability) AtLeast(other GPUComputeCapability) bool {
+	if c.Major != other.Major {
+		return c.Major > other.Major
+	}
+	return c.Minor >= other.Minor
+}
+
+func (c GPUComputeCapability) String() string {
+	return fmt.Sprintf("%d.%d", c.Major, c.Minor)
+}
+
+func GetGPUMinComputeCapability(podAnnotations map[string]string) (*GPUComputeCapability, error) {
+	data, ok := podAnnotations[AnnotationGPUMinComputeCapability]
+	if !ok {
+		return nil, nil
+	}
+	capability, err := ParseGPUComputeCapability(data)
+	if err != nil {
+		return nil, err
+	}
+	return &capability, nil
+}
+
+// GPUModelSelector is the allow list of the GPU models, e.g. ["A100", "A800"].
+type GPUModelSelector []string
+
+func ParseGPUModelSelector(value string) (GPUModelSelector, error) {
+	var selector GPUModelSelector
+	for _, model := range strings.Split(value, ",") {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			return nil, fmt.Errorf("invalid GPU model selector %q, expected comma-separated models", value)
+		}
+		selector = append(selector, model)
+	}
+	return selector, nil
+}
+
+// Matches returns whether the GPU model is one of the allowed models or their variants,
+// e.g. "A100" matches "A100" and "A100-SXM4-80GB". The match is case-insensitive.
+func (s GPUModelSelector) Matches(model string) bool {
+	if model == "" {
+		return false
+	}
+	model = strings.ToLower(model)
+	for _, allowed := range s {
+		allowed = strings.ToLower(allowed)
+		if model == allowed || strings.HasPrefix(model, allowed+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s GPUModelSelector) String() string {
+	return strings.Join(s, ",")
+}
+
+func GetGPUModelSelector(podAnnotations map[string]string) (GPUModelSelector, error) {
+	data, ok := podAnnotations[AnnotationGPUModel]
+	if !ok {
+		return nil, nil
+	}
+	return ParseGPUModelSelector(data)
+}
+
+// IsGPUExclusive checks if the pod demands the GPUs not shared with the other pods.
+func IsGPUExclusive(podAnnotations map[string]string) bool {
+	return podAnnotations[AnnotationGPUExclusive] == "true"
+}
+
 var GetMinNum = func(pod *corev1.Pod) (int, error) {
 	minRequiredNum, err := strconv.ParseInt(pod.Annotations[AnnotationGangMinNum], 10, 32)
 	if err != nil {
@@ -138,3 +138,255 @@ func Test_SetDeviceAllocations(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetDeviceOrderingHint(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        DeviceOrderingHint
+		wantErr     bool
+	}{
+		{
+			name: "nil annotations",
+		},
+		{
+			name: "valid hint",
+			annotations: map[string]string{
+				AnnotationDeviceOrderingHint: `{"gpu":[2,0,1]}`,
+			},
+			want: DeviceOrderingHint{
+				schedulingv1alpha1.GPU: {2, 0, 1},
+			},
+		},
+		{
+			name: "invalid hint",
+			annotations: map[string]string{
+				AnnotationDeviceOrderingHint: `[2,0,1]`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetDeviceOrderingHint(tt.annotations)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_GetDeviceJointAllocate(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *DeviceJointAllocate
+		wantErr     bool
+	}{
+		{
+			name: "nil annotations",
+		},
+		{
+			name: "valid joint allocate",
+			annotations: map[string]string{
+				AnnotationDeviceJointAllocate: `{"deviceTypes":["gpu","rdma"]}`,
+			},
+			want: &DeviceJointAllocate{
+				DeviceTypes: []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU, schedulingv1alpha1.RDMA},
+			},
+		},
+		{
+			name: "invalid joint allocate",
+			annotations: map[string]string{
+				AnnotationDeviceJointAllocate: `["gpu","rdma"]`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetDeviceJointAllocate(tt.annotations)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_GetDeviceAllocationsSummary(t *testing.T) {
+	gpu := func(minor int32, gpuCore string) *DeviceAllocation {
+		return &DeviceAllocation{
+			Minor: minor,
+			Resources: corev1.ResourceList{
+				GPUCore:        resource.MustParse(gpuCore),
+				GPUMemoryRatio: resource.MustParse(gpuCore),
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		allocations DeviceAllocations
+		gpuModel    string
+		want        string
+	}{
+		{
+			name: "single GPU",
+			allocations: DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpu(0, "100")},
+			},
+			gpuModel: "A100-80G",
+			want:     "allocated GPU 0 (A100-80G) on node test-node",
+		},
+		{
+			name: "multiple GPUs without model",
+			allocations: DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpu(3, "100"), gpu(0, "100")},
+			},
+			want: "allocated GPU 0,3 on node test-node",
+		},
+		{
+			name: "single fractional GPU",
+			allocations: DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpu(2, "50")},
+			},
+			gpuModel: "A100-80G",
+			want:     "allocated GPU 2 (A100-80G) on node test-node, 50% gpu-core",
+		},
+		{
+			name: "fractional GPUs with different shares",
+			allocations: DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpu(1, "30"), gpu(0, "50")},
+			},
+			want: "allocated GPU 0,1 on node test-node, 50%,30% gpu-core",
+		},
+		{
+			name: "GPUs and RDMA",
+			allocations: DeviceAllocations{
+				schedulingv1alpha1.RDMA: {{Minor: 1}},
+				schedulingv1alpha1.GPU:  {gpu(0, "100"), gpu(1, "100")},
+			},
+			gpuModel: "A100-80G",
+			want:     "allocated GPU 0,1 (A100-80G), RDMA 1 on node test-node",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetDeviceAllocationsSummary(tt.allocations, tt.gpuModel, "test-node"))
+		})
+	}
+}
+
+func Test_GetGPUMinComputeCapability(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *GPUComputeCapability
+		wantErr     bool
+	}{
+		{
+			name: "nil annotations",
+		},
+		{
+			name: "valid compute capability",
+			annotations: map[string]string{
+				AnnotationGPUMinComputeCapability: "8.6",
+			},
+			want: &GPUComputeCapability{Major: 8, Minor: 6},
+		},
+		{
+			name: "missing minor version",
+			annotations: map[string]string{
+				AnnotationGPUMinComputeCapability: "8",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid version",
+			annotations: map[string]string{
+				AnnotationGPUMinComputeCapability: "8.x",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetGPUMinComputeCapability(tt.annotations)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_GPUComputeCapability_AtLeast(t *testing.T) {
+	min := GPUComputeCapability{Major: 8, Minor: 0}
+	assert.True(t, GPUComputeCapability{Major: 8, Minor: 0}.AtLeast(min))
+	assert.True(t, GPUComputeCapability{Major: 8, Minor: 6}.AtLeast(min))
+	assert.True(t, GPUComputeCapability{Major: 9, Minor: 0}.AtLeast(min))
+	assert.False(t, GPUComputeCapability{Major: 7, Minor: 5}.AtLeast(min))
+	assert.Equal(t, "7.5", GPUComputeCapability{Major: 7, Minor: 5}.String())
+}
+
+func Test_GetGPUModelSelector(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        GPUModelSelector
+		wantErr     bool
+	}{
+		{
+			name: "nil annotations",
+		},
+		{
+			name: "single model",
+			annotations: map[string]string{
+				AnnotationGPUModel: "A100",
+			},
+			want: GPUModelSelector{"A100"},
+		},
+		{
+			name: "allow list of models",
+			annotations: map[string]string{
+				AnnotationGPUModel: "A100, A800",
+			},
+			want: GPUModelSelector{"A100", "A800"},
+		},
+		{
+			name: "empty model",
+			annotations: map[string]string{
+				AnnotationGPUModel: "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty model in the allow list",
+			annotations: map[string]string{
+				AnnotationGPUModel: "A100,,A800",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetGPUModelSelector(tt.annotations)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_GPUModelSelector_Matches(t *testing.T) {
+	selector := GPUModelSelector{"A100", "a800"}
+	assert.True(t, selector.Matches("A100"))
+	assert.True(t, selector.Matches("A100-SXM4-80GB"))
+	assert.True(t, selector.Matches("A800-PCIE-40GB"))
+	assert.False(t, selector.Matches("A10"))
+	assert.False(t, selector.Matches("A1000"))
+	assert.False(t, selector.Matches(""))
+	assert.Equal(t, "A100,a800", selector.String())
+}
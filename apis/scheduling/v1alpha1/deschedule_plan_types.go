@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type DeschedulePlanSpec struct {
+	// Victims are the pods the descheduling cycle intends to evict
+	Victims []DeschedulePlanVictim `json:"victims,omitempty"`
+	// Approved is set by the operator to approve (true) or deny (false) the plan.
+	// The plan keeps pending if it is not set.
+	// +optional
+	Approved *bool `json:"approved,omitempty"`
+	// ExpirationTime is the time after which the plan is discarded if it has not been executed
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+type DeschedulePlanVictim struct {
+	// PodRef represents the Pod to be evicted
+	PodRef *corev1.ObjectReference `json:"podRef"`
+	// Profile is the name of the descheduler profile which plans the eviction
+	Profile string `json:"profile,omitempty"`
+	// Trigger is the name of the plugin which plans the eviction
+	Trigger string `json:"trigger,omitempty"`
+	// Reason is the reason of the eviction
+	Reason string `json:"reason,omitempty"`
+}
+
+type DeschedulePlanPhase string
+
+const (
+	// DeschedulePlanPending represents the plan is waiting for the approval
+	DeschedulePlanPending DeschedulePlanPhase = "Pending"
+	// DeschedulePlanExecuted represents the plan is approved and executed
+	DeschedulePlanExecuted DeschedulePlanPhase = "Executed"
+	// DeschedulePlanDenied represents the plan is denied and discarded
+	DeschedulePlanDenied DeschedulePlanPhase = "Denied"
+	// DeschedulePlanExpired represents the plan is expired before executed and discarded
+	DeschedulePlanExpired DeschedulePlanPhase = "Expired"
+)
+
+type DeschedulePlanStatus struct {
+	// Phase represents the phase of the DeschedulePlan, e.g. Pending/Executed/Denied/Expired
+	Phase DeschedulePlanPhase `json:"phase,omitempty"`
+	// Message represents a human-readable message indicating details about why the DeschedulePlan is in this phase.
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the time the DeschedulePlan transitioned into the phase
+	// +nullable
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// DeschedulePlan records the evictions planned by a descheduling cycle which exceed the threshold of the approval,
+// and the cycle is executed only after the operator approves it.
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:resource:scope=Cluster,shortName=dsp
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase of DeschedulePlan"
+// +kubebuilder:printcolumn:name="Approved",type="boolean",JSONPath=".spec.approved"
+// +kubebuilder:printcolumn:name="Expiration",type="date",JSONPath=".spec.expirationTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+type DeschedulePlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeschedulePlanSpec   `json:"spec,omitempty"`
+	Status DeschedulePlanStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeschedulePlanList contains a list of DeschedulePlan
+type DeschedulePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeschedulePlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeschedulePlan{}, &DeschedulePlanList{})
+}
@@ -42,8 +42,52 @@ type DeviceInfo struct {
 	Type DeviceType `json:"type,omitempty"`
 	// Health indicates whether the device is normal
 	Health bool `json:"health,omitempty"`
+	// Unschedulable indicates the healthy device is not allocated to the new pods, e.g. it is being drained for
+	// maintenance. The allocations already on the device are not affected.
+	Unschedulable bool `json:"unschedulable,omitempty"`
 	// Resources is a set of (resource name, quantity) pairs
 	Resources corev1.ResourceList `json:"resources,omitempty"`
+	// ComputeCapability is the compute capability of the GPU in the form of "<major>.<minor>", e.g. "8.0"
+	ComputeCapability string `json:"computeCapability,omitempty"`
+	// Model is the model of the GPU, e.g. "A100-SXM4-80GB"
+	Model string `json:"model,omitempty"`
+	// Topology represents the topology information of the device
+	Topology *DeviceTopology `json:"topology,omitempty"`
+	// MIGInstances is the number of the MIG instances of each profile carved from the GPU, e.g. {"1g.5gb": 7}.
+	// The GPU is allocated only by the MIG instances if it is set.
+	MIGInstances map[string]int32 `json:"migInstances,omitempty"`
+	// MIGPartitions is the MIG partitions carved from the GPU. The MIG instances of the GPU are counted from
+	// the partitions and MIGInstances is ignored if it is set, so that the partitions can be allocated by identity.
+	MIGPartitions []MIGPartition `json:"migPartitions,omitempty"`
+	// VirtualFunctions is the SR-IOV virtual functions of the RDMA device. The device is allocated only by the
+	// virtual functions if it is set, each of which counts as a whole device.
+	VirtualFunctions []VirtualFunction `json:"virtualFunctions,omitempty"`
+	// SharedSlots is the number of the pods sharing the GPU by time-slicing. The GPU is allocated only by the
+	// slots if it is set, each pod taking one slot.
+	SharedSlots int32 `json:"sharedSlots,omitempty"`
+}
+
+type MIGPartition struct {
+	// Profile is the profile of the MIG partition, e.g. "1g.5gb"
+	Profile string `json:"profile"`
+	// GPUInstanceID is the ID of the GPU instance of the partition, unique within the GPU
+	GPUInstanceID int32 `json:"gpuInstanceID"`
+	// ComputeInstanceID is the ID of the compute instance of the partition, unique within the GPU instance
+	ComputeInstanceID int32 `json:"computeInstanceID"`
+}
+
+type VirtualFunction struct {
+	// BusID is the PCI bus address of the virtual function, e.g. "0000:1f:00.2"
+	BusID string `json:"busID"`
+}
+
+type DeviceTopology struct {
+	// NodeID is the ID of the NUMA node the device attached to
+	NodeID int32 `json:"nodeID"`
+	// PCIESwitchID is the ID of the PCIe switch the device attached to, unique within the node
+	PCIESwitchID string `json:"pcieSwitchID,omitempty"`
+	// NVLinkGroupID is the ID of the group of the GPUs connected to each other by NVLink, unique within the node
+	NVLinkGroupID string `json:"nvlinkGroupID,omitempty"`
 }
 
 type DeviceStatus struct {
@@ -27,6 +27,132 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeschedulePlan) DeepCopyInto(out *DeschedulePlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeschedulePlan.
+func (in *DeschedulePlan) DeepCopy() *DeschedulePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(DeschedulePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeschedulePlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeschedulePlanList) DeepCopyInto(out *DeschedulePlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeschedulePlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeschedulePlanList.
+func (in *DeschedulePlanList) DeepCopy() *DeschedulePlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeschedulePlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeschedulePlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeschedulePlanSpec) DeepCopyInto(out *DeschedulePlanSpec) {
+	*out = *in
+	if in.Victims != nil {
+		in, out := &in.Victims, &out.Victims
+		*out = make([]DeschedulePlanVictim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Approved != nil {
+		in, out := &in.Approved, &out.Approved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeschedulePlanSpec.
+func (in *DeschedulePlanSpec) DeepCopy() *DeschedulePlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeschedulePlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeschedulePlanStatus) DeepCopyInto(out *DeschedulePlanStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeschedulePlanStatus.
+func (in *DeschedulePlanStatus) DeepCopy() *DeschedulePlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeschedulePlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeschedulePlanVictim) DeepCopyInto(out *DeschedulePlanVictim) {
+	*out = *in
+	if in.PodRef != nil {
+		in, out := &in.PodRef, &out.PodRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeschedulePlanVictim.
+func (in *DeschedulePlanVictim) DeepCopy() *DeschedulePlanVictim {
+	if in == nil {
+		return nil
+	}
+	out := new(DeschedulePlanVictim)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Device) DeepCopyInto(out *Device) {
 	*out = *in
@@ -111,6 +237,28 @@ func (in *DeviceInfo) DeepCopyInto(out *DeviceInfo) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(DeviceTopology)
+		**out = **in
+	}
+	if in.MIGInstances != nil {
+		in, out := &in.MIGInstances, &out.MIGInstances
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MIGPartitions != nil {
+		in, out := &in.MIGPartitions, &out.MIGPartitions
+		*out = make([]MIGPartition, len(*in))
+		copy(*out, *in)
+	}
+	if in.VirtualFunctions != nil {
+		in, out := &in.VirtualFunctions, &out.VirtualFunctions
+		*out = make([]VirtualFunction, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceInfo.
@@ -199,6 +347,36 @@ func (in *DeviceStatus) DeepCopy() *DeviceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceTopology) DeepCopyInto(out *DeviceTopology) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceTopology.
+func (in *DeviceTopology) DeepCopy() *DeviceTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MIGPartition) DeepCopyInto(out *MIGPartition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MIGPartition.
+func (in *MIGPartition) DeepCopy() *MIGPartition {
+	if in == nil {
+		return nil
+	}
+	out := new(MIGPartition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodMigrateReservationOptions) DeepCopyInto(out *PodMigrateReservationOptions) {
 	*out = *in
@@ -634,3 +812,18 @@ func (in *ReservationTemplateSpec) DeepCopy() *ReservationTemplateSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualFunction) DeepCopyInto(out *VirtualFunction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualFunction.
+func (in *VirtualFunction) DeepCopy() *VirtualFunction {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualFunction)
+	in.DeepCopyInto(out)
+	return out
+}
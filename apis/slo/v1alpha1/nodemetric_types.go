@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -38,6 +39,9 @@ type NodeMetricInfo struct {
 	NodeUsage ResourceMap `json:"nodeUsage,omitempty"`
 	// AggregatedNodeUsages will report only if there are enough samples
 	AggregatedNodeUsages []AggregatedUsage `json:"aggregatedNodeUsages,omitempty"`
+	// BEMemorySafetyMargin is the memory held back from the batch memory of the node after the BE pods get OOM killed.
+	// It is not accounted in the NodeUsage.
+	BEMemorySafetyMargin *resource.Quantity `json:"beMemorySafetyMargin,omitempty"`
 }
 
 type AggregatedUsage struct {
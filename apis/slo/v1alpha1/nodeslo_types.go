@@ -27,8 +27,28 @@ import (
 type CPUQOS struct {
 	// group identity value for pods, default = 0
 	GroupIdentity *int64 `json:"groupIdentity,omitempty"`
+	// PodCFSQuotaPolicy specifies how the pod-level cfs quota is derived from the containers', default = Kubelet.
+	// It takes effect on the LS and BE pods, while the LSE and LSR pods bound to cpusets are not limited by cfs quota.
+	// +kubebuilder:validation:Enum=Kubelet;Unlimited;Scaled
+	PodCFSQuotaPolicy *PodCFSQuotaPolicy `json:"podCFSQuotaPolicy,omitempty"`
+	// PodCFSQuotaScalePercent specifies the percentage to scale the kubelet's pod-level cfs quota when the policy
+	// is `Scaled`, default = 100.
+	// +kubebuilder:validation:Minimum=1
+	PodCFSQuotaScalePercent *int64 `json:"podCFSQuotaScalePercent,omitempty"`
 }
 
+type PodCFSQuotaPolicy string
+
+const (
+	// PodCFSQuotaKubeletPolicy keeps the pod-level cfs quota set by the kubelet, i.e. the sum of the containers'.
+	PodCFSQuotaKubeletPolicy PodCFSQuotaPolicy = "Kubelet"
+	// PodCFSQuotaUnlimitedPolicy removes the pod-level cfs quota, while the containers are still limited individually.
+	// It is ignored for the BE pods when the cpu suppression is enabled.
+	PodCFSQuotaUnlimitedPolicy PodCFSQuotaPolicy = "Unlimited"
+	// PodCFSQuotaScaledPolicy scales the kubelet's pod-level cfs quota by PodCFSQuotaScalePercent.
+	PodCFSQuotaScaledPolicy PodCFSQuotaPolicy = "Scaled"
+)
+
 // MemoryQOS enables memory qos features.
 type MemoryQOS struct {
 	// memcg qos
@@ -129,6 +149,8 @@ type ResourceQOS struct {
 	CPUQOS     *CPUQOSCfg     `json:"cpuQOS,omitempty"`
 	MemoryQOS  *MemoryQOSCfg  `json:"memoryQOS,omitempty"`
 	ResctrlQOS *ResctrlQOSCfg `json:"resctrlQOS,omitempty"`
+	NetworkQOS *NetworkQOSCfg `json:"networkQOS,omitempty"`
+	BlkIOQOS   *BlkIOQOSCfg   `json:"blkioQOS,omitempty"`
 }
 
 type ResourceQOSStrategy struct {
@@ -165,6 +187,11 @@ type ResourceThresholdStrategy struct {
 	CPUSuppressThresholdPercent *int64 `json:"cpuSuppressThresholdPercent,omitempty"`
 	// CPUSuppressPolicy
 	CPUSuppressPolicy CPUSuppressPolicy `json:"cpuSuppressPolicy,omitempty"`
+	// CPUSuppressTerminatingUsagePercent is the percentage [0,100] of the CPU usage of the terminating LS pods counted
+	// as the LS usage when suppressing BE, to discount the usage the pods are about to release. default = 100
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	CPUSuppressTerminatingUsagePercent *int64 `json:"cpuSuppressTerminatingUsagePercent,omitempty"`
 
 	// upper: memory evict threshold percentage (0,100), default = 70
 	// +kubebuilder:validation:Maximum=100
@@ -183,6 +210,15 @@ type ResourceThresholdStrategy struct {
 	CPUEvictBEUsageThresholdPercent *int64 `json:"cpuEvictBEUsageThresholdPercent,omitempty"`
 	// cpu evict start after continue avg(cpuusage) > CPUEvictThresholdPercent in seconds
 	CPUEvictTimeWindowSeconds *int64 `json:"cpuEvictTimeWindowSeconds,omitempty"`
+
+	// BEPodPIDsMax is the pids.max set on the cgroup of each BE pod, not limited if not specified
+	// +kubebuilder:validation:Minimum=1
+	BEPodPIDsMax *int64 `json:"bePodPIDsMax,omitempty"`
+	// evict the BE pod with the most tasks when the node pid usage percentage (0,100] reaches PIDEvictThresholdPercent,
+	// not evicted if not specified
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	PIDEvictThresholdPercent *int64 `json:"pidEvictThresholdPercent,omitempty"`
 }
 
 // ResctrlQOSCfg stores node-level config of resctrl qos
@@ -205,6 +241,69 @@ type ResctrlQOS struct {
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
 	MBAPercent *int64 `json:"mbaPercent,omitempty"`
+	// MBAMinPercent is the lower bound of the MBA percent tightened by the memory bandwidth feedback
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MBAMinPercent *int64 `json:"mbaMinPercent,omitempty"`
+	// MBAMaxPercent is the upper bound of the MBA percent relaxed by the memory bandwidth feedback
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MBAMaxPercent *int64 `json:"mbaMaxPercent,omitempty"`
+}
+
+// NetworkQOS enables network qos features.
+type NetworkQOS struct {
+	// IngressLimitKbps is the RX bandwidth limit of each pod in kbit/s, enforced by the tbf qdiscs on the host-side
+	// veths of the pods. It takes effect on the BE pods only, except the ones using the host network.
+	// +kubebuilder:validation:Minimum=1
+	IngressLimitKbps *int64 `json:"ingressLimitKbps,omitempty"`
+	// IngressBurstKB is the burst size of the RX shapers in KB, default = 256
+	// +kubebuilder:validation:Minimum=1
+	IngressBurstKB *int64 `json:"ingressBurstKB,omitempty"`
+}
+
+// NetworkQOSCfg stores node-level config of network qos
+type NetworkQOSCfg struct {
+	// Enable indicates whether the network qos is enabled.
+	Enable     *bool `json:"enable,omitempty"`
+	NetworkQOS `json:",inline"`
+}
+
+// BlkIOQOS enables the IO throttling on the block devices.
+type BlkIOQOS struct {
+	// Blocks are the IO limits on the filesystems of the mount points. They take effect on the BE class only, and
+	// apply to the besteffort cgroup.
+	Blocks []*BlockCfg `json:"blocks,omitempty"`
+}
+
+// BlockCfg is the IO limits on the filesystem of a mount point. The limits apply to the physical disks under the
+// device the filesystem is mounted on, e.g. the disks of the LVM volume group, since the throttling against the
+// device-mapper and md devices does not take effect.
+type BlockCfg struct {
+	// MountPoint is the mount point of the filesystem in the host mount namespace, e.g. /var/lib/kubelet.
+	MountPoint string `json:"mountPoint,omitempty"`
+	IOCfg      `json:",inline"`
+}
+
+// IOCfg is the IO limits of a block device, unlimited if zero.
+type IOCfg struct {
+	// +kubebuilder:validation:Minimum=0
+	ReadIOPS *int64 `json:"readIOPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	WriteIOPS *int64 `json:"writeIOPS,omitempty"`
+	// ReadBPS is the read bandwidth limit in bytes/s.
+	// +kubebuilder:validation:Minimum=0
+	ReadBPS *int64 `json:"readBPS,omitempty"`
+	// WriteBPS is the write bandwidth limit in bytes/s.
+	// +kubebuilder:validation:Minimum=0
+	WriteBPS *int64 `json:"writeBPS,omitempty"`
+}
+
+// BlkIOQOSCfg stores node-level config of blkio qos
+type BlkIOQOSCfg struct {
+	// Enable indicates whether the blkio qos is enabled.
+	Enable   *bool `json:"enable,omitempty"`
+	BlkIOQOS `json:",inline"`
 }
 
 type CPUBurstPolicy string
@@ -71,6 +71,69 @@ func (in *AggregatedUsage) DeepCopy() *AggregatedUsage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlkIOQOS) DeepCopyInto(out *BlkIOQOS) {
+	*out = *in
+	if in.Blocks != nil {
+		in, out := &in.Blocks, &out.Blocks
+		*out = make([]*BlockCfg, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(BlockCfg)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlkIOQOS.
+func (in *BlkIOQOS) DeepCopy() *BlkIOQOS {
+	if in == nil {
+		return nil
+	}
+	out := new(BlkIOQOS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlkIOQOSCfg) DeepCopyInto(out *BlkIOQOSCfg) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	in.BlkIOQOS.DeepCopyInto(&out.BlkIOQOS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlkIOQOSCfg.
+func (in *BlkIOQOSCfg) DeepCopy() *BlkIOQOSCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(BlkIOQOSCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockCfg) DeepCopyInto(out *BlockCfg) {
+	*out = *in
+	in.IOCfg.DeepCopyInto(&out.IOCfg)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockCfg.
+func (in *BlockCfg) DeepCopy() *BlockCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CPUBurstConfig) DeepCopyInto(out *CPUBurstConfig) {
 	*out = *in
@@ -130,6 +193,16 @@ func (in *CPUQOS) DeepCopyInto(out *CPUQOS) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.PodCFSQuotaPolicy != nil {
+		in, out := &in.PodCFSQuotaPolicy, &out.PodCFSQuotaPolicy
+		*out = new(PodCFSQuotaPolicy)
+		**out = **in
+	}
+	if in.PodCFSQuotaScalePercent != nil {
+		in, out := &in.PodCFSQuotaScalePercent, &out.PodCFSQuotaScalePercent
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUQOS.
@@ -163,6 +236,41 @@ func (in *CPUQOSCfg) DeepCopy() *CPUQOSCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOCfg) DeepCopyInto(out *IOCfg) {
+	*out = *in
+	if in.ReadIOPS != nil {
+		in, out := &in.ReadIOPS, &out.ReadIOPS
+		*out = new(int64)
+		**out = **in
+	}
+	if in.WriteIOPS != nil {
+		in, out := &in.WriteIOPS, &out.WriteIOPS
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ReadBPS != nil {
+		in, out := &in.ReadBPS, &out.ReadBPS
+		*out = new(int64)
+		**out = **in
+	}
+	if in.WriteBPS != nil {
+		in, out := &in.WriteBPS, &out.WriteBPS
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOCfg.
+func (in *IOCfg) DeepCopy() *IOCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(IOCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemoryQOS) DeepCopyInto(out *MemoryQOS) {
 	*out = *in
@@ -312,6 +420,11 @@ func (in *NodeMetricInfo) DeepCopyInto(out *NodeMetricInfo) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BEMemorySafetyMargin != nil {
+		in, out := &in.BEMemorySafetyMargin, &out.BEMemorySafetyMargin
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetricInfo.
@@ -411,6 +524,52 @@ func (in *NodeMetricStatus) DeepCopy() *NodeMetricStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQOS) DeepCopyInto(out *NetworkQOS) {
+	*out = *in
+	if in.IngressLimitKbps != nil {
+		in, out := &in.IngressLimitKbps, &out.IngressLimitKbps
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IngressBurstKB != nil {
+		in, out := &in.IngressBurstKB, &out.IngressBurstKB
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQOS.
+func (in *NetworkQOS) DeepCopy() *NetworkQOS {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQOS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQOSCfg) DeepCopyInto(out *NetworkQOSCfg) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	in.NetworkQOS.DeepCopyInto(&out.NetworkQOS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQOSCfg.
+func (in *NetworkQOSCfg) DeepCopy() *NetworkQOSCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQOSCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeSLO) DeepCopyInto(out *NodeSLO) {
 	*out = *in
@@ -573,6 +732,16 @@ func (in *ResctrlQOS) DeepCopyInto(out *ResctrlQOS) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MBAMinPercent != nil {
+		in, out := &in.MBAMinPercent, &out.MBAMinPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MBAMaxPercent != nil {
+		in, out := &in.MBAMaxPercent, &out.MBAMaxPercent
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResctrlQOS.
@@ -653,6 +822,16 @@ func (in *ResourceQOS) DeepCopyInto(out *ResourceQOS) {
 		*out = new(ResctrlQOSCfg)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NetworkQOS != nil {
+		in, out := &in.NetworkQOS, &out.NetworkQOS
+		*out = new(NetworkQOSCfg)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlkIOQOS != nil {
+		in, out := &in.BlkIOQOS, &out.BlkIOQOS
+		*out = new(BlkIOQOSCfg)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQOS.
@@ -718,6 +897,11 @@ func (in *ResourceThresholdStrategy) DeepCopyInto(out *ResourceThresholdStrategy
 		*out = new(int64)
 		**out = **in
 	}
+	if in.CPUSuppressTerminatingUsagePercent != nil {
+		in, out := &in.CPUSuppressTerminatingUsagePercent, &out.CPUSuppressTerminatingUsagePercent
+		*out = new(int64)
+		**out = **in
+	}
 	if in.MemoryEvictThresholdPercent != nil {
 		in, out := &in.MemoryEvictThresholdPercent, &out.MemoryEvictThresholdPercent
 		*out = new(int64)
@@ -748,6 +932,16 @@ func (in *ResourceThresholdStrategy) DeepCopyInto(out *ResourceThresholdStrategy
 		*out = new(int64)
 		**out = **in
 	}
+	if in.BEPodPIDsMax != nil {
+		in, out := &in.BEPodPIDsMax, &out.BEPodPIDsMax
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PIDEvictThresholdPercent != nil {
+		in, out := &in.PIDEvictThresholdPercent, &out.PIDEvictThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceThresholdStrategy.
@@ -22,6 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
 	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 )
@@ -33,10 +34,12 @@ func init() {
 	_ = sev1alpha1.AddToScheme(clientgoscheme.Scheme)
 	_ = appsv1alpha1.AddToScheme(clientgoscheme.Scheme)
 	_ = appsv1beta1.AddToScheme(clientgoscheme.Scheme)
+	_ = schedv1alpha1.AddToScheme(clientgoscheme.Scheme)
 
 	_ = sev1alpha1.AddToScheme(scheme)
 	_ = appsv1alpha1.AddToScheme(scheme)
 	_ = appsv1beta1.AddToScheme(scheme)
+	_ = schedv1alpha1.AddToScheme(scheme)
 
 	scheme.AddUnversionedTypes(metav1.SchemeGroupVersion, &metav1.UpdateOptions{}, &metav1.DeleteOptions{}, &metav1.CreateOptions{})
 	// +kubebuilder:scaffold:scheme
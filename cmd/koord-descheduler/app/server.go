@@ -304,6 +304,9 @@ func Setup(ctx context.Context, opts *options.Options, outOfTreeRegistryOptions
 		descheduler.WithDryRun(cc.ComponentConfig.DryRun),
 		descheduler.WithDeschedulingInterval(cc.ComponentConfig.DeschedulingInterval.Duration),
 		descheduler.WithNodeSelector(cc.ComponentConfig.NodeSelector),
+		descheduler.WithEvictionApproval(cc.ComponentConfig.EvictionApproval),
+		descheduler.WithAdaptiveInterval(cc.ComponentConfig.AdaptiveInterval),
+		descheduler.WithClient(cc.Manager.GetClient()),
 		descheduler.WithPodAssignedToNodeFn(podAssignedToNode(cc.Manager.GetClient())),
 		descheduler.WithBuildFrameworkCapturer(func(profile deschedulerconfig.DeschedulerProfile) {
 			completedProfiles = append(completedProfiles, profile)
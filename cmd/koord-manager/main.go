@@ -27,6 +27,7 @@ import (
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/rest"
@@ -55,6 +56,8 @@ import (
 	// +kubebuilder:scaffold:imports
 )
 
+const leaderElectionID = "koordinator-manager"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -144,7 +147,7 @@ func main() {
 		MetricsBindAddress:         metricsAddr,
 		HealthProbeBindAddress:     healthProbeAddr,
 		LeaderElection:             enableLeaderElection,
-		LeaderElectionID:           "koordinator-manager",
+		LeaderElectionID:           leaderElectionID,
 		LeaderElectionNamespace:    leaderElectionNamespace,
 		LeaderElectionResourceLock: resourcelock.ConfigMapsResourceLock,
 		Namespace:                  namespace,
@@ -156,6 +159,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableLeaderElection && leaderElectionNamespace != "" {
+		// fence the node resource updates by the leadership generation to avoid the split-brain writes
+		noderesource.LeaderElectionLock = types.NamespacedName{Namespace: leaderElectionNamespace, Name: leaderElectionID}
+	}
+
 	setupLog.Info("register field index")
 	if err := fieldindex.RegisterFieldIndexes(mgr.GetCache()); err != nil {
 		setupLog.Error(err, "failed to register field index")
@@ -336,12 +336,24 @@ func Setup(ctx context.Context, opts *options.Options, schedulingHooks []framewo
 	// Get the completed config
 	cc := c.Complete()
 
+	tracerProvider, shutdownTracerProvider, err := frameworkext.NewTracerProvider(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		if err := shutdownTracerProvider(context.Background()); err != nil {
+			klog.ErrorS(err, "Failed to shutdown the tracer provider")
+		}
+	}()
+
 	// NOTE(joseph): K8s scheduling framework does not provide extension point for initialization.
 	// Currently, only by copying the initialization code and implementing custom initialization.
 	extendedHandle, err := frameworkext.NewExtendedHandle(
 		frameworkext.WithServicesEngine(cc.ServicesEngine),
 		frameworkext.WithKoordinatorClientSet(cc.KoordinatorClient),
 		frameworkext.WithKoordinatorSharedInformerFactory(cc.KoordinatorSharedInformerFactory),
+		frameworkext.WithTracerProvider(tracerProvider),
 	)
 	if err != nil {
 		return nil, nil, nil, err
@@ -382,12 +394,24 @@ func Setup(ctx context.Context, opts *options.Options, schedulingHooks []framewo
 	if err := scheduleroptions.LogOrWriteConfig(opts.WriteConfigTo, &cc.ComponentConfig, completedProfiles); err != nil {
 		return nil, nil, nil, err
 	}
+	for i := range completedProfiles {
+		if err := frameworkext.ValidatePluginOrders(&completedProfiles[i]); err != nil {
+			return nil, nil, nil, err
+		}
+	}
 
 	// TODO(joseph): Some extensions can also be made in the future,
 	//  such as replacing some interfaces in Scheduler to implement custom logic
 
+	// the shadow profiles run the frameworks of the profiles before being extended
+	shadowProfiles, err := frameworkext.NewShadowProfilesFromFlags(sched.Profiles)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hooks := append(append([]frameworkext.SchedulingPhaseHook{}, schedulingHooks...), shadowProfiles...)
+
 	// extend framework to hook run plugin functions
-	extendedFrameworkFactory := frameworkext.NewFrameworkExtenderFactory(extendedHandle, schedulingHooks...)
+	extendedFrameworkFactory := frameworkext.NewFrameworkExtenderFactory(extendedHandle, hooks...)
 	for k, v := range sched.Profiles {
 		sched.Profiles[k] = extendedFrameworkFactory.New(v)
 	}
@@ -42,6 +42,12 @@ func main() {
 	cfg := config.NewConfiguration()
 	cfg.InitFlags(flag.CommandLine)
 	flag.Parse()
+	if err := cfg.ApplyConfigFile(flag.CommandLine, os.Args[1:]); err != nil {
+		klog.Fatalf("Unable to apply config file: %v", err)
+	}
+	if err := cfg.ApplyProfile(flag.CommandLine); err != nil {
+		klog.Fatalf("Unable to apply profile: %v", err)
+	}
 
 	go wait.Forever(klog.Flush, 5*time.Second)
 	defer klog.Flush()
@@ -79,6 +85,10 @@ func main() {
 		klog.Error("Unable to init config from ConfigMap: ", err)
 		os.Exit(1)
 	}
+	if err = cfg.RunProfileSync(stopCtx.Done()); err != nil {
+		klog.Error("Unable to sync profile from ConfigMap: ", err)
+		os.Exit(1)
+	}
 
 	d, err := agent.NewDaemon(cfg)
 	if err != nil {
@@ -26,6 +26,7 @@ import (
 
 type SchedulingV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	DeschedulePlansGetter
 	DevicesGetter
 	PodMigrationJobsGetter
 	ReservationsGetter
@@ -36,6 +37,10 @@ type SchedulingV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *SchedulingV1alpha1Client) DeschedulePlans() DeschedulePlanInterface {
+	return newDeschedulePlans(c)
+}
+
 func (c *SchedulingV1alpha1Client) Devices() DeviceInterface {
 	return newDevices(c)
 }
@@ -59,6 +59,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1alpha1().ClusterColocationProfiles().Informer()}, nil
 
 		// Group=scheduling, Version=v1alpha1
+	case schedulingv1alpha1.SchemeGroupVersion.WithResource("descheduleplans"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Scheduling().V1alpha1().DeschedulePlans().Informer()}, nil
 	case schedulingv1alpha1.SchemeGroupVersion.WithResource("devices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Scheduling().V1alpha1().Devices().Informer()}, nil
 	case schedulingv1alpha1.SchemeGroupVersion.WithResource("podmigrationjobs"):
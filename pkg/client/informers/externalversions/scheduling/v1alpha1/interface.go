@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// DeschedulePlans returns a DeschedulePlanInformer.
+	DeschedulePlans() DeschedulePlanInformer
 	// Devices returns a DeviceInformer.
 	Devices() DeviceInformer
 	// PodMigrationJobs returns a PodMigrationJobInformer.
@@ -43,6 +45,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// DeschedulePlans returns a DeschedulePlanInformer.
+func (v *version) DeschedulePlans() DeschedulePlanInformer {
+	return &deschedulePlanInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Devices returns a DeviceInformer.
 func (v *version) Devices() DeviceInformer {
 	return &deviceInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
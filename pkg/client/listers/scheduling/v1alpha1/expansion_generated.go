@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// DeschedulePlanListerExpansion allows custom methods to be added to
+// DeschedulePlanLister.
+type DeschedulePlanListerExpansion interface{}
+
 // DeviceListerExpansion allows custom methods to be added to
 // DeviceLister.
 type DeviceListerExpansion interface{}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
+)
+
+// cycleOutcome summarizes what a descheduling cycle has done to the cluster.
+type cycleOutcome struct {
+	evicted                   int
+	nodeClassificationChanged bool
+}
+
+// isNoop returns true if the cycle neither performed evictions nor saw the node classifications change.
+func (o cycleOutcome) isNoop() bool {
+	return o.evicted == 0 && !o.nodeClassificationChanged
+}
+
+// adaptiveInterval computes the interval before the next descheduling cycle from the outcomes of the recent cycles.
+// The interval is halved down to the floor after an active cycle, and doubled up to the ceiling after the
+// consecutive no-op cycles.
+type adaptiveInterval struct {
+	minInterval      time.Duration
+	maxInterval      time.Duration
+	idleCyclesToGrow int32
+
+	current    time.Duration
+	idleCycles int32
+}
+
+func newAdaptiveInterval(initial time.Duration, cfg *deschedulerconfig.AdaptiveIntervalConfiguration) *adaptiveInterval {
+	a := &adaptiveInterval{
+		minInterval:      cfg.MinInterval.Duration,
+		maxInterval:      cfg.MaxInterval.Duration,
+		idleCyclesToGrow: cfg.IdleCyclesToGrow,
+	}
+	a.current = a.clamp(initial)
+	return a
+}
+
+func (a *adaptiveInterval) clamp(interval time.Duration) time.Duration {
+	if interval < a.minInterval {
+		return a.minInterval
+	}
+	if interval > a.maxInterval {
+		return a.maxInterval
+	}
+	return interval
+}
+
+// next records the outcome of a cycle and returns the interval before the next cycle.
+func (a *adaptiveInterval) next(outcome cycleOutcome) time.Duration {
+	if !outcome.isNoop() {
+		a.idleCycles = 0
+		a.current = a.clamp(a.current / 2)
+		return a.current
+	}
+	a.idleCycles++
+	if a.idleCycles >= a.idleCyclesToGrow {
+		a.idleCycles = 0
+		a.current = a.clamp(a.current * 2)
+	}
+	return a.current
+}
+
+// runWithAdaptiveInterval runs the descheduling cycles until the context is done, and the interval between the
+// cycles adapts to the cluster churn. Like the static interval, the interval counts from the start of a cycle.
+func (d *Descheduler) runWithAdaptiveInterval(ctx context.Context) {
+	metrics.DeschedulingInterval.Set(d.adaptiveInterval.current.Seconds())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		start := d.clock.Now()
+		evictedBefore := d.totalEvicted()
+		err := d.deschedulerOnce(ctx)
+		if err != nil {
+			klog.Errorf("Error descheduling pods: %v", err)
+		}
+		// the plugins may not run in a failed cycle, so their node classifications are left from the last cycle
+		outcome := cycleOutcome{
+			evicted:                   d.totalEvicted() - evictedBefore,
+			nodeClassificationChanged: err == nil && d.nodeClassificationChanged(),
+		}
+		interval := d.adaptiveInterval.next(outcome)
+		metrics.DeschedulingInterval.Set(interval.Seconds())
+		klog.V(4).InfoS("Descheduling cycle finished", "evicted", outcome.evicted,
+			"nodeClassificationChanged", outcome.nodeClassificationChanged, "nextInterval", interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.clock.After(interval - d.clock.Since(start)):
+		}
+	}
+}
+
+// totalEvicted returns the number of pods evicted by the Evictors of all profiles so far.
+func (d *Descheduler) totalEvicted() int {
+	total := 0
+	for _, p := range d.Profiles {
+		if counter, ok := p.Evictor().(framework.EvictionCounter); ok {
+			total += counter.TotalEvicted()
+		}
+	}
+	return total
+}
+
+func (d *Descheduler) nodeClassificationChanged() bool {
+	for _, p := range d.Profiles {
+		if classifier, ok := p.(framework.NodeClassifier); ok && classifier.NodeClassificationChanged() {
+			return true
+		}
+	}
+	return false
+}
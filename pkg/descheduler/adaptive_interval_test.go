@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+func TestAdaptiveInterval(t *testing.T) {
+	cfg := &deschedulerconfig.AdaptiveIntervalConfiguration{
+		MinInterval:      metav1.Duration{Duration: time.Minute},
+		MaxInterval:      metav1.Duration{Duration: 8 * time.Minute},
+		IdleCyclesToGrow: 2,
+	}
+	noop := cycleOutcome{}
+	evicted := cycleOutcome{evicted: 3}
+	reclassified := cycleOutcome{nodeClassificationChanged: true}
+
+	tests := []struct {
+		name     string
+		initial  time.Duration
+		outcomes []cycleOutcome
+		want     []time.Duration
+	}{
+		{
+			name:     "shrink down to the floor on evictions",
+			initial:  4 * time.Minute,
+			outcomes: []cycleOutcome{evicted, evicted, evicted},
+			want:     []time.Duration{2 * time.Minute, time.Minute, time.Minute},
+		},
+		{
+			name:     "shrink on node classification changes",
+			initial:  4 * time.Minute,
+			outcomes: []cycleOutcome{reclassified},
+			want:     []time.Duration{2 * time.Minute},
+		},
+		{
+			name:     "grow up to the ceiling after consecutive no-op cycles",
+			initial:  2 * time.Minute,
+			outcomes: []cycleOutcome{noop, noop, noop, noop, noop, noop},
+			want:     []time.Duration{2 * time.Minute, 4 * time.Minute, 4 * time.Minute, 8 * time.Minute, 8 * time.Minute, 8 * time.Minute},
+		},
+		{
+			name:     "active cycle resets the no-op cycles",
+			initial:  2 * time.Minute,
+			outcomes: []cycleOutcome{noop, evicted, noop, noop, reclassified, noop},
+			want:     []time.Duration{2 * time.Minute, time.Minute, time.Minute, 2 * time.Minute, time.Minute, time.Minute},
+		},
+		{
+			name:     "initial interval is clamped",
+			initial:  time.Hour,
+			outcomes: []cycleOutcome{noop, evicted},
+			want:     []time.Duration{8 * time.Minute, 4 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAdaptiveInterval(tt.initial, cfg)
+			var got []time.Duration
+			for _, outcome := range tt.outcomes {
+				got = append(got, a.next(outcome))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
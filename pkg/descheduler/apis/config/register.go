@@ -48,6 +48,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DeschedulerConfiguration{},
 		&DefaultEvictorArgs{},
 		&RemovePodsViolatingNodeAffinityArgs{},
+		&NodeMaintenanceMigrationArgs{},
+		&NodeConditionMigrationArgs{},
 		&MigrationControllerArgs{},
 		&LowNodeLoadArgs{},
 	)
@@ -61,6 +61,39 @@ type DeschedulerConfiguration struct {
 
 	// NodeSelector for a set of nodes to operate over
 	NodeSelector *metav1.LabelSelector
+
+	// EvictionApproval requires the operators to approve the descheduling cycles planning too many evictions.
+	// The approval workflow is disabled if it is nil.
+	EvictionApproval *EvictionApprovalConfiguration
+
+	// AdaptiveInterval adapts the interval between the descheduling cycles to the cluster churn.
+	// The DeschedulingInterval is used statically if it is nil, and the descheduler runs only once if the
+	// DeschedulingInterval is 0 even if it is set.
+	AdaptiveInterval *AdaptiveIntervalConfiguration
+}
+
+// AdaptiveIntervalConfiguration configures the adaptive interval of the descheduling cycles. Starting from the
+// DeschedulingInterval, the interval is halved down to the MinInterval after a cycle performing evictions or
+// changing the node classifications, and doubled up to the MaxInterval after IdleCyclesToGrow consecutive no-op cycles.
+type AdaptiveIntervalConfiguration struct {
+	// MinInterval is the floor of the interval.
+	MinInterval metav1.Duration
+	// MaxInterval is the ceiling of the interval.
+	MaxInterval metav1.Duration
+	// IdleCyclesToGrow is the number of consecutive no-op cycles before the interval grows.
+	IdleCyclesToGrow int32
+}
+
+// EvictionApprovalConfiguration configures the approval workflow of the descheduling cycles.
+type EvictionApprovalConfiguration struct {
+	// EvictionThreshold is the max number of evictions a descheduling cycle executes without the approval.
+	// A cycle planning more evictions writes a DeschedulePlan and waits for the operators to approve it.
+	EvictionThreshold int32
+	// PlanExpiration is the duration a DeschedulePlan waits for the approval before it is discarded.
+	PlanExpiration metav1.Duration
+	// FinishedPlanTTL is the duration a DeschedulePlan is retained after it is executed, denied or expired.
+	// The evictions same as a denied DeschedulePlan are discarded until the plan is deleted.
+	FinishedPlanTTL metav1.Duration
 }
 
 // DeschedulerProfile is a descheduling profile.
@@ -68,6 +101,9 @@ type DeschedulerProfile struct {
 	Name         string
 	PluginConfig []PluginConfig
 	Plugins      *Plugins
+	// NodeSelector restricts the plugins of the profile to operate over a subset of
+	// the nodes selected by DeschedulerConfiguration.NodeSelector.
+	NodeSelector *metav1.LabelSelector
 }
 
 type Plugins struct {
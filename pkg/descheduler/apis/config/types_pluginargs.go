@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -32,6 +33,9 @@ type DefaultEvictorArgs struct {
 	MaxNoOfPodsToEvictPerNode *int
 	// MaxNoOfPodsToEvictPerNamespace restricts maximum of pods to be evicted per namespace.
 	MaxNoOfPodsToEvictPerNamespace *int
+	// MaxPercentageOfPodsToEvictPerOwner restricts the pods of an owner workload evicted in a descheduling cycle
+	// to the percentage of its replicas, rounded down. At least one pod of each owner can be evicted.
+	MaxPercentageOfPodsToEvictPerOwner *int32
 
 	// EvictFailedBarePods allows pods without ownerReferences and in failed phase to be evicted.
 	EvictFailedBarePods bool
@@ -56,6 +60,37 @@ type DefaultEvictorArgs struct {
 	// LabelSelector sets whether to apply label filtering when evicting.
 	// Any pod matching the label selector is considered evictable.
 	LabelSelector *metav1.LabelSelector
+	// EvictionNotifier posts a notification to an endpoint for every eviction, including the ones in dry run mode.
+	// The notifications are disabled if it is nil.
+	EvictionNotifier *EvictionNotifierArgs
+	// EvictionPacing spaces the evictions in a descheduling cycle over time, evicting the pods of the lower priority
+	// first. The evictions are performed inline if it is nil.
+	EvictionPacing *EvictionPacingArgs
+}
+
+// EvictionNotifierArgs configures the HTTP callback notifying the evictions to the external systems.
+// The notifications are delivered asynchronously, so a slow or dead endpoint never blocks the evictions.
+type EvictionNotifierArgs struct {
+	// URL is the endpoint the JSON payloads are POSTed to.
+	URL string
+	// Timeout is the timeout of a delivery attempt.
+	Timeout metav1.Duration
+	// MaxRetries is the max number of retries after a failed delivery attempt.
+	MaxRetries int32
+	// FailureThreshold is the number of consecutive failed deliveries opening the circuit breaker.
+	// The notifications are dropped while the circuit breaker is open.
+	FailureThreshold int32
+	// CircuitBreakDuration is the duration the circuit breaker keeps open before trying a delivery again.
+	CircuitBreakDuration metav1.Duration
+}
+
+// EvictionPacingArgs configures the pacing of the evictions. The evictions accepted by the budgets are queued and
+// performed by a worker one at a time, and the queue is drained or canceled at the end of the descheduling cycle.
+type EvictionPacingArgs struct {
+	// MinInterval is the min interval between two evictions.
+	MinInterval metav1.Duration
+	// JitterFactor adds a random duration of up to JitterFactor*MinInterval to each interval.
+	JitterFactor float64
 }
 
 type PriorityThreshold struct {
@@ -72,6 +107,52 @@ type RemovePodsViolatingNodeAffinityArgs struct {
 	Namespaces       *Namespaces
 	LabelSelector    *metav1.LabelSelector
 	NodeAffinityType []string
+	// ReportOnly means only count and export the pods violating node affinity as metrics without evicting them.
+	ReportOnly bool
+	// ConsolidateNodes means evicting the violating pods on the least-utilized nodes first,
+	// so that those nodes are more likely to be emptied and scaled down.
+	ConsolidateNodes bool
+	// AvoidScaleUp means evicting a violating pod only if a ready node can host it right now,
+	// so that the rescheduling never relies on the cluster autoscaler adding nodes.
+	AvoidScaleUp bool
+	// NodeCostLabel is the label of the nodes whose value is the cost of the node, e.g. the price per hour.
+	// If set, the violating pods on the more expensive nodes are evicted first to free the expensive capacity.
+	NodeCostLabel string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeMaintenanceMigrationArgs holds arguments used to configure the NodeMaintenanceMigration plugin.
+type NodeMaintenanceMigrationArgs struct {
+	metav1.TypeMeta
+
+	Namespaces    *Namespaces
+	LabelSelector *metav1.LabelSelector
+	// CycleInterval is the expected interval between two descheduling cycles.
+	// It is used to spread the migrations evenly across the cycles before the maintenance window starts.
+	CycleInterval metav1.Duration
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeConditionMigrationArgs holds arguments used to configure the NodeConditionMigration plugin.
+type NodeConditionMigrationArgs struct {
+	metav1.TypeMeta
+
+	Namespaces    *Namespaces
+	LabelSelector *metav1.LabelSelector
+	// Conditions are the node conditions of the degraded hardware, the pods are migrated off the nodes having any of them.
+	Conditions []NodeConditionSelector
+	// DebounceDuration is how long a condition must have lasted before the pods are migrated for it.
+	DebounceDuration metav1.Duration
+	// MaxMigratingPerNode is the maximum number of pods migrated from a node in a descheduling cycle.
+	MaxMigratingPerNode int32
+}
+
+// NodeConditionSelector selects the node condition of the type in the status.
+type NodeConditionSelector struct {
+	Type   corev1.NodeConditionType
+	Status corev1.ConditionStatus
 }
 
 // Namespaces carries a list of included/excluded namespaces
@@ -146,7 +227,8 @@ type MigrationControllerArgs struct {
 	EvictQPS *Float64OrString
 	// EvictBurst is the maximum number of tokens
 	EvictBurst int32
-	// EvictionPolicy represents how to delete Pod, support "Delete" and "Eviction", default value is "Eviction"
+	// EvictionPolicy represents how to delete Pod, support "Delete", "Eviction" and "Reschedule", default value is "Eviction".
+	// "Reschedule" does not evict the Pod but annotates it to request its controller to reschedule it voluntarily.
 	EvictionPolicy string
 	// DefaultDeleteOptions defines options when deleting migrated pods and preempted pods through the method specified by EvictionPolicy
 	DefaultDeleteOptions *metav1.DeleteOptions
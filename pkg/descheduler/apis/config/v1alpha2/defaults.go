@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -41,6 +42,27 @@ const (
 	defaultMigrationJobEvictionPolicy = migrationevictor.NativeEvictorName
 	defaultMigrationEvictQPS          = 10
 	defaultMigrationEvictBurst        = 1
+
+	defaultNodeMaintenanceCycleInterval = 10 * time.Minute
+
+	defaultNodeConditionDebounceDuration    = 5 * time.Minute
+	defaultNodeConditionMaxMigratingPerNode = 1
+
+	defaultEvictionApprovalThreshold = 10
+	defaultDeschedulePlanExpiration  = time.Hour
+	defaultFinishedDeschedulePlanTTL = 24 * time.Hour
+
+	defaultAdaptiveMinInterval      = time.Minute
+	defaultAdaptiveMaxInterval      = 30 * time.Minute
+	defaultAdaptiveIdleCyclesToGrow = 3
+
+	defaultEvictionNotifierTimeout              = 5 * time.Second
+	defaultEvictionNotifierMaxRetries           = 3
+	defaultEvictionNotifierFailureThreshold     = 5
+	defaultEvictionNotifierCircuitBreakDuration = time.Minute
+
+	defaultEvictionPacingMinInterval  = time.Second
+	defaultEvictionPacingJitterFactor = 0.1
 )
 
 var (
@@ -205,11 +227,57 @@ func SetDefaults_DeschedulerConfiguration(obj *DeschedulerConfiguration) {
 	}
 }
 
+func SetDefaults_EvictionApprovalConfiguration(obj *EvictionApprovalConfiguration) {
+	if obj.EvictionThreshold == nil {
+		obj.EvictionThreshold = pointer.Int32(defaultEvictionApprovalThreshold)
+	}
+	if obj.PlanExpiration == nil {
+		obj.PlanExpiration = &metav1.Duration{Duration: defaultDeschedulePlanExpiration}
+	}
+	if obj.FinishedPlanTTL == nil {
+		obj.FinishedPlanTTL = &metav1.Duration{Duration: defaultFinishedDeschedulePlanTTL}
+	}
+}
+
+func SetDefaults_AdaptiveIntervalConfiguration(obj *AdaptiveIntervalConfiguration) {
+	if obj.MinInterval == nil {
+		obj.MinInterval = &metav1.Duration{Duration: defaultAdaptiveMinInterval}
+	}
+	if obj.MaxInterval == nil {
+		obj.MaxInterval = &metav1.Duration{Duration: defaultAdaptiveMaxInterval}
+	}
+	if obj.IdleCyclesToGrow == nil {
+		obj.IdleCyclesToGrow = pointer.Int32(defaultAdaptiveIdleCyclesToGrow)
+	}
+}
+
 func SetDefaults_DefaultEvictorArgs(obj *DefaultEvictorArgs) {
 	// TODO(joseph): the current version disables the eviction ability by default
 	if obj.DryRun == nil {
 		obj.DryRun = pointer.Bool(true)
 	}
+	if obj.EvictionNotifier != nil {
+		if obj.EvictionNotifier.Timeout == nil {
+			obj.EvictionNotifier.Timeout = &metav1.Duration{Duration: defaultEvictionNotifierTimeout}
+		}
+		if obj.EvictionNotifier.MaxRetries == nil {
+			obj.EvictionNotifier.MaxRetries = pointer.Int32(defaultEvictionNotifierMaxRetries)
+		}
+		if obj.EvictionNotifier.FailureThreshold == nil {
+			obj.EvictionNotifier.FailureThreshold = pointer.Int32(defaultEvictionNotifierFailureThreshold)
+		}
+		if obj.EvictionNotifier.CircuitBreakDuration == nil {
+			obj.EvictionNotifier.CircuitBreakDuration = &metav1.Duration{Duration: defaultEvictionNotifierCircuitBreakDuration}
+		}
+	}
+	if obj.EvictionPacing != nil {
+		if obj.EvictionPacing.MinInterval == nil {
+			obj.EvictionPacing.MinInterval = &metav1.Duration{Duration: defaultEvictionPacingMinInterval}
+		}
+		if obj.EvictionPacing.JitterFactor == nil {
+			obj.EvictionPacing.JitterFactor = pointer.Float64(defaultEvictionPacingJitterFactor)
+		}
+	}
 }
 
 func SetDefaults_RemovePodsViolatingNodeAffinityArgs(obj *RemovePodsViolatingNodeAffinityArgs) {
@@ -218,6 +286,26 @@ func SetDefaults_RemovePodsViolatingNodeAffinityArgs(obj *RemovePodsViolatingNod
 	}
 }
 
+func SetDefaults_NodeMaintenanceMigrationArgs(obj *NodeMaintenanceMigrationArgs) {
+	if obj.CycleInterval == nil {
+		obj.CycleInterval = &metav1.Duration{Duration: defaultNodeMaintenanceCycleInterval}
+	}
+}
+
+func SetDefaults_NodeConditionMigrationArgs(obj *NodeConditionMigrationArgs) {
+	for i := range obj.Conditions {
+		if obj.Conditions[i].Status == "" {
+			obj.Conditions[i].Status = corev1.ConditionTrue
+		}
+	}
+	if obj.DebounceDuration == nil {
+		obj.DebounceDuration = &metav1.Duration{Duration: defaultNodeConditionDebounceDuration}
+	}
+	if obj.MaxMigratingPerNode == nil {
+		obj.MaxMigratingPerNode = pointer.Int32(defaultNodeConditionMaxMigratingPerNode)
+	}
+}
+
 func SetDefaults_MigrationControllerArgs(obj *MigrationControllerArgs) {
 	if obj.MaxConcurrentReconciles == nil {
 		obj.MaxConcurrentReconciles = pointer.Int32(defaultMigrationControllerMaxConcurrentReconciles)
@@ -62,6 +62,39 @@ type DeschedulerConfiguration struct {
 
 	// NodeSelector for a set of nodes to operate over
 	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// EvictionApproval requires the operators to approve the descheduling cycles planning too many evictions.
+	// The approval workflow is disabled if it is nil.
+	EvictionApproval *EvictionApprovalConfiguration `json:"evictionApproval,omitempty"`
+
+	// AdaptiveInterval adapts the interval between the descheduling cycles to the cluster churn.
+	// The DeschedulingInterval is used statically if it is nil, and the descheduler runs only once if the
+	// DeschedulingInterval is 0 even if it is set.
+	AdaptiveInterval *AdaptiveIntervalConfiguration `json:"adaptiveInterval,omitempty"`
+}
+
+// AdaptiveIntervalConfiguration configures the adaptive interval of the descheduling cycles. Starting from the
+// DeschedulingInterval, the interval is halved down to the MinInterval after a cycle performing evictions or
+// changing the node classifications, and doubled up to the MaxInterval after IdleCyclesToGrow consecutive no-op cycles.
+type AdaptiveIntervalConfiguration struct {
+	// MinInterval is the floor of the interval.
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+	// MaxInterval is the ceiling of the interval.
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+	// IdleCyclesToGrow is the number of consecutive no-op cycles before the interval grows.
+	IdleCyclesToGrow *int32 `json:"idleCyclesToGrow,omitempty"`
+}
+
+// EvictionApprovalConfiguration configures the approval workflow of the descheduling cycles.
+type EvictionApprovalConfiguration struct {
+	// EvictionThreshold is the max number of evictions a descheduling cycle executes without the approval.
+	// A cycle planning more evictions writes a DeschedulePlan and waits for the operators to approve it.
+	EvictionThreshold *int32 `json:"evictionThreshold,omitempty"`
+	// PlanExpiration is the duration a DeschedulePlan waits for the approval before it is discarded.
+	PlanExpiration *metav1.Duration `json:"planExpiration,omitempty"`
+	// FinishedPlanTTL is the duration a DeschedulePlan is retained after it is executed, denied or expired.
+	// The evictions same as a denied DeschedulePlan are discarded until the plan is deleted.
+	FinishedPlanTTL *metav1.Duration `json:"finishedPlanTTL,omitempty"`
 }
 
 // DecodeNestedObjects decodes plugin args for known types.
@@ -97,6 +130,9 @@ type DeschedulerProfile struct {
 	Name         string         `json:"name,omitempty"`
 	PluginConfig []PluginConfig `json:"pluginConfig,omitempty"`
 	Plugins      *Plugins       `json:"plugins,omitempty"`
+	// NodeSelector restricts the plugins of the profile to operate over a subset of
+	// the nodes selected by DeschedulerConfiguration.NodeSelector.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
 }
 
 type Plugins struct {
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha2
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -34,6 +35,9 @@ type DefaultEvictorArgs struct {
 	MaxNoOfPodsToEvictPerNode *int `json:"maxNoOfPodsToEvictPerNode,omitempty"`
 	// MaxNoOfPodsToEvictPerNamespace restricts maximum of pods to be evicted per namespace.
 	MaxNoOfPodsToEvictPerNamespace *int `json:"maxNoOfPodsToEvictPerNamespace,omitempty"`
+	// MaxPercentageOfPodsToEvictPerOwner restricts the pods of an owner workload evicted in a descheduling cycle
+	// to the percentage of its replicas, rounded down. At least one pod of each owner can be evicted.
+	MaxPercentageOfPodsToEvictPerOwner *int32 `json:"maxPercentageOfPodsToEvictPerOwner,omitempty"`
 
 	// EvictFailedBarePods allows pods without ownerReferences and in failed phase to be evicted.
 	EvictFailedBarePods bool `json:"evictFailedBarePods"`
@@ -58,6 +62,37 @@ type DefaultEvictorArgs struct {
 	// LabelSelector sets whether to apply label filtering when evicting.
 	// Any pod matching the label selector is considered evictable.
 	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// EvictionNotifier posts a notification to an endpoint for every eviction, including the ones in dry run mode.
+	// The notifications are disabled if it is nil.
+	EvictionNotifier *EvictionNotifierArgs `json:"evictionNotifier,omitempty"`
+	// EvictionPacing spaces the evictions in a descheduling cycle over time, evicting the pods of the lower priority
+	// first. The evictions are performed inline if it is nil.
+	EvictionPacing *EvictionPacingArgs `json:"evictionPacing,omitempty"`
+}
+
+// EvictionNotifierArgs configures the HTTP callback notifying the evictions to the external systems.
+// The notifications are delivered asynchronously, so a slow or dead endpoint never blocks the evictions.
+type EvictionNotifierArgs struct {
+	// URL is the endpoint the JSON payloads are POSTed to.
+	URL string `json:"url"`
+	// Timeout is the timeout of a delivery attempt.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// MaxRetries is the max number of retries after a failed delivery attempt.
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+	// FailureThreshold is the number of consecutive failed deliveries opening the circuit breaker.
+	// The notifications are dropped while the circuit breaker is open.
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// CircuitBreakDuration is the duration the circuit breaker keeps open before trying a delivery again.
+	CircuitBreakDuration *metav1.Duration `json:"circuitBreakDuration,omitempty"`
+}
+
+// EvictionPacingArgs configures the pacing of the evictions. The evictions accepted by the budgets are queued and
+// performed by a worker one at a time, and the queue is drained or canceled at the end of the descheduling cycle.
+type EvictionPacingArgs struct {
+	// MinInterval is the min interval between two evictions.
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+	// JitterFactor adds a random duration of up to JitterFactor*MinInterval to each interval.
+	JitterFactor *float64 `json:"jitterFactor,omitempty"`
 }
 
 type PriorityThreshold struct {
@@ -74,6 +109,69 @@ type RemovePodsViolatingNodeAffinityArgs struct {
 	Namespaces       *Namespaces           `json:"namespaces,omitempty"`
 	LabelSelector    *metav1.LabelSelector `json:"labelSelector,omitempty"`
 	NodeAffinityType []string              `json:"nodeAffinityType,omitempty"`
+	// ReportOnly means only count and export the pods violating node affinity as metrics without evicting them.
+	// It is different from DryRun that the plugin only measures the violations and never calls the Evictor.
+	// Default is false
+	ReportOnly bool `json:"reportOnly,omitempty"`
+	// ConsolidateNodes means evicting the violating pods on the least-utilized nodes first,
+	// so that those nodes are more likely to be emptied and scaled down.
+	// The utilization of a node is measured by the requested resources against the allocatable.
+	// Default is false
+	ConsolidateNodes bool `json:"consolidateNodes,omitempty"`
+	// AvoidScaleUp means evicting a violating pod only if a ready node can host it right now,
+	// so that the rescheduling never relies on the cluster autoscaler adding nodes.
+	// The capacity of a node taken by the pods evicted earlier in the same cycle is excluded.
+	// Default is false
+	AvoidScaleUp bool `json:"avoidScaleUp,omitempty"`
+	// NodeCostLabel is the label of the nodes whose value is the cost of the node, e.g. the price per hour.
+	// If set, the violating pods on the more expensive nodes are evicted first to free the expensive capacity,
+	// and with AvoidScaleUp the cheapest node which can host a pod is expected to take it.
+	// The nodes without a valid cost are considered the cheapest.
+	// Not enabled by default
+	NodeCostLabel string `json:"nodeCostLabel,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeMaintenanceMigrationArgs holds arguments used to configure the NodeMaintenanceMigration plugin.
+type NodeMaintenanceMigrationArgs struct {
+	metav1.TypeMeta
+
+	Namespaces    *Namespaces           `json:"namespaces,omitempty"`
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// CycleInterval is the expected interval between two descheduling cycles, which should be consistent with
+	// the DeschedulingInterval. It is used to spread the migrations evenly across the cycles before the
+	// maintenance window starts.
+	// Default is 10 minutes
+	CycleInterval *metav1.Duration `json:"cycleInterval,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeConditionMigrationArgs holds arguments used to configure the NodeConditionMigration plugin.
+type NodeConditionMigrationArgs struct {
+	metav1.TypeMeta
+
+	Namespaces    *Namespaces           `json:"namespaces,omitempty"`
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// Conditions are the node conditions of the degraded hardware surfaced by e.g. node-problem-detector,
+	// the pods are migrated off the nodes having any of them until the conditions clear.
+	Conditions []NodeConditionSelector `json:"conditions,omitempty"`
+	// DebounceDuration is how long a condition must have lasted since its last transition before the pods are
+	// migrated for it, so that the flapping conditions are ignored.
+	// Default is 5 minutes
+	DebounceDuration *metav1.Duration `json:"debounceDuration,omitempty"`
+	// MaxMigratingPerNode is the maximum number of pods migrated from a node in a descheduling cycle.
+	// Default is 1
+	MaxMigratingPerNode *int32 `json:"maxMigratingPerNode,omitempty"`
+}
+
+// NodeConditionSelector selects the node condition of the type in the status.
+type NodeConditionSelector struct {
+	Type corev1.NodeConditionType `json:"type"`
+	// Status is the status of the condition selected.
+	// Default is True
+	Status corev1.ConditionStatus `json:"status,omitempty"`
 }
 
 // Namespaces carries a list of included/excluded namespaces
@@ -148,7 +246,8 @@ type MigrationControllerArgs struct {
 	EvictQPS *config.Float64OrString `json:"evictQPS,omitempty"`
 	// EvictBurst is the maximum number of tokens
 	EvictBurst *int32 `json:"evictBurst,omitempty"`
-	// EvictionPolicy represents how to delete Pod, support "Delete" and "Eviction", default value is "Eviction"
+	// EvictionPolicy represents how to delete Pod, support "Delete", "Eviction" and "Reschedule", default value is "Eviction".
+	// "Reschedule" does not evict the Pod but annotates it to request its controller to reschedule it voluntarily.
 	EvictionPolicy string `json:"evictionPolicy,omitempty"`
 	// DefaultDeleteOptions defines options when deleting migrated pods and preempted pods through the method specified by EvictionPolicy
 	DefaultDeleteOptions *metav1.DeleteOptions `json:"defaultDeleteOptions,omitempty"`
@@ -25,6 +25,7 @@ import (
 	unsafe "unsafe"
 
 	config "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -39,6 +40,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*AdaptiveIntervalConfiguration)(nil), (*config.AdaptiveIntervalConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_AdaptiveIntervalConfiguration_To_config_AdaptiveIntervalConfiguration(a.(*AdaptiveIntervalConfiguration), b.(*config.AdaptiveIntervalConfiguration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.AdaptiveIntervalConfiguration)(nil), (*AdaptiveIntervalConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_AdaptiveIntervalConfiguration_To_v1alpha2_AdaptiveIntervalConfiguration(a.(*config.AdaptiveIntervalConfiguration), b.(*AdaptiveIntervalConfiguration), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DefaultEvictorArgs)(nil), (*config.DefaultEvictorArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_DefaultEvictorArgs_To_config_DefaultEvictorArgs(a.(*DefaultEvictorArgs), b.(*config.DefaultEvictorArgs), scope)
 	}); err != nil {
@@ -59,6 +70,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*EvictionApprovalConfiguration)(nil), (*config.EvictionApprovalConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_EvictionApprovalConfiguration_To_config_EvictionApprovalConfiguration(a.(*EvictionApprovalConfiguration), b.(*config.EvictionApprovalConfiguration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.EvictionApprovalConfiguration)(nil), (*EvictionApprovalConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_EvictionApprovalConfiguration_To_v1alpha2_EvictionApprovalConfiguration(a.(*config.EvictionApprovalConfiguration), b.(*EvictionApprovalConfiguration), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*EvictionNotifierArgs)(nil), (*config.EvictionNotifierArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_EvictionNotifierArgs_To_config_EvictionNotifierArgs(a.(*EvictionNotifierArgs), b.(*config.EvictionNotifierArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.EvictionNotifierArgs)(nil), (*EvictionNotifierArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_EvictionNotifierArgs_To_v1alpha2_EvictionNotifierArgs(a.(*config.EvictionNotifierArgs), b.(*EvictionNotifierArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*EvictionPacingArgs)(nil), (*config.EvictionPacingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_EvictionPacingArgs_To_config_EvictionPacingArgs(a.(*EvictionPacingArgs), b.(*config.EvictionPacingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.EvictionPacingArgs)(nil), (*EvictionPacingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_EvictionPacingArgs_To_v1alpha2_EvictionPacingArgs(a.(*config.EvictionPacingArgs), b.(*EvictionPacingArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*LoadAnomalyCondition)(nil), (*config.LoadAnomalyCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(a.(*LoadAnomalyCondition), b.(*config.LoadAnomalyCondition), scope)
 	}); err != nil {
@@ -119,6 +160,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*NodeConditionMigrationArgs)(nil), (*config.NodeConditionMigrationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NodeConditionMigrationArgs_To_config_NodeConditionMigrationArgs(a.(*NodeConditionMigrationArgs), b.(*config.NodeConditionMigrationArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.NodeConditionMigrationArgs)(nil), (*NodeConditionMigrationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_NodeConditionMigrationArgs_To_v1alpha2_NodeConditionMigrationArgs(a.(*config.NodeConditionMigrationArgs), b.(*NodeConditionMigrationArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*NodeConditionSelector)(nil), (*config.NodeConditionSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NodeConditionSelector_To_config_NodeConditionSelector(a.(*NodeConditionSelector), b.(*config.NodeConditionSelector), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.NodeConditionSelector)(nil), (*NodeConditionSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_NodeConditionSelector_To_v1alpha2_NodeConditionSelector(a.(*config.NodeConditionSelector), b.(*NodeConditionSelector), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*NodeMaintenanceMigrationArgs)(nil), (*config.NodeMaintenanceMigrationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NodeMaintenanceMigrationArgs_To_config_NodeMaintenanceMigrationArgs(a.(*NodeMaintenanceMigrationArgs), b.(*config.NodeMaintenanceMigrationArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.NodeMaintenanceMigrationArgs)(nil), (*NodeMaintenanceMigrationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_NodeMaintenanceMigrationArgs_To_v1alpha2_NodeMaintenanceMigrationArgs(a.(*config.NodeMaintenanceMigrationArgs), b.(*NodeMaintenanceMigrationArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Plugin)(nil), (*config.Plugin)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Plugin_To_config_Plugin(a.(*Plugin), b.(*config.Plugin), scope)
 	}); err != nil {
@@ -192,12 +263,49 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha2_AdaptiveIntervalConfiguration_To_config_AdaptiveIntervalConfiguration(in *AdaptiveIntervalConfiguration, out *config.AdaptiveIntervalConfiguration, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.MinInterval, &out.MinInterval, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.MaxInterval, &out.MaxInterval, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.IdleCyclesToGrow, &out.IdleCyclesToGrow, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_AdaptiveIntervalConfiguration_To_config_AdaptiveIntervalConfiguration is an autogenerated conversion function.
+func Convert_v1alpha2_AdaptiveIntervalConfiguration_To_config_AdaptiveIntervalConfiguration(in *AdaptiveIntervalConfiguration, out *config.AdaptiveIntervalConfiguration, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AdaptiveIntervalConfiguration_To_config_AdaptiveIntervalConfiguration(in, out, s)
+}
+
+func autoConvert_config_AdaptiveIntervalConfiguration_To_v1alpha2_AdaptiveIntervalConfiguration(in *config.AdaptiveIntervalConfiguration, out *AdaptiveIntervalConfiguration, s conversion.Scope) error {
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.MinInterval, &out.MinInterval, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.MaxInterval, &out.MaxInterval, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.IdleCyclesToGrow, &out.IdleCyclesToGrow, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_AdaptiveIntervalConfiguration_To_v1alpha2_AdaptiveIntervalConfiguration is an autogenerated conversion function.
+func Convert_config_AdaptiveIntervalConfiguration_To_v1alpha2_AdaptiveIntervalConfiguration(in *config.AdaptiveIntervalConfiguration, out *AdaptiveIntervalConfiguration, s conversion.Scope) error {
+	return autoConvert_config_AdaptiveIntervalConfiguration_To_v1alpha2_AdaptiveIntervalConfiguration(in, out, s)
+}
+
 func autoConvert_v1alpha2_DefaultEvictorArgs_To_config_DefaultEvictorArgs(in *DefaultEvictorArgs, out *config.DefaultEvictorArgs, s conversion.Scope) error {
 	if err := v1.Convert_Pointer_bool_To_bool(&in.DryRun, &out.DryRun, s); err != nil {
 		return err
 	}
 	out.MaxNoOfPodsToEvictPerNode = (*int)(unsafe.Pointer(in.MaxNoOfPodsToEvictPerNode))
 	out.MaxNoOfPodsToEvictPerNamespace = (*int)(unsafe.Pointer(in.MaxNoOfPodsToEvictPerNamespace))
+	out.MaxPercentageOfPodsToEvictPerOwner = (*int32)(unsafe.Pointer(in.MaxPercentageOfPodsToEvictPerOwner))
 	out.EvictFailedBarePods = in.EvictFailedBarePods
 	out.EvictLocalStoragePods = in.EvictLocalStoragePods
 	out.EvictSystemCriticalPods = in.EvictSystemCriticalPods
@@ -205,6 +313,24 @@ func autoConvert_v1alpha2_DefaultEvictorArgs_To_config_DefaultEvictorArgs(in *De
 	out.NodeFit = in.NodeFit
 	out.PriorityThreshold = (*config.PriorityThreshold)(unsafe.Pointer(in.PriorityThreshold))
 	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	if in.EvictionNotifier != nil {
+		in, out := &in.EvictionNotifier, &out.EvictionNotifier
+		*out = new(config.EvictionNotifierArgs)
+		if err := Convert_v1alpha2_EvictionNotifierArgs_To_config_EvictionNotifierArgs(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EvictionNotifier = nil
+	}
+	if in.EvictionPacing != nil {
+		in, out := &in.EvictionPacing, &out.EvictionPacing
+		*out = new(config.EvictionPacingArgs)
+		if err := Convert_v1alpha2_EvictionPacingArgs_To_config_EvictionPacingArgs(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EvictionPacing = nil
+	}
 	return nil
 }
 
@@ -219,6 +345,7 @@ func autoConvert_config_DefaultEvictorArgs_To_v1alpha2_DefaultEvictorArgs(in *co
 	}
 	out.MaxNoOfPodsToEvictPerNode = (*int)(unsafe.Pointer(in.MaxNoOfPodsToEvictPerNode))
 	out.MaxNoOfPodsToEvictPerNamespace = (*int)(unsafe.Pointer(in.MaxNoOfPodsToEvictPerNamespace))
+	out.MaxPercentageOfPodsToEvictPerOwner = (*int32)(unsafe.Pointer(in.MaxPercentageOfPodsToEvictPerOwner))
 	out.EvictFailedBarePods = in.EvictFailedBarePods
 	out.EvictLocalStoragePods = in.EvictLocalStoragePods
 	out.EvictSystemCriticalPods = in.EvictSystemCriticalPods
@@ -226,6 +353,24 @@ func autoConvert_config_DefaultEvictorArgs_To_v1alpha2_DefaultEvictorArgs(in *co
 	out.NodeFit = in.NodeFit
 	out.PriorityThreshold = (*PriorityThreshold)(unsafe.Pointer(in.PriorityThreshold))
 	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	if in.EvictionNotifier != nil {
+		in, out := &in.EvictionNotifier, &out.EvictionNotifier
+		*out = new(EvictionNotifierArgs)
+		if err := Convert_config_EvictionNotifierArgs_To_v1alpha2_EvictionNotifierArgs(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EvictionNotifier = nil
+	}
+	if in.EvictionPacing != nil {
+		in, out := &in.EvictionPacing, &out.EvictionPacing
+		*out = new(EvictionPacingArgs)
+		if err := Convert_config_EvictionPacingArgs_To_v1alpha2_EvictionPacingArgs(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EvictionPacing = nil
+	}
 	return nil
 }
 
@@ -264,6 +409,24 @@ func autoConvert_v1alpha2_DeschedulerConfiguration_To_config_DeschedulerConfigur
 		out.Profiles = nil
 	}
 	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	if in.EvictionApproval != nil {
+		in, out := &in.EvictionApproval, &out.EvictionApproval
+		*out = new(config.EvictionApprovalConfiguration)
+		if err := Convert_v1alpha2_EvictionApprovalConfiguration_To_config_EvictionApprovalConfiguration(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EvictionApproval = nil
+	}
+	if in.AdaptiveInterval != nil {
+		in, out := &in.AdaptiveInterval, &out.AdaptiveInterval
+		*out = new(config.AdaptiveIntervalConfiguration)
+		if err := Convert_v1alpha2_AdaptiveIntervalConfiguration_To_config_AdaptiveIntervalConfiguration(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AdaptiveInterval = nil
+	}
 	return nil
 }
 
@@ -297,6 +460,24 @@ func autoConvert_config_DeschedulerConfiguration_To_v1alpha2_DeschedulerConfigur
 		out.Profiles = nil
 	}
 	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	if in.EvictionApproval != nil {
+		in, out := &in.EvictionApproval, &out.EvictionApproval
+		*out = new(EvictionApprovalConfiguration)
+		if err := Convert_config_EvictionApprovalConfiguration_To_v1alpha2_EvictionApprovalConfiguration(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.EvictionApproval = nil
+	}
+	if in.AdaptiveInterval != nil {
+		in, out := &in.AdaptiveInterval, &out.AdaptiveInterval
+		*out = new(AdaptiveIntervalConfiguration)
+		if err := Convert_config_AdaptiveIntervalConfiguration_To_v1alpha2_AdaptiveIntervalConfiguration(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AdaptiveInterval = nil
+	}
 	return nil
 }
 
@@ -314,6 +495,7 @@ func autoConvert_v1alpha2_DeschedulerProfile_To_config_DeschedulerProfile(in *De
 		out.PluginConfig = nil
 	}
 	out.Plugins = (*config.Plugins)(unsafe.Pointer(in.Plugins))
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
 	return nil
 }
 
@@ -336,6 +518,7 @@ func autoConvert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in *co
 		out.PluginConfig = nil
 	}
 	out.Plugins = (*Plugins)(unsafe.Pointer(in.Plugins))
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
 	return nil
 }
 
@@ -344,6 +527,116 @@ func Convert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in *config
 	return autoConvert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in, out, s)
 }
 
+func autoConvert_v1alpha2_EvictionApprovalConfiguration_To_config_EvictionApprovalConfiguration(in *EvictionApprovalConfiguration, out *config.EvictionApprovalConfiguration, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_int32_To_int32(&in.EvictionThreshold, &out.EvictionThreshold, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.PlanExpiration, &out.PlanExpiration, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.FinishedPlanTTL, &out.FinishedPlanTTL, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_EvictionApprovalConfiguration_To_config_EvictionApprovalConfiguration is an autogenerated conversion function.
+func Convert_v1alpha2_EvictionApprovalConfiguration_To_config_EvictionApprovalConfiguration(in *EvictionApprovalConfiguration, out *config.EvictionApprovalConfiguration, s conversion.Scope) error {
+	return autoConvert_v1alpha2_EvictionApprovalConfiguration_To_config_EvictionApprovalConfiguration(in, out, s)
+}
+
+func autoConvert_config_EvictionApprovalConfiguration_To_v1alpha2_EvictionApprovalConfiguration(in *config.EvictionApprovalConfiguration, out *EvictionApprovalConfiguration, s conversion.Scope) error {
+	if err := v1.Convert_int32_To_Pointer_int32(&in.EvictionThreshold, &out.EvictionThreshold, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.PlanExpiration, &out.PlanExpiration, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.FinishedPlanTTL, &out.FinishedPlanTTL, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_EvictionApprovalConfiguration_To_v1alpha2_EvictionApprovalConfiguration is an autogenerated conversion function.
+func Convert_config_EvictionApprovalConfiguration_To_v1alpha2_EvictionApprovalConfiguration(in *config.EvictionApprovalConfiguration, out *EvictionApprovalConfiguration, s conversion.Scope) error {
+	return autoConvert_config_EvictionApprovalConfiguration_To_v1alpha2_EvictionApprovalConfiguration(in, out, s)
+}
+
+func autoConvert_v1alpha2_EvictionNotifierArgs_To_config_EvictionNotifierArgs(in *EvictionNotifierArgs, out *config.EvictionNotifierArgs, s conversion.Scope) error {
+	out.URL = in.URL
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.Timeout, &out.Timeout, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxRetries, &out.MaxRetries, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.FailureThreshold, &out.FailureThreshold, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.CircuitBreakDuration, &out.CircuitBreakDuration, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_EvictionNotifierArgs_To_config_EvictionNotifierArgs is an autogenerated conversion function.
+func Convert_v1alpha2_EvictionNotifierArgs_To_config_EvictionNotifierArgs(in *EvictionNotifierArgs, out *config.EvictionNotifierArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_EvictionNotifierArgs_To_config_EvictionNotifierArgs(in, out, s)
+}
+
+func autoConvert_config_EvictionNotifierArgs_To_v1alpha2_EvictionNotifierArgs(in *config.EvictionNotifierArgs, out *EvictionNotifierArgs, s conversion.Scope) error {
+	out.URL = in.URL
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.Timeout, &out.Timeout, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxRetries, &out.MaxRetries, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.FailureThreshold, &out.FailureThreshold, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.CircuitBreakDuration, &out.CircuitBreakDuration, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_EvictionNotifierArgs_To_v1alpha2_EvictionNotifierArgs is an autogenerated conversion function.
+func Convert_config_EvictionNotifierArgs_To_v1alpha2_EvictionNotifierArgs(in *config.EvictionNotifierArgs, out *EvictionNotifierArgs, s conversion.Scope) error {
+	return autoConvert_config_EvictionNotifierArgs_To_v1alpha2_EvictionNotifierArgs(in, out, s)
+}
+
+func autoConvert_v1alpha2_EvictionPacingArgs_To_config_EvictionPacingArgs(in *EvictionPacingArgs, out *config.EvictionPacingArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.MinInterval, &out.MinInterval, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_float64_To_float64(&in.JitterFactor, &out.JitterFactor, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_EvictionPacingArgs_To_config_EvictionPacingArgs is an autogenerated conversion function.
+func Convert_v1alpha2_EvictionPacingArgs_To_config_EvictionPacingArgs(in *EvictionPacingArgs, out *config.EvictionPacingArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_EvictionPacingArgs_To_config_EvictionPacingArgs(in, out, s)
+}
+
+func autoConvert_config_EvictionPacingArgs_To_v1alpha2_EvictionPacingArgs(in *config.EvictionPacingArgs, out *EvictionPacingArgs, s conversion.Scope) error {
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.MinInterval, &out.MinInterval, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_float64_To_Pointer_float64(&in.JitterFactor, &out.JitterFactor, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_EvictionPacingArgs_To_v1alpha2_EvictionPacingArgs is an autogenerated conversion function.
+func Convert_config_EvictionPacingArgs_To_v1alpha2_EvictionPacingArgs(in *config.EvictionPacingArgs, out *EvictionPacingArgs, s conversion.Scope) error {
+	return autoConvert_config_EvictionPacingArgs_To_v1alpha2_EvictionPacingArgs(in, out, s)
+}
+
 func autoConvert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(in *LoadAnomalyCondition, out *config.LoadAnomalyCondition, s conversion.Scope) error {
 	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.Timeout, &out.Timeout, s); err != nil {
 		return err
@@ -580,6 +873,92 @@ func Convert_config_Namespaces_To_v1alpha2_Namespaces(in *config.Namespaces, out
 	return autoConvert_config_Namespaces_To_v1alpha2_Namespaces(in, out, s)
 }
 
+func autoConvert_v1alpha2_NodeConditionMigrationArgs_To_config_NodeConditionMigrationArgs(in *NodeConditionMigrationArgs, out *config.NodeConditionMigrationArgs, s conversion.Scope) error {
+	out.Namespaces = (*config.Namespaces)(unsafe.Pointer(in.Namespaces))
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	out.Conditions = *(*[]config.NodeConditionSelector)(unsafe.Pointer(&in.Conditions))
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.DebounceDuration, &out.DebounceDuration, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxMigratingPerNode, &out.MaxMigratingPerNode, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_NodeConditionMigrationArgs_To_config_NodeConditionMigrationArgs is an autogenerated conversion function.
+func Convert_v1alpha2_NodeConditionMigrationArgs_To_config_NodeConditionMigrationArgs(in *NodeConditionMigrationArgs, out *config.NodeConditionMigrationArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeConditionMigrationArgs_To_config_NodeConditionMigrationArgs(in, out, s)
+}
+
+func autoConvert_config_NodeConditionMigrationArgs_To_v1alpha2_NodeConditionMigrationArgs(in *config.NodeConditionMigrationArgs, out *NodeConditionMigrationArgs, s conversion.Scope) error {
+	out.Namespaces = (*Namespaces)(unsafe.Pointer(in.Namespaces))
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	out.Conditions = *(*[]NodeConditionSelector)(unsafe.Pointer(&in.Conditions))
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.DebounceDuration, &out.DebounceDuration, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxMigratingPerNode, &out.MaxMigratingPerNode, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_NodeConditionMigrationArgs_To_v1alpha2_NodeConditionMigrationArgs is an autogenerated conversion function.
+func Convert_config_NodeConditionMigrationArgs_To_v1alpha2_NodeConditionMigrationArgs(in *config.NodeConditionMigrationArgs, out *NodeConditionMigrationArgs, s conversion.Scope) error {
+	return autoConvert_config_NodeConditionMigrationArgs_To_v1alpha2_NodeConditionMigrationArgs(in, out, s)
+}
+
+func autoConvert_v1alpha2_NodeConditionSelector_To_config_NodeConditionSelector(in *NodeConditionSelector, out *config.NodeConditionSelector, s conversion.Scope) error {
+	out.Type = corev1.NodeConditionType(in.Type)
+	out.Status = corev1.ConditionStatus(in.Status)
+	return nil
+}
+
+// Convert_v1alpha2_NodeConditionSelector_To_config_NodeConditionSelector is an autogenerated conversion function.
+func Convert_v1alpha2_NodeConditionSelector_To_config_NodeConditionSelector(in *NodeConditionSelector, out *config.NodeConditionSelector, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeConditionSelector_To_config_NodeConditionSelector(in, out, s)
+}
+
+func autoConvert_config_NodeConditionSelector_To_v1alpha2_NodeConditionSelector(in *config.NodeConditionSelector, out *NodeConditionSelector, s conversion.Scope) error {
+	out.Type = corev1.NodeConditionType(in.Type)
+	out.Status = corev1.ConditionStatus(in.Status)
+	return nil
+}
+
+// Convert_config_NodeConditionSelector_To_v1alpha2_NodeConditionSelector is an autogenerated conversion function.
+func Convert_config_NodeConditionSelector_To_v1alpha2_NodeConditionSelector(in *config.NodeConditionSelector, out *NodeConditionSelector, s conversion.Scope) error {
+	return autoConvert_config_NodeConditionSelector_To_v1alpha2_NodeConditionSelector(in, out, s)
+}
+
+func autoConvert_v1alpha2_NodeMaintenanceMigrationArgs_To_config_NodeMaintenanceMigrationArgs(in *NodeMaintenanceMigrationArgs, out *config.NodeMaintenanceMigrationArgs, s conversion.Scope) error {
+	out.Namespaces = (*config.Namespaces)(unsafe.Pointer(in.Namespaces))
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.CycleInterval, &out.CycleInterval, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_NodeMaintenanceMigrationArgs_To_config_NodeMaintenanceMigrationArgs is an autogenerated conversion function.
+func Convert_v1alpha2_NodeMaintenanceMigrationArgs_To_config_NodeMaintenanceMigrationArgs(in *NodeMaintenanceMigrationArgs, out *config.NodeMaintenanceMigrationArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeMaintenanceMigrationArgs_To_config_NodeMaintenanceMigrationArgs(in, out, s)
+}
+
+func autoConvert_config_NodeMaintenanceMigrationArgs_To_v1alpha2_NodeMaintenanceMigrationArgs(in *config.NodeMaintenanceMigrationArgs, out *NodeMaintenanceMigrationArgs, s conversion.Scope) error {
+	out.Namespaces = (*Namespaces)(unsafe.Pointer(in.Namespaces))
+	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.CycleInterval, &out.CycleInterval, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_NodeMaintenanceMigrationArgs_To_v1alpha2_NodeMaintenanceMigrationArgs is an autogenerated conversion function.
+func Convert_config_NodeMaintenanceMigrationArgs_To_v1alpha2_NodeMaintenanceMigrationArgs(in *config.NodeMaintenanceMigrationArgs, out *NodeMaintenanceMigrationArgs, s conversion.Scope) error {
+	return autoConvert_config_NodeMaintenanceMigrationArgs_To_v1alpha2_NodeMaintenanceMigrationArgs(in, out, s)
+}
+
 func autoConvert_v1alpha2_Plugin_To_config_Plugin(in *Plugin, out *config.Plugin, s conversion.Scope) error {
 	out.Name = in.Name
 	return nil
@@ -710,6 +1089,10 @@ func autoConvert_v1alpha2_RemovePodsViolatingNodeAffinityArgs_To_config_RemovePo
 	out.Namespaces = (*config.Namespaces)(unsafe.Pointer(in.Namespaces))
 	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
 	out.NodeAffinityType = *(*[]string)(unsafe.Pointer(&in.NodeAffinityType))
+	out.ReportOnly = in.ReportOnly
+	out.ConsolidateNodes = in.ConsolidateNodes
+	out.AvoidScaleUp = in.AvoidScaleUp
+	out.NodeCostLabel = in.NodeCostLabel
 	return nil
 }
 
@@ -722,6 +1105,10 @@ func autoConvert_config_RemovePodsViolatingNodeAffinityArgs_To_v1alpha2_RemovePo
 	out.Namespaces = (*Namespaces)(unsafe.Pointer(in.Namespaces))
 	out.LabelSelector = (*v1.LabelSelector)(unsafe.Pointer(in.LabelSelector))
 	out.NodeAffinityType = *(*[]string)(unsafe.Pointer(&in.NodeAffinityType))
+	out.ReportOnly = in.ReportOnly
+	out.ConsolidateNodes = in.ConsolidateNodes
+	out.AvoidScaleUp = in.AvoidScaleUp
+	out.NodeCostLabel = in.NodeCostLabel
 	return nil
 }
 
@@ -28,6 +28,37 @@ import (
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveIntervalConfiguration) DeepCopyInto(out *AdaptiveIntervalConfiguration) {
+	*out = *in
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxInterval != nil {
+		in, out := &in.MaxInterval, &out.MaxInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.IdleCyclesToGrow != nil {
+		in, out := &in.IdleCyclesToGrow, &out.IdleCyclesToGrow
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveIntervalConfiguration.
+func (in *AdaptiveIntervalConfiguration) DeepCopy() *AdaptiveIntervalConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveIntervalConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultEvictorArgs) DeepCopyInto(out *DefaultEvictorArgs) {
 	*out = *in
@@ -47,6 +78,11 @@ func (in *DefaultEvictorArgs) DeepCopyInto(out *DefaultEvictorArgs) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.MaxPercentageOfPodsToEvictPerOwner != nil {
+		in, out := &in.MaxPercentageOfPodsToEvictPerOwner, &out.MaxPercentageOfPodsToEvictPerOwner
+		*out = new(int32)
+		**out = **in
+	}
 	if in.PriorityThreshold != nil {
 		in, out := &in.PriorityThreshold, &out.PriorityThreshold
 		*out = new(PriorityThreshold)
@@ -57,6 +93,16 @@ func (in *DefaultEvictorArgs) DeepCopyInto(out *DefaultEvictorArgs) {
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EvictionNotifier != nil {
+		in, out := &in.EvictionNotifier, &out.EvictionNotifier
+		*out = new(EvictionNotifierArgs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictionPacing != nil {
+		in, out := &in.EvictionPacing, &out.EvictionPacing
+		*out = new(EvictionPacingArgs)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -108,6 +154,16 @@ func (in *DeschedulerConfiguration) DeepCopyInto(out *DeschedulerConfiguration)
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EvictionApproval != nil {
+		in, out := &in.EvictionApproval, &out.EvictionApproval
+		*out = new(EvictionApprovalConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdaptiveInterval != nil {
+		in, out := &in.AdaptiveInterval, &out.AdaptiveInterval
+		*out = new(AdaptiveIntervalConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -144,6 +200,11 @@ func (in *DeschedulerProfile) DeepCopyInto(out *DeschedulerProfile) {
 		*out = new(Plugins)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -157,6 +218,99 @@ func (in *DeschedulerProfile) DeepCopy() *DeschedulerProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionApprovalConfiguration) DeepCopyInto(out *EvictionApprovalConfiguration) {
+	*out = *in
+	if in.EvictionThreshold != nil {
+		in, out := &in.EvictionThreshold, &out.EvictionThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PlanExpiration != nil {
+		in, out := &in.PlanExpiration, &out.PlanExpiration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FinishedPlanTTL != nil {
+		in, out := &in.FinishedPlanTTL, &out.FinishedPlanTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionApprovalConfiguration.
+func (in *EvictionApprovalConfiguration) DeepCopy() *EvictionApprovalConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionApprovalConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionNotifierArgs) DeepCopyInto(out *EvictionNotifierArgs) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CircuitBreakDuration != nil {
+		in, out := &in.CircuitBreakDuration, &out.CircuitBreakDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionNotifierArgs.
+func (in *EvictionNotifierArgs) DeepCopy() *EvictionNotifierArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionNotifierArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionPacingArgs) DeepCopyInto(out *EvictionPacingArgs) {
+	*out = *in
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.JitterFactor != nil {
+		in, out := &in.JitterFactor, &out.JitterFactor
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionPacingArgs.
+func (in *EvictionPacingArgs) DeepCopy() *EvictionPacingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionPacingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadAnomalyCondition) DeepCopyInto(out *LoadAnomalyCondition) {
 	*out = *in
@@ -420,6 +574,112 @@ func (in *Namespaces) DeepCopy() *Namespaces {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionMigrationArgs) DeepCopyInto(out *NodeConditionMigrationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeConditionSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.DebounceDuration != nil {
+		in, out := &in.DebounceDuration, &out.DebounceDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxMigratingPerNode != nil {
+		in, out := &in.MaxMigratingPerNode, &out.MaxMigratingPerNode
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionMigrationArgs.
+func (in *NodeConditionMigrationArgs) DeepCopy() *NodeConditionMigrationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionMigrationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeConditionMigrationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionSelector) DeepCopyInto(out *NodeConditionSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionSelector.
+func (in *NodeConditionSelector) DeepCopy() *NodeConditionSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceMigrationArgs) DeepCopyInto(out *NodeMaintenanceMigrationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CycleInterval != nil {
+		in, out := &in.CycleInterval, &out.CycleInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceMigrationArgs.
+func (in *NodeMaintenanceMigrationArgs) DeepCopy() *NodeMaintenanceMigrationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceMigrationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceMigrationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ObjectLimiterMap) DeepCopyInto(out *ObjectLimiterMap) {
 	{
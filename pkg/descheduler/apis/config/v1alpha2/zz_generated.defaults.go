@@ -33,6 +33,10 @@ func RegisterDefaults(scheme *runtime.Scheme) error {
 	scheme.AddTypeDefaultingFunc(&DeschedulerConfiguration{}, func(obj interface{}) { SetObjectDefaults_DeschedulerConfiguration(obj.(*DeschedulerConfiguration)) })
 	scheme.AddTypeDefaultingFunc(&LowNodeLoadArgs{}, func(obj interface{}) { SetObjectDefaults_LowNodeLoadArgs(obj.(*LowNodeLoadArgs)) })
 	scheme.AddTypeDefaultingFunc(&MigrationControllerArgs{}, func(obj interface{}) { SetObjectDefaults_MigrationControllerArgs(obj.(*MigrationControllerArgs)) })
+	scheme.AddTypeDefaultingFunc(&NodeConditionMigrationArgs{}, func(obj interface{}) { SetObjectDefaults_NodeConditionMigrationArgs(obj.(*NodeConditionMigrationArgs)) })
+	scheme.AddTypeDefaultingFunc(&NodeMaintenanceMigrationArgs{}, func(obj interface{}) {
+		SetObjectDefaults_NodeMaintenanceMigrationArgs(obj.(*NodeMaintenanceMigrationArgs))
+	})
 	scheme.AddTypeDefaultingFunc(&RemovePodsViolatingNodeAffinityArgs{}, func(obj interface{}) {
 		SetObjectDefaults_RemovePodsViolatingNodeAffinityArgs(obj.(*RemovePodsViolatingNodeAffinityArgs))
 	})
@@ -45,6 +49,12 @@ func SetObjectDefaults_DefaultEvictorArgs(in *DefaultEvictorArgs) {
 
 func SetObjectDefaults_DeschedulerConfiguration(in *DeschedulerConfiguration) {
 	SetDefaults_DeschedulerConfiguration(in)
+	if in.EvictionApproval != nil {
+		SetDefaults_EvictionApprovalConfiguration(in.EvictionApproval)
+	}
+	if in.AdaptiveInterval != nil {
+		SetDefaults_AdaptiveIntervalConfiguration(in.AdaptiveInterval)
+	}
 }
 
 func SetObjectDefaults_LowNodeLoadArgs(in *LowNodeLoadArgs) {
@@ -55,6 +65,14 @@ func SetObjectDefaults_MigrationControllerArgs(in *MigrationControllerArgs) {
 	SetDefaults_MigrationControllerArgs(in)
 }
 
+func SetObjectDefaults_NodeConditionMigrationArgs(in *NodeConditionMigrationArgs) {
+	SetDefaults_NodeConditionMigrationArgs(in)
+}
+
+func SetObjectDefaults_NodeMaintenanceMigrationArgs(in *NodeMaintenanceMigrationArgs) {
+	SetDefaults_NodeMaintenanceMigrationArgs(in)
+}
+
 func SetObjectDefaults_RemovePodsViolatingNodeAffinityArgs(in *RemovePodsViolatingNodeAffinityArgs) {
 	SetDefaults_RemovePodsViolatingNodeAffinityArgs(in)
 }
@@ -63,6 +63,32 @@ func ValidateDeschedulerConfiguration(cc *config.DeschedulerConfiguration) utile
 		}
 	}
 
+	if cc.EvictionApproval != nil {
+		approvalPath := field.NewPath("evictionApproval")
+		if cc.EvictionApproval.EvictionThreshold < 0 {
+			errs = append(errs, field.Invalid(approvalPath.Child("evictionThreshold"), cc.EvictionApproval.EvictionThreshold, "must be greater than or equal to 0"))
+		}
+		if cc.EvictionApproval.PlanExpiration.Duration <= 0 {
+			errs = append(errs, field.Invalid(approvalPath.Child("planExpiration"), cc.EvictionApproval.PlanExpiration, "must be greater than 0"))
+		}
+		if cc.EvictionApproval.FinishedPlanTTL.Duration <= 0 {
+			errs = append(errs, field.Invalid(approvalPath.Child("finishedPlanTTL"), cc.EvictionApproval.FinishedPlanTTL, "must be greater than 0"))
+		}
+	}
+
+	if cc.AdaptiveInterval != nil {
+		adaptivePath := field.NewPath("adaptiveInterval")
+		if cc.AdaptiveInterval.MinInterval.Duration <= 0 {
+			errs = append(errs, field.Invalid(adaptivePath.Child("minInterval"), cc.AdaptiveInterval.MinInterval, "must be greater than 0"))
+		}
+		if cc.AdaptiveInterval.MaxInterval.Duration < cc.AdaptiveInterval.MinInterval.Duration {
+			errs = append(errs, field.Invalid(adaptivePath.Child("maxInterval"), cc.AdaptiveInterval.MaxInterval, "must be greater than or equal to minInterval"))
+		}
+		if cc.AdaptiveInterval.IdleCyclesToGrow <= 0 {
+			errs = append(errs, field.Invalid(adaptivePath.Child("idleCyclesToGrow"), cc.AdaptiveInterval.IdleCyclesToGrow, "must be greater than 0"))
+		}
+	}
+
 	return utilerrors.Flatten(utilerrors.NewAggregate(errs))
 }
 
@@ -71,6 +97,11 @@ func validateDeschedulerProfile(path *field.Path, profile *config.DeschedulerPro
 	if len(profile.Name) == 0 {
 		errs = append(errs, field.Required(path.Child("name"), ""))
 	}
+	if profile.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(profile.NodeSelector); err != nil {
+			errs = append(errs, field.Invalid(path.Child("nodeSelector"), profile.NodeSelector, err.Error()))
+		}
+	}
 	errs = append(errs, validatePluginConfig(path, profile)...)
 	return errs
 }
@@ -80,6 +111,7 @@ func validatePluginConfig(path *field.Path, profile *config.DeschedulerProfile)
 	m := map[string]interface{}{
 		// NOTE: you can add the in-tree plugins configuration validation function
 		names.MigrationController:         ValidateMigrationControllerArgs,
+		"DefaultEvictor":                  ValidateDefaultEvictorArgs,
 		"RemovePodsViolatingNodeAffinity": ValidateRemovePodsViolatingNodeAffinityArgs,
 	}
 
@@ -18,15 +18,60 @@ package validation
 
 import (
 	"fmt"
+	"net/url"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 )
 
+func ValidateDefaultEvictorArgs(path *field.Path, args *deschedulerconfig.DefaultEvictorArgs) error {
+	var allErrs field.ErrorList
+
+	if args.MaxPercentageOfPodsToEvictPerOwner != nil {
+		if percentage := *args.MaxPercentageOfPodsToEvictPerOwner; percentage <= 0 || percentage > 100 {
+			allErrs = append(allErrs, field.Invalid(path.Child("maxPercentageOfPodsToEvictPerOwner"), percentage, "maxPercentageOfPodsToEvictPerOwner should be in (0, 100]"))
+		}
+	}
+	if args.EvictionNotifier != nil {
+		notifierPath := path.Child("evictionNotifier")
+		if u, err := url.Parse(args.EvictionNotifier.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(notifierPath.Child("url"), args.EvictionNotifier.URL, "url should be an absolute http or https URL"))
+		}
+		if args.EvictionNotifier.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(notifierPath.Child("timeout"), args.EvictionNotifier.Timeout, "timeout should be greater than 0"))
+		}
+		if args.EvictionNotifier.MaxRetries < 0 {
+			allErrs = append(allErrs, field.Invalid(notifierPath.Child("maxRetries"), args.EvictionNotifier.MaxRetries, "maxRetries should be greater than or equal to 0"))
+		}
+		if args.EvictionNotifier.FailureThreshold <= 0 {
+			allErrs = append(allErrs, field.Invalid(notifierPath.Child("failureThreshold"), args.EvictionNotifier.FailureThreshold, "failureThreshold should be greater than 0"))
+		}
+		if args.EvictionNotifier.CircuitBreakDuration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(notifierPath.Child("circuitBreakDuration"), args.EvictionNotifier.CircuitBreakDuration, "circuitBreakDuration should be greater than 0"))
+		}
+	}
+	if args.EvictionPacing != nil {
+		pacingPath := path.Child("evictionPacing")
+		if args.EvictionPacing.MinInterval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(pacingPath.Child("minInterval"), args.EvictionPacing.MinInterval, "minInterval should be greater than 0"))
+		}
+		if args.EvictionPacing.JitterFactor < 0 {
+			allErrs = append(allErrs, field.Invalid(pacingPath.Child("jitterFactor"), args.EvictionPacing.JitterFactor, "jitterFactor should be greater than or equal to 0"))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
+
 func ValidateRemovePodsViolatingNodeAffinityArgs(path *field.Path, args *deschedulerconfig.RemovePodsViolatingNodeAffinityArgs) error {
 	var allErrs field.ErrorList
 
@@ -37,6 +82,69 @@ func ValidateRemovePodsViolatingNodeAffinityArgs(path *field.Path, args *desched
 	if args.Namespaces != nil && len(args.Namespaces.Include) > 0 && len(args.Namespaces.Exclude) > 0 {
 		allErrs = append(allErrs, field.Invalid(path.Child("namespaces"), args.Namespaces, "only one of Include/Exclude namespaces can be set"))
 	}
+	if args.NodeCostLabel != "" {
+		for _, msg := range validation.IsQualifiedName(args.NodeCostLabel) {
+			allErrs = append(allErrs, field.Invalid(path.Child("nodeCostLabel"), args.NodeCostLabel, msg))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
+
+func ValidateNodeMaintenanceMigrationArgs(path *field.Path, args *deschedulerconfig.NodeMaintenanceMigrationArgs) error {
+	var allErrs field.ErrorList
+
+	if args.CycleInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("cycleInterval"), args.CycleInterval, "cycleInterval must be greater than 0"))
+	}
+	// At most one of include/exclude can be set
+	if args.Namespaces != nil && len(args.Namespaces.Include) > 0 && len(args.Namespaces.Exclude) > 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("namespaces"), args.Namespaces, "only one of Include/Exclude namespaces can be set"))
+	}
+	if args.LabelSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(args.LabelSelector, path.Child("labelSelector"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
+
+func ValidateNodeConditionMigrationArgs(path *field.Path, args *deschedulerconfig.NodeConditionMigrationArgs) error {
+	var allErrs field.ErrorList
+
+	if len(args.Conditions) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("conditions"), "at least one condition must be set"))
+	}
+	for i, condition := range args.Conditions {
+		conditionPath := path.Child("conditions").Index(i)
+		if condition.Type == "" {
+			allErrs = append(allErrs, field.Required(conditionPath.Child("type"), ""))
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown:
+		default:
+			allErrs = append(allErrs, field.NotSupported(conditionPath.Child("status"), condition.Status,
+				[]string{string(corev1.ConditionTrue), string(corev1.ConditionFalse), string(corev1.ConditionUnknown)}))
+		}
+	}
+	if args.DebounceDuration.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("debounceDuration"), args.DebounceDuration, "debounceDuration must be greater than or equal to 0"))
+	}
+	if args.MaxMigratingPerNode <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxMigratingPerNode"), args.MaxMigratingPerNode, "maxMigratingPerNode must be greater than 0"))
+	}
+	// At most one of include/exclude can be set
+	if args.Namespaces != nil && len(args.Namespaces.Include) > 0 && len(args.Namespaces.Exclude) > 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("namespaces"), args.Namespaces, "only one of Include/Exclude namespaces can be set"))
+	}
+	if args.LabelSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(args.LabelSelector, path.Child("labelSelector"))...)
+	}
 
 	if len(allErrs) == 0 {
 		return nil
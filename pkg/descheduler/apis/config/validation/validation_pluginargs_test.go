@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
@@ -28,6 +29,121 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/v1alpha2"
 )
 
+func TestValidateDefaultEvictorArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *v1alpha2.DefaultEvictorArgs
+		wantErr bool
+	}{
+		{
+			name:    "default args",
+			args:    &v1alpha2.DefaultEvictorArgs{},
+			wantErr: false,
+		},
+		{
+			name: "valid maxPercentageOfPodsToEvictPerOwner",
+			args: &v1alpha2.DefaultEvictorArgs{
+				MaxPercentageOfPodsToEvictPerOwner: pointer.Int32(100),
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero maxPercentageOfPodsToEvictPerOwner",
+			args: &v1alpha2.DefaultEvictorArgs{
+				MaxPercentageOfPodsToEvictPerOwner: pointer.Int32(0),
+			},
+			wantErr: true,
+		},
+		{
+			name: "too large maxPercentageOfPodsToEvictPerOwner",
+			args: &v1alpha2.DefaultEvictorArgs{
+				MaxPercentageOfPodsToEvictPerOwner: pointer.Int32(101),
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid evictionNotifier",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionNotifier: &v1alpha2.EvictionNotifierArgs{
+					URL: "https://change-management.example.com/evictions",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "evictionNotifier without url",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionNotifier: &v1alpha2.EvictionNotifierArgs{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "evictionNotifier with relative url",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionNotifier: &v1alpha2.EvictionNotifierArgs{
+					URL: "/evictions",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "evictionNotifier with negative maxRetries",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionNotifier: &v1alpha2.EvictionNotifierArgs{
+					URL:        "http://127.0.0.1:8080/evictions",
+					MaxRetries: pointer.Int32(-1),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "evictionNotifier with zero failureThreshold",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionNotifier: &v1alpha2.EvictionNotifierArgs{
+					URL:              "http://127.0.0.1:8080/evictions",
+					FailureThreshold: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid evictionPacing",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionPacing: &v1alpha2.EvictionPacingArgs{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "evictionPacing with zero minInterval",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionPacing: &v1alpha2.EvictionPacingArgs{
+					MinInterval: &metav1.Duration{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "evictionPacing with negative jitterFactor",
+			args: &v1alpha2.DefaultEvictorArgs{
+				EvictionPacing: &v1alpha2.EvictionPacingArgs{
+					JitterFactor: pointer.Float64(-0.1),
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1alpha2.SetDefaults_DefaultEvictorArgs(tt.args)
+			args := &deschedulerconfig.DefaultEvictorArgs{}
+			assert.NoError(t, v1alpha2.Convert_v1alpha2_DefaultEvictorArgs_To_config_DefaultEvictorArgs(tt.args, args, nil))
+			if err := ValidateDefaultEvictorArgs(nil, args); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDefaultEvictorArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateRemovePodsViolatingNodeAffinityArgs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -67,6 +183,20 @@ func TestValidateRemovePodsViolatingNodeAffinityArgs(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid node cost label",
+			args: &v1alpha2.RemovePodsViolatingNodeAffinityArgs{
+				NodeCostLabel: "node.koordinator.sh/cost",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid node cost label",
+			args: &v1alpha2.RemovePodsViolatingNodeAffinityArgs{
+				NodeCostLabel: "node cost",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -80,6 +210,108 @@ func TestValidateRemovePodsViolatingNodeAffinityArgs(t *testing.T) {
 	}
 }
 
+func TestValidateNodeMaintenanceMigrationArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *v1alpha2.NodeMaintenanceMigrationArgs
+		wantErr bool
+	}{
+		{
+			name:    "default args",
+			args:    &v1alpha2.NodeMaintenanceMigrationArgs{},
+			wantErr: false,
+		},
+		{
+			name: "invalid cycleInterval",
+			args: &v1alpha2.NodeMaintenanceMigrationArgs{
+				CycleInterval: &metav1.Duration{Duration: 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid namespaces",
+			args: &v1alpha2.NodeMaintenanceMigrationArgs{
+				Namespaces: &v1alpha2.Namespaces{
+					Include: []string{"test-1"},
+					Exclude: []string{"test-2"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1alpha2.SetDefaults_NodeMaintenanceMigrationArgs(tt.args)
+			args := &deschedulerconfig.NodeMaintenanceMigrationArgs{}
+			assert.NoError(t, v1alpha2.Convert_v1alpha2_NodeMaintenanceMigrationArgs_To_config_NodeMaintenanceMigrationArgs(tt.args, args, nil))
+			if err := ValidateNodeMaintenanceMigrationArgs(nil, args); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNodeMaintenanceMigrationArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNodeConditionMigrationArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *v1alpha2.NodeConditionMigrationArgs
+		wantErr bool
+	}{
+		{
+			name: "default args",
+			args: &v1alpha2.NodeConditionMigrationArgs{
+				Conditions: []v1alpha2.NodeConditionSelector{{Type: "CorrectableECCStorm"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing conditions",
+			args:    &v1alpha2.NodeConditionMigrationArgs{},
+			wantErr: true,
+		},
+		{
+			name: "missing condition type",
+			args: &v1alpha2.NodeConditionMigrationArgs{
+				Conditions: []v1alpha2.NodeConditionSelector{{Status: corev1.ConditionTrue}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid condition status",
+			args: &v1alpha2.NodeConditionMigrationArgs{
+				Conditions: []v1alpha2.NodeConditionSelector{{Type: "NICFlapping", Status: "Flapping"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid debounceDuration",
+			args: &v1alpha2.NodeConditionMigrationArgs{
+				Conditions:       []v1alpha2.NodeConditionSelector{{Type: "NICFlapping"}},
+				DebounceDuration: &metav1.Duration{Duration: -time.Minute},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid maxMigratingPerNode",
+			args: &v1alpha2.NodeConditionMigrationArgs{
+				Conditions:          []v1alpha2.NodeConditionSelector{{Type: "NICFlapping"}},
+				MaxMigratingPerNode: pointer.Int32(0),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1alpha2.SetDefaults_NodeConditionMigrationArgs(tt.args)
+			args := &deschedulerconfig.NodeConditionMigrationArgs{}
+			assert.NoError(t, v1alpha2.Convert_v1alpha2_NodeConditionMigrationArgs_To_config_NodeConditionMigrationArgs(tt.args, args, nil))
+			if err := ValidateNodeConditionMigrationArgs(nil, args); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNodeConditionMigrationArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateMigrationControllerArgs(t *testing.T) {
 	tests := []struct {
 		name    string
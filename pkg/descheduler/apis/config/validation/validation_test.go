@@ -18,6 +18,7 @@ package validation
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -78,6 +79,22 @@ func TestValidateDeschedulerConfiguration(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid profile nodeSelector",
+			args: &v1alpha2.DeschedulerConfiguration{
+				Profiles: []v1alpha2.DeschedulerProfile{
+					{
+						Name: "test",
+						NodeSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"test/a/b/c/d": "test",
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "duplicate plugin config",
 			args: &v1alpha2.DeschedulerConfiguration{
@@ -103,6 +120,83 @@ func TestValidateDeschedulerConfiguration(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid evictionApproval",
+			args: &v1alpha2.DeschedulerConfiguration{
+				EvictionApproval: &v1alpha2.EvictionApprovalConfiguration{
+					EvictionThreshold: pointer.Int32(0),
+					PlanExpiration:    &metav1.Duration{Duration: time.Hour},
+					FinishedPlanTTL:   &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid evictionApproval threshold",
+			args: &v1alpha2.DeschedulerConfiguration{
+				EvictionApproval: &v1alpha2.EvictionApprovalConfiguration{
+					EvictionThreshold: pointer.Int32(-1),
+					PlanExpiration:    &metav1.Duration{Duration: time.Hour},
+					FinishedPlanTTL:   &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid evictionApproval planExpiration",
+			args: &v1alpha2.DeschedulerConfiguration{
+				EvictionApproval: &v1alpha2.EvictionApprovalConfiguration{
+					EvictionThreshold: pointer.Int32(10),
+					PlanExpiration:    &metav1.Duration{Duration: -1},
+					FinishedPlanTTL:   &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid evictionApproval finishedPlanTTL",
+			args: &v1alpha2.DeschedulerConfiguration{
+				EvictionApproval: &v1alpha2.EvictionApprovalConfiguration{
+					EvictionThreshold: pointer.Int32(10),
+					PlanExpiration:    &metav1.Duration{Duration: time.Hour},
+					FinishedPlanTTL:   &metav1.Duration{Duration: 0},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid adaptiveInterval",
+			args: &v1alpha2.DeschedulerConfiguration{
+				AdaptiveInterval: &v1alpha2.AdaptiveIntervalConfiguration{
+					MinInterval:      &metav1.Duration{Duration: time.Minute},
+					MaxInterval:      &metav1.Duration{Duration: time.Hour},
+					IdleCyclesToGrow: pointer.Int32(3),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid adaptiveInterval maxInterval",
+			args: &v1alpha2.DeschedulerConfiguration{
+				AdaptiveInterval: &v1alpha2.AdaptiveIntervalConfiguration{
+					MinInterval:      &metav1.Duration{Duration: time.Hour},
+					MaxInterval:      &metav1.Duration{Duration: time.Minute},
+					IdleCyclesToGrow: pointer.Int32(3),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid adaptiveInterval idleCyclesToGrow",
+			args: &v1alpha2.DeschedulerConfiguration{
+				AdaptiveInterval: &v1alpha2.AdaptiveIntervalConfiguration{
+					MinInterval:      &metav1.Duration{Duration: time.Minute},
+					MaxInterval:      &metav1.Duration{Duration: time.Hour},
+					IdleCyclesToGrow: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -27,6 +27,24 @@ import (
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveIntervalConfiguration) DeepCopyInto(out *AdaptiveIntervalConfiguration) {
+	*out = *in
+	out.MinInterval = in.MinInterval
+	out.MaxInterval = in.MaxInterval
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveIntervalConfiguration.
+func (in *AdaptiveIntervalConfiguration) DeepCopy() *AdaptiveIntervalConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveIntervalConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultEvictorArgs) DeepCopyInto(out *DefaultEvictorArgs) {
 	*out = *in
@@ -41,6 +59,11 @@ func (in *DefaultEvictorArgs) DeepCopyInto(out *DefaultEvictorArgs) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.MaxPercentageOfPodsToEvictPerOwner != nil {
+		in, out := &in.MaxPercentageOfPodsToEvictPerOwner, &out.MaxPercentageOfPodsToEvictPerOwner
+		*out = new(int32)
+		**out = **in
+	}
 	if in.PriorityThreshold != nil {
 		in, out := &in.PriorityThreshold, &out.PriorityThreshold
 		*out = new(PriorityThreshold)
@@ -51,6 +74,16 @@ func (in *DefaultEvictorArgs) DeepCopyInto(out *DefaultEvictorArgs) {
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EvictionNotifier != nil {
+		in, out := &in.EvictionNotifier, &out.EvictionNotifier
+		*out = new(EvictionNotifierArgs)
+		**out = **in
+	}
+	if in.EvictionPacing != nil {
+		in, out := &in.EvictionPacing, &out.EvictionPacing
+		*out = new(EvictionPacingArgs)
+		**out = **in
+	}
 	return
 }
 
@@ -92,6 +125,16 @@ func (in *DeschedulerConfiguration) DeepCopyInto(out *DeschedulerConfiguration)
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EvictionApproval != nil {
+		in, out := &in.EvictionApproval, &out.EvictionApproval
+		*out = new(EvictionApprovalConfiguration)
+		**out = **in
+	}
+	if in.AdaptiveInterval != nil {
+		in, out := &in.AdaptiveInterval, &out.AdaptiveInterval
+		*out = new(AdaptiveIntervalConfiguration)
+		**out = **in
+	}
 	return
 }
 
@@ -128,6 +171,11 @@ func (in *DeschedulerProfile) DeepCopyInto(out *DeschedulerProfile) {
 		*out = new(Plugins)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -141,6 +189,59 @@ func (in *DeschedulerProfile) DeepCopy() *DeschedulerProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionApprovalConfiguration) DeepCopyInto(out *EvictionApprovalConfiguration) {
+	*out = *in
+	out.PlanExpiration = in.PlanExpiration
+	out.FinishedPlanTTL = in.FinishedPlanTTL
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionApprovalConfiguration.
+func (in *EvictionApprovalConfiguration) DeepCopy() *EvictionApprovalConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionApprovalConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionNotifierArgs) DeepCopyInto(out *EvictionNotifierArgs) {
+	*out = *in
+	out.Timeout = in.Timeout
+	out.CircuitBreakDuration = in.CircuitBreakDuration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionNotifierArgs.
+func (in *EvictionNotifierArgs) DeepCopy() *EvictionNotifierArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionNotifierArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionPacingArgs) DeepCopyInto(out *EvictionPacingArgs) {
+	*out = *in
+	out.MinInterval = in.MinInterval
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionPacingArgs.
+func (in *EvictionPacingArgs) DeepCopy() *EvictionPacingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionPacingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Float64OrString) DeepCopyInto(out *Float64OrString) {
 	*out = *in
@@ -377,6 +478,99 @@ func (in *Namespaces) DeepCopy() *Namespaces {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionMigrationArgs) DeepCopyInto(out *NodeConditionMigrationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeConditionSelector, len(*in))
+		copy(*out, *in)
+	}
+	out.DebounceDuration = in.DebounceDuration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionMigrationArgs.
+func (in *NodeConditionMigrationArgs) DeepCopy() *NodeConditionMigrationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionMigrationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeConditionMigrationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionSelector) DeepCopyInto(out *NodeConditionSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionSelector.
+func (in *NodeConditionSelector) DeepCopy() *NodeConditionSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceMigrationArgs) DeepCopyInto(out *NodeMaintenanceMigrationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.CycleInterval = in.CycleInterval
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceMigrationArgs.
+func (in *NodeMaintenanceMigrationArgs) DeepCopy() *NodeMaintenanceMigrationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceMigrationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceMigrationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ObjectLimiterMap) DeepCopyInto(out *ObjectLimiterMap) {
 	{
@@ -25,6 +25,12 @@ import (
 	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 )
 
+const (
+	// AnnotationSkipQuotaCheck marks the PodMigrationJob which evicts the pod even if the ElasticQuota of the pod
+	// is exhausted, e.g. the eviction reclaiming the quota.
+	AnnotationSkipQuotaCheck = "koordinator.sh/skip-quota-check"
+)
+
 var (
 	ctxKey = new(int)
 )
@@ -34,6 +40,8 @@ type JobContext struct {
 	Annotations map[string]string
 	Timeout     *time.Duration
 	Mode        sev1alpha1.PodMigrationJobMode
+	// SkipQuotaCheck evicts the pod even if its replacement cannot be scheduled within the exhausted ElasticQuota.
+	SkipQuotaCheck bool
 }
 
 func WithContext(ctx context.Context, jobCtx *JobContext) context.Context {
@@ -73,5 +81,11 @@ func (c *JobContext) ApplyTo(job *sev1alpha1.PodMigrationJob) error {
 	if c.Mode != "" {
 		job.Spec.Mode = c.Mode
 	}
+	if c.SkipQuotaCheck {
+		if job.Annotations == nil {
+			job.Annotations = make(map[string]string)
+		}
+		job.Annotations[AnnotationSkipQuotaCheck] = "true"
+	}
 	return nil
 }
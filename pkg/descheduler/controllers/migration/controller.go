@@ -259,6 +259,16 @@ func (r *Reconciler) Evict(ctx context.Context, pod *corev1.Pod, evictOptions fr
 		klog.Errorf("Pod %q cannot be evicted since failed to filter", klog.KObj(pod))
 		return false
 	}
+	if jobCtx := FromContext(ctx); jobCtx == nil || !jobCtx.SkipQuotaCheck {
+		mode := sev1alpha1.PodMigrationJobMode(r.args.DefaultJobMode)
+		if jobCtx != nil && jobCtx.Mode != "" {
+			mode = jobCtx.Mode
+		}
+		if !r.filterQuotaAvailable(pod, mode) {
+			klog.Errorf("Pod %q cannot be evicted since the ElasticQuota is exhausted for its replacement", klog.KObj(pod))
+			return false
+		}
+	}
 
 	err := CreatePodMigrationJob(ctx, pod, evictOptions, r.Client, r.args)
 	return err == nil
@@ -356,6 +366,7 @@ func (r *Reconciler) doScavenge() {
 // +kubebuilder:rbac:groups=scheduling.koordinator.sh,resources=podmigrationjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.koordinator.sh,resources=podmigrationjobs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=scheduling.koordinator.sh,resources=reservations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=scheduling.sigs.k8s.io,resources=elasticquotas,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a PodMigrationJob object and makes changes based on the state read
 // and what is in the Spec
@@ -528,6 +539,9 @@ func (r *Reconciler) preparePendingJob(ctx context.Context, job *sev1alpha1.PodM
 	if requeue, err := r.requeueJobIfRetriablePodFilterFailed(ctx, job); requeue || err != nil {
 		return reconcile.Result{RequeueAfter: defaultRequeueAfter}, err
 	}
+	if requeue, err := r.requeueJobIfQuotaExhausted(ctx, job); requeue || err != nil {
+		return reconcile.Result{RequeueAfter: defaultRequeueAfter}, err
+	}
 
 	job.Status.Phase = sev1alpha1.PodMigrationJobRunning
 	err = r.Client.Status().Update(ctx, job)
@@ -601,6 +615,26 @@ func (r *Reconciler) requeueJobIfRetriablePodFilterFailed(ctx context.Context, j
 	return false, nil
 }
 
+// requeueJobIfQuotaExhausted requeues the job until the ElasticQuota of the pod could schedule the replacement,
+// unless the job skips the quota check.
+func (r *Reconciler) requeueJobIfQuotaExhausted(ctx context.Context, job *sev1alpha1.PodMigrationJob) (bool, error) {
+	if job.Annotations[AnnotationSkipQuotaCheck] == "true" {
+		return false, nil
+	}
+
+	pod := &corev1.Pod{}
+	podNamespacedName := types.NamespacedName{Namespace: job.Spec.PodRef.Namespace, Name: job.Spec.PodRef.Name}
+	err := r.Client.Get(ctx, podNamespacedName, pod)
+	if err == nil {
+		if !r.filterQuotaAvailable(pod, job.Spec.Mode) {
+			r.eventRecorder.Eventf(job, nil, corev1.EventTypeWarning, "Requeue", "Migrating", "ElasticQuota is exhausted for the replacement")
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (r *Reconciler) abortJobIfUnretriablePodFilterFailed(ctx context.Context, job *sev1alpha1.PodMigrationJob) (bool, error) {
 	if r.unretriablePodFilter == nil {
 		return false, nil
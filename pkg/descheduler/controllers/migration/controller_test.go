@@ -18,6 +18,7 @@ package migration
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -26,6 +27,7 @@ import (
 	appsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,7 +41,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/v1alpha2"
@@ -112,6 +116,7 @@ func newTestReconciler() *Reconciler {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = appsv1alpha1.AddToScheme(scheme)
 	_ = appsv1beta1.AddToScheme(scheme)
+	_ = schedv1alpha1.AddToScheme(scheme)
 
 	var v1beta2args v1alpha2.MigrationControllerArgs
 	v1alpha2.SetDefaults_MigrationControllerArgs(&v1beta2args)
@@ -2455,3 +2460,275 @@ func TestFilterObjectLimiter(t *testing.T) {
 		})
 	}
 }
+
+func newTestElasticQuota(namespace, name string, used, runtime corev1.ResourceList) *schedv1alpha1.ElasticQuota {
+	quota := &schedv1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: schedv1alpha1.ElasticQuotaSpec{
+			Max: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10"),
+				corev1.ResourceMemory: resource.MustParse("20Gi"),
+			},
+		},
+		Status: schedv1alpha1.ElasticQuotaStatus{
+			Used: used,
+		},
+	}
+	if runtime != nil {
+		data, _ := json.Marshal(runtime)
+		quota.Annotations = map[string]string{extension.AnnotationRuntime: string(data)}
+	}
+	return quota
+}
+
+func newTestQuotaPod(namespace, name, quotaName string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					Controller: pointer.Bool(true),
+					Kind:       "Deployment",
+					Name:       "test",
+				},
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+	if quotaName != "" {
+		pod.Labels = map[string]string{extension.LabelQuotaName: quotaName}
+	}
+	return pod
+}
+
+func TestFilterQuotaAvailable(t *testing.T) {
+	tests := []struct {
+		name   string
+		quotas []*schedv1alpha1.ElasticQuota
+		pod    *corev1.Pod
+		mode   sev1alpha1.PodMigrationJobMode
+		want   bool
+	}{
+		{
+			name: "missing quota",
+			pod:  newTestQuotaPod("default", "test-pod", "test-quota"),
+			mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+			want: true,
+		},
+		{
+			name: "missing quota runtime",
+			quotas: []*schedv1alpha1.ElasticQuota{
+				newTestElasticQuota("quota-ns", "test-quota", corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("20Gi"),
+				}, nil),
+			},
+			pod:  newTestQuotaPod("default", "test-pod", "test-quota"),
+			mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+			want: true,
+		},
+		{
+			name: "quota available for the reserved replacement",
+			quotas: []*schedv1alpha1.ElasticQuota{
+				newTestElasticQuota("quota-ns", "test-quota", corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("6"),
+					corev1.ResourceMemory: resource.MustParse("12Gi"),
+				}, corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}),
+			},
+			pod:  newTestQuotaPod("default", "test-pod", "test-quota"),
+			mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+			want: true,
+		},
+		{
+			name: "quota full for the reserved replacement",
+			quotas: []*schedv1alpha1.ElasticQuota{
+				newTestElasticQuota("quota-ns", "test-quota", corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("12Gi"),
+				}, corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}),
+			},
+			pod:  newTestQuotaPod("default", "test-pod", "test-quota"),
+			mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+			want: false,
+		},
+		{
+			name: "quota full takes the quota released by the pod evicted directly",
+			quotas: []*schedv1alpha1.ElasticQuota{
+				newTestElasticQuota("quota-ns", "test-quota", corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("12Gi"),
+				}, corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}),
+			},
+			pod:  newTestQuotaPod("default", "test-pod", "test-quota"),
+			mode: sev1alpha1.PodMigrationJobModeEvictionDirectly,
+			want: true,
+		},
+		{
+			name: "quota runtime reclaimed below the used",
+			quotas: []*schedv1alpha1.ElasticQuota{
+				newTestElasticQuota("quota-ns", "test-quota", corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("12Gi"),
+				}, corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("6"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}),
+			},
+			pod:  newTestQuotaPod("default", "test-pod", "test-quota"),
+			mode: sev1alpha1.PodMigrationJobModeEvictionDirectly,
+			want: false,
+		},
+		{
+			name: "quota full in the namespace of the pod without the quota label",
+			quotas: []*schedv1alpha1.ElasticQuota{
+				newTestElasticQuota("default", "default-quota", corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("12Gi"),
+				}, corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}),
+			},
+			pod:  newTestQuotaPod("default", "test-pod", ""),
+			mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := newTestReconciler()
+			for _, quota := range tt.quotas {
+				assert.NoError(t, reconciler.Client.Create(context.TODO(), quota))
+			}
+			assert.Equal(t, tt.want, reconciler.filterQuotaAvailable(tt.pod, tt.mode))
+		})
+	}
+}
+
+func TestEvictWithQuotaExhausted(t *testing.T) {
+	reconciler := newTestReconciler()
+	quota := newTestElasticQuota("quota-ns", "test-quota", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	}, corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	})
+	assert.NoError(t, reconciler.Client.Create(context.TODO(), quota))
+	pod := newTestQuotaPod("test", "test-pod", "test-quota")
+	assert.True(t, reconciler.Filter(pod))
+
+	assert.False(t, reconciler.Evict(context.TODO(), pod, framework.EvictOptions{}))
+	var jobList sev1alpha1.PodMigrationJobList
+	assert.NoError(t, reconciler.Client.List(context.TODO(), &jobList))
+	assert.Equal(t, 0, len(jobList.Items))
+
+	// the eviction reclaiming the quota skips the quota check
+	ctx := WithContext(context.TODO(), &JobContext{SkipQuotaCheck: true})
+	assert.True(t, reconciler.Evict(ctx, pod, framework.EvictOptions{}))
+	assert.NoError(t, reconciler.Client.List(context.TODO(), &jobList))
+	assert.Equal(t, 1, len(jobList.Items))
+	assert.Equal(t, "true", jobList.Items[0].Annotations[AnnotationSkipQuotaCheck])
+}
+
+func TestRequeueJobIfQuotaExhausted(t *testing.T) {
+	tests := []struct {
+		name        string
+		used        corev1.ResourceList
+		annotations map[string]string
+		wantRequeue bool
+	}{
+		{
+			name: "quota available",
+			used: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			wantRequeue: false,
+		},
+		{
+			name: "quota full",
+			used: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+			wantRequeue: true,
+		},
+		{
+			name: "quota full but skip the quota check",
+			used: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+			annotations: map[string]string{AnnotationSkipQuotaCheck: "true"},
+			wantRequeue: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := newTestReconciler()
+			quota := newTestElasticQuota("quota-ns", "test-quota", tt.used, corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			})
+			assert.NoError(t, reconciler.Client.Create(context.TODO(), quota))
+			pod := newTestQuotaPod("default", "test-pod", "test-quota")
+			assert.NoError(t, reconciler.Client.Create(context.TODO(), pod))
+
+			job := &sev1alpha1.PodMigrationJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					CreationTimestamp: metav1.Time{Time: time.Now()},
+					Annotations:       tt.annotations,
+				},
+				Spec: sev1alpha1.PodMigrationJobSpec{
+					PodRef: &corev1.ObjectReference{
+						Namespace: "default",
+						Name:      "test-pod",
+					},
+					Mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+				},
+			}
+			assert.NoError(t, reconciler.Client.Create(context.TODO(), job))
+
+			result, err := reconciler.preparePendingJob(context.TODO(), job)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter != 0)
+			assert.NoError(t, reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: job.Name}, job))
+			if tt.wantRequeue {
+				assert.Equal(t, sev1alpha1.PodMigrationJobPhase(""), job.Status.Phase)
+			} else {
+				assert.Equal(t, sev1alpha1.PodMigrationJobRunning, job.Status.Phase)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictor
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func init() {
+	RegisterEvictor(RescheduleEvictorName, NewRescheduleEvictor)
+}
+
+const (
+	RescheduleEvictorName = "Reschedule"
+
+	// AnnotationRescheduleRequested marks the pod requested to be rescheduled voluntarily,
+	// and the value is the time of the request in RFC3339 format.
+	// The owner controller of the pod is expected to recreate the pod gracefully.
+	AnnotationRescheduleRequested = "koordinator.sh/reschedule-requested"
+)
+
+// RescheduleEvictor does not evict the pod, but annotates the pod to request its controller to reschedule it,
+// leaving the actual disruption to the controller.
+type RescheduleEvictor struct {
+	client kubernetes.Interface
+}
+
+func NewRescheduleEvictor(client kubernetes.Interface) (Interface, error) {
+	return &RescheduleEvictor{
+		client: client,
+	}, nil
+}
+
+func (e *RescheduleEvictor) Evict(ctx context.Context, job *sev1alpha1.PodMigrationJob, pod *corev1.Pod) error {
+	if _, ok := pod.Annotations[AnnotationRescheduleRequested]; ok {
+		return nil
+	}
+	trigger, reason := GetEvictionTriggerAndReason(job.Annotations)
+	_, err := util.NewPatch().WithClientset(e.client).AddAnnotations(map[string]string{
+		AnnotationRescheduleRequested: time.Now().Format(time.RFC3339),
+		AnnotationEvictReason:         reason,
+		AnnotationEvictTrigger:        trigger,
+	}).PatchPod(pod)
+	return err
+}
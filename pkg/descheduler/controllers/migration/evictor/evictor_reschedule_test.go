@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func TestRescheduleEvictor(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node",
+		},
+	}
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-job",
+			Annotations: map[string]string{
+				AnnotationEvictReason:  "node is overloaded",
+				AnnotationEvictTrigger: "LowNodeLoad",
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(pod)
+	evictor, err := NewRescheduleEvictor(client)
+	assert.NoError(t, err)
+	assert.NoError(t, evictor.Evict(context.TODO(), job, pod))
+
+	got, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	requestTime, err := time.Parse(time.RFC3339, got.Annotations[AnnotationRescheduleRequested])
+	assert.NoError(t, err)
+	assert.False(t, requestTime.IsZero())
+	assert.Equal(t, "node is overloaded", got.Annotations[AnnotationEvictReason])
+	assert.Equal(t, "LowNodeLoad", got.Annotations[AnnotationEvictTrigger])
+
+	// the pod is annotated only, and is neither evicted nor deleted
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "delete", action.GetVerb())
+		assert.NotEqual(t, "eviction", action.GetSubresource())
+	}
+
+	// the requested pod is kept untouched
+	client.ClearActions()
+	assert.NoError(t, evictor.Evict(context.TODO(), job, got))
+	assert.Empty(t, client.Actions())
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -64,6 +65,7 @@ type interpreterImpl struct {
 	defaultEvictor Interface
 	rateLimiter    flowcontrol.RateLimiter
 	eventRecorder  events.EventRecorder
+	annotator      *workloadAnnotator
 }
 
 func NewInterpreter(handle framework.Handle, defaultEvictionPolicy string, evictQPS float32, evictBurst int) (Interpreter, error) {
@@ -86,6 +88,7 @@ func NewInterpreter(handle framework.Handle, defaultEvictionPolicy string, evict
 		defaultEvictor: defaultEvictor,
 		rateLimiter:    rateLimiter,
 		eventRecorder:  handle.EventRecorder(),
+		annotator:      newWorkloadAnnotator(handle.ClientSet()),
 	}, nil
 }
 
@@ -119,6 +122,9 @@ func (p *interpreterImpl) Evict(ctx context.Context, job *sev1alpha1.PodMigratio
 
 	klog.V(1).InfoS("Evicted pod", "pod", klog.KObj(pod), "reason", reason, "trigger", trigger, "node", pod.Spec.NodeName)
 	p.eventRecorder.Eventf(pod, nil, corev1.EventTypeNormal, "Descheduled", "Migrating", "Pod evicted from node %q by the reason %q", pod.Spec.NodeName, reason)
+	if err := p.annotator.recordDescheduled(ctx, pod, time.Now()); err != nil {
+		klog.ErrorS(err, "Failed to record the descheduling history", "pod", klog.KObj(pod), "node", pod.Spec.NodeName)
+	}
 	return nil
 }
 
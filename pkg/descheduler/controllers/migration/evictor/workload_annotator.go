@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// maxDeschedulingRecords is the maximum number of the nodes recorded in the descheduling history of a workload.
+const maxDeschedulingRecords = 8
+
+// workloadAnnotator records the nodes the pods were descheduled from on the controllers of the pods,
+// so that the scheduler can avoid placing the replacements back on the nodes.
+// Only the ReplicaSets and the StatefulSets are supported.
+type workloadAnnotator struct {
+	client kubernetes.Interface
+}
+
+func newWorkloadAnnotator(client kubernetes.Interface) *workloadAnnotator {
+	return &workloadAnnotator{client: client}
+}
+
+func (a *workloadAnnotator) recordDescheduled(ctx context.Context, pod *corev1.Pod, now time.Time) error {
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil || pod.Spec.NodeName == "" {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(controllerRef.APIVersion)
+	if err != nil || gv.Group != appsv1.GroupName {
+		return nil
+	}
+
+	var annotations map[string]string
+	var patchFn func(data []byte) error
+	switch controllerRef.Kind {
+	case "ReplicaSet":
+		replicaSet, err := a.client.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, controllerRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if replicaSet.UID != controllerRef.UID {
+			return nil
+		}
+		annotations = replicaSet.Annotations
+		patchFn = func(data []byte) error {
+			_, err := a.client.AppsV1().ReplicaSets(pod.Namespace).Patch(ctx, controllerRef.Name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		}
+	case "StatefulSet":
+		statefulSet, err := a.client.AppsV1().StatefulSets(pod.Namespace).Get(ctx, controllerRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if statefulSet.UID != controllerRef.UID {
+			return nil
+		}
+		annotations = statefulSet.Annotations
+		patchFn = func(data []byte) error {
+			_, err := a.client.AppsV1().StatefulSets(pod.Namespace).Patch(ctx, controllerRef.Name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		}
+	default:
+		return nil
+	}
+
+	// the malformed history is overwritten
+	history, _ := extension.GetDeschedulingHistory(annotations)
+	history = addDeschedulingRecord(history, extension.DeschedulingRecord{
+		NodeName: pod.Spec.NodeName,
+		Time:     metav1.NewTime(now),
+	})
+	value, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				extension.AnnotationDeschedulingHistory: string(value),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := patchFn(data); err != nil {
+		return fmt.Errorf("failed to patch %s %s/%s, err: %v", controllerRef.Kind, pod.Namespace, controllerRef.Name, err)
+	}
+	return nil
+}
+
+// addDeschedulingRecord replaces the record of the same node, and keeps at most maxDeschedulingRecords latest records.
+func addDeschedulingRecord(history []extension.DeschedulingRecord, record extension.DeschedulingRecord) []extension.DeschedulingRecord {
+	records := make([]extension.DeschedulingRecord, 0, len(history)+1)
+	for _, r := range history {
+		if r.NodeName != record.NodeName {
+			records = append(records, r)
+		}
+	}
+	records = append(records, record)
+	if len(records) > maxDeschedulingRecords {
+		records = records[len(records)-maxDeschedulingRecords:]
+	}
+	return records
+}
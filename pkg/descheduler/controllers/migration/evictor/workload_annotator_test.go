@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func newAnnotatorTestPod(nodeName string, owner metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "test-pod",
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+func getTestDeschedulingHistory(t *testing.T, annotations map[string]string) map[string]time.Time {
+	history, err := extension.GetDeschedulingHistory(annotations)
+	assert.NoError(t, err)
+	records := map[string]time.Time{}
+	for _, record := range history {
+		records[record.NodeName] = record.Time.Time
+	}
+	return records
+}
+
+func TestWorkloadAnnotatorRecordDescheduled(t *testing.T) {
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-rs", UID: "rs-uid"},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-sts", UID: "sts-uid"},
+	}
+	client := fake.NewSimpleClientset(replicaSet, statefulSet)
+	annotator := newWorkloadAnnotator(client)
+	now := time.Now().Truncate(time.Second)
+
+	rsOwner := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "rs-uid", Controller: pointer.Bool(true)}
+	assert.NoError(t, annotator.recordDescheduled(context.TODO(), newAnnotatorTestPod("node-1", rsOwner), now))
+	assert.NoError(t, annotator.recordDescheduled(context.TODO(), newAnnotatorTestPod("node-2", rsOwner), now.Add(time.Minute)))
+	// the record of the same node is refreshed
+	assert.NoError(t, annotator.recordDescheduled(context.TODO(), newAnnotatorTestPod("node-1", rsOwner), now.Add(2*time.Minute)))
+	gotRS, err := client.AppsV1().ReplicaSets("default").Get(context.TODO(), "test-rs", metav1.GetOptions{})
+	assert.NoError(t, err)
+	rsHistory := getTestDeschedulingHistory(t, gotRS.Annotations)
+	assert.Len(t, rsHistory, 2)
+	assert.True(t, now.Add(2*time.Minute).Equal(rsHistory["node-1"]))
+	assert.True(t, now.Add(time.Minute).Equal(rsHistory["node-2"]))
+
+	stsOwner := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "test-sts", UID: "sts-uid", Controller: pointer.Bool(true)}
+	assert.NoError(t, annotator.recordDescheduled(context.TODO(), newAnnotatorTestPod("node-3", stsOwner), now))
+	gotSTS, err := client.AppsV1().StatefulSets("default").Get(context.TODO(), "test-sts", metav1.GetOptions{})
+	assert.NoError(t, err)
+	stsHistory := getTestDeschedulingHistory(t, gotSTS.Annotations)
+	assert.Len(t, stsHistory, 1)
+	assert.True(t, now.Equal(stsHistory["node-3"]))
+}
+
+func TestWorkloadAnnotatorSkipsUnsupportedOwners(t *testing.T) {
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-rs", UID: "rs-uid"},
+	}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+	}{
+		{
+			name: "no controller",
+			pod:  newAnnotatorTestPod("node-1", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "rs-uid"}),
+		},
+		{
+			name: "unsupported kind",
+			pod:  newAnnotatorTestPod("node-1", metav1.OwnerReference{APIVersion: "batch/v1", Kind: "Job", Name: "test-job", UID: "job-uid", Controller: pointer.Bool(true)}),
+		},
+		{
+			name: "controller recreated",
+			pod:  newAnnotatorTestPod("node-1", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: types.UID("old-rs-uid"), Controller: pointer.Bool(true)}),
+		},
+		{
+			name: "pod not assigned",
+			pod:  newAnnotatorTestPod("", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "rs-uid", Controller: pointer.Bool(true)}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(replicaSet)
+			annotator := newWorkloadAnnotator(client)
+			assert.NoError(t, annotator.recordDescheduled(context.TODO(), tt.pod, time.Now()))
+			for _, action := range client.Actions() {
+				assert.NotEqual(t, "patch", action.GetVerb())
+			}
+		})
+	}
+}
+
+func TestAddDeschedulingRecord(t *testing.T) {
+	now := time.Now()
+	var history []extension.DeschedulingRecord
+	for i := 0; i < maxDeschedulingRecords+2; i++ {
+		history = addDeschedulingRecord(history, extension.DeschedulingRecord{
+			NodeName: fmt.Sprintf("node-%d", i),
+			Time:     metav1.NewTime(now.Add(time.Duration(i) * time.Second)),
+		})
+	}
+	assert.Len(t, history, maxDeschedulingRecords)
+	assert.Equal(t, "node-2", history[0].NodeName)
+	assert.Equal(t, fmt.Sprintf("node-%d", maxDeschedulingRecords+1), history[len(history)-1].NodeName)
+
+	history = addDeschedulingRecord(history, extension.DeschedulingRecord{NodeName: "node-2", Time: metav1.NewTime(now.Add(time.Hour))})
+	assert.Len(t, history, maxDeschedulingRecords)
+	assert.Equal(t, "node-3", history[0].NodeName)
+	assert.Equal(t, "node-2", history[len(history)-1].NodeName)
+}
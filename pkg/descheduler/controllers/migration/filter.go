@@ -18,6 +18,7 @@ package migration
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	gocache "github.com/patrickmn/go-cache"
@@ -26,15 +27,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration/util"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/fieldindex"
+	koordutil "github.com/koordinator-sh/koordinator/pkg/util"
 	utilclient "github.com/koordinator-sh/koordinator/pkg/util/client"
 )
 
@@ -211,6 +216,62 @@ func (r *Reconciler) filterMaxMigratingOrUnavailablePerWorkload(pod *corev1.Pod)
 	return true
 }
 
+// filterQuotaAvailable checks whether the replacement of the pod could be scheduled within the ElasticQuota of the pod
+// by the used and the runtime published by the scheduler. The replacement is reserved while the pod still holds
+// the quota in the ReservationFirst mode, otherwise it takes the quota released by the pod. The pod can be evicted
+// if the quota or its runtime is missing.
+func (r *Reconciler) filterQuotaAvailable(pod *corev1.Pod, mode sev1alpha1.PodMigrationJobMode) bool {
+	quota := r.getPodElasticQuota(pod)
+	if quota == nil || quota.Annotations[extension.AnnotationRuntime] == "" {
+		return true
+	}
+	var runtime corev1.ResourceList
+	if err := json.Unmarshal([]byte(quota.Annotations[extension.AnnotationRuntime]), &runtime); err != nil {
+		klog.V(4).Infof("Failed to parse the runtime of ElasticQuota %s, err: %v", quota.Name, err)
+		return true
+	}
+
+	podRequest := koordutil.GetPodEffectiveRequest(pod)
+	required := quota.Status.Used
+	if mode != sev1alpha1.PodMigrationJobModeEvictionDirectly {
+		required = quotav1.Add(required, podRequest)
+	}
+	for resourceName := range podRequest {
+		runtimeQuantity, ok := runtime[resourceName]
+		if !ok {
+			continue
+		}
+		if requiredQuantity := required[resourceName]; requiredQuantity.Cmp(runtimeQuantity) > 0 {
+			maxQuantity := quota.Spec.Max.Name(resourceName, runtimeQuantity.Format)
+			klog.V(4).Infof("The replacement of Pod %q cannot be scheduled since ElasticQuota %s is exhausted, resource %s, required %s, runtime %s, max %s",
+				klog.KObj(pod), quota.Name, resourceName, requiredQuantity.String(), runtimeQuantity.String(), maxQuantity.String())
+			return false
+		}
+	}
+	return true
+}
+
+// getPodElasticQuota returns the ElasticQuota of the pod, nil if not found. The pod without the quota label belongs
+// to the quota in its namespace like in the scheduler.
+func (r *Reconciler) getPodElasticQuota(pod *corev1.Pod) *schedv1alpha1.ElasticQuota {
+	quotaName := extension.GetQuotaName(pod)
+	quotaList := &schedv1alpha1.ElasticQuotaList{}
+	var opts []client.ListOption
+	if quotaName == "" {
+		opts = append(opts, client.InNamespace(pod.Namespace))
+	}
+	if err := r.Client.List(context.TODO(), quotaList, opts...); err != nil {
+		klog.V(4).Infof("Failed to list ElasticQuotas for Pod %q, err: %v", klog.KObj(pod), err)
+		return nil
+	}
+	for i := range quotaList.Items {
+		if quotaName == "" || quotaList.Items[i].Name == quotaName {
+			return &quotaList.Items[i]
+		}
+	}
+	return nil
+}
+
 func (r *Reconciler) getUnavailablePods(pods []*corev1.Pod) map[types.NamespacedName]struct{} {
 	unavailablePods := make(map[types.NamespacedName]struct{})
 	for _, pod := range pods {
@@ -20,21 +20,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/scheme"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/v1alpha2"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/evictions"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
 	frameworkplugins "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins"
 	frameworkruntime "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
@@ -52,10 +58,21 @@ type Descheduler struct {
 
 	clientSet    clientset.Interface
 	nodeInformer corev1informers.NodeInformer
+	podLister    corev1listers.PodLister
 
 	dryRun               bool
 	deschedulingInterval time.Duration
 	nodeSelector         string
+	// profileNodeSelectors are the node selectors of the profiles indexed by the profile name.
+	profileNodeSelectors map[string]labels.Selector
+
+	// evictionApproval enables the approval workflow of the cycles planning too many evictions if it is not nil.
+	evictionApproval *deschedulerconfig.EvictionApprovalConfiguration
+	// adaptiveInterval adapts the interval between the cycles to the cluster churn if it is not nil.
+	adaptiveInterval *adaptiveInterval
+	// client operates the DeschedulePlans of the approval workflow.
+	client client.Client
+	clock  clock.Clock
 }
 
 type deschedulerOptions struct {
@@ -69,6 +86,9 @@ type deschedulerOptions struct {
 	dryRun                 bool
 	deschedulingInterval   time.Duration
 	nodeSelector           *metav1.LabelSelector
+	evictionApproval       *deschedulerconfig.EvictionApprovalConfiguration
+	adaptiveInterval       *deschedulerconfig.AdaptiveIntervalConfiguration
+	client                 client.Client
 }
 
 // Option configures a Scheduler
@@ -115,6 +135,28 @@ func WithDeschedulingInterval(interval time.Duration) Option {
 	}
 }
 
+// WithEvictionApproval enables the approval workflow of the descheduling cycles planning too many evictions.
+func WithEvictionApproval(evictionApproval *deschedulerconfig.EvictionApprovalConfiguration) Option {
+	return func(options *deschedulerOptions) {
+		options.evictionApproval = evictionApproval
+	}
+}
+
+// WithAdaptiveInterval adapts the interval between the descheduling cycles to the cluster churn,
+// starting from the descheduling interval.
+func WithAdaptiveInterval(adaptiveInterval *deschedulerconfig.AdaptiveIntervalConfiguration) Option {
+	return func(options *deschedulerOptions) {
+		options.adaptiveInterval = adaptiveInterval
+	}
+}
+
+// WithClient sets the client to operate the CRDs, e.g. the DeschedulePlans of the approval workflow.
+func WithClient(c client.Client) Option {
+	return func(options *deschedulerOptions) {
+		options.client = c
+	}
+}
+
 // WithFrameworkOutOfTreeRegistry sets the registry for out-of-tree plugins. Those plugins
 // will be appended to the default registry.
 func WithFrameworkOutOfTreeRegistry(registry frameworkruntime.Registry) Option {
@@ -180,6 +222,9 @@ func New(client clientset.Interface,
 		}
 		nodeSelector = selector.String()
 	}
+	if options.evictionApproval != nil && options.client == nil {
+		return nil, errors.New("the client is required by the eviction approval")
+	}
 
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	podInformer := informerFactory.Core().V1().Pods()
@@ -216,15 +261,37 @@ func New(client clientset.Interface,
 	if len(profiles) == 0 {
 		return nil, errors.New("at least one profile is required")
 	}
+	shareEvictionCounters(profiles)
+
+	profileNodeSelectors := map[string]labels.Selector{}
+	for _, p := range options.profiles {
+		if p.NodeSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(p.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nodeSelector of profile %s: %v", p.Name, err)
+		}
+		profileNodeSelectors[p.Name] = selector
+	}
 
 	descheduler := &Descheduler{
 		Profiles:             profiles,
 		StopEverything:       stopEverything,
 		clientSet:            client,
 		nodeInformer:         nodeInformer,
+		podLister:            podInformer.Lister(),
 		dryRun:               options.dryRun,
 		deschedulingInterval: options.deschedulingInterval,
 		nodeSelector:         nodeSelector,
+		profileNodeSelectors: profileNodeSelectors,
+		evictionApproval:     options.evictionApproval,
+		client:               options.client,
+		clock:                clock.RealClock{},
+	}
+	// the descheduler runs only once if no interval is specified, even if the interval is adaptive
+	if options.adaptiveInterval != nil && options.deschedulingInterval > 0 {
+		descheduler.adaptiveInterval = newAdaptiveInterval(options.deschedulingInterval, options.adaptiveInterval)
 	}
 	return descheduler, nil
 }
@@ -233,6 +300,12 @@ func (d *Descheduler) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	if d.adaptiveInterval != nil {
+		d.runWithAdaptiveInterval(ctx)
+		return nil
+	}
+
+	metrics.DeschedulingInterval.Set(d.deschedulingInterval.Seconds())
 	wait.NonSlidingUntil(func() {
 		if err := d.deschedulerOnce(ctx); err != nil {
 			klog.Errorf("Error descheduling pods: %v", err)
@@ -258,14 +331,55 @@ func (d *Descheduler) deschedulerOnce(ctx context.Context) error {
 	}
 
 	for _, p := range d.Profiles {
-		status := p.RunDeschedulePlugins(ctx, nodes)
+		if resetter, ok := p.Evictor().(framework.EvictorCycleResetter); ok {
+			resetter.ResetCycle()
+		}
+	}
+	// the memoized pods must not leak across cycles
+	defer func() {
+		for _, p := range d.Profiles {
+			if lister, ok := p.(framework.EvictablePodsLister); ok {
+				lister.ResetEvictablePods()
+			}
+		}
+	}()
+	// the paced evictions must not leak across cycles either
+	defer func() {
+		for _, p := range d.Profiles {
+			if finisher, ok := p.Evictor().(framework.EvictorCycleFinisher); ok {
+				finisher.FinishCycle(ctx)
+			}
+		}
+	}()
+
+	profileNodes := make(map[string][]*corev1.Node, len(d.Profiles))
+	for name := range d.Profiles {
+		profileNodes[name] = d.filterNodesForProfile(name, nodes)
+		metrics.ProfileNodesMatched.With(map[string]string{"profile": name}).Set(float64(len(profileNodes[name])))
+	}
+
+	if d.evictionApproval != nil {
+		return d.runProfilesWithApproval(ctx, profileNodes)
+	}
+	return d.runProfiles(ctx, profileNodes)
+}
+
+func (d *Descheduler) runProfiles(ctx context.Context, profileNodes map[string][]*corev1.Node) error {
+	for name, p := range d.Profiles {
+		if len(profileNodes[name]) == 0 {
+			continue
+		}
+		status := p.RunDeschedulePlugins(ctx, profileNodes[name])
 		if status != nil && status.Err != nil {
 			return status.Err
 		}
 	}
 
-	for _, p := range d.Profiles {
-		status := p.RunBalancePlugins(ctx, nodes)
+	for name, p := range d.Profiles {
+		if len(profileNodes[name]) == 0 {
+			continue
+		}
+		status := p.RunBalancePlugins(ctx, profileNodes[name])
 		if status != nil && status.Err != nil {
 			return status.Err
 		}
@@ -274,6 +388,38 @@ func (d *Descheduler) deschedulerOnce(ctx context.Context) error {
 	return nil
 }
 
+// shareEvictionCounters makes the evictors of the profiles count the evicted pods together, so that the profiles
+// acting on the overlapping nodes share the eviction limits, and their paced evictions are spaced together.
+func shareEvictionCounters(profiles profile.Map) {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var podEvictors []*evictions.PodEvictor
+	for _, name := range names {
+		if e, ok := profiles[name].Evictor().(interface{ PodEvictor() *evictions.PodEvictor }); ok {
+			podEvictors = append(podEvictors, e.PodEvictor())
+		}
+	}
+	evictions.ShareEvictionCounters(podEvictors...)
+}
+
+// filterNodesForProfile returns the nodes matching the node selector of the profile.
+func (d *Descheduler) filterNodesForProfile(profileName string, nodes []*corev1.Node) []*corev1.Node {
+	selector, ok := d.profileNodeSelectors[profileName]
+	if !ok {
+		return nodes
+	}
+	var matched []*corev1.Node
+	for _, node := range nodes {
+		if selector.Matches(labels.Set(node.Labels)) {
+			matched = append(matched, node)
+		}
+	}
+	return matched
+}
+
 func podAssignedToNodeAdaptor(fn PodAssignedToNodeFn) framework.GetPodsAssignedToNodeFunc {
 	return func(nodeName string, filterFunc framework.FilterFunc) ([]*corev1.Pod, error) {
 		if fn == nil {
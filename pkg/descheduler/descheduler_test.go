@@ -0,0 +1,303 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/pointer"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/evictions"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	frameworkruntime "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+type fakeEvictor struct{}
+
+func (f *fakeEvictor) Name() string { return "FakeEvictor" }
+
+func (f *fakeEvictor) Filter(pod *corev1.Pod) bool { return true }
+
+func (f *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	return true
+}
+
+type nodesRecorder struct {
+	lock  sync.Mutex
+	nodes map[string][]string
+}
+
+func (r *nodesRecorder) record(pluginName string, nodes []*corev1.Node) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	r.nodes[pluginName] = names
+}
+
+type fakeDeschedulePlugin struct {
+	name     string
+	recorder *nodesRecorder
+}
+
+func (f *fakeDeschedulePlugin) Name() string { return f.name }
+
+func (f *fakeDeschedulePlugin) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	f.recorder.record(f.name, nodes)
+	return nil
+}
+
+type fakeBalancePlugin struct {
+	name     string
+	recorder *nodesRecorder
+}
+
+func (f *fakeBalancePlugin) Name() string { return f.name }
+
+func (f *fakeBalancePlugin) Balance(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	f.recorder.record(f.name, nodes)
+	return nil
+}
+
+func TestDeschedulerOnceWithProfileNodeSelector(t *testing.T) {
+	nodes := []*corev1.Node{
+		test.BuildTestNode("colocation-node-1", 2000, 3000, 10, func(node *corev1.Node) {
+			node.Labels["pool"] = "colocation"
+		}),
+		test.BuildTestNode("colocation-node-2", 2000, 3000, 10, func(node *corev1.Node) {
+			node.Labels["pool"] = "colocation"
+		}),
+		test.BuildTestNode("batch-node-1", 2000, 3000, 10, func(node *corev1.Node) {
+			node.Labels["pool"] = "batch"
+		}),
+		test.BuildTestNode("other-node", 2000, 3000, 10, nil),
+	}
+	var objs []runtime.Object
+	for _, node := range nodes {
+		objs = append(objs, node)
+	}
+	fakeClient := fake.NewSimpleClientset(objs...)
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+
+	recorder := &nodesRecorder{nodes: map[string][]string{}}
+	registry := frameworkruntime.Registry{
+		"FakeEvictor": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakeEvictor{}, nil
+		},
+		"FakeLowNodeLoad": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakeBalancePlugin{name: "FakeLowNodeLoad", recorder: recorder}, nil
+		},
+		"FakePodLifeTime": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakeDeschedulePlugin{name: "FakePodLifeTime", recorder: recorder}, nil
+		},
+		"FakeRemoveDuplicates": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakeDeschedulePlugin{name: "FakeRemoveDuplicates", recorder: recorder}, nil
+		},
+	}
+	profiles := []deschedulerconfig.DeschedulerProfile{
+		{
+			Name: "colocation",
+			Plugins: &deschedulerconfig.Plugins{
+				Balance: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeLowNodeLoad"}}},
+				Evictor: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeEvictor"}}},
+			},
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "colocation"}},
+		},
+		{
+			Name: "batch",
+			Plugins: &deschedulerconfig.Plugins{
+				Deschedule: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakePodLifeTime"}}},
+				Evictor:    deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeEvictor"}}},
+			},
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "batch"}},
+		},
+		{
+			Name: "all",
+			Plugins: &deschedulerconfig.Plugins{
+				Deschedule: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeRemoveDuplicates"}}},
+				Evictor:    deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeEvictor"}}},
+			},
+		},
+	}
+	recorderFactory := func(string) events.EventRecorder {
+		return events.NewFakeRecorder(10)
+	}
+	d, err := New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profiles...),
+		WithFrameworkOutOfTreeRegistry(registry),
+	)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	assert.NoError(t, d.deschedulerOnce(ctx))
+	expected := map[string][]string{
+		"FakeLowNodeLoad":      {"colocation-node-1", "colocation-node-2"},
+		"FakePodLifeTime":      {"batch-node-1"},
+		"FakeRemoveDuplicates": {"batch-node-1", "colocation-node-1", "colocation-node-2", "other-node"},
+	}
+	assert.Equal(t, expected, recorder.nodes)
+}
+
+func TestNewWithInvalidProfileNodeSelector(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	registry := frameworkruntime.Registry{
+		"FakeEvictor": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakeEvictor{}, nil
+		},
+	}
+	profile := deschedulerconfig.DeschedulerProfile{
+		Name: "invalid",
+		Plugins: &deschedulerconfig.Plugins{
+			Evictor: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeEvictor"}}},
+		},
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"test/a/b/c/d": "test"}},
+	}
+	recorderFactory := func(string) events.EventRecorder {
+		return events.NewFakeRecorder(10)
+	}
+	_, err := New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profile),
+		WithFrameworkOutOfTreeRegistry(registry),
+	)
+	assert.Error(t, err)
+}
+
+type fakePodEvictor struct {
+	podEvictor *evictions.PodEvictor
+}
+
+func (f *fakePodEvictor) Name() string { return "FakePodEvictor" }
+
+func (f *fakePodEvictor) Filter(pod *corev1.Pod) bool { return true }
+
+func (f *fakePodEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	return f.podEvictor.Evict(ctx, pod, evictOptions)
+}
+
+func (f *fakePodEvictor) PodEvictor() *evictions.PodEvictor { return f.podEvictor }
+
+func TestNewSharesEvictionLimitsAcrossProfiles(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	registry := frameworkruntime.Registry{
+		"FakePodEvictor": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakePodEvictor{podEvictor: evictions.NewPodEvictor(handle.ClientSet(), handle.EventRecorder(), "v1", false, pointer.Int(1), nil)}, nil
+		},
+	}
+	var profiles []deschedulerconfig.DeschedulerProfile
+	for _, name := range []string{"colocation", "batch"} {
+		profiles = append(profiles, deschedulerconfig.DeschedulerProfile{
+			Name: name,
+			Plugins: &deschedulerconfig.Plugins{
+				Evictor: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakePodEvictor"}}},
+			},
+		})
+	}
+	recorderFactory := func(string) events.EventRecorder {
+		return events.NewFakeRecorder(10)
+	}
+	d, err := New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profiles...),
+		WithFrameworkOutOfTreeRegistry(registry),
+	)
+	assert.NoError(t, err)
+
+	pod1 := test.BuildTestPod("pod-1", 100, 0, "test-node", nil)
+	pod2 := test.BuildTestPod("pod-2", 100, 0, "test-node", nil)
+	for _, pod := range []*corev1.Pod{pod1, pod2} {
+		_, err := fakeClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	// the pod evicted by one profile consumes the per-node limit of the other profile
+	assert.True(t, d.Profiles["colocation"].Evictor().Evict(context.TODO(), pod1, framework.EvictOptions{}))
+	assert.False(t, d.Profiles["batch"].Evictor().Evict(context.TODO(), pod2, framework.EvictOptions{}))
+	assert.Equal(t, 1, d.Profiles["batch"].Evictor().(*fakePodEvictor).podEvictor.TotalEvicted())
+}
+
+func TestStartRunsOnceWithAdaptiveInterval(t *testing.T) {
+	adaptiveInterval := &deschedulerconfig.AdaptiveIntervalConfiguration{
+		MinInterval:      metav1.Duration{Duration: time.Minute},
+		MaxInterval:      metav1.Duration{Duration: 8 * time.Minute},
+		IdleCyclesToGrow: 2,
+	}
+	recorderFactory := func(string) events.EventRecorder {
+		return events.NewFakeRecorder(10)
+	}
+	registry := frameworkruntime.Registry{
+		"FakePodEvictor": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &fakePodEvictor{podEvictor: evictions.NewPodEvictor(handle.ClientSet(), handle.EventRecorder(), "v1", false, nil, nil)}, nil
+		},
+	}
+	profile := deschedulerconfig.DeschedulerProfile{
+		Name: "test",
+		Plugins: &deschedulerconfig.Plugins{
+			Evictor: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakePodEvictor"}}},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset()
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	d, err := New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profile),
+		WithFrameworkOutOfTreeRegistry(registry),
+		WithDeschedulingInterval(2*time.Minute),
+		WithAdaptiveInterval(adaptiveInterval),
+	)
+	assert.NoError(t, err)
+	assert.NotNil(t, d.adaptiveInterval)
+
+	// no interval is specified, so the descheduler runs only once
+	d, err = New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profile),
+		WithFrameworkOutOfTreeRegistry(registry),
+		WithAdaptiveInterval(adaptiveInterval),
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, d.adaptiveInterval)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, d.Start(context.TODO()))
+	}()
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("the descheduler does not stop after running once")
+	}
+}
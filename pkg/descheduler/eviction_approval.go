@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+const deschedulePlanGenerateName = "descheduleplan-"
+
+type plannedEviction struct {
+	profile string
+	framework.PlannedEviction
+}
+
+// runProfilesWithApproval runs the profiles with the evictions planned rather than executed. The planned evictions
+// are executed at the end of the cycle if they do not exceed the threshold, otherwise they are written into a
+// DeschedulePlan, which is executed or discarded in the following cycles depending on the operator's approval.
+// The cycles are skipped while a DeschedulePlan is waiting for the approval, and the evictions same as a denied
+// DeschedulePlan are discarded until the victims change or the plan is deleted after the FinishedPlanTTL.
+func (d *Descheduler) runProfilesWithApproval(ctx context.Context, profileNodes map[string][]*corev1.Node) error {
+	processed, denied, err := d.processDeschedulePlans(ctx)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	planners := map[string]framework.EvictionPlanner{}
+	for name, p := range d.Profiles {
+		if planner, ok := p.(framework.EvictionPlanner); ok {
+			planner.StartEvictionPlanning()
+			planners[name] = planner
+		}
+	}
+	err = d.runProfiles(ctx, profileNodes)
+	var evictions []plannedEviction
+	for name, planner := range planners {
+		for _, eviction := range planner.StopEvictionPlanning() {
+			evictions = append(evictions, plannedEviction{profile: name, PlannedEviction: eviction})
+		}
+	}
+	if len(evictions) > 0 {
+		sort.SliceStable(evictions, func(i, j int) bool {
+			return evictions[i].profile < evictions[j].profile
+		})
+		victims := newDeschedulePlanVictims(evictions)
+		if int32(len(evictions)) <= d.evictionApproval.EvictionThreshold {
+			for _, eviction := range evictions {
+				d.evictPlanned(ctx, eviction.profile, eviction.Pod, eviction.EvictOptions)
+			}
+		} else if denied.Has(getDeschedulePlanVictimsKey(victims)) {
+			klog.V(4).InfoS("The planned evictions are denied by a DeschedulePlan before, skip them", "evictions", len(evictions))
+		} else if planErr := d.createDeschedulePlan(ctx, victims); planErr != nil && err == nil {
+			err = planErr
+		}
+	}
+	return err
+}
+
+// processDeschedulePlans handles the pending DeschedulePlans and deletes the finished ones outliving the TTL. It
+// returns true if a plan is executed or still waiting for the approval, so that the cycle should be skipped, along
+// with the keys of the victims of the denied plans.
+func (d *Descheduler) processDeschedulePlans(ctx context.Context) (bool, sets.String, error) {
+	planList := &sev1alpha1.DeschedulePlanList{}
+	if err := d.client.List(ctx, planList); err != nil {
+		return false, nil, fmt.Errorf("failed to list DeschedulePlans: %v", err)
+	}
+	plans := planList.Items
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].CreationTimestamp.Before(&plans[j].CreationTimestamp)
+	})
+
+	processed := false
+	denied := sets.NewString()
+	for i := range plans {
+		plan := &plans[i]
+		if plan.Status.Phase != "" && plan.Status.Phase != sev1alpha1.DeschedulePlanPending {
+			if d.isDeschedulePlanOutdated(plan) {
+				d.deleteDeschedulePlan(ctx, plan)
+			} else if plan.Status.Phase == sev1alpha1.DeschedulePlanDenied {
+				denied.Insert(getDeschedulePlanVictimsKey(plan.Spec.Victims))
+			}
+			continue
+		}
+		var phase sev1alpha1.DeschedulePlanPhase
+		var message string
+		switch {
+		case plan.Spec.ExpirationTime != nil && !d.clock.Now().Before(plan.Spec.ExpirationTime.Time):
+			phase, message = sev1alpha1.DeschedulePlanExpired, "the plan is not executed before expiration"
+		case plan.Spec.Approved == nil:
+			klog.V(4).InfoS("DeschedulePlan is waiting for approval, skip the descheduling cycle", "plan", plan.Name)
+			processed = true
+			continue
+		case !*plan.Spec.Approved:
+			phase, message = sev1alpha1.DeschedulePlanDenied, "the plan is denied"
+			denied.Insert(getDeschedulePlanVictimsKey(plan.Spec.Victims))
+		default:
+			evicted := d.executeDeschedulePlan(ctx, plan)
+			phase, message = sev1alpha1.DeschedulePlanExecuted, fmt.Sprintf("%d of %d victims are evicted", evicted, len(plan.Spec.Victims))
+			processed = true
+		}
+		klog.InfoS("DeschedulePlan is processed", "plan", plan.Name, "phase", phase, "message", message)
+		if err := d.updateDeschedulePlanPhase(ctx, plan, phase, message); err != nil {
+			return processed, denied, err
+		}
+	}
+	return processed, denied, nil
+}
+
+// isDeschedulePlanOutdated checks if the finished plan is retained longer than the FinishedPlanTTL.
+func (d *Descheduler) isDeschedulePlanOutdated(plan *sev1alpha1.DeschedulePlan) bool {
+	finishedTime := plan.Status.LastTransitionTime
+	if finishedTime.IsZero() {
+		finishedTime = plan.CreationTimestamp
+	}
+	return !d.clock.Now().Before(finishedTime.Add(d.evictionApproval.FinishedPlanTTL.Duration))
+}
+
+func (d *Descheduler) deleteDeschedulePlan(ctx context.Context, plan *sev1alpha1.DeschedulePlan) {
+	if err := d.client.Delete(ctx, plan); err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete the outdated DeschedulePlan", "plan", plan.Name)
+		return
+	}
+	klog.V(4).InfoS("The outdated DeschedulePlan is deleted", "plan", plan.Name, "phase", plan.Status.Phase)
+}
+
+func (d *Descheduler) executeDeschedulePlan(ctx context.Context, plan *sev1alpha1.DeschedulePlan) int {
+	evicted := 0
+	for _, victim := range plan.Spec.Victims {
+		if victim.PodRef == nil {
+			continue
+		}
+		pod, err := d.podLister.Pods(victim.PodRef.Namespace).Get(victim.PodRef.Name)
+		if err != nil {
+			klog.V(4).InfoS("Skip evicting the victim since failed to get pod", "plan", plan.Name, "pod", klog.KRef(victim.PodRef.Namespace, victim.PodRef.Name), "err", err)
+			continue
+		}
+		// the pod is recreated after the plan is made
+		if victim.PodRef.UID != "" && pod.UID != victim.PodRef.UID {
+			klog.V(4).InfoS("Skip evicting the victim since the pod is recreated", "plan", plan.Name, "pod", klog.KObj(pod))
+			continue
+		}
+		if d.evictPlanned(ctx, victim.Profile, pod, framework.EvictOptions{PluginName: victim.Trigger, Reason: victim.Reason}) {
+			evicted++
+		}
+	}
+	return evicted
+}
+
+func (d *Descheduler) evictPlanned(ctx context.Context, profileName string, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	p, ok := d.Profiles[profileName]
+	if !ok {
+		klog.V(4).InfoS("Skip evicting pod since the profile is not found", "pod", klog.KObj(pod), "profile", profileName)
+		return false
+	}
+	evictor := p.Evictor()
+	if !evictor.Filter(pod) {
+		klog.V(4).InfoS("Skip evicting pod since it is not evictable anymore", "pod", klog.KObj(pod), "profile", profileName)
+		return false
+	}
+	return evictor.Evict(framework.PluginNameWithContext(ctx, evictOptions.PluginName), pod, evictOptions)
+}
+
+func newDeschedulePlanVictims(evictions []plannedEviction) []sev1alpha1.DeschedulePlanVictim {
+	victims := make([]sev1alpha1.DeschedulePlanVictim, 0, len(evictions))
+	for _, eviction := range evictions {
+		victims = append(victims, sev1alpha1.DeschedulePlanVictim{
+			PodRef: &corev1.ObjectReference{
+				Namespace: eviction.Pod.Namespace,
+				Name:      eviction.Pod.Name,
+				UID:       eviction.Pod.UID,
+			},
+			Profile: eviction.profile,
+			Trigger: eviction.EvictOptions.PluginName,
+			Reason:  eviction.EvictOptions.Reason,
+		})
+	}
+	return victims
+}
+
+// getDeschedulePlanVictimsKey returns the key identifying the victims regardless of their order. The reasons are
+// excluded since they may carry the metrics varying between the cycles.
+func getDeschedulePlanVictimsKey(victims []sev1alpha1.DeschedulePlanVictim) string {
+	keys := make([]string, 0, len(victims))
+	for _, victim := range victims {
+		if victim.PodRef == nil {
+			continue
+		}
+		keys = append(keys, fmt.Sprintf("%s/%s/%s/%s/%s", victim.Profile, victim.Trigger, victim.PodRef.Namespace, victim.PodRef.Name, victim.PodRef.UID))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func (d *Descheduler) createDeschedulePlan(ctx context.Context, victims []sev1alpha1.DeschedulePlanVictim) error {
+	plan := &sev1alpha1.DeschedulePlan{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: deschedulePlanGenerateName,
+		},
+		Spec: sev1alpha1.DeschedulePlanSpec{
+			ExpirationTime: &metav1.Time{Time: d.clock.Now().Add(d.evictionApproval.PlanExpiration.Duration)},
+			Victims:        victims,
+		},
+	}
+	if err := d.client.Create(ctx, plan); err != nil {
+		return fmt.Errorf("failed to create DeschedulePlan: %v", err)
+	}
+	klog.InfoS("The planned evictions exceed the threshold, DeschedulePlan is created and waiting for approval",
+		"plan", plan.Name, "evictions", len(victims), "threshold", d.evictionApproval.EvictionThreshold)
+	return d.updateDeschedulePlanPhase(ctx, plan, sev1alpha1.DeschedulePlanPending, "the plan is waiting for approval")
+}
+
+func (d *Descheduler) updateDeschedulePlanPhase(ctx context.Context, plan *sev1alpha1.DeschedulePlan, phase sev1alpha1.DeschedulePlanPhase, message string) error {
+	plan.Status.Phase = phase
+	plan.Status.Message = message
+	plan.Status.LastTransitionTime = metav1.Time{Time: d.clock.Now()}
+	if err := d.client.Status().Update(ctx, plan); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to update DeschedulePlan %s: %v", plan.Name, err)
+	}
+	return nil
+}
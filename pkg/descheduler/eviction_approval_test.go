@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	frameworkruntime "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+type recordingEvictor struct {
+	lock    sync.Mutex
+	evicted []string
+}
+
+func (e *recordingEvictor) Name() string { return "RecordingEvictor" }
+
+func (e *recordingEvictor) Filter(pod *corev1.Pod) bool { return true }
+
+func (e *recordingEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.evicted = append(e.evicted, pod.Name+"/"+evictOptions.PluginName)
+	return true
+}
+
+func (e *recordingEvictor) popEvicted() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	evicted := e.evicted
+	e.evicted = nil
+	return evicted
+}
+
+// evictingPlugin evicts the victims in each cycle
+type evictingPlugin struct {
+	handle  framework.Handle
+	victims []*corev1.Pod
+	runs    int
+}
+
+func (p *evictingPlugin) Name() string { return "FakeEvicting" }
+
+func (p *evictingPlugin) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	p.runs++
+	for _, pod := range p.victims {
+		p.handle.Evictor().Evict(ctx, pod, framework.EvictOptions{Reason: "test"})
+	}
+	// the same pod is planned only once
+	if len(p.victims) > 0 {
+		p.handle.Evictor().Evict(ctx, p.victims[0], framework.EvictOptions{Reason: "test"})
+	}
+	return nil
+}
+
+func newTestDeschedulerWithApproval(t *testing.T, pods []*corev1.Pod) (*Descheduler, *evictingPlugin, *recordingEvictor, client.Client) {
+	objs := []runtime.Object{
+		test.BuildTestNode("node-1", 2000, 3000, 10, nil),
+		test.BuildTestNode("node-2", 2000, 3000, 10, nil),
+	}
+	for _, pod := range pods {
+		objs = append(objs, pod)
+	}
+	fakeClient := kubefake.NewSimpleClientset(objs...)
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+
+	scheme := runtime.NewScheme()
+	_ = sev1alpha1.AddToScheme(scheme)
+	runtimeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	evictor := &recordingEvictor{}
+	plugin := &evictingPlugin{}
+	registry := frameworkruntime.Registry{
+		"RecordingEvictor": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return evictor, nil
+		},
+		"FakeEvicting": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			plugin.handle = handle
+			return plugin, nil
+		},
+	}
+	profile := deschedulerconfig.DeschedulerProfile{
+		Name: "test",
+		Plugins: &deschedulerconfig.Plugins{
+			Deschedule: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "FakeEvicting"}}},
+			Evictor:    deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "RecordingEvictor"}}},
+		},
+	}
+	recorderFactory := func(string) events.EventRecorder {
+		return events.NewFakeRecorder(10)
+	}
+	d, err := New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profile),
+		WithFrameworkOutOfTreeRegistry(registry),
+		WithEvictionApproval(&deschedulerconfig.EvictionApprovalConfiguration{
+			EvictionThreshold: 2,
+			PlanExpiration:    metav1.Duration{Duration: time.Hour},
+			FinishedPlanTTL:   metav1.Duration{Duration: 24 * time.Hour},
+		}),
+		WithClient(runtimeClient),
+	)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+	return d, plugin, evictor, runtimeClient
+}
+
+func listDeschedulePlans(t *testing.T, c client.Client) []sev1alpha1.DeschedulePlan {
+	planList := &sev1alpha1.DeschedulePlanList{}
+	assert.NoError(t, c.List(context.TODO(), planList))
+	return planList.Items
+}
+
+func TestDeschedulerWithEvictionApproval(t *testing.T) {
+	var pods []*corev1.Pod
+	for _, name := range []string{"pod-1", "pod-2", "pod-3"} {
+		pods = append(pods, test.BuildTestPod(name, 100, 100, "node-1", func(pod *corev1.Pod) {
+			pod.UID = types.UID(name)
+		}))
+	}
+
+	t.Run("small cycle bypasses the approval", func(t *testing.T) {
+		d, plugin, evictor, c := newTestDeschedulerWithApproval(t, pods)
+		plugin.victims = pods[:2]
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, []string{"pod-1/FakeEvicting", "pod-2/FakeEvicting"}, evictor.popEvicted())
+		assert.Empty(t, listDeschedulePlans(t, c))
+	})
+
+	t.Run("large cycle creates a plan and waits for the approval", func(t *testing.T) {
+		d, plugin, evictor, c := newTestDeschedulerWithApproval(t, pods)
+		plugin.victims = pods
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Empty(t, evictor.popEvicted())
+		plans := listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.Equal(t, sev1alpha1.DeschedulePlanPending, plans[0].Status.Phase)
+		assert.Equal(t, []sev1alpha1.DeschedulePlanVictim{
+			{PodRef: &corev1.ObjectReference{Namespace: "default", Name: "pod-1", UID: "pod-1"}, Profile: "test", Trigger: "FakeEvicting", Reason: "test"},
+			{PodRef: &corev1.ObjectReference{Namespace: "default", Name: "pod-2", UID: "pod-2"}, Profile: "test", Trigger: "FakeEvicting", Reason: "test"},
+			{PodRef: &corev1.ObjectReference{Namespace: "default", Name: "pod-3", UID: "pod-3"}, Profile: "test", Trigger: "FakeEvicting", Reason: "test"},
+		}, plans[0].Spec.Victims)
+
+		// the cycle is skipped while the plan is pending
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 1, plugin.runs)
+		assert.Empty(t, evictor.popEvicted())
+		assert.Len(t, listDeschedulePlans(t, c), 1)
+	})
+
+	t.Run("approved plan is executed", func(t *testing.T) {
+		d, plugin, evictor, c := newTestDeschedulerWithApproval(t, pods)
+		plugin.victims = pods
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		plan := listDeschedulePlans(t, c)[0]
+		plan.Spec.Approved = pointer.Bool(true)
+		assert.NoError(t, c.Update(context.TODO(), &plan))
+
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 1, plugin.runs)
+		assert.Equal(t, []string{"pod-1/FakeEvicting", "pod-2/FakeEvicting", "pod-3/FakeEvicting"}, evictor.popEvicted())
+		plans := listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.Equal(t, sev1alpha1.DeschedulePlanExecuted, plans[0].Status.Phase)
+		assert.Equal(t, "3 of 3 victims are evicted", plans[0].Status.Message)
+
+		// the executed plan does not block the following cycles
+		plugin.victims = pods[:1]
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 2, plugin.runs)
+		assert.Equal(t, []string{"pod-1/FakeEvicting"}, evictor.popEvicted())
+	})
+
+	t.Run("denied plan is discarded", func(t *testing.T) {
+		d, plugin, evictor, c := newTestDeschedulerWithApproval(t, pods)
+		plugin.victims = pods
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		plan := listDeschedulePlans(t, c)[0]
+		plan.Spec.Approved = pointer.Bool(false)
+		assert.NoError(t, c.Update(context.TODO(), &plan))
+
+		plugin.victims = pods[:1]
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 2, plugin.runs)
+		assert.Equal(t, []string{"pod-1/FakeEvicting"}, evictor.popEvicted())
+		plans := listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.Equal(t, sev1alpha1.DeschedulePlanDenied, plans[0].Status.Phase)
+
+		// the evictions same as the denied plan are discarded without a new plan
+		plugin.victims = []*corev1.Pod{pods[2], pods[1], pods[0]}
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 3, plugin.runs)
+		assert.Empty(t, evictor.popEvicted())
+		assert.Len(t, listDeschedulePlans(t, c), 1)
+
+		// a new plan is created once the victims change
+		recreated := pods[2].DeepCopy()
+		recreated.UID = "pod-3-recreated"
+		plugin.victims = []*corev1.Pod{pods[0], pods[1], recreated}
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 4, plugin.runs)
+		assert.Empty(t, evictor.popEvicted())
+		assert.Len(t, listDeschedulePlans(t, c), 2)
+	})
+
+	t.Run("finished plans are deleted after the TTL", func(t *testing.T) {
+		d, plugin, evictor, c := newTestDeschedulerWithApproval(t, pods)
+		plugin.victims = pods
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		plan := listDeschedulePlans(t, c)[0]
+		plan.Spec.Approved = pointer.Bool(false)
+		assert.NoError(t, c.Update(context.TODO(), &plan))
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 2, plugin.runs)
+		assert.Empty(t, evictor.popEvicted(), "the same evictions as the denied plan are discarded")
+		plans := listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.Equal(t, sev1alpha1.DeschedulePlanDenied, plans[0].Status.Phase)
+
+		// the denied plan is retained within the TTL
+		fakeClock := clock.NewFakeClock(time.Now().Add(23 * time.Hour))
+		d.clock = fakeClock
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 3, plugin.runs)
+		assert.Len(t, listDeschedulePlans(t, c), 1)
+
+		// the denied plan is deleted after the TTL, and the same evictions are planned again
+		fakeClock.Step(2 * time.Hour)
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 4, plugin.runs)
+		plans = listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.NotEqual(t, plan.Name, plans[0].Name)
+		assert.Equal(t, sev1alpha1.DeschedulePlanPending, plans[0].Status.Phase)
+
+		// the executed plan is deleted after the TTL as well
+		plans[0].Spec.Approved = pointer.Bool(true)
+		assert.NoError(t, c.Update(context.TODO(), &plans[0]))
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 4, plugin.runs)
+		assert.Len(t, evictor.popEvicted(), 3)
+		plans = listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.Equal(t, sev1alpha1.DeschedulePlanExecuted, plans[0].Status.Phase)
+
+		fakeClock.Step(25 * time.Hour)
+		plugin.victims = nil
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 5, plugin.runs)
+		assert.Empty(t, listDeschedulePlans(t, c))
+	})
+
+	t.Run("expired plan is discarded", func(t *testing.T) {
+		d, plugin, evictor, c := newTestDeschedulerWithApproval(t, pods)
+		plugin.victims = pods
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		// approved too late
+		plan := listDeschedulePlans(t, c)[0]
+		plan.Spec.Approved = pointer.Bool(true)
+		assert.NoError(t, c.Update(context.TODO(), &plan))
+		d.clock = clock.NewFakeClock(time.Now().Add(2 * time.Hour))
+
+		plugin.victims = nil
+		assert.NoError(t, d.deschedulerOnce(context.TODO()))
+		assert.Equal(t, 2, plugin.runs)
+		assert.Empty(t, evictor.popEvicted())
+		plans := listDeschedulePlans(t, c)
+		assert.Len(t, plans, 1)
+		assert.Equal(t, sev1alpha1.DeschedulePlanExpired, plans[0].Status.Phase)
+	})
+}
+
+func TestNewWithEvictionApprovalWithoutClient(t *testing.T) {
+	fakeClient := kubefake.NewSimpleClientset()
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	registry := frameworkruntime.Registry{
+		"RecordingEvictor": func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return &recordingEvictor{}, nil
+		},
+	}
+	profile := deschedulerconfig.DeschedulerProfile{
+		Name: "test",
+		Plugins: &deschedulerconfig.Plugins{
+			Evictor: deschedulerconfig.PluginSet{Enabled: []deschedulerconfig.Plugin{{Name: "RecordingEvictor"}}},
+		},
+	}
+	recorderFactory := func(string) events.EventRecorder {
+		return events.NewFakeRecorder(10)
+	}
+	_, err := New(fakeClient, sharedInformerFactory, nil, recorderFactory, nil,
+		WithProfiles(profile),
+		WithFrameworkOutOfTreeRegistry(registry),
+		WithEvictionApproval(&deschedulerconfig.EvictionApprovalConfiguration{EvictionThreshold: 2}),
+	)
+	assert.Error(t, err)
+}
@@ -31,8 +31,10 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/pointer"
 
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	evictutils "github.com/koordinator-sh/koordinator/pkg/descheduler/evictions/utils"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
@@ -47,6 +49,10 @@ const (
 
 type nodePodEvictedCount map[string]int
 type namespacePodEvictCount map[string]int
+type ownerPodEvictCount map[string]int
+
+// OwnerReplicasGetterFn returns the key and the replicas of the owner workload of the pod.
+type OwnerReplicasGetterFn func(pod *corev1.Pod) (owner string, replicas int32, ok bool)
 
 type PodEvictor struct {
 	client                     clientset.Interface
@@ -55,10 +61,35 @@ type PodEvictor struct {
 	dryRun                     bool
 	maxPodsToEvictPerNode      *int
 	maxPodsToEvictPerNamespace *int
-	lock                       sync.Mutex
-	totalCount                 int
-	nodepodCount               nodePodEvictedCount
-	namespacePodCount          namespacePodEvictCount
+	// maxPercentageOfPodsToEvictPerOwner works in a descheduling cycle.
+	maxPercentageOfPodsToEvictPerOwner *int32
+	ownerReplicasGetter                OwnerReplicasGetterFn
+	// counters may be shared with the other evictors, see ShareEvictionCounters.
+	counters *evictionCounters
+	notifier *EvictionNotifier
+	// pacer performs the evictions asynchronously one at a time if it is not nil.
+	pacer *evictionPacer
+}
+
+// evictionCounters counts the pods evicted by the evictors sharing it.
+type evictionCounters struct {
+	lock              sync.Mutex
+	totalCount        int
+	nodepodCount      nodePodEvictedCount
+	namespacePodCount namespacePodEvictCount
+	// ownerPodCount works in a descheduling cycle.
+	ownerPodCount ownerPodEvictCount
+	// evictedPods records the pods evicted in the current descheduling cycle and the plugins evicting them.
+	evictedPods map[string]string
+}
+
+func newEvictionCounters() *evictionCounters {
+	return &evictionCounters{
+		nodepodCount:      make(map[string]int),
+		namespacePodCount: make(map[string]int),
+		evictedPods:       make(map[string]string),
+		ownerPodCount:     make(map[string]int),
+	}
 }
 
 func NewPodEvictor(
@@ -68,58 +99,187 @@ func NewPodEvictor(
 	dryRun bool,
 	maxPodsToEvictPerNode *int,
 	maxPodsToEvictPerNamespace *int,
+	opts ...func(pe *PodEvictor),
 ) *PodEvictor {
-	return &PodEvictor{
+	pe := &PodEvictor{
 		client:                     client,
 		eventRecorder:              eventRecorder,
 		policyGroupVersion:         policyGroupVersion,
 		dryRun:                     dryRun,
 		maxPodsToEvictPerNode:      maxPodsToEvictPerNode,
 		maxPodsToEvictPerNamespace: maxPodsToEvictPerNamespace,
-		nodepodCount:               make(map[string]int),
-		namespacePodCount:          make(map[string]int),
+		counters:                   newEvictionCounters(),
+	}
+	for _, opt := range opts {
+		opt(pe)
+	}
+	return pe
+}
+
+// WithMaxPercentageOfPodsToEvictPerOwner restricts the pods of an owner evicted in a descheduling cycle
+// to the percentage of the replicas returned by the ownerReplicasGetter.
+func WithMaxPercentageOfPodsToEvictPerOwner(percentage int32, ownerReplicasGetter OwnerReplicasGetterFn) func(pe *PodEvictor) {
+	return func(pe *PodEvictor) {
+		pe.maxPercentageOfPodsToEvictPerOwner = pointer.Int32(percentage)
+		pe.ownerReplicasGetter = ownerReplicasGetter
 	}
 }
 
+// WithEvictionNotifier notifies the evictions to the endpoint of the notifier.
+func WithEvictionNotifier(notifier *EvictionNotifier) func(pe *PodEvictor) {
+	return func(pe *PodEvictor) {
+		pe.notifier = notifier
+	}
+}
+
+// WithEvictionPacing spaces the evictions by the min interval of the args. The evictions accepted by the budgets are
+// queued and performed by a worker, and the budgets of the failed ones are given back.
+func WithEvictionPacing(args *deschedulerconfig.EvictionPacingArgs) func(pe *PodEvictor) {
+	return func(pe *PodEvictor) {
+		pe.pacer = newEvictionPacer(args, clock.RealClock{})
+	}
+}
+
+// ShareEvictionCounters makes the evictors count the evicted pods together, so that the limits of each evictor apply
+// to the pods evicted by all of them, e.g. the evictors of the descheduling profiles. The paced evictions of them are
+// queued to the pacer of the first evictor pacing the evictions, so that they are spaced across the evictors too.
+// It must be called before any eviction.
+func ShareEvictionCounters(evictors ...*PodEvictor) {
+	if len(evictors) == 0 {
+		return
+	}
+	var pacer *evictionPacer
+	for _, pe := range evictors {
+		if pe.pacer != nil {
+			pacer = pe.pacer
+			break
+		}
+	}
+	for _, pe := range evictors {
+		pe.counters = evictors[0].counters
+		if pe.pacer != nil {
+			pe.pacer = pacer
+		}
+	}
+}
+
+// FinishCycle performs the evictions queued in the current descheduling cycle, and cancels the rest if the context
+// is done. It makes sure no eviction of the cycle is performed after it returns.
+func (pe *PodEvictor) FinishCycle(ctx context.Context) {
+	if pe.pacer == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		pe.pacer.cancel()
+		return
+	}
+	pe.pacer.flush(ctx)
+}
+
+// ResetCycle discards the pods evicted in the last descheduling cycle.
+func (pe *PodEvictor) ResetCycle() {
+	if pe.pacer != nil {
+		// the evictions left by the last cycle must not be accounted to the new one
+		pe.pacer.cancel()
+	}
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	pe.counters.evictedPods = make(map[string]string)
+	pe.counters.ownerPodCount = make(map[string]int)
+}
+
+// evictedBy returns the plugin which has evicted the pod in the current descheduling cycle.
+func (pe *PodEvictor) evictedBy(pod *corev1.Pod) (string, bool) {
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	pluginName, ok := pe.counters.evictedPods[evictedPodKey(pod)]
+	return pluginName, ok
+}
+
+func evictedPodKey(pod *corev1.Pod) string {
+	if pod.UID != "" {
+		return string(pod.UID)
+	}
+	return pod.Namespace + "/" + pod.Name
+}
+
 // NodeEvicted gives a number of pods evicted for node
 func (pe *PodEvictor) NodeEvicted(nodeName string) int {
-	pe.lock.Lock()
-	defer pe.lock.Unlock()
-	return pe.nodepodCount[nodeName]
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	return pe.counters.nodepodCount[nodeName]
 }
 
 func (pe *PodEvictor) NamespaceEvicted(namespace string) int {
-	pe.lock.Lock()
-	defer pe.lock.Unlock()
-	return pe.namespacePodCount[namespace]
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	return pe.counters.namespacePodCount[namespace]
+}
+
+func (pe *PodEvictor) ownerEvicted(owner string) int {
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	return pe.counters.ownerPodCount[owner]
 }
 
 // TotalEvicted gives a number of pods evicted through all nodes
 func (pe *PodEvictor) TotalEvicted() int {
-	pe.lock.Lock()
-	defer pe.lock.Unlock()
-	return pe.totalCount
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	return pe.counters.totalCount
 }
 
 // NodeLimitExceeded checks if the number of evictions for a node was exceeded
 func (pe *PodEvictor) NodeLimitExceeded(nodeName string) bool {
 	if pe.maxPodsToEvictPerNode != nil {
-		return pe.nodepodCount[nodeName] == *pe.maxPodsToEvictPerNode
+		return pe.NodeEvicted(nodeName) >= *pe.maxPodsToEvictPerNode
 	}
 	return false
 }
 
 func (pe *PodEvictor) NamespaceLimitExceeded(namespace string) bool {
 	if pe.maxPodsToEvictPerNamespace != nil {
-		return pe.namespacePodCount[namespace] == *pe.maxPodsToEvictPerNamespace
+		return pe.NamespaceEvicted(namespace) >= *pe.maxPodsToEvictPerNamespace
 	}
 	return false
 }
 
+// OwnerLimitExceeded checks if the number of evictions for the owner of the pod was exceeded in the current cycle
+func (pe *PodEvictor) OwnerLimitExceeded(pod *corev1.Pod) bool {
+	owner, limit, ok := pe.ownerLimit(pod)
+	if !ok {
+		return false
+	}
+	return pe.ownerEvicted(owner) >= limit
+}
+
+// ownerLimit returns the owner of the pod and the max number of its pods to evict in a cycle.
+func (pe *PodEvictor) ownerLimit(pod *corev1.Pod) (string, int, bool) {
+	if pe.maxPercentageOfPodsToEvictPerOwner == nil || pe.ownerReplicasGetter == nil {
+		return "", 0, false
+	}
+	owner, replicas, ok := pe.ownerReplicasGetter(pod)
+	if !ok || replicas <= 0 {
+		return "", 0, false
+	}
+	limit := int(replicas) * int(*pe.maxPercentageOfPodsToEvictPerOwner) / 100
+	if limit < 1 {
+		limit = 1
+	}
+	return owner, limit, true
+}
+
 func (pe *PodEvictor) Evict(ctx context.Context, pod *corev1.Pod, opts framework.EvictOptions) bool {
 	framework.FillEvictOptionsFromContext(ctx, &opts)
 
 	nodeName := pod.Spec.NodeName
+	if evictedBy, ok := pe.evictedBy(pod); ok {
+		// the pod has been handled in this cycle, never call the API or consume the budgets again
+		metrics.PodsEvictionDeduplicated.With(map[string]string{"evicted_by": evictedBy, "strategy": opts.PluginName}).Inc()
+		klog.V(4).InfoS("Skip evicting pod evicted in the current cycle", "pod", klog.KObj(pod), "evictedBy", evictedBy, "strategy", opts.PluginName, "node", nodeName)
+		return true
+	}
+
 	if pe.NodeLimitExceeded(nodeName) {
 		metrics.PodsEvicted.With(map[string]string{"result": "maximum number of pods per node reached", "strategy": opts.PluginName, "namespace": pod.Namespace, "node": nodeName}).Inc()
 		klog.ErrorS(fmt.Errorf("maximum number of evicted pods per node reached"), "Error evicting pod", "limit", *pe.maxPodsToEvictPerNode, "node", nodeName)
@@ -132,35 +292,112 @@ func (pe *PodEvictor) Evict(ctx context.Context, pod *corev1.Pod, opts framework
 		return false
 	}
 
+	owner, ownerLimit, hasOwnerLimit := pe.ownerLimit(pod)
+	if hasOwnerLimit && pe.ownerEvicted(owner) >= ownerLimit {
+		metrics.PodsEvicted.With(map[string]string{"result": "maximum number of pods per owner reached", "strategy": opts.PluginName, "namespace": pod.Namespace, "node": nodeName}).Inc()
+		klog.ErrorS(fmt.Errorf("maximum number of evicted pods per owner reached"), "Error evicting pod", "limit", ownerLimit, "pod", klog.KObj(pod), "owner", owner)
+		return false
+	}
+
 	if pe.dryRun {
 		klog.V(1).InfoS("Evicted pod in dry run mode", "pod", klog.KObj(pod), "reason", opts.Reason, "strategy", opts.PluginName, "node", nodeName)
+		if pe.notifier != nil {
+			pe.notifier.Notify(pod, opts.PluginName, opts.Reason, true)
+		}
+	} else if pe.pacer != nil {
+		// the budgets are consumed once the eviction is queued, and given back if it fails
+		pe.account(pod, opts.PluginName, owner, hasOwnerLimit, 1)
+		pe.pacer.enqueue(&pacedEviction{evictor: pe, ctx: ctx, pod: pod, opts: opts, owner: owner, hasOwnerLimit: hasOwnerLimit})
+		klog.V(4).InfoS("Queued the eviction of pod", "pod", klog.KObj(pod), "strategy", opts.PluginName, "node", nodeName)
 	} else {
-		err := EvictPod(ctx, pe.client, pod, pe.policyGroupVersion, opts.DeleteOptions)
-		if err != nil {
-			// err is used only for logging purposes
-			klog.ErrorS(err, "Error evicting pod", "pod", klog.KObj(pod), "reason", opts.Reason)
-			metrics.PodsEvicted.With(map[string]string{"result": "error", "strategy": opts.PluginName, "namespace": pod.Namespace, "node": nodeName}).Inc()
+		if !pe.evictPod(ctx, pod, opts) {
 			return false
 		}
+		pe.account(pod, opts.PluginName, owner, hasOwnerLimit, 1)
+		pe.evicted(pod, opts)
+	}
+	return true
+}
 
-		func() {
-			pe.lock.Lock()
-			defer pe.lock.Unlock()
-			if pod.Spec.NodeName != "" {
-				pe.nodepodCount[pod.Spec.NodeName]++
-			}
-			pe.namespacePodCount[pod.Namespace]++
-			pe.totalCount++
-		}()
+// PlanEvict consumes the budgets for the eviction of the pod without evicting it, so that the evictions planned in a
+// descheduling cycle are limited as if they were performed. It returns false if any budget is exhausted.
+func (pe *PodEvictor) PlanEvict(pod *corev1.Pod, opts framework.EvictOptions) bool {
+	if _, ok := pe.evictedBy(pod); ok {
+		return true
+	}
+	owner, ownerLimit, hasOwnerLimit := pe.ownerLimit(pod)
+	if pe.NodeLimitExceeded(pod.Spec.NodeName) || pe.NamespaceLimitExceeded(pod.Namespace) ||
+		(hasOwnerLimit && pe.ownerEvicted(owner) >= ownerLimit) {
+		klog.V(4).InfoS("Skip planning to evict pod since the maximum number of evicted pods reached", "pod", klog.KObj(pod), "strategy", opts.PluginName, "node", pod.Spec.NodeName)
+		return false
+	}
+	pe.account(pod, opts.PluginName, owner, hasOwnerLimit, 1)
+	return true
+}
 
-		metrics.PodsEvicted.With(map[string]string{"result": "success", "strategy": opts.PluginName, "namespace": pod.Namespace, "node": nodeName}).Inc()
+// UnplanEvict gives back the budgets consumed by PlanEvict, before the planned eviction is performed by Evict.
+func (pe *PodEvictor) UnplanEvict(pod *corev1.Pod) {
+	if _, ok := pe.evictedBy(pod); !ok {
+		return
+	}
+	owner, _, hasOwnerLimit := pe.ownerLimit(pod)
+	pe.account(pod, "", owner, hasOwnerLimit, -1)
+}
+
+// account adds delta to the evicted counts of the pod, and records the plugin evicting it for a positive delta.
+func (pe *PodEvictor) account(pod *corev1.Pod, pluginName string, owner string, hasOwnerLimit bool, delta int) {
+	pe.counters.lock.Lock()
+	defer pe.counters.lock.Unlock()
+	if pod.Spec.NodeName != "" {
+		pe.counters.nodepodCount[pod.Spec.NodeName] += delta
+	}
+	pe.counters.namespacePodCount[pod.Namespace] += delta
+	if hasOwnerLimit {
+		pe.counters.ownerPodCount[owner] += delta
+	}
+	pe.counters.totalCount += delta
+	if delta > 0 {
+		pe.counters.evictedPods[evictedPodKey(pod)] = pluginName
+	} else {
+		delete(pe.counters.evictedPods, evictedPodKey(pod))
+	}
+}
 
-		klog.V(1).InfoS("Evicted pod", "pod", klog.KObj(pod), "reason", opts.Reason, "strategy", opts.PluginName, "node", nodeName)
-		pe.eventRecorder.Eventf(pod, nil, corev1.EventTypeNormal, "Descheduled", "Evicting", "pod evicted by %s", opts.Reason)
+func (pe *PodEvictor) evictPod(ctx context.Context, pod *corev1.Pod, opts framework.EvictOptions) bool {
+	err := EvictPod(ctx, pe.client, pod, pe.policyGroupVersion, opts.DeleteOptions)
+	if err != nil {
+		// err is used only for logging purposes
+		klog.ErrorS(err, "Error evicting pod", "pod", klog.KObj(pod), "reason", opts.Reason)
+		metrics.PodsEvicted.With(map[string]string{"result": "error", "strategy": opts.PluginName, "namespace": pod.Namespace, "node": pod.Spec.NodeName}).Inc()
+		return false
 	}
 	return true
 }
 
+func (pe *PodEvictor) evicted(pod *corev1.Pod, opts framework.EvictOptions) {
+	nodeName := pod.Spec.NodeName
+	metrics.PodsEvicted.With(map[string]string{"result": "success", "strategy": opts.PluginName, "namespace": pod.Namespace, "node": nodeName}).Inc()
+
+	klog.V(1).InfoS("Evicted pod", "pod", klog.KObj(pod), "reason", opts.Reason, "strategy", opts.PluginName, "node", nodeName)
+	pe.eventRecorder.Eventf(pod, nil, corev1.EventTypeNormal, "Descheduled", "Evicting", "pod evicted by %s", opts.Reason)
+	if pe.notifier != nil {
+		pe.notifier.Notify(pod, opts.PluginName, opts.Reason, false)
+	}
+}
+
+func (pe *PodEvictor) evictPaced(e *pacedEviction) {
+	if !pe.evictPod(e.ctx, e.pod, e.opts) {
+		pe.account(e.pod, e.opts.PluginName, e.owner, e.hasOwnerLimit, -1)
+		return
+	}
+	pe.evicted(e.pod, e.opts)
+}
+
+func (pe *PodEvictor) dropPaced(e *pacedEviction) {
+	metrics.PodsEvicted.With(map[string]string{"result": "canceled", "strategy": e.opts.PluginName, "namespace": e.pod.Namespace, "node": e.pod.Spec.NodeName}).Inc()
+	pe.account(e.pod, e.opts.PluginName, e.owner, e.hasOwnerLimit, -1)
+}
+
 func EvictPod(ctx context.Context, client clientset.Interface, pod *corev1.Pod, policyGroupVersion string, deleteOptions *metav1.DeleteOptions) error {
 	eviction := &policy.Eviction{
 		TypeMeta: metav1.TypeMeta{
@@ -26,13 +26,16 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics/testutil"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
 	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/utils"
@@ -871,4 +874,100 @@ func TestPodEvictor(t *testing.T) {
 		assert.False(t, result)
 		assert.Equal(t, 1, podEvictor.TotalEvicted())
 	})
+
+	t.Run("test evict limit stepped past", func(t *testing.T) {
+		podEvictor.maxPodsToEvictPerNode = pointer.Int(0)
+		podEvictor.maxPodsToEvictPerNamespace = pointer.Int(0)
+		assert.True(t, podEvictor.NodeLimitExceeded("test-node-1"))
+		assert.True(t, podEvictor.NamespaceLimitExceeded("default"))
+	})
+}
+
+func TestPodEvictorDeduplicate(t *testing.T) {
+	metrics.Register()
+	fakeRecorder := record.NewFakeRecorder(1024)
+	eventRecorder := record.NewEventRecorderAdapter(fakeRecorder)
+	fakeClient := fake.NewSimpleClientset()
+	podEvictor := NewPodEvictor(fakeClient, eventRecorder, "", false, nil, pointer.Int(4))
+
+	var pods []*corev1.Pod
+	for _, name := range []string{"pod-1", "pod-2", "pod-3"} {
+		pod := test.BuildTestPod(name, 400, 0, "test-node-1", func(pod *corev1.Pod) {
+			pod.UID = types.UID(pod.Name)
+		})
+		_, err := fakeClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		pods = append(pods, pod)
+	}
+	countEvictions := func() int {
+		count := 0
+		for _, action := range fakeClient.Actions() {
+			if action.GetVerb() == "create" && action.GetSubresource() == "eviction" {
+				count++
+			}
+		}
+		return count
+	}
+
+	nodeAffinityCtx := framework.PluginNameWithContext(context.TODO(), "RemovePodsViolatingNodeAffinity")
+	lowNodeLoadCtx := framework.PluginNameWithContext(context.TODO(), "LowNodeLoad")
+	// the first plugin targets pod-1 and pod-2, the second one targets pod-2 and pod-3
+	for _, pod := range pods[:2] {
+		assert.True(t, podEvictor.Evict(nodeAffinityCtx, pod, framework.EvictOptions{}))
+	}
+	for _, pod := range pods[1:] {
+		assert.True(t, podEvictor.Evict(lowNodeLoadCtx, pod, framework.EvictOptions{}))
+	}
+	assert.Equal(t, 3, countEvictions())
+	assert.Equal(t, 3, podEvictor.TotalEvicted())
+	assert.Equal(t, 3, podEvictor.NamespaceEvicted(pods[0].Namespace))
+	hits, err := testutil.GetCounterMetricValue(metrics.PodsEvictionDeduplicated.WithLabelValues("RemovePodsViolatingNodeAffinity", "LowNodeLoad"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), hits)
+
+	// the dedupe set is reset in the next cycle, so the evicted pod is handled again
+	podEvictor.ResetCycle()
+	assert.True(t, podEvictor.Evict(lowNodeLoadCtx, pods[0], framework.EvictOptions{}))
+	assert.Equal(t, 4, countEvictions())
+	assert.Equal(t, 4, podEvictor.TotalEvicted())
+	hits, err = testutil.GetCounterMetricValue(metrics.PodsEvictionDeduplicated.WithLabelValues("RemovePodsViolatingNodeAffinity", "LowNodeLoad"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), hits)
+}
+
+func TestPodEvictorPlanEvict(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(1024)
+	eventRecorder := record.NewEventRecorderAdapter(fakeRecorder)
+	fakeClient := fake.NewSimpleClientset()
+	podEvictor := NewPodEvictor(fakeClient, eventRecorder, "", false, pointer.Int(2), nil)
+
+	var pods []*corev1.Pod
+	for _, name := range []string{"pod-1", "pod-2", "pod-3"} {
+		pod := test.BuildTestPod(name, 400, 0, "test-node-1", func(pod *corev1.Pod) {
+			pod.UID = types.UID(pod.Name)
+		})
+		_, err := fakeClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		pods = append(pods, pod)
+	}
+
+	// the planned evictions consume the budgets without evicting the pods
+	assert.True(t, podEvictor.PlanEvict(pods[0], framework.EvictOptions{}))
+	assert.True(t, podEvictor.PlanEvict(pods[0], framework.EvictOptions{}))
+	assert.True(t, podEvictor.PlanEvict(pods[1], framework.EvictOptions{}))
+	assert.False(t, podEvictor.PlanEvict(pods[2], framework.EvictOptions{}))
+	assert.Equal(t, 2, podEvictor.NodeEvicted("test-node-1"))
+	for _, action := range fakeClient.Actions() {
+		assert.NotEqual(t, "eviction", action.GetSubresource())
+	}
+
+	// the budgets are given back before the planned evictions are performed
+	podEvictor.UnplanEvict(pods[0])
+	podEvictor.UnplanEvict(pods[1])
+	podEvictor.UnplanEvict(pods[2])
+	assert.Equal(t, 0, podEvictor.NodeEvicted("test-node-1"))
+	assert.Equal(t, 0, podEvictor.TotalEvicted())
+	assert.True(t, podEvictor.Evict(context.TODO(), pods[0], framework.EvictOptions{}))
+	assert.True(t, podEvictor.Evict(context.TODO(), pods[1], framework.EvictOptions{}))
+	assert.Equal(t, 2, podEvictor.TotalEvicted())
 }
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
+)
+
+const (
+	// EvictionNotificationVersion is the version of the schema of EvictionNotification.
+	// Bump it on any incompatible change of the payload.
+	EvictionNotificationVersion = "v1"
+
+	evictionNotificationQueueSize = 1024
+	evictionNotificationRetryWait = time.Second
+)
+
+// EvictionNotification is the JSON payload posted to the endpoint for an eviction.
+type EvictionNotification struct {
+	Version   string                  `json:"version"`
+	Pod       EvictionNotificationPod `json:"pod"`
+	Node      string                  `json:"node,omitempty"`
+	Plugin    string                  `json:"plugin,omitempty"`
+	Reason    string                  `json:"reason,omitempty"`
+	Timestamp time.Time               `json:"timestamp"`
+	DryRun    bool                    `json:"dryRun"`
+}
+
+type EvictionNotificationPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// EvictionNotifier delivers the EvictionNotifications to the endpoint in the background.
+// The notifications failed after the retries are dropped, and the deliveries are skipped while the
+// circuit breaker is open, so the evictions never wait for the endpoint.
+type EvictionNotifier struct {
+	url                  string
+	client               *http.Client
+	maxRetries           int
+	retryWait            time.Duration
+	failureThreshold     int
+	circuitBreakDuration time.Duration
+	clock                clock.Clock
+	queue                chan *EvictionNotification
+
+	lock                sync.Mutex
+	consecutiveFailures int
+	circuitOpenUntil    time.Time
+}
+
+// NewEvictionNotifier returns an EvictionNotifier and starts its delivering worker.
+func NewEvictionNotifier(args *deschedulerconfig.EvictionNotifierArgs) *EvictionNotifier {
+	n := newEvictionNotifier(args, clock.RealClock{}, evictionNotificationRetryWait)
+	go n.run()
+	return n
+}
+
+func newEvictionNotifier(args *deschedulerconfig.EvictionNotifierArgs, clock clock.Clock, retryWait time.Duration) *EvictionNotifier {
+	return &EvictionNotifier{
+		url:                  args.URL,
+		client:               &http.Client{Timeout: args.Timeout.Duration},
+		maxRetries:           int(args.MaxRetries),
+		retryWait:            retryWait,
+		failureThreshold:     int(args.FailureThreshold),
+		circuitBreakDuration: args.CircuitBreakDuration.Duration,
+		clock:                clock,
+		queue:                make(chan *EvictionNotification, evictionNotificationQueueSize),
+	}
+}
+
+// Notify enqueues the notification of the eviction without blocking.
+func (n *EvictionNotifier) Notify(pod *corev1.Pod, pluginName, reason string, dryRun bool) {
+	notification := &EvictionNotification{
+		Version: EvictionNotificationVersion,
+		Pod: EvictionNotificationPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       string(pod.UID),
+		},
+		Node:      pod.Spec.NodeName,
+		Plugin:    pluginName,
+		Reason:    reason,
+		Timestamp: n.clock.Now().UTC(),
+		DryRun:    dryRun,
+	}
+	select {
+	case n.queue <- notification:
+	default:
+		metrics.EvictionNotificationFailures.With(map[string]string{"reason": "queue_full"}).Inc()
+		klog.V(4).InfoS("Drop the eviction notification since the queue is full", "pod", klog.KObj(pod))
+	}
+}
+
+func (n *EvictionNotifier) run() {
+	for notification := range n.queue {
+		n.deliver(notification)
+	}
+}
+
+// deliver posts the notification with retries unless the circuit breaker is open.
+func (n *EvictionNotifier) deliver(notification *EvictionNotification) bool {
+	if n.circuitOpen() {
+		metrics.EvictionNotificationFailures.With(map[string]string{"reason": "circuit_open"}).Inc()
+		klog.V(4).InfoS("Drop the eviction notification since the circuit breaker is open",
+			"pod", klog.KRef(notification.Pod.Namespace, notification.Pod.Name))
+		return false
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		metrics.EvictionNotificationFailures.With(map[string]string{"reason": "error"}).Inc()
+		klog.ErrorS(err, "Failed to marshal the eviction notification", "pod", klog.KRef(notification.Pod.Namespace, notification.Pod.Name))
+		return false
+	}
+	for attempt := 0; attempt <= n.maxRetries; attempt++ {
+		if attempt > 0 {
+			n.clock.Sleep(n.retryWait)
+		}
+		if err = n.post(body); err == nil {
+			n.recordResult(true)
+			return true
+		}
+		klog.V(4).InfoS("Failed to deliver the eviction notification", "pod", klog.KRef(notification.Pod.Namespace, notification.Pod.Name),
+			"attempt", attempt+1, "err", err)
+	}
+	metrics.EvictionNotificationFailures.With(map[string]string{"reason": "error"}).Inc()
+	klog.ErrorS(err, "Failed to deliver the eviction notification after retries", "pod", klog.KRef(notification.Pod.Namespace, notification.Pod.Name))
+	n.recordResult(false)
+	return false
+}
+
+func (n *EvictionNotifier) post(body []byte) error {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *EvictionNotifier) circuitOpen() bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.clock.Now().Before(n.circuitOpenUntil)
+}
+
+// recordResult opens the circuit breaker after the consecutive failures reach the threshold. After the circuit
+// breaker is closed again, a single failure reopens it until a delivery succeeds.
+func (n *EvictionNotifier) recordResult(success bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if success {
+		n.consecutiveFailures = 0
+		return
+	}
+	n.consecutiveFailures++
+	if n.consecutiveFailures >= n.failureThreshold {
+		n.circuitOpenUntil = n.clock.Now().Add(n.circuitBreakDuration)
+		klog.Warningf("Open the circuit breaker of the eviction notifier for %v after %d consecutive failures",
+			n.circuitBreakDuration, n.consecutiveFailures)
+	}
+}
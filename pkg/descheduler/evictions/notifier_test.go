@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+type fakeNotificationEndpoint struct {
+	lock          sync.Mutex
+	statusCode    int
+	requests      int
+	notifications []*EvictionNotification
+}
+
+func (f *fakeNotificationEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.requests++
+	notification := &EvictionNotification{}
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+		json.NewDecoder(r.Body).Decode(notification) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if f.statusCode != http.StatusOK {
+		w.WriteHeader(f.statusCode)
+		return
+	}
+	f.notifications = append(f.notifications, notification)
+}
+
+func (f *fakeNotificationEndpoint) setStatusCode(statusCode int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.statusCode = statusCode
+}
+
+func (f *fakeNotificationEndpoint) get() (int, []*EvictionNotification) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.requests, append([]*EvictionNotification{}, f.notifications...)
+}
+
+func newTestNotifierArgs(url string) *deschedulerconfig.EvictionNotifierArgs {
+	return &deschedulerconfig.EvictionNotifierArgs{
+		URL:                  url,
+		Timeout:              metav1.Duration{Duration: time.Second},
+		MaxRetries:           2,
+		FailureThreshold:     2,
+		CircuitBreakDuration: metav1.Duration{Duration: time.Minute},
+	}
+}
+
+func TestEvictionNotifierNotify(t *testing.T) {
+	endpoint := &fakeNotificationEndpoint{statusCode: http.StatusOK}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notifier := newEvictionNotifier(newTestNotifierArgs(server.URL), clocktesting.NewFakeClock(now), 0)
+	go notifier.run()
+	defer close(notifier.queue)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod", UID: "test-uid"},
+		Spec:       corev1.PodSpec{NodeName: "test-node"},
+	}
+	notifier.Notify(pod, "LowNodeLoad", "node is overutilized", true)
+
+	var notifications []*EvictionNotification
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, notifications = endpoint.get()
+		return len(notifications) == 1, nil
+	})
+	assert.NoError(t, err)
+	expected := &EvictionNotification{
+		Version: EvictionNotificationVersion,
+		Pod: EvictionNotificationPod{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-uid",
+		},
+		Node:      "test-node",
+		Plugin:    "LowNodeLoad",
+		Reason:    "node is overutilized",
+		Timestamp: now,
+		DryRun:    true,
+	}
+	assert.Equal(t, expected, notifications[0])
+}
+
+func TestEvictionNotifierCircuitBreaker(t *testing.T) {
+	endpoint := &fakeNotificationEndpoint{statusCode: http.StatusServiceUnavailable}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	notifier := newEvictionNotifier(newTestNotifierArgs(server.URL), fakeClock, 0)
+	notification := &EvictionNotification{
+		Version: EvictionNotificationVersion,
+		Pod:     EvictionNotificationPod{Namespace: "default", Name: "test-pod"},
+	}
+
+	// each delivery tries once and retries twice
+	assert.False(t, notifier.deliver(notification))
+	requests, _ := endpoint.get()
+	assert.Equal(t, 3, requests)
+	assert.False(t, notifier.circuitOpen())
+
+	// the circuit breaker opens after the failure threshold
+	assert.False(t, notifier.deliver(notification))
+	requests, _ = endpoint.get()
+	assert.Equal(t, 6, requests)
+	assert.True(t, notifier.circuitOpen())
+
+	// the deliveries are skipped while the circuit breaker is open
+	assert.False(t, notifier.deliver(notification))
+	requests, _ = endpoint.get()
+	assert.Equal(t, 6, requests)
+
+	// a failure after the circuit breaker duration reopens it at once
+	fakeClock.Step(time.Minute)
+	assert.False(t, notifier.circuitOpen())
+	assert.False(t, notifier.deliver(notification))
+	requests, _ = endpoint.get()
+	assert.Equal(t, 9, requests)
+	assert.True(t, notifier.circuitOpen())
+
+	// a success closes the circuit breaker and resets the failures
+	fakeClock.Step(time.Minute)
+	endpoint.setStatusCode(http.StatusOK)
+	assert.True(t, notifier.deliver(notification))
+	assert.False(t, notifier.circuitOpen())
+	endpoint.setStatusCode(http.StatusServiceUnavailable)
+	assert.False(t, notifier.deliver(notification))
+	assert.False(t, notifier.circuitOpen())
+	requests, notifications := endpoint.get()
+	assert.Equal(t, 13, requests)
+	assert.Len(t, notifications, 1)
+}
+
+func TestEvictionNotifierNeverBlocks(t *testing.T) {
+	notifier := newEvictionNotifier(newTestNotifierArgs("http://127.0.0.1:0"), clocktesting.NewFakeClock(time.Now()), 0)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+	// no worker is running, the notifications beyond the queue size are dropped
+	for i := 0; i < evictionNotificationQueueSize+10; i++ {
+		notifier.Notify(pod, "test", "test", false)
+	}
+	assert.Equal(t, evictionNotificationQueueSize, len(notifier.queue))
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+// pacedEviction is an eviction accepted by the budgets and waiting in the queue of the pacer.
+type pacedEviction struct {
+	// evictor is the evictor accepting the eviction, which performs it.
+	evictor       *PodEvictor
+	ctx           context.Context
+	pod           *corev1.Pod
+	opts          framework.EvictOptions
+	owner         string
+	hasOwnerLimit bool
+	seq           int64
+}
+
+func (e *pacedEviction) priority() int32 {
+	if e.pod.Spec.Priority == nil {
+		return 0
+	}
+	return *e.pod.Spec.Priority
+}
+
+// evictionPacer performs the queued evictions one at a time by a worker, spacing them by the min interval with
+// jitter. The pod of the lowest priority in the queue is evicted first, and the pods of the same priority are
+// evicted in the order they are queued.
+type evictionPacer struct {
+	minInterval  time.Duration
+	jitterFactor float64
+	clock        clock.Clock
+
+	lock          sync.Mutex
+	queue         []*pacedEviction
+	seq           int64
+	lastEvictTime time.Time
+	// stopCh and doneCh belong to the running worker, and they are nil if no worker is running
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func newEvictionPacer(args *deschedulerconfig.EvictionPacingArgs, clock clock.Clock) *evictionPacer {
+	return &evictionPacer{
+		minInterval:  args.MinInterval.Duration,
+		jitterFactor: args.JitterFactor,
+		clock:        clock,
+	}
+}
+
+// enqueue queues the eviction and starts the worker if it is not running.
+func (p *evictionPacer) enqueue(e *pacedEviction) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.seq++
+	e.seq = p.seq
+	p.queue = append(p.queue, e)
+	if p.doneCh == nil {
+		p.stopCh, p.doneCh = make(chan struct{}), make(chan struct{})
+		go p.run(p.stopCh, p.doneCh)
+	}
+}
+
+func (p *evictionPacer) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+	for {
+		p.lock.Lock()
+		if len(p.queue) == 0 {
+			p.stopCh, p.doneCh = nil, nil
+			p.lock.Unlock()
+			return
+		}
+		var wait time.Duration
+		if !p.lastEvictTime.IsZero() {
+			wait = p.lastEvictTime.Add(p.nextInterval()).Sub(p.clock.Now())
+		}
+		p.lock.Unlock()
+
+		if wait > 0 {
+			select {
+			case <-p.clock.After(wait):
+			case <-stopCh:
+				p.dropAll()
+				return
+			}
+		}
+		select {
+		case <-stopCh:
+			p.dropAll()
+			return
+		default:
+		}
+
+		e := p.pop()
+		e.evictor.evictPaced(e)
+		p.lock.Lock()
+		p.lastEvictTime = p.clock.Now()
+		p.lock.Unlock()
+	}
+}
+
+func (p *evictionPacer) nextInterval() time.Duration {
+	interval := p.minInterval
+	if p.jitterFactor > 0 {
+		interval += time.Duration(rand.Float64() * p.jitterFactor * float64(p.minInterval))
+	}
+	return interval
+}
+
+// pop removes the eviction of the lowest priority from the queue, the earliest queued one first.
+func (p *evictionPacer) pop() *pacedEviction {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	next := 0
+	for i, e := range p.queue {
+		if e.priority() < p.queue[next].priority() ||
+			(e.priority() == p.queue[next].priority() && e.seq < p.queue[next].seq) {
+			next = i
+		}
+	}
+	e := p.queue[next]
+	p.queue = append(p.queue[:next], p.queue[next+1:]...)
+	return e
+}
+
+func (p *evictionPacer) dropAll() {
+	p.lock.Lock()
+	queue := p.queue
+	p.queue = nil
+	p.stopCh, p.doneCh = nil, nil
+	p.lock.Unlock()
+	for _, e := range queue {
+		klog.V(4).InfoS("Canceled the paced eviction of pod at the end of the cycle", "pod", klog.KObj(e.pod), "strategy", e.opts.PluginName)
+		e.evictor.dropPaced(e)
+	}
+}
+
+// flush waits until the queued evictions are performed, and cancels the rest if the context is done.
+func (p *evictionPacer) flush(ctx context.Context) {
+	p.lock.Lock()
+	doneCh := p.doneCh
+	p.lock.Unlock()
+	if doneCh == nil {
+		return
+	}
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+		p.cancel()
+	}
+}
+
+// cancel gives up the queued evictions and waits until the worker exits.
+func (p *evictionPacer) cancel() {
+	p.lock.Lock()
+	stopCh, doneCh := p.stopCh, p.doneCh
+	p.stopCh = nil
+	p.lock.Unlock()
+	if doneCh == nil {
+		return
+	}
+	if stopCh != nil {
+		close(stopCh)
+	}
+	<-doneCh
+}
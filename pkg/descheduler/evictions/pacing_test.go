@@ -0,0 +1,257 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+type pacedEvictionRecorder struct {
+	lock  sync.Mutex
+	names []string
+	times []time.Time
+}
+
+func (r *pacedEvictionRecorder) record(name string, t time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.names = append(r.names, name)
+	r.times = append(r.times, t)
+}
+
+func (r *pacedEvictionRecorder) get() ([]string, []time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.names...), append([]time.Time(nil), r.times...)
+}
+
+func newPacedPodEvictor(t *testing.T, fakeClock *clocktesting.FakeClock, failedPods ...string) (*PodEvictor, *pacedEvictionRecorder, []*corev1.Pod) {
+	fakeClient := fake.NewSimpleClientset()
+	recorder := &pacedEvictionRecorder{}
+	fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		name := action.(core.CreateAction).GetObject().(metav1.Object).GetName()
+		for _, failed := range failedPods {
+			if name == failed {
+				return true, nil, fmt.Errorf("injected error")
+			}
+		}
+		recorder.record(name, fakeClock.Now())
+		return false, nil, nil
+	})
+	podEvictor := NewPodEvictor(fakeClient, record.NewEventRecorderAdapter(record.NewFakeRecorder(1024)), "", false, nil, nil,
+		WithEvictionPacing(&deschedulerconfig.EvictionPacingArgs{MinInterval: metav1.Duration{Duration: time.Second}}))
+	podEvictor.pacer.clock = fakeClock
+
+	var pods []*corev1.Pod
+	for i, priority := range []int32{0, 100, 0, 50} {
+		pod := test.BuildTestPod(fmt.Sprintf("pod-%d", i), 400, 0, "test-node-1", func(pod *corev1.Pod) {
+			pod.UID = types.UID(pod.Name)
+			pod.Spec.Priority = pointer.Int32(priority)
+		})
+		_, err := fakeClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		pods = append(pods, pod)
+	}
+	return podEvictor, recorder, pods
+}
+
+func waitForPacedEvictions(t *testing.T, recorder *pacedEvictionRecorder, count int) {
+	assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		names, _ := recorder.get()
+		return len(names) >= count, nil
+	}))
+}
+
+func TestPodEvictorPacing(t *testing.T) {
+	start := time.Now()
+	fakeClock := clocktesting.NewFakeClock(start)
+	podEvictor, recorder, pods := newPacedPodEvictor(t, fakeClock)
+	ctx := context.TODO()
+
+	// the first eviction is performed at once
+	assert.True(t, podEvictor.Evict(ctx, pods[0], framework.EvictOptions{}))
+	waitForPacedEvictions(t, recorder, 1)
+
+	// the evictions are accepted by the budgets at once, and performed in the order of the priority later
+	for _, pod := range pods[1:] {
+		assert.True(t, podEvictor.Evict(ctx, pod, framework.EvictOptions{}))
+	}
+	assert.Equal(t, 4, podEvictor.TotalEvicted())
+	assert.Equal(t, 4, podEvictor.NodeEvicted("test-node-1"))
+	names, _ := recorder.get()
+	assert.Equal(t, []string{"pod-0"}, names)
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		podEvictor.FinishCycle(ctx)
+	}()
+	for i := 2; i <= 4; i++ {
+		select {
+		case <-finished:
+			t.Fatalf("the cycle finished with %d evictions", i-1)
+		default:
+		}
+		assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}))
+		fakeClock.Step(time.Second)
+		waitForPacedEvictions(t, recorder, i)
+	}
+	<-finished
+
+	names, times := recorder.get()
+	assert.Equal(t, []string{"pod-0", "pod-2", "pod-3", "pod-1"}, names)
+	for i := range times {
+		assert.Equal(t, start.Add(time.Duration(i)*time.Second), times[i])
+	}
+	assert.Equal(t, 4, podEvictor.TotalEvicted())
+}
+
+func TestPodEvictorPacingCancel(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	podEvictor, recorder, pods := newPacedPodEvictor(t, fakeClock, "pod-0")
+	ctx := context.TODO()
+
+	// the budget of the failed eviction is given back
+	assert.True(t, podEvictor.Evict(ctx, pods[0], framework.EvictOptions{}))
+	assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return podEvictor.TotalEvicted() == 0, nil
+	}))
+	_, evicted := podEvictor.evictedBy(pods[0])
+	assert.False(t, evicted)
+
+	// the queued evictions are canceled once the cycle is canceled
+	for _, pod := range pods[1:] {
+		assert.True(t, podEvictor.Evict(ctx, pod, framework.EvictOptions{}))
+	}
+	assert.Equal(t, 3, podEvictor.TotalEvicted())
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	podEvictor.FinishCycle(canceledCtx)
+	assert.Equal(t, 0, podEvictor.TotalEvicted())
+	assert.Equal(t, 0, podEvictor.NodeEvicted("test-node-1"))
+	assert.Equal(t, 0, podEvictor.NamespaceEvicted(pods[1].Namespace))
+	names, _ := recorder.get()
+	assert.Empty(t, names)
+
+	// the evictions left by the last cycle are canceled in the next cycle
+	assert.True(t, podEvictor.Evict(ctx, pods[1], framework.EvictOptions{}))
+	assert.True(t, podEvictor.Evict(ctx, pods[2], framework.EvictOptions{}))
+	assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}))
+	fakeClock.Step(time.Second)
+	waitForPacedEvictions(t, recorder, 1)
+	podEvictor.ResetCycle()
+	assert.Equal(t, 1, podEvictor.TotalEvicted())
+	names, _ = recorder.get()
+	assert.Equal(t, []string{"pod-2"}, names)
+}
+
+func TestPodEvictorPacingShared(t *testing.T) {
+	start := time.Now()
+	fakeClock := clocktesting.NewFakeClock(start)
+	podEvictor, recorder, pods := newPacedPodEvictor(t, fakeClock)
+	otherEvictor := NewPodEvictor(podEvictor.client, podEvictor.eventRecorder, "", false, nil, nil,
+		WithEvictionPacing(&deschedulerconfig.EvictionPacingArgs{MinInterval: metav1.Duration{Duration: time.Second}}))
+	ShareEvictionCounters(podEvictor, otherEvictor)
+	assert.Same(t, podEvictor.pacer, otherEvictor.pacer)
+	ctx := context.TODO()
+
+	// the evictions of both evictors are counted together and spaced by the same pacer
+	assert.True(t, podEvictor.Evict(ctx, pods[0], framework.EvictOptions{}))
+	waitForPacedEvictions(t, recorder, 1)
+	assert.True(t, otherEvictor.Evict(ctx, pods[2], framework.EvictOptions{}))
+	assert.Equal(t, 2, podEvictor.TotalEvicted())
+	assert.Equal(t, 2, otherEvictor.NodeEvicted("test-node-1"))
+	assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}))
+	fakeClock.Step(time.Second)
+	waitForPacedEvictions(t, recorder, 2)
+	otherEvictor.FinishCycle(ctx)
+
+	names, times := recorder.get()
+	assert.Equal(t, []string{"pod-0", "pod-2"}, names)
+	assert.Equal(t, []time.Time{start, start.Add(time.Second)}, times)
+}
+
+func TestPodEvictorPacingSharedLimits(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	podEvictor, recorder, pods := newPacedPodEvictor(t, fakeClock, "pod-0")
+	otherEvictor := NewPodEvictor(podEvictor.client, podEvictor.eventRecorder, "", false, pointer.Int(3), pointer.Int(3))
+	ShareEvictionCounters(podEvictor, otherEvictor)
+	ctx := context.TODO()
+
+	// the other profile checks the limits while the worker of the pacer updates the shared counters
+	stopCh, doneCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			otherEvictor.NodeLimitExceeded("test-node-1")
+			otherEvictor.NamespaceLimitExceeded(pods[0].Namespace)
+		}
+	}()
+
+	// the budget of the failed eviction of pod-0 is given back by the worker
+	for _, pod := range pods {
+		assert.True(t, podEvictor.Evict(ctx, pod, framework.EvictOptions{}))
+	}
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}))
+		fakeClock.Step(time.Second)
+		waitForPacedEvictions(t, recorder, i)
+	}
+	podEvictor.FinishCycle(ctx)
+	close(stopCh)
+	<-doneCh
+
+	assert.Equal(t, 3, podEvictor.TotalEvicted())
+	assert.True(t, otherEvictor.NodeLimitExceeded("test-node-1"))
+	assert.True(t, otherEvictor.NamespaceLimitExceeded(pods[0].Namespace))
+}
@@ -24,8 +24,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
 
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/evictions"
 	evictutils "github.com/koordinator-sh/koordinator/pkg/descheduler/evictions/utils"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
@@ -43,6 +45,9 @@ type DefaultEvictor struct {
 }
 
 var _ framework.Evictor = &DefaultEvictor{}
+var _ framework.EvictorCycleResetter = &DefaultEvictor{}
+var _ framework.EvictorCycleFinisher = &DefaultEvictor{}
+var _ framework.EvictionCounter = &DefaultEvictor{}
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	evictorArgs, ok := args.(*deschedulerconfig.DefaultEvictorArgs)
@@ -50,6 +55,10 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		return nil, fmt.Errorf("want args to be of type DefaultEvictorArgs, got %T", args)
 	}
 
+	if err := validation.ValidateDefaultEvictorArgs(nil, evictorArgs); err != nil {
+		return nil, err
+	}
+
 	nodesGetter := func() ([]*corev1.Node, error) {
 		nodesLister := handle.SharedInformerFactory().Core().V1().Nodes().Lister()
 		return nodesLister.List(labels.Everything())
@@ -90,6 +99,17 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		evictions.WithPriorityThreshold(priorityThreshold),
 	)
 
+	var podEvictorOpts []func(pe *evictions.PodEvictor)
+	if evictorArgs.MaxPercentageOfPodsToEvictPerOwner != nil {
+		podEvictorOpts = append(podEvictorOpts, evictions.WithMaxPercentageOfPodsToEvictPerOwner(
+			*evictorArgs.MaxPercentageOfPodsToEvictPerOwner, newOwnerReplicasGetter(handle)))
+	}
+	if evictorArgs.EvictionNotifier != nil {
+		podEvictorOpts = append(podEvictorOpts, evictions.WithEvictionNotifier(evictions.NewEvictionNotifier(evictorArgs.EvictionNotifier)))
+	}
+	if evictorArgs.EvictionPacing != nil {
+		podEvictorOpts = append(podEvictorOpts, evictions.WithEvictionPacing(evictorArgs.EvictionPacing))
+	}
 	podEvictor := evictions.NewPodEvictor(
 		handle.ClientSet(),
 		handle.EventRecorder(),
@@ -97,6 +117,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		evictorArgs.DryRun,
 		evictorArgs.MaxNoOfPodsToEvictPerNode,
 		evictorArgs.MaxNoOfPodsToEvictPerNamespace,
+		podEvictorOpts...,
 	)
 
 	return &DefaultEvictor{
@@ -111,13 +132,91 @@ func (d *DefaultEvictor) Name() string {
 }
 
 func (d *DefaultEvictor) Filter(pod *corev1.Pod) bool {
-	return d.evictorFilter.Filter(pod)
+	if !d.evictorFilter.Filter(pod) {
+		return false
+	}
+	// the other pods are preferred if the owner has been disrupted enough in this cycle
+	if d.evictor.OwnerLimitExceeded(pod) {
+		klog.V(4).InfoS("Pod is not evictable since the maximum number of evicted pods per owner reached", "pod", klog.KObj(pod))
+		return false
+	}
+	return true
 }
 
 func (d *DefaultEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
 	return d.evictor.Evict(ctx, pod, evictOptions)
 }
 
+func (d *DefaultEvictor) PlanEvict(pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	return d.evictor.PlanEvict(pod, evictOptions)
+}
+
+func (d *DefaultEvictor) UnplanEvict(pod *corev1.Pod) {
+	d.evictor.UnplanEvict(pod)
+}
+
+func (d *DefaultEvictor) ResetCycle() {
+	d.evictor.ResetCycle()
+}
+
+func (d *DefaultEvictor) FinishCycle(ctx context.Context) {
+	d.evictor.FinishCycle(ctx)
+}
+
+func (d *DefaultEvictor) TotalEvicted() int {
+	return d.evictor.TotalEvicted()
+}
+
 func (d *DefaultEvictor) PodEvictor() *evictions.PodEvictor {
 	return d.evictor
 }
+
+// newOwnerReplicasGetter returns the replicas of the controller of the pod,
+// and the pods of the ReplicaSets owned by a Deployment are accounted to the Deployment.
+func newOwnerReplicasGetter(handle framework.Handle) evictions.OwnerReplicasGetterFn {
+	replicaSetLister := handle.SharedInformerFactory().Apps().V1().ReplicaSets().Lister()
+	deploymentLister := handle.SharedInformerFactory().Apps().V1().Deployments().Lister()
+	statefulSetLister := handle.SharedInformerFactory().Apps().V1().StatefulSets().Lister()
+	replicationControllerLister := handle.SharedInformerFactory().Core().V1().ReplicationControllers().Lister()
+	return func(pod *corev1.Pod) (string, int32, bool) {
+		ownerRef := metav1.GetControllerOf(pod)
+		if ownerRef == nil {
+			return "", 0, false
+		}
+		var replicas *int32
+		owner := ownerRef.UID
+		switch ownerRef.Kind {
+		case "ReplicaSet":
+			replicaSet, err := replicaSetLister.ReplicaSets(pod.Namespace).Get(ownerRef.Name)
+			if err != nil || replicaSet.UID != ownerRef.UID {
+				return "", 0, false
+			}
+			replicas = replicaSet.Spec.Replicas
+			if deploymentRef := metav1.GetControllerOf(replicaSet); deploymentRef != nil && deploymentRef.Kind == "Deployment" {
+				deployment, err := deploymentLister.Deployments(pod.Namespace).Get(deploymentRef.Name)
+				if err == nil && deployment.UID == deploymentRef.UID {
+					owner, replicas = deployment.UID, deployment.Spec.Replicas
+				}
+			}
+		case "StatefulSet":
+			statefulSet, err := statefulSetLister.StatefulSets(pod.Namespace).Get(ownerRef.Name)
+			if err != nil || statefulSet.UID != ownerRef.UID {
+				return "", 0, false
+			}
+			replicas = statefulSet.Spec.Replicas
+		case "ReplicationController":
+			replicationController, err := replicationControllerLister.ReplicationControllers(pod.Namespace).Get(ownerRef.Name)
+			if err != nil || replicationController.UID != ownerRef.UID {
+				return "", 0, false
+			}
+			replicas = replicationController.Spec.Replicas
+		default:
+			return "", 0, false
+		}
+		// the replicas defaults to 1
+		if replicas == nil {
+			return string(owner), 1, true
+		}
+		return string(owner), *replicas, true
+	}
+}
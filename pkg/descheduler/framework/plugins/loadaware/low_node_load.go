@@ -19,6 +19,7 @@ package loadaware
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -49,6 +50,7 @@ const (
 )
 
 var _ framework.BalancePlugin = &LowNodeLoad{}
+var _ framework.NodeClassifier = &LowNodeLoad{}
 
 // LowNodeLoad evicts pods from overutilized nodes to underutilized nodes.
 // Note that the plugin refers to the actual usage of the node.
@@ -58,8 +60,19 @@ type LowNodeLoad struct {
 	nodeMetricLister     koordslolisters.NodeMetricLister
 	args                 *deschedulerconfig.LowNodeLoadArgs
 	nodeAnomalyDetectors *gocache.Cache
+	// nodeClassification is the classification of the nodes made in the last run, indexed by the node name.
+	nodeClassification    map[string]nodeClass
+	classificationChanged bool
 }
 
+type nodeClass string
+
+const (
+	nodeClassLow    nodeClass = "low"
+	nodeClassNormal nodeClass = "normal"
+	nodeClassHigh   nodeClass = "high"
+)
+
 // NewLowNodeLoad builds plugin from its arguments while passing a handle
 func NewLowNodeLoad(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	loadLoadUtilizationArgs, ok := args.(*deschedulerconfig.LowNodeLoadArgs)
@@ -134,6 +147,7 @@ func (pl *LowNodeLoad) Name() string {
 
 // Balance extension point implementation for the plugin
 func (pl *LowNodeLoad) Balance(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	pl.classificationChanged = false
 	if pl.args.Paused {
 		klog.Infof("LowNodeLoad is paused and will do nothing.")
 		return nil
@@ -153,6 +167,7 @@ func (pl *LowNodeLoad) Balance(ctx context.Context, nodes []*corev1.Node) *frame
 	nodeUsages := getNodeUsage(nodes, resourceNames, pl.nodeMetricLister, pl.handle.GetPodsAssignedToNodeFunc())
 	nodeThresholds := getNodeThresholds(nodeUsages, lowThresholds, highThresholds, resourceNames, pl.args.UseDeviationThresholds)
 	lowNodes, sourceNodes := classifyNodes(nodeUsages, nodeThresholds, lowThresholdFilter, highThresholdFilter)
+	pl.recordNodeClassification(nodeUsages, lowNodes, sourceNodes)
 
 	logUtilizationCriteria("Criteria for a node under low thresholds", lowThresholds, len(lowNodes))
 	logUtilizationCriteria("Criteria for a node above high thresholds", highThresholds, len(sourceNodes))
@@ -220,6 +235,28 @@ func (pl *LowNodeLoad) Balance(ctx context.Context, nodes []*corev1.Node) *frame
 	return nil
 }
 
+// NodeClassificationChanged reports whether the nodes are classified differently from the run before the last one.
+func (pl *LowNodeLoad) NodeClassificationChanged() bool {
+	return pl.classificationChanged
+}
+
+// recordNodeClassification records the classification of the nodes. The first classification is not regarded as
+// a change since there is nothing to compare with.
+func (pl *LowNodeLoad) recordNodeClassification(nodeUsages map[string]*NodeUsage, lowNodes, highNodes []NodeInfo) {
+	classification := make(map[string]nodeClass, len(nodeUsages))
+	for _, v := range nodeUsages {
+		classification[v.node.Name] = nodeClassNormal
+	}
+	for _, v := range lowNodes {
+		classification[v.node.Name] = nodeClassLow
+	}
+	for _, v := range highNodes {
+		classification[v.node.Name] = nodeClassHigh
+	}
+	pl.classificationChanged = pl.nodeClassification != nil && !reflect.DeepEqual(pl.nodeClassification, classification)
+	pl.nodeClassification = classification
+}
+
 func markNormalNodes(lowNodes []NodeInfo, nodeAnomalyDetectors *gocache.Cache) {
 	for _, v := range lowNodes {
 		if obj, ok := nodeAnomalyDetectors.Get(v.node.Name); ok {
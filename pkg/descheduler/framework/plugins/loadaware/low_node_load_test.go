@@ -1316,3 +1316,25 @@ func Test_filterRealAbnormalNodes(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordNodeClassification(t *testing.T) {
+	buildNodeInfo := func(name string) NodeInfo {
+		return NodeInfo{NodeUsage: &NodeUsage{node: test.BuildTestNode(name, 4000, 3000, 10, nil)}}
+	}
+	nodeA, nodeB, nodeC := buildNodeInfo("node-a"), buildNodeInfo("node-b"), buildNodeInfo("node-c")
+	nodeUsages := map[string]*NodeUsage{
+		"node-a": nodeA.NodeUsage,
+		"node-b": nodeB.NodeUsage,
+		"node-c": nodeC.NodeUsage,
+	}
+
+	pl := &LowNodeLoad{}
+	pl.recordNodeClassification(nodeUsages, []NodeInfo{nodeA}, []NodeInfo{nodeC})
+	assert.False(t, pl.NodeClassificationChanged(), "the first classification is not a change")
+	pl.recordNodeClassification(nodeUsages, []NodeInfo{nodeA}, []NodeInfo{nodeC})
+	assert.False(t, pl.NodeClassificationChanged())
+	pl.recordNodeClassification(nodeUsages, []NodeInfo{nodeA}, []NodeInfo{nodeB, nodeC})
+	assert.True(t, pl.NodeClassificationChanged())
+	pl.recordNodeClassification(nodeUsages, []NodeInfo{nodeA}, []NodeInfo{nodeB, nodeC})
+	assert.False(t, pl.NodeClassificationChanged())
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecondition
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+)
+
+const PluginName = "NodeConditionMigration"
+
+// NodeConditionMigration migrates pods off the nodes having the conditions of the degraded hardware, e.g. the
+// correctable ECC storms or the NIC flaps surfaced by node-problem-detector, at a bounded rate until the conditions
+// clear.
+type NodeConditionMigration struct {
+	handle    framework.Handle
+	args      *deschedulerconfig.NodeConditionMigrationArgs
+	podFilter podutil.FilterFunc
+	clock     clock.Clock
+}
+
+var _ framework.Plugin = &NodeConditionMigration{}
+var _ framework.DeschedulePlugin = &NodeConditionMigration{}
+
+func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	conditionArgs, ok := args.(*deschedulerconfig.NodeConditionMigrationArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type NodeConditionMigrationArgs, got %T", args)
+	}
+
+	if err := validation.ValidateNodeConditionMigrationArgs(nil, conditionArgs); err != nil {
+		return nil, err
+	}
+
+	var includedNamespaces, excludedNamespaces sets.String
+	if conditionArgs.Namespaces != nil {
+		includedNamespaces = sets.NewString(conditionArgs.Namespaces.Include...)
+		excludedNamespaces = sets.NewString(conditionArgs.Namespaces.Exclude...)
+	}
+
+	podFilter, err := podutil.NewOptions().
+		WithNamespaces(includedNamespaces).
+		WithoutNamespaces(excludedNamespaces).
+		WithLabelSelector(conditionArgs.LabelSelector).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	return &NodeConditionMigration{
+		handle:    handle,
+		args:      conditionArgs,
+		podFilter: podFilter,
+		clock:     clock.RealClock{},
+	}, nil
+}
+
+func (d *NodeConditionMigration) Name() string {
+	return PluginName
+}
+
+// Deschedule checks the conditions of the nodes in each cycle, so the migrations stop once the conditions clear,
+// while the pods already handed to the migration go on.
+func (d *NodeConditionMigration) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	now := d.clock.Now()
+	for _, node := range nodes {
+		condition := d.getDegradedCondition(node, now)
+		if condition == nil {
+			continue
+		}
+		d.migrateNode(ctx, node, condition)
+	}
+	return nil
+}
+
+// getDegradedCondition returns the first condition of the node matching the args which has lasted for the debounce
+// duration, nil if none.
+func (d *NodeConditionMigration) getDegradedCondition(node *corev1.Node, now time.Time) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		condition := &node.Status.Conditions[i]
+		for _, selector := range d.args.Conditions {
+			if condition.Type != selector.Type || condition.Status != selector.Status {
+				continue
+			}
+			if now.Sub(condition.LastTransitionTime.Time) < d.args.DebounceDuration.Duration {
+				klog.V(4).InfoS("Node condition is too young to migrate pods", "node", klog.KObj(node),
+					"condition", condition.Type, "status", condition.Status, "lastTransitionTime", condition.LastTransitionTime)
+				continue
+			}
+			return condition
+		}
+	}
+	return nil
+}
+
+func (d *NodeConditionMigration) migrateNode(ctx context.Context, node *corev1.Node, condition *corev1.NodeCondition) {
+	// the pods being migrated are filtered out by the Evictor
+	pods, err := podutil.ListEvictablePodsOnANode(d.handle, node.Name, d.podFilter)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get pods", "node", klog.KObj(node))
+		return
+	}
+	if len(pods) == 0 {
+		return
+	}
+
+	budget := int(d.args.MaxMigratingPerNode)
+	klog.V(4).InfoS("Migrating pods off node with degraded condition", "node", klog.KObj(node),
+		"condition", condition.Type, "status", condition.Status, "evictable", len(pods), "budget", budget)
+
+	podutil.SortPodsBasedOnPriorityLowToHigh(pods)
+	jobCtx := migration.WithContext(ctx, &migration.JobContext{
+		Mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+	})
+	reason := fmt.Sprintf("node condition %s is %s since %s", condition.Type, condition.Status,
+		condition.LastTransitionTime.UTC().Format(time.RFC3339))
+	for _, pod := range pods {
+		if budget <= 0 {
+			break
+		}
+		klog.V(1).InfoS("Evicting pod", "pod", klog.KObj(pod), "node", klog.KObj(node))
+		if d.handle.Evictor().Evict(jobCtx, pod, framework.EvictOptions{Reason: reason}) {
+			budget--
+		}
+	}
+}
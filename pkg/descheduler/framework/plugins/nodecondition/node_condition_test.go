@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecondition
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	frameworkruntime "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
+	frameworktesting "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/testing"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+const testConditionType corev1.NodeConditionType = "CorrectableMemoryErrors"
+
+// fakeEvictor filters out the evicted pods as the MigrationController filters out the pods being migrated
+type fakeEvictor struct {
+	evicted  sets.String
+	reasons  []string
+	jobModes []sev1alpha1.PodMigrationJobMode
+}
+
+func (f *fakeEvictor) Name() string {
+	return "FakeEvictor"
+}
+
+func (f *fakeEvictor) Filter(pod *corev1.Pod) bool {
+	return !f.evicted.Has(pod.Name)
+}
+
+func (f *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	f.evicted.Insert(pod.Name)
+	f.reasons = append(f.reasons, evictOptions.Reason)
+	if jobCtx := migration.FromContext(ctx); jobCtx != nil {
+		f.jobModes = append(f.jobModes, jobCtx.Mode)
+	}
+	return true
+}
+
+func setNodeCondition(node *corev1.Node, status corev1.ConditionStatus, lastTransitionTime time.Time) {
+	node.Status.Conditions = []corev1.NodeCondition{
+		{
+			Type:               corev1.NodeReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(lastTransitionTime.Add(-time.Hour)),
+		},
+		{
+			Type:               testConditionType,
+			Status:             status,
+			LastTransitionTime: metav1.NewTime(lastTransitionTime),
+		},
+	}
+}
+
+func newTestPlugin(t *testing.T, ctx context.Context, evictor *fakeEvictor, objs ...runtime.Object) *NodeConditionMigration {
+	fakeClient := fake.NewSimpleClientset(objs...)
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	podInformer := sharedInformerFactory.Core().V1().Pods()
+	getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(podInformer)
+	assert.NoError(t, err)
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	fh, err := frameworktesting.NewFramework(
+		[]frameworktesting.RegisterPluginFunc{
+			frameworktesting.RegisterEvictorPlugin(evictor.Name(), func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+				return evictor, nil
+			}),
+		},
+		"test",
+		frameworkruntime.WithClientSet(fakeClient),
+		frameworkruntime.WithSharedInformerFactory(sharedInformerFactory),
+		frameworkruntime.WithGetPodsAssignedToNodeFunc(getPodsAssignedToNode),
+	)
+	assert.NoError(t, err)
+
+	args := &deschedulerconfig.NodeConditionMigrationArgs{
+		Conditions: []deschedulerconfig.NodeConditionSelector{
+			{Type: testConditionType, Status: corev1.ConditionTrue},
+		},
+		DebounceDuration:    metav1.Duration{Duration: 5 * time.Minute},
+		MaxMigratingPerNode: 2,
+	}
+	plugin, err := New(args, fh)
+	assert.NoError(t, err)
+	return plugin.(*NodeConditionMigration)
+}
+
+func TestDescheduleWithDegradedCondition(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+
+	node := test.BuildTestNode("node-1", 32000, 64000, 100, func(node *corev1.Node) {
+		setNodeCondition(node, corev1.ConditionTrue, now)
+	})
+	healthyNode := test.BuildTestNode("node-2", 32000, 64000, 100, func(node *corev1.Node) {
+		setNodeCondition(node, corev1.ConditionFalse, now.Add(-time.Hour))
+	})
+	nodes := []*corev1.Node{node, healthyNode}
+	objs := []runtime.Object{node, healthyNode}
+	for i := 0; i < 5; i++ {
+		objs = append(objs, test.BuildTestPod(fmt.Sprintf("pod-%d", i), 100, 0, node.Name, test.SetNormalOwnerRef))
+	}
+	objs = append(objs, test.BuildTestPod("pod-on-healthy-node", 100, 0, healthyNode.Name, test.SetNormalOwnerRef))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evictor := &fakeEvictor{evicted: sets.NewString()}
+	plugin := newTestPlugin(t, ctx, evictor, objs...)
+	plugin.clock = fakeClock
+
+	steps := []struct {
+		elapsed     time.Duration
+		wantEvicted int
+	}{
+		// the condition is too young
+		{elapsed: time.Minute, wantEvicted: 0},
+		{elapsed: 5 * time.Minute, wantEvicted: 2},
+		{elapsed: 6 * time.Minute, wantEvicted: 4},
+		{elapsed: 7 * time.Minute, wantEvicted: 5},
+		{elapsed: 8 * time.Minute, wantEvicted: 5},
+	}
+	for _, step := range steps {
+		fakeClock.SetTime(now.Add(step.elapsed))
+		status := plugin.Deschedule(ctx, nodes)
+		assert.Nil(t, status)
+		assert.Equal(t, step.wantEvicted, evictor.evicted.Len(), "elapsed %v", step.elapsed)
+	}
+	assert.False(t, evictor.evicted.Has("pod-on-healthy-node"))
+	for _, mode := range evictor.jobModes {
+		assert.Equal(t, sev1alpha1.PodMigrationJobModeReservationFirst, mode)
+	}
+	for _, reason := range evictor.reasons {
+		assert.Contains(t, reason, string(testConditionType))
+	}
+}
+
+func TestDescheduleStopsWhenConditionCleared(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+
+	node := test.BuildTestNode("node-1", 32000, 64000, 100, func(node *corev1.Node) {
+		setNodeCondition(node, corev1.ConditionTrue, now.Add(-10*time.Minute))
+	})
+	objs := []runtime.Object{node}
+	for i := 0; i < 6; i++ {
+		objs = append(objs, test.BuildTestPod(fmt.Sprintf("pod-%d", i), 100, 0, node.Name, test.SetNormalOwnerRef))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evictor := &fakeEvictor{evicted: sets.NewString()}
+	plugin := newTestPlugin(t, ctx, evictor, objs...)
+	plugin.clock = fakeClock
+
+	plugin.Deschedule(ctx, []*corev1.Node{node})
+	assert.Equal(t, 2, evictor.evicted.Len())
+
+	// the condition clears in the middle of the drain
+	clearedNode := node.DeepCopy()
+	setNodeCondition(clearedNode, corev1.ConditionFalse, now.Add(time.Minute))
+	for _, elapsed := range []time.Duration{2 * time.Minute, 20 * time.Minute} {
+		fakeClock.SetTime(now.Add(elapsed))
+		plugin.Deschedule(ctx, []*corev1.Node{clearedNode})
+	}
+	assert.Equal(t, 2, evictor.evicted.Len())
+
+	// the condition comes back, it is debounced again from the new transition
+	degradedAgainNode := node.DeepCopy()
+	setNodeCondition(degradedAgainNode, corev1.ConditionTrue, now.Add(20*time.Minute))
+	fakeClock.SetTime(now.Add(22 * time.Minute))
+	plugin.Deschedule(ctx, []*corev1.Node{degradedAgainNode})
+	assert.Equal(t, 2, evictor.evicted.Len())
+
+	fakeClock.SetTime(now.Add(25 * time.Minute))
+	plugin.Deschedule(ctx, []*corev1.Node{degradedAgainNode})
+	assert.Equal(t, 4, evictor.evicted.Len())
+}
+
+func TestNewWithInvalidArgs(t *testing.T) {
+	_, err := New(&deschedulerconfig.NodeConditionMigrationArgs{MaxMigratingPerNode: 1}, nil)
+	assert.Error(t, err)
+}
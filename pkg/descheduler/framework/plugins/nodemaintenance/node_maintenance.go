@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Koordinator Authors.
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemaintenance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+)
+
+const PluginName = "NodeMaintenanceMigration"
+
+const (
+	reasonMaintenanceMigrationOverdue = "MaintenanceMigrationOverdue"
+)
+
+// NodeMaintenanceMigration migrates pods out of the nodes gradually before their maintenance windows start,
+// instead of draining the nodes at the deadline.
+type NodeMaintenanceMigration struct {
+	handle    framework.Handle
+	args      *deschedulerconfig.NodeMaintenanceMigrationArgs
+	podFilter podutil.FilterFunc
+	clock     clock.Clock
+	// reported records the start of the maintenance window whose overdue pods have been reported for each node
+	reported map[string]time.Time
+}
+
+var _ framework.Plugin = &NodeMaintenanceMigration{}
+var _ framework.DeschedulePlugin = &NodeMaintenanceMigration{}
+
+func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	maintenanceArgs, ok := args.(*deschedulerconfig.NodeMaintenanceMigrationArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type NodeMaintenanceMigrationArgs, got %T", args)
+	}
+
+	if err := validation.ValidateNodeMaintenanceMigrationArgs(nil, maintenanceArgs); err != nil {
+		return nil, err
+	}
+
+	var includedNamespaces, excludedNamespaces sets.String
+	if maintenanceArgs.Namespaces != nil {
+		includedNamespaces = sets.NewString(maintenanceArgs.Namespaces.Include...)
+		excludedNamespaces = sets.NewString(maintenanceArgs.Namespaces.Exclude...)
+	}
+
+	podFilter, err := podutil.NewOptions().
+		WithNamespaces(includedNamespaces).
+		WithoutNamespaces(excludedNamespaces).
+		WithLabelSelector(maintenanceArgs.LabelSelector).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	return &NodeMaintenanceMigration{
+		handle:    handle,
+		args:      maintenanceArgs,
+		podFilter: podFilter,
+		clock:     clock.RealClock{},
+		reported:  map[string]time.Time{},
+	}, nil
+}
+
+func (d *NodeMaintenanceMigration) Name() string {
+	return PluginName
+}
+
+func (d *NodeMaintenanceMigration) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	now := d.clock.Now()
+	inMaintenance := sets.NewString()
+	for _, node := range nodes {
+		window, err := extension.GetNodeMaintenanceWindow(node.Annotations)
+		if err != nil {
+			klog.ErrorS(err, "Failed to parse maintenance window", "node", klog.KObj(node))
+			continue
+		}
+		if window == nil || (window.End != nil && !now.Before(window.End.Time)) {
+			continue
+		}
+		inMaintenance.Insert(node.Name)
+		d.migrateNode(ctx, node, window, now)
+	}
+
+	// forget the nodes whose maintenance window is removed or has ended
+	for nodeName := range d.reported {
+		if !inMaintenance.Has(nodeName) {
+			delete(d.reported, nodeName)
+		}
+	}
+	return nil
+}
+
+func (d *NodeMaintenanceMigration) migrateNode(ctx context.Context, node *corev1.Node, window *extension.NodeMaintenanceWindow, now time.Time) {
+	timeLeft := window.Start.Sub(now)
+	if timeLeft <= 0 {
+		d.reportOverduePods(node, window)
+		return
+	}
+
+	// the pods being migrated are filtered out by the Evictor
+	pods, err := podutil.ListEvictablePodsOnANode(d.handle, node.Name, d.podFilter)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get pods", "node", klog.KObj(node))
+		return
+	}
+	if len(pods) == 0 {
+		return
+	}
+
+	budget := evictionBudget(len(pods), timeLeft, d.args.CycleInterval.Duration)
+	klog.V(4).InfoS("Migrating pods before maintenance window", "node", klog.KObj(node),
+		"windowStart", window.Start, "timeLeft", timeLeft, "evictable", len(pods), "budget", budget)
+
+	podutil.SortPodsBasedOnPriorityLowToHigh(pods)
+	jobCtx := migration.WithContext(ctx, &migration.JobContext{
+		Mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+	})
+	reason := fmt.Sprintf("node enters maintenance window at %s", window.Start.UTC().Format(time.RFC3339))
+	for _, pod := range pods {
+		if budget <= 0 {
+			break
+		}
+		klog.V(1).InfoS("Evicting pod", "pod", klog.KObj(pod), "node", klog.KObj(node))
+		if d.handle.Evictor().Evict(jobCtx, pod, framework.EvictOptions{Reason: reason}) {
+			budget--
+		}
+	}
+}
+
+// evictionBudget spreads the migrations of the remaining pods evenly across the cycles left before the deadline.
+// All the remaining pods are migrated in the last cycle.
+func evictionBudget(remaining int, timeLeft, cycleInterval time.Duration) int {
+	cycles := math.Ceil(float64(timeLeft) / float64(cycleInterval))
+	if cycles < 1 {
+		cycles = 1
+	}
+	return int(math.Ceil(float64(remaining) / cycles))
+}
+
+// reportOverduePods sends an event on the node for the pods which are not migrated when the maintenance window
+// starts. It is reported once for each maintenance window.
+func (d *NodeMaintenanceMigration) reportOverduePods(node *corev1.Node, window *extension.NodeMaintenanceWindow) {
+	if reportedStart, ok := d.reported[node.Name]; ok && reportedStart.Equal(window.Start.Time) {
+		return
+	}
+
+	pods, err := podutil.ListPodsOnANode(
+		node.Name,
+		d.handle.GetPodsAssignedToNodeFunc(),
+		podutil.WrapFilterFuncs(d.podFilter, func(pod *corev1.Pod) bool {
+			return !isDaemonSetPod(pod)
+		}),
+	)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get pods", "node", klog.KObj(node))
+		return
+	}
+	d.reported[node.Name] = window.Start.Time
+	if len(pods) == 0 {
+		return
+	}
+
+	podNames := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		podNames = append(podNames, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+	}
+	klog.InfoS("Pods are not migrated before maintenance window starts", "node", klog.KObj(node), "pods", podNames)
+	d.handle.EventRecorder().Eventf(node, nil, corev1.EventTypeWarning, reasonMaintenanceMigrationOverdue, "Migrating",
+		"%d pods are not migrated before maintenance window starts at %s: %s",
+		len(pods), window.Start.UTC().Format(time.RFC3339), strings.Join(podNames, ", "))
+}
+
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	for _, ownerRef := range podutil.OwnerRef(pod) {
+		if ownerRef.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Koordinator Authors.
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemaintenance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	frameworkruntime "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
+	frameworktesting "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/testing"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+// fakeEvictor filters out the evicted pods as the MigrationController filters out the pods being migrated
+type fakeEvictor struct {
+	evicted     sets.String
+	unevictable sets.String
+	jobModes    []sev1alpha1.PodMigrationJobMode
+}
+
+func (f *fakeEvictor) Name() string {
+	return "FakeEvictor"
+}
+
+func (f *fakeEvictor) Filter(pod *corev1.Pod) bool {
+	return !f.evicted.Has(pod.Name) && !f.unevictable.Has(pod.Name)
+}
+
+func (f *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	f.evicted.Insert(pod.Name)
+	if jobCtx := migration.FromContext(ctx); jobCtx != nil {
+		f.jobModes = append(f.jobModes, jobCtx.Mode)
+	}
+	return true
+}
+
+func setMaintenanceWindow(node *corev1.Node, start time.Time) {
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[extension.AnnotationNodeMaintenanceWindow] = fmt.Sprintf(`{"start":%q}`, start.UTC().Format(time.RFC3339))
+}
+
+func newTestPlugin(t *testing.T, ctx context.Context, evictor *fakeEvictor, recorder events.EventRecorder, objs ...runtime.Object) *NodeMaintenanceMigration {
+	fakeClient := fake.NewSimpleClientset(objs...)
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	podInformer := sharedInformerFactory.Core().V1().Pods()
+	getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(podInformer)
+	assert.NoError(t, err)
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	fh, err := frameworktesting.NewFramework(
+		[]frameworktesting.RegisterPluginFunc{
+			frameworktesting.RegisterEvictorPlugin(evictor.Name(), func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+				return evictor, nil
+			}),
+		},
+		"test",
+		frameworkruntime.WithClientSet(fakeClient),
+		frameworkruntime.WithSharedInformerFactory(sharedInformerFactory),
+		frameworkruntime.WithGetPodsAssignedToNodeFunc(getPodsAssignedToNode),
+		frameworkruntime.WithEventRecorder(recorder),
+	)
+	assert.NoError(t, err)
+
+	args := &deschedulerconfig.NodeMaintenanceMigrationArgs{
+		CycleInterval: metav1.Duration{Duration: 10 * time.Minute},
+	}
+	plugin, err := New(args, fh)
+	assert.NoError(t, err)
+	return plugin.(*NodeMaintenanceMigration)
+}
+
+func TestDescheduleWithShrinkingTimeBudget(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+
+	node := test.BuildTestNode("node-1", 32000, 64000, 100, func(node *corev1.Node) {
+		setMaintenanceWindow(node, now.Add(60*time.Minute))
+	})
+	otherNode := test.BuildTestNode("node-2", 32000, 64000, 100, nil)
+	nodes := []*corev1.Node{node, otherNode}
+	objs := []runtime.Object{node, otherNode}
+	for i := 0; i < 12; i++ {
+		objs = append(objs, test.BuildTestPod(fmt.Sprintf("pod-%d", i), 100, 0, node.Name, test.SetNormalOwnerRef))
+	}
+	objs = append(objs, test.BuildTestPod("pod-on-other-node", 100, 0, otherNode.Name, test.SetNormalOwnerRef))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evictor := &fakeEvictor{evicted: sets.NewString(), unevictable: sets.NewString()}
+	recorder := events.NewFakeRecorder(10)
+	plugin := newTestPlugin(t, ctx, evictor, recorder, objs...)
+	plugin.clock = fakeClock
+
+	steps := []struct {
+		elapsed     time.Duration
+		wantEvicted int
+	}{
+		// 12 pods in 6 cycles
+		{elapsed: 0, wantEvicted: 2},
+		// 10 pods in 5 cycles
+		{elapsed: 10 * time.Minute, wantEvicted: 4},
+		// the cycles are delayed, 8 pods in 2 cycles
+		{elapsed: 40 * time.Minute, wantEvicted: 8},
+		// the last cycle before the deadline migrates all the rest
+		{elapsed: 55 * time.Minute, wantEvicted: 12},
+	}
+	for _, step := range steps {
+		fakeClock.SetTime(now.Add(step.elapsed))
+		status := plugin.Deschedule(ctx, nodes)
+		assert.Nil(t, status)
+		assert.Equal(t, step.wantEvicted, evictor.evicted.Len(), "elapsed %v", step.elapsed)
+	}
+	assert.False(t, evictor.evicted.Has("pod-on-other-node"))
+	for _, mode := range evictor.jobModes {
+		assert.Equal(t, sev1alpha1.PodMigrationJobModeReservationFirst, mode)
+	}
+	// nothing is reported before the window starts
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestDescheduleReportsOverduePods(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+
+	node := test.BuildTestNode("node-1", 32000, 64000, 100, func(node *corev1.Node) {
+		setMaintenanceWindow(node, now.Add(5*time.Minute))
+	})
+	nodes := []*corev1.Node{node}
+	objs := []runtime.Object{
+		node,
+		test.BuildTestPod("pod-1", 100, 0, node.Name, test.SetNormalOwnerRef),
+		test.BuildTestPod("unevictable-pod", 100, 0, node.Name, test.SetNormalOwnerRef),
+		test.BuildTestPod("daemonset-pod", 100, 0, node.Name, test.SetDSOwnerRef),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evictor := &fakeEvictor{evicted: sets.NewString(), unevictable: sets.NewString("unevictable-pod", "daemonset-pod")}
+	recorder := events.NewFakeRecorder(10)
+	plugin := newTestPlugin(t, ctx, evictor, recorder, objs...)
+	plugin.clock = fakeClock
+
+	plugin.Deschedule(ctx, nodes)
+	assert.Equal(t, []string{"pod-1"}, evictor.evicted.List())
+	assert.Len(t, recorder.Events, 0)
+
+	// the evicted pod is still being migrated when the window starts
+	fakeClock.Step(5 * time.Minute)
+	plugin.Deschedule(ctx, nodes)
+	assert.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, reasonMaintenanceMigrationOverdue)
+	assert.Contains(t, event, "default/pod-1")
+	assert.Contains(t, event, "default/unevictable-pod")
+	assert.NotContains(t, event, "daemonset-pod")
+
+	// reported only once for the window
+	fakeClock.Step(10 * time.Minute)
+	plugin.Deschedule(ctx, nodes)
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestDescheduleStopsWhenMaintenanceWindowRemoved(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+
+	node := test.BuildTestNode("node-1", 32000, 64000, 100, func(node *corev1.Node) {
+		setMaintenanceWindow(node, now.Add(30*time.Minute))
+	})
+	objs := []runtime.Object{node}
+	for i := 0; i < 6; i++ {
+		objs = append(objs, test.BuildTestPod(fmt.Sprintf("pod-%d", i), 100, 0, node.Name, test.SetNormalOwnerRef))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evictor := &fakeEvictor{evicted: sets.NewString(), unevictable: sets.NewString()}
+	recorder := events.NewFakeRecorder(10)
+	plugin := newTestPlugin(t, ctx, evictor, recorder, objs...)
+	plugin.clock = fakeClock
+
+	plugin.Deschedule(ctx, []*corev1.Node{node})
+	assert.Equal(t, 2, evictor.evicted.Len())
+
+	nodeWithoutWindow := node.DeepCopy()
+	delete(nodeWithoutWindow.Annotations, extension.AnnotationNodeMaintenanceWindow)
+	for _, elapsed := range []time.Duration{10 * time.Minute, 40 * time.Minute} {
+		fakeClock.SetTime(now.Add(elapsed))
+		plugin.Deschedule(ctx, []*corev1.Node{nodeWithoutWindow})
+	}
+	assert.Equal(t, 2, evictor.evicted.Len())
+	assert.Len(t, recorder.Events, 0)
+}
+
+func Test_evictionBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining int
+		timeLeft  time.Duration
+		want      int
+	}{
+		{name: "spread across cycles", remaining: 12, timeLeft: 60 * time.Minute, want: 2},
+		{name: "round up the budget", remaining: 5, timeLeft: 30 * time.Minute, want: 2},
+		{name: "partial cycle counts as a cycle", remaining: 6, timeLeft: 25 * time.Minute, want: 2},
+		{name: "last cycle", remaining: 5, timeLeft: 3 * time.Minute, want: 5},
+		{name: "fewer pods than cycles", remaining: 1, timeLeft: 600 * time.Minute, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, evictionBudget(tt.remaining, tt.timeLeft, 10*time.Minute))
+		})
+	}
+}
@@ -19,6 +19,8 @@ package plugins
 import (
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/defaultevictor"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/loadaware"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/nodecondition"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/nodemaintenance"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/removepodsviolatingnodeaffinity"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
 )
@@ -28,5 +30,7 @@ func NewInTreeRegistry() runtime.Registry {
 		removepodsviolatingnodeaffinity.PluginName: removepodsviolatingnodeaffinity.New,
 		defaultevictor.PluginName:                  defaultevictor.New,
 		loadaware.LowLoadUtilizationName:           loadaware.NewLowNodeLoad,
+		nodemaintenance.PluginName:                 nodemaintenance.New,
+		nodecondition.PluginName:                   nodecondition.New,
 	}
 }
@@ -20,17 +20,23 @@ package removepodsviolatingnodeaffinity
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
 
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
 	nodeutil "github.com/koordinator-sh/koordinator/pkg/descheduler/node"
 	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 const PluginName = "RemovePodsViolatingNodeAffinity"
@@ -82,20 +88,33 @@ func (d *RemovePodsViolatingNodeAffinity) Name() string {
 }
 
 func (d *RemovePodsViolatingNodeAffinity) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	if d.args.ReportOnly {
+		d.reportViolations(nodes)
+		return nil
+	}
+
 	for _, nodeAffinity := range d.args.NodeAffinityType {
 		klog.V(2).InfoS("Executing for nodeAffinityType", "nodeAffinity", nodeAffinity)
 
 		switch nodeAffinity {
 		case "requiredDuringSchedulingIgnoredDuringExecution":
-			for _, node := range nodes {
+			candidateNodes := nodes
+			// reserved records the resources taken on the target nodes by the pods evicted in this cycle
+			reserved := map[string]corev1.ResourceList{}
+			if d.args.ConsolidateNodes {
+				candidateNodes = d.sortNodesByUtilization(nodes)
+			}
+			if d.args.NodeCostLabel != "" {
+				candidateNodes = d.sortNodesByCost(candidateNodes, false)
+			}
+			for _, node := range candidateNodes {
 				klog.V(1).InfoS("Processing node", "node", klog.KObj(node))
 
-				pods, err := podutil.ListPodsOnANode(
+				pods, err := podutil.ListEvictablePodsOnANode(
+					d.handle,
 					node.Name,
-					d.handle.GetPodsAssignedToNodeFunc(),
 					podutil.WrapFilterFuncs(d.podFilter, func(pod *corev1.Pod) bool {
-						return d.handle.Evictor().Filter(pod) &&
-							!nodeutil.PodFitsCurrentNode(d.handle.GetPodsAssignedToNodeFunc(), pod, node) &&
+						return !nodeutil.PodFitsCurrentNode(d.handle.GetPodsAssignedToNodeFunc(), pod, node) &&
 							nodeutil.PodFitsAnyNode(d.handle.GetPodsAssignedToNodeFunc(), pod, nodes)
 					}),
 				)
@@ -105,8 +124,19 @@ func (d *RemovePodsViolatingNodeAffinity) Deschedule(ctx context.Context, nodes
 
 				for _, pod := range pods {
 					if pod.Spec.Affinity != nil && pod.Spec.Affinity.NodeAffinity != nil && pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+						var targetNode *corev1.Node
+						if d.args.AvoidScaleUp {
+							targetNode = d.findTargetNode(pod, node, nodes, reserved)
+							if targetNode == nil {
+								klog.V(2).InfoS("Skip evicting pod since no existing node can host it without scaling up", "pod", klog.KObj(pod))
+								continue
+							}
+						}
 						klog.V(1).InfoS("Evicting pod", "pod", klog.KObj(pod))
-						d.handle.Evictor().Evict(ctx, pod, framework.EvictOptions{Reason: "Pod violating NodeAffinity"})
+						if d.handle.Evictor().Evict(ctx, pod, framework.EvictOptions{Reason: "Pod violating NodeAffinity"}) && targetNode != nil {
+							requests := util.GetPodEffectiveRequest(pod)
+							reserved[targetNode.Name] = quotav1.Add(reserved[targetNode.Name], requests)
+						}
 					}
 				}
 			}
@@ -116,3 +146,109 @@ func (d *RemovePodsViolatingNodeAffinity) Deschedule(ctx context.Context, nodes
 	}
 	return nil
 }
+
+// findTargetNode returns an existing node other than the current one which can host the pod right now, considering
+// both the node affinity and the free capacity left after the resources reserved for the pods evicted earlier.
+// The cheapest node is preferred if the node cost is considered.
+func (d *RemovePodsViolatingNodeAffinity) findTargetNode(pod *corev1.Pod, currentNode *corev1.Node, nodes []*corev1.Node, reserved map[string]corev1.ResourceList) *corev1.Node {
+	if d.args.NodeCostLabel != "" {
+		nodes = d.sortNodesByCost(nodes, true)
+	}
+	for _, node := range nodes {
+		if node.Name == currentNode.Name {
+			continue
+		}
+		if nodeutil.PodFitsNodeWithReserved(d.handle.GetPodsAssignedToNodeFunc(), pod, node, reserved[node.Name]) {
+			return node
+		}
+	}
+	return nil
+}
+
+// sortNodesByUtilization sorts the nodes in ascending order of the resources requested against the allocatable,
+// so that the pods on the near-empty nodes are evicted first and those nodes can be scaled down.
+func (d *RemovePodsViolatingNodeAffinity) sortNodesByUtilization(nodes []*corev1.Node) []*corev1.Node {
+	resourceNames := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+	utilization := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		pods, err := podutil.ListPodsOnANode(node.Name, d.handle.GetPodsAssignedToNodeFunc(), nil)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get pods", "node", klog.KObj(node))
+			utilization[node.Name] = math.MaxFloat64
+			continue
+		}
+		requested := nodeutil.NodeUtilization(pods, resourceNames)
+		var sum float64
+		for _, name := range resourceNames {
+			allocatable := node.Status.Allocatable[name]
+			if allocatable.IsZero() {
+				continue
+			}
+			sum += float64(requested[name].MilliValue()) / float64(allocatable.MilliValue())
+		}
+		utilization[node.Name] = sum / float64(len(resourceNames))
+	}
+
+	sortedNodes := make([]*corev1.Node, len(nodes))
+	copy(sortedNodes, nodes)
+	sort.SliceStable(sortedNodes, func(i, j int) bool {
+		return utilization[sortedNodes[i].Name] < utilization[sortedNodes[j].Name]
+	})
+	return sortedNodes
+}
+
+// sortNodesByCost sorts the nodes by the cost in the node cost label, in descending order unless ascending,
+// and the nodes of the same cost are kept in order. The nodes without a valid cost are considered of zero cost.
+func (d *RemovePodsViolatingNodeAffinity) sortNodesByCost(nodes []*corev1.Node, ascending bool) []*corev1.Node {
+	costs := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		cost, err := strconv.ParseFloat(node.Labels[d.args.NodeCostLabel], 64)
+		if err != nil || math.IsNaN(cost) {
+			klog.V(5).InfoS("Invalid node cost", "node", klog.KObj(node), "label", d.args.NodeCostLabel)
+			cost = 0
+		}
+		costs[node.Name] = cost
+	}
+
+	sortedNodes := make([]*corev1.Node, len(nodes))
+	copy(sortedNodes, nodes)
+	sort.SliceStable(sortedNodes, func(i, j int) bool {
+		if ascending {
+			return costs[sortedNodes[i].Name] < costs[sortedNodes[j].Name]
+		}
+		return costs[sortedNodes[i].Name] > costs[sortedNodes[j].Name]
+	})
+	return sortedNodes
+}
+
+// reportViolations counts the pods violating node affinity on each node and exports them as metrics.
+// Unlike dry-run, it is purely measurement, so the pods are counted regardless they are evictable or not.
+func (d *RemovePodsViolatingNodeAffinity) reportViolations(nodes []*corev1.Node) {
+	// drop the series of nodes which are gone or have no violations any more
+	metrics.PodsViolatingNodeAffinity.Reset()
+
+	for _, nodeAffinity := range d.args.NodeAffinityType {
+		switch nodeAffinity {
+		case "requiredDuringSchedulingIgnoredDuringExecution":
+			for _, node := range nodes {
+				pods, err := podutil.ListPodsOnANode(
+					node.Name,
+					d.handle.GetPodsAssignedToNodeFunc(),
+					podutil.WrapFilterFuncs(d.podFilter, func(pod *corev1.Pod) bool {
+						return pod.Spec.Affinity != nil && pod.Spec.Affinity.NodeAffinity != nil &&
+							pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil &&
+							!nodeutil.PodFitsCurrentNode(d.handle.GetPodsAssignedToNodeFunc(), pod, node)
+					}),
+				)
+				if err != nil {
+					klog.ErrorS(err, "Failed to get pods", "node", klog.KObj(node))
+					continue
+				}
+				klog.V(4).InfoS("Found pods violating NodeAffinity", "node", klog.KObj(node), "nodeAffinity", nodeAffinity, "count", len(pods))
+				metrics.PodsViolatingNodeAffinity.WithLabelValues(node.Name, nodeAffinity).Set(float64(len(pods)))
+			}
+		default:
+			klog.ErrorS(nil, "Invalid nodeAffinityType", "nodeAffinity", nodeAffinity)
+		}
+	}
+}
@@ -0,0 +1,485 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package removepodsviolatingnodeaffinity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/utils/pointer"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	evictutils "github.com/koordinator-sh/koordinator/pkg/descheduler/evictions/utils"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/defaultevictor"
+	frameworkruntime "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
+	frameworktesting "github.com/koordinator-sh/koordinator/pkg/descheduler/framework/testing"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+type fakeEvictor struct {
+	evicted []string
+}
+
+func (f *fakeEvictor) Name() string {
+	return "FakeEvictor"
+}
+
+func (f *fakeEvictor) Filter(pod *corev1.Pod) bool {
+	return true
+}
+
+func (f *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	f.evicted = append(f.evicted, pod.Name)
+	return true
+}
+
+func requireZoneAffinity(zone string) func(pod *corev1.Pod) {
+	return func(pod *corev1.Pod) {
+		test.SetNormalOwnerRef(pod)
+		pod.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "zone",
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{zone},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+}
+
+// newTestFramework returns the framework handle listing the nodes and the pods, whose evictions are recorded by the
+// returned evictor.
+func newTestFramework(ctx context.Context, t *testing.T, nodes []*corev1.Node, pods []*corev1.Pod) (framework.Handle, *fakeEvictor) {
+	var objs []runtime.Object
+	for _, node := range nodes {
+		objs = append(objs, node)
+	}
+	for _, pod := range pods {
+		objs = append(objs, pod)
+	}
+	fakeClient := fake.NewSimpleClientset(objs...)
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	podInformer := sharedInformerFactory.Core().V1().Pods()
+	getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(podInformer)
+	assert.NoError(t, err)
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	evictor := &fakeEvictor{}
+	fh, err := frameworktesting.NewFramework(
+		[]frameworktesting.RegisterPluginFunc{
+			frameworktesting.RegisterEvictorPlugin(evictor.Name(), func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+				return evictor, nil
+			}),
+		},
+		"test",
+		frameworkruntime.WithClientSet(fakeClient),
+		frameworkruntime.WithSharedInformerFactory(sharedInformerFactory),
+		frameworkruntime.WithGetPodsAssignedToNodeFunc(getPodsAssignedToNode),
+	)
+	assert.NoError(t, err)
+	return fh, evictor
+}
+
+func TestReportOnly(t *testing.T) {
+	metrics.Register()
+
+	nodeA := test.BuildTestNode("node-a", 2000, 3000, 10, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "a"}
+	})
+	nodeB := test.BuildTestNode("node-b", 2000, 3000, 10, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "b"}
+	})
+	nodes := []*corev1.Node{nodeA, nodeB}
+	pods := []*corev1.Pod{
+		test.BuildTestPod("fit-pod", 100, 0, nodeA.Name, requireZoneAffinity("a")),
+		test.BuildTestPod("violating-pod-1", 100, 0, nodeB.Name, requireZoneAffinity("a")),
+		test.BuildTestPod("violating-pod-2", 100, 0, nodeB.Name, requireZoneAffinity("a")),
+		test.BuildTestPod("no-affinity-pod", 100, 0, nodeB.Name, test.SetNormalOwnerRef),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fh, evictor := newTestFramework(ctx, t, nodes, pods)
+
+	args := &deschedulerconfig.RemovePodsViolatingNodeAffinityArgs{
+		NodeAffinityType: []string{"requiredDuringSchedulingIgnoredDuringExecution"},
+		ReportOnly:       true,
+	}
+	plugin, err := New(args, fh)
+	assert.NoError(t, err)
+	status := plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+	assert.Nil(t, status)
+	assert.Empty(t, evictor.evicted)
+
+	violations, err := testutil.GetGaugeMetricValue(metrics.PodsViolatingNodeAffinity.WithLabelValues(nodeB.Name, "requiredDuringSchedulingIgnoredDuringExecution"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), violations)
+	violations, err = testutil.GetGaugeMetricValue(metrics.PodsViolatingNodeAffinity.WithLabelValues(nodeA.Name, "requiredDuringSchedulingIgnoredDuringExecution"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), violations)
+
+	// the pods are evicted as usual when ReportOnly is disabled
+	args.ReportOnly = false
+	plugin, err = New(args, fh)
+	assert.NoError(t, err)
+	plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+	assert.ElementsMatch(t, []string{"violating-pod-1", "violating-pod-2"}, evictor.evicted)
+}
+
+func TestConsolidateNodes(t *testing.T) {
+	nodeA := test.BuildTestNode("node-a", 4000, 3000, 10, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "a"}
+	})
+	busyNode := test.BuildTestNode("busy-node", 2000, 3000, 10, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "b"}
+	})
+	idleNode := test.BuildTestNode("idle-node", 2000, 3000, 10, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "b"}
+	})
+	nodes := []*corev1.Node{nodeA, busyNode, idleNode}
+	pods := []*corev1.Pod{
+		test.BuildTestPod("busy-pod", 1500, 2000, busyNode.Name, test.SetNormalOwnerRef),
+		test.BuildTestPod("violating-pod-on-busy-node", 100, 0, busyNode.Name, requireZoneAffinity("a")),
+		test.BuildTestPod("violating-pod-on-idle-node", 100, 0, idleNode.Name, requireZoneAffinity("a")),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fh, evictor := newTestFramework(ctx, t, nodes, pods)
+
+	args := &deschedulerconfig.RemovePodsViolatingNodeAffinityArgs{
+		NodeAffinityType: []string{"requiredDuringSchedulingIgnoredDuringExecution"},
+	}
+	plugin, err := New(args, fh)
+	assert.NoError(t, err)
+	plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+	assert.Equal(t, []string{"violating-pod-on-busy-node", "violating-pod-on-idle-node"}, evictor.evicted)
+
+	// the pod on the emptier node is chosen first
+	evictor.evicted = nil
+	args.ConsolidateNodes = true
+	plugin, err = New(args, fh)
+	assert.NoError(t, err)
+	plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+	assert.Equal(t, []string{"violating-pod-on-idle-node", "violating-pod-on-busy-node"}, evictor.evicted)
+}
+
+func TestAvoidScaleUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		targetCPU   int64
+		pods        []*corev1.Pod
+		wantEvicted []string
+		// wantEvictedOneOf is set if any one of the pods is expected to be evicted, as the pods are listed in no order
+		wantEvictedOneOf []string
+	}{
+		{
+			name:      "the only fitting node lacks capacity",
+			targetCPU: 1000,
+			pods: []*corev1.Pod{
+				test.BuildTestPod("running-pod", 800, 0, "node-a", test.SetNormalOwnerRef),
+				test.BuildTestPod("violating-pod", 500, 0, "node-b", requireZoneAffinity("a")),
+			},
+			wantEvicted: nil,
+		},
+		{
+			name:      "the only fitting node has capacity",
+			targetCPU: 1000,
+			pods: []*corev1.Pod{
+				test.BuildTestPod("violating-pod", 500, 0, "node-b", requireZoneAffinity("a")),
+			},
+			wantEvicted: []string{"violating-pod"},
+		},
+		{
+			name:      "the fitting node has capacity for only one of the pods",
+			targetCPU: 1000,
+			pods: []*corev1.Pod{
+				test.BuildTestPod("violating-pod-1", 600, 0, "node-b", requireZoneAffinity("a")),
+				test.BuildTestPod("violating-pod-2", 600, 0, "node-b", requireZoneAffinity("a")),
+			},
+			wantEvictedOneOf: []string{"violating-pod-1", "violating-pod-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeA := test.BuildTestNode("node-a", tt.targetCPU, 3000, 10, func(node *corev1.Node) {
+				node.Labels = map[string]string{"zone": "a"}
+			})
+			nodeB := test.BuildTestNode("node-b", 4000, 3000, 10, func(node *corev1.Node) {
+				node.Labels = map[string]string{"zone": "b"}
+			})
+			nodes := []*corev1.Node{nodeA, nodeB}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fh, evictor := newTestFramework(ctx, t, nodes, tt.pods)
+
+			args := &deschedulerconfig.RemovePodsViolatingNodeAffinityArgs{
+				NodeAffinityType: []string{"requiredDuringSchedulingIgnoredDuringExecution"},
+				AvoidScaleUp:     true,
+			}
+			plugin, err := New(args, fh)
+			assert.NoError(t, err)
+			plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+			if len(tt.wantEvictedOneOf) > 0 {
+				assert.Len(t, evictor.evicted, 1)
+				assert.Subset(t, tt.wantEvictedOneOf, evictor.evicted)
+				return
+			}
+			assert.Equal(t, tt.wantEvicted, evictor.evicted)
+		})
+	}
+}
+
+func TestNodeCost(t *testing.T) {
+	withCost := func(zone, cost string) func(node *corev1.Node) {
+		return func(node *corev1.Node) {
+			node.Labels = map[string]string{"zone": zone}
+			if cost != "" {
+				node.Labels["node.koordinator.sh/cost"] = cost
+			}
+		}
+	}
+	tests := []struct {
+		name          string
+		nodeCostLabel string
+		avoidScaleUp  bool
+		wantEvicted   []string
+	}{
+		{
+			name:        "node cost not considered",
+			wantEvicted: []string{"violating-pod-on-cheap-node", "violating-pod-on-unknown-cost-node", "violating-pod-on-expensive-node"},
+		},
+		{
+			name:          "the pod on the expensive node is evicted first",
+			nodeCostLabel: "node.koordinator.sh/cost",
+			wantEvicted:   []string{"violating-pod-on-expensive-node", "violating-pod-on-cheap-node", "violating-pod-on-unknown-cost-node"},
+		},
+		{
+			name:          "the capacity is taken by the pod on the expensive node",
+			nodeCostLabel: "node.koordinator.sh/cost",
+			avoidScaleUp:  true,
+			wantEvicted:   []string{"violating-pod-on-expensive-node"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetNode := test.BuildTestNode("target-node", 1000, 3000, 10, withCost("a", "1"))
+			cheapNode := test.BuildTestNode("cheap-node", 4000, 3000, 10, withCost("b", "2.5"))
+			unknownCostNode := test.BuildTestNode("unknown-cost-node", 4000, 3000, 10, withCost("b", "unknown"))
+			expensiveNode := test.BuildTestNode("expensive-node", 4000, 3000, 10, withCost("b", "10"))
+			nodes := []*corev1.Node{targetNode, cheapNode, unknownCostNode, expensiveNode}
+			pods := []*corev1.Pod{
+				test.BuildTestPod("violating-pod-on-cheap-node", 600, 0, cheapNode.Name, requireZoneAffinity("a")),
+				test.BuildTestPod("violating-pod-on-unknown-cost-node", 600, 0, unknownCostNode.Name, requireZoneAffinity("a")),
+				test.BuildTestPod("violating-pod-on-expensive-node", 600, 0, expensiveNode.Name, requireZoneAffinity("a")),
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fh, evictor := newTestFramework(ctx, t, nodes, pods)
+
+			args := &deschedulerconfig.RemovePodsViolatingNodeAffinityArgs{
+				NodeAffinityType: []string{"requiredDuringSchedulingIgnoredDuringExecution"},
+				AvoidScaleUp:     tt.avoidScaleUp,
+				NodeCostLabel:    tt.nodeCostLabel,
+			}
+			plugin, err := New(args, fh)
+			assert.NoError(t, err)
+			plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+			assert.Equal(t, tt.wantEvicted, evictor.evicted)
+		})
+	}
+}
+
+func TestSortNodesByCost(t *testing.T) {
+	newNode := func(name, cost string) *corev1.Node {
+		return test.BuildTestNode(name, 1000, 3000, 10, func(node *corev1.Node) {
+			node.Labels = map[string]string{"cost": cost}
+		})
+	}
+	nodes := []*corev1.Node{newNode("node-1", "3"), newNode("node-2", ""), newNode("node-3", "5"), newNode("node-4", "3")}
+	d := &RemovePodsViolatingNodeAffinity{args: &deschedulerconfig.RemovePodsViolatingNodeAffinityArgs{NodeCostLabel: "cost"}}
+
+	var names []string
+	for _, node := range d.sortNodesByCost(nodes, false) {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"node-3", "node-1", "node-4", "node-2"}, names)
+
+	names = nil
+	for _, node := range d.sortNodesByCost(nodes, true) {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"node-2", "node-1", "node-4", "node-3"}, names)
+	// the given nodes are kept untouched
+	assert.Equal(t, "node-1", nodes[0].Name)
+}
+
+func setupFakeDiscoveryWithPolicyResource(fake *coretesting.Fake) {
+	fake.AddReactor("get", "group", func(action coretesting.Action) (handled bool, ret runtime.Object, err error) {
+		fake.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: policy.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{
+					{
+						Name: evictutils.EvictionSubResouceName,
+						Kind: evictutils.EvictionKind,
+					},
+				},
+			},
+		}
+		return true, nil, nil
+	})
+	fake.AddReactor("get", "resource", func(action coretesting.Action) (handled bool, ret runtime.Object, err error) {
+		fake.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{
+						Name: evictutils.EvictionSubResouceName,
+						Kind: evictutils.EvictionKind,
+					},
+				},
+			},
+		}
+		return true, nil, nil
+	})
+}
+
+func TestMaxPercentageOfPodsToEvictPerOwner(t *testing.T) {
+	nodeA := test.BuildTestNode("node-a", 4000, 3000, 20, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "a"}
+	})
+	nodeB := test.BuildTestNode("node-b", 4000, 3000, 20, func(node *corev1.Node) {
+		node.Labels = map[string]string{"zone": "b"}
+	})
+	nodes := []*corev1.Node{nodeA, nodeB}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "deployment-uid"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(10)},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "test-rs",
+			UID:             "replicaset-uid",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Replicas: pointer.Int32(10)},
+	}
+	objs := []runtime.Object{nodeA, nodeB, deployment, replicaSet}
+	for i := 0; i < 10; i++ {
+		objs = append(objs, test.BuildTestPod(fmt.Sprintf("violating-pod-%d", i), 100, 0, nodeB.Name, func(pod *corev1.Pod) {
+			requireZoneAffinity("a")(pod)
+			pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(replicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}
+		}))
+	}
+
+	tests := []struct {
+		name                               string
+		maxPercentageOfPodsToEvictPerOwner *int32
+		wantEvictedPerCycle                int
+	}{
+		{
+			name:                "no limit",
+			wantEvictedPerCycle: 10,
+		},
+		{
+			name:                               "30 percent",
+			maxPercentageOfPodsToEvictPerOwner: pointer.Int32(30),
+			wantEvictedPerCycle:                3,
+		},
+		{
+			name:                               "at least one pod",
+			maxPercentageOfPodsToEvictPerOwner: pointer.Int32(5),
+			wantEvictedPerCycle:                1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fakeClient := fake.NewSimpleClientset(objs...)
+			setupFakeDiscoveryWithPolicyResource(&fakeClient.Fake)
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods()
+			getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(podInformer)
+			assert.NoError(t, err)
+
+			fh, err := frameworktesting.NewFramework(
+				[]frameworktesting.RegisterPluginFunc{
+					func(reg *frameworkruntime.Registry, profile *deschedulerconfig.DeschedulerProfile) {
+						reg.Register(defaultevictor.PluginName, defaultevictor.New)
+						profile.Plugins.Evictor.Enabled = append(profile.Plugins.Evictor.Enabled, deschedulerconfig.Plugin{Name: defaultevictor.PluginName})
+						profile.PluginConfig = append(profile.PluginConfig, deschedulerconfig.PluginConfig{
+							Name: defaultevictor.PluginName,
+							Args: &deschedulerconfig.DefaultEvictorArgs{
+								MaxPercentageOfPodsToEvictPerOwner: tt.maxPercentageOfPodsToEvictPerOwner,
+							},
+						})
+					},
+				},
+				"test",
+				frameworkruntime.WithClientSet(fakeClient),
+				frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+				frameworkruntime.WithSharedInformerFactory(sharedInformerFactory),
+				frameworkruntime.WithGetPodsAssignedToNodeFunc(getPodsAssignedToNode),
+			)
+			assert.NoError(t, err)
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			plugin, err := New(&deschedulerconfig.RemovePodsViolatingNodeAffinityArgs{
+				NodeAffinityType: []string{"requiredDuringSchedulingIgnoredDuringExecution"},
+			}, fh)
+			assert.NoError(t, err)
+			defaultEvictor := fh.Evictor().(*defaultevictor.DefaultEvictor)
+			plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+			assert.Equal(t, tt.wantEvictedPerCycle, defaultEvictor.PodEvictor().TotalEvicted())
+
+			// the limit is recovered in the next cycle
+			defaultEvictor.ResetCycle()
+			plugin.(framework.DeschedulePlugin).Deschedule(ctx, nodes)
+			assert.Equal(t, 2*tt.wantEvictedPerCycle, defaultEvictor.PodEvictor().TotalEvicted())
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+)
+
+var _ framework.EvictablePodsLister = &frameworkImpl{}
+
+// evictablePodsSnapshot memoizes the pods on each node passing the Evictor filter in a descheduling cycle.
+// It only references the pods in the informer cache, and it is cleared at the end of the cycle,
+// so the memory is bounded by the nodes processed in one cycle.
+// The pods are only memoized when the plugins are run by the framework, the plugins called directly
+// always get the latest pods.
+type evictablePodsSnapshot struct {
+	lock    sync.RWMutex
+	running bool
+	pods    map[string][]*corev1.Pod
+}
+
+func newEvictablePodsSnapshot() *evictablePodsSnapshot {
+	return &evictablePodsSnapshot{
+		pods: map[string][]*corev1.Pod{},
+	}
+}
+
+func (s *evictablePodsSnapshot) setRunning(running bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.running = running
+}
+
+func (s *evictablePodsSnapshot) get(nodeName string) ([]*corev1.Pod, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if !s.running {
+		return nil, false
+	}
+	pods, ok := s.pods[nodeName]
+	return pods, ok
+}
+
+func (s *evictablePodsSnapshot) set(nodeName string, pods []*corev1.Pod) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.running {
+		s.pods[nodeName] = pods
+	}
+}
+
+func (s *evictablePodsSnapshot) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pods = map[string][]*corev1.Pod{}
+}
+
+func (f *frameworkImpl) ListEvictablePodsOnANode(nodeName string, filter framework.FilterFunc) ([]*corev1.Pod, error) {
+	pods, ok := f.evictablePods.get(nodeName)
+	if !ok {
+		// the Evictor filter is evaluated outside the lock since it may be expensive
+		var err error
+		pods, err = podutil.ListPodsOnANode(nodeName, f.getPodsAssignedToNodeFunc, f.Evictor().Filter)
+		if err != nil {
+			return nil, err
+		}
+		f.evictablePods.set(nodeName, pods)
+	}
+
+	// always return a new slice since the callers may sort it
+	result := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if filter == nil || filter(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+func (f *frameworkImpl) ResetEvictablePods() {
+	f.evictablePods.reset()
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+)
+
+var _ framework.DeschedulePlugin = &testListingPlugin{}
+var _ framework.BalancePlugin = &testListingPlugin{}
+
+// testListingPlugin lists the evictable pods on each node with its own predicate as the descheduling plugins do.
+type testListingPlugin struct {
+	name      string
+	handle    framework.Handle
+	predicate framework.FilterFunc
+	snapshot  bool
+	listed    map[string][]string
+}
+
+func (pl *testListingPlugin) Name() string {
+	return pl.name
+}
+
+func (pl *testListingPlugin) list(nodes []*corev1.Node) *framework.Status {
+	pl.listed = map[string][]string{}
+	for _, node := range nodes {
+		var pods []*corev1.Pod
+		var err error
+		if pl.snapshot {
+			pods, err = podutil.ListEvictablePodsOnANode(pl.handle, node.Name, pl.predicate)
+		} else {
+			pods, err = podutil.ListPodsOnANode(node.Name, pl.handle.GetPodsAssignedToNodeFunc(), podutil.WrapFilterFuncs(pl.predicate, pl.handle.Evictor().Filter))
+		}
+		if err != nil {
+			return &framework.Status{Err: err}
+		}
+		for _, pod := range pods {
+			pl.listed[node.Name] = append(pl.listed[node.Name], pod.Name)
+		}
+	}
+	return nil
+}
+
+func (pl *testListingPlugin) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	return pl.list(nodes)
+}
+
+func (pl *testListingPlugin) Balance(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	return pl.list(nodes)
+}
+
+type testPodsStore struct {
+	nodes      []*corev1.Node
+	podsOnNode map[string][]*corev1.Pod
+}
+
+func newTestPodsStore(numNodes, podsPerNode int) *testPodsStore {
+	store := &testPodsStore{podsOnNode: map[string][]*corev1.Pod{}}
+	for i := 0; i < numNodes; i++ {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}
+		store.nodes = append(store.nodes, node)
+		for j := 0; j < podsPerNode; j++ {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: fmt.Sprintf("ns-%d", j%4),
+					Name:      fmt.Sprintf("pod-%d-%d", i, j),
+					Labels:    map[string]string{"app": fmt.Sprintf("app-%d", j%10)},
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "ReplicaSet", Name: fmt.Sprintf("rs-%d", j%10)},
+					},
+				},
+				Spec: corev1.PodSpec{NodeName: node.Name},
+			}
+			store.podsOnNode[node.Name] = append(store.podsOnNode[node.Name], pod)
+		}
+	}
+	return store
+}
+
+func (s *testPodsStore) getPodsAssignedToNode(nodeName string, filter framework.FilterFunc) ([]*corev1.Pod, error) {
+	var pods []*corev1.Pod
+	for _, pod := range s.podsOnNode[nodeName] {
+		if filter == nil || filter(pod) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func newEvictablePodsTestFramework(t testing.TB, store *testPodsStore, evictorFilter evictFilterFn, snapshot bool, predicates ...framework.FilterFunc) (framework.Handle, []*testListingPlugin) {
+	registry := Registry{}
+	profile := &deschedulerconfig.DeschedulerProfile{
+		Name: testProfileName,
+		Plugins: &deschedulerconfig.Plugins{
+			Evictor: deschedulerconfig.PluginSet{
+				Enabled: []deschedulerconfig.Plugin{{Name: evictorPluginName}},
+			},
+		},
+	}
+	assert.NoError(t, registry.Register(evictorPluginName, newTestEvictorPluginFactory(evictorFilter)))
+	var plugins []*testListingPlugin
+	for i := range predicates {
+		pl := &testListingPlugin{name: fmt.Sprintf("listing-plugin-%d", i), predicate: predicates[i], snapshot: snapshot}
+		plugins = append(plugins, pl)
+		assert.NoError(t, registry.Register(pl.name, func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			pl.handle = handle
+			return pl, nil
+		}))
+		profile.Plugins.Deschedule.Enabled = append(profile.Plugins.Deschedule.Enabled, deschedulerconfig.Plugin{Name: pl.name})
+		profile.Plugins.Balance.Enabled = append(profile.Plugins.Balance.Enabled, deschedulerconfig.Plugin{Name: pl.name})
+	}
+	fh, err := NewFramework(registry, profile, WithGetPodsAssignedToNodeFunc(store.getPodsAssignedToNode))
+	assert.NoError(t, err)
+	return fh, plugins
+}
+
+func TestListEvictablePodsOnANode(t *testing.T) {
+	store := newTestPodsStore(2, 4)
+	store.podsOnNode["node-0"][3].Status.Phase = corev1.PodSucceeded
+	unevictable := map[string]bool{"pod-1-0": true}
+	var filtered int32
+	evictorFilter := func(pod *corev1.Pod) bool {
+		atomic.AddInt32(&filtered, 1)
+		return !unevictable[pod.Name]
+	}
+	fh, plugins := newEvictablePodsTestFramework(t, store, evictorFilter, true,
+		func(pod *corev1.Pod) bool {
+			return pod.Namespace == "ns-1" || pod.Namespace == "ns-3"
+		},
+		func(pod *corev1.Pod) bool {
+			return strings.HasSuffix(pod.Name, "-0") || strings.HasSuffix(pod.Name, "-3")
+		},
+	)
+	lister, ok := fh.(framework.EvictablePodsLister)
+	assert.True(t, ok)
+
+	// the plugin filters still apply on the memoized pods
+	status := fh.RunDeschedulePlugins(context.TODO(), store.nodes)
+	assert.Nil(t, status.Err)
+	assert.Equal(t, map[string][]string{"node-0": {"pod-0-1"}, "node-1": {"pod-1-1", "pod-1-3"}}, plugins[0].listed)
+	assert.Equal(t, map[string][]string{"node-0": {"pod-0-0"}, "node-1": {"pod-1-3"}}, plugins[1].listed)
+	// the Evictor filter is evaluated once for each non-terminated pod in the cycle
+	assert.Equal(t, int32(7), atomic.LoadInt32(&filtered))
+
+	status = fh.RunBalancePlugins(context.TODO(), store.nodes)
+	assert.Nil(t, status.Err)
+	assert.Equal(t, map[string][]string{"node-0": {"pod-0-0"}, "node-1": {"pod-1-3"}}, plugins[1].listed)
+	assert.Equal(t, int32(7), atomic.LoadInt32(&filtered))
+
+	// the memoized pods never leak across cycles
+	lister.ResetEvictablePods()
+	unevictable["pod-1-3"] = true
+	status = fh.RunDeschedulePlugins(context.TODO(), store.nodes)
+	assert.Nil(t, status.Err)
+	assert.Equal(t, map[string][]string{"node-0": {"pod-0-1"}, "node-1": {"pod-1-1"}}, plugins[0].listed)
+	assert.Equal(t, map[string][]string{"node-0": {"pod-0-0"}}, plugins[1].listed)
+	assert.Equal(t, int32(14), atomic.LoadInt32(&filtered))
+
+	// the pods listed outside the plugins run are not memoized
+	lister.ResetEvictablePods()
+	for i := 0; i < 2; i++ {
+		pods, err := lister.ListEvictablePodsOnANode("node-1", nil)
+		assert.NoError(t, err)
+		assert.Len(t, pods, 2)
+	}
+	assert.Equal(t, int32(22), atomic.LoadInt32(&filtered))
+}
+
+func BenchmarkListEvictablePodsOnANode(b *testing.B) {
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "app", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"app-0"}},
+		},
+	})
+	assert.NoError(b, err)
+	evictorFilter := func(pod *corev1.Pod) bool {
+		ownerRefs := podutil.OwnerRef(pod)
+		if len(ownerRefs) == 0 || ownerRefs[0].Kind == "DaemonSet" {
+			return false
+		}
+		return selector.Matches(labels.Set(pod.Labels))
+	}
+	predicates := []framework.FilterFunc{
+		func(pod *corev1.Pod) bool { return pod.Namespace != "ns-0" },
+		func(pod *corev1.Pod) bool { return pod.Labels["app"] != "app-1" },
+		func(pod *corev1.Pod) bool { return pod.Spec.Affinity == nil },
+		func(pod *corev1.Pod) bool { return pod.Status.Phase != corev1.PodPending },
+	}
+
+	store := newTestPodsStore(500, 200)
+	for _, snapshot := range []bool{false, true} {
+		name := "ListPodsOnANode"
+		if snapshot {
+			name = "EvictablePodsSnapshot"
+		}
+		b.Run(name, func(b *testing.B) {
+			fh, _ := newEvictablePodsTestFramework(b, store, evictorFilter, snapshot, predicates...)
+			lister := fh.(framework.EvictablePodsLister)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fh.RunDeschedulePlugins(context.TODO(), store.nodes)
+				lister.ResetEvictablePods()
+			}
+		})
+	}
+}
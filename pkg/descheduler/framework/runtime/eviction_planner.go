@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+var _ framework.EvictionPlanner = &frameworkImpl{}
+
+// evictionPlan records the evictions requested by the plugins while the planning is started.
+type evictionPlan struct {
+	lock      sync.Mutex
+	planning  bool
+	planned   sets.String
+	evictions []framework.PlannedEviction
+}
+
+func newEvictionPlan() *evictionPlan {
+	return &evictionPlan{}
+}
+
+func (p *evictionPlan) isPlanning() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.planning
+}
+
+func (p *evictionPlan) start() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.planning = true
+	p.planned = sets.NewString()
+	p.evictions = nil
+}
+
+func (p *evictionPlan) stop() []framework.PlannedEviction {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	evictions := p.evictions
+	p.planning = false
+	p.planned = nil
+	p.evictions = nil
+	return evictions
+}
+
+func (p *evictionPlan) add(pod *corev1.Pod, evictOptions framework.EvictOptions) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	key := string(pod.UID)
+	if key == "" {
+		key = pod.Namespace + "/" + pod.Name
+	}
+	// the pod planned by another plugin in this cycle is evicted only once
+	if p.planned.Has(key) {
+		return
+	}
+	p.planned.Insert(key)
+	p.evictions = append(p.evictions, framework.PlannedEviction{Pod: pod, EvictOptions: evictOptions})
+}
+
+// plannedEvictor records the evictions into the plan, and the filtering and the budgets are still done by the Evictor plugin.
+type plannedEvictor struct {
+	framework.Evictor
+	plan *evictionPlan
+}
+
+func (e *plannedEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	framework.FillEvictOptionsFromContext(ctx, &evictOptions)
+	if budgetPlanner, ok := e.Evictor.(framework.EvictionBudgetPlanner); ok && !budgetPlanner.PlanEvict(pod, evictOptions) {
+		return false
+	}
+	klog.V(4).InfoS("Plan to evict pod", "pod", klog.KObj(pod), "strategy", evictOptions.PluginName, "reason", evictOptions.Reason)
+	e.plan.add(pod, evictOptions)
+	return true
+}
+
+func (f *frameworkImpl) StartEvictionPlanning() {
+	f.evictionPlan.start()
+}
+
+func (f *frameworkImpl) StopEvictionPlanning() []framework.PlannedEviction {
+	evictions := f.evictionPlan.stop()
+	if len(f.evictorPlugins) > 0 {
+		// the planned evictions consume the budgets again once they are performed
+		if budgetPlanner, ok := f.evictorPlugins[0].(framework.EvictionBudgetPlanner); ok {
+			for _, eviction := range evictions {
+				budgetPlanner.UnplanEvict(eviction.Pod)
+			}
+		}
+	}
+	return evictions
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+func TestEvictionPlan(t *testing.T) {
+	podA := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", UID: "a"}}
+	podB := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}}
+
+	plan := newEvictionPlan()
+	assert.False(t, plan.isPlanning())
+	plan.start()
+	assert.True(t, plan.isPlanning())
+	plan.add(podA, framework.EvictOptions{PluginName: "p1"})
+	plan.add(podB, framework.EvictOptions{PluginName: "p1"})
+	plan.add(podA, framework.EvictOptions{PluginName: "p2"})
+	plan.add(podB, framework.EvictOptions{PluginName: "p2"})
+	evictions := plan.stop()
+	assert.False(t, plan.isPlanning())
+	assert.Equal(t, []framework.PlannedEviction{
+		{Pod: podA, EvictOptions: framework.EvictOptions{PluginName: "p1"}},
+		{Pod: podB, EvictOptions: framework.EvictOptions{PluginName: "p1"}},
+	}, evictions)
+
+	plan.start()
+	assert.Empty(t, plan.stop())
+}
+
+type fakeBudgetEvictor struct {
+	budget int
+}
+
+func (e *fakeBudgetEvictor) Name() string                { return "FakeBudgetEvictor" }
+func (e *fakeBudgetEvictor) Filter(pod *corev1.Pod) bool { return true }
+func (e *fakeBudgetEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	return false
+}
+func (e *fakeBudgetEvictor) PlanEvict(pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	if e.budget <= 0 {
+		return false
+	}
+	e.budget--
+	return true
+}
+func (e *fakeBudgetEvictor) UnplanEvict(pod *corev1.Pod) { e.budget++ }
+
+func TestPlannedEvictorBudgets(t *testing.T) {
+	podA := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", UID: "a"}}
+	podB := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", UID: "b"}}
+
+	evictor := &fakeBudgetEvictor{budget: 1}
+	f := &frameworkImpl{evictorPlugins: []framework.Evictor{evictor}, evictionPlan: newEvictionPlan()}
+	f.StartEvictionPlanning()
+	assert.True(t, f.Evictor().Evict(context.TODO(), podA, framework.EvictOptions{PluginName: "p1"}))
+	// the planned evictions are limited by the budgets of the Evictor
+	assert.False(t, f.Evictor().Evict(context.TODO(), podB, framework.EvictOptions{PluginName: "p1"}))
+	assert.Equal(t, 0, evictor.budget)
+	assert.Equal(t, []framework.PlannedEviction{
+		{Pod: podA, EvictOptions: framework.EvictOptions{PluginName: "p1"}},
+	}, f.StopEvictionPlanning())
+	// the budgets are given back before the planned evictions are performed
+	assert.Equal(t, 1, evictor.budget)
+}
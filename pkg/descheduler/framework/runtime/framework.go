@@ -44,6 +44,8 @@ type frameworkImpl struct {
 	deschedulePlugins         []framework.DeschedulePlugin
 	balancePlugins            []framework.BalancePlugin
 	evictorPlugins            []framework.Evictor
+	evictablePods             *evictablePodsSnapshot
+	evictionPlan              *evictionPlan
 }
 
 // Option for the frameworkImpl.
@@ -113,6 +115,8 @@ func NewFramework(r Registry, profile *deschedulerconfig.DeschedulerProfile, opt
 		eventRecorder:             options.eventRecorder,
 		sharedInformerFactory:     options.sharedInformerFactory,
 		getPodsAssignedToNodeFunc: options.getPodsAssignedToNodeFunc,
+		evictablePods:             newEvictablePodsSnapshot(),
+		evictionPlan:              newEvictionPlan(),
 	}
 
 	if profile == nil || profile.Plugins == nil {
@@ -293,9 +297,27 @@ func (f *frameworkImpl) Evictor() framework.Evictor {
 	if len(f.evictorPlugins) == 0 {
 		panic("No Evictor plugin is registered in the frameworkImpl.")
 	}
+	if f.evictionPlan.isPlanning() {
+		return &plannedEvictor{Evictor: f.evictorPlugins[0], plan: f.evictionPlan}
+	}
 	return f.evictorPlugins[0]
 }
 
+// NodeClassificationChanged reports whether any plugin of the profile changed its node classification in the last run.
+func (f *frameworkImpl) NodeClassificationChanged() bool {
+	for _, pl := range f.deschedulePlugins {
+		if classifier, ok := pl.(framework.NodeClassifier); ok && classifier.NodeClassificationChanged() {
+			return true
+		}
+	}
+	for _, pl := range f.balancePlugins {
+		if classifier, ok := pl.(framework.NodeClassifier); ok && classifier.NodeClassificationChanged() {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *frameworkImpl) GetPodsAssignedToNodeFunc() framework.GetPodsAssignedToNodeFunc {
 	return f.getPodsAssignedToNodeFunc
 }
@@ -305,6 +327,9 @@ func (f *frameworkImpl) SharedInformerFactory() informers.SharedInformerFactory
 }
 
 func (f *frameworkImpl) RunDeschedulePlugins(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	f.evictablePods.setRunning(true)
+	defer f.evictablePods.setRunning(false)
+
 	var errs []error
 	for _, pl := range f.deschedulePlugins {
 		childCtx := framework.PluginNameWithContext(ctx, pl.Name())
@@ -325,6 +350,9 @@ func (f *frameworkImpl) RunDeschedulePlugins(ctx context.Context, nodes []*corev
 }
 
 func (f *frameworkImpl) RunBalancePlugins(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	f.evictablePods.setRunning(true)
+	defer f.evictablePods.setRunning(false)
+
 	var errs []error
 	for _, pl := range f.balancePlugins {
 		childCtx := framework.PluginNameWithContext(ctx, pl.Name())
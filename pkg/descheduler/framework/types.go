@@ -91,6 +91,68 @@ type Evictor interface {
 	Evict(ctx context.Context, pod *corev1.Pod, evictOptions EvictOptions) bool
 }
 
+// EvictorCycleResetter is an optional interface of Evictor. The descheduler calls ResetCycle
+// at the beginning of each descheduling cycle to discard the states kept in the last cycle.
+type EvictorCycleResetter interface {
+	ResetCycle()
+}
+
+// EvictorCycleFinisher is an optional interface of Evictor performing the evictions asynchronously. The descheduler
+// calls FinishCycle at the end of each descheduling cycle, which returns after the evictions of the cycle are
+// performed or canceled.
+type EvictorCycleFinisher interface {
+	FinishCycle(ctx context.Context)
+}
+
+// EvictionCounter is an optional interface of Evictor. It reports the number of pods evicted since the Evictor is
+// built, so that the descheduler can tell whether a descheduling cycle performed evictions.
+type EvictionCounter interface {
+	TotalEvicted() int
+}
+
+// EvictionBudgetPlanner is an optional interface of Evictor limiting the evictions by the budgets, e.g. the max number
+// of pods evicted per node. While the EvictionPlanner is planning, the planned evictions consume the budgets as if
+// they were performed, and the budgets are given back before the planned evictions are performed by Evict.
+type EvictionBudgetPlanner interface {
+	// PlanEvict consumes the budgets for the eviction of the pod without evicting it.
+	// It returns false if the budgets are exhausted.
+	PlanEvict(pod *corev1.Pod, evictOptions EvictOptions) bool
+	// UnplanEvict gives back the budgets consumed by PlanEvict.
+	UnplanEvict(pod *corev1.Pod)
+}
+
+// NodeClassifier is an optional interface of the plugins classifying the nodes, e.g. into the underutilized and
+// overutilized nodes, and of the Handle aggregating its plugins. NodeClassificationChanged reports whether the
+// classification made in the last run differs from the one made in the run before.
+type NodeClassifier interface {
+	NodeClassificationChanged() bool
+}
+
+// EvictablePodsLister is an optional interface of Handle. It memoizes the pods on each node passing the Evictor filter
+// in a descheduling cycle, so that the plugins of the profile don't list and filter the same pods repeatedly.
+type EvictablePodsLister interface {
+	// ListEvictablePodsOnANode lists the non-terminated pods on the node passing the Evictor filter,
+	// and further limited by the filter if it is not nil.
+	ListEvictablePodsOnANode(nodeName string, filter FilterFunc) ([]*corev1.Pod, error)
+	// ResetEvictablePods discards the memoized pods. The descheduler calls it at the end of each descheduling cycle.
+	ResetEvictablePods()
+}
+
+// EvictionPlanner is an optional interface of Handle. While the planning is started, the evictions requested by the
+// plugins of the profile are recorded instead of executed, so that the descheduler can review them before execution.
+type EvictionPlanner interface {
+	// StartEvictionPlanning starts recording the evictions requested by the plugins.
+	StartEvictionPlanning()
+	// StopEvictionPlanning stops recording and returns the recorded evictions in the order they are requested.
+	StopEvictionPlanning() []PlannedEviction
+}
+
+// PlannedEviction is an eviction recorded by the EvictionPlanner.
+type PlannedEviction struct {
+	Pod          *corev1.Pod
+	EvictOptions EvictOptions
+}
+
 type DeschedulePlugin interface {
 	Plugin
 	Deschedule(ctx context.Context, nodes []*corev1.Node) *Status
@@ -38,8 +38,53 @@ var (
 			StabilityLevel: metrics.ALPHA,
 		}, []string{"result", "strategy", "namespace", "node"})
 
+	PodsEvictionDeduplicated = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "pods_eviction_deduplicated",
+			Help:           "Number of eviction attempts skipped because the pod has been evicted in the same descheduling cycle, by the strategy that evicted the pod first, by the strategy that attempted again",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"evicted_by", "strategy"})
+
+	PodsViolatingNodeAffinity = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "pods_violating_node_affinity",
+			Help:           "Number of pods violating node affinity found in the last descheduling cycle, by the node name, by the node affinity type",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"node", "affinity_type"})
+
+	ProfileNodesMatched = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "profile_nodes_matched",
+			Help:           "Number of nodes matched by the node selector of the profile in the last descheduling cycle, by the profile name",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile"})
+
+	DeschedulingInterval = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "descheduling_interval_seconds",
+			Help:           "The effective interval between the descheduling cycles in seconds, which adapts to the cluster churn if the adaptive interval is enabled",
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	EvictionNotificationFailures = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "eviction_notification_failures",
+			Help:           "Number of eviction notifications failed to deliver to the endpoint, by the reason. 'circuit_open' reason means the notification is dropped since the circuit breaker is open",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"reason"})
+
 	metricsList = []metrics.Registerable{
 		PodsEvicted,
+		PodsEvictionDeduplicated,
+		PodsViolatingNodeAffinity,
+		ProfileNodesMatched,
+		DeschedulingInterval,
+		EvictionNotificationFailures,
 	}
 )
 
@@ -29,10 +29,10 @@ import (
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
-	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
 
 	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/utils"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 // ReadyNodes returns ready nodes irrespective of whether they are
@@ -189,6 +189,42 @@ func PodFitsCurrentNode(nodeIndexer podutil.GetPodsAssignedToNodeFunc, pod *core
 	return false
 }
 
+// PodFitsNodeWithReserved checks if the given pod fits the node as NodeFit does, excluding the resources reserved
+// on the node for the pods which are going to be rescheduled onto it.
+func PodFitsNodeWithReserved(nodeIndexer podutil.GetPodsAssignedToNodeFunc, pod *corev1.Pod, node *corev1.Node, reserved corev1.ResourceList) bool {
+	if errors := NodeFit(nodeIndexer, pod, node); len(errors) > 0 {
+		klog.V(5).InfoS("Pod does not fit on node", "pod", klog.KObj(pod), "node", klog.KObj(node), "errors", utilerrors.NewAggregate(errors))
+		return false
+	}
+	if len(reserved) == 0 {
+		return true
+	}
+
+	requests := util.GetPodEffectiveRequest(pod)
+	for name, quantity := range reserved {
+		request := requests[name]
+		request.Add(quantity)
+		requests[name] = request
+	}
+	resourceNames := make([]corev1.ResourceName, 0, len(requests))
+	for name := range requests {
+		resourceNames = append(resourceNames, name)
+	}
+	availableResources, err := nodeAvailableResources(nodeIndexer, node, resourceNames)
+	if err != nil {
+		return false
+	}
+	for _, resourceName := range resourceNames {
+		request := requests[resourceName]
+		availableResource, ok := availableResources[resourceName]
+		if !ok || request.MilliValue() > availableResource.MilliValue() {
+			klog.V(5).InfoS("Pod does not fit on node with the reserved resources", "pod", klog.KObj(pod), "node", klog.KObj(node), "resource", resourceName)
+			return false
+		}
+	}
+	return true
+}
+
 // IsNodeUnschedulable checks if the node is unschedulable. This is a helper function to check only in case of
 // underutilized node so that they won't be accounted for.
 func IsNodeUnschedulable(node *corev1.Node) bool {
@@ -201,7 +237,7 @@ func fitsRequest(nodeIndexer podutil.GetPodsAssignedToNodeFunc, pod *corev1.Pod,
 	var insufficientResources []error
 
 	// Get pod requests
-	podRequests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	podRequests := util.GetPodEffectiveRequest(pod)
 	resourceNames := make([]corev1.ResourceName, 0, len(podRequests))
 	for name := range podRequests {
 		resourceNames = append(resourceNames, name)
@@ -264,7 +300,7 @@ func NodeUtilization(pods []*corev1.Pod, resourceNames []corev1.ResourceName) ma
 	}
 
 	for _, pod := range pods {
-		req, _ := resourcehelper.PodRequestsAndLimits(pod)
+		req := util.GetPodEffectiveRequest(pod)
 		for _, name := range resourceNames {
 			quantity, ok := req[name]
 			if ok && name != corev1.ResourcePods {
@@ -913,3 +913,234 @@ func TestPodFitsAnyNode(t *testing.T) {
 		})
 	}
 }
+
+func TestPodFitsAnyNodeWithPodOverhead(t *testing.T) {
+	withOverhead := func(pod *corev1.Pod) {
+		pod.Spec.Overhead = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("200m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		}
+	}
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		success bool
+	}{
+		{
+			name:    "pod without overhead fits the node",
+			pod:     test.BuildTestPod("p2", 700, 0, "", nil),
+			success: true,
+		},
+		{
+			name:    "pod overhead exceeds the remaining cpu",
+			pod:     test.BuildTestPod("p2", 700, 0, "", withOverhead),
+			success: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			node := test.BuildTestNode("node1", 2000, 4*1000*1000*1000, 10, nil)
+			// the assigned pod occupies 1000m + 200m overhead
+			assigned := test.BuildTestPod("p1", 1000, 0, node.Name, withOverhead)
+
+			fakeClient := fake.NewSimpleClientset(node, assigned, tt.pod)
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods()
+			getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(podInformer)
+			if err != nil {
+				t.Errorf("Build get pods assigned to node function error: %v", err)
+			}
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			actual := PodFitsAnyNode(getPodsAssignedToNode, tt.pod, []*corev1.Node{node})
+			if actual != tt.success {
+				t.Errorf("Test %#v failed", tt.name)
+			}
+		})
+	}
+}
+
+func TestPodFitsNodeAffinityWithMatchFields(t *testing.T) {
+	nodeLabelKey := "kubernetes.io/desiredNode"
+	nodeLabelValue := "yes"
+	buildPod := func(terms ...corev1.NodeSelectorTerm) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test-pod",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node1",
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: terms,
+						},
+					},
+				},
+			},
+		}
+	}
+	// the node field selector only accepts one value for the In and NotIn operators
+	nodeNameIn := func(name string) []corev1.NodeSelectorRequirement {
+		return []corev1.NodeSelectorRequirement{
+			{
+				Key:      "metadata.name",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{name},
+			},
+		}
+	}
+	desiredNodeIn := []corev1.NodeSelectorRequirement{
+		{
+			Key:      nodeLabelKey,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{nodeLabelValue},
+		},
+	}
+	buildNode := func(name string, desired bool) *corev1.Node {
+		return test.BuildTestNode(name, 64000, 128*1000*1000*1000, 200, func(node *corev1.Node) {
+			if desired {
+				node.ObjectMeta.Labels = map[string]string{
+					nodeLabelKey: nodeLabelValue,
+				}
+			}
+		})
+	}
+
+	tests := []struct {
+		name           string
+		pod            *corev1.Pod
+		currentNode    *corev1.Node
+		nodes          []*corev1.Node
+		wantFitCurrent bool
+		wantFitAny     bool
+	}{
+		{
+			name:           "pod pinned to the existing current node",
+			pod:            buildPod(corev1.NodeSelectorTerm{MatchFields: nodeNameIn("node1")}),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", false)},
+			wantFitCurrent: true,
+			wantFitAny:     true,
+		},
+		{
+			name:           "pod pinned to another existing node",
+			pod:            buildPod(corev1.NodeSelectorTerm{MatchFields: nodeNameIn("node2")}),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", false)},
+			wantFitCurrent: false,
+			wantFitAny:     true,
+		},
+		{
+			name:           "pod pinned to a deleted node",
+			pod:            buildPod(corev1.NodeSelectorTerm{MatchFields: nodeNameIn("node-deleted")}),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", false)},
+			wantFitCurrent: false,
+			wantFitAny:     false,
+		},
+		{
+			name:           "pod pinned to the node replaced by another name",
+			pod:            buildPod(corev1.NodeSelectorTerm{MatchFields: nodeNameIn("node1")}),
+			currentNode:    buildNode("node1-replaced", false),
+			nodes:          []*corev1.Node{buildNode("node1-replaced", false), buildNode("node2", false)},
+			wantFitCurrent: false,
+			wantFitAny:     false,
+		},
+		{
+			name: "pod pinned to nodes not in the list",
+			pod: buildPod(corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+				{
+					Key:      "metadata.name",
+					Operator: corev1.NodeSelectorOpNotIn,
+					Values:   []string{"node1"},
+				},
+			}}),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", false)},
+			wantFitCurrent: false,
+			wantFitAny:     true,
+		},
+		{
+			name: "matchFields and matchExpressions in the same term are both required",
+			pod: buildPod(corev1.NodeSelectorTerm{
+				MatchExpressions: desiredNodeIn,
+				MatchFields:      nodeNameIn("node2"),
+			}),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", true), buildNode("node3", true)},
+			wantFitCurrent: false,
+			wantFitAny:     true,
+		},
+		{
+			name: "matchFields and matchExpressions in the same term match no node",
+			pod: buildPod(corev1.NodeSelectorTerm{
+				MatchExpressions: desiredNodeIn,
+				MatchFields:      nodeNameIn("node1"),
+			}),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", true)},
+			wantFitCurrent: false,
+			wantFitAny:     false,
+		},
+		{
+			name: "either matchFields term or matchExpressions term is satisfied",
+			pod: buildPod(
+				corev1.NodeSelectorTerm{MatchFields: nodeNameIn("node1")},
+				corev1.NodeSelectorTerm{MatchExpressions: desiredNodeIn},
+			),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", true)},
+			wantFitCurrent: true,
+			wantFitAny:     true,
+		},
+		{
+			name: "pod pinned to a deleted node falls back to the matchExpressions term",
+			pod: buildPod(
+				corev1.NodeSelectorTerm{MatchFields: nodeNameIn("node-deleted")},
+				corev1.NodeSelectorTerm{MatchExpressions: desiredNodeIn},
+			),
+			currentNode:    buildNode("node1", false),
+			nodes:          []*corev1.Node{buildNode("node1", false), buildNode("node2", true)},
+			wantFitCurrent: false,
+			wantFitAny:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var objs []runtime.Object
+			for _, node := range tt.nodes {
+				objs = append(objs, node)
+			}
+			objs = append(objs, tt.pod)
+
+			fakeClient := fake.NewSimpleClientset(objs...)
+
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods()
+
+			getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(podInformer)
+			if err != nil {
+				t.Errorf("Build get pods assigned to node function error: %v", err)
+			}
+
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			if got := PodFitsCurrentNode(getPodsAssignedToNode, tt.pod, tt.currentNode); got != tt.wantFitCurrent {
+				t.Errorf("PodFitsCurrentNode() = %v, want %v", got, tt.wantFitCurrent)
+			}
+			if got := PodFitsAnyNode(getPodsAssignedToNode, tt.pod, tt.nodes); got != tt.wantFitAny {
+				t.Errorf("PodFitsAnyNode() = %v, want %v", got, tt.wantFitAny)
+			}
+		})
+	}
+}
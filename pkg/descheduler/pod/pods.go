@@ -128,6 +128,20 @@ func ListPodsOnANode(
 	return ListAllPodsOnANode(nodeName, getPodsAssignedToNode, WrapFilterFuncs(f, filter))
 }
 
+// ListEvictablePodsOnANode lists the non-terminated pods on a node passing the Evictor filter of the handle,
+// and further limited by the filter. If the handle implements framework.EvictablePodsLister, the pods passing
+// the Evictor filter are memoized in the descheduling cycle, and only the filter is applied on each call.
+func ListEvictablePodsOnANode(
+	handle framework.Handle,
+	nodeName string,
+	filter FilterFunc,
+) ([]*corev1.Pod, error) {
+	if lister, ok := handle.(framework.EvictablePodsLister); ok {
+		return lister.ListEvictablePodsOnANode(nodeName, filter)
+	}
+	return ListPodsOnANode(nodeName, handle.GetPodsAssignedToNodeFunc(), WrapFilterFuncs(filter, handle.Evictor().Filter))
+}
+
 // ListAllPodsOnANode lists all the pods on a node no matter what the phase of the pod is.
 func ListAllPodsOnANode(
 	nodeName string,
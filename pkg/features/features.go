@@ -36,16 +36,20 @@ const (
 	// ElasticQuotaValidatingWebhook enables validating webhook for ElasticQuotas creations or updates
 	ElasticQuotaValidatingWebhook featuregate.Feature = "ElasticValidatingWebhook"
 
+	// ColocationProfileValidatingWebhook enables validating webhook for ClusterColocationProfiles creations or updates
+	ColocationProfileValidatingWebhook featuregate.Feature = "ColocationProfileValidatingWebhook"
+
 	// WebhookFramework enables webhook framework
 	WebhookFramework featuregate.Feature = "WebhookFramework"
 )
 
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-	PodMutatingWebhook:            {Default: true, PreRelease: featuregate.Beta},
-	PodValidatingWebhook:          {Default: true, PreRelease: featuregate.Beta},
-	ElasticQuotaMutatingWebhook:   {Default: true, PreRelease: featuregate.Beta},
-	ElasticQuotaValidatingWebhook: {Default: true, PreRelease: featuregate.Beta},
-	WebhookFramework:              {Default: true, PreRelease: featuregate.Beta},
+	PodMutatingWebhook:                 {Default: true, PreRelease: featuregate.Beta},
+	PodValidatingWebhook:               {Default: true, PreRelease: featuregate.Beta},
+	ElasticQuotaMutatingWebhook:        {Default: true, PreRelease: featuregate.Beta},
+	ElasticQuotaValidatingWebhook:      {Default: true, PreRelease: featuregate.Beta},
+	ColocationProfileValidatingWebhook: {Default: true, PreRelease: featuregate.Beta},
+	WebhookFramework:                   {Default: true, PreRelease: featuregate.Beta},
 }
 
 func init() {
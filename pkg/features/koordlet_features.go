@@ -103,6 +103,77 @@ const (
 	//
 	// PSICollector enables psi collector feature of koordlet.
 	PSICollector featuregate.Feature = "PSICollector"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// CgroupDriftWatchdog periodically verifies the cgroup values applied by koordlet and repairs the drifted ones.
+	CgroupDriftWatchdog featuregate.Feature = "CgroupDriftWatchdog"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// MemoryLocalityRepair moves the memory of LS containers back to the NUMA nodes allowed by their cpuset.mems.
+	MemoryLocalityRepair featuregate.Feature = "MemoryLocalityRepair"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// OrphanArtifactGC removes the resctrl tasks and tc shapers left by koordlet for the pods no longer existing.
+	OrphanArtifactGC featuregate.Feature = "OrphanArtifactGC"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// MBMCollector collects the memory bandwidth of the resctrl groups by the MBM counters.
+	MBMCollector featuregate.Feature = "MBMCollector"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// MBAFeedback adjusts the MBA percent of the BE resctrl group according to the memory bandwidth of LS pods.
+	MBAFeedback featuregate.Feature = "MBAFeedback"
+
+	// owner: @jasonliu747 @Joseph
+	// alpha: v1.1
+	//
+	// GPUMemoryLeakDetector raises an event on the pod whose GPU memory usage exceeds its allocated share.
+	// It requires the Accelerators feature to collect the GPU memory of the containers.
+	GPUMemoryLeakDetector featuregate.Feature = "GPUMemoryLeakDetector"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// ProcessCollector collects the number of the tasks and the open file descriptors of the containers and the node.
+	ProcessCollector featuregate.Feature = "ProcessCollector"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// BEPIDProtection limits the tasks of the BE pods and evicts the BE pod with the most tasks when the node pid usage
+	// is high. It requires the ProcessCollector feature to collect the tasks of the containers.
+	BEPIDProtection featuregate.Feature = "BEPIDProtection"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// BEOOMFeedback reports a safety margin in the NodeMetric when the BE pods are OOM-killed frequently, which the
+	// slo-controller holds back from the batch memory of the node. The margin decays over time.
+	BEOOMFeedback featuregate.Feature = "BEOOMFeedback"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// BENetIngressProtection limits the RX bandwidth of the BE pods by the tbf qdiscs on their host-side veths, so the
+	// inbound traffic of the BE pods does not saturate the node network. The CNI must route the pod IPs to the veths
+	// directly or through a linux bridge.
+	BENetIngressProtection featuregate.Feature = "BENetIngressProtection"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// DiskIOCollector collects the read and write throughput and iops of the disks under the configured mount points.
+	DiskIOCollector featuregate.Feature = "DiskIOCollector"
 )
 
 func init() {
@@ -126,6 +197,17 @@ var (
 		Accelerators:           {Default: false, PreRelease: featuregate.Alpha},
 		CPICollector:           {Default: false, PreRelease: featuregate.Alpha},
 		PSICollector:           {Default: false, PreRelease: featuregate.Alpha},
+		CgroupDriftWatchdog:    {Default: false, PreRelease: featuregate.Alpha},
+		MemoryLocalityRepair:   {Default: false, PreRelease: featuregate.Alpha},
+		OrphanArtifactGC:       {Default: false, PreRelease: featuregate.Alpha},
+		MBMCollector:           {Default: false, PreRelease: featuregate.Alpha},
+		MBAFeedback:            {Default: false, PreRelease: featuregate.Alpha},
+		GPUMemoryLeakDetector:  {Default: false, PreRelease: featuregate.Alpha},
+		ProcessCollector:       {Default: false, PreRelease: featuregate.Alpha},
+		BEPIDProtection:        {Default: false, PreRelease: featuregate.Alpha},
+		BEOOMFeedback:          {Default: false, PreRelease: featuregate.Alpha},
+		BENetIngressProtection: {Default: false, PreRelease: featuregate.Alpha},
+		DiskIOCollector:        {Default: false, PreRelease: featuregate.Alpha},
 	}
 )
 
@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"flag"
+	"time"
+)
+
+type Config struct {
+	// SocketPath is the path of the unix socket serving the admin commands, empty to disable them.
+	SocketPath string
+	// CheckpointPath is the path of the file keeping the feature pauses across the restarts.
+	CheckpointPath string
+	// MaxPauseDuration is the max duration a feature can be paused for before resumed automatically.
+	MaxPauseDuration time.Duration
+	// SyncInterval is the interval expiring the pauses and syncing them to the metrics and the node condition.
+	SyncInterval time.Duration
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		SocketPath:       "/host-var-run-koordlet/koordlet-admin.sock",
+		CheckpointPath:   "/host-var-run-koordlet/feature-pauses.json",
+		MaxPauseDuration: 24 * time.Hour,
+		SyncInterval:     10 * time.Second,
+	}
+}
+
+func (c *Config) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.SocketPath, "admin-socket-path", c.SocketPath, "The path of the unix socket serving the admin commands pausing and resuming the features, "+
+		"only accessible by the owner of the koordlet. Empty to disable the admin commands")
+	fs.StringVar(&c.CheckpointPath, "admin-checkpoint-path", c.CheckpointPath, "The path of the checkpoint file keeping the feature pauses across the koordlet restarts")
+	fs.DurationVar(&c.MaxPauseDuration, "admin-max-pause-duration", c.MaxPauseDuration, "The max duration a feature can be paused for before resumed automatically")
+}
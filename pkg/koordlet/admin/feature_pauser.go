@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+type ActionType string
+
+const (
+	ActionPaused  ActionType = "Paused"
+	ActionResumed ActionType = "Resumed"
+	ActionExpired ActionType = "Expired"
+	ActionRun     ActionType = "Run"
+	ActionSkipped ActionType = "Skipped"
+)
+
+// Pause is a feature paused temporarily until resumed or expired.
+type Pause struct {
+	Feature featuregate.Feature `json:"feature"`
+	Reason  string              `json:"reason,omitempty"`
+	Since   time.Time           `json:"since"`
+	Until   time.Time           `json:"until"`
+}
+
+// Action is the last action taken on a feature.
+type Action struct {
+	Feature featuregate.Feature `json:"feature"`
+	Action  ActionType          `json:"action"`
+	Time    time.Time           `json:"time"`
+	Reason  string              `json:"reason,omitempty"`
+}
+
+type checkpoint struct {
+	Pauses []Pause `json:"pauses"`
+}
+
+// FeaturePauser pauses the features for a bounded duration and resumes them automatically once expired.
+// The pauses are kept in the checkpoint file to survive the koordlet restarts.
+type FeaturePauser struct {
+	config *Config
+	clock  clock.Clock
+
+	lock    sync.Mutex
+	pauses  map[featuregate.Feature]*Pause
+	actions map[featuregate.Feature]*Action
+	// reported are the features ever paused, whose metrics are reset once resumed.
+	reported map[featuregate.Feature]struct{}
+
+	// syncStatus reflects the pauses on the node, e.g. the node condition.
+	syncStatus func(pauses []Pause) error
+	// isPausable returns whether any running module checks the pauses of the feature.
+	isPausable func(feature featuregate.Feature) bool
+}
+
+func NewFeaturePauser(config *Config, syncStatus func(pauses []Pause) error) *FeaturePauser {
+	return newFeaturePauser(config, clock.RealClock{}, syncStatus)
+}
+
+func newFeaturePauser(config *Config, clock clock.Clock, syncStatus func(pauses []Pause) error) *FeaturePauser {
+	p := &FeaturePauser{
+		config:     config,
+		clock:      clock,
+		pauses:     map[featuregate.Feature]*Pause{},
+		actions:    map[featuregate.Feature]*Action{},
+		reported:   map[featuregate.Feature]struct{}{},
+		syncStatus: syncStatus,
+		isPausable: util.IsFeaturePausable,
+	}
+	if err := p.loadCheckpoint(); err != nil {
+		klog.Errorf("failed to load the feature pauses from checkpoint %s, err: %v", config.CheckpointPath, err)
+	}
+	return p
+}
+
+// Pause pauses the feature for the duration at most MaxPauseDuration. Only the features whose running modules check
+// the pauses can be paused, since pausing the others would take no effect.
+func (p *FeaturePauser) Pause(feature featuregate.Feature, duration time.Duration, reason string) (*Pause, error) {
+	if _, ok := features.DefaultMutableKoordletFeatureGate.GetAll()[feature]; !ok {
+		return nil, fmt.Errorf("unknown feature %s", feature)
+	}
+	if !p.isPausable(feature) {
+		return nil, fmt.Errorf("feature %s cannot be paused, no running module of it checks the pauses", feature)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("pause duration %v must be greater than 0", duration)
+	}
+	if p.config.MaxPauseDuration > 0 && duration > p.config.MaxPauseDuration {
+		return nil, fmt.Errorf("pause duration %v exceeds the max %v", duration, p.config.MaxPauseDuration)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.clock.Now()
+	pause := &Pause{
+		Feature: feature,
+		Reason:  reason,
+		Since:   now,
+		Until:   now.Add(duration),
+	}
+	lastPause, lastAction := p.pauses[feature], p.actions[feature]
+	_, reported := p.reported[feature]
+	p.pauses[feature] = pause
+	p.reported[feature] = struct{}{}
+	p.recordAction(feature, ActionPaused, now, reason)
+	if err := p.saveCheckpoint(); err != nil {
+		// roll back so the pauses in memory never diverge from the checkpoint
+		p.restoreLocked(feature, lastPause, lastAction)
+		if !reported {
+			delete(p.reported, feature)
+		}
+		return nil, fmt.Errorf("failed to save the pause of feature %s, err: %w", feature, err)
+	}
+	klog.Infof("feature %s is paused until %v, reason: %s", feature, pause.Until, reason)
+	out := *pause
+	return &out, nil
+}
+
+// Resume resumes the paused feature before expired.
+func (p *FeaturePauser) Resume(feature featuregate.Feature) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	lastPause, ok := p.pauses[feature]
+	if !ok {
+		return fmt.Errorf("feature %s is not paused", feature)
+	}
+	lastAction := p.actions[feature]
+	delete(p.pauses, feature)
+	p.recordAction(feature, ActionResumed, p.clock.Now(), "")
+	if err := p.saveCheckpoint(); err != nil {
+		p.restoreLocked(feature, lastPause, lastAction)
+		return fmt.Errorf("failed to save the resume of feature %s, err: %w", feature, err)
+	}
+	klog.Infof("feature %s is resumed", feature)
+	return nil
+}
+
+// IsPaused returns whether the feature is paused and not expired yet.
+func (p *FeaturePauser) IsPaused(feature featuregate.Feature) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pause, ok := p.pauses[feature]
+	if !ok {
+		return false
+	}
+	if p.clock.Now().Before(pause.Until) {
+		return true
+	}
+	p.expireLocked()
+	return false
+}
+
+// RecordRun records the feature module is run or skipped for the pause.
+func (p *FeaturePauser) RecordRun(feature featuregate.Feature, skipped bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if skipped {
+		p.recordAction(feature, ActionSkipped, p.clock.Now(), "")
+	} else {
+		p.recordAction(feature, ActionRun, p.clock.Now(), "")
+	}
+}
+
+// List returns the current pauses sorted by the feature.
+func (p *FeaturePauser) List() []Pause {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.expireLocked()
+	return p.listLocked()
+}
+
+// Actions returns the last action of each feature sorted by the feature.
+func (p *FeaturePauser) Actions() []Action {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.expireLocked()
+	actions := make([]Action, 0, len(p.actions))
+	for _, action := range p.actions {
+		actions = append(actions, *action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Feature < actions[j].Feature
+	})
+	return actions
+}
+
+// Run expires the pauses and syncs them to the metrics and the node status periodically.
+func (p *FeaturePauser) Run(stopCh <-chan struct{}) {
+	wait.Until(p.sync, p.config.SyncInterval, stopCh)
+}
+
+func (p *FeaturePauser) sync() {
+	p.lock.Lock()
+	p.expireLocked()
+	pauses := p.listLocked()
+	for feature := range p.reported {
+		_, paused := p.pauses[feature]
+		metrics.RecordFeaturePaused(string(feature), paused)
+	}
+	p.lock.Unlock()
+
+	if p.syncStatus != nil {
+		if err := p.syncStatus(pauses); err != nil {
+			klog.Warningf("failed to sync the feature pauses to the node status, err: %v", err)
+		}
+	}
+}
+
+func (p *FeaturePauser) listLocked() []Pause {
+	pauses := make([]Pause, 0, len(p.pauses))
+	for _, pause := range p.pauses {
+		pauses = append(pauses, *pause)
+	}
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].Feature < pauses[j].Feature
+	})
+	return pauses
+}
+
+func (p *FeaturePauser) expireLocked() {
+	now := p.clock.Now()
+	expired := false
+	for feature, pause := range p.pauses {
+		if now.Before(pause.Until) {
+			continue
+		}
+		delete(p.pauses, feature)
+		p.recordAction(feature, ActionExpired, now, pause.Reason)
+		klog.Infof("feature %s is resumed since the pause expired at %v", feature, pause.Until)
+		expired = true
+	}
+	if !expired {
+		return
+	}
+	if err := p.saveCheckpoint(); err != nil {
+		klog.Errorf("failed to save the feature pauses to checkpoint %s, err: %v", p.config.CheckpointPath, err)
+	}
+}
+
+// restoreLocked restores the pause and the last action of the feature, which are removed if nil.
+func (p *FeaturePauser) restoreLocked(feature featuregate.Feature, pause *Pause, action *Action) {
+	if pause != nil {
+		p.pauses[feature] = pause
+	} else {
+		delete(p.pauses, feature)
+	}
+	if action != nil {
+		p.actions[feature] = action
+	} else {
+		delete(p.actions, feature)
+	}
+}
+
+func (p *FeaturePauser) recordAction(feature featuregate.Feature, action ActionType, now time.Time, reason string) {
+	p.actions[feature] = &Action{
+		Feature: feature,
+		Action:  action,
+		Time:    now,
+		Reason:  reason,
+	}
+}
+
+func (p *FeaturePauser) loadCheckpoint() error {
+	if len(p.config.CheckpointPath) == 0 {
+		return nil
+	}
+	data, err := ioutil.ReadFile(p.config.CheckpointPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var c checkpoint
+	if err = json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	now := p.clock.Now()
+	for i := range c.Pauses {
+		pause := c.Pauses[i]
+		if !now.Before(pause.Until) {
+			continue
+		}
+		p.pauses[pause.Feature] = &pause
+		p.reported[pause.Feature] = struct{}{}
+		p.recordAction(pause.Feature, ActionPaused, pause.Since, pause.Reason)
+		klog.Infof("feature %s is paused until %v restored from checkpoint", pause.Feature, pause.Until)
+	}
+	return nil
+}
+
+func (p *FeaturePauser) saveCheckpoint() error {
+	if len(p.config.CheckpointPath) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(&checkpoint{Pauses: p.listLocked()})
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p.config.CheckpointPath), 0700); err != nil {
+		return err
+	}
+	// write to a temporary file and then rename it to never leave a broken checkpoint
+	tmpPath := p.config.CheckpointPath + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, p.config.CheckpointPath)
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/component-base/featuregate"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func newTestConfig(t *testing.T) *Config {
+	cfg := NewDefaultConfig()
+	cfg.CheckpointPath = filepath.Join(t.TempDir(), "feature-pauses.json")
+	cfg.MaxPauseDuration = time.Hour
+	return cfg
+}
+
+// newTestFeaturePauser returns the pauser which takes all the features pausable except Accelerators.
+func newTestFeaturePauser(config *Config, clock clock.Clock, syncStatus func(pauses []Pause) error) *FeaturePauser {
+	p := newFeaturePauser(config, clock, syncStatus)
+	p.isPausable = func(feature featuregate.Feature) bool {
+		return feature != features.Accelerators
+	}
+	return p
+}
+
+func TestFeaturePauser_Pause(t *testing.T) {
+	tests := []struct {
+		name     string
+		feature  featuregate.Feature
+		duration time.Duration
+		wantErr  bool
+	}{
+		{
+			name:     "pause a known feature",
+			feature:  features.BEMemoryEvict,
+			duration: 10 * time.Minute,
+		},
+		{
+			name:     "unknown feature",
+			feature:  "UnknownFeature",
+			duration: 10 * time.Minute,
+			wantErr:  true,
+		},
+		{
+			name:     "feature not pausable",
+			feature:  features.Accelerators,
+			duration: 10 * time.Minute,
+			wantErr:  true,
+		},
+		{
+			name:     "non-positive duration",
+			feature:  features.BEMemoryEvict,
+			duration: 0,
+			wantErr:  true,
+		},
+		{
+			name:     "duration exceeds the max",
+			feature:  features.BEMemoryEvict,
+			duration: 2 * time.Hour,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestFeaturePauser(newTestConfig(t), clock.NewFakeClock(time.Now()), nil)
+			_, err := p.Pause(tt.feature, tt.duration, "incident")
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, !tt.wantErr, p.IsPaused(tt.feature))
+			assert.False(t, p.IsPaused(features.BECPUEvict))
+		})
+	}
+}
+
+func TestFeaturePauser_Expire(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	p := newTestFeaturePauser(newTestConfig(t), fakeClock, nil)
+	_, err := p.Pause(features.BEMemoryEvict, 10*time.Minute, "incident")
+	assert.NoError(t, err)
+	_, err = p.Pause(features.BECPUEvict, 30*time.Minute, "incident")
+	assert.NoError(t, err)
+
+	p.RecordRun(features.BEMemoryEvict, true)
+	assert.Equal(t, ActionSkipped, p.Actions()[1].Action)
+
+	fakeClock.Step(10 * time.Minute)
+	assert.False(t, p.IsPaused(features.BEMemoryEvict))
+	assert.True(t, p.IsPaused(features.BECPUEvict))
+	pauses := p.List()
+	assert.Equal(t, 1, len(pauses))
+	assert.Equal(t, features.BECPUEvict, pauses[0].Feature)
+
+	actions := p.Actions()
+	assert.Equal(t, 2, len(actions))
+	assert.Equal(t, features.BECPUEvict, actions[0].Feature)
+	assert.Equal(t, ActionPaused, actions[0].Action)
+	assert.Equal(t, features.BEMemoryEvict, actions[1].Feature)
+	assert.Equal(t, ActionExpired, actions[1].Action)
+
+	assert.NoError(t, p.Resume(features.BECPUEvict))
+	assert.False(t, p.IsPaused(features.BECPUEvict))
+	assert.Equal(t, ActionResumed, p.Actions()[0].Action)
+	assert.Error(t, p.Resume(features.BECPUEvict))
+}
+
+func TestFeaturePauser_Checkpoint(t *testing.T) {
+	cfg := newTestConfig(t)
+	fakeClock := clock.NewFakeClock(time.Now())
+	p := newTestFeaturePauser(cfg, fakeClock, nil)
+	_, err := p.Pause(features.BEMemoryEvict, 10*time.Minute, "incident")
+	assert.NoError(t, err)
+	_, err = p.Pause(features.BECPUEvict, 30*time.Minute, "incident")
+	assert.NoError(t, err)
+	_, err = p.Pause(features.BECPUSuppress, 30*time.Minute, "incident")
+	assert.NoError(t, err)
+	assert.NoError(t, p.Resume(features.BECPUSuppress))
+
+	// the pauses survive the restart
+	restarted := newTestFeaturePauser(cfg, fakeClock, nil)
+	expected, got := p.List(), restarted.List()
+	assert.Equal(t, 2, len(got))
+	for i := range expected {
+		assert.Equal(t, expected[i].Feature, got[i].Feature)
+		assert.True(t, expected[i].Until.Equal(got[i].Until))
+	}
+	assert.True(t, restarted.IsPaused(features.BEMemoryEvict))
+	assert.True(t, restarted.IsPaused(features.BECPUEvict))
+	assert.False(t, restarted.IsPaused(features.BECPUSuppress))
+
+	// the pauses expired during the restart are dropped
+	fakeClock.Step(20 * time.Minute)
+	restarted = newTestFeaturePauser(cfg, fakeClock, nil)
+	pauses := restarted.List()
+	assert.Equal(t, 1, len(pauses))
+	assert.Equal(t, features.BECPUEvict, pauses[0].Feature)
+	assert.Equal(t, "incident", pauses[0].Reason)
+
+	// the checkpoint is updated once the pause expired
+	fakeClock.Step(10 * time.Minute)
+	assert.False(t, restarted.IsPaused(features.BECPUEvict))
+	restarted = newTestFeaturePauser(cfg, clock.NewFakeClock(fakeClock.Now().Add(-time.Hour)), nil)
+	assert.Equal(t, 0, len(restarted.List()))
+}
+
+func TestFeaturePauser_CheckpointFailure(t *testing.T) {
+	cfg := newTestConfig(t)
+	p := newTestFeaturePauser(cfg, clock.NewFakeClock(time.Now()), nil)
+	_, err := p.Pause(features.BECPUEvict, 10*time.Minute, "incident")
+	assert.NoError(t, err)
+
+	// the checkpoint cannot be written under a regular file
+	validPath := cfg.CheckpointPath
+	cfg.CheckpointPath = filepath.Join(validPath, "feature-pauses.json")
+	_, err = p.Pause(features.BEMemoryEvict, 10*time.Minute, "incident")
+	assert.Error(t, err)
+	assert.False(t, p.IsPaused(features.BEMemoryEvict))
+	assert.Equal(t, 1, len(p.Actions()))
+
+	// the pause is kept if the resume cannot be saved
+	assert.Error(t, p.Resume(features.BECPUEvict))
+	assert.True(t, p.IsPaused(features.BECPUEvict))
+	assert.Equal(t, ActionPaused, p.Actions()[0].Action)
+
+	cfg.CheckpointPath = validPath
+	assert.NoError(t, p.Resume(features.BECPUEvict))
+	assert.False(t, p.IsPaused(features.BECPUEvict))
+}
+
+func TestFeaturePauser_Sync(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	var synced []Pause
+	p := newTestFeaturePauser(newTestConfig(t), fakeClock, func(pauses []Pause) error {
+		synced = pauses
+		return nil
+	})
+	_, err := p.Pause(features.BEMemoryEvict, 10*time.Minute, "incident")
+	assert.NoError(t, err)
+	p.sync()
+	assert.Equal(t, 1, len(synced))
+	assert.Equal(t, features.BEMemoryEvict, synced[0].Feature)
+
+	fakeClock.Step(10 * time.Minute)
+	p.sync()
+	assert.Equal(t, 0, len(synced))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// NodeConditionFeaturesPaused is true when some koordlet features are paused by the admin commands.
+	NodeConditionFeaturesPaused corev1.NodeConditionType = "KoordletFeaturesPaused"
+
+	reasonFeaturesPaused  = "FeaturesPaused"
+	reasonNoFeaturePaused = "NoFeaturePaused"
+)
+
+// NewNodeConditionSyncer returns the func reflecting the feature pauses on the node condition.
+func NewNodeConditionSyncer(kubeClient clientset.Interface, nodeName string) func(pauses []Pause) error {
+	return func(pauses []Pause) error {
+		return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			newNode := node.DeepCopy()
+			if !setFeaturesPausedCondition(newNode, pauses, metav1.Now()) {
+				return nil
+			}
+			_, err = kubeClient.CoreV1().Nodes().UpdateStatus(context.TODO(), newNode, metav1.UpdateOptions{})
+			return err
+		})
+	}
+}
+
+// setFeaturesPausedCondition sets the condition of the feature pauses and returns whether the node is changed.
+func setFeaturesPausedCondition(node *corev1.Node, pauses []Pause, now metav1.Time) bool {
+	condition := corev1.NodeCondition{
+		Type:               NodeConditionFeaturesPaused,
+		Status:             corev1.ConditionFalse,
+		Reason:             reasonNoFeaturePaused,
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+	}
+	if len(pauses) > 0 {
+		messages := make([]string, 0, len(pauses))
+		for _, pause := range pauses {
+			messages = append(messages, fmt.Sprintf("%s until %s", pause.Feature, pause.Until.UTC().Format(time.RFC3339)))
+		}
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = reasonFeaturesPaused
+		condition.Message = strings.Join(messages, ", ")
+	}
+
+	for i := range node.Status.Conditions {
+		old := &node.Status.Conditions[i]
+		if old.Type != NodeConditionFeaturesPaused {
+			continue
+		}
+		if old.Status == condition.Status && old.Reason == condition.Reason && old.Message == condition.Message {
+			return false
+		}
+		if old.Status == condition.Status {
+			condition.LastTransitionTime = old.LastTransitionTime
+		}
+		*old = condition
+		return true
+	}
+	// never add the condition until any feature is paused
+	if len(pauses) == 0 {
+		return false
+	}
+	node.Status.Conditions = append(node.Status.Conditions, condition)
+	return true
+}
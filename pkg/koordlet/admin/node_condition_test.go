@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func TestNodeConditionSyncer(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+	kubeClient := fake.NewSimpleClientset(node)
+	syncer := NewNodeConditionSyncer(kubeClient, "test-node")
+
+	// never add the condition until any feature is paused
+	assert.NoError(t, syncer(nil))
+	got, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), "test-node", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(got.Status.Conditions))
+
+	until := time.Date(2023, 1, 1, 0, 10, 0, 0, time.UTC)
+	assert.NoError(t, syncer([]Pause{{Feature: features.BEMemoryEvict, Until: until}}))
+	got, err = kubeClient.CoreV1().Nodes().Get(context.TODO(), "test-node", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(got.Status.Conditions))
+	assert.Equal(t, NodeConditionFeaturesPaused, got.Status.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, "BEMemoryEvict until 2023-01-01T00:10:00Z", got.Status.Conditions[0].Message)
+
+	assert.NoError(t, syncer(nil))
+	got, err = kubeClient.CoreV1().Nodes().Get(context.TODO(), "test-node", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(got.Status.Conditions))
+	assert.Equal(t, corev1.ConditionFalse, got.Status.Conditions[0].Status)
+	assert.Equal(t, reasonNoFeaturePaused, got.Status.Conditions[0].Reason)
+}
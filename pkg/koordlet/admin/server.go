@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+)
+
+const (
+	PathPause   = "/features/pause"
+	PathResume  = "/features/resume"
+	PathPauses  = "/features/pauses"
+	PathActions = "/features/actions"
+)
+
+// Server serves the admin commands on the unix socket. The commands are authorized by the socket file permissions,
+// which only allow the owner of the koordlet to connect.
+type Server struct {
+	config *Config
+	pauser *FeaturePauser
+}
+
+func NewServer(config *Config, pauser *FeaturePauser) *Server {
+	return &Server{
+		config: config,
+		pauser: pauser,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPause, s.handlePause)
+	mux.HandleFunc(PathResume, s.handleResume)
+	mux.HandleFunc(PathPauses, s.handlePauses)
+	mux.HandleFunc(PathActions, s.handleActions)
+	return mux
+}
+
+func (s *Server) Run(stopCh <-chan struct{}) error {
+	listener, err := listenUnixSocket(s.config.SocketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.config.SocketPath)
+
+	server := &http.Server{Handler: s.Handler()}
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+	klog.Infof("starting the admin server on %s", s.config.SocketPath)
+	if err = server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// listenUnixSocket listens on the unix socket only the owner can connect. The socket is created in a private directory
+// and moved to the path after its permissions are restricted, since the directory of the path can be shared with the
+// others and the socket created by net.Listen is connectable by anyone before it is chmod'ed.
+func listenUnixSocket(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	privateDir, err := os.MkdirTemp(filepath.Dir(path), ".koordlet-admin-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(privateDir)
+	privatePath := filepath.Join(privateDir, filepath.Base(path))
+	listener, err := net.Listen("unix", privatePath)
+	if err != nil {
+		return nil, err
+	}
+	// the socket is moved, so it is removed by the server instead of the listener
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err = os.Chmod(privatePath, 0600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	// replace the stale socket left by the previous koordlet
+	if err = os.Rename(privatePath, path); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+	query := r.URL.Query()
+	duration, err := time.ParseDuration(query.Get("duration"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid duration %q, err: %v", query.Get("duration"), err))
+		return
+	}
+	pause, err := s.pauser.Pause(featuregate.Feature(query.Get("feature")), duration, query.Get("reason"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, pause)
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+	if err := s.pauser.Resume(featuregate.Feature(r.URL.Query().Get("feature"))); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, s.pauser.List())
+}
+
+func (s *Server) handlePauses(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.pauser.List())
+}
+
+func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.pauser.Actions())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.Warningf("failed to write the admin response, err: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	http.Error(w, err.Error(), code)
+}
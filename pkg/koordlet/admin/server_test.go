@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func TestServer(t *testing.T) {
+	cfg := newTestConfig(t)
+	p := newTestFeaturePauser(cfg, clock.NewFakeClock(time.Now()), nil)
+	handler := NewServer(cfg, p).Handler()
+
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+		return w
+	}
+
+	w := serve(http.MethodGet, PathPause+"?feature=BEMemoryEvict&duration=10m")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	w = serve(http.MethodPost, PathPause+"?feature=BEMemoryEvict&duration=invalid")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(http.MethodPost, PathPause+"?feature=BEMemoryEvict&duration=10m&reason=incident")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, p.IsPaused(features.BEMemoryEvict))
+
+	w = serve(http.MethodGet, PathPauses)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var pauses []Pause
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pauses))
+	assert.Equal(t, 1, len(pauses))
+	assert.Equal(t, features.BEMemoryEvict, pauses[0].Feature)
+	assert.Equal(t, "incident", pauses[0].Reason)
+
+	w = serve(http.MethodPost, PathResume+"?feature=BEMemoryEvict")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, p.IsPaused(features.BEMemoryEvict))
+	w = serve(http.MethodPost, PathResume+"?feature=BEMemoryEvict")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(http.MethodGet, PathActions)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actions []Action
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actions))
+	assert.Equal(t, 1, len(actions))
+	assert.Equal(t, ActionResumed, actions[0].Action)
+}
+
+func TestServerRun(t *testing.T) {
+	// the unix socket path is limited in length
+	t.Setenv("TMPDIR", "/tmp")
+	cfg := newTestConfig(t)
+	cfg.SocketPath = filepath.Join(t.TempDir(), "admin", "koordlet-admin.sock")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(cfg.SocketPath), 0755))
+	// the stale socket left by the previous koordlet
+	assert.NoError(t, os.WriteFile(cfg.SocketPath, nil, 0666))
+	p := newTestFeaturePauser(cfg, clock.NewFakeClock(time.Now()), nil)
+
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- NewServer(cfg, p).Run(stopCh)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", cfg.SocketPath)
+		},
+	}}
+	assert.Eventually(t, func() bool {
+		resp, err := client.Get("http://koordlet" + PathPauses)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	info, err := os.Stat(cfg.SocketPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeSocket|0600, info.Mode())
+	// the private directory the socket is created in is removed
+	entries, err := os.ReadDir(filepath.Dir(cfg.SocketPath))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	close(stopCh)
+	assert.NoError(t, <-errCh)
+	_, err = os.Stat(cfg.SocketPath)
+	assert.True(t, os.IsNotExist(err))
+}
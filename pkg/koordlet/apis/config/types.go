@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KoordletConfiguration configures a koordlet
+type KoordletConfiguration struct {
+	metav1.TypeMeta
+
+	// ConfigMapName is the name of the configmap which koordlet loads the extra configs from.
+	ConfigMapName string
+	// ConfigMapNamespace is the namespace of the configmap which koordlet loads the extra configs from.
+	ConfigMapNamespace string
+	// FeatureGates is a map of feature names to bools that enable or disable alpha/experimental features.
+	FeatureGates map[string]bool
+	// Profile presets the collector intervals, the metric retention and the sync intervals, "standard" or "edge".
+	// The settings set explicitly by the flags or the fields of this configuration override the preset.
+	Profile string
+
+	// HostPaths specifies the paths of the host directories mounted into the koordlet container.
+	HostPaths HostPathsConfiguration
+	// StatesInformer configures how koordlet syncs the states of the node and pods.
+	StatesInformer StatesInformerConfiguration
+	// MetricsAdvisor configures the metric collectors.
+	MetricsAdvisor MetricsAdvisorConfiguration
+	// MetricCache configures the storage of the collected metrics.
+	MetricCache MetricCacheConfiguration
+	// ResManager configures the resource manager.
+	ResManager ResManagerConfiguration
+	// QoSManager configures the QoS manager.
+	QoSManager QoSManagerConfiguration
+	// RuntimeHooks configures the runtime hooks server.
+	RuntimeHooks RuntimeHooksConfiguration
+	// Audit configures the audit log.
+	Audit AuditConfiguration
+	// ResourceExecutor configures the executor updating the cgroups and system files.
+	ResourceExecutor ResourceExecutorConfiguration
+	// Admin configures the admin commands pausing and resuming the features.
+	Admin AdminConfiguration
+}
+
+// HostPathsConfiguration specifies the host paths. An empty path keeps the default of the agent mode.
+type HostPathsConfiguration struct {
+	CgroupRootDir      string
+	CgroupKubeDir      string
+	SysRootDir         string
+	SysFSRootDir       string
+	ProcRootDir        string
+	VarRunRootDir      string
+	NodeNameOverride   string
+	ContainerdEndpoint string
+	DockerEndpoint     string
+}
+
+type StatesInformerConfiguration struct {
+	KubeletPreferredAddressType string
+	KubeletSyncInterval         metav1.Duration
+	KubeletSyncTimeout          metav1.Duration
+	KubeletInsecureTLS          bool
+	KubeletReadOnlyPort         int32
+	NodeTopologySyncInterval    metav1.Duration
+	DisableQueryKubeletConfig   bool
+	EnableNodeMetricReport      bool
+	APIWriterQPS                float64
+	APIWriterBurst              int32
+	APIWriterCoalesceWindow     metav1.Duration
+}
+
+type MetricsAdvisorConfiguration struct {
+	CollectResUsedIntervalSeconds     int32
+	CollectNodeCPUInfoIntervalSeconds int32
+	CPICollectorIntervalSeconds       int32
+	PSICollectorIntervalSeconds       int32
+	CPICollectorTimeWindowSeconds     int32
+	MBMCollectorIntervalSeconds       int32
+	ProcessCollectorIntervalSeconds   int32
+	ProcessFDSampleBudget             int32
+	DiskIOCollectorIntervalSeconds    int32
+	DiskIOCollectMountPoints          []string
+}
+
+type MetricCacheConfiguration struct {
+	MetricGCIntervalSeconds int32
+	MetricExpireSeconds     int32
+}
+
+type ResManagerConfiguration struct {
+	ReconcileIntervalSeconds   int32
+	CPUSuppressIntervalSeconds int32
+	CPUEvictIntervalSeconds    int32
+	MemoryEvictIntervalSeconds int32
+	MemoryEvictCoolTimeSeconds int32
+	CPUEvictCoolTimeSeconds    int32
+
+	CgroupVerifyIntervalSeconds  int32
+	CgroupVerifySampleRatio      float64
+	CgroupVerifyMaxFilesPerCycle int32
+	CgroupDriftWarningThreshold  int32
+
+	MemoryLocalityRepairIntervalSeconds    int32
+	MemoryLocalityRepairMode               string
+	MemoryLocalitySampleContainersPerCycle int32
+	MemoryLocalityRepairContainersPerCycle int32
+	MemoryLocalityNodeCPUThresholdPercent  int64
+	MemoryLocalityExpandSeconds            int32
+
+	OrphanArtifactGCIntervalSeconds int32
+	OrphanArtifactGCDryRun          bool
+
+	MBAFeedbackIntervalSeconds int32
+	MBAFeedbackWindowSeconds   int32
+	MBAFeedbackDegradePercent  int64
+	MBAFeedbackRelaxRounds     int32
+
+	GPUMemoryLeakCheckIntervalSeconds int32
+	GPUMemoryLeakMarginPercent        int64
+	GPUMemoryLeakConsecutiveSamples   int32
+
+	PIDProtectionIntervalSeconds int32
+	PIDEvictCoolTimeSeconds      int32
+
+	NetIngressProtectionIntervalSeconds int32
+
+	// QOSExtensionPlugins is a map of the qos extension plugins to bools that enable or disable them.
+	QOSExtensionPlugins map[string]bool
+}
+
+type QoSManagerConfiguration struct {
+	// Plugins is a map of the QoS manager plugins to bools that enable or disable them.
+	Plugins map[string]bool
+}
+
+type RuntimeHooksConfiguration struct {
+	Network             string
+	Addr                string
+	FailurePolicy       string
+	PluginFailurePolicy string
+	// ConfigFilePath is the config file path for runtime hooks. An empty path keeps the default of the agent mode.
+	ConfigFilePath string
+	HostEndpoint   string
+	DisableStages  []string
+
+	// the pods managed by other agents are excluded from the runtime hooks
+	ExclusionPodSelectors []string
+	ExclusionOwnerKinds   []string
+	ExclusionHooks        map[string]bool
+}
+
+type AuditConfiguration struct {
+	LogDir               string
+	Verbose              int32
+	MaxDiskSpaceMB       int32
+	MaxConcurrentReaders int32
+	MaxEventsLimit       int32
+}
+
+type ResourceExecutorConfiguration struct {
+	ResourceForceUpdateSeconds int32
+}
+
+type AdminConfiguration struct {
+	SocketPath       string
+	CheckpointPath   string
+	MaxPauseDuration metav1.Duration
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config"
+)
+
+func TestKoordletConfigurationRoundTrip(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, config.AddToScheme(scheme))
+	assert.NoError(t, AddToScheme(scheme))
+
+	defaulted := &KoordletConfiguration{}
+	scheme.Default(defaulted)
+
+	customized := defaulted.DeepCopy()
+	customized.ConfigMapName = pointer.String("test-config")
+	customized.FeatureGates = map[string]bool{"AuditEvents": true}
+	customized.HostPaths = HostPathsConfiguration{
+		CgroupRootDir:    "/cgroup/",
+		NodeNameOverride: "test-node",
+	}
+	customized.StatesInformer.KubeletSyncInterval = &metav1.Duration{Duration: time.Minute}
+	customized.StatesInformer.APIWriterQPS = pointer.Float64(2.5)
+	customized.ResManager.MemoryLocalityNodeCPUThresholdPercent = pointer.Int64(30)
+	customized.ResManager.QOSExtensionPlugins = map[string]bool{"test-plugin": false}
+	customized.QoSManager.Plugins = map[string]bool{"test-plugin": true}
+	customized.RuntimeHooks.DisableStages = []string{"PreRunPodSandbox"}
+	customized.Audit.LogDir = pointer.String("/var/log/test")
+	customized.Admin.MaxPauseDuration = &metav1.Duration{Duration: time.Hour}
+
+	tests := []struct {
+		name string
+		obj  *KoordletConfiguration
+	}{
+		{
+			name: "defaulted configuration",
+			obj:  defaulted,
+		},
+		{
+			name: "customized configuration",
+			obj:  customized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			internal := &config.KoordletConfiguration{}
+			assert.NoError(t, scheme.Convert(tt.obj, internal, nil))
+			got := &KoordletConfiguration{}
+			assert.NoError(t, scheme.Convert(internal, got, nil))
+			assert.Equal(t, tt.obj, got)
+		})
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+// keep consistent with the defaults of the koordlet flags
+const (
+	defaultConfigMapName      = "koordlet-config"
+	defaultConfigMapNamespace = "koordinator-system"
+	defaultProfile            = "standard"
+
+	defaultKubeletPreferredAddressType = "InternalIP"
+	defaultKubeletSyncInterval         = 10 * time.Second
+	defaultKubeletSyncTimeout          = 3 * time.Second
+	defaultKubeletReadOnlyPort         = 10255
+	defaultNodeTopologySyncInterval    = 3 * time.Second
+	defaultAPIWriterQPS                = 5
+	defaultAPIWriterBurst              = 10
+	defaultAPIWriterCoalesceWindow     = time.Second
+
+	defaultCollectResUsedIntervalSeconds     = 1
+	defaultCollectNodeCPUInfoIntervalSeconds = 60
+	defaultCPICollectorIntervalSeconds       = 60
+	defaultPSICollectorIntervalSeconds       = 10
+	defaultCPICollectorTimeWindowSeconds     = 10
+	defaultMBMCollectorIntervalSeconds       = 10
+	defaultProcessCollectorIntervalSeconds   = 10
+	defaultProcessFDSampleBudget             = 1000
+	defaultDiskIOCollectorIntervalSeconds    = 10
+
+	defaultDiskIOCollectMountPoint = "/var/lib/kubelet"
+
+	defaultMetricGCIntervalSeconds = 300
+	defaultMetricExpireSeconds     = 1800
+
+	defaultReconcileIntervalSeconds               = 1
+	defaultCPUSuppressIntervalSeconds             = 1
+	defaultCPUEvictIntervalSeconds                = 1
+	defaultMemoryEvictIntervalSeconds             = 1
+	defaultMemoryEvictCoolTimeSeconds             = 4
+	defaultCPUEvictCoolTimeSeconds                = 20
+	defaultCgroupVerifyIntervalSeconds            = 60
+	defaultCgroupVerifySampleRatio                = 0.1
+	defaultCgroupVerifyMaxFilesPerCycle           = 200
+	defaultCgroupDriftWarningThreshold            = 3
+	defaultMemoryLocalityRepairIntervalSeconds    = 300
+	defaultMemoryLocalityRepairMode               = "Migrate"
+	defaultMemoryLocalitySampleContainersPerCycle = 20
+	defaultMemoryLocalityRepairContainersPerCycle = 1
+	defaultMemoryLocalityNodeCPUThresholdPercent  = 50
+	defaultMemoryLocalityExpandSeconds            = 600
+	defaultOrphanArtifactGCIntervalSeconds        = 600
+	defaultMBAFeedbackIntervalSeconds             = 10
+	defaultMBAFeedbackWindowSeconds               = 300
+	defaultMBAFeedbackDegradePercent              = 10
+	defaultMBAFeedbackRelaxRounds                 = 3
+	defaultGPUMemoryLeakCheckIntervalSeconds      = 30
+	defaultGPUMemoryLeakMarginPercent             = 10
+	defaultGPUMemoryLeakConsecutiveSamples        = 3
+	defaultPIDProtectionIntervalSeconds           = 10
+	defaultPIDEvictCoolTimeSeconds                = 60
+	defaultNetIngressProtectionIntervalSeconds    = 10
+
+	defaultRuntimeHooksNetwork             = "unix"
+	defaultRuntimeHooksAddr                = "/host-var-run-koordlet/koordlet.sock"
+	defaultRuntimeHooksFailurePolicy       = "Ignore"
+	defaultRuntimeHooksPluginFailurePolicy = "Ignore"
+	defaultRuntimeHooksHostEndpoint        = "/var/run/koordlet/koordlet.sock"
+
+	defaultAuditLogDir               = "/var/log/koordlet"
+	defaultAuditVerbose              = 3
+	defaultAuditMaxDiskSpaceMB       = 16
+	defaultAuditMaxConcurrentReaders = 4
+	defaultAuditMaxEventsLimit       = 2048
+
+	defaultResourceForceUpdateSeconds = 60
+
+	defaultAdminSocketPath       = "/host-var-run-koordlet/koordlet-admin.sock"
+	defaultAdminCheckpointPath   = "/host-var-run-koordlet/feature-pauses.json"
+	defaultAdminMaxPauseDuration = 24 * time.Hour
+)
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_KoordletConfiguration sets additional defaults
+func SetDefaults_KoordletConfiguration(obj *KoordletConfiguration) {
+	if obj.ConfigMapName == nil {
+		obj.ConfigMapName = pointer.String(defaultConfigMapName)
+	}
+	if obj.ConfigMapNamespace == nil {
+		obj.ConfigMapNamespace = pointer.String(defaultConfigMapNamespace)
+	}
+	if obj.Profile == nil {
+		obj.Profile = pointer.String(defaultProfile)
+	}
+}
+
+func SetDefaults_StatesInformerConfiguration(obj *StatesInformerConfiguration) {
+	if obj.KubeletPreferredAddressType == nil {
+		obj.KubeletPreferredAddressType = pointer.String(defaultKubeletPreferredAddressType)
+	}
+	if obj.KubeletSyncInterval == nil {
+		obj.KubeletSyncInterval = &metav1.Duration{Duration: defaultKubeletSyncInterval}
+	}
+	if obj.KubeletSyncTimeout == nil {
+		obj.KubeletSyncTimeout = &metav1.Duration{Duration: defaultKubeletSyncTimeout}
+	}
+	if obj.KubeletInsecureTLS == nil {
+		obj.KubeletInsecureTLS = pointer.Bool(false)
+	}
+	if obj.KubeletReadOnlyPort == nil {
+		obj.KubeletReadOnlyPort = pointer.Int32(defaultKubeletReadOnlyPort)
+	}
+	if obj.NodeTopologySyncInterval == nil {
+		obj.NodeTopologySyncInterval = &metav1.Duration{Duration: defaultNodeTopologySyncInterval}
+	}
+	if obj.DisableQueryKubeletConfig == nil {
+		obj.DisableQueryKubeletConfig = pointer.Bool(false)
+	}
+	if obj.EnableNodeMetricReport == nil {
+		obj.EnableNodeMetricReport = pointer.Bool(true)
+	}
+	if obj.APIWriterQPS == nil {
+		obj.APIWriterQPS = pointer.Float64(defaultAPIWriterQPS)
+	}
+	if obj.APIWriterBurst == nil {
+		obj.APIWriterBurst = pointer.Int32(defaultAPIWriterBurst)
+	}
+	if obj.APIWriterCoalesceWindow == nil {
+		obj.APIWriterCoalesceWindow = &metav1.Duration{Duration: defaultAPIWriterCoalesceWindow}
+	}
+}
+
+func SetDefaults_MetricsAdvisorConfiguration(obj *MetricsAdvisorConfiguration) {
+	if obj.CollectResUsedIntervalSeconds == nil {
+		obj.CollectResUsedIntervalSeconds = pointer.Int32(defaultCollectResUsedIntervalSeconds)
+	}
+	if obj.CollectNodeCPUInfoIntervalSeconds == nil {
+		obj.CollectNodeCPUInfoIntervalSeconds = pointer.Int32(defaultCollectNodeCPUInfoIntervalSeconds)
+	}
+	if obj.CPICollectorIntervalSeconds == nil {
+		obj.CPICollectorIntervalSeconds = pointer.Int32(defaultCPICollectorIntervalSeconds)
+	}
+	if obj.PSICollectorIntervalSeconds == nil {
+		obj.PSICollectorIntervalSeconds = pointer.Int32(defaultPSICollectorIntervalSeconds)
+	}
+	if obj.CPICollectorTimeWindowSeconds == nil {
+		obj.CPICollectorTimeWindowSeconds = pointer.Int32(defaultCPICollectorTimeWindowSeconds)
+	}
+	if obj.MBMCollectorIntervalSeconds == nil {
+		obj.MBMCollectorIntervalSeconds = pointer.Int32(defaultMBMCollectorIntervalSeconds)
+	}
+	if obj.ProcessCollectorIntervalSeconds == nil {
+		obj.ProcessCollectorIntervalSeconds = pointer.Int32(defaultProcessCollectorIntervalSeconds)
+	}
+	if obj.ProcessFDSampleBudget == nil {
+		obj.ProcessFDSampleBudget = pointer.Int32(defaultProcessFDSampleBudget)
+	}
+	if obj.DiskIOCollectorIntervalSeconds == nil {
+		obj.DiskIOCollectorIntervalSeconds = pointer.Int32(defaultDiskIOCollectorIntervalSeconds)
+	}
+	if obj.DiskIOCollectMountPoints == nil {
+		obj.DiskIOCollectMountPoints = []string{defaultDiskIOCollectMountPoint}
+	}
+}
+
+func SetDefaults_MetricCacheConfiguration(obj *MetricCacheConfiguration) {
+	if obj.MetricGCIntervalSeconds == nil {
+		obj.MetricGCIntervalSeconds = pointer.Int32(defaultMetricGCIntervalSeconds)
+	}
+	if obj.MetricExpireSeconds == nil {
+		obj.MetricExpireSeconds = pointer.Int32(defaultMetricExpireSeconds)
+	}
+}
+
+func SetDefaults_ResManagerConfiguration(obj *ResManagerConfiguration) {
+	if obj.ReconcileIntervalSeconds == nil {
+		obj.ReconcileIntervalSeconds = pointer.Int32(defaultReconcileIntervalSeconds)
+	}
+	if obj.CPUSuppressIntervalSeconds == nil {
+		obj.CPUSuppressIntervalSeconds = pointer.Int32(defaultCPUSuppressIntervalSeconds)
+	}
+	if obj.CPUEvictIntervalSeconds == nil {
+		obj.CPUEvictIntervalSeconds = pointer.Int32(defaultCPUEvictIntervalSeconds)
+	}
+	if obj.MemoryEvictIntervalSeconds == nil {
+		obj.MemoryEvictIntervalSeconds = pointer.Int32(defaultMemoryEvictIntervalSeconds)
+	}
+	if obj.MemoryEvictCoolTimeSeconds == nil {
+		obj.MemoryEvictCoolTimeSeconds = pointer.Int32(defaultMemoryEvictCoolTimeSeconds)
+	}
+	if obj.CPUEvictCoolTimeSeconds == nil {
+		obj.CPUEvictCoolTimeSeconds = pointer.Int32(defaultCPUEvictCoolTimeSeconds)
+	}
+	if obj.CgroupVerifyIntervalSeconds == nil {
+		obj.CgroupVerifyIntervalSeconds = pointer.Int32(defaultCgroupVerifyIntervalSeconds)
+	}
+	if obj.CgroupVerifySampleRatio == nil {
+		obj.CgroupVerifySampleRatio = pointer.Float64(defaultCgroupVerifySampleRatio)
+	}
+	if obj.CgroupVerifyMaxFilesPerCycle == nil {
+		obj.CgroupVerifyMaxFilesPerCycle = pointer.Int32(defaultCgroupVerifyMaxFilesPerCycle)
+	}
+	if obj.CgroupDriftWarningThreshold == nil {
+		obj.CgroupDriftWarningThreshold = pointer.Int32(defaultCgroupDriftWarningThreshold)
+	}
+	if obj.MemoryLocalityRepairIntervalSeconds == nil {
+		obj.MemoryLocalityRepairIntervalSeconds = pointer.Int32(defaultMemoryLocalityRepairIntervalSeconds)
+	}
+	if obj.MemoryLocalityRepairMode == nil {
+		obj.MemoryLocalityRepairMode = pointer.String(defaultMemoryLocalityRepairMode)
+	}
+	if obj.MemoryLocalitySampleContainersPerCycle == nil {
+		obj.MemoryLocalitySampleContainersPerCycle = pointer.Int32(defaultMemoryLocalitySampleContainersPerCycle)
+	}
+	if obj.MemoryLocalityRepairContainersPerCycle == nil {
+		obj.MemoryLocalityRepairContainersPerCycle = pointer.Int32(defaultMemoryLocalityRepairContainersPerCycle)
+	}
+	if obj.MemoryLocalityNodeCPUThresholdPercent == nil {
+		obj.MemoryLocalityNodeCPUThresholdPercent = pointer.Int64(defaultMemoryLocalityNodeCPUThresholdPercent)
+	}
+	if obj.MemoryLocalityExpandSeconds == nil {
+		obj.MemoryLocalityExpandSeconds = pointer.Int32(defaultMemoryLocalityExpandSeconds)
+	}
+	if obj.OrphanArtifactGCIntervalSeconds == nil {
+		obj.OrphanArtifactGCIntervalSeconds = pointer.Int32(defaultOrphanArtifactGCIntervalSeconds)
+	}
+	if obj.OrphanArtifactGCDryRun == nil {
+		obj.OrphanArtifactGCDryRun = pointer.Bool(false)
+	}
+	if obj.MBAFeedbackIntervalSeconds == nil {
+		obj.MBAFeedbackIntervalSeconds = pointer.Int32(defaultMBAFeedbackIntervalSeconds)
+	}
+	if obj.MBAFeedbackWindowSeconds == nil {
+		obj.MBAFeedbackWindowSeconds = pointer.Int32(defaultMBAFeedbackWindowSeconds)
+	}
+	if obj.MBAFeedbackDegradePercent == nil {
+		obj.MBAFeedbackDegradePercent = pointer.Int64(defaultMBAFeedbackDegradePercent)
+	}
+	if obj.MBAFeedbackRelaxRounds == nil {
+		obj.MBAFeedbackRelaxRounds = pointer.Int32(defaultMBAFeedbackRelaxRounds)
+	}
+	if obj.GPUMemoryLeakCheckIntervalSeconds == nil {
+		obj.GPUMemoryLeakCheckIntervalSeconds = pointer.Int32(defaultGPUMemoryLeakCheckIntervalSeconds)
+	}
+	if obj.GPUMemoryLeakMarginPercent == nil {
+		obj.GPUMemoryLeakMarginPercent = pointer.Int64(defaultGPUMemoryLeakMarginPercent)
+	}
+	if obj.GPUMemoryLeakConsecutiveSamples == nil {
+		obj.GPUMemoryLeakConsecutiveSamples = pointer.Int32(defaultGPUMemoryLeakConsecutiveSamples)
+	}
+	if obj.PIDProtectionIntervalSeconds == nil {
+		obj.PIDProtectionIntervalSeconds = pointer.Int32(defaultPIDProtectionIntervalSeconds)
+	}
+	if obj.PIDEvictCoolTimeSeconds == nil {
+		obj.PIDEvictCoolTimeSeconds = pointer.Int32(defaultPIDEvictCoolTimeSeconds)
+	}
+	if obj.NetIngressProtectionIntervalSeconds == nil {
+		obj.NetIngressProtectionIntervalSeconds = pointer.Int32(defaultNetIngressProtectionIntervalSeconds)
+	}
+}
+
+func SetDefaults_RuntimeHooksConfiguration(obj *RuntimeHooksConfiguration) {
+	if obj.Network == nil {
+		obj.Network = pointer.String(defaultRuntimeHooksNetwork)
+	}
+	if obj.Addr == nil {
+		obj.Addr = pointer.String(defaultRuntimeHooksAddr)
+	}
+	if obj.FailurePolicy == nil {
+		obj.FailurePolicy = pointer.String(defaultRuntimeHooksFailurePolicy)
+	}
+	if obj.PluginFailurePolicy == nil {
+		obj.PluginFailurePolicy = pointer.String(defaultRuntimeHooksPluginFailurePolicy)
+	}
+	if obj.HostEndpoint == nil {
+		obj.HostEndpoint = pointer.String(defaultRuntimeHooksHostEndpoint)
+	}
+}
+
+func SetDefaults_AuditConfiguration(obj *AuditConfiguration) {
+	if obj.LogDir == nil {
+		obj.LogDir = pointer.String(defaultAuditLogDir)
+	}
+	if obj.Verbose == nil {
+		obj.Verbose = pointer.Int32(defaultAuditVerbose)
+	}
+	if obj.MaxDiskSpaceMB == nil {
+		obj.MaxDiskSpaceMB = pointer.Int32(defaultAuditMaxDiskSpaceMB)
+	}
+	if obj.MaxConcurrentReaders == nil {
+		obj.MaxConcurrentReaders = pointer.Int32(defaultAuditMaxConcurrentReaders)
+	}
+	if obj.MaxEventsLimit == nil {
+		obj.MaxEventsLimit = pointer.Int32(defaultAuditMaxEventsLimit)
+	}
+}
+
+func SetDefaults_ResourceExecutorConfiguration(obj *ResourceExecutorConfiguration) {
+	if obj.ResourceForceUpdateSeconds == nil {
+		obj.ResourceForceUpdateSeconds = pointer.Int32(defaultResourceForceUpdateSeconds)
+	}
+}
+
+func SetDefaults_AdminConfiguration(obj *AdminConfiguration) {
+	if obj.SocketPath == nil {
+		obj.SocketPath = pointer.String(defaultAdminSocketPath)
+	}
+	if obj.CheckpointPath == nil {
+		obj.CheckpointPath = pointer.String(defaultAdminCheckpointPath)
+	}
+	if obj.MaxPauseDuration == nil {
+		obj.MaxPauseDuration = &metav1.Duration{Duration: defaultAdminMaxPauseDuration}
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KoordletConfiguration configures a koordlet. The fields not set keep the defaults of the corresponding flags,
+// and the flags set on the command line override the fields loaded from the configuration file.
+type KoordletConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ConfigMapName is the name of the configmap which koordlet loads the extra configs from.
+	ConfigMapName *string `json:"configMapName,omitempty"`
+	// ConfigMapNamespace is the namespace of the configmap which koordlet loads the extra configs from.
+	ConfigMapNamespace *string `json:"configMapNamespace,omitempty"`
+	// FeatureGates is a map of feature names to bools that enable or disable alpha/experimental features.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// Profile presets the collector intervals, the metric retention and the sync intervals, "standard" or "edge".
+	// The settings set explicitly by the flags or the fields of this configuration override the preset.
+	Profile *string `json:"profile,omitempty"`
+
+	// HostPaths specifies the paths of the host directories mounted into the koordlet container.
+	HostPaths HostPathsConfiguration `json:"hostPaths,omitempty"`
+	// StatesInformer configures how koordlet syncs the states of the node and pods.
+	StatesInformer StatesInformerConfiguration `json:"statesInformer,omitempty"`
+	// MetricsAdvisor configures the metric collectors.
+	MetricsAdvisor MetricsAdvisorConfiguration `json:"metricsAdvisor,omitempty"`
+	// MetricCache configures the storage of the collected metrics.
+	MetricCache MetricCacheConfiguration `json:"metricCache,omitempty"`
+	// ResManager configures the resource manager.
+	ResManager ResManagerConfiguration `json:"resManager,omitempty"`
+	// QoSManager configures the QoS manager.
+	QoSManager QoSManagerConfiguration `json:"qosManager,omitempty"`
+	// RuntimeHooks configures the runtime hooks server.
+	RuntimeHooks RuntimeHooksConfiguration `json:"runtimeHooks,omitempty"`
+	// Audit configures the audit log.
+	Audit AuditConfiguration `json:"audit,omitempty"`
+	// ResourceExecutor configures the executor updating the cgroups and system files.
+	ResourceExecutor ResourceExecutorConfiguration `json:"resourceExecutor,omitempty"`
+	// Admin configures the admin commands pausing and resuming the features.
+	Admin AdminConfiguration `json:"admin,omitempty"`
+}
+
+// HostPathsConfiguration specifies the host paths. An empty path keeps the default of the agent mode.
+type HostPathsConfiguration struct {
+	// CgroupRootDir is the cgroup root dir, the same as the flag --cgroup-root-dir.
+	CgroupRootDir string `json:"cgroupRootDir,omitempty"`
+	// CgroupKubeDir is the cgroup kube dir, the same as the flag --cgroup-kube-dir.
+	CgroupKubeDir string `json:"cgroupKubeDir,omitempty"`
+	// SysRootDir is the host /sys dir in container, the same as the flag --sys-root-dir.
+	SysRootDir string `json:"sysRootDir,omitempty"`
+	// SysFSRootDir is the host /sys/fs dir in container, the same as the flag --sys-fs-root-dir.
+	SysFSRootDir string `json:"sysFSRootDir,omitempty"`
+	// ProcRootDir is the host /proc dir in container, the same as the flag --proc-root-dir.
+	ProcRootDir string `json:"procRootDir,omitempty"`
+	// VarRunRootDir is the host /var/run dir in container, the same as the flag --var-run-root-dir.
+	VarRunRootDir string `json:"varRunRootDir,omitempty"`
+	// NodeNameOverride is used as the identification instead of the actual machine name if non-empty.
+	NodeNameOverride string `json:"nodeNameOverride,omitempty"`
+	// ContainerdEndpoint is the endpoint of containerd, the same as the flag --containerd-endpoint.
+	ContainerdEndpoint string `json:"containerdEndpoint,omitempty"`
+	// DockerEndpoint is the endpoint of docker, the same as the flag --docker-endpoint.
+	DockerEndpoint string `json:"dockerEndpoint,omitempty"`
+}
+
+type StatesInformerConfiguration struct {
+	// KubeletPreferredAddressType is the node address type to use when connecting to the kubelet.
+	KubeletPreferredAddressType *string `json:"kubeletPreferredAddressType,omitempty"`
+	// KubeletSyncInterval is the interval at which koordlet syncs the pods from the kubelet.
+	KubeletSyncInterval *metav1.Duration `json:"kubeletSyncInterval,omitempty"`
+	// KubeletSyncTimeout is the timeout of a single request to the kubelet.
+	KubeletSyncTimeout *metav1.Duration `json:"kubeletSyncTimeout,omitempty"`
+	// KubeletInsecureTLS uses the read-only port to communicate with the kubelet. For testing purposes only.
+	KubeletInsecureTLS *bool `json:"kubeletInsecureTLS,omitempty"`
+	// KubeletReadOnlyPort is the read-only port of the kubelet.
+	KubeletReadOnlyPort *int32 `json:"kubeletReadOnlyPort,omitempty"`
+	// NodeTopologySyncInterval is the interval at which koordlet reports the node topology.
+	NodeTopologySyncInterval *metav1.Duration `json:"nodeTopologySyncInterval,omitempty"`
+	// DisableQueryKubeletConfig disables querying the kubelet configuration from the kubelet.
+	DisableQueryKubeletConfig *bool `json:"disableQueryKubeletConfig,omitempty"`
+	// EnableNodeMetricReport enables the status update of the NodeMetric.
+	EnableNodeMetricReport *bool `json:"enableNodeMetricReport,omitempty"`
+	// APIWriterQPS is the QPS of the writes to the apiserver shared by the reporters.
+	APIWriterQPS *float64 `json:"apiWriterQPS,omitempty"`
+	// APIWriterBurst is the burst of the writes to the apiserver shared by the reporters.
+	APIWriterBurst *int32 `json:"apiWriterBurst,omitempty"`
+	// APIWriterCoalesceWindow is the window in which the writes of the same object are coalesced.
+	APIWriterCoalesceWindow *metav1.Duration `json:"apiWriterCoalesceWindow,omitempty"`
+}
+
+type MetricsAdvisorConfiguration struct {
+	// CollectResUsedIntervalSeconds is the interval to collect the resource usage of the node and pods.
+	CollectResUsedIntervalSeconds *int32 `json:"collectResUsedIntervalSeconds,omitempty"`
+	// CollectNodeCPUInfoIntervalSeconds is the interval to collect the cpu info of the node.
+	CollectNodeCPUInfoIntervalSeconds *int32 `json:"collectNodeCPUInfoIntervalSeconds,omitempty"`
+	// CPICollectorIntervalSeconds is the interval to collect the cpi.
+	CPICollectorIntervalSeconds *int32 `json:"cpiCollectorIntervalSeconds,omitempty"`
+	// PSICollectorIntervalSeconds is the interval to collect the psi.
+	PSICollectorIntervalSeconds *int32 `json:"psiCollectorIntervalSeconds,omitempty"`
+	// CPICollectorTimeWindowSeconds is the time window to collect the cpi.
+	CPICollectorTimeWindowSeconds *int32 `json:"cpiCollectorTimeWindowSeconds,omitempty"`
+	// MBMCollectorIntervalSeconds is the interval to collect the memory bandwidth of the resctrl groups.
+	MBMCollectorIntervalSeconds *int32 `json:"mbmCollectorIntervalSeconds,omitempty"`
+	// ProcessCollectorIntervalSeconds is the interval to collect the tasks and the file descriptors.
+	ProcessCollectorIntervalSeconds *int32 `json:"processCollectorIntervalSeconds,omitempty"`
+	// ProcessFDSampleBudget is the max number of the processes whose file descriptors are counted in a collection.
+	ProcessFDSampleBudget *int32 `json:"processFDSampleBudget,omitempty"`
+	// DiskIOCollectorIntervalSeconds is the interval to collect the io of the disks under the mount points.
+	DiskIOCollectorIntervalSeconds *int32 `json:"diskIOCollectorIntervalSeconds,omitempty"`
+	// DiskIOCollectMountPoints are the mount points whose disks the io is collected of.
+	DiskIOCollectMountPoints []string `json:"diskIOCollectMountPoints,omitempty"`
+}
+
+type MetricCacheConfiguration struct {
+	// MetricGCIntervalSeconds is the interval to gc the expired metrics.
+	MetricGCIntervalSeconds *int32 `json:"metricGCIntervalSeconds,omitempty"`
+	// MetricExpireSeconds is how long the metrics are kept.
+	MetricExpireSeconds *int32 `json:"metricExpireSeconds,omitempty"`
+}
+
+type ResManagerConfiguration struct {
+	// ReconcileIntervalSeconds is the interval to reconcile the cgroups of the be pods.
+	ReconcileIntervalSeconds *int32 `json:"reconcileIntervalSeconds,omitempty"`
+	// CPUSuppressIntervalSeconds is the interval to suppress the cpu of the be pods.
+	CPUSuppressIntervalSeconds *int32 `json:"cpuSuppressIntervalSeconds,omitempty"`
+	// CPUEvictIntervalSeconds is the interval to evict the be pods by cpu.
+	CPUEvictIntervalSeconds *int32 `json:"cpuEvictIntervalSeconds,omitempty"`
+	// MemoryEvictIntervalSeconds is the interval to evict the be pods by memory.
+	MemoryEvictIntervalSeconds *int32 `json:"memoryEvictIntervalSeconds,omitempty"`
+	// MemoryEvictCoolTimeSeconds is the cooling time after an eviction by memory.
+	MemoryEvictCoolTimeSeconds *int32 `json:"memoryEvictCoolTimeSeconds,omitempty"`
+	// CPUEvictCoolTimeSeconds is the cooling time after an eviction by cpu.
+	CPUEvictCoolTimeSeconds *int32 `json:"cpuEvictCoolTimeSeconds,omitempty"`
+
+	// CgroupVerifyIntervalSeconds is the interval to verify the cgroup values updated by koordlet.
+	CgroupVerifyIntervalSeconds *int32 `json:"cgroupVerifyIntervalSeconds,omitempty"`
+	// CgroupVerifySampleRatio is the fraction of the cgroup files to verify in one cycle.
+	CgroupVerifySampleRatio *float64 `json:"cgroupVerifySampleRatio,omitempty"`
+	// CgroupVerifyMaxFilesPerCycle is the max number of cgroup files to read in one cycle, non-positive means unlimited.
+	CgroupVerifyMaxFilesPerCycle *int32 `json:"cgroupVerifyMaxFilesPerCycle,omitempty"`
+	// CgroupDriftWarningThreshold is how many times the same cgroup file is repaired before a warning event is sent.
+	CgroupDriftWarningThreshold *int32 `json:"cgroupDriftWarningThreshold,omitempty"`
+
+	// MemoryLocalityRepairIntervalSeconds is the interval to repair the memory locality of the ls containers.
+	MemoryLocalityRepairIntervalSeconds *int32 `json:"memoryLocalityRepairIntervalSeconds,omitempty"`
+	// MemoryLocalityRepairMode is the way to repair the memory locality, Migrate or ExpandMems.
+	MemoryLocalityRepairMode *string `json:"memoryLocalityRepairMode,omitempty"`
+	// MemoryLocalitySampleContainersPerCycle is the max number of containers to read numa_maps in one cycle.
+	MemoryLocalitySampleContainersPerCycle *int32 `json:"memoryLocalitySampleContainersPerCycle,omitempty"`
+	// MemoryLocalityRepairContainersPerCycle is the max number of containers to repair in one cycle.
+	MemoryLocalityRepairContainersPerCycle *int32 `json:"memoryLocalityRepairContainersPerCycle,omitempty"`
+	// MemoryLocalityNodeCPUThresholdPercent is the node cpu usage percent below which the memory locality is repaired.
+	MemoryLocalityNodeCPUThresholdPercent *int64 `json:"memoryLocalityNodeCPUThresholdPercent,omitempty"`
+	// MemoryLocalityExpandSeconds is how long the cpuset.mems keeps expanded in the ExpandMems mode.
+	MemoryLocalityExpandSeconds *int32 `json:"memoryLocalityExpandSeconds,omitempty"`
+
+	// OrphanArtifactGCIntervalSeconds is the interval to remove the artifacts left for the pods no longer existing.
+	OrphanArtifactGCIntervalSeconds *int32 `json:"orphanArtifactGCIntervalSeconds,omitempty"`
+	// OrphanArtifactGCDryRun only logs and counts the orphan artifacts without removing them.
+	OrphanArtifactGCDryRun *bool `json:"orphanArtifactGCDryRun,omitempty"`
+
+	// MBAFeedbackIntervalSeconds is the interval to adjust the MBA percent of the BE resctrl group.
+	MBAFeedbackIntervalSeconds *int32 `json:"mbaFeedbackIntervalSeconds,omitempty"`
+	// MBAFeedbackWindowSeconds is the time window of the LS memory bandwidth and CPI regarded as the baseline.
+	MBAFeedbackWindowSeconds *int32 `json:"mbaFeedbackWindowSeconds,omitempty"`
+	// MBAFeedbackDegradePercent is the percent of the LS bandwidth drop or CPI rise regarded as degraded.
+	MBAFeedbackDegradePercent *int64 `json:"mbaFeedbackDegradePercent,omitempty"`
+	// MBAFeedbackRelaxRounds is the number of consecutive rounds without degradation before relaxing the MBA percent.
+	MBAFeedbackRelaxRounds *int32 `json:"mbaFeedbackRelaxRounds,omitempty"`
+
+	// GPUMemoryLeakCheckIntervalSeconds is the interval to check the GPU memory usage of pods against their shares.
+	GPUMemoryLeakCheckIntervalSeconds *int32 `json:"gpuMemoryLeakCheckIntervalSeconds,omitempty"`
+	// GPUMemoryLeakMarginPercent is the percent of the GPU memory usage over the allocated share regarded as overused.
+	GPUMemoryLeakMarginPercent *int64 `json:"gpuMemoryLeakMarginPercent,omitempty"`
+	// GPUMemoryLeakConsecutiveSamples is the number of consecutive overused samples before an event is raised on the pod.
+	GPUMemoryLeakConsecutiveSamples *int32 `json:"gpuMemoryLeakConsecutiveSamples,omitempty"`
+
+	// PIDProtectionIntervalSeconds is the interval to limit the tasks of the be pods and check the node pid usage.
+	PIDProtectionIntervalSeconds *int32 `json:"pidProtectionIntervalSeconds,omitempty"`
+	// PIDEvictCoolTimeSeconds is the cooling time after an eviction by the node pid usage.
+	PIDEvictCoolTimeSeconds *int32 `json:"pidEvictCoolTimeSeconds,omitempty"`
+
+	// NetIngressProtectionIntervalSeconds is the interval to sync the RX shapers of the be pods.
+	NetIngressProtectionIntervalSeconds *int32 `json:"netIngressProtectionIntervalSeconds,omitempty"`
+
+	// QOSExtensionPlugins is a map of the qos extension plugins to bools that enable or disable them.
+	QOSExtensionPlugins map[string]bool `json:"qosExtensionPlugins,omitempty"`
+}
+
+type QoSManagerConfiguration struct {
+	// Plugins is a map of the QoS manager plugins to bools that enable or disable them.
+	Plugins map[string]bool `json:"plugins,omitempty"`
+}
+
+type RuntimeHooksConfiguration struct {
+	// Network is the network type of the runtime hooks server, e.g. unix.
+	Network *string `json:"network,omitempty"`
+	// Addr is the address of the runtime hooks server.
+	Addr *string `json:"addr,omitempty"`
+	// FailurePolicy is the failure policy of the runtime hooks, Ignore or Fail.
+	FailurePolicy *string `json:"failurePolicy,omitempty"`
+	// PluginFailurePolicy determines whether to stop running the other hooks once one failed, Ignore or Fail.
+	PluginFailurePolicy *string `json:"pluginFailurePolicy,omitempty"`
+	// ConfigFilePath is the config file path for runtime hooks. An empty path keeps the default of the agent mode.
+	ConfigFilePath string `json:"configFilePath,omitempty"`
+	// HostEndpoint is the host endpoint of the runtime proxy.
+	HostEndpoint *string `json:"hostEndpoint,omitempty"`
+	// DisableStages are the disabled stages of the runtime hooks.
+	DisableStages []string `json:"disableStages,omitempty"`
+
+	// ExclusionPodSelectors are the label selectors of the pods managed by other agents, which are skipped by the runtime hooks.
+	ExclusionPodSelectors []string `json:"exclusionPodSelectors,omitempty"`
+	// ExclusionOwnerKinds are the owner kinds of the pods managed by other agents, e.g. DaemonSet.
+	ExclusionOwnerKinds []string `json:"exclusionOwnerKinds,omitempty"`
+	// ExclusionHooks overrides whether the hooks skip the excluded pods, all hooks skip them by default.
+	ExclusionHooks map[string]bool `json:"exclusionHooks,omitempty"`
+}
+
+type AuditConfiguration struct {
+	// LogDir is the dir of the audit log.
+	LogDir *string `json:"logDir,omitempty"`
+	// Verbose is the verbose of the audit log.
+	Verbose *int32 `json:"verbose,omitempty"`
+	// MaxDiskSpaceMB is the max disk space occupied by the audit log.
+	MaxDiskSpaceMB *int32 `json:"maxDiskSpaceMB,omitempty"`
+	// MaxConcurrentReaders is the max number of the concurrent readers of the audit log.
+	MaxConcurrentReaders *int32 `json:"maxConcurrentReaders,omitempty"`
+	// MaxEventsLimit is the max number of the events in one request of the audit log.
+	MaxEventsLimit *int32 `json:"maxEventsLimit,omitempty"`
+}
+
+type ResourceExecutorConfiguration struct {
+	// ResourceForceUpdateSeconds is the interval to force updating the resources.
+	ResourceForceUpdateSeconds *int32 `json:"resourceForceUpdateSeconds,omitempty"`
+}
+
+type AdminConfiguration struct {
+	// SocketPath is the path of the unix socket serving the admin commands, empty to disable them.
+	SocketPath *string `json:"socketPath,omitempty"`
+	// CheckpointPath is the path of the checkpoint file keeping the feature pauses across the restarts.
+	CheckpointPath *string `json:"checkpointPath,omitempty"`
+	// MaxPauseDuration is the max duration a feature can be paused for before resumed automatically.
+	MaxPauseDuration *metav1.Duration `json:"maxPauseDuration,omitempty"`
+}
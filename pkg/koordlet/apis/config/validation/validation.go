@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
+	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
+)
+
+var (
+	validNodeAddressTypes = sets.NewString(
+		string(corev1.NodeHostName),
+		string(corev1.NodeInternalIP),
+		string(corev1.NodeExternalIP),
+		string(corev1.NodeInternalDNS),
+		string(corev1.NodeExternalDNS),
+	)
+	validMemoryLocalityRepairModes = sets.NewString("Migrate", "ExpandMems")
+	validFailurePolicies           = sets.NewString(string(rmconfig.PolicyFail), string(rmconfig.PolicyIgnore))
+)
+
+// ValidateKoordletConfiguration validates the koordlet configuration loaded from the config file.
+func ValidateKoordletConfiguration(cc *config.KoordletConfiguration) utilerrors.Aggregate {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(cc.ConfigMapName) {
+		errs = append(errs, field.Invalid(field.NewPath("configMapName"), cc.ConfigMapName, msg))
+	}
+	for _, msg := range validation.IsDNS1123Label(cc.ConfigMapNamespace) {
+		errs = append(errs, field.Invalid(field.NewPath("configMapNamespace"), cc.ConfigMapNamespace, msg))
+	}
+	if !profile.IsValid(profile.Profile(cc.Profile)) {
+		errs = append(errs, field.NotSupported(field.NewPath("profile"), cc.Profile, []string{string(profile.ProfileStandard), string(profile.ProfileEdge)}))
+	}
+	errs = append(errs, validateStatesInformerConfiguration(field.NewPath("statesInformer"), &cc.StatesInformer)...)
+	errs = append(errs, validateMetricsAdvisorConfiguration(field.NewPath("metricsAdvisor"), &cc.MetricsAdvisor)...)
+	errs = append(errs, validateMetricCacheConfiguration(field.NewPath("metricCache"), &cc.MetricCache)...)
+	errs = append(errs, validateResManagerConfiguration(field.NewPath("resManager"), &cc.ResManager)...)
+	errs = append(errs, validateRuntimeHooksConfiguration(field.NewPath("runtimeHooks"), &cc.RuntimeHooks)...)
+	errs = append(errs, validateAuditConfiguration(field.NewPath("audit"), &cc.Audit)...)
+	errs = append(errs, validatePositive(field.NewPath("resourceExecutor", "resourceForceUpdateSeconds"), cc.ResourceExecutor.ResourceForceUpdateSeconds)...)
+	if cc.Admin.MaxPauseDuration.Duration < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("admin", "maxPauseDuration"), cc.Admin.MaxPauseDuration.Duration.String(), "must be greater than or equal to 0"))
+	}
+	return errs.ToAggregate()
+}
+
+func validateStatesInformerConfiguration(path *field.Path, cc *config.StatesInformerConfiguration) field.ErrorList {
+	var errs field.ErrorList
+	if !validNodeAddressTypes.Has(cc.KubeletPreferredAddressType) {
+		errs = append(errs, field.NotSupported(path.Child("kubeletPreferredAddressType"), cc.KubeletPreferredAddressType, validNodeAddressTypes.List()))
+	}
+	errs = append(errs, validatePositiveDuration(path.Child("kubeletSyncInterval"), cc.KubeletSyncInterval.Duration)...)
+	errs = append(errs, validatePositiveDuration(path.Child("kubeletSyncTimeout"), cc.KubeletSyncTimeout.Duration)...)
+	for _, msg := range validation.IsValidPortNum(int(cc.KubeletReadOnlyPort)) {
+		errs = append(errs, field.Invalid(path.Child("kubeletReadOnlyPort"), cc.KubeletReadOnlyPort, msg))
+	}
+	if cc.KubeletInsecureTLS && !cc.DisableQueryKubeletConfig {
+		errs = append(errs, field.Invalid(path.Child("disableQueryKubeletConfig"), cc.DisableQueryKubeletConfig, "must be true if kubeletInsecureTLS is true"))
+	}
+	errs = append(errs, validatePositiveDuration(path.Child("nodeTopologySyncInterval"), cc.NodeTopologySyncInterval.Duration)...)
+	if cc.APIWriterQPS < 0 {
+		errs = append(errs, field.Invalid(path.Child("apiWriterQPS"), cc.APIWriterQPS, "must be greater than or equal to 0"))
+	}
+	errs = append(errs, validateNonNegative(path.Child("apiWriterBurst"), int64(cc.APIWriterBurst))...)
+	if cc.APIWriterCoalesceWindow.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("apiWriterCoalesceWindow"), cc.APIWriterCoalesceWindow.Duration.String(), "must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+func validateMetricsAdvisorConfiguration(path *field.Path, cc *config.MetricsAdvisorConfiguration) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validatePositive(path.Child("collectResUsedIntervalSeconds"), cc.CollectResUsedIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("collectNodeCPUInfoIntervalSeconds"), cc.CollectNodeCPUInfoIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("cpiCollectorIntervalSeconds"), cc.CPICollectorIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("psiCollectorIntervalSeconds"), cc.PSICollectorIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("cpiCollectorTimeWindowSeconds"), cc.CPICollectorTimeWindowSeconds)...)
+	errs = append(errs, validatePositive(path.Child("mbmCollectorIntervalSeconds"), cc.MBMCollectorIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("processCollectorIntervalSeconds"), cc.ProcessCollectorIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("processFDSampleBudget"), cc.ProcessFDSampleBudget)...)
+	errs = append(errs, validatePositive(path.Child("diskIOCollectorIntervalSeconds"), cc.DiskIOCollectorIntervalSeconds)...)
+	for i, mountPoint := range cc.DiskIOCollectMountPoints {
+		if !filepath.IsAbs(mountPoint) {
+			errs = append(errs, field.Invalid(path.Child("diskIOCollectMountPoints").Index(i), mountPoint, "must be an absolute path"))
+		}
+	}
+	return errs
+}
+
+func validateMetricCacheConfiguration(path *field.Path, cc *config.MetricCacheConfiguration) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validatePositive(path.Child("metricGCIntervalSeconds"), cc.MetricGCIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("metricExpireSeconds"), cc.MetricExpireSeconds)...)
+	return errs
+}
+
+func validateResManagerConfiguration(path *field.Path, cc *config.ResManagerConfiguration) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validatePositive(path.Child("reconcileIntervalSeconds"), cc.ReconcileIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("cpuSuppressIntervalSeconds"), cc.CPUSuppressIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("cpuEvictIntervalSeconds"), cc.CPUEvictIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("memoryEvictIntervalSeconds"), cc.MemoryEvictIntervalSeconds)...)
+	errs = append(errs, validateNonNegative(path.Child("memoryEvictCoolTimeSeconds"), int64(cc.MemoryEvictCoolTimeSeconds))...)
+	errs = append(errs, validateNonNegative(path.Child("cpuEvictCoolTimeSeconds"), int64(cc.CPUEvictCoolTimeSeconds))...)
+	errs = append(errs, validatePositive(path.Child("cgroupVerifyIntervalSeconds"), cc.CgroupVerifyIntervalSeconds)...)
+	if cc.CgroupVerifySampleRatio < 0 || cc.CgroupVerifySampleRatio > 1 {
+		errs = append(errs, field.Invalid(path.Child("cgroupVerifySampleRatio"), cc.CgroupVerifySampleRatio, "must be in the range [0, 1]"))
+	}
+	errs = append(errs, validatePositive(path.Child("cgroupDriftWarningThreshold"), cc.CgroupDriftWarningThreshold)...)
+	errs = append(errs, validatePositive(path.Child("memoryLocalityRepairIntervalSeconds"), cc.MemoryLocalityRepairIntervalSeconds)...)
+	if !validMemoryLocalityRepairModes.Has(cc.MemoryLocalityRepairMode) {
+		errs = append(errs, field.NotSupported(path.Child("memoryLocalityRepairMode"), cc.MemoryLocalityRepairMode, validMemoryLocalityRepairModes.List()))
+	}
+	errs = append(errs, validateNonNegative(path.Child("memoryLocalitySampleContainersPerCycle"), int64(cc.MemoryLocalitySampleContainersPerCycle))...)
+	errs = append(errs, validateNonNegative(path.Child("memoryLocalityRepairContainersPerCycle"), int64(cc.MemoryLocalityRepairContainersPerCycle))...)
+	if cc.MemoryLocalityNodeCPUThresholdPercent < 0 || cc.MemoryLocalityNodeCPUThresholdPercent > 100 {
+		errs = append(errs, field.Invalid(path.Child("memoryLocalityNodeCPUThresholdPercent"), cc.MemoryLocalityNodeCPUThresholdPercent, "must be in the range [0, 100]"))
+	}
+	errs = append(errs, validatePositive(path.Child("memoryLocalityExpandSeconds"), cc.MemoryLocalityExpandSeconds)...)
+	errs = append(errs, validatePositive(path.Child("orphanArtifactGCIntervalSeconds"), cc.OrphanArtifactGCIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("mbaFeedbackIntervalSeconds"), cc.MBAFeedbackIntervalSeconds)...)
+	errs = append(errs, validatePositive(path.Child("mbaFeedbackWindowSeconds"), cc.MBAFeedbackWindowSeconds)...)
+	errs = append(errs, validateNonNegative(path.Child("mbaFeedbackDegradePercent"), cc.MBAFeedbackDegradePercent)...)
+	errs = append(errs, validateNonNegative(path.Child("mbaFeedbackRelaxRounds"), int64(cc.MBAFeedbackRelaxRounds))...)
+	errs = append(errs, validatePositive(path.Child("gpuMemoryLeakCheckIntervalSeconds"), cc.GPUMemoryLeakCheckIntervalSeconds)...)
+	errs = append(errs, validateNonNegative(path.Child("gpuMemoryLeakMarginPercent"), cc.GPUMemoryLeakMarginPercent)...)
+	errs = append(errs, validatePositive(path.Child("gpuMemoryLeakConsecutiveSamples"), cc.GPUMemoryLeakConsecutiveSamples)...)
+	errs = append(errs, validatePositive(path.Child("pidProtectionIntervalSeconds"), cc.PIDProtectionIntervalSeconds)...)
+	errs = append(errs, validateNonNegative(path.Child("pidEvictCoolTimeSeconds"), int64(cc.PIDEvictCoolTimeSeconds))...)
+	errs = append(errs, validatePositive(path.Child("netIngressProtectionIntervalSeconds"), cc.NetIngressProtectionIntervalSeconds)...)
+	return errs
+}
+
+func validateRuntimeHooksConfiguration(path *field.Path, cc *config.RuntimeHooksConfiguration) field.ErrorList {
+	var errs field.ErrorList
+	if len(cc.Network) == 0 {
+		errs = append(errs, field.Required(path.Child("network"), ""))
+	}
+	if len(cc.Addr) == 0 {
+		errs = append(errs, field.Required(path.Child("addr"), ""))
+	}
+	if !validFailurePolicies.Has(cc.FailurePolicy) {
+		errs = append(errs, field.NotSupported(path.Child("failurePolicy"), cc.FailurePolicy, validFailurePolicies.List()))
+	}
+	if !validFailurePolicies.Has(cc.PluginFailurePolicy) {
+		errs = append(errs, field.NotSupported(path.Child("pluginFailurePolicy"), cc.PluginFailurePolicy, validFailurePolicies.List()))
+	}
+	for i, selector := range cc.ExclusionPodSelectors {
+		if _, err := labels.Parse(selector); err != nil {
+			errs = append(errs, field.Invalid(path.Child("exclusionPodSelectors").Index(i), selector, err.Error()))
+		}
+	}
+	return errs
+}
+
+func validateAuditConfiguration(path *field.Path, cc *config.AuditConfiguration) field.ErrorList {
+	var errs field.ErrorList
+	if len(cc.LogDir) == 0 {
+		errs = append(errs, field.Required(path.Child("logDir"), ""))
+	}
+	errs = append(errs, validateNonNegative(path.Child("verbose"), int64(cc.Verbose))...)
+	errs = append(errs, validatePositive(path.Child("maxDiskSpaceMB"), cc.MaxDiskSpaceMB)...)
+	errs = append(errs, validatePositive(path.Child("maxConcurrentReaders"), cc.MaxConcurrentReaders)...)
+	errs = append(errs, validatePositive(path.Child("maxEventsLimit"), cc.MaxEventsLimit)...)
+	return errs
+}
+
+func validatePositive(path *field.Path, value int32) field.ErrorList {
+	if value <= 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be greater than 0")}
+	}
+	return nil
+}
+
+func validateNonNegative(path *field.Path, value int64) field.ErrorList {
+	if value < 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be greater than or equal to 0")}
+	}
+	return nil
+}
+
+func validatePositiveDuration(path *field.Path, value time.Duration) field.ErrorList {
+	if value <= 0 {
+		return field.ErrorList{field.Invalid(path, value.String(), "must be greater than 0")}
+	}
+	return nil
+}
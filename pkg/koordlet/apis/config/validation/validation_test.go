@@ -0,0 +1,273 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config/v1alpha1"
+)
+
+func TestValidateKoordletConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *v1alpha1.KoordletConfiguration
+		wantErr bool
+	}{
+		{
+			name:    "default args",
+			args:    &v1alpha1.KoordletConfiguration{},
+			wantErr: false,
+		},
+		{
+			name: "invalid configMapNamespace",
+			args: &v1alpha1.KoordletConfiguration{
+				ConfigMapNamespace: pointer.String("koordinator.system"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported kubeletPreferredAddressType",
+			args: &v1alpha1.KoordletConfiguration{
+				StatesInformer: v1alpha1.StatesInformerConfiguration{
+					KubeletPreferredAddressType: pointer.String("InternalAddress"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero kubeletSyncInterval",
+			args: &v1alpha1.KoordletConfiguration{
+				StatesInformer: v1alpha1.StatesInformerConfiguration{
+					KubeletSyncInterval: &metav1.Duration{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid kubeletReadOnlyPort",
+			args: &v1alpha1.KoordletConfiguration{
+				StatesInformer: v1alpha1.StatesInformerConfiguration{
+					KubeletReadOnlyPort: pointer.Int32(65536),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kubeletInsecureTLS without disableQueryKubeletConfig",
+			args: &v1alpha1.KoordletConfiguration{
+				StatesInformer: v1alpha1.StatesInformerConfiguration{
+					KubeletInsecureTLS: pointer.Bool(true),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kubeletInsecureTLS with disableQueryKubeletConfig",
+			args: &v1alpha1.KoordletConfiguration{
+				StatesInformer: v1alpha1.StatesInformerConfiguration{
+					KubeletInsecureTLS:        pointer.Bool(true),
+					DisableQueryKubeletConfig: pointer.Bool(true),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative apiWriterCoalesceWindow",
+			args: &v1alpha1.KoordletConfiguration{
+				StatesInformer: v1alpha1.StatesInformerConfiguration{
+					APIWriterCoalesceWindow: &metav1.Duration{Duration: -time.Second},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero collectResUsedIntervalSeconds",
+			args: &v1alpha1.KoordletConfiguration{
+				MetricsAdvisor: v1alpha1.MetricsAdvisorConfiguration{
+					CollectResUsedIntervalSeconds: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative metricExpireSeconds",
+			args: &v1alpha1.KoordletConfiguration{
+				MetricCache: v1alpha1.MetricCacheConfiguration{
+					MetricExpireSeconds: pointer.Int32(-1),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero processFDSampleBudget",
+			args: &v1alpha1.KoordletConfiguration{
+				MetricsAdvisor: v1alpha1.MetricsAdvisorConfiguration{
+					ProcessFDSampleBudget: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative diskIOCollectMountPoints",
+			args: &v1alpha1.KoordletConfiguration{
+				MetricsAdvisor: v1alpha1.MetricsAdvisorConfiguration{
+					DiskIOCollectMountPoints: []string{"var/lib/kubelet"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "cgroupVerifySampleRatio out of range",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					CgroupVerifySampleRatio: pointer.Float64(1.5),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-positive cgroupVerifyMaxFilesPerCycle means unlimited",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					CgroupVerifyMaxFilesPerCycle: pointer.Int32(0),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported memoryLocalityRepairMode",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					MemoryLocalityRepairMode: pointer.String("Rebind"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "memoryLocalityNodeCPUThresholdPercent out of range",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					MemoryLocalityNodeCPUThresholdPercent: pointer.Int64(101),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero orphanArtifactGCIntervalSeconds",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					OrphanArtifactGCIntervalSeconds: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero mbaFeedbackWindowSeconds",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					MBAFeedbackWindowSeconds: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero gpuMemoryLeakConsecutiveSamples",
+			args: &v1alpha1.KoordletConfiguration{
+				ResManager: v1alpha1.ResManagerConfiguration{
+					GPUMemoryLeakConsecutiveSamples: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported runtime hooks failurePolicy",
+			args: &v1alpha1.KoordletConfiguration{
+				RuntimeHooks: v1alpha1.RuntimeHooksConfiguration{
+					FailurePolicy: pointer.String("Retry"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty runtime hooks addr",
+			args: &v1alpha1.KoordletConfiguration{
+				RuntimeHooks: v1alpha1.RuntimeHooksConfiguration{
+					Addr: pointer.String(""),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid runtime hooks exclusionPodSelectors",
+			args: &v1alpha1.KoordletConfiguration{
+				RuntimeHooks: v1alpha1.RuntimeHooksConfiguration{
+					ExclusionPodSelectors: []string{"app=gpu-operator", "app in katalyst"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero audit maxEventsLimit",
+			args: &v1alpha1.KoordletConfiguration{
+				Audit: v1alpha1.AuditConfiguration{
+					MaxEventsLimit: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero resourceForceUpdateSeconds",
+			args: &v1alpha1.KoordletConfiguration{
+				ResourceExecutor: v1alpha1.ResourceExecutorConfiguration{
+					ResourceForceUpdateSeconds: pointer.Int32(0),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative admin maxPauseDuration",
+			args: &v1alpha1.KoordletConfiguration{
+				Admin: v1alpha1.AdminConfiguration{
+					MaxPauseDuration: &metav1.Duration{Duration: -time.Hour},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NoError(t, config.AddToScheme(scheme))
+			assert.NoError(t, v1alpha1.AddToScheme(scheme))
+			scheme.Default(tt.args)
+			args := &config.KoordletConfiguration{}
+			assert.NoError(t, scheme.Convert(tt.args, args, nil))
+			err := ValidateKoordletConfiguration(args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKoordletConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ const (
 
 	EvictPodByNodeMemoryUsage   = "EvictPodByNodeMemoryUsage"
 	EvictPodByBECPUSatisfaction = "EvictPodByBECPUSatisfaction"
+	EvictPodByNodePIDUsage      = "EvictPodByNodePIDUsage"
 
 	AdjustBEByNodeCPUUsage = "AdjustBEByNodeCPUUsage"
 
@@ -25,15 +25,18 @@ import (
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	cliflag "k8s.io/component-base/cli/flag"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/admin"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 	qosmanagerconfig "github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/config"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
@@ -47,11 +50,14 @@ const (
 	DefaultKoordletConfigMapName      = "koordlet-config"
 
 	CMKeyQoSPluginExtraConfigs = "qos-plugin-extra-configs"
+	CMKeyProfile               = "profile"
 )
 
 type Configuration struct {
+	ConfigFile         string
 	ConfigMapName      string
 	ConfigMapNamesapce string
+	Profile            string
 	KubeRestConf       *rest.Config
 	StatesInformerConf *statesinformer.Config
 	CollectorConf      *metricsadvisor.Config
@@ -60,13 +66,18 @@ type Configuration struct {
 	QosManagerConf     *qosmanagerconfig.Config
 	RuntimeHookConf    *runtimehooks.Config
 	AuditConf          *audit.Config
+	AdminConf          *admin.Config
 	FeatureGates       map[string]bool
+
+	// specifiedProfileSettings is the flags of the profile settings whose fields are set in the config file.
+	specifiedProfileSettings sets.String
 }
 
 func NewConfiguration() *Configuration {
 	return &Configuration{
 		ConfigMapName:      DefaultKoordletConfigMapName,
 		ConfigMapNamesapce: DefaultKoordletConfigMapNamespace,
+		Profile:            string(profile.ProfileStandard),
 		StatesInformerConf: statesinformer.NewDefaultConfig(),
 		CollectorConf:      metricsadvisor.NewDefaultConfig(),
 		MetricCacheConf:    metriccache.NewDefaultConfig(),
@@ -74,12 +85,16 @@ func NewConfiguration() *Configuration {
 		QosManagerConf:     qosmanagerconfig.NewDefaultConfig(),
 		RuntimeHookConf:    runtimehooks.NewDefaultConfig(),
 		AuditConf:          audit.NewDefaultConfig(),
+		AdminConf:          admin.NewDefaultConfig(),
 	}
 }
 
 func (c *Configuration) InitFlags(fs *flag.FlagSet) {
-	fs.StringVar(&c.ConfigMapName, "configmap-name", DefaultKoordletConfigMapName, "determines the name the koordlet configmap uses.")
-	fs.StringVar(&c.ConfigMapNamesapce, "configmap-namespace", DefaultKoordletConfigMapNamespace, "determines the namespace of configmap uses.")
+	fs.StringVar(&c.ConfigFile, "config", c.ConfigFile, "The path to the KoordletConfiguration file. The flags set on the command line override the fields in the file.")
+	fs.StringVar(&c.ConfigMapName, "configmap-name", c.ConfigMapName, "determines the name the koordlet configmap uses.")
+	fs.StringVar(&c.ConfigMapNamesapce, "configmap-namespace", c.ConfigMapNamesapce, "determines the namespace of configmap uses.")
+	fs.StringVar(&c.Profile, "profile", c.Profile, "The profile presetting the collector intervals, the metric retention and the sync intervals, standard or edge. "+
+		"The settings set explicitly override the preset. The profile can be switched at runtime by the key \""+CMKeyProfile+"\" of the koordlet configmap.")
 	system.Conf.InitFlags(fs)
 	c.StatesInformerConf.InitFlags(fs)
 	c.CollectorConf.InitFlags(fs)
@@ -87,6 +102,7 @@ func (c *Configuration) InitFlags(fs *flag.FlagSet) {
 	c.ResManagerConf.InitFlags(fs)
 	c.RuntimeHookConf.InitFlags(fs)
 	c.AuditConf.InitFlags(fs)
+	c.AdminConf.InitFlags(fs)
 	resourceexecutor.Conf.InitFlags(fs)
 	fs.Var(cliflag.NewMapStringBool(&c.FeatureGates), "feature-gates", "A set of key=value pairs that describe feature gates for alpha/experimental features. "+
 		"Options are:\n"+strings.Join(features.DefaultKoordletFeatureGate.KnownFeatures(), "\n"))
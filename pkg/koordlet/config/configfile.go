@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	koordletconfig "github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config"
+	koordletconfigscheme "github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config/scheme"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/exclusion"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+// ApplyConfigFile loads the KoordletConfiguration file specified by the flag --config if any. The args are parsed
+// again after the file is applied, so that the flags set on the command line override the fields in the file.
+func (c *Configuration) ApplyConfigFile(fs *flag.FlagSet, args []string) error {
+	if len(c.ConfigFile) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(c.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s, err: %w", c.ConfigFile, err)
+	}
+	cfg, err := loadConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s, err: %w", c.ConfigFile, err)
+	}
+	if err = validation.ValidateKoordletConfiguration(cfg); err != nil {
+		return fmt.Errorf("invalid config file %s, err: %w", c.ConfigFile, err)
+	}
+	versioned, err := loadVersionedConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s, err: %w", c.ConfigFile, err)
+	}
+	c.applyKoordletConfiguration(cfg)
+	if specified := getSpecifiedProfileSettings(versioned); specified.Len() > 0 {
+		c.specifiedProfileSettings = specified
+	}
+	return c.reparseFlags(fs, args)
+}
+
+func loadConfig(data []byte) (*koordletconfig.KoordletConfiguration, error) {
+	// The UniversalDecoder runs defaulting and returns the internal type by default.
+	// The unknown fields are rejected since the codecs are strict.
+	obj, gvk, err := koordletconfigscheme.Codecs.UniversalDecoder().Decode(data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfgObj, ok := obj.(*koordletconfig.KoordletConfiguration); ok {
+		return cfgObj, nil
+	}
+	return nil, fmt.Errorf("couldn't decode as KoordletConfiguration, got %s", gvk)
+}
+
+// loadVersionedConfig decodes the config without defaulting, to tell the fields set in the file.
+func loadVersionedConfig(data []byte) (*v1alpha1.KoordletConfiguration, error) {
+	obj, gvk, err := koordletconfigscheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfgObj, ok := obj.(*v1alpha1.KoordletConfiguration); ok {
+		return cfgObj, nil
+	}
+	return nil, fmt.Errorf("couldn't decode as v1alpha1 KoordletConfiguration, got %s", gvk)
+}
+
+// reparseFlags binds the flags of the configuration to a new flag set and parses the args again. The flags registered
+// by others, e.g. klog, are parsed into the same values as before.
+func (c *Configuration) reparseFlags(fs *flag.FlagSet, args []string) error {
+	overrides := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	overrides.SetOutput(io.Discard)
+	c.InitFlags(overrides)
+	fs.VisitAll(func(f *flag.Flag) {
+		if overrides.Lookup(f.Name) == nil {
+			overrides.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	return overrides.Parse(args)
+}
+
+func (c *Configuration) applyKoordletConfiguration(cfg *koordletconfig.KoordletConfiguration) {
+	c.ConfigMapName = cfg.ConfigMapName
+	c.ConfigMapNamesapce = cfg.ConfigMapNamespace
+	c.Profile = cfg.Profile
+	if cfg.FeatureGates != nil {
+		c.FeatureGates = cfg.FeatureGates
+	}
+
+	applyIfNotEmpty(&system.Conf.CgroupRootDir, cfg.HostPaths.CgroupRootDir)
+	applyIfNotEmpty(&system.Conf.CgroupKubePath, cfg.HostPaths.CgroupKubeDir)
+	applyIfNotEmpty(&system.Conf.SysRootDir, cfg.HostPaths.SysRootDir)
+	applyIfNotEmpty(&system.Conf.SysFSRootDir, cfg.HostPaths.SysFSRootDir)
+	applyIfNotEmpty(&system.Conf.ProcRootDir, cfg.HostPaths.ProcRootDir)
+	applyIfNotEmpty(&system.Conf.VarRunRootDir, cfg.HostPaths.VarRunRootDir)
+	applyIfNotEmpty(&system.Conf.NodeNameOverride, cfg.HostPaths.NodeNameOverride)
+	applyIfNotEmpty(&system.Conf.ContainerdEndPoint, cfg.HostPaths.ContainerdEndpoint)
+	applyIfNotEmpty(&system.Conf.DockerEndPoint, cfg.HostPaths.DockerEndpoint)
+
+	statesInformer := &cfg.StatesInformer
+	c.StatesInformerConf.KubeletPreferredAddressType = statesInformer.KubeletPreferredAddressType
+	c.StatesInformerConf.KubeletSyncInterval = statesInformer.KubeletSyncInterval.Duration
+	c.StatesInformerConf.KubeletSyncTimeout = statesInformer.KubeletSyncTimeout.Duration
+	c.StatesInformerConf.InsecureKubeletTLS = statesInformer.KubeletInsecureTLS
+	c.StatesInformerConf.KubeletReadOnlyPort = uint(statesInformer.KubeletReadOnlyPort)
+	c.StatesInformerConf.NodeTopologySyncInterval = statesInformer.NodeTopologySyncInterval.Duration
+	c.StatesInformerConf.DisableQueryKubeletConfig = statesInformer.DisableQueryKubeletConfig
+	c.StatesInformerConf.EnableNodeMetricReport = statesInformer.EnableNodeMetricReport
+	c.StatesInformerConf.APIWriterQPS = statesInformer.APIWriterQPS
+	c.StatesInformerConf.APIWriterBurst = int(statesInformer.APIWriterBurst)
+	c.StatesInformerConf.APIWriterCoalesceWindow = statesInformer.APIWriterCoalesceWindow.Duration
+
+	metricsAdvisor := &cfg.MetricsAdvisor
+	c.CollectorConf.CollectResUsedIntervalSeconds = int(metricsAdvisor.CollectResUsedIntervalSeconds)
+	c.CollectorConf.CollectNodeCPUInfoIntervalSeconds = int(metricsAdvisor.CollectNodeCPUInfoIntervalSeconds)
+	c.CollectorConf.CPICollectorIntervalSeconds = int(metricsAdvisor.CPICollectorIntervalSeconds)
+	c.CollectorConf.PSICollectorIntervalSeconds = int(metricsAdvisor.PSICollectorIntervalSeconds)
+	c.CollectorConf.CPICollectorTimeWindowSeconds = int(metricsAdvisor.CPICollectorTimeWindowSeconds)
+	c.CollectorConf.MBMCollectorIntervalSeconds = int(metricsAdvisor.MBMCollectorIntervalSeconds)
+	c.CollectorConf.ProcessCollectorIntervalSeconds = int(metricsAdvisor.ProcessCollectorIntervalSeconds)
+	c.CollectorConf.ProcessFDSampleBudget = int(metricsAdvisor.ProcessFDSampleBudget)
+	c.CollectorConf.DiskIOCollectorIntervalSeconds = int(metricsAdvisor.DiskIOCollectorIntervalSeconds)
+	if metricsAdvisor.DiskIOCollectMountPoints != nil {
+		c.CollectorConf.DiskIOCollectMountPoints = metricsAdvisor.DiskIOCollectMountPoints
+	}
+
+	c.MetricCacheConf.MetricGCIntervalSeconds = int(cfg.MetricCache.MetricGCIntervalSeconds)
+	c.MetricCacheConf.MetricExpireSeconds = int(cfg.MetricCache.MetricExpireSeconds)
+
+	resManager := &cfg.ResManager
+	c.ResManagerConf.ReconcileIntervalSeconds = int(resManager.ReconcileIntervalSeconds)
+	c.ResManagerConf.CPUSuppressIntervalSeconds = int(resManager.CPUSuppressIntervalSeconds)
+	c.ResManagerConf.CPUEvictIntervalSeconds = int(resManager.CPUEvictIntervalSeconds)
+	c.ResManagerConf.MemoryEvictIntervalSeconds = int(resManager.MemoryEvictIntervalSeconds)
+	c.ResManagerConf.MemoryEvictCoolTimeSeconds = int(resManager.MemoryEvictCoolTimeSeconds)
+	c.ResManagerConf.CPUEvictCoolTimeSeconds = int(resManager.CPUEvictCoolTimeSeconds)
+	c.ResManagerConf.CgroupVerifyIntervalSeconds = int(resManager.CgroupVerifyIntervalSeconds)
+	c.ResManagerConf.CgroupVerifySampleRatio = resManager.CgroupVerifySampleRatio
+	c.ResManagerConf.CgroupVerifyMaxFilesPerCycle = int(resManager.CgroupVerifyMaxFilesPerCycle)
+	c.ResManagerConf.CgroupDriftWarningThreshold = int(resManager.CgroupDriftWarningThreshold)
+	c.ResManagerConf.MemoryLocalityRepairIntervalSeconds = int(resManager.MemoryLocalityRepairIntervalSeconds)
+	c.ResManagerConf.MemoryLocalityRepairMode = resManager.MemoryLocalityRepairMode
+	c.ResManagerConf.MemoryLocalitySampleContainersPerCycle = int(resManager.MemoryLocalitySampleContainersPerCycle)
+	c.ResManagerConf.MemoryLocalityRepairContainersPerCycle = int(resManager.MemoryLocalityRepairContainersPerCycle)
+	c.ResManagerConf.MemoryLocalityNodeCPUThresholdPercent = resManager.MemoryLocalityNodeCPUThresholdPercent
+	c.ResManagerConf.MemoryLocalityExpandSeconds = int(resManager.MemoryLocalityExpandSeconds)
+	c.ResManagerConf.OrphanArtifactGCIntervalSeconds = int(resManager.OrphanArtifactGCIntervalSeconds)
+	c.ResManagerConf.OrphanArtifactGCDryRun = resManager.OrphanArtifactGCDryRun
+	c.ResManagerConf.MBAFeedbackIntervalSeconds = int(resManager.MBAFeedbackIntervalSeconds)
+	c.ResManagerConf.MBAFeedbackWindowSeconds = int(resManager.MBAFeedbackWindowSeconds)
+	c.ResManagerConf.MBAFeedbackDegradePercent = resManager.MBAFeedbackDegradePercent
+	c.ResManagerConf.MBAFeedbackRelaxRounds = int(resManager.MBAFeedbackRelaxRounds)
+	c.ResManagerConf.GPUMemoryLeakCheckIntervalSeconds = int(resManager.GPUMemoryLeakCheckIntervalSeconds)
+	c.ResManagerConf.GPUMemoryLeakMarginPercent = resManager.GPUMemoryLeakMarginPercent
+	c.ResManagerConf.GPUMemoryLeakConsecutiveSamples = int(resManager.GPUMemoryLeakConsecutiveSamples)
+	c.ResManagerConf.PIDProtectionIntervalSeconds = int(resManager.PIDProtectionIntervalSeconds)
+	c.ResManagerConf.PIDEvictCoolTimeSeconds = int(resManager.PIDEvictCoolTimeSeconds)
+	c.ResManagerConf.NetIngressProtectionIntervalSeconds = int(resManager.NetIngressProtectionIntervalSeconds)
+	if resManager.QOSExtensionPlugins != nil {
+		c.ResManagerConf.QOSExtensionCfg.FeatureGates = resManager.QOSExtensionPlugins
+	}
+
+	if cfg.QoSManager.Plugins != nil {
+		c.QosManagerConf.FeatureGates = cfg.QoSManager.Plugins
+	}
+
+	runtimeHooks := &cfg.RuntimeHooks
+	c.RuntimeHookConf.RuntimeHooksNetwork = runtimeHooks.Network
+	c.RuntimeHookConf.RuntimeHooksAddr = runtimeHooks.Addr
+	c.RuntimeHookConf.RuntimeHooksFailurePolicy = runtimeHooks.FailurePolicy
+	c.RuntimeHookConf.RuntimeHooksPluginFailurePolicy = runtimeHooks.PluginFailurePolicy
+	applyIfNotEmpty(&c.RuntimeHookConf.RuntimeHookConfigFilePath, runtimeHooks.ConfigFilePath)
+	c.RuntimeHookConf.RuntimeHookHostEndpoint = runtimeHooks.HostEndpoint
+	if runtimeHooks.DisableStages != nil {
+		c.RuntimeHookConf.RuntimeHookDisableStages = runtimeHooks.DisableStages
+	}
+	if runtimeHooks.ExclusionPodSelectors != nil {
+		c.RuntimeHookConf.RuntimeHookExclusionPodSelectors = exclusion.FormatPodSelectors(runtimeHooks.ExclusionPodSelectors)
+	}
+	if runtimeHooks.ExclusionOwnerKinds != nil {
+		c.RuntimeHookConf.RuntimeHookExclusionOwnerKinds = runtimeHooks.ExclusionOwnerKinds
+	}
+	if runtimeHooks.ExclusionHooks != nil {
+		c.RuntimeHookConf.RuntimeHookExclusionHooks = runtimeHooks.ExclusionHooks
+	}
+
+	c.AuditConf.LogDir = cfg.Audit.LogDir
+	c.AuditConf.Verbose = int(cfg.Audit.Verbose)
+	c.AuditConf.MaxDiskSpaceMB = int(cfg.Audit.MaxDiskSpaceMB)
+	c.AuditConf.MaxConcurrentReaders = int(cfg.Audit.MaxConcurrentReaders)
+	c.AuditConf.MaxEventsLimit = int(cfg.Audit.MaxEventsLimit)
+
+	resourceexecutor.Conf.ResourceForceUpdateSeconds = int(cfg.ResourceExecutor.ResourceForceUpdateSeconds)
+
+	c.AdminConf.SocketPath = cfg.Admin.SocketPath
+	c.AdminConf.CheckpointPath = cfg.Admin.CheckpointPath
+	c.AdminConf.MaxPauseDuration = cfg.Admin.MaxPauseDuration.Duration
+}
+
+func applyIfNotEmpty(dst *string, value string) {
+	if len(value) > 0 {
+		*dst = value
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "koordlet-config.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func parseConfiguration(t *testing.T, args []string) (*Configuration, error) {
+	cfg := NewConfiguration()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.InitFlags(fs)
+	assert.NoError(t, fs.Parse(args))
+	return cfg, cfg.ApplyConfigFile(fs, args)
+}
+
+func TestConfiguration_ApplyConfigFile(t *testing.T) {
+	oldSystemConf, oldExecutorConf := *system.Conf, *resourceexecutor.Conf
+	defer func() {
+		*system.Conf, *resourceexecutor.Conf = oldSystemConf, oldExecutorConf
+	}()
+
+	t.Run("flags only", func(t *testing.T) {
+		cfg, err := parseConfiguration(t, []string{"--cpu-evict-interval-seconds=3"})
+		assert.NoError(t, err)
+		expected := NewConfiguration()
+		expected.ResManagerConf.CPUEvictIntervalSeconds = 3
+		assert.Equal(t, expected, cfg)
+	})
+
+	t.Run("empty config file keeps the flag defaults", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+`)
+		cfg, err := parseConfiguration(t, []string{"--config=" + file})
+		assert.NoError(t, err)
+		expected := NewConfiguration()
+		expected.ConfigFile = file
+		assert.Equal(t, expected, cfg)
+		assert.Equal(t, oldSystemConf, *system.Conf)
+		assert.Equal(t, oldExecutorConf, *resourceexecutor.Conf)
+	})
+
+	t.Run("flags override the config file", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+configMapName: test-config
+featureGates:
+  AuditEvents: true
+hostPaths:
+  nodeNameOverride: test-node
+statesInformer:
+  kubeletSyncInterval: 30s
+  apiWriterQPS: 2.5
+metricsAdvisor:
+  mbmCollectorIntervalSeconds: 30
+  processFDSampleBudget: 500
+  diskIOCollectMountPoints:
+  - /var/lib/kubelet
+  - /var/lib/containerd
+resManager:
+  cpuEvictIntervalSeconds: 5
+  memoryEvictIntervalSeconds: 5
+  orphanArtifactGCDryRun: true
+  mbaFeedbackDegradePercent: 20
+  gpuMemoryLeakConsecutiveSamples: 5
+  pidEvictCoolTimeSeconds: 120
+  netIngressProtectionIntervalSeconds: 30
+runtimeHooks:
+  disableStages:
+  - PreRunPodSandbox
+  exclusionPodSelectors:
+  - app=gpu-operator
+  - app in (katalyst)
+  exclusionHooks:
+    CPUSetAllocator: false
+resourceExecutor:
+  resourceForceUpdateSeconds: 30
+admin:
+  socketPath: ""
+  maxPauseDuration: 1h
+`)
+		cfg, err := parseConfiguration(t, []string{
+			"--config=" + file,
+			"--cpu-evict-interval-seconds=7",
+			"--runtime-hooks-disable-stages=PreStartContainer",
+			"--resource-force-update-seconds=90",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "test-config", cfg.ConfigMapName)
+		assert.Equal(t, DefaultKoordletConfigMapNamespace, cfg.ConfigMapNamesapce)
+		assert.Equal(t, map[string]bool{"AuditEvents": true}, cfg.FeatureGates)
+		assert.Equal(t, "test-node", system.Conf.NodeNameOverride)
+		assert.Equal(t, oldSystemConf.CgroupRootDir, system.Conf.CgroupRootDir)
+		assert.Equal(t, 30*time.Second, cfg.StatesInformerConf.KubeletSyncInterval)
+		assert.Equal(t, 2.5, cfg.StatesInformerConf.APIWriterQPS)
+		assert.Equal(t, 30, cfg.CollectorConf.MBMCollectorIntervalSeconds)
+		assert.True(t, cfg.specifiedProfileSettings.Has("mbm-collector-interval-seconds"))
+		assert.Equal(t, 500, cfg.CollectorConf.ProcessFDSampleBudget)
+		assert.Equal(t, []string{"/var/lib/kubelet", "/var/lib/containerd"}, cfg.CollectorConf.DiskIOCollectMountPoints)
+		assert.Equal(t, 7, cfg.ResManagerConf.CPUEvictIntervalSeconds)
+		assert.Equal(t, 5, cfg.ResManagerConf.MemoryEvictIntervalSeconds)
+		assert.True(t, cfg.ResManagerConf.OrphanArtifactGCDryRun)
+		assert.Equal(t, int64(20), cfg.ResManagerConf.MBAFeedbackDegradePercent)
+		assert.Equal(t, 5, cfg.ResManagerConf.GPUMemoryLeakConsecutiveSamples)
+		assert.Equal(t, 120, cfg.ResManagerConf.PIDEvictCoolTimeSeconds)
+		assert.Equal(t, 30, cfg.ResManagerConf.NetIngressProtectionIntervalSeconds)
+		assert.Equal(t, []string{"PreStartContainer"}, cfg.RuntimeHookConf.RuntimeHookDisableStages)
+		assert.Equal(t, "app=gpu-operator;app in (katalyst)", cfg.RuntimeHookConf.RuntimeHookExclusionPodSelectors)
+		assert.Equal(t, map[string]bool{"CPUSetAllocator": false}, cfg.RuntimeHookConf.RuntimeHookExclusionHooks)
+		assert.Equal(t, 90, resourceexecutor.Conf.ResourceForceUpdateSeconds)
+		assert.Empty(t, cfg.AdminConf.SocketPath)
+		assert.Equal(t, time.Hour, cfg.AdminConf.MaxPauseDuration)
+	})
+
+	t.Run("other flags are kept", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+`)
+		cfg := NewConfiguration()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		cfg.InitFlags(fs)
+		verbose := fs.Bool("test-verbose", false, "")
+		args := []string{"--config=" + file, "--test-verbose"}
+		assert.NoError(t, fs.Parse(args))
+		assert.NoError(t, cfg.ApplyConfigFile(fs, args))
+		assert.True(t, *verbose)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+resManager:
+  cpuEvictIntervalSecond: 5
+`)
+		_, err := parseConfiguration(t, []string{"--config=" + file})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown field")
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KubeletConfiguration
+`)
+		_, err := parseConfiguration(t, []string{"--config=" + file})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+resManager:
+  memoryLocalityRepairMode: Rebind
+`)
+		cfg, err := parseConfiguration(t, []string{"--config=" + file})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "resManager.memoryLocalityRepairMode")
+		assert.Equal(t, NewConfiguration().ResManagerConf, cfg.ResManagerConf)
+	})
+
+	t.Run("config file not found", func(t *testing.T) {
+		_, err := parseConfiguration(t, []string{"--config=" + filepath.Join(t.TempDir(), "not-found.yaml")})
+		assert.Error(t, err)
+	})
+}
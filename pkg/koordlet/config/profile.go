@@ -0,0 +1,244 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/apis/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
+)
+
+const profileSyncInterval = time.Minute
+
+// profileSetting binds a setting of the profile preset to the flag and the field of the config file which set it.
+type profileSetting struct {
+	flag string
+	// specified returns whether the field is set in the config file, nil if the config file has no such field.
+	specified func(cfg *v1alpha1.KoordletConfiguration) bool
+	// bind returns the setting in the configuration and in the preset, both are *int or *time.Duration.
+	bind func(c *Configuration, preset *profile.Preset) (interface{}, interface{})
+}
+
+var profileSettings = []profileSetting{
+	{
+		flag: "collect-res-used-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.CollectResUsedIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.CollectResUsedIntervalSeconds, &preset.CollectResUsedIntervalSeconds
+		},
+	},
+	{
+		flag: "collect-node-cpu-info-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.CollectNodeCPUInfoIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.CollectNodeCPUInfoIntervalSeconds, &preset.CollectNodeCPUInfoIntervalSeconds
+		},
+	},
+	{
+		flag: "cpi-collector-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.CPICollectorIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.CPICollectorIntervalSeconds, &preset.CPICollectorIntervalSeconds
+		},
+	},
+	{
+		flag: "psi-collector-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.PSICollectorIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.PSICollectorIntervalSeconds, &preset.PSICollectorIntervalSeconds
+		},
+	},
+	{
+		flag: "mbm-collector-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.MBMCollectorIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.MBMCollectorIntervalSeconds, &preset.MBMCollectorIntervalSeconds
+		},
+	},
+	{
+		flag: "process-collector-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.ProcessCollectorIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.ProcessCollectorIntervalSeconds, &preset.ProcessCollectorIntervalSeconds
+		},
+	},
+	{
+		flag: "disk-io-collector-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.MetricsAdvisor.DiskIOCollectorIntervalSeconds != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.CollectorConf.DiskIOCollectorIntervalSeconds, &preset.DiskIOCollectorIntervalSeconds
+		},
+	},
+	{
+		flag:      "metric-gc-interval-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool { return cfg.MetricCache.MetricGCIntervalSeconds != nil },
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.MetricCacheConf.MetricGCIntervalSeconds, &preset.MetricGCIntervalSeconds
+		},
+	},
+	{
+		flag:      "metric-expire-seconds",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool { return cfg.MetricCache.MetricExpireSeconds != nil },
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.MetricCacheConf.MetricExpireSeconds, &preset.MetricExpireSeconds
+		},
+	},
+	{
+		flag:      "kubelet-sync-interval",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool { return cfg.StatesInformer.KubeletSyncInterval != nil },
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.StatesInformerConf.KubeletSyncInterval, &preset.KubeletSyncInterval
+		},
+	},
+	{
+		flag: "node-topology-sync-interval",
+		specified: func(cfg *v1alpha1.KoordletConfiguration) bool {
+			return cfg.StatesInformer.NodeTopologySyncInterval != nil
+		},
+		bind: func(c *Configuration, preset *profile.Preset) (interface{}, interface{}) {
+			return &c.StatesInformerConf.NodeTopologySyncInterval, &preset.NodeTopologySyncInterval
+		},
+	},
+}
+
+// getSpecifiedProfileSettings returns the flags of the profile settings whose fields are set in the config file.
+func getSpecifiedProfileSettings(cfg *v1alpha1.KoordletConfiguration) sets.String {
+	specified := sets.NewString()
+	for i := range profileSettings {
+		if s := &profileSettings[i]; s.specified != nil && s.specified(cfg) {
+			specified.Insert(s.flag)
+		}
+	}
+	return specified
+}
+
+// ApplyProfile applies the preset of the profile to the settings not set explicitly. The settings set by the flags
+// or the config file take precedence over the preset, also after the profile is switched at runtime.
+func (c *Configuration) ApplyProfile(fs *flag.FlagSet) error {
+	p := profile.Profile(c.Profile)
+	if !profile.IsValid(p) {
+		return fmt.Errorf("unknown profile %q", c.Profile)
+	}
+	explicit := sets.NewString(c.specifiedProfileSettings.UnsortedList()...)
+	fs.Visit(func(f *flag.Flag) {
+		explicit.Insert(f.Name)
+	})
+
+	var overrides []profile.Override
+	for i := range profileSettings {
+		if s := &profileSettings[i]; explicit.Has(s.flag) {
+			overrides = append(overrides, c.newProfileOverride(s))
+		}
+	}
+	m := profile.NewManager(overrides...)
+	if _, err := m.Switch(p); err != nil {
+		return err
+	}
+	profile.SetDefaultManager(m)
+
+	effective := m.Effective()
+	for i := range profileSettings {
+		setProfileSetting(profileSettings[i].bind(c, effective))
+	}
+	klog.V(4).Infof("koordlet profile %s is applied, effective settings %+v", p, *effective)
+	return nil
+}
+
+// newProfileOverride returns the override keeping the current value of the setting, since the configuration is
+// overwritten by the effective settings later.
+func (c *Configuration) newProfileOverride(s *profileSetting) profile.Override {
+	explicit := &profile.Preset{}
+	current, value := s.bind(c, explicit)
+	setProfileSetting(value, current)
+	return func(preset *profile.Preset) {
+		_, dst := s.bind(c, preset)
+		setProfileSetting(dst, value)
+	}
+}
+
+func setProfileSetting(dst, src interface{}) {
+	switch d := dst.(type) {
+	case *int:
+		*d = *src.(*int)
+	case *time.Duration:
+		*d = *src.(*time.Duration)
+	}
+}
+
+// RunProfileSync switches the profile to the one of the key in the koordlet configmap periodically, so the profile
+// takes effect without restart. The profile of the flags or the config file is restored if the key is removed.
+func (c *Configuration) RunProfileSync(stopCh <-chan struct{}) error {
+	if c.KubeRestConf == nil {
+		return errors.New("KubeRestConf is nil")
+	}
+	cli, err := kubernetes.NewForConfig(c.KubeRestConf)
+	if err != nil {
+		return err
+	}
+	go wait.Until(func() {
+		c.syncProfile(cli)
+	}, profileSyncInterval, stopCh)
+	return nil
+}
+
+func (c *Configuration) syncProfile(cli kubernetes.Interface) {
+	target := profile.Profile(c.Profile)
+	cm, err := cli.CoreV1().ConfigMaps(c.ConfigMapNamesapce).Get(context.TODO(), c.ConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		if value := cm.Data[CMKeyProfile]; len(value) > 0 {
+			target = profile.Profile(value)
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		klog.Warningf("failed to get configmap %s/%s to sync the profile, err: %v", c.ConfigMapNamesapce, c.ConfigMapName, err)
+		return
+	}
+
+	changed, err := profile.Switch(target)
+	if err != nil {
+		klog.Warningf("failed to switch to the profile of configmap %s/%s, err: %v", c.ConfigMapNamesapce, c.ConfigMapName, err)
+		return
+	}
+	if changed {
+		klog.Infof("koordlet profile is switched to %s, effective settings %+v", target, *profile.Effective())
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+func applyProfile(t *testing.T, args []string) (*Configuration, error) {
+	cfg := NewConfiguration()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.InitFlags(fs)
+	assert.NoError(t, fs.Parse(args))
+	if err := cfg.ApplyConfigFile(fs, args); err != nil {
+		return nil, err
+	}
+	return cfg, cfg.ApplyProfile(fs)
+}
+
+func Test_standardProfileKeepsDefaults(t *testing.T) {
+	preset, err := profile.GetPreset(profile.ProfileStandard)
+	assert.NoError(t, err)
+	collector, cache, informer := metricsadvisor.NewDefaultConfig(), metriccache.NewDefaultConfig(), statesinformer.NewDefaultConfig()
+	assert.Equal(t, profile.Preset{
+		CollectResUsedIntervalSeconds:     collector.CollectResUsedIntervalSeconds,
+		CollectNodeCPUInfoIntervalSeconds: collector.CollectNodeCPUInfoIntervalSeconds,
+		CPICollectorIntervalSeconds:       collector.CPICollectorIntervalSeconds,
+		PSICollectorIntervalSeconds:       collector.PSICollectorIntervalSeconds,
+		MBMCollectorIntervalSeconds:       collector.MBMCollectorIntervalSeconds,
+		ProcessCollectorIntervalSeconds:   collector.ProcessCollectorIntervalSeconds,
+		DiskIOCollectorIntervalSeconds:    collector.DiskIOCollectorIntervalSeconds,
+		MetricGCIntervalSeconds:           cache.MetricGCIntervalSeconds,
+		MetricExpireSeconds:               cache.MetricExpireSeconds,
+		KubeletSyncInterval:               informer.KubeletSyncInterval,
+		NodeTopologySyncInterval:          informer.NodeTopologySyncInterval,
+	}, preset)
+}
+
+func TestConfiguration_ApplyProfile(t *testing.T) {
+	defer profile.SetDefaultManager(profile.NewManager())
+
+	edge, err := profile.GetPreset(profile.ProfileEdge)
+	assert.NoError(t, err)
+
+	t.Run("standard by default", func(t *testing.T) {
+		cfg, err := applyProfile(t, nil)
+		assert.NoError(t, err)
+		expected := NewConfiguration()
+		assert.Equal(t, expected, cfg)
+		assert.Equal(t, profile.ProfileStandard, profile.Current())
+	})
+
+	t.Run("edge by flag", func(t *testing.T) {
+		cfg, err := applyProfile(t, []string{"--profile=edge"})
+		assert.NoError(t, err)
+		assert.Equal(t, edge, *profile.Effective())
+		assert.Equal(t, edge.CollectResUsedIntervalSeconds, cfg.CollectorConf.CollectResUsedIntervalSeconds)
+		assert.Equal(t, 0, cfg.CollectorConf.CPICollectorIntervalSeconds)
+		assert.Equal(t, edge.MetricExpireSeconds, cfg.MetricCacheConf.MetricExpireSeconds)
+		assert.Equal(t, edge.KubeletSyncInterval, cfg.StatesInformerConf.KubeletSyncInterval)
+		// the settings out of the profile are kept
+		assert.Equal(t, NewConfiguration().CollectorConf.CPICollectorTimeWindowSeconds, cfg.CollectorConf.CPICollectorTimeWindowSeconds)
+	})
+
+	t.Run("flags and config file override the preset", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+profile: edge
+metricsAdvisor:
+  cpiCollectorIntervalSeconds: 90
+metricCache:
+  metricExpireSeconds: 1200
+statesInformer:
+  kubeletSyncInterval: 20s
+`)
+		cfg, err := applyProfile(t, []string{
+			"--config=" + file,
+			"--process-collector-interval-seconds=15",
+			"--metric-expire-seconds=900",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "edge", cfg.Profile)
+		effective := profile.Effective()
+		assert.Equal(t, 90, effective.CPICollectorIntervalSeconds)
+		assert.Equal(t, 15, effective.ProcessCollectorIntervalSeconds)
+		// the flag takes precedence over the config file
+		assert.Equal(t, 900, effective.MetricExpireSeconds)
+		assert.Equal(t, 20*time.Second, effective.KubeletSyncInterval)
+		assert.Equal(t, edge.MBMCollectorIntervalSeconds, effective.MBMCollectorIntervalSeconds)
+		assert.Equal(t, edge.MetricGCIntervalSeconds, effective.MetricGCIntervalSeconds)
+		assert.Equal(t, 90, cfg.CollectorConf.CPICollectorIntervalSeconds)
+		assert.Equal(t, 900, cfg.MetricCacheConf.MetricExpireSeconds)
+		assert.Equal(t, edge.MetricGCIntervalSeconds, cfg.MetricCacheConf.MetricGCIntervalSeconds)
+
+		// the overrides are kept after the profile is switched at runtime
+		_, err = profile.Switch(profile.ProfileStandard)
+		assert.NoError(t, err)
+		effective = profile.Effective()
+		assert.Equal(t, 90, effective.CPICollectorIntervalSeconds)
+		assert.Equal(t, 900, effective.MetricExpireSeconds)
+		assert.Equal(t, 10, effective.MBMCollectorIntervalSeconds)
+		assert.Equal(t, 300, effective.MetricGCIntervalSeconds)
+	})
+
+	t.Run("flag overrides the profile of config file", func(t *testing.T) {
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+profile: edge
+`)
+		cfg, err := applyProfile(t, []string{"--config=" + file, "--profile=standard"})
+		assert.NoError(t, err)
+		assert.Equal(t, "standard", cfg.Profile)
+		assert.Equal(t, profile.ProfileStandard, profile.Current())
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := applyProfile(t, []string{"--profile=tiny"})
+		assert.Error(t, err)
+
+		file := writeConfigFile(t, `
+apiVersion: koordlet/v1alpha1
+kind: KoordletConfiguration
+profile: tiny
+`)
+		_, err = applyProfile(t, []string{"--config=" + file})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "profile")
+	})
+}
+
+func TestConfiguration_syncProfile(t *testing.T) {
+	defer profile.SetDefaultManager(profile.NewManager())
+
+	cfg, err := applyProfile(t, []string{"--metric-expire-seconds=900"})
+	assert.NoError(t, err)
+	cli := fake.NewSimpleClientset()
+
+	// no configmap keeps the profile
+	cfg.syncProfile(cli)
+	assert.Equal(t, profile.ProfileStandard, profile.Current())
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.ConfigMapName, Namespace: cfg.ConfigMapNamesapce},
+		Data:       map[string]string{CMKeyProfile: "edge"},
+	}
+	_, err = cli.CoreV1().ConfigMaps(cm.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	cfg.syncProfile(cli)
+	assert.Equal(t, profile.ProfileEdge, profile.Current())
+	assert.Equal(t, 10, profile.Effective().CollectResUsedIntervalSeconds)
+	assert.Equal(t, 900, profile.Effective().MetricExpireSeconds)
+
+	// an unknown profile is ignored
+	cm.Data[CMKeyProfile] = "tiny"
+	_, err = cli.CoreV1().ConfigMaps(cm.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	cfg.syncProfile(cli)
+	assert.Equal(t, profile.ProfileEdge, profile.Current())
+
+	// the profile of the flags is restored after the key is removed
+	delete(cm.Data, CMKeyProfile)
+	_, err = cli.CoreV1().ConfigMaps(cm.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	cfg.syncProfile(cli)
+	assert.Equal(t, profile.ProfileStandard, profile.Current())
+	assert.Equal(t, 1, profile.Effective().CollectResUsedIntervalSeconds)
+}
@@ -34,6 +34,7 @@ import (
 
 	clientsetbeta1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	"github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/admin"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/config"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
@@ -43,6 +44,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 var (
@@ -64,6 +66,8 @@ type daemon struct {
 	resManager     resmanager.ResManager
 	qosManager     qosmanager.QoSManager
 	runtimeHook    runtimehooks.RuntimeHook
+	featurePauser  *admin.FeaturePauser
+	adminServer    *admin.Server
 }
 
 func NewDaemon(config *config.Configuration) (Daemon, error) {
@@ -140,6 +144,13 @@ func NewDaemon(config *config.Configuration) (Daemon, error) {
 		runtimeHook:    runtimeHook,
 	}
 
+	// the feature pauses restored from the checkpoint take effect before any feature module runs
+	if len(config.AdminConf.SocketPath) > 0 {
+		d.featurePauser = admin.NewFeaturePauser(config.AdminConf, admin.NewNodeConditionSyncer(kubeClient, nodeName))
+		d.adminServer = admin.NewServer(config.AdminConf, d.featurePauser)
+		util.SetFeaturePauser(d.featurePauser)
+	}
+
 	return d, nil
 }
 
@@ -181,6 +192,15 @@ func (d *daemon) Run(stopCh <-chan struct{}) {
 		os.Exit(1)
 	}
 
+	if d.featurePauser != nil {
+		go d.featurePauser.Run(stopCh)
+		go func() {
+			if err := d.adminServer.Run(stopCh); err != nil {
+				klog.Error("Unable to run the admin server: ", err)
+			}
+		}()
+	}
+
 	// start resmanager
 	go func() {
 		if err := d.resManager.Run(stopCh); err != nil {
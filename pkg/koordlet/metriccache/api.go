@@ -90,6 +90,67 @@ type BECPUResourceQueryResult struct {
 	Metric *BECPUResourceMetric
 }
 
+// ResctrlMemBandwidthMetric is the memory bandwidth of a resctrl group measured by the MBM counters.
+type ResctrlMemBandwidthMetric struct {
+	Group string
+	// SocketBandwidth is the memory bandwidth on each socket in bytes per second.
+	SocketBandwidth map[int32]float64
+}
+
+// TotalBandwidth returns the memory bandwidth of all sockets in bytes per second.
+func (m *ResctrlMemBandwidthMetric) TotalBandwidth() float64 {
+	var total float64
+	for _, bandwidth := range m.SocketBandwidth {
+		total += bandwidth
+	}
+	return total
+}
+
+// TerminatingPodsResourceMetric is the total resource usage of the terminating pods which are not BE, i.e. the pods
+// deleted but still running like the containers in the long preStop hooks.
+type TerminatingPodsResourceMetric struct {
+	CPUUsed    resource.Quantity
+	MemoryUsed resource.Quantity
+}
+
+type TerminatingPodsResourceQueryResult struct {
+	QueryResult
+	Metric *TerminatingPodsResourceMetric
+}
+
+type ResctrlMemBandwidthQueryResult struct {
+	QueryResult
+	Metric *ResctrlMemBandwidthMetric
+}
+
+// ContainerProcessMetric is the number of the tasks and the open file descriptors of a container.
+type ContainerProcessMetric struct {
+	PodUID      string
+	ContainerID string
+	// PIDs is the number of the tasks, i.e. the processes and the threads, in the container cgroup.
+	PIDs int64
+	// FDs is the number of the open file descriptors of the processes in the container.
+	FDs int64
+}
+
+type ContainerProcessQueryResult struct {
+	QueryResult
+	Metric *ContainerProcessMetric
+}
+
+// NodeProcessMetric is the number of the tasks and the open file descriptors of the node, along with the limits.
+type NodeProcessMetric struct {
+	PIDs    int64
+	PIDsMax int64
+	FDs     int64
+	FDsMax  int64
+}
+
+type NodeProcessQueryResult struct {
+	QueryResult
+	Metric *NodeProcessMetric
+}
+
 type PodThrottledMetric struct {
 	PodUID             string
 	CPUThrottledMetric *CPUThrottledMetric
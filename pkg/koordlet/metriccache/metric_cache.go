@@ -23,8 +23,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 )
 
 type AggregationType string
@@ -94,6 +95,10 @@ type MetricCache interface {
 	GetContainerResourceMetric(containerID *string, param *QueryParam) ContainerResourceQueryResult
 	GetNodeCPUInfo(param *QueryParam) (*NodeCPUInfo, error)
 	GetBECPUResourceMetric(param *QueryParam) BECPUResourceQueryResult
+	GetTerminatingPodsResourceMetric(param *QueryParam) TerminatingPodsResourceQueryResult
+	GetResctrlMemBandwidthMetric(group *string, param *QueryParam) ResctrlMemBandwidthQueryResult
+	GetContainerProcessMetric(containerID *string, param *QueryParam) ContainerProcessQueryResult
+	GetNodeProcessMetric(param *QueryParam) NodeProcessQueryResult
 	GetPodThrottledMetric(podUID *string, param *QueryParam) PodThrottledQueryResult
 	GetContainerThrottledMetric(containerID *string, param *QueryParam) ContainerThrottledQueryResult
 	GetContainerInterferenceMetric(metricName InterferenceMetricName, podUID *string, containerID *string, param *QueryParam) ContainerInterferenceQueryResult
@@ -103,6 +108,10 @@ type MetricCache interface {
 	InsertContainerResourceMetric(t time.Time, containerResUsed *ContainerResourceMetric) error
 	InsertNodeCPUInfo(info *NodeCPUInfo) error
 	InsertBECPUResourceMetric(t time.Time, metric *BECPUResourceMetric) error
+	InsertTerminatingPodsResourceMetric(t time.Time, metric *TerminatingPodsResourceMetric) error
+	InsertResctrlMemBandwidthMetric(t time.Time, metric *ResctrlMemBandwidthMetric) error
+	InsertContainerProcessMetric(t time.Time, metric *ContainerProcessMetric) error
+	InsertNodeProcessMetric(t time.Time, metric *NodeProcessMetric) error
 	InsertPodThrottledMetrics(t time.Time, metric *PodThrottledMetric) error
 	InsertContainerThrottledMetrics(t time.Time, metric *ContainerThrottledMetric) error
 	InsertContainerInterferenceMetrics(t time.Time, metric *ContainerInterferenceMetric) error
@@ -128,9 +137,9 @@ func NewMetricCache(cfg *Config) (MetricCache, error) {
 func (m *metricCache) Run(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 
-	go wait.Until(func() {
-		m.recycleDB()
-	}, time.Duration(m.config.MetricGCIntervalSeconds)*time.Second, stopCh)
+	go profile.Until(m.recycleDB, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.MetricGCIntervalSeconds }, m.config.MetricGCIntervalSeconds)
+	}, stopCh)
 
 	return nil
 }
@@ -390,6 +399,182 @@ func (m *metricCache) GetBECPUResourceMetric(param *QueryParam) BECPUResourceQue
 	return result
 }
 
+func (m *metricCache) GetTerminatingPodsResourceMetric(param *QueryParam) TerminatingPodsResourceQueryResult {
+	result := TerminatingPodsResourceQueryResult{}
+	if param == nil || param.Start == nil || param.End == nil {
+		result.Error = fmt.Errorf("TerminatingPodsResourceMetric query parameters are illegal %v", param)
+		return result
+	}
+	metrics, err := m.db.GetTerminatingPodsResourceMetric(param.Start, param.End)
+	if err != nil {
+		result.Error = fmt.Errorf("get TerminatingPodsResourceMetric failed, query params %v, error %v", param, err)
+		return result
+	}
+	if len(metrics) == 0 {
+		result.Error = fmt.Errorf("get TerminatingPodsResourceMetric not exist, query params %v", param)
+		return result
+	}
+
+	aggregateFunc := getAggregateFunc(param.Aggregate)
+	cpuUsed, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "CPUUsedCores", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get terminating pods aggregate CPUUsedCores failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+	memoryUsed, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "MemoryUsedBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get terminating pods aggregate MemoryUsedBytes failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+
+	count, err := count(metrics)
+	if err != nil {
+		result.Error = fmt.Errorf("get terminating pods aggregate count failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+
+	result.AggregateInfo = &AggregateInfo{MetricsCount: int64(count)}
+	result.Metric = &TerminatingPodsResourceMetric{
+		CPUUsed:    *resource.NewMilliQuantity(int64(cpuUsed*1000), resource.DecimalSI),
+		MemoryUsed: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
+	}
+	return result
+}
+
+func (m *metricCache) GetResctrlMemBandwidthMetric(group *string, param *QueryParam) ResctrlMemBandwidthQueryResult {
+	result := ResctrlMemBandwidthQueryResult{}
+	if param == nil || param.Start == nil || param.End == nil {
+		result.Error = fmt.Errorf("ResctrlMemBandwidthMetric query parameters are illegal %v", param)
+		return result
+	}
+	metrics, err := m.db.GetResctrlMemBandwidthMetric(group, param.Start, param.End)
+	if err != nil {
+		result.Error = fmt.Errorf("get ResctrlMemBandwidthMetric failed, query params %v, error %v", param, err)
+		return result
+	}
+	if len(metrics) == 0 {
+		result.Error = fmt.Errorf("get ResctrlMemBandwidthMetric not exist, query params %v", param)
+		return result
+	}
+
+	// aggregate the samples of each socket separately
+	socketMetrics := map[int32][]resctrlMemBandwidthMetric{}
+	for _, metric := range metrics {
+		socketMetrics[metric.SocketID] = append(socketMetrics[metric.SocketID], metric)
+	}
+	aggregateFunc := getAggregateFunc(param.Aggregate)
+	socketBandwidth := make(map[int32]float64, len(socketMetrics))
+	for socketID, samples := range socketMetrics {
+		bandwidth, err := aggregateFunc(samples, AggregateParam{ValueFieldName: "Bandwidth", TimeFieldName: "Timestamp"})
+		if err != nil {
+			result.Error = fmt.Errorf("get aggregate Bandwidth of socket %v failed, metrics %v, error %v",
+				socketID, samples, err)
+			return result
+		}
+		socketBandwidth[socketID] = bandwidth
+	}
+
+	count, err := count(metrics)
+	if err != nil {
+		result.Error = fmt.Errorf("get resctrl mem bandwidth aggregate count failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+
+	result.AggregateInfo = &AggregateInfo{MetricsCount: int64(count)}
+	result.Metric = &ResctrlMemBandwidthMetric{
+		Group:           *group,
+		SocketBandwidth: socketBandwidth,
+	}
+	return result
+}
+
+func (m *metricCache) GetContainerProcessMetric(containerID *string, param *QueryParam) ContainerProcessQueryResult {
+	result := ContainerProcessQueryResult{}
+	if containerID == nil || param == nil || param.Start == nil || param.End == nil {
+		result.Error = fmt.Errorf("ContainerProcessMetric %v query parameters are illegal %v", containerID, param)
+		return result
+	}
+	metrics, err := m.db.GetContainerProcessMetric(containerID, param.Start, param.End)
+	if err != nil {
+		result.Error = fmt.Errorf("get ContainerProcessMetric %v failed, query params %v, error %v", containerID, param, err)
+		return result
+	}
+	if len(metrics) == 0 {
+		result.Error = fmt.Errorf("get ContainerProcessMetric %v not exist, query params %v", containerID, param)
+		return result
+	}
+
+	aggregateFunc := getAggregateFunc(param.Aggregate)
+	pids, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "PIDs", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get container %v aggregate PIDs failed, metrics %v, error %v", containerID, metrics, err)
+		return result
+	}
+	fds, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "FDs", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get container %v aggregate FDs failed, metrics %v, error %v", containerID, metrics, err)
+		return result
+	}
+
+	count, err := count(metrics)
+	if err != nil {
+		result.Error = fmt.Errorf("get container %v aggregate count failed, metrics %v, error %v", containerID, metrics, err)
+		return result
+	}
+
+	result.AggregateInfo = &AggregateInfo{MetricsCount: int64(count)}
+	result.Metric = &ContainerProcessMetric{
+		PodUID:      metrics[len(metrics)-1].PodUID,
+		ContainerID: *containerID,
+		PIDs:        int64(pids),
+		FDs:         int64(fds),
+	}
+	return result
+}
+
+func (m *metricCache) GetNodeProcessMetric(param *QueryParam) NodeProcessQueryResult {
+	result := NodeProcessQueryResult{}
+	if param == nil || param.Start == nil || param.End == nil {
+		result.Error = fmt.Errorf("NodeProcessMetric query parameters are illegal %v", param)
+		return result
+	}
+	metrics, err := m.db.GetNodeProcessMetric(param.Start, param.End)
+	if err != nil {
+		result.Error = fmt.Errorf("get NodeProcessMetric failed, query params %v, error %v", param, err)
+		return result
+	}
+	if len(metrics) == 0 {
+		result.Error = fmt.Errorf("get NodeProcessMetric not exist, query params %v", param)
+		return result
+	}
+
+	aggregateFunc := getAggregateFunc(param.Aggregate)
+	values := map[string]float64{}
+	for _, fieldName := range []string{"PIDs", "PIDsMax", "FDs", "FDsMax"} {
+		value, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: fieldName, TimeFieldName: "Timestamp"})
+		if err != nil {
+			result.Error = fmt.Errorf("get node aggregate %s failed, metrics %v, error %v", fieldName, metrics, err)
+			return result
+		}
+		values[fieldName] = value
+	}
+
+	count, err := count(metrics)
+	if err != nil {
+		result.Error = fmt.Errorf("get node process aggregate count failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+
+	result.AggregateInfo = &AggregateInfo{MetricsCount: int64(count)}
+	result.Metric = &NodeProcessMetric{
+		PIDs:    int64(values["PIDs"]),
+		PIDsMax: int64(values["PIDsMax"]),
+		FDs:     int64(values["FDs"]),
+		FDsMax:  int64(values["FDsMax"]),
+	}
+	return result
+}
+
 func (m *metricCache) GetNodeCPUInfo(param *QueryParam) (*NodeCPUInfo, error) {
 	// get node cpu info from the rawRecordTable
 	if param == nil {
@@ -732,6 +917,53 @@ func (m *metricCache) InsertBECPUResourceMetric(t time.Time, metric *BECPUResour
 	return m.db.InsertBECPUResourceMetric(dbItem)
 }
 
+func (m *metricCache) InsertTerminatingPodsResourceMetric(t time.Time, metric *TerminatingPodsResourceMetric) error {
+	dbItem := &terminatingPodsResourceMetric{
+		CPUUsedCores:    float64(metric.CPUUsed.MilliValue()) / 1000,
+		MemoryUsedBytes: float64(metric.MemoryUsed.Value()),
+		Timestamp:       t,
+	}
+	return m.db.InsertTerminatingPodsResourceMetric(dbItem)
+}
+
+func (m *metricCache) InsertResctrlMemBandwidthMetric(t time.Time, metric *ResctrlMemBandwidthMetric) error {
+	dbItems := make([]resctrlMemBandwidthMetric, 0, len(metric.SocketBandwidth))
+	for socketID, bandwidth := range metric.SocketBandwidth {
+		dbItems = append(dbItems, resctrlMemBandwidthMetric{
+			GroupName: metric.Group,
+			SocketID:  socketID,
+			Bandwidth: bandwidth,
+			Timestamp: t,
+		})
+	}
+	if len(dbItems) <= 0 {
+		return nil
+	}
+	return m.db.BatchInsertResctrlMemBandwidthMetric(dbItems)
+}
+
+func (m *metricCache) InsertContainerProcessMetric(t time.Time, metric *ContainerProcessMetric) error {
+	dbItem := &containerProcessMetric{
+		PodUID:      metric.PodUID,
+		ContainerID: metric.ContainerID,
+		PIDs:        float64(metric.PIDs),
+		FDs:         float64(metric.FDs),
+		Timestamp:   t,
+	}
+	return m.db.InsertContainerProcessMetric(dbItem)
+}
+
+func (m *metricCache) InsertNodeProcessMetric(t time.Time, metric *NodeProcessMetric) error {
+	dbItem := &nodeProcessMetric{
+		PIDs:      float64(metric.PIDs),
+		PIDsMax:   float64(metric.PIDsMax),
+		FDs:       float64(metric.FDs),
+		FDsMax:    float64(metric.FDsMax),
+		Timestamp: t,
+	}
+	return m.db.InsertNodeProcessMetric(dbItem)
+}
+
 func (m *metricCache) InsertNodeCPUInfo(info *NodeCPUInfo) error {
 	infoBytes, err := json.Marshal(info)
 	if err != nil {
@@ -819,7 +1051,7 @@ func (m *metricCache) aggregateGPUUsages(gpuResourceMetricsByTime [][]gpuResourc
 func (m *metricCache) recycleDB() {
 	now := time.Now()
 	oldTime := time.Unix(0, 0)
-	expiredTime := now.Add(-time.Duration(m.config.MetricExpireSeconds) * time.Second)
+	expiredTime := now.Add(-profile.Seconds(func(p *profile.Preset) int { return p.MetricExpireSeconds }, m.config.MetricExpireSeconds))
 	if err := m.db.DeletePodResourceMetric(&oldTime, &expiredTime); err != nil {
 		klog.Warningf("DeletePodResourceMetric failed during recycle, error %v", err)
 	}
@@ -832,6 +1064,18 @@ func (m *metricCache) recycleDB() {
 	if err := m.db.DeleteBECPUResourceMetric(&oldTime, &expiredTime); err != nil {
 		klog.Warningf("DeleteBECPUResourceMetric failed during recycle, error %v", err)
 	}
+	if err := m.db.DeleteTerminatingPodsResourceMetric(&oldTime, &expiredTime); err != nil {
+		klog.Warningf("DeleteTerminatingPodsResourceMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeleteResctrlMemBandwidthMetric(&oldTime, &expiredTime); err != nil {
+		klog.Warningf("DeleteResctrlMemBandwidthMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeleteContainerProcessMetric(&oldTime, &expiredTime); err != nil {
+		klog.Warningf("DeleteContainerProcessMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeleteNodeProcessMetric(&oldTime, &expiredTime); err != nil {
+		klog.Warningf("DeleteNodeProcessMetric failed during recycle, error %v", err)
+	}
 	if err := m.db.DeletePodThrottledMetric(&oldTime, &expiredTime); err != nil {
 		klog.Warningf("DeletePodThrottledMetric failed during recycle, error %v", err)
 	}
@@ -832,6 +832,214 @@ func Test_metricCache_BECPUResourceMetric_CRUD(t *testing.T) {
 	}
 }
 
+func Test_metricCache_TerminatingPodsResourceMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	samples := map[time.Time]TerminatingPodsResourceMetric{
+		now.Add(-time.Second * 120): {
+			CPUUsed:    *resource.NewQuantity(4, resource.DecimalSI),
+			MemoryUsed: *resource.NewQuantity(4<<30, resource.BinarySI),
+		},
+		now.Add(-time.Second * 10): {
+			CPUUsed:    *resource.NewQuantity(2, resource.DecimalSI),
+			MemoryUsed: *resource.NewQuantity(2<<30, resource.BinarySI),
+		},
+		now.Add(-time.Second * 5): {
+			CPUUsed:    *resource.NewMilliQuantity(1000, resource.DecimalSI),
+			MemoryUsed: *resource.NewQuantity(1<<30, resource.BinarySI),
+		},
+	}
+	for ts, sample := range samples {
+		sample := sample
+		if err := m.InsertTerminatingPodsResourceMetric(ts, &sample); err != nil {
+			t.Errorf("InsertTerminatingPodsResourceMetric failed %v", err)
+		}
+	}
+
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeAVG,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetTerminatingPodsResourceMetric(params)
+	if got.Error != nil {
+		t.Errorf("GetTerminatingPodsResourceMetric failed %v", got.Error)
+	}
+	assert.Equal(t, int64(3), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, int64(2333), got.Metric.CPUUsed.MilliValue())
+	assert.Equal(t, int64(7<<30/3), got.Metric.MemoryUsed.Value())
+
+	// delete expire items
+	m.recycleDB()
+
+	gotAfterDel := m.GetTerminatingPodsResourceMetric(params)
+	if gotAfterDel.Error != nil {
+		t.Errorf("GetTerminatingPodsResourceMetric failed %v", gotAfterDel.Error)
+	}
+	assert.Equal(t, int64(2), gotAfterDel.AggregateInfo.MetricsCount)
+	assert.Equal(t, int64(1500), gotAfterDel.Metric.CPUUsed.MilliValue())
+	assert.Equal(t, int64(3<<30/2), gotAfterDel.Metric.MemoryUsed.Value())
+
+	gotIllegal := m.GetTerminatingPodsResourceMetric(&QueryParam{})
+	assert.Error(t, gotIllegal.Error)
+}
+
+func Test_metricCache_ResctrlMemBandwidthMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	group := "BE"
+	samples := map[time.Time][]ResctrlMemBandwidthMetric{
+		now.Add(-time.Second * 120): {
+			{Group: "BE", SocketBandwidth: map[int32]float64{0: 100, 1: 1000}},
+			{Group: "LS", SocketBandwidth: map[int32]float64{0: 5000, 1: 5000}},
+		},
+		now.Add(-time.Second * 10): {
+			{Group: "BE", SocketBandwidth: map[int32]float64{0: 200, 1: 2000}},
+		},
+		now.Add(-time.Second * 5): {
+			{Group: "BE", SocketBandwidth: map[int32]float64{0: 300, 1: 3000}},
+			{Group: "LS", SocketBandwidth: map[int32]float64{0: 5000, 1: 5000}},
+		},
+	}
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	for ts, metrics := range samples {
+		for i := range metrics {
+			err := m.InsertResctrlMemBandwidthMetric(ts, &metrics[i])
+			assert.NoError(t, err)
+		}
+	}
+
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeAVG,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetResctrlMemBandwidthMetric(&group, params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, ResctrlMemBandwidthQueryResult{
+		Metric: &ResctrlMemBandwidthMetric{
+			Group:           "BE",
+			SocketBandwidth: map[int32]float64{0: 200, 1: 2000},
+		},
+		QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 6}},
+	}, got)
+	assert.Equal(t, float64(2200), got.Metric.TotalBandwidth())
+
+	// delete expire items
+	m.recycleDB()
+
+	gotAfterDel := m.GetResctrlMemBandwidthMetric(&group, params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, ResctrlMemBandwidthQueryResult{
+		Metric: &ResctrlMemBandwidthMetric{
+			Group:           "BE",
+			SocketBandwidth: map[int32]float64{0: 250, 1: 2500},
+		},
+		QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 4}},
+	}, gotAfterDel)
+
+	unknownGroup := "unknown"
+	gotUnknown := m.GetResctrlMemBandwidthMetric(&unknownGroup, params)
+	assert.Error(t, gotUnknown.Error)
+}
+
+func Test_metricCache_ProcessMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	containerID := "containerd://c1"
+	containerSamples := map[time.Time][]ContainerProcessMetric{
+		now.Add(-time.Second * 120): {
+			{PodUID: "p1", ContainerID: "containerd://c1", PIDs: 100, FDs: 1000},
+			{PodUID: "p2", ContainerID: "containerd://c2", PIDs: 5, FDs: 50},
+		},
+		now.Add(-time.Second * 10): {
+			{PodUID: "p1", ContainerID: "containerd://c1", PIDs: 200, FDs: 2000},
+		},
+		now.Add(-time.Second * 5): {
+			{PodUID: "p1", ContainerID: "containerd://c1", PIDs: 300, FDs: 3000},
+		},
+	}
+	nodeSamples := map[time.Time]NodeProcessMetric{
+		now.Add(-time.Second * 120): {PIDs: 1000, PIDsMax: 32768, FDs: 10000, FDsMax: 100000},
+		now.Add(-time.Second * 10):  {PIDs: 2000, PIDsMax: 32768, FDs: 20000, FDsMax: 100000},
+		now.Add(-time.Second * 5):   {PIDs: 3000, PIDsMax: 32768, FDs: 30000, FDsMax: 100000},
+	}
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	for ts, metrics := range containerSamples {
+		for i := range metrics {
+			err := m.InsertContainerProcessMetric(ts, &metrics[i])
+			assert.NoError(t, err)
+		}
+	}
+	for ts, metric := range nodeSamples {
+		metric := metric
+		err := m.InsertNodeProcessMetric(ts, &metric)
+		assert.NoError(t, err)
+	}
+
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeAVG,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetContainerProcessMetric(&containerID, params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, ContainerProcessQueryResult{
+		Metric:      &ContainerProcessMetric{PodUID: "p1", ContainerID: containerID, PIDs: 200, FDs: 2000},
+		QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 3}},
+	}, got)
+	gotNode := m.GetNodeProcessMetric(params)
+	assert.NoError(t, gotNode.Error)
+	assert.Equal(t, NodeProcessQueryResult{
+		Metric:      &NodeProcessMetric{PIDs: 2000, PIDsMax: 32768, FDs: 20000, FDsMax: 100000},
+		QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 3}},
+	}, gotNode)
+
+	// delete expire items
+	m.recycleDB()
+
+	gotAfterDel := m.GetContainerProcessMetric(&containerID, params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, ContainerProcessQueryResult{
+		Metric:      &ContainerProcessMetric{PodUID: "p1", ContainerID: containerID, PIDs: 250, FDs: 2500},
+		QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 2}},
+	}, gotAfterDel)
+	gotNodeAfterDel := m.GetNodeProcessMetric(params)
+	assert.NoError(t, gotNodeAfterDel.Error)
+	assert.Equal(t, int64(2500), gotNodeAfterDel.Metric.PIDs)
+
+	unknownContainerID := "containerd://unknown"
+	gotUnknown := m.GetContainerProcessMetric(&unknownContainerID, params)
+	assert.Error(t, gotUnknown.Error)
+	gotIllegal := m.GetNodeProcessMetric(nil)
+	assert.Error(t, gotIllegal.Error)
+}
+
 func Test_metricCache_NodeCPUInfo_CRUD(t *testing.T) {
 	type args struct {
 		config  *Config
@@ -79,6 +79,20 @@ func (mr *MockMetricCacheMockRecorder) GetContainerInterferenceMetric(metricName
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerInterferenceMetric", reflect.TypeOf((*MockMetricCache)(nil).GetContainerInterferenceMetric), metricName, podUID, containerID, param)
 }
 
+// GetContainerProcessMetric mocks base method.
+func (m *MockMetricCache) GetContainerProcessMetric(containerID *string, param *metriccache.QueryParam) metriccache.ContainerProcessQueryResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerProcessMetric", containerID, param)
+	ret0, _ := ret[0].(metriccache.ContainerProcessQueryResult)
+	return ret0
+}
+
+// GetContainerProcessMetric indicates an expected call of GetContainerProcessMetric.
+func (mr *MockMetricCacheMockRecorder) GetContainerProcessMetric(containerID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerProcessMetric", reflect.TypeOf((*MockMetricCache)(nil).GetContainerProcessMetric), containerID, param)
+}
+
 // GetContainerResourceMetric mocks base method.
 func (m *MockMetricCache) GetContainerResourceMetric(containerID *string, param *metriccache.QueryParam) metriccache.ContainerResourceQueryResult {
 	m.ctrl.T.Helper()
@@ -122,6 +136,20 @@ func (mr *MockMetricCacheMockRecorder) GetNodeCPUInfo(param interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodeCPUInfo", reflect.TypeOf((*MockMetricCache)(nil).GetNodeCPUInfo), param)
 }
 
+// GetNodeProcessMetric mocks base method.
+func (m *MockMetricCache) GetNodeProcessMetric(param *metriccache.QueryParam) metriccache.NodeProcessQueryResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodeProcessMetric", param)
+	ret0, _ := ret[0].(metriccache.NodeProcessQueryResult)
+	return ret0
+}
+
+// GetNodeProcessMetric indicates an expected call of GetNodeProcessMetric.
+func (mr *MockMetricCacheMockRecorder) GetNodeProcessMetric(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodeProcessMetric", reflect.TypeOf((*MockMetricCache)(nil).GetNodeProcessMetric), param)
+}
+
 // GetNodeResourceMetric mocks base method.
 func (m *MockMetricCache) GetNodeResourceMetric(param *metriccache.QueryParam) metriccache.NodeResourceQueryResult {
 	m.ctrl.T.Helper()
@@ -178,6 +206,34 @@ func (mr *MockMetricCacheMockRecorder) GetPodThrottledMetric(podUID, param inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodThrottledMetric", reflect.TypeOf((*MockMetricCache)(nil).GetPodThrottledMetric), podUID, param)
 }
 
+// GetResctrlMemBandwidthMetric mocks base method.
+func (m *MockMetricCache) GetResctrlMemBandwidthMetric(group *string, param *metriccache.QueryParam) metriccache.ResctrlMemBandwidthQueryResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResctrlMemBandwidthMetric", group, param)
+	ret0, _ := ret[0].(metriccache.ResctrlMemBandwidthQueryResult)
+	return ret0
+}
+
+// GetResctrlMemBandwidthMetric indicates an expected call of GetResctrlMemBandwidthMetric.
+func (mr *MockMetricCacheMockRecorder) GetResctrlMemBandwidthMetric(group, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResctrlMemBandwidthMetric", reflect.TypeOf((*MockMetricCache)(nil).GetResctrlMemBandwidthMetric), group, param)
+}
+
+// GetTerminatingPodsResourceMetric mocks base method.
+func (m *MockMetricCache) GetTerminatingPodsResourceMetric(param *metriccache.QueryParam) metriccache.TerminatingPodsResourceQueryResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTerminatingPodsResourceMetric", param)
+	ret0, _ := ret[0].(metriccache.TerminatingPodsResourceQueryResult)
+	return ret0
+}
+
+// GetTerminatingPodsResourceMetric indicates an expected call of GetTerminatingPodsResourceMetric.
+func (mr *MockMetricCacheMockRecorder) GetTerminatingPodsResourceMetric(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTerminatingPodsResourceMetric", reflect.TypeOf((*MockMetricCache)(nil).GetTerminatingPodsResourceMetric), param)
+}
+
 // InsertBECPUResourceMetric mocks base method.
 func (m *MockMetricCache) InsertBECPUResourceMetric(t time.Time, metric *metriccache.BECPUResourceMetric) error {
 	m.ctrl.T.Helper()
@@ -206,6 +262,20 @@ func (mr *MockMetricCacheMockRecorder) InsertContainerInterferenceMetrics(t, met
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertContainerInterferenceMetrics", reflect.TypeOf((*MockMetricCache)(nil).InsertContainerInterferenceMetrics), t, metric)
 }
 
+// InsertContainerProcessMetric mocks base method.
+func (m *MockMetricCache) InsertContainerProcessMetric(t time.Time, metric *metriccache.ContainerProcessMetric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertContainerProcessMetric", t, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertContainerProcessMetric indicates an expected call of InsertContainerProcessMetric.
+func (mr *MockMetricCacheMockRecorder) InsertContainerProcessMetric(t, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertContainerProcessMetric", reflect.TypeOf((*MockMetricCache)(nil).InsertContainerProcessMetric), t, metric)
+}
+
 // InsertContainerResourceMetric mocks base method.
 func (m *MockMetricCache) InsertContainerResourceMetric(t time.Time, containerResUsed *metriccache.ContainerResourceMetric) error {
 	m.ctrl.T.Helper()
@@ -248,6 +318,20 @@ func (mr *MockMetricCacheMockRecorder) InsertNodeCPUInfo(info interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNodeCPUInfo", reflect.TypeOf((*MockMetricCache)(nil).InsertNodeCPUInfo), info)
 }
 
+// InsertNodeProcessMetric mocks base method.
+func (m *MockMetricCache) InsertNodeProcessMetric(t time.Time, metric *metriccache.NodeProcessMetric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNodeProcessMetric", t, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertNodeProcessMetric indicates an expected call of InsertNodeProcessMetric.
+func (mr *MockMetricCacheMockRecorder) InsertNodeProcessMetric(t, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNodeProcessMetric", reflect.TypeOf((*MockMetricCache)(nil).InsertNodeProcessMetric), t, metric)
+}
+
 // InsertNodeResourceMetric mocks base method.
 func (m *MockMetricCache) InsertNodeResourceMetric(t time.Time, nodeResUsed *metriccache.NodeResourceMetric) error {
 	m.ctrl.T.Helper()
@@ -304,6 +388,34 @@ func (mr *MockMetricCacheMockRecorder) InsertPodThrottledMetrics(t, metric inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPodThrottledMetrics", reflect.TypeOf((*MockMetricCache)(nil).InsertPodThrottledMetrics), t, metric)
 }
 
+// InsertResctrlMemBandwidthMetric mocks base method.
+func (m *MockMetricCache) InsertResctrlMemBandwidthMetric(t time.Time, metric *metriccache.ResctrlMemBandwidthMetric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertResctrlMemBandwidthMetric", t, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertResctrlMemBandwidthMetric indicates an expected call of InsertResctrlMemBandwidthMetric.
+func (mr *MockMetricCacheMockRecorder) InsertResctrlMemBandwidthMetric(t, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertResctrlMemBandwidthMetric", reflect.TypeOf((*MockMetricCache)(nil).InsertResctrlMemBandwidthMetric), t, metric)
+}
+
+// InsertTerminatingPodsResourceMetric mocks base method.
+func (m *MockMetricCache) InsertTerminatingPodsResourceMetric(t time.Time, metric *metriccache.TerminatingPodsResourceMetric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTerminatingPodsResourceMetric", t, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertTerminatingPodsResourceMetric indicates an expected call of InsertTerminatingPodsResourceMetric.
+func (mr *MockMetricCacheMockRecorder) InsertTerminatingPodsResourceMetric(t, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTerminatingPodsResourceMetric", reflect.TypeOf((*MockMetricCache)(nil).InsertTerminatingPodsResourceMetric), t, metric)
+}
+
 // Run mocks base method.
 func (m *MockMetricCache) Run(stopCh <-chan struct{}) error {
 	m.ctrl.T.Helper()
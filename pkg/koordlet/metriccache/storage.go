@@ -40,9 +40,12 @@ func newStorage(dsn string) (*storage, error) {
 	}
 
 	db.AutoMigrate(&nodeResourceMetric{}, &podResourceMetric{}, &containerResourceMetric{}, &beCPUResourceMetric{})
+	db.AutoMigrate(&terminatingPodsResourceMetric{})
 	db.AutoMigrate(&rawRecord{})
 	db.AutoMigrate(&podThrottledMetric{}, &containerThrottledMetric{})
 	db.AutoMigrate(&containerCPIMetric{}, &containerPSIMetric{}, &podPSIMetric{})
+	db.AutoMigrate(&resctrlMemBandwidthMetric{})
+	db.AutoMigrate(&containerProcessMetric{}, &nodeProcessMetric{})
 
 	database, err := db.DB()
 	if err != nil {
@@ -81,6 +84,10 @@ func (s *storage) InsertBECPUResourceMetric(b *beCPUResourceMetric) error {
 	return s.db.Create(b).Error
 }
 
+func (s *storage) InsertTerminatingPodsResourceMetric(m *terminatingPodsResourceMetric) error {
+	return s.db.Create(m).Error
+}
+
 // InsertRawRecord inserts a raw record into the db
 func (s *storage) InsertRawRecord(record *rawRecord) error {
 	return s.db.Clauses(clause.OnConflict{
@@ -100,6 +107,18 @@ func (s *storage) InsertContainerCPIMetric(m *containerCPIMetric) error {
 	return s.db.Create(m).Error
 }
 
+func (s *storage) BatchInsertResctrlMemBandwidthMetric(m []resctrlMemBandwidthMetric) error {
+	return s.db.Create(&m).Error
+}
+
+func (s *storage) InsertContainerProcessMetric(m *containerProcessMetric) error {
+	return s.db.Create(m).Error
+}
+
+func (s *storage) InsertNodeProcessMetric(m *nodeProcessMetric) error {
+	return s.db.Create(m).Error
+}
+
 func (s *storage) InsertContainerPSIMetric(m *containerPSIMetric) error {
 	return s.db.Create(m).Error
 }
@@ -134,6 +153,30 @@ func (s *storage) GetBECPUResourceMetric(start, end *time.Time) ([]beCPUResource
 	return metrics, err
 }
 
+func (s *storage) GetTerminatingPodsResourceMetric(start, end *time.Time) ([]terminatingPodsResourceMetric, error) {
+	var metrics []terminatingPodsResourceMetric
+	err := s.db.Where("timestamp BETWEEN ? AND ?", start, end).Find(&metrics).Error
+	return metrics, err
+}
+
+func (s *storage) GetResctrlMemBandwidthMetric(group *string, start, end *time.Time) ([]resctrlMemBandwidthMetric, error) {
+	var metrics []resctrlMemBandwidthMetric
+	err := s.db.Where("group_name = ? AND timestamp BETWEEN ? AND ? order by timestamp", group, start, end).Find(&metrics).Error
+	return metrics, err
+}
+
+func (s *storage) GetContainerProcessMetric(containerID *string, start, end *time.Time) ([]containerProcessMetric, error) {
+	var metrics []containerProcessMetric
+	err := s.db.Where("container_id = ? AND timestamp BETWEEN ? AND ?", containerID, start, end).Find(&metrics).Error
+	return metrics, err
+}
+
+func (s *storage) GetNodeProcessMetric(start, end *time.Time) ([]nodeProcessMetric, error) {
+	var metrics []nodeProcessMetric
+	err := s.db.Where("timestamp BETWEEN ? AND ?", start, end).Find(&metrics).Error
+	return metrics, err
+}
+
 func (s *storage) GetRawRecord(recordName string) (*rawRecord, error) {
 	record := &rawRecord{}
 	err := s.db.Where("record_type = ?", recordName).First(&record).Error
@@ -192,6 +235,22 @@ func (s *storage) DeleteBECPUResourceMetric(start, end *time.Time) error {
 	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&beCPUResourceMetric{}).Error
 }
 
+func (s *storage) DeleteTerminatingPodsResourceMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&terminatingPodsResourceMetric{}).Error
+}
+
+func (s *storage) DeleteResctrlMemBandwidthMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&resctrlMemBandwidthMetric{}).Error
+}
+
+func (s *storage) DeleteContainerProcessMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&containerProcessMetric{}).Error
+}
+
+func (s *storage) DeleteNodeProcessMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&nodeProcessMetric{}).Error
+}
+
 func (s *storage) DeletePodThrottledMetric(start, end *time.Time) error {
 	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&podThrottledMetric{}).Error
 }
@@ -236,6 +295,24 @@ func (s *storage) CountBECPUResourceMetric() (int64, error) {
 	return count, err
 }
 
+func (s *storage) CountResctrlMemBandwidthMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&resctrlMemBandwidthMetric{}).Count(&count).Error
+	return count, err
+}
+
+func (s *storage) CountContainerProcessMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&containerProcessMetric{}).Count(&count).Error
+	return count, err
+}
+
+func (s *storage) CountNodeProcessMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&nodeProcessMetric{}).Count(&count).Error
+	return count, err
+}
+
 func (s *storage) CountPodThrottledMetric() (int64, error) {
 	count := int64(0)
 	err := s.db.Model(&podThrottledMetric{}).Count(&count).Error
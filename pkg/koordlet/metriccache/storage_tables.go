@@ -109,6 +109,39 @@ type beCPUResourceMetric struct {
 	Timestamp       time.Time
 }
 
+type terminatingPodsResourceMetric struct {
+	ID              uint64 `gorm:"primarykey"`
+	CPUUsedCores    float64
+	MemoryUsedBytes float64
+	Timestamp       time.Time
+}
+
+type resctrlMemBandwidthMetric struct {
+	ID        uint64 `gorm:"primarykey"`
+	GroupName string `gorm:"index:idx_resctrl_mem_bandwidth_group"`
+	SocketID  int32
+	Bandwidth float64
+	Timestamp time.Time
+}
+
+type containerProcessMetric struct {
+	ID          uint64 `gorm:"primarykey"`
+	PodUID      string `gorm:"index:idx_container_process_poduid"`
+	ContainerID string `gorm:"index:idx_container_process_containerid"`
+	PIDs        float64
+	FDs         float64
+	Timestamp   time.Time
+}
+
+type nodeProcessMetric struct {
+	ID        uint64 `gorm:"primarykey"`
+	PIDs      float64
+	PIDsMax   float64
+	FDs       float64
+	FDsMax    float64
+	Timestamp time.Time
+}
+
 type containerCPIMetric struct {
 	ID           uint64 `gorm:"primarykey"`
 	PodUID       string `gorm:"index:idx_container_cpi_poduid"`
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	APIWriteKindKey = "kind"
+)
+
+var (
+	APIWriterQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "api_writer_queue_depth",
+		Help:      "Number of the pending status updates in the api writer",
+	}, []string{NodeKey, APIWriteKindKey})
+
+	APIWriterCoalesced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "api_writer_coalesced",
+		Help:      "Number of the status updates coalesced by a newer update of the same object",
+	}, []string{NodeKey, APIWriteKindKey})
+
+	APIWriterDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "api_writer_dropped",
+		Help:      "Number of the status updates dropped after the retries are exhausted",
+	}, []string{NodeKey, APIWriteKindKey})
+
+	APIWriterCollectors = []prometheus.Collector{
+		APIWriterQueueDepth,
+		APIWriterCoalesced,
+		APIWriterDropped,
+	}
+)
+
+func RecordAPIWriterQueueDepth(kind string, depth int) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[APIWriteKindKey] = kind
+	APIWriterQueueDepth.With(labels).Set(float64(depth))
+}
+
+func RecordAPIWriterCoalesced(kind string) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[APIWriteKindKey] = kind
+	APIWriterCoalesced.With(labels).Inc()
+}
+
+func RecordAPIWriterDropped(kind string) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[APIWriteKindKey] = kind
+	APIWriterDropped.With(labels).Inc()
+}
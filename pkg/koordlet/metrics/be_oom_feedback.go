@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	OOMKillSourceCgroup = "cgroup"
+	OOMKillSourceKernel = "kernel"
+)
+
+var (
+	BEOOMKills = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_oom_kills",
+		Help:      "Number of the OOM kills of the BE pods observed by koordlet, by the cgroup memory events or the kernel log",
+	}, []string{NodeKey, OOMKillSourceKey})
+
+	BEMemorySafetyMarginRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_memory_safety_margin_ratio",
+		Help:      "Ratio of the node memory capacity added to the node memory usage reported in the NodeMetric for the BE OOM kills, decaying over time",
+	}, []string{NodeKey})
+
+	BEMemorySafetyMarginBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_memory_safety_margin_bytes",
+		Help:      "Bytes added to the node memory usage reported in the NodeMetric for the BE OOM kills",
+	}, []string{NodeKey})
+
+	BEOOMFeedbackCollectors = []prometheus.Collector{
+		BEOOMKills,
+		BEMemorySafetyMarginRatio,
+		BEMemorySafetyMarginBytes,
+	}
+)
+
+func RecordBEOOMKills(source string, count int64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[OOMKillSourceKey] = source
+	BEOOMKills.With(labels).Add(float64(count))
+}
+
+func RecordBEMemorySafetyMargin(ratio float64, bytes float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	BEMemorySafetyMarginRatio.With(labels).Set(ratio)
+	BEMemorySafetyMarginBytes.With(labels).Set(bytes)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	DiskReadBytesPerSecond = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "disk_read_bytes_per_second",
+		Help:      "Read throughput of the disk under the mount point collected by koordlet",
+	}, []string{NodeKey, MountPointKey, DeviceKey})
+
+	DiskWriteBytesPerSecond = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "disk_write_bytes_per_second",
+		Help:      "Write throughput of the disk under the mount point collected by koordlet",
+	}, []string{NodeKey, MountPointKey, DeviceKey})
+
+	DiskReadIOPS = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "disk_read_iops",
+		Help:      "Completed reads per second of the disk under the mount point collected by koordlet",
+	}, []string{NodeKey, MountPointKey, DeviceKey})
+
+	DiskWriteIOPS = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "disk_write_iops",
+		Help:      "Completed writes per second of the disk under the mount point collected by koordlet",
+	}, []string{NodeKey, MountPointKey, DeviceKey})
+
+	DiskIOCollectors = []prometheus.Collector{
+		DiskReadBytesPerSecond,
+		DiskWriteBytesPerSecond,
+		DiskReadIOPS,
+		DiskWriteIOPS,
+	}
+)
+
+func ResetDiskIO() {
+	DiskReadBytesPerSecond.Reset()
+	DiskWriteBytesPerSecond.Reset()
+	DiskReadIOPS.Reset()
+	DiskWriteIOPS.Reset()
+}
+
+func RecordDiskIO(mountPoint, device string, readBytes, writeBytes, readIOPS, writeIOPS float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[MountPointKey] = mountPoint
+	labels[DeviceKey] = device
+	DiskReadBytesPerSecond.With(labels).Set(readBytes)
+	DiskWriteBytesPerSecond.With(labels).Set(writeBytes)
+	DiskReadIOPS.With(labels).Set(readIOPS)
+	DiskWriteIOPS.With(labels).Set(writeIOPS)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	FeaturePaused = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "feature_paused",
+		Help:      "Whether the feature is paused temporarily by the admin commands: 1 for paused, 0 for running",
+	}, []string{NodeKey, FeatureKey})
+
+	FeaturePauseCollectors = []prometheus.Collector{
+		FeaturePaused,
+	}
+)
+
+func RecordFeaturePaused(feature string, paused bool) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[FeatureKey] = feature
+	value := 0.0
+	if paused {
+		value = 1.0
+	}
+	FeaturePaused.With(labels).Set(value)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	MemoryLocalityRepaired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "memory_locality_repaired",
+		Help:      "Number of containers whose memory locality is repaired by koordlet",
+	}, []string{NodeKey, MemoryLocalityRepairModeKey, StatusKey})
+
+	MemoryLocalityCollectors = []prometheus.Collector{
+		MemoryLocalityRepaired,
+	}
+)
+
+func RecordMemoryLocalityRepaired(mode string, succeeded bool) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[MemoryLocalityRepairModeKey] = mode
+	labels[StatusKey] = StatusSucceed
+	if !succeeded {
+		labels[StatusKey] = StatusFailed
+	}
+	MemoryLocalityRepaired.With(labels).Inc()
+}
@@ -31,6 +31,16 @@ func init() {
 	prometheus.MustRegister(PSICollectors...)
 	prometheus.MustRegister(CPUSuppressCollector...)
 	prometheus.MustRegister(CPUBurstCollector...)
+	prometheus.MustRegister(ResourceExecutorCollectors...)
+	prometheus.MustRegister(MemoryLocalityCollectors...)
+	prometheus.MustRegister(APIWriterCollectors...)
+	prometheus.MustRegister(RestartStormCollectors...)
+	prometheus.MustRegister(OrphanArtifactCollectors...)
+	prometheus.MustRegister(ResctrlCollectors...)
+	prometheus.MustRegister(ProcessCollectors...)
+	prometheus.MustRegister(DiskIOCollectors...)
+	prometheus.MustRegister(FeaturePauseCollectors...)
+	prometheus.MustRegister(BEOOMFeedbackCollectors...)
 }
 
 const (
@@ -53,6 +63,23 @@ const (
 	PodNamespace = "pod_namespace"
 
 	ResourceKey = "resource"
+
+	ResourceTypeKey = "resource_type"
+
+	MemoryLocalityRepairModeKey = "mode"
+
+	ArtifactTypeKey = "artifact_type"
+	DryRunKey       = "dry_run"
+
+	ResctrlGroupKey = "resctrl_group"
+	SocketKey       = "socket"
+
+	FeatureKey = "feature"
+
+	OOMKillSourceKey = "source"
+
+	MountPointKey = "mount_point"
+	DeviceKey     = "device"
 )
 
 var (
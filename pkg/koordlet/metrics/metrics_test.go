@@ -144,6 +144,13 @@ func TestCommonCollectors(t *testing.T) {
 		RecordContainerPSI(testingContainer, testingPod, testingPSI)
 		ResetPodPSI()
 		RecordPodPSI(testingPod, testingPSI)
+		RecordResctrlMemBandwidth("BE", "0", 1000)
+		RecordBEMBAFeedbackPercent(50)
+		ResetContainerProcess()
+		RecordContainerProcess(testingContainer, testingPod, 100, 1000)
+		RecordNodeProcess(1000, 10000)
+		RecordBEOOMKills(OOMKillSourceCgroup, 2)
+		RecordBEMemorySafetyMargin(0.05, 1<<30)
 	})
 }
 
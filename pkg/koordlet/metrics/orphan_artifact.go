@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	OrphanArtifactRemoved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "orphan_artifact_removed",
+		Help:      "Number of artifacts removed by koordlet since their pods no longer exist, counted without removal in the dry-run mode",
+	}, []string{NodeKey, ArtifactTypeKey, DryRunKey})
+
+	OrphanArtifactCollectors = []prometheus.Collector{
+		OrphanArtifactRemoved,
+	}
+)
+
+func RecordOrphanArtifactRemoved(artifactType string, dryRun bool) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[ArtifactTypeKey] = artifactType
+	labels[DryRunKey] = strconv.FormatBool(dryRun)
+	OrphanArtifactRemoved.With(labels).Inc()
+}
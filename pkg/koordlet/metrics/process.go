@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	ContainerPIDs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "container_pids",
+		Help:      "Number of the tasks in the container cgroup collected by koordlet",
+	}, []string{NodeKey, ContainerID, ContainerName, PodUID, PodName, PodNamespace})
+
+	ContainerFDs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "container_fds",
+		Help:      "Number of the open file descriptors of the container processes collected by koordlet",
+	}, []string{NodeKey, ContainerID, ContainerName, PodUID, PodName, PodNamespace})
+
+	NodePIDs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_pids",
+		Help:      "Number of the tasks on the node collected by koordlet",
+	}, []string{NodeKey})
+
+	NodeFDs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_fds",
+		Help:      "Number of the allocated file handles on the node collected by koordlet",
+	}, []string{NodeKey})
+
+	ProcessCollectors = []prometheus.Collector{
+		ContainerPIDs,
+		ContainerFDs,
+		NodePIDs,
+		NodeFDs,
+	}
+)
+
+func ResetContainerProcess() {
+	ContainerPIDs.Reset()
+	ContainerFDs.Reset()
+}
+
+func RecordContainerProcess(status *corev1.ContainerStatus, pod *corev1.Pod, pids, fds float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[ContainerID] = status.ContainerID
+	labels[ContainerName] = status.Name
+	labels[PodUID] = string(pod.UID)
+	labels[PodName] = pod.Name
+	labels[PodNamespace] = pod.Namespace
+	ContainerPIDs.With(labels).Set(pids)
+	ContainerFDs.With(labels).Set(fds)
+}
+
+func RecordNodeProcess(pids, fds float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	NodePIDs.With(labels).Set(pids)
+	NodeFDs.With(labels).Set(fds)
+}
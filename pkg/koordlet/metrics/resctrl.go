@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	ResctrlMemBandwidth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "resctrl_mem_bandwidth_bytes",
+		Help:      "Memory bandwidth per second of the resctrl group on the socket measured by the MBM counters",
+	}, []string{NodeKey, ResctrlGroupKey, SocketKey})
+
+	BEMBAFeedbackPercent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "be_mba_feedback_percent",
+		Help:      "MBA percent of the BE resctrl group adjusted by the memory bandwidth feedback",
+	}, []string{NodeKey})
+
+	ResctrlCollectors = []prometheus.Collector{
+		ResctrlMemBandwidth,
+		BEMBAFeedbackPercent,
+	}
+)
+
+func RecordResctrlMemBandwidth(group string, socket string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[ResctrlGroupKey] = group
+	labels[SocketKey] = socket
+	ResctrlMemBandwidth.With(labels).Set(value)
+}
+
+func RecordBEMBAFeedbackPercent(value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	BEMBAFeedbackPercent.With(labels).Set(value)
+}
@@ -29,9 +29,11 @@ import (
 	"k8s.io/component-base/featuregate"
 	"k8s.io/klog/v2"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
@@ -69,7 +71,14 @@ type collectContext struct {
 	lastPodCPUThrottled       sync.Map
 	lastContainerCPUThrottled sync.Map
 
+	// record latest mbm counters of each resctrl group on each L3 for calculate memory bandwidth
+	lastResctrlMbmStat map[string]map[int]mbmRecord
+
 	gpuDeviceManager GPUDeviceManager
+
+	// record latest io stat of each disk by the mount point for calculate disk throughput
+	lastDiskIOStat      map[string]diskIORecord
+	blockDeviceResolver *system.BlockDeviceResolver
 }
 
 func newCollectContext() *collectContext {
@@ -78,7 +87,10 @@ func newCollectContext() *collectContext {
 		lastContainerCPUStat:      sync.Map{},
 		lastPodCPUThrottled:       sync.Map{},
 		lastContainerCPUThrottled: sync.Map{},
+		lastResctrlMbmStat:        map[string]map[int]mbmRecord{},
 		gpuDeviceManager:          initGPUDeviceManager(),
+		lastDiskIOStat:            map[string]diskIORecord{},
+		blockDeviceResolver:       system.NewBlockDeviceResolver(),
 	}
 }
 
@@ -122,7 +134,7 @@ func (c *collector) Run(stopCh <-chan struct{}) error {
 		return nil
 	}
 
-	go wait.Until(func() {
+	go profile.Until(func() {
 		c.collectGPUUsage()
 		c.collectNodeResUsed()
 		// add sync statesInformer cache check before collect pod information
@@ -136,12 +148,16 @@ func (c *collector) Run(stopCh <-chan struct{}) error {
 		c.collectBECPUResourceMetric()
 		c.collectPodResUsed()
 		c.collectPodThrottledInfo()
-	}, time.Duration(c.config.CollectResUsedIntervalSeconds)*time.Second, stopCh)
+	}, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.CollectResUsedIntervalSeconds }, c.config.CollectResUsedIntervalSeconds)
+	}, stopCh)
 
-	go wait.Until(c.collectNodeCPUInfo, time.Duration(c.config.CollectNodeCPUInfoIntervalSeconds)*time.Second, stopCh)
+	go profile.Until(c.collectNodeCPUInfo, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.CollectNodeCPUInfoIntervalSeconds }, c.config.CollectNodeCPUInfoIntervalSeconds)
+	}, stopCh)
 
 	ic := NewPerformanceCollector(c.statesInformer, c.metricCache, c.config.CPICollectorTimeWindowSeconds)
-	util.RunFeature(func() {
+	runCollector(func() {
 		// add sync statesInformer cache check before collect pod information
 		// because collect function will get all pods.
 		if !cache.WaitForCacheSync(stopCh, c.statesInformer.HasSynced) {
@@ -150,9 +166,11 @@ func (c *collector) Run(stopCh <-chan struct{}) error {
 			return
 		}
 		ic.collectContainerCPI()
-	}, []featuregate.Feature{features.CPICollector}, c.config.CPICollectorIntervalSeconds, stopCh)
+	}, features.CPICollector, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.CPICollectorIntervalSeconds }, c.config.CPICollectorIntervalSeconds)
+	}, stopCh)
 
-	util.RunFeature(func() {
+	runCollector(func() {
 		// psi collector support only on anolis os currently
 		if !system.HostSystemInfo.IsAnolisOS {
 			klog.Fatalf("collect psi fail, need anolis os")
@@ -167,7 +185,31 @@ func (c *collector) Run(stopCh <-chan struct{}) error {
 		}
 		ic.collectContainerPSI()
 		ic.collectPodPSI()
-	}, []featuregate.Feature{features.PSICollector}, c.config.PSICollectorIntervalSeconds, stopCh)
+	}, features.PSICollector, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.PSICollectorIntervalSeconds }, c.config.PSICollectorIntervalSeconds)
+	}, stopCh)
+
+	runCollector(c.collectResctrlMemBandwidth, features.MBMCollector, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.MBMCollectorIntervalSeconds }, c.config.MBMCollectorIntervalSeconds)
+	}, stopCh)
+
+	runCollector(func() {
+		// add sync statesInformer cache check before collect pod information
+		// because collect function will get all pods.
+		if !cache.WaitForCacheSync(stopCh, c.statesInformer.HasSynced) {
+			// Koordlet exit because of statesInformer sync failed.
+			klog.Fatalf("timed out waiting for states informer caches to sync")
+			return
+		}
+		c.collectNodeProcess()
+		c.collectContainerProcess()
+	}, features.ProcessCollector, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.ProcessCollectorIntervalSeconds }, c.config.ProcessCollectorIntervalSeconds)
+	}, stopCh)
+
+	runCollector(c.collectDiskIO, features.DiskIOCollector, func() time.Duration {
+		return profile.Seconds(func(p *profile.Preset) int { return p.DiskIOCollectorIntervalSeconds }, c.config.DiskIOCollectorIntervalSeconds)
+	}, stopCh)
 
 	go wait.Until(c.cleanupContext, cleanupInterval, stopCh)
 
@@ -176,6 +218,16 @@ func (c *collector) Run(stopCh <-chan struct{}) error {
 	return nil
 }
 
+// runCollector runs the collector if the feature is enabled. The interval is got again in each period so the switched
+// profile takes effect, and the collector pauses while the interval is zero, e.g. disabled by the edge profile.
+func runCollector(collect func(), feature featuregate.Feature, interval func() time.Duration, stopCh <-chan struct{}) {
+	if !features.DefaultKoordletFeatureGate.Enabled(feature) {
+		klog.Infof("feature %v is disabled, the collector is not started", feature)
+		return
+	}
+	go profile.Until(collect, interval, stopCh)
+}
+
 func (c *collector) collectNodeResUsed() {
 	klog.V(6).Info("collectNodeResUsed start")
 	collectTime := time.Now()
@@ -224,7 +276,11 @@ func (c *collector) collectNodeResUsed() {
 
 func (c *collector) collectPodResUsed() {
 	klog.V(6).Info("start collectPodResUsed")
-	podMetas := c.statesInformer.GetAllPods()
+	// the terminating pods keep using the resources until their cgroups disappear, including the ghost pods removed
+	// from kubelet
+	podMetas := append(c.statesInformer.GetAllPods(), c.statesInformer.GetGhostPods()...)
+	terminatingCPUUsed := resource.NewMilliQuantity(0, resource.DecimalSI)
+	terminatingMemoryUsed := resource.NewQuantity(0, resource.BinarySI)
 	for _, meta := range podMetas {
 		pod := meta.Pod
 		uid := string(pod.UID) // types.UID
@@ -284,9 +340,22 @@ func (c *collector) collectPodResUsed() {
 			klog.Errorf("insert pod %s/%s, uid %s resource metric failed, metric %v, err %v",
 				pod.Namespace, pod.Name, uid, podMetric, err)
 		}
+		if util.IsPodTerminating(pod) && apiext.GetPodQoSClass(pod) != apiext.QoSBE &&
+			util.GetKubeQosClass(pod) != corev1.PodQOSBestEffort {
+			terminatingCPUUsed.Add(podMetric.CPUUsed.CPUUsed)
+			terminatingMemoryUsed.Add(podMetric.MemoryUsed.MemoryWithoutCache)
+		}
 		c.collectContainerResUsed(meta)
 	}
 
+	terminatingMetric := &metriccache.TerminatingPodsResourceMetric{
+		CPUUsed:    *terminatingCPUUsed,
+		MemoryUsed: *terminatingMemoryUsed,
+	}
+	if err := c.metricCache.InsertTerminatingPodsResourceMetric(time.Now(), terminatingMetric); err != nil {
+		klog.Errorf("insert terminating pods resource metric failed, metric %v, err %v", terminatingMetric, err)
+	}
+
 	// update collect time
 	c.state.RefreshTime(podResUsedUpdateTime)
 	klog.Infof("collectPodResUsed finished, pod num %d", len(podMetas))
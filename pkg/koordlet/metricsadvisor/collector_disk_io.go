@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsadvisor
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+var (
+	readDiskStats = system.ReadDiskStats
+)
+
+type diskIORecord struct {
+	device string
+	stat   system.DiskStat
+	ts     time.Time
+}
+
+// collectDiskIO collects the throughput and iops of the filesystem device under each mount point, e.g. the lvm volume
+// rather than the disks it is striped over. The devices are resolved again once the mounts change.
+func (c *collector) collectDiskIO() {
+	klog.V(6).Info("collectDiskIO start")
+	collectTime := time.Now()
+	stats, err := readDiskStats()
+	if err != nil {
+		klog.Warningf("failed to collect the disk stats, err: %v", err)
+		return
+	}
+	// the mount points failed to resolve are not reported, whose series are dropped
+	metrics.ResetDiskIO()
+	lastStats := c.context.lastDiskIOStat
+	c.context.lastDiskIOStat = map[string]diskIORecord{}
+	for _, mountPoint := range c.config.DiskIOCollectMountPoints {
+		resolution, err := c.context.blockDeviceResolver.Resolve(mountPoint)
+		if err != nil {
+			klog.V(4).Infof("failed to resolve the block device of mount point %s, err: %v", mountPoint, err)
+			continue
+		}
+		stat, ok := stats[resolution.Device.DeviceNumber()]
+		if !ok {
+			klog.V(4).Infof("disk stat of device %s under mount point %s not found", resolution.Device.Name, mountPoint)
+			continue
+		}
+		cur := diskIORecord{device: resolution.Device.Name, stat: *stat, ts: collectTime}
+		c.context.lastDiskIOStat[mountPoint] = cur
+
+		last, ok := lastStats[mountPoint]
+		// the counters restart on a remount to another device
+		if !ok || last.device != cur.device || cur.stat.ReadIOs < last.stat.ReadIOs || cur.stat.WriteIOs < last.stat.WriteIOs {
+			klog.V(6).Infof("ignore the first disk stat collection of mount point %s", mountPoint)
+			continue
+		}
+		seconds := cur.ts.Sub(last.ts).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		readBytes := float64(cur.stat.ReadSectors-last.stat.ReadSectors) * system.DiskSectorSize / seconds
+		writeBytes := float64(cur.stat.WriteSectors-last.stat.WriteSectors) * system.DiskSectorSize / seconds
+		readIOPS := float64(cur.stat.ReadIOs-last.stat.ReadIOs) / seconds
+		writeIOPS := float64(cur.stat.WriteIOs-last.stat.WriteIOs) / seconds
+		metrics.RecordDiskIO(mountPoint, cur.device, readBytes, writeBytes, readIOPS, writeIOPS)
+		klog.V(6).Infof("collect disk io of mount point %s on device %s, read %.0f B/s %.0f iops, write %.0f B/s %.0f iops",
+			mountPoint, cur.device, readBytes, readIOPS, writeBytes, writeIOPS)
+	}
+	klog.V(6).Info("collectDiskIO finished")
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsadvisor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_collectDiskIO(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = path.Join(helper.TempDir, "sys")
+	defer func() { system.Conf.SysRootDir = oldSysRootDir }()
+	metrics.Register(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	defer metrics.Register(nil)
+
+	// the kubelet root is on the lvm volume over nvme0n1, and /data is not mounted
+	helper.WriteFileContents("sys/block/nvme0n1/dev", "259:0\n")
+	helper.CreateFile("sys/block/nvme0n1/holders/dm-0")
+	helper.WriteFileContents("sys/block/dm-0/dev", "253:0\n")
+	helper.WriteFileContents("sys/block/dm-0/dm/name", "vg0-lv0\n")
+	helper.CreateFile("sys/block/dm-0/slaves/nvme0n1")
+	helper.WriteFileContents(path.Join("proc", system.ProcMountInfoName),
+		"36 1 253:0 / /var/lib/kubelet rw,relatime shared:1 - ext4 /dev/mapper/vg0-lv0 rw\n")
+
+	diskStats := map[string]*system.DiskStat{}
+	oldReadDiskStats := readDiskStats
+	readDiskStats = func() (map[string]*system.DiskStat, error) {
+		return diskStats, nil
+	}
+	defer func() { readDiskStats = oldReadDiskStats }()
+
+	c := &collector{
+		config:  NewDefaultConfig(),
+		context: newCollectContext(),
+	}
+	c.config.DiskIOCollectMountPoints = []string{"/var/lib/kubelet", "relative"}
+
+	diskStats["253:0"] = &system.DiskStat{ReadIOs: 100, ReadSectors: 1000, WriteIOs: 200, WriteSectors: 4000}
+	diskStats["259:0"] = &system.DiskStat{ReadIOs: 1000, ReadSectors: 10000, WriteIOs: 2000, WriteSectors: 40000}
+	c.collectDiskIO()
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.DiskReadIOPS))
+	last := c.context.lastDiskIOStat["/var/lib/kubelet"]
+	assert.Equal(t, "dm-0", last.device)
+
+	// rewind the last collection by 10s
+	last.ts = last.ts.Add(-10 * time.Second)
+	c.context.lastDiskIOStat["/var/lib/kubelet"] = last
+	diskStats["253:0"] = &system.DiskStat{ReadIOs: 200, ReadSectors: 3000, WriteIOs: 400, WriteSectors: 8000}
+	c.collectDiskIO()
+	labels := map[string]string{metrics.NodeKey: "test-node", metrics.MountPointKey: "/var/lib/kubelet", metrics.DeviceKey: "dm-0"}
+	assert.InDelta(t, 10, testutil.ToFloat64(metrics.DiskReadIOPS.With(labels)), 0.1)
+	assert.InDelta(t, 20, testutil.ToFloat64(metrics.DiskWriteIOPS.With(labels)), 0.1)
+	assert.InDelta(t, 2000*system.DiskSectorSize/10, testutil.ToFloat64(metrics.DiskReadBytesPerSecond.With(labels)), 100)
+	assert.InDelta(t, 4000*system.DiskSectorSize/10, testutil.ToFloat64(metrics.DiskWriteBytesPerSecond.With(labels)), 100)
+
+	// the counters of the remounted device restart
+	last = c.context.lastDiskIOStat["/var/lib/kubelet"]
+	last.ts = last.ts.Add(-10 * time.Second)
+	c.context.lastDiskIOStat["/var/lib/kubelet"] = last
+	diskStats["253:0"] = &system.DiskStat{ReadIOs: 1, ReadSectors: 8, WriteIOs: 1, WriteSectors: 8}
+	c.collectDiskIO()
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.DiskReadIOPS))
+}
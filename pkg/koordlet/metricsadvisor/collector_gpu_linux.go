@@ -19,6 +19,7 @@ package metricsadvisor
 import (
 	"errors"
 	"fmt"
+	"os"
 	"sort"
 	"sync"
 	"time"
@@ -30,7 +31,9 @@ import (
 
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
-	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 type gpuDeviceManager struct {
@@ -39,6 +42,8 @@ type gpuDeviceManager struct {
 	devices          []*device
 	collectTime      time.Time
 	processesMetrics map[uint32][]*rawGPUMetric
+	// processContainers maps the pids of the gpu processes to the ids of the containers they are running in
+	processContainers map[uint32]string
 }
 
 type rawGPUMetric struct {
@@ -46,11 +51,17 @@ type rawGPUMetric struct {
 	MemoryUsed uint64
 }
 
+// nvmlDevice is the subset of the nvml device methods used to collect the process metrics.
+type nvmlDevice interface {
+	GetComputeRunningProcesses() ([]nvml.ProcessInfo, nvml.Return)
+	GetProcessUtilization(LastSeenTimeStamp uint64) ([]nvml.ProcessUtilizationSample, nvml.Return)
+}
+
 type device struct {
 	Minor       int32 // index starting from 0
 	DeviceUUID  string
 	MemoryTotal uint64
-	Device      nvml.Device
+	Device      nvmlDevice
 }
 
 // initGPUDeviceManager will not retry if init fails,
@@ -204,10 +215,13 @@ func (g *gpuDeviceManager) getPodGPUUsage(podParentDir string, cs []corev1.Conta
 	if len(runningContainer) == 0 {
 		return nil, nil
 	}
-	pids, err := util.GetPIDsInPod(podParentDir, cs)
+	pids, err := koordletutil.GetPIDsInPod(podParentDir, cs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pid, error: %v", err)
 	}
+	for i := range runningContainer {
+		pids = g.mergeContainerPIDs(runningContainer[i].ContainerID, pids)
+	}
 	return g.getTotalGPUUsageOfPIDs(pids), nil
 }
 
@@ -216,11 +230,59 @@ func (g *gpuDeviceManager) getContainerGPUUsage(podParentDir string, c *corev1.C
 		klog.V(5).Infof("non-running container %s", c.ContainerID)
 		return nil, nil
 	}
-	currentPIDs, err := util.GetPIDsInContainer(podParentDir, c)
+	currentPIDs, err := koordletutil.GetPIDsInContainer(podParentDir, c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pid, error: %v", err)
 	}
-	return g.getTotalGPUUsageOfPIDs(currentPIDs), nil
+	// the processes in the child cgroups are not listed in the container's procs, so add the ones attributed by
+	// their own cgroups
+	return g.getTotalGPUUsageOfPIDs(g.mergeContainerPIDs(c.ContainerID, currentPIDs)), nil
+}
+
+// mergeContainerPIDs returns the given pids and the gpu processes attributed to the container, without duplicates.
+func (g *gpuDeviceManager) mergeContainerPIDs(containerID string, pids []uint32) []uint32 {
+	_, id, err := util.ParseContainerId(containerID)
+	if err != nil {
+		return pids
+	}
+	g.RLock()
+	defer g.RUnlock()
+	pidSet := make(map[uint32]struct{}, len(pids))
+	for _, pid := range pids {
+		pidSet[pid] = struct{}{}
+	}
+	merged := pids
+	for pid, processContainerID := range g.processContainers {
+		if _, ok := pidSet[pid]; ok || processContainerID != id {
+			continue
+		}
+		merged = append(merged, pid)
+	}
+	return merged
+}
+
+// mapProcessesToContainers attributes the gpu processes to the containers by the cgroup of each process. The processes
+// exited before the lookup, or not running in any container, are skipped.
+func mapProcessesToContainers(pids []uint32) map[uint32]string {
+	processContainers := make(map[uint32]string, len(pids))
+	for _, pid := range pids {
+		cgroupDir, err := system.ReadProcCgroupDir(pid)
+		if err != nil {
+			if os.IsNotExist(err) {
+				klog.V(5).Infof("skip attributing gpu process %v since it has exited", pid)
+			} else {
+				klog.V(4).Infof("failed to read cgroup of gpu process %v, error: %v", pid, err)
+			}
+			continue
+		}
+		containerID, err := koordletutil.ParseContainerIDFromCgroupDir(cgroupDir)
+		if err != nil {
+			klog.V(5).Infof("skip attributing gpu process %v not in any container, cgroup dir %s", pid, cgroupDir)
+			continue
+		}
+		processContainers[pid] = containerID
+	}
+	return processContainers
 }
 
 func (g *gpuDeviceManager) collectGPUUsage() {
@@ -237,29 +299,37 @@ func (g *gpuDeviceManager) collectGPUUsage() {
 			continue
 		}
 
-		// Sort by pid.
-		sort.Slice(processesInfos, func(i, j int) bool {
-			return processesInfos[i].Pid < processesInfos[j].Pid
-		})
-		sort.Slice(processUtilizations, func(i, j int) bool {
-			return processUtilizations[i].Pid < processUtilizations[j].Pid
-		})
+		// Match the utilization by pid, since a short-lived process can exit between the two queries.
+		smUtils := make(map[uint32]uint32, len(processUtilizations))
+		for _, utilization := range processUtilizations {
+			smUtils[utilization.Pid] = utilization.SmUtil
+		}
 
 		klog.V(3).Infof("Found %d processes on device %d\n", len(processesInfos), deviceIndex)
-		for idx, info := range processesInfos {
+		for _, info := range processesInfos {
 			if _, ok := processesGPUUsages[info.Pid]; !ok {
 				// pid not exist.
 				// init processes gpu metric array.
 				processesGPUUsages[info.Pid] = make([]*rawGPUMetric, g.deviceCount)
 			}
 			processesGPUUsages[info.Pid][deviceIndex] = &rawGPUMetric{
-				SMUtil:     processUtilizations[idx].SmUtil,
+				SMUtil:     smUtils[info.Pid],
 				MemoryUsed: info.UsedGpuMemory,
 			}
 		}
 	}
+	pids := make([]uint32, 0, len(processesGPUUsages))
+	for pid := range processesGPUUsages {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i] < pids[j]
+	})
+	processContainers := mapProcessesToContainers(pids)
+
 	g.Lock()
 	g.processesMetrics = processesGPUUsages
+	g.processContainers = processContainers
 	g.collectTime = time.Now()
 	g.Unlock()
 }
@@ -23,6 +23,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -632,3 +634,84 @@ func writeCgroupContent(filePath string, content []byte) error {
 	}
 	return os.WriteFile(filePath, content, 0655)
 }
+
+type fakeNVMLDevice struct {
+	processInfos        []nvml.ProcessInfo
+	processUtilizations []nvml.ProcessUtilizationSample
+}
+
+func (f *fakeNVMLDevice) GetComputeRunningProcesses() ([]nvml.ProcessInfo, nvml.Return) {
+	return f.processInfos, nvml.SUCCESS
+}
+
+func (f *fakeNVMLDevice) GetProcessUtilization(LastSeenTimeStamp uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+	return f.processUtilizations, nvml.SUCCESS
+}
+
+func Test_gpuDeviceManager_collectGPUUsage(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	system.SetupCgroupPathFormatter(system.Systemd)
+	defer system.SetupCgroupPathFormatter(system.Systemd)
+
+	// process 122 runs in the container, 333 runs in a child cgroup of the container, 444 runs out of any container
+	// and 555 has exited
+	containerDir := "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod6553a60b_2b97_442a_b6da_a5704d81dd98.slice/docker-703b1b4e811f56673d68f9531204e5dd4963e734e2929a7056fd5f33fde4abaf.scope"
+	helper.WriteProcSubFileContents("122/cgroup", "4:cpu,cpuacct:"+containerDir+"\n")
+	helper.WriteProcSubFileContents("333/cgroup", "4:cpu,cpuacct:"+containerDir+"/worker\n")
+	helper.WriteProcSubFileContents("444/cgroup", "4:cpu,cpuacct:/user.slice\n")
+
+	g := &gpuDeviceManager{
+		deviceCount: 2,
+		devices: []*device{
+			{
+				Minor: 0, DeviceUUID: "12", MemoryTotal: 14000,
+				Device: &fakeNVMLDevice{
+					processInfos: []nvml.ProcessInfo{
+						{Pid: 333, UsedGpuMemory: 2000},
+						{Pid: 122, UsedGpuMemory: 1500},
+						{Pid: 555, UsedGpuMemory: 500},
+					},
+					// the utilization of 555 is missing since it has exited between the two queries
+					processUtilizations: []nvml.ProcessUtilizationSample{
+						{Pid: 122, SmUtil: 70},
+						{Pid: 333, SmUtil: 20},
+					},
+				},
+			},
+			{
+				Minor: 1, DeviceUUID: "23", MemoryTotal: 24000,
+				Device: &fakeNVMLDevice{
+					processInfos: []nvml.ProcessInfo{
+						{Pid: 444, UsedGpuMemory: 3000},
+					},
+					processUtilizations: []nvml.ProcessUtilizationSample{
+						{Pid: 444, SmUtil: 40},
+					},
+				},
+			},
+		},
+	}
+	g.collectGPUUsage()
+
+	wantProcessesMetrics := map[uint32][]*rawGPUMetric{
+		122: {{SMUtil: 70, MemoryUsed: 1500}, nil},
+		333: {{SMUtil: 20, MemoryUsed: 2000}, nil},
+		444: {nil, {SMUtil: 40, MemoryUsed: 3000}},
+		555: {{SMUtil: 0, MemoryUsed: 500}, nil},
+	}
+	assert.Equal(t, wantProcessesMetrics, g.processesMetrics)
+	wantProcessContainers := map[uint32]string{
+		122: "703b1b4e811f56673d68f9531204e5dd4963e734e2929a7056fd5f33fde4abaf",
+		333: "703b1b4e811f56673d68f9531204e5dd4963e734e2929a7056fd5f33fde4abaf",
+	}
+	assert.Equal(t, wantProcessContainers, g.processContainers)
+
+	// the container procs only list 122, and 333 is attributed by its own cgroup
+	got := g.mergeContainerPIDs("docker://703b1b4e811f56673d68f9531204e5dd4963e734e2929a7056fd5f33fde4abaf", []uint32{122})
+	assert.ElementsMatch(t, []uint32{122, 333}, got)
+	got = g.mergeContainerPIDs("docker://703b1b4e811f56673d68f9531204e5dd4963e734e2929a7056fd5f33fde4acff", []uint32{122})
+	assert.ElementsMatch(t, []uint32{122}, got)
+	got = g.mergeContainerPIDs("invalid", []uint32{122})
+	assert.ElementsMatch(t, []uint32{122}, got)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsadvisor
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	// mbmCounterWidth is the width of the mbm counters read from resctrl.
+	// NOTE: The kernel extends the hardware counters (24 bits on the early platforms) to 64 bits, while the counters
+	// still wrap around and are reset when the group is recreated.
+	mbmCounterWidth = 64
+)
+
+type mbmRecord struct {
+	totalBytes uint64
+	ts         time.Time
+}
+
+// mbmCounterDelta returns the increment of a mbm counter with the given width from prev to cur.
+// A counter less than the previous one either overflows or is reset, e.g. the resctrl group is recreated. It is
+// regarded as an overflow only if the wrapped increment is less than half of the counter range, otherwise the counter
+// is reset and the increment is unknown.
+func mbmCounterDelta(prev, cur uint64, width uint) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	mask := uint64(math.MaxUint64)
+	if width < 64 {
+		mask = 1<<width - 1
+	}
+	delta := (cur - prev) & mask
+	if delta > mask>>1 {
+		return 0, false
+	}
+	return delta, true
+}
+
+// getL3SocketMap returns the socket id of each L3 cache, which the mbm counters are reported with.
+func getL3SocketMap(nodeCPUInfo *metriccache.NodeCPUInfo) map[int]int32 {
+	l3ToSocket := map[int]int32{}
+	for _, p := range nodeCPUInfo.ProcessorInfos {
+		l3ToSocket[int(p.L3)] = p.SocketID
+	}
+	return l3ToSocket
+}
+
+// listResctrlGroups returns the resctrl groups under the resctrl root, excluding the root group.
+func listResctrlGroups() ([]string, error) {
+	entries, err := os.ReadDir(system.GetResctrlSubsystemDirPath())
+	if err != nil {
+		return nil, err
+	}
+	var groups []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		switch entry.Name() {
+		case system.RdtInfoDir, system.ResctrlMonDataDir, "mon_groups":
+			continue
+		}
+		groups = append(groups, entry.Name())
+	}
+	return groups, nil
+}
+
+func (c *collector) collectResctrlMemBandwidth() {
+	klog.V(6).Info("collectResctrlMemBandwidth start")
+	nodeCPUInfo, err := c.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
+	if err != nil || nodeCPUInfo == nil {
+		klog.Warningf("failed to get nodeCPUInfo for collecting the memory bandwidth, err: %v", err)
+		return
+	}
+	l3ToSocket := getL3SocketMap(nodeCPUInfo)
+
+	groups, err := listResctrlGroups()
+	if err != nil {
+		klog.Warningf("failed to list resctrl groups, err: %v", err)
+		return
+	}
+
+	collectTime := time.Now()
+	lastStat := c.context.lastResctrlMbmStat
+	// rebuild the records so the ones of the removed groups are dropped
+	c.context.lastResctrlMbmStat = make(map[string]map[int]mbmRecord, len(groups))
+	for _, group := range groups {
+		counters, err := system.ReadResctrlMbmTotalBytes(group)
+		if os.IsNotExist(err) {
+			klog.V(5).Infof("skip collecting memory bandwidth for resctrl group %s, mbm is not supported", group)
+			continue
+		} else if err != nil {
+			klog.Warningf("failed to read mbm counters for resctrl group %s, err: %v", group, err)
+			continue
+		}
+		metric := c.calculateResctrlMemBandwidth(group, lastStat[group], counters, collectTime, l3ToSocket)
+		if metric == nil {
+			continue
+		}
+		if err = c.metricCache.InsertResctrlMemBandwidthMetric(collectTime, metric); err != nil {
+			klog.Warningf("failed to insert memory bandwidth of resctrl group %s, err: %v", group, err)
+		}
+		for socketID, bandwidth := range metric.SocketBandwidth {
+			metrics.RecordResctrlMemBandwidth(group, strconv.Itoa(int(socketID)), bandwidth)
+		}
+	}
+	klog.V(6).Info("collectResctrlMemBandwidth finished")
+}
+
+// calculateResctrlMemBandwidth records the current mbm counters of the group and returns the memory bandwidth since
+// the last records aggregated by socket. It returns nil if no bandwidth can be calculated, e.g. at the first time.
+func (c *collector) calculateResctrlMemBandwidth(group string, lastRecords map[int]mbmRecord, counters map[int]uint64,
+	collectTime time.Time, l3ToSocket map[int]int32) *metriccache.ResctrlMemBandwidthMetric {
+	records := make(map[int]mbmRecord, len(counters))
+	socketBandwidth := map[int32]float64{}
+	for l3, totalBytes := range counters {
+		records[l3] = mbmRecord{totalBytes: totalBytes, ts: collectTime}
+		last, ok := lastRecords[l3]
+		if !ok {
+			continue
+		}
+		seconds := collectTime.Sub(last.ts).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		delta, ok := mbmCounterDelta(last.totalBytes, totalBytes, mbmCounterWidth)
+		if !ok {
+			klog.V(4).Infof("mbm counter of resctrl group %s on L3 %d is reset, last %d, current %d",
+				group, l3, last.totalBytes, totalBytes)
+			continue
+		}
+		socketID, ok := l3ToSocket[l3]
+		if !ok {
+			klog.V(5).Infof("failed to find the socket of L3 %d for resctrl group %s", l3, group)
+			continue
+		}
+		socketBandwidth[socketID] += float64(delta) / seconds
+	}
+	c.context.lastResctrlMbmStat[group] = records
+
+	if len(socketBandwidth) <= 0 {
+		return nil
+	}
+	return &metriccache.ResctrlMemBandwidthMetric{
+		Group:           group,
+		SocketBandwidth: socketBandwidth,
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsadvisor
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_mbmCounterDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev      uint64
+		cur       uint64
+		width     uint
+		want      uint64
+		wantValid bool
+	}{
+		{
+			name:      "counter increases",
+			prev:      1000,
+			cur:       3000,
+			width:     64,
+			want:      2000,
+			wantValid: true,
+		},
+		{
+			name:      "counter overflows on 24 bits",
+			prev:      1<<24 - 100,
+			cur:       50,
+			width:     24,
+			want:      150,
+			wantValid: true,
+		},
+		{
+			name:      "counter overflows on 64 bits",
+			prev:      1<<64 - 1000,
+			cur:       1000,
+			width:     64,
+			want:      2000,
+			wantValid: true,
+		},
+		{
+			name:      "counter is reset on 24 bits",
+			prev:      1 << 23,
+			cur:       100,
+			width:     24,
+			want:      0,
+			wantValid: false,
+		},
+		{
+			name:      "counter is reset on 64 bits",
+			prev:      1 << 40,
+			cur:       100,
+			width:     64,
+			want:      0,
+			wantValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotValid := mbmCounterDelta(tt.prev, tt.cur, tt.width)
+			assert.Equal(t, tt.wantValid, gotValid)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_collector_calculateResctrlMemBandwidth(t *testing.T) {
+	// L3 0 and 1 are on socket 0, L3 2 is on socket 1
+	l3ToSocket := map[int]int32{0: 0, 1: 0, 2: 1}
+	now := time.Now()
+	steps := []struct {
+		counters map[int]uint64
+		want     *metriccache.ResctrlMemBandwidthMetric
+	}{
+		{
+			// no bandwidth for the first records
+			counters: map[int]uint64{0: 1000, 1: 2000, 2: 1<<64 - 500},
+			want:     nil,
+		},
+		{
+			// the counter of L3 2 overflows
+			counters: map[int]uint64{0: 3000, 1: 5000, 2: 1500},
+			want: &metriccache.ResctrlMemBandwidthMetric{
+				Group:           "BE",
+				SocketBandwidth: map[int32]float64{0: 500, 1: 200},
+			},
+		},
+		{
+			// the counter of L3 0 is reset, and the counter of L3 1 is unavailable
+			counters: map[int]uint64{0: 100, 2: 11500},
+			want: &metriccache.ResctrlMemBandwidthMetric{
+				Group:           "BE",
+				SocketBandwidth: map[int32]float64{1: 1000},
+			},
+		},
+		{
+			// the L3 1 is recorded again but has no bandwidth
+			counters: map[int]uint64{0: 10100, 1: 9000, 2: 11500},
+			want: &metriccache.ResctrlMemBandwidthMetric{
+				Group:           "BE",
+				SocketBandwidth: map[int32]float64{0: 1000, 1: 0},
+			},
+		},
+	}
+	c := &collector{context: newCollectContext()}
+	for i, step := range steps {
+		collectTime := now.Add(time.Duration(i*10) * time.Second)
+		got := c.calculateResctrlMemBandwidth("BE", c.context.lastResctrlMbmStat["BE"], step.counters, collectTime, l3ToSocket)
+		assert.Equal(t, step.want, got, "step %d", i)
+	}
+}
+
+func Test_collectResctrlMemBandwidth(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysFSRootDir := system.Conf.SysFSRootDir
+	system.Conf.SysFSRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() { system.Conf.SysFSRootDir = oldSysFSRootDir }()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctrl)
+	mockMetricCache.EXPECT().GetNodeCPUInfo(&metriccache.QueryParam{}).Return(&metriccache.NodeCPUInfo{
+		ProcessorInfos: []koordletutil.ProcessorInfo{
+			{CPUID: 0, SocketID: 0, L3: 0},
+			{CPUID: 1, SocketID: 1, L3: 1},
+		},
+	}, nil).Times(2)
+
+	writeCounters := func(group string, counters map[int]uint64) {
+		for l3, value := range counters {
+			helper.WriteFileContents(filepath.Join("sys", system.ResctrlDir, group, system.ResctrlMonDataDir,
+				system.ResctrlMonL3DirPrefix+"0"+strconv.Itoa(l3), system.ResctrlMbmTotalBytesName), strconv.FormatUint(value, 10))
+		}
+	}
+	// the info dir and the group without mbm should be skipped
+	helper.MkDirAll(filepath.Join("sys", system.ResctrlDir, system.RdtInfoDir))
+	helper.MkDirAll(filepath.Join("sys", system.ResctrlDir, "LSR"))
+	writeCounters("BE", map[int]uint64{0: 1000, 1: 2000})
+	writeCounters("LS", map[int]uint64{0: 1000, 1: 2000})
+
+	c := &collector{context: newCollectContext(), metricCache: mockMetricCache}
+	c.collectResctrlMemBandwidth()
+	assert.Len(t, c.context.lastResctrlMbmStat, 2)
+
+	// make the last records one second earlier
+	for _, records := range c.context.lastResctrlMbmStat {
+		for l3, record := range records {
+			record.ts = record.ts.Add(-time.Second)
+			records[l3] = record
+		}
+	}
+	writeCounters("BE", map[int]uint64{0: 1001000, 1: 2002000})
+	writeCounters("LS", map[int]uint64{0: 3001000, 1: 4002000})
+	var inserted []*metriccache.ResctrlMemBandwidthMetric
+	mockMetricCache.EXPECT().InsertResctrlMemBandwidthMetric(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ time.Time, metric *metriccache.ResctrlMemBandwidthMetric) error {
+			inserted = append(inserted, metric)
+			return nil
+		}).Times(2)
+	c.collectResctrlMemBandwidth()
+
+	expected := map[string]map[int32]float64{
+		"BE": {0: 1000000, 1: 2000000},
+		"LS": {0: 3000000, 1: 4000000},
+	}
+	assert.Len(t, inserted, 2)
+	for _, metric := range inserted {
+		for socketID, bandwidth := range expected[metric.Group] {
+			assert.InEpsilon(t, bandwidth, metric.SocketBandwidth[socketID], 0.1, "group %s socket %d", metric.Group, socketID)
+		}
+	}
+}
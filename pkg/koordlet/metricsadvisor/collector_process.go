@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsadvisor
+
+import (
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+var (
+	countProcessFDs = system.CountProcessFDs
+)
+
+type processContainer struct {
+	pod    *corev1.Pod
+	status *corev1.ContainerStatus
+	dir    string
+}
+
+func (c *collector) collectNodeProcess() {
+	klog.V(6).Info("collectNodeProcess start")
+	collectTime := time.Now()
+	pids, err := system.ReadNodeTaskCount()
+	if err != nil {
+		klog.Warningf("failed to collect the node tasks, err: %v", err)
+		return
+	}
+	pidsMax, err := system.ReadPIDMax()
+	if err != nil {
+		klog.Warningf("failed to collect the node pid_max, err: %v", err)
+		return
+	}
+	fds, fdsMax, err := system.ReadFileNr()
+	if err != nil {
+		klog.Warningf("failed to collect the node file-nr, err: %v", err)
+		return
+	}
+	nodeMetric := &metriccache.NodeProcessMetric{
+		PIDs:    pids,
+		PIDsMax: pidsMax,
+		FDs:     fds,
+		FDsMax:  fdsMax,
+	}
+	if err = c.metricCache.InsertNodeProcessMetric(collectTime, nodeMetric); err != nil {
+		klog.Errorf("insert node process metric failed, metric %v, err %v", nodeMetric, err)
+	}
+	metrics.RecordNodeProcess(float64(pids), float64(fds))
+	klog.V(6).Infof("collectNodeProcess finished, metric %+v", nodeMetric)
+}
+
+func (c *collector) collectContainerProcess() {
+	klog.V(6).Info("collectContainerProcess start")
+	containers := listProcessContainers(c.statesInformer.GetAllPods())
+	if len(containers) <= 0 {
+		return
+	}
+	// share the budget of counting the fds among the containers, so a container with lots of processes cannot
+	// stall the collection
+	sampleLimit := c.config.ProcessFDSampleBudget / len(containers)
+	if sampleLimit < 1 {
+		sampleLimit = 1
+	}
+
+	metrics.ResetContainerProcess()
+	count := 0
+	for _, container := range containers {
+		collectTime := time.Now()
+		pids, err := c.cgroupReader.ReadPidsCurrent(container.dir)
+		if err != nil {
+			klog.V(5).Infof("failed to collect the tasks for container %s/%s/%s, err: %v",
+				container.pod.Namespace, container.pod.Name, container.status.Name, err)
+			continue
+		}
+		procs, err := c.cgroupReader.ReadCPUProcs(container.dir)
+		if err != nil {
+			klog.V(5).Infof("failed to collect the processes for container %s/%s/%s, err: %v",
+				container.pod.Namespace, container.pod.Name, container.status.Name, err)
+			continue
+		}
+		containerMetric := &metriccache.ContainerProcessMetric{
+			PodUID:      string(container.pod.UID),
+			ContainerID: container.status.ContainerID,
+			PIDs:        pids,
+			FDs:         estimateProcessFDs(procs, sampleLimit),
+		}
+		if err = c.metricCache.InsertContainerProcessMetric(collectTime, containerMetric); err != nil {
+			klog.Errorf("insert container process metric failed, metric %v, err %v", containerMetric, err)
+			continue
+		}
+		metrics.RecordContainerProcess(container.status, container.pod, float64(containerMetric.PIDs), float64(containerMetric.FDs))
+		count++
+	}
+	klog.V(5).Infof("collectContainerProcess finished, container num %d, collected %d", len(containers), count)
+}
+
+// listProcessContainers returns the running containers of the pods along with their cgroup dirs.
+func listProcessContainers(podMetas []*statesinformer.PodMeta) []processContainer {
+	var containers []processContainer
+	for _, meta := range podMetas {
+		pod := meta.Pod
+		for i := range pod.Status.ContainerStatuses {
+			status := &pod.Status.ContainerStatuses[i]
+			if status.State.Running == nil {
+				continue
+			}
+			dir, err := koordletutil.GetContainerCgroupPathWithKube(meta.CgroupDir, status)
+			if err != nil {
+				klog.V(5).Infof("failed to get cgroup dir for container %s/%s/%s, err: %v",
+					pod.Namespace, pod.Name, status.Name, err)
+				continue
+			}
+			containers = append(containers, processContainer{pod: pod, status: status, dir: dir})
+		}
+	}
+	return containers
+}
+
+// estimateProcessFDs counts the fds of at most sampleLimit processes evenly picked from procs, and estimates the
+// fds of all the processes in proportion to the samples. The threads of a process share the fds, so only the
+// processes are counted.
+func estimateProcessFDs(procs []int32, sampleLimit int) int64 {
+	if len(procs) <= 0 || sampleLimit <= 0 {
+		return 0
+	}
+	step := (len(procs) + sampleLimit - 1) / sampleLimit
+	var sampledFDs, sampled int64
+	for i := 0; i < len(procs); i += step {
+		fds, err := countProcessFDs(uint32(procs[i]))
+		if os.IsNotExist(err) {
+			// the process has exited
+			continue
+		} else if err != nil {
+			klog.V(6).Infof("failed to count the fds of process %d, err: %v", procs[i], err)
+			continue
+		}
+		sampledFDs += fds
+		sampled++
+	}
+	if sampled <= 0 {
+		return 0
+	}
+	return sampledFDs * int64(len(procs)) / sampled
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsadvisor
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_estimateProcessFDs(t *testing.T) {
+	processFDs := map[int32]int64{1: 10, 2: 20, 3: 30, 4: 40}
+	oldCountProcessFDs := countProcessFDs
+	countProcessFDs = func(pid uint32) (int64, error) {
+		fds, ok := processFDs[int32(pid)]
+		if !ok {
+			// the process has exited
+			return 0, &os.PathError{Op: "open", Path: strconv.Itoa(int(pid)), Err: os.ErrNotExist}
+		}
+		return fds, nil
+	}
+	defer func() { countProcessFDs = oldCountProcessFDs }()
+
+	tests := []struct {
+		name        string
+		procs       []int32
+		sampleLimit int
+		want        int64
+	}{
+		{
+			name:        "no process",
+			procs:       nil,
+			sampleLimit: 10,
+			want:        0,
+		},
+		{
+			name:        "count all processes",
+			procs:       []int32{1, 2, 3, 4},
+			sampleLimit: 10,
+			want:        100,
+		},
+		{
+			name:        "estimate by the samples",
+			procs:       []int32{1, 2, 3, 4},
+			sampleLimit: 2,
+			// sample the process 1 and 3
+			want: 80,
+		},
+		{
+			name:        "skip the exited processes",
+			procs:       []int32{1, 5, 3, 6},
+			sampleLimit: 4,
+			want:        80,
+		},
+		{
+			name:        "all sampled processes exited",
+			procs:       []int32{5, 6},
+			sampleLimit: 1,
+			want:        0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateProcessFDs(tt.procs, tt.sampleLimit)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_collectProcess(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	system.SetupCgroupPathFormatter(system.Systemd)
+
+	testContainerDir := "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-podxxx.slice/cri-containerd-123abc.scope"
+	helper.WriteProcSubFileContents(system.ProcLoadAvgName, "0.52 0.58 0.59 3/1288 123456\n")
+	helper.WriteProcSubFileContents(system.SysctlSubDir+"/"+system.KernelPIDMax, "32768\n")
+	helper.WriteProcSubFileContents(system.SysctlSubDir+"/"+system.FsFileNr, "12000\t0\t100000\n")
+	helper.WriteCgroupFileContents(testContainerDir, system.PidsCurrent, "120\n")
+	helper.WriteCgroupFileContents(testContainerDir, system.CPUProcs, "1000\n1001\n")
+	for pid, fds := range map[string]int{"1000": 3, "1001": 5} {
+		for fd := 0; fd < fds; fd++ {
+			helper.WriteProcSubFileContents(pid+"/fd/"+strconv.Itoa(fd), "")
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctrl)
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	mockStatesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{
+		{
+			CgroupDir: "kubepods-besteffort.slice/kubepods-besteffort-podxxx.slice",
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default", UID: "xxx"},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:        "test-container",
+							ContainerID: "containerd://123abc",
+							State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+						},
+						{
+							Name:        "terminated-container",
+							ContainerID: "containerd://456def",
+							State:       corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+						},
+					},
+				},
+			},
+		},
+	}).Times(1)
+	mockMetricCache.EXPECT().InsertNodeProcessMetric(gomock.Any(), &metriccache.NodeProcessMetric{
+		PIDs:    1288,
+		PIDsMax: 32768,
+		FDs:     12000,
+		FDsMax:  100000,
+	}).Return(nil).Times(1)
+	mockMetricCache.EXPECT().InsertContainerProcessMetric(gomock.Any(), &metriccache.ContainerProcessMetric{
+		PodUID:      "xxx",
+		ContainerID: "containerd://123abc",
+		PIDs:        120,
+		FDs:         8,
+	}).Return(nil).Times(1)
+
+	c := &collector{
+		config:         NewDefaultConfig(),
+		statesInformer: mockStatesInformer,
+		metricCache:    mockMetricCache,
+		cgroupReader:   resourceexecutor.NewCgroupReader(),
+		context:        newCollectContext(),
+	}
+	c.collectNodeProcess()
+	c.collectContainerProcess()
+}
@@ -212,6 +212,7 @@ unevictable 0
 				state:          newCollectState(),
 			}
 
+			statesInformer.EXPECT().GetGhostPods().Return(nil).AnyTimes()
 			statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{
 				CgroupDir: testPodMetaDir,
 				Pod: &corev1.Pod{
@@ -233,6 +234,7 @@ unevictable 0
 				}}}).Times(2)
 			metricCache.EXPECT().InsertNodeResourceMetric(gomock.Any(), gomock.Any()).MaxTimes(1)
 			metricCache.EXPECT().InsertPodResourceMetric(gomock.Any(), gomock.Any()).MaxTimes(1)
+			metricCache.EXPECT().InsertTerminatingPodsResourceMetric(gomock.Any(), gomock.Any()).MaxTimes(1)
 			metricCache.EXPECT().InsertNodeCPUInfo(gomock.Any()).MaxTimes(1)
 
 			assert.NotPanics(t, func() {
@@ -403,6 +405,7 @@ unevictable 0
 
 			statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
 			metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+			statesInformer.EXPECT().GetGhostPods().Return(nil).AnyTimes()
 			statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{
 				CgroupDir: testPodMetaDir,
 				Pod:       testPod,
@@ -414,6 +417,7 @@ unevictable 0
 			if tt.want.containerResourceMetric {
 				metricCache.EXPECT().InsertContainerResourceMetric(gomock.Any(), gomock.Not(nil)).Times(1)
 			}
+			metricCache.EXPECT().InsertTerminatingPodsResourceMetric(gomock.Any(), gomock.Not(nil)).Times(1)
 
 			c := &collector{
 				config: &Config{
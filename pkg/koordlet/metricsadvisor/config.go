@@ -16,7 +16,11 @@ limitations under the License.
 
 package metricsadvisor
 
-import "flag"
+import (
+	"flag"
+
+	cliflag "k8s.io/component-base/cli/flag"
+)
 
 type Config struct {
 	CollectResUsedIntervalSeconds     int
@@ -24,6 +28,13 @@ type Config struct {
 	CPICollectorIntervalSeconds       int
 	PSICollectorIntervalSeconds       int
 	CPICollectorTimeWindowSeconds     int
+	MBMCollectorIntervalSeconds       int
+	ProcessCollectorIntervalSeconds   int
+	// ProcessFDSampleBudget is the max number of the processes whose file descriptors are counted in a round.
+	ProcessFDSampleBudget          int
+	DiskIOCollectorIntervalSeconds int
+	// DiskIOCollectMountPoints are the mount points whose disks the io is collected of.
+	DiskIOCollectMountPoints []string
 }
 
 func NewDefaultConfig() *Config {
@@ -33,6 +44,11 @@ func NewDefaultConfig() *Config {
 		CPICollectorIntervalSeconds:       60,
 		PSICollectorIntervalSeconds:       10,
 		CPICollectorTimeWindowSeconds:     10,
+		MBMCollectorIntervalSeconds:       10,
+		ProcessCollectorIntervalSeconds:   10,
+		ProcessFDSampleBudget:             1000,
+		DiskIOCollectorIntervalSeconds:    10,
+		DiskIOCollectMountPoints:          []string{"/var/lib/kubelet"},
 	}
 }
 
@@ -43,4 +59,9 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.CPICollectorIntervalSeconds, "cpi-collector-interval-seconds", c.CPICollectorIntervalSeconds, "Collect cpi interval by seconds")
 	fs.IntVar(&c.PSICollectorIntervalSeconds, "psi-collector-interval-seconds", c.PSICollectorIntervalSeconds, "Collect psi interval by seconds")
 	fs.IntVar(&c.CPICollectorTimeWindowSeconds, "collect-cpi-timewindow-seconds", c.CPICollectorTimeWindowSeconds, "Collect cpi time window by seconds")
+	fs.IntVar(&c.MBMCollectorIntervalSeconds, "mbm-collector-interval-seconds", c.MBMCollectorIntervalSeconds, "Collect resctrl memory bandwidth interval by seconds")
+	fs.IntVar(&c.ProcessCollectorIntervalSeconds, "process-collector-interval-seconds", c.ProcessCollectorIntervalSeconds, "Collect the tasks and the file descriptors interval by seconds")
+	fs.IntVar(&c.ProcessFDSampleBudget, "process-fd-sample-budget", c.ProcessFDSampleBudget, "The max number of the processes whose file descriptors are counted in a collection, the others are estimated by the samples")
+	fs.IntVar(&c.DiskIOCollectorIntervalSeconds, "disk-io-collector-interval-seconds", c.DiskIOCollectorIntervalSeconds, "Collect the io of the disks under the mount points interval by seconds")
+	fs.Var(cliflag.NewStringSlice(&c.DiskIOCollectMountPoints), "disk-io-collect-mount-points", "The mount points whose disks the io is collected of, repeat the flag for each mount point")
 }
@@ -30,6 +30,11 @@ func Test_NewDefaultConfig(t *testing.T) {
 		CPICollectorIntervalSeconds:       60,
 		PSICollectorIntervalSeconds:       10,
 		CPICollectorTimeWindowSeconds:     10,
+		MBMCollectorIntervalSeconds:       10,
+		ProcessCollectorIntervalSeconds:   10,
+		ProcessFDSampleBudget:             1000,
+		DiskIOCollectorIntervalSeconds:    10,
+		DiskIOCollectMountPoints:          []string{"/var/lib/kubelet"},
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -43,6 +48,12 @@ func Test_InitFlags(t *testing.T) {
 		"--cpi-collector-interval-seconds=90",
 		"--psi-collector-interval-seconds=5",
 		"--collect-cpi-timewindow-seconds=15",
+		"--mbm-collector-interval-seconds=5",
+		"--process-collector-interval-seconds=30",
+		"--process-fd-sample-budget=200",
+		"--disk-io-collector-interval-seconds=30",
+		"--disk-io-collect-mount-points=/var/lib/kubelet",
+		"--disk-io-collect-mount-points=/data",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
@@ -52,6 +63,11 @@ func Test_InitFlags(t *testing.T) {
 		CPICollectorIntervalSeconds       int
 		PSICollectorIntervalSeconds       int
 		CPICollectorTimeWindowSeconds     int
+		MBMCollectorIntervalSeconds       int
+		ProcessCollectorIntervalSeconds   int
+		ProcessFDSampleBudget             int
+		DiskIOCollectorIntervalSeconds    int
+		DiskIOCollectMountPoints          []string
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -69,6 +85,11 @@ func Test_InitFlags(t *testing.T) {
 				CPICollectorIntervalSeconds:       90,
 				PSICollectorIntervalSeconds:       5,
 				CPICollectorTimeWindowSeconds:     15,
+				MBMCollectorIntervalSeconds:       5,
+				ProcessCollectorIntervalSeconds:   30,
+				ProcessFDSampleBudget:             200,
+				DiskIOCollectorIntervalSeconds:    30,
+				DiskIOCollectMountPoints:          []string{"/var/lib/kubelet", "/data"},
 			},
 			args: args{fs: fs},
 		},
@@ -81,6 +102,11 @@ func Test_InitFlags(t *testing.T) {
 				CPICollectorIntervalSeconds:       tt.fields.CPICollectorIntervalSeconds,
 				PSICollectorIntervalSeconds:       tt.fields.PSICollectorIntervalSeconds,
 				CPICollectorTimeWindowSeconds:     tt.fields.CPICollectorTimeWindowSeconds,
+				MBMCollectorIntervalSeconds:       tt.fields.MBMCollectorIntervalSeconds,
+				ProcessCollectorIntervalSeconds:   tt.fields.ProcessCollectorIntervalSeconds,
+				ProcessFDSampleBudget:             tt.fields.ProcessFDSampleBudget,
+				DiskIOCollectorIntervalSeconds:    tt.fields.DiskIOCollectorIntervalSeconds,
+				DiskIOCollectMountPoints:          tt.fields.DiskIOCollectMountPoints,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
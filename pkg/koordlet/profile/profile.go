@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Profile is a "string" type.
+type Profile string
+
+const (
+	// ProfileStandard keeps the default settings of koordlet.
+	ProfileStandard Profile = "standard"
+	// ProfileEdge trades the precision of the metrics for the overhead on the edge nodes with few resources.
+	ProfileEdge Profile = "edge"
+)
+
+// Preset is the settings of koordlet preset by a profile. A zero collector interval disables the collector.
+type Preset struct {
+	// CollectResUsedIntervalSeconds is the interval to collect the resource usage of the node and pods.
+	CollectResUsedIntervalSeconds int
+	// CollectNodeCPUInfoIntervalSeconds is the interval to collect the cpu info of the node.
+	CollectNodeCPUInfoIntervalSeconds int
+	// CPICollectorIntervalSeconds is the interval to collect the cpi by the perf events of all containers.
+	CPICollectorIntervalSeconds int
+	// PSICollectorIntervalSeconds is the interval to collect the psi.
+	PSICollectorIntervalSeconds int
+	// MBMCollectorIntervalSeconds is the interval to collect the memory bandwidth of the resctrl groups.
+	MBMCollectorIntervalSeconds int
+	// ProcessCollectorIntervalSeconds is the interval to walk the /proc to count the tasks and file descriptors.
+	ProcessCollectorIntervalSeconds int
+	// DiskIOCollectorIntervalSeconds is the interval to collect the io of the disks under the mount points.
+	DiskIOCollectorIntervalSeconds int
+	// MetricGCIntervalSeconds is the interval to gc the expired metrics.
+	MetricGCIntervalSeconds int
+	// MetricExpireSeconds is how long the metrics are kept in the metric cache.
+	MetricExpireSeconds int
+	// KubeletSyncInterval is the interval to sync the pods from kubelet.
+	KubeletSyncInterval time.Duration
+	// NodeTopologySyncInterval is the interval to report the node topology and devices.
+	NodeTopologySyncInterval time.Duration
+}
+
+var presets = map[Profile]Preset{
+	ProfileStandard: {
+		CollectResUsedIntervalSeconds:     1,
+		CollectNodeCPUInfoIntervalSeconds: 60,
+		CPICollectorIntervalSeconds:       60,
+		PSICollectorIntervalSeconds:       10,
+		MBMCollectorIntervalSeconds:       10,
+		ProcessCollectorIntervalSeconds:   10,
+		DiskIOCollectorIntervalSeconds:    10,
+		MetricGCIntervalSeconds:           300,
+		MetricExpireSeconds:               1800,
+		KubeletSyncInterval:               10 * time.Second,
+		NodeTopologySyncInterval:          3 * time.Second,
+	},
+	ProfileEdge: {
+		CollectResUsedIntervalSeconds:     10,
+		CollectNodeCPUInfoIntervalSeconds: 300,
+		// the perf events, resctrl and /proc walks cost too much on the small nodes
+		CPICollectorIntervalSeconds:     0,
+		PSICollectorIntervalSeconds:     60,
+		MBMCollectorIntervalSeconds:     0,
+		ProcessCollectorIntervalSeconds: 0,
+		DiskIOCollectorIntervalSeconds:  60,
+		MetricGCIntervalSeconds:         120,
+		MetricExpireSeconds:             600,
+		KubeletSyncInterval:             30 * time.Second,
+		NodeTopologySyncInterval:        30 * time.Second,
+	},
+}
+
+// GetPreset returns the preset of the profile.
+func GetPreset(profile Profile) (Preset, error) {
+	preset, ok := presets[profile]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown profile %q", profile)
+	}
+	return preset, nil
+}
+
+// IsValid returns whether the profile is known.
+func IsValid(profile Profile) bool {
+	_, ok := presets[profile]
+	return ok
+}
+
+// Override sets a setting explicitly, which takes precedence over the preset of any profile.
+type Override func(preset *Preset)
+
+// Manager keeps the current profile of koordlet. The effective settings are the preset of the current profile with
+// the overrides applied.
+type Manager struct {
+	lock      sync.RWMutex
+	profile   Profile
+	overrides []Override
+	effective *Preset
+}
+
+func NewManager(overrides ...Override) *Manager {
+	return &Manager{overrides: overrides}
+}
+
+// Switch switches to the profile, and returns whether the effective settings are changed.
+func (m *Manager) Switch(profile Profile) (bool, error) {
+	preset, err := GetPreset(profile)
+	if err != nil {
+		return false, err
+	}
+	for _, override := range m.overrides {
+		override(&preset)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	changed := m.effective == nil || *m.effective != preset
+	m.profile = profile
+	m.effective = &preset
+	return changed, nil
+}
+
+// Profile returns the current profile, empty if no profile is switched to.
+func (m *Manager) Profile() Profile {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.profile
+}
+
+// Effective returns a copy of the effective settings, nil if no profile is switched to.
+func (m *Manager) Effective() *Preset {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.effective == nil {
+		return nil
+	}
+	preset := *m.effective
+	return &preset
+}
+
+var (
+	defaultManagerLock sync.RWMutex
+	defaultManager     = NewManager()
+)
+
+// SetDefaultManager sets the manager the components of koordlet read the effective settings from.
+func SetDefaultManager(m *Manager) {
+	defaultManagerLock.Lock()
+	defer defaultManagerLock.Unlock()
+	defaultManager = m
+}
+
+func getDefaultManager() *Manager {
+	defaultManagerLock.RLock()
+	defer defaultManagerLock.RUnlock()
+	return defaultManager
+}
+
+// Switch switches the profile of the default manager.
+func Switch(profile Profile) (bool, error) {
+	return getDefaultManager().Switch(profile)
+}
+
+// Current returns the profile of the default manager.
+func Current() Profile {
+	return getDefaultManager().Profile()
+}
+
+// Effective returns the effective settings of the default manager, nil if no profile is applied, e.g. in the tests.
+func Effective() *Preset {
+	return getDefaultManager().Effective()
+}
+
+// Seconds returns the interval in seconds got from the effective settings, or the fallback if no profile is applied.
+func Seconds(get func(preset *Preset) int, fallback int) time.Duration {
+	if preset := Effective(); preset != nil {
+		return time.Duration(get(preset)) * time.Second
+	}
+	return time.Duration(fallback) * time.Second
+}
+
+// Duration returns the interval got from the effective settings, or the fallback if no profile is applied.
+func Duration(get func(preset *Preset) time.Duration, fallback time.Duration) time.Duration {
+	if preset := Effective(); preset != nil {
+		return get(preset)
+	}
+	return fallback
+}
+
+// pausedRecheckInterval is how long a paused loop waits to get the interval again.
+var pausedRecheckInterval = 30 * time.Second
+
+// Until works like wait.Until but gets the interval again before each period, so the switched profile takes effect
+// without restart. f is paused while the interval is not positive.
+func Until(f func(), interval func() time.Duration, stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+		d := interval()
+		if d > 0 {
+			f()
+		} else {
+			klog.V(6).Infof("the loop is paused until the interval is positive")
+			d = pausedRecheckInterval
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(d):
+		}
+	}
+}
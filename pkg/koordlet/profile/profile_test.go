@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPreset(t *testing.T) {
+	standard, err := GetPreset(ProfileStandard)
+	assert.NoError(t, err)
+	edge, err := GetPreset(ProfileEdge)
+	assert.NoError(t, err)
+	assert.Equal(t, Preset{
+		CollectResUsedIntervalSeconds:     10,
+		CollectNodeCPUInfoIntervalSeconds: 300,
+		CPICollectorIntervalSeconds:       0,
+		PSICollectorIntervalSeconds:       60,
+		MBMCollectorIntervalSeconds:       0,
+		ProcessCollectorIntervalSeconds:   0,
+		DiskIOCollectorIntervalSeconds:    60,
+		MetricGCIntervalSeconds:           120,
+		MetricExpireSeconds:               600,
+		KubeletSyncInterval:               30 * time.Second,
+		NodeTopologySyncInterval:          30 * time.Second,
+	}, edge)
+	assert.Less(t, edge.MetricExpireSeconds, standard.MetricExpireSeconds)
+	assert.Greater(t, edge.KubeletSyncInterval, standard.KubeletSyncInterval)
+
+	// the preset is a copy
+	standard.MetricExpireSeconds = 1
+	got, _ := GetPreset(ProfileStandard)
+	assert.Equal(t, 1800, got.MetricExpireSeconds)
+
+	_, err = GetPreset("unknown")
+	assert.Error(t, err)
+	assert.False(t, IsValid("unknown"))
+}
+
+func TestManager(t *testing.T) {
+	m := NewManager(func(preset *Preset) {
+		preset.MetricExpireSeconds = 900
+	}, func(preset *Preset) {
+		preset.CPICollectorIntervalSeconds = 120
+	})
+	assert.Nil(t, m.Effective())
+	assert.Equal(t, Profile(""), m.Profile())
+
+	changed, err := m.Switch(ProfileEdge)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, ProfileEdge, m.Profile())
+	edge := m.Effective()
+	assert.Equal(t, 900, edge.MetricExpireSeconds)
+	assert.Equal(t, 120, edge.CPICollectorIntervalSeconds)
+	assert.Equal(t, 10, edge.CollectResUsedIntervalSeconds)
+	assert.Equal(t, 0, edge.MBMCollectorIntervalSeconds)
+
+	changed, err = m.Switch(ProfileEdge)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// the overrides take precedence over the preset of any profile
+	changed, err = m.Switch(ProfileStandard)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	standard := m.Effective()
+	assert.Equal(t, 900, standard.MetricExpireSeconds)
+	assert.Equal(t, 120, standard.CPICollectorIntervalSeconds)
+	assert.Equal(t, 1, standard.CollectResUsedIntervalSeconds)
+	assert.Equal(t, 10, standard.MBMCollectorIntervalSeconds)
+
+	// the effective settings are a copy
+	standard.MetricExpireSeconds = 1
+	assert.Equal(t, 900, m.Effective().MetricExpireSeconds)
+
+	_, err = m.Switch("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, ProfileStandard, m.Profile())
+}
+
+func TestSecondsAndDuration(t *testing.T) {
+	defer SetDefaultManager(NewManager())
+
+	SetDefaultManager(NewManager())
+	assert.Equal(t, 5*time.Second, Seconds(func(p *Preset) int { return p.MetricGCIntervalSeconds }, 5))
+	assert.Equal(t, time.Minute, Duration(func(p *Preset) time.Duration { return p.KubeletSyncInterval }, time.Minute))
+
+	_, err := Switch(ProfileEdge)
+	assert.NoError(t, err)
+	assert.Equal(t, ProfileEdge, Current())
+	assert.Equal(t, 120*time.Second, Seconds(func(p *Preset) int { return p.MetricGCIntervalSeconds }, 5))
+	assert.Equal(t, 30*time.Second, Duration(func(p *Preset) time.Duration { return p.KubeletSyncInterval }, time.Minute))
+}
+
+func TestUntil(t *testing.T) {
+	oldRecheckInterval := pausedRecheckInterval
+	defer func() {
+		pausedRecheckInterval = oldRecheckInterval
+	}()
+	pausedRecheckInterval = 10 * time.Millisecond
+
+	var interval time.Duration
+	intervalCh := make(chan time.Duration)
+	calls := make(chan struct{}, 100)
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Until(func() {
+			calls <- struct{}{}
+		}, func() time.Duration {
+			select {
+			case interval = <-intervalCh:
+			default:
+			}
+			return interval
+		}, stopCh)
+		close(done)
+	}()
+
+	// paused while the interval is zero
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, calls, 0)
+
+	// resumed after the interval is positive
+	intervalCh <- time.Millisecond
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("expect f is called after the interval is positive")
+	}
+
+	close(stopCh)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expect Until returns after stopped")
+	}
+}
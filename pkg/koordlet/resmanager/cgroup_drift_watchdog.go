@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	expireCache "github.com/koordinator-sh/koordinator/pkg/util/cache"
+)
+
+const (
+	cgroupDriftRepeated = "CgroupDriftRepeated"
+
+	// cgroupDriftCountExpiration is how long a repaired file is remembered to count the repeated drifts.
+	cgroupDriftCountExpiration = time.Hour
+)
+
+// CgroupDriftWatchdog verifies the cgroup values which koordlet has updated and records in the executor's cache.
+// Some node-level daemons may overwrite these values, while the cacheable updates will not notice the change until
+// the cache expires.
+type CgroupDriftWatchdog struct {
+	resmanager  *resmanager
+	executor    resourceexecutor.ResourceUpdateExecutor
+	driftCounts *expireCache.Cache
+}
+
+func NewCgroupDriftWatchdog(resmanager *resmanager) *CgroupDriftWatchdog {
+	return &CgroupDriftWatchdog{
+		resmanager:  resmanager,
+		executor:    resourceexecutor.NewResourceUpdateExecutor(),
+		driftCounts: expireCache.NewCache(cgroupDriftCountExpiration, time.Minute),
+	}
+}
+
+func (w *CgroupDriftWatchdog) RunInit(stopCh <-chan struct{}) error {
+	w.executor.Run(stopCh)
+	return w.driftCounts.Run(stopCh)
+}
+
+func (w *CgroupDriftWatchdog) verify() {
+	cfg := w.resmanager.config
+	repaired := w.executor.VerifyCacheableResources(cfg.CgroupVerifySampleRatio, cfg.CgroupVerifyMaxFilesPerCycle)
+	for _, updater := range repaired {
+		metrics.RecordCgroupDriftRepaired(string(updater.ResourceType()))
+
+		count := 1
+		if v, ok := w.driftCounts.Get(updater.Key()); ok {
+			count = v.(int) + 1
+		}
+		_ = w.driftCounts.SetDefault(updater.Key(), count)
+		if count != cfg.CgroupDriftWarningThreshold {
+			continue
+		}
+
+		node := w.resmanager.statesInformer.GetNode()
+		if node == nil {
+			klog.Warningf("cgroup file %s has drifted for %v times, skip sending the event since node is nil",
+				updater.Path(), count)
+			continue
+		}
+		w.resmanager.eventRecorder.Eventf(node, corev1.EventTypeWarning, cgroupDriftRepeated,
+			"cgroup file %s has been modified by others and repaired for %v times, expected value %s",
+			updater.Path(), count, updater.Value())
+	}
+	if len(repaired) > 0 {
+		klog.V(4).Infof("cgroup drift watchdog repaired %v resources", len(repaired))
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cache"
+)
+
+func TestCgroupDriftWatchdog_verify(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(node)
+	defer metrics.Register(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	statesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetNode().Return(node).AnyTimes()
+
+	fakeRecorder := &FakeRecorder{}
+	cfg := NewDefaultConfig()
+	cfg.CgroupVerifySampleRatio = 1
+	cfg.CgroupDriftWarningThreshold = 2
+	r := &resmanager{
+		config:         cfg,
+		statesInformer: statesInformer,
+		eventRecorder:  fakeRecorder,
+	}
+	executor := &resourceexecutor.ResourceUpdateExecutorImpl{
+		ResourceCache: cache.NewCacheDefault(),
+		Config:        resourceexecutor.NewDefaultConfig(),
+	}
+	w := &CgroupDriftWatchdog{
+		resmanager:  r,
+		executor:    executor,
+		driftCounts: cache.NewCacheDefault(),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, w.RunInit(stop))
+
+	testPodDir := "kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod-test.slice"
+	helper.WriteCgroupFileContents(testPodDir, system.CPUShares, "1024")
+	helper.WriteCgroupFileContents(testPodDir, system.CPUSet, "0-3")
+	sharesUpdater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.CPUSharesName, testPodDir, "2")
+	assert.NoError(t, err)
+	cpusetUpdater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.CPUSetCPUSName, testPodDir, "0,1")
+	assert.NoError(t, err)
+	executor.UpdateBatch(true, sharesUpdater, cpusetUpdater)
+	assert.Equal(t, "2", helper.ReadCgroupFileContents(testPodDir, system.CPUShares))
+
+	driftCounter := metrics.CgroupDriftRepaired.WithLabelValues(node.Name, string(system.CPUSharesName))
+	before := testutil.ToFloat64(driftCounter)
+
+	// no drift, the equivalent cpuset in another format is not considered as drifted
+	helper.WriteCgroupFileContents(testPodDir, system.CPUSet, "0-1")
+	w.verify()
+	assert.Equal(t, before, testutil.ToFloat64(driftCounter))
+	assert.Equal(t, "0-1", helper.ReadCgroupFileContents(testPodDir, system.CPUSet))
+
+	// the value is reset by others
+	helper.WriteCgroupFileContents(testPodDir, system.CPUShares, "1024")
+	w.verify()
+	assert.Equal(t, "2", helper.ReadCgroupFileContents(testPodDir, system.CPUShares))
+	assert.Equal(t, before+1, testutil.ToFloat64(driftCounter))
+	assert.Equal(t, "", fakeRecorder.eventReason)
+
+	// repeated drift sends a warning event
+	helper.WriteCgroupFileContents(testPodDir, system.CPUShares, "1024")
+	w.verify()
+	assert.Equal(t, "2", helper.ReadCgroupFileContents(testPodDir, system.CPUShares))
+	assert.Equal(t, before+2, testutil.ToFloat64(driftCounter))
+	assert.Equal(t, cgroupDriftRepeated, fakeRecorder.eventReason)
+}
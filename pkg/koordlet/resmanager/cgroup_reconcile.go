@@ -26,6 +26,7 @@ import (
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager/configextensions"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
@@ -101,7 +102,7 @@ func (m *CgroupResourcesReconcile) calculateAndUpdateResources(nodeSLO *slov1alp
 		klog.Errorf("failed to calculate resources, err: node is invalid: %v", util.DumpJSON(node))
 		return
 	}
-	podMetas := m.resmanager.statesInformer.GetAllPods()
+	podMetas := m.resmanager.getManagedPods(features.CgroupReconcile)
 
 	// calculate qos-level, pod-level and container-level resources
 	qosResources, podResources, containerResources := m.calculateResources(nodeSLO.Spec.ResourceQOSStrategy, node, podMetas)
@@ -341,7 +342,7 @@ func (m *CgroupResourcesReconcile) getMergedPodResourceQoS(pod *corev1.Pod, cfg
 }
 
 // mergePodResourceQoSForMemoryQoS merges pod-level memory qos config with node-level resource qos config
-// config overwrite: pod-level config > pod policy template > node-level config
+// config overwrite: pod-level config > pod policy template > node-level config > container env hints
 func (m *CgroupResourcesReconcile) mergePodResourceQoSForMemoryQoS(pod *corev1.Pod, cfg *slov1alpha1.ResourceQOS) {
 	// get the pod-level config and determine if the pod is allowed
 	if cfg.MemoryQOS == nil {
@@ -378,6 +379,9 @@ func (m *CgroupResourcesReconcile) mergePodResourceQoSForMemoryQoS(pod *corev1.P
 		cfg.MemoryQOS.MemoryQOS = getPodResourceQoSByQoSClass(pod, util.DefaultResourceQOSStrategy(), m.resmanager.config).MemoryQOS.MemoryQOS
 	}
 
+	// complete the fields unspecified by node-level config with container env hints
+	mergeMemoryQoSEnvHints(pod, &cfg.MemoryQOS.MemoryQOS)
+
 	// no need to merge config if pod-level config is nil
 	if podCfg == nil {
 		return
@@ -30,6 +30,51 @@ type Config struct {
 	MemoryEvictCoolTimeSeconds int
 	CPUEvictCoolTimeSeconds    int
 	QOSExtensionCfg            *plugins.QOSExtensionConfig
+	// CgroupVerifyIntervalSeconds is the interval of verifying the cgroup values updated by koordlet.
+	CgroupVerifyIntervalSeconds int
+	// CgroupVerifySampleRatio is the fraction of the owned cgroup files to verify in one cycle.
+	CgroupVerifySampleRatio float64
+	// CgroupVerifyMaxFilesPerCycle limits the cgroup files read in one cycle to bound the IO cost.
+	CgroupVerifyMaxFilesPerCycle int
+	// CgroupDriftWarningThreshold is the number of repairs on the same cgroup file before a warning event is sent.
+	CgroupDriftWarningThreshold int
+	// MemoryLocalityRepairIntervalSeconds is the interval of repairing the memory locality of LS containers.
+	MemoryLocalityRepairIntervalSeconds int
+	// MemoryLocalityRepairMode is the way to repair the memory locality, "Migrate" or "ExpandMems".
+	MemoryLocalityRepairMode string
+	// MemoryLocalitySampleContainersPerCycle limits the containers whose numa_maps are read in one cycle.
+	MemoryLocalitySampleContainersPerCycle int
+	// MemoryLocalityRepairContainersPerCycle limits the containers repaired in one cycle to throttle the migration.
+	MemoryLocalityRepairContainersPerCycle int
+	// MemoryLocalityNodeCPUThresholdPercent is the max node cpu usage percent allowing the repair.
+	MemoryLocalityNodeCPUThresholdPercent int64
+	// MemoryLocalityExpandSeconds is the minimum time the cpuset.mems keeps expanded in the "ExpandMems" mode before
+	// being restored in the idle cycles.
+	MemoryLocalityExpandSeconds int
+	// OrphanArtifactGCIntervalSeconds is the interval of removing the artifacts left for the pods no longer existing.
+	OrphanArtifactGCIntervalSeconds int
+	// OrphanArtifactGCDryRun only logs and counts the orphan artifacts without removing them.
+	OrphanArtifactGCDryRun bool
+	// MBAFeedbackIntervalSeconds is the interval of adjusting the MBA percent of the BE resctrl group.
+	MBAFeedbackIntervalSeconds int
+	// MBAFeedbackWindowSeconds is the time window of the LS memory bandwidth and CPI regarded as the baseline.
+	MBAFeedbackWindowSeconds int
+	// MBAFeedbackDegradePercent is the LS bandwidth drop or CPI rise against the baseline regarded as degraded.
+	MBAFeedbackDegradePercent int64
+	// MBAFeedbackRelaxRounds is the number of consecutive rounds without degradation before relaxing the MBA percent.
+	MBAFeedbackRelaxRounds int
+	// GPUMemoryLeakCheckIntervalSeconds is the interval of checking the GPU memory usage of pods against their shares.
+	GPUMemoryLeakCheckIntervalSeconds int
+	// GPUMemoryLeakMarginPercent is the percent of the GPU memory usage over the allocated share regarded as overused.
+	GPUMemoryLeakMarginPercent int64
+	// GPUMemoryLeakConsecutiveSamples is the number of consecutive overused samples before an event is raised on the pod.
+	GPUMemoryLeakConsecutiveSamples int
+	// PIDProtectionIntervalSeconds is the interval of limiting the tasks of BE pods and checking the node pid usage.
+	PIDProtectionIntervalSeconds int
+	// PIDEvictCoolTimeSeconds is the cooling time after evicting a BE pod for the node pid usage.
+	PIDEvictCoolTimeSeconds int
+	// NetIngressProtectionIntervalSeconds is the interval of syncing the RX shapers of the BE pods.
+	NetIngressProtectionIntervalSeconds int
 }
 
 func NewDefaultConfig() *Config {
@@ -41,6 +86,35 @@ func NewDefaultConfig() *Config {
 		MemoryEvictCoolTimeSeconds: 4,
 		CPUEvictCoolTimeSeconds:    20,
 		QOSExtensionCfg:            &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{}},
+
+		CgroupVerifyIntervalSeconds:  60,
+		CgroupVerifySampleRatio:      0.1,
+		CgroupVerifyMaxFilesPerCycle: 200,
+		CgroupDriftWarningThreshold:  3,
+
+		MemoryLocalityRepairIntervalSeconds:    300,
+		MemoryLocalityRepairMode:               string(memoryLocalityRepairModeMigrate),
+		MemoryLocalitySampleContainersPerCycle: 20,
+		MemoryLocalityRepairContainersPerCycle: 1,
+		MemoryLocalityNodeCPUThresholdPercent:  50,
+		MemoryLocalityExpandSeconds:            600,
+
+		OrphanArtifactGCIntervalSeconds: 600,
+		OrphanArtifactGCDryRun:          false,
+
+		MBAFeedbackIntervalSeconds: 10,
+		MBAFeedbackWindowSeconds:   300,
+		MBAFeedbackDegradePercent:  10,
+		MBAFeedbackRelaxRounds:     3,
+
+		GPUMemoryLeakCheckIntervalSeconds: 30,
+		GPUMemoryLeakMarginPercent:        10,
+		GPUMemoryLeakConsecutiveSamples:   3,
+
+		PIDProtectionIntervalSeconds: 10,
+		PIDEvictCoolTimeSeconds:      60,
+
+		NetIngressProtectionIntervalSeconds: 10,
 	}
 }
 
@@ -51,5 +125,27 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.MemoryEvictIntervalSeconds, "memory-evict-interval-seconds", c.MemoryEvictIntervalSeconds, "evict be pod(memory) interval by seconds")
 	fs.IntVar(&c.MemoryEvictCoolTimeSeconds, "memory-evict-cool-time-seconds", c.MemoryEvictCoolTimeSeconds, "cooling time: memory next evict time should after lastEvictTime + MemoryEvictCoolTimeSeconds")
 	fs.IntVar(&c.CPUEvictCoolTimeSeconds, "cpu-evict-cool-time-seconds", c.CPUEvictCoolTimeSeconds, "cooltime: CPU next evict time should after lastEvictTime + CPUEvictCoolTimeSeconds")
+	fs.IntVar(&c.CgroupVerifyIntervalSeconds, "cgroup-verify-interval-seconds", c.CgroupVerifyIntervalSeconds, "verify the cgroup values updated by koordlet interval by seconds")
+	fs.Float64Var(&c.CgroupVerifySampleRatio, "cgroup-verify-sample-ratio", c.CgroupVerifySampleRatio, "the fraction of cgroup files updated by koordlet to verify in one cycle")
+	fs.IntVar(&c.CgroupVerifyMaxFilesPerCycle, "cgroup-verify-max-files-per-cycle", c.CgroupVerifyMaxFilesPerCycle, "the max number of cgroup files to read in one verify cycle, non-positive means unlimited")
+	fs.IntVar(&c.CgroupDriftWarningThreshold, "cgroup-drift-warning-threshold", c.CgroupDriftWarningThreshold, "send a warning event when the same cgroup file has been repaired for this many times")
+	fs.IntVar(&c.MemoryLocalityRepairIntervalSeconds, "memory-locality-repair-interval-seconds", c.MemoryLocalityRepairIntervalSeconds, "repair the memory locality of ls containers interval by seconds")
+	fs.StringVar(&c.MemoryLocalityRepairMode, "memory-locality-repair-mode", c.MemoryLocalityRepairMode, "the way to repair the memory locality, Migrate or ExpandMems")
+	fs.IntVar(&c.MemoryLocalitySampleContainersPerCycle, "memory-locality-sample-containers-per-cycle", c.MemoryLocalitySampleContainersPerCycle, "the max number of containers to read numa_maps in one repair cycle")
+	fs.IntVar(&c.MemoryLocalityRepairContainersPerCycle, "memory-locality-repair-containers-per-cycle", c.MemoryLocalityRepairContainersPerCycle, "the max number of containers to repair in one repair cycle")
+	fs.Int64Var(&c.MemoryLocalityNodeCPUThresholdPercent, "memory-locality-node-cpu-threshold-percent", c.MemoryLocalityNodeCPUThresholdPercent, "repair the memory locality only when the node cpu usage percent is below the threshold")
+	fs.IntVar(&c.MemoryLocalityExpandSeconds, "memory-locality-expand-seconds", c.MemoryLocalityExpandSeconds, "the minimum time the cpuset.mems keeps expanded in the ExpandMems mode by seconds")
+	fs.IntVar(&c.OrphanArtifactGCIntervalSeconds, "orphan-artifact-gc-interval-seconds", c.OrphanArtifactGCIntervalSeconds, "remove the resctrl tasks and groups, best-effort cgroups and tc shapers left by koordlet for the pods no longer existing interval by seconds")
+	fs.BoolVar(&c.OrphanArtifactGCDryRun, "orphan-artifact-gc-dry-run", c.OrphanArtifactGCDryRun, "only log and count the orphan artifacts without removing them")
+	fs.IntVar(&c.MBAFeedbackIntervalSeconds, "mba-feedback-interval-seconds", c.MBAFeedbackIntervalSeconds, "adjust the mba percent of be resctrl group by the memory bandwidth feedback interval by seconds")
+	fs.IntVar(&c.MBAFeedbackWindowSeconds, "mba-feedback-window-seconds", c.MBAFeedbackWindowSeconds, "the time window of the ls memory bandwidth and cpi regarded as the baseline by seconds")
+	fs.Int64Var(&c.MBAFeedbackDegradePercent, "mba-feedback-degrade-percent", c.MBAFeedbackDegradePercent, "the percent of the ls memory bandwidth drop or cpi rise against the baseline regarded as degraded")
+	fs.IntVar(&c.MBAFeedbackRelaxRounds, "mba-feedback-relax-rounds", c.MBAFeedbackRelaxRounds, "the number of consecutive rounds without degradation before relaxing the mba percent of be resctrl group")
+	fs.IntVar(&c.GPUMemoryLeakCheckIntervalSeconds, "gpu-memory-leak-check-interval-seconds", c.GPUMemoryLeakCheckIntervalSeconds, "check the gpu memory usage of pods against their allocated shares interval by seconds")
+	fs.Int64Var(&c.GPUMemoryLeakMarginPercent, "gpu-memory-leak-margin-percent", c.GPUMemoryLeakMarginPercent, "the percent of the gpu memory usage over the allocated share regarded as overused")
+	fs.IntVar(&c.GPUMemoryLeakConsecutiveSamples, "gpu-memory-leak-consecutive-samples", c.GPUMemoryLeakConsecutiveSamples, "raise an event on the pod when its gpu memory is overused for this many consecutive samples")
+	fs.IntVar(&c.PIDProtectionIntervalSeconds, "pid-protection-interval-seconds", c.PIDProtectionIntervalSeconds, "limit the tasks of be pods and check the node pid usage interval by seconds")
+	fs.IntVar(&c.PIDEvictCoolTimeSeconds, "pid-evict-cool-time-seconds", c.PIDEvictCoolTimeSeconds, "cooling time: next evict for the node pid usage should after lastEvictTime + PIDEvictCoolTimeSeconds")
+	fs.IntVar(&c.NetIngressProtectionIntervalSeconds, "net-ingress-protection-interval-seconds", c.NetIngressProtectionIntervalSeconds, "sync the rx shapers of be pods interval by seconds")
 	c.QOSExtensionCfg.InitFlags(fs)
 }
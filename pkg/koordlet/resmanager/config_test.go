@@ -34,6 +34,34 @@ func Test_NewDefaultConfig(t *testing.T) {
 		MemoryEvictCoolTimeSeconds: 4,
 		CPUEvictCoolTimeSeconds:    20,
 		QOSExtensionCfg:            &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{}},
+
+		CgroupVerifyIntervalSeconds:  60,
+		CgroupVerifySampleRatio:      0.1,
+		CgroupVerifyMaxFilesPerCycle: 200,
+		CgroupDriftWarningThreshold:  3,
+
+		MemoryLocalityRepairIntervalSeconds:    300,
+		MemoryLocalityRepairMode:               "Migrate",
+		MemoryLocalitySampleContainersPerCycle: 20,
+		MemoryLocalityRepairContainersPerCycle: 1,
+		MemoryLocalityNodeCPUThresholdPercent:  50,
+		MemoryLocalityExpandSeconds:            600,
+
+		OrphanArtifactGCIntervalSeconds: 600,
+		OrphanArtifactGCDryRun:          false,
+		MBAFeedbackIntervalSeconds:      10,
+		MBAFeedbackWindowSeconds:        300,
+		MBAFeedbackDegradePercent:       10,
+		MBAFeedbackRelaxRounds:          3,
+
+		GPUMemoryLeakCheckIntervalSeconds: 30,
+		GPUMemoryLeakMarginPercent:        10,
+		GPUMemoryLeakConsecutiveSamples:   3,
+
+		PIDProtectionIntervalSeconds: 10,
+		PIDEvictCoolTimeSeconds:      60,
+
+		NetIngressProtectionIntervalSeconds: 10,
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -49,6 +77,28 @@ func Test_InitFlags(t *testing.T) {
 		"--memory-evict-cool-time-seconds=8",
 		"--cpu-evict-cool-time-seconds=40",
 		"--qos-extension-plugins=test-plugin=true",
+		"--cgroup-verify-interval-seconds=30",
+		"--cgroup-verify-sample-ratio=0.5",
+		"--cgroup-verify-max-files-per-cycle=100",
+		"--cgroup-drift-warning-threshold=5",
+		"--memory-locality-repair-interval-seconds=600",
+		"--memory-locality-repair-mode=ExpandMems",
+		"--memory-locality-sample-containers-per-cycle=10",
+		"--memory-locality-repair-containers-per-cycle=2",
+		"--memory-locality-node-cpu-threshold-percent=40",
+		"--memory-locality-expand-seconds=300",
+		"--orphan-artifact-gc-interval-seconds=300",
+		"--orphan-artifact-gc-dry-run=true",
+		"--mba-feedback-interval-seconds=5",
+		"--mba-feedback-window-seconds=600",
+		"--mba-feedback-degrade-percent=20",
+		"--mba-feedback-relax-rounds=5",
+		"--gpu-memory-leak-check-interval-seconds=60",
+		"--gpu-memory-leak-margin-percent=20",
+		"--gpu-memory-leak-consecutive-samples=5",
+		"--pid-protection-interval-seconds=30",
+		"--pid-evict-cool-time-seconds=120",
+		"--net-ingress-protection-interval-seconds=5",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
@@ -60,6 +110,33 @@ func Test_InitFlags(t *testing.T) {
 		MemoryEvictCoolTimeSeconds int
 		CPUEvictCoolTimeSeconds    int
 		QOSExtensionCfg            *plugins.QOSExtensionConfig
+
+		CgroupVerifyIntervalSeconds  int
+		CgroupVerifySampleRatio      float64
+		CgroupVerifyMaxFilesPerCycle int
+		CgroupDriftWarningThreshold  int
+
+		MemoryLocalityRepairIntervalSeconds    int
+		MemoryLocalityRepairMode               string
+		MemoryLocalitySampleContainersPerCycle int
+		MemoryLocalityRepairContainersPerCycle int
+		MemoryLocalityNodeCPUThresholdPercent  int64
+		MemoryLocalityExpandSeconds            int
+
+		OrphanArtifactGCIntervalSeconds int
+		OrphanArtifactGCDryRun          bool
+		MBAFeedbackIntervalSeconds      int
+		MBAFeedbackWindowSeconds        int
+		MBAFeedbackDegradePercent       int64
+		MBAFeedbackRelaxRounds          int
+
+		GPUMemoryLeakCheckIntervalSeconds int
+		GPUMemoryLeakMarginPercent        int64
+		GPUMemoryLeakConsecutiveSamples   int
+		PIDProtectionIntervalSeconds      int
+		PIDEvictCoolTimeSeconds           int
+
+		NetIngressProtectionIntervalSeconds int
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -79,6 +156,33 @@ func Test_InitFlags(t *testing.T) {
 				MemoryEvictCoolTimeSeconds: 8,
 				CPUEvictCoolTimeSeconds:    40,
 				QOSExtensionCfg:            &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{"test-plugin": true}},
+
+				CgroupVerifyIntervalSeconds:  30,
+				CgroupVerifySampleRatio:      0.5,
+				CgroupVerifyMaxFilesPerCycle: 100,
+				CgroupDriftWarningThreshold:  5,
+
+				MemoryLocalityRepairIntervalSeconds:    600,
+				MemoryLocalityRepairMode:               "ExpandMems",
+				MemoryLocalitySampleContainersPerCycle: 10,
+				MemoryLocalityRepairContainersPerCycle: 2,
+				MemoryLocalityNodeCPUThresholdPercent:  40,
+				MemoryLocalityExpandSeconds:            300,
+
+				OrphanArtifactGCIntervalSeconds: 300,
+				OrphanArtifactGCDryRun:          true,
+				MBAFeedbackIntervalSeconds:      5,
+				MBAFeedbackWindowSeconds:        600,
+				MBAFeedbackDegradePercent:       20,
+				MBAFeedbackRelaxRounds:          5,
+
+				GPUMemoryLeakCheckIntervalSeconds: 60,
+				GPUMemoryLeakMarginPercent:        20,
+				GPUMemoryLeakConsecutiveSamples:   5,
+				PIDProtectionIntervalSeconds:      30,
+				PIDEvictCoolTimeSeconds:           120,
+
+				NetIngressProtectionIntervalSeconds: 5,
 			},
 			args: args{fs: fs},
 		},
@@ -93,6 +197,33 @@ func Test_InitFlags(t *testing.T) {
 				MemoryEvictCoolTimeSeconds: tt.fields.MemoryEvictCoolTimeSeconds,
 				CPUEvictCoolTimeSeconds:    tt.fields.CPUEvictCoolTimeSeconds,
 				QOSExtensionCfg:            tt.fields.QOSExtensionCfg,
+
+				CgroupVerifyIntervalSeconds:  tt.fields.CgroupVerifyIntervalSeconds,
+				CgroupVerifySampleRatio:      tt.fields.CgroupVerifySampleRatio,
+				CgroupVerifyMaxFilesPerCycle: tt.fields.CgroupVerifyMaxFilesPerCycle,
+				CgroupDriftWarningThreshold:  tt.fields.CgroupDriftWarningThreshold,
+
+				MemoryLocalityRepairIntervalSeconds:    tt.fields.MemoryLocalityRepairIntervalSeconds,
+				MemoryLocalityRepairMode:               tt.fields.MemoryLocalityRepairMode,
+				MemoryLocalitySampleContainersPerCycle: tt.fields.MemoryLocalitySampleContainersPerCycle,
+				MemoryLocalityRepairContainersPerCycle: tt.fields.MemoryLocalityRepairContainersPerCycle,
+				MemoryLocalityNodeCPUThresholdPercent:  tt.fields.MemoryLocalityNodeCPUThresholdPercent,
+				MemoryLocalityExpandSeconds:            tt.fields.MemoryLocalityExpandSeconds,
+
+				OrphanArtifactGCIntervalSeconds: tt.fields.OrphanArtifactGCIntervalSeconds,
+				OrphanArtifactGCDryRun:          tt.fields.OrphanArtifactGCDryRun,
+				MBAFeedbackIntervalSeconds:      tt.fields.MBAFeedbackIntervalSeconds,
+				MBAFeedbackWindowSeconds:        tt.fields.MBAFeedbackWindowSeconds,
+				MBAFeedbackDegradePercent:       tt.fields.MBAFeedbackDegradePercent,
+				MBAFeedbackRelaxRounds:          tt.fields.MBAFeedbackRelaxRounds,
+
+				GPUMemoryLeakCheckIntervalSeconds: tt.fields.GPUMemoryLeakCheckIntervalSeconds,
+				GPUMemoryLeakMarginPercent:        tt.fields.GPUMemoryLeakMarginPercent,
+				GPUMemoryLeakConsecutiveSamples:   tt.fields.GPUMemoryLeakConsecutiveSamples,
+				PIDProtectionIntervalSeconds:      tt.fields.PIDProtectionIntervalSeconds,
+				PIDEvictCoolTimeSeconds:           tt.fields.PIDEvictCoolTimeSeconds,
+
+				NetIngressProtectionIntervalSeconds: tt.fields.NetIngressProtectionIntervalSeconds,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
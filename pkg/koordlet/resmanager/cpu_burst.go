@@ -28,6 +28,7 @@ import (
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager/configextensions"
@@ -189,7 +190,7 @@ func (b *CPUBurst) start() {
 		return
 	}
 	b.nodeCPUBurstStrategy = nodeSLO.Spec.CPUBurstStrategy
-	podsMeta := b.resmanager.statesInformer.GetAllPods()
+	podsMeta := b.resmanager.getManagedPods(features.CPUBurst)
 
 	// get node state by node share pool usage
 	nodeState := b.getNodeStateForBurst(*b.nodeCPUBurstStrategy.SharePoolThresholdPercent, podsMeta)
@@ -596,7 +597,9 @@ func calcStaticCPUBurstVal(container *corev1.Container, burstCfg *slov1alpha1.CP
 }
 
 // use node config by default, overlap if pod specify config
+// config overwrite: pod annotation > node-level config > container env hints
 func genPodBurstConfig(pod *corev1.Pod, nodeCfg *slov1alpha1.CPUBurstConfig) *slov1alpha1.CPUBurstConfig {
+	nodeCfg = mergeCPUBurstEnvHints(pod, nodeCfg)
 	podCPUBurstCfg, err := apiext.GetPodCPUBurstConfig(pod)
 	if err != nil {
 		klog.Infof("parse pod %s/%s cpu burst config failed, reason %v", pod.Namespace, pod.Name, err)
@@ -18,7 +18,6 @@ package resmanager
 
 import (
 	"fmt"
-	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -178,7 +177,7 @@ func (c *CPUEvictor) killAndEvictBEPodsRelease(node *corev1.Node, bePodInfos []*
 func (c *CPUEvictor) getPodEvictInfoAndSort(beMetric *metriccache.BECPUResourceMetric) []*podEvictCPUInfo {
 	var bePodInfos []*podEvictCPUInfo
 
-	for _, podMeta := range c.resmanager.statesInformer.GetAllPods() {
+	for _, podMeta := range c.resmanager.getManagedPods(features.BECPUEvict) {
 		pod := podMeta.Pod
 		if apiext.GetPodQoSClass(pod) == apiext.QoSBE {
 
@@ -206,12 +205,8 @@ func (c *CPUEvictor) getPodEvictInfoAndSort(beMetric *metriccache.BECPUResourceM
 		}
 	}
 
-	sort.Slice(bePodInfos, func(i, j int) bool {
-		if bePodInfos[i].pod.Spec.Priority == nil || bePodInfos[j].pod.Spec.Priority == nil ||
-			*bePodInfos[i].pod.Spec.Priority == *bePodInfos[j].pod.Spec.Priority {
-			return bePodInfos[i].cpuUsage > bePodInfos[j].cpuUsage
-		}
-		return *bePodInfos[i].pod.Spec.Priority < *bePodInfos[j].pod.Spec.Priority
+	sortEvictionVictims(bePodInfos, func(i int) evictionVictim {
+		return evictionVictim{pod: bePodInfos[i].pod, usage: bePodInfos[i].cpuUsage, usageKnown: true}
 	})
 	return bePodInfos
 }
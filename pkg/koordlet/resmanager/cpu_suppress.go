@@ -153,6 +153,27 @@ func (r *CPUSuppress) calculateBESuppressCPU(node *corev1.Node, nodeMetric *metr
 	return nodeBESuppressCPU
 }
 
+// getTerminatingCPUDiscount returns the part of the CPU usage of the terminating LS pods not counted as the LS usage
+// by the CPUSuppressTerminatingUsagePercent, zero if the usage is counted fully.
+func (r *CPUSuppress) getTerminatingCPUDiscount(strategy *slov1alpha1.ResourceThresholdStrategy) *resource.Quantity {
+	discount := resource.NewMilliQuantity(0, resource.DecimalSI)
+	if strategy == nil || strategy.CPUSuppressTerminatingUsagePercent == nil || *strategy.CPUSuppressTerminatingUsagePercent >= 100 {
+		return discount
+	}
+	queryParam := generateQueryParamsLast(r.resmanager.collectResUsedIntervalSeconds * 2)
+	queryResult := r.resmanager.metricCache.GetTerminatingPodsResourceMetric(queryParam)
+	if queryResult.Error != nil || queryResult.Metric == nil {
+		klog.V(5).Infof("get terminating pods resource metric failed, error %v", queryResult.Error)
+		return discount
+	}
+	percent := *strategy.CPUSuppressTerminatingUsagePercent
+	if percent < 0 {
+		percent = 0
+	}
+	discount.SetMilli(queryResult.Metric.CPUUsed.MilliValue() * (100 - percent) / 100)
+	return discount
+}
+
 func (r *CPUSuppress) applyBESuppressCPUSet(beCPUSet []int32, oldCPUSet []int32) error {
 	nodeTopo := r.resmanager.statesInformer.GetNodeTopo()
 	if nodeTopo == nil {
@@ -269,6 +290,12 @@ func (r *CPUSuppress) suppressBECPU() {
 
 	suppressCPUQuantity := r.calculateBESuppressCPU(node, nodeMetric, podMetrics, podMetas,
 		*nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressThresholdPercent)
+	if discount := r.getTerminatingCPUDiscount(nodeSLO.Spec.ResourceUsedThresholdWithBE); !discount.IsZero() {
+		// the usage the terminating LS pods are about to release is lent to BE
+		suppressCPUQuantity.Add(*discount)
+		klog.V(4).Infof("suppressBECPU discounts the terminating LS pods usage %v, nodeSuppressBE[CPU(Core)]:%v",
+			discount, suppressCPUQuantity.Value())
+	}
 
 	// Step 2.
 	nodeCPUInfo, err := r.resmanager.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
@@ -18,6 +18,7 @@ package resmanager
 
 import (
 	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"strconv"
 	"testing"
@@ -891,6 +892,88 @@ func Test_cpuSuppress_calculateBESuppressCPU(t *testing.T) {
 	}
 }
 
+func Test_cpuSuppress_getTerminatingCPUDiscount(t *testing.T) {
+	// a long-terminating LS pod still uses 4 cores before its cgroups disappear
+	terminatingMetric := &metriccache.TerminatingPodsResourceMetric{
+		CPUUsed:    *resource.NewQuantity(4, resource.DecimalSI),
+		MemoryUsed: *resource.NewQuantity(4<<30, resource.BinarySI),
+	}
+	tests := []struct {
+		name          string
+		strategy      *slov1alpha1.ResourceThresholdStrategy
+		queryResult   *metriccache.TerminatingPodsResourceQueryResult
+		wantMilliCPU  int64
+		wantQueryOnce bool
+	}{
+		{
+			name:         "nil strategy counts the terminating usage fully",
+			strategy:     nil,
+			wantMilliCPU: 0,
+		},
+		{
+			name:         "unset percent counts the terminating usage fully",
+			strategy:     &slov1alpha1.ResourceThresholdStrategy{},
+			wantMilliCPU: 0,
+		},
+		{
+			name: "100 percent counts the terminating usage fully",
+			strategy: &slov1alpha1.ResourceThresholdStrategy{
+				CPUSuppressTerminatingUsagePercent: pointer.Int64(100),
+			},
+			wantMilliCPU: 0,
+		},
+		{
+			name: "50 percent lends half of the terminating usage to BE",
+			strategy: &slov1alpha1.ResourceThresholdStrategy{
+				CPUSuppressTerminatingUsagePercent: pointer.Int64(50),
+			},
+			queryResult:   &metriccache.TerminatingPodsResourceQueryResult{Metric: terminatingMetric},
+			wantMilliCPU:  2000,
+			wantQueryOnce: true,
+		},
+		{
+			name: "0 percent lends all of the terminating usage to BE",
+			strategy: &slov1alpha1.ResourceThresholdStrategy{
+				CPUSuppressTerminatingUsagePercent: pointer.Int64(0),
+			},
+			queryResult:   &metriccache.TerminatingPodsResourceQueryResult{Metric: terminatingMetric},
+			wantMilliCPU:  4000,
+			wantQueryOnce: true,
+		},
+		{
+			name: "no discount when the terminating usage is missing",
+			strategy: &slov1alpha1.ResourceThresholdStrategy{
+				CPUSuppressTerminatingUsagePercent: pointer.Int64(0),
+			},
+			queryResult: &metriccache.TerminatingPodsResourceQueryResult{
+				QueryResult: metriccache.QueryResult{Error: fmt.Errorf("no metric")},
+			},
+			wantMilliCPU:  0,
+			wantQueryOnce: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockMetricCache := mockmetriccache.NewMockMetricCache(ctl)
+			if tt.wantQueryOnce {
+				mockMetricCache.EXPECT().GetTerminatingPodsResourceMetric(gomock.Any()).Return(*tt.queryResult).Times(1)
+			}
+			r := &resmanager{
+				metricCache:                   mockMetricCache,
+				config:                        NewDefaultConfig(),
+				collectResUsedIntervalSeconds: 1,
+			}
+			cpuSuppress := newTestCPUSuppress(r)
+
+			got := cpuSuppress.getTerminatingCPUDiscount(tt.strategy)
+			assert.Equal(t, tt.wantMilliCPU, got.MilliValue())
+		})
+	}
+}
+
 func Test_cpuSuppress_recoverCPUSetIfNeed(t *testing.T) {
 	type args struct {
 		oldCPUSets          string
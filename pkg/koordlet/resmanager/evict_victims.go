@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// evictionVictim is a pod to evict along with its resource usage considered by the evictor,
+// i.e. the memory usage for memory evict and the cpu usage for cpu evict.
+type evictionVictim struct {
+	pod        *corev1.Pod
+	usage      float64
+	usageKnown bool
+}
+
+// sortEvictionVictims sorts the pods in the order to evict, where victim(i) describes the i-th element of pods.
+// The victims are compared by:
+//  1. the priority band, the pods in the lower band first. The band is the koordinator priority class of the pod,
+//     e.g. koord-free is lower than koord-batch; for the pod without a koordinator priority class it is the priority.
+//  2. the pod deletion cost (controller.kubernetes.io/pod-deletion-cost), the pods with the lower cost first.
+//  3. the resource usage, the pods using more first, and the pods of unknown usage after the others.
+//  4. the start time, the pods started later first.
+func sortEvictionVictims(pods interface{}, victim func(i int) evictionVictim) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		return victim(i).evictBefore(victim(j))
+	})
+}
+
+func (v evictionVictim) evictBefore(o evictionVictim) bool {
+	if band, otherBand := getPriorityBand(v.pod), getPriorityBand(o.pod); band != otherBand {
+		return band < otherBand
+	}
+	if cost, otherCost := getPodDeletionCost(v.pod), getPodDeletionCost(o.pod); cost != otherCost {
+		return cost < otherCost
+	}
+	if v.usageKnown != o.usageKnown {
+		return v.usageKnown
+	}
+	if v.usage != o.usage {
+		return v.usage > o.usage
+	}
+	startTime, otherStartTime := v.pod.Status.StartTime, o.pod.Status.StartTime
+	if startTime == nil || otherStartTime == nil {
+		// the pod not started yet is evicted first
+		return startTime == nil && otherStartTime != nil
+	}
+	return otherStartTime.Before(startTime)
+}
+
+// getPriorityBand returns the lowest priority of the koordinator priority class of the pod,
+// or the priority of the pod if it has no koordinator priority class.
+func getPriorityBand(pod *corev1.Pod) int32 {
+	switch apiext.GetPriorityClass(pod) {
+	case apiext.PriorityProd:
+		return apiext.PriorityProdValueMin
+	case apiext.PriorityMid:
+		return apiext.PriorityMidValueMin
+	case apiext.PriorityBatch:
+		return apiext.PriorityBatchValueMin
+	case apiext.PriorityFree:
+		return apiext.PriorityFreeValueMin
+	}
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// getPodDeletionCost returns the deletion cost of the pod, 0 if it is unset or invalid.
+func getPodDeletionCost(pod *corev1.Pod) int32 {
+	s, ok := pod.Annotations[corev1.PodDeletionCost]
+	if !ok {
+		return 0
+	}
+	cost, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(cost)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+type testEvictionVictim struct {
+	name         string
+	priority     *int32
+	deletionCost string
+	usage        *float64
+	startTime    *time.Time
+}
+
+func (v testEvictionVictim) victim() evictionVictim {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: v.name},
+		Spec:       corev1.PodSpec{Priority: v.priority},
+	}
+	if v.deletionCost != "" {
+		pod.Annotations = map[string]string{corev1.PodDeletionCost: v.deletionCost}
+	}
+	if v.startTime != nil {
+		pod.Status.StartTime = &metav1.Time{Time: *v.startTime}
+	}
+	victim := evictionVictim{pod: pod}
+	if v.usage != nil {
+		victim.usage = *v.usage
+		victim.usageKnown = true
+	}
+	return victim
+}
+
+func Test_sortEvictionVictims(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		victims []testEvictionVictim
+		want    []string
+	}{
+		{
+			name: "lower priority band first",
+			victims: []testEvictionVictim{
+				{name: "batch", priority: pointer.Int32(5000), deletionCost: "-100"},
+				{name: "free", priority: pointer.Int32(3999), deletionCost: "100"},
+				{name: "none", priority: pointer.Int32(100)},
+			},
+			want: []string{"none", "free", "batch"},
+		},
+		{
+			name: "same band, lower deletion cost first regardless of the priority",
+			victims: []testEvictionVictim{
+				{name: "batch-5999-cost-0", priority: pointer.Int32(5999), usage: pointer.Float64(1)},
+				{name: "batch-5000-cost-10", priority: pointer.Int32(5000), deletionCost: "10", usage: pointer.Float64(2)},
+				{name: "batch-5500-cost-minus-10", priority: pointer.Int32(5500), deletionCost: "-10"},
+			},
+			want: []string{"batch-5500-cost-minus-10", "batch-5999-cost-0", "batch-5000-cost-10"},
+		},
+		{
+			name: "invalid deletion cost is zero",
+			victims: []testEvictionVictim{
+				{name: "cost-1", priority: pointer.Int32(5000), deletionCost: "1"},
+				{name: "cost-invalid", priority: pointer.Int32(5000), deletionCost: "expensive", usage: pointer.Float64(1)},
+			},
+			want: []string{"cost-invalid", "cost-1"},
+		},
+		{
+			name: "same deletion cost, more usage first and unknown usage last",
+			victims: []testEvictionVictim{
+				{name: "unknown", priority: pointer.Int32(5000), deletionCost: "10"},
+				{name: "usage-1", priority: pointer.Int32(5001), deletionCost: "10", usage: pointer.Float64(1)},
+				{name: "usage-2", priority: pointer.Int32(5002), deletionCost: "10", usage: pointer.Float64(2)},
+			},
+			want: []string{"usage-2", "usage-1", "unknown"},
+		},
+		{
+			name: "same usage, later started first and not started first of all",
+			victims: []testEvictionVictim{
+				{name: "earlier", priority: pointer.Int32(5000), usage: pointer.Float64(1), startTime: &earlier},
+				{name: "later", priority: pointer.Int32(5000), usage: pointer.Float64(1), startTime: &now},
+				{name: "not-started", priority: pointer.Int32(5000), usage: pointer.Float64(1)},
+			},
+			want: []string{"not-started", "later", "earlier"},
+		},
+		{
+			name: "all tied, keep the original order",
+			victims: []testEvictionVictim{
+				{name: "a", priority: pointer.Int32(5000), usage: pointer.Float64(1), startTime: &now},
+				{name: "b", priority: pointer.Int32(5000), usage: pointer.Float64(1), startTime: &now},
+			},
+			want: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			victims := make([]evictionVictim, len(tt.victims))
+			for i := range tt.victims {
+				victims[i] = tt.victims[i].victim()
+			}
+			sortEvictionVictims(victims, func(i int) evictionVictim {
+				return victims[i]
+			})
+			var got []string
+			for _, victim := range victims {
+				got = append(got, victim.pod.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+)
+
+const (
+	gpuMemoryOverused = "GPUMemoryOverused"
+)
+
+// gpuAllocatedShare is the GPU memory allocated to a pod on a device, either by the bytes or by the ratio of the total.
+type gpuAllocatedShare struct {
+	memory *resource.Quantity
+	ratio  *resource.Quantity
+}
+
+// memoryBytes returns the allocated bytes on the device with the total memory.
+func (s *gpuAllocatedShare) memoryBytes(memoryTotal int64) int64 {
+	if s.memory != nil {
+		return s.memory.Value()
+	}
+	if s.ratio != nil {
+		return memoryTotal * s.ratio.Value() / 100
+	}
+	return 0
+}
+
+// gpuMemoryUsage is the GPU memory used on a device by the containers of a pod.
+type gpuMemoryUsage struct {
+	memoryTotal int64
+	used        int64
+	// containerUsed is the memory used by each container, by the container name
+	containerUsed map[string]int64
+}
+
+// GPUMemoryLeakDetector compares the GPU memory used by the containers of each pod on each device with the share
+// allocated to the pod, and raises an event on the pod overusing its share for consecutive samples, so the leaked
+// GPU memory can be attributed to the containers before the device runs out of memory.
+type GPUMemoryLeakDetector struct {
+	resmanager *resmanager
+	// overusedCounts is the consecutive overused samples of each pod, by the pod uid
+	overusedCounts map[string]int
+}
+
+func NewGPUMemoryLeakDetector(resmanager *resmanager) *GPUMemoryLeakDetector {
+	return &GPUMemoryLeakDetector{
+		resmanager:     resmanager,
+		overusedCounts: map[string]int{},
+	}
+}
+
+func (d *GPUMemoryLeakDetector) detect() {
+	cfg := d.resmanager.config
+	alivePods := map[string]struct{}{}
+	for _, podMeta := range d.resmanager.statesInformer.GetAllPods() {
+		pod := podMeta.Pod
+		if pod == nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		shares, err := getPodGPUAllocatedShares(pod)
+		if err != nil {
+			klog.V(4).Infof("failed to get gpu allocation of pod %s/%s, error: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if len(shares) <= 0 {
+			continue
+		}
+		podUID := string(pod.UID)
+		alivePods[podUID] = struct{}{}
+
+		usages := d.getPodGPUMemoryUsages(pod)
+		if usages == nil {
+			// no sample collected, keep the count
+			continue
+		}
+		overusedMessages := getGPUMemoryOverusedMessages(shares, usages, cfg.GPUMemoryLeakMarginPercent)
+		if len(overusedMessages) <= 0 {
+			delete(d.overusedCounts, podUID)
+			continue
+		}
+
+		d.overusedCounts[podUID]++
+		count := d.overusedCounts[podUID]
+		klog.V(5).Infof("pod %s/%s overuses gpu memory for %v samples, %s",
+			pod.Namespace, pod.Name, count, strings.Join(overusedMessages, "; "))
+		if count != cfg.GPUMemoryLeakConsecutiveSamples {
+			continue
+		}
+		d.resmanager.eventRecorder.Eventf(pod, corev1.EventTypeWarning, gpuMemoryOverused,
+			"gpu memory exceeds the allocated share by more than %v%% for %v consecutive samples, %s",
+			cfg.GPUMemoryLeakMarginPercent, count, strings.Join(overusedMessages, "; "))
+		klog.Infof("pod %s/%s overuses gpu memory for %v consecutive samples, %s",
+			pod.Namespace, pod.Name, count, strings.Join(overusedMessages, "; "))
+	}
+
+	for podUID := range d.overusedCounts {
+		if _, ok := alivePods[podUID]; !ok {
+			delete(d.overusedCounts, podUID)
+		}
+	}
+}
+
+// getPodGPUMemoryUsages returns the latest GPU memory usages of the pod's containers on each device, by the minor.
+// It returns nil if none of the containers has the metric.
+func (d *GPUMemoryLeakDetector) getPodGPUMemoryUsages(pod *corev1.Pod) map[int32]*gpuMemoryUsage {
+	now := time.Now()
+	start := now.Add(-time.Duration(d.resmanager.config.GPUMemoryLeakCheckIntervalSeconds) * time.Second)
+	queryParam := &metriccache.QueryParam{Aggregate: metriccache.AggregationTypeLast, Start: &start, End: &now}
+
+	var usages map[int32]*gpuMemoryUsage
+	for i := range pod.Status.ContainerStatuses {
+		containerStatus := &pod.Status.ContainerStatuses[i]
+		if containerStatus.ContainerID == "" || containerStatus.State.Running == nil {
+			continue
+		}
+		result := d.resmanager.metricCache.GetContainerResourceMetric(&containerStatus.ContainerID, queryParam)
+		if result.Error != nil || result.Metric == nil {
+			klog.V(5).Infof("failed to get resource metric of container %s/%s/%s, error: %v",
+				pod.Namespace, pod.Name, containerStatus.Name, result.Error)
+			continue
+		}
+		if usages == nil {
+			usages = map[int32]*gpuMemoryUsage{}
+		}
+		for _, gpu := range result.Metric.GPUs {
+			usage, ok := usages[gpu.Minor]
+			if !ok {
+				usage = &gpuMemoryUsage{memoryTotal: gpu.MemoryTotal.Value(), containerUsed: map[string]int64{}}
+				usages[gpu.Minor] = usage
+			}
+			usage.used += gpu.MemoryUsed.Value()
+			usage.containerUsed[containerStatus.Name] += gpu.MemoryUsed.Value()
+		}
+	}
+	return usages
+}
+
+// getPodGPUAllocatedShares returns the GPU memory shares allocated to the pod in the device allocation annotation,
+// by the minor.
+func getPodGPUAllocatedShares(pod *corev1.Pod) (map[int32]*gpuAllocatedShare, error) {
+	allocations, err := extension.GetDeviceAllocations(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	shares := map[int32]*gpuAllocatedShare{}
+	for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+		if allocation == nil {
+			continue
+		}
+		share := &gpuAllocatedShare{}
+		if memory, ok := allocation.Resources[extension.GPUMemory]; ok {
+			share.memory = &memory
+		} else if ratio, ok := allocation.Resources[extension.GPUMemoryRatio]; ok {
+			share.ratio = &ratio
+		} else {
+			continue
+		}
+		shares[allocation.Minor] = share
+	}
+	return shares, nil
+}
+
+// getGPUMemoryOverusedMessages returns the messages of the devices on which the usage exceeds the allocated share by
+// more than the margin. The memory used on the devices not allocated to the pod is always overused.
+func getGPUMemoryOverusedMessages(shares map[int32]*gpuAllocatedShare, usages map[int32]*gpuMemoryUsage, marginPercent int64) []string {
+	minors := make([]int32, 0, len(usages))
+	for minor := range usages {
+		minors = append(minors, minor)
+	}
+	sort.Slice(minors, func(i, j int) bool {
+		return minors[i] < minors[j]
+	})
+
+	var messages []string
+	for _, minor := range minors {
+		usage := usages[minor]
+		var allocated int64
+		if share, ok := shares[minor]; ok {
+			allocated = share.memoryBytes(usage.memoryTotal)
+		}
+		if usage.used*100 <= allocated*(100+marginPercent) {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("gpu %v used %s, allocated %s, containers: %s", minor,
+			resource.NewQuantity(usage.used, resource.BinarySI).String(),
+			resource.NewQuantity(allocated, resource.BinarySI).String(),
+			formatContainerGPUMemoryUsed(usage.containerUsed)))
+	}
+	return messages
+}
+
+func formatContainerGPUMemoryUsed(containerUsed map[string]int64) string {
+	names := make([]string, 0, len(containerUsed))
+	for name := range containerUsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]string, 0, len(names))
+	for _, name := range names {
+		items = append(items, fmt.Sprintf("%s=%s", name, resource.NewQuantity(containerUsed[name], resource.BinarySI).String()))
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func newGPUTestPod(t *testing.T, name string, allocations extension.DeviceAllocations, containerNames ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("uid-" + name),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, containerName := range containerNames {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:        containerName,
+			ContainerID: "containerd://" + name + "-" + containerName,
+			State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		})
+	}
+	assert.NoError(t, extension.SetDeviceAllocations(pod, allocations))
+	return pod
+}
+
+func newContainerGPUMetric(minor int32, used, total int64) metriccache.ContainerResourceQueryResult {
+	return metriccache.ContainerResourceQueryResult{
+		Metric: &metriccache.ContainerResourceMetric{
+			GPUs: []metriccache.GPUMetric{
+				{
+					Minor:       minor,
+					MemoryUsed:  *resource.NewQuantity(used, resource.BinarySI),
+					MemoryTotal: *resource.NewQuantity(total, resource.BinarySI),
+				},
+			},
+		},
+	}
+}
+
+func TestGPUMemoryLeakDetector_detect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	statesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+	metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+
+	// pod-a is allocated 4Gi on gpu 0, pod-b is allocated 50% of gpu 1
+	podA := newGPUTestPod(t, "pod-a", extension.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 0, Resources: corev1.ResourceList{extension.GPUMemory: resource.MustParse("4Gi")}},
+		},
+	}, "main", "sidecar")
+	podB := newGPUTestPod(t, "pod-b", extension.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 1, Resources: corev1.ResourceList{extension.GPUMemoryRatio: resource.MustParse("50")}},
+		},
+	}, "main")
+	podNoGPU := newGPUTestPod(t, "pod-no-gpu", nil, "main")
+
+	pods := []*statesinformer.PodMeta{{Pod: podA}, {Pod: podB}, {Pod: podNoGPU}}
+	statesInformer.EXPECT().GetAllPods().DoAndReturn(func() []*statesinformer.PodMeta {
+		return pods
+	}).AnyTimes()
+
+	gi := int64(1024 * 1024 * 1024)
+	metrics := map[string]metriccache.ContainerResourceQueryResult{}
+	metricCache.EXPECT().GetContainerResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(containerID *string, param *metriccache.QueryParam) metriccache.ContainerResourceQueryResult {
+			if result, ok := metrics[*containerID]; ok {
+				return result
+			}
+			return metriccache.ContainerResourceQueryResult{}
+		}).AnyTimes()
+
+	fakeRecorder := &FakeRecorder{}
+	cfg := NewDefaultConfig()
+	cfg.GPUMemoryLeakMarginPercent = 10
+	cfg.GPUMemoryLeakConsecutiveSamples = 2
+	r := &resmanager{
+		config:         cfg,
+		statesInformer: statesInformer,
+		metricCache:    metricCache,
+		eventRecorder:  fakeRecorder,
+	}
+	d := NewGPUMemoryLeakDetector(r)
+
+	// within the margin
+	metrics["containerd://pod-a-main"] = newContainerGPUMetric(0, 3*gi, 16*gi)
+	metrics["containerd://pod-a-sidecar"] = newContainerGPUMetric(0, gi+gi/20, 16*gi)
+	metrics["containerd://pod-b-main"] = newContainerGPUMetric(1, 8*gi, 16*gi)
+	d.detect()
+	assert.Equal(t, "", fakeRecorder.eventReason)
+	assert.Empty(t, d.overusedCounts)
+
+	// the sidecar of pod-a leaks, and pod-b exceeds its ratio
+	metrics["containerd://pod-a-sidecar"] = newContainerGPUMetric(0, 3*gi, 16*gi)
+	metrics["containerd://pod-b-main"] = newContainerGPUMetric(1, 10*gi, 16*gi)
+	d.detect()
+	assert.Equal(t, "", fakeRecorder.eventReason)
+	assert.Equal(t, map[string]int{"uid-pod-a": 1, "uid-pod-b": 1}, d.overusedCounts)
+
+	// pod-b recovers, and the missing metric of pod-a keeps the count
+	delete(metrics, "containerd://pod-a-main")
+	delete(metrics, "containerd://pod-a-sidecar")
+	metrics["containerd://pod-b-main"] = newContainerGPUMetric(1, 8*gi, 16*gi)
+	d.detect()
+	assert.Equal(t, "", fakeRecorder.eventReason)
+	assert.Equal(t, map[string]int{"uid-pod-a": 1}, d.overusedCounts)
+
+	// pod-a overuses for the consecutive samples
+	metrics["containerd://pod-a-main"] = newContainerGPUMetric(0, 3*gi, 16*gi)
+	metrics["containerd://pod-a-sidecar"] = newContainerGPUMetric(0, 3*gi, 16*gi)
+	d.detect()
+	assert.Equal(t, gpuMemoryOverused, fakeRecorder.eventReason)
+	assert.Equal(t, map[string]int{"uid-pod-a": 2}, d.overusedCounts)
+
+	// the count of the deleted pod is dropped
+	pods = []*statesinformer.PodMeta{{Pod: podB}}
+	d.detect()
+	assert.Empty(t, d.overusedCounts)
+}
+
+func Test_getGPUMemoryOverusedMessages(t *testing.T) {
+	memory := resource.MustParse("1Ki")
+	shares := map[int32]*gpuAllocatedShare{
+		0: {memory: &memory},
+	}
+	usages := map[int32]*gpuMemoryUsage{
+		0: {memoryTotal: 4096, used: 1024, containerUsed: map[string]int64{"main": 1024}},
+		1: {memoryTotal: 4096, used: 2048, containerUsed: map[string]int64{"main": 1024, "init": 1024}},
+	}
+	got := getGPUMemoryOverusedMessages(shares, usages, 10)
+	assert.Equal(t, []string{"gpu 1 used 2Ki, allocated 0, containers: init=1Ki,main=1Ki"}, got)
+}
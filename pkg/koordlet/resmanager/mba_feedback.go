@@ -0,0 +1,294 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	// mbaFeedbackStepPercent is the MBA percent tightened or relaxed in one round, which is the granularity of MBA.
+	mbaFeedbackStepPercent int64 = 10
+	// mbaFeedbackDefaultMinPercent is the lower bound of the MBA percent if the NodeSLO does not specify.
+	mbaFeedbackDefaultMinPercent int64 = 10
+	// mbaFeedbackDefaultMaxPercent is the upper bound of the MBA percent if the NodeSLO does not specify.
+	mbaFeedbackDefaultMaxPercent int64 = 100
+)
+
+// mbaFeedbackSignals are the signals of the LS and BE resctrl groups in the last round and the baseline window.
+type mbaFeedbackSignals struct {
+	// lsBandwidth and lsBaselineBandwidth are the memory bandwidth of the LSR and LS groups in bytes per second.
+	lsBandwidth         float64
+	lsBaselineBandwidth float64
+	// lsCPI and lsBaselineCPI are the CPI of the LSR and LS pods, zero if the CPI is not collected.
+	lsCPI         float64
+	lsBaselineCPI float64
+	// beBandwidth is the memory bandwidth of the BE group in bytes per second.
+	beBandwidth float64
+}
+
+// isLSDegraded returns whether the LS bandwidth drops or the LS CPI rises beyond the ratio against the baseline while
+// the BE pods are consuming the memory bandwidth.
+func (s *mbaFeedbackSignals) isLSDegraded(degradeRatio float64) bool {
+	if s.beBandwidth <= 0 {
+		return false
+	}
+	if s.lsBaselineBandwidth > 0 && s.lsBandwidth < s.lsBaselineBandwidth*(1-degradeRatio) {
+		return true
+	}
+	return s.lsBaselineCPI > 0 && s.lsCPI > s.lsBaselineCPI*(1+degradeRatio)
+}
+
+// MBAFeedback adjusts the MBA percent of the BE resctrl group with the memory bandwidth measured by the MBM counters.
+// It tightens the BE group by a step when the memory bandwidth or the CPI of the LS pods degrades, and relaxes it by a
+// step when the LS pods keep healthy for several rounds, bounded by the MBAMinPercent and MBAMaxPercent of NodeSLO.
+// NOTE: The MBA of the BE group is no longer reconciled with the static MBAPercent when the feedback is enabled.
+type MBAFeedback struct {
+	resmanager *resmanager
+	executor   resourceexecutor.ResourceUpdateExecutor
+	// currentPercent is the MBA percent of the BE group applied by the feedback, zero if not initialized.
+	currentPercent int64
+	// healthyRounds is the number of consecutive rounds in which the LS pods are not degraded.
+	healthyRounds int
+}
+
+func NewMBAFeedback(resmanager *resmanager) *MBAFeedback {
+	return &MBAFeedback{
+		resmanager: resmanager,
+		executor:   resourceexecutor.NewResourceUpdateExecutor(),
+	}
+}
+
+func (m *MBAFeedback) RunInit(stopCh <-chan struct{}) error {
+	m.executor.Run(stopCh)
+	return nil
+}
+
+// getMBAFeedbackBounds returns the bounds of the MBA percent of the BE group.
+func getMBAFeedbackBounds(resctrlQoS *slov1alpha1.ResctrlQOS) (int64, int64, error) {
+	minPercent, maxPercent := mbaFeedbackDefaultMinPercent, mbaFeedbackDefaultMaxPercent
+	if resctrlQoS.MBAMinPercent != nil {
+		minPercent = *resctrlQoS.MBAMinPercent
+	}
+	if resctrlQoS.MBAMaxPercent != nil {
+		maxPercent = *resctrlQoS.MBAMaxPercent
+	}
+	if minPercent <= 0 || maxPercent > 100 || minPercent > maxPercent {
+		return 0, 0, fmt.Errorf("invalid MBA bounds, min %d, max %d", minPercent, maxPercent)
+	}
+	return minPercent, maxPercent, nil
+}
+
+func boundMBAPercent(percent, minPercent, maxPercent int64) int64 {
+	if percent < minPercent {
+		return minPercent
+	}
+	if percent > maxPercent {
+		return maxPercent
+	}
+	return percent
+}
+
+// adjust returns the next MBA percent of the BE group according to the signals.
+func (m *MBAFeedback) adjust(signals *mbaFeedbackSignals, minPercent, maxPercent int64) int64 {
+	percent := m.currentPercent
+	degradeRatio := float64(m.resmanager.config.MBAFeedbackDegradePercent) / 100
+	if signals.isLSDegraded(degradeRatio) {
+		m.healthyRounds = 0
+		percent -= mbaFeedbackStepPercent
+	} else {
+		m.healthyRounds++
+		if m.healthyRounds >= m.resmanager.config.MBAFeedbackRelaxRounds {
+			m.healthyRounds = 0
+			percent += mbaFeedbackStepPercent
+		}
+	}
+	return boundMBAPercent(percent, minPercent, maxPercent)
+}
+
+func (m *MBAFeedback) feedback() {
+	nodeSLO := m.resmanager.getNodeSLOCopy()
+	if nodeSLO == nil || nodeSLO.Spec.ResourceQOSStrategy == nil || nodeSLO.Spec.ResourceQOSStrategy.BEClass == nil ||
+		nodeSLO.Spec.ResourceQOSStrategy.BEClass.ResctrlQOS == nil {
+		klog.V(5).Infof("MBAFeedback skipped, the resctrl qos of BE is not configured")
+		return
+	}
+	// skip if host not support resctrl
+	if support, err := system.IsSupportResctrl(); err != nil {
+		klog.Warningf("check support resctrl failed, err: %s", err)
+		return
+	} else if !support {
+		klog.V(5).Infof("MBAFeedback skipped, cpu not support CAT/MBA")
+		return
+	}
+
+	m.reconcileBEMBA(&nodeSLO.Spec.ResourceQOSStrategy.BEClass.ResctrlQOS.ResctrlQOS)
+}
+
+// reconcileBEMBA adjusts the MBA percent of the BE group with the signals and applies it.
+func (m *MBAFeedback) reconcileBEMBA(resctrlQoS *slov1alpha1.ResctrlQOS) {
+	minPercent, maxPercent, err := getMBAFeedbackBounds(resctrlQoS)
+	if err != nil {
+		klog.Warningf("MBAFeedback skipped, err: %v", err)
+		return
+	}
+
+	signals, err := m.getSignals()
+	if err != nil {
+		klog.V(4).Infof("MBAFeedback skipped, failed to get the signals, err: %v", err)
+		return
+	}
+
+	if m.currentPercent <= 0 {
+		// start from the static MBA percent
+		m.currentPercent = maxPercent
+		if resctrlQoS.MBAPercent != nil {
+			m.currentPercent = *resctrlQoS.MBAPercent
+		}
+		m.currentPercent = boundMBAPercent(m.currentPercent, minPercent, maxPercent)
+	} else {
+		m.currentPercent = m.adjust(signals, minPercent, maxPercent)
+	}
+	klog.V(5).Infof("MBAFeedback adjusts the MBA percent of BE to %d, signals %+v", m.currentPercent, *signals)
+
+	if err = m.applyMBAPercent(m.currentPercent); err != nil {
+		klog.Warningf("MBAFeedback failed to apply the MBA percent %d, err: %v", m.currentPercent, err)
+		return
+	}
+	metrics.RecordBEMBAFeedbackPercent(float64(m.currentPercent))
+}
+
+// getSignals gets the signals from the memory bandwidth and CPI metrics. It fails if no memory bandwidth is collected.
+func (m *MBAFeedback) getSignals() (*mbaFeedbackSignals, error) {
+	intervalSeconds := int64(m.resmanager.config.MBAFeedbackIntervalSeconds)
+	windowSeconds := int64(m.resmanager.config.MBAFeedbackWindowSeconds)
+	recentParam := generateQueryParamsAvg(intervalSeconds)
+	baselineParam := generateQueryParamsAvg(windowSeconds)
+
+	signals := &mbaFeedbackSignals{}
+	found := false
+	for _, group := range []string{LSRResctrlGroup, LSResctrlGroup} {
+		if bandwidth, ok := m.getGroupBandwidth(group, recentParam); ok {
+			signals.lsBandwidth += bandwidth
+			found = true
+		}
+		if bandwidth, ok := m.getGroupBandwidth(group, baselineParam); ok {
+			signals.lsBaselineBandwidth += bandwidth
+		}
+	}
+	if bandwidth, ok := m.getGroupBandwidth(BEResctrlGroup, recentParam); ok {
+		signals.beBandwidth = bandwidth
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("memory bandwidth not collected in the last %d seconds", intervalSeconds)
+	}
+
+	signals.lsCPI, signals.lsBaselineCPI = m.getLSCPI(recentParam, baselineParam)
+	return signals, nil
+}
+
+func (m *MBAFeedback) getGroupBandwidth(group string, param *metriccache.QueryParam) (float64, bool) {
+	result := m.resmanager.metricCache.GetResctrlMemBandwidthMetric(&group, param)
+	if result.Error != nil || result.Metric == nil {
+		klog.V(6).Infof("failed to get memory bandwidth of resctrl group %s, err: %v", group, result.Error)
+		return 0, false
+	}
+	return result.Metric.TotalBandwidth(), true
+}
+
+// getLSCPI returns the CPI of all the LSR and LS pods in the recent and the baseline windows.
+func (m *MBAFeedback) getLSCPI(recentParam, baselineParam *metriccache.QueryParam) (float64, float64) {
+	var recent, baseline metriccache.CPIMetric
+	for _, podMeta := range m.resmanager.statesInformer.GetAllPods() {
+		pod := podMeta.Pod
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if group := getPodResctrlGroup(pod); group != LSRResctrlGroup && group != LSResctrlGroup {
+			continue
+		}
+		podUID := string(pod.UID)
+		if cpi := m.getPodCPI(&podUID, recentParam); cpi != nil {
+			recent.Cycles += cpi.Cycles
+			recent.Instructions += cpi.Instructions
+		}
+		if cpi := m.getPodCPI(&podUID, baselineParam); cpi != nil {
+			baseline.Cycles += cpi.Cycles
+			baseline.Instructions += cpi.Instructions
+		}
+	}
+	return calculateCPI(&recent), calculateCPI(&baseline)
+}
+
+func (m *MBAFeedback) getPodCPI(podUID *string, param *metriccache.QueryParam) *metriccache.CPIMetric {
+	result := m.resmanager.metricCache.GetPodInterferenceMetric(metriccache.MetricNamePodCPI, podUID, param)
+	if result.Error != nil || result.Metric == nil {
+		return nil
+	}
+	cpi, ok := result.Metric.MetricValue.(*metriccache.CPIMetric)
+	if !ok {
+		return nil
+	}
+	return cpi
+}
+
+func calculateCPI(cpi *metriccache.CPIMetric) float64 {
+	if cpi.Instructions <= 0 {
+		return 0
+	}
+	return float64(cpi.Cycles) / float64(cpi.Instructions)
+}
+
+func (m *MBAFeedback) applyMBAPercent(percent int64) error {
+	nodeCPUInfo, err := m.resmanager.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
+	if err != nil {
+		return fmt.Errorf("failed to get nodeCPUInfo, err: %v", err)
+	}
+	if nodeCPUInfo == nil {
+		return fmt.Errorf("failed to get nodeCPUInfo, the value is nil")
+	}
+	l3Num := int(nodeCPUInfo.TotalInfo.NumberL3s)
+	if l3Num <= 0 {
+		return fmt.Errorf("invalid number of l3 caches %v", l3Num)
+	}
+	if err = initCatGroupIfNotExist(BEResctrlGroup); err != nil {
+		return err
+	}
+
+	memBwPercent := calculateMbaPercentForGroup(BEResctrlGroup, &percent)
+	if memBwPercent == "" {
+		return fmt.Errorf("invalid MBA percent %d", percent)
+	}
+	resource := resourceexecutor.NewResctrlMbSchemataResource(BEResctrlGroup, memBwPercent, l3Num)
+	isUpdated, err := m.executor.Update(true, resource)
+	if err != nil {
+		return err
+	}
+	klog.V(5).Infof("apply mba policy for group %s by feedback finished, schemata %v, l3 number %v, isUpdated %v",
+		BEResctrlGroup, memBwPercent, l3Num, isUpdated)
+	return nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cache"
+)
+
+func Test_mbaFeedbackSignals_isLSDegraded(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals mbaFeedbackSignals
+		want    bool
+	}{
+		{
+			name:    "ls bandwidth drops",
+			signals: mbaFeedbackSignals{lsBandwidth: 800, lsBaselineBandwidth: 1000, beBandwidth: 500},
+			want:    true,
+		},
+		{
+			name:    "ls bandwidth drops within the ratio",
+			signals: mbaFeedbackSignals{lsBandwidth: 950, lsBaselineBandwidth: 1000, beBandwidth: 500},
+			want:    false,
+		},
+		{
+			name:    "ls cpi rises",
+			signals: mbaFeedbackSignals{lsBandwidth: 1000, lsBaselineBandwidth: 1000, lsCPI: 1.5, lsBaselineCPI: 1.0, beBandwidth: 500},
+			want:    true,
+		},
+		{
+			name:    "not degraded without be bandwidth",
+			signals: mbaFeedbackSignals{lsBandwidth: 500, lsBaselineBandwidth: 1000, lsCPI: 1.5, lsBaselineCPI: 1.0},
+			want:    false,
+		},
+		{
+			name:    "not degraded without baseline",
+			signals: mbaFeedbackSignals{lsBandwidth: 500, lsCPI: 1.5, beBandwidth: 500},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.signals.isLSDegraded(0.1))
+		})
+	}
+}
+
+func Test_getMBAFeedbackBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     *slov1alpha1.ResctrlQOS
+		wantMin int64
+		wantMax int64
+		wantErr bool
+	}{
+		{
+			name:    "default bounds",
+			arg:     &slov1alpha1.ResctrlQOS{},
+			wantMin: 10,
+			wantMax: 100,
+		},
+		{
+			name:    "bounds of nodeSLO",
+			arg:     &slov1alpha1.ResctrlQOS{MBAMinPercent: pointer.Int64(20), MBAMaxPercent: pointer.Int64(80)},
+			wantMin: 20,
+			wantMax: 80,
+		},
+		{
+			name:    "invalid bounds",
+			arg:     &slov1alpha1.ResctrlQOS{MBAMinPercent: pointer.Int64(80), MBAMaxPercent: pointer.Int64(20)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid min bound",
+			arg:     &slov1alpha1.ResctrlQOS{MBAMinPercent: pointer.Int64(0)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMin, gotMax, err := getMBAFeedbackBounds(tt.arg)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantMin, gotMin)
+			assert.Equal(t, tt.wantMax, gotMax)
+		})
+	}
+}
+
+func TestMBAFeedback_reconcileBEMBA(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	sysFSRootDirName := "mbaFeedback"
+	helper.MkDirAll(sysFSRootDirName)
+	oldSysFSRootDir := system.Conf.SysFSRootDir
+	system.Conf.SysFSRootDir = filepath.Join(helper.TempDir, sysFSRootDirName)
+	defer func() { system.Conf.SysFSRootDir = oldSysFSRootDir }()
+	testingPrepareResctrlL3CatGroups(t, "ff", "")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+
+	lsPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-ls-pod",
+			UID:    "test-ls-pod-uid",
+			Labels: map[string]string{extension.LabelPodQoS: string(extension.QoSLS)},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{Pod: lsPod}}).AnyTimes()
+
+	// the baseline of LS is 1000 bytes/s with CPI 1.0
+	type round struct {
+		lsBandwidth float64
+		beBandwidth float64
+		lsCPI       float64
+		want        int64
+	}
+	var current round
+	cfg := NewDefaultConfig()
+	isRecent := func(param *metriccache.QueryParam) bool {
+		return param.End.Sub(*param.Start) <= time.Duration(cfg.MBAFeedbackIntervalSeconds)*time.Second
+	}
+	metricCache.EXPECT().GetResctrlMemBandwidthMetric(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(group *string, param *metriccache.QueryParam) metriccache.ResctrlMemBandwidthQueryResult {
+			bandwidth := float64(0)
+			switch *group {
+			case LSResctrlGroup:
+				bandwidth = 1000
+				if isRecent(param) {
+					bandwidth = current.lsBandwidth
+				}
+			case BEResctrlGroup:
+				bandwidth = current.beBandwidth
+			default:
+				return metriccache.ResctrlMemBandwidthQueryResult{QueryResult: metriccache.QueryResult{Error: fmt.Errorf("not exist")}}
+			}
+			return metriccache.ResctrlMemBandwidthQueryResult{
+				Metric: &metriccache.ResctrlMemBandwidthMetric{
+					Group:           *group,
+					SocketBandwidth: map[int32]float64{0: bandwidth / 2, 1: bandwidth / 2},
+				},
+			}
+		}).AnyTimes()
+	metricCache.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodCPI, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ metriccache.InterferenceMetricName, podUID *string, param *metriccache.QueryParam) metriccache.PodInterferenceQueryResult {
+			cpi := &metriccache.CPIMetric{Cycles: 1000, Instructions: 1000}
+			if isRecent(param) {
+				cpi.Cycles = uint64(current.lsCPI * 1000)
+			}
+			return metriccache.PodInterferenceQueryResult{
+				Metric: &metriccache.PodInterferenceMetric{MetricName: metriccache.MetricNamePodCPI, PodUID: *podUID, MetricValue: cpi},
+			}
+		}).AnyTimes()
+	metricCache.EXPECT().GetNodeCPUInfo(&metriccache.QueryParam{}).Return(&metriccache.NodeCPUInfo{
+		TotalInfo: koordletutil.CPUTotalInfo{NumberL3s: 2},
+	}, nil).AnyTimes()
+
+	m := &MBAFeedback{
+		resmanager: &resmanager{
+			config:         cfg,
+			metricCache:    metricCache,
+			statesInformer: statesInformer,
+		},
+		executor: &resourceexecutor.ResourceUpdateExecutorImpl{
+			Config:        resourceexecutor.NewDefaultConfig(),
+			ResourceCache: cache.NewCacheDefault(),
+		},
+	}
+	stop := make(chan struct{})
+	assert.NoError(t, m.RunInit(stop))
+	defer func() { stop <- struct{}{} }()
+
+	resctrlQoS := &slov1alpha1.ResctrlQOS{
+		MBAPercent:    pointer.Int64(50),
+		MBAMinPercent: pointer.Int64(20),
+		MBAMaxPercent: pointer.Int64(70),
+	}
+	rounds := []round{
+		// start from the static MBA percent
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 50},
+		// tighten while the LS bandwidth drops
+		{lsBandwidth: 850, beBandwidth: 800, lsCPI: 1.0, want: 40},
+		{lsBandwidth: 800, beBandwidth: 900, lsCPI: 1.0, want: 30},
+		{lsBandwidth: 700, beBandwidth: 900, lsCPI: 1.0, want: 20},
+		// bounded by the min percent
+		{lsBandwidth: 700, beBandwidth: 900, lsCPI: 1.0, want: 20},
+		// relax after the LS keeps healthy for 3 rounds
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 20},
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 20},
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 30},
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 30},
+		// tighten while the LS CPI rises
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.5, want: 20},
+		// not degraded since the BE consumes no bandwidth
+		{lsBandwidth: 500, beBandwidth: 0, lsCPI: 1.5, want: 20},
+		{lsBandwidth: 500, beBandwidth: 0, lsCPI: 1.5, want: 20},
+		{lsBandwidth: 500, beBandwidth: 0, lsCPI: 1.5, want: 30},
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 30},
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 30},
+		{lsBandwidth: 1000, beBandwidth: 500, lsCPI: 1.0, want: 40},
+	}
+	schemataPath := filepath.Join(system.Conf.SysFSRootDir, system.ResctrlDir, BEResctrlGroup, system.ResctrlSchemataName)
+	for i, r := range rounds {
+		current = r
+		m.reconcileBEMBA(resctrlQoS)
+		assert.Equal(t, r.want, m.currentPercent, "round %d", i)
+		got, err := os.ReadFile(schemataPath)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("MB:0=%d;1=%d;\n", r.want, r.want), string(got), "round %d", i)
+	}
+
+	// relax until bounded by the max percent
+	for i := 0; i < 3*5; i++ {
+		m.reconcileBEMBA(resctrlQoS)
+	}
+	assert.Equal(t, int64(70), m.currentPercent)
+}
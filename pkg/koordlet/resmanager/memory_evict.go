@@ -18,7 +18,6 @@ package resmanager
 
 import (
 	"fmt"
-	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -159,7 +158,7 @@ func (m *MemoryEvictor) getSortedBEPodInfos(podMetrics []*metriccache.PodResourc
 	}
 
 	var bePodInfos []*podInfo
-	for _, podMeta := range m.resManager.statesInformer.GetAllPods() {
+	for _, podMeta := range m.resManager.getManagedPods(features.BEMemoryEvict) {
 		pod := podMeta.Pod
 		if extension.GetPodQoSClass(pod) == extension.QoSBE {
 			info := &podInfo{
@@ -170,18 +169,13 @@ func (m *MemoryEvictor) getSortedBEPodInfos(podMetrics []*metriccache.PodResourc
 		}
 	}
 
-	sort.Slice(bePodInfos, func(i, j int) bool {
-		// TODO: https://github.com/koordinator-sh/koordinator/pull/65#discussion_r849048467
-		// compare priority > podMetric > name
-		if bePodInfos[i].pod.Spec.Priority != nil && bePodInfos[j].pod.Spec.Priority != nil && *bePodInfos[i].pod.Spec.Priority != *bePodInfos[j].pod.Spec.Priority {
-			return *bePodInfos[i].pod.Spec.Priority < *bePodInfos[j].pod.Spec.Priority
+	sortEvictionVictims(bePodInfos, func(i int) evictionVictim {
+		victim := evictionVictim{pod: bePodInfos[i].pod}
+		if podMetric := bePodInfos[i].podMetric; podMetric != nil {
+			victim.usage = float64(podMetric.MemoryUsed.MemoryWithoutCache.Value())
+			victim.usageKnown = true
 		}
-		if bePodInfos[i].podMetric != nil && bePodInfos[j].podMetric != nil {
-			return bePodInfos[i].podMetric.MemoryUsed.MemoryWithoutCache.Value() > bePodInfos[j].podMetric.MemoryUsed.MemoryWithoutCache.Value()
-		} else if bePodInfos[i].podMetric == nil && bePodInfos[j].podMetric == nil {
-			return bePodInfos[i].pod.Name > bePodInfos[j].pod.Name
-		}
-		return bePodInfos[j].podMetric == nil
+		return victim
 	})
 
 	return bePodInfos
@@ -0,0 +1,370 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+type memoryLocalityRepairMode string
+
+const (
+	// memoryLocalityRepairModeMigrate migrates the pages on the disallowed NUMA node to the nodes in cpuset.mems.
+	memoryLocalityRepairModeMigrate memoryLocalityRepairMode = "Migrate"
+	// memoryLocalityRepairModeExpandMems temporarily adds the NUMA node holding the pages into cpuset.mems.
+	memoryLocalityRepairModeExpandMems memoryLocalityRepairMode = "ExpandMems"
+
+	ReasonRepairMemoryLocality = "RepairMemoryLocality"
+
+	// memoryLocalitySamplePidsPerContainer limits the processes whose numa_maps are read for one container.
+	memoryLocalitySamplePidsPerContainer = 5
+	// memoryLocalityRestoreIdleRounds is the number of consecutive idle cycles required before restoring an expanded
+	// cpuset.mems, so the pages are not migrated back during a transient dip in the node cpu usage.
+	memoryLocalityRestoreIdleRounds = 2
+)
+
+// numaMapsReader reads the resident memory in kB on each NUMA node of a process.
+type numaMapsReader interface {
+	ReadResidentMemory(pid int32) (map[int]int64, error)
+}
+
+// memoryMigrator moves the memory of a container between the NUMA nodes.
+type memoryMigrator interface {
+	// MigratePages moves the pages of the processes in the container from the source nodes to the target nodes.
+	MigratePages(containerDir string, pids []int32, fromNodes, toNodes []int) error
+	// SetCPUSetMems updates the cpuset.mems of the container.
+	SetCPUSetMems(containerDir string, mems string) error
+}
+
+type procNUMAMapsReader struct{}
+
+func (r *procNUMAMapsReader) ReadResidentMemory(pid int32) (map[int]int64, error) {
+	return sysutil.ReadProcNUMAMaps(pid)
+}
+
+type defaultMemoryMigrator struct {
+	executor resourceexecutor.ResourceUpdateExecutor
+}
+
+func (m *defaultMemoryMigrator) MigratePages(containerDir string, pids []int32, fromNodes, toNodes []int) error {
+	if sysutil.GetCurrentCgroupVersion() == sysutil.CgroupVersionV2 {
+		// on cgroups-v2, the kernel migrates the memory of the tasks when cpuset.mems changes
+		mems := cpuset.NewCPUSet(toNodes...)
+		if err := m.SetCPUSetMems(containerDir, mems.UnionSlice(fromNodes...).String()); err != nil {
+			return err
+		}
+		return m.SetCPUSetMems(containerDir, mems.String())
+	}
+
+	for _, pid := range pids {
+		notMoved, err := sysutil.MigratePages(pid, fromNodes, toNodes)
+		if err != nil {
+			return fmt.Errorf("failed to migrate pages of pid %v, err: %v", pid, err)
+		}
+		if notMoved > 0 {
+			klog.V(5).Infof("%v pages of pid %v are not migrated from NUMA nodes %v to %v", notMoved, pid, fromNodes, toNodes)
+		}
+	}
+	return nil
+}
+
+func (m *defaultMemoryMigrator) SetCPUSetMems(containerDir string, mems string) error {
+	updater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(sysutil.CPUSetMemsName, containerDir, mems)
+	if err != nil {
+		return err
+	}
+	_, err = m.executor.Update(false, updater)
+	return err
+}
+
+type expandedMemsRecord struct {
+	originalMems  string
+	expandedNodes []int
+	// holdUntil is the earliest time to restore the original cpuset.mems.
+	holdUntil time.Time
+	// idleRounds counts the consecutive idle cycles after holdUntil.
+	idleRounds int
+}
+
+type memoryLocalityCandidate struct {
+	pod          *corev1.Pod
+	container    string
+	containerDir string
+}
+
+// MemoryLocalityRepair finds the LS containers whose cpuset.mems excludes the NUMA node holding most of their resident
+// memory, e.g. the memory allocated before the cpuset is shrunk for an LSR neighbor, and moves the memory back to the
+// allowed nodes. The numa_maps reads and the repairs are budgeted in each cycle, and the repairs only happen when the
+// node cpu usage is low.
+type MemoryLocalityRepair struct {
+	resmanager *resmanager
+	executor   resourceexecutor.ResourceUpdateExecutor
+	reader     numaMapsReader
+	migrator   memoryMigrator
+	// sampleOffset rotates the containers to sample across cycles.
+	sampleOffset int
+	// expandedMems records the containers whose cpuset.mems are expanded, keyed by the container dir.
+	expandedMems map[string]*expandedMemsRecord
+}
+
+func NewMemoryLocalityRepair(resmanager *resmanager) *MemoryLocalityRepair {
+	executor := resourceexecutor.NewResourceUpdateExecutor()
+	return &MemoryLocalityRepair{
+		resmanager:   resmanager,
+		executor:     executor,
+		reader:       &procNUMAMapsReader{},
+		migrator:     &defaultMemoryMigrator{executor: executor},
+		expandedMems: map[string]*expandedMemsRecord{},
+	}
+}
+
+func (m *MemoryLocalityRepair) RunInit(stopCh <-chan struct{}) error {
+	mode := memoryLocalityRepairMode(m.resmanager.config.MemoryLocalityRepairMode)
+	if mode != memoryLocalityRepairModeMigrate && mode != memoryLocalityRepairModeExpandMems {
+		return fmt.Errorf("unsupported memory locality repair mode %s", mode)
+	}
+	m.executor.Run(stopCh)
+	return nil
+}
+
+func (m *MemoryLocalityRepair) repair() {
+	cfg := m.resmanager.config
+	now := time.Now()
+	idle := m.isNodeIdle()
+	m.restoreExpandedMems(now, idle)
+
+	if !idle {
+		klog.V(5).Infof("skip repairing memory locality since the node is busy")
+		return
+	}
+
+	candidates := m.getCandidates()
+	if len(candidates) <= 0 {
+		return
+	}
+	sampleNum := len(candidates)
+	if cfg.MemoryLocalitySampleContainersPerCycle > 0 && sampleNum > cfg.MemoryLocalitySampleContainersPerCycle {
+		sampleNum = cfg.MemoryLocalitySampleContainersPerCycle
+	}
+
+	repaired, sampled := 0, 0
+	for ; sampled < sampleNum; sampled++ {
+		if cfg.MemoryLocalityRepairContainersPerCycle > 0 && repaired >= cfg.MemoryLocalityRepairContainersPerCycle {
+			break
+		}
+		c := candidates[(m.sampleOffset+sampled)%len(candidates)]
+		if m.repairContainer(c, now) {
+			repaired++
+		}
+	}
+	m.sampleOffset = (m.sampleOffset + sampled) % len(candidates)
+	klog.V(5).Infof("finished repairing memory locality, candidates %v, sampled %v, repaired %v",
+		len(candidates), sampled, repaired)
+}
+
+// repairContainer checks the memory locality of the container and repairs it if necessary.
+// It returns true if a repair is performed.
+func (m *MemoryLocalityRepair) repairContainer(c *memoryLocalityCandidate, now time.Time) bool {
+	cgroupReader := m.resmanager.cgroupReader
+	mems, err := cgroupReader.ReadCPUSetMems(c.containerDir)
+	if err != nil || mems.IsEmpty() {
+		klog.V(5).Infof("failed to read cpuset.mems of container %s/%s, err: %v", util.GetPodKey(c.pod), c.container, err)
+		return false
+	}
+	pids, err := cgroupReader.ReadCPUProcs(c.containerDir)
+	if err != nil || len(pids) <= 0 {
+		klog.V(5).Infof("failed to read procs of container %s/%s, err: %v", util.GetPodKey(c.pod), c.container, err)
+		return false
+	}
+
+	residentKB := map[int]int64{}
+	for i := 0; i < len(pids) && i < memoryLocalitySamplePidsPerContainer; i++ {
+		nodeResident, err := m.reader.ReadResidentMemory(pids[i])
+		if err != nil {
+			klog.V(5).Infof("failed to read numa_maps of pid %v, err: %v", pids[i], err)
+			continue
+		}
+		for node, kb := range nodeResident {
+			residentKB[node] += kb
+		}
+	}
+	majorNode, majorKB := -1, int64(0)
+	for node, kb := range residentKB {
+		if kb > majorKB || (kb == majorKB && node < majorNode) {
+			majorNode, majorKB = node, kb
+		}
+	}
+	if majorNode < 0 || mems.Contains(majorNode) {
+		return false
+	}
+
+	mode := memoryLocalityRepairMode(m.resmanager.config.MemoryLocalityRepairMode)
+	klog.V(4).Infof("container %s/%s holds %v kB memory on NUMA node %v out of cpuset.mems %s, repair by %s",
+		util.GetPodKey(c.pod), c.container, majorKB, majorNode, mems.String(), mode)
+	_ = audit.V(3).Pod(c.pod.Namespace, c.pod.Name).Container(c.container).Reason(ReasonRepairMemoryLocality).
+		Message("repair memory on NUMA node %v out of cpuset.mems %s by %s", majorNode, mems.String(), mode).Do()
+
+	if mode == memoryLocalityRepairModeExpandMems {
+		err = m.migrator.SetCPUSetMems(c.containerDir, mems.UnionSlice(majorNode).String())
+		if err == nil {
+			m.expandedMems[c.containerDir] = &expandedMemsRecord{
+				originalMems:  mems.String(),
+				expandedNodes: []int{majorNode},
+				holdUntil:     now.Add(time.Duration(m.resmanager.config.MemoryLocalityExpandSeconds) * time.Second),
+			}
+		}
+	} else {
+		err = m.migrator.MigratePages(c.containerDir, pids, []int{majorNode}, mems.ToSlice())
+	}
+	metrics.RecordMemoryLocalityRepaired(string(mode), err == nil)
+	if err != nil {
+		klog.V(4).Infof("failed to repair memory locality of container %s/%s, err: %v", util.GetPodKey(c.pod), c.container, err)
+	}
+	return true
+}
+
+// restoreExpandedMems restores the cpuset.mems expanded before. A record is restored only after its hold time and
+// when the node keeps idle for memoryLocalityRestoreIdleRounds cycles, since the pages on the expanded nodes are
+// migrated back to the original mems before the cpuset.mems shrinks. Otherwise the container would keep most of its
+// memory out of the cpuset.mems and be repaired again right after the restore.
+func (m *MemoryLocalityRepair) restoreExpandedMems(now time.Time, idle bool) {
+	cgroupReader := m.resmanager.cgroupReader
+	for containerDir, record := range m.expandedMems {
+		if now.Before(record.holdUntil) {
+			continue
+		}
+		if !idle {
+			record.idleRounds = 0
+			continue
+		}
+		record.idleRounds++
+		if record.idleRounds < memoryLocalityRestoreIdleRounds {
+			continue
+		}
+
+		originalMems, err := cpuset.Parse(record.originalMems)
+		if err != nil {
+			klog.V(4).Infof("failed to parse the original cpuset.mems %s of container dir %s, err: %v",
+				record.originalMems, containerDir, err)
+			delete(m.expandedMems, containerDir)
+			continue
+		}
+		mems, err := cgroupReader.ReadCPUSetMems(containerDir)
+		if err != nil {
+			// the container may be removed
+			klog.V(5).Infof("failed to read cpuset.mems of container dir %s, err: %v", containerDir, err)
+			delete(m.expandedMems, containerDir)
+			continue
+		}
+		if !mems.Equals(originalMems.UnionSlice(record.expandedNodes...)) {
+			// the cpuset.mems is updated by others, e.g. the cpuset rule, keep it
+			klog.V(5).Infof("skip restoring cpuset.mems of container dir %s since it is changed to %s",
+				containerDir, mems.String())
+			delete(m.expandedMems, containerDir)
+			continue
+		}
+		pids, err := cgroupReader.ReadCPUProcs(containerDir)
+		if err != nil {
+			klog.V(5).Infof("failed to read procs of container dir %s, err: %v", containerDir, err)
+			delete(m.expandedMems, containerDir)
+			continue
+		}
+
+		err = m.migrator.MigratePages(containerDir, pids, record.expandedNodes, originalMems.ToSlice())
+		if err == nil {
+			err = m.migrator.SetCPUSetMems(containerDir, record.originalMems)
+		}
+		if err != nil {
+			// retry in the next idle rounds
+			klog.V(4).Infof("failed to restore cpuset.mems of container dir %s to %s, err: %v",
+				containerDir, record.originalMems, err)
+			record.idleRounds = 0
+			continue
+		}
+		klog.V(4).Infof("restored cpuset.mems of container dir %s to %s", containerDir, record.originalMems)
+		delete(m.expandedMems, containerDir)
+	}
+}
+
+func (m *MemoryLocalityRepair) isNodeIdle() bool {
+	node := m.resmanager.statesInformer.GetNode()
+	if node == nil {
+		return false
+	}
+	capacity := node.Status.Capacity.Cpu()
+	if capacity == nil || capacity.MilliValue() <= 0 {
+		return false
+	}
+	nodeMetric := m.resmanager.collectNodeMetricsAvg(int64(m.resmanager.config.MemoryLocalityRepairIntervalSeconds)).Metric
+	if nodeMetric == nil {
+		return false
+	}
+	usagePercent := nodeMetric.CPUUsed.CPUUsed.MilliValue() * 100 / capacity.MilliValue()
+	return usagePercent < m.resmanager.config.MemoryLocalityNodeCPUThresholdPercent
+}
+
+// getCandidates returns the running containers of the LS pods in a stable order.
+func (m *MemoryLocalityRepair) getCandidates() []*memoryLocalityCandidate {
+	var candidates []*memoryLocalityCandidate
+	for _, podMeta := range m.resmanager.getManagedPods(features.MemoryLocalityRepair) {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		pod := podMeta.Pod
+		if apiext.GetPodQoSClass(pod) != apiext.QoSLS || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for i := range pod.Status.ContainerStatuses {
+			containerStat := &pod.Status.ContainerStatuses[i]
+			if containerStat.State.Running == nil || containerStat.ContainerID == "" {
+				continue
+			}
+			containerDir, err := koordletutil.GetContainerCgroupPathWithKube(podMeta.CgroupDir, containerStat)
+			if err != nil {
+				klog.V(5).Infof("failed to get container dir of %s/%s, err: %v", util.GetPodKey(pod), containerStat.Name, err)
+				continue
+			}
+			if _, ok := m.expandedMems[containerDir]; ok {
+				continue
+			}
+			candidates = append(candidates, &memoryLocalityCandidate{
+				pod:          pod,
+				container:    containerStat.Name,
+				containerDir: containerDir,
+			})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].containerDir < candidates[j].containerDir
+	})
+	return candidates
+}
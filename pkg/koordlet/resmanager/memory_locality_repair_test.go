@@ -0,0 +1,330 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cache"
+)
+
+type fakeNUMAMapsReader struct {
+	residentKB map[int32]map[int]int64
+	readPids   []int32
+}
+
+func (f *fakeNUMAMapsReader) ReadResidentMemory(pid int32) (map[int]int64, error) {
+	f.readPids = append(f.readPids, pid)
+	resident, ok := f.residentKB[pid]
+	if !ok {
+		return nil, fmt.Errorf("pid %v not found", pid)
+	}
+	return resident, nil
+}
+
+type fakeMigration struct {
+	containerDir string
+	pids         []int32
+	fromNodes    []int
+	toNodes      []int
+}
+
+type fakeMemoryMigrator struct {
+	migrations []fakeMigration
+	mems       map[string]string
+}
+
+func (f *fakeMemoryMigrator) MigratePages(containerDir string, pids []int32, fromNodes, toNodes []int) error {
+	f.migrations = append(f.migrations, fakeMigration{containerDir: containerDir, pids: pids, fromNodes: fromNodes, toNodes: toNodes})
+	return nil
+}
+
+func (f *fakeMemoryMigrator) SetCPUSetMems(containerDir string, mems string) error {
+	f.mems[containerDir] = mems
+	return nil
+}
+
+func newTestLSPodMeta(name string, pids string, mems string, helper *system.FileTestUtil) (*statesinformer.PodMeta, string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("uid-" + name),
+			Labels: map[string]string{
+				apiext.LabelPodQoS: string(apiext.QoSLS),
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "main",
+					ContainerID: "containerd://" + name,
+					State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+	podMeta := &statesinformer.PodMeta{
+		Pod:       pod,
+		CgroupDir: "kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + name + ".slice",
+	}
+	containerDir, _ := koordletutil.GetContainerCgroupPathWithKube(podMeta.CgroupDir, &pod.Status.ContainerStatuses[0])
+	helper.WriteCgroupFileContents(containerDir, system.CPUSetMems, mems)
+	helper.WriteCgroupFileContents(containerDir, system.CPUProcs, pids)
+	return podMeta, containerDir
+}
+
+func TestMemoryLocalityRepair_repair(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("10"),
+			},
+		},
+	}
+	reader := &fakeNUMAMapsReader{
+		residentKB: map[int32]map[int]int64{
+			// drifted: most memory on node 1 while cpuset.mems is 0
+			100: {0: 100, 1: 1000},
+			101: {1: 500},
+			// local: most memory on node 0
+			200: {0: 1000, 1: 10},
+			// drifted
+			300: {0: 10, 1: 1000},
+		},
+	}
+
+	type args struct {
+		mode              memoryLocalityRepairMode
+		nodeCPUUsed       string
+		maxRepairs        int
+		maxSamples        int
+		wantMigrations    int
+		wantExpandedMems  map[string]string
+		wantReadPidsCount int
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			name: "skip when the node is busy",
+			args: args{
+				mode:        memoryLocalityRepairModeMigrate,
+				nodeCPUUsed: "8",
+				maxRepairs:  10,
+				maxSamples:  10,
+			},
+		},
+		{
+			name: "migrate the drifted containers",
+			args: args{
+				mode:              memoryLocalityRepairModeMigrate,
+				nodeCPUUsed:       "2",
+				maxRepairs:        10,
+				maxSamples:        10,
+				wantMigrations:    2,
+				wantReadPidsCount: 4,
+			},
+		},
+		{
+			name: "throttle the repairs",
+			args: args{
+				mode:              memoryLocalityRepairModeMigrate,
+				nodeCPUUsed:       "2",
+				maxRepairs:        1,
+				maxSamples:        10,
+				wantMigrations:    1,
+				wantReadPidsCount: 2,
+			},
+		},
+		{
+			name: "budget the samples",
+			args: args{
+				mode:              memoryLocalityRepairModeMigrate,
+				nodeCPUUsed:       "2",
+				maxRepairs:        10,
+				maxSamples:        2,
+				wantMigrations:    1,
+				wantReadPidsCount: 3,
+			},
+		},
+		{
+			name: "expand the mems",
+			args: args{
+				mode:              memoryLocalityRepairModeExpandMems,
+				nodeCPUUsed:       "2",
+				maxRepairs:        10,
+				maxSamples:        10,
+				wantExpandedMems:  map[string]string{"pod-a": "0-1", "pod-c": "0-1"},
+				wantReadPidsCount: 4,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			podA, dirA := newTestLSPodMeta("pod-a", "100\n101\n", "0", helper)
+			podB, dirB := newTestLSPodMeta("pod-b", "200\n", "0", helper)
+			podC, dirC := newTestLSPodMeta("pod-c", "300\n", "0", helper)
+			dirs := map[string]string{"pod-a": dirA, "pod-b": dirB, "pod-c": dirC}
+
+			statesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+			statesInformer.EXPECT().GetNode().Return(node).AnyTimes()
+			statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{podA, podB, podC}).AnyTimes()
+			metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+			metricCache.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+				Metric: &metriccache.NodeResourceMetric{
+					CPUUsed: metriccache.CPUMetric{CPUUsed: resource.MustParse(tt.args.nodeCPUUsed)},
+				},
+			}).AnyTimes()
+
+			cfg := NewDefaultConfig()
+			cfg.MemoryLocalityRepairMode = string(tt.args.mode)
+			cfg.MemoryLocalityRepairContainersPerCycle = tt.args.maxRepairs
+			cfg.MemoryLocalitySampleContainersPerCycle = tt.args.maxSamples
+			r := &resmanager{
+				config:         cfg,
+				statesInformer: statesInformer,
+				metricCache:    metricCache,
+				cgroupReader:   resourceexecutor.NewCgroupReader(),
+			}
+			reader.readPids = nil
+			migrator := &fakeMemoryMigrator{mems: map[string]string{}}
+			m := &MemoryLocalityRepair{
+				resmanager: r,
+				executor: &resourceexecutor.ResourceUpdateExecutorImpl{
+					ResourceCache: cache.NewCacheDefault(),
+					Config:        resourceexecutor.NewDefaultConfig(),
+				},
+				reader:       reader,
+				migrator:     migrator,
+				expandedMems: map[string]*expandedMemsRecord{},
+			}
+			stop := make(chan struct{})
+			defer close(stop)
+			assert.NoError(t, m.RunInit(stop))
+
+			m.repair()
+			assert.Equal(t, tt.args.wantMigrations, len(migrator.migrations))
+			for _, migration := range migrator.migrations {
+				assert.Equal(t, []int{1}, migration.fromNodes)
+				assert.Equal(t, []int{0}, migration.toNodes)
+				assert.NotEqual(t, dirB, migration.containerDir)
+			}
+			assert.Equal(t, tt.args.wantReadPidsCount, len(reader.readPids))
+			wantMems := map[string]string{}
+			for name, mems := range tt.args.wantExpandedMems {
+				wantMems[dirs[name]] = mems
+			}
+			assert.Equal(t, wantMems, migrator.mems)
+			assert.Equal(t, len(wantMems), len(m.expandedMems))
+		})
+	}
+}
+
+func TestMemoryLocalityRepair_restoreExpandedMems(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteCgroupFileContents("stable", system.CPUSetMems, "0-1")
+	helper.WriteCgroupFileContents("stable", system.CPUProcs, "100\n")
+	helper.WriteCgroupFileContents("unstable", system.CPUSetMems, "0-1")
+	helper.WriteCgroupFileContents("unstable", system.CPUProcs, "200\n")
+	helper.WriteCgroupFileContents("changed", system.CPUSetMems, "0")
+	helper.WriteCgroupFileContents("changed", system.CPUProcs, "300\n")
+	helper.WriteCgroupFileContents("held", system.CPUSetMems, "0-1")
+	helper.WriteCgroupFileContents("held", system.CPUProcs, "400\n")
+
+	now := time.Now()
+	newRecords := func() map[string]*expandedMemsRecord {
+		return map[string]*expandedMemsRecord{
+			"stable":   {originalMems: "0", expandedNodes: []int{1}, holdUntil: now.Add(-time.Second), idleRounds: 1},
+			"unstable": {originalMems: "0", expandedNodes: []int{1}, holdUntil: now.Add(-time.Second)},
+			"changed":  {originalMems: "1", expandedNodes: []int{0}, holdUntil: now.Add(-time.Second), idleRounds: 1},
+			"held":     {originalMems: "0", expandedNodes: []int{1}, holdUntil: now.Add(time.Minute), idleRounds: 1},
+			"removed":  {originalMems: "0", expandedNodes: []int{1}, holdUntil: now.Add(-time.Second), idleRounds: 1},
+		}
+	}
+
+	t.Run("restore the stable records after the hold time", func(t *testing.T) {
+		migrator := &fakeMemoryMigrator{mems: map[string]string{}}
+		m := &MemoryLocalityRepair{
+			resmanager:   &resmanager{cgroupReader: resourceexecutor.NewCgroupReader()},
+			migrator:     migrator,
+			expandedMems: newRecords(),
+		}
+		m.restoreExpandedMems(now, true)
+		assert.Equal(t, []fakeMigration{
+			{containerDir: "stable", pids: []int32{100}, fromNodes: []int{1}, toNodes: []int{0}},
+		}, migrator.migrations)
+		assert.Equal(t, map[string]string{"stable": "0"}, migrator.mems)
+		assert.Equal(t, 2, len(m.expandedMems))
+		assert.Equal(t, 1, m.expandedMems["unstable"].idleRounds)
+		assert.NotNil(t, m.expandedMems["held"])
+
+		m.restoreExpandedMems(now, true)
+		assert.Equal(t, map[string]string{"stable": "0", "unstable": "0"}, migrator.mems)
+		assert.Equal(t, 1, len(m.expandedMems))
+	})
+
+	t.Run("keep the records when the node is busy", func(t *testing.T) {
+		migrator := &fakeMemoryMigrator{mems: map[string]string{}}
+		m := &MemoryLocalityRepair{
+			resmanager:   &resmanager{cgroupReader: resourceexecutor.NewCgroupReader()},
+			migrator:     migrator,
+			expandedMems: newRecords(),
+		}
+		m.restoreExpandedMems(now, false)
+		assert.Empty(t, migrator.migrations)
+		assert.Empty(t, migrator.mems)
+		assert.Equal(t, 5, len(m.expandedMems))
+		assert.Equal(t, 0, m.expandedMems["stable"].idleRounds)
+		assert.Equal(t, 1, m.expandedMems["held"].idleRounds)
+	})
+}
+
+func TestMemoryLocalityRepair_RunInit(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.MemoryLocalityRepairMode = "unknown"
+	m := NewMemoryLocalityRepair(&resmanager{config: cfg})
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.Error(t, m.RunInit(stop))
+}
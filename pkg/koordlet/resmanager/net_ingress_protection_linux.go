@@ -0,0 +1,387 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+const (
+	defaultNetIngressBurstKB = 256
+	// netIngressLatencyMS is the longest time a packet stays in the queue of the RX shaper before being dropped.
+	netIngressLatencyMS = 25
+)
+
+// netIngressQdiscHandle is the handle of the root tbf qdiscs owned by the koordlet, so the root qdiscs set by the
+// others, e.g. the CNI bandwidth plugin, are told and left untouched.
+var netIngressQdiscHandle = netlink.MakeHandle(0x6b6f, 0)
+
+const (
+	netIngressFamilyAll    = netlink.FAMILY_ALL
+	netIngressFamilyBridge = unix.AF_BRIDGE
+)
+
+// netIngressHandle is the netlink operations of the RX shapers, which is implemented by the netlink.Handle.
+type netIngressHandle interface {
+	LinkList() ([]netlink.Link, error)
+	LinkByIndex(index int) (netlink.Link, error)
+	RouteGet(destination net.IP) ([]netlink.Route, error)
+	NeighList(linkIndex, family int) ([]netlink.Neigh, error)
+	QdiscList(link netlink.Link) ([]netlink.Qdisc, error)
+	QdiscReplace(qdisc netlink.Qdisc) error
+	QdiscDel(qdisc netlink.Qdisc) error
+}
+
+// netIngressRule is the RX shaper of a BE pod on its host-side veth.
+type netIngressRule struct {
+	pod       string
+	linkIndex int
+	linkName  string
+	limitKbps int64
+	burstKB   int64
+}
+
+// NetIngressProtector protects the node network from the inbound traffic of the BE pods. It installs a tbf root qdisc
+// on the host-side veth of each BE pod, which shapes the packets sent to the pod by the limit in NodeSLO.
+// The host-side veth is found by the route to the pod IP, so the CNI must route the pod IP to the veth directly
+// (e.g. calico) or through a linux bridge (e.g. the bridge and flannel plugins). The pods of the other CNIs and
+// the pods using the host network or the host ports are skipped.
+type NetIngressProtector struct {
+	resManager *resmanager
+	handle     netIngressHandle
+	// rules is the installed shapers by the link index
+	rules map[int]*netIngressRule
+	// cleaned indicates whether the shapers not in rules are removed, e.g. the ones left by the previous koordlet
+	cleaned bool
+}
+
+func NewNetIngressProtector(mgr *resmanager) *NetIngressProtector {
+	return &NetIngressProtector{
+		resManager: mgr,
+		handle:     &netlink.Handle{},
+		rules:      map[int]*netIngressRule{},
+	}
+}
+
+func (p *NetIngressProtector) protect() {
+	klog.V(5).Infof("starting net ingress protection process")
+	defer klog.V(5).Infof("net ingress protection process completed")
+
+	nodeSLO := p.resManager.getNodeSLOCopy()
+	if disabled, err := isFeatureDisabled(nodeSLO, features.BENetIngressProtection); err != nil {
+		klog.V(5).Infof("skip net ingress protection and clean up the shapers, err: %v", err)
+		p.cleanup()
+		return
+	} else if disabled {
+		klog.V(5).Infof("skip net ingress protection and clean up the shapers, disabled in NodeSLO")
+		p.cleanup()
+		return
+	}
+	networkQOS := nodeSLO.Spec.ResourceQOSStrategy.BEClass.NetworkQOS
+	if networkQOS.IngressLimitKbps == nil || *networkQOS.IngressLimitKbps <= 0 {
+		klog.V(5).Infof("skip net ingress protection and clean up the shapers, invalid ingress limit %v", networkQOS.IngressLimitKbps)
+		p.cleanup()
+		return
+	}
+	burstKB := int64(defaultNetIngressBurstKB)
+	if networkQOS.IngressBurstKB != nil && *networkQOS.IngressBurstKB > 0 {
+		burstKB = *networkQOS.IngressBurstKB
+	}
+
+	desired := p.getNetIngressRules(getBEPodMetas(p.resManager.getManagedPods(features.BENetIngressProtection)), *networkQOS.IngressLimitKbps, burstKB)
+	if !p.cleaned {
+		if err := p.removeUnknownShapers(desired); err != nil {
+			klog.Warningf("failed to remove the unknown rx shapers, err: %v", err)
+			return
+		}
+	}
+	for index, rule := range p.rules {
+		want, ok := desired[index]
+		if ok && *want == *rule {
+			continue
+		}
+		if !ok {
+			if err := p.deleteShaper(rule); err != nil {
+				klog.Warningf("failed to delete the rx shaper of pod %s on %s, err: %v", rule.pod, rule.linkName, err)
+				continue
+			}
+			klog.V(4).Infof("deleted the rx shaper of pod %s on %s", rule.pod, rule.linkName)
+		}
+		delete(p.rules, index)
+	}
+
+	indexes := make([]int, 0, len(desired))
+	for index := range desired {
+		if _, ok := p.rules[index]; !ok {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		rule := desired[index]
+		if err := p.replaceShaper(rule); err != nil {
+			klog.Warningf("failed to add the rx shaper of pod %s on %s, err: %v", rule.pod, rule.linkName, err)
+			continue
+		}
+		klog.V(4).Infof("added the rx shaper of pod %s on %s, limit %dkbit, burst %dk",
+			rule.pod, rule.linkName, rule.limitKbps, rule.burstKB)
+		p.rules[index] = rule
+	}
+}
+
+// cleanup removes all the shapers owned by the koordlet.
+func (p *NetIngressProtector) cleanup() {
+	if p.cleaned && len(p.rules) <= 0 {
+		return
+	}
+	p.rules = map[int]*netIngressRule{}
+	p.cleaned = false
+	if err := p.removeUnknownShapers(nil); err != nil {
+		klog.Warningf("failed to clean up the rx shapers, err: %v", err)
+	}
+}
+
+// removeUnknownShapers deletes the shapers owned by the koordlet on the veths not in the keep.
+func (p *NetIngressProtector) removeUnknownShapers(keep map[int]*netIngressRule) error {
+	links, err := p.handle.LinkList()
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if link.Type() != "veth" {
+			continue
+		}
+		if _, ok := keep[link.Attrs().Index]; ok {
+			continue
+		}
+		root, err := p.getRootQdisc(link)
+		if err != nil {
+			return err
+		}
+		if !isNetIngressQdisc(root) {
+			continue
+		}
+		if err := p.handle.QdiscDel(root); err != nil {
+			return err
+		}
+		klog.V(4).Infof("deleted the unknown rx shaper on %s", link.Attrs().Name)
+	}
+	p.cleaned = true
+	return nil
+}
+
+func (p *NetIngressProtector) replaceShaper(rule *netIngressRule) error {
+	link, err := p.handle.LinkByIndex(rule.linkIndex)
+	if err != nil {
+		return err
+	}
+	root, err := p.getRootQdisc(link)
+	if err != nil {
+		return err
+	}
+	if root != nil && root.Attrs().Handle != 0 && !isNetIngressQdisc(root) {
+		return fmt.Errorf("root qdisc %s %s is set by others", root.Type(), netlink.HandleStr(root.Attrs().Handle))
+	}
+	return p.handle.QdiscReplace(newNetIngressQdisc(rule))
+}
+
+func (p *NetIngressProtector) deleteShaper(rule *netIngressRule) error {
+	link, err := p.handle.LinkByIndex(rule.linkIndex)
+	if _, ok := err.(netlink.LinkNotFoundError); ok {
+		// the veth is deleted with the pod
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if link.Attrs().Name != rule.linkName {
+		// the link index is reused
+		return nil
+	}
+	root, err := p.getRootQdisc(link)
+	if err != nil || !isNetIngressQdisc(root) {
+		return err
+	}
+	return p.handle.QdiscDel(root)
+}
+
+func (p *NetIngressProtector) getRootQdisc(link netlink.Link) (netlink.Qdisc, error) {
+	qdiscs, err := p.handle.QdiscList(link)
+	if err != nil {
+		return nil, err
+	}
+	for _, qdisc := range qdiscs {
+		if qdisc.Attrs().LinkIndex == link.Attrs().Index && qdisc.Attrs().Parent == netlink.HANDLE_ROOT {
+			return qdisc, nil
+		}
+	}
+	return nil, nil
+}
+
+// getNetIngressRules returns the shapers to install by the link index of the host-side veths.
+func (p *NetIngressProtector) getNetIngressRules(bePods []*statesinformer.PodMeta, limitKbps, burstKB int64) map[int]*netIngressRule {
+	rules := map[int]*netIngressRule{}
+	for _, podMeta := range bePods {
+		pod := podMeta.Pod
+		if pod.Spec.HostNetwork || hasHostPort(pod) ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		link, err := p.getPodHostLink(pod)
+		if err != nil {
+			klog.V(4).Infof("skip the rx shaper of pod %s, err: %v", podKey, err)
+			continue
+		}
+		rules[link.Attrs().Index] = &netIngressRule{
+			pod:       podKey,
+			linkIndex: link.Attrs().Index,
+			linkName:  link.Attrs().Name,
+			limitKbps: limitKbps,
+			burstKB:   burstKB,
+		}
+	}
+	return rules
+}
+
+func hasHostPort(pod *corev1.Pod) bool {
+	for i := range pod.Spec.Containers {
+		for _, port := range pod.Spec.Containers[i].Ports {
+			if port.HostPort > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getPodHostLink returns the host-side veth of the pod by the route to the pod IPs.
+func (p *NetIngressProtector) getPodHostLink(pod *corev1.Pod) (netlink.Link, error) {
+	ips := getPodIPs(pod)
+	if len(ips) <= 0 {
+		return nil, fmt.Errorf("no pod ip")
+	}
+	var lastErr error
+	for _, ip := range ips {
+		link, err := p.getHostLinkOfIP(net.ParseIP(ip))
+		if err == nil {
+			return link, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (p *NetIngressProtector) getHostLinkOfIP(ip net.IP) (netlink.Link, error) {
+	routes, err := p.handle.RouteGet(ip)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) <= 0 {
+		return nil, fmt.Errorf("no route to %s", ip)
+	}
+	link, err := p.handle.LinkByIndex(routes[0].LinkIndex)
+	if err != nil {
+		return nil, err
+	}
+	switch link.Type() {
+	case "veth":
+		return link, nil
+	case "bridge":
+		return p.getBridgePortOfIP(link, ip)
+	}
+	return nil, fmt.Errorf("%s is routed to %s %s, neither a veth nor a bridge", ip, link.Type(), link.Attrs().Name)
+}
+
+// getBridgePortOfIP returns the veth port of the bridge by the neighbor of the IP and the bridge fdb.
+func (p *NetIngressProtector) getBridgePortOfIP(bridge netlink.Link, ip net.IP) (netlink.Link, error) {
+	bridgeIndex := bridge.Attrs().Index
+	neighs, err := p.handle.NeighList(bridgeIndex, netIngressFamilyAll)
+	if err != nil {
+		return nil, err
+	}
+	var mac net.HardwareAddr
+	for _, neigh := range neighs {
+		if neigh.IP.Equal(ip) && len(neigh.HardwareAddr) > 0 {
+			mac = neigh.HardwareAddr
+			break
+		}
+	}
+	if mac == nil {
+		return nil, fmt.Errorf("no neighbor of %s on bridge %s", ip, bridge.Attrs().Name)
+	}
+	fdbs, err := p.handle.NeighList(0, netIngressFamilyBridge)
+	if err != nil {
+		return nil, err
+	}
+	for _, fdb := range fdbs {
+		if fdb.MasterIndex != bridgeIndex || fdb.LinkIndex == bridgeIndex || !bytes.Equal(fdb.HardwareAddr, mac) {
+			continue
+		}
+		link, err := p.handle.LinkByIndex(fdb.LinkIndex)
+		if err == nil && link.Type() == "veth" {
+			return link, nil
+		}
+	}
+	return nil, fmt.Errorf("no veth port of %s (%s) on bridge %s", ip, mac, bridge.Attrs().Name)
+}
+
+func isNetIngressQdisc(qdisc netlink.Qdisc) bool {
+	return qdisc != nil && qdisc.Type() == "tbf" && qdisc.Attrs().Handle == netIngressQdiscHandle
+}
+
+func newNetIngressQdisc(rule *netIngressRule) *netlink.Tbf {
+	// the rate in bytes per second and the burst in bytes
+	rate := uint64(rule.limitKbps) * 1000 / 8
+	burst := uint32(rule.burstKB * 1024)
+	return &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: rule.linkIndex,
+			Handle:    netIngressQdiscHandle,
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		Rate:   rate,
+		Limit:  uint32(rate*netIngressLatencyMS/1000) + burst,
+		Buffer: netlink.Xmittime(rate, burst),
+	}
+}
+
+func getPodIPs(pod *corev1.Pod) []string {
+	var ips []string
+	for _, podIP := range pod.Status.PodIPs {
+		if net.ParseIP(podIP.IP) != nil {
+			ips = append(ips, podIP.IP)
+		}
+	}
+	if len(ips) <= 0 && net.ParseIP(pod.Status.PodIP) != nil {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return ips
+}
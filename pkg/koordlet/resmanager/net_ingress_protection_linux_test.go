@@ -0,0 +1,277 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+// fakeNetIngressHandle is a fake netlink keeping the links, routes, neighbors and root qdiscs of a node.
+type fakeNetIngressHandle struct {
+	links map[int]netlink.Link
+	// routes is the link index by the destination IP
+	routes map[string]int
+	neighs []netlink.Neigh
+	fdbs   []netlink.Neigh
+	// qdiscs is the root qdisc by the link index
+	qdiscs map[int]netlink.Qdisc
+}
+
+func newFakeNetIngressHandle() *fakeNetIngressHandle {
+	return &fakeNetIngressHandle{
+		links:  map[int]netlink.Link{},
+		routes: map[string]int{},
+		qdiscs: map[int]netlink.Qdisc{},
+	}
+}
+
+func (f *fakeNetIngressHandle) addLink(link netlink.Link) {
+	f.links[link.Attrs().Index] = link
+}
+
+func (f *fakeNetIngressHandle) LinkList() ([]netlink.Link, error) {
+	var links []netlink.Link
+	for _, link := range f.links {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Attrs().Index < links[j].Attrs().Index })
+	return links, nil
+}
+
+func (f *fakeNetIngressHandle) LinkByIndex(index int) (netlink.Link, error) {
+	link, ok := f.links[index]
+	if !ok {
+		return nil, netlink.LinkNotFoundError{}
+	}
+	return link, nil
+}
+
+func (f *fakeNetIngressHandle) RouteGet(destination net.IP) ([]netlink.Route, error) {
+	index, ok := f.routes[destination.String()]
+	if !ok {
+		return nil, fmt.Errorf("network is unreachable")
+	}
+	return []netlink.Route{{LinkIndex: index, Dst: &net.IPNet{IP: destination}}}, nil
+}
+
+func (f *fakeNetIngressHandle) NeighList(linkIndex, family int) ([]netlink.Neigh, error) {
+	if family == netIngressFamilyBridge {
+		return f.fdbs, nil
+	}
+	var neighs []netlink.Neigh
+	for _, neigh := range f.neighs {
+		if neigh.LinkIndex == linkIndex {
+			neighs = append(neighs, neigh)
+		}
+	}
+	return neighs, nil
+}
+
+func (f *fakeNetIngressHandle) QdiscList(link netlink.Link) ([]netlink.Qdisc, error) {
+	if qdisc, ok := f.qdiscs[link.Attrs().Index]; ok {
+		return []netlink.Qdisc{qdisc}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeNetIngressHandle) QdiscReplace(qdisc netlink.Qdisc) error {
+	f.qdiscs[qdisc.Attrs().LinkIndex] = qdisc
+	return nil
+}
+
+func (f *fakeNetIngressHandle) QdiscDel(qdisc netlink.Qdisc) error {
+	if _, ok := f.qdiscs[qdisc.Attrs().LinkIndex]; !ok {
+		return fmt.Errorf("no such qdisc")
+	}
+	delete(f.qdiscs, qdisc.Attrs().LinkIndex)
+	return nil
+}
+
+func (f *fakeNetIngressHandle) qdiscList() []string {
+	var qdiscs []string
+	for index, qdisc := range f.qdiscs {
+		desc := fmt.Sprintf("%s: %s %s", f.links[index].Attrs().Name, qdisc.Type(), netlink.HandleStr(qdisc.Attrs().Handle))
+		if tbf, ok := qdisc.(*netlink.Tbf); ok {
+			desc += fmt.Sprintf(" rate %d limit %d", tbf.Rate, tbf.Limit)
+		}
+		qdiscs = append(qdiscs, desc)
+	}
+	sort.Strings(qdiscs)
+	return qdiscs
+}
+
+func newTestVeth(index int, name string) *netlink.Veth {
+	return &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Index: index, Name: name}}
+}
+
+func createNetIngressTestPod(name string, qosClass apiext.QoSClass, ips ...string) *corev1.Pod {
+	pod := createMemoryEvictTestPod(name, qosClass, 0)
+	pod.Namespace = "default"
+	pod.Status.Phase = corev1.PodRunning
+	for _, ip := range ips {
+		pod.Status.PodIPs = append(pod.Status.PodIPs, corev1.PodIP{IP: ip})
+	}
+	if len(ips) > 0 {
+		pod.Status.PodIP = ips[0]
+	}
+	return pod
+}
+
+func getNodeSLOByNetworkQOS(networkQOS *slov1alpha1.NetworkQOSCfg) *slov1alpha1.NodeSLO {
+	return &slov1alpha1.NodeSLO{
+		Spec: slov1alpha1.NodeSLOSpec{
+			ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+				BEClass: &slov1alpha1.ResourceQOS{NetworkQOS: networkQOS},
+			},
+		},
+	}
+}
+
+func Test_NetIngressProtector_protect(t *testing.T) {
+	hostNetworkPod := createNetIngressTestPod("test_be_host_network", apiext.QoSBE, "10.0.0.1")
+	hostNetworkPod.Spec.HostNetwork = true
+	hostPortPod := createNetIngressTestPod("test_be_host_port", apiext.QoSBE, "10.1.0.3")
+	hostPortPod.Spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 80, HostPort: 8080}}
+	succeededPod := createNetIngressTestPod("test_be_succeeded", apiext.QoSBE, "10.1.0.4")
+	succeededPod.Status.Phase = corev1.PodSucceeded
+	pods := []*corev1.Pod{
+		createNetIngressTestPod("test_ls_pod", apiext.QoSLS, "10.1.0.5"),
+		hostNetworkPod,
+		hostPortPod,
+		succeededPod,
+		createNetIngressTestPod("test_be_pending", apiext.QoSBE),
+		// routed by an unsupported CNI
+		createNetIngressTestPod("test_be_overlay", apiext.QoSBE, "10.3.0.1"),
+		// the veth shaped by the CNI bandwidth plugin
+		createNetIngressTestPod("test_be_bandwidth", apiext.QoSBE, "10.1.0.9"),
+		// routed to the veth directly
+		createNetIngressTestPod("test_be_pod_0", apiext.QoSBE, "fd00::1", "10.1.0.1"),
+		// routed through the bridge
+		createNetIngressTestPod("test_be_pod_1", apiext.QoSBE, "10.2.0.2"),
+	}
+	networkQOS := &slov1alpha1.NetworkQOSCfg{
+		Enable:     pointer.Bool(true),
+		NetworkQOS: slov1alpha1.NetworkQOS{IngressLimitKbps: pointer.Int64(80000)},
+	}
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+	mockStatesInformer.EXPECT().GetAllPods().DoAndReturn(func() interface{} { return getPodMetas(pods) }).AnyTimes()
+	mockStatesInformer.EXPECT().GetNodeSLO().DoAndReturn(func() interface{} { return getNodeSLOByNetworkQOS(networkQOS) }).AnyTimes()
+	r := &resmanager{statesInformer: mockStatesInformer, config: NewDefaultConfig()}
+
+	newHandle := func() *fakeNetIngressHandle {
+		h := newFakeNetIngressHandle()
+		h.addLink(&netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: 1, Name: "eth0"}})
+		h.addLink(&netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Index: 2, Name: "cni0"}})
+		h.addLink(newTestVeth(10, "cali0"))
+		h.addLink(newTestVeth(11, "veth1"))
+		h.addLink(newTestVeth(12, "cali2"))
+		h.addLink(newTestVeth(13, "cali3"))
+		h.addLink(newTestVeth(14, "cali4"))
+		h.addLink(newTestVeth(15, "cali5"))
+		h.addLink(newTestVeth(16, "cali6"))
+		h.routes["10.1.0.5"] = 12
+		h.routes["10.0.0.1"] = 16
+		h.routes["10.1.0.3"] = 15
+		h.routes["10.3.0.1"] = 1
+		h.routes["10.1.0.9"] = 14
+		h.routes["10.1.0.1"] = 10
+		h.routes["10.2.0.2"] = 2
+		mac := net.HardwareAddr{0x0a, 0x58, 0x0a, 0x02, 0x00, 0x02}
+		h.neighs = []netlink.Neigh{{LinkIndex: 2, IP: net.ParseIP("10.2.0.2"), HardwareAddr: mac}}
+		h.fdbs = []netlink.Neigh{
+			{LinkIndex: 2, MasterIndex: 2, HardwareAddr: mac},
+			{LinkIndex: 11, MasterIndex: 2, HardwareAddr: mac},
+		}
+		h.qdiscs[14] = &netlink.Tbf{QdiscAttrs: netlink.QdiscAttrs{LinkIndex: 14, Handle: netlink.MakeHandle(1, 0), Parent: netlink.HANDLE_ROOT}}
+		return h
+	}
+	h := newHandle()
+	// the shapers left by the previous koordlet, the ones on the veths of the host network and host port pods are
+	// not kept since these pods are skipped
+	h.qdiscs[13] = newNetIngressQdisc(&netIngressRule{linkIndex: 13, limitKbps: 1000, burstKB: 256})
+	h.qdiscs[15] = newNetIngressQdisc(&netIngressRule{linkIndex: 15, limitKbps: 1000, burstKB: 256})
+	h.qdiscs[16] = newNetIngressQdisc(&netIngressRule{linkIndex: 16, limitKbps: 1000, burstKB: 256})
+	p := NewNetIngressProtector(r)
+	p.handle = h
+
+	p.protect()
+	assert.Equal(t, []string{
+		"cali0: tbf 6b6f:0 rate 10000000 limit 512144",
+		"cali4: tbf 1:0 rate 0 limit 0",
+		"veth1: tbf 6b6f:0 rate 10000000 limit 512144",
+	}, h.qdiscList())
+
+	// the shaper of the deleted pod is removed, and the ones of the changed pods are replaced
+	pods = pods[:len(pods)-1]
+	networkQOS.IngressBurstKB = pointer.Int64(512)
+	p.protect()
+	assert.Equal(t, []string{
+		"cali0: tbf 6b6f:0 rate 10000000 limit 774288",
+		"cali4: tbf 1:0 rate 0 limit 0",
+	}, h.qdiscList())
+
+	// the shapers are kept unless changed
+	p.protect()
+	assert.Len(t, h.qdiscList(), 2)
+
+	// the veth is deleted with the pod
+	delete(h.links, 10)
+	delete(h.qdiscs, 10)
+	pods = pods[:len(pods)-1]
+	p.protect()
+	assert.Equal(t, []string{"cali4: tbf 1:0 rate 0 limit 0"}, h.qdiscList())
+	assert.Empty(t, p.rules)
+
+	// the shapers are removed once disabled
+	h = newHandle()
+	p = NewNetIngressProtector(r)
+	p.handle = h
+	pods = append(pods, createNetIngressTestPod("test_be_pod_1", apiext.QoSBE, "10.2.0.2"))
+	p.protect()
+	assert.Len(t, h.qdiscList(), 2)
+	networkQOS.Enable = pointer.Bool(false)
+	p.protect()
+	assert.Equal(t, []string{"cali4: tbf 1:0 rate 0 limit 0"}, h.qdiscList())
+	p.protect()
+
+	// the shapers left are removed when the feature is not running
+	h = newHandle()
+	h.qdiscs[13] = newNetIngressQdisc(&netIngressRule{linkIndex: 13, limitKbps: 1000, burstKB: 256})
+	p = NewNetIngressProtector(r)
+	p.handle = h
+	p.cleanup()
+	assert.Equal(t, []string{"cali4: tbf 1:0 rate 0 limit 0"}, h.qdiscList())
+}
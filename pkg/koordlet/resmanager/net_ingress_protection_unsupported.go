@@ -0,0 +1,37 @@
+//go:build !linux
+// +build !linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"k8s.io/klog/v2"
+)
+
+// NetIngressProtector is only supported on linux.
+type NetIngressProtector struct{}
+
+func NewNetIngressProtector(mgr *resmanager) *NetIngressProtector {
+	return &NetIngressProtector{}
+}
+
+func (p *NetIngressProtector) protect() {
+	klog.V(5).Infof("skip net ingress protection, only supported on linux")
+}
+
+func (p *NetIngressProtector) cleanup() {}
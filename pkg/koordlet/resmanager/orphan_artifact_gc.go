@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	artifactTypeResctrlTask      = "ResctrlTask"
+	artifactTypeResctrlGroup     = "ResctrlGroup"
+	artifactTypeBECgroup         = "BECgroup"
+	artifactTypeNetIngressShaper = "NetIngressShaper"
+
+	// podArtifactPrefix is the name prefix of the resctrl groups and the best-effort sub-cgroups created by koordlet
+	// for a single pod, which are named by "koordlet-<pod uid>".
+	podArtifactPrefix = "koordlet-"
+)
+
+var (
+	// beCgroupSubsystems are the cgroup v1 subsystems where the best-effort sub-cgroups are created
+	beCgroupSubsystems = []string{system.CgroupCPUDir, system.CgroupCPUSetDir, system.CgroupCPUAcctDir,
+		system.CgroupMemDir, system.CgroupBlkioDir}
+)
+
+// orphanArtifact is an artifact created by koordlet on the node for a pod.
+type orphanArtifact struct {
+	// owner is the pod the artifact is created for, empty if the pod cannot be told from the artifact.
+	owner string
+	// path is the path of the artifact, e.g. the resctrl group or the name of the veth.
+	path string
+	// id is the kernel object of the artifact in the path, e.g. the task in the resctrl group or the index of the veth.
+	id int
+}
+
+func (a orphanArtifact) key() string {
+	return fmt.Sprintf("%s#%d", a.path, a.id)
+}
+
+// artifactCollector enumerates and removes one type of the artifacts created by koordlet for the pods.
+type artifactCollector interface {
+	// Type returns the type of the artifacts.
+	Type() string
+	// ListOrphans returns the artifacts on the node not created for any of the pods, none if the subsystem is not available.
+	ListOrphans(pods []*statesinformer.PodMeta) ([]orphanArtifact, error)
+	// Remove removes the artifact safely.
+	Remove(artifact orphanArtifact) error
+}
+
+// OrphanArtifactGC removes the artifacts koordlet left for the pods which no longer exist, e.g. after koordlet or the
+// node shuts down uncleanly. The artifacts created by the others, e.g. the pod cgroups of the kubelet, are never touched.
+// An artifact is removed only if it is found orphan in two consecutive rounds, in case its pod is just created and not
+// yet synced by the states informer.
+type OrphanArtifactGC struct {
+	resmanager *resmanager
+	collectors []artifactCollector
+	// candidates are the keys of the orphan artifacts found in the last round.
+	candidates sets.String
+}
+
+func NewOrphanArtifactGC(resmanager *resmanager) *OrphanArtifactGC {
+	collectors := []artifactCollector{&resctrlTaskCollector{}, &resctrlGroupCollector{}, &beCgroupCollector{}}
+	if c := newNetIngressShaperCollector(resmanager); c != nil {
+		collectors = append(collectors, c)
+	}
+	return &OrphanArtifactGC{
+		resmanager: resmanager,
+		collectors: collectors,
+		candidates: sets.NewString(),
+	}
+}
+
+func (g *OrphanArtifactGC) gc() {
+	pods := g.resmanager.statesInformer.GetAllPods()
+
+	dryRun := g.resmanager.config.OrphanArtifactGCDryRun
+	candidates := sets.NewString()
+	for _, collector := range g.collectors {
+		artifacts, err := collector.ListOrphans(pods)
+		if err != nil {
+			klog.Warningf("failed to list the artifacts of type %s, err: %v", collector.Type(), err)
+			continue
+		}
+		for _, artifact := range artifacts {
+			key := collector.Type() + "/" + artifact.key()
+			candidates.Insert(key)
+			if !g.candidates.Has(key) {
+				klog.V(4).Infof("found orphan %s %s of pod %q, remove it if still orphan in the next round",
+					collector.Type(), artifact.key(), artifact.owner)
+				continue
+			}
+			if dryRun {
+				klog.Infof("[dry-run] orphan %s %s of pod %q should be removed", collector.Type(), artifact.key(), artifact.owner)
+				metrics.RecordOrphanArtifactRemoved(collector.Type(), true)
+				continue
+			}
+			if err = collector.Remove(artifact); err != nil {
+				klog.Warningf("failed to remove orphan %s %s of pod %q, err: %v", collector.Type(), artifact.key(), artifact.owner, err)
+				continue
+			}
+			klog.Infof("orphan %s %s of pod %q is removed", collector.Type(), artifact.key(), artifact.owner)
+			metrics.RecordOrphanArtifactRemoved(collector.Type(), false)
+		}
+	}
+	g.candidates = candidates
+}
+
+// resctrlTaskCollector collects the tasks of the pods no longer existing in the QoS-level resctrl groups created by
+// the ResctrlReconcile, so the tasks still running are not left with the limited cache and memory bandwidth.
+// The QoS-level groups themselves are shared by the pods, so they are never removed.
+type resctrlTaskCollector struct{}
+
+func (c *resctrlTaskCollector) Type() string {
+	return artifactTypeResctrlTask
+}
+
+func (c *resctrlTaskCollector) ListOrphans(pods []*statesinformer.PodMeta) ([]orphanArtifact, error) {
+	if _, err := os.Stat(system.GetResctrlSubsystemDirPath()); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	podUIDs := getPodUIDs(pods)
+	var artifacts []orphanArtifact
+	for _, group := range resctrlGroupList {
+		tasks, err := system.ReadResctrlTasksMap(group)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for task := range tasks {
+			// the tasks not in the pods are left to the others
+			podUID, ok := getTaskPodUID(task)
+			if !ok || podUIDs.Has(podUID) {
+				continue
+			}
+			artifacts = append(artifacts, orphanArtifact{
+				owner: podUID,
+				path:  system.GetResctrlGroupRootDirPath(group),
+				id:    int(task),
+			})
+		}
+	}
+	return artifacts, nil
+}
+
+// Remove moves the task into the root group, which is not limited.
+func (c *resctrlTaskCollector) Remove(artifact orphanArtifact) error {
+	return moveResctrlTasksToRoot(map[int32]struct{}{int32(artifact.id): {}})
+}
+
+// resctrlGroupCollector collects the resctrl groups created for the pods no longer existing, which are named by
+// "koordlet-<pod uid>". The groups not named so, e.g. the QoS-level groups and the ones of the others, are kept.
+type resctrlGroupCollector struct{}
+
+func (c *resctrlGroupCollector) Type() string {
+	return artifactTypeResctrlGroup
+}
+
+func (c *resctrlGroupCollector) ListOrphans(pods []*statesinformer.PodMeta) ([]orphanArtifact, error) {
+	return listOrphanPodArtifactDirs(system.GetResctrlSubsystemDirPath(), getPodUIDs(pods))
+}
+
+// Remove moves the tasks into the root group before removing the group, so the tasks still running are not limited.
+func (c *resctrlGroupCollector) Remove(artifact orphanArtifact) error {
+	tasks, err := system.ReadResctrlTasksMap(filepath.Base(artifact.path))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err = moveResctrlTasksToRoot(tasks); err != nil {
+		return err
+	}
+	return os.RemoveAll(artifact.path)
+}
+
+// beCgroupCollector collects the sub-cgroups of the best-effort QoS cgroup created for the pods no longer existing,
+// which are named by "koordlet-<pod uid>". The pod cgroups owned by the kubelet are never touched.
+type beCgroupCollector struct{}
+
+func (c *beCgroupCollector) Type() string {
+	return artifactTypeBECgroup
+}
+
+func (c *beCgroupCollector) ListOrphans(pods []*statesinformer.PodMeta) ([]orphanArtifact, error) {
+	subsystems := beCgroupSubsystems
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		subsystems = []string{system.CgroupV2Dir}
+	}
+	podUIDs := getPodUIDs(pods)
+	beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	var artifacts []orphanArtifact
+	for _, subsystem := range subsystems {
+		subsystemArtifacts, err := listOrphanPodArtifactDirs(filepath.Join(koordletutil.GetRootCgroupSubfsDir(subsystem), beDir), podUIDs)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, subsystemArtifacts...)
+	}
+	return artifacts, nil
+}
+
+// Remove moves the processes into the best-effort QoS cgroup before removing the cgroup, so the processes still
+// running are limited as the best-effort ones.
+func (c *beCgroupCollector) Remove(artifact orphanArtifact) error {
+	procs, err := system.GetCgroupCurTasks(filepath.Join(artifact.path, system.CPUProcsName))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(procs) > 0 {
+		parentProcs, err := os.OpenFile(filepath.Join(filepath.Dir(artifact.path), system.CPUProcsName), os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open the procs of the best-effort cgroup, err: %v", err)
+		}
+		defer parentProcs.Close()
+		for _, proc := range procs {
+			// the cgroup accepts only one process per write
+			if _, err = parentProcs.WriteString(strconv.Itoa(proc) + "\n"); err != nil && !isTaskNotExist(err) {
+				return fmt.Errorf("failed to move process %v into the best-effort cgroup, err: %v", proc, err)
+			}
+		}
+	}
+	return os.RemoveAll(artifact.path)
+}
+
+// listOrphanPodArtifactDirs returns the sub-directories of the dir named by "koordlet-<pod uid>" whose pods are not
+// in the podUIDs, none if the dir does not exist.
+func listOrphanPodArtifactDirs(dir string, podUIDs sets.String) ([]orphanArtifact, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var artifacts []orphanArtifact
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), podArtifactPrefix) {
+			continue
+		}
+		podUID := strings.TrimPrefix(entry.Name(), podArtifactPrefix)
+		if len(podUID) <= 0 || podUIDs.Has(podUID) {
+			continue
+		}
+		artifacts = append(artifacts, orphanArtifact{
+			owner: podUID,
+			path:  filepath.Join(dir, entry.Name()),
+		})
+	}
+	return artifacts, nil
+}
+
+func getPodUIDs(pods []*statesinformer.PodMeta) sets.String {
+	podUIDs := sets.NewString()
+	for _, podMeta := range pods {
+		if podMeta != nil && podMeta.Pod != nil {
+			podUIDs.Insert(string(podMeta.Pod.UID))
+		}
+	}
+	return podUIDs
+}
+
+// getTaskPodUID returns the uid of the pod the task is running in by the cgroups of the task.
+func getTaskPodUID(task int32) (string, bool) {
+	content, err := os.ReadFile(filepath.Join(system.Conf.ProcRootDir, strconv.FormatInt(int64(task), 10), "cgroup"))
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		for _, dir := range strings.Split(fields[2], "/") {
+			if podUID, err := system.CgroupPathFormatter.PodIDParser(dir); err == nil {
+				// the systemd driver replaces the "-" in the pod uid with "_"
+				return strings.ReplaceAll(podUID, "_", "-"), true
+			}
+		}
+	}
+	return "", false
+}
+
+func moveResctrlTasksToRoot(tasks map[int32]struct{}) error {
+	rootTasks, err := os.OpenFile(system.GetResctrlTasksFilePath(""), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the root tasks, err: %v", err)
+	}
+	defer rootTasks.Close()
+	for task := range tasks {
+		// the resctrl accepts only one task per write
+		if _, err = rootTasks.WriteString(strconv.FormatInt(int64(task), 10) + "\n"); err != nil && !isTaskNotExist(err) {
+			return fmt.Errorf("failed to move task %v into the root group, err: %v", task, err)
+		}
+	}
+	return nil
+}
+
+func isTaskNotExist(err error) bool {
+	return errors.Is(err, syscall.ESRCH)
+}
@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"github.com/vishvananda/netlink"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+// netIngressShaperCollector collects the rx shapers installed by the NetIngressProtector on the veths of no BE pod,
+// e.g. the veths taken over by the other pods since koordlet was down. The root qdiscs set by the others are ignored.
+type netIngressShaperCollector struct {
+	protector *NetIngressProtector
+}
+
+func newNetIngressShaperCollector(mgr *resmanager) artifactCollector {
+	return &netIngressShaperCollector{protector: &NetIngressProtector{resManager: mgr, handle: &netlink.Handle{}}}
+}
+
+func (c *netIngressShaperCollector) Type() string {
+	return artifactTypeNetIngressShaper
+}
+
+func (c *netIngressShaperCollector) ListOrphans(pods []*statesinformer.PodMeta) ([]orphanArtifact, error) {
+	links, err := c.protector.handle.LinkList()
+	if err != nil {
+		return nil, err
+	}
+	// only the indexes of the host-side veths are needed
+	live := c.protector.getNetIngressRules(getBEPodMetas(pods), 0, 0)
+	var artifacts []orphanArtifact
+	for _, link := range links {
+		if link.Type() != "veth" {
+			continue
+		}
+		if _, ok := live[link.Attrs().Index]; ok {
+			continue
+		}
+		root, err := c.protector.getRootQdisc(link)
+		if err != nil {
+			return nil, err
+		}
+		if !isNetIngressQdisc(root) {
+			continue
+		}
+		artifacts = append(artifacts, orphanArtifact{
+			path: link.Attrs().Name,
+			id:   link.Attrs().Index,
+		})
+	}
+	return artifacts, nil
+}
+
+// Remove deletes the shaper only if the veth is not renamed or the index is not reused since it is listed.
+func (c *netIngressShaperCollector) Remove(artifact orphanArtifact) error {
+	return c.protector.deleteShaper(&netIngressRule{linkIndex: artifact.id, linkName: artifact.path})
+}
@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func Test_netIngressShaperCollector(t *testing.T) {
+	h := newFakeNetIngressHandle()
+	h.addLink(&netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: 1, Name: "eth0"}})
+	h.addLink(newTestVeth(10, "cali0"))
+	h.addLink(newTestVeth(11, "cali1"))
+	h.addLink(newTestVeth(12, "cali2"))
+	h.addLink(newTestVeth(13, "cali3"))
+	h.routes["10.1.0.1"] = 10
+	h.routes["10.1.0.2"] = 11
+	// the shaper of the live BE pod
+	h.qdiscs[10] = newNetIngressQdisc(&netIngressRule{linkIndex: 10, limitKbps: 1000, burstKB: 256})
+	// the shaper left on the veth of the LS pod
+	h.qdiscs[11] = newNetIngressQdisc(&netIngressRule{linkIndex: 11, limitKbps: 1000, burstKB: 256})
+	// the shaper left on the veth of no pod
+	h.qdiscs[12] = newNetIngressQdisc(&netIngressRule{linkIndex: 12, limitKbps: 1000, burstKB: 256})
+	// the root qdisc set by the others
+	h.qdiscs[13] = &netlink.Tbf{QdiscAttrs: netlink.QdiscAttrs{LinkIndex: 13, Handle: netlink.MakeHandle(1, 0), Parent: netlink.HANDLE_ROOT}}
+
+	c := &netIngressShaperCollector{protector: &NetIngressProtector{handle: h}}
+	pods := getPodMetas([]*corev1.Pod{
+		createNetIngressTestPod("test_be_pod", apiext.QoSBE, "10.1.0.1"),
+		createNetIngressTestPod("test_ls_pod", apiext.QoSLS, "10.1.0.2"),
+	})
+	artifacts, err := c.ListOrphans(pods)
+	assert.NoError(t, err)
+	assert.Equal(t, []orphanArtifact{
+		{path: "cali1", id: 11},
+		{path: "cali2", id: 12},
+	}, artifacts)
+
+	for _, artifact := range artifacts {
+		assert.NoError(t, c.Remove(artifact))
+	}
+	assert.Equal(t, []string{
+		"cali0: tbf 6b6f:0 rate 125000 limit 265269",
+		"cali3: tbf 1:0 rate 0 limit 0",
+	}, h.qdiscList())
+
+	// the veth renamed since listed is untouched
+	h.qdiscs[12] = newNetIngressQdisc(&netIngressRule{linkIndex: 12, limitKbps: 1000, burstKB: 256})
+	h.addLink(newTestVeth(12, "cali2-new"))
+	assert.NoError(t, c.Remove(orphanArtifact{path: "cali2", id: 12}))
+	assert.Len(t, h.qdiscList(), 3)
+}
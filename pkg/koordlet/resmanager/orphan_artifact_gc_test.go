@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	testLivePodUID     = "11111111-1111-1111-1111-111111111111"
+	testOrphanPodUID   = "22222222-2222-2222-2222-222222222222"
+	testBEQoSCgroupDir = "kubepods.slice/kubepods-besteffort.slice"
+)
+
+func testingBEPodCgroupDir(podUID string) string {
+	return filepath.Join(testBEQoSCgroupDir, "kubepods-besteffort-pod"+strings.ReplaceAll(podUID, "-", "_")+".slice")
+}
+
+func testingPrepareOrphanArtifacts(helper *system.FileTestUtil) {
+	// resctrl groups
+	helper.WriteFileContents("sys/resctrl/tasks", "")
+	helper.WriteFileContents("sys/resctrl/LSR/tasks", "")
+	helper.WriteFileContents("sys/resctrl/LS/tasks", "100\n")
+	helper.WriteFileContents("sys/resctrl/BE/tasks", "200\n201\n300\n400\n")
+	// the group not created by koordlet
+	helper.WriteFileContents("sys/resctrl/others/tasks", "202\n")
+	// the cgroups of the tasks, the task 400 has exited
+	helper.WriteFileContents("proc/100/cgroup", "0::/"+testingBEPodCgroupDir(testLivePodUID)+"/cri-containerd-100.scope\n")
+	helper.WriteFileContents("proc/200/cgroup", "0::/"+testingBEPodCgroupDir(testOrphanPodUID)+"/cri-containerd-200.scope\n")
+	helper.WriteFileContents("proc/201/cgroup", "0::/"+testingBEPodCgroupDir(testOrphanPodUID)+"/cri-containerd-201.scope\n")
+	helper.WriteFileContents("proc/202/cgroup", "0::/"+testingBEPodCgroupDir(testOrphanPodUID)+"/cri-containerd-202.scope\n")
+	helper.WriteFileContents("proc/300/cgroup", "0::/system.slice/sshd.service\n")
+	// the best-effort pod cgroup owned by the kubelet
+	helper.WriteFileContents(filepath.Join("cpu", testingBEPodCgroupDir(testOrphanPodUID), system.CPUProcsName), "")
+	// the resctrl groups created for the pods
+	helper.WriteFileContents("sys/resctrl/koordlet-"+testLivePodUID+"/tasks", "600\n")
+	helper.WriteFileContents("sys/resctrl/koordlet-"+testOrphanPodUID+"/tasks", "500\n501\n")
+	// the best-effort sub-cgroups created for the pods
+	for _, subsystem := range []string{"cpu", "memory"} {
+		helper.WriteFileContents(filepath.Join(subsystem, testBEQoSCgroupDir, system.CPUProcsName), "")
+		helper.WriteFileContents(filepath.Join(subsystem, testBEQoSCgroupDir, "koordlet-"+testLivePodUID, system.CPUProcsName), "800\n")
+		helper.WriteFileContents(filepath.Join(subsystem, testBEQoSCgroupDir, "koordlet-"+testOrphanPodUID, system.CPUProcsName), "700\n")
+	}
+}
+
+func testingNewOrphanArtifactGC(ctrl *gomock.Controller, dryRun bool) *OrphanArtifactGC {
+	statesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{
+		{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "live-pod", UID: types.UID(testLivePodUID)}}},
+	}).AnyTimes()
+	cfg := NewDefaultConfig()
+	cfg.OrphanArtifactGCDryRun = dryRun
+	g := NewOrphanArtifactGC(&resmanager{config: cfg, statesInformer: statesInformer})
+	// the collectors of the host network are tested apart
+	g.collectors = []artifactCollector{&resctrlTaskCollector{}, &resctrlGroupCollector{}, &beCgroupCollector{}}
+	return g
+}
+
+func testingSetupOrphanArtifactDirs(t *testing.T, helper *system.FileTestUtil) {
+	oldSysFSRootDir, oldProcRootDir := system.Conf.SysFSRootDir, system.Conf.ProcRootDir
+	system.Conf.SysFSRootDir = filepath.Join(helper.TempDir, "sys")
+	system.Conf.ProcRootDir = filepath.Join(helper.TempDir, "proc")
+	helper.SetCgroupsV2(false)
+	t.Cleanup(func() {
+		system.Conf.SysFSRootDir, system.Conf.ProcRootDir = oldSysFSRootDir, oldProcRootDir
+	})
+}
+
+func testingPathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestOrphanArtifactGC_gc(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	testingSetupOrphanArtifactDirs(t, helper)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(node)
+	defer metrics.Register(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testingPrepareOrphanArtifacts(helper)
+	g := testingNewOrphanArtifactGC(ctrl, false)
+
+	resctrlCounter := metrics.OrphanArtifactRemoved.WithLabelValues(node.Name, artifactTypeResctrlTask, "false")
+	resctrlBefore := testutil.ToFloat64(resctrlCounter)
+	groupCounter := metrics.OrphanArtifactRemoved.WithLabelValues(node.Name, artifactTypeResctrlGroup, "false")
+	groupBefore := testutil.ToFloat64(groupCounter)
+	cgroupCounter := metrics.OrphanArtifactRemoved.WithLabelValues(node.Name, artifactTypeBECgroup, "false")
+	cgroupBefore := testutil.ToFloat64(cgroupCounter)
+	orphanGroupDir := system.GetResctrlGroupRootDirPath("koordlet-" + testOrphanPodUID)
+	orphanCPUCgroupDir := filepath.Join(helper.TempDir, "cpu", testBEQoSCgroupDir, "koordlet-"+testOrphanPodUID)
+	orphanMemoryCgroupDir := filepath.Join(helper.TempDir, "memory", testBEQoSCgroupDir, "koordlet-"+testOrphanPodUID)
+
+	// the orphans found at the first time are only marked
+	g.gc()
+	assert.Equal(t, "", helper.ReadFileContents("sys/resctrl/tasks"))
+	assert.Equal(t, resctrlBefore, testutil.ToFloat64(resctrlCounter))
+	assert.Equal(t, groupBefore, testutil.ToFloat64(groupCounter))
+	assert.Equal(t, cgroupBefore, testutil.ToFloat64(cgroupCounter))
+	assert.True(t, testingPathExists(orphanGroupDir))
+	assert.True(t, testingPathExists(orphanCPUCgroupDir))
+
+	// the orphans are removed if still orphan, i.e. the tasks of the deleted pod are moved into the root group, and
+	// the groups and the cgroups of the deleted pod are removed after their tasks are moved out
+	g.gc()
+	assert.Equal(t, resctrlBefore+2, testutil.ToFloat64(resctrlCounter))
+	assert.Equal(t, groupBefore+1, testutil.ToFloat64(groupCounter))
+	assert.Equal(t, cgroupBefore+2, testutil.ToFloat64(cgroupCounter))
+	assert.Equal(t, "200\n201\n500\n501\n", sortedTasks(helper.ReadFileContents("sys/resctrl/tasks")))
+	assert.False(t, testingPathExists(orphanGroupDir))
+	assert.Equal(t, "700\n", helper.ReadFileContents(filepath.Join("cpu", testBEQoSCgroupDir, system.CPUProcsName)))
+	assert.Equal(t, "700\n", helper.ReadFileContents(filepath.Join("memory", testBEQoSCgroupDir, system.CPUProcsName)))
+	assert.False(t, testingPathExists(orphanCPUCgroupDir))
+	assert.False(t, testingPathExists(orphanMemoryCgroupDir))
+
+	// the group and the cgroups of the live pod are kept
+	assert.True(t, testingPathExists(system.GetResctrlGroupRootDirPath("koordlet-"+testLivePodUID)))
+	assert.True(t, testingPathExists(filepath.Join(helper.TempDir, "cpu", testBEQoSCgroupDir, "koordlet-"+testLivePodUID)))
+	assert.True(t, testingPathExists(filepath.Join(helper.TempDir, "memory", testBEQoSCgroupDir, "koordlet-"+testLivePodUID)))
+
+	// the QoS-level groups, the group created by the others and the pod cgroup owned by the kubelet are kept
+	assert.True(t, testingPathExists(system.GetResctrlGroupRootDirPath("LS")))
+	assert.True(t, testingPathExists(system.GetResctrlGroupRootDirPath("BE")))
+	assert.True(t, testingPathExists(system.GetResctrlGroupRootDirPath("others")))
+	assert.True(t, testingPathExists(filepath.Join(helper.TempDir, "cpu", testingBEPodCgroupDir(testOrphanPodUID))))
+}
+
+func TestOrphanArtifactGC_gcDryRun(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	testingSetupOrphanArtifactDirs(t, helper)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	metrics.Register(node)
+	defer metrics.Register(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testingPrepareOrphanArtifacts(helper)
+	g := testingNewOrphanArtifactGC(ctrl, true)
+
+	dryRunCounter := metrics.OrphanArtifactRemoved.WithLabelValues(node.Name, artifactTypeResctrlTask, "true")
+	before := testutil.ToFloat64(dryRunCounter)
+	groupDryRunCounter := metrics.OrphanArtifactRemoved.WithLabelValues(node.Name, artifactTypeResctrlGroup, "true")
+	groupBefore := testutil.ToFloat64(groupDryRunCounter)
+	cgroupDryRunCounter := metrics.OrphanArtifactRemoved.WithLabelValues(node.Name, artifactTypeBECgroup, "true")
+	cgroupBefore := testutil.ToFloat64(cgroupDryRunCounter)
+
+	g.gc()
+	g.gc()
+	assert.Equal(t, before+2, testutil.ToFloat64(dryRunCounter))
+	assert.Equal(t, groupBefore+1, testutil.ToFloat64(groupDryRunCounter))
+	assert.Equal(t, cgroupBefore+2, testutil.ToFloat64(cgroupDryRunCounter))
+	assert.Equal(t, "", helper.ReadFileContents("sys/resctrl/tasks"))
+	assert.True(t, testingPathExists(system.GetResctrlGroupRootDirPath("koordlet-"+testOrphanPodUID)))
+	assert.Equal(t, "", helper.ReadFileContents(filepath.Join("cpu", testBEQoSCgroupDir, system.CPUProcsName)))
+	assert.True(t, testingPathExists(filepath.Join(helper.TempDir, "cpu", testBEQoSCgroupDir, "koordlet-"+testOrphanPodUID)))
+}
+
+func TestOrphanArtifactGC_gcWithoutArtifacts(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	testingSetupOrphanArtifactDirs(t, helper)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// resctrl does not exist
+	g := testingNewOrphanArtifactGC(ctrl, false)
+	g.gc()
+	g.gc()
+	assert.Equal(t, 0, g.candidates.Len())
+}
+
+func Test_beCgroupCollector_ListOrphansV2(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.SetCgroupsV2(true)
+
+	helper.WriteFileContents(filepath.Join(testBEQoSCgroupDir, "koordlet-"+testLivePodUID, system.CPUProcsName), "")
+	helper.WriteFileContents(filepath.Join(testBEQoSCgroupDir, "koordlet-"+testOrphanPodUID, system.CPUProcsName), "")
+	// the pod cgroup owned by the kubelet
+	helper.WriteFileContents(filepath.Join(testingBEPodCgroupDir(testOrphanPodUID), system.CPUProcsName), "")
+
+	c := &beCgroupCollector{}
+	artifacts, err := c.ListOrphans([]*statesinformer.PodMeta{
+		{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "live-pod", UID: types.UID(testLivePodUID)}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []orphanArtifact{
+		{owner: testOrphanPodUID, path: filepath.Join(helper.TempDir, testBEQoSCgroupDir, "koordlet-"+testOrphanPodUID)},
+	}, artifacts)
+}
+
+// sortedTasks sorts the lines of the tasks since the tasks are moved in a random order.
+func sortedTasks(contents string) string {
+	lines := strings.Fields(contents)
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
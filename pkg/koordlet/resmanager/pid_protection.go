@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	// pidMetricsWindowSeconds is the time window to query the latest process metrics, which covers several
+	// intervals of the process collector.
+	pidMetricsWindowSeconds = 60
+)
+
+// PIDProtector protects the node from the pid exhaustion caused by the BE pods. It limits the tasks of each BE pod by
+// the pids.max of the pod cgroup, and evicts the BE pod with the most tasks when the node pid usage reaches the
+// threshold in NodeSLO.
+type PIDProtector struct {
+	resManager    *resmanager
+	executor      resourceexecutor.ResourceUpdateExecutor
+	lastEvictTime time.Time
+}
+
+type podPIDsInfo struct {
+	pod *corev1.Pod
+	// pids is the number of the tasks of the pod, -1 if unknown
+	pids int64
+}
+
+func NewPIDProtector(mgr *resmanager) *PIDProtector {
+	return &PIDProtector{
+		resManager:    mgr,
+		executor:      resourceexecutor.NewResourceUpdateExecutor(),
+		lastEvictTime: time.Now(),
+	}
+}
+
+func (p *PIDProtector) RunInit(stopCh <-chan struct{}) error {
+	p.executor.Run(stopCh)
+	return nil
+}
+
+func (p *PIDProtector) protect() {
+	klog.V(5).Infof("starting pid protection process")
+	defer klog.V(5).Infof("pid protection process completed")
+
+	nodeSLO := p.resManager.getNodeSLOCopy()
+	if disabled, err := isFeatureDisabled(nodeSLO, features.BEPIDProtection); err != nil {
+		klog.Errorf("failed to acquire pid protection feature-gate, error: %v", err)
+		return
+	} else if disabled {
+		klog.V(5).Infof("skip pid protection, disabled in NodeSLO")
+		return
+	}
+	thresholdConfig := nodeSLO.Spec.ResourceUsedThresholdWithBE
+
+	bePods := getBEPodMetas(p.resManager.getManagedPods(features.BEPIDProtection))
+	p.limitBEPodPIDs(bePods, thresholdConfig.BEPodPIDsMax)
+
+	thresholdPercent := thresholdConfig.PIDEvictThresholdPercent
+	if thresholdPercent == nil {
+		klog.V(5).Infof("skip pid evict, threshold percent is nil")
+		return
+	} else if *thresholdPercent <= 0 {
+		klog.Warningf("skip pid evict, threshold percent(%v) should greater than 0", *thresholdPercent)
+		return
+	}
+	if time.Now().Before(p.lastEvictTime.Add(time.Duration(p.resManager.config.PIDEvictCoolTimeSeconds) * time.Second)) {
+		klog.V(5).Infof("skip pid evict, still in evict cooling time")
+		return
+	}
+
+	queryParam := generateQueryParamsLast(pidMetricsWindowSeconds)
+	nodeResult := p.resManager.metricCache.GetNodeProcessMetric(queryParam)
+	if nodeResult.Error != nil || nodeResult.Metric == nil {
+		klog.Warningf("skip pid evict, get node process metric failed, error: %v", nodeResult.Error)
+		return
+	}
+	nodeMetric := nodeResult.Metric
+	if nodeMetric.PIDsMax <= 0 {
+		klog.Warningf("skip pid evict, node pid max(%v) should greater than 0", nodeMetric.PIDsMax)
+		return
+	}
+	nodePIDUsage := nodeMetric.PIDs * 100 / nodeMetric.PIDsMax
+	if nodePIDUsage < *thresholdPercent {
+		klog.V(5).Infof("skip pid evict, node pid usage(%v) is below threshold(%v)", nodePIDUsage, *thresholdPercent)
+		return
+	}
+
+	node := p.resManager.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("skip pid evict, Node %v is nil", p.resManager.nodeName)
+		return
+	}
+	klog.Infof("node(%v) PIDs(%v), PIDsMax(%v), pidUsage: %.2f, evictThresholdUsage: %.2f",
+		p.resManager.nodeName, nodeMetric.PIDs, nodeMetric.PIDsMax, float64(nodePIDUsage)/100, float64(*thresholdPercent)/100)
+
+	victim := p.selectVictim(bePods, queryParam)
+	if victim == nil {
+		klog.Warningf("skip pid evict, no BE pod to evict")
+		return
+	}
+	message := fmt.Sprintf("killAndEvictBEPod for node(%v), node pid usage %v%% reaches threshold %v%%, pod pids: %v",
+		p.resManager.nodeName, nodePIDUsage, *thresholdPercent, victim.pids)
+	killContainers(victim.pod, fmt.Sprintf("%v, kill pod: %v", message, victim.pod.Name))
+	p.resManager.evictPodIfNotEvicted(victim.pod, node, resourceexecutor.EvictPodByNodePIDUsage, message)
+	p.lastEvictTime = time.Now()
+}
+
+// limitBEPodPIDs sets the pids.max of the BE pod cgroups, and leaves them untouched if pidsMax is nil.
+func (p *PIDProtector) limitBEPodPIDs(bePods []*statesinformer.PodMeta, pidsMax *int64) {
+	if pidsMax == nil {
+		klog.V(5).Infof("skip limiting BE pod pids, pids max is nil")
+		return
+	}
+	value := strconv.FormatInt(*pidsMax, 10)
+	var updaters []resourceexecutor.ResourceUpdater
+	for _, podMeta := range bePods {
+		podDir := koordletutil.GetPodCgroupDirWithKube(podMeta.CgroupDir)
+		updater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.PidsMaxName, podDir, value)
+		if err != nil {
+			klog.V(4).Infof("failed to get pids.max updater for pod %s/%s, err: %v",
+				podMeta.Pod.Namespace, podMeta.Pod.Name, err)
+			continue
+		}
+		updaters = append(updaters, updater)
+	}
+	p.executor.UpdateBatch(true, updaters...)
+}
+
+// selectVictim returns the BE pod to evict first, which is the one with the most tasks among the pods of the lowest
+// priority band and deletion cost.
+func (p *PIDProtector) selectVictim(bePods []*statesinformer.PodMeta, queryParam *metriccache.QueryParam) *podPIDsInfo {
+	var infos []*podPIDsInfo
+	for _, podMeta := range bePods {
+		infos = append(infos, &podPIDsInfo{
+			pod:  podMeta.Pod,
+			pids: p.getPodPIDs(podMeta.Pod, queryParam),
+		})
+	}
+	if len(infos) <= 0 {
+		return nil
+	}
+	sortEvictionVictims(infos, func(i int) evictionVictim {
+		return evictionVictim{
+			pod:        infos[i].pod,
+			usage:      float64(infos[i].pids),
+			usageKnown: infos[i].pids >= 0,
+		}
+	})
+	return infos[0]
+}
+
+// getPodPIDs returns the sum of the tasks of the pod containers, -1 if none is collected.
+func (p *PIDProtector) getPodPIDs(pod *corev1.Pod, queryParam *metriccache.QueryParam) int64 {
+	pids, known := int64(0), false
+	for i := range pod.Status.ContainerStatuses {
+		containerID := pod.Status.ContainerStatuses[i].ContainerID
+		result := p.resManager.metricCache.GetContainerProcessMetric(&containerID, queryParam)
+		if result.Error != nil || result.Metric == nil {
+			klog.V(5).Infof("get container %v process metric failed, error %v", containerID, result.Error)
+			continue
+		}
+		pids += result.Metric.PIDs
+		known = true
+	}
+	if !known {
+		return -1
+	}
+	return pids
+}
+
+func getBEPodMetas(podMetas []*statesinformer.PodMeta) []*statesinformer.PodMeta {
+	var bePods []*statesinformer.PodMeta
+	for _, podMeta := range podMetas {
+		if podMeta.Pod != nil && extension.GetPodQoSClass(podMeta.Pod) == extension.QoSBE {
+			bePods = append(bePods, podMeta)
+		}
+	}
+	return bePods
+}
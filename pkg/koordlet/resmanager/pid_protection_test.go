@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime/handler"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cache"
+)
+
+func Test_PIDProtector_selectVictim(t *testing.T) {
+	tests := []struct {
+		name           string
+		pods           []*corev1.Pod
+		podPIDs        map[string]int64
+		wantVictim     string
+		wantVictimPIDs int64
+	}{
+		{
+			name:       "no BE pod",
+			pods:       nil,
+			wantVictim: "",
+		},
+		{
+			name: "evict the pod with the most pids",
+			pods: []*corev1.Pod{
+				createMemoryEvictTestPod("test_be_pod_0", apiext.QoSBE, 5000),
+				createMemoryEvictTestPod("test_be_pod_1", apiext.QoSBE, 5000),
+				createMemoryEvictTestPod("test_be_pod_2", apiext.QoSBE, 5000),
+			},
+			podPIDs: map[string]int64{
+				"test_be_pod_0": 100,
+				"test_be_pod_1": 20000,
+				"test_be_pod_2": 300,
+			},
+			wantVictim:     "test_be_pod_1",
+			wantVictimPIDs: 20000,
+		},
+		{
+			name: "evict the pod of the lower priority first",
+			pods: []*corev1.Pod{
+				createMemoryEvictTestPod("test_be_pod_0", apiext.QoSBE, 5000),
+				createMemoryEvictTestPod("test_be_pod_1", apiext.QoSBE, 5000),
+				createMemoryEvictTestPod("test_be_pod_2", apiext.QoSBE, 4000),
+			},
+			podPIDs: map[string]int64{
+				"test_be_pod_0": 100,
+				"test_be_pod_1": 20000,
+				"test_be_pod_2": 300,
+			},
+			wantVictim:     "test_be_pod_2",
+			wantVictimPIDs: 300,
+		},
+		{
+			name: "evict the pod of unknown pids after the others",
+			pods: []*corev1.Pod{
+				createMemoryEvictTestPod("test_be_pod_0", apiext.QoSBE, 5000),
+				createMemoryEvictTestPod("test_be_pod_1", apiext.QoSBE, 5000),
+			},
+			podPIDs: map[string]int64{
+				"test_be_pod_1": 10,
+			},
+			wantVictim:     "test_be_pod_1",
+			wantVictimPIDs: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			for _, pod := range tt.pods {
+				containerID := pod.Status.ContainerStatuses[0].ContainerID
+				result := metriccache.ContainerProcessQueryResult{}
+				if pids, ok := tt.podPIDs[pod.Name]; ok {
+					result.Metric = &metriccache.ContainerProcessMetric{PodUID: string(pod.UID), ContainerID: containerID, PIDs: pids}
+				} else {
+					result.Error = fmt.Errorf("metric not exist")
+				}
+				mockMetricCache.EXPECT().GetContainerProcessMetric(&containerID, gomock.Any()).Return(result).AnyTimes()
+			}
+
+			p := NewPIDProtector(&resmanager{metricCache: mockMetricCache, config: NewDefaultConfig()})
+			got := p.selectVictim(getPodMetas(tt.pods), generateQueryParamsLast(pidMetricsWindowSeconds))
+			if tt.wantVictim == "" {
+				assert.Nil(t, got)
+				return
+			}
+			assert.NotNil(t, got)
+			assert.Equal(t, tt.wantVictim, got.pod.Name)
+			assert.Equal(t, tt.wantVictimPIDs, got.pids)
+		})
+	}
+}
+
+func Test_PIDProtector_protect(t *testing.T) {
+	tests := []struct {
+		name               string
+		thresholdConfig    *slov1alpha1.ResourceThresholdStrategy
+		nodeMetric         *metriccache.NodeProcessMetric
+		wantPIDsMax        string
+		expectEvictPods    []string
+		expectNotEvictPods []string
+	}{
+		{
+			name: "disabled in NodeSLO",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.Bool(false),
+				BEPodPIDsMax:             pointer.Int64(1000),
+				PIDEvictThresholdPercent: pointer.Int64(80),
+			},
+			nodeMetric:         &metriccache.NodeProcessMetric{PIDs: 30000, PIDsMax: 32768},
+			wantPIDsMax:        "",
+			expectNotEvictPods: []string{"test_lsr_pod", "test_be_pod_0", "test_be_pod_1"},
+		},
+		{
+			name: "limit BE pods without evict",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:       pointer.Bool(true),
+				BEPodPIDsMax: pointer.Int64(1000),
+			},
+			nodeMetric:         &metriccache.NodeProcessMetric{PIDs: 30000, PIDsMax: 32768},
+			wantPIDsMax:        "1000",
+			expectNotEvictPods: []string{"test_lsr_pod", "test_be_pod_0", "test_be_pod_1"},
+		},
+		{
+			name: "node pid usage below threshold",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.Bool(true),
+				PIDEvictThresholdPercent: pointer.Int64(80),
+			},
+			nodeMetric:         &metriccache.NodeProcessMetric{PIDs: 10000, PIDsMax: 32768},
+			wantPIDsMax:        "",
+			expectNotEvictPods: []string{"test_lsr_pod", "test_be_pod_0", "test_be_pod_1"},
+		},
+		{
+			name: "evict the BE pod with the most pids",
+			thresholdConfig: &slov1alpha1.ResourceThresholdStrategy{
+				Enable:                   pointer.Bool(true),
+				BEPodPIDsMax:             pointer.Int64(20000),
+				PIDEvictThresholdPercent: pointer.Int64(80),
+			},
+			nodeMetric:         &metriccache.NodeProcessMetric{PIDs: 30000, PIDsMax: 32768},
+			wantPIDsMax:        "20000",
+			expectEvictPods:    []string{"test_be_pod_1"},
+			expectNotEvictPods: []string{"test_lsr_pod", "test_be_pod_0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			system.SetupCgroupPathFormatter(system.Systemd)
+
+			pods := []*corev1.Pod{
+				createMemoryEvictTestPod("test_lsr_pod", apiext.QoSLSR, 9000),
+				createMemoryEvictTestPod("test_be_pod_0", apiext.QoSBE, 5000),
+				createMemoryEvictTestPod("test_be_pod_1", apiext.QoSBE, 5000),
+			}
+			podPIDs := map[string]int64{"test_lsr_pod": 50000, "test_be_pod_0": 100, "test_be_pod_1": 20000}
+			podMetas := getPodMetas(pods)
+			for _, podMeta := range podMetas {
+				helper.WriteCgroupFileContents(koordletutil.GetPodCgroupDirWithKube(podMeta.CgroupDir), system.PidsMax, "")
+			}
+
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(podMetas).AnyTimes()
+			mockStatesInformer.EXPECT().GetNode().Return(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}).AnyTimes()
+			mockStatesInformer.EXPECT().GetNodeSLO().Return(getNodeSLOByThreshold(tt.thresholdConfig)).AnyTimes()
+			mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+			mockMetricCache.EXPECT().GetNodeProcessMetric(gomock.Any()).Return(metriccache.NodeProcessQueryResult{Metric: tt.nodeMetric}).AnyTimes()
+			for _, pod := range pods {
+				containerID := pod.Status.ContainerStatuses[0].ContainerID
+				mockMetricCache.EXPECT().GetContainerProcessMetric(&containerID, gomock.Any()).Return(metriccache.ContainerProcessQueryResult{
+					Metric: &metriccache.ContainerProcessMetric{PodUID: string(pod.UID), ContainerID: containerID, PIDs: podPIDs[pod.Name]},
+				}).AnyTimes()
+			}
+
+			client := clientsetfake.NewSimpleClientset()
+			for _, pod := range pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			runtime.DockerHandler = handler.NewFakeRuntimeHandler()
+			r := &resmanager{
+				statesInformer: mockStatesInformer,
+				podsEvicted:    cache.NewCacheDefault(),
+				eventRecorder:  &FakeRecorder{},
+				metricCache:    mockMetricCache,
+				kubeClient:     client,
+				config:         NewDefaultConfig(),
+			}
+			stop := make(chan struct{})
+			defer close(stop)
+			_ = r.podsEvicted.Run(stop)
+
+			p := NewPIDProtector(r)
+			p.executor = &resourceexecutor.ResourceUpdateExecutorImpl{
+				ResourceCache: cache.NewCacheDefault(),
+				Config:        resourceexecutor.NewDefaultConfig(),
+			}
+			assert.NoError(t, p.RunInit(stop))
+			p.lastEvictTime = time.Now().Add(-time.Duration(r.config.PIDEvictCoolTimeSeconds+1) * time.Second)
+			p.protect()
+
+			for i, podMeta := range podMetas {
+				got := helper.ReadCgroupFileContents(koordletutil.GetPodCgroupDirWithKube(podMeta.CgroupDir), system.PidsMax)
+				if i == 0 {
+					// the LS pod is never limited
+					assert.Equal(t, "", got, podMeta.Pod.Name)
+					continue
+				}
+				assert.Equal(t, tt.wantPIDsMax, got, podMeta.Pod.Name)
+			}
+			for _, name := range tt.expectEvictPods {
+				getEvictObject, err := client.Tracker().Get(podsResource, "", name)
+				assert.NoError(t, err)
+				assert.IsType(t, &policyv1beta1.Eviction{}, getEvictObject, name)
+			}
+			for _, name := range tt.expectNotEvictPods {
+				getObject, err := client.Tracker().Get(podsResource, "", name)
+				assert.NoError(t, err)
+				assert.IsType(t, &corev1.Pod{}, getObject, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"math"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	// legal range of CPUBurstConfig.CPUBurstPercent
+	minCPUBurstPercent = 0
+	maxCPUBurstPercent = 10000
+)
+
+// getPodEnvHint returns the value of the env in the pod spec where the first declaration among the containers wins.
+// Envs referring to other sources (valueFrom) are ignored.
+func getPodEnvHint(pod *corev1.Pod, name string) (string, bool) {
+	for i := range pod.Spec.Containers {
+		for _, env := range pod.Spec.Containers[i].Env {
+			if env.Name == name && env.ValueFrom == nil {
+				return env.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// getPodCPUBurstEnvHints parses the cpu burst hints from the container envs, nil if no valid hint is found.
+func getPodCPUBurstEnvHints(pod *corev1.Pod) *slov1alpha1.CPUBurstConfig {
+	if pod == nil {
+		return nil
+	}
+	var cfg *slov1alpha1.CPUBurstConfig
+	if value, ok := getPodEnvHint(pod, apiext.EnvPodCPUBurst); ok {
+		switch policy := slov1alpha1.CPUBurstPolicy(value); policy {
+		case slov1alpha1.CPUBurstNone, slov1alpha1.CPUBurstAuto, slov1alpha1.CPUBurstOnly, slov1alpha1.CFSQuotaBurstOnly:
+			cfg = &slov1alpha1.CPUBurstConfig{Policy: policy}
+		default:
+			klog.V(4).Infof("ignore illegal env %s=%s of pod %s", apiext.EnvPodCPUBurst, value, util.GetPodKey(pod))
+		}
+	}
+	if value, ok := getPodEnvHint(pod, apiext.EnvPodCPUBurstPercent); ok {
+		percent, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			klog.V(4).Infof("ignore illegal env %s=%s of pod %s, err: %v",
+				apiext.EnvPodCPUBurstPercent, value, util.GetPodKey(pod), err)
+		} else {
+			if cfg == nil {
+				cfg = &slov1alpha1.CPUBurstConfig{}
+			}
+			percent = util.MaxInt64(util.MinInt64(percent, maxCPUBurstPercent), minCPUBurstPercent)
+			cfg.CPUBurstPercent = pointer.Int64Ptr(percent)
+		}
+	}
+	return cfg
+}
+
+// getPodMemoryQoSEnvHints parses the memory qos hints from the container envs, nil if no valid hint is found.
+func getPodMemoryQoSEnvHints(pod *corev1.Pod) *slov1alpha1.MemoryQOS {
+	if pod == nil {
+		return nil
+	}
+	var cfg *slov1alpha1.MemoryQOS
+	parse := func(name string) *int64 {
+		value, ok := getPodEnvHint(pod, name)
+		if !ok {
+			return nil
+		}
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(ratio) {
+			klog.V(4).Infof("ignore illegal env %s=%s of pod %s, err: %v", name, value, util.GetPodKey(pod), err)
+			return nil
+		}
+		if cfg == nil {
+			cfg = &slov1alpha1.MemoryQOS{}
+		}
+		// the ratio is clamped into [0, 1], i.e. the percent is in [0, 100]
+		ratio = math.Max(math.Min(ratio, 1), 0)
+		return pointer.Int64Ptr(int64(math.Round(ratio * 100)))
+	}
+	minLimitPercent := parse(apiext.EnvPodMemoryMinRatio)
+	lowLimitPercent := parse(apiext.EnvPodMemoryLowRatio)
+	throttlingPercent := parse(apiext.EnvPodMemoryHighRatio)
+	if cfg == nil {
+		return nil
+	}
+	cfg.MinLimitPercent = minLimitPercent
+	cfg.LowLimitPercent = lowLimitPercent
+	cfg.ThrottlingPercent = throttlingPercent
+	return cfg
+}
+
+// mergeCPUBurstEnvHints returns the node-level config completed by the env hints of the pod.
+// The fields specified in the node-level config are not overwritten.
+func mergeCPUBurstEnvHints(pod *corev1.Pod, nodeCfg *slov1alpha1.CPUBurstConfig) *slov1alpha1.CPUBurstConfig {
+	envCfg := getPodCPUBurstEnvHints(pod)
+	if envCfg == nil {
+		return nodeCfg
+	}
+	if nodeCfg == nil {
+		return envCfg
+	}
+	merged, err := util.MergeCfg(envCfg, nodeCfg.DeepCopy())
+	if err != nil {
+		klog.V(4).Infof("failed to merge cpu burst env hints of pod %s, err: %v", util.GetPodKey(pod), err)
+		return nodeCfg
+	}
+	return merged.(*slov1alpha1.CPUBurstConfig)
+}
+
+// mergeMemoryQoSEnvHints completes the memory qos config with the env hints of the pod.
+// The fields specified in the config are not overwritten.
+func mergeMemoryQoSEnvHints(pod *corev1.Pod, cfg *slov1alpha1.MemoryQOS) {
+	envCfg := getPodMemoryQoSEnvHints(pod)
+	if envCfg == nil {
+		return
+	}
+	if cfg.MinLimitPercent == nil {
+		cfg.MinLimitPercent = envCfg.MinLimitPercent
+	}
+	if cfg.LowLimitPercent == nil {
+		cfg.LowLimitPercent = envCfg.LowLimitPercent
+	}
+	if cfg.ThrottlingPercent == nil {
+		cfg.ThrottlingPercent = envCfg.ThrottlingPercent
+	}
+}
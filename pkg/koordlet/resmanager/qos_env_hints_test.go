@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func newTestPodWithEnvs(annotations map[string]string, envs ...[]corev1.EnvVar) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-pod",
+			Namespace:   "default",
+			Annotations: annotations,
+			Labels: map[string]string{
+				apiext.LabelPodQoS: string(apiext.QoSLS),
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+	for i := range envs {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+			Name: "test-container",
+			Env:  envs[i],
+		})
+	}
+	return pod
+}
+
+func Test_getPodCPUBurstEnvHints(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want *slov1alpha1.CPUBurstConfig
+	}{
+		{
+			name: "no env hints",
+			pod:  newTestPodWithEnvs(nil, []corev1.EnvVar{{Name: "FOO", Value: "bar"}}),
+			want: nil,
+		},
+		{
+			name: "parse policy and percent",
+			pod: newTestPodWithEnvs(nil, []corev1.EnvVar{
+				{Name: apiext.EnvPodCPUBurst, Value: string(slov1alpha1.CPUBurstAuto)},
+				{Name: apiext.EnvPodCPUBurstPercent, Value: "500"},
+			}),
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy:          slov1alpha1.CPUBurstAuto,
+				CPUBurstPercent: pointer.Int64Ptr(500),
+			},
+		},
+		{
+			name: "ignore illegal policy and clamp percent",
+			pod: newTestPodWithEnvs(nil, []corev1.EnvVar{
+				{Name: apiext.EnvPodCPUBurst, Value: "always"},
+				{Name: apiext.EnvPodCPUBurstPercent, Value: "20000"},
+			}),
+			want: &slov1alpha1.CPUBurstConfig{
+				CPUBurstPercent: pointer.Int64Ptr(maxCPUBurstPercent),
+			},
+		},
+		{
+			name: "first container declaration wins",
+			pod: newTestPodWithEnvs(nil,
+				[]corev1.EnvVar{{Name: apiext.EnvPodCPUBurst, Value: string(slov1alpha1.CPUBurstOnly)}},
+				[]corev1.EnvVar{{Name: apiext.EnvPodCPUBurst, Value: string(slov1alpha1.CPUBurstAuto)}},
+			),
+			want: &slov1alpha1.CPUBurstConfig{
+				Policy: slov1alpha1.CPUBurstOnly,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getPodCPUBurstEnvHints(tt.pod))
+		})
+	}
+}
+
+func Test_getPodMemoryQoSEnvHints(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want *slov1alpha1.MemoryQOS
+	}{
+		{
+			name: "no env hints",
+			pod:  newTestPodWithEnvs(nil, nil),
+			want: nil,
+		},
+		{
+			name: "parse and clamp ratios",
+			pod: newTestPodWithEnvs(nil, []corev1.EnvVar{
+				{Name: apiext.EnvPodMemoryMinRatio, Value: "-0.5"},
+				{Name: apiext.EnvPodMemoryLowRatio, Value: "illegal"},
+				{Name: apiext.EnvPodMemoryHighRatio, Value: "1.5"},
+			}),
+			want: &slov1alpha1.MemoryQOS{
+				MinLimitPercent:   pointer.Int64Ptr(0),
+				ThrottlingPercent: pointer.Int64Ptr(100),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getPodMemoryQoSEnvHints(tt.pod))
+		})
+	}
+}
+
+func Test_genPodBurstConfig_EnvHintsPrecedence(t *testing.T) {
+	pod := newTestPodWithEnvs(map[string]string{
+		apiext.AnnotationPodCPUBurst: `{"cpuBurstPercent":300}`,
+	}, []corev1.EnvVar{
+		{Name: apiext.EnvPodCPUBurst, Value: string(slov1alpha1.CPUBurstAuto)},
+		{Name: apiext.EnvPodCPUBurstPercent, Value: "500"},
+	})
+	nodeCfg := &slov1alpha1.CPUBurstConfig{
+		CPUBurstPercent:      pointer.Int64Ptr(1000),
+		CFSQuotaBurstPercent: pointer.Int64Ptr(300),
+	}
+	want := &slov1alpha1.CPUBurstConfig{
+		Policy:               slov1alpha1.CPUBurstAuto, // from env since unset by node and annotation
+		CPUBurstPercent:      pointer.Int64Ptr(300),    // from annotation
+		CFSQuotaBurstPercent: pointer.Int64Ptr(300),    // from node
+	}
+	assert.Equal(t, want, genPodBurstConfig(pod, nodeCfg))
+	// node config is not modified
+	assert.Equal(t, &slov1alpha1.CPUBurstConfig{
+		CPUBurstPercent:      pointer.Int64Ptr(1000),
+		CFSQuotaBurstPercent: pointer.Int64Ptr(300),
+	}, nodeCfg)
+
+	// node policy takes precedence over env
+	nodeCfg.Policy = slov1alpha1.CPUBurstNone
+	got := genPodBurstConfig(pod, nodeCfg)
+	assert.Equal(t, slov1alpha1.CPUBurstNone, got.Policy)
+}
+
+func TestCgroupResourcesReconcile_getMergedPodResourceQoS_EnvHintsPrecedence(t *testing.T) {
+	pod := newTestPodWithEnvs(map[string]string{
+		apiext.AnnotationPodMemoryQoS: `{"throttlingPercent":80}`,
+	}, []corev1.EnvVar{
+		{Name: apiext.EnvPodMemoryMinRatio, Value: "0.5"},
+		{Name: apiext.EnvPodMemoryLowRatio, Value: "0.9"},
+		{Name: apiext.EnvPodMemoryHighRatio, Value: "0.9"},
+	})
+	nodeCfg := &slov1alpha1.ResourceQOS{
+		MemoryQOS: &slov1alpha1.MemoryQOSCfg{
+			Enable: pointer.BoolPtr(true),
+			MemoryQOS: slov1alpha1.MemoryQOS{
+				LowLimitPercent: pointer.Int64Ptr(10),
+			},
+		},
+	}
+	want := &slov1alpha1.ResourceQOS{
+		MemoryQOS: &slov1alpha1.MemoryQOSCfg{
+			Enable: pointer.BoolPtr(true),
+			MemoryQOS: slov1alpha1.MemoryQOS{
+				MinLimitPercent:   pointer.Int64Ptr(50), // from env since unset by node and annotation
+				LowLimitPercent:   pointer.Int64Ptr(10), // from node
+				ThrottlingPercent: pointer.Int64Ptr(80), // from annotation
+			},
+		},
+	}
+	c := CgroupResourcesReconcile{resmanager: &resmanager{config: NewDefaultConfig()}}
+	got, err := c.getMergedPodResourceQoS(pod, nodeCfg)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
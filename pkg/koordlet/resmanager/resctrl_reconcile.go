@@ -26,7 +26,9 @@ import (
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
@@ -54,6 +56,8 @@ type ResctrlReconcile struct {
 	resManager   *resmanager
 	executor     resourceexecutor.ResourceUpdateExecutor
 	cgroupReader resourceexecutor.CgroupReader
+	// restartStorm detects the crash-looping containers whose tasks are not moved until they stabilize.
+	restartStorm *statesinformer.RestartStormDetector
 }
 
 func NewResctrlReconcile(resManager *resmanager) *ResctrlReconcile {
@@ -62,6 +66,7 @@ func NewResctrlReconcile(resManager *resmanager) *ResctrlReconcile {
 		resManager:   resManager,
 		executor:     e,
 		cgroupReader: resManager.cgroupReader,
+		restartStorm: statesinformer.NewDefaultRestartStormDetector(),
 	}
 }
 
@@ -169,6 +174,13 @@ func (r *ResctrlReconcile) getPodCgroupNewTaskIds(podMeta *statesinformer.PodMet
 				pod.Name, containerStat.Name)
 			continue
 		}
+		// moving the tasks of a restart storming container is expensive and useless, defer it until it stabilizes
+		if r.restartStorm.InStorm(pod, containerStat.Name) {
+			klog.V(5).Infof("container %s/%s/%s is in restart storm, defer moving its tasks to the resctrl group",
+				pod.Namespace, pod.Name, container.Name)
+			metrics.RecordQoSApplicationDeferred(string(system.ResctrlTasks.ResourceType()))
+			continue
+		}
 
 		containerDir, err := koordletutil.GetContainerCgroupPathWithKube(podMeta.CgroupDir, &containerStat)
 		if err != nil {
@@ -322,6 +334,10 @@ func (r *ResctrlReconcile) reconcileCatResctrlPolicy(qosStrategy *slov1alpha1.Re
 		if err != nil {
 			klog.Warningf("failed to apply l3 cat policy for group %v, err: %v", group, err)
 		}
+		if group == BEResctrlGroup && features.DefaultKoordletFeatureGate.Enabled(features.MBAFeedback) {
+			// the MBA of BE group is adjusted by the memory bandwidth feedback
+			continue
+		}
 		err = r.calculateAndApplyCatMbPolicyForGroup(group, l3Num, resQoSStrategy)
 		if err != nil {
 			klog.Warningf("failed to apply cat MB policy for group %v, err: %v", group, err)
@@ -347,7 +363,8 @@ func (r *ResctrlReconcile) reconcileResctrlGroups(qosStrategy *slov1alpha1.Resou
 	}
 
 	taskIds := map[string][]int32{}
-	podsMeta := r.resManager.statesInformer.GetAllPods()
+	podsMeta := r.resManager.getManagedPods(features.RdtResctrl)
+	r.restartStorm.Update(podsMeta)
 	for _, podMeta := range podsMeta {
 		pod := podMeta.Pod
 		// only Running and Pending pods are considered
@@ -21,11 +21,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -475,6 +477,54 @@ func Test_getPodCgroupNewTaskIds(t *testing.T) {
 	}
 }
 
+func Test_getPodCgroupNewTaskIdsInRestartStorm(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	testingPrepareContainerCgroupCPUTasks(t, helper, "kubepods.slice/p0/cri-containerd-c0.scope", "122450\n122454")
+	system.CommonRootDir = ""
+
+	newPodMeta := func(restartCount int32) *statesinformer.PodMeta {
+		return &statesinformer.PodMeta{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pod0",
+					UID:  "p0",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "container0",
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "container0",
+							ContainerID:  "containerd://c0",
+							RestartCount: restartCount,
+						},
+					},
+				},
+			},
+			CgroupDir: "p0",
+		}
+	}
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := newTestResctrlReconcile(&resmanager{})
+	r.restartStorm = statesinformer.NewRestartStormDetector(5*time.Minute, 3, 2*time.Minute, fakeClock)
+	for i := int32(0); i <= 3; i++ {
+		r.restartStorm.Update([]*statesinformer.PodMeta{newPodMeta(i)})
+		fakeClock.Step(10 * time.Second)
+	}
+	// the tasks of the container in restart storm are not moved
+	assert.Nil(t, r.getPodCgroupNewTaskIds(newPodMeta(3), nil))
+
+	// the tasks are moved after the container stabilizes
+	fakeClock.Step(2 * time.Minute)
+	assert.Equal(t, []int32{122450, 122454}, r.getPodCgroupNewTaskIds(newPodMeta(3), nil))
+}
+
 func TestResctrlReconcile_calculateAndApplyCatL3PolicyForGroup(t *testing.T) {
 	type args struct {
 		group       string
@@ -42,6 +42,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager/configextensions"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager/plugins"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/exclusion"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -74,6 +75,11 @@ func (r *resmanager) getNodeSLOCopy() *slov1alpha1.NodeSLO {
 	return r.statesInformer.GetNodeSLO()
 }
 
+// getManagedPods returns the pods on the node except the ones excluded from the strategy.
+func (r *resmanager) getManagedPods(strategy featuregate.Feature) []*statesinformer.PodMeta {
+	return exclusion.SkipExcludedPods(string(strategy), r.statesInformer.GetAllPods())
+}
+
 func NewResManager(cfg *Config, schema *apiruntime.Scheme, kubeClient clientset.Interface, crdClient *koordclientset.Clientset, nodeName string,
 	statesInformer statesinformer.StatesInformer, metricCache metriccache.MetricCache, collectResUsedIntervalSeconds int64) ResManager {
 
@@ -105,11 +111,17 @@ func isFeatureDisabled(nodeSLO *slov1alpha1.NodeSLO, feature featuregate.Feature
 
 	spec := nodeSLO.Spec
 	switch feature {
-	case features.BECPUSuppress, features.BEMemoryEvict, features.BECPUEvict:
+	case features.BECPUSuppress, features.BEMemoryEvict, features.BECPUEvict, features.BEPIDProtection:
 		if spec.ResourceUsedThresholdWithBE == nil || spec.ResourceUsedThresholdWithBE.Enable == nil {
 			return true, fmt.Errorf("cannot parse feature config for invalid nodeSLO %v", nodeSLO)
 		}
 		return !(*spec.ResourceUsedThresholdWithBE.Enable), nil
+	case features.BENetIngressProtection:
+		if spec.ResourceQOSStrategy == nil || spec.ResourceQOSStrategy.BEClass == nil ||
+			spec.ResourceQOSStrategy.BEClass.NetworkQOS == nil || spec.ResourceQOSStrategy.BEClass.NetworkQOS.Enable == nil {
+			return true, fmt.Errorf("cannot parse feature config for invalid nodeSLO %v", nodeSLO)
+		}
+		return !(*spec.ResourceQOSStrategy.BEClass.NetworkQOS.Enable), nil
 	default:
 		return true, fmt.Errorf("cannot parse feature config for unsupported feature %s", feature)
 	}
@@ -148,6 +160,35 @@ func (r *resmanager) Run(stopCh <-chan struct{}) error {
 	util.RunFeatureWithInit(func() error { return rdtResCtrl.RunInit(stopCh) }, rdtResCtrl.reconcile,
 		[]featuregate.Feature{features.RdtResctrl}, r.config.ReconcileIntervalSeconds, stopCh)
 
+	cgroupDriftWatchdog := NewCgroupDriftWatchdog(r)
+	util.RunFeatureWithInit(func() error { return cgroupDriftWatchdog.RunInit(stopCh) }, cgroupDriftWatchdog.verify,
+		[]featuregate.Feature{features.CgroupDriftWatchdog}, r.config.CgroupVerifyIntervalSeconds, stopCh)
+
+	memoryLocalityRepair := NewMemoryLocalityRepair(r)
+	util.RunFeatureWithInit(func() error { return memoryLocalityRepair.RunInit(stopCh) }, memoryLocalityRepair.repair,
+		[]featuregate.Feature{features.MemoryLocalityRepair}, r.config.MemoryLocalityRepairIntervalSeconds, stopCh)
+
+	orphanArtifactGC := NewOrphanArtifactGC(r)
+	util.RunFeature(orphanArtifactGC.gc, []featuregate.Feature{features.OrphanArtifactGC}, r.config.OrphanArtifactGCIntervalSeconds, stopCh)
+
+	mbaFeedback := NewMBAFeedback(r)
+	util.RunFeatureWithInit(func() error { return mbaFeedback.RunInit(stopCh) }, mbaFeedback.feedback,
+		[]featuregate.Feature{features.MBAFeedback}, r.config.MBAFeedbackIntervalSeconds, stopCh)
+
+	gpuMemoryLeakDetector := NewGPUMemoryLeakDetector(r)
+	util.RunFeature(gpuMemoryLeakDetector.detect, []featuregate.Feature{features.GPUMemoryLeakDetector}, r.config.GPUMemoryLeakCheckIntervalSeconds, stopCh)
+
+	pidProtector := NewPIDProtector(r)
+	util.RunFeatureWithInit(func() error { return pidProtector.RunInit(stopCh) }, pidProtector.protect,
+		[]featuregate.Feature{features.BEPIDProtection}, r.config.PIDProtectionIntervalSeconds, stopCh)
+
+	netIngressProtector := NewNetIngressProtector(r)
+	if !util.RunFeature(netIngressProtector.protect, []featuregate.Feature{features.BENetIngressProtection},
+		r.config.NetIngressProtectionIntervalSeconds, stopCh) {
+		// remove the shapers left by the previous koordlet running with the feature enabled
+		netIngressProtector.cleanup()
+	}
+
 	klog.Infof("start resmanager extensions")
 	plugins.SetupPlugins(r.kubeClient, r.metricCache, r.statesInformer)
 	utilruntime.Must(plugins.StartPlugins(r.config.QOSExtensionCfg, stopCh))
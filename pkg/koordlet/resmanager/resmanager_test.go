@@ -39,6 +39,8 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/exclusion"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
 	expireCache "github.com/koordinator-sh/koordinator/pkg/util/cache"
 )
@@ -62,6 +64,28 @@ func TestNewResManager(t *testing.T) {
 	})
 }
 
+func Test_getManagedPods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	excludedPod := &statesinformer.PodMeta{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "excluded", UID: "excluded-uid", Labels: map[string]string{"app": "gpu-operator"}}}}
+	normalPod := &statesinformer.PodMeta{Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "normal", UID: "normal-uid"}}}
+	si := mock_statesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{excludedPod, normalPod}).AnyTimes()
+	si.EXPECT().RegisterCallbacks(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	r := &resmanager{statesInformer: si}
+
+	assert.NoError(t, exclusion.Setup(&exclusion.Config{
+		PodSelectors: []string{"app=gpu-operator"},
+		Hooks:        map[string]bool{string(features.BEMemoryEvict): false},
+	}, si))
+	defer exclusion.Setup(&exclusion.Config{}, si)
+	assert.Equal(t, []*statesinformer.PodMeta{normalPod}, r.getManagedPods(features.CPUBurst))
+	assert.Equal(t, []*statesinformer.PodMeta{excludedPod, normalPod}, r.getManagedPods(features.BEMemoryEvict))
+}
+
 func Test_isFeatureDisabled(t *testing.T) {
 	type args struct {
 		nodeSLO *slov1alpha1.NodeSLO
@@ -25,6 +25,7 @@ const (
 
 	EvictPodByNodeMemoryUsage   = "EvictPodByNodeMemoryUsage"
 	EvictPodByBECPUSatisfaction = "EvictPodByBECPUSatisfaction"
+	EvictPodByNodePIDUsage      = "EvictPodByNodePIDUsage"
 
 	AdjustBEByNodeCPUUsage = "AdjustBEByNodeCPUUsage"
 )
@@ -38,6 +38,9 @@ type ResourceUpdateExecutor interface {
 	// 2. update each cgroup resource by the order of layers: firstly update resources from upper to lower by merging
 	//    the new value with old value; then update resources from lower to upper with the new value.
 	LeveledUpdateBatch(cacheable bool, updaters [][]ResourceUpdater)
+	// VerifyCacheableResources reads the actual values of a sample of the cached cgroup resources, and repairs the
+	// resources which were modified by others after koordlet updated them. It returns the repaired resources.
+	VerifyCacheableResources(sampleRatio float64, maxFiles int) []ResourceUpdater
 	Run(stopCh <-chan struct{})
 }
 
@@ -46,7 +49,8 @@ type ResourceUpdateExecutorImpl struct {
 	ResourceCache     *cache.Cache
 	Config            *Config
 
-	onceRun sync.Once
+	onceRun      sync.Once
+	verifyOffset int
 }
 
 var singleton = &ResourceUpdateExecutorImpl{
@@ -36,6 +36,10 @@ type CgroupReader interface {
 	ReadMemoryLimit(parentDir string) (int64, error)
 	ReadMemoryStat(parentDir string) (*sysutil.MemoryStatRaw, error)
 	ReadCPUTasks(parentDir string) ([]int32, error)
+	ReadCPUProcs(parentDir string) ([]int32, error)
+	ReadCPUSetMems(parentDir string) (*cpuset.CPUSet, error)
+	ReadPidsCurrent(parentDir string) (int64, error)
+	ReadMemoryOOMKillCount(parentDir string) (int64, error)
 }
 
 var _ CgroupReader = &CgroupV1Reader{}
@@ -152,6 +156,40 @@ func (r *CgroupV1Reader) ReadCPUTasks(parentDir string) ([]int32, error) {
 	return sysutil.ReadCgroupAndParseInt32Slice(parentDir, resource)
 }
 
+func (r *CgroupV1Reader) ReadCPUProcs(parentDir string) ([]int32, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.CPUProcsName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	// content: `7742\n10971\n11049\n11051...`
+	return sysutil.ReadCgroupAndParseInt32Slice(parentDir, resource)
+}
+
+func (r *CgroupV1Reader) ReadCPUSetMems(parentDir string) (*cpuset.CPUSet, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.CPUSetMemsName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	return readCPUSetFormat(parentDir, resource)
+}
+
+func (r *CgroupV1Reader) ReadPidsCurrent(parentDir string) (int64, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.PidsCurrentName)
+	if !ok {
+		return -1, ErrResourceNotRegistered
+	}
+	return sysutil.ReadCgroupAndParseInt64(parentDir, resource)
+}
+
+func (r *CgroupV1Reader) ReadMemoryOOMKillCount(parentDir string) (int64, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.MemoryOomControlName)
+	if !ok {
+		return -1, ErrResourceNotRegistered
+	}
+	// content: `oom_kill_disable 0\nunder_oom 0\noom_kill 1\n`, the `oom_kill` is missing on the kernels before 4.13
+	return readMemoryOOMKillCount(parentDir, resource)
+}
+
 var _ CgroupReader = &CgroupV2Reader{}
 
 type CgroupV2Reader struct{}
@@ -293,6 +331,68 @@ func (r *CgroupV2Reader) ReadCPUTasks(parentDir string) ([]int32, error) {
 	return sysutil.ReadCgroupAndParseInt32Slice(parentDir, resource)
 }
 
+func (r *CgroupV2Reader) ReadCPUProcs(parentDir string) ([]int32, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.CPUProcsName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	// content: `7742\n10971\n11049\n11051...`
+	return sysutil.ReadCgroupAndParseInt32Slice(parentDir, resource)
+}
+
+func (r *CgroupV2Reader) ReadCPUSetMems(parentDir string) (*cpuset.CPUSet, error) {
+	// use `cpuset.mems.effective` for read cpuset on cgroups-v2, the same as `cpuset.cpus.effective`
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.CPUSetMemsEffectiveName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	return readCPUSetFormat(parentDir, resource)
+}
+
+func (r *CgroupV2Reader) ReadPidsCurrent(parentDir string) (int64, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.PidsCurrentName)
+	if !ok {
+		return -1, ErrResourceNotRegistered
+	}
+	return sysutil.ReadCgroupAndParseInt64(parentDir, resource)
+}
+
+func (r *CgroupV2Reader) ReadMemoryOOMKillCount(parentDir string) (int64, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.MemoryOomControlName)
+	if !ok {
+		return -1, ErrResourceNotRegistered
+	}
+	// content: `low 0\nhigh 0\nmax 0\noom 1\noom_kill 1\n`
+	return readMemoryOOMKillCount(parentDir, resource)
+}
+
+// readMemoryOOMKillCount reads the `oom_kill` count of the memory cgroup.
+func readMemoryOOMKillCount(parentDir string, resource sysutil.Resource) (int64, error) {
+	s, err := sysutil.CgroupFileRead(parentDir, resource)
+	if err != nil {
+		return -1, fmt.Errorf("cannot read cgroup file, err: %v", err)
+	}
+	v, err := sysutil.ParseMemoryOOMKillCount(s)
+	if err != nil {
+		return -1, fmt.Errorf("cannot parse cgroup value %s, err: %v", s, err)
+	}
+	return v, nil
+}
+
+// readCPUSetFormat reads the cgroup file in the cpuset list format, e.g. `0-3,8`.
+func readCPUSetFormat(parentDir string, resource sysutil.Resource) (*cpuset.CPUSet, error) {
+	s, err := sysutil.CgroupFileRead(parentDir, resource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cgroup file, err: %v", err)
+	}
+
+	v, err := cpuset.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup value %s, err: %v", s, err)
+	}
+	return &v, nil
+}
+
 func NewCgroupReader() CgroupReader {
 	if sysutil.GetCurrentCgroupVersion() == sysutil.CgroupVersionV2 {
 		return &CgroupV2Reader{}
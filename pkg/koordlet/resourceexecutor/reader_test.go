@@ -1075,3 +1075,178 @@ pagetables 1048576`,
 		})
 	}
 }
+
+func TestCgroupReader_ReadCPUSetMems(t *testing.T) {
+	testMemsStr := "0-1"
+	testMems := cpuset.MustParse(testMemsStr)
+	type fields struct {
+		UseCgroupsV2         bool
+		MemsValue            string
+		MemsEffectiveV2Value string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *cpuset.CPUSet
+		wantErr bool
+	}{
+		{
+			name:    "v1 path not exist",
+			fields:  fields{},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse v1 value successfully",
+			fields: fields{
+				MemsValue: testMemsStr,
+			},
+			want:    &testMems,
+			wantErr: false,
+		},
+		{
+			name: "v2 path not exist",
+			fields: fields{
+				UseCgroupsV2: true,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse v2 value successfully",
+			fields: fields{
+				UseCgroupsV2:         true,
+				MemsEffectiveV2Value: testMemsStr,
+			},
+			want:    &testMems,
+			wantErr: false,
+		},
+		{
+			name: "parse v2 value failed",
+			fields: fields{
+				UseCgroupsV2:         true,
+				MemsEffectiveV2Value: "unknown", // only for testing
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.fields.UseCgroupsV2)
+			parentDir := "/kubepods.slice"
+			if tt.fields.MemsValue != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.CPUSetMems, tt.fields.MemsValue)
+			}
+			if tt.fields.MemsEffectiveV2Value != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.CPUSetMemsEffectiveV2, tt.fields.MemsEffectiveV2Value)
+			}
+
+			got, gotErr := NewCgroupReader().ReadCPUSetMems(parentDir)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCgroupReader_ReadCPUProcs(t *testing.T) {
+	tests := []struct {
+		name         string
+		useCgroupsV2 bool
+		procsValue   string
+		want         []int32
+		wantErr      bool
+	}{
+		{
+			name:    "v1 path not exist",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:       "parse v1 value successfully",
+			procsValue: "1000\n1001\n",
+			want:       []int32{1000, 1001},
+			wantErr:    false,
+		},
+		{
+			name:         "parse v2 value successfully",
+			useCgroupsV2: true,
+			procsValue:   "1000\n",
+			want:         []int32{1000},
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.useCgroupsV2)
+			parentDir := "/kubepods.slice"
+			if tt.procsValue != "" {
+				procs := sysutil.CPUProcs
+				if tt.useCgroupsV2 {
+					procs = sysutil.CPUProcsV2
+				}
+				helper.WriteCgroupFileContents(parentDir, procs, tt.procsValue)
+			}
+
+			got, gotErr := NewCgroupReader().ReadCPUProcs(parentDir)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCgroupReader_ReadMemoryOOMKillCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		useCgroupsV2 bool
+		value        string
+		want         int64
+		wantErr      bool
+	}{
+		{
+			name:    "v1 path not exist",
+			want:    -1,
+			wantErr: true,
+		},
+		{
+			name:  "parse v1 value successfully",
+			value: "oom_kill_disable 0\nunder_oom 0\noom_kill 2\n",
+			want:  2,
+		},
+		{
+			name:    "v1 value missing on the old kernels",
+			value:   "oom_kill_disable 0\nunder_oom 0\n",
+			want:    -1,
+			wantErr: true,
+		},
+		{
+			name:         "parse v2 value successfully",
+			useCgroupsV2: true,
+			value:        "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n",
+			want:         1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.useCgroupsV2)
+			parentDir := "/kubepods.slice/kubepods-besteffort.slice"
+			if tt.value != "" {
+				if tt.useCgroupsV2 {
+					helper.WriteCgroupFileContents(parentDir, sysutil.MemoryOomControlV2, tt.value)
+				} else {
+					helper.WriteCgroupFileContents(parentDir, sysutil.MemoryOomControl, tt.value)
+				}
+			}
+
+			got, gotErr := NewCgroupReader().ReadMemoryOOMKillCount(parentDir)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -46,6 +46,7 @@ func init() {
 		sysutil.CPUBVTWarpNsName,
 		sysutil.CPUTasksName,
 		sysutil.CPUProcsName,
+		sysutil.CPUSetMemsName,
 		sysutil.MemoryWmarkRatioName,
 		sysutil.MemoryWmarkScaleFactorName,
 		sysutil.MemoryWmarkMinAdjName,
@@ -56,6 +57,7 @@ func init() {
 		sysutil.BlkioTRBpsName,
 		sysutil.BlkioTWIopsName,
 		sysutil.BlkioTWBpsName,
+		sysutil.PidsMaxName,
 	)
 	// special cases
 	DefaultCgroupUpdaterFactory.Register(NewCPUSharesCgroupUpdater, sysutil.CPUSharesName)
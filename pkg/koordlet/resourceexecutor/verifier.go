@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceexecutor
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+const ReasonRepairCgroups = "RepairCgroups"
+
+// unverifiableResources are the cgroup files whose content is not the value written, e.g. writing a pid into
+// `cgroup.procs` appends it to the existing list.
+var unverifiableResources = map[sysutil.ResourceType]bool{
+	sysutil.CPUTasksName:    true,
+	sysutil.CPUProcsName:    true,
+	sysutil.BlkioTRIopsName: true,
+	sysutil.BlkioTRBpsName:  true,
+	sysutil.BlkioTWIopsName: true,
+	sysutil.BlkioTWBpsName:  true,
+}
+
+// VerifyCacheableResources samples the cgroup resources kept in the resource cache, reads their actual values and
+// repairs the ones drifted from the cache. The keys are visited in a round-robin order across calls, and no more than
+// maxFiles files are read in one call. It returns the repaired resources.
+func (e *ResourceUpdateExecutorImpl) VerifyCacheableResources(sampleRatio float64, maxFiles int) []ResourceUpdater {
+	e.LeveledUpdateLock.Lock()
+	defer e.LeveledUpdateLock.Unlock()
+
+	var updaters []*CgroupResourceUpdater
+	for _, key := range e.ResourceCache.Keys() {
+		resource, ok := e.ResourceCache.Get(key)
+		if !ok {
+			continue
+		}
+		updater, ok := resource.(*CgroupResourceUpdater)
+		if !ok || unverifiableResources[updater.ResourceType()] {
+			continue
+		}
+		updaters = append(updaters, updater)
+	}
+	if len(updaters) <= 0 || sampleRatio <= 0 {
+		return nil
+	}
+	sort.Slice(updaters, func(i, j int) bool {
+		return updaters[i].Key() < updaters[j].Key()
+	})
+
+	sampleNum := int(math.Ceil(float64(len(updaters)) * math.Min(sampleRatio, 1)))
+	if maxFiles > 0 && sampleNum > maxFiles {
+		sampleNum = maxFiles
+	}
+
+	var repaired []ResourceUpdater
+	for i := 0; i < sampleNum; i++ {
+		updater := updaters[(e.verifyOffset+i)%len(updaters)]
+		actual, err := sysutil.CgroupFileRead(updater.parentDir, updater.file)
+		if err != nil {
+			// the cgroup may be removed along with the pod
+			klog.V(5).Infof("failed to verify resource %s, read err: %v", updater.Key(), err)
+			continue
+		}
+		if !isCgroupValueDrifted(updater.ResourceType(), updater.Value(), actual) {
+			continue
+		}
+
+		klog.V(4).Infof("resource %s drifted, expect %v, actual %v, start to repair",
+			updater.Key(), updater.Value(), actual)
+		_ = audit.V(3).Reason(ReasonRepairCgroups).Message("repair %v from %v to %v", updater.Path(), actual, updater.Value()).Do()
+		if err = sysutil.CgroupFileWrite(updater.parentDir, updater.file, updater.Value()); err != nil {
+			klog.V(4).Infof("failed to repair resource %s to %v, err: %v", updater.Key(), updater.Value(), err)
+			continue
+		}
+		updater.UpdateLastUpdateTimestamp(time.Now())
+		if err = e.ResourceCache.SetDefault(updater.Key(), updater); err != nil {
+			klog.V(4).Infof("failed to SetDefault in resourceCache for resource %s, err: %v", updater.Key(), err)
+		}
+		repaired = append(repaired, updater)
+	}
+	e.verifyOffset = (e.verifyOffset + sampleNum) % len(updaters)
+
+	klog.V(6).Infof("finished verifying resources, total %v, verified %v, repaired %v",
+		len(updaters), sampleNum, len(repaired))
+	return repaired
+}
+
+// isCgroupValueDrifted checks if the actual content of a cgroup file differs from the value koordlet wrote.
+// The kernel may present an equivalent value in another format, e.g. `cpuset.cpus` "0,1,2" is read as "0-2".
+func isCgroupValueDrifted(resourceType sysutil.ResourceType, expect, actual string) bool {
+	actual = strings.TrimSpace(actual)
+	if resourceType == sysutil.CPUCFSQuotaName {
+		// cgroups-v2 `cpu.max` shows as "$QUOTA $PERIOD"
+		if fields := strings.Fields(actual); len(fields) > 0 {
+			actual = fields[0]
+		}
+	}
+	if expect == actual {
+		return false
+	}
+
+	if resourceType == sysutil.CPUSetCPUSName {
+		expectCPUSet, err := cpuset.Parse(expect)
+		if err != nil {
+			return true
+		}
+		actualCPUSet, err := cpuset.Parse(actual)
+		return err != nil || !expectCPUSet.Equals(actualCPUSet)
+	}
+
+	if isUnlimitedCgroupValue(expect) && isUnlimitedCgroupValue(actual) {
+		return false
+	}
+	expectValue, err := strconv.ParseInt(expect, 10, 64)
+	if err != nil {
+		return true
+	}
+	actualValue, err := strconv.ParseInt(actual, 10, 64)
+	return err != nil || expectValue != actualValue
+}
+
+func isUnlimitedCgroupValue(value string) bool {
+	if value == "-1" || value == sysutil.CgroupMaxSymbolStr {
+		return true
+	}
+	v, err := strconv.ParseInt(value, 10, 64)
+	return err == nil && v >= sysutil.MemoryLimitUnlimitedValue
+}
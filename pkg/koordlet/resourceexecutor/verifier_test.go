@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceexecutor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cache"
+)
+
+func TestResourceUpdateExecutor_VerifyCacheableResources(t *testing.T) {
+	helper := sysutil.NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	e := &ResourceUpdateExecutorImpl{
+		ResourceCache: cache.NewCacheDefault(),
+		Config:        NewDefaultConfig(),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	e.Run(stop)
+
+	var dirs []string
+	var updaters []ResourceUpdater
+	for i := 0; i < 4; i++ {
+		dir := fmt.Sprintf("kubepods.slice/test-%d", i)
+		dirs = append(dirs, dir)
+		helper.WriteCgroupFileContents(dir, sysutil.CPUShares, "1024")
+		updater, err := DefaultCgroupUpdaterFactory.New(sysutil.CPUSharesName, dir, "2")
+		assert.NoError(t, err)
+		updaters = append(updaters, updater)
+	}
+	tasksUpdater, err := DefaultCgroupUpdaterFactory.New(sysutil.CPUTasksName, "kubepods.slice/test-0", "100")
+	assert.NoError(t, err)
+	helper.WriteCgroupFileContents("kubepods.slice/test-0", sysutil.CPUTasks, "")
+	e.UpdateBatch(true, append(updaters, tasksUpdater)...)
+	for _, dir := range dirs {
+		helper.WriteCgroupFileContents(dir, sysutil.CPUShares, "1024")
+	}
+
+	// sample half of the files per round, limited by the max files
+	assert.Len(t, e.VerifyCacheableResources(0.5, 1), 1)
+	assert.Len(t, e.VerifyCacheableResources(0.5, 0), 2)
+	repaired := e.VerifyCacheableResources(1, 0)
+	assert.Len(t, repaired, 1)
+	for _, dir := range dirs {
+		assert.Equal(t, "2", helper.ReadCgroupFileContents(dir, sysutil.CPUShares))
+	}
+	assert.Len(t, e.VerifyCacheableResources(1, 0), 0)
+	assert.Len(t, e.VerifyCacheableResources(0, 0), 0)
+}
+
+func Test_isCgroupValueDrifted(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType sysutil.ResourceType
+		expect       string
+		actual       string
+		want         bool
+	}{
+		{name: "same value", resourceType: sysutil.CPUSharesName, expect: "2", actual: "2\n", want: false},
+		{name: "changed value", resourceType: sysutil.CPUSharesName, expect: "2", actual: "1024", want: true},
+		{name: "same cpuset", resourceType: sysutil.CPUSetCPUSName, expect: "0,1,2,5", actual: "0-2,5", want: false},
+		{name: "changed cpuset", resourceType: sysutil.CPUSetCPUSName, expect: "0-3", actual: "0-7", want: true},
+		{name: "unlimited memory", resourceType: sysutil.MemoryLimitName, expect: "-1", actual: "9223372036854771712", want: false},
+		{name: "unlimited cfs quota v2", resourceType: sysutil.CPUCFSQuotaName, expect: "max", actual: "max 100000", want: false},
+		{name: "changed cfs quota v2", resourceType: sysutil.CPUCFSQuotaName, expect: "50000", actual: "max 100000", want: true},
+		{name: "invalid value", resourceType: sysutil.MemoryMinName, expect: "100", actual: "abc", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isCgroupValueDrifted(tt.resourceType, tt.expect, tt.actual))
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/batchresource"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/blkio"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/cpuset"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/gpu"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/groupidentity"
@@ -58,6 +59,12 @@ const (
 	//
 	// BatchResource set request and limits of cpu and memory on cgroup file.
 	BatchResource featuregate.Feature = "BatchResource"
+
+	// owner: @saintube @zwzhang0107
+	// alpha: v1.1
+	//
+	// BlkIOReconcile set the blkio throttling of the besteffort cgroup on the disks of the configured mount points.
+	BlkIOReconcile featuregate.Feature = "BlkIOReconcile"
 )
 
 var (
@@ -66,6 +73,7 @@ var (
 		CPUSetAllocator: {Default: true, PreRelease: featuregate.Beta},
 		GPUEnvInject:    {Default: false, PreRelease: featuregate.Alpha},
 		BatchResource:   {Default: true, PreRelease: featuregate.Beta},
+		BlkIOReconcile:  {Default: false, PreRelease: featuregate.Alpha},
 	}
 
 	runtimeHookPlugins = map[featuregate.Feature]HookPlugin{
@@ -73,6 +81,7 @@ var (
 		CPUSetAllocator: cpuset.Object(),
 		GPUEnvInject:    gpu.Object(),
 		BatchResource:   batchresource.Object(),
+		BlkIOReconcile:  blkio.Object(),
 	}
 )
 
@@ -85,6 +94,11 @@ type Config struct {
 	RuntimeHookHostEndpoint         string
 	RuntimeHookDisableStages        []string
 	FeatureGates                    map[string]bool // Deprecated
+
+	// the pods managed by other agents are excluded from the runtime hooks
+	RuntimeHookExclusionPodSelectors string
+	RuntimeHookExclusionOwnerKinds   []string
+	RuntimeHookExclusionHooks        map[string]bool
 }
 
 func NewDefaultConfig() *Config {
@@ -97,6 +111,8 @@ func NewDefaultConfig() *Config {
 		RuntimeHookHostEndpoint:         "/var/run/koordlet/koordlet.sock",
 		RuntimeHookDisableStages:        []string{},
 		FeatureGates:                    map[string]bool{},
+		RuntimeHookExclusionOwnerKinds:  []string{},
+		RuntimeHookExclusionHooks:       map[string]bool{},
 	}
 }
 
@@ -109,6 +125,12 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.RuntimeHookHostEndpoint, "runtime-hooks-host-endpoint", c.RuntimeHookHostEndpoint, "host endpoint of runtime proxy")
 	fs.Var(cliflag.NewStringSlice(&c.RuntimeHookDisableStages), "runtime-hooks-disable-stages", "disable stages for runtime hooks")
 	fs.Var(cliflag.NewMapStringBool(&c.FeatureGates), "runtime-hooks", "Deprecated because all settings have been moved to --feature-gates parameters")
+	fs.StringVar(&c.RuntimeHookExclusionPodSelectors, "runtime-hooks-exclusion-pod-selectors", c.RuntimeHookExclusionPodSelectors,
+		"label selectors separated by ';' of the pods managed by other agents, which are skipped by runtime hooks, e.g. 'app=gpu-operator;app in (katalyst)'")
+	fs.Var(cliflag.NewStringSlice(&c.RuntimeHookExclusionOwnerKinds), "runtime-hooks-exclusion-owner-kinds",
+		"owner kinds of the pods managed by other agents, which are skipped by runtime hooks, e.g. DaemonSet")
+	fs.Var(cliflag.NewMapStringBool(&c.RuntimeHookExclusionHooks), "runtime-hooks-exclusion-hooks",
+		"whether the runtime hooks skip the excluded pods, all hooks skip them by default, e.g. '*=false,CPUSetAllocator=true' only excludes them from cpuset")
 }
 
 func init() {
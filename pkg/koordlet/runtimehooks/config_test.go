@@ -35,6 +35,8 @@ func Test_NewDefaultConfig(t *testing.T) {
 		RuntimeHookHostEndpoint:         "/var/run/koordlet/koordlet.sock",
 		RuntimeHookDisableStages:        []string{},
 		FeatureGates:                    map[string]bool{},
+		RuntimeHookExclusionOwnerKinds:  []string{},
+		RuntimeHookExclusionHooks:       map[string]bool{},
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
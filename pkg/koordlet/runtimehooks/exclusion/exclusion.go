@@ -0,0 +1,260 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exclusion
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+const (
+	// AllHooks is the key of the hooks override which applies to the hooks not specified.
+	AllHooks = "*"
+
+	podSelectorSeparator = ";"
+)
+
+// Config describes the pods managed by other agents, which are skipped by the runtime hooks, the reconciler and the
+// resmanager strategies while still observed by the collectors.
+type Config struct {
+	// PodSelectors are the label selectors of the excluded pods, a pod is excluded if it matches any of them.
+	PodSelectors []string
+	// OwnerKinds are the kinds of the owners of the excluded pods, e.g. DaemonSet.
+	OwnerKinds []string
+	// Hooks overrides whether a hook or a resmanager strategy skips the excluded pods, all of them skip the pods by
+	// default. The key is the hook name like CPUSetAllocator, the feature gate of the strategy like CPUBurst, or
+	// AllHooks.
+	Hooks map[string]bool
+}
+
+// ParsePodSelectors parses the label selectors separated by ";".
+func ParsePodSelectors(s string) []string {
+	var selectors []string
+	for _, selector := range strings.Split(s, podSelectorSeparator) {
+		if selector = strings.TrimSpace(selector); len(selector) > 0 {
+			selectors = append(selectors, selector)
+		}
+	}
+	return selectors
+}
+
+// FormatPodSelectors joins the label selectors by ";", the reverse of ParsePodSelectors.
+func FormatPodSelectors(selectors []string) string {
+	return strings.Join(selectors, podSelectorSeparator)
+}
+
+type excluder struct {
+	selectors  []labels.Selector
+	ownerKinds sets.String
+	hooks      map[string]bool
+	getPods    func() []*statesinformer.PodMeta
+
+	lock sync.RWMutex
+	// decisions caches whether the pods are excluded by pod uid
+	decisions map[string]bool
+}
+
+var (
+	globalExcluderLock sync.RWMutex
+	globalExcluder     *excluder
+)
+
+// Setup configures the excluded pods. The decisions are cached per pod and pruned when the pods are removed.
+func Setup(cfg *Config, si statesinformer.StatesInformer) error {
+	e, err := newExcluder(cfg, si.GetAllPods)
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		si.RegisterCallbacks(statesinformer.RegisterTypeAllPods, "runtime-hooks-exclusion",
+			"Prune the exclusion decisions of the removed pods", e.podRefreshCallback)
+	}
+	globalExcluderLock.Lock()
+	defer globalExcluderLock.Unlock()
+	globalExcluder = e
+	return nil
+}
+
+func newExcluder(cfg *Config, getPods func() []*statesinformer.PodMeta) (*excluder, error) {
+	if cfg == nil || (len(cfg.PodSelectors) == 0 && len(cfg.OwnerKinds) == 0) {
+		return nil, nil
+	}
+	e := &excluder{
+		ownerKinds: sets.NewString(cfg.OwnerKinds...),
+		hooks:      cfg.Hooks,
+		getPods:    getPods,
+		decisions:  map[string]bool{},
+	}
+	for _, s := range cfg.PodSelectors {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the pod selector %q of the runtime hooks exclusion, err: %v", s, err)
+		}
+		e.selectors = append(e.selectors, selector)
+	}
+	klog.Infof("runtime hooks exclude the pods, selectors %v, owner kinds %v, hooks %v",
+		cfg.PodSelectors, cfg.OwnerKinds, cfg.Hooks)
+	return e, nil
+}
+
+// IsPodExcluded returns whether the hook should skip the pod of the request.
+func IsPodExcluded(hookName string, p protocol.HooksProtocol) bool {
+	globalExcluderLock.RLock()
+	e := globalExcluder
+	globalExcluderLock.RUnlock()
+	if e == nil || !e.hookEnabled(hookName) {
+		return false
+	}
+	var podMeta *protocol.PodMeta
+	var podLabels map[string]string
+	switch ctx := p.(type) {
+	case *protocol.PodContext:
+		podMeta, podLabels = &ctx.Request.PodMeta, ctx.Request.Labels
+	case *protocol.ContainerContext:
+		podMeta, podLabels = &ctx.Request.PodMeta, ctx.Request.PodLabels
+	default:
+		// the kube qos level cgroups are shared by all pods
+		return false
+	}
+	return e.isExcluded(podMeta, podLabels)
+}
+
+// IsPodMetaExcluded returns whether the hook or the resmanager strategy should skip the pod in the states informer.
+func IsPodMetaExcluded(name string, podMeta *statesinformer.PodMeta) bool {
+	globalExcluderLock.RLock()
+	e := globalExcluder
+	globalExcluderLock.RUnlock()
+	if e == nil || !e.hookEnabled(name) || podMeta == nil || podMeta.Pod == nil {
+		return false
+	}
+	return e.isPodExcluded(podMeta)
+}
+
+// SkipExcludedPods returns the pods listed from the states informer except the ones the hook or the resmanager
+// strategy should skip. The pods are returned as is if nothing is excluded.
+func SkipExcludedPods(name string, pods []*statesinformer.PodMeta) []*statesinformer.PodMeta {
+	globalExcluderLock.RLock()
+	e := globalExcluder
+	globalExcluderLock.RUnlock()
+	if e == nil || !e.hookEnabled(name) {
+		return pods
+	}
+	filtered := make([]*statesinformer.PodMeta, 0, len(pods))
+	for _, podMeta := range pods {
+		if podMeta != nil && podMeta.Pod != nil && e.isPodExcluded(podMeta) {
+			continue
+		}
+		filtered = append(filtered, podMeta)
+	}
+	return filtered
+}
+
+func (e *excluder) hookEnabled(hookName string) bool {
+	if enabled, ok := e.hooks[hookName]; ok {
+		return enabled
+	}
+	if enabled, ok := e.hooks[AllHooks]; ok {
+		return enabled
+	}
+	return true
+}
+
+func (e *excluder) isExcluded(podMeta *protocol.PodMeta, podLabels map[string]string) bool {
+	e.lock.RLock()
+	excluded, ok := e.decisions[podMeta.UID]
+	e.lock.RUnlock()
+	if ok {
+		return excluded
+	}
+
+	// the owners are only known from the pod in the states informer
+	var pod *statesinformer.PodMeta
+	for _, p := range e.getPods() {
+		if p != nil && p.Pod != nil && string(p.Pod.UID) == podMeta.UID {
+			pod = p
+			break
+		}
+	}
+	if pod == nil {
+		// decide by the labels in the request but never cache it, since the owners are unknown yet
+		return e.matchLabels(podLabels)
+	}
+	return e.isPodExcluded(pod)
+}
+
+// isPodExcluded decides whether the pod in the states informer is excluded, the decision is cached per pod uid.
+func (e *excluder) isPodExcluded(podMeta *statesinformer.PodMeta) bool {
+	pod := podMeta.Pod
+	uid := string(pod.UID)
+	e.lock.RLock()
+	excluded, ok := e.decisions[uid]
+	e.lock.RUnlock()
+	if ok {
+		return excluded
+	}
+
+	excluded = e.matchLabels(pod.Labels)
+	if !excluded {
+		for _, owner := range pod.OwnerReferences {
+			if e.ownerKinds.Has(owner.Kind) {
+				excluded = true
+				break
+			}
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if _, ok = e.decisions[uid]; !ok && excluded {
+		klog.Infof("pod %s/%s is managed by other agents, excluded from the runtime hooks", pod.Namespace, pod.Name)
+	}
+	e.decisions[uid] = excluded
+	return excluded
+}
+
+func (e *excluder) matchLabels(podLabels map[string]string) bool {
+	set := labels.Set(podLabels)
+	for _, selector := range e.selectors {
+		if selector.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *excluder) podRefreshCallback(t statesinformer.RegisterType, o interface{}, podsMeta []*statesinformer.PodMeta) {
+	uids := sets.NewString()
+	for _, podMeta := range podsMeta {
+		if podMeta != nil && podMeta.Pod != nil {
+			uids.Insert(string(podMeta.Pod.UID))
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for uid := range e.decisions {
+		if !uids.Has(uid) {
+			delete(e.decisions, uid)
+		}
+	}
+}
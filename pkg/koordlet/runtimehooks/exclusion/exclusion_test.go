@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exclusion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+func newTestPodMeta(name string, labels map[string]string, ownerKind string) *statesinformer.PodMeta {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name + "-uid"),
+			Labels:    labels,
+		},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: name + "-owner"}}
+	}
+	return &statesinformer.PodMeta{Pod: pod}
+}
+
+func newTestPodContext(podMeta *statesinformer.PodMeta) *protocol.PodContext {
+	return &protocol.PodContext{
+		Request: protocol.PodRequest{
+			PodMeta: protocol.PodMeta{
+				Namespace: podMeta.Pod.Namespace,
+				Name:      podMeta.Pod.Name,
+				UID:       string(podMeta.Pod.UID),
+			},
+			Labels: podMeta.Pod.Labels,
+		},
+	}
+}
+
+func newTestContainerContext(podMeta *statesinformer.PodMeta) *protocol.ContainerContext {
+	return &protocol.ContainerContext{
+		Request: protocol.ContainerRequest{
+			PodMeta: protocol.PodMeta{
+				Namespace: podMeta.Pod.Namespace,
+				Name:      podMeta.Pod.Name,
+				UID:       string(podMeta.Pod.UID),
+			},
+			ContainerMeta: protocol.ContainerMeta{Name: "main"},
+			PodLabels:     podMeta.Pod.Labels,
+		},
+	}
+}
+
+func setTestExcluder(t *testing.T, cfg *Config, pods []*statesinformer.PodMeta) *excluder {
+	e, err := newExcluder(cfg, func() []*statesinformer.PodMeta { return pods })
+	assert.NoError(t, err)
+	globalExcluderLock.Lock()
+	globalExcluder = e
+	globalExcluderLock.Unlock()
+	t.Cleanup(func() {
+		globalExcluderLock.Lock()
+		globalExcluder = nil
+		globalExcluderLock.Unlock()
+	})
+	return e
+}
+
+func TestParsePodSelectors(t *testing.T) {
+	assert.Nil(t, ParsePodSelectors(""))
+	assert.Equal(t, []string{"app=gpu-operator", "app in (katalyst,other),tier!=web"},
+		ParsePodSelectors(" app=gpu-operator ; app in (katalyst,other),tier!=web;"))
+	selectors := []string{"app=gpu-operator", "app in (katalyst,other),tier!=web"}
+	assert.Equal(t, selectors, ParsePodSelectors(FormatPodSelectors(selectors)))
+}
+
+func Test_newExcluder(t *testing.T) {
+	e, err := newExcluder(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, e)
+	e, err = newExcluder(&Config{Hooks: map[string]bool{"CPUSetAllocator": true}}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, e, "nothing is excluded without selectors or owner kinds")
+	_, err = newExcluder(&Config{PodSelectors: []string{"app in gpu"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestIsPodExcluded(t *testing.T) {
+	selectedPod := newTestPodMeta("selected", map[string]string{"app": "gpu-operator"}, "ReplicaSet")
+	ownedPod := newTestPodMeta("owned", map[string]string{"app": "agent"}, "DaemonSet")
+	normalPod := newTestPodMeta("normal", map[string]string{"app": "web"}, "ReplicaSet")
+	// the pod not yet synced by the states informer is decided by the labels in the request
+	unsyncedSelectedPod := newTestPodMeta("unsynced-selected", map[string]string{"app": "gpu-operator"}, "")
+	unsyncedOwnedPod := newTestPodMeta("unsynced-owned", nil, "DaemonSet")
+	pods := []*statesinformer.PodMeta{selectedPod, ownedPod, normalPod}
+
+	hookNames := []string{"GroupIdentity", "CPUSetAllocator", "BatchResource"}
+	tests := []struct {
+		name  string
+		hooks map[string]bool
+		// expected hooks skipping the excluded pods
+		wantSkippedHooks []string
+	}{
+		{
+			name:             "all hooks skip the excluded pods by default",
+			wantSkippedHooks: hookNames,
+		},
+		{
+			name:             "only exclude from cpuset",
+			hooks:            map[string]bool{AllHooks: false, "CPUSetAllocator": true},
+			wantSkippedHooks: []string{"CPUSetAllocator"},
+		},
+		{
+			name:             "exclude from all hooks but group identity",
+			hooks:            map[string]bool{"GroupIdentity": false},
+			wantSkippedHooks: []string{"CPUSetAllocator", "BatchResource"},
+		},
+		{
+			name:  "never exclude",
+			hooks: map[string]bool{AllHooks: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestExcluder(t, &Config{
+				PodSelectors: []string{"app=gpu-operator", "app in (katalyst)"},
+				OwnerKinds:   []string{"DaemonSet"},
+				Hooks:        tt.hooks,
+			}, pods)
+			for _, hookName := range hookNames {
+				wantSkipped := false
+				for _, h := range tt.wantSkippedHooks {
+					if h == hookName {
+						wantSkipped = true
+					}
+				}
+				assert.Equal(t, wantSkipped, IsPodExcluded(hookName, newTestPodContext(selectedPod)), hookName)
+				assert.Equal(t, wantSkipped, IsPodExcluded(hookName, newTestContainerContext(selectedPod)), hookName)
+				assert.Equal(t, wantSkipped, IsPodExcluded(hookName, newTestPodContext(ownedPod)), hookName)
+				assert.Equal(t, wantSkipped, IsPodExcluded(hookName, newTestContainerContext(ownedPod)), hookName)
+				assert.Equal(t, wantSkipped, IsPodExcluded(hookName, newTestPodContext(unsyncedSelectedPod)), hookName)
+				assert.False(t, IsPodExcluded(hookName, newTestPodContext(unsyncedOwnedPod)), hookName)
+				assert.False(t, IsPodExcluded(hookName, newTestPodContext(normalPod)), hookName)
+				assert.False(t, IsPodExcluded(hookName, newTestContainerContext(normalPod)), hookName)
+				assert.False(t, IsPodExcluded(hookName, &protocol.KubeQOSContext{}), hookName)
+			}
+		})
+	}
+}
+
+func TestIsPodExcludedNotConfigured(t *testing.T) {
+	setTestExcluder(t, &Config{}, nil)
+	podMeta := newTestPodMeta("selected", map[string]string{"app": "gpu-operator"}, "DaemonSet")
+	assert.False(t, IsPodExcluded("CPUSetAllocator", newTestPodContext(podMeta)))
+}
+
+func TestExcluderDecisionCache(t *testing.T) {
+	ownedPod := newTestPodMeta("owned", nil, "DaemonSet")
+	normalPod := newTestPodMeta("normal", nil, "ReplicaSet")
+	pods := []*statesinformer.PodMeta{ownedPod, normalPod}
+	e := setTestExcluder(t, &Config{OwnerKinds: []string{"DaemonSet"}}, pods)
+
+	assert.True(t, IsPodExcluded("CPUSetAllocator", newTestPodContext(ownedPod)))
+	assert.False(t, IsPodExcluded("CPUSetAllocator", newTestPodContext(normalPod)))
+	assert.Equal(t, map[string]bool{"owned-uid": true, "normal-uid": false}, e.decisions)
+
+	// the cached decision is used even if the pod is changed
+	pods[0] = newTestPodMeta("owned", nil, "")
+	assert.True(t, IsPodExcluded("BatchResource", newTestContainerContext(ownedPod)))
+
+	// the decisions of the removed pods are pruned
+	e.podRefreshCallback(statesinformer.RegisterTypeAllPods, nil, []*statesinformer.PodMeta{normalPod})
+	assert.Equal(t, map[string]bool{"normal-uid": false}, e.decisions)
+}
+
+func TestSkipExcludedPods(t *testing.T) {
+	ownedPod := newTestPodMeta("owned", nil, "DaemonSet")
+	selectedPod := newTestPodMeta("selected", map[string]string{"app": "gpu-operator"}, "")
+	normalPod := newTestPodMeta("normal", nil, "")
+	pods := []*statesinformer.PodMeta{ownedPod, selectedPod, normalPod}
+	assert.Equal(t, pods, SkipExcludedPods("CPUSetAllocator", pods), "nothing is excluded if not configured")
+
+	// the pods listed are decided by themselves even if the states informer has not synced them
+	setTestExcluder(t, &Config{
+		PodSelectors: []string{"app=gpu-operator"},
+		OwnerKinds:   []string{"DaemonSet"},
+		Hooks:        map[string]bool{"GroupIdentity": false},
+	}, nil)
+	assert.Equal(t, []*statesinformer.PodMeta{normalPod}, SkipExcludedPods("CPUSetAllocator", pods))
+	assert.Equal(t, []*statesinformer.PodMeta{normalPod}, SkipExcludedPods("CPUBurst", pods))
+	assert.Equal(t, pods, SkipExcludedPods("GroupIdentity", pods))
+
+	assert.True(t, IsPodMetaExcluded("CPUSetAllocator", ownedPod))
+	assert.False(t, IsPodMetaExcluded("GroupIdentity", ownedPod))
+	assert.False(t, IsPodMetaExcluded("CPUSetAllocator", normalPod))
+	assert.False(t, IsPodMetaExcluded("CPUSetAllocator", nil))
+}
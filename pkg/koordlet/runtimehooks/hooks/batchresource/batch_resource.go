@@ -55,17 +55,17 @@ func (p *plugin) Register() {
 	hooks.Register(rmconfig.PreRunPodSandbox, name, description+" (pod)", p.SetPodResources)
 	hooks.Register(rmconfig.PreCreateContainer, name, description+" (container)", p.SetContainerResources)
 	hooks.Register(rmconfig.PreUpdateContainerResources, name, description+" (container)", p.SetContainerResources)
-	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUShares, description+" (pod cpu shares)",
+	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUShares, name, description+" (pod cpu shares)",
 		p.SetPodCPUShares, reconciler.PodQOSFilter(), podQOSConditions...)
-	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUCFSQuota, description+" (pod cfs quota)",
+	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUCFSQuota, name, description+" (pod cfs quota)",
 		p.SetPodCFSQuota, reconciler.PodQOSFilter(), podQOSConditions...)
-	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.MemoryLimit, description+" (pod memory limit)",
+	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.MemoryLimit, name, description+" (pod memory limit)",
 		p.SetPodMemoryLimit, reconciler.PodQOSFilter(), podQOSConditions...)
-	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.CPUShares, description+" (container cpu shares)",
+	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.CPUShares, name, description+" (container cpu shares)",
 		p.SetContainerCPUShares, reconciler.PodQOSFilter(), podQOSConditions...)
-	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.CPUCFSQuota, description+" (container cfs quota)",
+	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.CPUCFSQuota, name, description+" (container cfs quota)",
 		p.SetContainerCFSQuota, reconciler.PodQOSFilter(), podQOSConditions...)
-	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.MemoryLimit, description+" (container memory limit)",
+	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.MemoryLimit, name, description+" (container memory limit)",
 		p.SetContainerMemoryLimit, reconciler.PodQOSFilter(), podQOSConditions...)
 }
 
@@ -148,6 +148,10 @@ func (p *plugin) SetPodCFSQuota(proto protocol.HooksProtocol) error {
 		return fmt.Errorf("pod protocol is nil for plugin %v", name)
 	}
 
+	if apiext.GetQoSClassByAttrs(podCtx.Request.Labels, podCtx.Request.Annotations) == apiext.QoSLS {
+		return p.setLSPodCFSQuota(podCtx)
+	}
+
 	if !isPodQoSBEByAttr(podCtx.Request.Labels, podCtx.Request.Annotations) {
 		return nil
 	}
@@ -186,6 +190,33 @@ func (p *plugin) SetPodCFSQuota(proto protocol.HooksProtocol) error {
 	if cfsQuota < sysutil.CFSQuotaMinValue { // cfs_quota_us should be no less than 1000
 		cfsQuota = sysutil.CFSQuotaMinValue
 	}
+	cfsQuota = p.getRule().getPodCFSQuotaPolicy(apiext.QoSBE).getCFSQuota(cfsQuota)
+
+	podCtx.Response.Resources.CFSQuota = pointer.Int64Ptr(cfsQuota)
+	return nil
+}
+
+// setLSPodCFSQuota applies the pod cfs quota policy of LS pods on the quota set by the kubelet. Since the kubelet can
+// rewrite the pod-level cfs quota, e.g. when the pod is restarted, the policy is re-applied once it is detected.
+func (p *plugin) setLSPodCFSQuota(podCtx *protocol.PodContext) error {
+	policy := p.getRule().getPodCFSQuotaPolicy(apiext.QoSLS)
+	// keep the kubelet's value if no policy is configured or the kubelet's value is unknown
+	if policy == nil || podCtx.Request.KubeletCFSQuota == nil {
+		return nil
+	}
+
+	kubeletCFSQuota := *podCtx.Request.KubeletCFSQuota
+	cfsQuota := policy.getCFSQuota(kubeletCFSQuota)
+	curCFSQuota, err := sysutil.CgroupFileReadInt(podCtx.Request.CgroupParent, sysutil.CPUCFSQuota)
+	if err != nil {
+		klog.V(5).Infof("failed to read pod-level cfs quota, pod %s/%s, err: %v",
+			podCtx.Request.PodMeta.Namespace, podCtx.Request.PodMeta.Name, err)
+	} else if *curCFSQuota == cfsQuota {
+		return nil
+	} else if *curCFSQuota == kubeletCFSQuota {
+		klog.V(4).Infof("pod-level cfs quota %v is rewritten by kubelet, pod %s/%s, policy %s, expect %v",
+			kubeletCFSQuota, podCtx.Request.PodMeta.Namespace, podCtx.Request.PodMeta.Name, policy.policy, cfsQuota)
+	}
 
 	podCtx.Response.Resources.CFSQuota = pointer.Int64Ptr(cfsQuota)
 	return nil
@@ -26,7 +26,9 @@ import (
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
 func Test_plugin_Register(t *testing.T) {
@@ -278,6 +280,136 @@ func Test_plugin_SetPodResources(t *testing.T) {
 	}
 }
 
+func Test_plugin_SetPodCFSQuotaWithPolicy(t *testing.T) {
+	testCgroupParent := "kubepods.slice/kubepods-pod123456.slice"
+	testSpec := &apiext.ExtendedResourceSpec{
+		Containers: map[string]apiext.ExtendedResourceContainerSpec{
+			"container-0": {
+				Limits: corev1.ResourceList{
+					apiext.BatchCPU: resource.MustParse("500"),
+				},
+			},
+		},
+	}
+	testRule := &batchResourceRule{
+		enableCFSQuota: true,
+		podCFSQuotaPolicies: map[apiext.QoSClass]podCFSQuotaPolicy{
+			apiext.QoSLS: {policy: slov1alpha1.PodCFSQuotaUnlimitedPolicy, scalePercent: 100},
+			apiext.QoSBE: {policy: slov1alpha1.PodCFSQuotaScaledPolicy, scalePercent: 200},
+		},
+	}
+	type args struct {
+		qos             apiext.QoSClass
+		kubeletCFSQuota *int64
+		curCFSQuota     string
+	}
+	tests := []struct {
+		name string
+		rule *batchResourceRule
+		args args
+		want *int64
+	}{
+		{
+			name: "keep kubelet's value for LS pod without policy",
+			rule: &batchResourceRule{enableCFSQuota: true},
+			args: args{
+				qos:             apiext.QoSLS,
+				kubeletCFSQuota: pointer.Int64(200000),
+				curCFSQuota:     "200000",
+			},
+			want: nil,
+		},
+		{
+			name: "unset the LS pod cfs quota rewritten by kubelet",
+			rule: testRule,
+			args: args{
+				qos:             apiext.QoSLS,
+				kubeletCFSQuota: pointer.Int64(200000),
+				curCFSQuota:     "200000",
+			},
+			want: pointer.Int64(-1),
+		},
+		{
+			name: "skip the LS pod whose cfs quota is already unset",
+			rule: testRule,
+			args: args{
+				qos:             apiext.QoSLS,
+				kubeletCFSQuota: pointer.Int64(200000),
+				curCFSQuota:     "-1",
+			},
+			want: nil,
+		},
+		{
+			name: "skip the LS pod when kubelet's value is unknown",
+			rule: testRule,
+			args: args{
+				qos:         apiext.QoSLS,
+				curCFSQuota: "200000",
+			},
+			want: nil,
+		},
+		{
+			name: "scale the LS pod cfs quota",
+			rule: &batchResourceRule{
+				enableCFSQuota: true,
+				podCFSQuotaPolicies: map[apiext.QoSClass]podCFSQuotaPolicy{
+					apiext.QoSLS: {policy: slov1alpha1.PodCFSQuotaScaledPolicy, scalePercent: 150},
+				},
+			},
+			args: args{
+				qos:             apiext.QoSLS,
+				kubeletCFSQuota: pointer.Int64(200000),
+				curCFSQuota:     "200000",
+			},
+			want: pointer.Int64(300000),
+		},
+		{
+			name: "scale the BE pod cfs quota",
+			rule: testRule,
+			args: args{
+				qos:         apiext.QoSBE,
+				curCFSQuota: "-1",
+			},
+			want: pointer.Int64(100000),
+		},
+		{
+			name: "unset the BE pod cfs quota when the cfs quota suppression is enabled",
+			rule: &batchResourceRule{
+				enableCFSQuota:      false,
+				podCFSQuotaPolicies: testRule.podCFSQuotaPolicies,
+			},
+			args: args{
+				qos:         apiext.QoSBE,
+				curCFSQuota: "50000",
+			},
+			want: pointer.Int64(-1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			helper.WriteCgroupFileContents(testCgroupParent, sysutil.CPUCFSQuota, tt.args.curCFSQuota)
+
+			p := &plugin{
+				rule: tt.rule,
+			}
+			podCtx := &protocol.PodContext{
+				Request: protocol.PodRequest{
+					Labels: map[string]string{
+						apiext.LabelPodQoS: string(tt.args.qos),
+					},
+					CgroupParent:      testCgroupParent,
+					ExtendedResources: testSpec,
+					KubeletCFSQuota:   tt.args.kubeletCFSQuota,
+				},
+			}
+			err := p.SetPodCFSQuota(podCtx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, podCtx.Response.Resources.CFSQuota)
+		})
+	}
+}
+
 func Test_plugin_SetContainerResources(t *testing.T) {
 	var testNilProto *protocol.ContainerContext
 	testEmptySpec := &apiext.ExtendedResourceSpec{}
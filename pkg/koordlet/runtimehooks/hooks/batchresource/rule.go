@@ -25,11 +25,19 @@ import (
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 type batchResourceRule struct {
 	enableCFSQuota bool
+	// podCFSQuotaPolicies is the pod-level cfs quota policy of each QoS class, nil if none is configured
+	podCFSQuotaPolicies map[apiext.QoSClass]podCFSQuotaPolicy
+}
+
+type podCFSQuotaPolicy struct {
+	policy       slov1alpha1.PodCFSQuotaPolicy
+	scalePercent int64
 }
 
 func (r *batchResourceRule) getEnableCFSQuota() bool {
@@ -39,6 +47,36 @@ func (r *batchResourceRule) getEnableCFSQuota() bool {
 	return r.enableCFSQuota
 }
 
+func (r *batchResourceRule) getPodCFSQuotaPolicy(qos apiext.QoSClass) *podCFSQuotaPolicy {
+	if r == nil {
+		return nil
+	}
+	policy, ok := r.podCFSQuotaPolicies[qos]
+	if !ok {
+		return nil
+	}
+	return &policy
+}
+
+// getCFSQuota returns the pod-level cfs quota derived from the sum of the containers' quota.
+func (p *podCFSQuotaPolicy) getCFSQuota(sumCFSQuota int64) int64 {
+	if p == nil || sumCFSQuota <= 0 {
+		return sumCFSQuota
+	}
+	switch p.policy {
+	case slov1alpha1.PodCFSQuotaUnlimitedPolicy:
+		return -1
+	case slov1alpha1.PodCFSQuotaScaledPolicy:
+		cfsQuota := sumCFSQuota * p.scalePercent / 100
+		if cfsQuota < sysutil.CFSQuotaMinValue {
+			cfsQuota = sysutil.CFSQuotaMinValue
+		}
+		return cfsQuota
+	default:
+		return sumCFSQuota
+	}
+}
+
 func (p *plugin) parseRule(mergedNodeSLOIf interface{}) (bool, error) {
 	mergedNodeSLO := mergedNodeSLOIf.(*slov1alpha1.NodeSLOSpec)
 
@@ -51,7 +89,8 @@ func (p *plugin) parseRule(mergedNodeSLOIf interface{}) (bool, error) {
 	}
 
 	rule := &batchResourceRule{
-		enableCFSQuota: enableCFSQuota,
+		enableCFSQuota:      enableCFSQuota,
+		podCFSQuotaPolicies: getPodCFSQuotaPolicies(mergedNodeSLO),
 	}
 
 	updated := p.updateRule(rule)
@@ -67,7 +106,7 @@ func (p *plugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 	}
 	for _, podMeta := range pods {
 		podQOS := apiext.GetPodQoSClass(podMeta.Pod)
-		if podQOS != apiext.QoSBE {
+		if podQOS != apiext.QoSBE && podQOS != apiext.QoSLS {
 			continue
 		}
 		// pod-level
@@ -122,3 +161,39 @@ func getCPUSuppressPolicy(nodeSLOSpec *slov1alpha1.NodeSLOSpec) (bool, slov1alph
 	return *nodeSLOSpec.ResourceUsedThresholdWithBE.Enable,
 		nodeSLOSpec.ResourceUsedThresholdWithBE.CPUSuppressPolicy
 }
+
+func getPodCFSQuotaPolicies(nodeSLOSpec *slov1alpha1.NodeSLOSpec) map[apiext.QoSClass]podCFSQuotaPolicy {
+	if nodeSLOSpec == nil || nodeSLOSpec.ResourceQOSStrategy == nil {
+		return nil
+	}
+	var policies map[apiext.QoSClass]podCFSQuotaPolicy
+	for qos, resourceQOS := range map[apiext.QoSClass]*slov1alpha1.ResourceQOS{
+		apiext.QoSLS: nodeSLOSpec.ResourceQOSStrategy.LSClass,
+		apiext.QoSBE: nodeSLOSpec.ResourceQOSStrategy.BEClass,
+	} {
+		if resourceQOS == nil || resourceQOS.CPUQOS == nil || resourceQOS.CPUQOS.PodCFSQuotaPolicy == nil ||
+			*resourceQOS.CPUQOS.PodCFSQuotaPolicy == slov1alpha1.PodCFSQuotaKubeletPolicy {
+			continue
+		}
+		policy := podCFSQuotaPolicy{
+			policy:       *resourceQOS.CPUQOS.PodCFSQuotaPolicy,
+			scalePercent: 100,
+		}
+		if resourceQOS.CPUQOS.PodCFSQuotaScalePercent != nil && *resourceQOS.CPUQOS.PodCFSQuotaScalePercent > 0 {
+			policy.scalePercent = *resourceQOS.CPUQOS.PodCFSQuotaScalePercent
+		}
+		// NOTE: The cpu suppression limits the BE pods by the cpu usage of the node, so BE pods should not get rid of
+		// the pod-level cfs quota which keeps them within their batch cpu limits.
+		if enable, _ := getCPUSuppressPolicy(nodeSLOSpec); qos == apiext.QoSBE && enable &&
+			policy.policy == slov1alpha1.PodCFSQuotaUnlimitedPolicy {
+			klog.V(4).Infof("ignore pod cfs quota policy %s for BE pods since the cpu suppression is enabled",
+				policy.policy)
+			continue
+		}
+		if policies == nil {
+			policies = map[apiext.QoSClass]podCFSQuotaPolicy{}
+		}
+		policies[qos] = policy
+	}
+	return policies
+}
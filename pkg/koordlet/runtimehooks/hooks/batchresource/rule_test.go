@@ -34,6 +34,9 @@ import (
 )
 
 func Test_plugin_parseRule(t *testing.T) {
+	testKubeletPolicy := slov1alpha1.PodCFSQuotaKubeletPolicy
+	testUnlimitedPolicy := slov1alpha1.PodCFSQuotaUnlimitedPolicy
+	testScaledPolicy := slov1alpha1.PodCFSQuotaScaledPolicy
 	type fields struct {
 		rule *batchResourceRule
 	}
@@ -146,6 +149,83 @@ func Test_plugin_parseRule(t *testing.T) {
 				enableCFSQuota: false,
 			},
 		},
+		{
+			name: "parse pod cfs quota policies",
+			fields: fields{
+				rule: &batchResourceRule{
+					enableCFSQuota: true,
+				},
+			},
+			args: args{
+				mergedNodeSLO: &slov1alpha1.NodeSLOSpec{
+					ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+						LSRClass: &slov1alpha1.ResourceQOS{
+							CPUQOS: &slov1alpha1.CPUQOSCfg{
+								CPUQOS: slov1alpha1.CPUQOS{
+									PodCFSQuotaPolicy: &testUnlimitedPolicy,
+								},
+							},
+						},
+						LSClass: &slov1alpha1.ResourceQOS{
+							CPUQOS: &slov1alpha1.CPUQOSCfg{
+								CPUQOS: slov1alpha1.CPUQOS{
+									PodCFSQuotaPolicy: &testUnlimitedPolicy,
+								},
+							},
+						},
+						BEClass: &slov1alpha1.ResourceQOS{
+							CPUQOS: &slov1alpha1.CPUQOSCfg{
+								CPUQOS: slov1alpha1.CPUQOS{
+									PodCFSQuotaPolicy:       &testScaledPolicy,
+									PodCFSQuotaScalePercent: pointer.Int64(150),
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    true,
+			wantErr: false,
+			wantRule: &batchResourceRule{
+				enableCFSQuota: true,
+				podCFSQuotaPolicies: map[apiext.QoSClass]podCFSQuotaPolicy{
+					apiext.QoSLS: {policy: slov1alpha1.PodCFSQuotaUnlimitedPolicy, scalePercent: 100},
+					apiext.QoSBE: {policy: slov1alpha1.PodCFSQuotaScaledPolicy, scalePercent: 150},
+				},
+			},
+		},
+		{
+			name: "ignore unlimited pod cfs quota of BE when cpu suppression is enabled",
+			args: args{
+				mergedNodeSLO: &slov1alpha1.NodeSLOSpec{
+					ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+						Enable:            pointer.Bool(true),
+						CPUSuppressPolicy: slov1alpha1.CPUSetPolicy,
+					},
+					ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+						LSClass: &slov1alpha1.ResourceQOS{
+							CPUQOS: &slov1alpha1.CPUQOSCfg{
+								CPUQOS: slov1alpha1.CPUQOS{
+									PodCFSQuotaPolicy: &testKubeletPolicy,
+								},
+							},
+						},
+						BEClass: &slov1alpha1.ResourceQOS{
+							CPUQOS: &slov1alpha1.CPUQOSCfg{
+								CPUQOS: slov1alpha1.CPUQOS{
+									PodCFSQuotaPolicy: &testUnlimitedPolicy,
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    true,
+			wantErr: false,
+			wantRule: &batchResourceRule{
+				enableCFSQuota: true,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
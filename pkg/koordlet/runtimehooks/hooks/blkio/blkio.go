@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blkio
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/reconciler"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	name        = "BlkIOReconcile"
+	description = "set the blkio throttling of the besteffort cgroup on the disks of the mount points"
+)
+
+var blkioResources = []sysutil.Resource{
+	sysutil.BlkioReadIops,
+	sysutil.BlkioWriteIops,
+	sysutil.BlkioReadBps,
+	sysutil.BlkioWriteBps,
+}
+
+type blkioPlugin struct {
+	rule         *blkioRule
+	ruleRWMutex  sync.RWMutex
+	sysSupported *bool
+	// resolver maps the mount points to the disks the throttling applies to
+	resolver *sysutil.BlockDeviceResolver
+	// applied is the throttled devices by the blkio file, whose limits are removed once out of the rule
+	applied      map[sysutil.ResourceType]sets.String
+	appliedMutex sync.Mutex
+	cgroupWrite  func(cgroupTaskDir string, r sysutil.Resource, value string) error
+}
+
+func (p *blkioPlugin) Register() {
+	klog.V(5).Infof("register hook %v", name)
+	rule.Register(name, description,
+		rule.WithParseFunc(statesinformer.RegisterTypeNodeSLOSpec, p.parseRule),
+		rule.WithUpdateCallback(p.ruleUpdateCb),
+		rule.WithSystemSupported(p.SystemSupported))
+	reconciler.RegisterCgroupReconciler(reconciler.KubeQOSLevel, sysutil.BlkioReadBps, name, "reconcile kubeqos level blkio throttling",
+		p.SetKubeQOSBlkIO, reconciler.NoneFilter())
+}
+
+func (p *blkioPlugin) SystemSupported() bool {
+	if p.sysSupported == nil {
+		isSupported, msg := false, "resource not found"
+		blkioResource, err := sysutil.GetCgroupResource(sysutil.BlkioTRBpsName)
+		if err == nil {
+			isSupported, msg = blkioResource.IsSupported(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort))
+		}
+		p.sysSupported = pointer.BoolPtr(isSupported)
+		klog.Infof("update system supported info to %v for plugin %v, supported msg %s", isSupported, name, msg)
+	}
+	return *p.sysSupported
+}
+
+var singleton *blkioPlugin
+
+func Object() *blkioPlugin {
+	if singleton == nil {
+		singleton = newPlugin()
+	}
+	return singleton
+}
+
+func newPlugin() *blkioPlugin {
+	return &blkioPlugin{
+		resolver:    sysutil.NewBlockDeviceResolver(),
+		applied:     map[sysutil.ResourceType]sets.String{},
+		cgroupWrite: sysutil.CgroupFileWrite,
+	}
+}
+
+func (p *blkioPlugin) SetKubeQOSBlkIO(proto protocol.HooksProtocol) error {
+	kubeQOSCtx := proto.(*protocol.KubeQOSContext)
+	if kubeQOSCtx == nil {
+		return fmt.Errorf("kube qos protocol is nil for plugin %v", name)
+	}
+	if kubeQOSCtx.Request.KubeQOSClass != corev1.PodQOSBestEffort {
+		return nil
+	}
+	return p.applyBlkIO(kubeQOSCtx.Request.CgroupParent)
+}
+
+// applyBlkIO sets the limits of the rule on the throttle devices of the mount points, and removes the limits applied
+// before but no longer in the rule.
+func (p *blkioPlugin) applyBlkIO(cgroupParent string) error {
+	desired := p.getRule().getDeviceLimits(p.resolver)
+
+	p.appliedMutex.Lock()
+	defer p.appliedMutex.Unlock()
+	var lastErr error
+	for _, resource := range blkioResources {
+		resourceType := resource.ResourceType()
+		limits := desired[resourceType]
+		applied := sets.NewString()
+		for _, device := range sets.StringKeySet(limits).List() {
+			if err := p.cgroupWrite(cgroupParent, resource, fmt.Sprintf("%s %d", device, limits[device])); err != nil {
+				klog.V(4).Infof("failed to set %s of device %s on %s, err: %v", resourceType, device, cgroupParent, err)
+				lastErr = err
+				continue
+			}
+			applied.Insert(device)
+			audit.V(3).Group(string(corev1.PodQOSBestEffort)).Reason(name).Message("set %s to %d on device %s",
+				resourceType, limits[device], device).Do()
+		}
+		for _, device := range p.applied[resourceType].Difference(applied).List() {
+			if _, ok := limits[device]; ok {
+				// keep retrying the device failed to update
+				applied.Insert(device)
+				continue
+			}
+			// the limit 0 removes the throttling rule of the device
+			if err := p.cgroupWrite(cgroupParent, resource, fmt.Sprintf("%s 0", device)); err != nil {
+				klog.V(4).Infof("failed to remove %s of device %s on %s, err: %v", resourceType, device, cgroupParent, err)
+				applied.Insert(device)
+				lastErr = err
+				continue
+			}
+			audit.V(3).Group(string(corev1.PodQOSBestEffort)).Reason(name).Message("remove %s on device %s",
+				resourceType, device).Do()
+		}
+		p.applied[resourceType] = applied
+	}
+	return lastErr
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blkio
+
+import (
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func prepareTestBlockDevices(t *testing.T) *sysutil.FileTestUtil {
+	helper := sysutil.NewFileTestUtil(t)
+	oldSysRootDir := sysutil.Conf.SysRootDir
+	sysutil.Conf.SysRootDir = path.Join(helper.TempDir, "sys")
+	t.Cleanup(func() {
+		sysutil.Conf.SysRootDir = oldSysRootDir
+	})
+	// the rootfs is on the partition nvme0n1p1, and /data is on the lvm striped over nvme0n1p2 and nvme1n1
+	helper.WriteFileContents("sys/block/nvme0n1/dev", "259:0\n")
+	helper.WriteFileContents("sys/block/nvme0n1/nvme0n1p1/dev", "259:1\n")
+	helper.WriteFileContents("sys/block/nvme0n1/nvme0n1p1/partition", "1\n")
+	helper.WriteFileContents("sys/block/nvme0n1/nvme0n1p2/dev", "259:2\n")
+	helper.WriteFileContents("sys/block/nvme0n1/nvme0n1p2/partition", "2\n")
+	helper.CreateFile("sys/block/nvme0n1/nvme0n1p2/holders/dm-0")
+	helper.WriteFileContents("sys/block/nvme1n1/dev", "259:3\n")
+	helper.CreateFile("sys/block/nvme1n1/holders/dm-0")
+	helper.WriteFileContents("sys/block/dm-0/dev", "253:0\n")
+	helper.WriteFileContents("sys/block/dm-0/dm/name", "vg0-lv0\n")
+	helper.CreateFile("sys/block/dm-0/slaves/nvme0n1p2")
+	helper.CreateFile("sys/block/dm-0/slaves/nvme1n1")
+	helper.WriteFileContents(path.Join("proc", sysutil.ProcMountInfoName), `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+36 22 253:0 / /data rw,relatime shared:2 - xfs /dev/mapper/vg0-lv0 rw
+`)
+	return helper
+}
+
+type fakeCgroupWriter struct {
+	writes []string
+	failed map[string]bool
+}
+
+func (f *fakeCgroupWriter) write(cgroupTaskDir string, r sysutil.Resource, value string) error {
+	line := fmt.Sprintf("%s %s %s", cgroupTaskDir, r.ResourceType(), value)
+	if f.failed[line] {
+		return fmt.Errorf("failed to write %s", line)
+	}
+	f.writes = append(f.writes, line)
+	return nil
+}
+
+func newTestNodeSLO(blocks ...*slov1alpha1.BlockCfg) *slov1alpha1.NodeSLOSpec {
+	return &slov1alpha1.NodeSLOSpec{
+		ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+			BEClass: &slov1alpha1.ResourceQOS{
+				BlkIOQOS: &slov1alpha1.BlkIOQOSCfg{
+					Enable: pointer.Bool(true),
+					BlkIOQOS: slov1alpha1.BlkIOQOS{
+						Blocks: blocks,
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_blkioPlugin_SetKubeQOSBlkIO(t *testing.T) {
+	helper := prepareTestBlockDevices(t)
+	defer helper.Cleanup()
+
+	writer := &fakeCgroupWriter{}
+	p := newPlugin()
+	p.cgroupWrite = writer.write
+
+	updated, err := p.parseRule(newTestNodeSLO(
+		&slov1alpha1.BlockCfg{MountPoint: "/", IOCfg: slov1alpha1.IOCfg{ReadBPS: pointer.Int64(2000), WriteIOPS: pointer.Int64(0)}},
+		&slov1alpha1.BlockCfg{MountPoint: "/data", IOCfg: slov1alpha1.IOCfg{ReadBPS: pointer.Int64(1000), WriteBPS: pointer.Int64(3000)}},
+	))
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	// the burstable kube qos is not throttled
+	err = p.SetKubeQOSBlkIO(&protocol.KubeQOSContext{Request: protocol.KubeQOSRequet{
+		KubeQOSClass: corev1.PodQOSBurstable,
+		CgroupParent: "kubepods/burstable",
+	}})
+	assert.NoError(t, err)
+	assert.Empty(t, writer.writes)
+
+	err = p.SetKubeQOSBlkIO(&protocol.KubeQOSContext{Request: protocol.KubeQOSRequet{
+		KubeQOSClass: corev1.PodQOSBestEffort,
+		CgroupParent: "kubepods/besteffort",
+	}})
+	assert.NoError(t, err)
+	// nvme0n1 under both mount points takes the lower read bps
+	assert.Equal(t, []string{
+		"kubepods/besteffort blkio.throttle.read_bps_device 259:0 1000",
+		"kubepods/besteffort blkio.throttle.read_bps_device 259:3 1000",
+		"kubepods/besteffort blkio.throttle.write_bps_device 259:0 3000",
+		"kubepods/besteffort blkio.throttle.write_bps_device 259:3 3000",
+	}, writer.writes)
+
+	// the disks out of the rule are reset, and the failed ones are retried in the next round
+	writer.writes = nil
+	writer.failed = map[string]bool{
+		"kubepods/besteffort blkio.throttle.read_bps_device 259:0 2000": true,
+	}
+	updated, err = p.parseRule(newTestNodeSLO(
+		&slov1alpha1.BlockCfg{MountPoint: "/", IOCfg: slov1alpha1.IOCfg{ReadBPS: pointer.Int64(2000)}},
+	))
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	err = p.applyBlkIO("kubepods/besteffort")
+	assert.Error(t, err)
+	assert.Equal(t, []string{
+		"kubepods/besteffort blkio.throttle.read_bps_device 259:3 0",
+		"kubepods/besteffort blkio.throttle.write_bps_device 259:0 0",
+		"kubepods/besteffort blkio.throttle.write_bps_device 259:3 0",
+	}, writer.writes)
+
+	writer.writes = nil
+	writer.failed = nil
+	err = p.applyBlkIO("kubepods/besteffort")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"kubepods/besteffort blkio.throttle.read_bps_device 259:0 2000",
+	}, writer.writes)
+
+	// disabling the rule removes all the limits
+	writer.writes = nil
+	updated, err = p.parseRule(&slov1alpha1.NodeSLOSpec{})
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	err = p.applyBlkIO("kubepods/besteffort")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"kubepods/besteffort blkio.throttle.read_bps_device 259:0 0",
+	}, writer.writes)
+}
+
+func Test_blkioRule_getDeviceLimits(t *testing.T) {
+	helper := prepareTestBlockDevices(t)
+	defer helper.Cleanup()
+
+	resolver := sysutil.NewBlockDeviceResolver()
+	var nilRule *blkioRule
+	assert.Empty(t, nilRule.getDeviceLimits(resolver))
+
+	r := &blkioRule{
+		enable: true,
+		blocks: []slov1alpha1.BlockCfg{
+			{MountPoint: "/data/containerd", IOCfg: slov1alpha1.IOCfg{ReadIOPS: pointer.Int64(500)}},
+			{MountPoint: "relative/path", IOCfg: slov1alpha1.IOCfg{ReadIOPS: pointer.Int64(100)}},
+		},
+	}
+	assert.Equal(t, map[sysutil.ResourceType]map[string]int64{
+		sysutil.BlkioTRIopsName: {
+			"259:0": 500,
+			"259:3": 500,
+		},
+	}, r.getDeviceLimits(resolver))
+}
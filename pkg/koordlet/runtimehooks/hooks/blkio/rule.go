@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blkio
+
+import (
+	"path/filepath"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+type blkioRule struct {
+	enable bool
+	blocks []slov1alpha1.BlockCfg
+}
+
+func (r *blkioRule) getEnable() bool {
+	if r == nil {
+		return false
+	}
+	return r.enable
+}
+
+// getDeviceLimits returns the limits by the device number of each blkio file. A disk under several mount points
+// takes the lowest limit.
+func (r *blkioRule) getDeviceLimits(resolver *sysutil.BlockDeviceResolver) map[sysutil.ResourceType]map[string]int64 {
+	limits := map[sysutil.ResourceType]map[string]int64{}
+	if !r.getEnable() {
+		return limits
+	}
+	for i := range r.blocks {
+		block := &r.blocks[i]
+		if !filepath.IsAbs(block.MountPoint) {
+			klog.V(4).Infof("skip the blkio throttling of mount point %s, not an absolute path", block.MountPoint)
+			continue
+		}
+		resolution, err := resolver.Resolve(block.MountPoint)
+		if err != nil {
+			klog.V(4).Infof("skip the blkio throttling of mount point %s, err: %v", block.MountPoint, err)
+			continue
+		}
+		for resource, limit := range map[sysutil.Resource]*int64{
+			sysutil.BlkioReadIops:  block.ReadIOPS,
+			sysutil.BlkioWriteIops: block.WriteIOPS,
+			sysutil.BlkioReadBps:   block.ReadBPS,
+			sysutil.BlkioWriteBps:  block.WriteBPS,
+		} {
+			if limit == nil || *limit <= 0 {
+				continue
+			}
+			resourceType := resource.ResourceType()
+			if limits[resourceType] == nil {
+				limits[resourceType] = map[string]int64{}
+			}
+			for _, device := range resolution.ThrottleDevices {
+				number := device.DeviceNumber()
+				if cur, ok := limits[resourceType][number]; !ok || *limit < cur {
+					limits[resourceType][number] = *limit
+				}
+			}
+		}
+	}
+	return limits
+}
+
+func (p *blkioPlugin) parseRule(mergedNodeSLOIf interface{}) (bool, error) {
+	mergedNodeSLO := mergedNodeSLOIf.(*slov1alpha1.NodeSLOSpec)
+
+	newRule := &blkioRule{}
+	if mergedNodeSLO.ResourceQOSStrategy != nil && mergedNodeSLO.ResourceQOSStrategy.BEClass != nil {
+		cfg := mergedNodeSLO.ResourceQOSStrategy.BEClass.BlkIOQOS
+		if cfg != nil && cfg.Enable != nil && *cfg.Enable {
+			newRule.enable = true
+			for _, block := range cfg.Blocks {
+				if block != nil && block.MountPoint != "" {
+					newRule.blocks = append(newRule.blocks, *block.DeepCopy())
+				}
+			}
+		}
+	}
+
+	updated := p.updateRule(newRule)
+	klog.Infof("runtime hook plugin %s update rule %v, new rule %v", name, updated, newRule)
+	return updated, nil
+}
+
+func (p *blkioPlugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
+	if !p.SystemSupported() {
+		klog.V(5).Infof("plugin %s is not supported by system", name)
+		return nil
+	}
+	if p.getRule() == nil {
+		klog.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		return nil
+	}
+	return p.applyBlkIO(util.GetKubeQosRelativePath(corev1.PodQOSBestEffort))
+}
+
+func (p *blkioPlugin) getRule() *blkioRule {
+	p.ruleRWMutex.RLock()
+	defer p.ruleRWMutex.RUnlock()
+	if p.rule == nil {
+		return nil
+	}
+	rule := *p.rule
+	return &rule
+}
+
+func (p *blkioPlugin) updateRule(newRule *blkioRule) bool {
+	p.ruleRWMutex.Lock()
+	defer p.ruleRWMutex.Unlock()
+	if !reflect.DeepEqual(newRule, p.rule) {
+		p.rule = newRule
+		return true
+	}
+	return false
+}
@@ -54,10 +54,10 @@ func (p *cpusetPlugin) Register() {
 	rule.Register(name, description,
 		rule.WithParseFunc(statesinformer.RegisterTypeNodeTopology, p.parseRule),
 		rule.WithUpdateCallback(p.ruleUpdateCb))
-	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.CPUSet,
+	reconciler.RegisterCgroupReconciler(reconciler.ContainerLevel, sysutil.CPUSet, name,
 		"set container cpuset and unset container cpu quota if needed",
 		p.SetContainerCPUSetAndUnsetCFS, reconciler.PodQOSFilter(), podQOSConditions...)
-	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUCFSQuota, "unset pod cpu quota if needed",
+	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUCFSQuota, name, "unset pod cpu quota if needed",
 		UnsetPodCPUQuota, reconciler.PodQOSFilter(), podQOSConditions...)
 }
 
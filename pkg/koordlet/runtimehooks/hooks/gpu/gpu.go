@@ -29,13 +29,18 @@ import (
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
-const GpuAllocEnv = "NVIDIA_VISIBLE_DEVICES"
+const (
+	name        = "GPUEnvInject"
+	description = "inject NVIDIA_VISIBLE_DEVICES env into container"
+
+	GpuAllocEnv = "NVIDIA_VISIBLE_DEVICES"
+)
 
 type gpuPlugin struct{}
 
 func (p *gpuPlugin) Register() {
-	klog.V(5).Infof("register hook %v", "gpu env inject")
-	hooks.Register(rmconfig.PreCreateContainer, "gpu env inject", "inject NVIDIA_VISIBLE_DEVICES env into container", p.InjectContainerGPUEnv)
+	klog.V(5).Infof("register hook %v", name)
+	hooks.Register(rmconfig.PreCreateContainer, name, description, p.InjectContainerGPUEnv)
 }
 
 var singleton *gpuPlugin
@@ -53,9 +53,9 @@ func (b *bvtPlugin) Register() {
 		rule.WithParseFunc(statesinformer.RegisterTypeNodeSLOSpec, b.parseRule),
 		rule.WithUpdateCallback(b.ruleUpdateCb),
 		rule.WithSystemSupported(b.SystemSupported))
-	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUBVTWarpNs, "reconcile pod level cpu bvt value",
+	reconciler.RegisterCgroupReconciler(reconciler.PodLevel, sysutil.CPUBVTWarpNs, name, "reconcile pod level cpu bvt value",
 		b.SetPodBvtValue, reconciler.NoneFilter())
-	reconciler.RegisterCgroupReconciler(reconciler.KubeQOSLevel, sysutil.CPUBVTWarpNs, "reconcile kubeqos level cpu bvt value",
+	reconciler.RegisterCgroupReconciler(reconciler.KubeQOSLevel, sysutil.CPUBVTWarpNs, name, "reconcile kubeqos level cpu bvt value",
 		b.SetKubeQOSBvtValue, reconciler.NoneFilter())
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/klog/v2"
 
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/exclusion"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
@@ -76,6 +77,10 @@ func RunHooks(failPolicy rmconfig.FailurePolicyType, stage rmconfig.RuntimeHookT
 	hooks := getHooksByStage(stage)
 	klog.V(5).Infof("start run %v hooks at %s", len(hooks), stage)
 	for _, hook := range hooks {
+		if exclusion.IsPodExcluded(hook.name, protocol) {
+			klog.V(5).Infof("skip hook %v since the pod is excluded", hook.name)
+			continue
+		}
 		klog.V(5).Infof("call hook %v", hook.name)
 		if err := hook.fn(protocol); err != nil {
 			klog.Errorf("failed to run hook %s in stage %s, reason: %v", hook.name, stage, err)
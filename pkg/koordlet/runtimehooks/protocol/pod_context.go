@@ -25,6 +25,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -52,6 +53,8 @@ type PodRequest struct {
 	Annotations       map[string]string
 	CgroupParent      string
 	ExtendedResources *apiext.ExtendedResourceSpec
+	// KubeletCFSQuota is the pod-level cfs quota set by the kubelet, which is unknown (nil) in the proxy request
+	KubeletCFSQuota *int64
 }
 
 func (p *PodRequest) FromProxy(req *runtimeapi.PodSandboxHookRequest) {
@@ -87,6 +90,15 @@ func (p *PodRequest) FromReconciler(podMeta *statesinformer.PodMeta) {
 	} else if specFromAnnotations != nil && specFromAnnotations.Containers != nil { // specFromPod == nil
 		p.ExtendedResources = specFromAnnotations
 	}
+	// kubelet sets the pod-level cfs quota as the sum of the containers' cpu limits
+	kubeletCFSQuota := int64(-1)
+	if milliCPULimit := util.GetPodMilliCPULimit(podMeta.Pod); milliCPULimit > 0 {
+		kubeletCFSQuota = milliCPULimit * sysutil.CFSBasePeriodValue / 1000
+		if kubeletCFSQuota < sysutil.CFSQuotaMinValue {
+			kubeletCFSQuota = sysutil.CFSQuotaMinValue
+		}
+	}
+	p.KubeletCFSQuota = &kubeletCFSQuota
 }
 
 type PodResponse struct {
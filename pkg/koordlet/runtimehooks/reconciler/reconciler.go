@@ -21,9 +21,12 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/exclusion"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
@@ -60,6 +63,9 @@ type cgroupReconciler struct {
 	level       ReconcilerLevel
 	filter      Filter
 	fn          map[string]reconcileFunc
+	// hooks are the names of the hooks registering the reconcile functions by condition, which decide the pods
+	// excluded from the reconcile functions
+	hooks map[string]string
 }
 
 // Filter & Conditions:
@@ -134,10 +140,12 @@ type reconcileFunc func(protocol.HooksProtocol) error
 
 // RegisterCgroupReconciler registers a cgroup reconciler according to the cgroup file, reconcile function and filter
 // conditions. A cgroup file of one level can have multiple reconcile functions with different filtered conditions.
-//   e.g. pod-level cfs_quota can be registered both by cpuset hook and batchresource hook. While cpuset hook reconciles
-//   cfs_quota for LSE and LSR pods, batchresource reconciles pods of other QoS classes.
+//
+//	e.g. pod-level cfs_quota can be registered both by cpuset hook and batchresource hook. While cpuset hook reconciles
+//	cfs_quota for LSE and LSR pods, batchresource reconciles pods of other QoS classes.
+//
 // TODO: support priority+qos filter.
-func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource, description string,
+func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource, hookName, description string,
 	fn reconcileFunc, filter Filter, conditions ...string) {
 	if len(conditions) <= 0 { // default condition
 		conditions = []string{NoneFilterCondition}
@@ -161,6 +169,7 @@ func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource,
 			}
 
 			r.fn[condition] = fn
+			r.hooks[condition] = hookName
 		}
 		klog.V(1).Infof("register reconcile function %v finished, info: level=%v, resourceType=%v, add conditions=%v",
 			description, level, cgroupFile.ResourceType(), conditions)
@@ -173,6 +182,7 @@ func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource,
 		description: description,
 		level:       level,
 		fn:          map[string]reconcileFunc{},
+		hooks:       map[string]string{},
 	}
 
 	globalCgroupReconcilers.all = append(globalCgroupReconcilers.all, r)
@@ -180,17 +190,20 @@ func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource,
 	case KubeQOSLevel:
 		r.filter = NoneFilter()
 		r.fn[NoneFilterCondition] = fn
+		r.hooks[NoneFilterCondition] = hookName
 		globalCgroupReconcilers.kubeQOSLevel[string(r.cgroupFile.ResourceType())] = r
 	case PodLevel:
 		r.filter = filter
 		for _, condition := range conditions {
 			r.fn[condition] = fn
+			r.hooks[condition] = hookName
 		}
 		globalCgroupReconcilers.podLevel[string(r.cgroupFile.ResourceType())] = r
 	case ContainerLevel:
 		r.filter = filter
 		for _, condition := range conditions {
 			r.fn[condition] = fn
+			r.hooks[condition] = hookName
 		}
 		globalCgroupReconcilers.containerLevel[string(r.cgroupFile.ResourceType())] = r
 	default:
@@ -200,13 +213,21 @@ func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource,
 		description, level, cgroupFile.ResourceType(), filter.Name(), conditions)
 }
 
+// restartStormEssentialResources are the minimal safe parameters still applied to the containers in restart storms,
+// while the other parameters are deferred until the containers stabilize.
+var restartStormEssentialResources = map[system.ResourceType]struct{}{
+	system.MemoryLimit.ResourceType(): {},
+}
+
 type Reconciler interface {
 	Run(stopCh <-chan struct{}) error
 }
 
 func NewReconciler(s statesinformer.StatesInformer) Reconciler {
 	r := &reconciler{
-		podUpdated: make(chan struct{}, 1),
+		podUpdated:   make(chan struct{}, 1),
+		clock:        clock.RealClock{},
+		restartStorm: statesinformer.NewDefaultRestartStormDetector(),
 	}
 	// TODO register individual pod event
 	s.RegisterCallbacks(statesinformer.RegisterTypeAllPods, "runtime-hooks-reconciler",
@@ -218,6 +239,14 @@ type reconciler struct {
 	podsMutex  sync.RWMutex
 	podsMeta   []*statesinformer.PodMeta
 	podUpdated chan struct{}
+
+	clock clock.Clock
+	// restartStorm detects the crash-looping containers, only the essential parameters are reconciled for them.
+	restartStorm *statesinformer.RestartStormDetector
+	// deferredMutex protects deferredScheduled, which indicates a reconciliation of the deferred parameters is
+	// scheduled after the stabilization period.
+	deferredMutex     sync.Mutex
+	deferredScheduled bool
 }
 
 func (c *reconciler) Run(stopCh <-chan struct{}) error {
@@ -232,11 +261,44 @@ func (c *reconciler) podRefreshCallback(t statesinformer.RegisterType, o interfa
 	c.podsMutex.Lock()
 	defer c.podsMutex.Unlock()
 	c.podsMeta = podsMeta
-	if len(c.podUpdated) == 0 {
-		c.podUpdated <- struct{}{}
+	c.restartStorm.Update(podsMeta)
+	c.notifyPodUpdated()
+}
+
+func (c *reconciler) notifyPodUpdated() {
+	select {
+	case c.podUpdated <- struct{}{}:
+	default:
 	}
 }
 
+// deferInRestartStorm checks if the reconciler should be deferred since the container is in restart storm.
+// The deferred parameters are reconciled after the stabilization period.
+func (c *reconciler) deferInRestartStorm(r *cgroupReconciler, podMeta *statesinformer.PodMeta, containerName string) bool {
+	if _, ok := restartStormEssentialResources[r.cgroupFile.ResourceType()]; ok {
+		return false
+	}
+	if !c.restartStorm.InStorm(podMeta.Pod, containerName) {
+		return false
+	}
+	klog.V(5).Infof("container %v/%v is in restart storm, defer calling reconcile function %v",
+		util.GetPodKey(podMeta.Pod), containerName, r.description)
+	metrics.RecordQoSApplicationDeferred(string(r.cgroupFile.ResourceType()))
+
+	c.deferredMutex.Lock()
+	defer c.deferredMutex.Unlock()
+	if !c.deferredScheduled {
+		c.deferredScheduled = true
+		c.clock.AfterFunc(c.restartStorm.StabilizationPeriod(), func() {
+			c.deferredMutex.Lock()
+			c.deferredScheduled = false
+			c.deferredMutex.Unlock()
+			c.notifyPodUpdated()
+		})
+	}
+	return true
+}
+
 func (c *reconciler) getPodsMeta() []*statesinformer.PodMeta {
 	c.podsMutex.RLock()
 	defer c.podsMutex.RUnlock()
@@ -289,48 +351,62 @@ func (c *reconciler) reconcilePodCgroup(stopCh <-chan struct{}) {
 	for {
 		select {
 		case <-c.podUpdated:
-			podsMeta := c.getPodsMeta()
-			for _, podMeta := range podsMeta {
-				for _, r := range globalCgroupReconcilers.podLevel {
-					reconcileFn, ok := r.fn[r.filter.Filter(podMeta)]
-					if !ok {
-						klog.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
-							r.description, r.filter.Filter(podMeta))
-						continue
-					}
-
-					podCtx := protocol.HooksProtocolBuilder.Pod(podMeta)
-					if err := reconcileFn(podCtx); err != nil {
-						klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
-					} else {
-						podCtx.ReconcilerDone()
-						klog.V(5).Infof("calling reconcile function %v for pod %v finished",
-							r.description, util.GetPodKey(podMeta.Pod))
-					}
-				}
-				for _, containerStat := range podMeta.Pod.Status.ContainerStatuses {
-					for _, r := range globalCgroupReconcilers.containerLevel {
-						reconcileFn, ok := r.fn[r.filter.Filter(podMeta)]
-						if !ok {
-							klog.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
-								r.description, r.filter.Filter(podMeta))
-							continue
-						}
-
-						containerCtx := protocol.HooksProtocolBuilder.Container(podMeta, containerStat.Name)
-						if err := reconcileFn(containerCtx); err != nil {
-							klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
-						} else {
-							containerCtx.ReconcilerDone()
-							klog.V(5).Infof("calling reconcile function %v for container %v/%v finish",
-								r.description, util.GetPodKey(podMeta.Pod), containerStat.Name)
-						}
-					}
-				}
-			}
+			c.reconcileAllPods(c.getPodsMeta())
 		case <-stopCh:
 			klog.V(1).Infof("stop reconcile pod cgroup")
 			return
 		}
 	}
 }
+
+func (c *reconciler) reconcileAllPods(podsMeta []*statesinformer.PodMeta) {
+	for _, podMeta := range podsMeta {
+		for _, r := range globalCgroupReconcilers.podLevel {
+			condition := r.filter.Filter(podMeta)
+			reconcileFn, ok := r.fn[condition]
+			if !ok {
+				klog.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
+					r.description, condition)
+				continue
+			}
+			if exclusion.IsPodMetaExcluded(r.hooks[condition], podMeta) {
+				continue
+			}
+
+			podCtx := protocol.HooksProtocolBuilder.Pod(podMeta)
+			if err := reconcileFn(podCtx); err != nil {
+				klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
+			} else {
+				podCtx.ReconcilerDone()
+				klog.V(5).Infof("calling reconcile function %v for pod %v finished",
+					r.description, util.GetPodKey(podMeta.Pod))
+			}
+		}
+		for _, containerStat := range podMeta.Pod.Status.ContainerStatuses {
+			for _, r := range globalCgroupReconcilers.containerLevel {
+				condition := r.filter.Filter(podMeta)
+				reconcileFn, ok := r.fn[condition]
+				if !ok {
+					klog.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
+						r.description, condition)
+					continue
+				}
+				if exclusion.IsPodMetaExcluded(r.hooks[condition], podMeta) {
+					continue
+				}
+				if c.deferInRestartStorm(r, podMeta, containerStat.Name) {
+					continue
+				}
+
+				containerCtx := protocol.HooksProtocolBuilder.Container(podMeta, containerStat.Name)
+				if err := reconcileFn(containerCtx); err != nil {
+					klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
+				} else {
+					containerCtx.ReconcilerDone()
+					klog.V(5).Infof("calling reconcile function %v for container %v/%v finish",
+						r.description, util.GetPodKey(podMeta.Pod), containerStat.Name)
+				}
+			}
+		}
+	}
+}
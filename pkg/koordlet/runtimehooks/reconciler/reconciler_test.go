@@ -19,12 +19,16 @@ package reconciler
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
@@ -78,7 +82,7 @@ func Test_doKubeQOSCgroup(t *testing.T) {
 				tt.gots.kubeQOSVal[kubeQOS] = tt.args.targetOutput[kubeQOS]
 				return nil
 			}
-			RegisterCgroupReconciler(KubeQOSLevel, tt.args.resource, tt.name, reconcilerFn, NoneFilter())
+			RegisterCgroupReconciler(KubeQOSLevel, tt.args.resource, "test", tt.name, reconcilerFn, NoneFilter())
 			doKubeQOSCgroup()
 			assert.Equal(t, tt.wants.kubeQOSVal, tt.gots.kubeQOSVal, "kube qos map value should be equal")
 		})
@@ -118,8 +122,8 @@ func Test_reconciler_reconcilePodCgroup(t *testing.T) {
 		tryStopFn()
 		return nil
 	}
-	RegisterCgroupReconciler(PodLevel, system.CPUBVTWarpNs, "get pod uid", podReconcilerFn, NoneFilter())
-	RegisterCgroupReconciler(ContainerLevel, system.CPUBVTWarpNs, "get container uid", containerReconcilerFn, NoneFilter())
+	RegisterCgroupReconciler(PodLevel, system.CPUBVTWarpNs, "test", "get pod uid", podReconcilerFn, NoneFilter())
+	RegisterCgroupReconciler(ContainerLevel, system.CPUBVTWarpNs, "test", "get container uid", containerReconcilerFn, NoneFilter())
 
 	type fields struct {
 		podsMeta *statesinformer.PodMeta
@@ -183,6 +187,82 @@ func Test_reconciler_reconcilePodCgroup(t *testing.T) {
 	})
 }
 
+func Test_reconciler_reconcileInRestartStorm(t *testing.T) {
+	metrics.Register(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	defer metrics.Register(nil)
+	deferred := metrics.QoSApplicationDeferred.WithLabelValues("test-node", string(system.CPUSet.ResourceType()))
+	deferredBefore := testutil.ToFloat64(deferred)
+
+	applied := map[system.ResourceType]int{}
+	for _, resource := range []system.Resource{system.MemoryLimit, system.CPUSet} {
+		resourceType := resource.ResourceType()
+		RegisterCgroupReconciler(ContainerLevel, resource, "test", "count "+string(resourceType), func(proto protocol.HooksProtocol) error {
+			applied[resourceType]++
+			return nil
+		}, NoneFilter())
+	}
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	c := &reconciler{
+		podUpdated:   make(chan struct{}, 1),
+		clock:        fakeClock,
+		restartStorm: statesinformer.NewRestartStormDetector(5*time.Minute, 3, 2*time.Minute, fakeClock),
+	}
+	restart := func(restartCount int32) {
+		podMeta := &statesinformer.PodMeta{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test-ns",
+					Name:      "test-be-pod",
+					UID:       "test-be-pod-uid",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-container"}},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "test-container",
+							ContainerID:  "test-container-id",
+							RestartCount: restartCount,
+						},
+					},
+				},
+			},
+		}
+		c.podRefreshCallback(statesinformer.RegisterTypeAllPods, nil, []*statesinformer.PodMeta{podMeta})
+		<-c.podUpdated
+		c.reconcileAllPods(c.getPodsMeta())
+	}
+
+	// the container crashes but not frequently enough
+	for i := int32(0); i < 3; i++ {
+		restart(i)
+		fakeClock.Step(10 * time.Second)
+	}
+	assert.Equal(t, 3, applied[system.MemoryLimit.ResourceType()])
+	assert.Equal(t, 3, applied[system.CPUSet.ResourceType()])
+
+	// the restart storm: only the memory limit is applied
+	restart(3)
+	fakeClock.Step(10 * time.Second)
+	restart(4)
+	assert.Equal(t, 5, applied[system.MemoryLimit.ResourceType()])
+	assert.Equal(t, 3, applied[system.CPUSet.ResourceType()])
+	assert.Equal(t, deferredBefore+2, testutil.ToFloat64(deferred))
+
+	// the container stays up for the stabilization period, then the deferred parameters are applied
+	fakeClock.Step(time.Minute)
+	assert.Len(t, c.podUpdated, 0)
+	fakeClock.Step(time.Minute)
+	assert.Len(t, c.podUpdated, 1)
+	<-c.podUpdated
+	c.reconcileAllPods(c.getPodsMeta())
+	assert.Equal(t, 6, applied[system.MemoryLimit.ResourceType()])
+	assert.Equal(t, 4, applied[system.CPUSet.ResourceType()])
+	assert.Equal(t, deferredBefore+2, testutil.ToFloat64(deferred))
+}
+
 func Test_reconciler_podRefreshCallback(t *testing.T) {
 	type args struct {
 		podsMeta []*statesinformer.PodMeta
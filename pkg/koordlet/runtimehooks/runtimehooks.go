@@ -20,6 +20,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/exclusion"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/proxyserver"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/reconciler"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
@@ -80,6 +81,14 @@ func NewRuntimeHook(si statesinformer.StatesInformer, cfg *Config) (RuntimeHook,
 	if err != nil {
 		return nil, err
 	}
+	exclusionCfg := &exclusion.Config{
+		PodSelectors: exclusion.ParsePodSelectors(cfg.RuntimeHookExclusionPodSelectors),
+		OwnerKinds:   cfg.RuntimeHookExclusionOwnerKinds,
+		Hooks:        cfg.RuntimeHookExclusionHooks,
+	}
+	if err := exclusion.Setup(exclusionCfg, si); err != nil {
+		return nil, err
+	}
 	r := &runtimeHook{
 		statesInformer: si,
 		server:         s,
@@ -55,6 +55,7 @@ func Test_runtimeHook_Run(t *testing.T) {
 					string(CPUSetAllocator): false,
 					string(GPUEnvInject):    false,
 					string(BatchResource):   false,
+					string(BlkIOReconcile):  false,
 				},
 			},
 			wantErr: false,
@@ -76,6 +77,7 @@ func Test_runtimeHook_Run(t *testing.T) {
 					string(CPUSetAllocator): false,
 					string(GPUEnvInject):    false,
 					string(BatchResource):   false,
+					string(BlkIOReconcile):  false,
 				},
 			},
 			wantErr: false,
@@ -97,6 +99,7 @@ func Test_runtimeHook_Run(t *testing.T) {
 					string(CPUSetAllocator): true,
 					string(GPUEnvInject):    true,
 					string(BatchResource):   true,
+					string(BlkIOReconcile):  true,
 				},
 			},
 			wantErr: false,
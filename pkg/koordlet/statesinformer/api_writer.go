@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+type apiWriteKind string
+
+const (
+	apiWriteKindNodeMetric           apiWriteKind = "NodeMetric"
+	apiWriteKindDevice               apiWriteKind = "Device"
+	apiWriteKindNodeResourceTopology apiWriteKind = "NodeResourceTopology"
+)
+
+// apiWritePriorities orders the pending writes, the kind with the lower value is written first.
+var apiWritePriorities = map[apiWriteKind]int{
+	apiWriteKindNodeMetric:           0,
+	apiWriteKindDevice:               1,
+	apiWriteKindNodeResourceTopology: 2,
+}
+
+// apiWriteBackoff retries the conflicted or throttled writes with jitter, so that the koordlets on all nodes
+// do not retry at the same time.
+var apiWriteBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 100 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.5,
+}
+
+type apiWriteRequest struct {
+	kind        apiWriteKind
+	name        string
+	write       func() error
+	enqueueTime time.Time
+}
+
+// apiWriter is shared by the koordlet reporters to write the objects to the apiserver.
+// The writes are rate limited in client side and sent in the priority of the object kinds, and a write is
+// coalesced into the newer one of the same object if both arrive within the coalescing window.
+type apiWriter struct {
+	rateLimiter    flowcontrol.RateLimiter
+	coalesceWindow time.Duration
+	backoff        wait.Backoff
+	clock          clock.Clock
+
+	lock    sync.Mutex
+	pending map[string]*apiWriteRequest
+	wakeup  chan struct{}
+}
+
+func newAPIWriter(qps float32, burst int, coalesceWindow time.Duration) *apiWriter {
+	return &apiWriter{
+		rateLimiter:    flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		coalesceWindow: coalesceWindow,
+		backoff:        apiWriteBackoff,
+		clock:          clock.RealClock{},
+		pending:        map[string]*apiWriteRequest{},
+		wakeup:         make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds the write of the object to the queue. The pending write of the same object is replaced since
+// the newer one carries the latest state. If the writer is nil, the write is done synchronously.
+func (w *apiWriter) Enqueue(kind apiWriteKind, name string, write func() error) {
+	if w == nil {
+		if err := retryAPIWrite(apiWriteBackoff, write); err != nil {
+			klog.Warningf("failed to write %s %s, err: %v", kind, name, err)
+		}
+		return
+	}
+
+	w.lock.Lock()
+	key := string(kind) + "/" + name
+	if req, ok := w.pending[key]; ok {
+		// keep the enqueue time, so the frequent updates of an object cannot postpone its write forever
+		req.write = write
+		metrics.RecordAPIWriterCoalesced(string(kind))
+		klog.V(5).Infof("coalesce the pending write of %s %s", kind, name)
+	} else {
+		w.pending[key] = &apiWriteRequest{
+			kind:        kind,
+			name:        name,
+			write:       write,
+			enqueueTime: w.clock.Now(),
+		}
+		w.recordQueueDepth(kind)
+	}
+	w.lock.Unlock()
+
+	select {
+	case w.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (w *apiWriter) Run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		readyIn, ok := w.nextReadyIn()
+		if !ok || readyIn > 0 {
+			var timeout <-chan time.Time
+			if ok {
+				timeout = w.clock.After(readyIn)
+			}
+			select {
+			case <-w.wakeup:
+			case <-timeout:
+			case <-stopCh:
+				return
+			}
+			continue
+		}
+
+		// pick the request after waiting for the rate limiter, so the writes of higher priority arriving
+		// in the meantime go first
+		if err := w.rateLimiter.Wait(ctx); err != nil {
+			return
+		}
+		req := w.pop()
+		if req == nil {
+			continue
+		}
+		if err := retryAPIWrite(w.backoff, req.write); err != nil {
+			metrics.RecordAPIWriterDropped(string(req.kind))
+			klog.Warningf("failed to write %s %s, drop it, err: %v", req.kind, req.name, err)
+		}
+	}
+}
+
+// nextReadyIn returns the duration until the earliest pending write is out of the coalescing window.
+func (w *apiWriter) nextReadyIn() (time.Duration, bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.pending) == 0 {
+		return 0, false
+	}
+	var earliest time.Time
+	for _, req := range w.pending {
+		if earliest.IsZero() || req.enqueueTime.Before(earliest) {
+			earliest = req.enqueueTime
+		}
+	}
+	return earliest.Add(w.coalesceWindow).Sub(w.clock.Now()), true
+}
+
+// pop removes and returns the ready write of the highest priority, the earlier one goes first if the same.
+func (w *apiWriter) pop() *apiWriteRequest {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	now := w.clock.Now()
+	var selected *apiWriteRequest
+	var selectedKey string
+	for key, req := range w.pending {
+		if req.enqueueTime.Add(w.coalesceWindow).After(now) {
+			continue
+		}
+		if selected == nil ||
+			apiWritePriorities[req.kind] < apiWritePriorities[selected.kind] ||
+			(apiWritePriorities[req.kind] == apiWritePriorities[selected.kind] && req.enqueueTime.Before(selected.enqueueTime)) {
+			selected, selectedKey = req, key
+		}
+	}
+	if selected != nil {
+		delete(w.pending, selectedKey)
+		w.recordQueueDepth(selected.kind)
+	}
+	return selected
+}
+
+func (w *apiWriter) recordQueueDepth(kind apiWriteKind) {
+	depth := 0
+	for _, req := range w.pending {
+		if req.kind == kind {
+			depth++
+		}
+	}
+	metrics.RecordAPIWriterQueueDepth(string(kind), depth)
+}
+
+func retryAPIWrite(backoff wait.Backoff, write func() error) error {
+	return retry.OnError(backoff, func(err error) bool {
+		return errors.IsConflict(err) || errors.IsTooManyRequests(err) || errors.IsServerTimeout(err) || errors.IsTimeout(err)
+	}, write)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+)
+
+// fakeAPIRateLimiter admits a write only when a token is given.
+type fakeAPIRateLimiter struct {
+	waiting chan struct{}
+	tokens  chan struct{}
+}
+
+func newFakeAPIRateLimiter() *fakeAPIRateLimiter {
+	return &fakeAPIRateLimiter{
+		waiting: make(chan struct{}, 10),
+		tokens:  make(chan struct{}),
+	}
+}
+
+func (f *fakeAPIRateLimiter) TryAccept() bool { return false }
+func (f *fakeAPIRateLimiter) Accept()         { <-f.tokens }
+func (f *fakeAPIRateLimiter) Stop()           {}
+func (f *fakeAPIRateLimiter) QPS() float32    { return 0 }
+func (f *fakeAPIRateLimiter) Wait(ctx context.Context) error {
+	f.waiting <- struct{}{}
+	select {
+	case <-f.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type apiWriteRecorder struct {
+	lock   sync.Mutex
+	writes []string
+}
+
+func (r *apiWriteRecorder) write(name string) func() error {
+	return func() error {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.writes = append(r.writes, name)
+		return nil
+	}
+}
+
+func (r *apiWriteRecorder) get() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.writes...)
+}
+
+func newTestAPIWriter(coalesceWindow time.Duration) *apiWriter {
+	w := newAPIWriter(1, 1, coalesceWindow)
+	w.rateLimiter = flowcontrol.NewFakeAlwaysRateLimiter()
+	w.backoff = wait.Backoff{Steps: 3, Duration: time.Millisecond}
+	return w
+}
+
+func Test_apiWriter_Coalesce(t *testing.T) {
+	metrics.Register(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	defer metrics.Register(nil)
+	coalesced := metrics.APIWriterCoalesced.WithLabelValues("test-node", string(apiWriteKindDevice))
+	before := testutil.ToFloat64(coalesced)
+
+	w := newTestAPIWriter(0)
+	recorder := &apiWriteRecorder{}
+	// back-to-back identical updates of the same object
+	w.Enqueue(apiWriteKindDevice, "test-node", recorder.write("device-1"))
+	w.Enqueue(apiWriteKindDevice, "test-node", recorder.write("device-2"))
+	w.Enqueue(apiWriteKindNodeMetric, "test-node", recorder.write("nodemetric"))
+	assert.Equal(t, before+1, testutil.ToFloat64(coalesced))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	assert.Eventually(t, func() bool {
+		return len(recorder.get()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"nodemetric", "device-2"}, recorder.get())
+}
+
+func Test_apiWriter_CoalesceWindow(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	w := newTestAPIWriter(time.Second)
+	w.clock = fakeClock
+	recorder := &apiWriteRecorder{}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	w.Enqueue(apiWriteKindNodeResourceTopology, "test-node", recorder.write("topo-1"))
+	// the write is held in the coalescing window
+	assert.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, recorder.get())
+
+	fakeClock.Step(500 * time.Millisecond)
+	w.Enqueue(apiWriteKindNodeResourceTopology, "test-node", recorder.write("topo-2"))
+	assert.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, recorder.get())
+
+	// the window starts from the first enqueue, so the frequent updates cannot postpone the write forever
+	fakeClock.Step(500 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return len(recorder.get()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"topo-2"}, recorder.get())
+}
+
+func Test_apiWriter_Priority(t *testing.T) {
+	w := newTestAPIWriter(0)
+	rateLimiter := newFakeAPIRateLimiter()
+	w.rateLimiter = rateLimiter
+	recorder := &apiWriteRecorder{}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	w.Enqueue(apiWriteKindNodeResourceTopology, "test-node", recorder.write(string(apiWriteKindNodeResourceTopology)))
+	// the writer is throttled, and the writes of higher priority arrive later
+	<-rateLimiter.waiting
+	w.Enqueue(apiWriteKindDevice, "test-node", recorder.write(string(apiWriteKindDevice)))
+	w.Enqueue(apiWriteKindNodeMetric, "test-node", recorder.write(string(apiWriteKindNodeMetric)))
+
+	for i := 1; i <= 3; i++ {
+		if i > 1 {
+			<-rateLimiter.waiting
+		}
+		rateLimiter.tokens <- struct{}{}
+		assert.Eventually(t, func() bool {
+			return len(recorder.get()) == i
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	assert.Equal(t, []string{
+		string(apiWriteKindNodeMetric),
+		string(apiWriteKindDevice),
+		string(apiWriteKindNodeResourceTopology),
+	}, recorder.get())
+}
+
+func Test_apiWriter_Retry(t *testing.T) {
+	metrics.Register(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	defer metrics.Register(nil)
+	dropped := metrics.APIWriterDropped.WithLabelValues("test-node", string(apiWriteKindDevice))
+	before := testutil.ToFloat64(dropped)
+
+	w := newTestAPIWriter(0)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	conflict := errors.NewConflict(schema.GroupResource{Resource: "devices"}, "test-node", nil)
+	var lock sync.Mutex
+	attempts := 0
+	// succeed after a conflict
+	w.Enqueue(apiWriteKindDevice, "test-node", func() error {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts++
+		if attempts < 2 {
+			return conflict
+		}
+		return nil
+	})
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return attempts == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// drop after the retries are exhausted
+	w.Enqueue(apiWriteKindDevice, "test-node", func() error {
+		return conflict
+	})
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(dropped) == before+1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_apiWriter_Nil(t *testing.T) {
+	var w *apiWriter
+	recorder := &apiWriteRecorder{}
+	w.Enqueue(apiWriteKindNodeMetric, "test-node", recorder.write("nodemetric"))
+	assert.Equal(t, []string{"nodemetric"}, recorder.get())
+}
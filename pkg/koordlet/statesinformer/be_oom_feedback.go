@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"math"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	// DefaultBEOOMFeedbackWindow is the duration in which the OOM kills of the BE pods are counted.
+	DefaultBEOOMFeedbackWindow = 10 * time.Minute
+	// DefaultBEOOMFeedbackThreshold is the number of the BE OOM kills in the window to raise the safety margin.
+	DefaultBEOOMFeedbackThreshold = 3
+	// DefaultBEOOMFeedbackMarginStep is the ratio of the node memory capacity the safety margin is raised by each time.
+	DefaultBEOOMFeedbackMarginStep = 0.05
+	// DefaultBEOOMFeedbackMaxMargin is the maximum ratio of the node memory capacity of the safety margin.
+	DefaultBEOOMFeedbackMaxMargin = 0.3
+	// DefaultBEOOMFeedbackMarginHalfLife is how long the safety margin decays to half.
+	DefaultBEOOMFeedbackMarginHalfLife = 30 * time.Minute
+
+	// minBEOOMFeedbackMargin is the margin below which the decayed margin is cleared.
+	minBEOOMFeedbackMargin = 0.001
+)
+
+// kernelOOMKillSource reads the memory cgroups of the tasks OOM-killed since the last read.
+type kernelOOMKillSource interface {
+	ReadOOMKillMemcgs() ([]string, error)
+}
+
+// BEOOMFeedback concludes the batch memory of the node is too aggressive when the BE pods are OOM-killed frequently.
+// The OOM kills are observed from the `oom_kill` counts of the BE pod cgroups and the kernel log, the latter covers
+// the pods deleted before their cgroups are read. When there are at least threshold OOM kills in the window, the
+// safety margin, a ratio of the node memory capacity, is raised by the step up to the maximum. The margin decays
+// exponentially by the half-life, so the batch memory recovers when the OOM kills stop.
+type BEOOMFeedback struct {
+	lock       sync.Mutex
+	clock      clock.Clock
+	window     time.Duration
+	threshold  int
+	marginStep float64
+	maxMargin  float64
+	halfLife   time.Duration
+
+	cgroupReader resourceexecutor.CgroupReader
+	kernelLog    kernelOOMKillSource
+
+	// podOOMKills are the `oom_kill` counts of the BE pod cgroups read last time, by the pod UID.
+	podOOMKills map[types.UID]int64
+	// killTimes are the times the OOM kills are observed in the window since the margin was raised last time.
+	killTimes  []time.Time
+	margin     float64
+	updateTime time.Time
+}
+
+func NewBEOOMFeedback(window time.Duration, threshold int, marginStep, maxMargin float64, halfLife time.Duration,
+	cgroupReader resourceexecutor.CgroupReader, kernelLog kernelOOMKillSource, clock clock.Clock) *BEOOMFeedback {
+	return &BEOOMFeedback{
+		clock:        clock,
+		window:       window,
+		threshold:    threshold,
+		marginStep:   marginStep,
+		maxMargin:    maxMargin,
+		halfLife:     halfLife,
+		cgroupReader: cgroupReader,
+		kernelLog:    kernelLog,
+		podOOMKills:  map[types.UID]int64{},
+	}
+}
+
+func NewDefaultBEOOMFeedback() *BEOOMFeedback {
+	return NewBEOOMFeedback(DefaultBEOOMFeedbackWindow, DefaultBEOOMFeedbackThreshold, DefaultBEOOMFeedbackMarginStep,
+		DefaultBEOOMFeedbackMaxMargin, DefaultBEOOMFeedbackMarginHalfLife, resourceexecutor.NewCgroupReader(),
+		sysutil.NewKernelOOMKillReader(sysutil.KmsgFilePath), clock.RealClock{})
+}
+
+// Update observes the OOM kills of the BE pods since the last update, adjusts the safety margin and returns it.
+// The `oom_kill` count observed first of a pod is taken as the baseline.
+func (f *BEOOMFeedback) Update(podsMeta []*PodMeta) float64 {
+	if f == nil {
+		return 0
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	now := f.clock.Now()
+
+	if !f.updateTime.IsZero() && f.margin > 0 {
+		f.margin *= math.Pow(0.5, float64(now.Sub(f.updateTime))/float64(f.halfLife))
+		if f.margin < minBEOOMFeedbackMargin {
+			f.margin = 0
+		}
+	}
+	f.updateTime = now
+
+	for kills := f.observeOOMKills(podsMeta); kills > 0; kills-- {
+		f.killTimes = append(f.killTimes, now)
+	}
+	for len(f.killTimes) > 0 && now.Sub(f.killTimes[0]) > f.window {
+		f.killTimes = f.killTimes[1:]
+	}
+	if len(f.killTimes) >= f.threshold {
+		f.margin = math.Min(f.margin+f.marginStep, f.maxMargin)
+		klog.V(4).Infof("raise the BE memory safety margin to %v for %v BE OOM kills in %v", f.margin, len(f.killTimes), f.window)
+		f.killTimes = nil
+	}
+	return f.margin
+}
+
+// Margin returns the safety margin as the ratio of the node memory capacity.
+func (f *BEOOMFeedback) Margin() float64 {
+	if f == nil {
+		return 0
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.margin
+}
+
+// beOOMPodCgroup is the pod a cgroup belongs to.
+type beOOMPodCgroup struct {
+	uid  types.UID
+	isBE bool
+}
+
+// observeOOMKills returns the number of the OOM kills of the BE pods since the last observation. An OOM kill of a
+// running pod is both counted in the cgroup and logged by the kernel, so the larger count of the two is taken.
+func (f *BEOOMFeedback) observeOOMKills(podsMeta []*PodMeta) int64 {
+	podCgroups := make(map[string]beOOMPodCgroup, len(podsMeta))
+	cgroupKills := map[types.UID]int64{}
+	existing := make(map[types.UID]struct{}, len(f.podOOMKills))
+	for _, podMeta := range podsMeta {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		pod := podMeta.Pod
+		isBE := apiext.GetPodQoSClass(pod) == apiext.QoSBE
+		if cgroupDir := strings.Trim(podMeta.CgroupDir, "/"); cgroupDir != "" {
+			podCgroups[cgroupDir] = beOOMPodCgroup{uid: pod.UID, isBE: isBE}
+		}
+		if !isBE {
+			continue
+		}
+		count, err := f.cgroupReader.ReadMemoryOOMKillCount(podMeta.CgroupDir)
+		if err != nil {
+			klog.V(5).Infof("failed to read the oom kill count of pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		existing[pod.UID] = struct{}{}
+		if last, ok := f.podOOMKills[pod.UID]; ok && count > last {
+			cgroupKills[pod.UID] = count - last
+		}
+		f.podOOMKills[pod.UID] = count
+	}
+	for uid := range f.podOOMKills {
+		if _, ok := existing[uid]; !ok {
+			delete(f.podOOMKills, uid)
+		}
+	}
+
+	var memcgs []string
+	if f.kernelLog != nil {
+		var err error
+		if memcgs, err = f.kernelLog.ReadOOMKillMemcgs(); err != nil {
+			klog.V(4).Infof("failed to read the oom kills from the kernel log, err: %v", err)
+		}
+	}
+	kernelKills := map[types.UID]int64{}
+	var deletedPodKills int64
+	for _, memcg := range memcgs {
+		podCgroup, ok := matchPodCgroup(podCgroups, memcg)
+		if !ok {
+			// the pod is deleted, judge its QoS by the cgroup
+			if strings.Contains(memcg, "besteffort") {
+				deletedPodKills++
+			}
+			continue
+		}
+		if podCgroup.isBE {
+			kernelKills[podCgroup.uid]++
+		}
+	}
+
+	var totalCgroupKills, totalKernelKills int64
+	kills := deletedPodKills
+	for _, podCgroup := range podCgroups {
+		if !podCgroup.isBE {
+			continue
+		}
+		totalCgroupKills += cgroupKills[podCgroup.uid]
+		totalKernelKills += kernelKills[podCgroup.uid]
+		kills += util.MaxInt64(cgroupKills[podCgroup.uid], kernelKills[podCgroup.uid])
+	}
+	if totalCgroupKills > 0 {
+		metrics.RecordBEOOMKills(metrics.OOMKillSourceCgroup, totalCgroupKills)
+	}
+	if totalKernelKills+deletedPodKills > 0 {
+		metrics.RecordBEOOMKills(metrics.OOMKillSourceKernel, totalKernelKills+deletedPodKills)
+	}
+	return kills
+}
+
+// matchPodCgroup finds the pod of the memory cgroup which is the pod cgroup or a container cgroup of the pod.
+func matchPodCgroup(podCgroups map[string]beOOMPodCgroup, memcg string) (beOOMPodCgroup, bool) {
+	for dir := strings.Trim(memcg, "/"); dir != "" && dir != "."; dir = path.Dir(dir) {
+		if podCgroup, ok := podCgroups[dir]; ok {
+			return podCgroup, true
+		}
+	}
+	return beOOMPodCgroup{}, false
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+)
+
+type fakeOOMKillCgroupReader struct {
+	resourceexecutor.CgroupReader
+	oomKills map[string]int64
+}
+
+func (r *fakeOOMKillCgroupReader) ReadMemoryOOMKillCount(parentDir string) (int64, error) {
+	return r.oomKills[parentDir], nil
+}
+
+type fakeKernelOOMKillSource struct {
+	memcgs []string
+}
+
+func (s *fakeKernelOOMKillSource) ReadOOMKillMemcgs() ([]string, error) {
+	memcgs := s.memcgs
+	s.memcgs = nil
+	return memcgs, nil
+}
+
+func newTestOOMPodMeta(name string, qos apiext.QoSClass, cgroupDir string) *PodMeta {
+	return &PodMeta{
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID("uid-" + name),
+				Labels:    map[string]string{apiext.LabelPodQoS: string(qos)},
+			},
+		},
+		CgroupDir: cgroupDir,
+	}
+}
+
+func TestBEOOMFeedback(t *testing.T) {
+	bePod := newTestOOMPodMeta("be-1", apiext.QoSBE, "kubepods/besteffort/pod-be-1")
+	lsPod := newTestOOMPodMeta("ls-1", apiext.QoSLS, "kubepods/burstable/pod-ls-1")
+	halfLife := 30 * time.Minute
+	decayed := func(margin float64, elapsed time.Duration) float64 {
+		return margin * math.Pow(0.5, float64(elapsed)/float64(halfLife))
+	}
+	type step struct {
+		elapsed      time.Duration
+		cgroupKills  map[string]int64
+		kernelMemcgs []string
+		pods         []*PodMeta
+		wantMargin   float64
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "raise the margin when the BE OOM kills reach the threshold",
+			steps: []step{
+				// the count observed first is the baseline
+				{cgroupKills: map[string]int64{bePod.CgroupDir: 5}},
+				// the kills counted in the cgroup and logged by the kernel are not double-counted, the LS kills are ignored
+				{
+					elapsed:      time.Minute,
+					cgroupKills:  map[string]int64{bePod.CgroupDir: 7},
+					kernelMemcgs: []string{"/kubepods/besteffort/pod-be-1/c1", "/kubepods/besteffort/pod-be-1/c1", "/kubepods/burstable/pod-ls-1/c2"},
+				},
+				// the kill of the deleted BE pod is counted by the kernel log
+				{
+					elapsed:      time.Minute,
+					cgroupKills:  map[string]int64{bePod.CgroupDir: 7},
+					kernelMemcgs: []string{"/kubepods/besteffort/pod-deleted/c3"},
+					wantMargin:   0.05,
+				},
+				// the margin decays without the kills
+				{
+					elapsed:     10 * time.Minute,
+					cgroupKills: map[string]int64{bePod.CgroupDir: 7},
+					wantMargin:  decayed(0.05, 10*time.Minute),
+				},
+				// the margin is raised again but capped
+				{
+					elapsed:     time.Minute,
+					cgroupKills: map[string]int64{bePod.CgroupDir: 10},
+					wantMargin:  decayed(0.05, 11*time.Minute) + 0.05,
+				},
+				{
+					elapsed:     time.Minute,
+					cgroupKills: map[string]int64{bePod.CgroupDir: 13},
+					wantMargin:  0.1,
+				},
+				// the margin is cleared after decaying enough
+				{
+					elapsed:     10 * time.Hour,
+					cgroupKills: map[string]int64{bePod.CgroupDir: 13},
+					wantMargin:  0,
+				},
+			},
+		},
+		{
+			name: "the kills out of the window are not counted",
+			steps: []step{
+				{cgroupKills: map[string]int64{bePod.CgroupDir: 0}},
+				{elapsed: time.Minute, cgroupKills: map[string]int64{bePod.CgroupDir: 2}},
+				{elapsed: 11 * time.Minute, cgroupKills: map[string]int64{bePod.CgroupDir: 3}},
+				{elapsed: time.Minute, cgroupKills: map[string]int64{bePod.CgroupDir: 4}},
+				{elapsed: time.Minute, cgroupKills: map[string]int64{bePod.CgroupDir: 5}, wantMargin: 0.05},
+			},
+		},
+		{
+			name: "the pod recreated takes the count as the baseline again",
+			steps: []step{
+				{cgroupKills: map[string]int64{bePod.CgroupDir: 10}},
+				{elapsed: time.Minute, pods: []*PodMeta{lsPod}},
+				{elapsed: time.Minute, cgroupKills: map[string]int64{bePod.CgroupDir: 12}},
+				{elapsed: time.Minute, cgroupKills: map[string]int64{bePod.CgroupDir: 13}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(time.Now())
+			cgroupReader := &fakeOOMKillCgroupReader{}
+			kernelLog := &fakeKernelOOMKillSource{}
+			f := NewBEOOMFeedback(10*time.Minute, 3, 0.05, 0.1, halfLife, cgroupReader, kernelLog, fakeClock)
+			for i, s := range tt.steps {
+				fakeClock.Step(s.elapsed)
+				cgroupReader.oomKills = s.cgroupKills
+				kernelLog.memcgs = s.kernelMemcgs
+				pods := s.pods
+				if pods == nil {
+					pods = []*PodMeta{bePod, lsPod}
+				}
+				assert.InDelta(t, s.wantMargin, f.Update(pods), 1e-9, "step %d", i)
+				assert.InDelta(t, s.wantMargin, f.Margin(), 1e-9, "step %d", i)
+			}
+		})
+	}
+}
+
+func Test_nodeMetricInformer_getBEMemorySafetyMargin(t *testing.T) {
+	bePod := newTestOOMPodMeta("be-1", apiext.QoSBE, "kubepods/besteffort/pod-be-1")
+	cgroupReader := &fakeOOMKillCgroupReader{oomKills: map[string]int64{bePod.CgroupDir: 0}}
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := &nodeMetricInformer{
+		nodeInformer: &nodeInformer{
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Status: corev1.NodeStatus{
+					Capacity: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("100Gi")},
+				},
+			},
+		},
+		oomFeedback: NewBEOOMFeedback(10*time.Minute, 2, 0.05, 0.5, 30*time.Minute, cgroupReader, nil, fakeClock),
+	}
+	// no margin without the OOM kills
+	assert.Nil(t, r.getBEMemorySafetyMargin([]*PodMeta{bePod}))
+
+	// the margin is reported in bytes of the node memory capacity
+	fakeClock.Step(time.Minute)
+	cgroupReader.oomKills[bePod.CgroupDir] = 2
+	got := r.getBEMemorySafetyMargin([]*PodMeta{bePod})
+	assert.NotNil(t, got)
+	assert.True(t, resource.MustParse("5Gi").Equal(*got))
+
+	// node not ready
+	assert.Nil(t, (&nodeMetricInformer{nodeInformer: &nodeInformer{}, oomFeedback: r.oomFeedback}).getBEMemorySafetyMargin([]*PodMeta{bePod}))
+
+	// disabled
+	assert.Nil(t, (&nodeMetricInformer{}).getBEMemorySafetyMargin([]*PodMeta{bePod}))
+}
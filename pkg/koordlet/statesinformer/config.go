@@ -21,6 +21,8 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 )
 
 type Config struct {
@@ -33,6 +35,9 @@ type Config struct {
 	DisableQueryKubeletConfig   bool
 	EnableNodeMetricReport      bool
 	MetricReportInterval        time.Duration // Deprecated
+	APIWriterQPS                float64
+	APIWriterBurst              int
+	APIWriterCoalesceWindow     time.Duration
 }
 
 func NewDefaultConfig() *Config {
@@ -45,6 +50,9 @@ func NewDefaultConfig() *Config {
 		NodeTopologySyncInterval:    3 * time.Second,
 		DisableQueryKubeletConfig:   false,
 		EnableNodeMetricReport:      true,
+		APIWriterQPS:                5,
+		APIWriterBurst:              10,
+		APIWriterCoalesceWindow:     time.Second,
 	}
 }
 
@@ -58,4 +66,12 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.DisableQueryKubeletConfig, "disable-query-kubelet-config", c.DisableQueryKubeletConfig, "Disables querying the kubelet configuration from kubelet. Flag must be set to true if kubelet-insecure-tls=true is configured")
 	fs.DurationVar(&c.MetricReportInterval, "report-interval", c.MetricReportInterval, "Deprecated since v1.1, use ColocationStrategy.MetricReportIntervalSeconds in config map of slo-controller")
 	fs.BoolVar(&c.EnableNodeMetricReport, "enable-node-metric-report", c.EnableNodeMetricReport, "Enable status update of node metric crd.")
+	fs.Float64Var(&c.APIWriterQPS, "api-writer-qps", c.APIWriterQPS, "The QPS of the writes to the apiserver shared by the reporters of NodeMetric, Device and NodeResourceTopology.")
+	fs.IntVar(&c.APIWriterBurst, "api-writer-burst", c.APIWriterBurst, "The burst of the writes to the apiserver shared by the reporters of NodeMetric, Device and NodeResourceTopology.")
+	fs.DurationVar(&c.APIWriterCoalesceWindow, "api-writer-coalesce-window", c.APIWriterCoalesceWindow, "The window in which the writes of the same object are coalesced into the latest one. Non-zero values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+}
+
+// nodeTopologySyncInterval returns the interval of the effective profile, so the switched profile takes effect.
+func (c *Config) nodeTopologySyncInterval() time.Duration {
+	return profile.Duration(func(p *profile.Preset) time.Duration { return p.NodeTopologySyncInterval }, c.NodeTopologySyncInterval)
 }
@@ -42,6 +42,9 @@ func TestNewDefaultConfig(t *testing.T) {
 				DisableQueryKubeletConfig:   false,
 				EnableNodeMetricReport:      true,
 				MetricReportInterval:        0,
+				APIWriterQPS:                5,
+				APIWriterBurst:              10,
+				APIWriterCoalesceWindow:     time.Second,
 			},
 		},
 	}
@@ -64,6 +67,9 @@ func TestConfig_InitFlags(t *testing.T) {
 		"--node-topology-sync-interval=10s",
 		"--disable-query-kubelet-config=true",
 		"--enable-node-metric-report=false",
+		"--api-writer-qps=2",
+		"--api-writer-burst=4",
+		"--api-writer-coalesce-window=3s",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
@@ -76,6 +82,9 @@ func TestConfig_InitFlags(t *testing.T) {
 		NodeTopologySyncInterval    time.Duration
 		DisableQueryKubeletConfig   bool
 		EnableNodeMetricReport      bool
+		APIWriterQPS                float64
+		APIWriterBurst              int
+		APIWriterCoalesceWindow     time.Duration
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -96,6 +105,9 @@ func TestConfig_InitFlags(t *testing.T) {
 				NodeTopologySyncInterval:    10 * time.Second,
 				DisableQueryKubeletConfig:   true,
 				EnableNodeMetricReport:      false,
+				APIWriterQPS:                2,
+				APIWriterBurst:              4,
+				APIWriterCoalesceWindow:     3 * time.Second,
 			},
 			args: args{fs: fs},
 		},
@@ -111,6 +123,9 @@ func TestConfig_InitFlags(t *testing.T) {
 				NodeTopologySyncInterval:    tt.fields.NodeTopologySyncInterval,
 				DisableQueryKubeletConfig:   tt.fields.DisableQueryKubeletConfig,
 				EnableNodeMetricReport:      tt.fields.EnableNodeMetricReport,
+				APIWriterQPS:                tt.fields.APIWriterQPS,
+				APIWriterBurst:              tt.fields.APIWriterBurst,
+				APIWriterCoalesceWindow:     tt.fields.APIWriterCoalesceWindow,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
@@ -67,6 +67,20 @@ func (mr *MockStatesInformerMockRecorder) GetAllPods() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPods", reflect.TypeOf((*MockStatesInformer)(nil).GetAllPods))
 }
 
+// GetGhostPods mocks base method.
+func (m *MockStatesInformer) GetGhostPods() []*statesinformer.PodMeta {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGhostPods")
+	ret0, _ := ret[0].([]*statesinformer.PodMeta)
+	return ret0
+}
+
+// GetGhostPods indicates an expected call of GetGhostPods.
+func (mr *MockStatesInformerMockRecorder) GetGhostPods() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGhostPods", reflect.TypeOf((*MockStatesInformer)(nil).GetGhostPods))
+}
+
 // GetNode mocks base method.
 func (m *MockStatesInformer) GetNode() *v1.Node {
 	m.ctrl.T.Helper()
@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	// DefaultRestartStormWindow is the duration in which the restarts of a container are counted.
+	DefaultRestartStormWindow = 5 * time.Minute
+	// DefaultRestartStormThreshold is the number of restarts in the window to consider a container in restart storm.
+	DefaultRestartStormThreshold = 3
+	// DefaultRestartStormStabilizationPeriod is how long a container should stay up to end its restart storm.
+	DefaultRestartStormStabilizationPeriod = 2 * time.Minute
+)
+
+// RestartStormDetector detects the containers restarting too frequently, e.g. the crash-looping containers,
+// according to the restart counts observed in the pod statuses. A container is in restart storm if it restarted
+// at least threshold times in the window, until it stays up for the stabilization period.
+type RestartStormDetector struct {
+	lock                sync.Mutex
+	clock               clock.Clock
+	window              time.Duration
+	threshold           int
+	stabilizationPeriod time.Duration
+	// containers maps the pod UID and the container name to its restart record.
+	containers map[containerKey]*containerRestartRecord
+}
+
+type containerKey struct {
+	podUID        types.UID
+	containerName string
+}
+
+type containerRestartRecord struct {
+	restartCount int32
+	// restartTimes are the times the restarts are observed in the window before the last restart.
+	restartTimes []time.Time
+}
+
+func NewRestartStormDetector(window time.Duration, threshold int, stabilizationPeriod time.Duration, clock clock.Clock) *RestartStormDetector {
+	return &RestartStormDetector{
+		clock:               clock,
+		window:              window,
+		threshold:           threshold,
+		stabilizationPeriod: stabilizationPeriod,
+		containers:          map[containerKey]*containerRestartRecord{},
+	}
+}
+
+func NewDefaultRestartStormDetector() *RestartStormDetector {
+	return NewRestartStormDetector(DefaultRestartStormWindow, DefaultRestartStormThreshold,
+		DefaultRestartStormStabilizationPeriod, clock.RealClock{})
+}
+
+// Update observes the restart counts of the containers, and forgets the containers no longer existing.
+// The restart count observed first of a container is taken as the baseline.
+func (d *RestartStormDetector) Update(podsMeta []*PodMeta) {
+	if d == nil {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	now := d.clock.Now()
+
+	existing := make(map[containerKey]struct{}, len(d.containers))
+	for _, podMeta := range podsMeta {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		for i := range podMeta.Pod.Status.ContainerStatuses {
+			status := &podMeta.Pod.Status.ContainerStatuses[i]
+			key := containerKey{podUID: podMeta.Pod.UID, containerName: status.Name}
+			existing[key] = struct{}{}
+			record, ok := d.containers[key]
+			if !ok {
+				d.containers[key] = &containerRestartRecord{restartCount: status.RestartCount}
+				continue
+			}
+			for restarts := status.RestartCount - record.restartCount; restarts > 0; restarts-- {
+				record.restartTimes = append(record.restartTimes, now)
+				if len(record.restartTimes) > d.threshold {
+					record.restartTimes = record.restartTimes[1:]
+				}
+			}
+			record.restartCount = status.RestartCount
+			for len(record.restartTimes) > 0 && now.Sub(record.restartTimes[0]) > d.window {
+				record.restartTimes = record.restartTimes[1:]
+			}
+		}
+	}
+	for key := range d.containers {
+		if _, ok := existing[key]; !ok {
+			delete(d.containers, key)
+		}
+	}
+}
+
+// InStorm checks if the container of the pod is in restart storm.
+func (d *RestartStormDetector) InStorm(pod *corev1.Pod, containerName string) bool {
+	if d == nil || pod == nil {
+		return false
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	record, ok := d.containers[containerKey{podUID: pod.UID, containerName: containerName}]
+	if !ok || len(record.restartTimes) < d.threshold {
+		return false
+	}
+	lastRestart := record.restartTimes[len(record.restartTimes)-1]
+	return d.clock.Since(lastRestart) < d.stabilizationPeriod
+}
+
+// StabilizationPeriod returns how long a container should stay up to end its restart storm.
+func (d *RestartStormDetector) StabilizationPeriod() time.Duration {
+	return d.stabilizationPeriod
+}
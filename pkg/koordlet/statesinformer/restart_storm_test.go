@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func newRestartStormTestPodMeta(restartCount int32) *PodMeta {
+	return &PodMeta{
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod", UID: "test-pod-uid"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "test-container", RestartCount: restartCount},
+				},
+			},
+		},
+	}
+}
+
+func TestRestartStormDetector(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	d := NewRestartStormDetector(5*time.Minute, 3, 2*time.Minute, fakeClock)
+	pod := newRestartStormTestPodMeta(0).Pod
+
+	// the restart count observed first is the baseline
+	d.Update([]*PodMeta{newRestartStormTestPodMeta(10)})
+	assert.False(t, d.InStorm(pod, "test-container"))
+
+	// the restarts spread out of the window are not a storm
+	for i := int32(11); i <= 13; i++ {
+		fakeClock.Step(3 * time.Minute)
+		d.Update([]*PodMeta{newRestartStormTestPodMeta(i)})
+		assert.False(t, d.InStorm(pod, "test-container"), "restart count %d", i)
+	}
+
+	// 3 restarts in the window
+	fakeClock.Step(time.Minute)
+	d.Update([]*PodMeta{newRestartStormTestPodMeta(15)})
+	assert.True(t, d.InStorm(pod, "test-container"))
+	assert.False(t, d.InStorm(pod, "other-container"))
+
+	// the storm ends after the container stays up for the stabilization period
+	fakeClock.Step(time.Minute)
+	d.Update([]*PodMeta{newRestartStormTestPodMeta(15)})
+	assert.True(t, d.InStorm(pod, "test-container"))
+	fakeClock.Step(time.Minute)
+	assert.False(t, d.InStorm(pod, "test-container"))
+
+	// the deleted containers are forgotten
+	d.Update(nil)
+	assert.Empty(t, d.containers)
+
+	var nilDetector *RestartStormDetector
+	nilDetector.Update([]*PodMeta{newRestartStormTestPodMeta(1)})
+	assert.False(t, nilDetector.InStorm(pod, "test-container"))
+}
@@ -18,6 +18,7 @@ package statesinformer
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"sort"
 	"strings"
@@ -34,7 +35,6 @@ import (
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
-	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 func generateQueryParam() *metriccache.QueryParam {
@@ -59,22 +59,25 @@ func (s *statesInformer) reportDevice() {
 	device := s.buildBasicDevice(node)
 	s.fillGPUDevice(device, gpuDevices, gpuModel, gpuDriverVer)
 
-	err := s.updateDevice(device)
-	if err == nil {
-		klog.V(4).Infof("successfully update Device %s", node.Name)
-		return
-	}
-	if !errors.IsNotFound(err) {
-		klog.Errorf("Failed to updateDevice %s, err: %v", node.Name, err)
-		return
-	}
+	s.states.apiWriter.Enqueue(apiWriteKindDevice, node.Name, func() error {
+		err := s.updateDevice(device)
+		if err == nil {
+			klog.V(4).Infof("successfully update Device %s", node.Name)
+			return nil
+		}
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to updateDevice %s, err: %v", node.Name, err)
+			return err
+		}
 
-	err = s.createDevice(device)
-	if err == nil {
-		klog.V(4).Infof("successfully create Device %s", node.Name)
-	} else {
-		klog.Errorf("Failed to create Device %s, err: %v", node.Name, err)
-	}
+		err = s.createDevice(device)
+		if err == nil {
+			klog.V(4).Infof("successfully create Device %s", node.Name)
+		} else {
+			klog.Errorf("Failed to create Device %s, err: %v", node.Name, err)
+		}
+		return err
+	})
 }
 
 func (s *statesInformer) buildBasicDevice(node *corev1.Node) *schedulingv1alpha1.Device {
@@ -126,22 +129,21 @@ func (s *statesInformer) updateDevice(deviceNew *schedulingv1alpha1.Device) erro
 	}
 	sorter(deviceNew.Spec.Devices)
 
-	return util.RetryOnConflictOrTooManyRequests(func() error {
-		deviceOld, err := s.deviceClient.Get(context.TODO(), deviceNew.Name, metav1.GetOptions{ResourceVersion: "0"})
-		if err != nil {
-			return err
-		}
-		sorter(deviceOld.Spec.Devices)
+	// the conflicts are retried by the api writer
+	deviceOld, err := s.deviceClient.Get(context.TODO(), deviceNew.Name, metav1.GetOptions{ResourceVersion: "0"})
+	if err != nil {
+		return err
+	}
+	sorter(deviceOld.Spec.Devices)
 
-		if apiequality.Semantic.DeepEqual(deviceNew.Spec.Devices, deviceOld.Spec.Devices) &&
-			apiequality.Semantic.DeepEqual(deviceNew.Labels, deviceOld.Labels) {
-			klog.V(4).Infof("Device %s has not changed and does not need to be updated", deviceNew.Name)
-			return nil
-		}
+	if apiequality.Semantic.DeepEqual(deviceNew.Spec.Devices, deviceOld.Spec.Devices) &&
+		apiequality.Semantic.DeepEqual(deviceNew.Labels, deviceOld.Labels) {
+		klog.V(4).Infof("Device %s has not changed and does not need to be updated", deviceNew.Name)
+		return nil
+	}
 
-		_, err = s.deviceClient.Update(context.TODO(), deviceNew, metav1.UpdateOptions{})
-		return err
-	})
+	_, err = s.deviceClient.Update(context.TODO(), deviceNew, metav1.UpdateOptions{})
+	return err
 }
 
 func (s *statesInformer) buildGPUDevice() []schedulingv1alpha1.DeviceInfo {
@@ -164,11 +166,21 @@ func (s *statesInformer) buildGPUDevice() []schedulingv1alpha1.DeviceInfo {
 			health = false
 		}
 		s.gpuMutex.RUnlock()
+		var computeCapability string
+		if s.getGPUComputeCapabilityFunc != nil {
+			computeCapability = s.getGPUComputeCapabilityFunc(gpu.DeviceUUID)
+		}
+		var model string
+		if s.getGPUModelFunc != nil {
+			model = s.getGPUModelFunc(gpu.DeviceUUID)
+		}
 		deviceInfos = append(deviceInfos, schedulingv1alpha1.DeviceInfo{
-			UUID:   gpu.DeviceUUID,
-			Minor:  &gpu.Minor,
-			Type:   schedulingv1alpha1.GPU,
-			Health: health,
+			UUID:              gpu.DeviceUUID,
+			Minor:             &gpu.Minor,
+			Type:              schedulingv1alpha1.GPU,
+			Health:            health,
+			ComputeCapability: computeCapability,
+			Model:             model,
 			Resources: map[corev1.ResourceName]resource.Quantity{
 				extension.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
 				extension.GPUMemory:      gpu.MemoryTotal,
@@ -227,6 +239,19 @@ func (s *statesInformer) getGPUDriverAndModel() (string, string) {
 		}
 	}
 
+	transModel := normalizeGPUModel(model)
+
+	driverVersion, ret := nvml.SystemGetDriverVersion()
+	if ret != nvml.SUCCESS {
+		klog.Errorf("unable to get device driver version: %v", nvml.ErrorString(ret))
+		return "", ""
+	}
+
+	return transModel, driverVersion
+}
+
+// normalizeGPUModel returns the GPU model reported by NVML without the vendor prefix and the spaces.
+func normalizeGPUModel(model string) string {
 	// NVIDIA Driver 470 report GPU Model with "NVIDIA " Prefix
 	model = strings.TrimPrefix(model, "NVIDIA ")
 
@@ -238,15 +263,38 @@ func (s *statesInformer) getGPUDriverAndModel() (string, string) {
 	// Tesla M40 -> Tesla-M40
 	// GeForce RTX 2080 Ti -> GeForce-RTX-2080-Ti
 	// GeForce GTX 1080 Ti -> GeForce-GTX-1080-Ti
-	transModel := strings.ReplaceAll(model, " ", "-")
+	return strings.ReplaceAll(model, " ", "-")
+}
 
-	driverVersion, ret := nvml.SystemGetDriverVersion()
+// getGPUModel returns the normalized model of the GPU, e.g. "A100-SXM4-80GB", or empty if unknown.
+func (s *statesInformer) getGPUModel(uuid string) string {
+	gpuDevice, ret := nvml.DeviceGetHandleByUUID(uuid)
 	if ret != nvml.SUCCESS {
-		klog.Errorf("unable to get device driver version: %v", nvml.ErrorString(ret))
-		return "", ""
+		klog.Errorf("unable to get device %s: %v", uuid, nvml.ErrorString(ret))
+		return ""
 	}
+	model, ret := gpuDevice.GetName()
+	if ret != nvml.SUCCESS {
+		klog.Errorf("unable to get device %s model: %v", uuid, nvml.ErrorString(ret))
+		return ""
+	}
+	return normalizeGPUModel(model)
+}
 
-	return transModel, driverVersion
+// getGPUComputeCapability returns the compute capability of the GPU in the form of "<major>.<minor>",
+// or empty if unknown.
+func (s *statesInformer) getGPUComputeCapability(uuid string) string {
+	gpuDevice, ret := nvml.DeviceGetHandleByUUID(uuid)
+	if ret != nvml.SUCCESS {
+		klog.Errorf("unable to get device %s: %v", uuid, nvml.ErrorString(ret))
+		return ""
+	}
+	major, minor, ret := gpuDevice.GetCudaComputeCapability()
+	if ret != nvml.SUCCESS {
+		klog.Errorf("unable to get device %s compute capability: %v", uuid, nvml.ErrorString(ret))
+		return ""
+	}
+	return fmt.Sprintf("%d.%d", major, minor)
 }
 
 func (s *statesInformer) gpuHealCheck(stopCh <-chan struct{}) {
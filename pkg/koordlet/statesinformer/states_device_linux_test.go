@@ -77,14 +77,28 @@ func Test_reportGPUDevice(t *testing.T) {
 		getGPUDriverAndModelFunc: func() (string, string) {
 			return "A100", "470"
 		},
+		getGPUComputeCapabilityFunc: func(uuid string) string {
+			if uuid == "1" {
+				return "8.0"
+			}
+			return ""
+		},
+		getGPUModelFunc: func(uuid string) string {
+			if uuid == "1" {
+				return "A100-SXM4-80GB"
+			}
+			return ""
+		},
 	}
 	r.reportDevice()
 	expectedDevices := []schedulingv1alpha1.DeviceInfo{
 		{
-			UUID:   "1",
-			Minor:  pointer.Int32Ptr(0),
-			Type:   schedulingv1alpha1.GPU,
-			Health: true,
+			UUID:              "1",
+			Minor:             pointer.Int32Ptr(0),
+			Type:              schedulingv1alpha1.GPU,
+			Health:            true,
+			ComputeCapability: "8.0",
+			Model:             "A100-SXM4-80GB",
 			Resources: map[corev1.ResourceName]resource.Quantity{
 				extension.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
 				extension.GPUMemory:      *resource.NewQuantity(8000, resource.BinarySI),
@@ -34,3 +34,11 @@ func (s *statesInformer) gpuHealCheck(stopCh <-chan struct{}) {
 func (s *statesInformer) getGPUDriverAndModel() (string, string) {
 	return "", ""
 }
+
+func (s *statesInformer) getGPUComputeCapability(uuid string) string {
+	return ""
+}
+
+func (s *statesInformer) getGPUModel(uuid string) string {
+	return ""
+}
@@ -26,7 +26,6 @@ import (
 	"go.uber.org/atomic"
 	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -36,6 +35,7 @@ import (
 	schedv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 )
 
 const (
@@ -51,6 +51,8 @@ type StatesInformer interface {
 	GetNodeSLO() *slov1alpha1.NodeSLO
 
 	GetAllPods() []*PodMeta
+	// GetGhostPods returns the pods removed from kubelet whose cgroups still exist.
+	GetGhostPods() []*PodMeta
 
 	GetNodeTopo() *topov1alpha1.NodeResourceTopology
 
@@ -71,9 +73,12 @@ type pluginState struct {
 	metricCache     metriccache.MetricCache
 	callbackRunner  *callbackRunner
 	informerPlugins map[pluginName]informerPlugin
+	apiWriter       *apiWriter
 }
 
 type GetGPUDriverAndModelFunc func() (string, string)
+type GetGPUComputeCapabilityFunc func(uuid string) string
+type GetGPUModelFunc func(uuid string) string
 
 type statesInformer struct {
 	// TODO refactor device as plugin
@@ -87,7 +92,9 @@ type statesInformer struct {
 	states  *pluginState
 	started *atomic.Bool
 
-	getGPUDriverAndModelFunc GetGPUDriverAndModelFunc
+	getGPUDriverAndModelFunc    GetGPUDriverAndModelFunc
+	getGPUComputeCapabilityFunc GetGPUComputeCapabilityFunc
+	getGPUModelFunc             GetGPUModelFunc
 }
 
 type informerPlugin interface {
@@ -109,6 +116,7 @@ func NewStatesInformer(config *Config, kubeClient clientset.Interface, crdClient
 		metricCache:     metricsCache,
 		informerPlugins: map[pluginName]informerPlugin{},
 		callbackRunner:  NewCallbackRunner(),
+		apiWriter:       newAPIWriter(float32(config.APIWriterQPS), config.APIWriterBurst, config.APIWriterCoalesceWindow),
 	}
 	s := &statesInformer{
 		config:       config,
@@ -121,6 +129,8 @@ func NewStatesInformer(config *Config, kubeClient clientset.Interface, crdClient
 		started: atomic.NewBool(false),
 	}
 	s.getGPUDriverAndModelFunc = s.getGPUDriverAndModel
+	s.getGPUComputeCapabilityFunc = s.getGPUComputeCapability
+	s.getGPUModelFunc = s.getGPUModel
 	s.initInformerPlugins()
 	return s
 }
@@ -140,6 +150,9 @@ func (s *statesInformer) Run(stopCh <-chan struct{}) error {
 	s.states.callbackRunner.Setup(s)
 	go s.states.callbackRunner.Start(stopCh)
 
+	klog.V(2).Infof("starting api writer")
+	go s.states.apiWriter.Run(stopCh)
+
 	klog.V(2).Infof("starting informer plugins")
 	s.setupPlugins()
 	s.startPlugins(stopCh)
@@ -152,7 +165,7 @@ func (s *statesInformer) Run(stopCh <-chan struct{}) error {
 	}
 
 	if features.DefaultKoordletFeatureGate.Enabled(features.Accelerators) {
-		go wait.Until(s.reportDevice, s.config.NodeTopologySyncInterval, stopCh)
+		go profile.Until(s.reportDevice, s.config.nodeTopologySyncInterval, stopCh)
 		// check is nvml is available
 		if s.initGPU() {
 			go s.gpuHealCheck(stopCh)
@@ -226,6 +239,15 @@ func (s *statesInformer) GetAllPods() []*PodMeta {
 	return podsInformer.GetAllPods()
 }
 
+func (s *statesInformer) GetGhostPods() []*PodMeta {
+	podsInformerIf := s.states.informerPlugins[podsInformerName]
+	podsInformer, ok := podsInformerIf.(*podsInformer)
+	if !ok {
+		klog.Fatalf("pods informer format error")
+	}
+	return podsInformer.GetGhostPods()
+}
+
 func (s *statesInformer) RegisterCallbacks(rType RegisterType, name, description string, callbackFn UpdateCbFn) {
 	s.states.callbackRunner.RegisterCallbacks(rType, name, description, callbackFn)
 }
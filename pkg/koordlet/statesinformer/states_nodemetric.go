@@ -35,7 +35,6 @@ import (
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
@@ -45,7 +44,9 @@ import (
 	clientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	clientsetv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1"
 	listerv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -89,9 +90,13 @@ type nodeMetricInformer struct {
 	nodeMetricLister   listerv1alpha1.NodeMetricLister
 	eventRecorder      record.EventRecorder
 	statusUpdater      *statusUpdater
+	apiWriter          *apiWriter
 
 	podsInformer *podsInformer
+	nodeInformer *nodeInformer
 	metricCache  metriccache.MetricCache
+	// oomFeedback adds the safety margin for the BE OOM kills to the node memory usage, nil if disabled.
+	oomFeedback *BEOOMFeedback
 
 	rwMutex    sync.RWMutex
 	nodeMetric *slov1alpha1.NodeMetric
@@ -124,6 +129,7 @@ func (r *nodeMetricInformer) Setup(ctx *pluginOption, state *pluginState) {
 	r.eventRecorder = eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "koordlet-NodeMetric", Host: ctx.NodeName})
 
 	r.statusUpdater = newStatusUpdater(ctx.KoordClient.SloV1alpha1().NodeMetrics())
+	r.apiWriter = state.apiWriter
 
 	r.metricCache = state.metricCache
 	podsInformerIf := state.informerPlugins[podsInformerName]
@@ -132,6 +138,14 @@ func (r *nodeMetricInformer) Setup(ctx *pluginOption, state *pluginState) {
 		klog.Fatalf("pods informer format error")
 	}
 	r.podsInformer = podsInformer
+	if features.DefaultKoordletFeatureGate.Enabled(features.BEOOMFeedback) {
+		nodeInformer, ok := state.informerPlugins[nodeInformerName].(*nodeInformer)
+		if !ok {
+			klog.Fatalf("node informer format error")
+		}
+		r.nodeInformer = nodeInformer
+		r.oomFeedback = NewDefaultBEOOMFeedback()
+	}
 
 	r.nodeMetricInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -242,7 +256,7 @@ func (r *nodeMetricInformer) sync() {
 		NodeMetric: nodeMetricInfo,
 		PodsMetric: podMetricInfo,
 	}
-	retErr := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+	r.apiWriter.Enqueue(apiWriteKindNodeMetric, r.nodeName, func() error {
 		nodeMetric, err := r.nodeMetricLister.Get(r.nodeName)
 		if errors.IsNotFound(err) {
 			klog.Warningf("nodeMetric %v not found, skip", r.nodeName)
@@ -252,14 +266,13 @@ func (r *nodeMetricInformer) sync() {
 			return err
 		}
 		err = r.statusUpdater.updateStatus(nodeMetric, newStatus)
+		if err != nil {
+			klog.Warningf("update node metric status failed, status %v, err %v", util.DumpJSON(newStatus), err)
+		} else {
+			klog.V(4).Infof("update node metric status success, detail: %v", util.DumpJSON(newStatus))
+		}
 		return err
 	})
-
-	if retErr != nil {
-		klog.Warningf("update node metric status failed, status %v, err %v", util.DumpJSON(newStatus), retErr)
-	} else {
-		klog.V(4).Infof("update node metric status success, detail: %v", util.DumpJSON(newStatus))
-	}
 }
 
 func newNodeMetricInformer(client clientset.Interface, nodeName string) cache.SharedIndexInformer {
@@ -322,6 +335,7 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 	}
 
 	podsMeta := r.podsInformer.GetAllPods()
+	nodeMetricInfo.BEMemorySafetyMargin = r.getBEMemorySafetyMargin(podsMeta)
 	podsMetricInfo := make([]*slov1alpha1.PodMetricInfo, 0, len(podsMeta))
 	podQueryParam := &metriccache.QueryParam{
 		Aggregate: metriccache.AggregationTypeAVG,
@@ -339,6 +353,27 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 	return nodeMetricInfo, podsMetricInfo
 }
 
+// getBEMemorySafetyMargin returns the safety margin in bytes for the BE OOM kills, which the slo-controller holds
+// back from the batch memory of the node. The node usage is reported as it is.
+func (r *nodeMetricInformer) getBEMemorySafetyMargin(podsMeta []*PodMeta) *resource.Quantity {
+	if r.oomFeedback == nil {
+		return nil
+	}
+	margin := r.oomFeedback.Update(podsMeta)
+	node := r.nodeInformer.GetNode()
+	if node == nil {
+		klog.V(4).Infof("node is not ready, skip reporting the BE memory safety margin")
+		return nil
+	}
+	marginBytes := int64(margin * float64(node.Status.Capacity.Memory().Value()))
+	metrics.RecordBEMemorySafetyMargin(margin, float64(marginBytes))
+	if marginBytes <= 0 {
+		return nil
+	}
+	klog.V(4).Infof("report the BE memory safety margin %v (%v bytes)", margin, marginBytes)
+	return resource.NewQuantity(marginBytes, resource.BinarySI)
+}
+
 func (r *nodeMetricInformer) queryNodeMetric(start time.Time, end time.Time, aggregateType metriccache.AggregationType,
 	coldStartFilter bool) slov1alpha1.ResourceMap {
 	queryParam := &metriccache.QueryParam{
@@ -35,7 +35,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -45,9 +44,9 @@ import (
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kubelet"
-	"github.com/koordinator-sh/koordinator/pkg/util"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
 
@@ -63,6 +62,7 @@ type nodeTopoInformer struct {
 
 	metricCache    metriccache.MetricCache
 	callbackRunner *callbackRunner
+	apiWriter      *apiWriter
 
 	nodeResourceTopologyInformer cache.SharedIndexInformer
 	nodeResourceTopologyLister   topologylister.NodeResourceTopologyLister
@@ -87,6 +87,7 @@ func (s *nodeTopoInformer) Setup(ctx *pluginOption, state *pluginState) {
 	s.topologyClient = ctx.TopoClient
 	s.metricCache = state.metricCache
 	s.callbackRunner = state.callbackRunner
+	s.apiWriter = state.apiWriter
 
 	s.nodeResourceTopologyInformer = newNodeResourceTopologyInformer(ctx.TopoClient, ctx.NodeName)
 	s.nodeResourceTopologyLister = topologylister.NewNodeResourceTopologyLister(s.nodeResourceTopologyInformer.GetIndexer())
@@ -129,7 +130,7 @@ func (s *nodeTopoInformer) Start(stopCh <-chan struct{}) {
 	}
 	s.kubelet = stub
 
-	go wait.Until(s.reportNodeTopology, s.config.NodeTopologySyncInterval, stopCh)
+	go profile.Until(s.reportNodeTopology, s.config.nodeTopologySyncInterval, stopCh)
 	klog.V(2).Infof("node topo informer started")
 }
 
@@ -360,7 +361,7 @@ func (s *nodeTopoInformer) reportNodeTopology() {
 	}
 
 	node := s.nodeInformer.GetNode()
-	err = util.RetryOnConflictOrTooManyRequests(func() error {
+	update := func() error {
 		var nodeResourceTopology *v1alpha1.NodeResourceTopology
 		if features.DefaultKoordletFeatureGate.Enabled(features.NodeTopologyReport) {
 			nodeResourceTopology, err = s.nodeResourceTopologyLister.Get(node.Name)
@@ -393,10 +394,13 @@ func (s *nodeTopoInformer) reportNodeTopology() {
 			}
 		}
 		return nil
-	})
-	if err != nil {
-		klog.Errorf("failed to update NodeResourceTopology, err: %v", err)
 	}
+	if !features.DefaultKoordletFeatureGate.Enabled(features.NodeTopologyReport) {
+		// only the node topo object is updated internally
+		_ = update()
+		return
+	}
+	s.apiWriter.Enqueue(apiWriteKindNodeResourceTopology, node.Name, update)
 }
 
 func isSyncNeeded(oldNRT, newNRT *v1alpha1.NodeResourceTopology, nodename string) bool {
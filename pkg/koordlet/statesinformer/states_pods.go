@@ -17,12 +17,15 @@ limitations under the License.
 package statesinformer
 
 import (
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"go.uber.org/atomic"
 	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -31,6 +34,7 @@ import (
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/pleg"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/profile"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -38,13 +42,24 @@ import (
 
 const (
 	podsInformerName pluginName = "podsInformer"
+
+	// ghostPodExpiration bounds how long the pod removed from kubelet is kept while its cgroup exists, so that the
+	// cgroup leaked by the runtime does not keep the pod forever.
+	ghostPodExpiration = 10 * time.Minute
 )
 
+// ghostPod is the pod removed from kubelet while its cgroup still exists, e.g. force deleted during the preStop hooks.
+type ghostPod struct {
+	podMeta     *PodMeta
+	removedTime time.Time
+}
+
 type podsInformer struct {
 	config *Config
 
 	podRWMutex     sync.RWMutex
 	podMap         map[string]*PodMeta
+	ghostPodMap    map[string]*ghostPod
 	podUpdatedTime time.Time
 	podHasSynced   *atomic.Bool
 
@@ -66,6 +81,7 @@ func NewPodsInformer() *podsInformer {
 
 	podsInformer := &podsInformer{
 		podMap:       map[string]*PodMeta{},
+		ghostPodMap:  map[string]*ghostPod{},
 		podHasSynced: atomic.NewBool(false),
 		pleg:         p,
 		podCreated:   make(chan string, 1),
@@ -140,6 +156,18 @@ func (s *podsInformer) GetAllPods() []*PodMeta {
 	return pods
 }
 
+// GetGhostPods returns the pods removed from kubelet whose cgroups still exist. They are terminating and still use
+// the resources, but are not returned by GetAllPods.
+func (s *podsInformer) GetGhostPods() []*PodMeta {
+	s.podRWMutex.RLock()
+	defer s.podRWMutex.RUnlock()
+	pods := make([]*PodMeta, 0, len(s.ghostPodMap))
+	for _, ghost := range s.ghostPodMap {
+		pods = append(pods, ghost.podMeta.DeepCopy())
+	}
+	return pods
+}
+
 func (s *podsInformer) syncPods() error {
 	podList, err := s.kubelet.GetAllPods()
 
@@ -160,7 +188,11 @@ func (s *podsInformer) syncPods() error {
 		// record pod container metrics
 		recordPodResourceMetrics(podMeta)
 	}
+	newGhostPodMap := s.syncGhostPods(newPodMap, time.Now())
+	s.podRWMutex.Lock()
 	s.podMap = newPodMap
+	s.ghostPodMap = newGhostPodMap
+	s.podRWMutex.Unlock()
 	s.podHasSynced.Store(true)
 	s.podUpdatedTime = time.Now()
 	klog.Infof("get pods success, len %d, time %s", len(s.podMap), s.podUpdatedTime.String())
@@ -168,6 +200,48 @@ func (s *podsInformer) syncPods() error {
 	return nil
 }
 
+// syncGhostPods returns the ghost pods after the pods listed from kubelet. The pod deleted from kubelet may still run
+// in its cgroup, e.g. force deleted during the preStop hooks, so it is kept until the cgroup disappears or it expires.
+func (s *podsInformer) syncGhostPods(newPodMap map[string]*PodMeta, now time.Time) map[string]*ghostPod {
+	s.podRWMutex.RLock()
+	defer s.podRWMutex.RUnlock()
+	newGhostPodMap := map[string]*ghostPod{}
+	for uid, ghost := range s.ghostPodMap {
+		if _, ok := newPodMap[uid]; ok || !isPodCgroupExist(ghost.podMeta.CgroupDir) {
+			continue
+		}
+		if now.Sub(ghost.removedTime) >= ghostPodExpiration {
+			klog.V(4).Infof("drop the pod %s/%s removed from kubelet for %v although its cgroup exists",
+				ghost.podMeta.Pod.Namespace, ghost.podMeta.Pod.Name, now.Sub(ghost.removedTime))
+			continue
+		}
+		newGhostPodMap[uid] = ghost
+	}
+	for uid, podMeta := range s.podMap {
+		if _, ok := newPodMap[uid]; ok || !isPodCgroupExist(podMeta.CgroupDir) {
+			continue
+		}
+		podMeta = podMeta.DeepCopy()
+		if podMeta.Pod.DeletionTimestamp == nil {
+			// the pod removed from kubelet is terminating even if the deletion was not observed
+			deletionTime := metav1.NewTime(now)
+			podMeta.Pod.DeletionTimestamp = &deletionTime
+		}
+		newGhostPodMap[uid] = &ghostPod{podMeta: podMeta, removedTime: now}
+		klog.V(4).Infof("keep the pod %s/%s removed from kubelet until its cgroup disappears",
+			podMeta.Pod.Namespace, podMeta.Pod.Name)
+	}
+	return newGhostPodMap
+}
+
+// kubeletSyncInterval returns the sync interval of the effective profile, so the switched profile takes effect.
+func (s *podsInformer) kubeletSyncInterval(fallback time.Duration) time.Duration {
+	if d := profile.Duration(func(p *profile.Preset) time.Duration { return p.KubeletSyncInterval }, fallback); d > 0 {
+		return d
+	}
+	return fallback
+}
+
 func (s *podsInformer) syncKubeletLoop(duration time.Duration, stopCh <-chan struct{}) {
 	timer := time.NewTimer(duration)
 	defer timer.Stop()
@@ -185,12 +259,12 @@ func (s *podsInformer) syncKubeletLoop(duration time.Duration, stopCh <-chan str
 				if !timer.Stop() {
 					<-timer.C
 				}
-				timer.Reset(duration)
+				timer.Reset(s.kubeletSyncInterval(duration))
 			} else {
 				klog.V(4).Infof("new pod created, but sync rate limiter is not allowed")
 			}
 		case <-timer.C:
-			timer.Reset(duration)
+			timer.Reset(s.kubeletSyncInterval(duration))
 			s.syncPods()
 		case <-stopCh:
 			klog.Infof("sync kubelet loop is exited")
@@ -236,6 +310,14 @@ func newKubeletStubFromConfig(node *corev1.Node, cfg *Config) (KubeletStub, erro
 	return NewKubeletStub(address, port, scheme, cfg.KubeletSyncTimeout, restConfig)
 }
 
+// isPodCgroupExist checks whether the pod cgroup exists in the cpuacct subsystem.
+func isPodCgroupExist(podCgroupDir string) bool {
+	podCgroupPath := filepath.Join(koordletutil.GetRootCgroupSubfsDir(system.CgroupCPUAcctDir),
+		koordletutil.GetPodCgroupDirWithKube(podCgroupDir))
+	_, err := os.Stat(podCgroupPath)
+	return err == nil
+}
+
 func genPodCgroupParentDir(pod *corev1.Pod) string {
 	// todo use cri interface to get pod cgroup dir
 	// e.g. kubepods-burstable.slice/kubepods-burstable-pod9dba1d9e_67ba_4db6_8a73_fb3ea297c363.slice/
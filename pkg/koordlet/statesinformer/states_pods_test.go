@@ -36,7 +36,9 @@ import (
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 func Test_genPodCgroupParentDirWithCgroupfsDriver(t *testing.T) {
@@ -154,6 +156,125 @@ func Test_statesInformer_syncPods(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_statesInformer_syncPodsKeepTerminating(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	runningPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "running-pod",
+			Namespace: "default",
+			UID:       "running-pod-uid",
+		},
+		Status: corev1.PodStatus{
+			QOSClass: corev1.PodQOSGuaranteed,
+			Phase:    corev1.PodRunning,
+		},
+	}
+	removedPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "removed-pod",
+			Namespace: "default",
+			UID:       "removed-pod-uid",
+		},
+		Status: corev1.PodStatus{
+			QOSClass: corev1.PodQOSBurstable,
+			Phase:    corev1.PodRunning,
+		},
+	}
+	m := &podsInformer{
+		kubelet: &testKubeletStub{pods: corev1.PodList{
+			Items: []corev1.Pod{runningPod, removedPod},
+		}},
+		podHasSynced:   atomic.NewBool(false),
+		callbackRunner: NewCallbackRunner(),
+	}
+	err := m.syncPods()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(m.GetAllPods()))
+
+	// the removed pod is kept as a ghost pod while its cgroup exists
+	removedPodCgroupDir := koordletutil.GetPodCgroupDirWithKube(genPodCgroupParentDir(&removedPod))
+	helper.MkDirAll(filepath.Join(system.CgroupCPUAcctDir, removedPodCgroupDir))
+	m.kubelet = &testKubeletStub{pods: corev1.PodList{
+		Items: []corev1.Pod{runningPod},
+	}}
+	err = m.syncPods()
+	assert.NoError(t, err)
+	pods := m.GetAllPods()
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, runningPod.UID, pods[0].Pod.UID)
+	ghostPods := m.GetGhostPods()
+	assert.Equal(t, 1, len(ghostPods))
+	assert.Equal(t, removedPod.UID, ghostPods[0].Pod.UID)
+	assert.True(t, util.IsPodTerminating(ghostPods[0].Pod))
+
+	// the ghost pod is kept in the following syncs
+	err = m.syncPods()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(m.GetGhostPods()))
+
+	// the ghost pod is dropped once its cgroup disappears
+	err = os.RemoveAll(filepath.Join(helper.TempDir, system.CgroupCPUAcctDir, removedPodCgroupDir))
+	assert.NoError(t, err)
+	err = m.syncPods()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(m.GetAllPods()))
+	assert.Equal(t, 0, len(m.GetGhostPods()))
+}
+
+func Test_statesInformer_syncPodsExpireGhostPods(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	runningPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "running-pod", Namespace: "default", UID: "running-pod-uid"},
+		Status:     corev1.PodStatus{QOSClass: corev1.PodQOSGuaranteed, Phase: corev1.PodRunning},
+	}
+	removedPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "removed-pod", Namespace: "default", UID: "removed-pod-uid"},
+		Status:     corev1.PodStatus{QOSClass: corev1.PodQOSBurstable, Phase: corev1.PodRunning},
+	}
+	removedPodCgroupDir := koordletutil.GetPodCgroupDirWithKube(genPodCgroupParentDir(&removedPod))
+	helper.MkDirAll(filepath.Join(system.CgroupCPUAcctDir, removedPodCgroupDir))
+	m := &podsInformer{
+		kubelet: &testKubeletStub{pods: corev1.PodList{
+			Items: []corev1.Pod{runningPod, removedPod},
+		}},
+		podHasSynced:   atomic.NewBool(false),
+		callbackRunner: NewCallbackRunner(),
+	}
+	assert.NoError(t, m.syncPods())
+	m.kubelet = &testKubeletStub{pods: corev1.PodList{
+		Items: []corev1.Pod{runningPod},
+	}}
+	assert.NoError(t, m.syncPods())
+	assert.Equal(t, 1, len(m.GetGhostPods()))
+
+	// the ghost pod expires even if the cgroup is leaked
+	m.ghostPodMap[string(removedPod.UID)].removedTime = time.Now().Add(-ghostPodExpiration)
+	assert.NoError(t, m.syncPods())
+	assert.Equal(t, 0, len(m.GetGhostPods()))
+	assert.Equal(t, 1, len(m.GetAllPods()))
+
+	// the ghost pod listed by kubelet again is no longer a ghost
+	m.kubelet = &testKubeletStub{pods: corev1.PodList{
+		Items: []corev1.Pod{runningPod, removedPod},
+	}}
+	assert.NoError(t, m.syncPods())
+	m.kubelet = &testKubeletStub{pods: corev1.PodList{
+		Items: []corev1.Pod{runningPod},
+	}}
+	assert.NoError(t, m.syncPods())
+	assert.Equal(t, 1, len(m.GetGhostPods()))
+	m.kubelet = &testKubeletStub{pods: corev1.PodList{
+		Items: []corev1.Pod{runningPod, removedPod},
+	}}
+	assert.NoError(t, m.syncPods())
+	assert.Equal(t, 0, len(m.GetGhostPods()))
+	assert.Equal(t, 2, len(m.GetAllPods()))
+}
+
 func Test_newKubeletStub(t *testing.T) {
 	testingNode := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
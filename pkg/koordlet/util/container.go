@@ -17,6 +17,7 @@ limitations under the License.
 package util
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"strconv"
@@ -242,3 +243,17 @@ func GetPIDsInContainer(podParentDir string, c *corev1.ContainerStatus) ([]uint3
 	}
 	return pids, nil
 }
+
+// ParseContainerIDFromCgroupDir returns the container id from the cgroup dir of a process in the container.
+// e.g. kubepods.slice/kubepods-burstable.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-xxx.scope
+// returns xxx. The process can also be in a child cgroup of the container.
+func ParseContainerIDFromCgroupDir(cgroupDir string) (string, error) {
+	dirs := strings.Split(strings.Trim(cgroupDir, "/"), "/")
+	for i := 0; i+1 < len(dirs); i++ {
+		if _, err := ParsePodID(dirs[i]); err != nil {
+			continue
+		}
+		return ParseContainerID(dirs[i+1])
+	}
+	return "", fmt.Errorf("container not found in cgroup dir %s", cgroupDir)
+}
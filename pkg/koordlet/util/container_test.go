@@ -679,3 +679,40 @@ func writeCgroupContent(filePath string, content []byte) error {
 	}
 	return os.WriteFile(filePath, content, 0655)
 }
+
+func Test_ParseContainerIDFromCgroupDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		cgroupDir string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "guaranteed container",
+			cgroupDir: "/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-abc.scope",
+			want:      "abc",
+		},
+		{
+			name:      "burstable container",
+			cgroupDir: "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/cri-containerd-def.scope",
+			want:      "def",
+		},
+		{
+			name:      "pod cgroup only",
+			cgroupDir: "/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice",
+			wantErr:   true,
+		},
+		{
+			name:      "not in pod",
+			cgroupDir: "/user.slice/user-0.slice/session-1.scope",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseContainerIDFromCgroupDir(tt.cgroupDir)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
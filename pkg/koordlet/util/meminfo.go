@@ -19,11 +19,13 @@ package util
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
@@ -74,6 +76,17 @@ type MemInfo struct {
 	DirectMap4k       uint64 `json:"direct_map_4k"`
 	DirectMap2M       uint64 `json:"direct_map_2M"`
 	DirectMap1G       uint64 `json:"direct_map_1G"`
+	// Zswap is the size of the zswap pool, and Zswapped is the size of the pages stored in it.
+	Zswap    uint64 `json:"zswap"`
+	Zswapped uint64 `json:"zswapped"`
+}
+
+// ZramStat is the memory statistics of a zram device in bytes.
+type ZramStat struct {
+	// OrigDataSize is the uncompressed size of the data stored in the device.
+	OrigDataSize uint64
+	// MemUsedTotal is the memory allocated to store the compressed data, including the metadata.
+	MemUsedTotal uint64
 }
 
 func readMemInfo(path string) (*MemInfo, error) {
@@ -118,6 +131,67 @@ func readMemInfo(path string) (*MemInfo, error) {
 	return &info, nil
 }
 
+// readZramStats reads the statistics of all zram devices in the sys block dir, none if zram is not used.
+// The format of mm_stat is "orig_data_size compr_data_size mem_used_total mem_limit mem_used_max same_pages ...".
+func readZramStats(sysBlockDir string) ([]ZramStat, error) {
+	paths, err := filepath.Glob(filepath.Join(sysBlockDir, "zram*", system.ZramMMStatName))
+	if err != nil {
+		return nil, err
+	}
+	var stats []ZramStat
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%s is illegally formatted: %s", path, string(data))
+		}
+		origDataSize, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse orig_data_size of %s, err: %v", path, err)
+		}
+		memUsedTotal, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mem_used_total of %s, err: %v", path, err)
+		}
+		stats = append(stats, ZramStat{OrigDataSize: origDataSize, MemUsedTotal: memUsedTotal})
+	}
+	return stats, nil
+}
+
+// getCompressedSavingKB returns the memory saved by compressing the swapped-out pages into zswap and zram (kB).
+// The kernel accounts only the compressed pools as used, so the saving looks free while the pages are still demanded.
+func getCompressedSavingKB(memInfo *MemInfo, zramStats []ZramStat) uint64 {
+	var saving uint64
+	if memInfo.Zswapped > memInfo.Zswap {
+		saving += memInfo.Zswapped - memInfo.Zswap
+	}
+	for _, stat := range zramStats {
+		if stat.OrigDataSize > stat.MemUsedTotal {
+			saving += (stat.OrigDataSize - stat.MemUsedTotal) / 1024
+		}
+	}
+	return saving
+}
+
+// getEffectiveMemUsageKB returns the memory usage including the saving of the compressed pools, so the memory of the
+// pages swapped out into zram or zswap is not regarded as free. The usage is at most the total memory.
+func getEffectiveMemUsageKB(memInfo *MemInfo, zramStats []ZramStat) int64 {
+	usage := memInfo.MemTotal - memInfo.MemAvailable
+	if saving := getCompressedSavingKB(memInfo, zramStats); saving > 0 {
+		usage += saving
+		if usage > memInfo.MemTotal {
+			usage = memInfo.MemTotal
+		}
+	}
+	return int64(usage)
+}
+
 // GetMemInfoUsageKB returns the node's memory usage quantity (kB)
 func GetMemInfoUsageKB() (int64, error) {
 	meminfoPath := system.GetProcFilePath(system.ProcMemInfoName)
@@ -125,8 +199,12 @@ func GetMemInfoUsageKB() (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	usage := int64(memInfo.MemTotal - memInfo.MemAvailable)
-	return usage, nil
+	zramStats, err := readZramStats(system.GetSysBlockFilePath(""))
+	if err != nil {
+		// the zram devices are optional, never fail the usage collection for them
+		klog.V(4).Infof("failed to read zram stats, err: %v", err)
+	}
+	return getEffectiveMemUsageKB(memInfo, zramStats), nil
 }
 
 // DEPRECATED: use NewCgroupReader().ReadMemoryStat() instead.
@@ -176,6 +176,117 @@ func Test_GetMemInfoUsageKB(t *testing.T) {
 	t.Log("meminfo: ", memInfoUsage)
 }
 
+func Test_readZramStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []ZramStat
+		wantErr bool
+	}{
+		{
+			name: "no zram",
+			want: nil,
+		},
+		{
+			name: "multiple zram devices",
+			files: map[string]string{
+				"zram0/mm_stat": "  4194304  1048576  1310720        0  1310720        0        0        0\n",
+				"zram1/mm_stat": "  2097152   524288   655360        0   655360       10        0        0\n",
+				"loop0/stat":    "0 0 0 0 0 0 0 0 0 0 0\n",
+			},
+			want: []ZramStat{
+				{OrigDataSize: 4194304, MemUsedTotal: 1310720},
+				{OrigDataSize: 2097152, MemUsedTotal: 655360},
+			},
+		},
+		{
+			name: "illegal format",
+			files: map[string]string{
+				"zram0/mm_stat": "4194304\n",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.files {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0777))
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0666))
+			}
+			got, err := readZramStats(dir)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_getEffectiveMemUsageKB(t *testing.T) {
+	tests := []struct {
+		name      string
+		memInfo   *MemInfo
+		zramStats []ZramStat
+		want      int64
+	}{
+		{
+			name:    "no compressed swap",
+			memInfo: &MemInfo{MemTotal: 1048576, MemAvailable: 524288},
+			want:    524288,
+		},
+		{
+			name:    "zswap",
+			memInfo: &MemInfo{MemTotal: 1048576, MemAvailable: 524288, Zswap: 32768, Zswapped: 131072},
+			want:    524288 + 98304,
+		},
+		{
+			name:    "zram",
+			memInfo: &MemInfo{MemTotal: 1048576, MemAvailable: 524288},
+			zramStats: []ZramStat{
+				{OrigDataSize: 268435456, MemUsedTotal: 67108864},
+				// no saving for the device holding the metadata only
+				{OrigDataSize: 1024, MemUsedTotal: 4096},
+			},
+			want: 524288 + 196608,
+		},
+		{
+			name:    "at most the total memory",
+			memInfo: &MemInfo{MemTotal: 1048576, MemAvailable: 131072, Zswap: 65536, Zswapped: 524288},
+			want:    1048576,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getEffectiveMemUsageKB(tt.memInfo, tt.zramStats))
+		})
+	}
+}
+
+func Test_GetMemInfoUsageKBWithZram(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+	helper.WriteProcSubFileContents(system.ProcMemInfoName, "MemTotal:        8388608 kB\nMemFree:         2097152 kB\n"+
+		"MemAvailable:    4194304 kB\nSwapTotal:       4194304 kB\nSwapFree:        2097152 kB\n"+
+		"Zswap:             65536 kB\nZswapped:         262144 kB\n")
+
+	// only zswap
+	got, err := GetMemInfoUsageKB()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4194304+196608), got)
+
+	// 1GiB swapped out into zram and compressed into 256MiB
+	helper.WriteFileContents("sys/block/zram0/mm_stat", "1073741824 251658240 268435456 0 268435456 0 0 0\n")
+	got, err = GetMemInfoUsageKB()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4194304+196608+786432), got)
+}
+
 func Test_readPodMemStat(t *testing.T) {
 	tempDir := t.TempDir()
 	tempInvalidPodCgroupDir := filepath.Join(tempDir, "no_cgroup")
@@ -0,0 +1,492 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ProcMountInfoName is the mount info of the host mount namespace under /proc/1/.
+	ProcMountInfoName = "1/mountinfo"
+	// ProcDiskStatsName is the IO statistics of the block devices under /proc/.
+	ProcDiskStatsName = "diskstats"
+
+	sysBlockDevName       = "dev"
+	sysBlockPartitionName = "partition"
+	sysBlockSlavesDir     = "slaves"
+	sysBlockHoldersDir    = "holders"
+	sysBlockDMNameFile    = "dm/name"
+	sysBlockMDDir         = "md"
+
+	devMapperDir = "/dev/mapper/"
+)
+
+type BlockDeviceType string
+
+const (
+	BlockDeviceDisk      BlockDeviceType = "disk"
+	BlockDevicePartition BlockDeviceType = "partition"
+	// BlockDeviceDM is the device-mapper device, e.g. the LVM logical volume.
+	BlockDeviceDM BlockDeviceType = "dm"
+	// BlockDeviceMD is the software RAID device.
+	BlockDeviceMD BlockDeviceType = "md"
+)
+
+// BlockDevice is a block device under /sys/block/, or a partition of it.
+type BlockDevice struct {
+	// Name is the kernel name of the device, e.g. nvme0n1, nvme0n1p1, dm-0, md0.
+	Name  string
+	Major int64
+	Minor int64
+	Type  BlockDeviceType
+	// Parent is the disk of the partition, empty for the others.
+	Parent string
+	// DMName is the name of the device-mapper device, e.g. vg0-lv0 for /dev/mapper/vg0-lv0.
+	DMName string
+	// Slaves are the devices the device is stacked on, and Holders are the devices stacked on the device.
+	Slaves  []string
+	Holders []string
+}
+
+// DeviceNumber returns the `major:minor` of the device used by the cgroup blkio and io files.
+func (d *BlockDevice) DeviceNumber() string {
+	return fmt.Sprintf("%d:%d", d.Major, d.Minor)
+}
+
+// BlockDeviceTopology is the stacking of the block devices through the partition, device-mapper and md layers.
+type BlockDeviceTopology struct {
+	devices  map[string]*BlockDevice
+	byNumber map[string]*BlockDevice
+	byDMName map[string]*BlockDevice
+}
+
+// LoadBlockDeviceTopology walks the block devices and their partitions under /sys/block/.
+func LoadBlockDeviceTopology() (*BlockDeviceTopology, error) {
+	sysBlockDir := GetSysBlockFilePath("")
+	entries, err := os.ReadDir(sysBlockDir)
+	if err != nil {
+		return nil, err
+	}
+	t := &BlockDeviceTopology{
+		devices:  map[string]*BlockDevice{},
+		byNumber: map[string]*BlockDevice{},
+		byDMName: map[string]*BlockDevice{},
+	}
+	for _, entry := range entries {
+		deviceDir := filepath.Join(sysBlockDir, entry.Name())
+		device, err := readBlockDevice(deviceDir, entry.Name())
+		if err != nil {
+			klog.V(5).Infof("failed to read block device %s, err: %v", entry.Name(), err)
+			continue
+		}
+		t.add(device)
+
+		// the partitions are the sub-directories having the partition file
+		subEntries, err := os.ReadDir(deviceDir)
+		if err != nil {
+			continue
+		}
+		for _, subEntry := range subEntries {
+			partitionDir := filepath.Join(deviceDir, subEntry.Name())
+			if !FileExists(filepath.Join(partitionDir, sysBlockPartitionName)) {
+				continue
+			}
+			partition, err := readBlockDevice(partitionDir, subEntry.Name())
+			if err != nil {
+				klog.V(5).Infof("failed to read partition %s of block device %s, err: %v", subEntry.Name(), entry.Name(), err)
+				continue
+			}
+			partition.Type = BlockDevicePartition
+			partition.Parent = device.Name
+			t.add(partition)
+		}
+	}
+	return t, nil
+}
+
+func readBlockDevice(deviceDir, name string) (*BlockDevice, error) {
+	content, err := os.ReadFile(filepath.Join(deviceDir, sysBlockDevName))
+	if err != nil {
+		return nil, err
+	}
+	major, minor, err := parseDeviceNumber(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	device := &BlockDevice{
+		Name:    name,
+		Major:   major,
+		Minor:   minor,
+		Type:    BlockDeviceDisk,
+		Slaves:  readDirNames(filepath.Join(deviceDir, sysBlockSlavesDir)),
+		Holders: readDirNames(filepath.Join(deviceDir, sysBlockHoldersDir)),
+	}
+	if dmName, err := os.ReadFile(filepath.Join(deviceDir, sysBlockDMNameFile)); err == nil {
+		device.Type = BlockDeviceDM
+		device.DMName = strings.TrimSpace(string(dmName))
+	} else if FileExists(filepath.Join(deviceDir, sysBlockMDDir)) {
+		device.Type = BlockDeviceMD
+	}
+	return device, nil
+}
+
+// readDirNames returns the sorted names in the directory, or nil if it does not exist.
+func readDirNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseDeviceNumber(s string) (int64, int64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid device number %q", s)
+	}
+	major, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid device number %q, err: %v", s, err)
+	}
+	minor, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid device number %q, err: %v", s, err)
+	}
+	return major, minor, nil
+}
+
+func (t *BlockDeviceTopology) add(device *BlockDevice) {
+	t.devices[device.Name] = device
+	t.byNumber[device.DeviceNumber()] = device
+	if device.DMName != "" {
+		t.byDMName[device.DMName] = device
+	}
+}
+
+// GetDevice returns the device by the kernel name, nil if not found.
+func (t *BlockDeviceTopology) GetDevice(name string) *BlockDevice {
+	return t.devices[name]
+}
+
+// GetDeviceByNumber returns the device by the `major:minor`, nil if not found.
+func (t *BlockDeviceTopology) GetDeviceByNumber(number string) *BlockDevice {
+	return t.byNumber[number]
+}
+
+// GetDeviceBySource returns the device of the mount source, e.g. /dev/nvme0n1p1 or /dev/mapper/vg0-lv0.
+func (t *BlockDeviceTopology) GetDeviceBySource(source string) *BlockDevice {
+	if strings.HasPrefix(source, devMapperDir) {
+		return t.byDMName[strings.TrimPrefix(source, devMapperDir)]
+	}
+	if !strings.HasPrefix(source, "/dev/") {
+		return nil
+	}
+	return t.devices[filepath.Base(source)]
+}
+
+// GetPhysicalDisks walks the slaves down from the device to the disks it is stacked on, the partitions resolve to
+// their disks. The disks are sorted by the name.
+func (t *BlockDeviceTopology) GetPhysicalDisks(name string) []*BlockDevice {
+	disks := map[string]*BlockDevice{}
+	visited := map[string]bool{}
+	var walk func(name string)
+	walk = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		device := t.devices[name]
+		if device == nil {
+			return
+		}
+		if len(device.Slaves) > 0 {
+			for _, slave := range device.Slaves {
+				walk(slave)
+			}
+			return
+		}
+		if device.Type == BlockDevicePartition {
+			if disk := t.devices[device.Parent]; disk != nil {
+				disks[disk.Name] = disk
+			}
+			return
+		}
+		disks[device.Name] = device
+	}
+	walk(name)
+	return sortBlockDevices(disks)
+}
+
+// GetStackedDevices walks the holders up from the device to the devices stacked on it, including the ones stacked on
+// its partitions. The devices are sorted by the name.
+func (t *BlockDeviceTopology) GetStackedDevices(name string) []*BlockDevice {
+	stacked := map[string]*BlockDevice{}
+	var walk func(name string)
+	walk = func(name string) {
+		device := t.devices[name]
+		if device == nil {
+			return
+		}
+		for _, holder := range device.Holders {
+			if _, ok := stacked[holder]; ok {
+				continue
+			}
+			if holderDevice := t.devices[holder]; holderDevice != nil {
+				stacked[holder] = holderDevice
+				walk(holder)
+			}
+		}
+	}
+	walk(name)
+	for _, device := range t.devices {
+		if device.Type == BlockDevicePartition && device.Parent == name {
+			walk(device.Name)
+		}
+	}
+	return sortBlockDevices(stacked)
+}
+
+func sortBlockDevices(devices map[string]*BlockDevice) []*BlockDevice {
+	sorted := make([]*BlockDevice, 0, len(devices))
+	for _, device := range devices {
+		sorted = append(sorted, device)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// MountInfo is a mount in the mountinfo.
+type MountInfo struct {
+	// DeviceNumber is the `major:minor` of the device the filesystem is mounted on.
+	DeviceNumber string
+	MountPoint   string
+	FSType       string
+	Source       string
+}
+
+// ReadMountInfo reads the mounts of the host mount namespace.
+func ReadMountInfo() ([]MountInfo, error) {
+	content, err := os.ReadFile(GetProcFilePath(ProcMountInfoName))
+	if err != nil {
+		return nil, err
+	}
+	return ParseMountInfo(string(content)), nil
+}
+
+// ParseMountInfo parses the content of the mountinfo, the malformed lines are skipped.
+// e.g. `36 35 253:0 / /var/lib/kubelet rw,relatime shared:1 - ext4 /dev/mapper/vg0-lv0 rw`
+func ParseMountInfo(content string) []MountInfo {
+	var mounts []MountInfo
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		// the optional fields end with the separator `-`
+		separator := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				separator = i
+				break
+			}
+		}
+		if separator < 0 || len(fields) < separator+3 {
+			continue
+		}
+		mounts = append(mounts, MountInfo{
+			DeviceNumber: fields[2],
+			MountPoint:   fields[4],
+			FSType:       fields[separator+1],
+			Source:       fields[separator+2],
+		})
+	}
+	return mounts
+}
+
+// BlockDeviceResolution is the devices of a mount point.
+type BlockDeviceResolution struct {
+	MountPoint string
+	// Device is the device the filesystem is mounted on, e.g. the LVM logical volume. Its statistics are the IO of
+	// the mount point.
+	Device *BlockDevice
+	// ThrottleDevices are the physical disks under the device, which the blkio throttling applies to since the
+	// limits against the device-mapper and md devices or the partitions do not take effect.
+	ThrottleDevices []*BlockDevice
+}
+
+// BlockDeviceResolver resolves the mount points to the block devices through the partition, device-mapper and md
+// layers. The topology is reloaded when the mountinfo changes or a mount point can't be resolved, so that the
+// remounted volumes and the hot-added devices are found.
+type BlockDeviceResolver struct {
+	lock      sync.Mutex
+	topology  *BlockDeviceTopology
+	mountInfo string
+	mounts    []MountInfo
+}
+
+func NewBlockDeviceResolver() *BlockDeviceResolver {
+	return &BlockDeviceResolver{}
+}
+
+// Refresh reloads the block device topology and the mounts.
+func (r *BlockDeviceResolver) Refresh() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.refreshLocked()
+}
+
+func (r *BlockDeviceResolver) refreshLocked() error {
+	topology, err := LoadBlockDeviceTopology()
+	if err != nil {
+		return fmt.Errorf("failed to load block device topology, err: %v", err)
+	}
+	content, err := os.ReadFile(GetProcFilePath(ProcMountInfoName))
+	if err != nil {
+		return fmt.Errorf("failed to read mount info, err: %v", err)
+	}
+	r.topology, r.mountInfo, r.mounts = topology, string(content), ParseMountInfo(string(content))
+	return nil
+}
+
+// mountInfoChangedLocked returns whether the mountinfo differs from the one the mounts are parsed from.
+func (r *BlockDeviceResolver) mountInfoChangedLocked() bool {
+	content, err := os.ReadFile(GetProcFilePath(ProcMountInfoName))
+	return err != nil || string(content) != r.mountInfo
+}
+
+// Resolve returns the devices of the mount point the path is under.
+func (r *BlockDeviceResolver) Resolve(path string) (*BlockDeviceResolution, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.topology != nil && !r.mountInfoChangedLocked() {
+		if resolution := r.resolveLocked(path); resolution != nil {
+			return resolution, nil
+		}
+	}
+	// the device may be hot-added or the volume remounted after the last refresh
+	if err := r.refreshLocked(); err != nil {
+		return nil, err
+	}
+	if resolution := r.resolveLocked(path); resolution != nil {
+		return resolution, nil
+	}
+	return nil, fmt.Errorf("no block device found for path %s", path)
+}
+
+func (r *BlockDeviceResolver) resolveLocked(path string) *BlockDeviceResolution {
+	path = filepath.Clean(path)
+	var mount *MountInfo
+	for i := range r.mounts {
+		m := &r.mounts[i]
+		if !isPathUnder(path, m.MountPoint) {
+			continue
+		}
+		// the later mount over the same mount point shadows the earlier one
+		if mount == nil || len(m.MountPoint) >= len(mount.MountPoint) {
+			mount = m
+		}
+	}
+	if mount == nil {
+		return nil
+	}
+	device := r.topology.GetDeviceByNumber(mount.DeviceNumber)
+	if device == nil {
+		// e.g. btrfs reports an anonymous device number
+		device = r.topology.GetDeviceBySource(mount.Source)
+	}
+	if device == nil {
+		return nil
+	}
+	return &BlockDeviceResolution{
+		MountPoint:      mount.MountPoint,
+		Device:          device,
+		ThrottleDevices: r.topology.GetPhysicalDisks(device.Name),
+	}
+}
+
+func isPathUnder(path, mountPoint string) bool {
+	if mountPoint == "/" || path == mountPoint {
+		return true
+	}
+	return strings.HasPrefix(path, mountPoint+"/")
+}
+
+// DiskStat is the IO statistics of a block device in /proc/diskstats.
+type DiskStat struct {
+	ReadIOs      uint64
+	ReadSectors  uint64
+	WriteIOs     uint64
+	WriteSectors uint64
+}
+
+// DiskSectorSize is the size of the sectors counted in /proc/diskstats, which is always 512 bytes.
+const DiskSectorSize = 512
+
+// ReadDiskStats reads the IO statistics of the block devices by the `major:minor`.
+func ReadDiskStats() (map[string]*DiskStat, error) {
+	content, err := os.ReadFile(GetProcFilePath(ProcDiskStatsName))
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiskStats(string(content)), nil
+}
+
+// ParseDiskStats parses the content of /proc/diskstats, the malformed lines are skipped.
+// e.g. `259 0 nvme0n1 1148 0 85966 233 396 94 46370 321 0 536 554 0 0 0 0`
+func ParseDiskStats(content string) map[string]*DiskStat {
+	stats := map[string]*DiskStat{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+		var values [4]uint64
+		valid := true
+		// reads completed, sectors read, writes completed, sectors written
+		for i, index := range []int{3, 5, 7, 9} {
+			v, err := strconv.ParseUint(fields[index], 10, 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			values[i] = v
+		}
+		if !valid {
+			continue
+		}
+		stats[fields[0]+":"+fields[1]] = &DiskStat{
+			ReadIOs:      values[0],
+			ReadSectors:  values[1],
+			WriteIOs:     values[2],
+			WriteSectors: values[3],
+		}
+	}
+	return stats
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockDevice struct {
+	// path is relative to the /sys/block/, e.g. nvme0n1/nvme0n1p1 for the partition.
+	path    string
+	dev     string
+	dmName  string
+	isMD    bool
+	slaves  []string
+	holders []string
+}
+
+func writeTestBlockDevices(helper *FileTestUtil, devices []testBlockDevice) {
+	for _, d := range devices {
+		dir := path.Join("sys/block", d.path)
+		helper.WriteFileContents(path.Join(dir, sysBlockDevName), d.dev+"\n")
+		if path.Dir(d.path) != "." {
+			helper.WriteFileContents(path.Join(dir, sysBlockPartitionName), "1\n")
+		}
+		if d.dmName != "" {
+			helper.WriteFileContents(path.Join(dir, sysBlockDMNameFile), d.dmName+"\n")
+		}
+		if d.isMD {
+			helper.MkDirAll(path.Join(dir, sysBlockMDDir))
+		}
+		helper.MkDirAll(path.Join(dir, sysBlockSlavesDir))
+		for _, slave := range d.slaves {
+			helper.CreateFile(path.Join(dir, sysBlockSlavesDir, slave))
+		}
+		helper.MkDirAll(path.Join(dir, sysBlockHoldersDir))
+		for _, holder := range d.holders {
+			helper.CreateFile(path.Join(dir, sysBlockHoldersDir, holder))
+		}
+	}
+}
+
+func blockDeviceNames(devices []*BlockDevice) []string {
+	var names []string
+	for _, d := range devices {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+func TestBlockDeviceResolver(t *testing.T) {
+	tests := []struct {
+		name               string
+		devices            []testBlockDevice
+		mountInfo          string
+		path               string
+		wantErr            bool
+		wantMountPoint     string
+		wantDevice         string
+		wantDeviceNumber   string
+		wantThrottleDevice []string
+	}{
+		{
+			name: "plain nvme partition",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0"},
+				{path: "nvme0n1/nvme0n1p1", dev: "259:1"},
+			},
+			mountInfo: `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+`,
+			path:               "/var/lib/kubelet/pods",
+			wantMountPoint:     "/",
+			wantDevice:         "nvme0n1p1",
+			wantDeviceNumber:   "259:1",
+			wantThrottleDevice: []string{"nvme0n1"},
+		},
+		{
+			name: "plain nvme disk",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0"},
+				{path: "nvme1n1", dev: "259:2"},
+			},
+			mountInfo: `22 1 259:0 / / rw,relatime shared:1 - ext4 /dev/nvme0n1 rw
+36 22 259:2 / /data rw,relatime shared:2 - xfs /dev/nvme1n1 rw
+`,
+			path:               "/data/containerd",
+			wantMountPoint:     "/data",
+			wantDevice:         "nvme1n1",
+			wantDeviceNumber:   "259:2",
+			wantThrottleDevice: []string{"nvme1n1"},
+		},
+		{
+			name: "lvm on nvme partition",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0"},
+				{path: "nvme0n1/nvme0n1p1", dev: "259:1"},
+				{path: "nvme0n1/nvme0n1p2", dev: "259:2", holders: []string{"dm-0"}},
+				{path: "dm-0", dev: "253:0", dmName: "vg0-lv0", slaves: []string{"nvme0n1p2"}},
+			},
+			mountInfo: `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+36 22 253:0 / /var/lib/kubelet rw,relatime shared:2 - ext4 /dev/mapper/vg0-lv0 rw
+`,
+			path:               "/var/lib/kubelet",
+			wantMountPoint:     "/var/lib/kubelet",
+			wantDevice:         "dm-0",
+			wantDeviceNumber:   "253:0",
+			wantThrottleDevice: []string{"nvme0n1"},
+		},
+		{
+			name: "lvm striped over nvme disks resolved by the mapper source",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0", holders: []string{"dm-0"}},
+				{path: "nvme1n1", dev: "259:1", holders: []string{"dm-0"}},
+				{path: "dm-0", dev: "253:0", dmName: "vg0-lv0", slaves: []string{"nvme0n1", "nvme1n1"}},
+			},
+			mountInfo: `36 22 0:45 / /data rw,relatime shared:2 - btrfs /dev/mapper/vg0-lv0 rw
+`,
+			path:               "/data/a",
+			wantMountPoint:     "/data",
+			wantDevice:         "dm-0",
+			wantDeviceNumber:   "253:0",
+			wantThrottleDevice: []string{"nvme0n1", "nvme1n1"},
+		},
+		{
+			name: "lvm on md raid over nvme partitions",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0"},
+				{path: "nvme0n1/nvme0n1p1", dev: "259:1", holders: []string{"md0"}},
+				{path: "nvme1n1", dev: "259:2"},
+				{path: "nvme1n1/nvme1n1p1", dev: "259:3", holders: []string{"md0"}},
+				{path: "md0", dev: "9:0", isMD: true, slaves: []string{"nvme0n1p1", "nvme1n1p1"}, holders: []string{"dm-0"}},
+				{path: "dm-0", dev: "253:0", dmName: "vg0-lv0", slaves: []string{"md0"}},
+			},
+			mountInfo: `36 22 253:0 / /data rw,relatime shared:2 - ext4 /dev/mapper/vg0-lv0 rw
+`,
+			path:               "/data",
+			wantMountPoint:     "/data",
+			wantDevice:         "dm-0",
+			wantDeviceNumber:   "253:0",
+			wantThrottleDevice: []string{"nvme0n1", "nvme1n1"},
+		},
+		{
+			name: "md raid over nvme partitions",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0"},
+				{path: "nvme0n1/nvme0n1p1", dev: "259:1", holders: []string{"md0"}},
+				{path: "nvme1n1", dev: "259:2"},
+				{path: "nvme1n1/nvme1n1p1", dev: "259:3", holders: []string{"md0"}},
+				{path: "md0", dev: "9:0", isMD: true, slaves: []string{"nvme0n1p1", "nvme1n1p1"}},
+			},
+			mountInfo: `36 22 9:0 / /data rw,relatime shared:2 - xfs /dev/md0 rw
+`,
+			path:               "/data",
+			wantMountPoint:     "/data",
+			wantDevice:         "md0",
+			wantDeviceNumber:   "9:0",
+			wantThrottleDevice: []string{"nvme0n1", "nvme1n1"},
+		},
+		{
+			name: "not a block device mount",
+			devices: []testBlockDevice{
+				{path: "nvme0n1", dev: "259:0"},
+			},
+			mountInfo: `36 22 0:30 / /data rw,relatime shared:2 - tmpfs tmpfs rw
+`,
+			path:    "/data",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := NewFileTestUtil(t)
+			defer helper.Cleanup()
+			oldSysRootDir := Conf.SysRootDir
+			Conf.SysRootDir = path.Join(helper.TempDir, "sys")
+			defer func() {
+				Conf.SysRootDir = oldSysRootDir
+			}()
+			writeTestBlockDevices(helper, tt.devices)
+			helper.WriteFileContents(path.Join("proc", ProcMountInfoName), tt.mountInfo)
+
+			r := NewBlockDeviceResolver()
+			got, err := r.Resolve(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMountPoint, got.MountPoint)
+			assert.Equal(t, tt.wantDevice, got.Device.Name)
+			assert.Equal(t, tt.wantDeviceNumber, got.Device.DeviceNumber())
+			assert.Equal(t, tt.wantThrottleDevice, blockDeviceNames(got.ThrottleDevices))
+		})
+	}
+}
+
+func TestBlockDeviceResolverHotAdd(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = path.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+	writeTestBlockDevices(helper, []testBlockDevice{
+		{path: "nvme0n1", dev: "259:0"},
+		{path: "nvme0n1/nvme0n1p1", dev: "259:1"},
+	})
+	helper.WriteFileContents(path.Join("proc", ProcMountInfoName), `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+`)
+
+	r := NewBlockDeviceResolver()
+	got, err := r.Resolve("/data")
+	assert.NoError(t, err)
+	assert.Equal(t, "nvme0n1p1", got.Device.Name)
+
+	// hot-add a disk and mount the lvm volume on it
+	writeTestBlockDevices(helper, []testBlockDevice{
+		{path: "nvme1n1", dev: "259:2", holders: []string{"dm-0"}},
+		{path: "dm-0", dev: "253:0", dmName: "vg1-data", slaves: []string{"nvme1n1"}},
+	})
+	helper.WriteFileContents(path.Join("proc", ProcMountInfoName), `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+36 22 253:0 / /data rw,relatime shared:2 - xfs /dev/mapper/vg1-data rw
+`)
+	// the topology is reloaded once the mountinfo changes
+	got, err = r.Resolve("/data")
+	assert.NoError(t, err)
+	assert.Equal(t, "/data", got.MountPoint)
+	assert.Equal(t, "dm-0", got.Device.Name)
+	assert.Equal(t, []string{"nvme1n1"}, blockDeviceNames(got.ThrottleDevices))
+
+	topology := r.topology
+	assert.Equal(t, []string{"dm-0"}, blockDeviceNames(topology.GetStackedDevices("nvme1n1")))
+	assert.Empty(t, topology.GetStackedDevices("nvme0n1"))
+}
+
+func TestParseMountInfo(t *testing.T) {
+	content := `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw,errors=remount-ro
+36 22 253:0 / /var/lib/kubelet rw,relatime - ext4 /dev/mapper/vg0-lv0 rw
+malformed line
+`
+	got := ParseMountInfo(content)
+	assert.Equal(t, []MountInfo{
+		{DeviceNumber: "259:1", MountPoint: "/", FSType: "ext4", Source: "/dev/nvme0n1p1"},
+		{DeviceNumber: "253:0", MountPoint: "/var/lib/kubelet", FSType: "ext4", Source: "/dev/mapper/vg0-lv0"},
+	}, got)
+}
+
+func TestBlockDeviceResolverRefresh(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = path.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+	writeTestBlockDevices(helper, []testBlockDevice{
+		{path: "nvme0n1", dev: "259:0"},
+		{path: "nvme0n1/nvme0n1p1", dev: "259:1"},
+	})
+	helper.WriteFileContents(path.Join("proc", ProcMountInfoName), `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+`)
+
+	r := NewBlockDeviceResolver()
+	got, err := r.Resolve("/data")
+	assert.NoError(t, err)
+	assert.Equal(t, "nvme0n1p1", got.Device.Name)
+
+	// the hot-added disk is not loaded while the mounts are unchanged until refreshed
+	writeTestBlockDevices(helper, []testBlockDevice{
+		{path: "nvme1n1", dev: "259:2"},
+	})
+	_, err = r.Resolve("/data")
+	assert.NoError(t, err)
+	assert.Nil(t, r.topology.GetDeviceByNumber("259:2"))
+
+	assert.NoError(t, r.Refresh())
+	assert.NotNil(t, r.topology.GetDeviceByNumber("259:2"))
+}
+
+func TestParseDiskStats(t *testing.T) {
+	content := ` 259       0 nvme0n1 1148 0 85966 233 396 94 46370 321 0 536 554 0 0 0 0
+ 253       0 dm-0 200 0 4000 10 300 0 6000 20 0 30 30 0 0 0 0
+ malformed line
+`
+	got := ParseDiskStats(content)
+	assert.Equal(t, map[string]*DiskStat{
+		"259:0": {ReadIOs: 1148, ReadSectors: 85966, WriteIOs: 396, WriteSectors: 46370},
+		"253:0": {ReadIOs: 200, ReadSectors: 4000, WriteIOs: 300, WriteSectors: 6000},
+	}, got)
+}
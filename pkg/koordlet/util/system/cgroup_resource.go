@@ -101,6 +101,7 @@ const ( // subsystems
 	CgroupCPUAcctDir string = "cpuacct/"
 	CgroupMemDir     string = "memory/"
 	CgroupBlkioDir   string = "blkio/"
+	CgroupPidsDir    string = "pids/"
 
 	CgroupV2Dir = ""
 )
@@ -126,6 +127,8 @@ const (
 
 	CPUSetCPUSName          = "cpuset.cpus"
 	CPUSetCPUSEffectiveName = "cpuset.cpus.effective"
+	CPUSetMemsName          = "cpuset.mems"
+	CPUSetMemsEffectiveName = "cpuset.mems.effective"
 
 	CPUAcctStatName           = "cpuacct.stat"
 	CPUAcctUsageName          = "cpuacct.usage"
@@ -147,11 +150,16 @@ const (
 	MemoryPriorityName         = "memory.priority"
 	MemoryUsePriorityOomName   = "memory.use_priority_oom"
 	MemoryOomGroupName         = "memory.oom.group"
+	MemoryOomControlName       = "memory.oom_control"
+	MemoryEventsName           = "memory.events" // cgroups-v2
 
 	BlkioTRIopsName = "blkio.throttle.read_iops_device"
 	BlkioTRBpsName  = "blkio.throttle.read_bps_device"
 	BlkioTWIopsName = "blkio.throttle.write_iops_device"
 	BlkioTWBpsName  = "blkio.throttle.write_bps_device"
+
+	PidsCurrentName = "pids.current"
+	PidsMaxName     = "pids.max"
 )
 
 var (
@@ -169,6 +177,7 @@ var (
 	MemoryWmarkScaleFactorFileNameValidator = &RangeValidator{min: 1, max: 1000}
 
 	CPUSetCPUSValidator = &CPUSetStrValidator{}
+	CPUSetMemsValidator = &CPUSetStrValidator{}
 )
 
 // for cgroup resources, we use the corresponding cgroups-v1 filename as its resource type
@@ -184,7 +193,8 @@ var (
 	CPUTasks     = DefaultFactory.New(CPUTasksName, CgroupCPUDir)
 	CPUProcs     = DefaultFactory.New(CPUProcsName, CgroupCPUDir)
 
-	CPUSet = DefaultFactory.New(CPUSetCPUSName, CgroupCPUSetDir).WithValidator(CPUSetCPUSValidator)
+	CPUSet     = DefaultFactory.New(CPUSetCPUSName, CgroupCPUSetDir).WithValidator(CPUSetCPUSValidator)
+	CPUSetMems = DefaultFactory.New(CPUSetMemsName, CgroupCPUSetDir).WithValidator(CPUSetMemsValidator)
 
 	CPUAcctStat           = DefaultFactory.New(CPUAcctStatName, CgroupCPUAcctDir)
 	CPUAcctUsage          = DefaultFactory.New(CPUAcctUsageName, CgroupCPUAcctDir)
@@ -204,12 +214,16 @@ var (
 	MemoryPriority         = DefaultFactory.New(MemoryPriorityName, CgroupMemDir).WithValidator(MemoryPriorityValidator).WithSupported(SupportedIfFileExistsInKubepods(MemoryPriorityName, CgroupMemDir))
 	MemoryUsePriorityOom   = DefaultFactory.New(MemoryUsePriorityOomName, CgroupMemDir).WithValidator(MemoryUsePriorityOomValidator).WithSupported(SupportedIfFileExistsInKubepods(MemoryUsePriorityOomName, CgroupMemDir))
 	MemoryOomGroup         = DefaultFactory.New(MemoryOomGroupName, CgroupMemDir).WithValidator(MemoryOomGroupValidator).WithSupported(SupportedIfFileExistsInKubepods(MemoryOomGroupName, CgroupMemDir))
+	MemoryOomControl       = DefaultFactory.New(MemoryOomControlName, CgroupMemDir)
 
 	BlkioReadIops  = DefaultFactory.New(BlkioTRIopsName, CgroupBlkioDir) // TODO: add validator for blkio.throttle
 	BlkioReadBps   = DefaultFactory.New(BlkioTRBpsName, CgroupBlkioDir)
 	BlkioWriteIops = DefaultFactory.New(BlkioTWIopsName, CgroupBlkioDir)
 	BlkioWriteBps  = DefaultFactory.New(BlkioTWBpsName, CgroupBlkioDir)
 
+	PidsCurrent = DefaultFactory.New(PidsCurrentName, CgroupPidsDir)
+	PidsMax     = DefaultFactory.New(PidsMaxName, CgroupPidsDir)
+
 	knownCgroupResources = []Resource{
 		CPUStat,
 		CPUShares,
@@ -217,8 +231,10 @@ var (
 		CPUCFSPeriod,
 		CPUBurst,
 		CPUTasks,
+		CPUProcs,
 		CPUBVTWarpNs,
 		CPUSet,
+		CPUSetMems,
 		CPUAcctStat,
 		CPUAcctUsage,
 		CPUAcctCPUPressure,
@@ -236,10 +252,13 @@ var (
 		MemoryPriority,
 		MemoryUsePriorityOom,
 		MemoryOomGroup,
+		MemoryOomControl,
 		BlkioReadIops,
 		BlkioReadBps,
 		BlkioWriteIops,
 		BlkioWriteBps,
+		PidsCurrent,
+		PidsMax,
 	}
 
 	CPUCFSQuotaV2            = DefaultFactory.NewV2(CPUCFSQuotaName, CPUMaxName)
@@ -250,6 +269,8 @@ var (
 	CPUAcctUsageV2           = DefaultFactory.NewV2(CPUAcctUsageName, CPUStatName)
 	CPUSetV2                 = DefaultFactory.NewV2(CPUSetCPUSName, CPUSetCPUSName).WithValidator(CPUSetCPUSValidator)
 	CPUSetEffectiveV2        = DefaultFactory.NewV2(CPUSetCPUSEffectiveName, CPUSetCPUSEffectiveName) // TODO: unify the R/W
+	CPUSetMemsV2             = DefaultFactory.NewV2(CPUSetMemsName, CPUSetMemsName).WithValidator(CPUSetMemsValidator)
+	CPUSetMemsEffectiveV2    = DefaultFactory.NewV2(CPUSetMemsEffectiveName, CPUSetMemsEffectiveName)
 	CPUTasksV2               = DefaultFactory.NewV2(CPUTasksName, CPUThreadsName)
 	CPUProcsV2               = DefaultFactory.NewV2(CPUProcsName, CPUProcsName)
 	MemoryLimitV2            = DefaultFactory.NewV2(MemoryLimitName, MemoryMaxName)
@@ -264,6 +285,9 @@ var (
 	MemoryPriorityV2         = DefaultFactory.NewV2(MemoryPriorityName, MemoryPriorityName).WithValidator(MemoryPriorityValidator).WithCheckSupported(SupportedIfFileExists)
 	MemoryUsePriorityOomV2   = DefaultFactory.NewV2(MemoryUsePriorityOomName, MemoryUsePriorityOomName).WithValidator(MemoryUsePriorityOomValidator).WithCheckSupported(SupportedIfFileExists)
 	MemoryOomGroupV2         = DefaultFactory.NewV2(MemoryOomGroupName, MemoryOomGroupName).WithValidator(MemoryOomGroupValidator).WithCheckSupported(SupportedIfFileExists)
+	MemoryOomControlV2       = DefaultFactory.NewV2(MemoryOomControlName, MemoryEventsName)
+	PidsCurrentV2            = DefaultFactory.NewV2(PidsCurrentName, PidsCurrentName)
+	PidsMaxV2                = DefaultFactory.NewV2(PidsMaxName, PidsMaxName)
 
 	knownCgroupV2Resources = []Resource{
 		CPUCFSQuotaV2,
@@ -274,6 +298,8 @@ var (
 		CPUAcctUsageV2,
 		CPUSetV2,
 		CPUSetEffectiveV2,
+		CPUSetMemsV2,
+		CPUSetMemsEffectiveV2,
 		CPUTasksV2,
 		CPUProcsV2,
 		MemoryLimitV2,
@@ -288,6 +314,9 @@ var (
 		MemoryPriorityV2,
 		MemoryUsePriorityOomV2,
 		MemoryOomGroupV2,
+		MemoryOomControlV2,
+		PidsCurrentV2,
+		PidsMaxV2,
 	}
 )
 
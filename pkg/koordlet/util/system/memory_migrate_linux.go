@@ -0,0 +1,60 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// MigratePages moves the pages of the process on the source NUMA nodes to the target NUMA nodes by the syscall
+// migrate_pages(2). It returns the number of pages which could not be moved.
+func MigratePages(pid int32, fromNodes, toNodes []int) (int, error) {
+	if len(fromNodes) <= 0 || len(toNodes) <= 0 {
+		return 0, fmt.Errorf("empty NUMA nodes, from %v, to %v", fromNodes, toNodes)
+	}
+	maxNode := 0
+	for _, node := range append(append([]int{}, fromNodes...), toNodes...) {
+		if node < 0 {
+			return 0, fmt.Errorf("invalid NUMA node %v", node)
+		}
+		if node > maxNode {
+			maxNode = node
+		}
+	}
+	words := maxNode/64 + 1
+	fromMask, toMask := make([]uint64, words), make([]uint64, words)
+	for _, node := range fromNodes {
+		fromMask[node/64] |= 1 << uint(node%64)
+	}
+	for _, node := range toNodes {
+		toMask[node/64] |= 1 << uint(node%64)
+	}
+
+	// the kernel reads maxnode-1 bits of the masks
+	notMoved, _, errno := unix.Syscall6(unix.SYS_MIGRATE_PAGES, uintptr(pid), uintptr(words*64+1),
+		uintptr(unsafe.Pointer(&fromMask[0])), uintptr(unsafe.Pointer(&toMask[0])), 0, 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(notMoved), nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const ProcNUMAMapsName = "numa_maps"
+
+// GetProcPIDNUMAMapsPath returns the path of `/proc/<pid>/numa_maps`.
+func GetProcPIDNUMAMapsPath(pid int32) string {
+	return GetProcFilePath(filepath.Join(strconv.FormatInt(int64(pid), 10), ProcNUMAMapsName))
+}
+
+// ReadProcNUMAMaps returns the resident memory in kB on each NUMA node of the process.
+func ReadProcNUMAMaps(pid int32) (map[int]int64, error) {
+	content, err := os.ReadFile(GetProcPIDNUMAMapsPath(pid))
+	if err != nil {
+		return nil, err
+	}
+	return ParseNUMAMaps(string(content))
+}
+
+// ParseNUMAMaps parses the content of the numa_maps and sums the resident memory in kB on each NUMA node.
+// e.g. `7f2e4c000000 default anon=3 dirty=3 N0=2 N1=1 kernelpagesize_kB=4`
+func ParseNUMAMaps(content string) (map[int]int64, error) {
+	residentKB := map[int]int64{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		pageSizeKB := int64(4)
+		nodePages := map[int]int64{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			if kv[0] == "kernelpagesize_kB" {
+				v, err := strconv.ParseInt(kv[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse page size %s, err: %v", field, err)
+				}
+				pageSizeKB = v
+				continue
+			}
+			if len(kv[0]) < 2 || kv[0][0] != 'N' {
+				continue
+			}
+			node, err := strconv.Atoi(kv[0][1:])
+			if err != nil {
+				continue
+			}
+			pages, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse pages %s, err: %v", field, err)
+			}
+			nodePages[node] += pages
+		}
+		for node, pages := range nodePages {
+			residentKB[node] += pages * pageSizeKB
+		}
+	}
+	return residentKB, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNUMAMaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[int]int64
+		wantErr bool
+	}{
+		{
+			name:    "empty content",
+			content: "",
+			want:    map[int]int64{},
+		},
+		{
+			name: "parse successfully",
+			content: `55a8c5a00000 default file=/usr/bin/app mapped=10 active=0 N0=10 kernelpagesize_kB=4
+55a8c7400000 default heap anon=300 dirty=300 N0=100 N1=200 kernelpagesize_kB=4
+7f2e40000000 default anon=2 dirty=2 N1=2 kernelpagesize_kB=2048
+7ffd1dbf2000 default stack anon=1 dirty=1 N1=1 kernelpagesize_kB=4
+`,
+			want: map[int]int64{
+				0: 440,
+				1: 800 + 4096 + 4,
+			},
+		},
+		{
+			name:    "invalid pages",
+			content: "55a8c7400000 default heap anon=300 N0=x kernelpagesize_kB=4",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := ParseNUMAMaps(tt.content)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadProcNUMAMaps(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	_, err := ReadProcNUMAMaps(1000)
+	assert.Error(t, err)
+
+	helper.WriteProcSubFileContents("1000/numa_maps", "55a8c7400000 default heap anon=3 N0=1 N1=2 kernelpagesize_kB=4\n")
+	got, err := ReadProcNUMAMaps(1000)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int64{0: 4, 1: 8}, got)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	// OOMKillKey is the key of the OOM kill count in `memory.oom_control` on cgroups-v1 and `memory.events` on
+	// cgroups-v2, e.g. `oom_kill 3`.
+	OOMKillKey = "oom_kill"
+
+	// kernelOOMKillPrefix is the prefix of the kernel log message of an OOM kill, e.g.
+	// `oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=...,oom_memcg=...,task_memcg=...,task=...,pid=...,uid=...`
+	kernelOOMKillPrefix = "oom-kill:"
+	kernelTaskMemcgKey  = "task_memcg="
+)
+
+// KmsgFilePath is the path of the kernel log device.
+var KmsgFilePath = "/dev/kmsg"
+
+// ParseMemoryOOMKillCount parses the number of the tasks OOM-killed in the memory cgroup.
+func ParseMemoryOOMKillCount(content string) (int64, error) {
+	v, ok := ParseKVMap(content)[OOMKillKey]
+	if !ok {
+		return 0, fmt.Errorf("%s not found", OOMKillKey)
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// ParseKernelOOMKillMemcg parses the memory cgroup of the task killed from a kernel log record of an OOM kill.
+// The record can be in the format of `/dev/kmsg`, i.e. `<priority>,<sequence>,<timestamp>,<flags>;<message>`.
+func ParseKernelOOMKillMemcg(record string) (string, bool) {
+	message := record
+	if i := strings.IndexByte(record, ';'); i >= 0 && !strings.HasPrefix(record, kernelOOMKillPrefix) {
+		message = record[i+1:]
+	}
+	if !strings.HasPrefix(message, kernelOOMKillPrefix) {
+		return "", false
+	}
+	for _, field := range strings.Split(strings.TrimPrefix(message, kernelOOMKillPrefix), ",") {
+		if strings.HasPrefix(field, kernelTaskMemcgKey) {
+			memcg := strings.TrimSpace(strings.TrimPrefix(field, kernelTaskMemcgKey))
+			return memcg, memcg != ""
+		}
+	}
+	return "", false
+}
+
+// KernelOOMKillReader reads the OOM kills from the kernel log incrementally.
+type KernelOOMKillReader struct {
+	path string
+	file *os.File
+	// partial is the incomplete line left by the last read.
+	partial string
+}
+
+func NewKernelOOMKillReader(path string) *KernelOOMKillReader {
+	return &KernelOOMKillReader{path: path}
+}
+
+// ReadOOMKillMemcgs returns the memory cgroups of the tasks OOM-killed logged since the last read. The records logged
+// before the first read are skipped, since they could have been counted by the previous process.
+func (r *KernelOOMKillReader) ReadOOMKillMemcgs() ([]string, error) {
+	if r.file == nil {
+		file, err := os.OpenFile(r.path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = file.Seek(0, io.SeekEnd); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		r.file = file
+		return nil, nil
+	}
+
+	var memcgs []string
+	buf := make([]byte, 8192)
+	for {
+		n, err := r.file.Read(buf)
+		if n > 0 {
+			lines := strings.Split(r.partial+string(buf[:n]), "\n")
+			r.partial = lines[len(lines)-1]
+			for _, line := range lines[:len(lines)-1] {
+				if memcg, ok := ParseKernelOOMKillMemcg(line); ok {
+					memcgs = append(memcgs, memcg)
+				}
+			}
+		}
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, syscall.EPIPE) {
+			// the records are overwritten before read, continue with the next records
+			continue
+		}
+		if err == io.EOF || errors.Is(err, syscall.EAGAIN) {
+			return memcgs, nil
+		}
+		return memcgs, err
+	}
+}
+
+// Close closes the kernel log.
+func (r *KernelOOMKillReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	r.partial = ""
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseMemoryOOMKillCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "parse cgroups-v1 memory.oom_control",
+			content: "oom_kill_disable 0\nunder_oom 0\noom_kill 3\n",
+			want:    3,
+		},
+		{
+			name:    "parse cgroups-v2 memory.events",
+			content: "low 0\nhigh 0\nmax 10\noom 2\noom_kill 1\noom_group_kill 0\n",
+			want:    1,
+		},
+		{
+			name:    "oom_kill missing on the old kernels",
+			content: "oom_kill_disable 0\nunder_oom 0\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid oom_kill",
+			content: "oom_kill unknown\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMemoryOOMKillCount(tt.content)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_ParseKernelOOMKillMemcg(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "parse kmsg record",
+			record: "6,1234,5678901,-;oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=abc,mems_allowed=0,oom_memcg=/kubepods/besteffort/pod-1,task_memcg=/kubepods/besteffort/pod-1/abc,task=stress,pid=100,uid=0",
+			want:   "/kubepods/besteffort/pod-1/abc",
+			wantOK: true,
+		},
+		{
+			name:   "parse message",
+			record: "oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/kubepods/burstable/pod-2,task=java,pid=200,uid=0",
+			want:   "/kubepods/burstable/pod-2",
+			wantOK: true,
+		},
+		{
+			name:   "not an oom kill",
+			record: "6,1235,5678902,-;Memory cgroup out of memory: Killed process 100 (stress)",
+		},
+		{
+			name:   "task_memcg missing",
+			record: "6,1236,5678903,-;oom-kill:constraint=CONSTRAINT_MEMCG,task=stress,pid=100,uid=0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOK := ParseKernelOOMKillMemcg(tt.record)
+			assert.Equal(t, tt.wantOK, gotOK)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_KernelOOMKillReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kmsg")
+	assert.NoError(t, os.WriteFile(path, []byte("6,1,1,-;oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/kubepods/besteffort/pod-0,task=a,pid=1,uid=0\n"), 0644))
+	appendRecords := func(records string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		assert.NoError(t, err)
+		_, err = f.WriteString(records)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+	}
+
+	r := NewKernelOOMKillReader(path)
+	defer r.Close()
+	// the records logged before the first read are skipped
+	got, err := r.ReadOOMKillMemcgs()
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	appendRecords("6,2,2,-;oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/kubepods/besteffort/pod-1,task=a,pid=2,uid=0\n" +
+		"6,3,3,-;Memory cgroup out of memory: Killed process 2 (a)\n" +
+		"6,4,4,-;oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/kubepods/besteffort/pod-2")
+	got, err = r.ReadOOMKillMemcgs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/kubepods/besteffort/pod-1"}, got)
+
+	// the incomplete record is read with the rest of it
+	appendRecords(",task=b,pid=3,uid=0\n")
+	got, err = r.ReadOOMKillMemcgs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/kubepods/besteffort/pod-2"}, got)
+
+	got, err = r.ReadOOMKillMemcgs()
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = NewKernelOOMKillReader(filepath.Join(t.TempDir(), "not-exist")).ReadOOMKillMemcgs()
+	assert.True(t, os.IsNotExist(err))
+}
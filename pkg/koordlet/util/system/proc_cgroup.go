@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const ProcCgroupName = "cgroup"
+
+// GetProcPIDCgroupPath returns the path of `/proc/<pid>/cgroup`.
+func GetProcPIDCgroupPath(pid uint32) string {
+	return GetProcFilePath(filepath.Join(strconv.FormatUint(uint64(pid), 10), ProcCgroupName))
+}
+
+// ReadProcCgroupDir returns the cgroup dir of the process in the cpu subsystem, or in the unified hierarchy on the
+// cgroup v2. It returns the os.ErrNotExist error if the process has exited.
+func ReadProcCgroupDir(pid uint32) (string, error) {
+	content, err := os.ReadFile(GetProcPIDCgroupPath(pid))
+	if err != nil {
+		return "", err
+	}
+	return ParseProcCgroupDir(string(content))
+}
+
+// ParseProcCgroupDir parses the content of the `/proc/<pid>/cgroup`.
+// e.g. `4:cpu,cpuacct:/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-xxx.scope`
+func ParseProcCgroupDir(content string) (string, error) {
+	unifiedDir := ""
+	for _, line := range strings.Split(content, "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[1] == "" {
+			unifiedDir = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			if controller == "cpu" {
+				return fields[2], nil
+			}
+		}
+	}
+	if unifiedDir != "" {
+		return unifiedDir, nil
+	}
+	return "", fmt.Errorf("cpu cgroup not found in %q", content)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseProcCgroupDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "cgroup v1",
+			content: `12:memory:/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-abc.scope
+4:cpu,cpuacct:/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-abc.scope
+1:name=systemd:/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-abc.scope
+`,
+			want: "/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/docker-abc.scope",
+		},
+		{
+			name:    "cgroup v2",
+			content: "0::/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/cri-containerd-abc.scope\n",
+			want:    "/kubepods.slice/kubepods-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/cri-containerd-abc.scope",
+		},
+		{
+			name:    "cpu cgroup not found",
+			content: "12:memory:/user.slice\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProcCgroupDir(tt.content)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ReadProcCgroupDir(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	helper.WriteProcSubFileContents("100/cgroup", "4:cpu,cpuacct:/kubepods.slice/docker-abc.scope\n")
+	got, err := ReadProcCgroupDir(100)
+	assert.NoError(t, err)
+	assert.Equal(t, "/kubepods.slice/docker-abc.scope", got)
+
+	_, err = ReadProcCgroupDir(200)
+	assert.True(t, os.IsNotExist(err), err)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProcLoadAvgName = "loadavg"
+	ProcPIDFDDir    = "fd"
+
+	KernelPIDMax = "kernel/pid_max"
+	FsFileNr     = "fs/file-nr"
+)
+
+// GetProcPIDFDDir returns the path of `/proc/<pid>/fd`.
+func GetProcPIDFDDir(pid uint32) string {
+	return GetProcFilePath(filepath.Join(strconv.FormatUint(uint64(pid), 10), ProcPIDFDDir))
+}
+
+// CountProcessFDs returns the number of the open file descriptors of the process.
+// It returns the os.ErrNotExist error if the process has exited.
+func CountProcessFDs(pid uint32) (int64, error) {
+	entries, err := os.ReadDir(GetProcPIDFDDir(pid))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(entries)), nil
+}
+
+// ReadNodeTaskCount returns the number of the tasks, i.e. the processes and the threads, on the node.
+// The format of the loadavg is "0.10 0.20 0.30 <running>/<total> <last pid>".
+func ReadNodeTaskCount() (int64, error) {
+	path := GetProcFilePath(ProcLoadAvgName)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("%s is illegally formatted: %s", path, string(content))
+	}
+	tasks := strings.SplitN(fields[3], "/", 2)
+	if len(tasks) != 2 {
+		return 0, fmt.Errorf("%s is illegally formatted: %s", path, string(content))
+	}
+	total, err := strconv.ParseInt(tasks[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the tasks of %s, err: %v", path, err)
+	}
+	return total, nil
+}
+
+// ReadPIDMax returns the max pid of the node, the tasks on the node are limited by it.
+func ReadPIDMax() (int64, error) {
+	path := GetProcSysFilePath(KernelPIDMax)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pidMax, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s, err: %v", path, err)
+	}
+	return pidMax, nil
+}
+
+// ReadFileNr returns the number of the allocated file handles and the max file handles of the node.
+// The format of the file-nr is "<allocated> <unused> <max>".
+func ReadFileNr() (int64, int64, error) {
+	path := GetProcSysFilePath(FsFileNr)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 3 {
+		return 0, 0, fmt.Errorf("%s is illegally formatted: %s", path, string(content))
+	}
+	values := make([]int64, len(fields))
+	for i := range fields {
+		values[i], err = strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse %s, err: %v", path, err)
+		}
+	}
+	return values[0] - values[1], values[2], nil
+}
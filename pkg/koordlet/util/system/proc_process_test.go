@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CountProcessFDs(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	for _, fd := range []string{"0", "1", "2", "5"} {
+		helper.WriteProcSubFileContents("100/fd/"+fd, "")
+	}
+
+	got, err := CountProcessFDs(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), got)
+
+	_, err = CountProcessFDs(101)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_ReadNodeTaskCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "read tasks",
+			content: "0.52 0.58 0.59 3/1288 123456\n",
+			want:    1288,
+		},
+		{
+			name:    "illegal format",
+			content: "0.52 0.58 0.59\n",
+			wantErr: true,
+		},
+		{
+			name:    "illegal tasks",
+			content: "0.52 0.58 0.59 1288 123456\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.WriteProcSubFileContents(ProcLoadAvgName, tt.content)
+
+			got, err := ReadNodeTaskCount()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_ReadPIDMaxAndFileNr(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	_, err := ReadPIDMax()
+	assert.Error(t, err)
+	_, _, err = ReadFileNr()
+	assert.Error(t, err)
+
+	helper.WriteProcSubFileContents(SysctlSubDir+"/"+KernelPIDMax, "32768\n")
+	helper.WriteProcSubFileContents(SysctlSubDir+"/"+FsFileNr, "12000\t200\t9223372036854775807\n")
+	pidMax, err := ReadPIDMax()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(32768), pidMax)
+	allocated, fileMax, err := ReadFileNr()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11800), allocated)
+	assert.Equal(t, int64(9223372036854775807), fileMax)
+
+	helper.WriteProcSubFileContents(SysctlSubDir+"/"+FsFileNr, "12000 200\n")
+	_, _, err = ReadFileNr()
+	assert.Error(t, err)
+}
@@ -43,6 +43,13 @@ const (
 	ResctrlCbmMaskName  string = "cbm_mask"
 	ResctrlTasksName    string = "tasks"
 
+	// ResctrlMonDataDir is the dir of the monitoring data of a resctrl group
+	ResctrlMonDataDir string = "mon_data"
+	// ResctrlMonL3DirPrefix is the prefix of the monitoring dirs of each L3 domain, e.g. mon_L3_00
+	ResctrlMonL3DirPrefix string = "mon_L3_"
+	// ResctrlMbmTotalBytesName is the counter of the total memory bandwidth of a resctrl group on a L3 domain
+	ResctrlMbmTotalBytesName string = "mbm_total_bytes"
+
 	// L3SchemataPrefix is the prefix of l3 cat schemata
 	L3SchemataPrefix = "L3"
 	// MbSchemataPrefix is the prefix of mba schemata
@@ -448,6 +455,42 @@ func ReadResctrlTasksMap(groupPath string) (map[int32]struct{}, error) {
 	return tasksMap, nil
 }
 
+// ReadResctrlMbmTotalBytes reads the mbm_total_bytes counters of the given resctrl group on each L3 domain.
+// e.g. /sys/fs/resctrl/BE/mon_data/mon_L3_01/mbm_total_bytes -> {1: 123456}
+// The domains whose counters are unavailable are skipped.
+func ReadResctrlMbmTotalBytes(groupPath string) (map[int]uint64, error) {
+	monDataDir := filepath.Join(GetResctrlGroupRootDirPath(groupPath), ResctrlMonDataDir)
+	entries, err := os.ReadDir(monDataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := map[int]uint64{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), ResctrlMonL3DirPrefix) {
+			continue
+		}
+		l3ID, err := strconv.ParseInt(strings.TrimPrefix(entry.Name(), ResctrlMonL3DirPrefix), 10, 32)
+		if err != nil {
+			klog.V(6).Infof("failed to parse resctrl mon dir %s, err: %s", entry.Name(), err)
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(monDataDir, entry.Name(), ResctrlMbmTotalBytesName))
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+		if err != nil {
+			// the counter is "Unavailable" when the hardware fails to read it
+			klog.V(6).Infof("skip the unavailable mbm counter of group %s on L3 %d, content %s",
+				groupPath, l3ID, string(content))
+			continue
+		}
+		counters[int(l3ID)] = value
+	}
+	return counters, nil
+}
+
 // CheckAndTryEnableResctrlCat checks if resctrl and l3_cat are enabled; if not, try to enable the features by mount
 // resctrl subsystem; See MountResctrlSubsystem() for the detail.
 // It returns whether the resctrl cat is enabled, and the error if failed to enable or to check resctrl interfaces
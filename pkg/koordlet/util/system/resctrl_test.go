@@ -96,6 +96,58 @@ func Test_ReadResctrlTasksMap(t *testing.T) {
 	}
 }
 
+func Test_ReadResctrlMbmTotalBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		counters map[string]string
+		want     map[int]uint64
+		wantErr  bool
+	}{
+		{
+			name:    "mon data not exist",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse correctly",
+			counters: map[string]string{
+				"mon_L3_00": "1000\n",
+				"mon_L3_01": "2000\n",
+			},
+			want:    map[int]uint64{0: 1000, 1: 2000},
+			wantErr: false,
+		},
+		{
+			name: "skip unavailable counter",
+			counters: map[string]string{
+				"mon_L3_00": "Unavailable\n",
+				"mon_L3_01": "2000\n",
+			},
+			want:    map[int]uint64{1: 2000},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sysFSRootDir := t.TempDir()
+			Conf = &Config{
+				SysFSRootDir: sysFSRootDir,
+			}
+			for dir, content := range tt.counters {
+				monDir := filepath.Join(sysFSRootDir, ResctrlDir, "BE", ResctrlMonDataDir, dir)
+				err := os.MkdirAll(monDir, 0700)
+				assert.NoError(t, err)
+				err = os.WriteFile(filepath.Join(monDir, ResctrlMbmTotalBytesName), []byte(content), 0666)
+				assert.NoError(t, err)
+			}
+
+			got, err := ReadResctrlMbmTotalBytes("BE")
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestResctrlSchemataRaw(t *testing.T) {
 	type fields struct {
 		l3Num     int
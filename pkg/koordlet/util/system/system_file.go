@@ -35,6 +35,10 @@ const (
 	ProcMemInfoName = "meminfo"
 	SysctlSubDir    = "sys"
 
+	SysBlockSubDir = "block"
+	// ZramMMStatName is the memory statistics of a zram device under /sys/block/zram<id>/
+	ZramMMStatName = "mm_stat"
+
 	KernelSchedGroupIdentityEnable = "kernel/sched_group_identity_enabled"
 )
 
@@ -93,6 +97,10 @@ func GetProcFilePath(file string) string {
 	return filepath.Join(Conf.ProcRootDir, file)
 }
 
+func GetSysBlockFilePath(file string) string {
+	return filepath.Join(Conf.SysRootDir, SysBlockSubDir, file)
+}
+
 func GetProcSysFilePath(file string) string {
 	return filepath.Join(Conf.ProcRootDir, SysctlSubDir, file)
 }
@@ -25,6 +25,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
 )
 
 var (
@@ -56,6 +58,13 @@ var (
 		},
 	}
 
+	defaultDeviceShareScoringResources = []schedconfig.ResourceSpec{
+		{Name: string(extension.GPUCore), Weight: 1},
+		{Name: string(extension.GPUMemoryRatio), Weight: 1},
+		{Name: string(extension.KoordRDMA), Weight: 1},
+		{Name: string(extension.KoordFPGA), Weight: 1},
+	}
+
 	defaultEnablePreemption = pointer.Bool(false)
 
 	defaultDelayEvictTime       = 120 * time.Second
@@ -75,6 +84,25 @@ var (
 	defaultMonitorAllQuotas       = pointer.Bool(false)
 	defaultEnableCheckParentQuota = pointer.Bool(false)
 
+	defaultMetricsQuotaGroupBuckets int32 = 16
+
+	defaultReleaseTerminatedPods = pointer.Bool(true)
+
+	// keep consistent with retry.DefaultBackoff
+	defaultPatchRetryMaxAttempts    int32 = 4
+	defaultPatchRetryInitialBackoff       = 10 * time.Millisecond
+	defaultPatchRetryBackoffFactor        = 5.0
+
+	defaultWaitlistMinGPUs      int32 = 4
+	defaultWaitlistHoldDuration       = 5 * time.Minute
+
+	defaultDeviceReconcileStrategy  = DeviceReconcileConservativeMin
+	defaultDeviceNUMATopologyPolicy = DeviceNUMATopologyBestEffort
+	defaultDeviceGPUTopologyPolicy  = DeviceGPUTopologyBestEffort
+
+	defaultGPUCoreGranularity     int32 = 5
+	defaultLocalityAffinityWeight int64 = 100
+
 	defaultTimeout           = 600 * time.Second
 	defaultControllerWorkers = 1
 )
@@ -146,6 +174,60 @@ func SetDefaults_ElasticQuotaArgs(obj *ElasticQuotaArgs) {
 	if obj.EnableCheckParentQuota == nil {
 		obj.EnableCheckParentQuota = defaultEnableCheckParentQuota
 	}
+	if obj.MetricsQuotaGroupBuckets == nil {
+		obj.MetricsQuotaGroupBuckets = pointer.Int32(defaultMetricsQuotaGroupBuckets)
+	}
+}
+
+func SetDefaults_DeviceShareArgs(obj *DeviceShareArgs) {
+	if obj.ReleaseTerminatedPods == nil {
+		obj.ReleaseTerminatedPods = defaultReleaseTerminatedPods
+	}
+	if obj.PreBindPatchRetry == nil {
+		obj.PreBindPatchRetry = &PatchRetryPolicy{}
+	}
+	if obj.PreBindPatchRetry.MaxAttempts == nil {
+		obj.PreBindPatchRetry.MaxAttempts = pointer.Int32(defaultPatchRetryMaxAttempts)
+	}
+	if obj.PreBindPatchRetry.InitialBackoff == nil {
+		obj.PreBindPatchRetry.InitialBackoff = &metav1.Duration{Duration: defaultPatchRetryInitialBackoff}
+	}
+	if obj.PreBindPatchRetry.BackoffFactor == nil {
+		factor := defaultPatchRetryBackoffFactor
+		obj.PreBindPatchRetry.BackoffFactor = &factor
+	}
+	if obj.Waitlist != nil {
+		if obj.Waitlist.MinGPUs == nil {
+			obj.Waitlist.MinGPUs = pointer.Int32(defaultWaitlistMinGPUs)
+		}
+		if obj.Waitlist.HoldDuration == nil {
+			obj.Waitlist.HoldDuration = &metav1.Duration{Duration: defaultWaitlistHoldDuration}
+		}
+	}
+	if obj.ReconcileStrategy == "" {
+		obj.ReconcileStrategy = defaultDeviceReconcileStrategy
+	}
+	if obj.GPUCoreGranularity == nil {
+		obj.GPUCoreGranularity = pointer.Int32(defaultGPUCoreGranularity)
+	}
+	if obj.LocalityAffinity != nil && obj.LocalityAffinity.Weight == nil {
+		obj.LocalityAffinity.Weight = pointer.Int64(defaultLocalityAffinityWeight)
+	}
+	if obj.ScoringStrategy == nil {
+		obj.ScoringStrategy = &ScoringStrategy{Type: MostAllocated}
+	}
+	if len(obj.ScoringStrategy.Resources) == 0 {
+		obj.ScoringStrategy.Resources = append([]schedconfig.ResourceSpec{}, defaultDeviceShareScoringResources...)
+	}
+	if obj.EnablePreemption == nil {
+		obj.EnablePreemption = defaultEnablePreemption
+	}
+	if obj.NUMATopologyPolicy == "" {
+		obj.NUMATopologyPolicy = defaultDeviceNUMATopologyPolicy
+	}
+	if obj.GPUTopologyPolicy == "" {
+		obj.GPUTopologyPolicy = defaultDeviceGPUTopologyPolicy
+	}
 }
 
 func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
@@ -22,6 +22,7 @@ import (
 	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 )
 
@@ -55,6 +56,10 @@ type LoadAwareSchedulingArgs struct {
 	EstimatedScalingFactors map[corev1.ResourceName]int64 `json:"estimatedScalingFactors,omitempty"`
 	// Aggregated supports resource utilization filtering and scoring based on percentile statistics
 	Aggregated *LoadAwareSchedulingAggregatedArgs `json:"aggregated,omitempty"`
+	// DeschedulingHistoryTTL indicates how long the node a workload was descheduled from is scored the lowest
+	// for the pods of the workload, so that the replacements are not placed back on the node right away.
+	// Not enabled by default
+	DeschedulingHistoryTTL *metav1.Duration `json:"deschedulingHistoryTTL,omitempty"`
 }
 
 type LoadAwareSchedulingAggregatedArgs struct {
@@ -176,6 +181,13 @@ type ElasticQuotaArgs struct {
 
 	// EnableCheckParentQuota check parentQuotaGroups' used and runtime Quota in PreFilter
 	EnableCheckParentQuota *bool `json:"enableCheckParentQuota,omitempty"`
+
+	// MetricsQuotaGroups are the quotaGroups labeled by name in the metrics besides the default and system quotaGroups,
+	// the other quotaGroups are labeled by the hash buckets of their names to bound the cardinality of the metrics
+	MetricsQuotaGroups []string `json:"metricsQuotaGroups,omitempty"`
+
+	// MetricsQuotaGroupBuckets is the number of the hash buckets labeling the quotaGroups not in MetricsQuotaGroups
+	MetricsQuotaGroupBuckets *int32 `json:"metricsQuotaGroupBuckets,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -200,4 +212,135 @@ type DeviceShareArgs struct {
 
 	// Allocator indicates the expected allocator to use
 	Allocator string `json:"allocator,omitempty"`
+	// LocalityAffinity prefers the nodes already running the pods matching the selector,
+	// e.g. co-locating the GPU consumer pods with the data-producer pods.
+	LocalityAffinity *DeviceLocalityAffinity `json:"localityAffinity,omitempty"`
+	// MinResourcesPerGPU is the minimum CPU and memory a pod must request for each GPU it requests.
+	// The pods requesting less are rejected in PreFilter. No minimum is enforced if empty.
+	MinResourcesPerGPU corev1.ResourceList `json:"minResourcesPerGPU,omitempty"`
+	// ReleaseTerminatedPods indicates whether to release the devices of the Succeeded or Failed pods, and of the
+	// pods past their deletion grace period, before the pods are deleted. Defaults to true.
+	ReleaseTerminatedPods *bool `json:"releaseTerminatedPods,omitempty"`
+	// PreBindPatchRetry configures how to retry patching the device allocations to the pod in PreBind
+	// when the API server responds with conflicts or throttling, or the device allocations read back from
+	// the patched pod are not the allocated ones. Defaults to retry.DefaultBackoff if nil.
+	PreBindPatchRetry *PatchRetryPolicy `json:"preBindPatchRetry,omitempty"`
+	// Waitlist lets the pending pods requesting many whole GPUs hold the freeing GPUs of a node for a bounded time,
+	// so they are not starved by the smaller pods. It takes effect only if the PostFilter extension point of the
+	// plugin is enabled. The waitlist is disabled if nil.
+	Waitlist *DeviceWaitlistArgs `json:"waitlist,omitempty"`
+	// AllocationCooldowns is how long the resources of a device released by a pod are not allocated again,
+	// by device type, e.g. for the drivers reclaiming the GPU memory lazily. No cooldown if unset.
+	AllocationCooldowns map[schedulingv1alpha1.DeviceType]metav1.Duration `json:"allocationCooldowns,omitempty"`
+	// ReconcileStrategy is how the cache reconciles the device allocations of the pods which the Device can't
+	// account for, e.g. the capacity of a device shrank or a device is no longer reported. Defaults to ConservativeMin,
+	// which the empty strategy also means.
+	ReconcileStrategy DeviceReconcileStrategy `json:"reconcileStrategy,omitempty"`
+	// GPUCoreGranularity is the granularity of the gpu-core share derived for the pods requesting only gpu-memory.
+	// The share is proportional to the memory of the allocated GPU and rounded up to a multiple of it. Defaults to 5.
+	GPUCoreGranularity *int32 `json:"gpuCoreGranularity,omitempty"`
+	// ScoringStrategy scores the nodes by the device resources allocated after placing the pod, MostAllocated to
+	// binpack the pods onto the partially allocated nodes or LeastAllocated to spread them. Only the requested device
+	// resources are scored, and all the device resources are weighted by 1 if the resources are empty.
+	// Defaults to MostAllocated so the small pods consolidate onto fewer device nodes.
+	ScoringStrategy *ScoringStrategy `json:"scoringStrategy,omitempty"`
+	// EnablePreemption lets the pod lacking devices preempt the lower priority pods using the devices of a node,
+	// like the DefaultPreemption but accounting the devices. It takes effect only if the PostFilter extension point
+	// of the plugin is enabled. Defaults to false.
+	EnablePreemption *bool `json:"enablePreemption,omitempty"`
+	// NUMATopologyPolicy is how the GPUs and RDMA NICs requested together by a pod are aligned to the NUMA nodes.
+	// BestEffort prefers allocating them on the same NUMA node, and Restricted rejects the node in Filter if they
+	// cannot be allocated on the same NUMA node. Defaults to BestEffort.
+	NUMATopologyPolicy DeviceNUMATopologyPolicy `json:"numaTopologyPolicy,omitempty"`
+	// GPUTopologyPolicy is how the whole GPUs requested by a pod are aligned to the NVLink groups, the PCIe switches
+	// and the NUMA nodes of the GPUs, preferred in that order. BestEffort falls back to any GPUs, and Restricted
+	// rejects the node in Filter if the GPUs cannot be allocated on as few of the top reported topology groups as
+	// the node could place them on. Defaults to BestEffort.
+	GPUTopologyPolicy DeviceGPUTopologyPolicy `json:"gpuTopologyPolicy,omitempty"`
+	// OvercommitRatios multiplies the capacity of the devices by device type for the pods selected by the
+	// OvercommitPriorityClasses or the OvercommitQoSClasses, e.g. to pack more low priority jobs on the GPUs of
+	// the dev/test clusters. The other pods see the physical capacity. Not overcommitted if empty.
+	OvercommitRatios map[schedulingv1alpha1.DeviceType]float64 `json:"overcommitRatios,omitempty"`
+	// OvercommitPriorityClasses and OvercommitQoSClasses select the pods allocated with the overcommitted capacity
+	// by the koordinator priority class or the QoS class of the pods.
+	OvercommitPriorityClasses []extension.PriorityClass `json:"overcommitPriorityClasses,omitempty"`
+	OvercommitQoSClasses      []extension.QoSClass      `json:"overcommitQoSClasses,omitempty"`
+	// CustomDeviceTypes registers the device types other than GPU, RDMA and FPGA, e.g. the accelerators of the other
+	// vendors. They are allocated by the whole devices like RDMA and FPGA, 100 of the resource for each device.
+	CustomDeviceTypes []CustomDeviceType `json:"customDeviceTypes,omitempty"`
+}
+
+// CustomDeviceType is a device type the pods request by the resource.
+type CustomDeviceType struct {
+	// ResourceName is the resource the pods request the devices by, which must not be a device resource of
+	// the built-in device types.
+	ResourceName corev1.ResourceName `json:"resourceName"`
+	// DeviceType is the type of the devices reported by the Device.
+	DeviceType schedulingv1alpha1.DeviceType `json:"deviceType"`
+}
+
+// DeviceReconcileStrategy is a "string" type.
+type DeviceReconcileStrategy string
+
+const (
+	// DeviceReconcileTrustPods trusts the allocations of the pods, the free resources of a device are computed as if
+	// its capacity were raised to cover them. The capacity reported by the Device is kept.
+	DeviceReconcileTrustPods DeviceReconcileStrategy = "TrustPods"
+	// DeviceReconcileTrustCRD trusts the capacity reported by the Device, the allocations beyond it only leave
+	// no free resources on the device.
+	DeviceReconcileTrustCRD DeviceReconcileStrategy = "TrustCRD"
+	// DeviceReconcileConservativeMin trusts the smaller of both, the allocations the Device can't account for
+	// are also deducted from the free resources of the other devices of the same type, so they are never over-allocated.
+	DeviceReconcileConservativeMin DeviceReconcileStrategy = "ConservativeMin"
+)
+
+// DeviceNUMATopologyPolicy is a "string" type.
+type DeviceNUMATopologyPolicy string
+
+const (
+	// DeviceNUMATopologyBestEffort prefers the devices on the same NUMA node, and falls back to the devices
+	// across the NUMA nodes.
+	DeviceNUMATopologyBestEffort DeviceNUMATopologyPolicy = "BestEffort"
+	// DeviceNUMATopologyRestricted allocates only the devices on the same NUMA node.
+	DeviceNUMATopologyRestricted DeviceNUMATopologyPolicy = "Restricted"
+)
+
+// DeviceGPUTopologyPolicy is a "string" type.
+type DeviceGPUTopologyPolicy string
+
+const (
+	// DeviceGPUTopologyBestEffort prefers the GPUs connected by the closest link, and falls back to any GPUs.
+	DeviceGPUTopologyBestEffort DeviceGPUTopologyPolicy = "BestEffort"
+	// DeviceGPUTopologyRestricted allocates only the GPUs connected by the closest link the node could offer.
+	DeviceGPUTopologyRestricted DeviceGPUTopologyPolicy = "Restricted"
+)
+
+// DeviceWaitlistArgs describes how the large pending pods hold the GPUs.
+type DeviceWaitlistArgs struct {
+	// MinGPUs is the minimum number of whole GPUs requested by a pod to join the waitlist. Defaults to 4.
+	MinGPUs *int32 `json:"minGPUs,omitempty"`
+	// HoldDuration is how long the holds of a pod last. After the holds expire, the pod cannot hold GPUs again
+	// in the same duration, so the smaller pods are never blocked permanently. Defaults to 5m.
+	HoldDuration *metav1.Duration `json:"holdDuration,omitempty"`
+}
+
+// PatchRetryPolicy describes the exponential backoff to retry the patch.
+type PatchRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. Defaults to 4.
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+	// InitialBackoff is the duration to wait before the first retry. Defaults to 10ms.
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// BackoffFactor multiplies the backoff after each retry. Defaults to 5.
+	BackoffFactor *float64 `json:"backoffFactor,omitempty"`
+}
+
+// DeviceLocalityAffinity selects the pods which the pods requesting devices prefer to co-locate with.
+type DeviceLocalityAffinity struct {
+	// PodSelector selects the pods to co-locate with.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Namespaces restricts the namespaces of the selected pods. All namespaces are selected if empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Weight is the score in (0, 100] added to the device resources score of the nodes running the selected pods.
+	// Defaults to 100.
+	Weight *int64 `json:"weight,omitempty"`
 }
@@ -25,7 +25,8 @@ import (
 	unsafe "unsafe"
 
 	extension "github.com/koordinator-sh/koordinator/apis/extension"
-	v1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	config "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,6 +52,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CustomDeviceType)(nil), (*config.CustomDeviceType)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_CustomDeviceType_To_config_CustomDeviceType(a.(*CustomDeviceType), b.(*config.CustomDeviceType), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CustomDeviceType)(nil), (*CustomDeviceType)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CustomDeviceType_To_v1beta2_CustomDeviceType(a.(*config.CustomDeviceType), b.(*CustomDeviceType), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DeviceLocalityAffinity)(nil), (*config.DeviceLocalityAffinity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_DeviceLocalityAffinity_To_config_DeviceLocalityAffinity(a.(*DeviceLocalityAffinity), b.(*config.DeviceLocalityAffinity), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DeviceLocalityAffinity)(nil), (*DeviceLocalityAffinity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DeviceLocalityAffinity_To_v1beta2_DeviceLocalityAffinity(a.(*config.DeviceLocalityAffinity), b.(*DeviceLocalityAffinity), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DeviceShareArgs)(nil), (*config.DeviceShareArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_DeviceShareArgs_To_config_DeviceShareArgs(a.(*DeviceShareArgs), b.(*config.DeviceShareArgs), scope)
 	}); err != nil {
@@ -61,6 +82,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*DeviceWaitlistArgs)(nil), (*config.DeviceWaitlistArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_DeviceWaitlistArgs_To_config_DeviceWaitlistArgs(a.(*DeviceWaitlistArgs), b.(*config.DeviceWaitlistArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DeviceWaitlistArgs)(nil), (*DeviceWaitlistArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DeviceWaitlistArgs_To_v1beta2_DeviceWaitlistArgs(a.(*config.DeviceWaitlistArgs), b.(*DeviceWaitlistArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ElasticQuotaArgs)(nil), (*config.ElasticQuotaArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_ElasticQuotaArgs_To_config_ElasticQuotaArgs(a.(*ElasticQuotaArgs), b.(*config.ElasticQuotaArgs), scope)
 	}); err != nil {
@@ -101,6 +132,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PatchRetryPolicy)(nil), (*config.PatchRetryPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta2_PatchRetryPolicy_To_config_PatchRetryPolicy(a.(*PatchRetryPolicy), b.(*config.PatchRetryPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PatchRetryPolicy)(nil), (*PatchRetryPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PatchRetryPolicy_To_v1beta2_PatchRetryPolicy(a.(*config.PatchRetryPolicy), b.(*PatchRetryPolicy), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ReservationArgs)(nil), (*config.ReservationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta2_ReservationArgs_To_config_ReservationArgs(a.(*ReservationArgs), b.(*config.ReservationArgs), scope)
 	}); err != nil {
@@ -146,8 +187,70 @@ func Convert_config_CoschedulingArgs_To_v1beta2_CoschedulingArgs(in *config.Cosc
 	return autoConvert_config_CoschedulingArgs_To_v1beta2_CoschedulingArgs(in, out, s)
 }
 
+func autoConvert_v1beta2_CustomDeviceType_To_config_CustomDeviceType(in *CustomDeviceType, out *config.CustomDeviceType, s conversion.Scope) error {
+	out.ResourceName = corev1.ResourceName(in.ResourceName)
+	out.DeviceType = v1alpha1.DeviceType(in.DeviceType)
+	return nil
+}
+
+// Convert_v1beta2_CustomDeviceType_To_config_CustomDeviceType is an autogenerated conversion function.
+func Convert_v1beta2_CustomDeviceType_To_config_CustomDeviceType(in *CustomDeviceType, out *config.CustomDeviceType, s conversion.Scope) error {
+	return autoConvert_v1beta2_CustomDeviceType_To_config_CustomDeviceType(in, out, s)
+}
+
+func autoConvert_config_CustomDeviceType_To_v1beta2_CustomDeviceType(in *config.CustomDeviceType, out *CustomDeviceType, s conversion.Scope) error {
+	out.ResourceName = corev1.ResourceName(in.ResourceName)
+	out.DeviceType = v1alpha1.DeviceType(in.DeviceType)
+	return nil
+}
+
+// Convert_config_CustomDeviceType_To_v1beta2_CustomDeviceType is an autogenerated conversion function.
+func Convert_config_CustomDeviceType_To_v1beta2_CustomDeviceType(in *config.CustomDeviceType, out *CustomDeviceType, s conversion.Scope) error {
+	return autoConvert_config_CustomDeviceType_To_v1beta2_CustomDeviceType(in, out, s)
+}
+
+func autoConvert_v1beta2_DeviceLocalityAffinity_To_config_DeviceLocalityAffinity(in *DeviceLocalityAffinity, out *config.DeviceLocalityAffinity, s conversion.Scope) error {
+	out.PodSelector = (*v1.LabelSelector)(unsafe.Pointer(in.PodSelector))
+	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	out.Weight = (*int64)(unsafe.Pointer(in.Weight))
+	return nil
+}
+
+// Convert_v1beta2_DeviceLocalityAffinity_To_config_DeviceLocalityAffinity is an autogenerated conversion function.
+func Convert_v1beta2_DeviceLocalityAffinity_To_config_DeviceLocalityAffinity(in *DeviceLocalityAffinity, out *config.DeviceLocalityAffinity, s conversion.Scope) error {
+	return autoConvert_v1beta2_DeviceLocalityAffinity_To_config_DeviceLocalityAffinity(in, out, s)
+}
+
+func autoConvert_config_DeviceLocalityAffinity_To_v1beta2_DeviceLocalityAffinity(in *config.DeviceLocalityAffinity, out *DeviceLocalityAffinity, s conversion.Scope) error {
+	out.PodSelector = (*v1.LabelSelector)(unsafe.Pointer(in.PodSelector))
+	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	out.Weight = (*int64)(unsafe.Pointer(in.Weight))
+	return nil
+}
+
+// Convert_config_DeviceLocalityAffinity_To_v1beta2_DeviceLocalityAffinity is an autogenerated conversion function.
+func Convert_config_DeviceLocalityAffinity_To_v1beta2_DeviceLocalityAffinity(in *config.DeviceLocalityAffinity, out *DeviceLocalityAffinity, s conversion.Scope) error {
+	return autoConvert_config_DeviceLocalityAffinity_To_v1beta2_DeviceLocalityAffinity(in, out, s)
+}
+
 func autoConvert_v1beta2_DeviceShareArgs_To_config_DeviceShareArgs(in *DeviceShareArgs, out *config.DeviceShareArgs, s conversion.Scope) error {
 	out.Allocator = in.Allocator
+	out.LocalityAffinity = (*config.DeviceLocalityAffinity)(unsafe.Pointer(in.LocalityAffinity))
+	out.MinResourcesPerGPU = *(*corev1.ResourceList)(unsafe.Pointer(&in.MinResourcesPerGPU))
+	out.ReleaseTerminatedPods = (*bool)(unsafe.Pointer(in.ReleaseTerminatedPods))
+	out.PreBindPatchRetry = (*config.PatchRetryPolicy)(unsafe.Pointer(in.PreBindPatchRetry))
+	out.Waitlist = (*config.DeviceWaitlistArgs)(unsafe.Pointer(in.Waitlist))
+	out.AllocationCooldowns = *(*map[v1alpha1.DeviceType]v1.Duration)(unsafe.Pointer(&in.AllocationCooldowns))
+	out.ReconcileStrategy = config.DeviceReconcileStrategy(in.ReconcileStrategy)
+	out.GPUCoreGranularity = (*int32)(unsafe.Pointer(in.GPUCoreGranularity))
+	out.ScoringStrategy = (*config.ScoringStrategy)(unsafe.Pointer(in.ScoringStrategy))
+	out.EnablePreemption = (*bool)(unsafe.Pointer(in.EnablePreemption))
+	out.NUMATopologyPolicy = config.DeviceNUMATopologyPolicy(in.NUMATopologyPolicy)
+	out.GPUTopologyPolicy = config.DeviceGPUTopologyPolicy(in.GPUTopologyPolicy)
+	out.OvercommitRatios = *(*map[v1alpha1.DeviceType]float64)(unsafe.Pointer(&in.OvercommitRatios))
+	out.OvercommitPriorityClasses = *(*[]extension.PriorityClass)(unsafe.Pointer(&in.OvercommitPriorityClasses))
+	out.OvercommitQoSClasses = *(*[]extension.QoSClass)(unsafe.Pointer(&in.OvercommitQoSClasses))
+	out.CustomDeviceTypes = *(*[]config.CustomDeviceType)(unsafe.Pointer(&in.CustomDeviceTypes))
 	return nil
 }
 
@@ -158,6 +261,22 @@ func Convert_v1beta2_DeviceShareArgs_To_config_DeviceShareArgs(in *DeviceShareAr
 
 func autoConvert_config_DeviceShareArgs_To_v1beta2_DeviceShareArgs(in *config.DeviceShareArgs, out *DeviceShareArgs, s conversion.Scope) error {
 	out.Allocator = in.Allocator
+	out.LocalityAffinity = (*DeviceLocalityAffinity)(unsafe.Pointer(in.LocalityAffinity))
+	out.MinResourcesPerGPU = *(*corev1.ResourceList)(unsafe.Pointer(&in.MinResourcesPerGPU))
+	out.ReleaseTerminatedPods = (*bool)(unsafe.Pointer(in.ReleaseTerminatedPods))
+	out.PreBindPatchRetry = (*PatchRetryPolicy)(unsafe.Pointer(in.PreBindPatchRetry))
+	out.Waitlist = (*DeviceWaitlistArgs)(unsafe.Pointer(in.Waitlist))
+	out.AllocationCooldowns = *(*map[v1alpha1.DeviceType]v1.Duration)(unsafe.Pointer(&in.AllocationCooldowns))
+	out.ReconcileStrategy = DeviceReconcileStrategy(in.ReconcileStrategy)
+	out.GPUCoreGranularity = (*int32)(unsafe.Pointer(in.GPUCoreGranularity))
+	out.ScoringStrategy = (*ScoringStrategy)(unsafe.Pointer(in.ScoringStrategy))
+	out.EnablePreemption = (*bool)(unsafe.Pointer(in.EnablePreemption))
+	out.NUMATopologyPolicy = DeviceNUMATopologyPolicy(in.NUMATopologyPolicy)
+	out.GPUTopologyPolicy = DeviceGPUTopologyPolicy(in.GPUTopologyPolicy)
+	out.OvercommitRatios = *(*map[v1alpha1.DeviceType]float64)(unsafe.Pointer(&in.OvercommitRatios))
+	out.OvercommitPriorityClasses = *(*[]extension.PriorityClass)(unsafe.Pointer(&in.OvercommitPriorityClasses))
+	out.OvercommitQoSClasses = *(*[]extension.QoSClass)(unsafe.Pointer(&in.OvercommitQoSClasses))
+	out.CustomDeviceTypes = *(*[]CustomDeviceType)(unsafe.Pointer(&in.CustomDeviceTypes))
 	return nil
 }
 
@@ -166,6 +285,28 @@ func Convert_config_DeviceShareArgs_To_v1beta2_DeviceShareArgs(in *config.Device
 	return autoConvert_config_DeviceShareArgs_To_v1beta2_DeviceShareArgs(in, out, s)
 }
 
+func autoConvert_v1beta2_DeviceWaitlistArgs_To_config_DeviceWaitlistArgs(in *DeviceWaitlistArgs, out *config.DeviceWaitlistArgs, s conversion.Scope) error {
+	out.MinGPUs = (*int32)(unsafe.Pointer(in.MinGPUs))
+	out.HoldDuration = (*v1.Duration)(unsafe.Pointer(in.HoldDuration))
+	return nil
+}
+
+// Convert_v1beta2_DeviceWaitlistArgs_To_config_DeviceWaitlistArgs is an autogenerated conversion function.
+func Convert_v1beta2_DeviceWaitlistArgs_To_config_DeviceWaitlistArgs(in *DeviceWaitlistArgs, out *config.DeviceWaitlistArgs, s conversion.Scope) error {
+	return autoConvert_v1beta2_DeviceWaitlistArgs_To_config_DeviceWaitlistArgs(in, out, s)
+}
+
+func autoConvert_config_DeviceWaitlistArgs_To_v1beta2_DeviceWaitlistArgs(in *config.DeviceWaitlistArgs, out *DeviceWaitlistArgs, s conversion.Scope) error {
+	out.MinGPUs = (*int32)(unsafe.Pointer(in.MinGPUs))
+	out.HoldDuration = (*v1.Duration)(unsafe.Pointer(in.HoldDuration))
+	return nil
+}
+
+// Convert_config_DeviceWaitlistArgs_To_v1beta2_DeviceWaitlistArgs is an autogenerated conversion function.
+func Convert_config_DeviceWaitlistArgs_To_v1beta2_DeviceWaitlistArgs(in *config.DeviceWaitlistArgs, out *DeviceWaitlistArgs, s conversion.Scope) error {
+	return autoConvert_config_DeviceWaitlistArgs_To_v1beta2_DeviceWaitlistArgs(in, out, s)
+}
+
 func autoConvert_v1beta2_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *ElasticQuotaArgs, out *config.ElasticQuotaArgs, s conversion.Scope) error {
 	out.DelayEvictTime = (*v1.Duration)(unsafe.Pointer(in.DelayEvictTime))
 	out.RevokePodInterval = (*v1.Duration)(unsafe.Pointer(in.RevokePodInterval))
@@ -174,6 +315,8 @@ func autoConvert_v1beta2_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *Elastic
 	out.QuotaGroupNamespace = in.QuotaGroupNamespace
 	out.MonitorAllQuotas = (*bool)(unsafe.Pointer(in.MonitorAllQuotas))
 	out.EnableCheckParentQuota = (*bool)(unsafe.Pointer(in.EnableCheckParentQuota))
+	out.MetricsQuotaGroups = *(*[]string)(unsafe.Pointer(&in.MetricsQuotaGroups))
+	out.MetricsQuotaGroupBuckets = (*int32)(unsafe.Pointer(in.MetricsQuotaGroupBuckets))
 	return nil
 }
 
@@ -190,6 +333,8 @@ func autoConvert_config_ElasticQuotaArgs_To_v1beta2_ElasticQuotaArgs(in *config.
 	out.QuotaGroupNamespace = in.QuotaGroupNamespace
 	out.MonitorAllQuotas = (*bool)(unsafe.Pointer(in.MonitorAllQuotas))
 	out.EnableCheckParentQuota = (*bool)(unsafe.Pointer(in.EnableCheckParentQuota))
+	out.MetricsQuotaGroups = *(*[]string)(unsafe.Pointer(&in.MetricsQuotaGroups))
+	out.MetricsQuotaGroupBuckets = (*int32)(unsafe.Pointer(in.MetricsQuotaGroupBuckets))
 	return nil
 }
 
@@ -200,11 +345,11 @@ func Convert_config_ElasticQuotaArgs_To_v1beta2_ElasticQuotaArgs(in *config.Elas
 
 func autoConvert_v1beta2_LoadAwareSchedulingAggregatedArgs_To_config_LoadAwareSchedulingAggregatedArgs(in *LoadAwareSchedulingAggregatedArgs, out *config.LoadAwareSchedulingAggregatedArgs, s conversion.Scope) error {
 	out.UsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
-	out.UsageAggregationType = v1alpha1.AggregationType(in.UsageAggregationType)
+	out.UsageAggregationType = slov1alpha1.AggregationType(in.UsageAggregationType)
 	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.UsageAggregatedDuration, &out.UsageAggregatedDuration, s); err != nil {
 		return err
 	}
-	out.ScoreAggregationType = v1alpha1.AggregationType(in.ScoreAggregationType)
+	out.ScoreAggregationType = slov1alpha1.AggregationType(in.ScoreAggregationType)
 	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.ScoreAggregatedDuration, &out.ScoreAggregatedDuration, s); err != nil {
 		return err
 	}
@@ -218,11 +363,11 @@ func Convert_v1beta2_LoadAwareSchedulingAggregatedArgs_To_config_LoadAwareSchedu
 
 func autoConvert_config_LoadAwareSchedulingAggregatedArgs_To_v1beta2_LoadAwareSchedulingAggregatedArgs(in *config.LoadAwareSchedulingAggregatedArgs, out *LoadAwareSchedulingAggregatedArgs, s conversion.Scope) error {
 	out.UsageThresholds = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.UsageThresholds))
-	out.UsageAggregationType = v1alpha1.AggregationType(in.UsageAggregationType)
+	out.UsageAggregationType = slov1alpha1.AggregationType(in.UsageAggregationType)
 	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.UsageAggregatedDuration, &out.UsageAggregatedDuration, s); err != nil {
 		return err
 	}
-	out.ScoreAggregationType = v1alpha1.AggregationType(in.ScoreAggregationType)
+	out.ScoreAggregationType = slov1alpha1.AggregationType(in.ScoreAggregationType)
 	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.ScoreAggregatedDuration, &out.ScoreAggregatedDuration, s); err != nil {
 		return err
 	}
@@ -254,6 +399,7 @@ func autoConvert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingAr
 	} else {
 		out.Aggregated = nil
 	}
+	out.DeschedulingHistoryTTL = (*v1.Duration)(unsafe.Pointer(in.DeschedulingHistoryTTL))
 	return nil
 }
 
@@ -282,6 +428,7 @@ func autoConvert_config_LoadAwareSchedulingArgs_To_v1beta2_LoadAwareSchedulingAr
 	} else {
 		out.Aggregated = nil
 	}
+	out.DeschedulingHistoryTTL = (*v1.Duration)(unsafe.Pointer(in.DeschedulingHistoryTTL))
 	return nil
 }
 
@@ -312,6 +459,30 @@ func Convert_config_NodeNUMAResourceArgs_To_v1beta2_NodeNUMAResourceArgs(in *con
 	return autoConvert_config_NodeNUMAResourceArgs_To_v1beta2_NodeNUMAResourceArgs(in, out, s)
 }
 
+func autoConvert_v1beta2_PatchRetryPolicy_To_config_PatchRetryPolicy(in *PatchRetryPolicy, out *config.PatchRetryPolicy, s conversion.Scope) error {
+	out.MaxAttempts = (*int32)(unsafe.Pointer(in.MaxAttempts))
+	out.InitialBackoff = (*v1.Duration)(unsafe.Pointer(in.InitialBackoff))
+	out.BackoffFactor = (*float64)(unsafe.Pointer(in.BackoffFactor))
+	return nil
+}
+
+// Convert_v1beta2_PatchRetryPolicy_To_config_PatchRetryPolicy is an autogenerated conversion function.
+func Convert_v1beta2_PatchRetryPolicy_To_config_PatchRetryPolicy(in *PatchRetryPolicy, out *config.PatchRetryPolicy, s conversion.Scope) error {
+	return autoConvert_v1beta2_PatchRetryPolicy_To_config_PatchRetryPolicy(in, out, s)
+}
+
+func autoConvert_config_PatchRetryPolicy_To_v1beta2_PatchRetryPolicy(in *config.PatchRetryPolicy, out *PatchRetryPolicy, s conversion.Scope) error {
+	out.MaxAttempts = (*int32)(unsafe.Pointer(in.MaxAttempts))
+	out.InitialBackoff = (*v1.Duration)(unsafe.Pointer(in.InitialBackoff))
+	out.BackoffFactor = (*float64)(unsafe.Pointer(in.BackoffFactor))
+	return nil
+}
+
+// Convert_config_PatchRetryPolicy_To_v1beta2_PatchRetryPolicy is an autogenerated conversion function.
+func Convert_config_PatchRetryPolicy_To_v1beta2_PatchRetryPolicy(in *config.PatchRetryPolicy, out *PatchRetryPolicy, s conversion.Scope) error {
+	return autoConvert_config_PatchRetryPolicy_To_v1beta2_PatchRetryPolicy(in, out, s)
+}
+
 func autoConvert_v1beta2_ReservationArgs_To_config_ReservationArgs(in *ReservationArgs, out *config.ReservationArgs, s conversion.Scope) error {
 	out.EnablePreemption = (*bool)(unsafe.Pointer(in.EnablePreemption))
 	return nil
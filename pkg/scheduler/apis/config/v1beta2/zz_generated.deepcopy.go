@@ -22,6 +22,8 @@ limitations under the License.
 package v1beta2
 
 import (
+	extension "github.com/koordinator-sh/koordinator/apis/extension"
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -63,10 +65,128 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDeviceType) DeepCopyInto(out *CustomDeviceType) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDeviceType.
+func (in *CustomDeviceType) DeepCopy() *CustomDeviceType {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDeviceType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceLocalityAffinity) DeepCopyInto(out *DeviceLocalityAffinity) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceLocalityAffinity.
+func (in *DeviceLocalityAffinity) DeepCopy() *DeviceLocalityAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceLocalityAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceShareArgs) DeepCopyInto(out *DeviceShareArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.LocalityAffinity != nil {
+		in, out := &in.LocalityAffinity, &out.LocalityAffinity
+		*out = new(DeviceLocalityAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinResourcesPerGPU != nil {
+		in, out := &in.MinResourcesPerGPU, &out.MinResourcesPerGPU
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.ReleaseTerminatedPods != nil {
+		in, out := &in.ReleaseTerminatedPods, &out.ReleaseTerminatedPods
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PreBindPatchRetry != nil {
+		in, out := &in.PreBindPatchRetry, &out.PreBindPatchRetry
+		*out = new(PatchRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Waitlist != nil {
+		in, out := &in.Waitlist, &out.Waitlist
+		*out = new(DeviceWaitlistArgs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllocationCooldowns != nil {
+		in, out := &in.AllocationCooldowns, &out.AllocationCooldowns
+		*out = make(map[v1alpha1.DeviceType]v1.Duration, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GPUCoreGranularity != nil {
+		in, out := &in.GPUCoreGranularity, &out.GPUCoreGranularity
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScoringStrategy != nil {
+		in, out := &in.ScoringStrategy, &out.ScoringStrategy
+		*out = new(ScoringStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnablePreemption != nil {
+		in, out := &in.EnablePreemption, &out.EnablePreemption
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OvercommitRatios != nil {
+		in, out := &in.OvercommitRatios, &out.OvercommitRatios
+		*out = make(map[v1alpha1.DeviceType]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.OvercommitPriorityClasses != nil {
+		in, out := &in.OvercommitPriorityClasses, &out.OvercommitPriorityClasses
+		*out = make([]extension.PriorityClass, len(*in))
+		copy(*out, *in)
+	}
+	if in.OvercommitQoSClasses != nil {
+		in, out := &in.OvercommitQoSClasses, &out.OvercommitQoSClasses
+		*out = make([]extension.QoSClass, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomDeviceTypes != nil {
+		in, out := &in.CustomDeviceTypes, &out.CustomDeviceTypes
+		*out = make([]CustomDeviceType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -88,6 +208,32 @@ func (in *DeviceShareArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceWaitlistArgs) DeepCopyInto(out *DeviceWaitlistArgs) {
+	*out = *in
+	if in.MinGPUs != nil {
+		in, out := &in.MinGPUs, &out.MinGPUs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HoldDuration != nil {
+		in, out := &in.HoldDuration, &out.HoldDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceWaitlistArgs.
+func (in *DeviceWaitlistArgs) DeepCopy() *DeviceWaitlistArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceWaitlistArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
 	*out = *in
@@ -126,6 +272,16 @@ func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MetricsQuotaGroups != nil {
+		in, out := &in.MetricsQuotaGroups, &out.MetricsQuotaGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetricsQuotaGroupBuckets != nil {
+		in, out := &in.MetricsQuotaGroupBuckets, &out.MetricsQuotaGroupBuckets
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -232,6 +388,11 @@ func (in *LoadAwareSchedulingArgs) DeepCopyInto(out *LoadAwareSchedulingArgs) {
 		*out = new(LoadAwareSchedulingAggregatedArgs)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeschedulingHistoryTTL != nil {
+		in, out := &in.DeschedulingHistoryTTL, &out.DeschedulingHistoryTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -283,6 +444,37 @@ func (in *NodeNUMAResourceArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatchRetryPolicy) DeepCopyInto(out *PatchRetryPolicy) {
+	*out = *in
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BackoffFactor != nil {
+		in, out := &in.BackoffFactor, &out.BackoffFactor
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatchRetryPolicy.
+func (in *PatchRetryPolicy) DeepCopy() *PatchRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PatchRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReservationArgs) DeepCopyInto(out *ReservationArgs) {
 	*out = *in
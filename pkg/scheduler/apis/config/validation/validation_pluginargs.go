@@ -21,11 +21,23 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
+// deviceShareScoringResources are the device resources allowed in the scoring strategy of DeviceShareArgs.
+var deviceShareScoringResources = sets.NewString(
+	string(extension.GPUCore),
+	string(extension.GPUMemory),
+	string(extension.GPUMemoryRatio),
+	string(extension.KoordRDMA),
+	string(extension.KoordFPGA),
+)
+
 // ValidateLoadAwareSchedulingArgs validates that LoadAwareSchedulingArgs are correct.
 func ValidateLoadAwareSchedulingArgs(args *config.LoadAwareSchedulingArgs) error {
 	var allErrs field.ErrorList
@@ -51,6 +63,10 @@ func ValidateLoadAwareSchedulingArgs(args *config.LoadAwareSchedulingArgs) error
 		}
 	}
 
+	if args.DeschedulingHistoryTTL != nil && args.DeschedulingHistoryTTL.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("deschedulingHistoryTTL"), args.DeschedulingHistoryTTL.Duration, "deschedulingHistoryTTL should not be negative"))
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -116,6 +132,10 @@ func ValidateElasticQuotaArgs(elasticArgs *config.ElasticQuotaArgs) error {
 		return fmt.Errorf("elasticQuotaArgs error, RevokePodCycle should be a positive value")
 	}
 
+	if elasticArgs.MetricsQuotaGroupBuckets != nil && *elasticArgs.MetricsQuotaGroupBuckets <= 0 {
+		return fmt.Errorf("elasticQuotaArgs error, MetricsQuotaGroupBuckets should be a positive value")
+	}
+
 	return nil
 }
 
@@ -128,3 +148,107 @@ func ValidateCoschedulingArgs(coeSchedulingArgs *config.CoschedulingArgs) error
 	}
 	return nil
 }
+
+// ValidateDeviceShareArgs validates that DeviceShareArgs are correct.
+func ValidateDeviceShareArgs(args *config.DeviceShareArgs) error {
+	for resName, q := range args.MinResourcesPerGPU {
+		if resName != corev1.ResourceCPU && resName != corev1.ResourceMemory {
+			return fmt.Errorf("deviceShareArgs error, minResourcesPerGPU only supports cpu and memory, got %v", resName)
+		}
+		if q.Sign() < 0 {
+			return fmt.Errorf("deviceShareArgs error, minResourcesPerGPU should be a positive value, resourceName:%v, got %v",
+				resName, q.String())
+		}
+	}
+	if retry := args.PreBindPatchRetry; retry != nil {
+		if retry.MaxAttempts != nil && *retry.MaxAttempts <= 0 {
+			return fmt.Errorf("deviceShareArgs error, preBindPatchRetry.maxAttempts should be positive, got %v", *retry.MaxAttempts)
+		}
+		if retry.InitialBackoff != nil && retry.InitialBackoff.Duration < 0 {
+			return fmt.Errorf("deviceShareArgs error, preBindPatchRetry.initialBackoff should not be negative, got %v", retry.InitialBackoff.Duration)
+		}
+		if retry.BackoffFactor != nil && *retry.BackoffFactor < 0 {
+			return fmt.Errorf("deviceShareArgs error, preBindPatchRetry.backoffFactor should not be negative, got %v", *retry.BackoffFactor)
+		}
+	}
+	if waitlist := args.Waitlist; waitlist != nil {
+		if waitlist.MinGPUs != nil && *waitlist.MinGPUs <= 0 {
+			return fmt.Errorf("deviceShareArgs error, waitlist.minGPUs should be positive, got %v", *waitlist.MinGPUs)
+		}
+		if waitlist.HoldDuration != nil && waitlist.HoldDuration.Duration <= 0 {
+			return fmt.Errorf("deviceShareArgs error, waitlist.holdDuration should be positive, got %v", waitlist.HoldDuration.Duration)
+		}
+	}
+	for deviceType, cooldown := range args.AllocationCooldowns {
+		if cooldown.Duration < 0 {
+			return fmt.Errorf("deviceShareArgs error, allocationCooldowns should not be negative, deviceType:%v, got %v", deviceType, cooldown.Duration)
+		}
+	}
+	switch args.ReconcileStrategy {
+	case "", config.DeviceReconcileTrustPods, config.DeviceReconcileTrustCRD, config.DeviceReconcileConservativeMin:
+	default:
+		return fmt.Errorf("deviceShareArgs error, reconcileStrategy %q is not supported", args.ReconcileStrategy)
+	}
+	switch args.NUMATopologyPolicy {
+	case "", config.DeviceNUMATopologyBestEffort, config.DeviceNUMATopologyRestricted:
+	default:
+		return fmt.Errorf("deviceShareArgs error, numaTopologyPolicy %q is not supported", args.NUMATopologyPolicy)
+	}
+	switch args.GPUTopologyPolicy {
+	case "", config.DeviceGPUTopologyBestEffort, config.DeviceGPUTopologyRestricted:
+	default:
+		return fmt.Errorf("deviceShareArgs error, gpuTopologyPolicy %q is not supported", args.GPUTopologyPolicy)
+	}
+	if args.GPUCoreGranularity != nil && (*args.GPUCoreGranularity <= 0 || *args.GPUCoreGranularity > 100) {
+		return fmt.Errorf("deviceShareArgs error, gpuCoreGranularity should be in (0, 100], got %v", *args.GPUCoreGranularity)
+	}
+	if locality := args.LocalityAffinity; locality != nil && locality.Weight != nil &&
+		(*locality.Weight <= 0 || *locality.Weight > 100) {
+		return fmt.Errorf("deviceShareArgs error, localityAffinity.weight should be in (0, 100], got %v", *locality.Weight)
+	}
+	if scoringStrategy := args.ScoringStrategy; scoringStrategy != nil {
+		switch scoringStrategy.Type {
+		case config.MostAllocated, config.LeastAllocated:
+		default:
+			return fmt.Errorf("deviceShareArgs error, scoringStrategy.type %q is not supported", scoringStrategy.Type)
+		}
+		for _, r := range scoringStrategy.Resources {
+			if !deviceShareScoringResources.Has(r.Name) {
+				return fmt.Errorf("deviceShareArgs error, scoringStrategy.resources only supports the device resources %v, got %v",
+					deviceShareScoringResources.List(), r.Name)
+			}
+			if r.Weight <= 0 || r.Weight > 100 {
+				return fmt.Errorf("deviceShareArgs error, scoringStrategy.resources weight should be in (0, 100], resourceName:%v, got %v",
+					r.Name, r.Weight)
+			}
+		}
+	}
+	deviceTypes := sets.NewString(string(schedulingv1alpha1.GPU), string(schedulingv1alpha1.RDMA), string(schedulingv1alpha1.FPGA))
+	for _, custom := range args.CustomDeviceTypes {
+		deviceTypes.Insert(string(custom.DeviceType))
+	}
+	for deviceType, ratio := range args.OvercommitRatios {
+		if !deviceTypes.Has(string(deviceType)) {
+			return fmt.Errorf("deviceShareArgs error, overcommitRatios only supports the device types %v, got %v", deviceTypes.List(), deviceType)
+		}
+		if ratio < 1 {
+			return fmt.Errorf("deviceShareArgs error, overcommitRatios should be at least 1, deviceType:%v, got %v", deviceType, ratio)
+		}
+	}
+	if len(args.OvercommitRatios) > 0 && len(args.OvercommitPriorityClasses) == 0 && len(args.OvercommitQoSClasses) == 0 {
+		return fmt.Errorf("deviceShareArgs error, overcommitRatios requires the pods selected by overcommitPriorityClasses or overcommitQoSClasses")
+	}
+	for _, priorityClass := range args.OvercommitPriorityClasses {
+		switch priorityClass {
+		case extension.PriorityProd, extension.PriorityMid, extension.PriorityBatch, extension.PriorityFree:
+		default:
+			return fmt.Errorf("deviceShareArgs error, overcommitPriorityClasses %q is not supported", priorityClass)
+		}
+	}
+	for _, qosClass := range args.OvercommitQoSClasses {
+		if extension.GetPodQoSClassByName(string(qosClass)) == extension.QoSNone {
+			return fmt.Errorf("deviceShareArgs error, overcommitQoSClasses %q is not supported", qosClass)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/services"
+)
+
+var (
+	consistencyCheckInterval   time.Duration
+	consistencyCheckAutoRepair = false
+)
+
+func addConsistencyCheckFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&consistencyCheckInterval, "consistency-check-interval", consistencyCheckInterval, "the interval to check the consistency between the plugin caches and the API objects, disable the periodic check if set to 0")
+	fs.BoolVar(&consistencyCheckAutoRepair, "consistency-auto-repair", consistencyCheckAutoRepair, "repair the plugin caches with the API objects when the periodic consistency check finds discrepancies")
+}
+
+var (
+	consistencyDiscrepancies = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "scheduler",
+			Name:           "consistency_discrepancies_total",
+			Help:           "Number of discrepancies between the plugin caches and the API objects found by the consistency checker, by the plugin, by the object kind, by whether the discrepancy is repaired",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"plugin", "kind", "repaired"})
+
+	consistencyLastDiscrepancies = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "scheduler",
+			Name:           "consistency_last_discrepancies",
+			Help:           "Number of discrepancies found in the last consistency check, by the plugin",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"plugin"})
+
+	registerConsistencyMetrics sync.Once
+)
+
+// ConsistencyCheckable is implemented by the plugins maintaining caches derived from the API objects.
+// The plugin rebuilds a fresh view from the listers and diffs it against its cache.
+type ConsistencyCheckable interface {
+	Name() string
+	// CheckConsistency returns the discrepancies between the cache and the listers.
+	// If repair is true, the plugin corrects the cache with the listers and marks the discrepancies repaired.
+	CheckConsistency(repair bool) []Discrepancy
+}
+
+// Discrepancy describes an object whose state in the plugin cache differs from the API object.
+type Discrepancy struct {
+	// Kind is the kind of the object, e.g. Pod, Device or ElasticQuota.
+	Kind string `json:"kind"`
+	// Key identifies the object, e.g. namespace/name of a pod.
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+	Repaired bool   `json:"repaired"`
+}
+
+// ConsistencyReport is the result of the latest consistency check of a plugin.
+type ConsistencyReport struct {
+	Plugin        string        `json:"plugin"`
+	CheckTime     time.Time     `json:"checkTime"`
+	Discrepancies []Discrepancy `json:"discrepancies,omitempty"`
+}
+
+// ConsistencyChecker checks the registered plugins periodically and on demand via the debug endpoints.
+type ConsistencyChecker struct {
+	lock       sync.Mutex
+	checkables map[string]ConsistencyCheckable
+	reports    map[string]*ConsistencyReport
+}
+
+var _ services.APIServiceProvider = &ConsistencyChecker{}
+
+func NewConsistencyChecker() *ConsistencyChecker {
+	registerConsistencyMetrics.Do(func() {
+		legacyregistry.MustRegister(consistencyDiscrepancies, consistencyLastDiscrepancies)
+	})
+	return &ConsistencyChecker{
+		checkables: map[string]ConsistencyCheckable{},
+		reports:    map[string]*ConsistencyReport{},
+	}
+}
+
+func (c *ConsistencyChecker) Register(plugin framework.Plugin) {
+	checkable, ok := plugin.(ConsistencyCheckable)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, exist := c.checkables[checkable.Name()]; exist {
+		klog.Warningf("Plugin %s already registered the consistency check, skip it", checkable.Name())
+		return
+	}
+	c.checkables[checkable.Name()] = checkable
+	klog.Infof("register plugin:%v consistency check", checkable.Name())
+}
+
+// Start checks the plugins periodically if the interval is set.
+func (c *ConsistencyChecker) Start() {
+	if consistencyCheckInterval <= 0 {
+		return
+	}
+	wait.Forever(func() {
+		c.CheckAll(consistencyCheckAutoRepair)
+	}, consistencyCheckInterval)
+}
+
+// CheckAll checks all the registered plugins in the order of the plugin names.
+func (c *ConsistencyChecker) CheckAll(repair bool) []*ConsistencyReport {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	names := make([]string, 0, len(c.checkables))
+	for name := range c.checkables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reports := make([]*ConsistencyReport, 0, len(names))
+	for _, name := range names {
+		discrepancies := c.checkables[name].CheckConsistency(repair)
+		for _, d := range discrepancies {
+			klog.Warningf("consistency check found discrepancy, plugin: %v, kind: %v, key: %v, repaired: %v, message: %v",
+				name, d.Kind, d.Key, d.Repaired, d.Message)
+			consistencyDiscrepancies.WithLabelValues(name, d.Kind, strconv.FormatBool(d.Repaired)).Inc()
+		}
+		consistencyLastDiscrepancies.WithLabelValues(name).Set(float64(len(discrepancies)))
+		report := &ConsistencyReport{
+			Plugin:        name,
+			CheckTime:     time.Now(),
+			Discrepancies: discrepancies,
+		}
+		c.reports[name] = report
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func (c *ConsistencyChecker) lastReports() []*ConsistencyReport {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	reports := make([]*ConsistencyReport, 0, len(c.reports))
+	for _, report := range c.reports {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Plugin < reports[j].Plugin
+	})
+	return reports
+}
+
+func (c *ConsistencyChecker) RegisterEndpoints(group *gin.RouterGroup) {
+	group.GET("/reports", func(context *gin.Context) {
+		context.JSON(http.StatusOK, c.lastReports())
+	})
+	group.POST("/check", func(context *gin.Context) {
+		repair := false
+		if value := context.Query("repair"); value != "" {
+			var err error
+			repair, err = strconv.ParseBool(value)
+			if err != nil {
+				services.ResponseErrorMessage(context, http.StatusBadRequest, "invalid repair %s: %v", value, err)
+				return
+			}
+		}
+		context.JSON(http.StatusOK, c.CheckAll(repair))
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCheckable keeps the cached and the expected values of the objects.
+type fakeCheckable struct {
+	name     string
+	cache    map[string]string
+	expected map[string]string
+}
+
+func (f *fakeCheckable) Name() string { return f.name }
+
+func (f *fakeCheckable) CheckConsistency(repair bool) []Discrepancy {
+	var discrepancies []Discrepancy
+	for _, key := range []string{"a", "b", "c"} {
+		if f.cache[key] == f.expected[key] {
+			continue
+		}
+		if repair {
+			f.cache[key] = f.expected[key]
+		}
+		discrepancies = append(discrepancies, Discrepancy{Kind: "Fake", Key: key, Repaired: repair})
+	}
+	return discrepancies
+}
+
+func TestConsistencyChecker(t *testing.T) {
+	checker := NewConsistencyChecker()
+	drifted := &fakeCheckable{
+		name:     "drifted",
+		cache:    map[string]string{"a": "1", "b": "2"},
+		expected: map[string]string{"a": "1", "b": "3", "c": "4"},
+	}
+	consistent := &fakeCheckable{
+		name:     "consistent",
+		cache:    map[string]string{"a": "1"},
+		expected: map[string]string{"a": "1"},
+	}
+	checker.Register(drifted)
+	checker.Register(consistent)
+	checker.Register(&fakeCheckable{name: "drifted"})
+
+	engine := gin.Default()
+	checker.RegisterEndpoints(engine.Group("/"))
+	serve := func(method, path string) (int, []*ConsistencyReport) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		engine.ServeHTTP(w, req)
+		var reports []*ConsistencyReport
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&reports))
+		}
+		return w.Code, reports
+	}
+
+	code, reports := serve(http.MethodGet, "/reports")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, reports)
+
+	code, reports = serve(http.MethodPost, "/check")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "consistent", reports[0].Plugin)
+	assert.Empty(t, reports[0].Discrepancies)
+	assert.Equal(t, "drifted", reports[1].Plugin)
+	assert.Equal(t, []Discrepancy{{Kind: "Fake", Key: "b"}, {Kind: "Fake", Key: "c"}}, reports[1].Discrepancies)
+	assert.Equal(t, "2", drifted.cache["b"])
+
+	code, _ = serve(http.MethodPost, "/check?repair=invalid")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, reports = serve(http.MethodPost, "/check?repair=true")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []Discrepancy{{Kind: "Fake", Key: "b", Repaired: true}, {Kind: "Fake", Key: "c", Repaired: true}}, reports[1].Discrepancies)
+	assert.Equal(t, drifted.expected, drifted.cache)
+
+	assert.Empty(t, checker.CheckAll(false)[1].Discrepancies)
+	code, reports = serve(http.MethodGet, "/reports")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, reports, 2)
+	assert.Empty(t, reports[1].Discrepancies)
+}
@@ -36,6 +36,10 @@ var (
 func AddFlags(fs *pflag.FlagSet) {
 	fs.IntVarP(&debugTopNScores, "debug-scores", "s", debugTopNScores, "logging topN nodes score and scores for each plugin after running the score extension, disable if set to 0")
 	fs.BoolVarP(&debugFilterFailure, "debug-filters", "f", debugFilterFailure, "logging filter failures")
+	addConsistencyCheckFlags(fs)
+	addDynamicArgsFlags(fs)
+	addTracingFlags(fs)
+	addShadowFlags(fs)
 }
 
 // DebugScoresSetter updates debugTopNScores to specified value
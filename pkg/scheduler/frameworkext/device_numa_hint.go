@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"sync"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// DeviceNUMAHintStateKey is the key in the CycleState to the NUMA nodes of the devices allocated to the pod.
+// It is written by DeviceShare and read by NodeNUMAResource to allocate the CPUs near the devices.
+const DeviceNUMAHintStateKey = "koordinator.sh/device-numa-hint"
+
+// DeviceNUMAHint is pending since the PreFilter of the pod requesting the devices,
+// records the NUMA nodes of the devices filtered on each node, and the NUMA nodes of the devices once they are reserved on a node.
+// The plugins reading the hint must Filter and Reserve after the plugin writing it.
+type DeviceNUMAHint struct {
+	// NodeName is the node on which the devices are reserved, empty if the devices are not reserved yet.
+	NodeName string
+	// NUMANodes is the NUMA nodes the reserved devices attached to, empty if the devices do not report the topology.
+	NUMANodes []int
+
+	// lock protects filtered since the nodes are filtered in parallel.
+	lock sync.RWMutex
+	// filtered is the NUMA nodes of the devices filtered by the node name.
+	filtered map[string][]int
+}
+
+func (h *DeviceNUMAHint) Clone() framework.StateData {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	var filtered map[string][]int
+	if h.filtered != nil {
+		filtered = make(map[string][]int, len(h.filtered))
+		for nodeName, numaNodes := range h.filtered {
+			filtered[nodeName] = append([]int(nil), numaNodes...)
+		}
+	}
+	return &DeviceNUMAHint{
+		NodeName:  h.NodeName,
+		NUMANodes: append([]int(nil), h.NUMANodes...),
+		filtered:  filtered,
+	}
+}
+
+// SetFilteredNUMANodes records the NUMA nodes of the devices filtered on the node.
+func (h *DeviceNUMAHint) SetFilteredNUMANodes(nodeName string, numaNodes []int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.filtered == nil {
+		h.filtered = map[string][]int{}
+	}
+	h.filtered[nodeName] = numaNodes
+}
+
+// GetFilteredNUMANodes returns the NUMA nodes of the devices filtered on the node,
+// and false if the devices are not filtered on the node yet.
+func (h *DeviceNUMAHint) GetFilteredNUMANodes(nodeName string) ([]int, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	numaNodes, ok := h.filtered[nodeName]
+	return numaNodes, ok
+}
+
+// IsReserved returns whether the devices are reserved on the node.
+func (h *DeviceNUMAHint) IsReserved(nodeName string) bool {
+	return h.NodeName != "" && h.NodeName == nodeName
+}
+
+// SetDeviceNUMAHint writes the hint into the CycleState.
+func SetDeviceNUMAHint(cycleState *framework.CycleState, hint *DeviceNUMAHint) {
+	cycleState.Write(DeviceNUMAHintStateKey, hint)
+}
+
+// GetDeviceNUMAHint returns the hint in the CycleState, nil if the pod does not request the devices.
+func GetDeviceNUMAHint(cycleState *framework.CycleState) *DeviceNUMAHint {
+	value, err := cycleState.Read(DeviceNUMAHintStateKey)
+	if err != nil {
+		return nil
+	}
+	hint, _ := value.(*DeviceNUMAHint)
+	return hint
+}
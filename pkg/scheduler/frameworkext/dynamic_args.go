@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+const (
+	// ReasonDynamicArgsRejected is the reason of the event on the ConfigMap when the dynamic args of a plugin
+	// are invalid and not applied.
+	ReasonDynamicArgsRejected = "DynamicArgsRejected"
+	// ReasonDynamicArgsApplied is the reason of the event on the ConfigMap when the dynamic args of a plugin
+	// are applied.
+	ReasonDynamicArgsApplied = "DynamicArgsApplied"
+)
+
+var (
+	// dynamicArgsConfigMap is the namespace/name of the ConfigMap containing the dynamic args of the plugins.
+	dynamicArgsConfigMap string
+)
+
+func addDynamicArgsFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&dynamicArgsConfigMap, "dynamic-args-configmap", dynamicArgsConfigMap, "the namespace/name of the ConfigMap containing the dynamic args of the plugins keyed by the plugin names, the args are reloaded without restarting the scheduler, disable if empty")
+}
+
+// DynamicArgs is implemented by the plugins whose args can be reloaded while the scheduler is running.
+// Only the fields explicitly marked dynamic, e.g. scoring strategies and thresholds, can be reloaded. The fields
+// building the informers or the allocators are fixed at the start.
+type DynamicArgs interface {
+	Name() string
+	// DecodeDynamicArgs decodes and validates the dynamic args, the data containing the non-dynamic fields is rejected.
+	DecodeDynamicArgs(data []byte) (interface{}, error)
+	// UpdateDynamicArgs swaps the decoded args into the running plugin atomically, so a scheduling cycle
+	// observes either the old args or the new args.
+	UpdateDynamicArgs(args interface{})
+}
+
+// DynamicArgsWatcher watches the designated ConfigMap and reloads the dynamic args of the registered plugins.
+// The ConfigMap data is keyed by the plugin names, and applied to the plugin instances of all profiles.
+// The args of a plugin are applied only if valid for every instance, otherwise the running instances keep
+// their args and a warning event is recorded on the ConfigMap.
+type DynamicArgsWatcher struct {
+	lock sync.Mutex
+	// plugins are the instances of each plugin, one per profile enabling it.
+	plugins map[string][]DynamicArgs
+	// appliedData is the data of the args applied to each plugin, the unchanged data is not decoded again.
+	appliedData map[string]string
+}
+
+func NewDynamicArgsWatcher() *DynamicArgsWatcher {
+	return &DynamicArgsWatcher{
+		plugins:     map[string][]DynamicArgs{},
+		appliedData: map[string]string{},
+	}
+}
+
+func (w *DynamicArgsWatcher) Register(plugin framework.Plugin) {
+	dynamicArgs, ok := plugin.(DynamicArgs)
+	if !ok {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.plugins[dynamicArgs.Name()] = append(w.plugins[dynamicArgs.Name()], dynamicArgs)
+	klog.Infof("register plugin:%v dynamic args, instances: %d", dynamicArgs.Name(), len(w.plugins[dynamicArgs.Name()]))
+}
+
+// Start watches the ConfigMap if it is designated.
+func (w *DynamicArgsWatcher) Start(client kubernetes.Interface, recorder events.EventRecorder, stopCh <-chan struct{}) {
+	if dynamicArgsConfigMap == "" {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(dynamicArgsConfigMap)
+	if err != nil || namespace == "" || name == "" {
+		klog.Errorf("invalid dynamic args ConfigMap %q, expect namespace/name", dynamicArgsConfigMap)
+		return
+	}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	informerFactory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			configMap, ok := obj.(*corev1.ConfigMap)
+			return ok && configMap.Name == name
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				w.Sync(obj.(*corev1.ConfigMap), recorder)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				w.Sync(newObj.(*corev1.ConfigMap), recorder)
+			},
+		},
+	})
+	informerFactory.Start(stopCh)
+	klog.Infof("start watching the dynamic args ConfigMap %s", dynamicArgsConfigMap)
+}
+
+// Sync decodes the args of each registered plugin in the ConfigMap and applies the changed valid ones.
+// The keys not matching any registered plugin are rejected, and the plugins removed from the ConfigMap keep their
+// current args.
+func (w *DynamicArgsWatcher) Sync(configMap *corev1.ConfigMap, recorder events.EventRecorder) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rejected []string
+	for _, pluginName := range keys {
+		data := configMap.Data[pluginName]
+		instances, ok := w.plugins[pluginName]
+		if !ok {
+			rejected = append(rejected, pluginName)
+			w.recordEvent(recorder, configMap, corev1.EventTypeWarning, ReasonDynamicArgsRejected,
+				fmt.Sprintf("plugin %s does not support dynamic args", pluginName))
+			continue
+		}
+		if applied, ok := w.appliedData[pluginName]; ok && applied == data {
+			continue
+		}
+		// each instance decodes the args against its own args at the start
+		decoded := make([]interface{}, 0, len(instances))
+		var err error
+		for _, instance := range instances {
+			var args interface{}
+			if args, err = instance.DecodeDynamicArgs([]byte(data)); err != nil {
+				break
+			}
+			decoded = append(decoded, args)
+		}
+		if err != nil {
+			rejected = append(rejected, pluginName)
+			w.recordEvent(recorder, configMap, corev1.EventTypeWarning, ReasonDynamicArgsRejected,
+				fmt.Sprintf("invalid dynamic args of plugin %s: %v", pluginName, err))
+			continue
+		}
+		for i, instance := range instances {
+			instance.UpdateDynamicArgs(decoded[i])
+		}
+		w.appliedData[pluginName] = data
+		w.recordEvent(recorder, configMap, corev1.EventTypeNormal, ReasonDynamicArgsApplied,
+			fmt.Sprintf("dynamic args of plugin %s applied", pluginName))
+		klog.Infof("dynamic args of plugin %s applied from ConfigMap %s/%s, resourceVersion: %s",
+			pluginName, configMap.Namespace, configMap.Name, configMap.ResourceVersion)
+	}
+	if len(rejected) > 0 {
+		klog.Warningf("dynamic args of plugins %s rejected from ConfigMap %s/%s, resourceVersion: %s",
+			strings.Join(rejected, ","), configMap.Namespace, configMap.Name, configMap.ResourceVersion)
+	}
+}
+
+func (w *DynamicArgsWatcher) recordEvent(recorder events.EventRecorder, configMap *corev1.ConfigMap, eventType, reason, message string) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(configMap, nil, eventType, reason, "ReloadDynamicArgs", "%s", message)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+)
+
+// fakeDynamicArgsPlugin accepts a positive integer as the dynamic args.
+type fakeDynamicArgsPlugin struct {
+	lock    sync.Mutex
+	value   int
+	decoded int
+}
+
+func (f *fakeDynamicArgsPlugin) Name() string { return "fake" }
+
+func (f *fakeDynamicArgsPlugin) DecodeDynamicArgs(data []byte) (interface{}, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.decoded++
+	value, err := strconv.Atoi(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if value <= 0 {
+		return nil, fmt.Errorf("value should be positive, got %v", value)
+	}
+	return value, nil
+}
+
+func (f *fakeDynamicArgsPlugin) UpdateDynamicArgs(args interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.value = args.(int)
+}
+
+func (f *fakeDynamicArgsPlugin) getValue() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.value
+}
+
+func TestDynamicArgsWatcher_Sync(t *testing.T) {
+	plugin := &fakeDynamicArgsPlugin{value: 1}
+	// the instance of the same plugin in another profile
+	otherPlugin := &fakeDynamicArgsPlugin{value: 1}
+	watcher := NewDynamicArgsWatcher()
+	watcher.Register(plugin)
+	watcher.Register(otherPlugin)
+	recorder := events.NewFakeRecorder(10)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dynamic-args"},
+		Data:       map[string]string{"fake": "2", "unknown": "3"},
+	}
+	watcher.Sync(configMap, recorder)
+	assert.Equal(t, 2, plugin.getValue())
+	assert.Equal(t, 2, otherPlugin.getValue())
+	assert.Contains(t, <-recorder.Events, ReasonDynamicArgsApplied)
+	assert.Contains(t, <-recorder.Events, ReasonDynamicArgsRejected)
+
+	// the unchanged args are not decoded again
+	delete(configMap.Data, "unknown")
+	watcher.Sync(configMap, recorder)
+	assert.Equal(t, 1, plugin.decoded)
+	assert.Len(t, recorder.Events, 0)
+
+	// the invalid args are rejected
+	configMap.Data["fake"] = "-1"
+	watcher.Sync(configMap, recorder)
+	assert.Equal(t, 2, plugin.getValue())
+	assert.Equal(t, 2, otherPlugin.getValue())
+	assert.Contains(t, <-recorder.Events, ReasonDynamicArgsRejected)
+}
+
+func TestDynamicArgsWatcher_Start(t *testing.T) {
+	dynamicArgsConfigMap = "default/dynamic-args"
+	defer func() {
+		dynamicArgsConfigMap = ""
+	}()
+
+	plugin := &fakeDynamicArgsPlugin{value: 1}
+	watcher := NewDynamicArgsWatcher()
+	watcher.Register(plugin)
+
+	client := kubefake.NewSimpleClientset()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	watcher.Start(client, events.NewFakeRecorder(10), stopCh)
+
+	// the other ConfigMaps are ignored
+	_, err := client.CoreV1().ConfigMaps("default").Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "others"},
+		Data:       map[string]string{"fake": "5"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	configMap, err := client.CoreV1().ConfigMaps("default").Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dynamic-args"},
+		Data:       map[string]string{"fake": "2"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return plugin.getValue() == 2, nil
+	})
+	assert.NoError(t, err)
+
+	configMap.Data["fake"] = "3"
+	_, err = client.CoreV1().ConfigMaps("default").Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return plugin.getValue() == 3, nil
+	})
+	assert.NoError(t, err)
+}
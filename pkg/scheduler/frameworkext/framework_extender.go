@@ -18,10 +18,13 @@ package frameworkext
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
@@ -39,6 +42,8 @@ type ExtendedHandle interface {
 	KoordinatorClientSet() koordinatorclientset.Interface
 	KoordinatorSharedInformerFactory() koordinatorinformers.SharedInformerFactory
 	SnapshotSharedLister() framework.SharedLister
+	// TracerProvider returns the provider of the tracers for the plugins, the tracers are no-op if no provider is set.
+	TracerProvider() trace.TracerProvider
 	Run()
 }
 
@@ -47,6 +52,7 @@ type extendedHandleOptions struct {
 	koordinatorClientSet             koordinatorclientset.Interface
 	koordinatorSharedInformerFactory koordinatorinformers.SharedInformerFactory
 	sharedListerAdapter              SharedListerAdapter
+	tracerProvider                   trace.TracerProvider
 }
 
 type SharedListerAdapter func(lister framework.SharedLister) framework.SharedLister
@@ -77,6 +83,12 @@ func WithSharedListerFactory(adapter SharedListerAdapter) Option {
 	}
 }
 
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(options *extendedHandleOptions) {
+		options.tracerProvider = tracerProvider
+	}
+}
+
 type frameworkExtendedHandleImpl struct {
 	once sync.Once
 	framework.Handle
@@ -84,7 +96,10 @@ type frameworkExtendedHandleImpl struct {
 	koordinatorClientSet             koordinatorclientset.Interface
 	koordinatorSharedInformerFactory koordinatorinformers.SharedInformerFactory
 	sharedListerAdapter              SharedListerAdapter
+	tracerProvider                   trace.TracerProvider
 	controllerMaps                   *ControllersMap
+	consistencyChecker               *ConsistencyChecker
+	dynamicArgsWatcher               *DynamicArgsWatcher
 }
 
 func NewExtendedHandle(options ...Option) (ExtendedHandle, error) {
@@ -97,17 +112,34 @@ func NewExtendedHandle(options ...Option) (ExtendedHandle, error) {
 		return nil, err
 	}
 
+	tracerProvider := handleOptions.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = trace.NewNoopTracerProvider()
+	}
+
+	consistencyChecker := NewConsistencyChecker()
+	if handleOptions.servicesEngine != nil {
+		handleOptions.servicesEngine.RegisterService("consistency", consistencyChecker)
+	}
+
 	return &frameworkExtendedHandleImpl{
 		servicesEngine:                   handleOptions.servicesEngine,
 		koordinatorClientSet:             handleOptions.koordinatorClientSet,
 		koordinatorSharedInformerFactory: handleOptions.koordinatorSharedInformerFactory,
 		sharedListerAdapter:              handleOptions.sharedListerAdapter,
+		tracerProvider:                   tracerProvider,
 		controllerMaps:                   NewControllersMap(),
+		consistencyChecker:               consistencyChecker,
+		dynamicArgsWatcher:               NewDynamicArgsWatcher(),
 	}, nil
 }
 
 func (ext *frameworkExtendedHandleImpl) Run() {
 	go ext.controllerMaps.Start()
+	go ext.consistencyChecker.Start()
+	if ext.Handle != nil {
+		ext.dynamicArgsWatcher.Start(ext.Handle.ClientSet(), ext.Handle.EventRecorder(), wait.NeverStop)
+	}
 }
 
 func (ext *frameworkExtendedHandleImpl) KoordinatorClientSet() koordinatorclientset.Interface {
@@ -118,6 +150,10 @@ func (ext *frameworkExtendedHandleImpl) KoordinatorSharedInformerFactory() koord
 	return ext.koordinatorSharedInformerFactory
 }
 
+func (ext *frameworkExtendedHandleImpl) TracerProvider() trace.TracerProvider {
+	return ext.tracerProvider
+}
+
 func (ext *frameworkExtendedHandleImpl) SnapshotSharedLister() framework.SharedLister {
 	if ext.sharedListerAdapter != nil {
 		return ext.sharedListerAdapter(ext.Handle.SnapshotSharedLister())
@@ -160,6 +196,8 @@ type frameworkExtenderFactoryImpl struct {
 	preFilterHooks []PreFilterPhaseHook
 	filterHooks    []FilterPhaseHook
 	scoreHooks     []ScorePhaseHook
+	// shadowProfiles are evaluated in the sampled scheduling cycles without affecting the placement
+	shadowProfiles []*ShadowProfile
 }
 
 func NewFrameworkExtenderFactory(handle ExtendedHandle, hooks ...SchedulingPhaseHook) FrameworkExtenderFactory {
@@ -167,6 +205,11 @@ func NewFrameworkExtenderFactory(handle ExtendedHandle, hooks ...SchedulingPhase
 		handle: handle,
 	}
 	for _, h := range hooks {
+		if shadow, ok := h.(*ShadowProfile); ok {
+			i.shadowProfiles = append(i.shadowProfiles, shadow)
+			klog.V(4).InfoS("framework extender got shadow profile registered", "shadow", shadow.Name())
+			continue
+		}
 		// a hook may register in multiple phases
 		preFilter, ok := h.(PreFilterPhaseHook)
 		if ok {
@@ -188,12 +231,21 @@ func NewFrameworkExtenderFactory(handle ExtendedHandle, hooks ...SchedulingPhase
 }
 
 func (i *frameworkExtenderFactoryImpl) New(f framework.Framework) FrameworkExtender {
+	// a shadow profile is not evaluated against itself
+	var shadowProfiles []*ShadowProfile
+	for _, shadow := range i.shadowProfiles {
+		if shadow.fwk == nil || shadow.fwk.ProfileName() != f.ProfileName() {
+			shadowProfiles = append(shadowProfiles, shadow)
+		}
+	}
 	return &frameworkExtenderImpl{
 		Framework:      f,
 		handle:         i.handle,
+		tracer:         i.handle.TracerProvider().Tracer(tracerName),
 		preFilterHooks: i.preFilterHooks,
 		filterHooks:    i.filterHooks,
 		scoreHooks:     i.scoreHooks,
+		shadowProfiles: shadowProfiles,
 	}
 }
 
@@ -202,14 +254,23 @@ var _ framework.Framework = &frameworkExtenderImpl{}
 type frameworkExtenderImpl struct {
 	framework.Framework
 	handle ExtendedHandle
+	// tracer starts the span of each scheduling cycle, the plugins' spans are the children of the cycle span.
+	tracer trace.Tracer
 
 	preFilterHooks []PreFilterPhaseHook
 	filterHooks    []FilterPhaseHook
 	scoreHooks     []ScorePhaseHook
+	shadowProfiles []*ShadowProfile
 }
 
 // RunPreFilterPlugins hooks the PreFilter phase of framework with pre-filter hooks.
+// The scheduling cycle starts here, so the span of the cycle is started if the cycle is sampled.
 func (ext *frameworkExtenderImpl) RunPreFilterPlugins(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+	ctx = startSchedulingCycleTrace(ctx, ext.tracer, cycleState, pod)
+	cycleTrace := getSchedulingCycleTrace(cycleState)
+	if cycleTrace != nil {
+		cycleTrace.span.SetAttributes(attributeKeyNodesTotal.Int(ext.numNodes()))
+	}
 	for _, hook := range ext.preFilterHooks {
 		newPod, hooked := hook.PreFilterHook(ext.handle, cycleState, pod)
 		if hooked {
@@ -217,12 +278,31 @@ func (ext *frameworkExtenderImpl) RunPreFilterPlugins(ctx context.Context, cycle
 			pod = newPod
 		}
 	}
-	return ext.Framework.RunPreFilterPlugins(ctx, cycleState, pod)
+	if len(ext.shadowProfiles) > 0 {
+		startShadowCycles(ctx, ext.shadowProfiles, cycleState, pod)
+	}
+	status := ext.Framework.RunPreFilterPlugins(ctx, cycleState, pod)
+	if cycleTrace != nil {
+		cycleTrace.recordRejection(status, false)
+	}
+	return status
+}
+
+func (ext *frameworkExtenderImpl) numNodes() int {
+	if ext.SnapshotSharedLister() == nil {
+		return 0
+	}
+	nodeInfos, err := ext.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return 0
+	}
+	return len(nodeInfos)
 }
 
 // RunFilterPluginsWithNominatedPods hooks the Filter phase of framework with filter hooks.
 // We don't hook RunFilterPlugins since framework's RunFilterPluginsWithNominatedPods just calls its RunFilterPlugins.
 func (ext *frameworkExtenderImpl) RunFilterPluginsWithNominatedPods(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	ctx, cycleTrace := contextWithSchedulingCycleSpan(ctx, cycleState)
 	for _, hook := range ext.filterHooks {
 		// hook can change the args (cycleState, pod, nodeInfo) for filter plugins
 		newPod, newNodeInfo, hooked := hook.FilterHook(ext.handle, cycleState, pod, nodeInfo)
@@ -233,6 +313,12 @@ func (ext *frameworkExtenderImpl) RunFilterPluginsWithNominatedPods(ctx context.
 		}
 	}
 	status := ext.Framework.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo)
+	if cycleTrace != nil {
+		cycleTrace.recordRejection(status, true)
+	}
+	for _, sc := range getShadowCycles(cycleState) {
+		sc.filter(ctx, nodeInfo, status.IsSuccess())
+	}
 	if !status.IsSuccess() && debugFilterFailure {
 		klog.Infof("Failed to filter for Pod %q on Node %q, failedPlugin: %s, reason: %s", klog.KObj(pod), klog.KObj(nodeInfo.Node()), status.FailedPlugin(), status.Message())
 	}
@@ -240,6 +326,10 @@ func (ext *frameworkExtenderImpl) RunFilterPluginsWithNominatedPods(ctx context.
 }
 
 func (ext *frameworkExtenderImpl) RunScorePlugins(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodes []*corev1.Node) (framework.PluginToNodeScores, *framework.Status) {
+	ctx, cycleTrace := contextWithSchedulingCycleSpan(ctx, state)
+	if cycleTrace != nil {
+		cycleTrace.span.SetAttributes(attributeKeyNodesFeasible.Int(len(nodes)))
+	}
 	for _, hook := range ext.scoreHooks {
 		// hook can change the args (cycleState, pod, nodeInfo) for score plugins
 		newPod, newNodes, hooked := hook.ScoreHook(ext.handle, state, pod, nodes)
@@ -256,6 +346,48 @@ func (ext *frameworkExtenderImpl) RunScorePlugins(ctx context.Context, state *fr
 	return pluginToNodeScores, status
 }
 
+// RunPostFilterPlugins ends the span of the scheduling cycle since the pod is unschedulable in this cycle.
+// The shadow profiles are compared before the PostFilter plugins, which run the Filter again to preempt.
+func (ext *frameworkExtenderImpl) RunPostFilterPlugins(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	ctx, cycleTrace := contextWithSchedulingCycleSpan(ctx, state)
+	finishShadowCycles(ctx, state, pod, "")
+	result, status := ext.Framework.RunPostFilterPlugins(ctx, state, pod, filteredNodeStatusMap)
+	if cycleTrace != nil {
+		cycleTrace.end(framework.Unschedulable.String(), nil)
+	}
+	return result, status
+}
+
+// RunReservePluginsReserve compares the shadow profiles with the node chosen in this cycle.
+func (ext *frameworkExtenderImpl) RunReservePluginsReserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	ctx, _ = contextWithSchedulingCycleSpan(ctx, state)
+	finishShadowCycles(ctx, state, pod, nodeName)
+	return ext.Framework.RunReservePluginsReserve(ctx, state, pod, nodeName)
+}
+
+// RunReservePluginsUnreserve ends the span of the scheduling cycle since the pod fails in Reserve, Permit or binding.
+func (ext *frameworkExtenderImpl) RunReservePluginsUnreserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
+	ctx, cycleTrace := contextWithSchedulingCycleSpan(ctx, state)
+	ext.Framework.RunReservePluginsUnreserve(ctx, state, pod, nodeName)
+	if cycleTrace != nil {
+		cycleTrace.end("Unreserved", fmt.Errorf("the pod is unreserved from node %s", nodeName))
+	}
+}
+
+func (ext *frameworkExtenderImpl) RunPreBindPlugins(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	ctx, _ = contextWithSchedulingCycleSpan(ctx, state)
+	return ext.Framework.RunPreBindPlugins(ctx, state, pod, nodeName)
+}
+
+// RunPostBindPlugins ends the span of the scheduling cycle since the pod is bound.
+func (ext *frameworkExtenderImpl) RunPostBindPlugins(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
+	ctx, cycleTrace := contextWithSchedulingCycleSpan(ctx, state)
+	ext.Framework.RunPostBindPlugins(ctx, state, pod, nodeName)
+	if cycleTrace != nil {
+		cycleTrace.end("Bound", nil)
+	}
+}
+
 // PluginFactoryProxy is used to proxy the call to the PluginFactory function and pass in the ExtendedHandle for the custom plugin
 func PluginFactoryProxy(extendHandle ExtendedHandle, factoryFn frameworkruntime.PluginFactory) frameworkruntime.PluginFactory {
 	return func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
@@ -273,6 +405,12 @@ func PluginFactoryProxy(extendHandle ExtendedHandle, factoryFn frameworkruntime.
 		if impl.controllerMaps != nil {
 			impl.controllerMaps.RegisterControllers(plugin)
 		}
+		if impl.consistencyChecker != nil {
+			impl.consistencyChecker.Register(plugin)
+		}
+		if impl.dynamicArgsWatcher != nil {
+			impl.dynamicArgsWatcher.Register(plugin)
+		}
 		return plugin, nil
 	}
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"fmt"
+
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+)
+
+// pluginOrder requires the plugin Before to run before the plugin After at an extension point
+// if both of them are enabled, since After reads the CycleState written by Before.
+type pluginOrder struct {
+	Before string
+	After  string
+}
+
+var (
+	// filterPluginOrders is the orders of the Filter plugins sharing the CycleState.
+	// NodeNUMAResource reads the NUMA nodes of the devices filtered by DeviceShare on the node.
+	filterPluginOrders = []pluginOrder{
+		{Before: "DeviceShare", After: "NodeNUMAResource"},
+	}
+	// reservePluginOrders is the orders of the Reserve plugins sharing the CycleState.
+	// DeviceShare reads the reservation assumed by Reservation,
+	// and NodeNUMAResource reads the NUMA nodes of the devices reserved by DeviceShare.
+	reservePluginOrders = []pluginOrder{
+		{Before: "Reservation", After: "DeviceShare"},
+		{Before: "DeviceShare", After: "NodeNUMAResource"},
+	}
+)
+
+// ValidatePluginOrders validates the plugins of the profile sharing the CycleState are enabled in the required orders.
+func ValidatePluginOrders(profile *kubeschedulerconfig.KubeSchedulerProfile) error {
+	if profile.Plugins == nil {
+		return nil
+	}
+	if err := validatePluginSetOrders(profile.SchedulerName, "filter", &profile.Plugins.Filter, filterPluginOrders); err != nil {
+		return err
+	}
+	return validatePluginSetOrders(profile.SchedulerName, "reserve", &profile.Plugins.Reserve, reservePluginOrders)
+}
+
+func validatePluginSetOrders(schedulerName, extensionPoint string, pluginSet *kubeschedulerconfig.PluginSet, orders []pluginOrder) error {
+	indexes := make(map[string]int, len(pluginSet.Enabled))
+	for i, plugin := range pluginSet.Enabled {
+		indexes[plugin.Name] = i
+	}
+	for _, order := range orders {
+		before, ok := indexes[order.Before]
+		if !ok {
+			continue
+		}
+		after, ok := indexes[order.After]
+		if !ok {
+			continue
+		}
+		if before > after {
+			return fmt.Errorf("profile %q: %s plugin %s must be enabled before %s", schedulerName, extensionPoint, order.Before, order.After)
+		}
+	}
+	return nil
+}
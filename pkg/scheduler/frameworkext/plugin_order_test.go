@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	schedconfigv1beta2 "k8s.io/kube-scheduler/config/v1beta2"
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	kubeschedulerconfigv1beta2 "k8s.io/kubernetes/pkg/scheduler/apis/config/v1beta2"
+	"sigs.k8s.io/yaml"
+)
+
+func newPluginSet(names ...string) kubeschedulerconfig.PluginSet {
+	pluginSet := kubeschedulerconfig.PluginSet{}
+	for _, name := range names {
+		pluginSet.Enabled = append(pluginSet.Enabled, kubeschedulerconfig.Plugin{Name: name})
+	}
+	return pluginSet
+}
+
+func TestValidatePluginOrders(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  kubeschedulerconfig.PluginSet
+		reserve kubeschedulerconfig.PluginSet
+		wantErr string
+	}{
+		{
+			name:    "valid orders",
+			filter:  newPluginSet("LoadAwareScheduling", "DeviceShare", "NodeNUMAResource", "Reservation"),
+			reserve: newPluginSet("LoadAwareScheduling", "Reservation", "DeviceShare", "NodeNUMAResource"),
+		},
+		{
+			name:    "ignore the plugins not enabled",
+			filter:  newPluginSet("NodeNUMAResource"),
+			reserve: newPluginSet("NodeNUMAResource", "Reservation"),
+		},
+		{
+			name:    "DeviceShare filters after NodeNUMAResource",
+			filter:  newPluginSet("NodeNUMAResource", "DeviceShare"),
+			reserve: newPluginSet("Reservation", "DeviceShare", "NodeNUMAResource"),
+			wantErr: `profile "koord-scheduler": filter plugin DeviceShare must be enabled before NodeNUMAResource`,
+		},
+		{
+			name:    "DeviceShare reserves before Reservation",
+			filter:  newPluginSet("DeviceShare", "NodeNUMAResource"),
+			reserve: newPluginSet("DeviceShare", "Reservation", "NodeNUMAResource"),
+			wantErr: `profile "koord-scheduler": reserve plugin Reservation must be enabled before DeviceShare`,
+		},
+		{
+			name:    "DeviceShare reserves after NodeNUMAResource",
+			filter:  newPluginSet("DeviceShare", "NodeNUMAResource"),
+			reserve: newPluginSet("Reservation", "NodeNUMAResource", "DeviceShare"),
+			wantErr: `profile "koord-scheduler": reserve plugin DeviceShare must be enabled before NodeNUMAResource`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &kubeschedulerconfig.KubeSchedulerProfile{
+				SchedulerName: "koord-scheduler",
+				Plugins: &kubeschedulerconfig.Plugins{
+					Filter:  tt.filter,
+					Reserve: tt.reserve,
+				},
+			}
+			err := ValidatePluginOrders(profile)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePluginOrdersOfShippedConfig(t *testing.T) {
+	data, err := os.ReadFile("../../../config/manager/scheduler-config.yaml")
+	assert.NoError(t, err)
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, yaml.Unmarshal(data, cm))
+	cfg := &schedconfigv1beta2.KubeSchedulerConfiguration{}
+	assert.NoError(t, yaml.Unmarshal([]byte(cm.Data["koord-scheduler-config"]), cfg))
+	assert.NotEmpty(t, cfg.Profiles)
+	for i := range cfg.Profiles {
+		profile := &kubeschedulerconfig.KubeSchedulerProfile{Plugins: &kubeschedulerconfig.Plugins{}}
+		if cfg.Profiles[i].SchedulerName != nil {
+			profile.SchedulerName = *cfg.Profiles[i].SchedulerName
+		}
+		assert.NotNil(t, cfg.Profiles[i].Plugins)
+		assert.NoError(t, kubeschedulerconfigv1beta2.Convert_v1beta2_Plugins_To_config_Plugins(cfg.Profiles[i].Plugins, profile.Plugins, nil))
+		assert.NoError(t, ValidatePluginOrders(profile), profile.SchedulerName)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// ReservationNominationStateKey is the key in the CycleState to the reservations nominated to the pod.
+// It is written by Reservation and read by the plugins allocating the resources held by the reservations, e.g. DeviceShare.
+const ReservationNominationStateKey = "koordinator.sh/reservation-nomination"
+
+// ReservationNomination records the available reservations matching the pod since the PreFilter,
+// and the reservation the pod is assumed to allocate once the pod is reserved on a node.
+// The plugins reading the assumed reservation must Reserve after the plugin writing it.
+type ReservationNomination struct {
+	// Matched is the available reservations matching the pod by the node name.
+	Matched map[string][]*schedulingv1alpha1.Reservation
+	// Assumed is the reservation the pod is assumed to allocate, nil if the pod is not reserved on a reservation.
+	Assumed *schedulingv1alpha1.Reservation
+}
+
+func (n *ReservationNomination) Clone() framework.StateData {
+	matched := make(map[string][]*schedulingv1alpha1.Reservation, len(n.Matched))
+	for nodeName, reservations := range n.Matched {
+		matched[nodeName] = append([]*schedulingv1alpha1.Reservation(nil), reservations...)
+	}
+	return &ReservationNomination{
+		Matched: matched,
+		Assumed: n.Assumed,
+	}
+}
+
+// GetAssumedOnNode returns the reservation the pod is assumed to allocate on the node, nil if none.
+func (n *ReservationNomination) GetAssumedOnNode(nodeName string) *schedulingv1alpha1.Reservation {
+	if n == nil || n.Assumed == nil || n.Assumed.Status.NodeName != nodeName {
+		return nil
+	}
+	return n.Assumed
+}
+
+// GetMatchedOnNode returns the available reservations matching the pod on the node.
+func (n *ReservationNomination) GetMatchedOnNode(nodeName string) []*schedulingv1alpha1.Reservation {
+	if n == nil {
+		return nil
+	}
+	return n.Matched[nodeName]
+}
+
+// SetReservationNomination writes the nomination into the CycleState.
+func SetReservationNomination(cycleState *framework.CycleState, nomination *ReservationNomination) {
+	cycleState.Write(ReservationNominationStateKey, nomination)
+}
+
+// GetReservationNomination returns the nomination in the CycleState, nil if the pod matches no reservation.
+func GetReservationNomination(cycleState *framework.CycleState) *ReservationNomination {
+	value, err := cycleState.Read(ReservationNominationStateKey)
+	if err != nil {
+		return nil
+	}
+	nomination, _ := value.(*ReservationNomination)
+	return nomination
+}
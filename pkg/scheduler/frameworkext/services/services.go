@@ -87,6 +87,12 @@ func (e *Engine) RegisterPluginService(plugin framework.Plugin) {
 	}
 }
 
+// RegisterService registers the endpoints of the serviceProvider under the services base path with the name.
+func (e *Engine) RegisterService(name string, serviceProvider APIServiceProvider) {
+	baseGroup := e.Engine.Group(servicesBaseRelativePath)
+	serviceProvider.RegisterEndpoints(baseGroup.Group(name))
+}
+
 func listRegisteredServices(e *gin.Engine) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		routes := e.Routes()
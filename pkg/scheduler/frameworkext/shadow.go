@@ -0,0 +1,393 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	shadowCycleStateKey = "koordinator.sh/shadow-cycles"
+
+	// ShadowDivergenceChosenNode means the node chosen by the active configuration is not the best of the shadow.
+	ShadowDivergenceChosenNode = "chosen_node"
+	// ShadowDivergenceFeasibleNodes means the active configuration and the shadow find different numbers of feasible nodes.
+	ShadowDivergenceFeasibleNodes = "feasible_nodes"
+)
+
+var (
+	shadowEvaluations = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "scheduler",
+			Name:           "shadow_evaluations_total",
+			Help:           "Number of scheduling cycles evaluated by the shadow profile, by the profile",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile"})
+
+	shadowDivergences = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "scheduler",
+			Name:           "shadow_divergences_total",
+			Help:           "Number of scheduling cycles in which the shadow profile diverges from the active configuration, by the profile, by the divergence type",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "type"})
+
+	registerShadowMetrics sync.Once
+
+	// shadowProfileNames are the names of the scheduler profiles evaluated as the shadows of the other profiles.
+	shadowProfileNames []string
+	// shadowSampleRate is the fraction of the scheduling cycles evaluated by the shadow profiles.
+	shadowSampleRate = 0.01
+)
+
+func addShadowFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&shadowProfileNames, "shadow-profiles", shadowProfileNames, "the names of the scheduler profiles evaluated as the shadows of the other profiles in the sampled scheduling cycles without affecting the placement, the shadow profiles should not be used by any pod, disable if empty")
+	fs.Float64Var(&shadowSampleRate, "shadow-sample-rate", shadowSampleRate, "the fraction of the scheduling cycles evaluated by the shadow profiles, in the range of [0, 1]")
+}
+
+// ShadowProfile evaluates the plugins of another configuration in the sampled scheduling cycles, and compares its
+// decisions with the active configuration without affecting the placement. The plugins run on the clones of the
+// cycle state, the pod and the nodes, and their results only go to the divergence metrics and the logs.
+// The overhead is bounded by the sample rate since nothing is done in the cycles not sampled.
+// It is registered into NewFrameworkExtenderFactory along with the scheduling hooks, e.g. the scheduler profiles named
+// by --shadow-profiles.
+type ShadowProfile struct {
+	name       string
+	sampleRate float64
+
+	// fwk runs the plugins of a scheduler profile instead of the plugins below if set.
+	fwk framework.Framework
+
+	preFilterPlugins []framework.PreFilterPlugin
+	filterPlugins    []framework.FilterPlugin
+	preScorePlugins  []framework.PreScorePlugin
+	scorePlugins     []framework.ScorePlugin
+	scoreWeights     []int64
+}
+
+var _ SchedulingPhaseHook = &ShadowProfile{}
+
+// NewShadowProfile returns the shadow profile running the plugins in the extension points they implement.
+// The weights of the score plugins are got by the plugin name, 1 by default.
+func NewShadowProfile(name string, sampleRate float64, plugins []framework.Plugin, scoreWeights map[string]int64) (*ShadowProfile, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("shadow profile %s sample rate must be in the range of [0, 1], got %v", name, sampleRate)
+	}
+	registerShadowMetrics.Do(func() {
+		legacyregistry.MustRegister(shadowEvaluations, shadowDivergences)
+	})
+
+	p := &ShadowProfile{
+		name:       name,
+		sampleRate: sampleRate,
+	}
+	for _, plugin := range plugins {
+		if preFilter, ok := plugin.(framework.PreFilterPlugin); ok {
+			p.preFilterPlugins = append(p.preFilterPlugins, preFilter)
+		}
+		if filter, ok := plugin.(framework.FilterPlugin); ok {
+			p.filterPlugins = append(p.filterPlugins, filter)
+		}
+		if preScore, ok := plugin.(framework.PreScorePlugin); ok {
+			p.preScorePlugins = append(p.preScorePlugins, preScore)
+		}
+		if score, ok := plugin.(framework.ScorePlugin); ok {
+			weight, ok := scoreWeights[score.Name()]
+			if !ok {
+				weight = 1
+			}
+			p.scorePlugins = append(p.scorePlugins, score)
+			p.scoreWeights = append(p.scoreWeights, weight)
+		}
+	}
+	return p, nil
+}
+
+// NewShadowProfileFromFramework returns the shadow profile running the plugins of a scheduler profile, the fwk must
+// be the framework not extended by NewFrameworkExtenderFactory.
+func NewShadowProfileFromFramework(sampleRate float64, fwk framework.Framework) (*ShadowProfile, error) {
+	p, err := NewShadowProfile(fwk.ProfileName(), sampleRate, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.fwk = fwk
+	return p, nil
+}
+
+// NewShadowProfilesFromFlags returns the shadow profiles of the scheduler profiles named by --shadow-profiles.
+func NewShadowProfilesFromFlags(profiles map[string]framework.Framework) ([]SchedulingPhaseHook, error) {
+	var hooks []SchedulingPhaseHook
+	for _, name := range shadowProfileNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		fwk, ok := profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("shadow profile %s is not a scheduler profile", name)
+		}
+		p, err := NewShadowProfileFromFramework(shadowSampleRate, fwk)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, p)
+	}
+	return hooks, nil
+}
+
+func (p *ShadowProfile) Name() string { return p.name }
+
+// preFilter returns false if the shadow PreFilter rejects the pod.
+func (p *ShadowProfile) preFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) bool {
+	if p.fwk != nil {
+		return p.fwk.RunPreFilterPlugins(ctx, cycleState, pod).IsSuccess()
+	}
+	for _, plugin := range p.preFilterPlugins {
+		if status := plugin.PreFilter(ctx, cycleState, pod); !status.IsSuccess() {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns true if the node passes the shadow Filter.
+func (p *ShadowProfile) filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) bool {
+	if p.fwk != nil {
+		return p.fwk.RunFilterPlugins(ctx, cycleState, pod, nodeInfo).Merge().IsSuccess()
+	}
+	for _, plugin := range p.filterPlugins {
+		if status := plugin.Filter(ctx, cycleState, pod, nodeInfo); !status.IsSuccess() {
+			return false
+		}
+	}
+	return true
+}
+
+// score returns the total weighted scores of the nodes by the shadow Score.
+func (p *ShadowProfile) score(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodes []*corev1.Node) ([]int64, error) {
+	totalScores := make([]int64, len(nodes))
+	if p.fwk != nil {
+		if status := p.fwk.RunPreScorePlugins(ctx, cycleState, pod, nodes); !status.IsSuccess() {
+			return nil, fmt.Errorf("PreScore failed, %s", status.Message())
+		}
+		pluginToNodeScores, status := p.fwk.RunScorePlugins(ctx, cycleState, pod, nodes)
+		if !status.IsSuccess() {
+			return nil, fmt.Errorf("Score failed, %s", status.Message())
+		}
+		for _, scores := range pluginToNodeScores {
+			for j := range scores {
+				totalScores[j] += scores[j].Score
+			}
+		}
+		return totalScores, nil
+	}
+
+	for _, plugin := range p.preScorePlugins {
+		if status := plugin.PreScore(ctx, cycleState, pod, nodes); !status.IsSuccess() {
+			return nil, fmt.Errorf("PreScore plugin %s failed, %s", plugin.Name(), status.Message())
+		}
+	}
+	for i, plugin := range p.scorePlugins {
+		scores := make(framework.NodeScoreList, len(nodes))
+		for j, node := range nodes {
+			score, status := plugin.Score(ctx, cycleState, pod, node.Name)
+			if !status.IsSuccess() {
+				return nil, fmt.Errorf("Score plugin %s failed, %s", plugin.Name(), status.Message())
+			}
+			scores[j] = framework.NodeScore{Name: node.Name, Score: score}
+		}
+		if extensions := plugin.ScoreExtensions(); extensions != nil {
+			if status := extensions.NormalizeScore(ctx, cycleState, pod, scores); !status.IsSuccess() {
+				return nil, fmt.Errorf("NormalizeScore of plugin %s failed, %s", plugin.Name(), status.Message())
+			}
+		}
+		for j := range scores {
+			totalScores[j] += scores[j].Score * p.scoreWeights[i]
+		}
+	}
+	return totalScores, nil
+}
+
+// shadowCycle is the evaluation of a shadow profile in a sampled scheduling cycle.
+type shadowCycle struct {
+	profile *ShadowProfile
+	state   *framework.CycleState
+	pod     *corev1.Pod
+	// rejected is true if the shadow PreFilter rejects the pod, so no node is feasible.
+	rejected bool
+
+	lock           sync.Mutex
+	activeFeasible int
+	shadowFeasible []*corev1.Node
+}
+
+// shadowCycles holds the evaluations of a scheduling cycle in the CycleState. The clones of the state, e.g. in the
+// preemption, get the copies of the evaluations, so the nodes filtered on the clones are not counted in the cycle.
+type shadowCycles []*shadowCycle
+
+func (s shadowCycles) Clone() framework.StateData {
+	cloned := make(shadowCycles, 0, len(s))
+	for _, sc := range s {
+		cloned = append(cloned, sc.clone())
+	}
+	return cloned
+}
+
+func (sc *shadowCycle) clone() *shadowCycle {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	return &shadowCycle{
+		profile:        sc.profile,
+		state:          sc.state.Clone(),
+		pod:            sc.pod.DeepCopy(),
+		rejected:       sc.rejected,
+		activeFeasible: sc.activeFeasible,
+		shadowFeasible: append([]*corev1.Node(nil), sc.shadowFeasible...),
+	}
+}
+
+func getShadowCycles(cycleState *framework.CycleState) shadowCycles {
+	if cycleState == nil {
+		return nil
+	}
+	value, err := cycleState.Read(shadowCycleStateKey)
+	if err != nil {
+		return nil
+	}
+	cycles, _ := value.(shadowCycles)
+	return cycles
+}
+
+// startShadowCycles samples the cycle for each shadow profile, and runs the shadow PreFilter on the clones of the
+// inputs before the active PreFilter changes the cycle state.
+func startShadowCycles(ctx context.Context, profiles []*ShadowProfile, cycleState *framework.CycleState, pod *corev1.Pod) {
+	var cycles shadowCycles
+	for _, profile := range profiles {
+		if profile.sampleRate <= 0 || rand.Float64() >= profile.sampleRate {
+			continue
+		}
+		sc := &shadowCycle{
+			profile: profile,
+			state:   cycleState.Clone(),
+			pod:     pod.DeepCopy(),
+		}
+		sc.rejected = !profile.preFilter(ctx, sc.state, sc.pod)
+		cycles = append(cycles, sc)
+	}
+	if len(cycles) > 0 {
+		cycleState.Write(shadowCycleStateKey, cycles)
+	}
+}
+
+// filter runs the shadow Filter on a node evaluated by the active Filter. It is called in parallel for the nodes.
+// The nominated pods are not added to the node for the shadow.
+func (sc *shadowCycle) filter(ctx context.Context, nodeInfo *framework.NodeInfo, activeFeasible bool) {
+	shadowFeasible := !sc.rejected && nodeInfo.Node() != nil
+	if shadowFeasible {
+		shadowFeasible = sc.profile.filter(ctx, sc.state, sc.pod, nodeInfo.Clone())
+	}
+
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	if activeFeasible {
+		sc.activeFeasible++
+	}
+	if shadowFeasible {
+		sc.shadowFeasible = append(sc.shadowFeasible, nodeInfo.Node())
+	}
+}
+
+// bestNodes returns the feasible nodes of the highest total score by the shadow Score.
+func (sc *shadowCycle) bestNodes(ctx context.Context) ([]string, error) {
+	nodes := sc.shadowFeasible
+	if len(nodes) <= 1 {
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		return names, nil
+	}
+
+	totalScores, err := sc.profile.score(ctx, sc.state, sc.pod, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var best []string
+	var maxScore int64
+	for i, node := range nodes {
+		if len(best) == 0 || totalScores[i] > maxScore {
+			best, maxScore = []string{node.Name}, totalScores[i]
+		} else if totalScores[i] == maxScore {
+			best = append(best, node.Name)
+		}
+	}
+	return best, nil
+}
+
+// finishShadowCycles compares the decisions of the shadow profiles with the node chosen by the active configuration,
+// which is empty if the pod is unschedulable. The node chosen is not a divergence if it ties for the best of the shadow.
+func finishShadowCycles(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, chosenNode string) {
+	cycles := getShadowCycles(cycleState)
+	if len(cycles) == 0 {
+		return
+	}
+	cycleState.Delete(shadowCycleStateKey)
+
+	for _, sc := range cycles {
+		name := sc.profile.name
+		shadowEvaluations.WithLabelValues(name).Inc()
+
+		sc.lock.Lock()
+		activeFeasible, shadowFeasible := sc.activeFeasible, len(sc.shadowFeasible)
+		sc.lock.Unlock()
+		if activeFeasible != shadowFeasible {
+			shadowDivergences.WithLabelValues(name, ShadowDivergenceFeasibleNodes).Inc()
+		}
+
+		bestNodes, err := sc.bestNodes(ctx)
+		if err != nil {
+			klog.V(4).InfoS("Failed to evaluate the shadow profile", "profile", name, "pod", klog.KObj(pod), "err", err)
+			continue
+		}
+		chosenDiverged := len(bestNodes) > 0 || chosenNode != ""
+		for _, node := range bestNodes {
+			if node == chosenNode {
+				chosenDiverged = false
+				break
+			}
+		}
+		if chosenDiverged {
+			shadowDivergences.WithLabelValues(name, ShadowDivergenceChosenNode).Inc()
+		}
+		if chosenDiverged || activeFeasible != shadowFeasible {
+			klog.V(4).InfoS("Shadow profile diverged from the active configuration", "profile", name, "pod", klog.KObj(pod),
+				"chosenNode", chosenNode, "shadowBestNodes", bestNodes, "activeFeasibleNodes", activeFeasible, "shadowFeasibleNodes", shadowFeasible)
+		}
+	}
+}
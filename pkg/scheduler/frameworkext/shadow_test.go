@@ -0,0 +1,318 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+const shadowTestStateKey = "koordinator.sh/shadow-test"
+
+var (
+	_ framework.PreFilterPlugin = &shadowTestPlugin{}
+	_ framework.FilterPlugin    = &shadowTestPlugin{}
+	_ framework.ScorePlugin     = &shadowTestPlugin{}
+)
+
+// shadowTestPlugin rejects the nodes with the label rejectLabel, and prefers the nodes with the label preferLabel.
+type shadowTestPlugin struct {
+	rejectLabel string
+	preferLabel string
+}
+
+func (p *shadowTestPlugin) Name() string { return "ShadowTestPlugin" }
+
+func (p *shadowTestPlugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+	cycleState.Write(shadowTestStateKey, shadowCycles{})
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations["shadow"] = "true"
+	return nil
+}
+
+func (p *shadowTestPlugin) PreFilterExtensions() framework.PreFilterExtensions { return nil }
+
+func (p *shadowTestPlugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if _, ok := nodeInfo.Node().Labels[p.rejectLabel]; ok {
+		return framework.NewStatus(framework.Unschedulable, "node(s) rejected by shadow")
+	}
+	return nil
+}
+
+func (p *shadowTestPlugin) Score(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	if nodeName == p.preferLabel {
+		return framework.MaxNodeScore, nil
+	}
+	return 0, nil
+}
+
+func (p *shadowTestPlugin) ScoreExtensions() framework.ScoreExtensions { return nil }
+
+func newShadowTestFramework(t *testing.T, nodes []*corev1.Node, shadows ...*ShadowProfile) FrameworkExtender {
+	extendedHandle, err := NewExtendedHandle()
+	assert.NoError(t, err)
+	newPlugin := func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+		return &tracingTestPlugin{}, nil
+	}
+	fh, err := schedulertesting.NewFramework(
+		[]schedulertesting.RegisterPluginFunc{
+			schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			schedulertesting.RegisterPluginAsExtensions(tracingTestPluginName, newPlugin, "PreFilter", "Filter", "Reserve", "PreBind"),
+		},
+		"koord-scheduler",
+		frameworkruntime.WithSnapshotSharedLister(newTracingTestSharedLister(nodes)),
+		frameworkruntime.WithPodNominator(&tracingTestPodNominator{}),
+	)
+	assert.NoError(t, err)
+	var hooks []SchedulingPhaseHook
+	for _, shadow := range shadows {
+		hooks = append(hooks, shadow)
+	}
+	return NewFrameworkExtenderFactory(extendedHandle, hooks...).New(fh)
+}
+
+func getShadowTestCounter(t *testing.T, profile, divergence string) float64 {
+	var value float64
+	var err error
+	if divergence == "" {
+		value, err = testutil.GetCounterMetricValue(shadowEvaluations.WithLabelValues(profile))
+	} else {
+		value, err = testutil.GetCounterMetricValue(shadowDivergences.WithLabelValues(profile, divergence))
+	}
+	assert.NoError(t, err)
+	return value
+}
+
+func TestShadowProfile(t *testing.T) {
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"shadow-reject": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-c"}},
+	}
+	rejectAll := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"reject": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"reject": ""}}},
+	}
+	tests := []struct {
+		name               string
+		nodes              []*corev1.Node
+		sampleRate         float64
+		plugin             *shadowTestPlugin
+		wantEvaluations    float64
+		wantChosenNode     float64
+		wantFeasibleNodes  float64
+		wantShadowFeasible int
+	}{
+		{
+			name:            "same decisions",
+			nodes:           nodes,
+			sampleRate:      1,
+			plugin:          &shadowTestPlugin{rejectLabel: "reject", preferLabel: "node-a"},
+			wantEvaluations: 1,
+		},
+		{
+			name:            "the chosen node ties for the best",
+			nodes:           nodes,
+			sampleRate:      1,
+			plugin:          &shadowTestPlugin{rejectLabel: "reject"},
+			wantEvaluations: 1,
+		},
+		{
+			name:              "different chosen node and feasible nodes",
+			nodes:             nodes,
+			sampleRate:        1,
+			plugin:            &shadowTestPlugin{rejectLabel: "shadow-reject", preferLabel: "node-c"},
+			wantEvaluations:   1,
+			wantChosenNode:    1,
+			wantFeasibleNodes: 1,
+		},
+		{
+			name:              "unschedulable by the active but schedulable by the shadow",
+			nodes:             rejectAll,
+			sampleRate:        1,
+			plugin:            &shadowTestPlugin{rejectLabel: "shadow-reject"},
+			wantEvaluations:   1,
+			wantChosenNode:    1,
+			wantFeasibleNodes: 1,
+		},
+		{
+			name:       "not sampled",
+			nodes:      nodes,
+			sampleRate: 0,
+			plugin:     &shadowTestPlugin{rejectLabel: "shadow-reject", preferLabel: "node-c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profileName := "shadow-" + tt.name
+			shadow, err := NewShadowProfile(profileName, tt.sampleRate, []framework.Plugin{tt.plugin}, nil)
+			assert.NoError(t, err)
+			fwk := newShadowTestFramework(t, tt.nodes, shadow)
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+			runTracingTestSchedulingCycle(context.TODO(), fwk, pod, tt.nodes)
+			// the shadow runs on the clones
+			assert.Empty(t, pod.Annotations)
+
+			assert.Equal(t, tt.wantEvaluations, getShadowTestCounter(t, profileName, ""))
+			assert.Equal(t, tt.wantChosenNode, getShadowTestCounter(t, profileName, ShadowDivergenceChosenNode))
+			assert.Equal(t, tt.wantFeasibleNodes, getShadowTestCounter(t, profileName, ShadowDivergenceFeasibleNodes))
+		})
+	}
+}
+
+func TestShadowProfileCycleState(t *testing.T) {
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+	}
+	shadow, err := NewShadowProfile("shadow-cycle-state", 1, []framework.Plugin{&shadowTestPlugin{}}, nil)
+	assert.NoError(t, err)
+	fwk := newShadowTestFramework(t, nodes, shadow)
+
+	ctx := context.TODO()
+	cycleState := framework.NewCycleState()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+	assert.True(t, fwk.RunPreFilterPlugins(ctx, cycleState, pod).IsSuccess())
+	_, err = cycleState.Read(shadowTestStateKey)
+	assert.Error(t, err, "the shadow must not write the active cycle state")
+	cycles := getShadowCycles(cycleState)
+	assert.Len(t, cycles, 1)
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		assert.True(t, fwk.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo).IsSuccess())
+	}
+	assert.Equal(t, 2, cycles[0].activeFeasible)
+	assert.Len(t, cycles[0].shadowFeasible, 2)
+
+	assert.True(t, fwk.RunReservePluginsReserve(ctx, cycleState, pod, "node-a").IsSuccess())
+	assert.Nil(t, getShadowCycles(cycleState), "the evaluation is finished once")
+	assert.Equal(t, float64(1), getShadowTestCounter(t, "shadow-cycle-state", ""))
+}
+
+func TestNewShadowProfile(t *testing.T) {
+	_, err := NewShadowProfile("invalid", 1.5, nil, nil)
+	assert.Error(t, err)
+
+	shadow, err := NewShadowProfile("weights", 0.1, []framework.Plugin{&shadowTestPlugin{}}, map[string]int64{"ShadowTestPlugin": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "weights", shadow.Name())
+	assert.Len(t, shadow.preFilterPlugins, 1)
+	assert.Len(t, shadow.filterPlugins, 1)
+	assert.Len(t, shadow.scorePlugins, 1)
+	assert.Equal(t, []int64{3}, shadow.scoreWeights)
+}
+
+func TestNewShadowProfilesFromFlags(t *testing.T) {
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"shadow-reject": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-c"}},
+	}
+	newPlugin := func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+		return &shadowTestPlugin{rejectLabel: "shadow-reject", preferLabel: "node-c"}, nil
+	}
+	shadowFwk, err := schedulertesting.NewFramework(
+		[]schedulertesting.RegisterPluginFunc{
+			schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			schedulertesting.RegisterPluginAsExtensions("ShadowTestPlugin", newPlugin, "PreFilter", "Filter", "Score"),
+		},
+		"shadow-framework",
+		frameworkruntime.WithSnapshotSharedLister(newTracingTestSharedLister(nodes)),
+	)
+	assert.NoError(t, err)
+	profiles := map[string]framework.Framework{"shadow-framework": shadowFwk}
+
+	defer func(names []string, rate float64) {
+		shadowProfileNames, shadowSampleRate = names, rate
+	}(shadowProfileNames, shadowSampleRate)
+	shadowProfileNames, shadowSampleRate = []string{"unknown"}, 1
+	_, err = NewShadowProfilesFromFlags(profiles)
+	assert.Error(t, err)
+
+	shadowProfileNames = []string{"shadow-framework"}
+	hooks, err := NewShadowProfilesFromFlags(profiles)
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 1)
+	shadow := hooks[0].(*ShadowProfile)
+	assert.Equal(t, "shadow-framework", shadow.Name())
+
+	fwk := newShadowTestFramework(t, nodes, shadow)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+	runTracingTestSchedulingCycle(context.TODO(), fwk, pod, nodes)
+	assert.Empty(t, pod.Annotations)
+	assert.Equal(t, float64(1), getShadowTestCounter(t, "shadow-framework", ""))
+	assert.Equal(t, float64(1), getShadowTestCounter(t, "shadow-framework", ShadowDivergenceChosenNode))
+	assert.Equal(t, float64(1), getShadowTestCounter(t, "shadow-framework", ShadowDivergenceFeasibleNodes))
+
+	// the shadow profile is not evaluated against itself
+	extendedHandle, err := NewExtendedHandle()
+	assert.NoError(t, err)
+	extender := NewFrameworkExtenderFactory(extendedHandle, shadow).New(shadowFwk).(*frameworkExtenderImpl)
+	assert.Empty(t, extender.shadowProfiles)
+}
+
+func TestShadowCyclesClone(t *testing.T) {
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+	}
+	shadow, err := NewShadowProfile("shadow-clone", 1, []framework.Plugin{&shadowTestPlugin{}}, nil)
+	assert.NoError(t, err)
+	fwk := newShadowTestFramework(t, nodes, shadow)
+
+	ctx := context.TODO()
+	cycleState := framework.NewCycleState()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+	assert.True(t, fwk.RunPreFilterPlugins(ctx, cycleState, pod).IsSuccess())
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(nodes[0])
+	assert.True(t, fwk.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo).IsSuccess())
+
+	// the nodes filtered on the clone, e.g. in the preemption, are not counted in the cycle
+	clonedState := cycleState.Clone()
+	nodeInfo = framework.NewNodeInfo()
+	nodeInfo.SetNode(nodes[1])
+	assert.True(t, fwk.RunFilterPluginsWithNominatedPods(ctx, clonedState, pod, nodeInfo).IsSuccess())
+
+	cycles, clonedCycles := getShadowCycles(cycleState), getShadowCycles(clonedState)
+	assert.Len(t, cycles, 1)
+	assert.Len(t, clonedCycles, 1)
+	assert.NotSame(t, cycles[0], clonedCycles[0])
+	assert.NotSame(t, cycles[0].state, clonedCycles[0].state)
+	assert.NotSame(t, cycles[0].pod, clonedCycles[0].pod)
+	assert.Equal(t, 1, cycles[0].activeFeasible)
+	assert.Equal(t, []*corev1.Node{nodes[0]}, cycles[0].shadowFeasible)
+	assert.Equal(t, 2, clonedCycles[0].activeFeasible)
+	assert.Equal(t, []*corev1.Node{nodes[0], nodes[1]}, clonedCycles[0].shadowFeasible)
+}
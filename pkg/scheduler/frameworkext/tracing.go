@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	tracerName = "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+
+	schedulingCycleSpanName = "SchedulingCycle"
+
+	schedulingCycleTraceStateKey = "koordinator.sh/scheduling-cycle-trace"
+)
+
+const (
+	attributeKeyPod              = attribute.Key("pod")
+	attributeKeyPodUID           = attribute.Key("pod.uid")
+	attributeKeyNode             = attribute.Key("node")
+	attributeKeyNodesTotal       = attribute.Key("nodes.total")
+	attributeKeyNodesFiltered    = attribute.Key("nodes.filtered")
+	attributeKeyNodesFeasible    = attribute.Key("nodes.feasible")
+	attributeKeyRejectionReason  = attribute.Key("rejection.reason")
+	attributeKeyRejectionReasons = attribute.Key("rejection.reasons")
+	attributeKeyOutcome          = attribute.Key("outcome")
+)
+
+var (
+	tracingOTLPEndpoint  = ""
+	tracingOTLPInsecure  = false
+	tracingSamplingRatio = 0.01
+)
+
+func addTracingFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&tracingOTLPEndpoint, "tracing-otlp-endpoint", tracingOTLPEndpoint, "the OTLP gRPC endpoint to export the spans of the scheduling cycles, disable the tracing if empty")
+	fs.BoolVar(&tracingOTLPInsecure, "tracing-otlp-insecure", tracingOTLPInsecure, "export the spans to the OTLP endpoint without TLS")
+	fs.Float64Var(&tracingSamplingRatio, "tracing-sampling-ratio", tracingSamplingRatio, "the ratio of the scheduling cycles to trace, in the range of [0, 1]")
+}
+
+// NewTracerProvider returns the provider exporting the spans to the OTLP endpoint with the sampling ratio of the flags,
+// and the function to flush and stop the exporting. It returns a nil provider if the tracing is disabled.
+func NewTracerProvider(ctx context.Context) (trace.TracerProvider, func(context.Context) error, error) {
+	if tracingOTLPEndpoint == "" {
+		return nil, func(context.Context) error { return nil }, nil
+	}
+	if tracingSamplingRatio < 0 || tracingSamplingRatio > 1 {
+		return nil, nil, fmt.Errorf("tracing sampling ratio must be in the range of [0, 1], got %v", tracingSamplingRatio)
+	}
+	driverOptions := []otlpgrpc.Option{otlpgrpc.WithEndpoint(tracingOTLPEndpoint)}
+	if tracingOTLPInsecure {
+		driverOptions = append(driverOptions, otlpgrpc.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(driverOptions...))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create OTLP exporter, err: %v", err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracingSamplingRatio))),
+		sdktrace.WithBatcher(exporter),
+	)
+	return tracerProvider, tracerProvider.Shutdown, nil
+}
+
+// schedulingCycleTrace is the span of a scheduling cycle, which is the parent of the spans of the plugins.
+// It is only written into the CycleState if the cycle is sampled.
+type schedulingCycleTrace struct {
+	span trace.Span
+
+	lock sync.Mutex
+	// filteredNodes is the number of nodes rejected by the Filter plugins.
+	filteredNodes int64
+	// rejections counts the rejected nodes by the plugin and the reason.
+	rejections map[string]int
+}
+
+func (t *schedulingCycleTrace) Clone() framework.StateData {
+	return t
+}
+
+func startSchedulingCycleTrace(ctx context.Context, tracer trace.Tracer, cycleState *framework.CycleState, pod *corev1.Pod) context.Context {
+	ctx, span := tracer.Start(ctx, schedulingCycleSpanName)
+	if !span.IsRecording() {
+		return ctx
+	}
+	span.SetAttributes(
+		attributeKeyPod.String(pod.Namespace+"/"+pod.Name),
+		attributeKeyPodUID.String(string(pod.UID)),
+	)
+	cycleState.Write(schedulingCycleTraceStateKey, &schedulingCycleTrace{span: span, rejections: map[string]int{}})
+	return ctx
+}
+
+// getSchedulingCycleTrace returns the trace of the scheduling cycle, nil if the cycle is not traced.
+func getSchedulingCycleTrace(cycleState *framework.CycleState) *schedulingCycleTrace {
+	if cycleState == nil {
+		return nil
+	}
+	value, err := cycleState.Read(schedulingCycleTraceStateKey)
+	if err != nil {
+		return nil
+	}
+	cycleTrace, _ := value.(*schedulingCycleTrace)
+	return cycleTrace
+}
+
+// contextWithSchedulingCycleSpan returns the context carrying the span of the scheduling cycle if it is traced.
+func contextWithSchedulingCycleSpan(ctx context.Context, cycleState *framework.CycleState) (context.Context, *schedulingCycleTrace) {
+	cycleTrace := getSchedulingCycleTrace(cycleState)
+	if cycleTrace == nil {
+		return ctx, nil
+	}
+	return trace.ContextWithSpan(ctx, cycleTrace.span), cycleTrace
+}
+
+func (t *schedulingCycleTrace) recordRejection(status *framework.Status, filtered bool) {
+	if status.IsSuccess() {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if filtered {
+		t.filteredNodes++
+	}
+	t.rejections[fmt.Sprintf("%s: %s", status.FailedPlugin(), status.Message())]++
+}
+
+// end ends the span of the scheduling cycle with the outcome and the rejection reasons.
+func (t *schedulingCycleTrace) end(outcome string, err error) {
+	if !t.span.IsRecording() {
+		return
+	}
+	t.lock.Lock()
+	reasons := make([]string, 0, len(t.rejections))
+	for reason, count := range t.rejections {
+		reasons = append(reasons, fmt.Sprintf("%s (%d)", reason, count))
+	}
+	filteredNodes := t.filteredNodes
+	t.lock.Unlock()
+	sort.Strings(reasons)
+
+	t.span.SetAttributes(attributeKeyNodesFiltered.Int64(filteredNodes), attributeKeyOutcome.String(outcome))
+	if len(reasons) > 0 {
+		t.span.SetAttributes(attributeKeyRejectionReasons.Array(reasons))
+	}
+	if err != nil {
+		t.span.SetStatus(codes.Error, err.Error())
+	}
+	t.span.End()
+}
+
+// StartSpan starts the span named as <pluginName>/<extensionPoint> as the child of the scheduling cycle span in
+// the context. The span is no-op without allocations if the scheduling cycle is not traced.
+func StartSpan(ctx context.Context, pluginName, extensionPoint string, pod *corev1.Pod, nodeName string) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, parent
+	}
+	attributes := []attribute.KeyValue{attributeKeyPod.String(pod.Namespace + "/" + pod.Name)}
+	if nodeName != "" {
+		attributes = append(attributes, attributeKeyNode.String(nodeName))
+	}
+	return parent.Tracer().Start(ctx, pluginName+"/"+extensionPoint, trace.WithAttributes(attributes...))
+}
+
+// EndSpan ends the span started by StartSpan with the outcome and the rejection reason of the status.
+func EndSpan(span trace.Span, status *framework.Status) {
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(attributeKeyOutcome.String(status.Code().String()))
+	switch {
+	case status.Code() == framework.Error:
+		span.SetStatus(codes.Error, status.Message())
+	case !status.IsSuccess():
+		span.SetAttributes(attributeKeyRejectionReason.String(status.Message()))
+	}
+	span.End()
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+const tracingTestPluginName = "TracingTestPlugin"
+
+var (
+	_ framework.PreFilterPlugin = &tracingTestPlugin{}
+	_ framework.FilterPlugin    = &tracingTestPlugin{}
+	_ framework.ReservePlugin   = &tracingTestPlugin{}
+	_ framework.PreBindPlugin   = &tracingTestPlugin{}
+)
+
+// tracingTestPlugin rejects the nodes with the label "reject" and fails the pods with the label "fail" in PreBind.
+type tracingTestPlugin struct{}
+
+func (p *tracingTestPlugin) Name() string { return tracingTestPluginName }
+
+func (p *tracingTestPlugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) (status *framework.Status) {
+	_, span := StartSpan(ctx, tracingTestPluginName, "PreFilter", pod, "")
+	defer func() { EndSpan(span, status) }()
+	return nil
+}
+
+func (p *tracingTestPlugin) PreFilterExtensions() framework.PreFilterExtensions { return nil }
+
+func (p *tracingTestPlugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	_, span := StartSpan(ctx, tracingTestPluginName, "Filter", pod, nodeInfo.Node().Name)
+	defer func() { EndSpan(span, status) }()
+	if _, ok := nodeInfo.Node().Labels["reject"]; ok {
+		return framework.NewStatus(framework.Unschedulable, "node(s) rejected")
+	}
+	return nil
+}
+
+func (p *tracingTestPlugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := StartSpan(ctx, tracingTestPluginName, "Reserve", pod, nodeName)
+	defer func() { EndSpan(span, status) }()
+	return nil
+}
+
+func (p *tracingTestPlugin) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) {
+}
+
+func (p *tracingTestPlugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := StartSpan(ctx, tracingTestPluginName, "PreBind", pod, nodeName)
+	defer func() { EndSpan(span, status) }()
+	if _, ok := pod.Labels["fail"]; ok {
+		return framework.NewStatus(framework.Error, "failed to patch pod")
+	}
+	return nil
+}
+
+type tracingTestSharedLister struct {
+	nodeInfos []*framework.NodeInfo
+}
+
+func newTracingTestSharedLister(nodes []*corev1.Node) *tracingTestSharedLister {
+	lister := &tracingTestSharedLister{}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		lister.nodeInfos = append(lister.nodeInfos, nodeInfo)
+	}
+	return lister
+}
+
+func (l *tracingTestSharedLister) NodeInfos() framework.NodeInfoLister { return l }
+
+func (l *tracingTestSharedLister) List() ([]*framework.NodeInfo, error) { return l.nodeInfos, nil }
+
+func (l *tracingTestSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (l *tracingTestSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (l *tracingTestSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	for _, nodeInfo := range l.nodeInfos {
+		if nodeInfo.Node().Name == nodeName {
+			return nodeInfo, nil
+		}
+	}
+	return nil, nil
+}
+
+type tracingTestPodNominator struct{}
+
+func (n *tracingTestPodNominator) AddNominatedPod(pod *framework.PodInfo, nodeName string) {}
+
+func (n *tracingTestPodNominator) DeleteNominatedPodIfExists(pod *corev1.Pod) {}
+
+func (n *tracingTestPodNominator) UpdateNominatedPod(oldPod *corev1.Pod, newPodInfo *framework.PodInfo) {
+}
+
+func (n *tracingTestPodNominator) NominatedPodsForNode(nodeName string) []*framework.PodInfo {
+	return nil
+}
+
+func newTracingTestFramework(t *testing.T, tracerProvider *sdktrace.TracerProvider, nodes []*corev1.Node) FrameworkExtender {
+	var options []Option
+	if tracerProvider != nil {
+		options = append(options, WithTracerProvider(tracerProvider))
+	}
+	extendedHandle, err := NewExtendedHandle(options...)
+	assert.NoError(t, err)
+	newPlugin := func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+		return &tracingTestPlugin{}, nil
+	}
+	fh, err := schedulertesting.NewFramework(
+		[]schedulertesting.RegisterPluginFunc{
+			schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			schedulertesting.RegisterPluginAsExtensions(tracingTestPluginName, newPlugin, "PreFilter", "Filter", "Reserve", "PreBind"),
+		},
+		"koord-scheduler",
+		frameworkruntime.WithSnapshotSharedLister(newTracingTestSharedLister(nodes)),
+		frameworkruntime.WithPodNominator(&tracingTestPodNominator{}),
+	)
+	assert.NoError(t, err)
+	return NewFrameworkExtenderFactory(extendedHandle).New(fh)
+}
+
+func tracingTestSpanAttributes(span *sdktrace.SpanSnapshot) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func runTracingTestSchedulingCycle(ctx context.Context, fwk FrameworkExtender, pod *corev1.Pod, nodes []*corev1.Node) {
+	cycleState := framework.NewCycleState()
+	if !fwk.RunPreFilterPlugins(ctx, cycleState, pod).IsSuccess() {
+		return
+	}
+	var feasibleNodes []*corev1.Node
+	filteredNodeStatusMap := framework.NodeToStatusMap{}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		if status := fwk.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo); status.IsSuccess() {
+			feasibleNodes = append(feasibleNodes, node)
+		} else {
+			filteredNodeStatusMap[node.Name] = status
+		}
+	}
+	if len(feasibleNodes) == 0 {
+		fwk.RunPostFilterPlugins(ctx, cycleState, pod, filteredNodeStatusMap)
+		return
+	}
+	fwk.RunScorePlugins(ctx, cycleState, pod, feasibleNodes)
+	nodeName := feasibleNodes[0].Name
+	if !fwk.RunReservePluginsReserve(ctx, cycleState, pod, nodeName).IsSuccess() {
+		fwk.RunReservePluginsUnreserve(ctx, cycleState, pod, nodeName)
+		return
+	}
+	if !fwk.RunPreBindPlugins(ctx, cycleState, pod, nodeName).IsSuccess() {
+		fwk.RunReservePluginsUnreserve(ctx, cycleState, pod, nodeName)
+		return
+	}
+	fwk.RunPostBindPlugins(ctx, cycleState, pod, nodeName)
+}
+
+func TestSchedulingCycleTracing(t *testing.T) {
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"reject": "true"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3", Labels: map[string]string{"reject": "true"}}},
+	}
+	tests := []struct {
+		name          string
+		pod           *corev1.Pod
+		nodes         []*corev1.Node
+		wantSpans     []string
+		wantOutcome   string
+		wantFeasible  bool
+		wantFiltered  int64
+		wantRejection string
+		wantError     bool
+	}{
+		{
+			name:          "pod is bound",
+			pod:           &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1", UID: "uid-1"}},
+			nodes:         nodes,
+			wantSpans:     []string{"PreFilter", "Filter", "Filter", "Filter", "Reserve", "PreBind"},
+			wantOutcome:   "Bound",
+			wantFeasible:  true,
+			wantFiltered:  2,
+			wantRejection: "TracingTestPlugin: node(s) rejected (2)",
+		},
+		{
+			name:          "pod is unschedulable",
+			pod:           &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2", UID: "uid-2"}},
+			nodes:         []*corev1.Node{nodes[0], nodes[2]},
+			wantSpans:     []string{"PreFilter", "Filter", "Filter"},
+			wantOutcome:   "Unschedulable",
+			wantFiltered:  2,
+			wantRejection: "TracingTestPlugin: node(s) rejected (2)",
+		},
+		{
+			name:         "pod fails in PreBind",
+			pod:          &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-3", UID: "uid-3", Labels: map[string]string{"fail": "true"}}},
+			nodes:        []*corev1.Node{nodes[1]},
+			wantSpans:    []string{"PreFilter", "Filter", "Reserve", "PreBind"},
+			wantOutcome:  "Unreserved",
+			wantFeasible: true,
+			wantError:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			fwk := newTracingTestFramework(t, tracerProvider, nodes)
+			runTracingTestSchedulingCycle(context.TODO(), fwk, tt.pod, tt.nodes)
+
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, len(tt.wantSpans)+1)
+			cycleSpan := spans[len(spans)-1]
+			assert.Equal(t, schedulingCycleSpanName, cycleSpan.Name)
+			for i, span := range spans[:len(spans)-1] {
+				assert.Equal(t, tracingTestPluginName+"/"+tt.wantSpans[i], span.Name)
+				assert.Equal(t, cycleSpan.SpanContext.SpanID(), span.Parent.SpanID())
+				assert.Equal(t, tt.pod.Namespace+"/"+tt.pod.Name, tracingTestSpanAttributes(span)[attributeKeyPod].AsString())
+			}
+
+			attributes := tracingTestSpanAttributes(cycleSpan)
+			assert.Equal(t, tt.pod.Namespace+"/"+tt.pod.Name, attributes[attributeKeyPod].AsString())
+			assert.Equal(t, string(tt.pod.UID), attributes[attributeKeyPodUID].AsString())
+			assert.Equal(t, int64(len(nodes)), attributes[attributeKeyNodesTotal].AsInt64())
+			assert.Equal(t, tt.wantFiltered, attributes[attributeKeyNodesFiltered].AsInt64())
+			assert.Equal(t, tt.wantOutcome, attributes[attributeKeyOutcome].AsString())
+			if tt.wantFeasible {
+				assert.Equal(t, int64(len(tt.nodes))-tt.wantFiltered, attributes[attributeKeyNodesFeasible].AsInt64())
+			} else {
+				assert.NotContains(t, attributes, attributeKeyNodesFeasible)
+			}
+			if tt.wantRejection != "" {
+				assert.Equal(t, [1]string{tt.wantRejection}, attributes[attributeKeyRejectionReasons].AsArray())
+			} else {
+				assert.NotContains(t, attributes, attributeKeyRejectionReasons)
+			}
+			if tt.wantError {
+				assert.Equal(t, codes.Error, cycleSpan.StatusCode)
+			} else {
+				assert.Equal(t, codes.Unset, cycleSpan.StatusCode)
+			}
+		})
+	}
+}
+
+func TestSchedulingCycleTracingRejectionReason(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, cycleSpan := tracerProvider.Tracer(tracerName).Start(context.TODO(), schedulingCycleSpanName)
+	_, span := StartSpan(ctx, tracingTestPluginName, "Filter", &corev1.Pod{}, "node-1")
+	EndSpan(span, framework.NewStatus(framework.Unschedulable, "node(s) rejected"))
+	_, span = StartSpan(ctx, tracingTestPluginName, "Filter", &corev1.Pod{}, "node-2")
+	EndSpan(span, framework.NewStatus(framework.Error, "failed to filter"))
+	cycleSpan.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+	attributes := tracingTestSpanAttributes(spans[0])
+	assert.Equal(t, "node-1", attributes[attributeKeyNode].AsString())
+	assert.Equal(t, "Unschedulable", attributes[attributeKeyOutcome].AsString())
+	assert.Equal(t, "node(s) rejected", attributes[attributeKeyRejectionReason].AsString())
+	assert.Equal(t, codes.Unset, spans[0].StatusCode)
+	attributes = tracingTestSpanAttributes(spans[1])
+	assert.Equal(t, "Error", attributes[attributeKeyOutcome].AsString())
+	assert.NotContains(t, attributes, attributeKeyRejectionReason)
+	assert.Equal(t, codes.Error, spans[1].StatusCode)
+	assert.Equal(t, "failed to filter", spans[1].StatusMessage)
+}
+
+func TestSchedulingCycleTracingDisabled(t *testing.T) {
+	nodes := []*corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}
+
+	// no tracer provider
+	fwk := newTracingTestFramework(t, nil, nodes)
+	cycleState := framework.NewCycleState()
+	assert.True(t, fwk.RunPreFilterPlugins(context.TODO(), cycleState, pod).IsSuccess())
+	assert.Nil(t, getSchedulingCycleTrace(cycleState))
+
+	// the scheduling cycle is not sampled
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter), sdktrace.WithSampler(sdktrace.NeverSample()))
+	fwk = newTracingTestFramework(t, tracerProvider, nodes)
+	runTracingTestSchedulingCycle(context.TODO(), fwk, pod, nodes)
+	assert.Empty(t, exporter.GetSpans())
+
+	// the plugin spans are no-op without the scheduling cycle span
+	ctx, span := StartSpan(context.TODO(), tracingTestPluginName, "Filter", pod, "node-1")
+	assert.Equal(t, context.TODO(), ctx)
+	assert.False(t, span.IsRecording())
+	EndSpan(span, nil)
+}
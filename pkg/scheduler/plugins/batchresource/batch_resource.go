@@ -25,6 +25,7 @@ import (
 	resschedplug "k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 )
 
 const (
@@ -51,7 +52,14 @@ func (p *Plugin) Name() string {
 	return Name
 }
 
-func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	var nodeName string
+	if nodeInfo.Node() != nil {
+		nodeName = nodeInfo.Node().Name
+	}
+	_, span := frameworkext.StartSpan(ctx, Name, "Filter", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	insufficientResources := fitsRequest(pod, nodeInfo)
 
 	if len(insufficientResources) != 0 {
@@ -20,6 +20,8 @@ package core
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,6 +48,13 @@ import (
 
 type Status string
 
+const (
+	// GangTimeoutReason is the reason of the event emitted when a gang times out at Permit.
+	GangTimeoutReason = "GangTimeout"
+	// maxGangTimeoutEventMembers limits the members listed in the timeout event.
+	maxGangTimeoutEventMembers = 10
+)
+
 const (
 	// PodGroupNotSpecified denotes no PodGroup is specified in the Pod spec.
 	PodGroupNotSpecified Status = "PodGroup not specified"
@@ -71,6 +80,7 @@ type Manager interface {
 	GetGangSummary(gangId string) (*GangSummary, bool)
 	GetGangSummaries() map[string]*GangSummary
 	IsGangMinSatisfied(*corev1.Pod) bool
+	RecordSchedulingFailure(*corev1.Pod, string)
 }
 
 // PodGroupManager defines the scheduling operation called
@@ -88,6 +98,8 @@ type PodGroupManager struct {
 	reserveResourcePercentage int32
 	// cache stores gang info
 	cache *GangCache
+	// statusUpdater updates the scheduling status of the gangs asynchronously
+	statusUpdater *GangStatusUpdater
 	sync.RWMutex
 }
 
@@ -107,6 +119,7 @@ func NewPodGroupManager(
 		podLister: podInformer.Lister(),
 		cache:     gangCache,
 	}
+	pgMgr.statusUpdater = newGangStatusUpdater(pgClient, pgMgr.pgLister, gangCache)
 
 	podGroupEventHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc:    gangCache.onPodGroupAdd,
@@ -286,6 +299,7 @@ func (pgMgr *PodGroupManager) Permit(ctx context.Context, pod *corev1.Pod) (time
 	}
 	// first add pod to the gang's WaitingPodsMap
 	gang.addAssumedPod(pod)
+	pgMgr.statusUpdater.enqueue(gang.Name)
 
 	gangGroup := gang.getGangGroup()
 	allGangGroupAssumed := true
@@ -315,8 +329,13 @@ func (pgMgr *PodGroupManager) Unreserve(ctx context.Context, state *framework.Cy
 		klog.InfoS("Pod does not belong to any gang", "pod", klog.KObj(pod))
 		return
 	}
+	// the waiting pods are rejected by the framework when they time out at Permit
+	if gang.tryExpirePermitDeadline(pod, timeNowFn()) {
+		pgMgr.recordGangTimeout(gang, pod, handle)
+	}
 	// first delete the pod from gang's waitingFroBindChildren map
 	gang.delAssumedPod(pod)
+	pgMgr.statusUpdater.enqueue(gang.Name)
 
 	if !gang.isGangOnceResourceSatisfied() && gang.getGangMode() == extension.GangModeStrict {
 		pgMgr.rejectGangGroupById(pluginName, gang.Name, handle)
@@ -363,6 +382,7 @@ func (pgMgr *PodGroupManager) PostBind(ctx context.Context, pod *corev1.Pod, nod
 	}
 	// first update gang in cache
 	gang.addBoundPod(pod)
+	pgMgr.statusUpdater.enqueue(gang.Name)
 
 	//  update PodGroup
 	_, pg := pgMgr.GetPodGroup(pod)
@@ -420,13 +440,13 @@ func (pgMgr *PodGroupManager) GetCreatTime(podInfo *framework.QueuedPodInfo) tim
 	return time.Now()
 }
 
-// PatchPodGroup patches a podGroup.
+// PatchPodGroup patches the status of a podGroup.
 func (pgMgr *PodGroupManager) PatchPodGroup(pgName string, namespace string, patch []byte) error {
 	if len(patch) == 0 {
 		return nil
 	}
 	_, err := pgMgr.pgClient.SchedulingV1alpha1().PodGroups(namespace).Patch(context.TODO(), pgName,
-		types.MergePatchType, patch, metav1.PatchOptions{})
+		types.MergePatchType, patch, metav1.PatchOptions{}, "status")
 	return err
 }
 
@@ -451,6 +471,12 @@ func (pgMgr *PodGroupManager) AllowGangGroup(pod *corev1.Pod, handle framework.H
 	}
 
 	gangSlices := gang.getGangGroup()
+	for _, gangIdTmp := range gangSlices {
+		if gangTmp := pgMgr.cache.getGangFromCacheByGangId(gangIdTmp, false); gangTmp != nil {
+			gangTmp.clearPermitDeadline()
+			pgMgr.statusUpdater.enqueue(gangIdTmp)
+		}
+	}
 
 	handle.IterateOverWaitingPods(func(waitingPod framework.WaitingPod) {
 		podGangId := util.GetId(waitingPod.GetPod().Namespace,
@@ -503,3 +529,56 @@ func (pgMgr *PodGroupManager) GetGangSummaries() map[string]*GangSummary {
 
 	return result
 }
+
+// RecordSchedulingFailure records the reason why the pod failed in the scheduling cycle.
+func (pgMgr *PodGroupManager) RecordSchedulingFailure(pod *corev1.Pod, reason string) {
+	if !util.IsPodNeedGang(pod) {
+		return
+	}
+	gang := pgMgr.GetGangByPod(pod)
+	if gang == nil {
+		return
+	}
+	gang.setChildFailureReason(pod, reason)
+	pgMgr.statusUpdater.enqueue(gang.Name)
+}
+
+// GetGangStatusUpdater returns the updater of the scheduling status of the gangs.
+func (pgMgr *PodGroupManager) GetGangStatusUpdater() *GangStatusUpdater {
+	return pgMgr.statusUpdater
+}
+
+// recordGangTimeout emits an event listing the children never scheduled and their last failure reasons when the
+// gang times out at Permit.
+func (pgMgr *PodGroupManager) recordGangTimeout(gang *Gang, pod *corev1.Pod, handle framework.Handle) {
+	reasons := gang.getUnscheduledChildrenReasons()
+	podIds := make([]string, 0, len(reasons))
+	for podId := range reasons {
+		podIds = append(podIds, podId)
+	}
+	sort.Strings(podIds)
+	members := make([]string, 0, maxGangTimeoutEventMembers+1)
+	for i, podId := range podIds {
+		if i >= maxGangTimeoutEventMembers {
+			members = append(members, fmt.Sprintf("and %d more", len(podIds)-i))
+			break
+		}
+		reason := reasons[podId]
+		if reason == "" {
+			reason = "no failure recorded"
+		}
+		members = append(members, fmt.Sprintf("%s (%s)", podId, reason))
+	}
+	message := fmt.Sprintf("Gang %s timed out after waiting %v at Permit, %d/%d members assumed, members never scheduled: %s",
+		gang.Name, gang.getGangWaitTime(), gang.getGangAssumedPods(), gang.getGangMinNum(), strings.Join(members, "; "))
+	klog.InfoS("Gang timed out at Permit", "gang", gang.Name, "pod", klog.KObj(pod), "message", message)
+	if handle == nil || handle.EventRecorder() == nil {
+		return
+	}
+	_, pg := pgMgr.GetPodGroup(pod)
+	if pg != nil {
+		handle.EventRecorder().Eventf(pg, pod, corev1.EventTypeWarning, GangTimeoutReason, "Scheduling", "%s", message)
+	} else {
+		handle.EventRecorder().Eventf(pod, nil, corev1.EventTypeWarning, GangTimeoutReason, "Scheduling", "%s", message)
+	}
+}
@@ -76,6 +76,11 @@ type Gang struct {
 	GangFrom    string
 	HasGangInit bool
 
+	// PermitDeadline is the time when the children waiting at Permit time out, it is zero if no child is waiting.
+	PermitDeadline time.Time
+	// ChildrenFailureReasons records the reason of the last failed scheduling attempt of each child.
+	ChildrenFailureReasons map[string]string
+
 	lock sync.Mutex
 }
 
@@ -238,6 +243,7 @@ func (gang *Gang) deletePod(pod *v1.Pod) bool {
 	delete(gang.WaitingForBindChildren, podId)
 	delete(gang.BoundChildren, podId)
 	delete(gang.ChildrenScheduleRoundMap, podId)
+	delete(gang.ChildrenFailureReasons, podId)
 	if gang.GangFrom == GangFromPodAnnotation {
 		if len(gang.Children) == 0 {
 			return true
@@ -389,7 +395,11 @@ func (gang *Gang) addAssumedPod(pod *v1.Pod) {
 	defer gang.lock.Unlock()
 
 	podId := util.GetId(pod.Namespace, pod.Name)
+	delete(gang.ChildrenFailureReasons, podId)
 	if _, ok := gang.WaitingForBindChildren[podId]; !ok {
+		if len(gang.WaitingForBindChildren) == 0 && gang.WaitTime > 0 {
+			gang.PermitDeadline = timeNowFn().Add(gang.WaitTime)
+		}
 		gang.WaitingForBindChildren[podId] = pod
 		klog.Infof("AddAssumedPod, gangName: %v, podName: %v", gang.Name, podId)
 	}
@@ -404,6 +414,9 @@ func (gang *Gang) delAssumedPod(pod *v1.Pod) {
 		delete(gang.WaitingForBindChildren, podId)
 		klog.Infof("delAssumedPod, gangName: %v, podName: %v", gang.Name, podId)
 	}
+	if len(gang.WaitingForBindChildren) == 0 {
+		gang.PermitDeadline = time.Time{}
+	}
 }
 
 func (gang *Gang) getChildrenFromGang() (children []*v1.Pod) {
@@ -438,7 +451,11 @@ func (gang *Gang) addBoundPod(pod *v1.Pod) {
 
 	podId := util.GetId(pod.Namespace, pod.Name)
 	delete(gang.WaitingForBindChildren, podId)
+	delete(gang.ChildrenFailureReasons, podId)
 	gang.BoundChildren[podId] = pod
+	if len(gang.WaitingForBindChildren) == 0 {
+		gang.PermitDeadline = time.Time{}
+	}
 
 	klog.Infof("AddBoundPod, gangName: %v, podName: %v", gang.Name, podId)
 	if len(gang.BoundChildren) >= gang.MinRequiredNumber {
@@ -456,3 +473,73 @@ func (gang *Gang) isGangValidForPermit() bool {
 	}
 	return len(gang.WaitingForBindChildren) >= gang.MinRequiredNumber || gang.OnceResourceSatisfied == true
 }
+
+func (gang *Gang) setChildFailureReason(pod *v1.Pod, reason string) {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+
+	podId := util.GetId(pod.Namespace, pod.Name)
+	if _, ok := gang.Children[podId]; !ok {
+		return
+	}
+	if gang.ChildrenFailureReasons == nil {
+		gang.ChildrenFailureReasons = make(map[string]string)
+	}
+	gang.ChildrenFailureReasons[podId] = reason
+}
+
+// clearPermitDeadline clears the deadline when the waiting children are allowed to bind.
+func (gang *Gang) clearPermitDeadline() {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+	gang.PermitDeadline = time.Time{}
+}
+
+// tryExpirePermitDeadline returns true and clears the deadline if the waiting pod has timed out at Permit,
+// so a timeout is reported only once although all the waiting children are rejected.
+func (gang *Gang) tryExpirePermitDeadline(pod *v1.Pod, now time.Time) bool {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+	if gang.PermitDeadline.IsZero() || now.Before(gang.PermitDeadline) {
+		return false
+	}
+	if _, ok := gang.WaitingForBindChildren[util.GetId(pod.Namespace, pod.Name)]; !ok {
+		return false
+	}
+	gang.PermitDeadline = time.Time{}
+	return true
+}
+
+// getUnscheduledChildrenReasons returns the last failure reasons of the children neither bound nor waiting at Permit.
+func (gang *Gang) getUnscheduledChildrenReasons() map[string]string {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+	reasons := make(map[string]string)
+	for podId := range gang.Children {
+		if _, ok := gang.BoundChildren[podId]; ok {
+			continue
+		}
+		if _, ok := gang.WaitingForBindChildren[podId]; ok {
+			continue
+		}
+		reasons[podId] = gang.ChildrenFailureReasons[podId]
+	}
+	return reasons
+}
+
+// gangSchedulingStatus is the number of the children in each scheduling stage.
+type gangSchedulingStatus struct {
+	scheduled int32
+	waiting   int32
+	failed    int32
+}
+
+func (gang *Gang) getSchedulingStatus() *gangSchedulingStatus {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+	return &gangSchedulingStatus{
+		scheduled: int32(len(gang.BoundChildren)),
+		waiting:   int32(len(gang.WaitingForBindChildren)),
+		failed:    int32(len(gang.ChildrenFailureReasons)),
+	}
+}
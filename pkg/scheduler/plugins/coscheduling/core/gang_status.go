@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+	pgclientset "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned"
+	pglister "sigs.k8s.io/scheduler-plugins/pkg/generated/listers/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+)
+
+const GangStatusUpdaterName = "GangStatusUpdater"
+
+var (
+	// gangStatusUpdateInterval throttles the status updates of a gang, the changes in the interval are merged into
+	// one update.
+	gangStatusUpdateInterval = 5 * time.Second
+)
+
+// GangStatusUpdater updates the status subresource of the PodGroups asynchronously, so the scheduling cycles
+// never wait for the API server.
+type GangStatusUpdater struct {
+	pgClient pgclientset.Interface
+	pgLister pglister.PodGroupLister
+	cache    *GangCache
+	queue    workqueue.RateLimitingInterface
+}
+
+func newGangStatusUpdater(pgClient pgclientset.Interface, pgLister pglister.PodGroupLister, gangCache *GangCache) *GangStatusUpdater {
+	return &GangStatusUpdater{
+		pgClient: pgClient,
+		pgLister: pgLister,
+		cache:    gangCache,
+		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "GangStatus"),
+	}
+}
+
+func (u *GangStatusUpdater) Name() string {
+	return GangStatusUpdaterName
+}
+
+func (u *GangStatusUpdater) Start() {
+	go u.Run(context.TODO().Done())
+}
+
+func (u *GangStatusUpdater) Run(stopCh <-chan struct{}) {
+	defer u.queue.ShutDown()
+	go wait.Until(u.worker, time.Second, stopCh)
+	<-stopCh
+}
+
+// enqueue requests to update the status of the gang later.
+func (u *GangStatusUpdater) enqueue(gangId string) {
+	u.queue.AddAfter(gangId, gangStatusUpdateInterval)
+}
+
+func (u *GangStatusUpdater) worker() {
+	for u.processNextWorkItem() {
+	}
+}
+
+func (u *GangStatusUpdater) processNextWorkItem() bool {
+	key, quit := u.queue.Get()
+	if quit {
+		return false
+	}
+	defer u.queue.Done(key)
+
+	gangId, ok := key.(string)
+	if !ok {
+		u.queue.Forget(key)
+		return true
+	}
+	if err := u.sync(gangId); err != nil {
+		runtime.HandleError(fmt.Errorf("failed to update the status of gang %s, err: %v", gangId, err))
+		u.queue.AddRateLimited(key)
+		return true
+	}
+	u.queue.Forget(key)
+	return true
+}
+
+func (u *GangStatusUpdater) sync(gangId string) error {
+	gang := u.cache.getGangFromCacheByGangId(gangId, false)
+	if gang == nil {
+		return nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(gangId)
+	if err != nil {
+		return nil
+	}
+	pg, err := u.pgLister.PodGroups(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	pgCopy := pg.DeepCopy()
+	updateSchedulingStatus(&pgCopy.Status, pg.Spec.MinMember, gang.getSchedulingStatus())
+	if reflect.DeepEqual(pg.Status, pgCopy.Status) {
+		return nil
+	}
+	patch, err := util.CreateMergePatch(pg, pgCopy)
+	if err != nil {
+		return err
+	}
+	_, err = u.pgClient.SchedulingV1alpha1().PodGroups(namespace).Patch(context.TODO(), name,
+		types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err == nil {
+		klog.V(4).InfoS("GangStatusUpdater updated the status of gang", "gang", gangId,
+			"phase", pgCopy.Status.Phase, "scheduled", pgCopy.Status.Scheduled)
+	}
+	return err
+}
+
+// updateSchedulingStatus updates the scheduled members and the phase of a PodGroup not yet scheduled. The PodGroups
+// in the phases after Scheduled are left to the PodGroup controller.
+func updateSchedulingStatus(status *v1alpha1.PodGroupStatus, minMember int32, gangStatus *gangSchedulingStatus) {
+	switch status.Phase {
+	case "", v1alpha1.PodGroupPending, v1alpha1.PodGroupPreScheduling, v1alpha1.PodGroupScheduling:
+	default:
+		return
+	}
+	status.Scheduled = gangStatus.scheduled
+	if gangStatus.scheduled >= minMember {
+		status.Phase = v1alpha1.PodGroupScheduled
+		return
+	}
+	if gangStatus.scheduled+gangStatus.waiting+gangStatus.failed <= 0 {
+		return
+	}
+	status.Phase = v1alpha1.PodGroupScheduling
+	if status.ScheduleStartTime.IsZero() {
+		status.ScheduleStartTime = metav1.Time{Time: timeNowFn()}
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+)
+
+type fakeGangHandle struct {
+	framework.Handle
+	recorder *events.FakeRecorder
+}
+
+func (h *fakeGangHandle) EventRecorder() events.EventRecorder {
+	return h.recorder
+}
+
+func (h *fakeGangHandle) IterateOverWaitingPods(callback func(framework.WaitingPod)) {}
+
+func prepareGangForStatusTest(t *testing.T, bigMgr *Mgr, pg *v1alpha1.PodGroup, pods []*corev1.Pod) {
+	mgr := bigMgr.pgMgr
+	_, err := mgr.pgClient.SchedulingV1alpha1().PodGroups(pg.Namespace).Create(context.TODO(), pg, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, bigMgr.pgInformer.Informer().GetStore().Add(pg))
+	mgr.cache.onPodGroupAdd(pg)
+	for _, pod := range pods {
+		mgr.cache.onPodAdd(pod)
+	}
+}
+
+// syncGangStatus runs the status update of the gang and returns the status of the PodGroup.
+func syncGangStatus(t *testing.T, bigMgr *Mgr, pg *v1alpha1.PodGroup) v1alpha1.PodGroupStatus {
+	mgr := bigMgr.pgMgr
+	assert.NoError(t, mgr.statusUpdater.sync(pg.Namespace+"/"+pg.Name))
+	got, err := mgr.pgClient.SchedulingV1alpha1().PodGroups(pg.Namespace).Get(context.TODO(), pg.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	// the informer is not started, so sync the store manually
+	assert.NoError(t, bigMgr.pgInformer.Informer().GetStore().Update(got))
+	return got.Status
+}
+
+func TestGangSchedulingStatus(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	defer func(fn func() time.Time) { timeNowFn = fn }(timeNowFn)
+	timeNowFn = func() time.Time { return now }
+
+	filterReason := "0/2 nodes are available: 2 Insufficient cpu."
+	startTime := metav1.Time{Time: now}
+
+	t.Run("gang scheduled successfully", func(t *testing.T) {
+		bigMgr := NewManagerForTest()
+		mgr := bigMgr.pgMgr
+		recorder := events.NewFakeRecorder(10)
+		handle := &fakeGangHandle{recorder: recorder}
+		pg := makePg("gang-a", "ns1", 2, &now, nil)
+		pg.Status.Phase = v1alpha1.PodGroupPreScheduling
+		pod1 := st.MakePod().Name("pod1").UID("pod1").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "gang-a").Obj()
+		pod2 := st.MakePod().Name("pod2").UID("pod2").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "gang-a").Obj()
+		prepareGangForStatusTest(t, bigMgr, pg, []*corev1.Pod{pod1, pod2})
+
+		// nothing changes before any member is scheduled
+		assert.Equal(t, v1alpha1.PodGroupStatus{Phase: v1alpha1.PodGroupPreScheduling}, syncGangStatus(t, bigMgr, pg))
+
+		// pod1 failed at Filter
+		mgr.RecordSchedulingFailure(pod1, filterReason)
+		assert.Equal(t, v1alpha1.PodGroupStatus{
+			Phase:             v1alpha1.PodGroupScheduling,
+			ScheduleStartTime: startTime,
+		}, syncGangStatus(t, bigMgr, pg))
+
+		// pod1 waits at Permit, the schedule start time is kept
+		timeNowFn = func() time.Time { return now.Add(time.Second) }
+		defer func() { timeNowFn = func() time.Time { return now } }()
+		_, status := mgr.Permit(context.TODO(), pod1)
+		assert.Equal(t, Wait, status)
+		assert.Equal(t, v1alpha1.PodGroupStatus{
+			Phase:             v1alpha1.PodGroupScheduling,
+			ScheduleStartTime: startTime,
+		}, syncGangStatus(t, bigMgr, pg))
+
+		// pod2 makes the gang ready, the waiting pods are allowed
+		_, status = mgr.Permit(context.TODO(), pod2)
+		assert.Equal(t, Success, status)
+		mgr.AllowGangGroup(pod2, handle, "Coscheduling")
+
+		mgr.PostBind(context.TODO(), pod1, "node1")
+		assert.Equal(t, v1alpha1.PodGroupStatus{
+			Phase:             v1alpha1.PodGroupScheduling,
+			Scheduled:         1,
+			ScheduleStartTime: startTime,
+		}, syncGangStatus(t, bigMgr, pg))
+		mgr.PostBind(context.TODO(), pod2, "node2")
+		assert.Equal(t, v1alpha1.PodGroupStatus{
+			Phase:             v1alpha1.PodGroupScheduled,
+			Scheduled:         2,
+			ScheduleStartTime: startTime,
+		}, syncGangStatus(t, bigMgr, pg))
+		assert.Len(t, recorder.Events, 0)
+	})
+
+	t.Run("gang timed out at Permit", func(t *testing.T) {
+		bigMgr := NewManagerForTest()
+		mgr := bigMgr.pgMgr
+		recorder := events.NewFakeRecorder(10)
+		handle := &fakeGangHandle{recorder: recorder}
+		pg := makePg("gang-b", "ns1", 3, &now, nil)
+		pg.Status.Phase = v1alpha1.PodGroupPreScheduling
+		pod1 := st.MakePod().Name("pod1").UID("pod1").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "gang-b").Obj()
+		pod2 := st.MakePod().Name("pod2").UID("pod2").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "gang-b").Obj()
+		pod3 := st.MakePod().Name("pod3").UID("pod3").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "gang-b").Obj()
+		prepareGangForStatusTest(t, bigMgr, pg, []*corev1.Pod{pod1, pod2, pod3})
+
+		_, status := mgr.Permit(context.TODO(), pod1)
+		assert.Equal(t, Wait, status)
+		_, status = mgr.Permit(context.TODO(), pod2)
+		assert.Equal(t, Wait, status)
+		mgr.RecordSchedulingFailure(pod3, filterReason)
+		assert.Equal(t, v1alpha1.PodGroupStatus{
+			Phase:             v1alpha1.PodGroupScheduling,
+			ScheduleStartTime: startTime,
+		}, syncGangStatus(t, bigMgr, pg))
+
+		// the waiting pods are rejected after the deadline
+		timeNowFn = func() time.Time { return now.Add(11 * time.Second) }
+		defer func() { timeNowFn = func() time.Time { return now } }()
+		cycleState := framework.NewCycleState()
+		mgr.Unreserve(context.TODO(), cycleState, pod1, "node1", handle, "Coscheduling")
+		mgr.Unreserve(context.TODO(), cycleState, pod2, "node2", handle, "Coscheduling")
+
+		// only one event is emitted for the timeout, listing the member never scheduled
+		assert.Len(t, recorder.Events, 1)
+		event := <-recorder.Events
+		assert.Equal(t, "Warning GangTimeout Gang ns1/gang-b timed out after waiting 10s at Permit, 2/3 members assumed, "+
+			"members never scheduled: ns1/pod3 ("+filterReason+")", event)
+
+		// the gang is still scheduling since the failed member is retried
+		assert.Equal(t, v1alpha1.PodGroupStatus{
+			Phase:             v1alpha1.PodGroupScheduling,
+			ScheduleStartTime: startTime,
+		}, syncGangStatus(t, bigMgr, pg))
+	})
+
+	t.Run("the phases after scheduled are left to the controller", func(t *testing.T) {
+		bigMgr := NewManagerForTest()
+		mgr := bigMgr.pgMgr
+		pg := makePg("gang-c", "ns1", 2, &now, nil)
+		pg.Status.Phase = v1alpha1.PodGroupRunning
+		pg.Status.Scheduled = 2
+		pod1 := st.MakePod().Name("pod1").UID("pod1").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "gang-c").Obj()
+		prepareGangForStatusTest(t, bigMgr, pg, []*corev1.Pod{pod1})
+
+		mgr.RecordSchedulingFailure(pod1, filterReason)
+		assert.Equal(t, v1alpha1.PodGroupStatus{Phase: v1alpha1.PodGroupRunning, Scheduled: 2}, syncGangStatus(t, bigMgr, pg))
+	})
+}
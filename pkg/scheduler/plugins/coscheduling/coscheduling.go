@@ -35,6 +35,7 @@ import (
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/core"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
 )
@@ -154,11 +155,15 @@ func (cs *Coscheduling) Less(podInfo1, podInfo2 *framework.QueuedPodInfo) bool {
 // ii.Check whether the Gang has been timeout(check the pod's annotation,later introduced at Permit section) or is inited, and reject the pod if positive.
 // iii.Check whether the Gang has met the scheduleCycleValid check, and reject the pod if negative.
 // iv.Try update scheduleCycle, scheduleCycleValid, childrenScheduleRoundMap as mentioned above.
-func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) *framework.Status {
+func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "PreFilter", pod, "")
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	// If PreFilter fails, return framework.Error to avoid
 	// any preemption attempts.
 	if err := cs.pgMgr.PreFilter(ctx, pod); err != nil {
 		klog.ErrorS(err, "PreFilter failed", "pod", klog.KObj(pod))
+		cs.pgMgr.RecordSchedulingFailure(pod, err.Error())
 		return framework.AsStatus(err)
 	}
 	return framework.NewStatus(framework.Success, "")
@@ -169,6 +174,13 @@ func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleSta
 // ii. If non-strict mode, we will do nothing.
 func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod,
 	filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	// the reasons why the nodes are filtered are listed in the gang timeout event, the same as in the pod condition
+	fitErr := &framework.FitError{
+		Pod:         pod,
+		NumAllNodes: len(filteredNodeStatusMap),
+		Diagnosis:   framework.Diagnosis{NodeToStatusMap: filteredNodeStatusMap},
+	}
+	cs.pgMgr.RecordSchedulingFailure(pod, fitErr.Error())
 	return cs.pgMgr.PostFilter(ctx, pod, cs.frameworkHandler, Name)
 }
 
@@ -203,7 +215,10 @@ func (cs *Coscheduling) Permit(ctx context.Context, state *framework.CycleState,
 }
 
 // Reserve is the functions invoked by the framework at "reserve" extension point.
-func (cs *Coscheduling) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+func (cs *Coscheduling) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "Reserve", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	return nil
 }
 
@@ -36,5 +36,5 @@ func (cs *Coscheduling) NewControllers() ([]frameworkext.Controller, error) {
 		controllerWorkers = int(*cs.args.ControllerWorkers)
 	}
 	podGroupController := controller.NewPodGroupController(cs.pgInformer, podInformer, cs.pgClient, pgMgr, controllerWorkers)
-	return []frameworkext.Controller{podGroupController}, nil
+	return []frameworkext.Controller{podGroupController, pgMgr.GetGangStatusUpdater()}, nil
 }
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	listerschedulingv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
+)
+
+const (
+	reasonDeviceAllocated = "DeviceAllocated"
+
+	allocationReportQPS   = 20
+	allocationReportBurst = 100
+
+	// the reported pods are remembered for a while to report each pod only once
+	maxReportedPods       = 10000
+	allocationReportedTTL = time.Hour
+)
+
+type allocationReport struct {
+	pod         *corev1.Pod
+	nodeName    string
+	allocations apiext.DeviceAllocations
+	podRequest  corev1.ResourceList
+}
+
+// allocationReporter reports the devices allocated to the bound pods by events asynchronously, so that the users can
+// tell the allocation result without decoding the annotation. The worker is started once a report is queued and exits
+// once the queue is drained, so that no worker is left behind by the plugin instances of the rebuilt frameworks.
+type allocationReporter struct {
+	recorder     events.EventRecorder
+	deviceLister listerschedulingv1alpha1.DeviceLister
+	// resourceNames formats the device requests of the pods.
+	resourceNames deviceResourceNames
+	limiter       flowcontrol.RateLimiter
+	queue         workqueue.Interface
+	lock          sync.Mutex
+	pending       map[types.UID]*allocationReport
+	// running indicates whether the worker is running.
+	running  bool
+	reported *utilcache.LRUExpireCache
+}
+
+func newAllocationReporter(recorder events.EventRecorder, deviceLister listerschedulingv1alpha1.DeviceLister, resourceNames deviceResourceNames) *allocationReporter {
+	if recorder == nil {
+		return nil
+	}
+	return &allocationReporter{
+		recorder:      recorder,
+		deviceLister:  deviceLister,
+		resourceNames: resourceNames,
+		limiter:       flowcontrol.NewTokenBucketRateLimiter(allocationReportQPS, allocationReportBurst),
+		queue:         workqueue.NewNamed("DeviceShareAllocationReport"),
+		pending:       map[types.UID]*allocationReport{},
+		reported:      utilcache.NewLRUExpireCache(maxReportedPods),
+	}
+}
+
+func (r *allocationReporter) enqueue(pod *corev1.Pod, nodeName string, allocations apiext.DeviceAllocations, podRequest corev1.ResourceList) {
+	if r == nil || len(allocations) == 0 {
+		return
+	}
+	if _, ok := r.reported.Get(pod.UID); ok {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.pending[pod.UID] = &allocationReport{pod: pod, nodeName: nodeName, allocations: allocations, podRequest: podRequest}
+	r.queue.Add(pod.UID)
+	if !r.running {
+		r.running = true
+		go r.run()
+	}
+}
+
+func (r *allocationReporter) run() {
+	for {
+		r.lock.Lock()
+		if r.queue.Len() == 0 {
+			r.running = false
+			r.lock.Unlock()
+			return
+		}
+		r.lock.Unlock()
+		r.processNextReport()
+	}
+}
+
+// processNextReport emits the event of one report off the queue.
+func (r *allocationReporter) processNextReport() {
+	item, _ := r.queue.Get()
+	defer r.queue.Done(item)
+
+	uid := item.(types.UID)
+	r.lock.Lock()
+	report := r.pending[uid]
+	delete(r.pending, uid)
+	r.lock.Unlock()
+	if report == nil {
+		return
+	}
+	if _, ok := r.reported.Get(uid); ok {
+		return
+	}
+
+	var gpuModel string
+	if device, err := r.deviceLister.Get(report.nodeName); err == nil {
+		gpuModel = device.Labels[apiext.GPUModel]
+	}
+	r.limiter.Accept()
+	summary := apiext.GetDeviceAllocationsSummary(report.allocations, gpuModel, report.nodeName)
+	if request := r.resourceNames.formatDeviceRequest(report.podRequest); request != "" {
+		summary += ", requested " + request
+	}
+	r.reported.Add(uid, struct{}{}, allocationReportedTTL)
+	r.recorder.Eventf(report.pod, nil, corev1.EventTypeNormal, reasonDeviceAllocated, "Binding", "%s", summary)
+	klog.V(5).InfoS("reported device allocation", "pod", klog.KObj(report.pod), "summary", summary)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+)
+
+func gpuAllocation(minor int32, gpuCore int64) *apiext.DeviceAllocation {
+	return &apiext.DeviceAllocation{
+		Minor: minor,
+		Resources: corev1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(gpuCore, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(gpuCore, resource.DecimalSI),
+		},
+	}
+}
+
+func TestAllocationReporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		allocations apiext.DeviceAllocations
+		podRequest  corev1.ResourceList
+		wantEvent   string
+	}{
+		{
+			name: "single GPU",
+			allocations: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpuAllocation(1, 100)},
+			},
+			wantEvent: "Normal DeviceAllocated allocated GPU 1 (A100-80G) on node test-node-1",
+		},
+		{
+			name: "multiple GPUs",
+			allocations: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpuAllocation(3, 100), gpuAllocation(0, 100)},
+			},
+			wantEvent: "Normal DeviceAllocated allocated GPU 0,3 (A100-80G) on node test-node-1",
+		},
+		{
+			name: "fractional GPUs",
+			allocations: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpuAllocation(0, 50), gpuAllocation(3, 50)},
+			},
+			wantEvent: "Normal DeviceAllocated allocated GPU 0,3 (A100-80G) on node test-node-1, 50% gpu-core each",
+		},
+		{
+			name: "with the device request",
+			allocations: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {gpuAllocation(1, 100)},
+			},
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+			},
+			wantEvent: "Normal DeviceAllocated allocated GPU 1 (A100-80G) on node test-node-1, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordfake.NewSimpleClientset(), 0)
+			deviceInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Devices()
+			assert.NoError(t, deviceInformer.Informer().GetStore().Add(&schedulingv1alpha1.Device{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test-node-1",
+					Labels: map[string]string{apiext.GPUModel: "A100-80G"},
+				},
+			}))
+			recorder := events.NewFakeRecorder(10)
+			reporter := newAllocationReporter(recorder, deviceInformer.Lister(), DeviceResourceNames)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod-1",
+					UID:       "123456",
+				},
+			}
+
+			reporter.enqueue(pod, "test-node-1", tt.allocations, tt.podRequest)
+			assert.Equal(t, tt.wantEvent, <-recorder.Events)
+
+			// the pod is reported only once
+			reporter.enqueue(pod, "test-node-1", tt.allocations, tt.podRequest)
+			assert.Equal(t, 0, reporter.queue.Len())
+			assert.Len(t, recorder.Events, 0)
+
+			// the worker exits once the queue is drained
+			assert.NoError(t, wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+				reporter.lock.Lock()
+				defer reporter.lock.Unlock()
+				return !reporter.running, nil
+			}))
+		})
+	}
+}
+
+func TestAllocationReporterWithoutRecorder(t *testing.T) {
+	reporter := newAllocationReporter(nil, nil, DeviceResourceNames)
+	assert.Nil(t, reporter)
+	reporter.enqueue(&corev1.Pod{}, "test-node-1", apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {gpuAllocation(0, 100)},
+	}, nil)
+}
+
+func Test_Plugin_PostBind(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     *preFilterState
+		wantEvent bool
+	}{
+		{
+			name: "empty state",
+		},
+		{
+			name:  "state skip",
+			state: &preFilterState{skip: true},
+		},
+		{
+			name: "report allocation",
+			state: &preFilterState{
+				allocationResult: apiext.DeviceAllocations{
+					schedulingv1alpha1.GPU: {gpuAllocation(0, 100)},
+				},
+			},
+			wantEvent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordfake.NewSimpleClientset(), 0)
+			recorder := events.NewFakeRecorder(10)
+			reporter := newAllocationReporter(recorder, koordSharedInformerFactory.Scheduling().V1alpha1().Devices().Lister(), DeviceResourceNames)
+			p := &Plugin{allocationReporter: reporter}
+			cycleState := framework.NewCycleState()
+			if tt.state != nil {
+				cycleState.Write(stateKey, tt.state)
+			}
+			p.PostBind(context.TODO(), cycleState, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "123456"}}, "test-node-1")
+			if tt.wantEvent {
+				assert.Contains(t, <-recorder.Events, reasonDeviceAllocated)
+			} else {
+				assert.Len(t, recorder.Events, 0)
+			}
+		})
+	}
+}
@@ -17,15 +17,32 @@ limitations under the License.
 package deviceshare
 
 import (
+	"errors"
+	"sort"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/informers"
+	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
 var defaultAllocatorName = "default"
 
+var (
+	errUnalignedNUMADevices  = errors.New(ErrUnalignedNUMADevices)
+	errUnalignedNUMAGPUs     = errors.New(ErrUnalignedNUMAGPUs)
+	errUnalignedGPUTopology  = errors.New(ErrUnalignedGPUTopology)
+	errRDMAVFsExhausted      = errors.New(ErrRDMAVFsExhausted)
+	errUnalignedJointDevices = errors.New(ErrUnalignedJointDevices)
+	errUnmetGPUExclusive     = errors.New(ErrUnmetGPUExclusive)
+)
+
 var allocatorFactories = map[string]AllocatorFactoryFn{
 	defaultAllocatorName: NewDefaultAllocator,
 }
@@ -33,10 +50,23 @@ var allocatorFactories = map[string]AllocatorFactoryFn{
 type AllocatorOptions struct {
 	SharedInformerFactory      informers.SharedInformerFactory
 	KoordSharedInformerFactory koordinatorinformers.SharedInformerFactory
+	NUMATopologyPolicy         config.DeviceNUMATopologyPolicy
+	GPUTopologyPolicy          config.DeviceGPUTopologyPolicy
 }
 
 type AllocatorFactoryFn func(options AllocatorOptions) Allocator
 
+// Allocator allocates the devices of a node to the pod. The requested count of devices is a hard requirement, while
+// the topology of the devices is a soft preference: an Allocator must not fail an allocation which could be
+// satisfied by ignoring the topology, and the topology only decides which devices are allocated. The exceptions are
+// the topologies demanded by the policies or by the pod, and the allocation fails with the error of each otherwise:
+//   - errUnalignedNUMADevices: the Restricted NUMATopologyPolicy of the plugin places the GPUs and RDMA NICs
+//     requested together on the same NUMA node.
+//   - errUnalignedNUMAGPUs: the Restricted NUMATopologyPolicy of the pod places the GPUs on a single NUMA node.
+//   - errUnalignedGPUTopology: the Restricted GPUTopologyPolicy connects the whole GPUs by the closest topology the
+//     node could offer.
+//   - errUnalignedJointDevices: the joint allocation of the pod pairs the devices of the joint types under the same
+//     PCIe switches.
 type Allocator interface {
 	Name() string
 	Allocate(nodeName string, pod *corev1.Pod, podRequest corev1.ResourceList, nodeDevice *nodeDevice) (apiext.DeviceAllocations, error)
@@ -58,10 +88,15 @@ func NewAllocator(
 func NewDefaultAllocator(
 	options AllocatorOptions,
 ) Allocator {
-	return &defaultAllocator{}
+	return &defaultAllocator{
+		numaTopologyPolicy: options.NUMATopologyPolicy,
+		gpuTopologyPolicy:  options.GPUTopologyPolicy,
+	}
 }
 
 type defaultAllocator struct {
+	numaTopologyPolicy config.DeviceNUMATopologyPolicy
+	gpuTopologyPolicy  config.DeviceGPUTopologyPolicy
 }
 
 func (a *defaultAllocator) Name() string {
@@ -69,7 +104,316 @@ func (a *defaultAllocator) Name() string {
 }
 
 func (a *defaultAllocator) Allocate(nodeName string, pod *corev1.Pod, podRequest corev1.ResourceList, nodeDevice *nodeDevice) (apiext.DeviceAllocations, error) {
-	return nodeDevice.tryAllocateDevice(podRequest)
+	if pod == nil {
+		return a.tryAllocateDevice(nodeDevice, podRequest, "", apiext.NUMATopologyPolicyDefault)
+	}
+	resourceSpec, err := apiext.GetResourceSpec(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	minComputeCapability, err := apiext.GetGPUMinComputeCapability(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if minComputeCapability != nil {
+		nodeDevice = nodeDevice.withGPUsOfComputeCapability(*minComputeCapability)
+	}
+	jointAllocate, err := apiext.GetDeviceJointAllocate(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	targetGPUUUID := apiext.GetGPUTargetUUID(pod.Annotations)
+	var allocations apiext.DeviceAllocations
+	if jointAllocate != nil {
+		allocations, err = a.tryAllocateJointDevice(nodeDevice, podRequest, targetGPUUUID, resourceSpec.NUMATopologyPolicy, jointAllocate.DeviceTypes)
+		if err != nil {
+			return nil, err
+		}
+	} else if targetGPUUUID == "" {
+		// the restarted pod reclaims the devices it used if they are free, otherwise it falls back to the usual allocation
+		if reuseHint, err := apiext.GetDeviceReuseHint(pod.Annotations); err != nil {
+			klog.V(4).InfoS("ignore the invalid device reuse hint", "pod", klog.KObj(pod), "err", err)
+		} else {
+			allocations = nodeDevice.tryAllocateByReuseHint(podRequest, reuseHint)
+		}
+	}
+	if allocations == nil {
+		allocations, err = a.tryAllocateDevice(nodeDevice, podRequest, targetGPUUUID, resourceSpec.NUMATopologyPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// respect the device order expected by the pod, e.g. restored from a checkpoint made on another node
+	hint, err := apiext.GetDeviceOrderingHint(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDeviceOrderingHint(allocations, hint); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+// tryAllocateDevice allocates the devices requested by the pod, and rejects the whole GPUs not aligned to the
+// topology of the node if the GPUTopologyPolicy is Restricted.
+func (a *defaultAllocator) tryAllocateDevice(nodeDevice *nodeDevice, podRequest corev1.ResourceList, targetGPUUUID string, podNUMAPolicy apiext.NUMATopologyPolicy) (apiext.DeviceAllocations, error) {
+	allocations, err := a.tryAllocateNUMAAlignedDevice(nodeDevice, podRequest, targetGPUUUID, podNUMAPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if a.gpuTopologyPolicy == config.DeviceGPUTopologyRestricted && !nodeDevice.isGPUTopologyAligned(allocations) {
+		klog.V(5).Infof("the GPUs cannot be allocated with the closest topology of the node")
+		return nil, errUnalignedGPUTopology
+	}
+	return allocations, nil
+}
+
+// tryAllocateNUMAAlignedDevice allocates the GPUs and RDMA NICs requested together on the same NUMA node if
+// possible, trying the NUMA nodes with fewer free GPUs first to leave the others to the larger requests. If no NUMA
+// node satisfies the request, the devices are allocated across the NUMA nodes unless the policy is Restricted.
+// The GPUs requested without RDMA NICs are aligned the same way if the pod aligns its CPUs to them by the
+// BestEffort or Restricted NUMATopologyPolicy, so that the CPUs could be allocated on the same NUMA node.
+// The alignment is skipped if any of the devices does not report the topology.
+func (a *defaultAllocator) tryAllocateNUMAAlignedDevice(nodeDevice *nodeDevice, podRequest corev1.ResourceList, targetGPUUUID string, podNUMAPolicy apiext.NUMATopologyPolicy) (apiext.DeviceAllocations, error) {
+	if !nodeDevice.getResourceNames().hasDeviceResource(podRequest, schedulingv1alpha1.GPU) {
+		return nodeDevice.tryAllocateDevice(podRequest, targetGPUUUID)
+	}
+	deviceTypes := []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU}
+	restricted := podNUMAPolicy == apiext.NUMATopologyPolicyRestricted
+	errUnaligned := errUnalignedNUMAGPUs
+	if nodeDevice.getResourceNames().hasDeviceResource(podRequest, schedulingv1alpha1.RDMA) {
+		deviceTypes = append(deviceTypes, schedulingv1alpha1.RDMA)
+		restricted = restricted || a.numaTopologyPolicy == config.DeviceNUMATopologyRestricted
+		errUnaligned = errUnalignedNUMADevices
+	} else if podNUMAPolicy != apiext.NUMATopologyPolicyBestEffort && !restricted {
+		return nodeDevice.tryAllocateDevice(podRequest, targetGPUUUID)
+	}
+	numaNodes, ok := nodeDevice.getDeviceNUMANodes(deviceTypes...)
+	if !ok {
+		return nodeDevice.tryAllocateDevice(podRequest, targetGPUUUID)
+	}
+	freeGPUs := map[int32]int{}
+	for minor, free := range nodeDevice.deviceFree[schedulingv1alpha1.GPU] {
+		if !quotav1.IsZero(free) {
+			freeGPUs[nodeDevice.gpuNUMANodes[minor]]++
+		}
+	}
+	sort.SliceStable(numaNodes, func(i, j int) bool {
+		return freeGPUs[numaNodes[i]] < freeGPUs[numaNodes[j]]
+	})
+	for _, numaNode := range numaNodes {
+		allocations, err := nodeDevice.withDevicesOnNUMANode(numaNode, deviceTypes...).tryAllocateDevice(podRequest, targetGPUUUID)
+		if err == nil {
+			return allocations, nil
+		}
+	}
+
+	allocations, err := nodeDevice.tryAllocateDevice(podRequest, targetGPUUUID)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		klog.V(5).Infof("the devices %v cannot be allocated on the same NUMA node", deviceTypes)
+		return nil, errUnaligned
+	}
+	return allocations, nil
+}
+
+// tryAllocateJointDevice allocates the devices of the joint device types all under the same PCIe switch, trying the
+// PCIe switches with fewer free devices of the first type first to leave the others to the larger requests. If no
+// PCIe switch holds the whole request, the devices requested by multiple whole devices are paired under several PCIe
+// switches instead. The allocations of the joint types record the PCIe switch for the node agent to pair the
+// devices, and the allocation fails with errUnalignedJointDevices if the devices cannot be paired. The devices not
+// reporting the PCIe switch are never allocated jointly.
+func (a *defaultAllocator) tryAllocateJointDevice(nodeDevice *nodeDevice, podRequest corev1.ResourceList, targetGPUUUID string, podNUMAPolicy apiext.NUMATopologyPolicy, deviceTypes []schedulingv1alpha1.DeviceType) (apiext.DeviceAllocations, error) {
+	if len(deviceTypes) == 0 {
+		return a.tryAllocateDevice(nodeDevice, podRequest, targetGPUUUID, podNUMAPolicy)
+	}
+	pcieSwitches := nodeDevice.getDevicePCIeSwitches(deviceTypes...)
+	freeDevices := map[string]int{}
+	pcieSwitchesOfFirstType := nodeDevice.getPCIeSwitchesOfDeviceType(deviceTypes[0])
+	for minor, free := range nodeDevice.deviceFree[deviceTypes[0]] {
+		if pcieSwitch, ok := pcieSwitchesOfFirstType[minor]; ok && !quotav1.IsZero(free) {
+			freeDevices[pcieSwitch]++
+		}
+	}
+	sort.SliceStable(pcieSwitches, func(i, j int) bool {
+		return freeDevices[pcieSwitches[i]] < freeDevices[pcieSwitches[j]]
+	})
+	for _, pcieSwitch := range pcieSwitches {
+		allocations, err := a.tryAllocateDevice(nodeDevice.withDevicesOnPCIeSwitch(pcieSwitch, deviceTypes...), podRequest, targetGPUUUID, podNUMAPolicy)
+		if err != nil {
+			continue
+		}
+		for _, deviceType := range deviceTypes {
+			for _, allocation := range allocations[deviceType] {
+				allocation.PCIeSwitchID = pcieSwitch
+			}
+		}
+		return allocations, nil
+	}
+
+	if targetGPUUUID == "" {
+		// pair the devices under as few PCIe switches as possible
+		sort.SliceStable(pcieSwitches, func(i, j int) bool {
+			return freeDevices[pcieSwitches[i]] > freeDevices[pcieSwitches[j]]
+		})
+		if allocations := a.tryAllocateJointDeviceAcrossPCIeSwitches(nodeDevice, podRequest, podNUMAPolicy, deviceTypes, pcieSwitches); allocations != nil {
+			return allocations, nil
+		}
+	}
+	klog.V(5).Infof("the devices %v cannot be paired under the PCIe switches", deviceTypes)
+	return nil, errUnalignedJointDevices
+}
+
+// tryAllocateJointDeviceAcrossPCIeSwitches allocates the union of the devices paired under several PCIe switches, in
+// which each PCIe switch taken offers at least one device of every joint type, e.g. 2 GPUs and 1 RDMA NIC under each
+// of 2 PCIe switches to the pod requesting 4 GPUs and 2 NICs. Only the joint types requested by multiple whole
+// devices can be split, and the PCIe switches are taken in order until they hold the whole request. The PCIe switches
+// are taken on the same NUMA node if the NUMA alignment is Restricted. It returns nil if the devices cannot be paired.
+func (a *defaultAllocator) tryAllocateJointDeviceAcrossPCIeSwitches(nodeDevice *nodeDevice, podRequest corev1.ResourceList, podNUMAPolicy apiext.NUMATopologyPolicy, deviceTypes []schedulingv1alpha1.DeviceType, pcieSwitches []string) apiext.DeviceAllocations {
+	resourceNames := nodeDevice.getResourceNames()
+	requests := map[schedulingv1alpha1.DeviceType]corev1.ResourceList{}
+	wanted := map[schedulingv1alpha1.DeviceType]int64{}
+	for _, deviceType := range deviceTypes {
+		request, count := resourceNames.getWholeDeviceRequest(podRequest, deviceType)
+		if count <= 1 {
+			return nil
+		}
+		requests[deviceType], wanted[deviceType] = request, count
+	}
+
+	restricted := podNUMAPolicy == apiext.NUMATopologyPolicyRestricted ||
+		(a.numaTopologyPolicy == config.DeviceNUMATopologyRestricted && resourceNames.hasDeviceResource(podRequest, schedulingv1alpha1.RDMA))
+	numaNodes, ok := nodeDevice.getDeviceNUMANodes(deviceTypes...)
+	if !restricted || !ok {
+		return a.pairJointDevices(nodeDevice, podRequest, deviceTypes, pcieSwitches, requests, wanted)
+	}
+	for _, numaNode := range numaNodes {
+		view := nodeDevice.withDevicesOnNUMANode(numaNode, deviceTypes...)
+		if allocations := a.pairJointDevices(view, podRequest, deviceTypes, pcieSwitches, requests, wanted); allocations != nil {
+			return allocations
+		}
+	}
+	return nil
+}
+
+func (a *defaultAllocator) pairJointDevices(nodeDevice *nodeDevice, podRequest corev1.ResourceList, deviceTypes []schedulingv1alpha1.DeviceType,
+	pcieSwitches []string, requests map[schedulingv1alpha1.DeviceType]corev1.ResourceList, wanted map[schedulingv1alpha1.DeviceType]int64) apiext.DeviceAllocations {
+	// take the PCIe switches offering every joint type until they hold the whole request, and each PCIe switch
+	// taken needs at least one device of every joint type
+	var taken []string
+	fits := map[string]map[schedulingv1alpha1.DeviceType]int64{}
+	held := map[schedulingv1alpha1.DeviceType]int64{}
+	for _, pcieSwitch := range pcieSwitches {
+		view := nodeDevice.withDevicesOnPCIeSwitch(pcieSwitch, deviceTypes...)
+		fit := map[schedulingv1alpha1.DeviceType]int64{}
+		for _, deviceType := range deviceTypes {
+			fit[deviceType] = view.countFitDevices(requests[deviceType], wanted[deviceType])
+		}
+		if !isAllPositive(fit) {
+			continue
+		}
+		taken = append(taken, pcieSwitch)
+		fits[pcieSwitch] = fit
+		for deviceType, count := range fit {
+			held[deviceType] += count
+		}
+		if isAllHeld(held, wanted) {
+			break
+		}
+	}
+	if !isAllHeld(held, wanted) {
+		return nil
+	}
+	for _, deviceType := range deviceTypes {
+		if int64(len(taken)) > wanted[deviceType] {
+			return nil
+		}
+	}
+
+	// each PCIe switch takes a device of every joint type first, and the rest are spread in order
+	counts := map[string]map[schedulingv1alpha1.DeviceType]int64{}
+	for _, pcieSwitch := range taken {
+		counts[pcieSwitch] = map[schedulingv1alpha1.DeviceType]int64{}
+	}
+	for _, deviceType := range deviceTypes {
+		rest := wanted[deviceType] - int64(len(taken))
+		for _, pcieSwitch := range taken {
+			count := fits[pcieSwitch][deviceType] - 1
+			if count > rest {
+				count = rest
+			}
+			counts[pcieSwitch][deviceType] = 1 + count
+			rest -= count
+		}
+	}
+
+	allocations := apiext.DeviceAllocations{}
+	jointResourceNames := sets.NewString()
+	for _, deviceType := range deviceTypes {
+		for resourceName := range requests[deviceType] {
+			jointResourceNames.Insert(string(resourceName))
+		}
+	}
+	for _, pcieSwitch := range taken {
+		request := corev1.ResourceList{}
+		for _, deviceType := range deviceTypes {
+			request = quotav1.Add(request, scaleDeviceRequest(requests[deviceType], counts[pcieSwitch][deviceType], wanted[deviceType]))
+		}
+		switchAllocations, err := nodeDevice.withDevicesOnPCIeSwitch(pcieSwitch, deviceTypes...).tryAllocateDevice(request, "")
+		if err != nil {
+			klog.V(5).Infof("failed to allocate the devices %v under the PCIe switch %s, err: %v", deviceTypes, pcieSwitch, err)
+			return nil
+		}
+		for _, deviceType := range deviceTypes {
+			for _, allocation := range switchAllocations[deviceType] {
+				allocation.PCIeSwitchID = pcieSwitch
+			}
+			allocations[deviceType] = append(allocations[deviceType], switchAllocations[deviceType]...)
+		}
+	}
+	// the other device types requested together are allocated from the whole node
+	otherRequest := corev1.ResourceList{}
+	for resourceName, quantity := range podRequest {
+		if !jointResourceNames.Has(string(resourceName)) {
+			otherRequest[resourceName] = quantity
+		}
+	}
+	if len(otherRequest) > 0 {
+		otherAllocations, err := nodeDevice.tryAllocateDevice(otherRequest, "")
+		if err != nil {
+			return nil
+		}
+		for deviceType, deviceAllocations := range otherAllocations {
+			if _, ok := allocations[deviceType]; !ok {
+				allocations[deviceType] = deviceAllocations
+			}
+		}
+	}
+	if a.gpuTopologyPolicy == config.DeviceGPUTopologyRestricted && !nodeDevice.isGPUTopologyAligned(allocations) {
+		klog.V(5).Infof("the GPUs paired under the PCIe switches %v are not aligned to the closest topology", taken)
+		return nil
+	}
+	return allocations
+}
+
+func isAllPositive(counts map[schedulingv1alpha1.DeviceType]int64) bool {
+	for _, count := range counts {
+		if count <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllHeld(held, wanted map[schedulingv1alpha1.DeviceType]int64) bool {
+	for deviceType, count := range wanted {
+		if held[deviceType] < count {
+			return false
+		}
+	}
+	return true
 }
 
 func (a *defaultAllocator) Reserve(pod *corev1.Pod, nodeDevice *nodeDevice, allocations apiext.DeviceAllocations) {
@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	consistencyKindDevice = "Device"
+	consistencyKindPod    = "Pod"
+)
+
+// CheckConsistency rebuilds the node devices from the Device and Pod listers and diffs them against the cache.
+// The devices allocated in cache to the pods not bound yet are assumed in Reserve, they are not treated as drift.
+func (p *Plugin) CheckConsistency(repair bool) []frameworkext.Discrepancy {
+	expected := newNodeDeviceCache()
+	expected.releaseTerminatedPods = p.nodeDeviceCache.releaseTerminatedPods
+	expected.clock = p.nodeDeviceCache.clock
+	expected.resourceNames = p.nodeDeviceCache.resourceNames
+	devices, err := p.deviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list devices for consistency check, err: %v", err)
+		return nil
+	}
+	for _, device := range devices {
+		expected.updateNodeDevice(device.Name, device)
+	}
+	if p.reservationLister != nil {
+		reservations, err := p.reservationLister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("failed to list reservations for consistency check, err: %v", err)
+			return nil
+		}
+		for _, r := range reservations {
+			if util.IsReservationActive(r) {
+				expected.addReservation(r)
+			}
+		}
+	}
+	pods, err := p.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods for consistency check, err: %v", err)
+		return nil
+	}
+	podsByKey := make(map[types.NamespacedName]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podsByKey[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = pod
+		if pod.Spec.NodeName != "" && !expected.isPodReleased(pod) {
+			expected.addPod(pod)
+		}
+	}
+
+	nodeNames := sets.NewString()
+	for nodeName := range expected.nodeDeviceInfos {
+		nodeNames.Insert(nodeName)
+	}
+	p.nodeDeviceCache.lock.RLock()
+	for nodeName := range p.nodeDeviceCache.nodeDeviceInfos {
+		nodeNames.Insert(nodeName)
+	}
+	p.nodeDeviceCache.lock.RUnlock()
+
+	var discrepancies []frameworkext.Discrepancy
+	for _, nodeName := range nodeNames.List() {
+		if nodeName == "" {
+			continue
+		}
+		expectedInfo := expected.getNodeDevice(nodeName)
+		if expectedInfo == nil {
+			expectedInfo = newNodeDevice()
+		}
+		info := p.nodeDeviceCache.getNodeDevice(nodeName)
+		if info == nil {
+			if repair {
+				info = p.nodeDeviceCache.createNodeDevice(nodeName)
+			} else {
+				info = newNodeDevice()
+			}
+		}
+		discrepancies = append(discrepancies, checkNodeDeviceConsistency(nodeName, info, expectedInfo, podsByKey, repair)...)
+	}
+	return discrepancies
+}
+
+func checkNodeDeviceConsistency(nodeName string, info, expected *nodeDevice, pods map[types.NamespacedName]*corev1.Pod, repair bool) []frameworkext.Discrepancy {
+	info.lock.Lock()
+	defer info.lock.Unlock()
+
+	var discrepancies []frameworkext.Discrepancy
+	if !equalDeviceTotal(info.deviceTotal, expected.deviceTotal) {
+		if repair {
+			deviceTotal := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(expected.deviceTotal))
+			for deviceType, resources := range expected.deviceTotal {
+				deviceTotal[deviceType] = resources.DeepCopy()
+			}
+			info.resetDeviceTotal(deviceTotal)
+			info.deviceUUIDs = expected.deviceUUIDs
+		}
+		discrepancies = append(discrepancies, frameworkext.Discrepancy{
+			Kind:     consistencyKindDevice,
+			Key:      nodeName,
+			Message:  "the device resources in cache differ from the Device",
+			Repaired: repair,
+		})
+	}
+
+	podKeys := map[types.NamespacedName]struct{}{}
+	for _, allocateSet := range info.allocateSet {
+		for podKey := range allocateSet {
+			podKeys[podKey] = struct{}{}
+		}
+	}
+	for _, allocateSet := range expected.allocateSet {
+		for podKey := range allocateSet {
+			podKeys[podKey] = struct{}{}
+		}
+	}
+	sortedPodKeys := make([]types.NamespacedName, 0, len(podKeys))
+	for podKey := range podKeys {
+		sortedPodKeys = append(sortedPodKeys, podKey)
+	}
+	sort.Slice(sortedPodKeys, func(i, j int) bool {
+		return sortedPodKeys[i].String() < sortedPodKeys[j].String()
+	})
+
+	for _, podKey := range sortedPodKeys {
+		cached, allocated := info.getPodAllocations(podKey), expected.getPodAllocations(podKey)
+		if equalDeviceAllocations(cached, allocated) {
+			continue
+		}
+		pod := pods[podKey]
+		if len(allocated) == 0 && (pod != nil && pod.Spec.NodeName == "" || info.isReservingDevices(podKey)) {
+			// assumed in Reserve and waiting for binding
+			continue
+		}
+
+		var message string
+		switch {
+		case len(allocated) == 0:
+			message = fmt.Sprintf("the devices on node %s are still allocated in cache, but the pod does not use them", nodeName)
+		case len(cached) == 0:
+			message = fmt.Sprintf("the devices on node %s allocated to the pod are missing in cache", nodeName)
+		default:
+			message = fmt.Sprintf("the devices on node %s allocated to the pod in cache differ from the annotation", nodeName)
+		}
+		if repair {
+			if len(cached) > 0 {
+				podStub := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: podKey.Namespace, Name: podKey.Name}}
+				info.updateCacheUsed(cached, podStub, false)
+			}
+			if len(allocated) > 0 {
+				info.updateCacheUsed(allocated, pod, true)
+			}
+		}
+		discrepancies = append(discrepancies, frameworkext.Discrepancy{
+			Kind:     consistencyKindPod,
+			Key:      podKey.String(),
+			Message:  message,
+			Repaired: repair,
+		})
+	}
+	return discrepancies
+}
+
+// getPodAllocations converts the allocateSet of the pod back to the DeviceAllocations.
+func (n *nodeDevice) getPodAllocations(podKey types.NamespacedName) apiext.DeviceAllocations {
+	allocations := apiext.DeviceAllocations{}
+	for deviceType, allocateSet := range n.allocateSet {
+		resources, ok := allocateSet[podKey]
+		if !ok {
+			continue
+		}
+		for _, pair := range sortDeviceResourcesByMinor(resources) {
+			allocations[deviceType] = append(allocations[deviceType], &apiext.DeviceAllocation{
+				Minor:     int32(pair.minor),
+				Resources: pair.resources.DeepCopy(),
+			})
+		}
+	}
+	return allocations
+}
+
+func equalDeviceAllocations(a, b apiext.DeviceAllocations) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for deviceType, allocations := range a {
+		if len(allocations) != len(b[deviceType]) {
+			return false
+		}
+		for i, allocation := range allocations {
+			if allocation.Minor != b[deviceType][i].Minor || !quotav1.Equals(allocation.Resources, b[deviceType][i].Resources) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// equalDeviceTotal treats the devices without resources, e.g. the unhealthy ones, as absent.
+func equalDeviceTotal(a, b map[schedulingv1alpha1.DeviceType]deviceResources) bool {
+	return containsDeviceTotal(a, b) && containsDeviceTotal(b, a)
+}
+
+func containsDeviceTotal(a, b map[schedulingv1alpha1.DeviceType]deviceResources) bool {
+	for deviceType, resources := range a {
+		for minor, resourceList := range resources {
+			if quotav1.IsZero(resourceList) {
+				continue
+			}
+			if !quotav1.Equals(resourceList, b[deviceType][minor]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+func newConsistencyTestPod(name, nodeName string, minor int32) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+	}
+	_ = apiext.SetDeviceAllocations(pod, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: minor,
+				Resources: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("50"),
+					apiext.GPUMemoryRatio: resource.MustParse("50"),
+					apiext.GPUMemory:      resource.MustParse("8Gi"),
+				},
+			},
+		},
+	})
+	return pod
+}
+
+func TestPlugin_CheckConsistency(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	koordInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordfake.NewSimpleClientset(), 0)
+	podStore := informerFactory.Core().V1().Pods().Informer().GetStore()
+	deviceStore := koordInformerFactory.Scheduling().V1alpha1().Devices().Informer().GetStore()
+
+	device := generateMultipleFakeDevice()
+	assert.NoError(t, deviceStore.Add(device))
+	boundPod := newConsistencyTestPod("bound", "test-node-1", 0)
+	missingPod := newConsistencyTestPod("missing", "test-node-1", 1)
+	assumedPod := newConsistencyTestPod("assumed", "", 1)
+	stalePod := newConsistencyTestPod("stale", "test-node-1", 0)
+	for _, pod := range []*corev1.Pod{boundPod, missingPod, assumedPod} {
+		assert.NoError(t, podStore.Add(pod))
+	}
+
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice(device.Name, device)
+	deviceCache.addPod(boundPod)
+	// the assumed pod is reserved on the node but not bound yet
+	assumedPod.Spec.NodeName = "test-node-1"
+	deviceCache.addPod(assumedPod)
+	// inject the drift: a deleted pod still in cache, a bound pod missed in cache and a stale device
+	deviceCache.addPod(stalePod)
+	info := deviceCache.getNodeDevice("test-node-1")
+	info.deviceTotal[schedulingv1alpha1.GPU][0] = corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("8Gi"),
+	}
+
+	p := &Plugin{
+		nodeDeviceCache: deviceCache,
+		podLister:       informerFactory.Core().V1().Pods().Lister(),
+		deviceLister:    koordInformerFactory.Scheduling().V1alpha1().Devices().Lister(),
+	}
+	wantKeys := []string{"Device/test-node-1", "Pod/default/missing", "Pod/default/stale"}
+	discrepancyKeys := func(discrepancies []frameworkext.Discrepancy, repaired bool) []string {
+		var keys []string
+		for _, d := range discrepancies {
+			assert.Equal(t, repaired, d.Repaired)
+			keys = append(keys, d.Kind+"/"+d.Key)
+		}
+		return keys
+	}
+
+	assert.Equal(t, wantKeys, discrepancyKeys(p.CheckConsistency(false), false))
+	// detection only does not touch the cache
+	assert.Equal(t, wantKeys, discrepancyKeys(p.CheckConsistency(false), false))
+
+	assert.Equal(t, wantKeys, discrepancyKeys(p.CheckConsistency(true), true))
+	assert.Empty(t, p.CheckConsistency(false))
+
+	info = deviceCache.getNodeDevice("test-node-1")
+	assert.Equal(t, resource.MustParse("16Gi"), info.deviceTotal[schedulingv1alpha1.GPU][0][apiext.GPUMemory])
+	gpuAllocateSet := info.allocateSet[schedulingv1alpha1.GPU]
+	assert.Contains(t, gpuAllocateSet, types.NamespacedName{Namespace: "default", Name: "bound"})
+	assert.Contains(t, gpuAllocateSet, types.NamespacedName{Namespace: "default", Name: "missing"})
+	assert.Contains(t, gpuAllocateSet, types.NamespacedName{Namespace: "default", Name: "assumed"})
+	assert.NotContains(t, gpuAllocateSet, types.NamespacedName{Namespace: "default", Name: "stale"})
+	assert.True(t, quotav1.Equals(corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("50"),
+		apiext.GPUMemoryRatio: resource.MustParse("50"),
+		apiext.GPUMemory:      resource.MustParse("8Gi"),
+	}, info.deviceUsed[schedulingv1alpha1.GPU][0]))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// deviceRelease is the resources of a device released by a pod, which are not allocated again until coolUntil.
+type deviceRelease struct {
+	resources corev1.ResourceList
+	coolUntil time.Time
+}
+
+// recordDeviceReleased records the resources released by a pod, the expired records of the devices are dropped.
+func (n *nodeDevice) recordDeviceReleased(deviceType schedulingv1alpha1.DeviceType, allocations []*apiext.DeviceAllocation, now time.Time, cooldown time.Duration) {
+	if n.deviceReleases == nil {
+		n.deviceReleases = make(map[schedulingv1alpha1.DeviceType]map[int][]deviceRelease)
+	}
+	if n.deviceReleases[deviceType] == nil {
+		n.deviceReleases[deviceType] = make(map[int][]deviceRelease)
+	}
+	releases := n.deviceReleases[deviceType]
+	for _, allocation := range allocations {
+		minor := int(allocation.Minor)
+		var cooling []deviceRelease
+		for _, release := range releases[minor] {
+			if now.Before(release.coolUntil) {
+				cooling = append(cooling, release)
+			}
+		}
+		releases[minor] = append(cooling, deviceRelease{
+			resources: allocation.Resources.DeepCopy(),
+			coolUntil: now.Add(cooldown),
+		})
+	}
+}
+
+// withoutCoolingDevices returns a view of the node devices in which the resources released in the cooldown are not
+// free. The node devices are returned as is if no resources are cooling down.
+func (n *nodeDevice) withoutCoolingDevices(now time.Time) *nodeDevice {
+	var deviceFree map[schedulingv1alpha1.DeviceType]deviceResources
+	for deviceType, releases := range n.deviceReleases {
+		copied := false
+		for minor, minorReleases := range releases {
+			free, ok := n.deviceFree[deviceType][minor]
+			if !ok {
+				continue
+			}
+			var cooling corev1.ResourceList
+			for _, release := range minorReleases {
+				if now.Before(release.coolUntil) {
+					cooling = quotav1.Add(cooling, release.resources)
+				}
+			}
+			if quotav1.IsZero(cooling) {
+				continue
+			}
+			if deviceFree == nil {
+				deviceFree = make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+				for t, resources := range n.deviceFree {
+					deviceFree[t] = resources
+				}
+			}
+			if !copied {
+				resources := make(deviceResources, len(n.deviceFree[deviceType]))
+				for m, r := range n.deviceFree[deviceType] {
+					resources[m] = r
+				}
+				deviceFree[deviceType] = resources
+				copied = true
+			}
+			deviceFree[deviceType][minor] = quotav1.SubtractWithNonNegativeResult(free, cooling)
+		}
+	}
+	if deviceFree == nil {
+		return n
+	}
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
+// withoutCoolingDevices returns the view of the node devices without the cooling resources if the cooldown is enabled.
+func (n *nodeDeviceCache) withoutCoolingDevices(info *nodeDevice) *nodeDevice {
+	if len(n.allocationCooldowns) == 0 {
+		return info
+	}
+	return info.withoutCoolingDevices(n.clock.Now())
+}
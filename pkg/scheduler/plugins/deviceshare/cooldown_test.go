@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newCooldownTestPlugin(cooldown time.Duration) (*Plugin, *clock.FakeClock, *framework.NodeInfo) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	deviceCache := newNodeDeviceCache()
+	deviceCache.clock = fakeClock
+	deviceCache.allocationCooldowns = map[schedulingv1alpha1.DeviceType]time.Duration{
+		schedulingv1alpha1.GPU: cooldown,
+	}
+	deviceCache.createNodeDevice("test-node-1").resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: {
+			0: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.GPUMemory:      resource.MustParse("16Gi"),
+			},
+		},
+	})
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"}})
+	return &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}, fakeClock, nodeInfo
+}
+
+// bindCooldownTestPod reserves the GPU for the pod and returns the bound pod with the allocations.
+func bindCooldownTestPod(t *testing.T, p *Plugin, nodeInfo *framework.NodeInfo, name string, gpuCore int64) *corev1.Pod {
+	pod := newWaitlistTestPod(name)
+	cycleState := newWaitlistTestCycleState(gpuCore)
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+	state, _ := getPreFilterState(cycleState)
+	assert.NoError(t, apiext.SetDeviceAllocations(pod, state.allocationResult))
+	pod.Spec.NodeName = "test-node-1"
+	return pod
+}
+
+func TestAllocationCooldown(t *testing.T) {
+	p, fakeClock, nodeInfo := newCooldownTestPlugin(time.Minute)
+	pod := bindCooldownTestPod(t, p, nodeInfo, "pod-1", 100)
+
+	p.nodeDeviceCache.deletePod(pod)
+	// the pod is deleted after it is terminated, the cooldown is not extended
+	fakeClock.Step(30 * time.Second)
+	p.nodeDeviceCache.deletePod(pod)
+
+	newPod := newWaitlistTestPod("pod-2")
+	assert.Equal(t, ErrInsufficientDevices, p.Filter(context.TODO(), newWaitlistTestCycleState(100), newPod, nodeInfo).Message())
+	assert.False(t, p.Reserve(context.TODO(), newWaitlistTestCycleState(100), newPod, "test-node-1").IsSuccess())
+
+	fakeClock.Step(30 * time.Second)
+	assert.True(t, p.Filter(context.TODO(), newWaitlistTestCycleState(100), newPod, nodeInfo).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), newWaitlistTestCycleState(100), newPod, "test-node-1").IsSuccess())
+}
+
+func TestAllocationCooldownOnlyHoldsReleasedSlice(t *testing.T) {
+	p, fakeClock, nodeInfo := newCooldownTestPlugin(time.Minute)
+	pod := bindCooldownTestPod(t, p, nodeInfo, "pod-1", 50)
+	p.nodeDeviceCache.deletePod(pod)
+
+	// the half never used by the pod is still available
+	assert.False(t, p.Filter(context.TODO(), newWaitlistTestCycleState(100), newWaitlistTestPod("pod-2"), nodeInfo).IsSuccess())
+	bindCooldownTestPod(t, p, nodeInfo, "pod-3", 50)
+	assert.False(t, p.Filter(context.TODO(), newWaitlistTestCycleState(50), newWaitlistTestPod("pod-4"), nodeInfo).IsSuccess())
+
+	fakeClock.Step(time.Minute)
+	bindCooldownTestPod(t, p, nodeInfo, "pod-4", 50)
+}
+
+func TestAllocationCooldownUnreserve(t *testing.T) {
+	p, _, nodeInfo := newCooldownTestPlugin(time.Minute)
+	pod := newWaitlistTestPod("pod-1")
+	cycleState := newWaitlistTestCycleState(100)
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+	// the devices are never used by the pod failed to bind, they are reallocated immediately
+	p.Unreserve(context.TODO(), cycleState, pod, "test-node-1")
+	assert.True(t, p.Filter(context.TODO(), newWaitlistTestCycleState(100), newWaitlistTestPod("pod-2"), nodeInfo).IsSuccess())
+}
+
+func TestWithoutCoolingDevicesKeepsGPUCoreGranularity(t *testing.T) {
+	p, fakeClock, nodeInfo := newCooldownTestPlugin(time.Minute)
+	nodeDevice := p.nodeDeviceCache.getNodeDevice("test-node-1")
+	nodeDevice.gpuCoreGranularity = 10
+	pod := bindCooldownTestPod(t, p, nodeInfo, "pod-1", 50)
+	p.nodeDeviceCache.deletePod(pod)
+
+	view := nodeDevice.withoutCoolingDevices(fakeClock.Now())
+	assert.NotSame(t, nodeDevice, view)
+	assert.Equal(t, int64(10), view.gpuCoreGranularity)
+}
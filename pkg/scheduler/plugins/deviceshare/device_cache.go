@@ -19,16 +19,25 @@ package deviceshare
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	listercorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
 // deviceResources is used to present resources per device.
@@ -73,6 +82,60 @@ type nodeDevice struct {
 	deviceFree  map[schedulingv1alpha1.DeviceType]deviceResources
 	deviceUsed  map[schedulingv1alpha1.DeviceType]deviceResources
 	allocateSet map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList
+	// physicalTotal is the total resources reported by the Device in the view of the overcommitted devices,
+	// nil in the other views.
+	physicalTotal map[schedulingv1alpha1.DeviceType]deviceResources
+	// deviceUUIDs maps the UUID of the device reported by the node to its minor.
+	deviceUUIDs map[schedulingv1alpha1.DeviceType]map[string]int
+	// gpuComputeCapabilities is the compute capabilities of the GPUs reporting it by minor.
+	gpuComputeCapabilities map[int]apiext.GPUComputeCapability
+	// gpuModels is the models of the GPUs by minor, falling back to the model of the node if the GPU
+	// does not report it.
+	gpuModels map[int]string
+	// migPartitions is the MIG partitions of the GPUs reporting them by minor, and migAllocateSet is the MIG
+	// partitions allocated to the pods by minor.
+	migPartitions  map[int][]schedulingv1alpha1.MIGPartition
+	migAllocateSet map[types.NamespacedName]map[int][]schedulingv1alpha1.MIGPartition
+	// sharedSlotAllocateSet is the time-slicing slots of the shared GPUs allocated to the pods by minor.
+	sharedSlotAllocateSet map[types.NamespacedName]map[int][]int32
+	// gpuNUMANodes is the NUMA nodes of the GPUs reporting the topology by minor.
+	gpuNUMANodes map[int]int32
+	// gpuPCIeSwitches and gpuNVLinkGroups are the PCIe switches and the NVLink groups of the GPUs reporting them
+	// by minor.
+	gpuPCIeSwitches map[int]string
+	gpuNVLinkGroups map[int]string
+	// rdmaNUMANodes and rdmaPCIeSwitches are the NUMA nodes and the PCIe switches of the RDMA NICs reporting the
+	// topology by minor.
+	rdmaNUMANodes    map[int]int32
+	rdmaPCIeSwitches map[int]string
+	// rdmaVFs is the virtual functions of the RDMA NICs reporting them by minor, and vfAllocateSet is the virtual
+	// functions allocated to the pods by minor.
+	rdmaVFs       map[int][]schedulingv1alpha1.VirtualFunction
+	vfAllocateSet map[types.NamespacedName]map[int][]schedulingv1alpha1.VirtualFunction
+	// reserveStats counts the recent reserve results to find the nodes failing chronically.
+	reserveStats reserveStatistics
+	// allocatorPolicy is the allocator policy which produced the latest allocation on the node,
+	// and allocatorPolicyChangedTime is when the policy changed.
+	allocatorPolicy            string
+	allocatorPolicyChangedTime time.Time
+	// deviceReleases records the resources released by the pods in the allocation cooldown.
+	deviceReleases map[schedulingv1alpha1.DeviceType]map[int][]deviceRelease
+	// reconcileStrategy is how to reconcile the allocations of the pods which the Device can't account for,
+	// ConservativeMin if empty.
+	reconcileStrategy config.DeviceReconcileStrategy
+	// gpuCoreGranularity is the granularity of the gpu-core share derived for the gpu-memory-only requests.
+	gpuCoreGranularity int64
+	// overcommitRatios is the overcommit ratios of the devices by device type, the allocations within the
+	// overcommitted capacity are not reconciled as the ones the Device can't account for.
+	overcommitRatios map[schedulingv1alpha1.DeviceType]float64
+	// unhealthyDevices is the minors of the devices reported unhealthy or unschedulable. They are never free to
+	// allocate, while the allocations already on them stay accounted until the pods are gone.
+	unhealthyDevices map[schedulingv1alpha1.DeviceType]sets.Int
+	// reservations is the devices held by the reservations on the node by the reservation UID.
+	reservations map[types.UID]*reservedDevices
+	// resourceNames is the resource names of the device types supported by the plugin instance, nil for the
+	// built-in ones.
+	resourceNames deviceResourceNames
 }
 
 func newNodeDevice() *nodeDevice {
@@ -81,9 +144,53 @@ func newNodeDevice() *nodeDevice {
 		deviceFree:  make(map[schedulingv1alpha1.DeviceType]deviceResources),
 		deviceUsed:  make(map[schedulingv1alpha1.DeviceType]deviceResources),
 		allocateSet: make(map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList),
+		deviceUUIDs: make(map[schedulingv1alpha1.DeviceType]map[string]int),
 	}
 }
 
+// shallowCopy returns a view of the node devices sharing every field with n except the lock. A view overrides only
+// the fields it changes, so each field added to nodeDevice must be copied here.
+func (n *nodeDevice) shallowCopy() *nodeDevice {
+	return &nodeDevice{
+		deviceTotal:                n.deviceTotal,
+		deviceFree:                 n.deviceFree,
+		deviceUsed:                 n.deviceUsed,
+		allocateSet:                n.allocateSet,
+		physicalTotal:              n.physicalTotal,
+		deviceUUIDs:                n.deviceUUIDs,
+		gpuComputeCapabilities:     n.gpuComputeCapabilities,
+		gpuModels:                  n.gpuModels,
+		migPartitions:              n.migPartitions,
+		migAllocateSet:             n.migAllocateSet,
+		sharedSlotAllocateSet:      n.sharedSlotAllocateSet,
+		gpuNUMANodes:               n.gpuNUMANodes,
+		gpuPCIeSwitches:            n.gpuPCIeSwitches,
+		gpuNVLinkGroups:            n.gpuNVLinkGroups,
+		rdmaNUMANodes:              n.rdmaNUMANodes,
+		rdmaPCIeSwitches:           n.rdmaPCIeSwitches,
+		rdmaVFs:                    n.rdmaVFs,
+		vfAllocateSet:              n.vfAllocateSet,
+		reserveStats:               n.reserveStats,
+		allocatorPolicy:            n.allocatorPolicy,
+		allocatorPolicyChangedTime: n.allocatorPolicyChangedTime,
+		deviceReleases:             n.deviceReleases,
+		reconcileStrategy:          n.reconcileStrategy,
+		gpuCoreGranularity:         n.gpuCoreGranularity,
+		overcommitRatios:           n.overcommitRatios,
+		unhealthyDevices:           n.unhealthyDevices,
+		reservations:               n.reservations,
+		resourceNames:              n.resourceNames,
+	}
+}
+
+// getResourceNames returns the resource names of the device types supported by the node devices.
+func (n *nodeDevice) getResourceNames() deviceResourceNames {
+	if n.resourceNames == nil {
+		return DeviceResourceNames
+	}
+	return n.resourceNames
+}
+
 func (n *nodeDevice) getNodeDeviceSummary() *NodeDeviceSummary {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
@@ -112,6 +219,19 @@ func (n *nodeDevice) getNodeDeviceSummary() *NodeDeviceSummary {
 	calFunc(n.deviceTotal, nodeDeviceSummary.DeviceTotal, nodeDeviceSummary.DeviceTotalDetail)
 	calFunc(n.deviceFree, nodeDeviceSummary.DeviceFree, nodeDeviceSummary.DeviceFreeDetail)
 	calFunc(n.deviceUsed, nodeDeviceSummary.DeviceUsed, nodeDeviceSummary.DeviceUsedDetail)
+	if len(n.overcommitRatios) > 0 {
+		effectiveTotal := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceTotal))
+		for deviceType := range n.deviceTotal {
+			effectiveTotal[deviceType] = n.getOvercommittedTotal(n.overcommitRatios, deviceType)
+		}
+		nodeDeviceSummary.DeviceEffectiveTotal = make(map[corev1.ResourceName]*resource.Quantity)
+		nodeDeviceSummary.DeviceEffectiveTotalDetail = make(map[schedulingv1alpha1.DeviceType]deviceResources)
+		calFunc(effectiveTotal, nodeDeviceSummary.DeviceEffectiveTotal, nodeDeviceSummary.DeviceEffectiveTotalDetail)
+		nodeDeviceSummary.OvercommitRatios = make(map[schedulingv1alpha1.DeviceType]float64, len(n.overcommitRatios))
+		for deviceType, ratio := range n.overcommitRatios {
+			nodeDeviceSummary.OvercommitRatios[deviceType] = ratio
+		}
+	}
 
 	for deviceType, allocateSet := range n.allocateSet {
 		nodeDeviceSummary.AllocateSet[deviceType] = make(map[string]map[int]corev1.ResourceList)
@@ -123,9 +243,33 @@ func (n *nodeDevice) getNodeDeviceSummary() *NodeDeviceSummary {
 		}
 	}
 
+	nodeDeviceSummary.ReserveSucceeded, nodeDeviceSummary.ReserveFailed = n.reserveStats.count(time.Now())
+	nodeDeviceSummary.AllocatorPolicy = n.allocatorPolicy
+	if !n.allocatorPolicyChangedTime.IsZero() {
+		nodeDeviceSummary.AllocatorPolicyChangedTime = &metav1.Time{Time: n.allocatorPolicyChangedTime}
+	}
+	nodeDeviceSummary.ReservationRemaining = n.getReservationRemaining()
+	for deviceType, minors := range n.unhealthyDevices {
+		if minors.Len() == 0 {
+			continue
+		}
+		if nodeDeviceSummary.UnschedulableDevices == nil {
+			nodeDeviceSummary.UnschedulableDevices = make(map[schedulingv1alpha1.DeviceType][]int)
+		}
+		nodeDeviceSummary.UnschedulableDevices[deviceType] = minors.List()
+	}
+
 	return nodeDeviceSummary
 }
 
+func (n *nodeDevice) recordAllocatorPolicy(policy string, now time.Time) {
+	if n.allocatorPolicy == policy {
+		return
+	}
+	n.allocatorPolicy = policy
+	n.allocatorPolicyChangedTime = now
+}
+
 func (n *nodeDevice) resetDeviceTotal(resources map[schedulingv1alpha1.DeviceType]deviceResources) {
 	for deviceType := range n.deviceTotal {
 		if _, ok := resources[deviceType]; !ok {
@@ -153,23 +297,68 @@ func (n *nodeDevice) updateCacheUsed(deviceAllocations apiext.DeviceAllocations,
 }
 
 func (n *nodeDevice) resetDeviceFree(deviceType schedulingv1alpha1.DeviceType) {
-	if n.deviceFree[deviceType] == nil {
-		n.deviceFree[deviceType] = make(deviceResources)
-	}
 	if n.deviceTotal[deviceType] == nil {
 		n.deviceTotal[deviceType] = make(deviceResources)
 	}
-	n.deviceFree[deviceType] = n.deviceTotal[deviceType].DeepCopy()
+	total := n.deviceTotal[deviceType]
+	free := total.DeepCopy()
+	// unaccounted is the resources allocated to the pods which the Device can't account for
+	var unaccounted corev1.ResourceList
 	for minor, usedResource := range n.deviceUsed[deviceType] {
-		if n.deviceFree[deviceType][minor] == nil {
-			n.deviceFree[deviceType][minor] = make(corev1.ResourceList)
+		capacity, reported := total[minor]
+		// the pods overcommitting the devices are allowed to use up to the overcommitted capacity
+		overcommitted := getOvercommittedCapacity(n.overcommitRatios, deviceType, capacity)
+		switch n.reconcileStrategy {
+		case config.DeviceReconcileTrustPods:
+			// the capacity is raised to cover the allocations only for the free resources, the total stays as reported
+			capacity = quotav1.Add(capacity, quotav1.SubtractWithNonNegativeResult(usedResource, overcommitted))
+		case config.DeviceReconcileTrustCRD:
+		default:
+			// ConservativeMin, which is also the empty strategy
+			if !reported {
+				unaccounted = quotav1.Add(unaccounted, usedResource)
+				continue
+			}
+			// the unhealthy devices report no resources but the pods still run on them
+			if !quotav1.IsZero(capacity) {
+				overflow := quotav1.SubtractWithNonNegativeResult(usedResource, overcommitted)
+				unaccounted = quotav1.Add(unaccounted, quotav1.Mask(overflow, quotav1.ResourceNames(capacity)))
+			}
 		}
-		if n.deviceTotal[deviceType][minor] == nil {
-			n.deviceTotal[deviceType][minor] = make(corev1.ResourceList)
+		free[minor] = quotav1.SubtractWithNonNegativeResult(capacity, usedResource)
+	}
+	if !quotav1.IsZero(unaccounted) {
+		deductDeviceFree(free, unaccounted)
+	}
+	for minor := range n.unhealthyDevices[deviceType] {
+		delete(free, minor)
+	}
+	n.deviceFree[deviceType] = free
+}
+
+// deductDeviceFree deducts the resources from the free resources of the devices, starting with the largest minor.
+func deductDeviceFree(free deviceResources, resources corev1.ResourceList) {
+	minors := make([]int, 0, len(free))
+	for minor := range free {
+		minors = append(minors, minor)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(minors)))
+	remaining := resources.DeepCopy()
+	for _, minor := range minors {
+		for resourceName, quantity := range remaining {
+			available, ok := free[minor][resourceName]
+			if !ok || quantity.Sign() <= 0 || available.Sign() <= 0 {
+				continue
+			}
+			deducted := quantity.DeepCopy()
+			if available.Cmp(deducted) < 0 {
+				deducted = available.DeepCopy()
+			}
+			available.Sub(deducted)
+			quantity.Sub(deducted)
+			free[minor][resourceName] = available
+			remaining[resourceName] = quantity
 		}
-		n.deviceFree[deviceType][minor] = quotav1.SubtractWithNonNegativeResult(
-			n.deviceTotal[deviceType][minor],
-			usedResource)
 	}
 }
 
@@ -183,10 +372,11 @@ func (n *nodeDevice) updateDeviceUsed(deviceType schedulingv1alpha1.DeviceType,
 		if deviceUsed[int(allocation.Minor)] == nil {
 			deviceUsed[int(allocation.Minor)] = make(corev1.ResourceList)
 		}
+		resources := n.getAccountedResources(deviceType, allocation)
 		if add {
-			deviceUsed[int(allocation.Minor)] = quotav1.Add(deviceUsed[int(allocation.Minor)], allocation.Resources)
+			deviceUsed[int(allocation.Minor)] = quotav1.Add(deviceUsed[int(allocation.Minor)], resources)
 		} else {
-			used := quotav1.SubtractWithNonNegativeResult(deviceUsed[int(allocation.Minor)], allocation.Resources)
+			used := quotav1.SubtractWithNonNegativeResult(deviceUsed[int(allocation.Minor)], resources)
 			if quotav1.IsZero(used) {
 				delete(deviceUsed, int(allocation.Minor))
 			} else {
@@ -199,6 +389,15 @@ func (n *nodeDevice) updateDeviceUsed(deviceType schedulingv1alpha1.DeviceType,
 	}
 }
 
+// getAccountedResources returns the resources of the device used by the allocation. The exclusive allocation uses
+// the whole device, so that the rest of the device is not allocated to the other pods.
+func (n *nodeDevice) getAccountedResources(deviceType schedulingv1alpha1.DeviceType, allocation *apiext.DeviceAllocation) corev1.ResourceList {
+	if !allocation.Exclusive {
+		return allocation.Resources
+	}
+	return quotav1.Max(allocation.Resources, n.deviceTotal[deviceType][int(allocation.Minor)])
+}
+
 func (n *nodeDevice) isValid(deviceType schedulingv1alpha1.DeviceType, pod *corev1.Pod, add bool) bool {
 	allocateSet := n.allocateSet[deviceType]
 	if allocateSet == nil {
@@ -239,37 +438,208 @@ func (n *nodeDevice) updateAllocateSet(deviceType schedulingv1alpha1.DeviceType,
 	} else {
 		delete(n.allocateSet[deviceType], podNamespacedName)
 	}
+	if deviceType == schedulingv1alpha1.GPU {
+		n.updateMIGAllocateSet(podNamespacedName, allocations, add)
+		n.updateSharedSlotAllocateSet(podNamespacedName, allocations, add)
+	}
+	if deviceType == schedulingv1alpha1.RDMA {
+		n.updateVFAllocateSet(podNamespacedName, allocations, add)
+	}
+}
+
+func (n *nodeDevice) updateMIGAllocateSet(podNamespacedName types.NamespacedName, allocations []*apiext.DeviceAllocation, add bool) {
+	if !add {
+		delete(n.migAllocateSet, podNamespacedName)
+		return
+	}
+	partitions := getAllocatedMIGPartitions(allocations)
+	if len(partitions) == 0 {
+		return
+	}
+	if n.migAllocateSet == nil {
+		n.migAllocateSet = make(map[types.NamespacedName]map[int][]schedulingv1alpha1.MIGPartition)
+	}
+	n.migAllocateSet[podNamespacedName] = partitions
 }
 
-func (n *nodeDevice) tryAllocateDevice(podRequest corev1.ResourceList) (apiext.DeviceAllocations, error) {
+// getAllocatedMIGPartitions returns the MIG partitions in the GPU allocations by minor.
+func getAllocatedMIGPartitions(allocations []*apiext.DeviceAllocation) map[int][]schedulingv1alpha1.MIGPartition {
+	var partitions map[int][]schedulingv1alpha1.MIGPartition
+	for _, allocation := range allocations {
+		if len(allocation.MIGPartitions) == 0 {
+			continue
+		}
+		if partitions == nil {
+			partitions = make(map[int][]schedulingv1alpha1.MIGPartition)
+		}
+		partitions[int(allocation.Minor)] = append(partitions[int(allocation.Minor)], allocation.MIGPartitions...)
+	}
+	return partitions
+}
+
+func (n *nodeDevice) tryAllocateDevice(podRequest corev1.ResourceList, targetGPUUUID string) (apiext.DeviceAllocations, error) {
 	allocateResult := make(apiext.DeviceAllocations)
 
-	for deviceType := range DeviceResourceNames {
+	for deviceType := range n.getResourceNames() {
 		switch deviceType {
-		case schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA:
-			if !hasDeviceResource(podRequest, deviceType) {
+		case schedulingv1alpha1.GPU:
+			if !n.getResourceNames().hasDeviceResource(podRequest, deviceType) {
 				break
 			}
-			if err := n.tryAllocateCommonDevice(podRequest, deviceType, allocateResult); err != nil {
-				return nil, err
-			}
-		case schedulingv1alpha1.GPU:
-			if !hasDeviceResource(podRequest, deviceType) {
+			if targetGPUUUID != "" {
+				if err := n.tryAllocateGPUByUUID(podRequest, targetGPUUUID, allocateResult); err != nil {
+					return nil, err
+				}
 				break
 			}
 			if err := n.tryAllocateGPU(podRequest, allocateResult); err != nil {
 				return nil, err
 			}
 		default:
-			klog.Warningf("device type %v is not supported yet", deviceType)
+			// RDMA, FPGA and the custom device types are allocated by the whole devices
+			if !n.getResourceNames().hasDeviceResource(podRequest, deviceType) {
+				break
+			}
+			if err := n.tryAllocateCommonDevice(podRequest, deviceType, allocateResult); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return allocateResult, nil
 }
 
+// tryAllocateByReuseHint allocates the devices in the reuse hint only, e.g. the GPUs and the RDMA NICs used by the pod
+// before it restarted. All the requested device types must be hinted and satisfied by the hinted devices, so that
+// the pairs of GPU and NIC are reclaimed together or not at all. It returns nil if the hint cannot be applied.
+func (n *nodeDevice) tryAllocateByReuseHint(podRequest corev1.ResourceList, hint apiext.DeviceAllocations) apiext.DeviceAllocations {
+	if len(hint) == 0 {
+		return nil
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	for deviceType := range n.getResourceNames() {
+		if !n.getResourceNames().hasDeviceResource(podRequest, deviceType) {
+			continue
+		}
+		if len(hint[deviceType]) == 0 {
+			return nil
+		}
+		hintedFree := deviceResources{}
+		for _, allocation := range hint[deviceType] {
+			if free, ok := n.deviceFree[deviceType][int(allocation.Minor)]; ok {
+				hintedFree[int(allocation.Minor)] = free
+			}
+		}
+		deviceFree[deviceType] = hintedFree
+	}
+	hinted := n.shallowCopy()
+	hinted.deviceFree = deviceFree
+	allocations, err := hinted.tryAllocateDevice(podRequest, "")
+	if err != nil {
+		klog.V(5).Infof("the hinted devices cannot be reused, err: %v", err)
+		return nil
+	}
+	return allocations
+}
+
+// hasGPUsOfComputeCapability returns whether the node has any GPU of at least the given compute capability.
+func (n *nodeDevice) hasGPUsOfComputeCapability(min apiext.GPUComputeCapability) bool {
+	for _, capability := range n.gpuComputeCapabilities {
+		if capability.AtLeast(min) {
+			return true
+		}
+	}
+	return false
+}
+
+// withGPUsOfComputeCapability returns the view of the node devices in which only the GPUs of at least
+// the given compute capability are free.
+func (n *nodeDevice) withGPUsOfComputeCapability(min apiext.GPUComputeCapability) *nodeDevice {
+	gpuFree := deviceResources{}
+	for minor, free := range n.deviceFree[schedulingv1alpha1.GPU] {
+		if capability, ok := n.gpuComputeCapabilities[minor]; ok && capability.AtLeast(min) {
+			gpuFree[minor] = free
+		}
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	deviceFree[schedulingv1alpha1.GPU] = gpuFree
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
+// hasUnusedGPUs returns whether the node has any GPU used by no pod.
+func (n *nodeDevice) hasUnusedGPUs() bool {
+	for minor := range n.deviceFree[schedulingv1alpha1.GPU] {
+		if quotav1.IsZero(n.deviceUsed[schedulingv1alpha1.GPU][minor]) {
+			return true
+		}
+	}
+	return false
+}
+
+// withUnusedGPUsOnly returns the view of the node devices in which only the GPUs used by no pod are free.
+func (n *nodeDevice) withUnusedGPUsOnly() *nodeDevice {
+	gpuFree := deviceResources{}
+	for minor, free := range n.deviceFree[schedulingv1alpha1.GPU] {
+		if quotav1.IsZero(n.deviceUsed[schedulingv1alpha1.GPU][minor]) {
+			gpuFree[minor] = free
+		}
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	deviceFree[schedulingv1alpha1.GPU] = gpuFree
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
+// markGPUsExclusive marks the GPU allocations exclusive.
+func markGPUsExclusive(allocations apiext.DeviceAllocations) {
+	for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+		allocation.Exclusive = true
+	}
+}
+
+// hasGPUsOfModels returns whether the node has any GPU of the models allowed by the selector.
+func (n *nodeDevice) hasGPUsOfModels(selector apiext.GPUModelSelector) bool {
+	for _, model := range n.gpuModels {
+		if selector.Matches(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// withGPUsOfModels returns the view of the node devices in which only the GPUs of the models allowed by the
+// selector are free.
+func (n *nodeDevice) withGPUsOfModels(selector apiext.GPUModelSelector) *nodeDevice {
+	gpuFree := deviceResources{}
+	for minor, free := range n.deviceFree[schedulingv1alpha1.GPU] {
+		if model, ok := n.gpuModels[minor]; ok && selector.Matches(model) {
+			gpuFree[minor] = free
+		}
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	deviceFree[schedulingv1alpha1.GPU] = gpuFree
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
 func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType, allocateResult apiext.DeviceAllocations) error {
-	podRequest = quotav1.Mask(podRequest, DeviceResourceNames[deviceType])
+	podRequest = quotav1.Mask(podRequest, n.getResourceNames()[deviceType])
 	nodeDeviceTotal := n.deviceTotal[deviceType]
 	if len(nodeDeviceTotal) <= 0 {
 		return fmt.Errorf("node does not have enough %v", deviceType)
@@ -277,26 +647,19 @@ func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, dev
 
 	var deviceAllocations []*apiext.DeviceAllocation
 
-	if isMultipleCommonDevicePod(podRequest, deviceType) {
-		var commonDeviceWanted int64
-		var podRequestPerCard corev1.ResourceList
-		switch deviceType {
-		case schedulingv1alpha1.RDMA:
-			commonDevice := podRequest[apiext.KoordRDMA]
-			commonDeviceWanted = commonDevice.Value() / 100
-			podRequestPerCard = corev1.ResourceList{
-				apiext.KoordRDMA: *resource.NewQuantity(commonDevice.Value()/commonDeviceWanted, resource.DecimalSI),
-			}
-		case schedulingv1alpha1.FPGA:
-			commonDevice := podRequest[apiext.KoordFPGA]
-			commonDeviceWanted = commonDevice.Value() / 100
-			podRequestPerCard = corev1.ResourceList{
-				apiext.KoordFPGA: *resource.NewQuantity(commonDevice.Value()/commonDeviceWanted, resource.DecimalSI),
-			}
+	if n.getResourceNames().isMultipleCommonDevicePod(podRequest, deviceType) {
+		resourceName, _ := n.getResourceNames().getCommonDeviceResourceName(deviceType)
+		commonDevice := podRequest[resourceName]
+		commonDeviceWanted := commonDevice.Value() / 100
+		podRequestPerCard := corev1.ResourceList{
+			resourceName: *resource.NewQuantity(commonDevice.Value()/commonDeviceWanted, resource.DecimalSI),
 		}
 		satisfiedDeviceCount := 0
 		orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[deviceType])
 		for _, deviceResource := range orderedDeviceResources {
+			if !n.hasFreeVirtualFunction(deviceType, deviceResource.minor) {
+				continue
+			}
 			if satisfied, _ := quotav1.LessThanOrEqual(podRequestPerCard, deviceResource.resources); satisfied {
 				satisfiedDeviceCount++
 				deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
@@ -305,37 +668,119 @@ func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, dev
 				})
 			}
 			if satisfiedDeviceCount == int(commonDeviceWanted) {
+				n.allocateVirtualFunctions(deviceType, deviceAllocations)
 				allocateResult[deviceType] = deviceAllocations
 				return nil
 			}
 		}
 		klog.V(5).Infof("node resource does not satisfy pod's multiple %v request, expect %v, got %v", deviceType, commonDeviceWanted, satisfiedDeviceCount)
-		return fmt.Errorf("node does not have enough %v", deviceType)
+		return n.insufficientCommonDeviceError(deviceType)
 	}
 
 	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[deviceType])
 	for _, deviceResource := range orderedDeviceResources {
+		if !n.hasFreeVirtualFunction(deviceType, deviceResource.minor) {
+			continue
+		}
 		if satisfied, _ := quotav1.LessThanOrEqual(podRequest, deviceResource.resources); satisfied {
 			deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
 				Minor:     int32(deviceResource.minor),
 				Resources: podRequest,
 			})
+			n.allocateVirtualFunctions(deviceType, deviceAllocations)
 			allocateResult[deviceType] = deviceAllocations
 			return nil
 		}
 	}
 	klog.V(5).Infof("node resource does not satisfy pod's %v request", deviceType)
+	return n.insufficientCommonDeviceError(deviceType)
+}
+
+// insufficientCommonDeviceError tells the RDMA NICs running out of the virtual functions from the other shortages.
+func (n *nodeDevice) insufficientCommonDeviceError(deviceType schedulingv1alpha1.DeviceType) error {
+	if deviceType == schedulingv1alpha1.RDMA && len(n.rdmaVFs) > 0 {
+		return errRDMAVFsExhausted
+	}
 	return fmt.Errorf("node does not have enough %v", deviceType)
 }
 
+// hasFreeVirtualFunction checks if the device has any virtual function not allocated to the pods. It is always true
+// for the devices not reporting the virtual functions.
+func (n *nodeDevice) hasFreeVirtualFunction(deviceType schedulingv1alpha1.DeviceType, minor int) bool {
+	if deviceType != schedulingv1alpha1.RDMA || len(n.rdmaVFs[minor]) == 0 {
+		return true
+	}
+	return len(n.getFreeVirtualFunctions(minor)) > 0
+}
+
+func (n *nodeDevice) updateSharedSlotAllocateSet(podNamespacedName types.NamespacedName, allocations []*apiext.DeviceAllocation, add bool) {
+	if !add {
+		delete(n.sharedSlotAllocateSet, podNamespacedName)
+		return
+	}
+	slots := getAllocatedSharedSlots(allocations)
+	if len(slots) == 0 {
+		return
+	}
+	if n.sharedSlotAllocateSet == nil {
+		n.sharedSlotAllocateSet = make(map[types.NamespacedName]map[int][]int32)
+	}
+	n.sharedSlotAllocateSet[podNamespacedName] = slots
+}
+
+// getAllocatedSharedSlots returns the time-slicing slots in the GPU allocations by minor.
+func getAllocatedSharedSlots(allocations []*apiext.DeviceAllocation) map[int][]int32 {
+	var slots map[int][]int32
+	for _, allocation := range allocations {
+		if len(allocation.SharedSlots) == 0 {
+			continue
+		}
+		if slots == nil {
+			slots = make(map[int][]int32)
+		}
+		slots[int(allocation.Minor)] = append(slots[int(allocation.Minor)], allocation.SharedSlots...)
+	}
+	return slots
+}
+
+// allocateVirtualFunctions picks a free virtual function for each allocation on the RDMA NICs reporting them, in the
+// order of the bus addresses. The allocation takes the whole virtual function even if less is requested.
+func (n *nodeDevice) allocateVirtualFunctions(deviceType schedulingv1alpha1.DeviceType, deviceAllocations []*apiext.DeviceAllocation) {
+	if deviceType != schedulingv1alpha1.RDMA {
+		return
+	}
+	for _, allocation := range deviceAllocations {
+		free := n.getFreeVirtualFunctions(int(allocation.Minor))
+		if len(free) == 0 {
+			continue
+		}
+		allocation.Resources = getVirtualFunctionResources(1)
+		allocation.VirtualFunctions = free[:1]
+	}
+}
+
 func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResult apiext.DeviceAllocations) error {
+	migRequest := getMIGRequest(podRequest)
+	sharedSlots, sharedSlotsRequested := podRequest[apiext.GPUSharedSlots]
 	podRequest = quotav1.Mask(podRequest, DeviceResourceNames[schedulingv1alpha1.GPU])
 	nodeDeviceTotal := n.deviceTotal[schedulingv1alpha1.GPU]
 	if len(nodeDeviceTotal) <= 0 {
 		return fmt.Errorf("node does not have enough GPU")
 	}
 
-	fillGPUTotalMem(nodeDeviceTotal, podRequest)
+	if len(migRequest) > 0 {
+		return n.tryAllocateMIG(migRequest, allocateResult)
+	}
+	if sharedSlotsRequested {
+		return n.tryAllocateSharedSlots(sharedSlots.Value(), allocateResult)
+	}
+	n = n.withoutMIGAndSharedSlotsGPUs()
+
+	if isGPUMemoryOnlyRequest(podRequest) {
+		return n.tryAllocateGPUMemoryOnly(podRequest, allocateResult)
+	}
+
+	fillGPUTotalMem(n.getPhysicalTotal(schedulingv1alpha1.GPU), podRequest)
 
 	var deviceAllocations []*apiext.DeviceAllocation
 	if isMultipleGPUPod(podRequest) {
@@ -346,23 +791,27 @@ func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResu
 			apiext.GPUMemory:      *resource.NewQuantity(gpuMem.Value()/gpuWanted, resource.BinarySI),
 			apiext.GPUMemoryRatio: *resource.NewQuantity(gpuMemRatio.Value()/gpuWanted, resource.DecimalSI),
 		}
-		satisfiedDeviceCount := 0
+		var satisfiedMinors []int
 		orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
 		for _, deviceResource := range orderedDeviceResources {
-			if satisfied, _ := quotav1.LessThanOrEqual(podRequestPerCard, deviceResource.resources); satisfied {
-				satisfiedDeviceCount++
-				deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
-					Minor:     int32(deviceResource.minor),
-					Resources: podRequestPerCard,
-				})
-			}
-			if satisfiedDeviceCount == int(gpuWanted) {
-				allocateResult[schedulingv1alpha1.GPU] = deviceAllocations
-				return nil
+			if satisfied, _ := quotav1.LessThanOrEqual(podRequestPerCard, deviceResource.resources); satisfied &&
+				n.fitsGPUMemoryCapacity(deviceResource.minor, podRequestPerCard) {
+				satisfiedMinors = append(satisfiedMinors, deviceResource.minor)
 			}
 		}
-		klog.V(5).Infof("node GPU resource does not satisfy pod's multiple GPU request, expect %v, got %v", gpuWanted, satisfiedDeviceCount)
-		return fmt.Errorf("node does not have enough GPU")
+		if len(satisfiedMinors) < int(gpuWanted) {
+			klog.V(5).Infof("node GPU resource does not satisfy pod's multiple GPU request, expect %v, got %v", gpuWanted, len(satisfiedMinors))
+			return fmt.Errorf("node does not have enough GPU")
+		}
+		// the count is guaranteed here, and the topology only decides which of the satisfied GPUs are allocated
+		for _, minor := range n.selectGPUsByTopology(satisfiedMinors, int(gpuWanted)) {
+			deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
+				Minor:     int32(minor),
+				Resources: podRequestPerCard,
+			})
+		}
+		allocateResult[schedulingv1alpha1.GPU] = deviceAllocations
+		return nil
 	}
 
 	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
@@ -370,6 +819,9 @@ func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResu
 		if satisfied, _ := quotav1.LessThanOrEqual(podRequest, deviceResource.resources); !satisfied {
 			continue
 		}
+		if !n.fitsGPUMemoryCapacity(deviceResource.minor, podRequest) {
+			continue
+		}
 
 		deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
 			Minor:     int32(deviceResource.minor),
@@ -382,17 +834,813 @@ func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResu
 	return fmt.Errorf("node does not have enough GPU")
 }
 
+// tryAllocateGPUMemoryOnly allocates a GPU to the pod requesting only gpu-memory. The gpu-core share is derived
+// for each candidate GPU from its own memory, since the GPUs of a node may differ in memory.
+func (n *nodeDevice) tryAllocateGPUMemoryOnly(podRequest corev1.ResourceList, allocateResult apiext.DeviceAllocations) error {
+	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
+	for _, deviceResource := range orderedDeviceResources {
+		instanceRequest, ok := deriveGPUMemoryOnlyRequest(podRequest, n.getPhysicalTotal(schedulingv1alpha1.GPU)[deviceResource.minor], n.gpuCoreGranularity)
+		if !ok {
+			continue
+		}
+		if satisfied, _ := quotav1.LessThanOrEqual(instanceRequest, deviceResource.resources); !satisfied {
+			continue
+		}
+		if !n.fitsGPUMemoryCapacity(deviceResource.minor, instanceRequest) {
+			continue
+		}
+		allocateResult[schedulingv1alpha1.GPU] = []*apiext.DeviceAllocation{
+			{
+				Minor:     int32(deviceResource.minor),
+				Resources: instanceRequest,
+			},
+		}
+		return nil
+	}
+	klog.V(5).Infof("node GPU resource does not satisfy pod's gpu-memory request")
+	return fmt.Errorf("node does not have enough GPU")
+}
+
+// tryAllocateMIG allocates the MIG instances of the requested profile. The instances are allocated from a single GPU
+// if possible, otherwise from as few GPUs as possible in the order of the minors.
+func (n *nodeDevice) tryAllocateMIG(migRequest corev1.ResourceList, allocateResult apiext.DeviceAllocations) error {
+	var resourceName corev1.ResourceName
+	var wanted int64
+	for name, quantity := range migRequest {
+		resourceName, wanted = name, quantity.Value()
+	}
+	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
+	for _, deviceResource := range orderedDeviceResources {
+		free := deviceResource.resources[resourceName]
+		if free.Value() >= wanted {
+			deviceAllocations := []*apiext.DeviceAllocation{
+				{
+					Minor:     int32(deviceResource.minor),
+					Resources: corev1.ResourceList{resourceName: *resource.NewQuantity(wanted, resource.DecimalSI)},
+				},
+			}
+			if err := n.allocateMIGPartitions(resourceName, deviceAllocations); err != nil {
+				return err
+			}
+			allocateResult[schedulingv1alpha1.GPU] = deviceAllocations
+			return nil
+		}
+	}
+	sort.SliceStable(orderedDeviceResources, func(i, j int) bool {
+		a, b := orderedDeviceResources[i].resources[resourceName], orderedDeviceResources[j].resources[resourceName]
+		return a.Cmp(b) > 0
+	})
+	var deviceAllocations []*apiext.DeviceAllocation
+	remaining := wanted
+	for _, deviceResource := range orderedDeviceResources {
+		free := deviceResource.resources[resourceName]
+		if remaining <= 0 || free.Value() <= 0 {
+			break
+		}
+		count := free.Value()
+		if count > remaining {
+			count = remaining
+		}
+		deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
+			Minor:     int32(deviceResource.minor),
+			Resources: corev1.ResourceList{resourceName: *resource.NewQuantity(count, resource.DecimalSI)},
+		})
+		remaining -= count
+	}
+	if remaining > 0 {
+		klog.V(5).Infof("node MIG instances do not satisfy pod's request, expect %v %v", wanted, resourceName)
+		return fmt.Errorf("node does not have enough GPU")
+	}
+	sort.Slice(deviceAllocations, func(i, j int) bool {
+		return deviceAllocations[i].Minor < deviceAllocations[j].Minor
+	})
+	if err := n.allocateMIGPartitions(resourceName, deviceAllocations); err != nil {
+		return err
+	}
+	allocateResult[schedulingv1alpha1.GPU] = deviceAllocations
+	return nil
+}
+
+// tryAllocateSharedSlots allocates the time-slicing slots of a shared GPU. All the slots are allocated from a single
+// GPU in the order of the minors, since the slots of the different GPUs do not add up to a larger time share.
+func (n *nodeDevice) tryAllocateSharedSlots(wanted int64, allocateResult apiext.DeviceAllocations) error {
+	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
+	for _, deviceResource := range orderedDeviceResources {
+		free := deviceResource.resources[apiext.GPUSharedSlots]
+		if free.Value() < wanted {
+			continue
+		}
+		slots := n.getFreeSharedSlots(deviceResource.minor)
+		if int64(len(slots)) < wanted {
+			continue
+		}
+		allocateResult[schedulingv1alpha1.GPU] = []*apiext.DeviceAllocation{
+			{
+				Minor:       int32(deviceResource.minor),
+				Resources:   corev1.ResourceList{apiext.GPUSharedSlots: *resource.NewQuantity(wanted, resource.DecimalSI)},
+				SharedSlots: slots[:wanted],
+			},
+		}
+		return nil
+	}
+	klog.V(5).Infof("node GPU shared slots do not satisfy pod's request, expect %v", wanted)
+	return fmt.Errorf("node does not have enough GPU")
+}
+
+// getFreeSharedSlots returns the indexes of the time-slicing slots on the GPU which are not allocated to any pod
+// in ascending order.
+func (n *nodeDevice) getFreeSharedSlots(minor int) []int32 {
+	used := sets.NewInt32()
+	for _, slots := range n.sharedSlotAllocateSet {
+		used.Insert(slots[minor]...)
+	}
+	total := n.deviceTotal[schedulingv1alpha1.GPU][minor][apiext.GPUSharedSlots]
+	var free []int32
+	for i := int32(0); i < int32(total.Value()); i++ {
+		if !used.Has(i) {
+			free = append(free, i)
+		}
+	}
+	return free
+}
+
+// isSharedSlotsGPU checks if the GPU is shared by the time-slicing slots.
+func isSharedSlotsGPU(resources corev1.ResourceList) bool {
+	_, ok := resources[apiext.GPUSharedSlots]
+	return ok
+}
+
+// hasSharedSlotsGPU checks if any GPU of the node is shared by the time-slicing slots.
+func (n *nodeDevice) hasSharedSlotsGPU() bool {
+	for _, total := range n.deviceTotal[schedulingv1alpha1.GPU] {
+		if isSharedSlotsGPU(total) {
+			return true
+		}
+	}
+	return false
+}
+
+// allocateMIGPartitions picks the free MIG partitions of the requested profile for the allocations on the GPUs
+// reporting the partitions, in the order of the GPU instance and compute instance IDs.
+func (n *nodeDevice) allocateMIGPartitions(resourceName corev1.ResourceName, deviceAllocations []*apiext.DeviceAllocation) error {
+	profile := strings.TrimPrefix(string(resourceName), apiext.NvidiaMIGPrefix)
+	for _, allocation := range deviceAllocations {
+		minor := int(allocation.Minor)
+		if len(n.migPartitions[minor]) == 0 {
+			continue
+		}
+		wanted := allocation.Resources[resourceName]
+		free := n.getFreeMIGPartitions(minor, profile)
+		if int64(len(free)) < wanted.Value() {
+			klog.V(5).Infof("GPU %v does not have enough free MIG partitions, expect %v %v", minor, wanted.Value(), profile)
+			return fmt.Errorf("node does not have enough GPU")
+		}
+		allocation.MIGPartitions = free[:wanted.Value()]
+	}
+	return nil
+}
+
+// getFreeMIGPartitions returns the MIG partitions of the profile on the GPU which are not allocated to any pod.
+func (n *nodeDevice) getFreeMIGPartitions(minor int, profile string) []schedulingv1alpha1.MIGPartition {
+	used := map[schedulingv1alpha1.MIGPartition]struct{}{}
+	for _, partitions := range n.migAllocateSet {
+		for _, partition := range partitions[minor] {
+			used[partition] = struct{}{}
+		}
+	}
+	var free []schedulingv1alpha1.MIGPartition
+	for _, partition := range n.migPartitions[minor] {
+		if _, ok := used[partition]; !ok && partition.Profile == profile {
+			free = append(free, partition)
+		}
+	}
+	return free
+}
+
+// countMIGPartitions returns the number of the MIG partitions of each profile.
+func countMIGPartitions(partitions []schedulingv1alpha1.MIGPartition) map[string]int32 {
+	instances := map[string]int32{}
+	for _, partition := range partitions {
+		instances[partition.Profile]++
+	}
+	return instances
+}
+
+// sortMIGPartitions sorts the MIG partitions by the GPU instance and compute instance IDs.
+func sortMIGPartitions(partitions []schedulingv1alpha1.MIGPartition) []schedulingv1alpha1.MIGPartition {
+	sorted := make([]schedulingv1alpha1.MIGPartition, len(partitions))
+	copy(sorted, partitions)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].GPUInstanceID != sorted[j].GPUInstanceID {
+			return sorted[i].GPUInstanceID < sorted[j].GPUInstanceID
+		}
+		return sorted[i].ComputeInstanceID < sorted[j].ComputeInstanceID
+	})
+	return sorted
+}
+
+func (n *nodeDevice) updateVFAllocateSet(podNamespacedName types.NamespacedName, allocations []*apiext.DeviceAllocation, add bool) {
+	if !add {
+		delete(n.vfAllocateSet, podNamespacedName)
+		return
+	}
+	vfs := getAllocatedVirtualFunctions(allocations)
+	if len(vfs) == 0 {
+		return
+	}
+	if n.vfAllocateSet == nil {
+		n.vfAllocateSet = make(map[types.NamespacedName]map[int][]schedulingv1alpha1.VirtualFunction)
+	}
+	n.vfAllocateSet[podNamespacedName] = vfs
+}
+
+// getAllocatedVirtualFunctions returns the virtual functions in the RDMA allocations by minor.
+func getAllocatedVirtualFunctions(allocations []*apiext.DeviceAllocation) map[int][]schedulingv1alpha1.VirtualFunction {
+	var vfs map[int][]schedulingv1alpha1.VirtualFunction
+	for _, allocation := range allocations {
+		if len(allocation.VirtualFunctions) == 0 {
+			continue
+		}
+		if vfs == nil {
+			vfs = make(map[int][]schedulingv1alpha1.VirtualFunction)
+		}
+		vfs[int(allocation.Minor)] = append(vfs[int(allocation.Minor)], allocation.VirtualFunctions...)
+	}
+	return vfs
+}
+
+// getFreeVirtualFunctions returns the virtual functions of the RDMA NIC which are not allocated to any pod.
+func (n *nodeDevice) getFreeVirtualFunctions(minor int) []schedulingv1alpha1.VirtualFunction {
+	used := map[schedulingv1alpha1.VirtualFunction]struct{}{}
+	for _, vfs := range n.vfAllocateSet {
+		for _, vf := range vfs[minor] {
+			used[vf] = struct{}{}
+		}
+	}
+	var free []schedulingv1alpha1.VirtualFunction
+	for _, vf := range n.rdmaVFs[minor] {
+		if _, ok := used[vf]; !ok {
+			free = append(free, vf)
+		}
+	}
+	return free
+}
+
+// getVirtualFunctionResources returns the resources of the virtual functions, each of which counts as a whole device.
+func getVirtualFunctionResources(count int) corev1.ResourceList {
+	return corev1.ResourceList{
+		apiext.KoordRDMA: *resource.NewQuantity(int64(100*count), resource.DecimalSI),
+	}
+}
+
+// sortVirtualFunctions sorts the virtual functions by the bus addresses.
+func sortVirtualFunctions(vfs []schedulingv1alpha1.VirtualFunction) []schedulingv1alpha1.VirtualFunction {
+	sorted := make([]schedulingv1alpha1.VirtualFunction, len(vfs))
+	copy(sorted, vfs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].BusID < sorted[j].BusID
+	})
+	return sorted
+}
+
+// getMIGResources returns the resources of the MIG instances of each profile.
+func getMIGResources(instances map[string]int32) corev1.ResourceList {
+	resources := corev1.ResourceList{}
+	for profile, count := range instances {
+		resources[apiext.NvidiaMIGResourceName(profile)] = *resource.NewQuantity(int64(count), resource.DecimalSI)
+	}
+	return resources
+}
+
+// withoutMIGAndSharedSlotsGPUs returns the view of the node devices in which the GPUs carved into the MIG instances
+// and the GPUs shared by the time-slicing slots are not free, since the missing GPU resources of them would be
+// regarded as satisfied by the full GPU requests.
+func (n *nodeDevice) withoutMIGAndSharedSlotsGPUs() *nodeDevice {
+	gpuFree := deviceResources{}
+	for minor, free := range n.deviceFree[schedulingv1alpha1.GPU] {
+		total := n.deviceTotal[schedulingv1alpha1.GPU][minor]
+		if len(getMIGRequest(total)) == 0 && !isSharedSlotsGPU(total) {
+			gpuFree[minor] = free
+		}
+	}
+	if len(gpuFree) == len(n.deviceFree[schedulingv1alpha1.GPU]) {
+		return n
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	deviceFree[schedulingv1alpha1.GPU] = gpuFree
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
+// getDeviceNUMANodes returns the NUMA nodes attached by all the device types in ascending order.
+// It returns false if any device of the types does not report the topology.
+func (n *nodeDevice) getDeviceNUMANodes(deviceTypes ...schedulingv1alpha1.DeviceType) ([]int32, bool) {
+	var numaNodes sets.Int32
+	for _, deviceType := range deviceTypes {
+		deviceNUMANodes := n.getNUMANodesOfDeviceType(deviceType)
+		typeNUMANodes := sets.NewInt32()
+		for minor := range n.deviceTotal[deviceType] {
+			numaNode, ok := deviceNUMANodes[minor]
+			if !ok {
+				return nil, false
+			}
+			typeNUMANodes.Insert(numaNode)
+		}
+		if numaNodes == nil {
+			numaNodes = typeNUMANodes
+		} else {
+			numaNodes = numaNodes.Intersection(typeNUMANodes)
+		}
+	}
+	return numaNodes.List(), true
+}
+
+// getNUMANodesOfDeviceType returns the NUMA nodes of the devices of the type reporting the topology by minor.
+func (n *nodeDevice) getNUMANodesOfDeviceType(deviceType schedulingv1alpha1.DeviceType) map[int]int32 {
+	switch deviceType {
+	case schedulingv1alpha1.GPU:
+		return n.gpuNUMANodes
+	case schedulingv1alpha1.RDMA:
+		return n.rdmaNUMANodes
+	}
+	return nil
+}
+
+// withDevicesOnNUMANode returns the view of the node devices in which only the devices of the types attached to
+// the NUMA node are free.
+func (n *nodeDevice) withDevicesOnNUMANode(numaNode int32, deviceTypes ...schedulingv1alpha1.DeviceType) *nodeDevice {
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	for _, deviceType := range deviceTypes {
+		deviceNUMANodes := n.getNUMANodesOfDeviceType(deviceType)
+		free := deviceResources{}
+		for minor, resources := range n.deviceFree[deviceType] {
+			if numaNodeOfMinor, ok := deviceNUMANodes[minor]; ok && numaNodeOfMinor == numaNode {
+				free[minor] = resources
+			}
+		}
+		deviceFree[deviceType] = free
+	}
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
+// getDevicePCIeSwitches returns the PCIe switches attached by the devices of all the device types in ascending order.
+// The devices not reporting the PCIe switch are ignored.
+func (n *nodeDevice) getDevicePCIeSwitches(deviceTypes ...schedulingv1alpha1.DeviceType) []string {
+	var pcieSwitches sets.String
+	for _, deviceType := range deviceTypes {
+		devicePCIeSwitches := n.getPCIeSwitchesOfDeviceType(deviceType)
+		typePCIeSwitches := sets.NewString()
+		for minor := range n.deviceTotal[deviceType] {
+			if pcieSwitch, ok := devicePCIeSwitches[minor]; ok {
+				typePCIeSwitches.Insert(pcieSwitch)
+			}
+		}
+		if pcieSwitches == nil {
+			pcieSwitches = typePCIeSwitches
+		} else {
+			pcieSwitches = pcieSwitches.Intersection(typePCIeSwitches)
+		}
+	}
+	return pcieSwitches.List()
+}
+
+// getPCIeSwitchesOfDeviceType returns the PCIe switches of the devices of the type reporting them by minor.
+func (n *nodeDevice) getPCIeSwitchesOfDeviceType(deviceType schedulingv1alpha1.DeviceType) map[int]string {
+	switch deviceType {
+	case schedulingv1alpha1.GPU:
+		return n.gpuPCIeSwitches
+	case schedulingv1alpha1.RDMA:
+		return n.rdmaPCIeSwitches
+	}
+	return nil
+}
+
+// withDevicesOnPCIeSwitch returns the view of the node devices in which only the devices of the types attached to
+// the PCIe switch are free.
+func (n *nodeDevice) withDevicesOnPCIeSwitch(pcieSwitch string, deviceTypes ...schedulingv1alpha1.DeviceType) *nodeDevice {
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	for _, deviceType := range deviceTypes {
+		devicePCIeSwitches := n.getPCIeSwitchesOfDeviceType(deviceType)
+		free := deviceResources{}
+		for minor, resources := range n.deviceFree[deviceType] {
+			if pcieSwitchOfMinor, ok := devicePCIeSwitches[minor]; ok && pcieSwitchOfMinor == pcieSwitch {
+				free[minor] = resources
+			}
+		}
+		deviceFree[deviceType] = free
+	}
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
+
+// countFitDevices returns how many devices at most could be allocated to the request of count devices.
+func (n *nodeDevice) countFitDevices(request corev1.ResourceList, count int64) int64 {
+	for fit := count; fit > 0; fit-- {
+		if _, err := n.tryAllocateDevice(scaleDeviceRequest(request, fit, count), ""); err == nil {
+			return fit
+		}
+	}
+	return 0
+}
+
+// gpuTopologyLevels are the levels of the topology grouping the GPUs of a node, from the closest to the farthest.
+var gpuTopologyLevels = []apiext.DeviceTopologyLevel{
+	apiext.DeviceTopologyLevelNVLink,
+	apiext.DeviceTopologyLevelPCIeSwitch,
+	apiext.DeviceTopologyLevelNUMANode,
+}
+
+// getGPUTopologyGroups returns the group of the GPUs reporting the topology level by minor.
+func (n *nodeDevice) getGPUTopologyGroups(level apiext.DeviceTopologyLevel) map[int]string {
+	switch level {
+	case apiext.DeviceTopologyLevelNVLink:
+		return n.gpuNVLinkGroups
+	case apiext.DeviceTopologyLevelPCIeSwitch:
+		return n.gpuPCIeSwitches
+	case apiext.DeviceTopologyLevelNUMANode:
+		if len(n.gpuNUMANodes) == 0 {
+			return nil
+		}
+		groups := make(map[int]string, len(n.gpuNUMANodes))
+		for minor, numaNode := range n.gpuNUMANodes {
+			groups[minor] = strconv.Itoa(int(numaNode))
+		}
+		return groups
+	}
+	return nil
+}
+
+// groupGPUsByTopology groups the GPUs by the topology groups, with the larger groups first and the groups of the
+// same size ordered by their lowest minor. It returns false if any GPU does not report the topology level.
+func groupGPUsByTopology(minors []int, groups map[int]string) ([][]int, bool) {
+	groupGPUs := map[string][]int{}
+	for _, minor := range minors {
+		group, ok := groups[minor]
+		if !ok {
+			return nil, false
+		}
+		groupGPUs[group] = append(groupGPUs[group], minor)
+	}
+	grouped := make([][]int, 0, len(groupGPUs))
+	for _, gpus := range groupGPUs {
+		sort.Ints(gpus)
+		grouped = append(grouped, gpus)
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		if len(grouped[i]) != len(grouped[j]) {
+			return len(grouped[i]) > len(grouped[j])
+		}
+		return grouped[i][0] < grouped[j][0]
+	})
+	return grouped, true
+}
+
+// selectGPUsByTopology selects the wanted count of GPUs from the candidates in the same NVLink group, then on the
+// same PCIe switch, then on the same NUMA node, preferring the smallest group holding all the wanted GPUs to leave
+// the larger ones to the larger requests, and the GPUs in the group are selected by the farther levels likewise.
+// Otherwise, the GPUs spread over the fewest groups of the closest level reported by all the candidates.
+// The topology is strictly a preference: it never rejects the candidates, and the GPUs are selected by minor
+// if any candidate does not report the topology.
+func (n *nodeDevice) selectGPUsByTopology(candidates []int, wanted int) []int {
+	return n.selectGPUsByTopologyLevels(candidates, wanted, gpuTopologyLevels)
+}
+
+func (n *nodeDevice) selectGPUsByTopologyLevels(candidates []int, wanted int, levels []apiext.DeviceTopologyLevel) []int {
+	if len(candidates) <= wanted {
+		return candidates
+	}
+	var spreadGroups [][]int
+	for i, level := range levels {
+		grouped, ok := groupGPUsByTopology(candidates, n.getGPUTopologyGroups(level))
+		if !ok {
+			continue
+		}
+		var fittest []int
+		for _, gpus := range grouped {
+			if len(gpus) >= wanted && (fittest == nil || len(gpus) < len(fittest)) {
+				fittest = gpus
+			}
+		}
+		if fittest != nil {
+			return n.selectGPUsByTopologyLevels(fittest, wanted, levels[i+1:])
+		}
+		if spreadGroups == nil {
+			spreadGroups = grouped
+		}
+	}
+	if spreadGroups == nil {
+		return candidates[:wanted]
+	}
+
+	selected := make([]int, 0, wanted)
+	for _, gpus := range spreadGroups {
+		if remaining := wanted - len(selected); len(gpus) > remaining {
+			gpus = gpus[:remaining]
+		}
+		selected = append(selected, gpus...)
+		if len(selected) == wanted {
+			break
+		}
+	}
+	sort.Ints(selected)
+	return selected
+}
+
+// countFewestGPUTopologyGroups returns the fewest groups of the GPUs of the node which could hold the count of GPUs.
+func (n *nodeDevice) countFewestGPUTopologyGroups(groups map[int]string, count int) int {
+	groupGPUCount := map[string]int{}
+	for minor, resources := range n.deviceTotal[schedulingv1alpha1.GPU] {
+		if group, ok := groups[minor]; ok && len(resources) > 0 {
+			groupGPUCount[group]++
+		}
+	}
+	counts := make([]int, 0, len(groupGPUCount))
+	for _, groupCount := range groupGPUCount {
+		counts = append(counts, groupCount)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	fewest, held := 0, 0
+	for _, groupCount := range counts {
+		if held >= count {
+			break
+		}
+		held += groupCount
+		fewest++
+	}
+	return fewest
+}
+
+// getGPUTopologyLevel returns the closest topology level connecting all the GPUs, Node if the GPUs report the
+// topology but span the groups of all the levels, and empty if a single GPU is given or no level is reported.
+func (n *nodeDevice) getGPUTopologyLevel(minors []int) apiext.DeviceTopologyLevel {
+	if len(minors) <= 1 {
+		return ""
+	}
+	var level apiext.DeviceTopologyLevel
+	for _, topologyLevel := range gpuTopologyLevels {
+		grouped, ok := groupGPUsByTopology(minors, n.getGPUTopologyGroups(topologyLevel))
+		if !ok {
+			continue
+		}
+		if len(grouped) == 1 {
+			return topologyLevel
+		}
+		level = apiext.DeviceTopologyLevelNode
+	}
+	return level
+}
+
+// isGPUTopologyAligned checks whether the allocated GPUs span no more groups than the fewest groups of the node
+// holding as many GPUs, at the closest topology level reported by all the allocated GPUs. The allocation of a
+// single GPU or of the GPUs reporting no topology is always aligned.
+func (n *nodeDevice) isGPUTopologyAligned(allocations apiext.DeviceAllocations) bool {
+	gpuAllocations := allocations[schedulingv1alpha1.GPU]
+	if len(gpuAllocations) <= 1 {
+		return true
+	}
+	minors := make([]int, 0, len(gpuAllocations))
+	for _, allocation := range gpuAllocations {
+		minors = append(minors, int(allocation.Minor))
+	}
+	for _, level := range gpuTopologyLevels {
+		groups := n.getGPUTopologyGroups(level)
+		grouped, ok := groupGPUsByTopology(minors, groups)
+		if !ok {
+			continue
+		}
+		return len(grouped) <= n.countFewestGPUTopologyGroups(groups, len(minors))
+	}
+	return true
+}
+
+// getAllocatedTopology returns the topology of the allocated GPUs, nil if any of them does not report the topology.
+// The allocation is degraded if the GPUs span more NUMA nodes than the fewest NUMA nodes holding as many GPUs.
+func (n *nodeDevice) getAllocatedTopology(allocations apiext.DeviceAllocations) apiext.DeviceAllocatedTopology {
+	gpuAllocations := allocations[schedulingv1alpha1.GPU]
+	if len(gpuAllocations) == 0 || len(n.gpuNUMANodes) == 0 {
+		return nil
+	}
+	minors := make([]int, 0, len(gpuAllocations))
+	allocatedNUMANodes := sets.NewInt32()
+	for _, allocation := range gpuAllocations {
+		numaNode, ok := n.gpuNUMANodes[int(allocation.Minor)]
+		if !ok {
+			return nil
+		}
+		minors = append(minors, int(allocation.Minor))
+		allocatedNUMANodes.Insert(numaNode)
+	}
+	fewestNUMANodes := n.countFewestGPUTopologyGroups(n.getGPUTopologyGroups(apiext.DeviceTopologyLevelNUMANode), len(minors))
+
+	return apiext.DeviceAllocatedTopology{
+		schedulingv1alpha1.GPU: &apiext.DeviceTopologyResult{
+			NUMANodes: allocatedNUMANodes.List(),
+			Degraded:  allocatedNUMANodes.Len() > fewestNUMANodes,
+			Level:     n.getGPUTopologyLevel(minors),
+		},
+	}
+}
+
+// getAllocatedNUMANodes returns the NUMA nodes the allocated GPUs and RDMA NICs attached to,
+// empty if none of them reports the topology.
+func (n *nodeDevice) getAllocatedNUMANodes(allocations apiext.DeviceAllocations) []int {
+	numaNodes := sets.NewInt()
+	for deviceType, deviceNUMANodes := range map[schedulingv1alpha1.DeviceType]map[int]int32{
+		schedulingv1alpha1.GPU:  n.gpuNUMANodes,
+		schedulingv1alpha1.RDMA: n.rdmaNUMANodes,
+	} {
+		for _, allocation := range allocations[deviceType] {
+			if numaNode, ok := deviceNUMANodes[int(allocation.Minor)]; ok {
+				numaNodes.Insert(int(numaNode))
+			}
+		}
+	}
+	return numaNodes.List()
+}
+
+// tryAllocateGPUByUUID places the pod exactly on the GPU with the given UUID.
+func (n *nodeDevice) tryAllocateGPUByUUID(podRequest corev1.ResourceList, uuid string, allocateResult apiext.DeviceAllocations) error {
+	podRequest = quotav1.Mask(podRequest, DeviceResourceNames[schedulingv1alpha1.GPU])
+	minor, ok := n.deviceUUIDs[schedulingv1alpha1.GPU][uuid]
+	if !ok {
+		return fmt.Errorf("node does not have GPU %s", uuid)
+	}
+	if isMultipleGPUPod(podRequest) {
+		return fmt.Errorf("multiple GPUs cannot be placed on GPU %s", uuid)
+	}
+
+	if isGPUMemoryOnlyRequest(podRequest) {
+		if podRequest, ok = deriveGPUMemoryOnlyRequest(podRequest, n.getPhysicalTotal(schedulingv1alpha1.GPU)[minor], n.gpuCoreGranularity); !ok {
+			return fmt.Errorf("GPU %s does not report its memory", uuid)
+		}
+	} else {
+		fillGPUTotalMem(n.getPhysicalTotal(schedulingv1alpha1.GPU), podRequest)
+	}
+
+	free, ok := n.deviceFree[schedulingv1alpha1.GPU][minor]
+	if !ok {
+		return fmt.Errorf("GPU %s is not available", uuid)
+	}
+	if satisfied, _ := quotav1.LessThanOrEqual(podRequest, free); !satisfied || !n.fitsGPUMemoryCapacity(minor, podRequest) {
+		klog.V(5).Infof("node GPU %v resource does not satisfy pod's request", uuid)
+		return fmt.Errorf("GPU %s is not available", uuid)
+	}
+	allocateResult[schedulingv1alpha1.GPU] = []*apiext.DeviceAllocation{
+		{
+			Minor:     int32(minor),
+			Resources: podRequest,
+		},
+	}
+	return nil
+}
+
+// fitsGPUMemoryCapacity keeps the invariant that the sum of the charged GPU memory on a card never exceeds the
+// physical capacity of the card. The requests are rounded separately when converting between gpu-memory and
+// gpu-memory-ratio, so the invariant is checked against the accumulated usage rather than the clamped free resources.
+func (n *nodeDevice) fitsGPUMemoryCapacity(minor int, podRequest corev1.ResourceList) bool {
+	total := n.deviceTotal[schedulingv1alpha1.GPU][minor]
+	used := n.deviceUsed[schedulingv1alpha1.GPU][minor]
+	for _, resourceName := range []corev1.ResourceName{apiext.GPUMemory, apiext.GPUMemoryRatio} {
+		capacity, ok := total[resourceName]
+		if !ok {
+			continue
+		}
+		charged := used[resourceName].DeepCopy()
+		charged.Add(podRequest[resourceName])
+		if charged.Cmp(capacity) > 0 {
+			klog.V(5).Infof("GPU %d would be over-allocated, %v capacity: %v, charged: %v",
+				minor, resourceName, capacity.String(), charged.String())
+			return false
+		}
+	}
+	return true
+}
+
+// getExhaustedGPUResource returns the resource of the gpu-core and gpu-memory-ratio request which the free GPUs
+// can't satisfy while the other can, so that the node rejecting the request tells which of the two is exhausted.
+func (n *nodeDevice) getExhaustedGPUResource(podRequest corev1.ResourceList) (corev1.ResourceName, bool) {
+	gpuWanted := int64(1)
+	if isMultipleGPUPod(podRequest) {
+		gpuCore := podRequest[apiext.GPUCore]
+		gpuWanted = gpuCore.Value() / 100
+	}
+	var exhausted []corev1.ResourceName
+	for _, resourceName := range []corev1.ResourceName{apiext.GPUCore, apiext.GPUMemoryRatio} {
+		request := podRequest[resourceName]
+		satisfied := int64(0)
+		for _, free := range n.deviceFree[schedulingv1alpha1.GPU] {
+			if quantity, ok := free[resourceName]; ok && quantity.Value() >= request.Value()/gpuWanted {
+				satisfied++
+			}
+		}
+		if satisfied < gpuWanted {
+			exhausted = append(exhausted, resourceName)
+		}
+	}
+	if len(exhausted) != 1 {
+		return "", false
+	}
+	return exhausted[0], true
+}
+
+const (
+	// reserveStatisticsWindow is the rolling window of the reserve results surfaced in the NodeDeviceSummary.
+	reserveStatisticsWindow = 10 * time.Minute
+	// reserveStatisticsBuckets is the number of buckets dividing the window.
+	reserveStatisticsBuckets = 10
+)
+
+type reserveResultBucket struct {
+	start     time.Time
+	succeeded int64
+	failed    int64
+}
+
+// reserveStatistics counts the reserve results in a rolling window. The window is divided into fixed-size buckets
+// which are reused in a ring, so that the memory is bounded no matter how many pods are reserved.
+type reserveStatistics struct {
+	buckets []reserveResultBucket
+}
+
+func (s *reserveStatistics) record(now time.Time, succeeded bool) {
+	bucketDuration := reserveStatisticsWindow / reserveStatisticsBuckets
+	if s.buckets == nil {
+		s.buckets = make([]reserveResultBucket, reserveStatisticsBuckets)
+	}
+	start := now.Truncate(bucketDuration)
+	bucket := &s.buckets[int(start.UnixNano()/int64(bucketDuration))%reserveStatisticsBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = reserveResultBucket{start: start}
+	}
+	if succeeded {
+		bucket.succeeded++
+	} else {
+		bucket.failed++
+	}
+}
+
+func (s *reserveStatistics) count(now time.Time) (succeeded, failed int64) {
+	for _, bucket := range s.buckets {
+		if bucket.start.IsZero() || now.Sub(bucket.start) >= reserveStatisticsWindow {
+			continue
+		}
+		succeeded += bucket.succeeded
+		failed += bucket.failed
+	}
+	return succeeded, failed
+}
+
 type nodeDeviceCache struct {
 	lock sync.RWMutex
 	// nodeDeviceInfos stores nodeDevice for each node
 	// and uses node name as map key.
 	nodeDeviceInfos map[string]*nodeDevice
+	// releaseTerminatedPods releases the devices of the Succeeded or Failed pods before they are deleted.
+	releaseTerminatedPods bool
+	// allocationCooldowns is how long the resources released by a pod are not allocated again, by device type.
+	allocationCooldowns map[schedulingv1alpha1.DeviceType]time.Duration
+	// reconcileStrategy is how the node devices reconcile the allocations the Device can't account for.
+	reconcileStrategy config.DeviceReconcileStrategy
+	// gpuCoreGranularity is the granularity of the gpu-core share derived for the gpu-memory-only requests.
+	gpuCoreGranularity int64
+	// overcommitRatios multiplies the capacity of the devices by device type for the pods overcommitting them.
+	overcommitRatios map[schedulingv1alpha1.DeviceType]float64
+	// resourceNames is the resource names of the built-in device types and the custom ones registered by the args,
+	// nil for the built-in ones only.
+	resourceNames deviceResourceNames
+	clock         clock.Clock
+	// terminatingPods resyncs the terminating pods by the namespaced name once their deletion grace period expires,
+	// nil if the terminated pods are not released.
+	terminatingPods workqueue.DelayingInterface
+	podLister       listercorev1.PodLister
 }
 
 func newNodeDeviceCache() *nodeDeviceCache {
 	return &nodeDeviceCache{
 		nodeDeviceInfos: make(map[string]*nodeDevice),
+		clock:           clock.RealClock{},
+	}
+}
+
+// getResourceNames returns the resource names of the device types supported by the plugin instance.
+func (n *nodeDeviceCache) getResourceNames() deviceResourceNames {
+	if n == nil || n.resourceNames == nil {
+		return DeviceResourceNames
 	}
+	return n.resourceNames
 }
 
 func (n *nodeDeviceCache) getNodeDevice(nodeName string) *nodeDevice {
@@ -404,8 +1652,13 @@ func (n *nodeDeviceCache) getNodeDevice(nodeName string) *nodeDevice {
 func (n *nodeDeviceCache) createNodeDevice(nodeName string) *nodeDevice {
 	n.lock.Lock()
 	defer n.lock.Unlock()
-	n.nodeDeviceInfos[nodeName] = newNodeDevice()
-	return n.nodeDeviceInfos[nodeName]
+	info := newNodeDevice()
+	info.reconcileStrategy = n.reconcileStrategy
+	info.gpuCoreGranularity = n.gpuCoreGranularity
+	info.overcommitRatios = n.overcommitRatios
+	info.resourceNames = n.resourceNames
+	n.nodeDeviceInfos[nodeName] = info
+	return info
 }
 
 func (n *nodeDeviceCache) removeNodeDevice(nodeName string) {
@@ -431,14 +1684,135 @@ func (n *nodeDeviceCache) updateNodeDevice(nodeName string, device *schedulingv1
 	defer info.lock.Unlock()
 
 	nodeDeviceResource := map[schedulingv1alpha1.DeviceType]deviceResources{}
+	deviceUUIDs := map[schedulingv1alpha1.DeviceType]map[string]int{}
+	var gpuComputeCapabilities map[int]apiext.GPUComputeCapability
+	var gpuModels map[int]string
+	var migPartitions map[int][]schedulingv1alpha1.MIGPartition
+	var rdmaVFs map[int][]schedulingv1alpha1.VirtualFunction
+	var gpuNUMANodes, rdmaNUMANodes map[int]int32
+	var gpuPCIeSwitches, gpuNVLinkGroups, rdmaPCIeSwitches map[int]string
+	var unhealthyDevices map[schedulingv1alpha1.DeviceType]sets.Int
 	for _, deviceInfo := range device.Spec.Devices {
+		if deviceInfo.Type == schedulingv1alpha1.GPU && deviceInfo.Topology != nil {
+			if gpuNUMANodes == nil {
+				gpuNUMANodes = make(map[int]int32)
+			}
+			gpuNUMANodes[int(*deviceInfo.Minor)] = deviceInfo.Topology.NodeID
+			if deviceInfo.Topology.PCIESwitchID != "" {
+				if gpuPCIeSwitches == nil {
+					gpuPCIeSwitches = make(map[int]string)
+				}
+				gpuPCIeSwitches[int(*deviceInfo.Minor)] = deviceInfo.Topology.PCIESwitchID
+			}
+			if deviceInfo.Topology.NVLinkGroupID != "" {
+				if gpuNVLinkGroups == nil {
+					gpuNVLinkGroups = make(map[int]string)
+				}
+				gpuNVLinkGroups[int(*deviceInfo.Minor)] = deviceInfo.Topology.NVLinkGroupID
+			}
+		}
+		if deviceInfo.Type == schedulingv1alpha1.RDMA && deviceInfo.Topology != nil {
+			if rdmaNUMANodes == nil {
+				rdmaNUMANodes = make(map[int]int32)
+			}
+			rdmaNUMANodes[int(*deviceInfo.Minor)] = deviceInfo.Topology.NodeID
+			if deviceInfo.Topology.PCIESwitchID != "" {
+				if rdmaPCIeSwitches == nil {
+					rdmaPCIeSwitches = make(map[int]string)
+				}
+				rdmaPCIeSwitches[int(*deviceInfo.Minor)] = deviceInfo.Topology.PCIESwitchID
+			}
+		}
+		if deviceInfo.Type == schedulingv1alpha1.GPU && deviceInfo.ComputeCapability != "" {
+			if capability, err := apiext.ParseGPUComputeCapability(deviceInfo.ComputeCapability); err != nil {
+				klog.Errorf("Find device compute capability invalid, nodeName:%v, minor:%v, err:%v",
+					nodeName, deviceInfo.Minor, err)
+			} else {
+				if gpuComputeCapabilities == nil {
+					gpuComputeCapabilities = make(map[int]apiext.GPUComputeCapability)
+				}
+				gpuComputeCapabilities[int(*deviceInfo.Minor)] = capability
+			}
+		}
+		if deviceInfo.Type == schedulingv1alpha1.GPU {
+			// the model of the node is reported only if all the GPUs are of the same model
+			model := deviceInfo.Model
+			if model == "" {
+				model = device.Labels[apiext.GPUModel]
+			}
+			if model != "" {
+				if gpuModels == nil {
+					gpuModels = make(map[int]string)
+				}
+				gpuModels[int(*deviceInfo.Minor)] = model
+			}
+		}
 		if nodeDeviceResource[deviceInfo.Type] == nil {
 			nodeDeviceResource[deviceInfo.Type] = make(deviceResources)
 		}
+		if deviceInfo.UUID != "" {
+			if deviceUUIDs[deviceInfo.Type] == nil {
+				deviceUUIDs[deviceInfo.Type] = make(map[string]int)
+			}
+			deviceUUIDs[deviceInfo.Type][deviceInfo.UUID] = int(*deviceInfo.Minor)
+		}
+		if deviceInfo.Health && deviceInfo.Unschedulable {
+			// the unschedulable device keeps its resources, so that the allocations on it are accounted as usual
+			if unhealthyDevices == nil {
+				unhealthyDevices = make(map[schedulingv1alpha1.DeviceType]sets.Int)
+			}
+			if unhealthyDevices[deviceInfo.Type] == nil {
+				unhealthyDevices[deviceInfo.Type] = sets.NewInt()
+			}
+			unhealthyDevices[deviceInfo.Type].Insert(int(*deviceInfo.Minor))
+			klog.V(4).Infof("Find device unschedulable, nodeName:%v, deviceType:%v, minor:%v",
+				nodeName, deviceInfo.Type, *deviceInfo.Minor)
+		}
 		if !deviceInfo.Health {
 			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = make(corev1.ResourceList)
+			if unhealthyDevices == nil {
+				unhealthyDevices = make(map[schedulingv1alpha1.DeviceType]sets.Int)
+			}
+			if unhealthyDevices[deviceInfo.Type] == nil {
+				unhealthyDevices[deviceInfo.Type] = sets.NewInt()
+			}
+			unhealthyDevices[deviceInfo.Type].Insert(int(*deviceInfo.Minor))
 			klog.Errorf("Find device unhealthy, nodeName:%v, deviceType:%v, minor:%v",
-				nodeName, deviceInfo.Type, deviceInfo.Minor)
+				nodeName, deviceInfo.Type, *deviceInfo.Minor)
+			if !info.unhealthyDevices[deviceInfo.Type].Has(int(*deviceInfo.Minor)) && !quotav1.IsZero(info.deviceUsed[deviceInfo.Type][int(*deviceInfo.Minor)]) {
+				klog.Warningf("Device turns unhealthy with pods allocated, the allocations stay accounted but the device is never allocated any more, nodeName:%v, deviceType:%v, minor:%v",
+					nodeName, deviceInfo.Type, *deviceInfo.Minor)
+			}
+		} else if deviceInfo.Type == schedulingv1alpha1.RDMA && len(deviceInfo.VirtualFunctions) > 0 {
+			// the RDMA NIC exposing the virtual functions is allocated only by the virtual functions
+			if rdmaVFs == nil {
+				rdmaVFs = make(map[int][]schedulingv1alpha1.VirtualFunction)
+			}
+			rdmaVFs[int(*deviceInfo.Minor)] = sortVirtualFunctions(deviceInfo.VirtualFunctions)
+			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = getVirtualFunctionResources(len(deviceInfo.VirtualFunctions))
+			klog.V(5).Infof("Find RDMA device resource update, nodeName:%v, minor:%v, virtualFunctions:%v",
+				nodeName, deviceInfo.Minor, deviceInfo.VirtualFunctions)
+		} else if deviceInfo.Type == schedulingv1alpha1.GPU && len(deviceInfo.MIGPartitions) > 0 {
+			// the MIG instances are counted from the partitions which are allocated by identity
+			if migPartitions == nil {
+				migPartitions = make(map[int][]schedulingv1alpha1.MIGPartition)
+			}
+			migPartitions[int(*deviceInfo.Minor)] = sortMIGPartitions(deviceInfo.MIGPartitions)
+			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = getMIGResources(countMIGPartitions(deviceInfo.MIGPartitions))
+			klog.V(5).Infof("Find MIG device resource update, nodeName:%v, minor:%v, partitions:%v",
+				nodeName, deviceInfo.Minor, deviceInfo.MIGPartitions)
+		} else if deviceInfo.Type == schedulingv1alpha1.GPU && deviceInfo.SharedSlots > 0 {
+			// the GPU shared by the time-slicing slots is allocated only by the slots
+			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = corev1.ResourceList{
+				apiext.GPUSharedSlots: *resource.NewQuantity(int64(deviceInfo.SharedSlots), resource.DecimalSI),
+			}
+			klog.V(5).Infof("Find shared GPU resource update, nodeName:%v, minor:%v, sharedSlots:%v",
+				nodeName, deviceInfo.Minor, deviceInfo.SharedSlots)
+		} else if deviceInfo.Type == schedulingv1alpha1.GPU && len(deviceInfo.MIGInstances) > 0 {
+			// the GPU carved into the MIG instances is allocated only by the instances
+			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = getMIGResources(deviceInfo.MIGInstances)
+			klog.V(5).Infof("Find MIG device resource update, nodeName:%v, minor:%v, instances:%v",
+				nodeName, deviceInfo.Minor, deviceInfo.MIGInstances)
 		} else {
 			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = deviceInfo.Resources
 			klog.V(5).Infof("Find device resource update, nodeName:%v, deviceType:%v, minor:%v, res:%v",
@@ -446,7 +1820,18 @@ func (n *nodeDeviceCache) updateNodeDevice(nodeName string, device *schedulingv1
 		}
 	}
 
+	info.unhealthyDevices = unhealthyDevices
 	info.resetDeviceTotal(nodeDeviceResource)
+	info.deviceUUIDs = deviceUUIDs
+	info.gpuComputeCapabilities = gpuComputeCapabilities
+	info.gpuModels = gpuModels
+	info.migPartitions = migPartitions
+	info.gpuNUMANodes = gpuNUMANodes
+	info.gpuPCIeSwitches = gpuPCIeSwitches
+	info.gpuNVLinkGroups = gpuNVLinkGroups
+	info.rdmaNUMANodes = rdmaNUMANodes
+	info.rdmaPCIeSwitches = rdmaPCIeSwitches
+	info.rdmaVFs = rdmaVFs
 }
 
 func (n *nodeDeviceCache) getNodeDeviceSummary(nodeName string) (*NodeDeviceSummary, bool) {
@@ -461,6 +1846,37 @@ func (n *nodeDeviceCache) getNodeDeviceSummary(nodeName string) (*NodeDeviceSumm
 	return nodeDeviceSummary, true
 }
 
+// getLargestSchedulableGPU returns the largest GPU request fitting on the node, excluding the cooling GPUs.
+func (n *nodeDeviceCache) getLargestSchedulableGPU(nodeName string) (*NodeLargestSchedulableGPU, bool) {
+	info := n.getNodeDevice(nodeName)
+	if info == nil {
+		return nil, false
+	}
+	info.lock.RLock()
+	defer info.lock.RUnlock()
+	return n.withoutCoolingDevices(info).getLargestSchedulableGPU(), true
+}
+
+// getAllLargestSchedulableGPU returns the largest GPU request fitting on each node having GPUs.
+func (n *nodeDeviceCache) getAllLargestSchedulableGPU() map[string]*NodeLargestSchedulableGPU {
+	n.lock.RLock()
+	infos := make(map[string]*nodeDevice, len(n.nodeDeviceInfos))
+	for nodeName, info := range n.nodeDeviceInfos {
+		infos[nodeName] = info
+	}
+	n.lock.RUnlock()
+
+	results := make(map[string]*NodeLargestSchedulableGPU, len(infos))
+	for nodeName, info := range infos {
+		info.lock.RLock()
+		if len(info.deviceTotal[schedulingv1alpha1.GPU]) > 0 {
+			results[nodeName] = n.withoutCoolingDevices(info).getLargestSchedulableGPU()
+		}
+		info.lock.RUnlock()
+	}
+	return results
+}
+
 func (n *nodeDeviceCache) getAllNodeDeviceSummary() map[string]*NodeDeviceSummary {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
@@ -17,18 +17,30 @@ limitations under the License.
 package deviceshare
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/utils/pointer"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
 func Test_newNodeDeviceCache(t *testing.T) {
 	expectNodeDeviceCache := &nodeDeviceCache{
 		nodeDeviceInfos: map[string]*nodeDevice{},
+		clock:           clock.RealClock{},
 	}
 	assert.Equal(t, expectNodeDeviceCache, newNodeDeviceCache())
 }
@@ -39,6 +51,1609 @@ func Test_newNodeDevice(t *testing.T) {
 		deviceFree:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 		deviceUsed:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 		allocateSet: map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]v1.ResourceList{},
+		deviceUUIDs: map[schedulingv1alpha1.DeviceType]map[string]int{},
 	}
 	assert.Equal(t, expectNodeDevice, newNodeDevice())
 }
+
+func Test_nodeDevice_tryAllocateGPU_RoundedFractionalRequests(t *testing.T) {
+	nd := newNodeDevice()
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: {
+			0: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+			},
+		},
+	})
+	allocator := &defaultAllocator{}
+
+	// each request of 251 bytes is rounded down to 25% of the card, so the ratio alone admits 4 pods,
+	// but the 4th one must be rejected since the charged memory would exceed the physical memory.
+	for i := 0; i < 4; i++ {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)},
+		}
+		podRequest := v1.ResourceList{
+			apiext.GPUCore:   *resource.NewQuantity(10, resource.DecimalSI),
+			apiext.GPUMemory: *resource.NewQuantity(251, resource.BinarySI),
+		}
+		allocations, err := allocator.Allocate("test-node", pod, podRequest, nd)
+		if i < 3 {
+			assert.NoError(t, err, pod.Name)
+			allocator.Reserve(pod, nd, allocations)
+			continue
+		}
+		assert.Error(t, err)
+		assert.Nil(t, allocations)
+	}
+	used := nd.deviceUsed[schedulingv1alpha1.GPU][0][apiext.GPUMemory]
+	assert.Equal(t, int64(753), used.Value())
+}
+
+func Test_nodeDevice_tryAllocateGPU_MemoryOnlyRequests(t *testing.T) {
+	nd := newNodeDevice()
+	nd.gpuCoreGranularity = 5
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: {
+			0: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse("24Gi"),
+			},
+			1: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse("80Gi"),
+			},
+		},
+	})
+	allocator := &defaultAllocator{}
+
+	// the same 12Gi request takes a half of the 24Gi GPU, and 15% of the 80Gi GPU once the 24Gi GPU is full
+	wantAllocations := []struct {
+		minor   int32
+		gpuCore int64
+	}{
+		{minor: 0, gpuCore: 50},
+		{minor: 0, gpuCore: 50},
+		{minor: 1, gpuCore: 15},
+	}
+	for i, want := range wantAllocations {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)},
+		}
+		podRequest := v1.ResourceList{
+			apiext.GPUMemory: resource.MustParse("12Gi"),
+		}
+		allocations, err := allocator.Allocate("test-node", pod, podRequest, nd)
+		assert.NoError(t, err, pod.Name)
+		assert.Len(t, allocations[schedulingv1alpha1.GPU], 1)
+		allocation := allocations[schedulingv1alpha1.GPU][0]
+		assert.Equal(t, want.minor, allocation.Minor, pod.Name)
+		gpuCore, gpuMemory := allocation.Resources[apiext.GPUCore], allocation.Resources[apiext.GPUMemory]
+		assert.Equal(t, want.gpuCore, gpuCore.Value(), pod.Name)
+		assert.Equal(t, int64(12*1024*1024*1024), gpuMemory.Value(), pod.Name)
+		allocator.Reserve(pod, nd, allocations)
+	}
+	used := nd.deviceUsed[schedulingv1alpha1.GPU][1][apiext.GPUCore]
+	assert.Equal(t, int64(15), used.Value())
+}
+
+func Test_nodeDevice_tryAllocateGPU_MIGRequests(t *testing.T) {
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Type:   schedulingv1alpha1.GPU,
+					Minor:  pointer.Int32(0),
+					Health: true,
+					Resources: v1.ResourceList{
+						apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.GPUMemory:      resource.MustParse("80Gi"),
+					},
+				},
+				{
+					Type:         schedulingv1alpha1.GPU,
+					Minor:        pointer.Int32(1),
+					Health:       true,
+					MIGInstances: map[string]int32{"1g.10gb": 2},
+				},
+				{
+					Type:         schedulingv1alpha1.GPU,
+					Minor:        pointer.Int32(2),
+					Health:       true,
+					MIGInstances: map[string]int32{"1g.10gb": 3},
+				},
+			},
+		},
+	})
+	nd := cache.getNodeDevice("test-node")
+	assert.Equal(t, v1.ResourceList{
+		apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(2, resource.DecimalSI),
+	}, nd.deviceTotal[schedulingv1alpha1.GPU][1])
+	allocator := &defaultAllocator{}
+
+	tests := []struct {
+		name            string
+		podRequest      v1.ResourceList
+		wantAllocations []*apiext.DeviceAllocation
+		wantErr         bool
+	}{
+		{
+			name: "allocate the MIG instances from a single GPU",
+			podRequest: v1.ResourceList{
+				apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(2, resource.DecimalSI),
+			},
+			wantAllocations: []*apiext.DeviceAllocation{
+				{
+					Minor: 1,
+					Resources: v1.ResourceList{
+						apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(2, resource.DecimalSI),
+					},
+				},
+			},
+		},
+		{
+			name: "allocate the MIG instances across the GPUs",
+			podRequest: v1.ResourceList{
+				apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(4, resource.DecimalSI),
+			},
+			wantAllocations: []*apiext.DeviceAllocation{
+				{
+					Minor: 1,
+					Resources: v1.ResourceList{
+						apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(1, resource.DecimalSI),
+					},
+				},
+				{
+					Minor: 2,
+					Resources: v1.ResourceList{
+						apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(3, resource.DecimalSI),
+					},
+				},
+			},
+		},
+		{
+			name: "the full GPU never serves a MIG profile",
+			podRequest: v1.ResourceList{
+				apiext.NvidiaMIGResourceName("2g.20gb"): *resource.NewQuantity(1, resource.DecimalSI),
+			},
+			wantErr: true,
+		},
+		{
+			name: "not enough MIG instances",
+			podRequest: v1.ResourceList{
+				apiext.NvidiaMIGResourceName("1g.10gb"): *resource.NewQuantity(6, resource.DecimalSI),
+			},
+			wantErr: true,
+		},
+		{
+			name: "the MIG GPUs never serve a full GPU",
+			podRequest: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(200, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(200, resource.DecimalSI),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"},
+			}
+			allocations, err := allocator.Allocate("test-node", pod, tt.podRequest, nd)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, apiext.DeviceAllocations{schedulingv1alpha1.GPU: tt.wantAllocations}, allocations)
+		})
+	}
+}
+
+func Test_nodeDevice_tryAllocateGPU_MIGPartitions(t *testing.T) {
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Type:   schedulingv1alpha1.GPU,
+					Minor:  pointer.Int32(0),
+					Health: true,
+					// the partitions win over the MIG instances
+					MIGInstances: map[string]int32{"1g.5gb": 7},
+					MIGPartitions: []schedulingv1alpha1.MIGPartition{
+						{Profile: "3g.20gb", GPUInstanceID: 1, ComputeInstanceID: 0},
+						{Profile: "1g.5gb", GPUInstanceID: 8, ComputeInstanceID: 0},
+						{Profile: "1g.5gb", GPUInstanceID: 7, ComputeInstanceID: 0},
+					},
+				},
+			},
+		},
+	})
+	nd := cache.getNodeDevice("test-node")
+	assert.Equal(t, v1.ResourceList{
+		apiext.NvidiaMIGResourceName("1g.5gb"):  *resource.NewQuantity(2, resource.DecimalSI),
+		apiext.NvidiaMIGResourceName("3g.20gb"): *resource.NewQuantity(1, resource.DecimalSI),
+	}, nd.deviceTotal[schedulingv1alpha1.GPU][0])
+
+	allocator := &defaultAllocator{}
+	request := func(profile string) v1.ResourceList {
+		return v1.ResourceList{apiext.NvidiaMIGResourceName(profile): *resource.NewQuantity(1, resource.DecimalSI)}
+	}
+	allocated := func(profile string, gpuInstanceID int32) apiext.DeviceAllocations {
+		return apiext.DeviceAllocations{
+			schedulingv1alpha1.GPU: {
+				{
+					Minor:     0,
+					Resources: request(profile),
+					MIGPartitions: []schedulingv1alpha1.MIGPartition{
+						{Profile: profile, GPUInstanceID: gpuInstanceID, ComputeInstanceID: 0},
+					},
+				},
+			},
+		}
+	}
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+
+	pod1 := newPod("pod-1")
+	allocations, err := allocator.Allocate("test-node", pod1, request("1g.5gb"), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated("1g.5gb", 7), allocations)
+	nd.updateCacheUsed(allocations, pod1, true)
+
+	pod2 := newPod("pod-2")
+	allocations, err = allocator.Allocate("test-node", pod2, request("1g.5gb"), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated("1g.5gb", 8), allocations)
+	nd.updateCacheUsed(allocations, pod2, true)
+
+	allocations, err = allocator.Allocate("test-node", newPod("pod-3"), request("3g.20gb"), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated("3g.20gb", 1), allocations)
+
+	_, err = allocator.Allocate("test-node", newPod("pod-3"), request("1g.5gb"), nd)
+	assert.Error(t, err)
+
+	// the partitions of the pods removed in the simulation are free
+	delta := newNodeDeviceDelta()
+	delta.removed[types.NamespacedName{Namespace: "default", Name: "pod-1"}] = allocated("1g.5gb", 7)
+	allocations, err = allocator.Allocate("test-node", newPod("pod-3"), request("1g.5gb"), nd.withDelta(delta))
+	assert.NoError(t, err)
+	assert.Equal(t, allocated("1g.5gb", 7), allocations)
+
+	// the released partitions are free again
+	nd.updateCacheUsed(allocated("1g.5gb", 7), pod1, false)
+	allocations, err = allocator.Allocate("test-node", newPod("pod-3"), request("1g.5gb"), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated("1g.5gb", 7), allocations)
+}
+
+func Test_nodeDevice_tryAllocateGPU_SharedSlots(t *testing.T) {
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Type:        schedulingv1alpha1.GPU,
+					Minor:       pointer.Int32(0),
+					Health:      true,
+					SharedSlots: 2,
+				},
+				{
+					Type:   schedulingv1alpha1.GPU,
+					Minor:  pointer.Int32(1),
+					Health: true,
+					Resources: v1.ResourceList{
+						apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+					},
+				},
+			},
+		},
+	})
+	nd := cache.getNodeDevice("test-node")
+	assert.Equal(t, v1.ResourceList{
+		apiext.GPUSharedSlots: *resource.NewQuantity(2, resource.DecimalSI),
+	}, nd.deviceTotal[schedulingv1alpha1.GPU][0])
+
+	allocator := &defaultAllocator{}
+	request := v1.ResourceList{apiext.GPUSharedSlots: *resource.NewQuantity(1, resource.DecimalSI)}
+	allocated := func(slot int32) apiext.DeviceAllocations {
+		return apiext.DeviceAllocations{
+			schedulingv1alpha1.GPU: {
+				{
+					Minor:       0,
+					Resources:   request,
+					SharedSlots: []int32{slot},
+				},
+			},
+		}
+	}
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+
+	// the full GPU request is never allocated on the shared GPU
+	allocations, err := allocator.Allocate("test-node", newPod("pod-0"), v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+	}, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+
+	pod1 := newPod("pod-1")
+	allocations, err = allocator.Allocate("test-node", pod1, request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated(0), allocations)
+	nd.updateCacheUsed(allocations, pod1, true)
+
+	pod2 := newPod("pod-2")
+	allocations, err = allocator.Allocate("test-node", pod2, request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated(1), allocations)
+	nd.updateCacheUsed(allocations, pod2, true)
+
+	// the slots are exhausted
+	_, err = allocator.Allocate("test-node", newPod("pod-3"), request, nd)
+	assert.Error(t, err)
+
+	// the slots of the pods removed in the simulation are free
+	delta := newNodeDeviceDelta()
+	delta.removed[types.NamespacedName{Namespace: "default", Name: "pod-1"}] = allocated(0)
+	allocations, err = allocator.Allocate("test-node", newPod("pod-3"), request, nd.withDelta(delta))
+	assert.NoError(t, err)
+	assert.Equal(t, allocated(0), allocations)
+
+	// the released slots are free again
+	nd.updateCacheUsed(allocated(0), pod1, false)
+	allocations, err = allocator.Allocate("test-node", newPod("pod-3"), request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, allocated(0), allocations)
+}
+
+func Test_nodeDevice_fitsGPUMemoryCapacity(t *testing.T) {
+	nd := newNodeDevice()
+	nd.deviceTotal[schedulingv1alpha1.GPU] = deviceResources{
+		0: v1.ResourceList{
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+		},
+	}
+	// the accumulated usage exceeds the capacity, e.g. rounded allocations recovered from the pods
+	nd.deviceUsed[schedulingv1alpha1.GPU] = deviceResources{
+		0: v1.ResourceList{
+			apiext.GPUMemoryRatio: *resource.NewQuantity(99, resource.DecimalSI),
+			apiext.GPUMemory:      *resource.NewQuantity(1001, resource.BinarySI),
+		},
+	}
+	assert.False(t, nd.fitsGPUMemoryCapacity(0, v1.ResourceList{
+		apiext.GPUMemoryRatio: *resource.NewQuantity(0, resource.DecimalSI),
+	}))
+	nd.deviceUsed[schedulingv1alpha1.GPU][0][apiext.GPUMemory] = *resource.NewQuantity(990, resource.BinarySI)
+	assert.True(t, nd.fitsGPUMemoryCapacity(0, v1.ResourceList{
+		apiext.GPUMemoryRatio: *resource.NewQuantity(1, resource.DecimalSI),
+		apiext.GPUMemory:      *resource.NewQuantity(10, resource.BinarySI),
+	}))
+	assert.False(t, nd.fitsGPUMemoryCapacity(0, v1.ResourceList{
+		apiext.GPUMemoryRatio: *resource.NewQuantity(2, resource.DecimalSI),
+		apiext.GPUMemory:      *resource.NewQuantity(10, resource.BinarySI),
+	}))
+}
+
+func Test_defaultAllocator_AllocateWithDeviceOrderingHint(t *testing.T) {
+	nd := newNodeDevice()
+	total := deviceResources{}
+	for minor := 0; minor < 4; minor++ {
+		total[minor] = v1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+		}
+	}
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{schedulingv1alpha1.GPU: total})
+	allocator := &defaultAllocator{}
+
+	// the card 0 is occupied, the restored pod gets the cards 1, 2, 3
+	occupied := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}
+	allocations, err := allocator.Allocate("test-node", occupied, v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+	}, nd)
+	assert.NoError(t, err)
+	allocator.Reserve(occupied, nd, allocations)
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "restored",
+			Annotations: map[string]string{
+				apiext.AnnotationDeviceOrderingHint: `{"gpu":[2,0,1]}`,
+			},
+		},
+	}
+	allocations, err = allocator.Allocate("test-node", pod, v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(300, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(300, resource.DecimalSI),
+	}, nd)
+	assert.NoError(t, err)
+	var minors []int32
+	for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+		minors = append(minors, allocation.Minor)
+	}
+	assert.Equal(t, []int32{3, 1, 2}, minors)
+}
+
+func Test_defaultAllocator_AllocateWithDeviceReuseHint(t *testing.T) {
+	newTestNodeDevice := func() *nodeDevice {
+		nd := newNodeDevice()
+		gpus, nics := deviceResources{}, deviceResources{}
+		for minor := 0; minor < 4; minor++ {
+			gpus[minor] = v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+			}
+			nics[minor] = v1.ResourceList{
+				apiext.KoordRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+			}
+		}
+		nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU:  gpus,
+			schedulingv1alpha1.RDMA: nics,
+		})
+		return nd
+	}
+	podRequest := v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.KoordRDMA:      *resource.NewQuantity(100, resource.DecimalSI),
+	}
+	getMinors := func(allocations apiext.DeviceAllocations, deviceType schedulingv1alpha1.DeviceType) []int32 {
+		var minors []int32
+		for _, allocation := range allocations[deviceType] {
+			minors = append(minors, allocation.Minor)
+		}
+		return minors
+	}
+	newTestPod := func(name, hint string) *v1.Pod {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if hint != "" {
+			pod.Annotations = map[string]string{apiext.AnnotationDeviceReuseHint: hint}
+		}
+		return pod
+	}
+	allocator := &defaultAllocator{}
+	// the GPU 3 and the NIC 2 were the pair used by the pod before it restarted
+	reuseHint := `{"gpu":[{"minor":3,"resources":{}}],"rdma":[{"minor":2,"resources":{}}]}`
+
+	tests := []struct {
+		name         string
+		hint         string
+		occupiedHint string
+		wantGPUs     []int32
+		wantNICs     []int32
+	}{
+		{
+			name:     "the restarted pod reclaims the prior GPU and NIC pair",
+			hint:     reuseHint,
+			wantGPUs: []int32{3},
+			wantNICs: []int32{2},
+		},
+		{
+			name:         "the prior NIC is taken, falls back to the usual allocation of both",
+			hint:         reuseHint,
+			occupiedHint: `{"gpu":[{"minor":1,"resources":{}}],"rdma":[{"minor":2,"resources":{}}]}`,
+			wantGPUs:     []int32{0},
+			wantNICs:     []int32{0},
+		},
+		{
+			name:         "the prior GPU is taken, falls back to the usual allocation of both",
+			hint:         reuseHint,
+			occupiedHint: `{"gpu":[{"minor":3,"resources":{}}],"rdma":[{"minor":1,"resources":{}}]}`,
+			wantGPUs:     []int32{0},
+			wantNICs:     []int32{0},
+		},
+		{
+			name:     "the hint misses the NIC, falls back to the usual allocation",
+			hint:     `{"gpu":[{"minor":3,"resources":{}}]}`,
+			wantGPUs: []int32{0},
+			wantNICs: []int32{0},
+		},
+		{
+			name:     "the hinted devices are not on the node",
+			hint:     `{"gpu":[{"minor":7,"resources":{}}],"rdma":[{"minor":2,"resources":{}}]}`,
+			wantGPUs: []int32{0},
+			wantNICs: []int32{0},
+		},
+		{
+			name:     "invalid hint",
+			hint:     `invalid`,
+			wantGPUs: []int32{0},
+			wantNICs: []int32{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := newTestNodeDevice()
+			if tt.occupiedHint != "" {
+				occupied := newTestPod("occupied", tt.occupiedHint)
+				allocations, err := allocator.Allocate("test-node", occupied, podRequest, nd)
+				assert.NoError(t, err)
+				allocator.Reserve(occupied, nd, allocations)
+			}
+			allocations, err := allocator.Allocate("test-node", newTestPod("restarted", tt.hint), podRequest, nd)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantGPUs, getMinors(allocations, schedulingv1alpha1.GPU))
+			assert.Equal(t, tt.wantNICs, getMinors(allocations, schedulingv1alpha1.RDMA))
+		})
+	}
+}
+
+func Test_defaultAllocator_AllocateNUMAAlignedGPUAndRDMA(t *testing.T) {
+	// the GPUs 0, 1 and the NIC 0 are attached to the NUMA node 0, the GPUs 2, 3 and the NIC 1 to the NUMA node 1
+	newTestNodeDevice := func(occupied apiext.DeviceAllocations) *nodeDevice {
+		nd := newNodeDevice()
+		gpus, nics := deviceResources{}, deviceResources{}
+		for minor := 0; minor < 4; minor++ {
+			gpus[minor] = v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+			}
+		}
+		for minor := 0; minor < 2; minor++ {
+			nics[minor] = v1.ResourceList{
+				apiext.KoordRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+			}
+		}
+		nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU:  gpus,
+			schedulingv1alpha1.RDMA: nics,
+		})
+		nd.gpuNUMANodes = map[int]int32{0: 0, 1: 0, 2: 1, 3: 1}
+		nd.rdmaNUMANodes = map[int]int32{0: 0, 1: 1}
+		if len(occupied) > 0 {
+			nd.updateCacheUsed(occupied, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}, true)
+		}
+		return nd
+	}
+	wholeGPU := v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+	}
+	wholeNIC := v1.ResourceList{
+		apiext.KoordRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+	}
+	newRequest := func(gpus, nics int64) v1.ResourceList {
+		request := v1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(100*gpus, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100*gpus, resource.DecimalSI),
+		}
+		if nics > 0 {
+			request[apiext.KoordRDMA] = *resource.NewQuantity(100*nics, resource.DecimalSI)
+		}
+		return request
+	}
+	getMinors := func(allocations apiext.DeviceAllocations, deviceType schedulingv1alpha1.DeviceType) []int32 {
+		var minors []int32
+		for _, allocation := range allocations[deviceType] {
+			minors = append(minors, allocation.Minor)
+		}
+		return minors
+	}
+
+	tests := []struct {
+		name        string
+		policy      config.DeviceNUMATopologyPolicy
+		occupied    apiext.DeviceAllocations
+		withoutRDMA bool
+		noTopology  bool
+		podRequest  v1.ResourceList
+		wantGPUs    []int32
+		wantNICs    []int32
+		wantErr     error
+	}{
+		{
+			name: "the GPU follows the free NIC to the NUMA node 1",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}},
+			},
+			podRequest: newRequest(1, 1),
+			wantGPUs:   []int32{2},
+			wantNICs:   []int32{1},
+		},
+		{
+			name: "the NUMA node with fewer free GPUs is preferred",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {{Minor: 2, Resources: wholeGPU}},
+			},
+			podRequest: newRequest(1, 1),
+			wantGPUs:   []int32{3},
+			wantNICs:   []int32{1},
+		},
+		{
+			name: "BestEffort falls back to the devices across the NUMA nodes",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU:  {{Minor: 3, Resources: wholeGPU}},
+				schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}},
+			},
+			policy:     config.DeviceNUMATopologyBestEffort,
+			podRequest: newRequest(2, 1),
+			wantGPUs:   []int32{0, 1},
+			wantNICs:   []int32{1},
+		},
+		{
+			name: "Restricted rejects the devices across the NUMA nodes",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU:  {{Minor: 3, Resources: wholeGPU}},
+				schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}},
+			},
+			policy:     config.DeviceNUMATopologyRestricted,
+			podRequest: newRequest(2, 1),
+			wantErr:    errUnalignedNUMADevices,
+		},
+		{
+			name:       "Restricted allocates the devices on the same NUMA node",
+			policy:     config.DeviceNUMATopologyRestricted,
+			podRequest: newRequest(2, 1),
+			wantGPUs:   []int32{0, 1},
+			wantNICs:   []int32{0},
+		},
+		{
+			name:       "Restricted does not align the GPUs requested without the NICs",
+			policy:     config.DeviceNUMATopologyRestricted,
+			podRequest: newRequest(4, 0),
+			wantGPUs:   []int32{0, 1, 2, 3},
+		},
+		{
+			name:       "Restricted does not align the devices not reporting the topology",
+			policy:     config.DeviceNUMATopologyRestricted,
+			noTopology: true,
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU:  {{Minor: 3, Resources: wholeGPU}},
+				schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}},
+			},
+			podRequest: newRequest(2, 1),
+			wantGPUs:   []int32{0, 1},
+			wantNICs:   []int32{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := newTestNodeDevice(tt.occupied)
+			if tt.noTopology {
+				nd.rdmaNUMANodes = nil
+			}
+			allocator := NewDefaultAllocator(AllocatorOptions{NUMATopologyPolicy: tt.policy})
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+			allocations, err := allocator.Allocate("test-node", pod, tt.podRequest, nd)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantGPUs, getMinors(allocations, schedulingv1alpha1.GPU))
+			assert.Equal(t, tt.wantNICs, getMinors(allocations, schedulingv1alpha1.RDMA))
+		})
+	}
+
+	t.Run("Restricted reports the insufficient devices as usual", func(t *testing.T) {
+		nd := newTestNodeDevice(apiext.DeviceAllocations{
+			schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}, {Minor: 1, Resources: wholeNIC}},
+		})
+		allocator := NewDefaultAllocator(AllocatorOptions{NUMATopologyPolicy: config.DeviceNUMATopologyRestricted})
+		_, err := allocator.Allocate("test-node", &v1.Pod{}, newRequest(1, 1), nd)
+		assert.Error(t, err)
+		assert.NotEqual(t, errUnalignedNUMADevices, err)
+	})
+}
+
+func Test_defaultAllocator_AllocateJointGPUAndRDMA(t *testing.T) {
+	// the GPUs 0, 1 and the NIC 0 are attached to the PCIe switch pcie-0, the GPUs 2, 3 and the NIC 1 to pcie-1,
+	// all on the NUMA node 0
+	newTestNodeDevice := func(occupied apiext.DeviceAllocations) *nodeDevice {
+		nd := newNodeDevice()
+		gpus, nics := deviceResources{}, deviceResources{}
+		for minor := 0; minor < 4; minor++ {
+			gpus[minor] = v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+			}
+		}
+		for minor := 0; minor < 2; minor++ {
+			nics[minor] = v1.ResourceList{
+				apiext.KoordRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+			}
+		}
+		nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU:  gpus,
+			schedulingv1alpha1.RDMA: nics,
+		})
+		nd.gpuNUMANodes = map[int]int32{0: 0, 1: 0, 2: 0, 3: 0}
+		nd.gpuPCIeSwitches = map[int]string{0: "pcie-0", 1: "pcie-0", 2: "pcie-1", 3: "pcie-1"}
+		nd.rdmaNUMANodes = map[int]int32{0: 0, 1: 0}
+		nd.rdmaPCIeSwitches = map[int]string{0: "pcie-0", 1: "pcie-1"}
+		if len(occupied) > 0 {
+			nd.updateCacheUsed(occupied, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}, true)
+		}
+		return nd
+	}
+	wholeGPU := v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+	}
+	wholeNIC := v1.ResourceList{
+		apiext.KoordRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+	}
+	newRequest := func(gpus, nics int64) v1.ResourceList {
+		return v1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(100*gpus, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100*gpus, resource.DecimalSI),
+			apiext.KoordRDMA:      *resource.NewQuantity(100*nics, resource.DecimalSI),
+		}
+	}
+	getDevices := func(allocations apiext.DeviceAllocations, deviceType schedulingv1alpha1.DeviceType) map[int32]string {
+		if len(allocations[deviceType]) == 0 {
+			return nil
+		}
+		devices := map[int32]string{}
+		for _, allocation := range allocations[deviceType] {
+			devices[allocation.Minor] = allocation.PCIeSwitchID
+		}
+		return devices
+	}
+
+	tests := []struct {
+		name       string
+		occupied   apiext.DeviceAllocations
+		noTopology bool
+		podRequest v1.ResourceList
+		wantGPUs   map[int32]string
+		wantNICs   map[int32]string
+		wantErr    error
+	}{
+		{
+			name: "the GPU follows the free NIC to the PCIe switch pcie-1",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}},
+			},
+			podRequest: newRequest(1, 1),
+			wantGPUs:   map[int32]string{2: "pcie-1"},
+			wantNICs:   map[int32]string{1: "pcie-1"},
+		},
+		{
+			name: "the PCIe switch with fewer free GPUs is preferred",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {{Minor: 2, Resources: wholeGPU}},
+			},
+			podRequest: newRequest(1, 1),
+			wantGPUs:   map[int32]string{3: "pcie-1"},
+			wantNICs:   map[int32]string{1: "pcie-1"},
+		},
+		{
+			name:       "the GPUs and the NIC are allocated under the same PCIe switch",
+			podRequest: newRequest(2, 1),
+			wantGPUs:   map[int32]string{0: "pcie-0", 1: "pcie-0"},
+			wantNICs:   map[int32]string{0: "pcie-0"},
+		},
+		{
+			name: "the devices across the PCIe switches are rejected",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU:  {{Minor: 3, Resources: wholeGPU}},
+				schedulingv1alpha1.RDMA: {{Minor: 0, Resources: wholeNIC}},
+			},
+			podRequest: newRequest(2, 1),
+			wantErr:    errUnalignedJointDevices,
+		},
+		{
+			name:       "the request exceeding any PCIe switch is paired under both PCIe switches",
+			podRequest: newRequest(4, 2),
+			wantGPUs:   map[int32]string{0: "pcie-0", 1: "pcie-0", 2: "pcie-1", 3: "pcie-1"},
+			wantNICs:   map[int32]string{0: "pcie-0", 1: "pcie-1"},
+		},
+		{
+			name: "the GPUs are paired with the NICs under their own PCIe switches",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {{Minor: 0, Resources: wholeGPU}},
+			},
+			podRequest: newRequest(3, 2),
+			wantGPUs:   map[int32]string{1: "pcie-0", 2: "pcie-1", 3: "pcie-1"},
+			wantNICs:   map[int32]string{0: "pcie-0", 1: "pcie-1"},
+		},
+		{
+			name:       "the GPUs under a PCIe switch without a NIC taken are rejected",
+			podRequest: newRequest(4, 1),
+			wantErr:    errUnalignedJointDevices,
+		},
+		{
+			name: "the PCIe switch without a free NIC cannot pair the GPUs",
+			occupied: apiext.DeviceAllocations{
+				schedulingv1alpha1.RDMA: {{Minor: 1, Resources: wholeNIC}},
+			},
+			podRequest: newRequest(3, 1),
+			wantErr:    errUnalignedJointDevices,
+		},
+		{
+			name:       "the devices not reporting the PCIe switch are not allocated jointly",
+			noTopology: true,
+			podRequest: newRequest(1, 1),
+			wantErr:    errUnalignedJointDevices,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := newTestNodeDevice(tt.occupied)
+			if tt.noTopology {
+				nd.rdmaPCIeSwitches = nil
+			}
+			allocator := NewDefaultAllocator(AllocatorOptions{})
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+					Annotations: map[string]string{
+						apiext.AnnotationDeviceJointAllocate: `{"deviceTypes":["gpu","rdma"]}`,
+					},
+				},
+			}
+			allocations, err := allocator.Allocate("test-node", pod, tt.podRequest, nd)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantGPUs, getDevices(allocations, schedulingv1alpha1.GPU))
+			assert.Equal(t, tt.wantNICs, getDevices(allocations, schedulingv1alpha1.RDMA))
+		})
+	}
+
+	t.Run("the PCIe switches paired are on the same NUMA node if Restricted", func(t *testing.T) {
+		nd := newTestNodeDevice(nil)
+		nd.gpuNUMANodes = map[int]int32{0: 0, 1: 0, 2: 1, 3: 1}
+		nd.rdmaNUMANodes = map[int]int32{0: 0, 1: 1}
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test-pod",
+				Annotations: map[string]string{
+					apiext.AnnotationDeviceJointAllocate: `{"deviceTypes":["gpu","rdma"]}`,
+				},
+			},
+		}
+		allocator := NewDefaultAllocator(AllocatorOptions{})
+		allocations, err := allocator.Allocate("test-node", pod, newRequest(4, 2), nd)
+		assert.NoError(t, err)
+		assert.Len(t, allocations[schedulingv1alpha1.GPU], 4)
+
+		allocator = NewDefaultAllocator(AllocatorOptions{NUMATopologyPolicy: config.DeviceNUMATopologyRestricted})
+		_, err = allocator.Allocate("test-node", pod, newRequest(4, 2), nd)
+		assert.Equal(t, errUnalignedJointDevices, err)
+	})
+}
+
+func Test_defaultAllocator_AllocatePodNUMAAlignedGPUs(t *testing.T) {
+	// the GPUs 0-3 are attached to the NUMA node 0, and the GPUs 4-7 to the NUMA node 1
+	wholeGPU := v1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemory:      *resource.NewQuantity(1000, resource.BinarySI),
+	}
+	newTestNodeDevice := func(occupied []int32) *nodeDevice {
+		nd := newNodeDevice()
+		gpus := deviceResources{}
+		nd.gpuNUMANodes = map[int]int32{}
+		for minor := 0; minor < 8; minor++ {
+			gpus[minor] = wholeGPU.DeepCopy()
+			nd.gpuNUMANodes[minor] = int32(minor / 4)
+		}
+		nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: gpus,
+		})
+		if len(occupied) > 0 {
+			allocations := apiext.DeviceAllocations{}
+			for _, minor := range occupied {
+				allocations[schedulingv1alpha1.GPU] = append(allocations[schedulingv1alpha1.GPU], &apiext.DeviceAllocation{Minor: minor, Resources: wholeGPU})
+			}
+			nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}, true)
+		}
+		return nd
+	}
+	newRequest := func(gpus int64) v1.ResourceList {
+		return v1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(100*gpus, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100*gpus, resource.DecimalSI),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		policy        apiext.NUMATopologyPolicy
+		occupied      []int32
+		noTopology    bool
+		gpus          int64
+		wantNUMANodes []int32
+		wantErr       error
+	}{
+		{
+			name:          "BestEffort allocates the GPUs on the NUMA node with fewer free GPUs",
+			policy:        apiext.NUMATopologyPolicyBestEffort,
+			occupied:      []int32{4},
+			gpus:          2,
+			wantNUMANodes: []int32{1},
+		},
+		{
+			name:          "Restricted allocates the GPUs on a single NUMA node",
+			policy:        apiext.NUMATopologyPolicyRestricted,
+			occupied:      []int32{0, 5},
+			gpus:          3,
+			wantNUMANodes: []int32{0},
+		},
+		{
+			name:          "BestEffort falls back to the 4 GPUs across the NUMA nodes",
+			policy:        apiext.NUMATopologyPolicyBestEffort,
+			occupied:      []int32{0, 5},
+			gpus:          4,
+			wantNUMANodes: []int32{0, 1},
+		},
+		{
+			name:     "Restricted rejects the 4 GPUs across the NUMA nodes",
+			policy:   apiext.NUMATopologyPolicyRestricted,
+			occupied: []int32{0, 5},
+			gpus:     4,
+			wantErr:  errUnalignedNUMAGPUs,
+		},
+		{
+			name:          "None does not align the GPUs",
+			policy:        apiext.NUMATopologyPolicyNone,
+			occupied:      []int32{0, 5},
+			gpus:          4,
+			wantNUMANodes: []int32{0, 1},
+		},
+		{
+			name:       "Restricted does not align the GPUs not reporting the topology",
+			policy:     apiext.NUMATopologyPolicyRestricted,
+			noTopology: true,
+			occupied:   []int32{0, 5},
+			gpus:       4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := newTestNodeDevice(tt.occupied)
+			if tt.noTopology {
+				delete(nd.gpuNUMANodes, 7)
+			}
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+					Annotations: map[string]string{
+						apiext.AnnotationResourceSpec: fmt.Sprintf(`{"numaTopologyPolicy": %q}`, tt.policy),
+					},
+				},
+			}
+			allocator := NewDefaultAllocator(AllocatorOptions{})
+			allocations, err := allocator.Allocate("test-node", pod, newRequest(tt.gpus), nd)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Len(t, allocations[schedulingv1alpha1.GPU], int(tt.gpus))
+			if tt.wantNUMANodes != nil {
+				// the NUMA nodes of the GPUs are published for the CPUs to align to them
+				topology := nd.getAllocatedTopology(allocations)
+				assert.Equal(t, tt.wantNUMANodes, topology[schedulingv1alpha1.GPU].NUMANodes)
+			}
+		})
+	}
+}
+
+func Test_nodeDevice_tryAllocateRDMAVirtualFunctions(t *testing.T) {
+	// the NIC 0 exposes 2 virtual functions and the NIC 1 exposes 1
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.RDMA,
+					Resources: v1.ResourceList{
+						apiext.KoordRDMA: resource.MustParse("100"),
+					},
+					VirtualFunctions: []schedulingv1alpha1.VirtualFunction{
+						{BusID: "0000:1f:00.3"},
+						{BusID: "0000:1f:00.2"},
+					},
+				},
+				{
+					Minor:  pointer.Int32Ptr(1),
+					Health: true,
+					Type:   schedulingv1alpha1.RDMA,
+					Resources: v1.ResourceList{
+						apiext.KoordRDMA: resource.MustParse("100"),
+					},
+					VirtualFunctions: []schedulingv1alpha1.VirtualFunction{
+						{BusID: "0000:90:00.2"},
+					},
+				},
+			},
+		},
+	}
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", device)
+	nd := cache.getNodeDevice("test-node")
+	assert.True(t, quotav1.Equals(getVirtualFunctionResources(2), nd.deviceTotal[schedulingv1alpha1.RDMA][0]))
+	assert.True(t, quotav1.Equals(getVirtualFunctionResources(1), nd.deviceTotal[schedulingv1alpha1.RDMA][1]))
+
+	allocate := func(name string, rdma int64) (apiext.DeviceAllocations, error) {
+		podRequest := v1.ResourceList{apiext.KoordRDMA: *resource.NewQuantity(rdma, resource.DecimalSI)}
+		allocations, err := nd.tryAllocateDevice(podRequest, "")
+		if err == nil {
+			nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}, true)
+		}
+		return allocations, err
+	}
+
+	// the partial request takes a whole virtual function
+	allocations, err := allocate("pod-1", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiext.DeviceAllocation{
+		{Minor: 0, Resources: getVirtualFunctionResources(1), VirtualFunctions: []schedulingv1alpha1.VirtualFunction{{BusID: "0000:1f:00.2"}}},
+	}, allocations[schedulingv1alpha1.RDMA])
+
+	// the pods never receive the same virtual function
+	allocations, err = allocate("pod-2", 200)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiext.DeviceAllocation{
+		{Minor: 0, Resources: getVirtualFunctionResources(1), VirtualFunctions: []schedulingv1alpha1.VirtualFunction{{BusID: "0000:1f:00.3"}}},
+		{Minor: 1, Resources: getVirtualFunctionResources(1), VirtualFunctions: []schedulingv1alpha1.VirtualFunction{{BusID: "0000:90:00.2"}}},
+	}, allocations[schedulingv1alpha1.RDMA])
+
+	_, err = allocate("pod-3", 100)
+	assert.Equal(t, errRDMAVFsExhausted, err)
+
+	// releasing the pod returns its virtual functions
+	nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2"}}, false)
+	assert.Equal(t, []schedulingv1alpha1.VirtualFunction{{BusID: "0000:1f:00.3"}}, nd.getFreeVirtualFunctions(0))
+	allocations, err = allocate("pod-3", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiext.DeviceAllocation{
+		{Minor: 0, Resources: getVirtualFunctionResources(1), VirtualFunctions: []schedulingv1alpha1.VirtualFunction{{BusID: "0000:1f:00.3"}}},
+	}, allocations[schedulingv1alpha1.RDMA])
+}
+
+func Test_reserveStatistics(t *testing.T) {
+	bucketDuration := reserveStatisticsWindow / reserveStatisticsBuckets
+	start := time.Now().Truncate(bucketDuration)
+	stats := &reserveStatistics{}
+
+	succeeded, failed := stats.count(start)
+	assert.Equal(t, int64(0), succeeded)
+	assert.Equal(t, int64(0), failed)
+
+	stats.record(start, true)
+	stats.record(start, false)
+	stats.record(start.Add(bucketDuration), true)
+	stats.record(start.Add(2*bucketDuration), false)
+	succeeded, failed = stats.count(start.Add(2 * bucketDuration))
+	assert.Equal(t, int64(2), succeeded)
+	assert.Equal(t, int64(2), failed)
+
+	// the results in the first bucket slide out of the window
+	now := start.Add(reserveStatisticsWindow)
+	succeeded, failed = stats.count(now)
+	assert.Equal(t, int64(1), succeeded)
+	assert.Equal(t, int64(1), failed)
+
+	// the bucket of the first results is reused
+	stats.record(now, true)
+	succeeded, failed = stats.count(now)
+	assert.Equal(t, int64(2), succeeded)
+	assert.Equal(t, int64(1), failed)
+
+	succeeded, failed = stats.count(now.Add(reserveStatisticsWindow))
+	assert.Equal(t, int64(0), succeeded)
+	assert.Equal(t, int64(0), failed)
+}
+
+func Test_nodeDevice_reconcileConflictingAllocations(t *testing.T) {
+	gpu := func(core, memory int64) v1.ResourceList {
+		return v1.ResourceList{
+			apiext.GPUCore:   *resource.NewQuantity(core, resource.DecimalSI),
+			apiext.GPUMemory: *resource.NewQuantity(memory, resource.BinarySI),
+		}
+	}
+	newAllocations := func(minor int32, resources v1.ResourceList) apiext.DeviceAllocations {
+		return apiext.DeviceAllocations{
+			schedulingv1alpha1.GPU: {{Minor: minor, Resources: resources}},
+		}
+	}
+	tests := []struct {
+		name      string
+		strategy  config.DeviceReconcileStrategy
+		wantTotal map[int][2]int64
+		wantFree  map[int][2]int64
+		wantFit   bool
+	}{
+		{
+			name:      "trust pods covers the allocations without raising the reported capacity",
+			strategy:  config.DeviceReconcileTrustPods,
+			wantTotal: map[int][2]int64{0: {100, 1000}, 1: {100, 1000}},
+			wantFree:  map[int][2]int64{0: {0, 0}, 1: {100, 1000}, 2: {0, 0}},
+			wantFit:   true,
+		},
+		{
+			name:      "trust crd keeps the capacity reported by the device",
+			strategy:  config.DeviceReconcileTrustCRD,
+			wantTotal: map[int][2]int64{0: {100, 1000}, 1: {100, 1000}},
+			wantFree:  map[int][2]int64{0: {0, 0}, 1: {100, 1000}, 2: {0, 0}},
+			wantFit:   true,
+		},
+		{
+			name:      "conservative min deducts the unaccounted allocations from the other devices",
+			strategy:  config.DeviceReconcileConservativeMin,
+			wantTotal: map[int][2]int64{0: {100, 1000}, 1: {100, 1000}},
+			wantFree:  map[int][2]int64{0: {0, 0}, 1: {50, 0}},
+			wantFit:   false,
+		},
+		{
+			name:      "the empty strategy is conservative min",
+			wantTotal: map[int][2]int64{0: {100, 1000}, 1: {100, 1000}},
+			wantFree:  map[int][2]int64{0: {0, 0}, 1: {50, 0}},
+			wantFit:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := newNodeDevice()
+			nd.reconcileStrategy = tt.strategy
+			// the memory of GPU 0 shrank and GPU 2 is not reported anymore, the pods started before still use them
+			nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+				schedulingv1alpha1.GPU: {0: gpu(100, 1000), 1: gpu(100, 1000)},
+			})
+			nd.updateCacheUsed(newAllocations(0, gpu(100, 1500)),
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shrank", UID: "shrank"}}, true)
+			nd.updateCacheUsed(newAllocations(2, gpu(50, 500)),
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "orphan", UID: "orphan"}}, true)
+
+			summarize := func(resources deviceResources) map[int][2]int64 {
+				got := map[int][2]int64{}
+				for minor, resourceList := range resources {
+					got[minor] = [2]int64{resourceList.Name(apiext.GPUCore, resource.DecimalSI).Value(),
+						resourceList.Name(apiext.GPUMemory, resource.BinarySI).Value()}
+				}
+				return got
+			}
+			assert.Equal(t, tt.wantTotal, summarize(nd.deviceTotal[schedulingv1alpha1.GPU]))
+			assert.Equal(t, tt.wantFree, summarize(nd.deviceFree[schedulingv1alpha1.GPU]))
+
+			_, err := nd.tryAllocateDevice(gpu(100, 1000), "")
+			assert.Equal(t, tt.wantFit, err == nil)
+
+			// the device is free as reported once the pods are released
+			nd.updateCacheUsed(newAllocations(0, gpu(100, 1500)),
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shrank", UID: "shrank"}}, false)
+			assert.Equal(t, [2]int64{100, 1000}, summarize(nd.deviceFree[schedulingv1alpha1.GPU])[0])
+		})
+	}
+}
+
+func Test_nodeDevice_getAllocatedNUMANodes(t *testing.T) {
+	nd := newNodeDevice()
+	nd.gpuNUMANodes = map[int]int32{0: 1, 1: 1}
+	nd.rdmaNUMANodes = map[int]int32{0: 0, 1: 1}
+	assert.Equal(t, []int{1}, nd.getAllocatedNUMANodes(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU:  {{Minor: 0}, {Minor: 1}},
+		schedulingv1alpha1.RDMA: {{Minor: 1}},
+	}))
+	assert.Equal(t, []int{0, 1}, nd.getAllocatedNUMANodes(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU:  {{Minor: 1}},
+		schedulingv1alpha1.RDMA: {{Minor: 0}},
+	}))
+	// the devices not reporting the topology are ignored
+	assert.Equal(t, []int{}, nd.getAllocatedNUMANodes(apiext.DeviceAllocations{
+		schedulingv1alpha1.FPGA: {{Minor: 0}},
+	}))
+}
+
+func Test_nodeDevice_selectGPUsByTopology(t *testing.T) {
+	tests := []struct {
+		name         string
+		gpuNUMANodes map[int]int32
+		candidates   []int
+		wanted       int
+		want         []int
+	}{
+		{
+			name:       "no topology",
+			candidates: []int{0, 1, 2, 3},
+			wanted:     2,
+			want:       []int{0, 1},
+		},
+		{
+			name:         "partial topology",
+			gpuNUMANodes: map[int]int32{0: 0, 1: 0, 2: 1},
+			candidates:   []int{1, 2, 3},
+			wanted:       2,
+			want:         []int{1, 2},
+		},
+		{
+			name:         "smallest NUMA node holding all the wanted GPUs",
+			gpuNUMANodes: map[int]int32{0: 0, 1: 0, 2: 0, 3: 1, 4: 1, 5: 2},
+			candidates:   []int{0, 1, 2, 3, 4, 5},
+			wanted:       2,
+			want:         []int{3, 4},
+		},
+		{
+			name:         "spread over the fewest NUMA nodes",
+			gpuNUMANodes: map[int]int32{0: 0, 1: 1, 2: 1, 3: 2, 4: 2, 5: 2},
+			candidates:   []int{0, 1, 2, 3, 4, 5},
+			wanted:       4,
+			want:         []int{1, 3, 4, 5},
+		},
+		{
+			name:         "candidates are just enough",
+			gpuNUMANodes: map[int]int32{0: 0, 3: 1},
+			candidates:   []int{0, 3},
+			wanted:       2,
+			want:         []int{0, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &nodeDevice{gpuNUMANodes: tt.gpuNUMANodes}
+			assert.Equal(t, tt.want, n.selectGPUsByTopology(tt.candidates, tt.wanted))
+		})
+	}
+}
+
+func Test_nodeDeviceCache_getLargestSchedulableGPU(t *testing.T) {
+	gpu := func(minor int32, health bool) schedulingv1alpha1.DeviceInfo {
+		return schedulingv1alpha1.DeviceInfo{
+			Type:   schedulingv1alpha1.GPU,
+			Minor:  pointer.Int32(minor),
+			Health: health,
+			Resources: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse("80Gi"),
+			},
+		}
+	}
+	gpuAllocation := func(minor int32, core, ratio int64, memory string) *apiext.DeviceAllocation {
+		return &apiext.DeviceAllocation{
+			Minor: minor,
+			Resources: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(core, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(ratio, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse(memory),
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		devices     []schedulingv1alpha1.DeviceInfo
+		allocations []*apiext.DeviceAllocation
+		want        *NodeLargestSchedulableGPU
+		// wantUnlisted is whether the node is left out of all the nodes for having no GPUs
+		wantUnlisted bool
+	}{
+		{
+			name:         "node without GPUs",
+			wantUnlisted: true,
+			devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Type:      schedulingv1alpha1.RDMA,
+					Minor:     pointer.Int32(0),
+					Health:    true,
+					Resources: v1.ResourceList{apiext.KoordRDMA: *resource.NewQuantity(100, resource.DecimalSI)},
+				},
+			},
+			want: &NodeLargestSchedulableGPU{},
+		},
+		{
+			name:    "all the GPUs are free",
+			devices: []schedulingv1alpha1.DeviceInfo{gpu(0, true), gpu(1, true)},
+			want: &NodeLargestSchedulableGPU{
+				WholeGPUs:    2,
+				LargestShare: &GPUShare{Minor: 0, GPUCore: 100, GPUMemoryRatio: 100, GPUMemory: resourcePtr("80Gi")},
+				GPUs: []GPUShare{
+					{Minor: 0, GPUCore: 100, GPUMemoryRatio: 100, GPUMemory: resourcePtr("80Gi")},
+					{Minor: 1, GPUCore: 100, GPUMemoryRatio: 100, GPUMemory: resourcePtr("80Gi")},
+				},
+			},
+		},
+		{
+			name:        "all the GPUs are used up",
+			devices:     []schedulingv1alpha1.DeviceInfo{gpu(0, true), gpu(1, true)},
+			allocations: []*apiext.DeviceAllocation{gpuAllocation(0, 100, 100, "80Gi"), gpuAllocation(1, 100, 100, "80Gi")},
+			want:        &NodeLargestSchedulableGPU{},
+		},
+		{
+			name:    "fragmented GPUs",
+			devices: []schedulingv1alpha1.DeviceInfo{gpu(0, true), gpu(1, true), gpu(2, true), gpu(3, false)},
+			allocations: []*apiext.DeviceAllocation{
+				gpuAllocation(0, 70, 70, "56Gi"),
+				gpuAllocation(2, 20, 50, "40Gi"),
+				gpuAllocation(1, 100, 100, "80Gi"),
+			},
+			want: &NodeLargestSchedulableGPU{
+				WholeGPUs:    0,
+				LargestShare: &GPUShare{Minor: 2, GPUCore: 80, GPUMemoryRatio: 50, GPUMemory: resourcePtr("40Gi")},
+				GPUs: []GPUShare{
+					{Minor: 0, GPUCore: 30, GPUMemoryRatio: 30, GPUMemory: resourcePtr("24Gi")},
+					{Minor: 2, GPUCore: 80, GPUMemoryRatio: 50, GPUMemory: resourcePtr("40Gi")},
+				},
+			},
+		},
+		{
+			name: "the GPUs carved into the MIG instances are excluded",
+			devices: []schedulingv1alpha1.DeviceInfo{
+				gpu(0, true),
+				{
+					Type:         schedulingv1alpha1.GPU,
+					Minor:        pointer.Int32(1),
+					Health:       true,
+					MIGInstances: map[string]int32{"1g.10gb": 7},
+				},
+			},
+			want: &NodeLargestSchedulableGPU{
+				WholeGPUs:    1,
+				LargestShare: &GPUShare{Minor: 0, GPUCore: 100, GPUMemoryRatio: 100, GPUMemory: resourcePtr("80Gi")},
+				GPUs: []GPUShare{
+					{Minor: 0, GPUCore: 100, GPUMemoryRatio: 100, GPUMemory: resourcePtr("80Gi")},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newNodeDeviceCache()
+			cache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Spec:       schedulingv1alpha1.DeviceSpec{Devices: tt.devices},
+			})
+			if len(tt.allocations) > 0 {
+				pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"}}
+				cache.getNodeDevice("test-node").updateCacheUsed(apiext.DeviceAllocations{schedulingv1alpha1.GPU: tt.allocations}, pod, true)
+			}
+			got, ok := cache.getLargestSchedulableGPU("test-node")
+			assert.True(t, ok)
+			assert.True(t, apiequality.Semantic.DeepEqual(tt.want, got), "want %+v, got %+v", tt.want, got)
+
+			all := cache.getAllLargestSchedulableGPU()
+			if tt.wantUnlisted {
+				assert.Empty(t, all)
+			} else {
+				assert.True(t, apiequality.Semantic.DeepEqual(tt.want, all["test-node"]))
+			}
+		})
+	}
+
+	_, ok := newNodeDeviceCache().getLargestSchedulableGPU("missing-node")
+	assert.False(t, ok)
+}
+
+func resourcePtr(value string) *resource.Quantity {
+	quantity := resource.MustParse(value)
+	return &quantity
+}
+
+func Test_nodeDevice_skipUnschedulableGPUs(t *testing.T) {
+	newDevice := func(unschedulableMinors ...int32) *schedulingv1alpha1.Device {
+		unschedulable := sets.NewInt32(unschedulableMinors...)
+		device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		for minor := int32(0); minor < 4; minor++ {
+			device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+				Type:          schedulingv1alpha1.GPU,
+				Minor:         pointer.Int32(minor),
+				Health:        true,
+				Unschedulable: unschedulable.Has(minor),
+				Resources: v1.ResourceList{
+					apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.GPUMemory:      resource.MustParse("80Gi"),
+				},
+			})
+		}
+		return device
+	}
+	gpuRequest := func(count int64) v1.ResourceList {
+		return v1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(100*count, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100*count, resource.DecimalSI),
+		}
+	}
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+	allocator := &defaultAllocator{}
+
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", newDevice())
+	nd := cache.getNodeDevice("test-node")
+	running := newPod("running")
+	runningAllocations, err := allocator.Allocate("test-node", running, gpuRequest(1), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), runningAllocations[schedulingv1alpha1.GPU][0].Minor)
+	nd.updateCacheUsed(runningAllocations, running, true)
+
+	// the GPUs turn unschedulable, the allocation on them stays accounted
+	cache.updateNodeDevice("test-node", newDevice(0, 1))
+	assert.Len(t, nd.deviceTotal[schedulingv1alpha1.GPU], 4)
+	assert.Len(t, nd.deviceFree[schedulingv1alpha1.GPU], 2)
+	assert.True(t, quotav1.Equals(runningAllocations[schedulingv1alpha1.GPU][0].Resources, nd.deviceUsed[schedulingv1alpha1.GPU][0]))
+	_, err = allocator.Allocate("test-node", newPod("pod-3"), gpuRequest(3), nd)
+	assert.Error(t, err)
+	allocations, err := allocator.Allocate("test-node", newPod("pod-2"), gpuRequest(2), nd)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{2, 3}, []int32{allocations[schedulingv1alpha1.GPU][0].Minor, allocations[schedulingv1alpha1.GPU][1].Minor})
+
+	// the summary excludes the unschedulable GPUs from the free
+	summary := nd.getNodeDeviceSummary()
+	assert.Equal(t, map[schedulingv1alpha1.DeviceType][]int{schedulingv1alpha1.GPU: {0, 1}}, summary.UnschedulableDevices)
+	assert.Equal(t, int64(200), summary.DeviceFree[apiext.GPUCore].Value())
+	assert.Equal(t, int64(400), summary.DeviceTotal[apiext.GPUCore].Value())
+
+	// the GPUs are allocated again once schedulable
+	cache.updateNodeDevice("test-node", newDevice())
+	_, err = allocator.Allocate("test-node", newPod("pod-3"), gpuRequest(3), nd)
+	assert.NoError(t, err)
+	assert.Nil(t, nd.getNodeDeviceSummary().UnschedulableDevices)
+}
+
+func Test_nodeDeviceCache_getNodeDeviceSummaryIsolated(t *testing.T) {
+	device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	for minor := int32(0); minor < 2; minor++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			Type:   schedulingv1alpha1.GPU,
+			Minor:  pointer.Int32(minor),
+			Health: true,
+			Resources: v1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		})
+	}
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", device)
+	nd := cache.getNodeDevice("test-node")
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1", UID: "pod-1"}}
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 0,
+				Resources: v1.ResourceList{
+					apiext.GPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+					apiext.GPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+				},
+			},
+		},
+	}
+
+	// the summaries are queried while the allocations change
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			nd.lock.Lock()
+			nd.updateCacheUsed(allocations, pod, i%2 == 0)
+			nd.lock.Unlock()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		summary, ok := cache.getNodeDeviceSummary("test-node")
+		assert.True(t, ok)
+		assert.Equal(t, int64(200), summary.DeviceTotal[apiext.GPUCore].Value())
+	}
+	<-done
+
+	// mutating the summary does not leak into the cache
+	summary, ok := cache.getNodeDeviceSummary("test-node")
+	assert.True(t, ok)
+	assert.Equal(t, int64(200), summary.DeviceFree[apiext.GPUCore].Value())
+	summary.DeviceFree[apiext.GPUCore].Sub(resource.MustParse("100"))
+	summary.DeviceFreeDetail[schedulingv1alpha1.GPU][0][apiext.GPUCore] = resource.MustParse("0")
+	delete(summary.DeviceTotalDetail[schedulingv1alpha1.GPU], 1)
+	free := nd.deviceFree[schedulingv1alpha1.GPU][0][apiext.GPUCore]
+	assert.Equal(t, int64(100), free.Value())
+	assert.Len(t, nd.deviceTotal[schedulingv1alpha1.GPU], 2)
+	summary, _ = cache.getNodeDeviceSummary("test-node")
+	assert.Equal(t, int64(200), summary.DeviceFree[apiext.GPUCore].Value())
+
+	_, ok = cache.getNodeDeviceSummary("unknown-node")
+	assert.False(t, ok)
+}
+
+func Test_nodeDevice_skipUnhealthyGPUs(t *testing.T) {
+	newDevice := func(unhealthyMinors ...int32) *schedulingv1alpha1.Device {
+		unhealthy := sets.NewInt32(unhealthyMinors...)
+		device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		for minor := int32(0); minor < 8; minor++ {
+			device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+				Type:   schedulingv1alpha1.GPU,
+				Minor:  pointer.Int32(minor),
+				Health: !unhealthy.Has(minor),
+				Resources: v1.ResourceList{
+					apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.GPUMemory:      resource.MustParse("80Gi"),
+				},
+			})
+		}
+		return device
+	}
+	gpuRequest := func(count int64) v1.ResourceList {
+		return v1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(100*count, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(100*count, resource.DecimalSI),
+		}
+	}
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+	allocatedMinors := func(allocations apiext.DeviceAllocations) []int32 {
+		var minors []int32
+		for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+			minors = append(minors, allocation.Minor)
+		}
+		return minors
+	}
+	allocator := &defaultAllocator{}
+
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node", newDevice(2, 5))
+	nd := cache.getNodeDevice("test-node")
+	assert.Len(t, nd.deviceFree[schedulingv1alpha1.GPU], 6)
+
+	// the node with 8 GPUs and 2 unhealthy only fits the 6-GPU pod
+	_, err := allocator.Allocate("test-node", newPod("pod-7"), gpuRequest(7), nd)
+	assert.Error(t, err)
+	_, err = allocator.Allocate("test-node", newPod("pod-8"), gpuRequest(8), nd)
+	assert.Error(t, err)
+	allocations, err := allocator.Allocate("test-node", newPod("pod-6"), gpuRequest(6), nd)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{0, 1, 3, 4, 6, 7}, allocatedMinors(allocations))
+
+	// the GPU turns unhealthy with a pod allocated, the allocation stays accounted but the GPU is never allocated
+	cache.updateNodeDevice("test-node", newDevice())
+	running := newPod("running")
+	runningAllocations, err := allocator.Allocate("test-node", running, gpuRequest(1), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{0}, allocatedMinors(runningAllocations))
+	nd.updateCacheUsed(runningAllocations, running, true)
+	cache.updateNodeDevice("test-node", newDevice(0))
+	assert.True(t, quotav1.Equals(runningAllocations[schedulingv1alpha1.GPU][0].Resources, nd.deviceUsed[schedulingv1alpha1.GPU][0]))
+	_, ok := nd.deviceFree[schedulingv1alpha1.GPU][0]
+	assert.False(t, ok)
+	_, err = allocator.Allocate("test-node", newPod("pod-8"), gpuRequest(8), nd)
+	assert.Error(t, err)
+	allocations, err = allocator.Allocate("test-node", newPod("pod-7"), gpuRequest(7), nd)
+	assert.NoError(t, err)
+	assert.NotContains(t, allocatedMinors(allocations), int32(0))
+
+	// removing the pod on the unhealthy GPU in the preemption never frees the GPU
+	delta := newNodeDeviceDelta()
+	delta.removed[types.NamespacedName{Namespace: "default", Name: "running"}] = runningAllocations
+	view := nd.withDelta(delta)
+	_, ok = view.deviceFree[schedulingv1alpha1.GPU][0]
+	assert.False(t, ok)
+	assert.True(t, quotav1.IsZero(view.deviceUsed[schedulingv1alpha1.GPU][0]))
+	assert.Empty(t, view.getPodAllocations(types.NamespacedName{Namespace: "default", Name: "running"}))
+	assert.NotEmpty(t, nd.getPodAllocations(types.NamespacedName{Namespace: "default", Name: "running"}))
+
+	// the GPU is allocated again once healthy
+	cache.updateNodeDevice("test-node", newDevice())
+	nd.updateCacheUsed(runningAllocations, running, false)
+	allocations, err = allocator.Allocate("test-node", newPod("pod-8"), gpuRequest(8), nd)
+	assert.NoError(t, err)
+	assert.Len(t, allocations[schedulingv1alpha1.GPU], 8)
+}
@@ -146,6 +146,9 @@ func Test_nodeDeviceCache_onDeviceAdd(t *testing.T) {
 					},
 					deviceUsed:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 					allocateSet: map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList{},
+					deviceUUIDs: map[schedulingv1alpha1.DeviceType]map[string]int{
+						schedulingv1alpha1.GPU: {fakeGPUUUID0: 0, fakeGPUUUID1: 1},
+					},
 				},
 			},
 		},
@@ -185,7 +188,7 @@ func Test_nodeDeviceCache_onDeviceUpdate(t *testing.T) {
 				Spec: schedulingv1alpha1.DeviceSpec{
 					Devices: []schedulingv1alpha1.DeviceInfo{
 						{
-							UUID:   string(uuid.NewUUID()),
+							UUID:   fakeGPUUUID1,
 							Minor:  pointer.Int32Ptr(1),
 							Health: true,
 							Type:   schedulingv1alpha1.GPU,
@@ -223,6 +226,9 @@ func Test_nodeDeviceCache_onDeviceUpdate(t *testing.T) {
 					},
 					deviceUsed:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 					allocateSet: map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList{},
+					deviceUUIDs: map[schedulingv1alpha1.DeviceType]map[string]int{
+						schedulingv1alpha1.GPU: {fakeGPUUUID1: 1},
+					},
 				},
 			},
 		},
@@ -250,6 +256,7 @@ func Test_nodeDeviceCache_onDeviceUpdate(t *testing.T) {
 					},
 					deviceUsed:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 					allocateSet: map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList{},
+					deviceUUIDs: map[schedulingv1alpha1.DeviceType]map[string]int{},
 				},
 			},
 		},
@@ -343,6 +350,9 @@ func Test_nodeDeviceCache_onDeviceUpdate(t *testing.T) {
 					},
 					deviceUsed:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 					allocateSet: map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList{},
+					deviceUUIDs: map[schedulingv1alpha1.DeviceType]map[string]int{
+						schedulingv1alpha1.GPU: {fakeGPUUUID1: 1},
+					},
 				},
 			},
 		},
@@ -407,6 +417,11 @@ func Test_nodeDeviceCache_onDeviceDelete(t *testing.T) {
 	}
 }
 
+const (
+	fakeGPUUUID0 = "GPU-a8e6c9f1-6b5d-4b8a-9f0e-3f2c1d4e5a60"
+	fakeGPUUUID1 = "GPU-a8e6c9f1-6b5d-4b8a-9f0e-3f2c1d4e5a61"
+)
+
 func generateFakeDevice() *schedulingv1alpha1.Device {
 	return &schedulingv1alpha1.Device{
 		ObjectMeta: metav1.ObjectMeta{
@@ -415,7 +430,7 @@ func generateFakeDevice() *schedulingv1alpha1.Device {
 		Spec: schedulingv1alpha1.DeviceSpec{
 			Devices: []schedulingv1alpha1.DeviceInfo{
 				{
-					UUID:   string(uuid.NewUUID()),
+					UUID:   fakeGPUUUID1,
 					Minor:  pointer.Int32Ptr(1),
 					Health: true,
 					Type:   schedulingv1alpha1.GPU,
@@ -438,7 +453,7 @@ func generateMultipleFakeDevice() *schedulingv1alpha1.Device {
 		Spec: schedulingv1alpha1.DeviceSpec{
 			Devices: []schedulingv1alpha1.DeviceInfo{
 				{
-					UUID:   string(uuid.NewUUID()),
+					UUID:   fakeGPUUUID0,
 					Minor:  pointer.Int32Ptr(0),
 					Health: true,
 					Type:   schedulingv1alpha1.GPU,
@@ -449,7 +464,7 @@ func generateMultipleFakeDevice() *schedulingv1alpha1.Device {
 					},
 				},
 				{
-					UUID:   string(uuid.NewUUID()),
+					UUID:   fakeGPUUUID1,
 					Minor:  pointer.Int32Ptr(1),
 					Health: true,
 					Type:   schedulingv1alpha1.GPU,
@@ -487,6 +502,9 @@ func generateFakeNodeDeviceInfos() map[string]*nodeDevice {
 			},
 			deviceUsed:  map[schedulingv1alpha1.DeviceType]deviceResources{},
 			allocateSet: map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList{},
+			deviceUUIDs: map[schedulingv1alpha1.DeviceType]map[string]int{
+				schedulingv1alpha1.GPU: {fakeGPUUUID1: 1},
+			},
 		},
 	}
 }
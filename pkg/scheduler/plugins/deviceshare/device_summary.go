@@ -17,9 +17,13 @@ limitations under the License.
 package deviceshare
 
 import (
+	"sort"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 )
 
@@ -32,7 +36,31 @@ type NodeDeviceSummary struct {
 	DeviceFreeDetail  map[schedulingv1alpha1.DeviceType]deviceResources `json:"deviceFreeDetail"`
 	DeviceUsedDetail  map[schedulingv1alpha1.DeviceType]deviceResources `json:"deviceUsedDetail"`
 
+	// DeviceEffectiveTotal and DeviceEffectiveTotalDetail are the capacity of the devices overcommitted by the
+	// OvercommitRatios, which the pods overcommitting the devices are allocated with, while DeviceTotal is the
+	// physical capacity. They are set only if the devices are overcommitted.
+	DeviceEffectiveTotal       map[v1.ResourceName]*resource.Quantity            `json:"deviceEffectiveTotal,omitempty"`
+	DeviceEffectiveTotalDetail map[schedulingv1alpha1.DeviceType]deviceResources `json:"deviceEffectiveTotalDetail,omitempty"`
+	OvercommitRatios           map[schedulingv1alpha1.DeviceType]float64         `json:"overcommitRatios,omitempty"`
+
 	AllocateSet map[schedulingv1alpha1.DeviceType]map[string]map[int]v1.ResourceList `json:"allocateSet"`
+
+	// ReserveSucceeded and ReserveFailed count the reserve results on the node in the recent rolling window.
+	ReserveSucceeded int64 `json:"reserveSucceeded"`
+	ReserveFailed    int64 `json:"reserveFailed"`
+
+	// AllocatorPolicy is the allocator policy which produced the current allocations on the node,
+	// and AllocatorPolicyChangedTime is when the node started using the policy.
+	AllocatorPolicy            string       `json:"allocatorPolicy,omitempty"`
+	AllocatorPolicyChangedTime *metav1.Time `json:"allocatorPolicyChangedTime,omitempty"`
+
+	// ReservationRemaining is the devices held by the reservations on the node and not consumed by the pods yet,
+	// by the reservation name.
+	ReservationRemaining map[string]map[schedulingv1alpha1.DeviceType]deviceResources `json:"reservationRemaining,omitempty"`
+
+	// UnschedulableDevices is the minors of the unhealthy or unschedulable devices by the device type, which are
+	// excluded from DeviceFree.
+	UnschedulableDevices map[schedulingv1alpha1.DeviceType][]int `json:"unschedulableDevices,omitempty"`
 }
 
 func NewNodeDeviceSummary() *NodeDeviceSummary {
@@ -46,3 +74,72 @@ func NewNodeDeviceSummary() *NodeDeviceSummary {
 		AllocateSet:       make(map[schedulingv1alpha1.DeviceType]map[string]map[int]v1.ResourceList),
 	}
 }
+
+// NodeLargestSchedulableGPU is the largest GPU request which fits on the node currently.
+type NodeLargestSchedulableGPU struct {
+	// WholeGPUs is the max number of the whole GPUs one pod can request.
+	WholeGPUs int `json:"wholeGPUs"`
+	// LargestShare is the free share of the GPU fitting the largest fractional request, nil if no GPU has free share.
+	LargestShare *GPUShare `json:"largestShare,omitempty"`
+	// GPUs are the free shares of the GPUs having free share, ordered by the minor.
+	GPUs []GPUShare `json:"gpus,omitempty"`
+}
+
+// GPUShare is the free share of a GPU.
+type GPUShare struct {
+	Minor          int                `json:"minor"`
+	GPUCore        int64              `json:"gpuCore"`
+	GPUMemoryRatio int64              `json:"gpuMemoryRatio"`
+	GPUMemory      *resource.Quantity `json:"gpuMemory,omitempty"`
+}
+
+// getLargestSchedulableGPU derives the largest GPU request fitting on the node from the free GPUs. It's computed
+// on demand in the time linear in the number of the GPUs. The GPUs carved into the MIG instances and shared by the time-slicing slots are excluded.
+func (n *nodeDevice) getLargestSchedulableGPU() *NodeLargestSchedulableGPU {
+	result := &NodeLargestSchedulableGPU{}
+	n = n.withoutMIGAndSharedSlotsGPUs()
+	for minor, free := range n.deviceFree[schedulingv1alpha1.GPU] {
+		total := n.deviceTotal[schedulingv1alpha1.GPU][minor]
+		coreTotal, ratioTotal := total[apiext.GPUCore], total[apiext.GPUMemoryRatio]
+		// the unhealthy GPUs report no resources
+		if coreTotal.IsZero() || ratioTotal.IsZero() {
+			continue
+		}
+		core, ratio := free[apiext.GPUCore], free[apiext.GPUMemoryRatio]
+		if core.Sign() <= 0 || ratio.Sign() <= 0 {
+			continue
+		}
+		share := GPUShare{
+			Minor:          minor,
+			GPUCore:        core.Value(),
+			GPUMemoryRatio: ratio.Value(),
+		}
+		if memory, ok := free[apiext.GPUMemory]; ok {
+			memory = memory.DeepCopy()
+			share.GPUMemory = &memory
+		}
+		result.GPUs = append(result.GPUs, share)
+		if core.Cmp(coreTotal) >= 0 && ratio.Cmp(ratioTotal) >= 0 {
+			result.WholeGPUs++
+		}
+	}
+	sort.Slice(result.GPUs, func(i, j int) bool {
+		return result.GPUs[i].Minor < result.GPUs[j].Minor
+	})
+	// the largest fractional request asks for the same share of the cores and the memory
+	for i := range result.GPUs {
+		share := &result.GPUs[i]
+		if result.LargestShare == nil || minInt64(share.GPUCore, share.GPUMemoryRatio) > minInt64(result.LargestShare.GPUCore, result.LargestShare.GPUMemoryRatio) {
+			largest := *share
+			result.LargestShare = &largest
+		}
+	}
+	return result
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+var _ frameworkext.DynamicArgs = &Plugin{}
+
+// DynamicArgs are the fields of DeviceShareArgs which can be reloaded without restarting the scheduler.
+// Each field set replaces the one of the args at the start, and the unset fields keep the args at the start.
+// The allocator and the informers are fixed at the start.
+type DynamicArgs struct {
+	LocalityAffinity   *config.DeviceLocalityAffinity `json:"localityAffinity,omitempty"`
+	MinResourcesPerGPU corev1.ResourceList            `json:"minResourcesPerGPU,omitempty"`
+	ScoringStrategy    *config.ScoringStrategy        `json:"scoringStrategy,omitempty"`
+}
+
+// dynamicArgsState is the decoded dynamic args merged into the args at the start.
+type dynamicArgsState struct {
+	locality           *localityAffinity
+	minResourcesPerGPU corev1.ResourceList
+	scoringStrategy    *config.ScoringStrategy
+}
+
+func (p *Plugin) DecodeDynamicArgs(data []byte) (interface{}, error) {
+	dynamicArgs := &DynamicArgs{}
+	if err := yaml.UnmarshalStrict(data, dynamicArgs); err != nil {
+		return nil, err
+	}
+
+	args := &config.DeviceShareArgs{}
+	if p.initialArgs != nil {
+		args = p.initialArgs.DeepCopy()
+	}
+	if dynamicArgs.LocalityAffinity != nil {
+		args.LocalityAffinity = dynamicArgs.LocalityAffinity
+	}
+	if dynamicArgs.MinResourcesPerGPU != nil {
+		args.MinResourcesPerGPU = dynamicArgs.MinResourcesPerGPU
+	}
+	if dynamicArgs.ScoringStrategy != nil {
+		args.ScoringStrategy = dynamicArgs.ScoringStrategy
+	}
+	if err := validation.ValidateDeviceShareArgs(args); err != nil {
+		return nil, err
+	}
+	locality, err := newLocalityAffinity(args.LocalityAffinity)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamicArgsState{
+		locality:           locality,
+		minResourcesPerGPU: args.MinResourcesPerGPU,
+		scoringStrategy:    args.ScoringStrategy,
+	}, nil
+}
+
+func (p *Plugin) UpdateDynamicArgs(args interface{}) {
+	state, ok := args.(*dynamicArgsState)
+	if !ok {
+		return
+	}
+	p.argsLock.Lock()
+	defer p.argsLock.Unlock()
+	p.locality = state.locality
+	p.minResourcesPerGPU = state.minResourcesPerGPU
+	p.scoringStrategy = state.scoringStrategy
+}
+
+// getDynamicArgs returns the current locality affinity, minimum resources per GPU and scoring strategy.
+func (p *Plugin) getDynamicArgs() dynamicArgsState {
+	p.argsLock.RLock()
+	defer p.argsLock.RUnlock()
+	return dynamicArgsState{
+		locality:           p.locality,
+		minResourcesPerGPU: p.minResourcesPerGPU,
+		scoringStrategy:    p.scoringStrategy,
+	}
+}
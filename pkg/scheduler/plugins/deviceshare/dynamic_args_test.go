@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func TestPlugin_DynamicArgs(t *testing.T) {
+	p := &Plugin{
+		initialArgs: &config.DeviceShareArgs{
+			MinResourcesPerGPU: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+		},
+		minResourcesPerGPU: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+	}
+	podRequest := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("6")}
+	gpuRequest := corev1.ResourceList{apiext.GPUCore: resource.MustParse("200")}
+	assert.False(t, p.checkMinResourcesPerGPU(podRequest, gpuRequest).IsSuccess())
+
+	args, err := p.DecodeDynamicArgs([]byte(`
+minResourcesPerGPU:
+  cpu: "2"
+localityAffinity:
+  podSelector:
+    matchLabels:
+      app: producer
+`))
+	assert.NoError(t, err)
+	p.UpdateDynamicArgs(args)
+	assert.True(t, p.checkMinResourcesPerGPU(podRequest, gpuRequest).IsSuccess())
+	assert.NotNil(t, p.getDynamicArgs().locality)
+
+	// the non-dynamic fields and the invalid values are rejected
+	_, err = p.DecodeDynamicArgs([]byte(`allocator: default`))
+	assert.Error(t, err)
+	_, err = p.DecodeDynamicArgs([]byte(`
+minResourcesPerGPU:
+  nvidia.com/gpu: "1"
+`))
+	assert.Error(t, err)
+	assert.True(t, p.checkMinResourcesPerGPU(podRequest, gpuRequest).IsSuccess())
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+// overcommitPodSelector selects the pods allocated with the overcommitted capacity of the devices by the
+// koordinator priority class or the QoS class.
+type overcommitPodSelector struct {
+	priorityClasses sets.String
+	qosClasses      sets.String
+}
+
+// newOvercommitPodSelector returns nil if the devices are not overcommitted.
+func newOvercommitPodSelector(args *config.DeviceShareArgs) *overcommitPodSelector {
+	if len(args.OvercommitRatios) == 0 {
+		return nil
+	}
+	selector := &overcommitPodSelector{
+		priorityClasses: sets.NewString(),
+		qosClasses:      sets.NewString(),
+	}
+	for _, priorityClass := range args.OvercommitPriorityClasses {
+		selector.priorityClasses.Insert(string(priorityClass))
+	}
+	for _, qosClass := range args.OvercommitQoSClasses {
+		selector.qosClasses.Insert(string(qosClass))
+	}
+	return selector
+}
+
+func (s *overcommitPodSelector) matches(pod *corev1.Pod) bool {
+	if s == nil {
+		return false
+	}
+	if priorityClass := apiext.GetPriorityClass(pod); priorityClass != apiext.PriorityNone && s.priorityClasses.Has(string(priorityClass)) {
+		return true
+	}
+	if qosClass := apiext.GetPodQoSClass(pod); qosClass != apiext.QoSNone && s.qosClasses.Has(string(qosClass)) {
+		return true
+	}
+	return false
+}
+
+// scaleDeviceResources multiplies the resources of a device by the overcommit ratio, rounded down.
+func scaleDeviceResources(resources corev1.ResourceList, ratio float64) corev1.ResourceList {
+	scaled := make(corev1.ResourceList, len(resources))
+	for resourceName, quantity := range resources {
+		scaled[resourceName] = *resource.NewQuantity(int64(math.Floor(float64(quantity.Value())*ratio)), quantity.Format)
+	}
+	return scaled
+}
+
+// getOvercommittedCapacity returns the capacity of a device overcommitted by the ratios of the node devices. The GPUs
+// carved into the MIG instances are allocated by the partitions, and the GPUs shared by the time-slicing slots are
+// allocated by the slot indexes, so they are never overcommitted.
+func getOvercommittedCapacity(ratios map[schedulingv1alpha1.DeviceType]float64, deviceType schedulingv1alpha1.DeviceType, capacity corev1.ResourceList) corev1.ResourceList {
+	ratio, ok := ratios[deviceType]
+	if !ok || ratio <= 1 || len(getMIGRequest(capacity)) > 0 || isSharedSlotsGPU(capacity) {
+		return capacity
+	}
+	return scaleDeviceResources(capacity, ratio)
+}
+
+// getOvercommittedTotal returns the total resources of the devices of the type overcommitted by the ratios.
+func (n *nodeDevice) getOvercommittedTotal(ratios map[schedulingv1alpha1.DeviceType]float64, deviceType schedulingv1alpha1.DeviceType) deviceResources {
+	total := make(deviceResources, len(n.deviceTotal[deviceType]))
+	for minor, capacity := range n.deviceTotal[deviceType] {
+		total[minor] = getOvercommittedCapacity(ratios, deviceType, capacity)
+	}
+	return total
+}
+
+// getPhysicalTotal returns the total resources of the devices of the type reported by the Device, which the
+// gpu-memory and gpu-memory-ratio of the requests are converted by even if the devices are overcommitted.
+func (n *nodeDevice) getPhysicalTotal(deviceType schedulingv1alpha1.DeviceType) deviceResources {
+	if total, ok := n.physicalTotal[deviceType]; ok {
+		return total
+	}
+	return n.deviceTotal[deviceType]
+}
+
+// withOvercommittedDevices returns the view of the node devices in which the capacity of the devices is multiplied
+// by the overcommit ratios, and the free resources are raised by the extra capacity.
+func (n *nodeDevice) withOvercommittedDevices(ratios map[schedulingv1alpha1.DeviceType]float64) *nodeDevice {
+	deviceTotal := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceTotal))
+	for deviceType, resources := range n.deviceTotal {
+		deviceTotal[deviceType] = resources
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	for deviceType, ratio := range ratios {
+		if ratio <= 1 || len(n.deviceTotal[deviceType]) == 0 {
+			continue
+		}
+		total := n.getOvercommittedTotal(ratios, deviceType)
+		free := make(deviceResources, len(n.deviceFree[deviceType]))
+		for minor, resources := range n.deviceFree[deviceType] {
+			used := n.deviceUsed[deviceType][minor]
+			extra := quotav1.Subtract(
+				quotav1.SubtractWithNonNegativeResult(total[minor], used),
+				quotav1.SubtractWithNonNegativeResult(n.deviceTotal[deviceType][minor], used),
+			)
+			free[minor] = quotav1.Add(resources, quotav1.Mask(extra, quotav1.ResourceNames(resources)))
+		}
+		deviceTotal[deviceType] = total
+		deviceFree[deviceType] = free
+	}
+	view := n.shallowCopy()
+	view.deviceTotal = deviceTotal
+	view.physicalTotal = n.deviceTotal
+	view.deviceFree = deviceFree
+	return view
+}
+
+// withOvercommittedDevices returns the view of the node devices with the overcommitted capacity if the pod is
+// selected to overcommit the devices.
+func (p *Plugin) withOvercommittedDevices(pod *corev1.Pod, info *nodeDevice) *nodeDevice {
+	if !p.overcommitPods.matches(pod) {
+		return info
+	}
+	return info.withOvercommittedDevices(p.nodeDeviceCache.overcommitRatios)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func newOvercommitTestPlugin(reconcileStrategy config.DeviceReconcileStrategy, gpus int) (*Plugin, *framework.NodeInfo) {
+	args := &config.DeviceShareArgs{
+		OvercommitRatios: map[schedulingv1alpha1.DeviceType]float64{
+			schedulingv1alpha1.GPU: 2,
+		},
+		OvercommitPriorityClasses: []apiext.PriorityClass{apiext.PriorityBatch},
+		OvercommitQoSClasses:      []apiext.QoSClass{apiext.QoSBE},
+	}
+	deviceCache := newNodeDeviceCache()
+	deviceCache.reconcileStrategy = reconcileStrategy
+	deviceCache.overcommitRatios = args.OvercommitRatios
+	gpuTotal := deviceResources{}
+	for minor := 0; minor < gpus; minor++ {
+		gpuTotal[minor] = corev1.ResourceList{
+			apiext.GPUCore:        resource.MustParse("100"),
+			apiext.GPUMemoryRatio: resource.MustParse("100"),
+			apiext.GPUMemory:      resource.MustParse("16Gi"),
+		}
+	}
+	deviceCache.createNodeDevice("test-node-1").resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: gpuTotal,
+	})
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"}})
+	return &Plugin{
+		nodeDeviceCache: deviceCache,
+		allocator:       &defaultAllocator{},
+		overcommitPods:  newOvercommitPodSelector(args),
+	}, nodeInfo
+}
+
+func newOvercommitTestPod(name string, priority int32) *corev1.Pod {
+	pod := newWaitlistTestPod(name)
+	pod.Spec.Priority = pointer.Int32(priority)
+	return pod
+}
+
+// bindOvercommitTestPod runs Filter and Reserve of the pod requesting gpuCore, and returns the allocations.
+func bindOvercommitTestPod(t *testing.T, p *Plugin, nodeInfo *framework.NodeInfo, pod *corev1.Pod, gpuCore int64) (apiext.DeviceAllocations, bool) {
+	cycleState := newWaitlistTestCycleState(gpuCore)
+	if !p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess() {
+		assert.False(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess(), "pod %s", pod.Name)
+		return nil, false
+	}
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess(), "pod %s", pod.Name)
+	state, _ := getPreFilterState(cycleState)
+	return state.allocationResult, true
+}
+
+func TestOvercommitPodSelector(t *testing.T) {
+	selector := newOvercommitPodSelector(&config.DeviceShareArgs{
+		OvercommitRatios:          map[schedulingv1alpha1.DeviceType]float64{schedulingv1alpha1.GPU: 2},
+		OvercommitPriorityClasses: []apiext.PriorityClass{apiext.PriorityBatch},
+		OvercommitQoSClasses:      []apiext.QoSClass{apiext.QoSBE},
+	})
+	assert.True(t, selector.matches(newOvercommitTestPod("batch-pod", 5500)))
+	assert.False(t, selector.matches(newOvercommitTestPod("prod-pod", 9500)))
+	bePod := newOvercommitTestPod("be-pod", 9500)
+	bePod.Labels = map[string]string{apiext.LabelPodQoS: string(apiext.QoSBE)}
+	assert.True(t, selector.matches(bePod))
+	assert.False(t, selector.matches(newWaitlistTestPod("pod-without-priority")))
+
+	assert.Nil(t, newOvercommitPodSelector(&config.DeviceShareArgs{}))
+	var disabled *overcommitPodSelector
+	assert.False(t, disabled.matches(newOvercommitTestPod("batch-pod", 5500)))
+}
+
+func TestOvercommitGPUs(t *testing.T) {
+	p, nodeInfo := newOvercommitTestPlugin(config.DeviceReconcileConservativeMin, 1)
+
+	allocations, ok := bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("batch-pod-1", 5500), 100)
+	assert.True(t, ok)
+	// the gpu-memory is converted by the physical memory of the GPU
+	assert.Equal(t, resource.MustParse("16Gi"), allocations[schedulingv1alpha1.GPU][0].Resources[apiext.GPUMemory])
+
+	// the production pods see the physical capacity
+	_, ok = bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("prod-pod", 9500), 50)
+	assert.False(t, ok)
+
+	_, ok = bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("batch-pod-2", 5500), 100)
+	assert.True(t, ok)
+	_, ok = bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("batch-pod-3", 5500), 50)
+	assert.False(t, ok)
+
+	summary, ok := p.getNodeDeviceSummary("test-node-1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), summary.DeviceTotal[apiext.GPUCore].Value())
+	assert.Equal(t, int64(200), summary.DeviceEffectiveTotal[apiext.GPUCore].Value())
+	assert.Equal(t, int64(200), summary.DeviceUsed[apiext.GPUCore].Value())
+	effectiveGPUMemory := summary.DeviceEffectiveTotalDetail[schedulingv1alpha1.GPU][0][apiext.GPUMemory]
+	assert.Equal(t, int64(32*1024*1024*1024), effectiveGPUMemory.Value())
+	assert.Equal(t, map[schedulingv1alpha1.DeviceType]float64{schedulingv1alpha1.GPU: 2}, summary.OvercommitRatios)
+}
+
+func TestOvercommitGPUsNotReconciledAsUnaccounted(t *testing.T) {
+	p, nodeInfo := newOvercommitTestPlugin(config.DeviceReconcileConservativeMin, 2)
+
+	for _, name := range []string{"batch-pod-1", "batch-pod-2"} {
+		allocations, ok := bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod(name, 5500), 100)
+		assert.True(t, ok)
+		assert.Equal(t, int32(0), allocations[schedulingv1alpha1.GPU][0].Minor)
+	}
+	// the GPU 0 is used beyond its physical capacity, which is not deducted from the GPU 1
+	allocations, ok := bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("prod-pod", 9500), 100)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+}
+
+func TestWithOvercommittedDevices(t *testing.T) {
+	p, _ := newOvercommitTestPlugin(config.DeviceReconcileConservativeMin, 1)
+	nodeDevice := p.nodeDeviceCache.getNodeDevice("test-node-1")
+	nodeDevice.gpuCoreGranularity = 10
+
+	view := nodeDevice.withOvercommittedDevices(p.nodeDeviceCache.overcommitRatios)
+	assert.NotSame(t, nodeDevice, view)
+	assert.Equal(t, int64(10), view.gpuCoreGranularity)
+	gpuCore := view.deviceFree[schedulingv1alpha1.GPU][0][apiext.GPUCore]
+	assert.Equal(t, int64(200), gpuCore.Value())
+	gpuCore = view.getPhysicalTotal(schedulingv1alpha1.GPU)[0][apiext.GPUCore]
+	assert.Equal(t, int64(100), gpuCore.Value())
+	// the node devices are not changed
+	gpuCore = nodeDevice.deviceFree[schedulingv1alpha1.GPU][0][apiext.GPUCore]
+	assert.Equal(t, int64(100), gpuCore.Value())
+	// the views derived from the overcommitted view keep the physical capacity
+	assert.Equal(t, view.physicalTotal, view.withUnusedGPUsOnly().physicalTotal)
+}
+
+func TestOvercommitGPUsInAllocationCooldown(t *testing.T) {
+	p, nodeInfo := newOvercommitTestPlugin(config.DeviceReconcileConservativeMin, 1)
+	fakeClock := clock.NewFakeClock(time.Now())
+	p.nodeDeviceCache.clock = fakeClock
+	p.nodeDeviceCache.allocationCooldowns = map[schedulingv1alpha1.DeviceType]time.Duration{
+		schedulingv1alpha1.GPU: time.Minute,
+	}
+
+	var pods []*corev1.Pod
+	for _, name := range []string{"batch-pod-1", "batch-pod-2"} {
+		pod := newOvercommitTestPod(name, 5500)
+		allocations, ok := bindOvercommitTestPod(t, p, nodeInfo, pod, 100)
+		assert.True(t, ok)
+		assert.NoError(t, apiext.SetDeviceAllocations(pod, allocations))
+		pod.Spec.NodeName = "test-node-1"
+		pods = append(pods, pod)
+	}
+	for _, pod := range pods {
+		p.nodeDeviceCache.deletePod(pod)
+	}
+
+	// the overcommitted view keeps the releases, so the cooling GPU stays excluded
+	_, ok := bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("batch-pod-3", 5500), 100)
+	assert.False(t, ok)
+
+	fakeClock.Step(time.Minute)
+	_, ok = bindOvercommitTestPod(t, p, nodeInfo, newOvercommitTestPod("batch-pod-3", 5500), 100)
+	assert.True(t, ok)
+}
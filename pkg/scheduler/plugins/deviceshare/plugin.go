@@ -18,20 +18,31 @@ package deviceshare
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
-	"k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	listerschedulingv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
@@ -48,80 +59,229 @@ const (
 
 	// ErrInsufficientDevices when node can't satisfy Pod's requested resource.
 	ErrInsufficientDevices = "Insufficient Devices"
+
+	// ErrFractionalGPUUnsupported when node only supports whole GPUs but Pod requests a part of a GPU.
+	ErrFractionalGPUUnsupported = "node(s) only support whole GPUs"
+
+	// ErrUnmetGPUComputeCapability when node has no GPUs of the minimum compute capability required by Pod.
+	ErrUnmetGPUComputeCapability = "node(s) didn't have GPUs of the required compute capability"
+
+	// ErrUnmetGPUModel when node has no GPUs of the models allowed by Pod.
+	ErrUnmetGPUModel = "node(s) didn't have GPUs of the requested models"
+
+	// ErrUnmetGPUExclusive when node has no GPUs used by no pod for the Pod demanding the GPUs exclusively.
+	ErrUnmetGPUExclusive = "node(s) didn't have GPUs free of other pods"
+
+	// ErrUnalignedNUMADevices when node can't allocate the GPUs and RDMA NICs requested by Pod on the same NUMA node
+	// with the Restricted NUMATopologyPolicy.
+	ErrUnalignedNUMADevices = "node(s) didn't have the requested GPUs and RDMA devices on the same NUMA node"
+
+	// ErrUnalignedNUMAGPUs when node can't allocate the GPUs requested by Pod on the same NUMA node with the
+	// Restricted NUMATopologyPolicy of the Pod.
+	ErrUnalignedNUMAGPUs = "node(s) didn't have the requested GPUs on the same NUMA node"
+
+	// ErrUnalignedGPUTopology when node can't allocate the whole GPUs requested by Pod connected by the closest
+	// topology it could offer with the Restricted GPUTopologyPolicy.
+	ErrUnalignedGPUTopology = "node(s) didn't have the requested GPUs connected by the required topology"
+
+	// ErrMissingRDMADevice when node has no RDMA device for Pod requesting RDMA.
+	ErrMissingRDMADevice = "node(s) didn't have RDMA devices"
+
+	// ErrRDMAVFsExhausted when the RDMA devices of node don't have enough free virtual functions for Pod.
+	ErrRDMAVFsExhausted = "node(s) didn't have enough free RDMA virtual functions"
+
+	// ErrUnalignedJointDevices when node can't allocate the devices requested by Pod jointly under the same
+	// PCIe switch.
+	ErrUnalignedJointDevices = "node(s) didn't have the requested devices under the same PCIe switch"
 )
 
 type Plugin struct {
 	handle          framework.Handle
 	nodeDeviceCache *nodeDeviceCache
 	allocator       Allocator
+	podLister       corelisters.PodLister
+	deviceLister    listerschedulingv1alpha1.DeviceLister
+	// reservationLister lists the reservations holding the devices for the consistency check, nil if not served.
+	reservationLister listerschedulingv1alpha1.ReservationLister
+	// initialArgs is the args at the start, the dynamic args override the fields of it.
+	initialArgs *config.DeviceShareArgs
+	// argsLock guards the fields swapped by the dynamic args, i.e. locality, minResourcesPerGPU and scoringStrategy.
+	argsLock sync.RWMutex
+	locality *localityAffinity
+	// minResourcesPerGPU is the minimum CPU and memory requests for each requested GPU.
+	minResourcesPerGPU corev1.ResourceList
+	// scoringStrategy scores the nodes by the allocated device resources, nil if not scored by them.
+	scoringStrategy *config.ScoringStrategy
+	// preBindPatchBackoff is the backoff to retry patching the pod in PreBind.
+	preBindPatchBackoff *wait.Backoff
+	// waitlist keeps the GPUs held by the large pending pods, nil if disabled.
+	waitlist *deviceWaitlist
+	// tracer traces the device allocation in the extension points, no-op if no tracer provider is set.
+	tracer trace.Tracer
+	// allocationReporter reports the allocation results of the bound pods by events, nil if no event recorder.
+	allocationReporter *allocationReporter
+	// schedulingEvents emits the events of the device filtering failures, nil if no event recorder.
+	schedulingEvents *schedulingEventRecorder
+	// enablePreemption lets the pod lacking devices preempt the lower priority pods using the devices in PostFilter.
+	enablePreemption bool
+	// pdbLister lists the PodDisruptionBudgets respected by the preemption, nil if not served.
+	pdbLister policylisters.PodDisruptionBudgetLister
+	// overcommitPods selects the pods allocated with the overcommitted capacity of the devices, nil if the devices
+	// are not overcommitted.
+	overcommitPods *overcommitPodSelector
 }
 
 var (
-	_ framework.PreFilterPlugin = &Plugin{}
-	_ framework.FilterPlugin    = &Plugin{}
-	_ framework.ReservePlugin   = &Plugin{}
-	_ framework.PreBindPlugin   = &Plugin{}
+	_ framework.PreFilterPlugin     = &Plugin{}
+	_ framework.PreFilterExtensions = &Plugin{}
+	_ framework.FilterPlugin        = &Plugin{}
+	_ framework.PostFilterPlugin    = &Plugin{}
+	_ framework.ReservePlugin       = &Plugin{}
+	_ framework.PreBindPlugin       = &Plugin{}
+	_ framework.PostBindPlugin      = &Plugin{}
+	_ framework.PreScorePlugin      = &Plugin{}
+	_ framework.ScorePlugin         = &Plugin{}
+	_ framework.ScoreExtensions     = &Plugin{}
+
+	_ frameworkext.ConsistencyCheckable = &Plugin{}
 )
 
+// errDeviceAllocationsMismatch when the device allocations returned by the patch of the pod in PreBind are not
+// the allocated ones.
+var errDeviceAllocationsMismatch = errors.New("the device allocations of the pod are not patched as expected")
+
 type preFilterState struct {
 	skip                    bool
 	allocationResult        apiext.DeviceAllocations
 	convertedDeviceResource corev1.ResourceList
+	// gpuMinComputeCapability is the minimum compute capability of the GPUs required by the pod, nil if no minimum.
+	gpuMinComputeCapability *apiext.GPUComputeCapability
+	// gpuModelSelector is the models of the GPUs allowed by the pod, nil if any model is allowed.
+	gpuModelSelector apiext.GPUModelSelector
+	// gpuExclusive is whether the pod demands the GPUs not shared with the other pods.
+	gpuExclusive bool
+	// allocatedTopology is the topology of the allocated devices, nil if the devices do not report the topology.
+	allocatedTopology apiext.DeviceAllocatedTopology
+	// nodeDeviceDeltas are the devices of the pods removed or added by AddPod and RemovePod, keyed by the node name.
+	nodeDeviceDeltas map[string]*nodeDeviceDelta
+	// reservationUID is the UID of the reserve pod of the reservation the devices are allocated from in Reserve,
+	// and reservedAllocations is the devices carved out of the reservation, the rest are the free devices.
+	reservationUID      types.UID
+	reservedAllocations apiext.DeviceAllocations
 }
 
 func (s *preFilterState) Clone() framework.StateData {
-	return s
+	out := *s
+	if s.nodeDeviceDeltas != nil {
+		out.nodeDeviceDeltas = make(map[string]*nodeDeviceDelta, len(s.nodeDeviceDeltas))
+		for nodeName, delta := range s.nodeDeviceDeltas {
+			out.nodeDeviceDeltas[nodeName] = delta.clone()
+		}
+	}
+	return &out
 }
 
 func (p *Plugin) Name() string {
 	return Name
 }
 
-func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) (status *framework.Status) {
+	_, span := p.startSpan(ctx, "PreFilter", pod, "")
+	defer func() { p.endSpan(span, cycleState, status) }()
+
 	state := &preFilterState{
 		skip:                    true,
 		convertedDeviceResource: make(corev1.ResourceList),
 	}
 
-	podRequest, _ := resource.PodRequestsAndLimits(pod)
+	podRequest := util.GetPodEffectiveRequest(pod)
+	resourceNames := p.nodeDeviceCache.getResourceNames()
 
-	for deviceType := range DeviceResourceNames {
-		switch deviceType {
-		case schedulingv1alpha1.GPU:
-			if !hasDeviceResource(podRequest, deviceType) {
-				break
-			}
-			combination, err := ValidateGPURequest(podRequest)
-			if err != nil {
-				return framework.NewStatus(framework.Error, err.Error())
-			}
-			state.convertedDeviceResource = quotav1.Add(
-				state.convertedDeviceResource,
-				ConvertGPUResource(podRequest, combination),
-			)
-			state.skip = false
-		case schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA:
-			if !hasDeviceResource(podRequest, deviceType) {
-				break
-			}
-			if err := validateCommonDeviceRequest(podRequest, deviceType); err != nil {
-				return framework.NewStatus(framework.Error, err.Error())
-			}
-			state.convertedDeviceResource = quotav1.Add(
-				state.convertedDeviceResource,
-				convertCommonDeviceResource(podRequest, deviceType),
-			)
-			state.skip = false
-		default:
-			klog.Warningf("device type %v is not supported yet, pod: %v", deviceType, klog.KObj(pod))
+	gpuModelSelector, err := apiext.GetGPUModelSelector(pod.Annotations)
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("invalid GPU model selector: %v", err))
+	}
+	if gpuModelSelector != nil && !resourceNames.hasDeviceResource(podRequest, schedulingv1alpha1.GPU) {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("GPU model %s is selected but no GPU is requested", gpuModelSelector))
+	}
+	gpuExclusive := apiext.IsGPUExclusive(pod.Annotations)
+	if gpuExclusive && !resourceNames.hasDeviceResource(podRequest, schedulingv1alpha1.GPU) {
+		return framework.NewStatus(framework.Error, "GPU exclusive is demanded but no GPU is requested")
+	}
+
+	deviceRequest, gpuRequest, err := resourceNames.convertDeviceRequest(podRequest)
+	if err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	if gpuRequest != nil {
+		if status := p.checkMinResourcesPerGPU(podRequest, gpuRequest); !status.IsSuccess() {
+			return status
+		}
+		minComputeCapability, err := apiext.GetGPUMinComputeCapability(pod.Annotations)
+		if err != nil {
+			return framework.NewStatus(framework.Error, fmt.Sprintf("invalid GPU minimum compute capability: %v", err))
+		}
+		state.gpuMinComputeCapability = minComputeCapability
+		state.gpuModelSelector = gpuModelSelector
+		state.gpuExclusive = gpuExclusive
+	}
+	if len(deviceRequest) > 0 {
+		state.convertedDeviceResource = deviceRequest
+		state.skip = false
+	}
+
+	if !state.skip {
+		hint, err := apiext.GetDeviceOrderingHint(pod.Annotations)
+		if err != nil {
+			return framework.NewStatus(framework.Error, fmt.Sprintf("invalid device ordering hint: %v", err))
+		}
+		if err := resourceNames.validateDeviceOrderingHint(hint, state.convertedDeviceResource); err != nil {
+			return framework.NewStatus(framework.Error, err.Error())
+		}
+		if uuid := apiext.GetGPUTargetUUID(pod.Annotations); uuid != "" && isMultipleGPUPod(state.convertedDeviceResource) {
+			return framework.NewStatus(framework.Error, fmt.Sprintf("multiple GPUs cannot be placed on GPU %s", uuid))
+		}
+		jointAllocate, err := apiext.GetDeviceJointAllocate(pod.Annotations)
+		if err != nil {
+			return framework.NewStatus(framework.Error, fmt.Sprintf("invalid device joint allocate: %v", err))
+		}
+		if err := resourceNames.validateDeviceJointAllocate(jointAllocate, state.convertedDeviceResource); err != nil {
+			return framework.NewStatus(framework.Error, err.Error())
 		}
 	}
 
 	cycleState.Write(stateKey, state)
+	if !state.skip {
+		frameworkext.SetDeviceNUMAHint(cycleState, &frameworkext.DeviceNUMAHint{})
+		cycleState.Write(filterFailuresKey, newDeviceFilterFailures())
+	}
 	return nil
 }
 
-func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
+// checkMinResourcesPerGPU rejects the pod if it requests less CPU or memory than the configured minimum
+// for the GPUs it requests.
+func (p *Plugin) checkMinResourcesPerGPU(podRequest, gpuRequest corev1.ResourceList) *framework.Status {
+	minResourcesPerGPU := p.getDynamicArgs().minResourcesPerGPU
+	if len(minResourcesPerGPU) == 0 {
+		return nil
+	}
+	gpuCount := DeviceResourceNames.getDeviceCount(schedulingv1alpha1.GPU, gpuRequest)
+	var reasons []string
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		minPerGPU, ok := minResourcesPerGPU[resourceName]
+		if !ok || minPerGPU.IsZero() {
+			continue
+		}
+		required := scaleQuantity(minPerGPU, gpuCount)
+		requested := podRequest[resourceName]
+		if requested.Cmp(required) < 0 {
+			reasons = append(reasons, fmt.Sprintf("Insufficient %s requests for %d GPU(s), requested %s, at least %s required",
+				resourceName, gpuCount, requested.String(), required.String()))
+		}
+	}
+	if len(reasons) > 0 {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, reasons...)
+	}
 	return nil
 }
 
@@ -134,7 +294,14 @@ func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, *fram
 	return state, nil
 }
 
-func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	var nodeName string
+	if nodeInfo.Node() != nil {
+		nodeName = nodeInfo.Node().Name
+	}
+	ctx, span := p.startSpan(ctx, "Filter", pod, nodeName)
+	defer func() { p.endSpan(span, cycleState, status) }()
+
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -142,6 +309,7 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	if state.skip {
 		return nil
 	}
+	defer func() { getDeviceFilterFailures(cycleState).add(nodeName, status) }()
 
 	if nodeInfo.Node() == nil {
 		return framework.NewStatus(framework.Error, "node not found")
@@ -153,19 +321,168 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	}
 
 	podRequest := state.convertedDeviceResource
+	if apiext.IsNodeGPUWholeOnly(nodeInfo.Node().Labels) && isFractionalGPURequest(podRequest) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrFractionalGPUUnsupported)
+	}
 
 	nodeDeviceInfo.lock.RLock()
 	defer nodeDeviceInfo.lock.RUnlock()
 
-	allocateResult, err := p.allocator.Allocate(nodeInfo.Node().Name, pod, podRequest, nodeDeviceInfo)
+	if minComputeCapability := state.gpuMinComputeCapability; minComputeCapability != nil &&
+		!nodeDeviceInfo.hasGPUsOfComputeCapability(*minComputeCapability) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUComputeCapability)
+	}
+	if state.gpuModelSelector != nil && !nodeDeviceInfo.hasGPUsOfModels(state.gpuModelSelector) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUModel)
+	}
+	if nodeDeviceInfo.getResourceNames().hasDeviceResource(podRequest, schedulingv1alpha1.RDMA) && len(nodeDeviceInfo.deviceTotal[schedulingv1alpha1.RDMA]) == 0 {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingRDMADevice)
+	}
+
+	baseDevice := nodeDeviceInfo.withDelta(state.nodeDeviceDeltas[nodeName])
+	nodeDevice := p.nodeDeviceCache.withoutCoolingDevices(p.withOvercommittedDevices(pod, baseDevice)).withoutFreeGPUs(p.waitlist.heldGPUs(nodeInfo.Node().Name, pod))
+	if state.gpuModelSelector != nil {
+		// the node may mix the GPU models, only the GPUs of the allowed models are allocated
+		nodeDevice = nodeDevice.withGPUsOfModels(state.gpuModelSelector)
+	}
+	// the devices held by the reservations matching the pod are available to it besides the free devices
+	nominated := baseDevice.getNominatedReservedDevices(pod, frameworkext.GetReservationNomination(cycleState).GetMatchedOnNode(nodeName))
+	allocateResult, _, _, err := p.allocateDevices(ctx, nodeName, pod, state, nominated, baseDevice, nodeDevice)
 	if len(allocateResult) != 0 && err == nil {
+		if hint := frameworkext.GetDeviceNUMAHint(cycleState); hint != nil {
+			// NodeNUMAResource filters the CPUs near the devices allocated tentatively
+			hint.SetFilteredNUMANodes(nodeName, nodeDeviceInfo.getAllocatedNUMANodes(allocateResult))
+		}
 		return nil
 	}
+	if errors.Is(err, errUnmetGPUExclusive) {
+		return framework.NewStatus(framework.Unschedulable, ErrUnmetGPUExclusive)
+	}
+	if errors.Is(err, errUnalignedNUMADevices) {
+		return framework.NewStatus(framework.Unschedulable, ErrUnalignedNUMADevices)
+	}
+	if errors.Is(err, errUnalignedNUMAGPUs) {
+		return framework.NewStatus(framework.Unschedulable, ErrUnalignedNUMAGPUs)
+	}
+	if errors.Is(err, errUnalignedGPUTopology) {
+		return framework.NewStatus(framework.Unschedulable, ErrUnalignedGPUTopology)
+	}
+	if errors.Is(err, errRDMAVFsExhausted) {
+		return framework.NewStatus(framework.Unschedulable, ErrRDMAVFsExhausted)
+	}
+	if errors.Is(err, errUnalignedJointDevices) {
+		return framework.NewStatus(framework.Unschedulable, ErrUnalignedJointDevices)
+	}
+	_, hasGPUCore := podRequest[apiext.GPUCore]
+	_, hasGPUMemoryRatio := podRequest[apiext.GPUMemoryRatio]
+	if hasGPUCore && hasGPUMemoryRatio {
+		if resourceName, exhausted := nodeDevice.getExhaustedGPUResource(podRequest); exhausted {
+			return framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices, fmt.Sprintf("Insufficient %v", resourceName))
+		}
+	}
 
 	return framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices)
 }
 
-func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+// allocateDevices allocates the devices requested by the pod from the reservations nominated to it first, and from
+// the free devices of nodeDevice otherwise. The allocation from the free devices fails with errUnmetGPUExclusive if
+// the pod demands the exclusive GPUs and no GPU is unused.
+func (p *Plugin) allocateDevices(ctx context.Context, nodeName string, pod *corev1.Pod, state *preFilterState, nominated []*reservedDevices, baseDevice *nodeDevice, nodeDevice *nodeDevice) (apiext.DeviceAllocations, apiext.DeviceAllocations, types.UID, error) {
+	if allocateResult, reservedAllocations, reservationUID := p.allocateFromReservations(ctx, nodeName, pod, state, nominated, baseDevice, nodeDevice); len(allocateResult) != 0 {
+		return allocateResult, reservedAllocations, reservationUID, nil
+	}
+	if state.gpuExclusive {
+		if !nodeDevice.hasUnusedGPUs() {
+			return nil, nil, "", errUnmetGPUExclusive
+		}
+		nodeDevice = nodeDevice.withUnusedGPUsOnly()
+	}
+	allocateResult, err := p.allocate(ctx, nodeName, pod, state.convertedDeviceResource, nodeDevice)
+	return allocateResult, nil, "", err
+}
+
+// PostFilter nominates the node on which the lower priority pods using the devices are preempted for the pod,
+// if the preemption is enabled. Otherwise, it lets the pod requesting many whole GPUs hold the GPUs of the node
+// with the most free GPUs among the nodes lacking devices, if the waitlist is enabled. The pod is still
+// unschedulable in this cycle.
+func (p *Plugin) PostFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	if state, status := getPreFilterState(cycleState); status.IsSuccess() && !state.skip {
+		p.schedulingEvents.filterFailed(pod, state.convertedDeviceResource, getDeviceFilterFailures(cycleState))
+	}
+	if p.enablePreemption {
+		state, status := getPreFilterState(cycleState)
+		if !status.IsSuccess() {
+			return nil, status
+		}
+		if !state.skip {
+			nominatedNodeName, status := p.preempt(ctx, cycleState, pod, filteredNodeStatusMap)
+			if !status.IsSuccess() {
+				// an Error stops the PostFilter plugins after DeviceShare, e.g. the DefaultPreemption
+				klog.V(4).InfoS("Failed to preempt the devices", "pod", klog.KObj(pod), "status", status.Message())
+				return nil, framework.NewStatus(framework.Unschedulable, status.Message())
+			}
+			if nominatedNodeName != "" {
+				return &framework.PostFilterResult{NominatedNodeName: nominatedNodeName}, framework.NewStatus(framework.Success)
+			}
+		}
+	}
+	if p.waitlist == nil {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return nil, status
+	}
+	gpuWanted := getWholeGPUCount(state.convertedDeviceResource)
+	if state.skip || gpuWanted < p.waitlist.minGPUs {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	if nodeName := p.waitlist.holdingNode(pod); nodeName != "" {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("pod is holding GPUs of node %s", nodeName))
+	}
+
+	var candidate string
+	candidateFree := -1
+	for nodeName, nodeStatus := range filteredNodeStatusMap {
+		if nodeStatus.Code() != framework.Unschedulable || !nodeStatusHasReason(nodeStatus, ErrInsufficientDevices) {
+			continue
+		}
+		if p.waitlist.heldGPUs(nodeName, pod) > 0 {
+			continue
+		}
+		nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+		if nodeDeviceInfo == nil {
+			continue
+		}
+		nodeDeviceInfo.lock.RLock()
+		total, free := nodeDeviceInfo.countWholeGPUs()
+		nodeDeviceInfo.lock.RUnlock()
+		if total < gpuWanted {
+			continue
+		}
+		if free > candidateFree || (free == candidateFree && nodeName < candidate) {
+			candidate, candidateFree = nodeName, free
+		}
+	}
+	if candidate == "" || !p.waitlist.hold(candidate, pod, gpuWanted) {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("pod holds GPUs of node %s", candidate))
+}
+
+func nodeStatusHasReason(status *framework.Status, reason string) bool {
+	for _, r := range status.Reasons() {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	ctx, span := p.startSpan(ctx, "Reserve", pod, nodeName)
+	defer func() { p.endSpan(span, cycleState, status) }()
+
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -179,18 +496,51 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingDevice)
 	}
 
-	podRequest := state.convertedDeviceResource
-
 	nodeDeviceInfo.lock.Lock()
 	defer nodeDeviceInfo.lock.Unlock()
 
-	allocateResult, err := p.allocator.Allocate(nodeName, pod, podRequest, nodeDeviceInfo)
+	nodeDevice := p.nodeDeviceCache.withoutCoolingDevices(p.withOvercommittedDevices(pod, nodeDeviceInfo)).withoutFreeGPUs(p.waitlist.heldGPUs(nodeName, pod))
+	if state.gpuModelSelector != nil {
+		nodeDevice = nodeDevice.withGPUsOfModels(state.gpuModelSelector)
+	}
+	// the devices held by the reservation the pod is assumed to allocate by the Reservation plugin are available to it
+	var assumed []*schedulingv1alpha1.Reservation
+	if reservation := frameworkext.GetReservationNomination(cycleState).GetAssumedOnNode(nodeName); reservation != nil {
+		assumed = append(assumed, reservation)
+	}
+	nominated := nodeDeviceInfo.getNominatedReservedDevices(pod, assumed)
+	allocateResult, reservedAllocations, reservationUID, err := p.allocateDevices(ctx, nodeName, pod, state, nominated, nodeDeviceInfo, nodeDevice)
 	if err != nil || len(allocateResult) == 0 {
+		nodeDeviceInfo.reserveStats.record(time.Now(), false)
 		return framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices)
 	}
-	p.allocator.Reserve(pod, nodeDeviceInfo, allocateResult)
+	if state.gpuExclusive {
+		markGPUsExclusive(allocateResult)
+	}
+	if util.IsReservePod(pod) {
+		// the reserve pod holds the devices for the reservation instead of using them
+		nodeDeviceInfo.reserveDevices(pod, nil, allocateResult)
+	} else {
+		if reservationUID != "" {
+			nodeDeviceInfo.consumeReservedDevices(reservationUID, pod, reservedAllocations)
+		}
+		p.allocator.Reserve(pod, nodeDeviceInfo, allocateResult)
+	}
+	nodeDeviceInfo.reserveStats.record(time.Now(), true)
+	nodeDeviceInfo.recordAllocatorPolicy(p.allocator.Name(), time.Now())
+	p.waitlist.release(pod)
 
 	state.allocationResult = allocateResult
+	state.reservationUID = reservationUID
+	state.reservedAllocations = reservedAllocations
+	state.allocatedTopology = nodeDeviceInfo.getAllocatedTopology(allocateResult)
+	frameworkext.SetDeviceNUMAHint(cycleState, &frameworkext.DeviceNUMAHint{
+		NodeName:  nodeName,
+		NUMANodes: nodeDeviceInfo.getAllocatedNUMANodes(allocateResult),
+	})
+	if topology := state.allocatedTopology[schedulingv1alpha1.GPU]; topology != nil && topology.Degraded {
+		klog.V(4).InfoS("the GPUs are allocated with degraded topology", "pod", klog.KObj(pod), "node", nodeName, "numaNodes", topology.NUMANodes)
+	}
 	return nil
 }
 
@@ -211,11 +561,24 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	nodeDeviceInfo.lock.Lock()
 	defer nodeDeviceInfo.lock.Unlock()
 
-	p.allocator.Unreserve(pod, nodeDeviceInfo, state.allocationResult)
+	if util.IsReservePod(pod) {
+		nodeDeviceInfo.unreserveDevices(pod.UID)
+	} else {
+		p.allocator.Unreserve(pod, nodeDeviceInfo, state.allocationResult)
+		// the devices carved out of the reservation are held by the reservation again instead of being free
+		nodeDeviceInfo.releaseReservedDevices(pod)
+	}
 	state.allocationResult = nil
+	state.reservationUID = ""
+	state.reservedAllocations = nil
+	state.allocatedTopology = nil
+	frameworkext.SetDeviceNUMAHint(cycleState, &frameworkext.DeviceNUMAHint{})
 }
 
-func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	ctx, span := p.startSpan(ctx, "PreBind", pod, nodeName)
+	defer func() { p.endSpan(span, cycleState, status) }()
+
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -229,6 +592,11 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	if err := apiext.SetDeviceAllocations(newPod, allocResult); err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
 	}
+	if state.allocatedTopology != nil {
+		if err := apiext.SetDeviceAllocatedTopology(newPod, state.allocatedTopology); err != nil {
+			return framework.NewStatus(framework.Error, err.Error())
+		}
+	}
 
 	// NOTE: APIServer won't allow the following modification. Error: pod updates may not change fields other than
 	// `spec.containers[*].image`, `spec.initContainers[*].image`, `spec.activeDeadlineSeconds`,
@@ -243,10 +611,29 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	if err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
 	}
-	err = util.RetryOnConflictOrTooManyRequests(func() error {
-		_, podErr := p.handle.ClientSet().CoreV1().Pods(pod.Namespace).
+	backoff := retry.DefaultBackoff
+	if p.preBindPatchBackoff != nil {
+		backoff = *p.preBindPatchBackoff
+	}
+	err = p.patchPod(ctx, backoff, func(ctx context.Context) error {
+		if util.IsReservePod(pod) {
+			// the reserve pod is not created, the devices are recorded in the reservation instead
+			patched, reservationErr := util.NewPatch().WithHandle(p.handle).AddAnnotations(newPod.Annotations).PatchPodOrReservation(pod)
+			if reservationErr != nil {
+				return reservationErr
+			}
+			patchedObj, ok := patched.(metav1.Object)
+			if !ok {
+				return fmt.Errorf("unexpected patched object %T", patched)
+			}
+			return verifyDeviceAllocations(pod, patchedObj.GetAnnotations(), allocResult)
+		}
+		patchedPod, podErr := p.handle.ClientSet().CoreV1().Pods(pod.Namespace).
 			Patch(ctx, pod.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
-		return podErr
+		if podErr != nil {
+			return podErr
+		}
+		return verifyDeviceAllocations(pod, patchedPod.Annotations, allocResult)
 	})
 	if err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
@@ -255,6 +642,29 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	return nil
 }
 
+// verifyDeviceAllocations checks the device allocations in the annotations of the patched pod or reservation returned
+// by the patch are exactly the expected ones, e.g. the RDMA devices could be missing if the patch partially applied.
+// It returns errDeviceAllocationsMismatch to patch again otherwise.
+func verifyDeviceAllocations(pod *corev1.Pod, annotations map[string]string, allocResult apiext.DeviceAllocations) error {
+	patched, err := apiext.GetDeviceAllocations(annotations)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errDeviceAllocationsMismatch, err)
+	}
+	if !equalDeviceAllocations(patched, allocResult) {
+		klog.V(4).InfoS("the device allocations of the pod are not patched as expected", "pod", klog.KObj(pod))
+		return errDeviceAllocationsMismatch
+	}
+	return nil
+}
+
+func (p *Plugin) PostBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() || state.skip {
+		return
+	}
+	p.allocationReporter.enqueue(pod, nodeName, state.allocationResult, state.convertedDeviceResource)
+}
+
 func (p *Plugin) getNodeDeviceSummary(nodeName string) (*NodeDeviceSummary, bool) {
 	return p.nodeDeviceCache.getNodeDeviceSummary(nodeName)
 }
@@ -263,11 +673,37 @@ func (p *Plugin) getAllNodeDeviceSummary() map[string]*NodeDeviceSummary {
 	return p.nodeDeviceCache.getAllNodeDeviceSummary()
 }
 
+func (p *Plugin) getLargestSchedulableGPU(nodeName string) (*NodeLargestSchedulableGPU, bool) {
+	return p.nodeDeviceCache.getLargestSchedulableGPU(nodeName)
+}
+
+func (p *Plugin) getAllLargestSchedulableGPU() map[string]*NodeLargestSchedulableGPU {
+	return p.nodeDeviceCache.getAllLargestSchedulableGPU()
+}
+
+func getDefaultDeviceShareArgs() (*config.DeviceShareArgs, error) {
+	var v1beta2args v1beta2.DeviceShareArgs
+	v1beta2.SetDefaults_DeviceShareArgs(&v1beta2args)
+	var deviceShareArgs config.DeviceShareArgs
+	err := v1beta2.Convert_v1beta2_DeviceShareArgs_To_config_DeviceShareArgs(&v1beta2args, &deviceShareArgs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &deviceShareArgs, nil
+}
+
 func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	args, ok := obj.(*config.DeviceShareArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type DeviceShareArgs, got %T", obj)
 	}
+	if err := validation.ValidateDeviceShareArgs(args); err != nil {
+		return nil, err
+	}
+	resourceNames, err := newDeviceResourceNames(args.CustomDeviceTypes)
+	if err != nil {
+		return nil, err
+	}
 
 	extendedHandle, ok := handle.(frameworkext.ExtendedHandle)
 	if !ok {
@@ -275,18 +711,81 @@ func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	}
 
 	deviceCache := newNodeDeviceCache()
+	deviceCache.releaseTerminatedPods = pointer.BoolDeref(args.ReleaseTerminatedPods, true)
+	deviceCache.reconcileStrategy = args.ReconcileStrategy
+	gpuCoreGranularity := args.GPUCoreGranularity
+	if gpuCoreGranularity == nil {
+		defaultArgs, err := getDefaultDeviceShareArgs()
+		if err != nil {
+			return nil, err
+		}
+		gpuCoreGranularity = defaultArgs.GPUCoreGranularity
+	}
+	deviceCache.gpuCoreGranularity = int64(*gpuCoreGranularity)
+	deviceCache.overcommitRatios = args.OvercommitRatios
+	deviceCache.resourceNames = resourceNames
+	if len(args.AllocationCooldowns) > 0 {
+		deviceCache.allocationCooldowns = make(map[schedulingv1alpha1.DeviceType]time.Duration, len(args.AllocationCooldowns))
+		for deviceType, cooldown := range args.AllocationCooldowns {
+			deviceCache.allocationCooldowns[deviceType] = cooldown.Duration
+		}
+	}
 	registerDeviceEventHandler(deviceCache, extendedHandle.KoordinatorSharedInformerFactory())
+	registerReservationEventHandler(deviceCache, extendedHandle.KoordinatorSharedInformerFactory())
 	registerPodEventHandler(deviceCache, handle.SharedInformerFactory())
 
 	allocatorOpts := AllocatorOptions{
 		SharedInformerFactory:      extendedHandle.SharedInformerFactory(),
 		KoordSharedInformerFactory: extendedHandle.KoordinatorSharedInformerFactory(),
+		NUMATopologyPolicy:         args.NUMATopologyPolicy,
+		GPUTopologyPolicy:          args.GPUTopologyPolicy,
 	}
 	allocator := NewAllocator(args.Allocator, allocatorOpts)
 
+	locality, err := newLocalityAffinity(args.LocalityAffinity)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceLister := extendedHandle.KoordinatorSharedInformerFactory().Scheduling().V1alpha1().Devices().Lister()
 	return &Plugin{
 		handle:          handle,
 		nodeDeviceCache: deviceCache,
 		allocator:       allocator,
+		podLister:       handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		deviceLister:    deviceLister,
+		initialArgs:     args,
+		locality:        locality,
+
+		reservationLister: extendedHandle.KoordinatorSharedInformerFactory().Scheduling().V1alpha1().Reservations().Lister(),
+
+		minResourcesPerGPU:  args.MinResourcesPerGPU,
+		scoringStrategy:     args.ScoringStrategy,
+		preBindPatchBackoff: newPatchBackoff(args.PreBindPatchRetry),
+		waitlist:            newDeviceWaitlist(args.Waitlist, clock.RealClock{}),
+		tracer:              extendedHandle.TracerProvider().Tracer(tracerName),
+		allocationReporter:  newAllocationReporter(handle.EventRecorder(), deviceLister, deviceCache.getResourceNames()),
+		schedulingEvents:    newSchedulingEventRecorder(handle.EventRecorder(), deviceCache.getResourceNames()),
+		enablePreemption:    pointer.BoolDeref(args.EnablePreemption, false),
+		pdbLister:           getPDBLister(handle),
+		overcommitPods:      newOvercommitPodSelector(args),
 	}, nil
 }
+
+// newPatchBackoff converts the retry policy to the backoff, the unset fields fall back to retry.DefaultBackoff.
+func newPatchBackoff(policy *config.PatchRetryPolicy) *wait.Backoff {
+	if policy == nil {
+		return nil
+	}
+	backoff := retry.DefaultBackoff
+	if policy.MaxAttempts != nil {
+		backoff.Steps = int(*policy.MaxAttempts)
+	}
+	if policy.InitialBackoff != nil {
+		backoff.Duration = policy.InitialBackoff.Duration
+	}
+	if policy.BackoffFactor != nil {
+		backoff.Factor = *policy.BackoffFactor
+	}
+	return &backoff
+}
@@ -40,4 +40,16 @@ func (p *Plugin) RegisterEndpoints(group *gin.RouterGroup) {
 		}
 		c.JSON(http.StatusOK, nodeDeviceSummary)
 	})
+	group.GET("/largestSchedulableGPUs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, p.getAllLargestSchedulableGPU())
+	})
+	group.GET("/largestSchedulableGPUs/:name", func(c *gin.Context) {
+		nodeName := c.Param("name")
+		largest, exist := p.getLargestSchedulableGPU(nodeName)
+		if !exist {
+			services.ResponseErrorMessage(c, http.StatusNotFound, "cannot find node %s", nodeName)
+			return
+		}
+		c.JSON(http.StatusOK, largest)
+	})
 }
@@ -235,3 +235,104 @@ func TestEndpointsQueryNodeDeviceSummary(t *testing.T) {
 	}
 	assert.True(t, apiequality.Semantic.DeepEqual(nodeDeviceSummary["node1"].AllocateSet, nodeDeviceSummaryExpect["node1"].AllocateSet))
 }
+
+func TestEndpointsQueryNodeDeviceSummaryByName(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	p, err := suit.proxyNew(&config.DeviceShareArgs{}, suit.Handle)
+	assert.NoError(t, err)
+	ds := p.(*Plugin)
+
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	}
+	ds.nodeDeviceCache.onDeviceAdd(device)
+
+	engine := gin.Default()
+	ds.RegisterEndpoints(engine.Group("/"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/nodeDeviceSummaries/node1", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	nodeDeviceSummary := &NodeDeviceSummary{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), nodeDeviceSummary))
+	expectedTotal := map[corev1.ResourceName]*resource.Quantity{
+		apiext.GPUCore:        resource.NewQuantity(100, resource.DecimalSI),
+		apiext.GPUMemoryRatio: resource.NewQuantity(100, resource.DecimalSI),
+	}
+	assert.True(t, apiequality.Semantic.DeepEqual(expectedTotal, nodeDeviceSummary.DeviceTotal))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/nodeDeviceSummaries/node2", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "cannot find node node2")
+}
+
+func TestEndpointsQueryLargestSchedulableGPU(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	p, err := suit.proxyNew(&config.DeviceShareArgs{}, suit.Handle)
+	assert.NotNil(t, p)
+	assert.Nil(t, err)
+
+	ds := p.(*Plugin)
+	ds.nodeDeviceCache.onDeviceAdd(&schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	})
+
+	engine := gin.Default()
+	ds.RegisterEndpoints(engine.Group("/"))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/largestSchedulableGPUs", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	var all map[string]*NodeLargestSchedulableGPU
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &all))
+	expected := &NodeLargestSchedulableGPU{
+		WholeGPUs:    1,
+		LargestShare: &GPUShare{Minor: 0, GPUCore: 100, GPUMemoryRatio: 100},
+		GPUs:         []GPUShare{{Minor: 0, GPUCore: 100, GPUMemoryRatio: 100}},
+	}
+	assert.Equal(t, map[string]*NodeLargestSchedulableGPU{"node1": expected}, all)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/largestSchedulableGPUs/node1", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	var largest *NodeLargestSchedulableGPU
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &largest))
+	assert.Equal(t, expected, largest)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/largestSchedulableGPUs/node2", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
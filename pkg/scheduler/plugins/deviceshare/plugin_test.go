@@ -21,24 +21,31 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/retry"
 	schedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
 	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
@@ -46,12 +53,14 @@ import (
 	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 type fakeExtendedHandle struct {
 	frameworkext.ExtendedHandle
 	cs                    *kubefake.Clientset
 	sharedInformerFactory informers.SharedInformerFactory
+	eventRecorder         events.EventRecorder
 }
 
 func (f *fakeExtendedHandle) ClientSet() clientset.Interface {
@@ -62,6 +71,10 @@ func (f *fakeExtendedHandle) SharedInformerFactory() informers.SharedInformerFac
 	return f.sharedInformerFactory
 }
 
+func (f *fakeExtendedHandle) EventRecorder() events.EventRecorder {
+	return f.eventRecorder
+}
+
 var _ framework.SharedLister = &testSharedLister{}
 
 type testSharedLister struct {
@@ -230,12 +243,14 @@ func Test_New(t *testing.T) {
 	assert.NotNil(t, p)
 	assert.Nil(t, err)
 	assert.Equal(t, Name, p.Name())
+	// the unset gpu-core granularity falls back to the defaulted one
+	assert.Equal(t, int64(5), p.(*Plugin).nodeDeviceCache.gpuCoreGranularity)
 }
 
 func Test_Plugin_PreFilterExtensions(t *testing.T) {
 	t.Run("test not panic", func(t *testing.T) {
 		p := &Plugin{}
-		assert.Nil(t, nil, p.PreFilterExtensions())
+		assert.Equal(t, p, p.PreFilterExtensions())
 	})
 }
 
@@ -406,6 +421,294 @@ func Test_Plugin_PreFilter(t *testing.T) {
 	}
 }
 
+func Test_Plugin_PreFilterWithMinResourcesPerGPU(t *testing.T) {
+	newGPUPod := func(requests corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:       "123456789",
+				Namespace: "default",
+				Name:      "test",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "test-container-a",
+						Resources: corev1.ResourceRequirements{
+							Requests: requests,
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		pod        *corev1.Pod
+		wantStatus *framework.Status
+	}{
+		{
+			name: "pod requests enough cpu and memory",
+			pod: newGPUPod(corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("32Gi"),
+				apiext.NvidiaGPU:      resource.MustParse("2"),
+			}),
+		},
+		{
+			name: "pod requests a gpu with insufficient cpu",
+			pod: newGPUPod(corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				apiext.NvidiaGPU:      resource.MustParse("1"),
+			}),
+			wantStatus: framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				"Insufficient cpu requests for 1 GPU(s), requested 2, at least 4 required"),
+		},
+		{
+			name: "pod requests multiple gpus without cpu and memory",
+			pod: newGPUPod(corev1.ResourceList{
+				apiext.NvidiaGPU: resource.MustParse("2"),
+			}),
+			wantStatus: framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				"Insufficient cpu requests for 2 GPU(s), requested 0, at least 8 required",
+				"Insufficient memory requests for 2 GPU(s), requested 0, at least 32Gi required"),
+		},
+		{
+			name: "shared gpu is counted as one",
+			pod: newGPUPod(corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				apiext.KoordGPU:       resource.MustParse("50"),
+			}),
+		},
+		{
+			name: "non-gpu pod is not validated",
+			pod: newGPUPod(corev1.ResourceList{
+				apiext.KoordRDMA: resource.MustParse("100"),
+			}),
+		},
+		{
+			name: "pod overhead is counted",
+			pod: func() *corev1.Pod {
+				pod := newGPUPod(corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("3800m"),
+					corev1.ResourceMemory: resource.MustParse("16128Mi"),
+					apiext.NvidiaGPU:      resource.MustParse("1"),
+				})
+				pod.Spec.Overhead = corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("200m"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				}
+				return pod
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{
+				minResourcesPerGPU: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				},
+			}
+			cycleState := framework.NewCycleState()
+			status := p.PreFilter(context.TODO(), cycleState, tt.pod)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func Test_Plugin_PreFilterWithDeviceOrderingHint(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				apiext.AnnotationDeviceOrderingHint: `{"gpu":[1,0]}`,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container-a",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.NvidiaGPU: resource.MustParse("3"),
+						},
+					},
+				},
+			},
+		},
+	}
+	p := &Plugin{}
+	status := p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.Error, "device ordering hint of gpu expects 3 indices, got 2"), status)
+
+	pod.Annotations[apiext.AnnotationDeviceOrderingHint] = `{"gpu":[1,2,0]}`
+	status = p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.True(t, status.IsSuccess())
+}
+
+func Test_Plugin_PreFilterWithGPUMinComputeCapability(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				apiext.AnnotationGPUMinComputeCapability: "eight",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container-a",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.NvidiaGPU: resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+	p := &Plugin{}
+	status := p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.Error,
+		`invalid GPU minimum compute capability: invalid compute capability "eight", expected <major>.<minor>`), status)
+
+	pod.Annotations[apiext.AnnotationGPUMinComputeCapability] = "8.0"
+	cycleState := framework.NewCycleState()
+	status = p.PreFilter(context.TODO(), cycleState, pod)
+	assert.True(t, status.IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, &apiext.GPUComputeCapability{Major: 8, Minor: 0}, state.gpuMinComputeCapability)
+}
+
+func Test_Plugin_PreFilterWithGPUModel(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				apiext.AnnotationGPUModel: "A100,,A800",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container-a",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.NvidiaGPU: resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+	p := &Plugin{}
+	status := p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.Error,
+		`invalid GPU model selector: invalid GPU model selector "A100,,A800", expected comma-separated models`), status)
+
+	pod.Annotations[apiext.AnnotationGPUModel] = "A100, A800"
+	cycleState := framework.NewCycleState()
+	status = p.PreFilter(context.TODO(), cycleState, pod)
+	assert.True(t, status.IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, apiext.GPUModelSelector{"A100", "A800"}, state.gpuModelSelector)
+
+	// the GPU model is selected by the pod requesting no GPU
+	pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+		apiext.KoordRDMA: resource.MustParse("100"),
+	}
+	status = p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.Error, "GPU model A100,A800 is selected but no GPU is requested"), status)
+}
+
+func Test_Plugin_PreFilterWithGPUExclusive(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				apiext.AnnotationGPUExclusive: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container-a",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.GPUCore:        resource.MustParse("50"),
+							apiext.GPUMemoryRatio: resource.MustParse("50"),
+						},
+					},
+				},
+			},
+		},
+	}
+	p := &Plugin{}
+	cycleState := framework.NewCycleState()
+	status := p.PreFilter(context.TODO(), cycleState, pod)
+	assert.True(t, status.IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.True(t, state.gpuExclusive)
+
+	// the GPU exclusive is demanded by the pod requesting no GPU
+	pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+		apiext.KoordRDMA: resource.MustParse("100"),
+	}
+	status = p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.Error, "GPU exclusive is demanded but no GPU is requested"), status)
+}
+
+func Test_Plugin_PreFilterWithMIGRequest(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container-a",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.NvidiaGPU:                       resource.MustParse("1"),
+							apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+	p := &Plugin{}
+	status := p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.Error,
+		"request is not valid, full GPUs and MIG instances cannot be requested together"), status)
+
+	delete(pod.Spec.Containers[0].Resources.Requests, apiext.NvidiaGPU)
+	cycleState := framework.NewCycleState()
+	status = p.PreFilter(context.TODO(), cycleState, pod)
+	assert.True(t, status.IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.False(t, state.skip)
+	assert.Equal(t, corev1.ResourceList{
+		apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("1"),
+	}, state.convertedDeviceResource)
+}
+
 func Test_Plugin_Filter(t *testing.T) {
 	testNode := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
@@ -758,71 +1061,1158 @@ func Test_Plugin_Filter(t *testing.T) {
 			want:     nil,
 		},
 		{
-			name: "sufficient device resource 4",
-			state: &preFilterState{
-				skip: false,
+			name: "sufficient device resource 4",
+			state: &preFilterState{
+				skip: false,
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+				},
+			},
+			pod: &corev1.Pod{},
+			nodeDeviceCache: &nodeDeviceCache{
+				nodeDeviceInfos: map[string]*nodeDevice{
+					"test-node": {
+						deviceFree: map[schedulingv1alpha1.DeviceType]deviceResources{
+							schedulingv1alpha1.GPU: {
+								0: corev1.ResourceList{
+									apiext.GPUCore:        resource.MustParse("25"),
+									apiext.GPUMemoryRatio: resource.MustParse("25"),
+									apiext.GPUMemory:      resource.MustParse("4Gi"),
+								},
+								1: corev1.ResourceList{
+									apiext.GPUCore:        resource.MustParse("100"),
+									apiext.GPUMemoryRatio: resource.MustParse("100"),
+									apiext.GPUMemory:      resource.MustParse("16Gi"),
+								},
+							},
+						},
+						deviceTotal: map[schedulingv1alpha1.DeviceType]deviceResources{
+							schedulingv1alpha1.GPU: {
+								0: corev1.ResourceList{
+									apiext.GPUCore:        resource.MustParse("100"),
+									apiext.GPUMemoryRatio: resource.MustParse("100"),
+									apiext.GPUMemory:      resource.MustParse("16Gi"),
+								},
+								1: corev1.ResourceList{
+									apiext.GPUCore:        resource.MustParse("100"),
+									apiext.GPUMemoryRatio: resource.MustParse("100"),
+									apiext.GPUMemory:      resource.MustParse("16Gi"),
+								},
+							},
+						},
+						deviceUsed: map[schedulingv1alpha1.DeviceType]deviceResources{
+							schedulingv1alpha1.GPU: {
+								0: corev1.ResourceList{
+									apiext.GPUCore:        resource.MustParse("75"),
+									apiext.GPUMemoryRatio: resource.MustParse("75"),
+									apiext.GPUMemory:      resource.MustParse("12Gi"),
+								},
+							},
+						},
+					},
+				},
+			},
+			nodeInfo: testNodeInfo,
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: tt.nodeDeviceCache, allocator: &defaultAllocator{}}
+			cycleState := framework.NewCycleState()
+			if tt.state != nil {
+				cycleState.Write(stateKey, tt.state)
+			}
+			status := p.Filter(context.TODO(), cycleState, tt.pod, tt.nodeInfo)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func Test_Plugin_FilterWithGPUTargetUUID(t *testing.T) {
+	newGPUDevice := func(nodeName string, uuids ...string) *schedulingv1alpha1.Device {
+		device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		for i, uuid := range uuids {
+			device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+				UUID:   uuid,
+				Minor:  pointer.Int32Ptr(int32(i)),
+				Health: true,
+				Type:   schedulingv1alpha1.GPU,
+				Resources: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			})
+		}
+		return device
+	}
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("test-node", newGPUDevice("test-node", "GPU-0", "GPU-1", "GPU-2"))
+	deviceCache.updateNodeDevice("other-node", newGPUDevice("other-node", "GPU-3"))
+
+	// GPU-1 is fully occupied
+	allocator := &defaultAllocator{}
+	occupied := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}
+	allocator.Reserve(occupied, deviceCache.getNodeDevice("test-node"), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 1,
+				Resources: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			},
+		},
+	})
+
+	testNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	testNodeInfo := framework.NewNodeInfo()
+	testNodeInfo.SetNode(testNode)
+	gpuRequest := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("50"),
+		apiext.GPUMemoryRatio: resource.MustParse("50"),
+		apiext.GPUMemory:      resource.MustParse("8Gi"),
+	}
+
+	tests := []struct {
+		name      string
+		uuid      string
+		want      *framework.Status
+		wantMinor int32
+	}{
+		{
+			name:      "allocate the free GPU with the target UUID",
+			uuid:      "GPU-2",
+			wantMinor: 2,
+		},
+		{
+			name: "reject the busy GPU with the target UUID",
+			uuid: "GPU-1",
+			want: framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name: "reject the GPU on another node",
+			uuid: "GPU-3",
+			want: framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: deviceCache, allocator: allocator}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test",
+					Annotations: map[string]string{
+						apiext.AnnotationGPUTargetUUID: tt.uuid,
+					},
+				},
+			}
+			state := &preFilterState{convertedDeviceResource: gpuRequest}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, state)
+			status := p.Filter(context.TODO(), cycleState, pod, testNodeInfo)
+			assert.Equal(t, tt.want, status)
+			if !status.IsSuccess() {
+				return
+			}
+			status = p.Reserve(context.TODO(), cycleState, pod, "test-node")
+			assert.True(t, status.IsSuccess())
+			assert.Len(t, state.allocationResult[schedulingv1alpha1.GPU], 1)
+			assert.Equal(t, tt.wantMinor, state.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+			p.Unreserve(context.TODO(), cycleState, pod, "test-node")
+		})
+	}
+}
+
+func Test_Plugin_FilterWholeGPUOnly(t *testing.T) {
+	deviceCache := newNodeDeviceCache()
+	for _, nodeName := range []string{"whole-gpu-node", "shared-gpu-node"} {
+		deviceCache.createNodeDevice(nodeName).resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: {
+				0: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			},
+		})
+	}
+	wholeGPUNodeInfo := framework.NewNodeInfo()
+	wholeGPUNodeInfo.SetNode(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "whole-gpu-node",
+			Labels: map[string]string{
+				apiext.LabelNodeGPUWholeOnly: "true",
+			},
+		},
+	})
+	sharedGPUNodeInfo := framework.NewNodeInfo()
+	sharedGPUNodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "shared-gpu-node"}})
+
+	halfGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("50"),
+		apiext.GPUMemoryRatio: resource.MustParse("50"),
+		apiext.GPUMemory:      resource.MustParse("8Gi"),
+	}
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	tests := []struct {
+		name       string
+		nodeInfo   *framework.NodeInfo
+		podRequest corev1.ResourceList
+		want       *framework.Status
+	}{
+		{
+			name:       "whole-GPU-only node rejects 0.5 GPU",
+			nodeInfo:   wholeGPUNodeInfo,
+			podRequest: halfGPU,
+			want:       framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrFractionalGPUUnsupported),
+		},
+		{
+			name:     "whole-GPU-only node rejects GPU memory",
+			nodeInfo: wholeGPUNodeInfo,
+			podRequest: corev1.ResourceList{
+				apiext.GPUMemory: resource.MustParse("8Gi"),
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrFractionalGPUUnsupported),
+		},
+		{
+			name:       "whole-GPU-only node accepts 1 GPU",
+			nodeInfo:   wholeGPUNodeInfo,
+			podRequest: wholeGPU,
+		},
+		{
+			name:       "shared node accepts 0.5 GPU",
+			nodeInfo:   sharedGPUNodeInfo,
+			podRequest: halfGPU,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: tt.podRequest})
+			status := p.Filter(context.TODO(), cycleState, pod, tt.nodeInfo)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func Test_Plugin_FilterWithGPUMinComputeCapability(t *testing.T) {
+	newGPUDevice := func(nodeName string, computeCapabilities ...string) *schedulingv1alpha1.Device {
+		device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		for i, computeCapability := range computeCapabilities {
+			device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+				Minor:             pointer.Int32Ptr(int32(i)),
+				Health:            true,
+				Type:              schedulingv1alpha1.GPU,
+				ComputeCapability: computeCapability,
+				Resources: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			})
+		}
+		return device
+	}
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("ampere-node", newGPUDevice("ampere-node", "8.0"))
+	deviceCache.updateNodeDevice("mixed-node", newGPUDevice("mixed-node", "7.5", "8.6"))
+	deviceCache.updateNodeDevice("busy-mixed-node", newGPUDevice("busy-mixed-node", "7.5", "8.6"))
+	deviceCache.updateNodeDevice("turing-node", newGPUDevice("turing-node", "7.5"))
+	deviceCache.updateNodeDevice("unreported-node", newGPUDevice("unreported-node", ""))
+
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	// the GPU of compute capability 8.6 is fully occupied
+	allocator := &defaultAllocator{}
+	occupied := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}
+	allocator.Reserve(occupied, deviceCache.getNodeDevice("busy-mixed-node"), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: wholeGPU}},
+	})
+
+	tests := []struct {
+		name      string
+		nodeName  string
+		want      *framework.Status
+		wantMinor int32
+	}{
+		{
+			name:      "allocate the GPU of the higher compute capability",
+			nodeName:  "ampere-node",
+			wantMinor: 0,
+		},
+		{
+			name:      "allocate only the GPU meeting the compute capability on the mixed node",
+			nodeName:  "mixed-node",
+			wantMinor: 1,
+		},
+		{
+			name:     "reject the mixed node if the GPU meeting the compute capability is busy",
+			nodeName: "busy-mixed-node",
+			want:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:     "reject the node of the lower compute capability",
+			nodeName: "turing-node",
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUComputeCapability),
+		},
+		{
+			name:     "reject the node not reporting the compute capability",
+			nodeName: "unreported-node",
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUComputeCapability),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: deviceCache, allocator: allocator}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test",
+					Annotations: map[string]string{
+						apiext.AnnotationGPUMinComputeCapability: "8.0",
+					},
+				},
+			}
+			state := &preFilterState{
+				convertedDeviceResource: wholeGPU,
+				gpuMinComputeCapability: &apiext.GPUComputeCapability{Major: 8, Minor: 0},
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, state)
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: tt.nodeName}})
+			status := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+			assert.Equal(t, tt.want, status)
+			if !status.IsSuccess() {
+				return
+			}
+			status = p.Reserve(context.TODO(), cycleState, pod, tt.nodeName)
+			assert.True(t, status.IsSuccess())
+			assert.Len(t, state.allocationResult[schedulingv1alpha1.GPU], 1)
+			assert.Equal(t, tt.wantMinor, state.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+			p.Unreserve(context.TODO(), cycleState, pod, tt.nodeName)
+		})
+	}
+}
+
+func Test_Plugin_FilterWithGPUModel(t *testing.T) {
+	newGPUDevice := func(nodeName, nodeModel string, models ...string) *schedulingv1alpha1.Device {
+		device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		if nodeModel != "" {
+			device.Labels = map[string]string{apiext.GPUModel: nodeModel}
+		}
+		for i, model := range models {
+			device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+				Minor:  pointer.Int32Ptr(int32(i)),
+				Health: true,
+				Type:   schedulingv1alpha1.GPU,
+				Model:  model,
+				Resources: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			})
+		}
+		return device
+	}
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("a100-node", newGPUDevice("a100-node", "", "A100-SXM4-80GB"))
+	deviceCache.updateNodeDevice("mixed-node", newGPUDevice("mixed-node", "", "A10", "A100-SXM4-80GB"))
+	deviceCache.updateNodeDevice("busy-mixed-node", newGPUDevice("busy-mixed-node", "", "A10", "A100-SXM4-80GB"))
+	deviceCache.updateNodeDevice("a10-node", newGPUDevice("a10-node", "", "A10"))
+	deviceCache.updateNodeDevice("labeled-node", newGPUDevice("labeled-node", "A100-PCIE-40GB", ""))
+	deviceCache.updateNodeDevice("unreported-node", newGPUDevice("unreported-node", "", ""))
+
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	// the A100 GPU is fully occupied
+	allocator := &defaultAllocator{}
+	occupied := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "occupied"}}
+	allocator.Reserve(occupied, deviceCache.getNodeDevice("busy-mixed-node"), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: wholeGPU}},
+	})
+
+	tests := []struct {
+		name      string
+		nodeName  string
+		want      *framework.Status
+		wantMinor int32
+	}{
+		{
+			name:      "allocate the GPU of the requested model",
+			nodeName:  "a100-node",
+			wantMinor: 0,
+		},
+		{
+			name:      "allocate only the GPU of the requested model on the mixed node",
+			nodeName:  "mixed-node",
+			wantMinor: 1,
+		},
+		{
+			name:     "reject the mixed node if the GPU of the requested model is busy",
+			nodeName: "busy-mixed-node",
+			want:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:     "reject the node of the other model",
+			nodeName: "a10-node",
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUModel),
+		},
+		{
+			name:      "allocate the GPU of the model of the node",
+			nodeName:  "labeled-node",
+			wantMinor: 0,
+		},
+		{
+			name:     "reject the node not reporting the model",
+			nodeName: "unreported-node",
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUModel),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: deviceCache, allocator: allocator}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test",
+					Annotations: map[string]string{
+						apiext.AnnotationGPUModel: "A100",
+					},
+				},
+			}
+			state := &preFilterState{
+				convertedDeviceResource: wholeGPU,
+				gpuModelSelector:        apiext.GPUModelSelector{"A100"},
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, state)
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: tt.nodeName}})
+			status := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+			assert.Equal(t, tt.want, status)
+			if !status.IsSuccess() {
+				return
+			}
+			status = p.Reserve(context.TODO(), cycleState, pod, tt.nodeName)
+			assert.True(t, status.IsSuccess())
+			assert.Len(t, state.allocationResult[schedulingv1alpha1.GPU], 1)
+			assert.Equal(t, tt.wantMinor, state.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+			p.Unreserve(context.TODO(), cycleState, pod, tt.nodeName)
+		})
+	}
+}
+
+func Test_Plugin_ReserveWithGPUExclusive(t *testing.T) {
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	deviceCache := newNodeDeviceCache()
+	device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	for i := 0; i < 2; i++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			Minor:     pointer.Int32Ptr(int32(i)),
+			Health:    true,
+			Type:      schedulingv1alpha1.GPU,
+			Resources: wholeGPU,
+		})
+	}
+	deviceCache.updateNodeDevice("test-node", device)
+	nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+
+	halfGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("50"),
+		apiext.GPUMemoryRatio: resource.MustParse("50"),
+		apiext.GPUMemory:      resource.MustParse("8Gi"),
+	}
+	schedule := func(name string, exclusive bool) (*framework.CycleState, *preFilterState, *framework.Status) {
+		state := &preFilterState{convertedDeviceResource: halfGPU, gpuExclusive: exclusive}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, state)
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if status := p.Filter(context.TODO(), cycleState, pod, nodeInfo); !status.IsSuccess() {
+			return cycleState, state, status
+		}
+		return cycleState, state, p.Reserve(context.TODO(), cycleState, pod, "test-node")
+	}
+
+	// the shared pods pack onto one GPU
+	_, sharedState1, status := schedule("shared-1", false)
+	assert.True(t, status.IsSuccess())
+	_, sharedState2, status := schedule("shared-2", false)
+	assert.True(t, status.IsSuccess())
+	sharedMinor := sharedState1.allocationResult[schedulingv1alpha1.GPU][0].Minor
+	assert.Equal(t, sharedMinor, sharedState2.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+	assert.True(t, quotav1.Equals(wholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][int(sharedMinor)]))
+
+	// release one shared pod, the exclusive pod is still allocated the other GPU
+	p.Unreserve(context.TODO(), func() *framework.CycleState {
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, sharedState2)
+		return cycleState
+	}(), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared-2"}}, "test-node")
+	assert.True(t, quotav1.Equals(halfGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][int(sharedMinor)]))
+	exclusiveCycleState, exclusiveState, status := schedule("exclusive", true)
+	assert.True(t, status.IsSuccess())
+	exclusiveAllocation := exclusiveState.allocationResult[schedulingv1alpha1.GPU][0]
+	assert.NotEqual(t, sharedMinor, exclusiveAllocation.Minor)
+	assert.True(t, exclusiveAllocation.Exclusive)
+	assert.True(t, quotav1.Equals(halfGPU, exclusiveAllocation.Resources))
+	// the rest of the exclusive GPU is not allocated to the other pods
+	assert.True(t, quotav1.Equals(wholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][int(exclusiveAllocation.Minor)]))
+
+	// only the shared GPU remains for the other exclusive pod
+	_, _, status = schedule("exclusive-2", true)
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrUnmetGPUExclusive), status)
+
+	_, sharedState3, status := schedule("shared-3", false)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, sharedMinor, sharedState3.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+	_, _, status = schedule("shared-4", false)
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices), status)
+
+	// the whole GPU is returned once the exclusive pod is unreserved
+	p.Unreserve(context.TODO(), exclusiveCycleState, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "exclusive"}}, "test-node")
+	assert.Nil(t, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][int(exclusiveAllocation.Minor)])
+	_, sharedState4, status := schedule("shared-4", false)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, exclusiveAllocation.Minor, sharedState4.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+}
+
+func Test_Plugin_FilterWithSeparateGPUCoreAndMemoryRatio(t *testing.T) {
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.GPUCore:        resource.MustParse("100"),
+						apiext.GPUMemoryRatio: resource.MustParse("100"),
+						apiext.GPUMemory:      resource.MustParse("16Gi"),
+					},
+				},
+			},
+		},
+	})
+	allocator := &defaultAllocator{}
+	// the running pod uses a little of the cores but the most of the memory
+	running := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "running"}}
+	allocator.Reserve(running, deviceCache.getNodeDevice("test-node"), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: corev1.ResourceList{
+			apiext.GPUCore:        resource.MustParse("20"),
+			apiext.GPUMemoryRatio: resource.MustParse("60"),
+			apiext.GPUMemory:      resource.MustParse("9830Mi"),
+		}}},
+	})
+
+	tests := []struct {
+		name           string
+		gpuCore        string
+		gpuMemoryRatio string
+		want           *framework.Status
+	}{
+		{
+			name:           "the cores and the memory both fit",
+			gpuCore:        "80",
+			gpuMemoryRatio: "30",
+		},
+		{
+			name:           "the memory fits but the cores don't",
+			gpuCore:        "90",
+			gpuMemoryRatio: "30",
+			want:           framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices, "Insufficient kubernetes.io/gpu-core"),
+		},
+		{
+			name:           "the cores fit but the memory doesn't",
+			gpuCore:        "50",
+			gpuMemoryRatio: "50",
+			want:           framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices, "Insufficient kubernetes.io/gpu-memory-ratio"),
+		},
+		{
+			name:           "neither the cores nor the memory fits",
+			gpuCore:        "90",
+			gpuMemoryRatio: "50",
+			want:           framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: deviceCache, allocator: allocator}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									apiext.GPUCore:        resource.MustParse(tt.gpuCore),
+									apiext.GPUMemoryRatio: resource.MustParse(tt.gpuMemoryRatio),
+								},
+							},
+						},
+					},
+				},
+			}
+			cycleState := framework.NewCycleState()
+			status := p.PreFilter(context.TODO(), cycleState, pod)
+			assert.True(t, status.IsSuccess())
+			state, status := getPreFilterState(cycleState)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse(tt.gpuCore),
+				apiext.GPUMemoryRatio: resource.MustParse(tt.gpuMemoryRatio),
+			}, state.convertedDeviceResource)
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+			assert.Equal(t, tt.want, p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+		})
+	}
+}
+
+func Test_Plugin_FilterWithMIGRequest(t *testing.T) {
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("full-gpu-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "full-gpu-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.GPUCore:        resource.MustParse("100"),
+						apiext.GPUMemoryRatio: resource.MustParse("100"),
+						apiext.GPUMemory:      resource.MustParse("40Gi"),
+					},
+				},
+			},
+		},
+	})
+	deviceCache.updateNodeDevice("mig-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "mig-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.GPUCore:        resource.MustParse("100"),
+						apiext.GPUMemoryRatio: resource.MustParse("100"),
+						apiext.GPUMemory:      resource.MustParse("40Gi"),
+					},
+				},
+				{
+					Minor:        pointer.Int32Ptr(1),
+					Health:       true,
+					Type:         schedulingv1alpha1.GPU,
+					MIGInstances: map[string]int32{"1g.5gb": 7},
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		nodeName  string
+		want      *framework.Status
+		wantMinor int32
+	}{
+		{
+			name:     "reject the node having the raw GPU free but no MIG instances",
+			nodeName: "full-gpu-node",
+			want:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:      "allocate the MIG instances of the requested profile",
+			nodeName:  "mig-node",
+			wantMinor: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+			}
+			state := &preFilterState{
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("2"),
+				},
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, state)
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: tt.nodeName}})
+			status := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+			assert.Equal(t, tt.want, status)
+			if !status.IsSuccess() {
+				return
+			}
+			status = p.Reserve(context.TODO(), cycleState, pod, tt.nodeName)
+			assert.True(t, status.IsSuccess())
+			assert.Len(t, state.allocationResult[schedulingv1alpha1.GPU], 1)
+			assert.Equal(t, tt.wantMinor, state.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+			p.Unreserve(context.TODO(), cycleState, pod, tt.nodeName)
+		})
+	}
+}
+
+func Test_Plugin_FilterWithNUMATopologyPolicy(t *testing.T) {
+	// the GPU is attached to the NUMA node 0 while the NIC is attached to the NUMA node 1
+	newDevice := func(rdmaNUMANode int32) *schedulingv1alpha1.Device {
+		return &schedulingv1alpha1.Device{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec: schedulingv1alpha1.DeviceSpec{
+				Devices: []schedulingv1alpha1.DeviceInfo{
+					{
+						Minor:    pointer.Int32Ptr(0),
+						Health:   true,
+						Type:     schedulingv1alpha1.GPU,
+						Topology: &schedulingv1alpha1.DeviceTopology{NodeID: 0},
+						Resources: corev1.ResourceList{
+							apiext.GPUCore:        resource.MustParse("100"),
+							apiext.GPUMemoryRatio: resource.MustParse("100"),
+							apiext.GPUMemory:      resource.MustParse("16Gi"),
+						},
+					},
+					{
+						Minor:    pointer.Int32Ptr(0),
+						Health:   true,
+						Type:     schedulingv1alpha1.RDMA,
+						Topology: &schedulingv1alpha1.DeviceTopology{NodeID: rdmaNUMANode},
+						Resources: corev1.ResourceList{
+							apiext.KoordRDMA: resource.MustParse("100"),
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name         string
+		policy       config.DeviceNUMATopologyPolicy
+		rdmaNUMANode int32
+		want         *framework.Status
+	}{
+		{
+			name:         "BestEffort allows the devices across the NUMA nodes",
+			policy:       config.DeviceNUMATopologyBestEffort,
+			rdmaNUMANode: 1,
+		},
+		{
+			name:         "Restricted rejects the devices across the NUMA nodes",
+			policy:       config.DeviceNUMATopologyRestricted,
+			rdmaNUMANode: 1,
+			want:         framework.NewStatus(framework.Unschedulable, ErrUnalignedNUMADevices),
+		},
+		{
+			name:         "Restricted allows the devices on the same NUMA node",
+			policy:       config.DeviceNUMATopologyRestricted,
+			rdmaNUMANode: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceCache := newNodeDeviceCache()
+			deviceCache.updateNodeDevice("test-node", newDevice(tt.rdmaNUMANode))
+			p := &Plugin{
+				nodeDeviceCache: deviceCache,
+				allocator:       NewDefaultAllocator(AllocatorOptions{NUMATopologyPolicy: tt.policy}),
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.KoordRDMA:      resource.MustParse("100"),
+				},
+			})
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+			assert.Equal(t, tt.want, p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+		})
+	}
+}
+
+func Test_Plugin_FilterWithDeviceJointAllocate(t *testing.T) {
+	// the GPU is attached to the PCIe switch pcie-0 while the NIC is attached to the PCIe switch of the test case
+	newDevice := func(rdmaPCIeSwitch string) *schedulingv1alpha1.Device {
+		return &schedulingv1alpha1.Device{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec: schedulingv1alpha1.DeviceSpec{
+				Devices: []schedulingv1alpha1.DeviceInfo{
+					{
+						Minor:    pointer.Int32Ptr(0),
+						Health:   true,
+						Type:     schedulingv1alpha1.GPU,
+						Topology: &schedulingv1alpha1.DeviceTopology{NodeID: 0, PCIESwitchID: "pcie-0"},
+						Resources: corev1.ResourceList{
+							apiext.GPUCore:        resource.MustParse("100"),
+							apiext.GPUMemoryRatio: resource.MustParse("100"),
+							apiext.GPUMemory:      resource.MustParse("16Gi"),
+						},
+					},
+					{
+						Minor:    pointer.Int32Ptr(0),
+						Health:   true,
+						Type:     schedulingv1alpha1.RDMA,
+						Topology: &schedulingv1alpha1.DeviceTopology{NodeID: 0, PCIESwitchID: rdmaPCIeSwitch},
+						Resources: corev1.ResourceList{
+							apiext.KoordRDMA: resource.MustParse("100"),
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		jointAllocate  bool
+		rdmaPCIeSwitch string
+		want           *framework.Status
+	}{
+		{
+			name:           "the devices across the PCIe switches are allowed without the joint allocation",
+			rdmaPCIeSwitch: "pcie-1",
+		},
+		{
+			name:           "the joint allocation rejects the devices across the PCIe switches",
+			jointAllocate:  true,
+			rdmaPCIeSwitch: "pcie-1",
+			want:           framework.NewStatus(framework.Unschedulable, ErrUnalignedJointDevices),
+		},
+		{
+			name:           "the joint allocation allows the devices under the same PCIe switch",
+			jointAllocate:  true,
+			rdmaPCIeSwitch: "pcie-0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceCache := newNodeDeviceCache()
+			deviceCache.updateNodeDevice("test-node", newDevice(tt.rdmaPCIeSwitch))
+			p := &Plugin{
+				nodeDeviceCache: deviceCache,
+				allocator:       NewDefaultAllocator(AllocatorOptions{}),
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+			if tt.jointAllocate {
+				pod.Annotations = map[string]string{
+					apiext.AnnotationDeviceJointAllocate: `{"deviceTypes":["gpu","rdma"]}`,
+				}
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.KoordRDMA:      resource.MustParse("100"),
+				},
+			})
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+			assert.Equal(t, tt.want, p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+		})
+	}
+}
+
+func Test_Plugin_FilterWithRDMAVirtualFunctions(t *testing.T) {
+	gpu := schedulingv1alpha1.DeviceInfo{
+		Minor:  pointer.Int32Ptr(0),
+		Health: true,
+		Type:   schedulingv1alpha1.GPU,
+		Resources: corev1.ResourceList{
+			apiext.GPUCore:        resource.MustParse("100"),
+			apiext.GPUMemoryRatio: resource.MustParse("100"),
+			apiext.GPUMemory:      resource.MustParse("16Gi"),
+		},
+	}
+	nic := schedulingv1alpha1.DeviceInfo{
+		Minor:  pointer.Int32Ptr(0),
+		Health: true,
+		Type:   schedulingv1alpha1.RDMA,
+		Resources: corev1.ResourceList{
+			apiext.KoordRDMA: resource.MustParse("100"),
+		},
+		VirtualFunctions: []schedulingv1alpha1.VirtualFunction{
+			{BusID: "0000:1f:00.2"},
+			{BusID: "0000:1f:00.3"},
+		},
+	}
+	tests := []struct {
+		name     string
+		devices  []schedulingv1alpha1.DeviceInfo
+		occupied int
+		rdma     string
+		want     *framework.Status
+	}{
+		{
+			name:    "node without RDMA devices",
+			devices: []schedulingv1alpha1.DeviceInfo{gpu},
+			rdma:    "100",
+			want:    framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingRDMADevice),
+		},
+		{
+			name:     "allocates the free virtual function",
+			devices:  []schedulingv1alpha1.DeviceInfo{gpu, nic},
+			occupied: 1,
+			rdma:     "100",
+		},
+		{
+			name:     "virtual functions exhausted",
+			devices:  []schedulingv1alpha1.DeviceInfo{gpu, nic},
+			occupied: 2,
+			rdma:     "100",
+			want:     framework.NewStatus(framework.Unschedulable, ErrRDMAVFsExhausted),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceCache := newNodeDeviceCache()
+			deviceCache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Spec:       schedulingv1alpha1.DeviceSpec{Devices: tt.devices},
+			})
+			nd := deviceCache.getNodeDevice("test-node")
+			for i := 0; i < tt.occupied; i++ {
+				allocations, err := nd.tryAllocateDevice(corev1.ResourceList{apiext.KoordRDMA: resource.MustParse("100")}, "")
+				assert.NoError(t, err)
+				nd.updateCacheUsed(allocations, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("occupied-%d", i)}}, true)
+			}
+			p := &Plugin{
+				nodeDeviceCache: deviceCache,
+				allocator:       NewDefaultAllocator(AllocatorOptions{}),
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{
 				convertedDeviceResource: corev1.ResourceList{
-					apiext.GPUCore:        resource.MustParse("100"),
-					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.KoordRDMA: resource.MustParse(tt.rdma),
 				},
-			},
-			pod: &corev1.Pod{},
-			nodeDeviceCache: &nodeDeviceCache{
-				nodeDeviceInfos: map[string]*nodeDevice{
-					"test-node": {
-						deviceFree: map[schedulingv1alpha1.DeviceType]deviceResources{
-							schedulingv1alpha1.GPU: {
-								0: corev1.ResourceList{
-									apiext.GPUCore:        resource.MustParse("25"),
-									apiext.GPUMemoryRatio: resource.MustParse("25"),
-									apiext.GPUMemory:      resource.MustParse("4Gi"),
-								},
-								1: corev1.ResourceList{
-									apiext.GPUCore:        resource.MustParse("100"),
-									apiext.GPUMemoryRatio: resource.MustParse("100"),
-									apiext.GPUMemory:      resource.MustParse("16Gi"),
-								},
-							},
-						},
-						deviceTotal: map[schedulingv1alpha1.DeviceType]deviceResources{
-							schedulingv1alpha1.GPU: {
-								0: corev1.ResourceList{
-									apiext.GPUCore:        resource.MustParse("100"),
-									apiext.GPUMemoryRatio: resource.MustParse("100"),
-									apiext.GPUMemory:      resource.MustParse("16Gi"),
-								},
-								1: corev1.ResourceList{
-									apiext.GPUCore:        resource.MustParse("100"),
-									apiext.GPUMemoryRatio: resource.MustParse("100"),
-									apiext.GPUMemory:      resource.MustParse("16Gi"),
-								},
-							},
-						},
-						deviceUsed: map[schedulingv1alpha1.DeviceType]deviceResources{
-							schedulingv1alpha1.GPU: {
-								0: corev1.ResourceList{
-									apiext.GPUCore:        resource.MustParse("75"),
-									apiext.GPUMemoryRatio: resource.MustParse("75"),
-									apiext.GPUMemory:      resource.MustParse("12Gi"),
-								},
-							},
+			})
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+			assert.Equal(t, tt.want, p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+		})
+	}
+}
+
+func Test_Plugin_CustomDeviceType(t *testing.T) {
+	const npu schedulingv1alpha1.DeviceType = "npu"
+	const npuResource corev1.ResourceName = "vendor.com/npu"
+
+	var devices []schedulingv1alpha1.DeviceInfo
+	for minor := int32(0); minor < 3; minor++ {
+		devices = append(devices, schedulingv1alpha1.DeviceInfo{
+			Minor:     pointer.Int32Ptr(minor),
+			Health:    true,
+			Type:      npu,
+			Resources: corev1.ResourceList{npuResource: resource.MustParse("100")},
+		})
+	}
+	deviceCache := newNodeDeviceCache()
+	resourceNames, err := newDeviceResourceNames([]config.CustomDeviceType{{ResourceName: npuResource, DeviceType: npu}})
+	assert.NoError(t, err)
+	deviceCache.resourceNames = resourceNames
+	deviceCache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec:       schedulingv1alpha1.DeviceSpec{Devices: devices},
+	})
+	p := &Plugin{
+		nodeDeviceCache: deviceCache,
+		allocator:       NewDefaultAllocator(AllocatorOptions{}),
+	}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+
+	newPod := func(name, npuRequest string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{npuResource: resource.MustParse(npuRequest)},
 						},
 					},
 				},
 			},
-			nodeInfo: testNodeInfo,
-			want:     nil,
+		}
+	}
+
+	// the custom devices are requested by the whole devices
+	cycleState := framework.NewCycleState()
+	assert.False(t, p.PreFilter(context.TODO(), cycleState, newPod("invalid-pod", "150")).IsSuccess())
+
+	pod := newPod("pod-1", "200")
+	cycleState = framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.False(t, state.skip)
+	assert.Equal(t, corev1.ResourceList{npuResource: resource.MustParse("200")}, state.convertedDeviceResource)
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node").IsSuccess())
+	assert.Len(t, state.allocationResult[npu], 2)
+	for _, allocation := range state.allocationResult[npu] {
+		assert.Equal(t, corev1.ResourceList{npuResource: *resource.NewQuantity(100, resource.DecimalSI)}, allocation.Resources)
+	}
+
+	pod = newPod("pod-2", "200")
+	cycleState = framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices), p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+
+	summary, ok := p.getNodeDeviceSummary("test-node")
+	assert.True(t, ok)
+	assert.Equal(t, int64(300), summary.DeviceTotal[npuResource].Value())
+	assert.Equal(t, int64(200), summary.DeviceUsed[npuResource].Value())
+	assert.Equal(t, int64(100), summary.DeviceFree[npuResource].Value())
+	assert.Len(t, summary.AllocateSet[npu]["default/pod-1"], 2)
+}
+
+func Test_Plugin_FilterWithGPUTopologyPolicy(t *testing.T) {
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+	// the NVLink island 0 has GPU 0-3 and the island 1 has GPU 4-7, each PCIe switch has 2 GPUs,
+	// and the NUMA nodes interleave the GPUs so they never align with the islands
+	for minor := int32(0); minor < 8; minor++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			Minor:     pointer.Int32(minor),
+			Type:      schedulingv1alpha1.GPU,
+			Health:    true,
+			Resources: wholeGPU.DeepCopy(),
+			Topology: &schedulingv1alpha1.DeviceTopology{
+				NodeID:        minor % 2,
+				PCIESwitchID:  fmt.Sprintf("pcie-%d", minor/2),
+				NVLinkGroupID: fmt.Sprintf("nvlink-%d", minor/4),
+			},
+		})
+	}
+	tests := []struct {
+		name       string
+		policy     config.DeviceGPUTopologyPolicy
+		gpus       int64
+		usedMinors []int32
+		want       *framework.Status
+		wantMinors []int32
+		wantLevel  apiext.DeviceTopologyLevel
+	}{
+		{
+			name:       "Restricted allocates the 4 GPUs in the free NVLink island",
+			policy:     config.DeviceGPUTopologyRestricted,
+			gpus:       4,
+			usedMinors: []int32{0},
+			wantMinors: []int32{4, 5, 6, 7},
+			wantLevel:  apiext.DeviceTopologyLevelNVLink,
+		},
+		{
+			name:       "Restricted rejects the 4 GPUs across the NVLink islands",
+			policy:     config.DeviceGPUTopologyRestricted,
+			gpus:       4,
+			usedMinors: []int32{0, 4},
+			want:       framework.NewStatus(framework.Unschedulable, ErrUnalignedGPUTopology),
+		},
+		{
+			name:       "BestEffort allocates the 4 GPUs across the NVLink islands",
+			policy:     config.DeviceGPUTopologyBestEffort,
+			gpus:       4,
+			usedMinors: []int32{0, 4},
+			wantMinors: []int32{1, 3, 5, 7},
+			wantLevel:  apiext.DeviceTopologyLevelNUMANode,
+		},
+		{
+			name:       "Restricted allocates the 2 GPUs on the same PCIe switch",
+			policy:     config.DeviceGPUTopologyRestricted,
+			gpus:       2,
+			usedMinors: []int32{0},
+			wantMinors: []int32{2, 3},
+			wantLevel:  apiext.DeviceTopologyLevelNVLink,
+		},
+		{
+			name:       "Restricted allocates the 8 GPUs over both NVLink islands",
+			policy:     config.DeviceGPUTopologyRestricted,
+			gpus:       8,
+			wantMinors: []int32{0, 1, 2, 3, 4, 5, 6, 7},
+			wantLevel:  apiext.DeviceTopologyLevelNode,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := &Plugin{nodeDeviceCache: tt.nodeDeviceCache, allocator: &defaultAllocator{}}
+			deviceCache := newNodeDeviceCache()
+			deviceCache.updateNodeDevice("test-node", device)
+			nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+			usedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "used", UID: "used"}}
+			var used []*apiext.DeviceAllocation
+			for _, minor := range tt.usedMinors {
+				used = append(used, &apiext.DeviceAllocation{Minor: minor, Resources: wholeGPU.DeepCopy()})
+			}
+			nodeDeviceInfo.updateCacheUsed(apiext.DeviceAllocations{schedulingv1alpha1.GPU: used}, usedPod, true)
+
+			p := &Plugin{
+				nodeDeviceCache: deviceCache,
+				allocator:       NewDefaultAllocator(AllocatorOptions{GPUTopologyPolicy: tt.policy}),
+			}
 			cycleState := framework.NewCycleState()
-			if tt.state != nil {
-				cycleState.Write(stateKey, tt.state)
+			cycleState.Write(stateKey, &preFilterState{
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.GPUCore:        *resource.NewQuantity(100*tt.gpus, resource.DecimalSI),
+					apiext.GPUMemoryRatio: *resource.NewQuantity(100*tt.gpus, resource.DecimalSI),
+					apiext.GPUMemory:      *resource.NewQuantity(16*1024*1024*1024*tt.gpus, resource.BinarySI),
+				},
+			})
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test"}}
+			assert.Equal(t, tt.want, p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+			if tt.want != nil {
+				return
 			}
-			status := p.Filter(context.TODO(), cycleState, tt.pod, tt.nodeInfo)
-			assert.Equal(t, tt.want, status)
+
+			assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node").IsSuccess())
+			state, status := getPreFilterState(cycleState)
+			assert.True(t, status.IsSuccess())
+			var gotMinors []int32
+			for _, allocation := range state.allocationResult[schedulingv1alpha1.GPU] {
+				gotMinors = append(gotMinors, allocation.Minor)
+			}
+			assert.Equal(t, tt.wantMinors, gotMinors)
+			assert.Equal(t, tt.wantLevel, state.allocatedTopology[schedulingv1alpha1.GPU].Level)
 		})
 	}
 }
@@ -1513,6 +2903,44 @@ func Test_Plugin_Reserve(t *testing.T) {
 	}
 }
 
+func Test_Plugin_ReserveStatistics(t *testing.T) {
+	deviceCache := newNodeDeviceCache()
+	deviceCache.createNodeDevice("test-node").resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: {
+			0: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.GPUMemory:      resource.MustParse("16Gi"),
+			},
+		},
+	})
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	reserve := func(name string) *framework.Status {
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, &preFilterState{
+			convertedDeviceResource: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("50"),
+				apiext.GPUMemoryRatio: resource.MustParse("50"),
+				apiext.GPUMemory:      resource.MustParse("8Gi"),
+			},
+		})
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		return p.Reserve(context.TODO(), cycleState, pod, "test-node")
+	}
+
+	// the GPU fits two pods, and the latter ones fail
+	assert.True(t, reserve("pod-1").IsSuccess())
+	assert.True(t, reserve("pod-2").IsSuccess())
+	assert.False(t, reserve("pod-3").IsSuccess())
+	assert.False(t, reserve("pod-4").IsSuccess())
+	assert.False(t, reserve("pod-5").IsSuccess())
+
+	summary, ok := deviceCache.getNodeDeviceSummary("test-node")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), summary.ReserveSucceeded)
+	assert.Equal(t, int64(3), summary.ReserveFailed)
+}
+
 func sortDeviceAllocations(deviceAllocations apiext.DeviceAllocations) {
 	for k, v := range deviceAllocations {
 		sort.Slice(v, func(i, j int) bool {
@@ -1522,6 +2950,208 @@ func sortDeviceAllocations(deviceAllocations apiext.DeviceAllocations) {
 	}
 }
 
+type fakeNamedAllocator struct {
+	defaultAllocator
+	name string
+}
+
+func (a *fakeNamedAllocator) Name() string {
+	return a.name
+}
+
+func Test_Plugin_ReserveAllocatorPolicy(t *testing.T) {
+	deviceCache := newNodeDeviceCache()
+	for _, nodeName := range []string{"test-node-1", "test-node-2"} {
+		deviceCache.createNodeDevice(nodeName).resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: {
+				0: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			},
+		})
+	}
+	reserve := func(p *Plugin, name, nodeName string) *framework.Status {
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, &preFilterState{
+			convertedDeviceResource: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("50"),
+				apiext.GPUMemoryRatio: resource.MustParse("50"),
+				apiext.GPUMemory:      resource.MustParse("8Gi"),
+			},
+		})
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		return p.Reserve(context.TODO(), cycleState, pod, nodeName)
+	}
+	defaultPlugin := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	binpackPlugin := &Plugin{nodeDeviceCache: deviceCache, allocator: &fakeNamedAllocator{name: "binpack"}}
+
+	summaries := deviceCache.getAllNodeDeviceSummary()
+	assert.Empty(t, summaries["test-node-1"].AllocatorPolicy)
+	assert.Nil(t, summaries["test-node-1"].AllocatorPolicyChangedTime)
+
+	assert.True(t, reserve(defaultPlugin, "pod-1", "test-node-1").IsSuccess())
+	assert.True(t, reserve(binpackPlugin, "pod-2", "test-node-2").IsSuccess())
+	summaries = deviceCache.getAllNodeDeviceSummary()
+	assert.Equal(t, defaultAllocatorName, summaries["test-node-1"].AllocatorPolicy)
+	assert.NotNil(t, summaries["test-node-1"].AllocatorPolicyChangedTime)
+	assert.Equal(t, "binpack", summaries["test-node-2"].AllocatorPolicy)
+	assert.NotNil(t, summaries["test-node-2"].AllocatorPolicyChangedTime)
+
+	// the changed time is kept while the policy is unchanged
+	changedTime := summaries["test-node-2"].AllocatorPolicyChangedTime
+	assert.True(t, reserve(binpackPlugin, "pod-3", "test-node-2").IsSuccess())
+	summary, ok := deviceCache.getNodeDeviceSummary("test-node-2")
+	assert.True(t, ok)
+	assert.Equal(t, "binpack", summary.AllocatorPolicy)
+	assert.Equal(t, changedTime, summary.AllocatorPolicyChangedTime)
+}
+
+func Test_Plugin_ReserveWithGPUTopology(t *testing.T) {
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+	// NUMA node 0 has GPU 0 and 1, and NUMA node 1 has GPU 2 and 3
+	for minor := int32(0); minor < 4; minor++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			Minor:     pointer.Int32(minor),
+			Type:      schedulingv1alpha1.GPU,
+			Health:    true,
+			Resources: wholeGPU.DeepCopy(),
+			Topology:  &schedulingv1alpha1.DeviceTopology{NodeID: minor / 2},
+		})
+	}
+	tests := []struct {
+		name         string
+		usedMinors   []int32
+		wantMinors   []int32
+		wantTopology apiext.DeviceAllocatedTopology
+	}{
+		{
+			name:       "allocate GPUs of the same NUMA node",
+			usedMinors: []int32{0},
+			wantMinors: []int32{2, 3},
+			wantTopology: apiext.DeviceAllocatedTopology{
+				schedulingv1alpha1.GPU: {NUMANodes: []int32{1}, Level: apiext.DeviceTopologyLevelNUMANode},
+			},
+		},
+		{
+			name:       "still allocate GPUs with degraded topology",
+			usedMinors: []int32{1, 2},
+			wantMinors: []int32{0, 3},
+			wantTopology: apiext.DeviceAllocatedTopology{
+				schedulingv1alpha1.GPU: {NUMANodes: []int32{0, 1}, Degraded: true, Level: apiext.DeviceTopologyLevelNode},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceCache := newNodeDeviceCache()
+			deviceCache.updateNodeDevice("test-node", device)
+			nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+			usedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "used", UID: "used"}}
+			var used []*apiext.DeviceAllocation
+			for _, minor := range tt.usedMinors {
+				used = append(used, &apiext.DeviceAllocation{Minor: minor, Resources: wholeGPU.DeepCopy()})
+			}
+			nodeDeviceInfo.updateCacheUsed(apiext.DeviceAllocations{schedulingv1alpha1.GPU: used}, usedPod, true)
+
+			testPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test"}}
+			p := &Plugin{
+				nodeDeviceCache: deviceCache,
+				allocator:       &defaultAllocator{},
+				handle:          &fakeExtendedHandle{cs: kubefake.NewSimpleClientset(testPod)},
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("200"),
+					apiext.GPUMemoryRatio: resource.MustParse("200"),
+					apiext.GPUMemory:      resource.MustParse("32Gi"),
+				},
+			})
+			assert.True(t, p.Reserve(context.TODO(), cycleState, testPod, "test-node").IsSuccess())
+			state, status := getPreFilterState(cycleState)
+			assert.True(t, status.IsSuccess())
+			var gotMinors []int32
+			for _, allocation := range state.allocationResult[schedulingv1alpha1.GPU] {
+				gotMinors = append(gotMinors, allocation.Minor)
+			}
+			assert.Equal(t, tt.wantMinors, gotMinors)
+			assert.Equal(t, tt.wantTopology, state.allocatedTopology)
+
+			assert.True(t, p.PreBind(context.TODO(), cycleState, testPod, "test-node").IsSuccess())
+			boundPod, err := p.handle.ClientSet().CoreV1().Pods("default").Get(context.TODO(), "test", metav1.GetOptions{})
+			assert.NoError(t, err)
+			topology, err := apiext.GetDeviceAllocatedTopology(boundPod.Annotations)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTopology, topology)
+		})
+	}
+}
+
+func Test_Plugin_ReserveDeviceNUMAHint(t *testing.T) {
+	// the node has two NUMA nodes, and the GPUs are attached only to the NUMA node 1
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+	for minor := int32(0); minor < 2; minor++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			Minor:  pointer.Int32(minor),
+			Type:   schedulingv1alpha1.GPU,
+			Health: true,
+			Resources: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.GPUMemory:      resource.MustParse("16Gi"),
+			},
+			Topology: &schedulingv1alpha1.DeviceTopology{NodeID: 1},
+		})
+	}
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("test-node", device)
+	p := &Plugin{
+		nodeDeviceCache: deviceCache,
+		allocator:       &defaultAllocator{},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.NvidiaGPU: resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.Equal(t, &frameworkext.DeviceNUMAHint{}, frameworkext.GetDeviceNUMAHint(cycleState))
+
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node").IsSuccess())
+	assert.Equal(t, &frameworkext.DeviceNUMAHint{NodeName: "test-node", NUMANodes: []int{1}}, frameworkext.GetDeviceNUMAHint(cycleState))
+
+	p.Unreserve(context.TODO(), cycleState, pod, "test-node")
+	assert.Equal(t, &frameworkext.DeviceNUMAHint{}, frameworkext.GetDeviceNUMAHint(cycleState))
+
+	// the pod requesting no devices does not publish the hint
+	cycleState = framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, &corev1.Pod{}).IsSuccess())
+	assert.Nil(t, frameworkext.GetDeviceNUMAHint(cycleState))
+}
+
 func Test_Plugin_Unreserve(t *testing.T) {
 	namespacedName := types.NamespacedName{
 		Namespace: "default",
@@ -1845,6 +3475,7 @@ func Test_Plugin_Unreserve(t *testing.T) {
 				assert.Empty(t, tt.args.state.allocationResult)
 				stateCmpOpts := []cmp.Option{
 					cmp.AllowUnexported(nodeDevice{}),
+					cmp.AllowUnexported(reserveStatistics{}),
 					cmp.AllowUnexported(nodeDeviceCache{}),
 					cmpopts.IgnoreFields(nodeDevice{}, "lock"),
 					cmpopts.IgnoreFields(nodeDeviceCache{}, "lock"),
@@ -1953,6 +3584,208 @@ func Test_Plugin_PreBind(t *testing.T) {
 	}
 }
 
+func Test_Plugin_PreBindPatchRetry(t *testing.T) {
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test",
+		},
+	}
+	gpuCore := resource.MustParse("100")
+	tests := []struct {
+		name         string
+		policy       *config.PatchRetryPolicy
+		conflicts    int
+		wantAttempts int
+		wantSuccess  bool
+	}{
+		{
+			name:         "give up after the configured attempts",
+			policy:       &config.PatchRetryPolicy{MaxAttempts: pointer.Int32Ptr(2), InitialBackoff: &metav1.Duration{Duration: time.Millisecond}},
+			conflicts:    10,
+			wantAttempts: 2,
+		},
+		{
+			name:         "succeed within the configured attempts",
+			policy:       &config.PatchRetryPolicy{MaxAttempts: pointer.Int32Ptr(6), InitialBackoff: &metav1.Duration{Duration: time.Millisecond}},
+			conflicts:    5,
+			wantAttempts: 6,
+			wantSuccess:  true,
+		},
+		{
+			name:         "default to retry.DefaultBackoff",
+			conflicts:    10,
+			wantAttempts: retry.DefaultBackoff.Steps,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := kubefake.NewSimpleClientset(testPod)
+			attempts := 0
+			cs.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+				attempts++
+				if attempts <= tt.conflicts {
+					return true, nil, apierrors.NewConflict(corev1.Resource("pods"), testPod.Name, fmt.Errorf("conflict"))
+				}
+				return false, nil, nil
+			})
+			p := &Plugin{
+				nodeDeviceCache:     newNodeDeviceCache(),
+				handle:              &fakeExtendedHandle{cs: cs},
+				allocator:           &defaultAllocator{},
+				preBindPatchBackoff: newPatchBackoff(tt.policy),
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{
+				allocationResult: apiext.DeviceAllocations{
+					schedulingv1alpha1.GPU: {
+						{Minor: 0, Resources: corev1.ResourceList{apiext.GPUCore: gpuCore}},
+					},
+				},
+			})
+			status := p.PreBind(context.TODO(), cycleState, testPod, "test-node")
+			assert.Equal(t, tt.wantSuccess, status.IsSuccess())
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func Test_Plugin_PreBindVerifyDeviceAllocations(t *testing.T) {
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test",
+		},
+	}
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 0, Resources: corev1.ResourceList{apiext.GPUCore: resource.MustParse("100")}},
+		},
+		schedulingv1alpha1.RDMA: {
+			{Minor: 1, Resources: corev1.ResourceList{apiext.KoordRDMA: resource.MustParse("100")}},
+		},
+	}
+	// the patch partially applied, the RDMA allocations are missing
+	partialAnnotations := func() map[string]string {
+		partialPod := &corev1.Pod{}
+		assert.NoError(t, apiext.SetDeviceAllocations(partialPod, apiext.DeviceAllocations{
+			schedulingv1alpha1.GPU: allocations[schedulingv1alpha1.GPU],
+		}))
+		return partialPod.Annotations
+	}
+	tests := []struct {
+		name         string
+		partial      int
+		wantPatches  int
+		wantSuccess  bool
+		wantVerified bool
+	}{
+		{
+			name:         "verified at the first attempt",
+			wantPatches:  1,
+			wantSuccess:  true,
+			wantVerified: true,
+		},
+		{
+			name:         "patch again if the RDMA allocations are missing",
+			partial:      2,
+			wantPatches:  3,
+			wantSuccess:  true,
+			wantVerified: true,
+		},
+		{
+			name:        "give up after the configured attempts",
+			partial:     10,
+			wantPatches: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := kubefake.NewSimpleClientset(testPod)
+			patches := 0
+			cs.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+				patches++
+				if patches > tt.partial {
+					return false, nil, nil
+				}
+				partialPod := testPod.DeepCopy()
+				partialPod.Annotations = partialAnnotations()
+				return true, partialPod, nil
+			})
+			cs.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+				assert.Fail(t, "the patched pod should be verified without reading it again")
+				return false, nil, nil
+			})
+			p := &Plugin{
+				nodeDeviceCache: newNodeDeviceCache(),
+				handle:          &fakeExtendedHandle{cs: cs},
+				allocator:       &defaultAllocator{},
+				preBindPatchBackoff: newPatchBackoff(&config.PatchRetryPolicy{
+					MaxAttempts:    pointer.Int32Ptr(4),
+					InitialBackoff: &metav1.Duration{Duration: time.Millisecond},
+				}),
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{allocationResult: allocations})
+			status := p.PreBind(context.TODO(), cycleState, testPod, "test-node")
+			assert.Equal(t, tt.wantSuccess, status.IsSuccess())
+			assert.Equal(t, tt.wantPatches, patches)
+
+			cs.ReactionChain = cs.ReactionChain[1:]
+			pod, err := cs.CoreV1().Pods(testPod.Namespace).Get(context.TODO(), testPod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			if tt.wantVerified {
+				got, err := apiext.GetDeviceAllocations(pod.Annotations)
+				assert.NoError(t, err)
+				assert.True(t, equalDeviceAllocations(allocations, got))
+			}
+		})
+	}
+
+	t.Run("verify the reservation of the reserve pod", func(t *testing.T) {
+		reservation := &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-reservation", UID: "123456"},
+		}
+		reservePod := testPod.DeepCopy()
+		reservePod.Annotations = map[string]string{
+			util.AnnotationReservePod:      "true",
+			util.AnnotationReservationName: reservation.Name,
+		}
+		koordClientSet := koordfake.NewSimpleClientset(reservation)
+		patches := 0
+		koordClientSet.PrependReactor("patch", "reservations", func(action k8stesting.Action) (bool, apiruntime.Object, error) {
+			patches++
+			if patches > 1 {
+				return false, nil, nil
+			}
+			partialReservation := reservation.DeepCopy()
+			partialReservation.Annotations = partialAnnotations()
+			return true, partialReservation, nil
+		})
+		extendHandle, _ := frameworkext.NewExtendedHandle(frameworkext.WithKoordinatorClientSet(koordClientSet))
+		p := &Plugin{
+			nodeDeviceCache: newNodeDeviceCache(),
+			handle:          &fakeExtendedHandle{ExtendedHandle: extendHandle, cs: kubefake.NewSimpleClientset()},
+			allocator:       &defaultAllocator{},
+			preBindPatchBackoff: newPatchBackoff(&config.PatchRetryPolicy{
+				MaxAttempts:    pointer.Int32Ptr(4),
+				InitialBackoff: &metav1.Duration{Duration: time.Millisecond},
+			}),
+		}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, &preFilterState{allocationResult: allocations})
+		status := p.PreBind(context.TODO(), cycleState, reservePod, "test-node")
+		assert.True(t, status.IsSuccess())
+		assert.Equal(t, 2, patches)
+
+		got, err := koordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), reservation.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		gotAllocations, err := apiext.GetDeviceAllocations(got.Annotations)
+		assert.NoError(t, err)
+		assert.True(t, equalDeviceAllocations(allocations, gotAllocations))
+	})
+}
+
 type fakeAllocator struct {
 }
 
@@ -20,12 +20,17 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
+	listercorev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 func registerPodEventHandler(deviceCache *nodeDeviceCache, sharedInformerFactory informers.SharedInformerFactory) {
@@ -35,41 +40,106 @@ func registerPodEventHandler(deviceCache *nodeDeviceCache, sharedInformerFactory
 		UpdateFunc: deviceCache.onPodUpdate,
 		DeleteFunc: deviceCache.onPodDelete,
 	}
+	// the pods stuck in Terminating past their deletion grace period may not be updated anymore,
+	// so they are resynced once their grace period expires
+	deviceCache.startTerminatingPodsResync(sharedInformerFactory.Core().V1().Pods().Lister(), context.TODO().Done())
 	// make sure Pods are loaded before scheduler starts working
 	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), sharedInformerFactory, podInformer, eventHandler)
 }
 
+// startTerminatingPodsResync starts to release the devices of the terminating pods once their deletion grace period expires.
+func (n *nodeDeviceCache) startTerminatingPodsResync(podLister listercorev1.PodLister, stopCh <-chan struct{}) {
+	if !n.releaseTerminatedPods {
+		return
+	}
+	n.podLister = podLister
+	n.terminatingPods = workqueue.NewDelayingQueueWithCustomClock(n.clock, "DeviceShareTerminatingPods")
+	go func() {
+		<-stopCh
+		n.terminatingPods.ShutDown()
+	}()
+	go func() {
+		for n.processNextTerminatingPod() {
+		}
+	}()
+}
+
+// enqueueTerminatingPod resyncs the terminating pod once its deletion grace period expires.
+func (n *nodeDeviceCache) enqueueTerminatingPod(pod *corev1.Pod) {
+	if n.terminatingPods == nil || pod.DeletionTimestamp == nil {
+		return
+	}
+	n.terminatingPods.AddAfter(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, pod.DeletionTimestamp.Sub(n.clock.Now()))
+}
+
+// processNextTerminatingPod releases the devices of one terminating pod off the queue. It returns false when it's time to quit.
+func (n *nodeDeviceCache) processNextTerminatingPod() bool {
+	item, quit := n.terminatingPods.Get()
+	if quit {
+		return false
+	}
+	defer n.terminatingPods.Done(item)
+
+	key := item.(types.NamespacedName)
+	pod, err := n.podLister.Pods(key.Namespace).Get(key.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "failed to get the terminating pod", "pod", key)
+		}
+		// the deleted pod is released by the delete event
+		return true
+	}
+	if !n.isPodReleased(pod) {
+		// the pod is enqueued again by its updates if it is still terminating
+		n.enqueueTerminatingPod(pod)
+		return true
+	}
+	if n.isPodAccounted(pod) {
+		n.deletePod(pod)
+		klog.V(4).InfoS("pod past its deletion grace period, release the devices", "pod", klog.KObj(pod))
+	}
+	return true
+}
+
 func (n *nodeDeviceCache) onPodAdd(obj interface{}) {
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
 		klog.Errorf("pod cache add failed to parse, obj %T", obj)
 		return
 	}
+	if n.isPodReleased(pod) {
+		return
+	}
+	n.addPod(pod)
+	n.enqueueTerminatingPod(pod)
+}
 
-	devicesAllocation, err := apiext.GetDeviceAllocations(pod.Annotations)
-	if err != nil {
-		klog.Errorf("failed to get device allocation from pod %v, err: %v", klog.KObj(pod), err)
+func (n *nodeDeviceCache) onPodUpdate(oldObj, newObj interface{}) {
+	oldPod, ok := oldObj.(*corev1.Pod)
+	if !ok {
 		return
 	}
-	if len(devicesAllocation) == 0 {
+	newPod, ok := newObj.(*corev1.Pod)
+	if !ok {
 		return
 	}
 
-	info := n.getNodeDevice(pod.Spec.NodeName)
-	if info == nil {
-		info = n.createNodeDevice(pod.Spec.NodeName)
-		klog.V(5).Infof("node device cache not found, nodeName: %v, pod: %v, createNodeDevice", pod.Spec.NodeName, klog.KObj(pod))
+	// the pod past its deletion grace period is released by whichever update comes first, so the cache rather than
+	// the old pod tells whether the devices are still accounted
+	if n.isPodReleased(newPod) {
+		if n.isPodAccounted(newPod) {
+			n.deletePod(newPod)
+			klog.V(4).InfoS("pod terminated, release the devices", "pod", klog.KObj(newPod), "phase", newPod.Status.Phase)
+		}
+	} else if n.isPodReleased(oldPod) {
+		// a terminated pod is not expected to come back, re-account its devices anyway to avoid over-commitment
+		klog.Warningf("pod %v transitioned from phase %v to %v, re-account the devices",
+			klog.KObj(newPod), oldPod.Status.Phase, newPod.Status.Phase)
+		n.addPod(newPod)
+	}
+	if oldPod.DeletionTimestamp == nil && !n.isPodReleased(newPod) {
+		n.enqueueTerminatingPod(newPod)
 	}
-
-	info.lock.Lock()
-	defer info.lock.Unlock()
-
-	info.updateCacheUsed(devicesAllocation, pod, true)
-	klog.V(5).InfoS("pod cache added", "pod", klog.KObj(pod))
-}
-
-func (n *nodeDeviceCache) onPodUpdate(oldObj, newObj interface{}) {
-	return
 }
 
 func (n *nodeDeviceCache) onPodDelete(obj interface{}) {
@@ -87,7 +157,73 @@ func (n *nodeDeviceCache) onPodDelete(obj interface{}) {
 	default:
 		return
 	}
+	n.deletePod(pod)
+}
+
+// isPodReleased checks if the devices of the pod should be released before the pod is deleted.
+// A pod is released when it is Succeeded or Failed and none of its containers are still running, since the phase
+// reported right after a kubelet restart may be stale. A pod still not deleted past its deletion grace period is
+// released as well.
+func (n *nodeDeviceCache) isPodReleased(pod *corev1.Pod) bool {
+	if !n.releaseTerminatedPods {
+		return false
+	}
+	if pod.DeletionTimestamp != nil && n.clock.Now().After(pod.DeletionTimestamp.Time) {
+		return true
+	}
+	if !util.IsPodTerminated(pod) {
+		return false
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Running != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isPodAccounted checks if the devices of the pod are accounted in the cache.
+func (n *nodeDeviceCache) isPodAccounted(pod *corev1.Pod) bool {
+	info := n.getNodeDevice(pod.Spec.NodeName)
+	if info == nil {
+		return false
+	}
+	info.lock.RLock()
+	defer info.lock.RUnlock()
+	return len(info.getPodAllocations(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})) > 0
+}
+
+func (n *nodeDeviceCache) addPod(pod *corev1.Pod) {
+	devicesAllocation, err := apiext.GetDeviceAllocations(pod.Annotations)
+	if err != nil {
+		klog.Errorf("failed to get device allocation from pod %v, err: %v", klog.KObj(pod), err)
+		return
+	}
+	if len(devicesAllocation) == 0 {
+		return
+	}
+
+	info := n.getNodeDevice(pod.Spec.NodeName)
+	if info == nil {
+		info = n.createNodeDevice(pod.Spec.NodeName)
+		klog.V(5).Infof("node device cache not found, nodeName: %v, pod: %v, createNodeDevice", pod.Spec.NodeName, klog.KObj(pod))
+	}
 
+	info.lock.Lock()
+	defer info.lock.Unlock()
+
+	// the pod allocated from a reservation consumes the reserved devices, unless it is already assumed in Reserve
+	if reservationAllocated, err := apiext.GetReservationAllocated(pod); err != nil {
+		klog.Errorf("failed to get reservation allocated from pod %v, err: %v", klog.KObj(pod), err)
+	} else if reservationAllocated != nil &&
+		len(info.getPodAllocations(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})) == 0 {
+		info.consumeReservedDevices(reservationAllocated.UID, pod, devicesAllocation)
+	}
+	info.updateCacheUsed(devicesAllocation, pod, true)
+	klog.V(5).InfoS("pod cache added", "pod", klog.KObj(pod))
+}
+
+func (n *nodeDeviceCache) deletePod(pod *corev1.Pod) {
 	devicesAllocation, err := apiext.GetDeviceAllocations(pod.Annotations)
 	if err != nil {
 		klog.Errorf("failed to get device allocation from pod %v, err: %v", klog.KObj(pod), err)
@@ -106,6 +242,17 @@ func (n *nodeDeviceCache) onPodDelete(obj interface{}) {
 	info.lock.Lock()
 	defer info.lock.Unlock()
 
+	if len(n.allocationCooldowns) > 0 {
+		now := n.clock.Now()
+		podNamespacedName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		for deviceType, allocations := range devicesAllocation {
+			// the pod may be deleted after it is terminated, only record the first release
+			if _, ok := info.allocateSet[deviceType][podNamespacedName]; ok && n.allocationCooldowns[deviceType] > 0 {
+				info.recordDeviceReleased(deviceType, allocations, now, n.allocationCooldowns[deviceType])
+			}
+		}
+	}
 	info.updateCacheUsed(devicesAllocation, pod, false)
+	info.releaseReservedDevices(pod)
 	klog.V(5).InfoS("pod cache deleted", "pod", klog.KObj(pod))
 }
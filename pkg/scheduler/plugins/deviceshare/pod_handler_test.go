@@ -17,7 +17,10 @@ limitations under the License.
 package deviceshare
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -26,6 +29,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -494,6 +500,7 @@ func Test_nodeDeviceCache_onPodDelete(t *testing.T) {
 			deviceCache.onPodDelete(tt.pod)
 			stateCmpOpts := []cmp.Option{
 				cmp.AllowUnexported(nodeDevice{}),
+				cmp.AllowUnexported(reserveStatistics{}),
 				cmpopts.IgnoreFields(nodeDevice{}, "lock"),
 			}
 			if diff := cmp.Diff(tt.wantCache, deviceCache.nodeDeviceInfos, stateCmpOpts...); diff != "" {
@@ -502,3 +509,252 @@ func Test_nodeDeviceCache_onPodDelete(t *testing.T) {
 		})
 	}
 }
+
+func Test_nodeDeviceCache_releaseTerminatedPods(t *testing.T) {
+	newCache := func(releaseTerminatedPods bool) *nodeDeviceCache {
+		deviceCache := newNodeDeviceCache()
+		deviceCache.releaseTerminatedPods = releaseTerminatedPods
+		deviceCache.createNodeDevice("test-node").resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: {
+				0: corev1.ResourceList{
+					apiext.GPUCore:        resource.MustParse("100"),
+					apiext.GPUMemoryRatio: resource.MustParse("100"),
+					apiext.GPUMemory:      resource.MustParse("16Gi"),
+				},
+			},
+		})
+		return deviceCache
+	}
+	newPod := func(name string, phase corev1.PodPhase, containerRunning bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Annotations: map[string]string{
+					apiext.AnnotationDeviceAllocated: `{"gpu":[{"minor":0,"resources":{"kubernetes.io/gpu-core":"100","kubernetes.io/gpu-memory":"16Gi","kubernetes.io/gpu-memory-ratio":"100"}}]}`,
+				},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node",
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: "test-container-a",
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{},
+						},
+					},
+				},
+			},
+		}
+		if containerRunning {
+			pod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{},
+			}
+		}
+		return pod
+	}
+	podRequest := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	tryAllocate := func(deviceCache *nodeDeviceCache) (apiext.DeviceAllocations, error) {
+		allocator := &defaultAllocator{}
+		nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+		nodeDeviceInfo.lock.Lock()
+		defer nodeDeviceInfo.lock.Unlock()
+		return allocator.Allocate("test-node", newPod("new-pod", corev1.PodPending, false), podRequest, nodeDeviceInfo)
+	}
+
+	t.Run("succeeded pod releases the devices before deleted", func(t *testing.T) {
+		deviceCache := newCache(true)
+		runningPod := newPod("old-pod", corev1.PodRunning, true)
+		deviceCache.onPodAdd(runningPod)
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+
+		succeededPod := newPod("old-pod", corev1.PodSucceeded, false)
+		deviceCache.onPodUpdate(runningPod, succeededPod)
+		allocations, err := tryAllocate(deviceCache)
+		assert.NoError(t, err)
+		assert.Len(t, allocations[schedulingv1alpha1.GPU], 1)
+		assert.Equal(t, int32(0), allocations[schedulingv1alpha1.GPU][0].Minor)
+
+		// the released pod is deleted later
+		deviceCache.onPodDelete(succeededPod)
+		_, err = tryAllocate(deviceCache)
+		assert.NoError(t, err)
+	})
+
+	t.Run("terminated pod is not accounted when added", func(t *testing.T) {
+		deviceCache := newCache(true)
+		deviceCache.onPodAdd(newPod("old-pod", corev1.PodFailed, false))
+		_, err := tryAllocate(deviceCache)
+		assert.NoError(t, err)
+	})
+
+	t.Run("stale phase with running containers keeps the devices", func(t *testing.T) {
+		deviceCache := newCache(true)
+		runningPod := newPod("old-pod", corev1.PodRunning, true)
+		deviceCache.onPodAdd(runningPod)
+		deviceCache.onPodUpdate(runningPod, newPod("old-pod", corev1.PodFailed, true))
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+	})
+
+	t.Run("pod transitioned back from terminated phase is re-accounted", func(t *testing.T) {
+		deviceCache := newCache(true)
+		succeededPod := newPod("old-pod", corev1.PodSucceeded, false)
+		deviceCache.onPodAdd(succeededPod)
+		deviceCache.onPodUpdate(succeededPod, newPod("old-pod", corev1.PodRunning, true))
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+	})
+
+	t.Run("release terminated pods disabled", func(t *testing.T) {
+		deviceCache := newCache(false)
+		runningPod := newPod("old-pod", corev1.PodRunning, true)
+		deviceCache.onPodAdd(runningPod)
+		deviceCache.onPodUpdate(runningPod, newPod("old-pod", corev1.PodSucceeded, false))
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+	})
+}
+
+func Test_nodeDeviceCache_releaseCompletedJobPod(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	newCache := func() *nodeDeviceCache {
+		deviceCache := newNodeDeviceCache()
+		deviceCache.releaseTerminatedPods = true
+		deviceCache.clock = fakeClock
+		total := deviceResources{}
+		for minor := 0; minor < 8; minor++ {
+			total[minor] = corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.GPUMemory:      resource.MustParse("16Gi"),
+			}
+		}
+		deviceCache.createNodeDevice("test-node").resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: total,
+		})
+		return deviceCache
+	}
+	allocations := apiext.DeviceAllocations{}
+	for minor := 0; minor < 8; minor++ {
+		allocations[schedulingv1alpha1.GPU] = append(allocations[schedulingv1alpha1.GPU], &apiext.DeviceAllocation{
+			Minor: int32(minor),
+			Resources: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.GPUMemory:      resource.MustParse("16Gi"),
+			},
+		})
+	}
+	data, err := json.Marshal(allocations)
+	assert.NoError(t, err)
+	newJobPod := func(phase corev1.PodPhase) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "job-pod",
+				Annotations: map[string]string{
+					apiext.AnnotationDeviceAllocated: string(data),
+				},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node",
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+			},
+		}
+		if phase == corev1.PodRunning {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: "main", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}
+		}
+		return pod
+	}
+	podRequest := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("800"),
+		apiext.GPUMemoryRatio: resource.MustParse("800"),
+		apiext.GPUMemory:      resource.MustParse(fmt.Sprintf("%dGi", 8*16)),
+	}
+	tryAllocate := func(deviceCache *nodeDeviceCache) (apiext.DeviceAllocations, error) {
+		allocator := &defaultAllocator{}
+		nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+		nodeDeviceInfo.lock.Lock()
+		defer nodeDeviceInfo.lock.Unlock()
+		pendingPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending-pod"}}
+		return allocator.Allocate("test-node", pendingPod, podRequest, nodeDeviceInfo)
+	}
+
+	t.Run("completed job pod does not block the pending pod", func(t *testing.T) {
+		deviceCache := newCache()
+		runningPod := newJobPod(corev1.PodRunning)
+		deviceCache.onPodAdd(runningPod)
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+
+		succeededPod := newJobPod(corev1.PodSucceeded)
+		deviceCache.onPodUpdate(runningPod, succeededPod)
+		got, err := tryAllocate(deviceCache)
+		assert.NoError(t, err)
+		assert.Len(t, got[schedulingv1alpha1.GPU], 8)
+
+		// the later updates and the deletion of the completed pod do not release the devices again
+		deviceCache.onPodUpdate(succeededPod, succeededPod)
+		deviceCache.onPodDelete(succeededPod)
+		nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+		for minor := 0; minor < 8; minor++ {
+			assert.Equal(t, resource.MustParse("100"), nodeDeviceInfo.deviceFree[schedulingv1alpha1.GPU][minor][apiext.GPUCore])
+		}
+	})
+
+	t.Run("pod past its deletion grace period releases the devices", func(t *testing.T) {
+		deviceCache := newCache()
+		runningPod := newJobPod(corev1.PodRunning)
+		deviceCache.onPodAdd(runningPod)
+
+		deletingPod := newJobPod(corev1.PodRunning)
+		deletionTimestamp := metav1.NewTime(fakeClock.Now().Add(30 * time.Second))
+		deletingPod.DeletionTimestamp = &deletionTimestamp
+		deviceCache.onPodUpdate(runningPod, deletingPod)
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+
+		fakeClock.Step(time.Minute)
+		// the old pod is past the grace period as well by the next update
+		deviceCache.onPodUpdate(deletingPod, deletingPod)
+		_, err = tryAllocate(deviceCache)
+		assert.NoError(t, err)
+	})
+
+	t.Run("pod stuck in Terminating is resynced past its deletion grace period", func(t *testing.T) {
+		deviceCache := newCache()
+		deletingPod := newJobPod(corev1.PodRunning)
+		deletionTimestamp := metav1.NewTime(fakeClock.Now().Add(30 * time.Second))
+		deletingPod.DeletionTimestamp = &deletionTimestamp
+		informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+		podInformer := informerFactory.Core().V1().Pods()
+		assert.NoError(t, podInformer.Informer().GetStore().Add(deletingPod))
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		deviceCache.startTerminatingPodsResync(podInformer.Lister(), stopCh)
+
+		deviceCache.onPodAdd(deletingPod)
+		_, err := tryAllocate(deviceCache)
+		assert.Error(t, err)
+
+		// no more updates of the pod, the devices are released once the grace period expires
+		assert.Eventually(t, func() bool {
+			fakeClock.Step(time.Second)
+			_, err := tryAllocate(deviceCache)
+			return err == nil
+		}, 10*time.Second, 10*time.Millisecond)
+	})
+}
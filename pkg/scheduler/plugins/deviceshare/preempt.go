@@ -0,0 +1,347 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/util/feature"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/features"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+	"k8s.io/kubernetes/pkg/scheduler/util"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+type candidate struct {
+	victims *extenderv1.Victims
+	name    string
+}
+
+// Victims returns s.victims.
+func (s *candidate) Victims() *extenderv1.Victims {
+	return s.victims
+}
+
+// Name returns s.name.
+func (s *candidate) Name() string {
+	return s.name
+}
+
+// preempt returns the node nominated for the pod lacking devices, on which the lower priority pods using the devices
+// are preempted. It follows the DefaultPreemption, but only the nodes lacking devices are the candidates, and only
+// the pods using the devices are the victims. The node is not nominated if the pod does not fit even if all the
+// lower priority pods using the devices are preempted.
+func (p *Plugin) preempt(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (string, *framework.Status) {
+	nodeLister := p.handle.SnapshotSharedLister().NodeInfos()
+
+	// Fetch the latest version of <pod>, the same as the DefaultPreemption.
+	podNamespace, podName := pod.Namespace, pod.Name
+	pod, err := p.podLister.Pods(pod.Namespace).Get(pod.Name)
+	if err != nil {
+		klog.ErrorS(err, "getting the updated preemptor pod object", "pod", klog.KRef(podNamespace, podName))
+		return "", framework.AsStatus(err)
+	}
+
+	if !defaultpreemption.PodEligibleToPreemptOthers(pod, nodeLister, filteredNodeStatusMap[pod.Status.NominatedNodeName]) {
+		klog.V(5).InfoS("Pod is not eligible for more preemption", "pod", klog.KObj(pod))
+		return "", nil
+	}
+
+	candidates, status := p.findCandidates(ctx, state, pod, filteredNodeStatusMap)
+	if !status.IsSuccess() {
+		return "", status
+	}
+
+	candidates, status = defaultpreemption.CallExtenders(p.handle.Extenders(), pod, nodeLister, candidates)
+	if !status.IsSuccess() {
+		return "", status
+	}
+
+	bestCandidate := defaultpreemption.SelectCandidate(candidates)
+	if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
+		return "", nil
+	}
+
+	if status := defaultpreemption.PrepareCandidate(bestCandidate, p.handle, p.handle.ClientSet(), pod, p.Name()); !status.IsSuccess() {
+		return "", status
+	}
+	return bestCandidate.Name(), nil
+}
+
+// findCandidates simulates the preemption on the nodes rejected for lacking devices in parallel.
+func (p *Plugin) findCandidates(
+	ctx context.Context,
+	state *framework.CycleState,
+	pod *corev1.Pod,
+	filteredNodeStatusMap framework.NodeToStatusMap,
+) ([]defaultpreemption.Candidate, *framework.Status) {
+	var potentialNodes []*framework.NodeInfo
+	for nodeName, nodeStatus := range filteredNodeStatusMap {
+		if nodeStatus.Code() != framework.Unschedulable || !nodeStatusHasReason(nodeStatus, ErrInsufficientDevices) {
+			continue
+		}
+		nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+		if err != nil || nodeInfo == nil || nodeInfo.Node() == nil {
+			continue
+		}
+		potentialNodes = append(potentialNodes, nodeInfo)
+	}
+	if len(potentialNodes) == 0 {
+		klog.V(4).InfoS("Preempting the devices will not help schedule pod on any node", "pod", klog.KObj(pod))
+		return nil, nil
+	}
+
+	pdbs, err := getPodDisruptionBudgets(p.pdbLister)
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+
+	var resultLock sync.Mutex
+	var candidates []defaultpreemption.Candidate
+	checkNode := func(i int) {
+		nodeInfoCopy := potentialNodes[i].Clone()
+		stateCopy := state.Clone()
+
+		pods, numPDBViolations, status := p.selectVictimsOnNode(ctx, stateCopy, pod, nodeInfoCopy, pdbs)
+		if !status.IsSuccess() {
+			klog.V(5).InfoS("Preempting the devices will not help schedule pod on node", "pod", klog.KObj(pod), "node", nodeInfoCopy.Node().Name, "status", status.Message())
+			return
+		}
+		resultLock.Lock()
+		defer resultLock.Unlock()
+		candidates = append(candidates, &candidate{
+			victims: &extenderv1.Victims{
+				Pods:             pods,
+				NumPDBViolations: int64(numPDBViolations),
+			},
+			name: nodeInfoCopy.Node().Name,
+		})
+	}
+	p.handle.Parallelizer().Until(ctx, len(potentialNodes), checkNode)
+	return candidates, nil
+}
+
+// selectVictimsOnNode finds the minimum set of the lower priority pods using the devices of the node that should
+// be preempted to make room for the pod, the same as the DefaultPreemption. A higher priority pod is never preempted
+// when a lower priority pod could be, and the pods whose PodDisruptionBudget would be violated are reprieved first.
+func (p *Plugin) selectVictimsOnNode(
+	ctx context.Context,
+	state *framework.CycleState,
+	pod *corev1.Pod,
+	nodeInfo *framework.NodeInfo,
+	pdbs []*policy.PodDisruptionBudget,
+) ([]*corev1.Pod, int, *framework.Status) {
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeInfo.Node().Name)
+	if nodeDeviceInfo == nil {
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingDevice)
+	}
+
+	removePod := func(rpi *framework.PodInfo) error {
+		if err := nodeInfo.RemovePod(rpi.Pod); err != nil {
+			return err
+		}
+		status := p.handle.RunPreFilterExtensionRemovePod(ctx, state, pod, rpi, nodeInfo)
+		if !status.IsSuccess() {
+			return status.AsError()
+		}
+		return nil
+	}
+	addPod := func(api *framework.PodInfo) error {
+		nodeInfo.AddPodInfo(api)
+		status := p.handle.RunPreFilterExtensionAddPod(ctx, state, pod, api, nodeInfo)
+		if !status.IsSuccess() {
+			return status.AsError()
+		}
+		return nil
+	}
+
+	preemptorState, status := getPreFilterState(state)
+	if !status.IsSuccess() {
+		return nil, 0, status
+	}
+	requestedDeviceTypes := p.nodeDeviceCache.getResourceNames().getRequestedDeviceTypes(preemptorState.convertedDeviceResource)
+
+	// As the first step, remove all the lower priority pods using the requested devices and check if the pod fits.
+	var potentialVictims []*framework.PodInfo
+	podPriority := corev1helpers.PodPriority(pod)
+	nodeDeviceInfo.lock.RLock()
+	for _, pi := range nodeInfo.Pods {
+		if corev1helpers.PodPriority(pi.Pod) >= podPriority {
+			continue
+		}
+		allocations := nodeDeviceInfo.getPodAllocations(types.NamespacedName{Namespace: pi.Pod.Namespace, Name: pi.Pod.Name})
+		if holdsAnyDeviceType(allocations, requestedDeviceTypes) {
+			potentialVictims = append(potentialVictims, pi)
+		}
+	}
+	nodeDeviceInfo.lock.RUnlock()
+	if len(potentialVictims) == 0 {
+		message := fmt.Sprintf("No victims using the requested devices found on node %v for preemptor pod %v", nodeInfo.Node().Name, pod.Name)
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, message)
+	}
+	for _, pi := range potentialVictims {
+		if err := removePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+	if status := p.handle.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+		return nil, 0, status
+	}
+
+	// Try to reprieve as many pods as possible. We first try to reprieve the PDB violating victims and then
+	// the other non-violating ones. In both cases, we start from the highest priority victims.
+	var victims []*corev1.Pod
+	numViolatingVictim := 0
+	sort.Slice(potentialVictims, func(i, j int) bool { return util.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod) })
+	violatingVictims, nonViolatingVictims := filterPodsWithPDBViolation(potentialVictims, pdbs)
+	reprievePod := func(pi *framework.PodInfo) (bool, error) {
+		if err := addPod(pi); err != nil {
+			return false, err
+		}
+		fits := p.handle.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo).IsSuccess()
+		if !fits {
+			if err := removePod(pi); err != nil {
+				return false, err
+			}
+			victims = append(victims, pi.Pod)
+			klog.V(5).InfoS("Pod is a potential preemption victim on node", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()))
+		}
+		return fits, nil
+	}
+	for _, pi := range violatingVictims {
+		if fits, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		} else if !fits {
+			numViolatingVictim++
+		}
+	}
+	for _, pi := range nonViolatingVictims {
+		if _, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+	return victims, numViolatingVictim, nil
+}
+
+// filterPodsWithPDBViolation groups the given "pods" into two groups of "violatingPods"
+// and "nonViolatingPods" based on whether their PDBs will be violated if they are
+// preempted.
+// This function is stable and does not change the order of received pods. So, if it
+// receives a sorted list, grouping will preserve the order of the input list.
+func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.PodDisruptionBudget) (violatingPodInfos, nonViolatingPodInfos []*framework.PodInfo) {
+	pdbsAllowed := make([]int32, len(pdbs))
+	for i, pdb := range pdbs {
+		pdbsAllowed[i] = pdb.Status.DisruptionsAllowed
+	}
+
+	for _, podInfo := range podInfos {
+		pod := podInfo.Pod
+		pdbForPodIsViolated := false
+		// A pod with no labels will not match any PDB. So, no need to check.
+		if len(pod.Labels) != 0 {
+			for i, pdb := range pdbs {
+				if pdb.Namespace != pod.Namespace {
+					continue
+				}
+				selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+				if err != nil {
+					continue
+				}
+				// A PDB with a nil or empty selector matches nothing.
+				if selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+					continue
+				}
+
+				// Existing in DisruptedPods means it has been processed in API server,
+				// we don't treat it as a violating case.
+				if _, exist := pdb.Status.DisruptedPods[pod.Name]; exist {
+					continue
+				}
+				// Only decrement the matched pdb when it's not in its <DisruptedPods>;
+				// otherwise we may over-decrement the budget number.
+				pdbsAllowed[i]--
+				// We have found a matching PDB.
+				if pdbsAllowed[i] < 0 {
+					pdbForPodIsViolated = true
+				}
+			}
+		}
+		if pdbForPodIsViolated {
+			violatingPodInfos = append(violatingPodInfos, podInfo)
+		} else {
+			nonViolatingPodInfos = append(nonViolatingPodInfos, podInfo)
+		}
+	}
+	return violatingPodInfos, nonViolatingPodInfos
+}
+
+// getPDBLister returns nil if the PodDisruptionBudget of policy/v1 is not served.
+func getPDBLister(handle framework.Handle) policylisters.PodDisruptionBudgetLister {
+	if !feature.DefaultFeatureGate.Enabled(features.PodDisruptionBudget) {
+		return nil
+	}
+
+	resources, err := handle.ClientSet().Discovery().ServerResourcesForGroupVersion(policy.SchemeGroupVersion.String())
+	if err == nil && resources.Size() != 0 {
+		return handle.SharedInformerFactory().Policy().V1().PodDisruptionBudgets().Lister()
+	}
+
+	return nil
+}
+
+func getPodDisruptionBudgets(pdbLister policylisters.PodDisruptionBudgetLister) ([]*policy.PodDisruptionBudget, error) {
+	if pdbLister != nil {
+		return pdbLister.List(labels.Everything())
+	}
+	return nil, nil
+}
+
+// getRequestedDeviceTypes returns the device types requested by the pod.
+func (d deviceResourceNames) getRequestedDeviceTypes(podRequest corev1.ResourceList) []schedulingv1alpha1.DeviceType {
+	var deviceTypes []schedulingv1alpha1.DeviceType
+	for _, deviceType := range append([]schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU}, d.getCommonDeviceTypes()...) {
+		if d.hasDeviceResource(podRequest, deviceType) {
+			deviceTypes = append(deviceTypes, deviceType)
+		}
+	}
+	return deviceTypes
+}
+
+// holdsAnyDeviceType checks if the allocations contain any device of the given types.
+func holdsAnyDeviceType(allocations apiext.DeviceAllocations, deviceTypes []schedulingv1alpha1.DeviceType) bool {
+	for _, deviceType := range deviceTypes {
+		if len(allocations[deviceType]) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+var _ framework.PodNominator = &fakePodNominator{}
+
+// fakePodNominator nominates no pods.
+type fakePodNominator struct{}
+
+func (f *fakePodNominator) AddNominatedPod(pod *framework.PodInfo, nodeName string) {}
+
+func (f *fakePodNominator) DeleteNominatedPodIfExists(pod *corev1.Pod) {}
+
+func (f *fakePodNominator) UpdateNominatedPod(oldPod *corev1.Pod, newPodInfo *framework.PodInfo) {}
+
+func (f *fakePodNominator) NominatedPodsForNode(nodeName string) []*framework.PodInfo {
+	return nil
+}
+
+func Test_Plugin_PostFilterPreemption(t *testing.T) {
+	partialGPU := func(percent string) corev1.ResourceList {
+		return corev1.ResourceList{
+			apiext.GPUCore:        resource.MustParse(percent),
+			apiext.GPUMemoryRatio: resource.MustParse(percent),
+		}
+	}
+	newPod := func(name string, priority int32, labels map[string]string, percent string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name), Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: "test-node", Priority: pointer.Int32(priority)},
+		}
+		if percent != "" {
+			assert.NoError(t, apiext.SetDeviceAllocations(pod, apiext.DeviceAllocations{
+				schedulingv1alpha1.GPU: {{Minor: 0, Resources: partialGPU(percent)}},
+			}))
+		}
+		return pod
+	}
+	newRDMAPod := func(name string, priority int32) *corev1.Pod {
+		pod := newPod(name, priority, nil, "")
+		assert.NoError(t, apiext.SetDeviceAllocations(pod, apiext.DeviceAllocations{
+			schedulingv1alpha1.RDMA: {{Minor: 1, Resources: corev1.ResourceList{apiext.KoordRDMA: resource.MustParse("100")}}},
+		}))
+		return pod
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+
+	tests := []struct {
+		name          string
+		runningPods   []*corev1.Pod
+		pdbs          []*policy.PodDisruptionBudget
+		nodeStatus    *framework.Status
+		request       corev1.ResourceList
+		enablePreempt bool
+		// preemptorGone removes the preemptor from the lister, so the preemption fails
+		preemptorGone  bool
+		wantNominated  string
+		wantVictims    []string
+		wantNotVictims []string
+	}{
+		{
+			name: "preempt the lowest priority partial GPU user",
+			runningPods: []*corev1.Pod{
+				newPod("low-1", 10, nil, "50"),
+				newPod("low-2", 20, nil, "50"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("50"),
+			enablePreempt:  true,
+			wantNominated:  "test-node",
+			wantVictims:    []string{"low-1"},
+			wantNotVictims: []string{"low-2"},
+		},
+		{
+			name: "preempt all the partial GPU users if needed",
+			runningPods: []*corev1.Pod{
+				newPod("low-1", 10, nil, "50"),
+				newPod("low-2", 20, nil, "50"),
+			},
+			nodeStatus:    framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:       partialGPU("100"),
+			enablePreempt: true,
+			wantNominated: "test-node",
+			wantVictims:   []string{"low-1", "low-2"},
+		},
+		{
+			name: "higher priority pods are never victims",
+			runningPods: []*corev1.Pod{
+				newPod("low", 10, nil, "50"),
+				newPod("high", 200, nil, "50"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("100"),
+			enablePreempt:  true,
+			wantNotVictims: []string{"low", "high"},
+		},
+		{
+			name: "pods without devices are never victims",
+			runningPods: []*corev1.Pod{
+				newPod("low-without-gpu", 10, nil, ""),
+				newPod("high", 200, nil, "75"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("50"),
+			enablePreempt:  true,
+			wantNotVictims: []string{"low-without-gpu", "high"},
+		},
+		{
+			name: "pods without the requested device types are never victims",
+			runningPods: []*corev1.Pod{
+				newRDMAPod("low-with-rdma", 10),
+				newPod("high", 200, nil, "75"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("50"),
+			enablePreempt:  true,
+			wantNotVictims: []string{"low-with-rdma", "high"},
+		},
+		{
+			name: "reprieve the pod protected by PDB first",
+			runningPods: []*corev1.Pod{
+				newPod("protected", 10, map[string]string{"app": "protected"}, "50"),
+				newPod("unprotected", 10, nil, "50"),
+			},
+			pdbs: []*policy.PodDisruptionBudget{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb"},
+					Spec: policy.PodDisruptionBudgetSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "protected"}},
+					},
+					Status: policy.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+				},
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("50"),
+			enablePreempt:  true,
+			wantNominated:  "test-node",
+			wantVictims:    []string{"unprotected"},
+			wantNotVictims: []string{"protected"},
+		},
+		{
+			name: "node not rejected for lacking devices is not a candidate",
+			runningPods: []*corev1.Pod{
+				newPod("low-1", 10, nil, "50"),
+				newPod("low-2", 20, nil, "50"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, "node(s) didn't match Pod's node affinity"),
+			request:        partialGPU("50"),
+			enablePreempt:  true,
+			wantNotVictims: []string{"low-1", "low-2"},
+		},
+		{
+			name: "preemption disabled",
+			runningPods: []*corev1.Pod{
+				newPod("low-1", 10, nil, "50"),
+				newPod("low-2", 20, nil, "50"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("50"),
+			wantNotVictims: []string{"low-1", "low-2"},
+		},
+		{
+			name: "failed preemption leaves the other PostFilter plugins to run",
+			runningPods: []*corev1.Pod{
+				newPod("low-1", 10, nil, "50"),
+				newPod("low-2", 20, nil, "50"),
+			},
+			nodeStatus:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+			request:        partialGPU("50"),
+			enablePreempt:  true,
+			preemptorGone:  true,
+			wantNotVictims: []string{"low-1", "low-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceCache := newNodeDeviceCache()
+			nodeDeviceInfo := deviceCache.createNodeDevice(node.Name)
+			nodeDeviceInfo.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+				schedulingv1alpha1.GPU:  {0: partialGPU("100")},
+				schedulingv1alpha1.RDMA: {1: corev1.ResourceList{apiext.KoordRDMA: resource.MustParse("100")}},
+			})
+			for _, pod := range tt.runningPods {
+				allocations, err := apiext.GetDeviceAllocations(pod.Annotations)
+				assert.NoError(t, err)
+				if len(allocations) > 0 {
+					nodeDeviceInfo.updateCacheUsed(allocations, pod, true)
+				}
+			}
+
+			preemptor := newPod("preemptor", 100, nil, "")
+			preemptor.Spec.NodeName = ""
+			p := &Plugin{
+				nodeDeviceCache:  deviceCache,
+				allocator:        &defaultAllocator{},
+				enablePreemption: tt.enablePreempt,
+			}
+			factory := func(_ apiruntime.Object, _ framework.Handle) (framework.Plugin, error) {
+				return p, nil
+			}
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				schedulertesting.RegisterPreFilterPlugin(Name, factory),
+				schedulertesting.RegisterFilterPlugin(Name, factory),
+			}
+
+			var objects []apiruntime.Object
+			for _, pod := range tt.runningPods {
+				objects = append(objects, pod)
+			}
+			cs := kubefake.NewSimpleClientset(objects...)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			if !tt.preemptorGone {
+				assert.NoError(t, informerFactory.Core().V1().Pods().Informer().GetStore().Add(preemptor))
+			}
+			for _, pdb := range tt.pdbs {
+				assert.NoError(t, informerFactory.Policy().V1().PodDisruptionBudgets().Informer().GetStore().Add(pdb))
+			}
+			fh, err := schedulertesting.NewFramework(
+				registeredPlugins,
+				"koord-scheduler",
+				runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory),
+				runtime.WithSnapshotSharedLister(newTestSharedLister(tt.runningPods, []*corev1.Node{node})),
+				runtime.WithPodNominator(&fakePodNominator{}),
+				runtime.WithEventRecorder(events.NewFakeRecorder(10)),
+			)
+			assert.NoError(t, err)
+			p.handle = fh
+			p.podLister = informerFactory.Core().V1().Pods().Lister()
+			p.pdbLister = informerFactory.Policy().V1().PodDisruptionBudgets().Lister()
+
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: tt.request})
+			result, status := p.PostFilter(context.TODO(), cycleState, preemptor, framework.NodeToStatusMap{node.Name: tt.nodeStatus})
+			if tt.wantNominated != "" {
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, &framework.PostFilterResult{NominatedNodeName: tt.wantNominated}, result)
+			} else {
+				assert.Equal(t, framework.Unschedulable, status.Code())
+				assert.Nil(t, result)
+			}
+			for _, name := range tt.wantVictims {
+				_, err := cs.CoreV1().Pods("default").Get(context.TODO(), name, metav1.GetOptions{})
+				assert.True(t, apierrors.IsNotFound(err), "pod %s should be preempted", name)
+			}
+			for _, name := range tt.wantNotVictims {
+				_, err := cs.CoreV1().Pods("default").Get(context.TODO(), name, metav1.GetOptions{})
+				assert.NoError(t, err, "pod %s should not be preempted", name)
+			}
+			// the preemption is simulated without changing the cache
+			for _, pod := range tt.runningPods {
+				allocations := nodeDeviceInfo.getPodAllocations(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+				assert.Equal(t, pod.Annotations[apiext.AnnotationDeviceAllocated] != "", len(allocations) > 0)
+			}
+		})
+	}
+}
+
+func Test_filterPodsWithPDBViolation(t *testing.T) {
+	newPodInfo := func(name string, labels map[string]string) *framework.PodInfo {
+		return framework.NewPodInfo(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels}})
+	}
+	pdbs := []*policy.PodDisruptionBudget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb"},
+			Spec: policy.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+			},
+			Status: policy.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+		},
+	}
+	podInfos := []*framework.PodInfo{
+		newPodInfo("a-1", map[string]string{"app": "a"}),
+		newPodInfo("b", map[string]string{"app": "b"}),
+		newPodInfo("a-2", map[string]string{"app": "a"}),
+		newPodInfo("no-labels", nil),
+	}
+	violating, nonViolating := filterPodsWithPDBViolation(podInfos, pdbs)
+	assert.Equal(t, []*framework.PodInfo{podInfos[2]}, violating)
+	assert.Equal(t, []*framework.PodInfo{podInfos[0], podInfos[1], podInfos[3]}, nonViolating)
+}
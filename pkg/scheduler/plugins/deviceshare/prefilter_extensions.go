@@ -0,0 +1,315 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// nodeDeviceDelta is the device allocations of the pods removed from or added to a node when the scheduling
+// framework simulates the preemption or the nominated pods, which are not reflected in the nodeDeviceCache.
+type nodeDeviceDelta struct {
+	removed map[types.NamespacedName]apiext.DeviceAllocations
+	added   map[types.NamespacedName]apiext.DeviceAllocations
+}
+
+func newNodeDeviceDelta() *nodeDeviceDelta {
+	return &nodeDeviceDelta{
+		removed: map[types.NamespacedName]apiext.DeviceAllocations{},
+		added:   map[types.NamespacedName]apiext.DeviceAllocations{},
+	}
+}
+
+func (d *nodeDeviceDelta) clone() *nodeDeviceDelta {
+	out := newNodeDeviceDelta()
+	for k, v := range d.removed {
+		out.removed[k] = v
+	}
+	for k, v := range d.added {
+		out.added[k] = v
+	}
+	return out
+}
+
+func (d *nodeDeviceDelta) isEmpty() bool {
+	return d == nil || (len(d.removed) == 0 && len(d.added) == 0)
+}
+
+func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
+	return p
+}
+
+// AddPod accounts the devices of the pod added to the node in the simulation, unless the pod is accounted in
+// the nodeDeviceCache already. The nominated pod which has not allocated the devices yet is allocated the devices
+// it requests from the simulated node, so the devices freed for it are not regarded as free by the other pods.
+func (p *Plugin) AddPod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *corev1.Pod, podInfoToAdd *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	return p.updateNodeDeviceDelta(cycleState, podInfoToAdd.Pod, nodeInfo, true)
+}
+
+// RemovePod releases the devices of the pod removed from the node in the simulation, if the pod is accounted in
+// the nodeDeviceCache.
+func (p *Plugin) RemovePod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *corev1.Pod, podInfoToRemove *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	return p.updateNodeDeviceDelta(cycleState, podInfoToRemove.Pod, nodeInfo, false)
+}
+
+func (p *Plugin) updateNodeDeviceDelta(cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo, add bool) *framework.Status {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return status
+	}
+	if state.skip || nodeInfo.Node() == nil {
+		return nil
+	}
+	nodeName := nodeInfo.Node().Name
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+	if nodeDeviceInfo == nil {
+		return nil
+	}
+	allocations, err := apiext.GetDeviceAllocations(pod.Annotations)
+	if err != nil {
+		klog.V(4).InfoS("Failed to get the device allocations of pod", "pod", klog.KObj(pod), "err", err)
+		return nil
+	}
+
+	podKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	delta := state.nodeDeviceDeltas[nodeName]
+	nodeDeviceInfo.lock.RLock()
+	cachedAllocations := nodeDeviceInfo.getPodAllocations(podKey)
+	accounted := len(cachedAllocations) > 0
+	if len(allocations) == 0 {
+		if !add {
+			// the pod is accounted in the cache or added in the simulation, unless it is removed already
+			allocations = nodeDeviceInfo.withDelta(delta).getPodAllocations(podKey)
+		} else if accounted {
+			// the assumed pod is accounted in the cache before the allocations are annotated
+			allocations = cachedAllocations
+		} else {
+			allocations = p.allocateNominatedPod(nodeName, pod, p.withOvercommittedDevices(pod, nodeDeviceInfo.withDelta(delta)))
+		}
+	}
+	nodeDeviceInfo.lock.RUnlock()
+	if len(allocations) == 0 {
+		return nil
+	}
+
+	if state.nodeDeviceDeltas == nil {
+		state.nodeDeviceDeltas = map[string]*nodeDeviceDelta{}
+	}
+	if delta == nil {
+		delta = newNodeDeviceDelta()
+		state.nodeDeviceDeltas[nodeName] = delta
+	}
+	if add {
+		if _, ok := delta.removed[podKey]; ok {
+			delete(delta.removed, podKey)
+		} else if !accounted {
+			delta.added[podKey] = allocations
+		}
+	} else {
+		if _, ok := delta.added[podKey]; ok {
+			delete(delta.added, podKey)
+		} else if accounted {
+			delta.removed[podKey] = allocations
+		}
+	}
+	return nil
+}
+
+// allocateNominatedPod allocates the devices requested by the nominated pod from the simulated node devices,
+// nil if the pod requests no devices or the node cannot satisfy it.
+func (p *Plugin) allocateNominatedPod(nodeName string, pod *corev1.Pod, nodeDevice *nodeDevice) apiext.DeviceAllocations {
+	podRequest, _, err := p.nodeDeviceCache.getResourceNames().convertDeviceRequest(util.GetPodEffectiveRequest(pod))
+	if err != nil {
+		klog.V(4).InfoS("Failed to get the device request of the nominated pod", "pod", klog.KObj(pod), "err", err)
+		return nil
+	}
+	if len(podRequest) == 0 {
+		return nil
+	}
+	if gpuModelSelector, err := apiext.GetGPUModelSelector(pod.Annotations); err != nil {
+		klog.V(4).InfoS("Failed to get the GPU model selector of the nominated pod", "pod", klog.KObj(pod), "err", err)
+		return nil
+	} else if gpuModelSelector != nil {
+		nodeDevice = nodeDevice.withGPUsOfModels(gpuModelSelector)
+	}
+	gpuExclusive := apiext.IsGPUExclusive(pod.Annotations)
+	if gpuExclusive {
+		nodeDevice = nodeDevice.withUnusedGPUsOnly()
+	}
+	allocations, err := p.allocator.Allocate(nodeName, pod, podRequest, nodeDevice)
+	if err != nil {
+		klog.V(5).InfoS("The nominated pod cannot allocate the devices on the simulated node", "pod", klog.KObj(pod), "node", nodeName, "err", err)
+		return nil
+	}
+	if gpuExclusive {
+		markGPUsExclusive(allocations)
+	}
+	return allocations
+}
+
+// withDelta returns a view of the node devices in which the devices of the removed pods are free and the devices
+// of the added pods are used, and the allocations of the pods are updated accordingly. The node devices are returned
+// as is if the delta is empty.
+func (n *nodeDevice) withDelta(delta *nodeDeviceDelta) *nodeDevice {
+	if delta.isEmpty() {
+		return n
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources.DeepCopy()
+	}
+	deviceUsed := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceUsed))
+	for deviceType, resources := range n.deviceUsed {
+		deviceUsed[deviceType] = resources.DeepCopy()
+	}
+	apply := func(allocations apiext.DeviceAllocations, release bool) {
+		for deviceType, typeAllocations := range allocations {
+			for _, allocation := range typeAllocations {
+				minor := int(allocation.Minor)
+				total, ok := n.deviceTotal[deviceType][minor]
+				if !ok {
+					continue
+				}
+				if deviceFree[deviceType] == nil {
+					deviceFree[deviceType] = deviceResources{}
+				}
+				if deviceUsed[deviceType] == nil {
+					deviceUsed[deviceType] = deviceResources{}
+				}
+				resources := n.getAccountedResources(deviceType, allocation)
+				// the unhealthy devices are never free even if the pods on them are removed
+				unhealthy := n.unhealthyDevices[deviceType].Has(minor)
+				if release {
+					if !unhealthy {
+						deviceFree[deviceType][minor] = minResourceList(quotav1.Add(deviceFree[deviceType][minor], resources), total)
+					}
+					deviceUsed[deviceType][minor] = quotav1.SubtractWithNonNegativeResult(deviceUsed[deviceType][minor], resources)
+				} else {
+					if !unhealthy {
+						deviceFree[deviceType][minor] = quotav1.SubtractWithNonNegativeResult(deviceFree[deviceType][minor], resources)
+					}
+					deviceUsed[deviceType][minor] = quotav1.Add(deviceUsed[deviceType][minor], resources)
+				}
+			}
+		}
+	}
+	for _, allocations := range delta.removed {
+		apply(allocations, true)
+	}
+	for _, allocations := range delta.added {
+		apply(allocations, false)
+	}
+	allocateSet := make(map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList, len(n.allocateSet))
+	for deviceType, podAllocations := range n.allocateSet {
+		allocateSet[deviceType] = make(map[types.NamespacedName]map[int]corev1.ResourceList, len(podAllocations))
+		for podKey, resources := range podAllocations {
+			allocateSet[deviceType][podKey] = resources
+		}
+	}
+	for podKey := range delta.removed {
+		for _, podAllocations := range allocateSet {
+			delete(podAllocations, podKey)
+		}
+	}
+	for podKey, allocations := range delta.added {
+		for deviceType, typeAllocations := range allocations {
+			if allocateSet[deviceType] == nil {
+				allocateSet[deviceType] = make(map[types.NamespacedName]map[int]corev1.ResourceList)
+			}
+			resources := make(map[int]corev1.ResourceList, len(typeAllocations))
+			for _, allocation := range typeAllocations {
+				resources[int(allocation.Minor)] = allocation.Resources.DeepCopy()
+			}
+			allocateSet[deviceType][podKey] = resources
+		}
+	}
+	migAllocateSet := n.migAllocateSet
+	if len(n.migPartitions) > 0 {
+		migAllocateSet = make(map[types.NamespacedName]map[int][]schedulingv1alpha1.MIGPartition, len(n.migAllocateSet))
+		for podKey, partitions := range n.migAllocateSet {
+			migAllocateSet[podKey] = partitions
+		}
+		for podKey := range delta.removed {
+			delete(migAllocateSet, podKey)
+		}
+		for podKey, allocations := range delta.added {
+			if partitions := getAllocatedMIGPartitions(allocations[schedulingv1alpha1.GPU]); len(partitions) > 0 {
+				migAllocateSet[podKey] = partitions
+			}
+		}
+	}
+	sharedSlotAllocateSet := n.sharedSlotAllocateSet
+	if n.hasSharedSlotsGPU() {
+		sharedSlotAllocateSet = make(map[types.NamespacedName]map[int][]int32, len(n.sharedSlotAllocateSet))
+		for podKey, slots := range n.sharedSlotAllocateSet {
+			sharedSlotAllocateSet[podKey] = slots
+		}
+		for podKey := range delta.removed {
+			delete(sharedSlotAllocateSet, podKey)
+		}
+		for podKey, allocations := range delta.added {
+			if slots := getAllocatedSharedSlots(allocations[schedulingv1alpha1.GPU]); len(slots) > 0 {
+				sharedSlotAllocateSet[podKey] = slots
+			}
+		}
+	}
+	vfAllocateSet := n.vfAllocateSet
+	if len(n.rdmaVFs) > 0 {
+		vfAllocateSet = make(map[types.NamespacedName]map[int][]schedulingv1alpha1.VirtualFunction, len(n.vfAllocateSet))
+		for podKey, vfs := range n.vfAllocateSet {
+			vfAllocateSet[podKey] = vfs
+		}
+		for podKey := range delta.removed {
+			delete(vfAllocateSet, podKey)
+		}
+		for podKey, allocations := range delta.added {
+			if vfs := getAllocatedVirtualFunctions(allocations[schedulingv1alpha1.RDMA]); len(vfs) > 0 {
+				vfAllocateSet[podKey] = vfs
+			}
+		}
+	}
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	view.deviceUsed = deviceUsed
+	view.allocateSet = allocateSet
+	view.migAllocateSet = migAllocateSet
+	view.sharedSlotAllocateSet = sharedSlotAllocateSet
+	view.vfAllocateSet = vfAllocateSet
+	return view
+}
+
+// minResourceList returns the smaller quantity of each resource in a.
+func minResourceList(a, b corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for name, quantity := range a {
+		if other, ok := b[name]; ok && other.Cmp(quantity) < 0 {
+			quantity = other
+		}
+		result[name] = quantity.DeepCopy()
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func Test_Plugin_PreFilterExtensionsAddRemovePod(t *testing.T) {
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	newPodWithGPUs := func(name string, minors ...int32) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name)},
+			Spec:       corev1.PodSpec{NodeName: "test-node"},
+		}
+		var allocations []*apiext.DeviceAllocation
+		for _, minor := range minors {
+			allocations = append(allocations, &apiext.DeviceAllocation{Minor: minor, Resources: wholeGPU.DeepCopy()})
+		}
+		assert.NoError(t, apiext.SetDeviceAllocations(pod, apiext.DeviceAllocations{schedulingv1alpha1.GPU: allocations}))
+		return pod
+	}
+	// the nominated pod only requests the GPUs, the devices are not allocated until it is scheduled
+	newNominatedPod := func(name string, gpus int64) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								apiext.KoordGPU: *resource.NewQuantity(100*gpus, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	newDeviceCache := func(runningPods ...*corev1.Pod) *nodeDeviceCache {
+		deviceCache := newNodeDeviceCache()
+		gpus := deviceResources{}
+		for minor := 0; minor < 4; minor++ {
+			gpus[minor] = wholeGPU.DeepCopy()
+		}
+		nodeDeviceInfo := deviceCache.createNodeDevice("test-node")
+		nodeDeviceInfo.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{schedulingv1alpha1.GPU: gpus})
+		for _, pod := range runningPods {
+			allocations, err := apiext.GetDeviceAllocations(pod.Annotations)
+			assert.NoError(t, err)
+			nodeDeviceInfo.updateCacheUsed(allocations, pod, true)
+		}
+		return deviceCache
+	}
+	twoGPUs := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("200"),
+		apiext.GPUMemoryRatio: resource.MustParse("200"),
+		apiext.GPUMemory:      resource.MustParse("32Gi"),
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+
+	tests := []struct {
+		name        string
+		runningPods []*corev1.Pod
+		removedPods []*corev1.Pod
+		addedPods   []*corev1.Pod
+		want        *framework.Status
+	}{
+		{
+			name:        "insufficient devices without simulation",
+			runningPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1), newPodWithGPUs("running", 2, 3)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "schedulable after removing the victim",
+			runningPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1), newPodWithGPUs("running", 2, 3)},
+			removedPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1)},
+		},
+		{
+			name:        "insufficient devices after adding back the removed victim",
+			runningPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1), newPodWithGPUs("running", 2, 3)},
+			removedPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1)},
+			addedPods:   []*corev1.Pod{newPodWithGPUs("victim", 0, 1)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "removing the pod unknown to the cache frees nothing",
+			runningPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1), newPodWithGPUs("running", 2, 3)},
+			removedPods: []*corev1.Pod{newPodWithGPUs("unknown", 0, 1)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "schedulable without the nominated pod",
+			runningPods: []*corev1.Pod{newPodWithGPUs("running", 2, 3)},
+		},
+		{
+			name:        "infeasible after adding the nominated pod",
+			runningPods: []*corev1.Pod{newPodWithGPUs("running", 2, 3)},
+			addedPods:   []*corev1.Pod{newPodWithGPUs("nominated", 0)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "adding the pod accounted in the cache uses nothing more",
+			runningPods: []*corev1.Pod{newPodWithGPUs("running", 2, 3)},
+			addedPods:   []*corev1.Pod{newPodWithGPUs("running", 2, 3)},
+		},
+		{
+			name:        "infeasible after adding the nominated pod without allocations",
+			runningPods: []*corev1.Pod{newPodWithGPUs("running", 2, 3)},
+			addedPods:   []*corev1.Pod{newNominatedPod("nominated", 1)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "the nominated pod takes the GPUs freed by the victim",
+			runningPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1), newPodWithGPUs("running", 2, 3)},
+			removedPods: []*corev1.Pod{newPodWithGPUs("victim", 0, 1)},
+			addedPods:   []*corev1.Pod{newNominatedPod("nominated", 2)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "removing the victim twice frees its devices once",
+			runningPods: []*corev1.Pod{newPodWithGPUs("victim", 0), newPodWithGPUs("running", 2, 3)},
+			removedPods: []*corev1.Pod{newPodWithGPUs("victim", 0), newPodWithGPUs("victim", 0)},
+			addedPods:   []*corev1.Pod{newNominatedPod("nominated", 1)},
+			want:        framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
+		},
+		{
+			name:        "the nominated pod not fitting the node uses nothing",
+			runningPods: []*corev1.Pod{newPodWithGPUs("running", 2, 3)},
+			addedPods:   []*corev1.Pod{newNominatedPod("nominated", 4)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{nodeDeviceCache: newDeviceCache(tt.runningPods...), allocator: &defaultAllocator{}}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "preemptor"}}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: twoGPUs})
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+
+			// the simulation works on a copy of the cycle state
+			simulatedState := cycleState.Clone()
+			for _, removed := range tt.removedPods {
+				status := p.PreFilterExtensions().RemovePod(context.TODO(), simulatedState, pod, framework.NewPodInfo(removed), nodeInfo)
+				assert.True(t, status.IsSuccess())
+			}
+			for _, added := range tt.addedPods {
+				status := p.PreFilterExtensions().AddPod(context.TODO(), simulatedState, pod, framework.NewPodInfo(added), nodeInfo)
+				assert.True(t, status.IsSuccess())
+			}
+			assert.Equal(t, tt.want, p.Filter(context.TODO(), simulatedState, pod, nodeInfo))
+
+			// removing the added pods restores the simulated node, the nominated pods without allocations are
+			// removed by the devices allocated to them in the simulation
+			if len(tt.addedPods) > 0 {
+				for _, added := range tt.addedPods {
+					status := p.PreFilterExtensions().RemovePod(context.TODO(), simulatedState, pod, framework.NewPodInfo(added), nodeInfo)
+					assert.True(t, status.IsSuccess())
+				}
+				state, status := getPreFilterState(simulatedState)
+				assert.True(t, status.IsSuccess())
+				if delta := state.nodeDeviceDeltas["test-node"]; delta != nil {
+					assert.Empty(t, delta.added)
+				}
+				removedOnlyState := cycleState.Clone()
+				for _, removed := range tt.removedPods {
+					status := p.PreFilterExtensions().RemovePod(context.TODO(), removedOnlyState, pod, framework.NewPodInfo(removed), nodeInfo)
+					assert.True(t, status.IsSuccess())
+				}
+				assert.Equal(t, p.Filter(context.TODO(), removedOnlyState, pod, nodeInfo), p.Filter(context.TODO(), simulatedState, pod, nodeInfo))
+			}
+
+			// the original cycle state and the cache are not affected by the simulation
+			if len(tt.removedPods) > 0 {
+				assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices), p.Filter(context.TODO(), cycleState, pod, nodeInfo))
+			}
+		})
+	}
+}
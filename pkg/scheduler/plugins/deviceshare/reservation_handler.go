@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func registerReservationEventHandler(deviceCache *nodeDeviceCache, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory) {
+	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Informer()
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    deviceCache.onReservationAdd,
+		UpdateFunc: deviceCache.onReservationUpdate,
+		DeleteFunc: deviceCache.onReservationDelete,
+	}
+	// make sure the reserved devices are loaded before the Pods consuming them
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, reservationInformer, eventHandler)
+}
+
+func (n *nodeDeviceCache) onReservationAdd(obj interface{}) {
+	r, ok := obj.(*schedulingv1alpha1.Reservation)
+	if !ok {
+		klog.Errorf("reservation cache add failed to parse, obj %T", obj)
+		return
+	}
+	if util.IsReservationActive(r) {
+		n.addReservation(r)
+	}
+}
+
+func (n *nodeDeviceCache) onReservationUpdate(oldObj, newObj interface{}) {
+	oldR, oldOK := oldObj.(*schedulingv1alpha1.Reservation)
+	newR, newOK := newObj.(*schedulingv1alpha1.Reservation)
+	if !oldOK || !newOK {
+		klog.Errorf("reservation cache update failed to parse, oldObj %T, newObj %T", oldObj, newObj)
+		return
+	}
+	if util.IsReservationActive(newR) {
+		n.addReservation(newR)
+	} else if util.IsReservationActive(oldR) {
+		n.deleteReservation(oldR)
+		klog.V(4).InfoS("reservation inactive, release the reserved devices", "reservation", klog.KObj(newR), "phase", newR.Status.Phase)
+	}
+}
+
+func (n *nodeDeviceCache) onReservationDelete(obj interface{}) {
+	var r *schedulingv1alpha1.Reservation
+	switch t := obj.(type) {
+	case *schedulingv1alpha1.Reservation:
+		r = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		r, ok = t.Obj.(*schedulingv1alpha1.Reservation)
+		if !ok {
+			klog.V(5).Infof("reservation cache remove failed to parse, obj %T", obj)
+			return
+		}
+	default:
+		return
+	}
+	n.deleteReservation(r)
+}
+
+func (n *nodeDeviceCache) addReservation(r *schedulingv1alpha1.Reservation) {
+	devicesAllocation, err := apiext.GetDeviceAllocations(r.Annotations)
+	if err != nil {
+		klog.Errorf("failed to get device allocation from reservation %v, err: %v", klog.KObj(r), err)
+		return
+	}
+	nodeName := util.GetReservationNodeName(r)
+	info := n.getNodeDevice(nodeName)
+	if info == nil {
+		if len(devicesAllocation) == 0 {
+			return
+		}
+		info = n.createNodeDevice(nodeName)
+		klog.V(5).Infof("node device cache not found, nodeName: %v, reservation: %v, createNodeDevice", nodeName, klog.KObj(r))
+	}
+
+	info.lock.Lock()
+	defer info.lock.Unlock()
+
+	info.reserveDevices(util.NewReservePod(r), r, devicesAllocation)
+	klog.V(5).InfoS("reservation cache added", "reservation", klog.KObj(r))
+}
+
+func (n *nodeDeviceCache) deleteReservation(r *schedulingv1alpha1.Reservation) {
+	info := n.getNodeDevice(util.GetReservationNodeName(r))
+	if info == nil {
+		return
+	}
+
+	info.lock.Lock()
+	defer info.lock.Unlock()
+
+	info.unreserveDevices(r.UID)
+	klog.V(5).InfoS("reservation cache deleted", "reservation", klog.KObj(r))
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// reservedDevices is the devices held by a reservation on the node. The pods matching the reservation allocate the
+// devices from it, and the devices not consumed by them yet are accounted as used by the reserve pod, so that the
+// other pods can't allocate them.
+type reservedDevices struct {
+	// reservation is the reservation holding the devices, nil until it is seen available.
+	reservation *schedulingv1alpha1.Reservation
+	// reservePod is the reserve pod the remaining devices are accounted to, nil until the devices are reserved.
+	reservePod  *corev1.Pod
+	allocations apiext.DeviceAllocations
+	// consumers is the devices allocated from the reservation by pod.
+	consumers map[types.NamespacedName]apiext.DeviceAllocations
+	// remaining is the reserved devices not consumed by the pods, which are accounted as used by the reserve pod.
+	remaining apiext.DeviceAllocations
+}
+
+func (r *reservedDevices) name() string {
+	if r.reservation != nil {
+		return r.reservation.Name
+	}
+	return util.GetReservationNameFromReservePod(r.reservePod)
+}
+
+// getRemaining subtracts the devices consumed by the pods from the reserved devices. A device partly consumed is no
+// longer exclusive to the reservation, the consumers account for the rest of it.
+func (r *reservedDevices) getRemaining() apiext.DeviceAllocations {
+	consumed := map[schedulingv1alpha1.DeviceType]deviceResources{}
+	for _, allocations := range r.consumers {
+		for deviceType, deviceAllocations := range allocations {
+			if consumed[deviceType] == nil {
+				consumed[deviceType] = deviceResources{}
+			}
+			for _, allocation := range deviceAllocations {
+				minor := int(allocation.Minor)
+				consumed[deviceType][minor] = quotav1.Add(consumed[deviceType][minor], allocation.Resources)
+			}
+		}
+	}
+	consumedVFs := map[schedulingv1alpha1.VirtualFunction]struct{}{}
+	for _, allocations := range r.consumers {
+		for _, allocation := range allocations[schedulingv1alpha1.RDMA] {
+			for _, vf := range allocation.VirtualFunctions {
+				consumedVFs[vf] = struct{}{}
+			}
+		}
+	}
+	remaining := apiext.DeviceAllocations{}
+	for deviceType, deviceAllocations := range r.allocations {
+		for _, allocation := range deviceAllocations {
+			used, ok := consumed[deviceType][int(allocation.Minor)]
+			resources := quotav1.SubtractWithNonNegativeResult(allocation.Resources, used)
+			if quotav1.IsZero(resources) {
+				continue
+			}
+			var vfs []schedulingv1alpha1.VirtualFunction
+			for _, vf := range allocation.VirtualFunctions {
+				if _, consumed := consumedVFs[vf]; !consumed {
+					vfs = append(vfs, vf)
+				}
+			}
+			remaining[deviceType] = append(remaining[deviceType], &apiext.DeviceAllocation{
+				Minor:            allocation.Minor,
+				Resources:        resources,
+				Exclusive:        allocation.Exclusive && !ok,
+				VirtualFunctions: vfs,
+			})
+		}
+	}
+	return remaining
+}
+
+func (n *nodeDevice) getOrCreateReservedDevices(reservationUID types.UID) *reservedDevices {
+	if n.reservations == nil {
+		n.reservations = map[types.UID]*reservedDevices{}
+	}
+	reserved := n.reservations[reservationUID]
+	if reserved == nil {
+		reserved = &reservedDevices{consumers: map[types.NamespacedName]apiext.DeviceAllocations{}}
+		n.reservations[reservationUID] = reserved
+	}
+	return reserved
+}
+
+// reserveDevices holds the devices for the reservation of the reserve pod. The reservation is nil if the devices are
+// reserved in the scheduling cycle of the reserve pod, before the reservation is available.
+func (n *nodeDevice) reserveDevices(reservePod *corev1.Pod, reservation *schedulingv1alpha1.Reservation, allocations apiext.DeviceAllocations) {
+	if len(allocations) == 0 && n.reservations[reservePod.UID] == nil {
+		return
+	}
+	reserved := n.getOrCreateReservedDevices(reservePod.UID)
+	if reservation != nil {
+		reserved.reservation = reservation
+	}
+	if reserved.reservePod != nil || len(allocations) == 0 {
+		return
+	}
+	reserved.reservePod = reservePod
+	reserved.allocations = allocations
+	n.accountReservedDevices(reserved)
+}
+
+// unreserveDevices releases the devices not consumed yet of the reservation. The devices consumed stay accounted to
+// the pods until they are gone.
+func (n *nodeDevice) unreserveDevices(reservationUID types.UID) {
+	reserved := n.reservations[reservationUID]
+	if reserved == nil {
+		return
+	}
+	if reserved.reservePod != nil {
+		n.updateCacheUsed(reserved.remaining, reserved.reservePod, false)
+	}
+	delete(n.reservations, reservationUID)
+}
+
+// consumeReservedDevices records the devices allocated by the pod from the reservation, so that the devices remaining
+// in the reservation shrink accordingly. The pod consumes one reservation at most.
+func (n *nodeDevice) consumeReservedDevices(reservationUID types.UID, pod *corev1.Pod, allocations apiext.DeviceAllocations) {
+	podNamespacedName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	for _, reserved := range n.reservations {
+		if _, ok := reserved.consumers[podNamespacedName]; ok {
+			return
+		}
+	}
+	reserved := n.getOrCreateReservedDevices(reservationUID)
+	reserved.consumers[podNamespacedName] = allocations
+	n.accountReservedDevices(reserved)
+}
+
+// releaseReservedDevices returns the devices consumed by the pod to the reservation it allocated from.
+func (n *nodeDevice) releaseReservedDevices(pod *corev1.Pod) {
+	podNamespacedName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	for reservationUID, reserved := range n.reservations {
+		if _, ok := reserved.consumers[podNamespacedName]; !ok {
+			continue
+		}
+		delete(reserved.consumers, podNamespacedName)
+		if reserved.reservePod == nil && len(reserved.consumers) == 0 {
+			delete(n.reservations, reservationUID)
+			return
+		}
+		n.accountReservedDevices(reserved)
+		return
+	}
+}
+
+// accountReservedDevices accounts the devices remaining in the reservation as used by the reserve pod.
+func (n *nodeDevice) accountReservedDevices(reserved *reservedDevices) {
+	if reserved.reservePod == nil {
+		return
+	}
+	n.updateCacheUsed(reserved.remaining, reserved.reservePod, false)
+	reserved.remaining = reserved.getRemaining()
+	n.updateCacheUsed(reserved.remaining, reserved.reservePod, true)
+}
+
+// isReservingDevices checks if the pod is a reserve pod holding the devices for the reservation not available yet.
+func (n *nodeDevice) isReservingDevices(podNamespacedName types.NamespacedName) bool {
+	for _, reserved := range n.reservations {
+		if reserved.reservation == nil && reserved.reservePod != nil &&
+			reserved.reservePod.Namespace == podNamespacedName.Namespace && reserved.reservePod.Name == podNamespacedName.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// getNominatedReservedDevices returns the reservations nominated to the pod by the Reservation plugin which hold the
+// remaining devices, in the order of the nomination.
+func (n *nodeDevice) getNominatedReservedDevices(pod *corev1.Pod, reservations []*schedulingv1alpha1.Reservation) []*reservedDevices {
+	if util.IsReservePod(pod) {
+		return nil
+	}
+	var nominated []*reservedDevices
+	for _, reservation := range reservations {
+		reserved := n.reservations[reservation.UID]
+		if reserved == nil || reserved.reservePod == nil || len(reserved.remaining) == 0 {
+			continue
+		}
+		nominated = append(nominated, reserved)
+	}
+	return nominated
+}
+
+// withReservedDevices returns the view of the node devices in which only the devices remaining in the reservation
+// are free, and they are not used by the reserve pod.
+func (n *nodeDevice) withReservedDevices(reserved *reservedDevices) *nodeDevice {
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	deviceUsed := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceUsed))
+	for deviceType := range n.deviceFree {
+		deviceFree[deviceType] = deviceResources{}
+	}
+	for deviceType, resources := range n.deviceUsed {
+		deviceUsed[deviceType] = resources
+	}
+	for deviceType, allocations := range reserved.remaining {
+		free := deviceResources{}
+		used := n.deviceUsed[deviceType].DeepCopy()
+		for _, allocation := range allocations {
+			minor := int(allocation.Minor)
+			if n.unhealthyDevices[deviceType].Has(minor) {
+				continue
+			}
+			free[minor] = allocation.Resources.DeepCopy()
+			if remainingUsed := quotav1.SubtractWithNonNegativeResult(used[minor], n.getAccountedResources(deviceType, allocation)); quotav1.IsZero(remainingUsed) {
+				delete(used, minor)
+			} else {
+				used[minor] = remainingUsed
+			}
+		}
+		deviceFree[deviceType] = free
+		deviceUsed[deviceType] = used
+	}
+	// the virtual functions of the reservation are allocated only, which are not used by the reserve pod
+	rdmaVFs, vfAllocateSet := n.rdmaVFs, n.vfAllocateSet
+	if reservedVFs := getAllocatedVirtualFunctions(reserved.remaining[schedulingv1alpha1.RDMA]); len(reservedVFs) > 0 {
+		rdmaVFs = make(map[int][]schedulingv1alpha1.VirtualFunction, len(n.rdmaVFs))
+		for minor, vfs := range n.rdmaVFs {
+			rdmaVFs[minor] = vfs
+		}
+		for minor, vfs := range reservedVFs {
+			rdmaVFs[minor] = sortVirtualFunctions(vfs)
+		}
+		vfAllocateSet = make(map[types.NamespacedName]map[int][]schedulingv1alpha1.VirtualFunction, len(n.vfAllocateSet))
+		for podKey, vfs := range n.vfAllocateSet {
+			vfAllocateSet[podKey] = vfs
+		}
+		delete(vfAllocateSet, types.NamespacedName{Namespace: reserved.reservePod.Namespace, Name: reserved.reservePod.Name})
+	}
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	view.deviceUsed = deviceUsed
+	view.rdmaVFs = rdmaVFs
+	view.vfAllocateSet = vfAllocateSet
+	return view
+}
+
+// getReservationRemaining returns the devices remaining in the reservations by the reservation name.
+func (n *nodeDevice) getReservationRemaining() map[string]map[schedulingv1alpha1.DeviceType]deviceResources {
+	if len(n.reservations) == 0 {
+		return nil
+	}
+	remaining := map[string]map[schedulingv1alpha1.DeviceType]deviceResources{}
+	for _, reserved := range n.reservations {
+		if reserved.reservePod == nil {
+			continue
+		}
+		resources := map[schedulingv1alpha1.DeviceType]deviceResources{}
+		for deviceType, allocations := range reserved.remaining {
+			resources[deviceType] = deviceResources{}
+			for _, allocation := range allocations {
+				resources[deviceType][int(allocation.Minor)] = allocation.Resources.DeepCopy()
+			}
+		}
+		remaining[reserved.name()] = resources
+	}
+	return remaining
+}
+
+// allocateFromReservations allocates the devices requested by the pod from the devices remaining in the reservations
+// nominated to the pod, and returns the devices allocated, the devices carved out of the reservation and the UID of
+// the reservation allocated from. The views of the reservations are built from baseDevice, i.e. the node devices with
+// the delta of the cycle. If no reservation satisfies the pod alone, the pod takes as many devices as it can from a
+// reservation and the rest from the free devices of freeDevice, unless freeDevice is nil or the devices of the pod
+// must be aligned to the topology, since the topology is only aligned within the devices from the reservation and
+// the free devices respectively.
+// The nodeDevice must be locked.
+func (p *Plugin) allocateFromReservations(ctx context.Context, nodeName string, pod *corev1.Pod, state *preFilterState, nominated []*reservedDevices, baseDevice *nodeDevice, freeDevice *nodeDevice) (apiext.DeviceAllocations, apiext.DeviceAllocations, types.UID) {
+	for _, reserved := range nominated {
+		nodeDevice := p.withReservedDevices(baseDevice, reserved, state)
+		allocateResult, err := p.allocate(ctx, nodeName, pod, state.convertedDeviceResource, nodeDevice)
+		if err == nil && len(allocateResult) != 0 {
+			klog.V(4).InfoS("allocate the devices held by the reservation", "pod", klog.KObj(pod), "node", nodeName, "reservation", reserved.name())
+			return allocateResult, allocateResult, reserved.reservePod.UID
+		}
+	}
+	if freeDevice == nil || len(nominated) == 0 || p.isTopologyRestricted(pod, state.convertedDeviceResource, baseDevice) {
+		return nil, nil, ""
+	}
+	if state.gpuExclusive {
+		freeDevice = freeDevice.withUnusedGPUsOnly()
+	}
+	hint, err := apiext.GetDeviceOrderingHint(pod.Annotations)
+	if err != nil {
+		return nil, nil, ""
+	}
+	// the devices are ordered by the hint after the devices of both parts are allocated
+	partPod := withoutDeviceOrderingHint(pod)
+	for _, reserved := range nominated {
+		nodeDevice := p.withReservedDevices(baseDevice, reserved, state)
+		reservedRequest, restRequest := splitReservedDeviceRequest(state.convertedDeviceResource, nodeDevice)
+		if len(reservedRequest) == 0 || len(restRequest) == 0 {
+			continue
+		}
+		reservedResult, err := p.allocate(ctx, nodeName, partPod, reservedRequest, nodeDevice)
+		if err != nil || len(reservedResult) == 0 {
+			continue
+		}
+		restResult, err := p.allocate(ctx, nodeName, partPod, restRequest, freeDevice.withoutDevices(reservedResult))
+		if err != nil || len(restResult) == 0 {
+			continue
+		}
+		allocateResult := apiext.DeviceAllocations{}
+		for _, result := range []apiext.DeviceAllocations{reservedResult, restResult} {
+			for deviceType, allocations := range result {
+				allocateResult[deviceType] = append(allocateResult[deviceType], allocations...)
+			}
+		}
+		if err := applyDeviceOrderingHint(allocateResult, hint); err != nil {
+			continue
+		}
+		klog.V(4).InfoS("allocate the devices held by the reservation and the free devices", "pod", klog.KObj(pod), "node", nodeName, "reservation", reserved.name())
+		return allocateResult, reservedResult, reserved.reservePod.UID
+	}
+	return nil, nil, ""
+}
+
+// withReservedDevices returns the view of the devices remaining in the reservation which the pod can allocate.
+func (p *Plugin) withReservedDevices(baseDevice *nodeDevice, reserved *reservedDevices, state *preFilterState) *nodeDevice {
+	nodeDevice := baseDevice.withReservedDevices(reserved)
+	if state.gpuModelSelector != nil {
+		nodeDevice = nodeDevice.withGPUsOfModels(state.gpuModelSelector)
+	}
+	if state.gpuExclusive {
+		nodeDevice = nodeDevice.withUnusedGPUsOnly()
+	}
+	return nodeDevice
+}
+
+// isTopologyRestricted checks if the devices requested by the pod must be aligned to the topology, in which case the
+// devices are not split between a reservation and the free devices.
+func (p *Plugin) isTopologyRestricted(pod *corev1.Pod, podRequest corev1.ResourceList, nodeDevice *nodeDevice) bool {
+	if jointAllocate, err := apiext.GetDeviceJointAllocate(pod.Annotations); err != nil || jointAllocate != nil {
+		return true
+	}
+	if resourceSpec, err := apiext.GetResourceSpec(pod.Annotations); err != nil || resourceSpec.NUMATopologyPolicy == apiext.NUMATopologyPolicyRestricted {
+		return true
+	}
+	if p.initialArgs == nil {
+		return false
+	}
+	if p.initialArgs.GPUTopologyPolicy == config.DeviceGPUTopologyRestricted {
+		return true
+	}
+	return p.initialArgs.NUMATopologyPolicy == config.DeviceNUMATopologyRestricted &&
+		nodeDevice.getResourceNames().hasDeviceResource(podRequest, schedulingv1alpha1.RDMA)
+}
+
+// splitReservedDeviceRequest splits the devices requested by the pod into the devices taken from the reservation
+// and the rest, taking as many devices of each type as the free devices of the reservation view could satisfy.
+// The MIG instances and the time-slicing slots are never split.
+func splitReservedDeviceRequest(podRequest corev1.ResourceList, reservedDevice *nodeDevice) (corev1.ResourceList, corev1.ResourceList) {
+	reservedRequest, restRequest := corev1.ResourceList{}, corev1.ResourceList{}
+	deviceResourceNames := reservedDevice.getResourceNames()
+	for deviceType, resourceNames := range deviceResourceNames {
+		if !deviceResourceNames.hasDeviceResource(podRequest, deviceType) {
+			continue
+		}
+		if deviceType == schedulingv1alpha1.GPU {
+			if migRequest := getMIGRequest(podRequest); len(migRequest) > 0 {
+				restRequest = quotav1.Add(restRequest, migRequest)
+				continue
+			}
+			if sharedSlots, ok := podRequest[apiext.GPUSharedSlots]; ok {
+				restRequest[apiext.GPUSharedSlots] = sharedSlots
+				continue
+			}
+		}
+		request := quotav1.Mask(podRequest, resourceNames)
+		count := deviceResourceNames.getDeviceCount(deviceType, request)
+		requestPerDevice := scaleDeviceRequest(request, 1, count)
+		reservedCount := int64(0)
+		for _, free := range reservedDevice.deviceFree[deviceType] {
+			if satisfied, _ := quotav1.LessThanOrEqual(requestPerDevice, free); satisfied && reservedCount < count {
+				reservedCount++
+			}
+		}
+		if reservedCount > 0 {
+			reservedRequest = quotav1.Add(reservedRequest, scaleDeviceRequest(request, reservedCount, count))
+		}
+		if reservedCount < count {
+			restRequest = quotav1.Add(restRequest, scaleDeviceRequest(request, count-reservedCount, count))
+		}
+	}
+	return reservedRequest, restRequest
+}
+
+// scaleDeviceRequest scales the request of count devices to the request of n devices.
+func scaleDeviceRequest(request corev1.ResourceList, n, count int64) corev1.ResourceList {
+	scaled := make(corev1.ResourceList, len(request))
+	for resourceName, quantity := range request {
+		scaled[resourceName] = *resource.NewQuantity(quantity.Value()*n/count, quantity.Format)
+	}
+	return scaled
+}
+
+// withoutDeviceOrderingHint returns a shallow copy of the pod without the device ordering hint.
+func withoutDeviceOrderingHint(pod *corev1.Pod) *corev1.Pod {
+	if _, ok := pod.Annotations[apiext.AnnotationDeviceOrderingHint]; !ok {
+		return pod
+	}
+	out := *pod
+	out.Annotations = make(map[string]string, len(pod.Annotations))
+	for key, value := range pod.Annotations {
+		if key != apiext.AnnotationDeviceOrderingHint {
+			out.Annotations[key] = value
+		}
+	}
+	return &out
+}
+
+// withoutDevices returns the view of the node devices in which the allocated devices are not free, so that a device
+// is not allocated to the pod twice.
+func (n *nodeDevice) withoutDevices(allocations apiext.DeviceAllocations) *nodeDevice {
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	for deviceType, deviceAllocations := range allocations {
+		free := deviceResources{}
+		for minor, resources := range n.deviceFree[deviceType] {
+			free[minor] = resources
+		}
+		for _, allocation := range deviceAllocations {
+			delete(free, int(allocation.Minor))
+		}
+		deviceFree[deviceType] = free
+	}
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
@@ -0,0 +1,431 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	schedconfigv1beta2 "k8s.io/kube-scheduler/config/v1beta2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+var (
+	testWholeGPU = corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	testHalfGPU = corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("50"),
+		apiext.GPUMemoryRatio: resource.MustParse("50"),
+		apiext.GPUMemory:      resource.MustParse("8Gi"),
+	}
+)
+
+func newTestGPUDeviceCache(gpus int) *nodeDeviceCache {
+	deviceCache := newNodeDeviceCache()
+	device := &schedulingv1alpha1.Device{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	for i := 0; i < gpus; i++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			Minor:     pointer.Int32Ptr(int32(i)),
+			Health:    true,
+			Type:      schedulingv1alpha1.GPU,
+			Resources: testWholeGPU,
+		})
+	}
+	deviceCache.updateNodeDevice("test-node", device)
+	return deviceCache
+}
+
+func newTestGPUReservation(allocations apiext.DeviceAllocations) *schedulingv1alpha1.Reservation {
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reservation-gpu",
+			UID:  "reservation-gpu-uid",
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+			Owners: []schedulingv1alpha1.ReservationOwner{
+				{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "reserved"}}},
+			},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "test-node",
+		},
+	}
+	if allocations != nil {
+		data, _ := json.Marshal(allocations)
+		r.Annotations = map[string]string{apiext.AnnotationDeviceAllocated: string(data)}
+	}
+	return r
+}
+
+// nominateTestReservation records the reservation as matching the pod on the node, as the Reservation plugin does in
+// PreFilter.
+func nominateTestReservation(cycleState *framework.CycleState, r *schedulingv1alpha1.Reservation) {
+	frameworkext.SetReservationNomination(cycleState, &frameworkext.ReservationNomination{
+		Matched: map[string][]*schedulingv1alpha1.Reservation{r.Status.NodeName: {r}},
+	})
+}
+
+// assumeTestReservation assumes the first reservation matching the pod, as the Reservation plugin does in Reserve
+// before DeviceShare.
+func assumeTestReservation(cycleState *framework.CycleState) {
+	nomination := frameworkext.GetReservationNomination(cycleState)
+	for _, matched := range nomination.GetMatchedOnNode("test-node") {
+		nomination.Assumed = matched
+		return
+	}
+}
+
+func Test_Plugin_ReserveFromReservation(t *testing.T) {
+	deviceCache := newTestGPUDeviceCache(2)
+	r := newTestGPUReservation(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: testWholeGPU}},
+	})
+	deviceCache.addReservation(r)
+	nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+	assert.True(t, quotav1.IsZero(nodeDeviceInfo.deviceFree[schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	schedule := func(name string, owner bool, request corev1.ResourceList) (*framework.CycleState, *preFilterState, *framework.Status) {
+		state := &preFilterState{convertedDeviceResource: request}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, state)
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if owner {
+			pod.Labels = map[string]string{"app": "reserved"}
+			nominateTestReservation(cycleState, r)
+		}
+		if status := p.Filter(context.TODO(), cycleState, pod, nodeInfo); !status.IsSuccess() {
+			return cycleState, state, status
+		}
+		assumeTestReservation(cycleState)
+		return cycleState, state, p.Reserve(context.TODO(), cycleState, pod, "test-node")
+	}
+
+	// the other pods can't allocate the reserved GPU
+	_, otherState, status := schedule("other-1", false, testWholeGPU)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int32(1), otherState.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+	_, _, status = schedule("other-2", false, testHalfGPU)
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices), status)
+
+	// the owners allocate from the reserved GPU without double-counting it
+	_, ownerState1, status := schedule("owner-1", true, testHalfGPU)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int32(0), ownerState1.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+	remaining := nodeDeviceInfo.getReservationRemaining()
+	assert.True(t, quotav1.Equals(testHalfGPU, remaining["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+
+	ownerCycleState2, ownerState2, status := schedule("owner-2", true, testHalfGPU)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int32(0), ownerState2.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+	assert.Empty(t, nodeDeviceInfo.getReservationRemaining()["reservation-gpu"])
+	_, _, status = schedule("owner-3", true, testHalfGPU)
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices), status)
+
+	// the devices unreserved return to the reservation
+	p.Unreserve(context.TODO(), ownerCycleState2, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner-2"}}, "test-node")
+	remaining = nodeDeviceInfo.getReservationRemaining()
+	assert.True(t, quotav1.Equals(testHalfGPU, remaining["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+
+	// the devices not consumed are released with the reservation, the consumed ones stay allocated to the owner
+	deviceCache.deleteReservation(r)
+	assert.Nil(t, nodeDeviceInfo.getReservationRemaining())
+	assert.True(t, quotav1.Equals(testHalfGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+	_, otherState, status = schedule("other-2", false, testHalfGPU)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int32(0), otherState.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+}
+
+func Test_Plugin_ReserveFromReservationAndFreeDevices(t *testing.T) {
+	deviceCache := newTestGPUDeviceCache(3)
+	r := newTestGPUReservation(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: testWholeGPU}},
+	})
+	deviceCache.addReservation(r)
+	nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	schedule := func(name string, request corev1.ResourceList) (*framework.CycleState, *preFilterState, *framework.Status) {
+		state := &preFilterState{convertedDeviceResource: request}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, state)
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": "reserved"}}}
+		nominateTestReservation(cycleState, r)
+		if status := p.Filter(context.TODO(), cycleState, pod, nodeInfo); !status.IsSuccess() {
+			return cycleState, state, status
+		}
+		assumeTestReservation(cycleState)
+		return cycleState, state, p.Reserve(context.TODO(), cycleState, pod, "test-node")
+	}
+
+	// the owner requesting exactly the reserved GPU allocates the reserved GPU only
+	cycleState, state, status := schedule("owner-1", testWholeGPU)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, types.UID("reservation-gpu-uid"), state.reservationUID)
+	assert.Equal(t, state.allocationResult, state.reservedAllocations)
+	assert.Equal(t, []int32{0}, allocatedMinors(state.allocationResult[schedulingv1alpha1.GPU]))
+	p.Unreserve(context.TODO(), cycleState, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner-1"}}, "test-node")
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.getReservationRemaining()["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+
+	// the owner requesting more than the reservation allocates the reserved GPU and a free GPU
+	twoGPUs := quotav1.Add(testWholeGPU, testWholeGPU)
+	cycleState, state, status = schedule("owner-2", twoGPUs)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, types.UID("reservation-gpu-uid"), state.reservationUID)
+	minors := allocatedMinors(state.allocationResult[schedulingv1alpha1.GPU])
+	assert.Len(t, minors, 2)
+	assert.Contains(t, minors, int32(0))
+	assert.NotEqual(t, minors[0], minors[1])
+	assert.Equal(t, []int32{0}, allocatedMinors(state.reservedAllocations[schedulingv1alpha1.GPU]))
+	assert.Empty(t, nodeDeviceInfo.getReservationRemaining()["reservation-gpu"])
+	freeMinor := minors[0]
+	if freeMinor == 0 {
+		freeMinor = minors[1]
+	}
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][int(freeMinor)]))
+
+	// the reserved GPU returns to the reservation and the free GPU is free again
+	p.Unreserve(context.TODO(), cycleState, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner-2"}}, "test-node")
+	assert.Empty(t, state.reservationUID)
+	assert.Nil(t, state.reservedAllocations)
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.getReservationRemaining()["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.IsZero(nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][int(freeMinor)]))
+
+	// the owner can't allocate more than the reservation and the free devices
+	_, _, status = schedule("owner-3", quotav1.Add(twoGPUs, twoGPUs))
+	assert.False(t, status.IsSuccess())
+
+	// the devices aligned to the topology are not split between the reservation and the free devices
+	state = &preFilterState{convertedDeviceResource: twoGPUs}
+	cycleState = framework.NewCycleState()
+	cycleState.Write(stateKey, state)
+	nominateTestReservation(cycleState, r)
+	restrictedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "owner-4",
+		Labels:      map[string]string{"app": "reserved"},
+		Annotations: map[string]string{apiext.AnnotationResourceSpec: `{"numaTopologyPolicy":"Restricted"}`},
+	}}
+	allocateResult, _, reservationUID, err := p.allocateDevices(context.TODO(), "test-node", restrictedPod, state,
+		nodeDeviceInfo.getNominatedReservedDevices(restrictedPod, []*schedulingv1alpha1.Reservation{r}), nodeDeviceInfo, nodeDeviceInfo)
+	assert.NoError(t, err)
+	assert.Empty(t, reservationUID)
+	assert.Equal(t, []int32{1, 2}, allocatedMinors(allocateResult[schedulingv1alpha1.GPU]))
+}
+
+func Test_Plugin_ReserveWithoutAssumedReservation(t *testing.T) {
+	deviceCache := newTestGPUDeviceCache(2)
+	r := newTestGPUReservation(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: testWholeGPU}},
+	})
+	deviceCache.addReservation(r)
+	nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	state := &preFilterState{convertedDeviceResource: testWholeGPU}
+	cycleState := framework.NewCycleState()
+	cycleState.Write(stateKey, state)
+	nominateTestReservation(cycleState, r)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner-1", Labels: map[string]string{"app": "reserved"}}}
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+
+	// the Reservation plugin does not assume the reservation, the pod allocates the free devices only
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node").IsSuccess())
+	assert.Empty(t, state.reservationUID)
+	assert.Equal(t, []int32{1}, allocatedMinors(state.allocationResult[schedulingv1alpha1.GPU]))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.getReservationRemaining()["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+}
+
+// getShippedReservePlugins returns the Reserve plugins enabled in config/manager/scheduler-config.yaml in order.
+func getShippedReservePlugins(t *testing.T) []string {
+	data, err := os.ReadFile("../../../../config/manager/scheduler-config.yaml")
+	assert.NoError(t, err)
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, yaml.Unmarshal(data, cm))
+	cfg := &schedconfigv1beta2.KubeSchedulerConfiguration{}
+	assert.NoError(t, yaml.Unmarshal([]byte(cm.Data["koord-scheduler-config"]), cfg))
+	assert.Len(t, cfg.Profiles, 1)
+	var names []string
+	for _, plugin := range cfg.Profiles[0].Plugins.Reserve.Enabled {
+		names = append(names, plugin.Name)
+	}
+	return names
+}
+
+func Test_Plugin_ReserveFromReservationInShippedOrder(t *testing.T) {
+	// the only GPU is held by the reservation, so the owner allocates it only if the reservation is assumed
+	deviceCache := newTestGPUDeviceCache(1)
+	r := newTestGPUReservation(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: testWholeGPU}},
+	})
+	deviceCache.addReservation(r)
+
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+	reserve := func(plugins []string) (*preFilterState, *framework.Status) {
+		state := &preFilterState{convertedDeviceResource: testWholeGPU}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, state)
+		nominateTestReservation(cycleState, r)
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner", Labels: map[string]string{"app": "reserved"}}}
+		assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+		for _, name := range plugins {
+			switch name {
+			case "Reservation":
+				assumeTestReservation(cycleState)
+			case Name:
+				if status := p.Reserve(context.TODO(), cycleState, pod, "test-node"); !status.IsSuccess() {
+					return state, status
+				}
+			}
+		}
+		return state, nil
+	}
+
+	// DeviceShare reserving before Reservation misses the assumed reservation
+	_, status := reserve([]string{Name, "Reservation"})
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices), status)
+
+	plugins := getShippedReservePlugins(t)
+	assert.Contains(t, plugins, "Reservation")
+	assert.Contains(t, plugins, Name)
+	state, status := reserve(plugins)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, r.UID, state.reservationUID)
+	assert.Equal(t, []int32{0}, allocatedMinors(state.allocationResult[schedulingv1alpha1.GPU]))
+}
+
+func allocatedMinors(allocations []*apiext.DeviceAllocation) []int32 {
+	var minors []int32
+	for _, allocation := range allocations {
+		minors = append(minors, allocation.Minor)
+	}
+	return minors
+}
+
+func Test_Plugin_ReserveReservePod(t *testing.T) {
+	r := newTestGPUReservation(nil)
+	koordClientSet := koordfake.NewSimpleClientset(r)
+	extendHandle, _ := frameworkext.NewExtendedHandle(frameworkext.WithKoordinatorClientSet(koordClientSet))
+	deviceCache := newTestGPUDeviceCache(2)
+	nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+	p := &Plugin{
+		nodeDeviceCache: deviceCache,
+		allocator:       &defaultAllocator{},
+		handle:          &fakeExtendedHandle{ExtendedHandle: extendHandle, cs: kubefake.NewSimpleClientset()},
+	}
+
+	reservePod := util.NewReservePod(r)
+	cycleState := framework.NewCycleState()
+	cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: testWholeGPU})
+	status := p.Reserve(context.TODO(), cycleState, reservePod, "test-node")
+	assert.True(t, status.IsSuccess())
+	reservePodKey := types.NamespacedName{Namespace: reservePod.Namespace, Name: reservePod.Name}
+	assert.True(t, nodeDeviceInfo.isReservingDevices(reservePodKey))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+
+	// the devices reserved are recorded in the reservation since the reserve pod is never created
+	status = p.PreBind(context.TODO(), cycleState, reservePod, "test-node")
+	assert.True(t, status.IsSuccess())
+	patched, err := koordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), r.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	allocations, err := apiext.GetDeviceAllocations(patched.Annotations)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), allocations[schedulingv1alpha1.GPU][0].Minor)
+
+	// the available reservation takes over the devices reserved without accounting them again
+	deviceCache.addReservation(patched)
+	assert.False(t, nodeDeviceInfo.isReservingDevices(reservePodKey))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.IsZero(nodeDeviceInfo.deviceFree[schedulingv1alpha1.GPU][0]))
+
+	// the reserve pod failing to bind releases the devices
+	p.Unreserve(context.TODO(), cycleState, reservePod, "test-node")
+	assert.Nil(t, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0])
+	assert.Nil(t, nodeDeviceInfo.getReservationRemaining())
+}
+
+func Test_nodeDeviceCache_addPodConsumingReservation(t *testing.T) {
+	deviceCache := newTestGPUDeviceCache(2)
+	r := newTestGPUReservation(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: testWholeGPU}},
+	})
+	deviceCache.addReservation(r)
+	nodeDeviceInfo := deviceCache.getNodeDevice("test-node")
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner", Labels: map[string]string{"app": "reserved"}},
+		Spec:       corev1.PodSpec{NodeName: "test-node"},
+	}
+	assert.NoError(t, apiext.SetDeviceAllocations(pod, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: testHalfGPU}},
+	}))
+	apiext.SetReservationAllocated(pod, r)
+	deviceCache.addPod(pod)
+	remaining := nodeDeviceInfo.getReservationRemaining()
+	assert.True(t, quotav1.Equals(testHalfGPU, remaining["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+
+	// the pod added again does not consume the reservation twice
+	deviceCache.addPod(pod)
+	remaining = nodeDeviceInfo.getReservationRemaining()
+	assert.True(t, quotav1.Equals(testHalfGPU, remaining["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+
+	deviceCache.deletePod(pod)
+	remaining = nodeDeviceInfo.getReservationRemaining()
+	assert.True(t, quotav1.Equals(testWholeGPU, remaining["reservation-gpu"][schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.Equals(testWholeGPU, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0]))
+
+	// the inactive reservation releases the devices
+	succeeded := r.DeepCopy()
+	succeeded.Status.Phase = schedulingv1alpha1.ReservationSucceeded
+	deviceCache.onReservationUpdate(r, succeeded)
+	assert.Nil(t, nodeDeviceInfo.deviceUsed[schedulingv1alpha1.GPU][0])
+}
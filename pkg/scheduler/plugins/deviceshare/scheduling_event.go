@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+const (
+	reasonDeviceMissing      = "DeviceMissing"
+	reasonDeviceInsufficient = "DeviceInsufficient"
+	reasonDeviceFilterFailed = "DeviceFilterFailed"
+
+	// filterFailuresKey is the key in CycleState to the failures of Filter on the nodes.
+	filterFailuresKey = Name + "/filterFailures"
+
+	// each pod emits at most one event of each reason in the interval, so that the pod stuck for many scheduling
+	// cycles doesn't flood the events
+	schedulingEventInterval = time.Minute
+	maxSchedulingEventPods  = 10000
+	// maxSchedulingEventNodes limits the nodes listed in a failure event.
+	maxSchedulingEventNodes = 5
+)
+
+// schedulingEventRecorder emits the events of the device filtering failures of the pods. The devices allocated are
+// reported by the allocationReporter once the pod is bound.
+type schedulingEventRecorder struct {
+	recorder events.EventRecorder
+	// resourceNames formats the requests of the device types supported by the plugin instance.
+	resourceNames deviceResourceNames
+	lock          sync.Mutex
+	// emitted are the pod UIDs and reasons of the events emitted in the interval.
+	emitted *utilcache.LRUExpireCache
+}
+
+func newSchedulingEventRecorder(recorder events.EventRecorder, resourceNames deviceResourceNames) *schedulingEventRecorder {
+	if recorder == nil {
+		return nil
+	}
+	return &schedulingEventRecorder{
+		recorder:      recorder,
+		resourceNames: resourceNames,
+		emitted:       utilcache.NewLRUExpireCache(maxSchedulingEventPods),
+	}
+}
+
+// deviceFilterFailure is the nodes failed in Filter for the same event reason, and the first failure message.
+type deviceFilterFailure struct {
+	message string
+	nodes   []string
+}
+
+// deviceFilterFailures collects the failures of Filter on the nodes in a scheduling cycle, so that the event of a
+// reason lists all the nodes failed for it instead of the first node filtered. Filter runs in parallel for the nodes.
+type deviceFilterFailures struct {
+	lock     sync.Mutex
+	failures map[string]*deviceFilterFailure
+}
+
+func newDeviceFilterFailures() *deviceFilterFailures {
+	return &deviceFilterFailures{failures: map[string]*deviceFilterFailure{}}
+}
+
+// Clone returns empty failures, the Filter runs on the cloned CycleState, e.g. the dry runs of the preemption, are
+// not the failures to schedule the pod.
+func (f *deviceFilterFailures) Clone() framework.StateData {
+	return newDeviceFilterFailures()
+}
+
+func getDeviceFilterFailures(cycleState *framework.CycleState) *deviceFilterFailures {
+	value, err := cycleState.Read(filterFailuresKey)
+	if err != nil {
+		return nil
+	}
+	return value.(*deviceFilterFailures)
+}
+
+// add records the failure of Filter on the node. ErrMissingDevice and ErrInsufficientDevices are told apart.
+func (f *deviceFilterFailures) add(nodeName string, status *framework.Status) {
+	if f == nil || status.IsSuccess() {
+		return
+	}
+	reason := reasonDeviceFilterFailed
+	switch status.Reasons()[0] {
+	case ErrMissingDevice:
+		reason = reasonDeviceMissing
+	case ErrInsufficientDevices:
+		reason = reasonDeviceInsufficient
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	failure := f.failures[reason]
+	if failure == nil {
+		failure = &deviceFilterFailure{message: status.Message()}
+		f.failures[reason] = failure
+	}
+	failure.nodes = append(failure.nodes, nodeName)
+}
+
+// filterFailed emits an event for each reason of the failures of Filter in the scheduling cycle, listing the nodes
+// failed for the reason.
+func (r *schedulingEventRecorder) filterFailed(pod *corev1.Pod, podRequest corev1.ResourceList, failures *deviceFilterFailures) {
+	if r == nil || failures == nil {
+		return
+	}
+	failures.lock.Lock()
+	defer failures.lock.Unlock()
+	reasons := make([]string, 0, len(failures.failures))
+	for reason := range failures.failures {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		if !r.allow(pod, reason) {
+			continue
+		}
+		failure := failures.failures[reason]
+		r.recorder.Eventf(pod, nil, corev1.EventTypeWarning, reason, "Scheduling", "%s on %s, requested %s",
+			failure.message, formatEventNodes(failure.nodes), r.resourceNames.formatDeviceRequest(podRequest))
+	}
+}
+
+// formatEventNodes formats the nodes, e.g. "node-1" or "3 nodes: node-1,node-2,node-3", names at most
+// maxSchedulingEventNodes nodes.
+func formatEventNodes(nodes []string) string {
+	if len(nodes) == 1 {
+		return "node " + nodes[0]
+	}
+	sorted := append([]string(nil), nodes...)
+	sort.Strings(sorted)
+	listed := sorted
+	if len(listed) > maxSchedulingEventNodes {
+		listed = listed[:maxSchedulingEventNodes]
+	}
+	message := fmt.Sprintf("%d nodes: %s", len(sorted), strings.Join(listed, ","))
+	if len(sorted) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(sorted)-len(listed))
+	}
+	return message
+}
+
+func (r *schedulingEventRecorder) allow(pod *corev1.Pod, reason string) bool {
+	key := string(pod.UID) + "/" + reason
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.emitted.Get(key); ok {
+		return false
+	}
+	r.emitted.Add(key, struct{}{}, schedulingEventInterval)
+	return true
+}
+
+// formatDeviceRequest formats the converted device request with the device types, e.g. "gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100)".
+func (d deviceResourceNames) formatDeviceRequest(podRequest corev1.ResourceList) string {
+	var requests []string
+	for _, deviceType := range d.getRequestedDeviceTypes(podRequest) {
+		request := quotav1.Mask(podRequest, d[deviceType])
+		if deviceType == schedulingv1alpha1.GPU {
+			request = quotav1.Add(request, getMIGRequest(podRequest))
+			if sharedSlots, ok := podRequest[apiext.GPUSharedSlots]; ok {
+				request[apiext.GPUSharedSlots] = sharedSlots
+			}
+		}
+		quantities := make([]string, 0, len(request))
+		for resourceName, quantity := range request {
+			quantities = append(quantities, fmt.Sprintf("%s:%s", resourceName, quantity.String()))
+		}
+		sort.Strings(quantities)
+		requests = append(requests, fmt.Sprintf("%s(%s)", deviceType, strings.Join(quantities, ",")))
+	}
+	return strings.Join(requests, " ")
+}
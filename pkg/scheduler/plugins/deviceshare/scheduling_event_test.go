@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func TestSchedulingEventRecorder(t *testing.T) {
+	podRequest := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.KoordRDMA:      resource.MustParse("1"),
+	}
+	tests := []struct {
+		name       string
+		failures   map[string]*framework.Status
+		wantEvents []string
+	}{
+		{
+			name: "no failure",
+		},
+		{
+			name: "missing device",
+			failures: map[string]*framework.Status{
+				"test-node-1": framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingDevice),
+				"test-node-2": nil,
+			},
+			wantEvents: []string{
+				"Warning DeviceMissing node(s) missing Device on node test-node-1, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100) rdma(kubernetes.io/rdma:1)",
+			},
+		},
+		{
+			name: "insufficient devices on the nodes",
+			failures: map[string]*framework.Status{
+				"test-node-2": framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices, "Insufficient kubernetes.io/gpu-core"),
+				"test-node-1": framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices, "Insufficient kubernetes.io/gpu-core"),
+			},
+			wantEvents: []string{
+				"Warning DeviceInsufficient Insufficient Devices, Insufficient kubernetes.io/gpu-core on 2 nodes: test-node-1,test-node-2, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100) rdma(kubernetes.io/rdma:1)",
+			},
+		},
+		{
+			name: "different reasons",
+			failures: map[string]*framework.Status{
+				"test-node-1": framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrUnmetGPUModel),
+				"test-node-2": framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingDevice),
+			},
+			wantEvents: []string{
+				"Warning DeviceFilterFailed " + ErrUnmetGPUModel + " on node test-node-1, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100) rdma(kubernetes.io/rdma:1)",
+				"Warning DeviceMissing node(s) missing Device on node test-node-2, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100) rdma(kubernetes.io/rdma:1)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := events.NewFakeRecorder(10)
+			r := newSchedulingEventRecorder(recorder, DeviceResourceNames)
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-1", UID: "123456"}}
+			failures := newDeviceFilterFailures()
+			for nodeName, status := range tt.failures {
+				failures.add(nodeName, status)
+			}
+
+			r.filterFailed(pod, podRequest, failures)
+			assert.Len(t, recorder.Events, len(tt.wantEvents))
+			for _, wantEvent := range tt.wantEvents {
+				assert.Equal(t, wantEvent, <-recorder.Events)
+			}
+			if len(tt.wantEvents) == 0 {
+				return
+			}
+
+			// the events of the same reason are rate-limited per pod
+			r.filterFailed(pod, podRequest, failures)
+			assert.Len(t, recorder.Events, 0)
+			r.filterFailed(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-2", UID: "654321"}}, podRequest, failures)
+			assert.Len(t, recorder.Events, len(tt.wantEvents))
+		})
+	}
+}
+
+func TestSchedulingEventRecorderWithoutRecorder(t *testing.T) {
+	r := newSchedulingEventRecorder(nil, DeviceResourceNames)
+	assert.Nil(t, r)
+	var failures *deviceFilterFailures
+	failures.add("test-node-1", framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices))
+	r.filterFailed(&corev1.Pod{}, nil, newDeviceFilterFailures())
+}
+
+func TestFormatEventNodes(t *testing.T) {
+	assert.Equal(t, "node node-1", formatEventNodes([]string{"node-1"}))
+	assert.Equal(t, "2 nodes: node-1,node-2", formatEventNodes([]string{"node-2", "node-1"}))
+	assert.Equal(t, "7 nodes: node-1,node-2,node-3,node-4,node-5 and 2 more",
+		formatEventNodes([]string{"node-7", "node-6", "node-5", "node-4", "node-3", "node-2", "node-1"}))
+}
+
+func Test_Plugin_FilterAndPostFilterEmitEvents(t *testing.T) {
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.GPUCore:        resource.MustParse("100"),
+						apiext.GPUMemoryRatio: resource.MustParse("100"),
+						apiext.GPUMemory:      resource.MustParse("16Gi"),
+					},
+				},
+			},
+		},
+	})
+	recorder := events.NewFakeRecorder(10)
+	p := &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}, schedulingEvents: newSchedulingEventRecorder(recorder, DeviceResourceNames)}
+	newPod := func(name, gpuCore string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								apiext.GPUCore:        resource.MustParse(gpuCore),
+								apiext.GPUMemoryRatio: resource.MustParse(gpuCore),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	newNodeInfo := func(nodeName string) *framework.NodeInfo {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}})
+		return nodeInfo
+	}
+
+	pod := newPod("test-pod-1", "50")
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.False(t, p.Filter(context.TODO(), cycleState, pod, newNodeInfo("missing-node")).IsSuccess())
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, newNodeInfo("test-node")).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node").IsSuccess())
+	// the pod scheduled emits no failure event
+	assert.Len(t, recorder.Events, 0)
+
+	pod = newPod("test-pod-2", "100")
+	cycleState = framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.False(t, p.Filter(context.TODO(), cycleState, pod, newNodeInfo("test-node")).IsSuccess())
+	assert.False(t, p.Filter(context.TODO(), cycleState, pod, newNodeInfo("missing-node")).IsSuccess())
+	assert.Len(t, recorder.Events, 0)
+	_, status := p.PostFilter(context.TODO(), cycleState, pod, framework.NodeToStatusMap{})
+	assert.False(t, status.IsSuccess())
+	assert.Len(t, recorder.Events, 2)
+	assert.Equal(t, "Warning DeviceInsufficient Insufficient Devices on node test-node, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100)", <-recorder.Events)
+	assert.Equal(t, "Warning DeviceMissing node(s) missing Device on node missing-node, requested gpu(kubernetes.io/gpu-core:100,kubernetes.io/gpu-memory-ratio:100)", <-recorder.Events)
+}
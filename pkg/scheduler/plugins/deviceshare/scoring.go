@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	pluginhelper "k8s.io/kubernetes/pkg/scheduler/framework/plugins/helper"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// preScoreStateKey is the key in CycleState to the data pre-computed in PreScore.
+const preScoreStateKey = "PreScore" + Name
+
+type preScoreState struct {
+	// localityNodes contains the nodes running the pods selected by LocalityAffinity.
+	localityNodes sets.String
+	// localityWeight is the score added to the localityNodes.
+	localityWeight int64
+}
+
+func (s *preScoreState) Clone() framework.StateData {
+	return s
+}
+
+// localityAffinity is the parsed LocalityAffinity in DeviceShareArgs.
+type localityAffinity struct {
+	selector   labels.Selector
+	namespaces sets.String
+	// weight is the score added to the nodes running the selected pods.
+	weight int64
+}
+
+func newLocalityAffinity(args *config.DeviceLocalityAffinity) (*localityAffinity, error) {
+	if args == nil || args.PodSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(args.PodSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid podSelector of localityAffinity, err: %v", err)
+	}
+	weight := framework.MaxNodeScore
+	if args.Weight != nil {
+		weight = *args.Weight
+	}
+	return &localityAffinity{
+		selector:   selector,
+		namespaces: sets.NewString(args.Namespaces...),
+		weight:     weight,
+	}, nil
+}
+
+func (l *localityAffinity) matches(pod *corev1.Pod) bool {
+	if l.namespaces.Len() > 0 && !l.namespaces.Has(pod.Namespace) {
+		return false
+	}
+	return pod.Spec.NodeName != "" && !util.IsPodTerminated(pod)
+}
+
+func (p *Plugin) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodes []*corev1.Node) *framework.Status {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return status
+	}
+	locality := p.getDynamicArgs().locality
+	if state.skip || locality == nil {
+		return nil
+	}
+
+	pods, err := p.podLister.List(locality.selector)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	localityNodes := sets.NewString()
+	for _, v := range pods {
+		if v.UID != pod.UID && locality.matches(v) {
+			localityNodes.Insert(v.Spec.NodeName)
+		}
+	}
+	cycleState.Write(preScoreStateKey, &preScoreState{
+		localityNodes:  localityNodes,
+		localityWeight: locality.weight,
+	})
+	return nil
+}
+
+func getPreScoreState(cycleState *framework.CycleState) *preScoreState {
+	value, err := cycleState.Read(preScoreStateKey)
+	if err != nil {
+		return nil
+	}
+	return value.(*preScoreState)
+}
+
+func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return 0, status
+	}
+	if state.skip {
+		return 0, nil
+	}
+
+	// the locality nodes get the locality weight on top of the device resources score, and NormalizeScore scales
+	// the sum back into the node score range
+	var score int64
+	if scoringStrategy := p.getDynamicArgs().scoringStrategy; scoringStrategy != nil {
+		score = p.scoreDeviceResources(scoringStrategy, state.convertedDeviceResource, nodeName)
+	}
+	if scoreState := getPreScoreState(cycleState); scoreState != nil && scoreState.localityNodes.Has(nodeName) {
+		score += scoreState.localityWeight
+	}
+	return score, nil
+}
+
+// scoreDeviceResources scores the node by the device resources allocated after placing the pod requests, which are
+// weighted by the scoring strategy. The resources not requested by the pod are ignored.
+// The node without the devices scores zero.
+func (p *Plugin) scoreDeviceResources(scoringStrategy *config.ScoringStrategy, podRequest corev1.ResourceList, nodeName string) int64 {
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+	if nodeDeviceInfo == nil {
+		return 0
+	}
+	nodeDeviceInfo.lock.RLock()
+	defer nodeDeviceInfo.lock.RUnlock()
+
+	var score, weightSum int64
+	for _, r := range scoringStrategy.Resources {
+		resourceName := corev1.ResourceName(r.Name)
+		requested, ok := podRequest[resourceName]
+		if !ok || requested.IsZero() {
+			continue
+		}
+		weightSum += r.Weight
+		deviceType := p.nodeDeviceCache.getResourceNames().getResourceDeviceType(resourceName)
+		total := sumDeviceResource(nodeDeviceInfo.deviceTotal[deviceType], resourceName)
+		if total <= 0 {
+			continue
+		}
+		allocated := total - sumDeviceResource(nodeDeviceInfo.deviceFree[deviceType], resourceName) + requested.Value()
+		if allocated > total {
+			allocated = total
+		}
+		var resourceScore int64
+		switch scoringStrategy.Type {
+		case config.MostAllocated:
+			resourceScore = allocated * framework.MaxNodeScore / total
+		case config.LeastAllocated:
+			resourceScore = (total - allocated) * framework.MaxNodeScore / total
+		}
+		score += resourceScore * r.Weight
+	}
+	if weightSum <= 0 {
+		return 0
+	}
+	return score / weightSum
+}
+
+// getResourceDeviceType returns the device type providing the resource.
+func (d deviceResourceNames) getResourceDeviceType(resourceName corev1.ResourceName) schedulingv1alpha1.DeviceType {
+	for deviceType, resourceNames := range d {
+		for _, name := range resourceNames {
+			if name == resourceName {
+				return deviceType
+			}
+		}
+	}
+	return ""
+}
+
+// sumDeviceResource returns the sum of the resource of all devices.
+func sumDeviceResource(resources deviceResources, resourceName corev1.ResourceName) int64 {
+	var sum int64
+	for _, resourceList := range resources {
+		if q, ok := resourceList[resourceName]; ok {
+			sum += q.Value()
+		}
+	}
+	return sum
+}
+
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	return p
+}
+
+// NormalizeScore scales the scores so the best node scores MaxNodeScore, which keeps the device scores comparable
+// with the other plugins when all nodes are lightly allocated.
+func (p *Plugin) NormalizeScore(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, scores framework.NodeScoreList) *framework.Status {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return status
+	}
+	if state.skip {
+		return nil
+	}
+	return pluginhelper.DefaultNormalizeScore(framework.MaxNodeScore, false, scores)
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func Test_newLocalityAffinity(t *testing.T) {
+	locality, err := newLocalityAffinity(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, locality)
+
+	_, err = newLocalityAffinity(&config.DeviceLocalityAffinity{
+		PodSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: "BadOperator"},
+			},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func Test_Plugin_Score_LocalityAffinity(t *testing.T) {
+	producer := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "producer",
+			UID:       "producer",
+			Labels:    map[string]string{"app": "data-producer"},
+		},
+		Spec: corev1.PodSpec{NodeName: "node-1"},
+	}
+	otherNamespaceProducer := producer.DeepCopy()
+	otherNamespaceProducer.Namespace = "other"
+	otherNamespaceProducer.UID = "other-producer"
+	otherNamespaceProducer.Spec.NodeName = "node-3"
+	terminatedProducer := producer.DeepCopy()
+	terminatedProducer.Name = "terminated-producer"
+	terminatedProducer.UID = "terminated-producer"
+	terminatedProducer.Spec.NodeName = "node-4"
+	terminatedProducer.Status.Phase = corev1.PodSucceeded
+	unrelated := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "unrelated",
+			UID:       "unrelated",
+		},
+		Spec: corev1.PodSpec{NodeName: "node-2"},
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	for _, pod := range []*corev1.Pod{producer, otherNamespaceProducer, terminatedProducer, unrelated} {
+		assert.NoError(t, podInformer.Informer().GetStore().Add(pod))
+	}
+
+	tests := []struct {
+		name     string
+		locality *config.DeviceLocalityAffinity
+		skip     bool
+		want     map[string]int64
+	}{
+		{
+			name: "no locality affinity",
+			want: map[string]int64{"node-1": 0, "node-2": 0, "node-3": 0, "node-4": 0},
+		},
+		{
+			name: "prefer the node running producer",
+			locality: &config.DeviceLocalityAffinity{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "data-producer"}},
+				Namespaces:  []string{"default"},
+			},
+			want: map[string]int64{"node-1": framework.MaxNodeScore, "node-2": 0, "node-3": 0, "node-4": 0},
+		},
+		{
+			name: "all namespaces selected",
+			locality: &config.DeviceLocalityAffinity{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "data-producer"}},
+			},
+			want: map[string]int64{"node-1": framework.MaxNodeScore, "node-2": 0, "node-3": framework.MaxNodeScore, "node-4": 0},
+		},
+		{
+			name: "weighted locality affinity",
+			locality: &config.DeviceLocalityAffinity{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "data-producer"}},
+				Namespaces:  []string{"default"},
+				Weight:      pointer.Int64(30),
+			},
+			want: map[string]int64{"node-1": 30, "node-2": 0, "node-3": 0, "node-4": 0},
+		},
+		{
+			name: "skip pod without device requests",
+			locality: &config.DeviceLocalityAffinity{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "data-producer"}},
+			},
+			skip: true,
+			want: map[string]int64{"node-1": 0, "node-2": 0, "node-3": 0, "node-4": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locality, err := newLocalityAffinity(tt.locality)
+			assert.NoError(t, err)
+			p := &Plugin{
+				podLister: podInformer.Lister(),
+				locality:  locality,
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "consumer", UID: "consumer"},
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{skip: tt.skip})
+
+			status := p.PreScore(context.TODO(), cycleState, pod, nil)
+			assert.True(t, status.IsSuccess())
+			for nodeName, want := range tt.want {
+				score, status := p.Score(context.TODO(), cycleState, pod, nodeName)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, want, score, nodeName)
+			}
+		})
+	}
+}
+
+func Test_Plugin_Score_ScoringStrategy(t *testing.T) {
+	wholeGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	deviceCache := newNodeDeviceCache()
+	for _, nodeName := range []string{"half-used-node", "empty-node"} {
+		gpus := deviceResources{}
+		for minor := 0; minor < 8; minor++ {
+			gpus[minor] = wholeGPU.DeepCopy()
+		}
+		deviceCache.createNodeDevice(nodeName).resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: gpus,
+			schedulingv1alpha1.RDMA: {
+				0: corev1.ResourceList{apiext.KoordRDMA: resource.MustParse("100")},
+			},
+		})
+	}
+	usedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "used", UID: "used"}}
+	var used []*apiext.DeviceAllocation
+	for minor := int32(0); minor < 4; minor++ {
+		used = append(used, &apiext.DeviceAllocation{Minor: minor, Resources: wholeGPU.DeepCopy()})
+	}
+	deviceCache.getNodeDevice("half-used-node").updateCacheUsed(apiext.DeviceAllocations{schedulingv1alpha1.GPU: used}, usedPod, true)
+
+	oneGPU := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.GPUMemory:      resource.MustParse("16Gi"),
+	}
+	tests := []struct {
+		name            string
+		scoringStrategy *config.ScoringStrategy
+		podRequest      corev1.ResourceList
+		want            map[string]int64
+	}{
+		{
+			name:       "no scoring strategy",
+			podRequest: oneGPU,
+			want:       map[string]int64{"half-used-node": 0, "empty-node": 0, "no-device-node": 0},
+		},
+		{
+			name: "MostAllocated prefers the half used node",
+			scoringStrategy: &config.ScoringStrategy{
+				Type: config.MostAllocated,
+				Resources: []schedconfig.ResourceSpec{
+					{Name: string(apiext.GPUCore), Weight: 1},
+					{Name: string(apiext.GPUMemoryRatio), Weight: 1},
+				},
+			},
+			podRequest: oneGPU,
+			want:       map[string]int64{"half-used-node": 62, "empty-node": 12, "no-device-node": 0},
+		},
+		{
+			name: "LeastAllocated prefers the empty node",
+			scoringStrategy: &config.ScoringStrategy{
+				Type: config.LeastAllocated,
+				Resources: []schedconfig.ResourceSpec{
+					{Name: string(apiext.GPUCore), Weight: 1},
+					{Name: string(apiext.GPUMemoryRatio), Weight: 1},
+				},
+			},
+			podRequest: oneGPU,
+			want:       map[string]int64{"half-used-node": 37, "empty-node": 87, "no-device-node": 0},
+		},
+		{
+			name: "weighted by all requested device types",
+			scoringStrategy: &config.ScoringStrategy{
+				Type: config.MostAllocated,
+				Resources: []schedconfig.ResourceSpec{
+					{Name: string(apiext.GPUCore), Weight: 1},
+					{Name: string(apiext.KoordRDMA), Weight: 3},
+					{Name: string(apiext.KoordFPGA), Weight: 1},
+				},
+			},
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.KoordRDMA:      resource.MustParse("100"),
+			},
+			// (62 * 1 + 100 * 3) / 4 and (12 * 1 + 100 * 3) / 4
+			want: map[string]int64{"half-used-node": 90, "empty-node": 78, "no-device-node": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{
+				nodeDeviceCache: deviceCache,
+				scoringStrategy: tt.scoringStrategy,
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test"},
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: tt.podRequest})
+
+			status := p.PreScore(context.TODO(), cycleState, pod, nil)
+			assert.True(t, status.IsSuccess())
+			for nodeName, want := range tt.want {
+				score, status := p.Score(context.TODO(), cycleState, pod, nodeName)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, want, score, nodeName)
+			}
+		})
+	}
+
+	t.Run("the locality weight is added on top of the device resources score", func(t *testing.T) {
+		p := &Plugin{
+			nodeDeviceCache: deviceCache,
+			scoringStrategy: tests[1].scoringStrategy,
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test"}}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: oneGPU})
+		cycleState.Write(preScoreStateKey, &preScoreState{
+			localityNodes:  sets.NewString("empty-node"),
+			localityWeight: framework.MaxNodeScore,
+		})
+		var scores framework.NodeScoreList
+		for _, nodeName := range []string{"half-used-node", "empty-node"} {
+			score, status := p.Score(context.TODO(), cycleState, pod, nodeName)
+			assert.True(t, status.IsSuccess())
+			scores = append(scores, framework.NodeScore{Name: nodeName, Score: score})
+		}
+		assert.Equal(t, framework.NodeScoreList{{Name: "half-used-node", Score: 62}, {Name: "empty-node", Score: 112}}, scores)
+		assert.True(t, p.NormalizeScore(context.TODO(), cycleState, pod, scores).IsSuccess())
+		assert.Equal(t, framework.NodeScoreList{{Name: "half-used-node", Score: 55}, {Name: "empty-node", Score: 100}}, scores)
+	})
+
+	t.Run("the reloaded scoring strategy affects the subsequent scores", func(t *testing.T) {
+		p := &Plugin{
+			nodeDeviceCache: deviceCache,
+			initialArgs:     &config.DeviceShareArgs{ScoringStrategy: tests[1].scoringStrategy},
+			scoringStrategy: tests[1].scoringStrategy,
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test"}}
+		cycleState := framework.NewCycleState()
+		cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: oneGPU})
+		score, status := p.Score(context.TODO(), cycleState, pod, "half-used-node")
+		assert.True(t, status.IsSuccess())
+		assert.Equal(t, int64(62), score)
+
+		args, err := p.DecodeDynamicArgs([]byte(`
+scoringStrategy:
+  type: LeastAllocated
+  resources:
+  - name: kubernetes.io/gpu-core
+    weight: 1
+  - name: kubernetes.io/gpu-memory-ratio
+    weight: 1
+`))
+		assert.NoError(t, err)
+		p.UpdateDynamicArgs(args)
+		score, status = p.Score(context.TODO(), cycleState, pod, "half-used-node")
+		assert.True(t, status.IsSuccess())
+		assert.Equal(t, int64(37), score)
+
+		_, err = p.DecodeDynamicArgs([]byte(`
+scoringStrategy:
+  type: Unknown
+`))
+		assert.Error(t, err)
+	})
+}
+
+func Test_Plugin_NormalizeScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  *preFilterState
+		scores framework.NodeScoreList
+		want   framework.NodeScoreList
+	}{
+		{
+			name:   "scale the best node to the max score",
+			state:  &preFilterState{},
+			scores: framework.NodeScoreList{{Name: "node-1", Score: 50}, {Name: "node-2", Score: 25}, {Name: "node-3", Score: 0}},
+			want:   framework.NodeScoreList{{Name: "node-1", Score: 100}, {Name: "node-2", Score: 50}, {Name: "node-3", Score: 0}},
+		},
+		{
+			name:   "all nodes score zero",
+			state:  &preFilterState{},
+			scores: framework.NodeScoreList{{Name: "node-1", Score: 0}, {Name: "node-2", Score: 0}},
+			want:   framework.NodeScoreList{{Name: "node-1", Score: 0}, {Name: "node-2", Score: 0}},
+		},
+		{
+			name:   "skip the pod without device requests",
+			state:  &preFilterState{skip: true},
+			scores: framework.NodeScoreList{{Name: "node-1", Score: 50}, {Name: "node-2", Score: 25}},
+			want:   framework.NodeScoreList{{Name: "node-1", Score: 50}, {Name: "node-2", Score: 25}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, tt.state)
+			status := p.ScoreExtensions().NormalizeScore(context.TODO(), cycleState, &corev1.Pod{}, tt.scores)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.want, tt.scores)
+		})
+	}
+}
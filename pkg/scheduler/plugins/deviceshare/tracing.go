@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+const tracerName = "github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/deviceshare"
+
+const (
+	attributeKeyPod         = attribute.Key("pod")
+	attributeKeyNode        = attribute.Key("node")
+	attributeKeyDeviceTypes = attribute.Key("device.types")
+	attributeKeyOutcome     = attribute.Key("outcome")
+	attributeKeyAllocator   = attribute.Key("allocator")
+	attributeKeyAttempts    = attribute.Key("attempts")
+)
+
+var noopTracer = trace.NewNoopTracerProvider().Tracer(tracerName)
+
+// startSpan starts the span of the extension point named as DeviceShare/<extensionPoint>.
+func (p *Plugin) startSpan(ctx context.Context, extensionPoint string, pod *corev1.Pod, nodeName string) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{attributeKeyPod.String(pod.Namespace + "/" + pod.Name)}
+	if nodeName != "" {
+		attributes = append(attributes, attributeKeyNode.String(nodeName))
+	}
+	return p.getTracer().Start(ctx, Name+"/"+extensionPoint, trace.WithAttributes(attributes...))
+}
+
+func (p *Plugin) getTracer() trace.Tracer {
+	if p.tracer == nil {
+		return noopTracer
+	}
+	return p.tracer
+}
+
+// allocate calls the allocator in the span named as DeviceShare/Allocate, the child of the extension point's span.
+func (p *Plugin) allocate(ctx context.Context, nodeName string, pod *corev1.Pod, podRequest corev1.ResourceList, nodeDevice *nodeDevice) (apiext.DeviceAllocations, error) {
+	_, span := p.getTracer().Start(ctx, Name+"/Allocate")
+	defer span.End()
+	allocateResult, err := p.allocator.Allocate(nodeName, pod, podRequest, nodeDevice)
+	if span.IsRecording() {
+		span.SetAttributes(attributeKeyAllocator.String(p.allocator.Name()))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	return allocateResult, err
+}
+
+// patchPod patches the pod in the span named as DeviceShare/PatchPod with the number of the attempts.
+// The patch is retried on the conflicts, the throttling and the device allocations not read back as patched.
+func (p *Plugin) patchPod(ctx context.Context, backoff wait.Backoff, patch func(ctx context.Context) error) error {
+	ctx, span := p.getTracer().Start(ctx, Name+"/PatchPod")
+	defer span.End()
+	attempts := 0
+	err := retry.OnError(backoff, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsTooManyRequests(err) || errors.Is(err, errDeviceAllocationsMismatch)
+	}, func() error {
+		attempts++
+		return patch(ctx)
+	})
+	if span.IsRecording() {
+		span.SetAttributes(attributeKeyAttempts.Int(attempts))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	return err
+}
+
+// endSpan annotates the span with the device types requested by the pod and the outcome of the extension point.
+func (p *Plugin) endSpan(span trace.Span, cycleState *framework.CycleState, status *framework.Status) {
+	defer span.End()
+	if !span.IsRecording() {
+		return
+	}
+	if state, s := getPreFilterState(cycleState); s.IsSuccess() && len(state.convertedDeviceResource) > 0 {
+		var deviceTypes []string
+		resourceNames := p.nodeDeviceCache.getResourceNames()
+		for deviceType := range resourceNames {
+			if resourceNames.hasDeviceResource(state.convertedDeviceResource, deviceType) {
+				deviceTypes = append(deviceTypes, string(deviceType))
+			}
+		}
+		sort.Strings(deviceTypes)
+		span.SetAttributes(attributeKeyDeviceTypes.Array(deviceTypes))
+	}
+	span.SetAttributes(attributeKeyOutcome.String(status.Code().String()))
+	if status.Code() == framework.Error {
+		span.SetStatus(codes.Error, status.Message())
+	}
+}
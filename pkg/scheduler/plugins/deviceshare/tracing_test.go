@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func spanAttributes(span *sdktrace.SpanSnapshot) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestPluginTracing(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.GPUCore:        resource.MustParse("100"),
+							apiext.GPUMemoryRatio: resource.MustParse("100"),
+						},
+					},
+				},
+			},
+		},
+	}
+	deviceCache := newNodeDeviceCache()
+	deviceCache.updateNodeDevice("test-node-1", generateFakeDevice())
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"}})
+	missingNodeInfo := framework.NewNodeInfo()
+	missingNodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node-2"}})
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	p := &Plugin{
+		handle:          &fakeExtendedHandle{cs: kubefake.NewSimpleClientset(pod)},
+		nodeDeviceCache: deviceCache,
+		allocator:       &defaultAllocator{},
+		tracer:          tracerProvider.Tracer(tracerName),
+	}
+
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+	assert.False(t, p.Filter(context.TODO(), cycleState, pod, missingNodeInfo).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+	assert.True(t, p.PreBind(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+	// the pod without devices is traced without the device types
+	assert.True(t, p.PreFilter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}).IsSuccess())
+	assert.False(t, p.Reserve(context.TODO(), framework.NewCycleState(), pod, "test-node-1").IsSuccess())
+
+	// the spans of the allocator and the patch are the children of the extension points' spans
+	var spans []*sdktrace.SpanSnapshot
+	var childSpans []*sdktrace.SpanSnapshot
+	for _, span := range exporter.GetSpans() {
+		if span.Parent.IsValid() {
+			childSpans = append(childSpans, span)
+		} else {
+			spans = append(spans, span)
+		}
+	}
+	assert.Len(t, spans, 7)
+	assert.Len(t, childSpans, 3)
+	wantChildren := []struct {
+		name   string
+		parent int
+	}{
+		{name: "DeviceShare/Allocate", parent: 1},
+		{name: "DeviceShare/Allocate", parent: 3},
+		{name: "DeviceShare/PatchPod", parent: 4},
+	}
+	for i, want := range wantChildren {
+		assert.Equal(t, want.name, childSpans[i].Name)
+		assert.Equal(t, spans[want.parent].SpanContext.SpanID(), childSpans[i].Parent.SpanID())
+		assert.Equal(t, codes.Unset, childSpans[i].StatusCode)
+	}
+	assert.Equal(t, "default", spanAttributes(childSpans[0])[attributeKeyAllocator].AsString())
+	assert.Equal(t, int64(1), spanAttributes(childSpans[2])[attributeKeyAttempts].AsInt64())
+	wants := []struct {
+		name    string
+		node    string
+		outcome string
+		isError bool
+	}{
+		{name: "DeviceShare/PreFilter", outcome: "Success"},
+		{name: "DeviceShare/Filter", node: "test-node-1", outcome: "Success"},
+		{name: "DeviceShare/Filter", node: "test-node-2", outcome: "UnschedulableAndUnresolvable"},
+		{name: "DeviceShare/Reserve", node: "test-node-1", outcome: "Success"},
+		{name: "DeviceShare/PreBind", node: "test-node-1", outcome: "Success"},
+		{name: "DeviceShare/PreFilter", outcome: "Success"},
+		{name: "DeviceShare/Reserve", node: "test-node-1", outcome: "Error", isError: true},
+	}
+	for i, want := range wants {
+		span := spans[i]
+		attributes := spanAttributes(span)
+		assert.Equal(t, want.name, span.Name)
+		assert.Equal(t, want.outcome, attributes[attributeKeyOutcome].AsString())
+		if want.node != "" {
+			assert.Equal(t, want.node, attributes[attributeKeyNode].AsString())
+		} else {
+			assert.NotContains(t, attributes, attributeKeyNode)
+		}
+		if i < 5 {
+			assert.Equal(t, "default/test", attributes[attributeKeyPod].AsString())
+			assert.Equal(t, [1]string{"gpu"}, attributes[attributeKeyDeviceTypes].AsArray())
+		} else {
+			assert.NotContains(t, attributes, attributeKeyDeviceTypes)
+		}
+		if want.isError {
+			assert.Equal(t, codes.Error, span.StatusCode)
+		} else {
+			assert.Equal(t, codes.Unset, span.StatusCode)
+		}
+	}
+}
+
+func TestPluginTracingDisabled(t *testing.T) {
+	p := &Plugin{nodeDeviceCache: newNodeDeviceCache()}
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, &corev1.Pod{}).IsSuccess())
+	ctx, span := p.startSpan(context.TODO(), "Filter", &corev1.Pod{}, "test-node-1")
+	assert.NotNil(t, ctx)
+	assert.False(t, span.IsRecording())
+}
@@ -18,13 +18,17 @@ package deviceshare
 
 import (
 	"fmt"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
 const (
@@ -33,50 +37,170 @@ const (
 	GPUCoreExist
 	GPUMemoryExist
 	GPUMemoryRatioExist
+	NvidiaMIGExist
+	GPUSharedSlotsExist
 )
 
-var DeviceResourceNames = map[schedulingv1alpha1.DeviceType][]corev1.ResourceName{
+// DeviceResourceNames is the resource names of the built-in device types.
+var DeviceResourceNames = deviceResourceNames{
 	schedulingv1alpha1.GPU:  {apiext.NvidiaGPU, apiext.KoordGPU, apiext.GPUCore, apiext.GPUMemory, apiext.GPUMemoryRatio},
 	schedulingv1alpha1.RDMA: {apiext.KoordRDMA},
 	schedulingv1alpha1.FPGA: {apiext.KoordFPGA},
 }
 
-func hasDeviceResource(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) bool {
+// builtinDeviceTypes are the device types supported without the registration by the args.
+var builtinDeviceTypes = sets.NewString(string(schedulingv1alpha1.GPU), string(schedulingv1alpha1.RDMA), string(schedulingv1alpha1.FPGA))
+
+// deviceResourceNames maps the device types to their resource names. Each plugin instance holds the table of the
+// built-in device types and the custom ones registered by its own args, so the profiles never share the custom
+// device types.
+type deviceResourceNames map[schedulingv1alpha1.DeviceType][]corev1.ResourceName
+
+// newDeviceResourceNames returns the table of the built-in device types and the custom device types, which are
+// requested and allocated by the whole devices like RDMA and FPGA. The resource names and the device types must not
+// collide with the built-in ones or with each other.
+func newDeviceResourceNames(customDeviceTypes []config.CustomDeviceType) (deviceResourceNames, error) {
+	builtinResourceNames := sets.NewString()
+	for deviceType := range builtinDeviceTypes {
+		for _, resourceName := range DeviceResourceNames[schedulingv1alpha1.DeviceType(deviceType)] {
+			builtinResourceNames.Insert(string(resourceName))
+		}
+	}
+	seenDeviceTypes, seenResourceNames := sets.NewString(), sets.NewString()
+	for _, custom := range customDeviceTypes {
+		if custom.ResourceName == "" || custom.DeviceType == "" {
+			return nil, fmt.Errorf("custom device type should specify both the resource name and the device type, got %+v", custom)
+		}
+		if builtinDeviceTypes.Has(string(custom.DeviceType)) {
+			return nil, fmt.Errorf("custom device type %v collides with the built-in device type", custom.DeviceType)
+		}
+		if builtinResourceNames.Has(string(custom.ResourceName)) || apiext.IsNvidiaMIGResource(custom.ResourceName) ||
+			custom.ResourceName == apiext.GPUSharedSlots {
+			return nil, fmt.Errorf("resource %v of custom device type %v collides with the built-in device resources", custom.ResourceName, custom.DeviceType)
+		}
+		if seenDeviceTypes.Has(string(custom.DeviceType)) {
+			return nil, fmt.Errorf("custom device type %v is registered more than once", custom.DeviceType)
+		}
+		if seenResourceNames.Has(string(custom.ResourceName)) {
+			return nil, fmt.Errorf("resource %v is registered by more than one custom device type", custom.ResourceName)
+		}
+		seenDeviceTypes.Insert(string(custom.DeviceType))
+		seenResourceNames.Insert(string(custom.ResourceName))
+	}
+	resourceNames := make(deviceResourceNames, len(DeviceResourceNames)+len(customDeviceTypes))
+	for deviceType, names := range DeviceResourceNames {
+		resourceNames[deviceType] = names
+	}
+	for _, custom := range customDeviceTypes {
+		resourceNames[custom.DeviceType] = []corev1.ResourceName{custom.ResourceName}
+	}
+	return resourceNames, nil
+}
+
+// getCommonDeviceResourceName returns the resource of the device type allocated by the whole devices, i.e. RDMA,
+// FPGA and the custom device types, and false for GPU or the unknown device types.
+func (d deviceResourceNames) getCommonDeviceResourceName(deviceType schedulingv1alpha1.DeviceType) (corev1.ResourceName, bool) {
+	resourceNames := d[deviceType]
+	if deviceType == schedulingv1alpha1.GPU || len(resourceNames) != 1 {
+		return "", false
+	}
+	return resourceNames[0], true
+}
+
+// getCommonDeviceTypes returns the device types allocated by the whole devices in order.
+func (d deviceResourceNames) getCommonDeviceTypes() []schedulingv1alpha1.DeviceType {
+	var deviceTypes []schedulingv1alpha1.DeviceType
+	for deviceType := range d {
+		if _, ok := d.getCommonDeviceResourceName(deviceType); ok {
+			deviceTypes = append(deviceTypes, deviceType)
+		}
+	}
+	sort.Slice(deviceTypes, func(i, j int) bool {
+		return deviceTypes[i] < deviceTypes[j]
+	})
+	return deviceTypes
+}
+
+func (d deviceResourceNames) hasDeviceResource(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) bool {
 	if podRequest == nil || len(podRequest) == 0 {
 		klog.Warningf("skip checking hasDeviceResource, because pod request is empty")
 		return false
 	}
-	for _, resourceName := range DeviceResourceNames[deviceType] {
+	for _, resourceName := range d[deviceType] {
 		if _, ok := podRequest[resourceName]; ok {
 			return true
 		}
 	}
+	if deviceType == schedulingv1alpha1.GPU && len(getMIGRequest(podRequest)) > 0 {
+		return true
+	}
+	if _, ok := podRequest[apiext.GPUSharedSlots]; ok && deviceType == schedulingv1alpha1.GPU {
+		return true
+	}
 	klog.V(5).Infof("pod does not request %v resource", deviceType)
 	return false
 }
 
-func validateCommonDeviceRequest(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) error {
+// convertDeviceRequest validates and converts the device resources requested by the pod to the resources allocated
+// from the devices, e.g. kubernetes.io/gpu to the gpu-core and gpu-memory-ratio. The converted GPU request is also
+// returned alone, nil if the pod requests no GPU.
+func (d deviceResourceNames) convertDeviceRequest(podRequest corev1.ResourceList) (deviceRequest, gpuRequest corev1.ResourceList, err error) {
+	deviceRequest = corev1.ResourceList{}
+	if d.hasDeviceResource(podRequest, schedulingv1alpha1.GPU) {
+		combination, err := ValidateGPURequest(podRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		gpuRequest = ConvertGPUResource(podRequest, combination)
+		deviceRequest = quotav1.Add(deviceRequest, gpuRequest)
+	}
+	// RDMA, FPGA and the custom device types are requested by the whole devices
+	for _, deviceType := range d.getCommonDeviceTypes() {
+		if !d.hasDeviceResource(podRequest, deviceType) {
+			continue
+		}
+		if err := d.validateCommonDeviceRequest(podRequest, deviceType); err != nil {
+			return nil, nil, err
+		}
+		deviceRequest = quotav1.Add(deviceRequest, d.convertCommonDeviceResource(podRequest, deviceType))
+	}
+	return deviceRequest, gpuRequest, nil
+}
+
+func (d deviceResourceNames) validateCommonDeviceRequest(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) error {
 	if podRequest == nil || len(podRequest) == 0 {
 		return fmt.Errorf("pod request should not be empty")
 	}
-	var commonDevice resource.Quantity
-	switch deviceType {
-	case schedulingv1alpha1.FPGA:
-		commonDevice = podRequest[apiext.KoordFPGA]
-	case schedulingv1alpha1.RDMA:
-		commonDevice = podRequest[apiext.KoordRDMA]
-	default:
+	resourceName, ok := d.getCommonDeviceResourceName(deviceType)
+	if !ok {
 		return fmt.Errorf("device type %v is not supported yet", deviceType)
 	}
+	commonDevice := podRequest[resourceName]
 	if commonDevice.Value() > 100 && commonDevice.Value()%100 != 0 {
-		return fmt.Errorf("failed to validate %v%v: %v", apiext.ResourceDomainPrefix, deviceType, commonDevice.Value())
+		return fmt.Errorf("failed to validate %v: %v", resourceName, commonDevice.Value())
 	}
 	return nil
 }
 
+// getMIGRequest returns the requested MIG instances in the pod request.
+func getMIGRequest(podRequest corev1.ResourceList) corev1.ResourceList {
+	var migRequest corev1.ResourceList
+	for resourceName, quantity := range podRequest {
+		if apiext.IsNvidiaMIGResource(resourceName) {
+			if migRequest == nil {
+				migRequest = corev1.ResourceList{}
+			}
+			migRequest[resourceName] = quantity
+		}
+	}
+	return migRequest
+}
+
 // ValidateGPURequest uses binary to store each request status.
 // For example, 00010 stands for koordinator.sh/gpu exists, and vice versa.
-// only 00001 || 00010 || 10100 || 01100 are valid GPU request combination
+// only 00001 || 00010 || 10100 || 01100 || 01000 || 100000 || 1000000 are valid GPU request combination,
+// and the MIG instances of a single profile or the time-slicing slots can be requested only without the other GPU
+// resources.
 var ValidateGPURequest = func(podRequest corev1.ResourceList) (uint, error) {
 	var gpuCombination uint
 
@@ -109,40 +233,84 @@ var ValidateGPURequest = func(podRequest corev1.ResourceList) (uint, error) {
 		}
 		gpuCombination |= GPUMemoryRatioExist
 	}
+	if migRequest := getMIGRequest(podRequest); len(migRequest) > 0 {
+		if gpuCombination != 0 {
+			return gpuCombination, fmt.Errorf("request is not valid, full GPUs and MIG instances cannot be requested together")
+		}
+		if len(migRequest) > 1 {
+			return gpuCombination, fmt.Errorf("request is not valid, MIG instances of multiple profiles cannot be requested together")
+		}
+		for resourceName, quantity := range migRequest {
+			if quantity.Value() <= 0 {
+				return gpuCombination, fmt.Errorf("failed to validate %v: %v", resourceName, quantity.Value())
+			}
+		}
+		gpuCombination |= NvidiaMIGExist
+	}
+	if sharedSlots, exist := podRequest[apiext.GPUSharedSlots]; exist {
+		if gpuCombination != 0 {
+			return gpuCombination, fmt.Errorf("request is not valid, %v cannot be requested with the other GPU resources", apiext.GPUSharedSlots)
+		}
+		if sharedSlots.Value() <= 0 {
+			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.GPUSharedSlots, sharedSlots.Value())
+		}
+		gpuCombination |= GPUSharedSlotsExist
+	}
+	if gpuCombination == (GPUCoreExist | GPUMemoryRatioExist) {
+		if err := validateGPUCoreAndMemoryRatio(podRequest[apiext.GPUCore], podRequest[apiext.GPUMemoryRatio]); err != nil {
+			return gpuCombination, err
+		}
+	}
 
 	if gpuCombination == (NvidiaGPUExist) ||
 		gpuCombination == (KoordGPUExist) ||
 		gpuCombination == (GPUCoreExist|GPUMemoryExist) ||
-		gpuCombination == (GPUCoreExist|GPUMemoryRatioExist) {
+		gpuCombination == (GPUCoreExist|GPUMemoryRatioExist) ||
+		gpuCombination == (GPUMemoryExist) ||
+		gpuCombination == (NvidiaMIGExist) ||
+		gpuCombination == (GPUSharedSlotsExist) {
 		return gpuCombination, nil
 	}
 
 	return gpuCombination, fmt.Errorf("request is not valid, current combination: %b", gpuCombination)
 }
 
-func convertCommonDeviceResource(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) corev1.ResourceList {
+// validateGPUCoreAndMemoryRatio checks the gpu-core and gpu-memory-ratio requested separately, e.g. the most of
+// the cores with a little of the memory. The memory of the multiple GPUs requested by the cores is split evenly.
+func validateGPUCoreAndMemoryRatio(gpuCore, gpuMemRatio resource.Quantity) error {
+	if gpuCore.Value() <= 0 {
+		return fmt.Errorf("failed to validate %v: %v, should be positive", apiext.GPUCore, gpuCore.Value())
+	}
+	if gpuMemRatio.Value() <= 0 {
+		return fmt.Errorf("failed to validate %v: %v, should be positive", apiext.GPUMemoryRatio, gpuMemRatio.Value())
+	}
+	gpuWanted := int64(1)
+	if gpuCore.Value() > 100 {
+		gpuWanted = gpuCore.Value() / 100
+	}
+	if gpuMemRatio.Value() > gpuWanted*100 || gpuMemRatio.Value()%gpuWanted != 0 {
+		return fmt.Errorf("failed to validate %v: %v, should be split evenly on the %d GPU(s) requested by %v",
+			apiext.GPUMemoryRatio, gpuMemRatio.Value(), gpuWanted, apiext.GPUCore)
+	}
+	return nil
+}
+
+func (d deviceResourceNames) convertCommonDeviceResource(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) corev1.ResourceList {
 	if podRequest == nil || len(podRequest) == 0 {
 		klog.Warningf("pod request should not be empty")
 		return nil
 	}
-	var resources corev1.ResourceList
-	switch deviceType {
-	case schedulingv1alpha1.RDMA:
-		if value, ok := podRequest[apiext.KoordRDMA]; ok {
-			resources = corev1.ResourceList{
-				apiext.KoordRDMA: value,
-			}
-		}
-	case schedulingv1alpha1.FPGA:
-		if value, ok := podRequest[apiext.KoordFPGA]; ok {
-			resources = corev1.ResourceList{
-				apiext.KoordFPGA: value,
-			}
-		}
-	default:
+	resourceName, ok := d.getCommonDeviceResourceName(deviceType)
+	if !ok {
 		klog.Warningf("device type %v is not supported yet", deviceType)
 		return nil
 	}
+	var resources corev1.ResourceList
+	if value, ok := podRequest[resourceName]; ok {
+		resources = corev1.ResourceList{
+			resourceName: value,
+		}
+	}
 	return resources
 }
 
@@ -155,6 +323,11 @@ var ConvertGPUResource = func(podRequest corev1.ResourceList, combination uint)
 		return nil
 	}
 	switch combination {
+	case GPUMemoryExist:
+		// the gpu-core share is derived from the memory of the allocated GPU
+		return corev1.ResourceList{
+			apiext.GPUMemory: podRequest[apiext.GPUMemory],
+		}
 	case GPUCoreExist | GPUMemoryExist:
 		return corev1.ResourceList{
 			apiext.GPUCore:   podRequest[apiext.GPUCore],
@@ -176,25 +349,137 @@ var ConvertGPUResource = func(podRequest corev1.ResourceList, combination uint)
 			apiext.GPUCore:        *resource.NewQuantity(nvidiaGpu.Value()*100, resource.DecimalSI),
 			apiext.GPUMemoryRatio: *resource.NewQuantity(nvidiaGpu.Value()*100, resource.DecimalSI),
 		}
+	case NvidiaMIGExist:
+		return getMIGRequest(podRequest)
+	case GPUSharedSlotsExist:
+		return corev1.ResourceList{
+			apiext.GPUSharedSlots: podRequest[apiext.GPUSharedSlots],
+		}
+	}
+	return nil
+}
+
+// getWholeDeviceRequest returns the request of the device type and the count of the devices if the device type is
+// requested by multiple whole devices, otherwise the count is zero, e.g. for the shared GPU or the MIG instances.
+func (d deviceResourceNames) getWholeDeviceRequest(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) (corev1.ResourceList, int64) {
+	var request resource.Quantity
+	if deviceType == schedulingv1alpha1.GPU {
+		if len(getMIGRequest(podRequest)) > 0 {
+			return nil, 0
+		}
+		if _, ok := podRequest[apiext.GPUSharedSlots]; ok {
+			return nil, 0
+		}
+		request = podRequest[apiext.GPUCore]
+	} else if resourceName, ok := d.getCommonDeviceResourceName(deviceType); ok {
+		request = podRequest[resourceName]
+	}
+	if request.Value() < 100 || request.Value()%100 != 0 {
+		return nil, 0
+	}
+	return quotav1.Mask(podRequest, d[deviceType]), request.Value() / 100
+}
+
+// getDeviceCount returns the number of devices the converted device request occupies, and a shared device is
+// counted as one.
+func (d deviceResourceNames) getDeviceCount(deviceType schedulingv1alpha1.DeviceType, podRequest corev1.ResourceList) int64 {
+	var request resource.Quantity
+	if deviceType == schedulingv1alpha1.GPU {
+		request = podRequest[apiext.GPUCore]
+	} else if resourceName, ok := d.getCommonDeviceResourceName(deviceType); ok {
+		request = podRequest[resourceName]
+	}
+	count := (request.Value() + 99) / 100
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// validateDeviceOrderingHint checks that the hint of each device type is a permutation of the relative indices of
+// the devices the pod requests.
+func (d deviceResourceNames) validateDeviceOrderingHint(hint apiext.DeviceOrderingHint, podRequest corev1.ResourceList) error {
+	for deviceType, order := range hint {
+		if !d.hasDeviceResource(podRequest, deviceType) {
+			return fmt.Errorf("device ordering hint specified for %v, but pod does not request it", deviceType)
+		}
+		count := d.getDeviceCount(deviceType, podRequest)
+		if int64(len(order)) != count {
+			return fmt.Errorf("device ordering hint of %v expects %d indices, got %d", deviceType, count, len(order))
+		}
+		seen := make([]bool, count)
+		for _, index := range order {
+			if index < 0 || int64(index) >= count || seen[index] {
+				return fmt.Errorf("device ordering hint of %v should be a permutation of 0 to %d, got %v", deviceType, count-1, order)
+			}
+			seen[index] = true
+		}
+	}
+	return nil
+}
+
+// validateDeviceJointAllocate checks that the joint allocation names at least two distinct device types reporting the
+// PCIe switches, i.e. GPU and RDMA, all of which the pod requests.
+func (d deviceResourceNames) validateDeviceJointAllocate(jointAllocate *apiext.DeviceJointAllocate, podRequest corev1.ResourceList) error {
+	if jointAllocate == nil {
+		return nil
+	}
+	seen := map[schedulingv1alpha1.DeviceType]bool{}
+	for _, deviceType := range jointAllocate.DeviceTypes {
+		if deviceType != schedulingv1alpha1.GPU && deviceType != schedulingv1alpha1.RDMA {
+			return fmt.Errorf("device joint allocate does not support %v", deviceType)
+		}
+		if seen[deviceType] {
+			return fmt.Errorf("device joint allocate specified %v more than once", deviceType)
+		}
+		seen[deviceType] = true
+		if !d.hasDeviceResource(podRequest, deviceType) {
+			return fmt.Errorf("device joint allocate specified for %v, but pod does not request it", deviceType)
+		}
+	}
+	if len(seen) < 2 {
+		return fmt.Errorf("device joint allocate expects at least 2 device types, got %v", jointAllocate.DeviceTypes)
 	}
 	return nil
 }
 
-func isMultipleCommonDevicePod(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) bool {
+// applyDeviceOrderingHint reorders the allocated devices so that the i-th device is the device with the relative
+// index hint[i] among the allocated devices sorted by minor.
+func applyDeviceOrderingHint(allocations apiext.DeviceAllocations, hint apiext.DeviceOrderingHint) error {
+	for deviceType, order := range hint {
+		devices := allocations[deviceType]
+		if len(devices) != len(order) {
+			return fmt.Errorf("device ordering hint of %v expects %d devices, got %d", deviceType, len(order), len(devices))
+		}
+		sortedDevices := make([]*apiext.DeviceAllocation, len(devices))
+		copy(sortedDevices, devices)
+		sort.Slice(sortedDevices, func(i, j int) bool {
+			return sortedDevices[i].Minor < sortedDevices[j].Minor
+		})
+		orderedDevices := make([]*apiext.DeviceAllocation, 0, len(order))
+		for _, index := range order {
+			orderedDevices = append(orderedDevices, sortedDevices[index])
+		}
+		allocations[deviceType] = orderedDevices
+	}
+	return nil
+}
+
+func scaleQuantity(q resource.Quantity, n int64) resource.Quantity {
+	return *resource.NewMilliQuantity(q.MilliValue()*n, q.Format)
+}
+
+func (d deviceResourceNames) isMultipleCommonDevicePod(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) bool {
 	if podRequest == nil || len(podRequest) == 0 {
 		klog.Warningf("pod request should not be empty")
 		return false
 	}
-	switch deviceType {
-	case schedulingv1alpha1.RDMA:
-		rdma := podRequest[apiext.KoordRDMA]
-		return rdma.Value() > 100 && rdma.Value()%100 == 0
-	case schedulingv1alpha1.FPGA:
-		fpga := podRequest[apiext.KoordFPGA]
-		return fpga.Value() > 100 && fpga.Value()%100 == 0
-	default:
+	resourceName, ok := d.getCommonDeviceResourceName(deviceType)
+	if !ok {
 		return false
 	}
+	commonDevice := podRequest[resourceName]
+	return commonDevice.Value() > 100 && commonDevice.Value()%100 == 0
 }
 
 func isMultipleGPUPod(podRequest corev1.ResourceList) bool {
@@ -240,10 +525,13 @@ func patchContainerGPUResource(pod *corev1.Pod, podRequest corev1.ResourceList)
 func fillGPUTotalMem(nodeDeviceTotal deviceResources, podRequest corev1.ResourceList) {
 	// nodeDeviceTotal uses the minor of GPU as key. However, under certain circumstances,
 	// minor 0 might not exist. We need to iterate the cache once to find the active minor.
+	// the GPUs carved into the MIG instances do not report the memory, and are skipped.
 	var activeMinor int
 	for i := range nodeDeviceTotal {
 		activeMinor = i
-		break
+		if _, ok := nodeDeviceTotal[i][apiext.GPUMemory]; ok {
+			break
+		}
 	}
 
 	// a node can only contain one type of GPU, so each of them has the same total memory.
@@ -254,3 +542,50 @@ func fillGPUTotalMem(nodeDeviceTotal deviceResources, podRequest corev1.Resource
 		podRequest[apiext.GPUMemory] = memRatioToBytes(gpuMemRatio, nodeDeviceTotal[activeMinor][apiext.GPUMemory])
 	}
 }
+
+// isGPUMemoryOnlyRequest checks if the converted GPU request specifies only the gpu-memory, so the gpu-core share
+// depends on the GPU it is allocated to.
+func isGPUMemoryOnlyRequest(podRequest corev1.ResourceList) bool {
+	_, hasGPUCore := podRequest[apiext.GPUCore]
+	_, hasGPUMemory := podRequest[apiext.GPUMemory]
+	return hasGPUMemory && !hasGPUCore
+}
+
+// deriveGPUMemoryOnlyRequest returns the request of a gpu-memory-only pod on the GPU of the given total resources.
+// The gpu-core share is the requested fraction of the GPU memory in percentage rounded up to a multiple of the
+// granularity, so the same request derives different shares on the GPUs of different memory. It returns false if
+// the GPU does not report its memory.
+func deriveGPUMemoryOnlyRequest(podRequest, total corev1.ResourceList, granularity int64) (corev1.ResourceList, bool) {
+	gpuMem := podRequest[apiext.GPUMemory]
+	totalMem := total[apiext.GPUMemory]
+	if totalMem.Value() <= 0 {
+		return nil, false
+	}
+	if granularity <= 0 {
+		granularity = 1
+	}
+	percentage := (gpuMem.Value()*100 + totalMem.Value() - 1) / totalMem.Value()
+	gpuCore := (percentage + granularity - 1) / granularity * granularity
+	if gpuCore > 100 {
+		gpuCore = 100
+	}
+	return corev1.ResourceList{
+		apiext.GPUCore:        *resource.NewQuantity(gpuCore, resource.DecimalSI),
+		apiext.GPUMemory:      gpuMem,
+		apiext.GPUMemoryRatio: memBytesToRatio(gpuMem, totalMem),
+	}, true
+}
+
+// getWholeGPUCount returns the number of whole GPUs requested, 0 if the pod requests a part of a GPU.
+func getWholeGPUCount(podRequest corev1.ResourceList) int {
+	gpuCore := podRequest[apiext.GPUCore]
+	if gpuCore.Value() <= 0 || gpuCore.Value()%100 != 0 {
+		return 0
+	}
+	return int(gpuCore.Value() / 100)
+}
+
+// isFractionalGPURequest checks if the pod requests a part of a GPU, e.g. 0.5 GPU or only GPU memory.
+func isFractionalGPURequest(podRequest corev1.ResourceList) bool {
+	return DeviceResourceNames.hasDeviceResource(podRequest, schedulingv1alpha1.GPU) && getWholeGPUCount(podRequest) == 0
+}
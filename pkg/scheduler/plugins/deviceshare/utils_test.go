@@ -23,9 +23,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 )
 
 func Test_hasDeviceResource(t *testing.T) {
@@ -84,7 +86,7 @@ func Test_hasDeviceResource(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := hasDeviceResource(tt.args.podRequest, tt.args.deviceType)
+			got := DeviceResourceNames.hasDeviceResource(tt.args.podRequest, tt.args.deviceType)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -161,7 +163,7 @@ func Test_validateCommonDeviceRequest(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateCommonDeviceRequest(tt.args.podRequest, tt.args.deviceType)
+			err := DeviceResourceNames.validateCommonDeviceRequest(tt.args.podRequest, tt.args.deviceType)
 			assert.Equal(t, tt.wantErr, err != nil)
 		})
 	}
@@ -217,6 +219,33 @@ func Test_validateGPURequest(t *testing.T) {
 			want:    0,
 			wantErr: true,
 		},
+		{
+			name: "invalid gpu request with zero gpu-core",
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("0"),
+				apiext.GPUMemoryRatio: resource.MustParse("30"),
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "invalid gpu request with the memory of more GPUs than the cores",
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("50"),
+				apiext.GPUMemoryRatio: resource.MustParse("200"),
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "invalid gpu request with the memory not split evenly",
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("200"),
+				apiext.GPUMemoryRatio: resource.MustParse("75"),
+			},
+			want:    0,
+			wantErr: true,
+		},
 		{
 			name: "valid gpu request 1",
 			podRequest: corev1.ResourceList{
@@ -251,6 +280,83 @@ func Test_validateGPURequest(t *testing.T) {
 			want:    GPUCoreExist | GPUMemoryRatioExist,
 			wantErr: false,
 		},
+		{
+			name: "valid gpu request with the most of the cores but a little of the memory",
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("90"),
+				apiext.GPUMemoryRatio: resource.MustParse("30"),
+			},
+			want:    GPUCoreExist | GPUMemoryRatioExist,
+			wantErr: false,
+		},
+		{
+			name: "valid gpu request with the memory split on the GPUs",
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("200"),
+				apiext.GPUMemoryRatio: resource.MustParse("60"),
+			},
+			want:    GPUCoreExist | GPUMemoryRatioExist,
+			wantErr: false,
+		},
+		{
+			name: "valid gpu request 5",
+			podRequest: corev1.ResourceList{
+				apiext.GPUMemory: resource.MustParse("12Gi"),
+			},
+			want:    GPUMemoryExist,
+			wantErr: false,
+		},
+		{
+			name: "valid mig request",
+			podRequest: corev1.ResourceList{
+				apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("2"),
+			},
+			want:    NvidiaMIGExist,
+			wantErr: false,
+		},
+		{
+			name: "invalid mig request with the full GPU",
+			podRequest: corev1.ResourceList{
+				apiext.NvidiaGPU:                       resource.MustParse("1"),
+				apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("1"),
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "invalid mig request of multiple profiles",
+			podRequest: corev1.ResourceList{
+				apiext.NvidiaMIGResourceName("1g.5gb"):  resource.MustParse("1"),
+				apiext.NvidiaMIGResourceName("2g.10gb"): resource.MustParse("1"),
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "valid gpu shared slots request",
+			podRequest: corev1.ResourceList{
+				apiext.GPUSharedSlots: resource.MustParse("1"),
+			},
+			want:    GPUSharedSlotsExist,
+			wantErr: false,
+		},
+		{
+			name: "invalid gpu shared slots request with the gpu-core",
+			podRequest: corev1.ResourceList{
+				apiext.GPUSharedSlots: resource.MustParse("1"),
+				apiext.GPUCore:        resource.MustParse("50"),
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "invalid gpu shared slots request of zero",
+			podRequest: corev1.ResourceList{
+				apiext.GPUSharedSlots: resource.MustParse("0"),
+			},
+			want:    0,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -318,12 +424,64 @@ func Test_convertCommonDeviceResource(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := convertCommonDeviceResource(tt.args.podRequest, tt.args.deviceType)
+			got := DeviceResourceNames.convertCommonDeviceResource(tt.args.podRequest, tt.args.deviceType)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
+func Test_convertDeviceRequest(t *testing.T) {
+	tests := []struct {
+		name              string
+		podRequest        corev1.ResourceList
+		wantDeviceRequest corev1.ResourceList
+		wantGPURequest    corev1.ResourceList
+		wantErr           bool
+	}{
+		{
+			name:              "no device request",
+			podRequest:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			wantDeviceRequest: corev1.ResourceList{},
+		},
+		{
+			name: "gpu and rdma",
+			podRequest: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+				apiext.KoordGPU:    resource.MustParse("100"),
+				apiext.KoordRDMA:   resource.MustParse("100"),
+			},
+			wantDeviceRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.KoordRDMA:      resource.MustParse("100"),
+			},
+			wantGPURequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+			},
+		},
+		{
+			name: "invalid fpga",
+			podRequest: corev1.ResourceList{
+				apiext.KoordFPGA: resource.MustParse("150"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceRequest, gpuRequest, err := DeviceResourceNames.convertDeviceRequest(tt.podRequest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, quotav1.Equals(tt.wantDeviceRequest, deviceRequest))
+			assert.True(t, quotav1.Equals(tt.wantGPURequest, gpuRequest))
+		})
+	}
+}
+
 func Test_convertGPUResource(t *testing.T) {
 	type args struct {
 		podRequest     corev1.ResourceList
@@ -406,6 +564,44 @@ func Test_convertGPUResource(t *testing.T) {
 				apiext.GPUMemory: resource.MustParse("32Gi"),
 			},
 		},
+		{
+			name: "gpuMemoryExist",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.GPUMemory: resource.MustParse("12Gi"),
+				},
+				gpuCombination: GPUMemoryExist,
+			},
+			want: corev1.ResourceList{
+				apiext.GPUMemory: resource.MustParse("12Gi"),
+			},
+		},
+		{
+			name: "nvidiaMIGExist",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("2"),
+					corev1.ResourceCPU:                     resource.MustParse("4"),
+				},
+				gpuCombination: NvidiaMIGExist,
+			},
+			want: corev1.ResourceList{
+				apiext.NvidiaMIGResourceName("1g.5gb"): resource.MustParse("2"),
+			},
+		},
+		{
+			name: "gpuSharedSlotsExist",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.GPUSharedSlots: resource.MustParse("1"),
+					corev1.ResourceCPU:    resource.MustParse("4"),
+				},
+				gpuCombination: GPUSharedSlotsExist,
+			},
+			want: corev1.ResourceList{
+				apiext.GPUSharedSlots: resource.MustParse("1"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -486,7 +682,7 @@ func Test_isMultipleCommonDevicePod(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isMultipleCommonDevicePod(tt.args.podRequest, tt.args.deviceType)
+			got := DeviceResourceNames.isMultipleCommonDevicePod(tt.args.podRequest, tt.args.deviceType)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -730,3 +926,266 @@ func Test_fillGPUTotalMem(t *testing.T) {
 		})
 	}
 }
+
+func Test_deriveGPUMemoryOnlyRequest(t *testing.T) {
+	podRequest := corev1.ResourceList{
+		apiext.GPUMemory: resource.MustParse("12Gi"),
+	}
+	tests := []struct {
+		name        string
+		totalMemory string
+		granularity int64
+		want        corev1.ResourceList
+		wantOK      bool
+	}{
+		{
+			name:        "24Gi GPU",
+			totalMemory: "24Gi",
+			granularity: 5,
+			want: corev1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse("12Gi"),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+			},
+			wantOK: true,
+		},
+		{
+			name:        "80Gi GPU",
+			totalMemory: "80Gi",
+			granularity: 5,
+			want: corev1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(15, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse("12Gi"),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(15, resource.DecimalSI),
+			},
+			wantOK: true,
+		},
+		{
+			name:        "80Gi GPU rounded up to the granularity",
+			totalMemory: "80Gi",
+			granularity: 10,
+			want: corev1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(20, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse("12Gi"),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(15, resource.DecimalSI),
+			},
+			wantOK: true,
+		},
+		{
+			name:        "GPU without memory",
+			totalMemory: "0",
+			granularity: 5,
+			wantOK:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := corev1.ResourceList{
+				apiext.GPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.GPUMemory:      resource.MustParse(tt.totalMemory),
+				apiext.GPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			}
+			got, ok := deriveGPUMemoryOnlyRequest(podRequest, total, tt.granularity)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, quotav1.Equals(tt.want, got), "want %v, got %v", tt.want, got)
+		})
+	}
+}
+
+func Test_validateDeviceOrderingHint(t *testing.T) {
+	multipleGPURequest := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("300"),
+		apiext.GPUMemoryRatio: resource.MustParse("300"),
+	}
+	tests := []struct {
+		name       string
+		hint       apiext.DeviceOrderingHint
+		podRequest corev1.ResourceList
+		wantErr    bool
+	}{
+		{
+			name:       "no hint",
+			podRequest: multipleGPURequest,
+		},
+		{
+			name:       "valid hint",
+			hint:       apiext.DeviceOrderingHint{schedulingv1alpha1.GPU: {2, 0, 1}},
+			podRequest: multipleGPURequest,
+		},
+		{
+			name: "valid hint for shared gpu",
+			hint: apiext.DeviceOrderingHint{schedulingv1alpha1.GPU: {0}},
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("50"),
+				apiext.GPUMemoryRatio: resource.MustParse("50"),
+			},
+		},
+		{
+			name:       "arity mismatch",
+			hint:       apiext.DeviceOrderingHint{schedulingv1alpha1.GPU: {1, 0}},
+			podRequest: multipleGPURequest,
+			wantErr:    true,
+		},
+		{
+			name:       "duplicated index",
+			hint:       apiext.DeviceOrderingHint{schedulingv1alpha1.GPU: {0, 0, 1}},
+			podRequest: multipleGPURequest,
+			wantErr:    true,
+		},
+		{
+			name:       "index out of range",
+			hint:       apiext.DeviceOrderingHint{schedulingv1alpha1.GPU: {0, 1, 3}},
+			podRequest: multipleGPURequest,
+			wantErr:    true,
+		},
+		{
+			name:       "device not requested",
+			hint:       apiext.DeviceOrderingHint{schedulingv1alpha1.RDMA: {0}},
+			podRequest: multipleGPURequest,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DeviceResourceNames.validateDeviceOrderingHint(tt.hint, tt.podRequest)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_validateDeviceJointAllocate(t *testing.T) {
+	podRequest := corev1.ResourceList{
+		apiext.GPUCore:        resource.MustParse("100"),
+		apiext.GPUMemoryRatio: resource.MustParse("100"),
+		apiext.KoordRDMA:      resource.MustParse("100"),
+	}
+	tests := []struct {
+		name          string
+		jointAllocate *apiext.DeviceJointAllocate
+		podRequest    corev1.ResourceList
+		wantErr       bool
+	}{
+		{
+			name:       "no joint allocate",
+			podRequest: podRequest,
+		},
+		{
+			name: "valid joint allocate",
+			jointAllocate: &apiext.DeviceJointAllocate{
+				DeviceTypes: []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU, schedulingv1alpha1.RDMA},
+			},
+			podRequest: podRequest,
+		},
+		{
+			name: "single device type",
+			jointAllocate: &apiext.DeviceJointAllocate{
+				DeviceTypes: []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU},
+			},
+			podRequest: podRequest,
+			wantErr:    true,
+		},
+		{
+			name: "duplicated device type",
+			jointAllocate: &apiext.DeviceJointAllocate{
+				DeviceTypes: []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU, schedulingv1alpha1.GPU},
+			},
+			podRequest: podRequest,
+			wantErr:    true,
+		},
+		{
+			name: "unsupported device type",
+			jointAllocate: &apiext.DeviceJointAllocate{
+				DeviceTypes: []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU, schedulingv1alpha1.FPGA},
+			},
+			podRequest: podRequest,
+			wantErr:    true,
+		},
+		{
+			name: "device not requested",
+			jointAllocate: &apiext.DeviceJointAllocate{
+				DeviceTypes: []schedulingv1alpha1.DeviceType{schedulingv1alpha1.GPU, schedulingv1alpha1.RDMA},
+			},
+			podRequest: corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DeviceResourceNames.validateDeviceJointAllocate(tt.jointAllocate, tt.podRequest)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_newDeviceResourceNames(t *testing.T) {
+	tests := []struct {
+		name              string
+		customDeviceTypes []config.CustomDeviceType
+		wantErr           bool
+	}{
+		{
+			name: "register custom device type",
+			customDeviceTypes: []config.CustomDeviceType{
+				{ResourceName: "vendor.com/npu", DeviceType: "npu"},
+			},
+		},
+		{
+			name: "collides with built-in device type",
+			customDeviceTypes: []config.CustomDeviceType{
+				{ResourceName: "vendor.com/fpga", DeviceType: schedulingv1alpha1.FPGA},
+			},
+			wantErr: true,
+		},
+		{
+			name: "collides with built-in device resource",
+			customDeviceTypes: []config.CustomDeviceType{
+				{ResourceName: apiext.GPUCore, DeviceType: "npu"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "collides with MIG resource",
+			customDeviceTypes: []config.CustomDeviceType{
+				{ResourceName: "nvidia.com/mig-1g.10gb", DeviceType: "npu"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicated custom resource",
+			customDeviceTypes: []config.CustomDeviceType{
+				{ResourceName: "vendor.com/npu", DeviceType: "npu"},
+				{ResourceName: "vendor.com/npu", DeviceType: "tpu"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing resource name",
+			customDeviceTypes: []config.CustomDeviceType{
+				{DeviceType: "npu"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceNames, err := newDeviceResourceNames(tt.customDeviceTypes)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			// the built-in table is never changed by the custom device types
+			_, registered := DeviceResourceNames["npu"]
+			assert.False(t, registered)
+			assert.Equal(t, []corev1.ResourceName{apiext.KoordFPGA}, DeviceResourceNames[schedulingv1alpha1.FPGA])
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, []corev1.ResourceName{"vendor.com/npu"}, resourceNames["npu"])
+			resourceName, ok := resourceNames.getCommonDeviceResourceName("npu")
+			assert.True(t, ok)
+			assert.Equal(t, corev1.ResourceName("vendor.com/npu"), resourceName)
+			assert.Equal(t, []schedulingv1alpha1.DeviceType{schedulingv1alpha1.FPGA, "npu", schedulingv1alpha1.RDMA}, resourceNames.getCommonDeviceTypes())
+			assert.Equal(t, []schedulingv1alpha1.DeviceType{schedulingv1alpha1.FPGA, schedulingv1alpha1.RDMA}, DeviceResourceNames.getCommonDeviceTypes())
+		})
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+// deviceWaitlist keeps the holds of the pending pods requesting many whole GPUs. A node is held by at most one pod,
+// and the free whole GPUs of a held node, up to the number wanted by the holding pod, are kept away from the other
+// pods, so that the holding pod accumulates the GPUs freed on the node ahead of the newly-arriving smaller pods.
+// The holds expire after the hold duration, and then the pod cannot hold again in the same duration.
+type deviceWaitlist struct {
+	lock         sync.Mutex
+	clock        clock.Clock
+	minGPUs      int
+	holdDuration time.Duration
+	// holds maps the name of the node to the hold on it.
+	holds map[string]*deviceHold
+	// cooldowns records when the pods whose holds expired can hold again.
+	cooldowns map[types.UID]time.Time
+}
+
+type deviceHold struct {
+	podUID     types.UID
+	podKey     string
+	gpuWanted  int
+	expireTime time.Time
+}
+
+func newDeviceWaitlist(args *config.DeviceWaitlistArgs, clock clock.Clock) *deviceWaitlist {
+	if args == nil {
+		return nil
+	}
+	w := &deviceWaitlist{
+		clock:     clock,
+		holds:     map[string]*deviceHold{},
+		cooldowns: map[types.UID]time.Time{},
+	}
+	if args.MinGPUs != nil {
+		w.minGPUs = int(*args.MinGPUs)
+	}
+	if args.HoldDuration != nil {
+		w.holdDuration = args.HoldDuration.Duration
+	}
+	return w
+}
+
+// cleanupLocked expires the holds and the cooldowns.
+func (w *deviceWaitlist) cleanupLocked(now time.Time) {
+	for nodeName, hold := range w.holds {
+		if now.Before(hold.expireTime) {
+			continue
+		}
+		delete(w.holds, nodeName)
+		w.cooldowns[hold.podUID] = now.Add(w.holdDuration)
+		klog.V(4).Infof("the hold of pod %s on the GPUs of node %s expired", hold.podKey, nodeName)
+	}
+	for podUID, cooldownEnd := range w.cooldowns {
+		if !now.Before(cooldownEnd) {
+			delete(w.cooldowns, podUID)
+		}
+	}
+}
+
+// heldGPUs returns the number of the free whole GPUs on the node kept away from the pod.
+func (w *deviceWaitlist) heldGPUs(nodeName string, pod *corev1.Pod) int {
+	if w == nil {
+		return 0
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.cleanupLocked(w.clock.Now())
+
+	hold := w.holds[nodeName]
+	if hold == nil || hold.podUID == pod.UID {
+		return 0
+	}
+	return hold.gpuWanted
+}
+
+// holdingNode returns the node held by the pod, empty if none.
+func (w *deviceWaitlist) holdingNode(pod *corev1.Pod) string {
+	if w == nil {
+		return ""
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.cleanupLocked(w.clock.Now())
+
+	for nodeName, hold := range w.holds {
+		if hold.podUID == pod.UID {
+			return nodeName
+		}
+	}
+	return ""
+}
+
+// hold makes the pod hold the GPUs of the node, it fails if the node is held by another pod
+// or the pod is cooling down.
+func (w *deviceWaitlist) hold(nodeName string, pod *corev1.Pod, gpuWanted int) bool {
+	if w == nil {
+		return false
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	now := w.clock.Now()
+	w.cleanupLocked(now)
+
+	if _, ok := w.cooldowns[pod.UID]; ok {
+		return false
+	}
+	if hold := w.holds[nodeName]; hold != nil {
+		return hold.podUID == pod.UID
+	}
+	w.holds[nodeName] = &deviceHold{
+		podUID:     pod.UID,
+		podKey:     klog.KObj(pod).String(),
+		gpuWanted:  gpuWanted,
+		expireTime: now.Add(w.holdDuration),
+	}
+	klog.V(4).Infof("pod %s holds %d GPUs of node %s until %v", klog.KObj(pod), gpuWanted, nodeName, now.Add(w.holdDuration))
+	return true
+}
+
+// release drops the holds of the pod, e.g. once the pod is reserved.
+func (w *deviceWaitlist) release(pod *corev1.Pod) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for nodeName, hold := range w.holds {
+		if hold.podUID == pod.UID {
+			delete(w.holds, nodeName)
+		}
+	}
+	delete(w.cooldowns, pod.UID)
+}
+
+// countWholeGPUs returns the number of the GPUs on the node and the number of the GPUs not used by any pod.
+func (n *nodeDevice) countWholeGPUs() (total, free int) {
+	total = len(n.deviceTotal[schedulingv1alpha1.GPU])
+	for minor := range n.deviceFree[schedulingv1alpha1.GPU] {
+		if quotav1.IsZero(n.deviceUsed[schedulingv1alpha1.GPU][minor]) {
+			free++
+		}
+	}
+	return total, free
+}
+
+// withoutFreeGPUs returns a view of the node devices in which the given number of the GPUs not used by any pod
+// are not free. The node devices are returned as is if there are no such GPUs.
+func (n *nodeDevice) withoutFreeGPUs(count int) *nodeDevice {
+	if count <= 0 {
+		return n
+	}
+	gpuFree := deviceResources{}
+	held := 0
+	for _, deviceResource := range sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU]) {
+		if held < count && quotav1.IsZero(n.deviceUsed[schedulingv1alpha1.GPU][deviceResource.minor]) {
+			held++
+			continue
+		}
+		gpuFree[deviceResource.minor] = deviceResource.resources
+	}
+	if held == 0 {
+		return n
+	}
+	deviceFree := make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree))
+	for deviceType, resources := range n.deviceFree {
+		deviceFree[deviceType] = resources
+	}
+	deviceFree[schedulingv1alpha1.GPU] = gpuFree
+	view := n.shallowCopy()
+	view.deviceFree = deviceFree
+	return view
+}
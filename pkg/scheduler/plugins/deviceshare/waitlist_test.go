@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+type waitlistTestSuit struct {
+	t         *testing.T
+	plugin    *Plugin
+	clock     *clock.FakeClock
+	nodeInfos map[string]*framework.NodeInfo
+	// cycleStates keeps the cycle states of the reserved pods to unreserve them.
+	cycleStates map[types.UID]*framework.CycleState
+}
+
+func newWaitlistTestSuit(t *testing.T, gpusPerNode int, nodeNames ...string) *waitlistTestSuit {
+	deviceCache := newNodeDeviceCache()
+	nodeInfos := map[string]*framework.NodeInfo{}
+	for _, nodeName := range nodeNames {
+		gpus := deviceResources{}
+		for minor := 0; minor < gpusPerNode; minor++ {
+			gpus[minor] = corev1.ResourceList{
+				apiext.GPUCore:        resource.MustParse("100"),
+				apiext.GPUMemoryRatio: resource.MustParse("100"),
+				apiext.GPUMemory:      resource.MustParse("16Gi"),
+			}
+		}
+		deviceCache.createNodeDevice(nodeName).resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+			schedulingv1alpha1.GPU: gpus,
+		})
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}})
+		nodeInfos[nodeName] = nodeInfo
+	}
+	fakeClock := clock.NewFakeClock(time.Now())
+	waitlist := newDeviceWaitlist(&config.DeviceWaitlistArgs{
+		MinGPUs:      pointer.Int32(4),
+		HoldDuration: &metav1.Duration{Duration: 5 * time.Minute},
+	}, fakeClock)
+	return &waitlistTestSuit{
+		t:           t,
+		plugin:      &Plugin{nodeDeviceCache: deviceCache, allocator: &defaultAllocator{}, waitlist: waitlist},
+		clock:       fakeClock,
+		nodeInfos:   nodeInfos,
+		cycleStates: map[types.UID]*framework.CycleState{},
+	}
+}
+
+func newWaitlistTestPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)}}
+}
+
+func newWaitlistTestCycleState(gpuCore int64) *framework.CycleState {
+	cycleState := framework.NewCycleState()
+	cycleState.Write(stateKey, &preFilterState{
+		convertedDeviceResource: corev1.ResourceList{
+			apiext.GPUCore:        *resource.NewQuantity(gpuCore, resource.DecimalSI),
+			apiext.GPUMemoryRatio: *resource.NewQuantity(gpuCore, resource.DecimalSI),
+		},
+	})
+	return cycleState
+}
+
+// schedule runs a scheduling cycle of the pod requesting gpuCore, and returns the node the pod is reserved on.
+func (s *waitlistTestSuit) schedule(pod *corev1.Pod, gpuCore int64) string {
+	cycleState := newWaitlistTestCycleState(gpuCore)
+	statusMap := framework.NodeToStatusMap{}
+	// try the nodes in order so that the pods are placed deterministically
+	nodeNames := make([]string, 0, len(s.nodeInfos))
+	for nodeName := range s.nodeInfos {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		status := s.plugin.Filter(context.TODO(), cycleState, pod, s.nodeInfos[nodeName])
+		if status.IsSuccess() {
+			assert.True(s.t, s.plugin.Reserve(context.TODO(), cycleState, pod, nodeName).IsSuccess())
+			s.cycleStates[pod.UID] = cycleState
+			return nodeName
+		}
+		statusMap[nodeName] = status
+	}
+	_, status := s.plugin.PostFilter(context.TODO(), cycleState, pod, statusMap)
+	assert.Equal(s.t, framework.Unschedulable, status.Code())
+	return ""
+}
+
+func (s *waitlistTestSuit) release(pod *corev1.Pod, nodeName string) {
+	s.plugin.Unreserve(context.TODO(), s.cycleStates[pod.UID], pod, nodeName)
+	delete(s.cycleStates, pod.UID)
+}
+
+func TestWaitlistLargePodAccumulatesGPUs(t *testing.T) {
+	s := newWaitlistTestSuit(t, 4, "test-node-1")
+	var smallPods []*corev1.Pod
+	for i := 0; i < 4; i++ {
+		pod := newWaitlistTestPod(fmt.Sprintf("small-%d", i))
+		assert.Equal(t, "test-node-1", s.schedule(pod, 100))
+		smallPods = append(smallPods, pod)
+	}
+
+	largePod := newWaitlistTestPod("large")
+	assert.Empty(t, s.schedule(largePod, 400))
+	assert.Equal(t, "test-node-1", s.plugin.waitlist.holdingNode(largePod))
+
+	// the GPUs freed one by one are held for the large pod instead of being taken by the newly-arriving small pods
+	for i, smallPod := range smallPods {
+		s.release(smallPod, "test-node-1")
+		s.clock.Step(time.Minute)
+		newPod := newWaitlistTestPod(fmt.Sprintf("new-small-%d", i))
+		assert.Empty(t, s.schedule(newPod, 100), "new small pod %d", i)
+		if i < len(smallPods)-1 {
+			assert.Empty(t, s.schedule(largePod, 400))
+		}
+	}
+
+	// the large pod gets all the GPUs it accumulated and the hold is released
+	assert.Equal(t, "test-node-1", s.schedule(largePod, 400))
+	assert.Empty(t, s.plugin.waitlist.holdingNode(largePod))
+	summary, ok := s.plugin.getNodeDeviceSummary("test-node-1")
+	assert.True(t, ok)
+	assert.Len(t, summary.AllocateSet[schedulingv1alpha1.GPU], 1)
+	assert.Len(t, summary.AllocateSet[schedulingv1alpha1.GPU]["default/large"], 4)
+}
+
+func TestWaitlistKeepsPartialGPUsAvailable(t *testing.T) {
+	s := newWaitlistTestSuit(t, 4, "test-node-1")
+	sharedPod := newWaitlistTestPod("shared")
+	assert.Equal(t, "test-node-1", s.schedule(sharedPod, 50))
+	// the node has only 4 GPUs, so it cannot be held for the pod wanting 5 GPUs
+	hugePod := newWaitlistTestPod("huge")
+	assert.Empty(t, s.schedule(hugePod, 500))
+	assert.Empty(t, s.plugin.waitlist.holdingNode(hugePod))
+
+	largePod := newWaitlistTestPod("large")
+	assert.Empty(t, s.schedule(largePod, 400))
+	assert.Equal(t, "test-node-1", s.plugin.waitlist.holdingNode(largePod))
+	// the GPU partially used by the shared pod is not held
+	assert.Equal(t, "test-node-1", s.schedule(newWaitlistTestPod("shared-2"), 50))
+	assert.Empty(t, s.schedule(newWaitlistTestPod("shared-3"), 60))
+}
+
+func TestWaitlistHoldsExpire(t *testing.T) {
+	s := newWaitlistTestSuit(t, 4, "test-node-1", "test-node-2")
+	for i := 0; i < 8; i++ {
+		pod := newWaitlistTestPod(fmt.Sprintf("small-%d", i))
+		assert.NotEmpty(t, s.schedule(pod, 100))
+	}
+	// the pods requesting less than the minimum GPUs never hold
+	mediumPod := newWaitlistTestPod("medium")
+	assert.Empty(t, s.schedule(mediumPod, 300))
+	assert.Empty(t, s.plugin.waitlist.holdingNode(mediumPod))
+
+	largePod := newWaitlistTestPod("large")
+	assert.Empty(t, s.schedule(largePod, 400))
+	heldNode := s.plugin.waitlist.holdingNode(largePod)
+	assert.NotEmpty(t, heldNode)
+	// another large pod holds the other node
+	anotherLargePod := newWaitlistTestPod("another-large")
+	assert.Empty(t, s.schedule(anotherLargePod, 400))
+	anotherHeldNode := s.plugin.waitlist.holdingNode(anotherLargePod)
+	assert.NotEmpty(t, anotherHeldNode)
+	assert.NotEqual(t, heldNode, anotherHeldNode)
+
+	s.release(newWaitlistTestPod("small-0"), "test-node-1")
+	s.release(newWaitlistTestPod("small-4"), "test-node-2")
+	newPod := newWaitlistTestPod("new-small")
+	assert.Empty(t, s.schedule(newPod, 100))
+
+	// the holds expire and the small pod gets the freed GPU
+	s.clock.Step(5 * time.Minute)
+	assert.NotEmpty(t, s.schedule(newPod, 100))
+	assert.Empty(t, s.plugin.waitlist.holdingNode(largePod))
+
+	// the large pod cannot hold again until it cooled down
+	assert.Empty(t, s.schedule(largePod, 400))
+	assert.Empty(t, s.plugin.waitlist.holdingNode(largePod))
+	s.clock.Step(5 * time.Minute)
+	assert.Empty(t, s.schedule(largePod, 400))
+	assert.NotEmpty(t, s.plugin.waitlist.holdingNode(largePod))
+}
+
+func TestWaitlistDisabled(t *testing.T) {
+	s := newWaitlistTestSuit(t, 1, "test-node-1")
+	s.plugin.waitlist = nil
+	assert.Equal(t, "test-node-1", s.schedule(newWaitlistTestPod("small"), 100))
+	largePod := newWaitlistTestPod("large")
+	assert.Empty(t, s.schedule(largePod, 400))
+	assert.Empty(t, s.plugin.waitlist.holdingNode(largePod))
+}
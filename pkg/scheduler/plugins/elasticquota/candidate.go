@@ -23,6 +23,8 @@ import (
 type candidate struct {
 	victims *extenderv1.Victims
 	name    string
+	// gangs is the IDs of the gangs whose members are all preempted on the candidate.
+	gangs []string
 }
 
 // Victims returns s.victims.
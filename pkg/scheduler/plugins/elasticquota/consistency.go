@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	schedulerv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+const (
+	consistencyKindElasticQuota = "ElasticQuota"
+	consistencyKindPod          = "Pod"
+)
+
+// CheckConsistency diffs the quotas and the pods of each quota in the GroupQuotaManager against the listers.
+// The quotas are checked before the pods since the quota of a pod is resolved with the quotas in cache.
+func (g *Plugin) CheckConsistency(repair bool) []frameworkext.Discrepancy {
+	var discrepancies []frameworkext.Discrepancy
+	quotaDiscrepancies, err := g.checkQuotaConsistency(repair)
+	if err != nil {
+		klog.Errorf("failed to check the consistency of quotas, err: %v", err)
+		return nil
+	}
+	discrepancies = append(discrepancies, quotaDiscrepancies...)
+	podDiscrepancies, err := g.checkPodConsistency(repair)
+	if err != nil {
+		klog.Errorf("failed to check the consistency of pods, err: %v", err)
+		return discrepancies
+	}
+	return append(discrepancies, podDiscrepancies...)
+}
+
+func (g *Plugin) checkQuotaConsistency(repair bool) ([]frameworkext.Discrepancy, error) {
+	quotas, err := g.quotaLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Name < quotas[j].Name
+	})
+
+	var discrepancies []frameworkext.Discrepancy
+	existing := map[string]struct{}{
+		extension.RootQuotaName:    {},
+		extension.SystemQuotaName:  {},
+		extension.DefaultQuotaName: {},
+	}
+	for _, quota := range quotas {
+		if quota.DeletionTimestamp != nil {
+			continue
+		}
+		existing[quota.Name] = struct{}{}
+		quota = core.RunDecorateElasticQuota(quota)
+		if !g.groupQuotaManager.IsQuotaChanged(quota) {
+			continue
+		}
+		message := "the quota in cache differs from the ElasticQuota"
+		if g.groupQuotaManager.GetQuotaInfoByName(quota.Name) == nil {
+			message = "the quota is missing in cache"
+		}
+		if repair {
+			if err := g.groupQuotaManager.UpdateQuota(quota, false); err != nil {
+				klog.Errorf("failed to repair quota %v, err: %v", quota.Name, err)
+			}
+		}
+		discrepancies = append(discrepancies, frameworkext.Discrepancy{
+			Kind:     consistencyKindElasticQuota,
+			Key:      quota.Name,
+			Message:  message,
+			Repaired: repair,
+		})
+	}
+
+	var staleQuotaNames []string
+	for quotaName := range g.groupQuotaManager.GetAllQuotaNames() {
+		if _, ok := existing[quotaName]; !ok {
+			staleQuotaNames = append(staleQuotaNames, quotaName)
+		}
+	}
+	sort.Strings(staleQuotaNames)
+	for _, quotaName := range staleQuotaNames {
+		if repair {
+			g.OnQuotaDelete(&schedulerv1alpha1.ElasticQuota{ObjectMeta: metav1.ObjectMeta{Name: quotaName}})
+		}
+		discrepancies = append(discrepancies, frameworkext.Discrepancy{
+			Kind:     consistencyKindElasticQuota,
+			Key:      quotaName,
+			Message:  "the quota is still in cache, but the ElasticQuota is deleted",
+			Repaired: repair,
+		})
+	}
+	return discrepancies, nil
+}
+
+func (g *Plugin) checkPodConsistency(repair bool) ([]frameworkext.Discrepancy, error) {
+	pods, err := g.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	expectedQuotas := make(map[string]string, len(pods))
+	expectedPods := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		pod = core.RunDecoratePod(pod)
+		podKey := pod.Namespace + "/" + pod.Name
+		expectedQuotas[podKey] = g.getPodAssociateQuotaName(pod)
+		expectedPods[podKey] = pod
+	}
+	cachedQuotas := map[string]string{}
+	cachedPods := map[string]*corev1.Pod{}
+	for quotaName := range g.groupQuotaManager.GetAllQuotaNames() {
+		quotaInfo := g.groupQuotaManager.GetQuotaInfoByName(quotaName)
+		if quotaInfo == nil {
+			continue
+		}
+		for podKey, pod := range quotaInfo.GetPodCache() {
+			cachedQuotas[podKey] = quotaName
+			cachedPods[podKey] = pod
+		}
+	}
+
+	podKeys := make([]string, 0, len(expectedQuotas))
+	for podKey := range expectedQuotas {
+		podKeys = append(podKeys, podKey)
+	}
+	for podKey := range cachedQuotas {
+		if _, ok := expectedQuotas[podKey]; !ok {
+			podKeys = append(podKeys, podKey)
+		}
+	}
+	sort.Strings(podKeys)
+
+	var discrepancies []frameworkext.Discrepancy
+	for _, podKey := range podKeys {
+		expectedQuota, expected := expectedQuotas[podKey]
+		cachedQuota, cached := cachedQuotas[podKey]
+		var message string
+		switch {
+		case !cached:
+			message = fmt.Sprintf("the pod is missing in quota %s in cache", expectedQuota)
+			if repair {
+				g.groupQuotaManager.OnPodAdd(expectedQuota, expectedPods[podKey])
+			}
+		case !expected:
+			message = fmt.Sprintf("the pod is still in quota %s in cache, but the pod is deleted", cachedQuota)
+			if repair {
+				g.groupQuotaManager.OnPodDelete(cachedQuota, cachedPods[podKey])
+			}
+		case expectedQuota != cachedQuota:
+			message = fmt.Sprintf("the pod is in quota %s in cache, but it belongs to quota %s", cachedQuota, expectedQuota)
+			if repair {
+				g.groupQuotaManager.OnPodUpdate(expectedQuota, cachedQuota, expectedPods[podKey], cachedPods[podKey])
+			}
+		default:
+			continue
+		}
+		discrepancies = append(discrepancies, frameworkext.Discrepancy{
+			Kind:     consistencyKindPod,
+			Key:      podKey,
+			Message:  message,
+			Repaired: repair,
+		})
+	}
+	return discrepancies, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	listercorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/generated/listers/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+func TestPlugin_CheckConsistency(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	plugin := suit.plugin.(*Plugin)
+	gqm := plugin.groupQuotaManager
+
+	quotaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	plugin.quotaLister = v1alpha1.NewElasticQuotaLister(quotaIndexer)
+	plugin.podLister = listercorev1.NewPodLister(podIndexer)
+	assert.NoError(t, quotaIndexer.Add(CreateQuota2("test1", "root", 96, 160, 50, 80, 96, 160, false)))
+	assert.NoError(t, quotaIndexer.Add(CreateQuota2("test2", "root", 96, 160, 50, 80, 96, 160, false)))
+	for _, pod := range []*corev1.Pod{
+		defaultCreatePodWithQuotaName("1", "test1", 10, 10, 10),
+		defaultCreatePodWithQuotaName("2", "test1", 10, 10, 10),
+		defaultCreatePodWithQuotaName("3", "test2", 10, 10, 10),
+	} {
+		assert.NoError(t, podIndexer.Add(pod))
+	}
+
+	// inject the drift: quota test2 and pod 2 are missed, pod 3 is in the wrong quota,
+	// quota stale and pod 4 are deleted but still in cache
+	plugin.addQuota("test1", "root", 96, 160, 50, 80, 96, 160, false, "")
+	plugin.addQuota("stale", "root", 96, 160, 50, 80, 96, 160, false, "")
+	plugin.OnPodAdd(defaultCreatePodWithQuotaName("1", "test1", 10, 10, 10))
+	plugin.OnPodAdd(defaultCreatePodWithQuotaName("3", "test1", 10, 10, 10))
+	plugin.OnPodAdd(defaultCreatePodWithQuotaName("4", "test1", 10, 10, 10))
+
+	discrepancyKeys := func(discrepancies []frameworkext.Discrepancy, repaired bool) []string {
+		var keys []string
+		for _, d := range discrepancies {
+			assert.Equal(t, repaired, d.Repaired)
+			keys = append(keys, d.Kind+":"+d.Key)
+		}
+		return keys
+	}
+	// pod 3 belongs to the default quota until quota test2 is repaired
+	assert.Equal(t, []string{
+		"ElasticQuota:test2", "ElasticQuota:stale", "Pod:/2", "Pod:/3", "Pod:/4",
+	}, discrepancyKeys(plugin.CheckConsistency(false), false))
+	assert.Nil(t, gqm.GetQuotaInfoByName("test2"))
+	assert.NotNil(t, gqm.GetQuotaInfoByName("stale"))
+	assert.Equal(t, 3, len(gqm.GetQuotaInfoByName("test1").GetPodCache()))
+
+	assert.Equal(t, []string{
+		"ElasticQuota:test2", "ElasticQuota:stale", "Pod:/2", "Pod:/3", "Pod:/4",
+	}, discrepancyKeys(plugin.CheckConsistency(true), true))
+	assert.Empty(t, plugin.CheckConsistency(false))
+
+	assert.Nil(t, gqm.GetQuotaInfoByName("stale"))
+	test1Pods := gqm.GetQuotaInfoByName("test1").GetPodCache()
+	assert.Equal(t, 2, len(test1Pods))
+	assert.Contains(t, test1Pods, "/1")
+	assert.Contains(t, test1Pods, "/2")
+	assert.Equal(t, createResourceList(20, 20), gqm.GetQuotaInfoByName("test1").GetRequest())
+	test2Pods := gqm.GetQuotaInfoByName("test2").GetPodCache()
+	assert.Equal(t, 1, len(test2Pods))
+	assert.Contains(t, test2Pods, "/3")
+	assert.Equal(t, createResourceList(10, 10), gqm.GetQuotaInfoByName("test2").GetRequest())
+}
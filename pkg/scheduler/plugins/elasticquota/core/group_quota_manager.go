@@ -24,7 +24,6 @@ import (
 	v1 "k8s.io/api/core/v1"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
-	"k8s.io/kubernetes/pkg/api/v1/resource"
 	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -325,6 +324,18 @@ func (gqm *GroupQuotaManager) UpdateQuota(quota *v1alpha1.ElasticQuota, isDelete
 	return nil
 }
 
+// IsQuotaChanged checks if the quota is missing in cache or its meta in cache differs from the quota.
+func (gqm *GroupQuotaManager) IsQuotaChanged(quota *v1alpha1.ElasticQuota) bool {
+	gqm.hierarchyUpdateLock.RLock()
+	defer gqm.hierarchyUpdateLock.RUnlock()
+
+	localQuotaInfo, exist := gqm.quotaInfoMap[quota.Name]
+	if !exist {
+		return true
+	}
+	return localQuotaInfo.isQuotaMetaChange(NewQuotaInfoFromQuota(quota))
+}
+
 func (gqm *GroupQuotaManager) updateQuotaGroupConfigNoLock() {
 	// rebuild gqm.quotaTopoNodeMap
 	gqm.buildSubParGroupTopoNoLock()
@@ -479,13 +490,13 @@ func (gqm *GroupQuotaManager) GetAllQuotaNames() map[string]struct{} {
 func (gqm *GroupQuotaManager) updatePodRequestNoLock(quotaName string, oldPod, newPod *v1.Pod) {
 	var oldPodReq, newPodReq v1.ResourceList
 	if oldPod != nil {
-		oldPodReq, _ = resource.PodRequestsAndLimits(oldPod)
+		oldPodReq = util.GetPodEffectiveRequest(oldPod)
 	} else {
 		oldPodReq = make(v1.ResourceList)
 	}
 
 	if newPod != nil {
-		newPodReq, _ = resource.PodRequestsAndLimits(newPod)
+		newPodReq = util.GetPodEffectiveRequest(newPod)
 	} else {
 		newPodReq = make(v1.ResourceList)
 	}
@@ -510,13 +521,13 @@ func (gqm *GroupQuotaManager) updatePodUsedNoLock(quotaName string, oldPod, newP
 
 	var oldPodUsed, newPodUsed v1.ResourceList
 	if oldPod != nil {
-		oldPodUsed, _ = resource.PodRequestsAndLimits(oldPod)
+		oldPodUsed = util.GetPodEffectiveRequest(oldPod)
 	} else {
 		oldPodUsed = make(v1.ResourceList)
 	}
 
 	if newPod != nil {
-		newPodUsed, _ = resource.PodRequestsAndLimits(newPod)
+		newPodUsed = util.GetPodEffectiveRequest(newPod)
 	} else {
 		newPodUsed = make(v1.ResourceList)
 	}
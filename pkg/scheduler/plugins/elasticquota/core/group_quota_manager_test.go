@@ -146,6 +146,16 @@ func TestGroupQuotaManager_UpdateQuota(t *testing.T) {
 	assert.Equal(t, len(gqm.quotaInfoMap), 3)
 }
 
+func TestGroupQuotaManager_IsQuotaChanged(t *testing.T) {
+	gqm := NewGroupQuotaManager4Test()
+	quota := CreateQuota("test1", extension.RootQuotaName, 64, 100*GigaByte, 50, 80*GigaByte, true, false)
+	assert.True(t, gqm.IsQuotaChanged(quota))
+	assert.NoError(t, gqm.UpdateQuota(quota, false))
+	assert.False(t, gqm.IsQuotaChanged(quota))
+	quota = CreateQuota("test1", extension.RootQuotaName, 96, 100*GigaByte, 50, 80*GigaByte, true, false)
+	assert.True(t, gqm.IsQuotaChanged(quota))
+}
+
 func TestGroupQuotaManager_UpdateQuotaInternalAndRequest(t *testing.T) {
 	gqm := NewGroupQuotaManager4Test()
 	deltaRes := createResourceList(96, 160*GigaByte)
@@ -23,10 +23,10 @@ import (
 	v1 "k8s.io/api/core/v1"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
-	resourcev1 "k8s.io/kubernetes/pkg/api/v1/resource"
 	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 type QuotaCalculateInfo struct {
@@ -226,6 +226,12 @@ func (qi *QuotaInfo) GetRuntime() v1.ResourceList {
 	return qi.CalculateInfo.Runtime.DeepCopy()
 }
 
+func (qi *QuotaInfo) GetMin() v1.ResourceList {
+	qi.lock.Lock()
+	defer qi.lock.Unlock()
+	return qi.CalculateInfo.Min.DeepCopy()
+}
+
 func (qi *QuotaInfo) getMax() v1.ResourceList {
 	qi.lock.Lock()
 	defer qi.lock.Unlock()
@@ -403,7 +409,7 @@ type PodInfo struct {
 }
 
 func NewPodInfo(pod *v1.Pod) *PodInfo {
-	res, _ := resourcev1.PodRequestsAndLimits(pod)
+	res := util.GetPodEffectiveRequest(pod)
 	return &PodInfo{
 		pod:      pod,
 		resource: res,
@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	schetesting "k8s.io/kubernetes/pkg/scheduler/testing"
 )
 
@@ -112,3 +114,17 @@ func TestUpdateQuotaInfoFromRemote(t *testing.T) {
 	assert.NotEqual(t, qi.CalculateInfo.Request, remoteQuotaInfo.CalculateInfo.Request)
 	assert.NotEqual(t, qi.CalculateInfo.Runtime, remoteQuotaInfo.CalculateInfo.Runtime)
 }
+
+func TestNewPodInfoWithPodOverhead(t *testing.T) {
+	pod := schetesting.MakePod().Name("test").Req(map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1",
+		v1.ResourceMemory: "1Gi",
+	}).Obj()
+	pod.Spec.Overhead = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("200m"),
+		v1.ResourceMemory: resource.MustParse("256Mi"),
+	}
+	podInfo := NewPodInfo(pod)
+	assert.Equal(t, int64(1200), podInfo.resource.Cpu().MilliValue())
+	assert.Equal(t, int64(1280*1024*1024), podInfo.resource.Memory().Value())
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	koordutil "github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	reasonGangQuotaInsufficient = "GangQuotaInsufficient"
+)
+
+// checkGangQuota rejects all the pods of a gang up front if the remaining runtime quota can't admit the gang,
+// preventing two gangs from the same quota group admitted halfway deadlocking each other. The members of the rejected
+// gang reserved and waiting in Permit are rejected too, so that the gang admitted halfway doesn't hold the quota.
+// The gang members already assigned (including the reserved ones waiting in Permit) have been counted in the used,
+// so the request of the gang is the sum of the unassigned members until the minMember satisfied. If the member pods
+// are not all created yet, the pod being scheduled is regarded as the template of the missing members.
+func (g *Plugin) checkGangQuota(pod *corev1.Pod, quotaName string, quotaInfo *core.QuotaInfo) *framework.Status {
+	gangName := util.GetGangNameByPod(pod)
+	if gangName == "" {
+		return nil
+	}
+	gangId := util.GetId(pod.Namespace, gangName)
+	minMember := g.getGangMinMember(pod, gangName)
+	if minMember <= 1 {
+		return nil
+	}
+
+	assignedNum := 0
+	var pendingMembers []*corev1.Pod
+	for _, p := range quotaInfo.GetPodCache() {
+		if p.Namespace != pod.Namespace || util.GetGangNameByPod(p) != gangName {
+			continue
+		}
+		if quotaInfo.CheckPodIsAssigned(p) {
+			assignedNum++
+		} else if p.UID != pod.UID {
+			pendingMembers = append(pendingMembers, p)
+		}
+	}
+	if assignedNum >= minMember {
+		// the gang has been admitted, the rest members follow the per-pod check
+		g.gangQuotaAdmitted(gangId)
+		return nil
+	}
+
+	sort.Slice(pendingMembers, func(i, j int) bool {
+		return pendingMembers[i].Name < pendingMembers[j].Name
+	})
+	podRequest := koordutil.GetPodEffectiveRequest(core.RunDecoratePod(pod))
+	gangRequest := podRequest.DeepCopy()
+	for i := 1; i < minMember-assignedNum; i++ {
+		if i <= len(pendingMembers) {
+			memberRequest := koordutil.GetPodEffectiveRequest(core.RunDecoratePod(pendingMembers[i-1]))
+			gangRequest = quotav1.Add(gangRequest, memberRequest)
+		} else {
+			gangRequest = quotav1.Add(gangRequest, podRequest)
+		}
+	}
+
+	quotaUsed := quotaInfo.GetUsed()
+	quotaRuntime := quotaInfo.GetRuntime()
+	newUsed := quotav1.Add(gangRequest, quotaUsed)
+	if isLessEqual, exceedDimensions := quotav1.LessThanOrEqual(newUsed, quotaRuntime); !isLessEqual {
+		msg := fmt.Sprintf("Scheduling refused due to insufficient quotas for gang, "+
+			"quotaName: %v, gang: %v, minMember: %v, runtime: %v, used: %v, gang's request: %v, exceedDimensions: %v",
+			quotaName, gangId, minMember, printResourceList(quotaRuntime), printResourceList(quotaUsed),
+			printResourceList(gangRequest), exceedDimensions)
+		g.quotaRejected(quotaName, exceedDimensions)
+		g.gangQuotaRejected(pod, gangId, gangName, msg)
+		g.releaseGangReservations(pod.Namespace, gangName, msg)
+		return framework.NewStatus(framework.Unschedulable, msg)
+	}
+	g.gangQuotaAdmitted(gangId)
+	return nil
+}
+
+// releaseGangReservations rejects the members of the gang waiting in Permit, and the framework unreserves them to
+// release the quota they hold.
+func (g *Plugin) releaseGangReservations(namespace, gangName, msg string) {
+	g.handle.IterateOverWaitingPods(func(waitingPod framework.WaitingPod) {
+		waiting := waitingPod.GetPod()
+		if waiting.Namespace != namespace || util.GetGangNameByPod(waiting) != gangName {
+			return
+		}
+		klog.V(4).Infof("reject the waiting pod %v/%v of the gang %v rejected by quota", waiting.Namespace, waiting.Name, gangName)
+		waitingPod.Reject(Name, msg)
+	})
+}
+
+func (g *Plugin) getGangMinMember(pod *corev1.Pod, gangName string) int {
+	if g.pgLister != nil {
+		if pg, err := g.pgLister.PodGroups(pod.Namespace).Get(gangName); err == nil {
+			return int(pg.Spec.MinMember)
+		}
+	}
+	minNum, err := util.GetGangMinNumFromPod(pod)
+	if err != nil {
+		return 0
+	}
+	return minNum
+}
+
+// gangQuotaRejected emits one event for the gang when it turns rejected instead of one event per member pod.
+func (g *Plugin) gangQuotaRejected(pod *corev1.Pod, gangId, gangName, msg string) {
+	g.rejectedGangsLock.Lock()
+	_, rejected := g.rejectedGangs[gangId]
+	g.rejectedGangs[gangId] = struct{}{}
+	g.rejectedGangsLock.Unlock()
+	if rejected {
+		return
+	}
+
+	klog.V(4).Infof("gang %v rejected by quota, %v", gangId, msg)
+	recorder := g.handle.EventRecorder()
+	if recorder == nil {
+		return
+	}
+	var regarding runtime.Object = pod
+	if g.pgLister != nil {
+		if pg, err := g.pgLister.PodGroups(pod.Namespace).Get(gangName); err == nil {
+			regarding = pg
+		}
+	}
+	recorder.Eventf(regarding, nil, corev1.EventTypeWarning, reasonGangQuotaInsufficient, "Scheduling", msg)
+}
+
+func (g *Plugin) gangQuotaAdmitted(gangId string) {
+	g.rejectedGangsLock.Lock()
+	defer g.rejectedGangsLock.Unlock()
+	delete(g.rejectedGangs, gangId)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func makeGangPodWithAnnotation(name, gangName, minNum string) *corev1.Pod {
+	pod := MakePod("t1-ns1", name).UID(name).Container(MakeResourceList().CPU(2).Mem(2).Obj()).Obj()
+	pod.Annotations = map[string]string{
+		extension.AnnotationGangName:   gangName,
+		extension.AnnotationGangMinNum: minNum,
+	}
+	return pod
+}
+
+func TestPlugin_PreFilter_GangQuota(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	_, err := suit.client.SchedulingV1alpha1().PodGroups("t1-ns1").Create(context.TODO(), &schedv1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "t1-ns1", Name: "gang-b"},
+		Spec:       schedv1alpha1.PodGroupSpec{MinMember: 2},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	p, err := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
+	assert.NoError(t, err)
+	gp := p.(*Plugin)
+
+	qi := gp.groupQuotaManager.GetQuotaInfoByName(extension.DefaultQuotaName)
+	qi.Lock()
+	qi.CalculateInfo.Runtime = MakeResourceList().CPU(4).Mem(4).Obj()
+	qi.UnLock()
+
+	// gang-a is defined by annotations, gang-b is defined by the PodGroup
+	gangA := []*corev1.Pod{
+		makeGangPodWithAnnotation("a-1", "gang-a", "2"),
+		makeGangPodWithAnnotation("a-2", "gang-a", "2"),
+	}
+	gangB := []*corev1.Pod{
+		MakePod("t1-ns1", "b-1").UID("b-1").Label(schedv1alpha1.PodGroupLabel, "gang-b").
+			Container(MakeResourceList().CPU(2).Mem(2).Obj()).Obj(),
+		MakePod("t1-ns1", "b-2").UID("b-2").Label(schedv1alpha1.PodGroupLabel, "gang-b").
+			Container(MakeResourceList().CPU(2).Mem(2).Obj()).Obj(),
+	}
+	for _, pod := range append(gangA, gangB...) {
+		gp.OnPodAdd(pod)
+	}
+
+	// both gangs fit the quota alone
+	status := gp.PreFilter(context.TODO(), framework.NewCycleState(), gangA[0])
+	assert.True(t, status.IsSuccess())
+	status = gp.PreFilter(context.TODO(), framework.NewCycleState(), gangB[0])
+	assert.True(t, status.IsSuccess())
+
+	// a-1 reserved and waits for its sibling in Permit
+	gp.Reserve(context.TODO(), framework.NewCycleState(), gangA[0], "node-1")
+
+	// the whole gang-b is rejected since it can't fit into the remaining quota
+	for _, pod := range gangB {
+		status = gp.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+		assert.Equal(t, framework.Unschedulable, status.Code(), pod.Name)
+	}
+	assert.Contains(t, gp.rejectedGangs, "t1-ns1/gang-b")
+
+	// the sibling of the admitted gang-a still fits
+	status = gp.PreFilter(context.TODO(), framework.NewCycleState(), gangA[1])
+	assert.True(t, status.IsSuccess())
+	gp.Reserve(context.TODO(), framework.NewCycleState(), gangA[1], "node-1")
+	assert.Equal(t, MakeResourceList().CPU(4).Mem(4).Obj(), qi.GetUsed())
+
+	// gang-b is admitted after gang-a releases the quota
+	gp.Unreserve(context.TODO(), framework.NewCycleState(), gangA[0], "node-1")
+	gp.Unreserve(context.TODO(), framework.NewCycleState(), gangA[1], "node-1")
+	status = gp.PreFilter(context.TODO(), framework.NewCycleState(), gangB[0])
+	assert.True(t, status.IsSuccess())
+	assert.NotContains(t, gp.rejectedGangs, "t1-ns1/gang-b")
+}
+
+// fakeWaitingPodsHandle serves the waiting pods, and unreserves the waiting pods rejected as the framework does.
+type fakeWaitingPodsHandle struct {
+	framework.Handle
+	plugin      *Plugin
+	waitingPods map[string]*corev1.Pod
+}
+
+func (h *fakeWaitingPodsHandle) IterateOverWaitingPods(callback func(framework.WaitingPod)) {
+	for _, pod := range h.waitingPods {
+		callback(&fakeWaitingPod{handle: h, pod: pod})
+	}
+}
+
+type fakeWaitingPod struct {
+	handle *fakeWaitingPodsHandle
+	pod    *corev1.Pod
+}
+
+func (w *fakeWaitingPod) GetPod() *corev1.Pod { return w.pod }
+
+func (w *fakeWaitingPod) GetPendingPlugins() []string { return nil }
+
+func (w *fakeWaitingPod) Allow(pluginName string) {}
+
+func (w *fakeWaitingPod) Reject(pluginName, msg string) {
+	delete(w.handle.waitingPods, w.pod.Name)
+	w.handle.plugin.Unreserve(context.TODO(), framework.NewCycleState(), w.pod, "node-1")
+}
+
+func TestPlugin_PreFilter_GangQuotaReleasesReservations(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	p, err := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
+	assert.NoError(t, err)
+	gp := p.(*Plugin)
+	handle := &fakeWaitingPodsHandle{Handle: gp.handle, plugin: gp, waitingPods: map[string]*corev1.Pod{}}
+	gp.handle = handle
+
+	qi := gp.groupQuotaManager.GetQuotaInfoByName(extension.DefaultQuotaName)
+	qi.Lock()
+	qi.CalculateInfo.Runtime = MakeResourceList().CPU(4).Mem(4).Obj()
+	qi.UnLock()
+
+	gangA := []*corev1.Pod{
+		makeGangPodWithAnnotation("a-1", "gang-a", "2"),
+		makeGangPodWithAnnotation("a-2", "gang-a", "2"),
+	}
+	gangB := []*corev1.Pod{
+		makeGangPodWithAnnotation("b-1", "gang-b", "2"),
+		makeGangPodWithAnnotation("b-2", "gang-b", "2"),
+	}
+	for _, pod := range append(gangA, gangB...) {
+		gp.OnPodAdd(pod)
+	}
+
+	// a-1 and b-1 are admitted before the quota shrinks, and both wait for their siblings in Permit
+	for _, pod := range []*corev1.Pod{gangA[0], gangB[0]} {
+		gp.Reserve(context.TODO(), framework.NewCycleState(), pod, "node-1")
+		handle.waitingPods[pod.Name] = pod
+	}
+	assert.Equal(t, MakeResourceList().CPU(4).Mem(4).Obj(), qi.GetUsed())
+
+	// gang-b is rejected, and the quota held by the waiting b-1 is released
+	status := gp.PreFilter(context.TODO(), framework.NewCycleState(), gangB[1])
+	assert.Equal(t, framework.Unschedulable, status.Code())
+	assert.NotContains(t, handle.waitingPods, "b-1")
+	assert.Contains(t, handle.waitingPods, "a-1")
+	assert.Equal(t, MakeResourceList().CPU(2).Mem(2).Obj(), qi.GetUsed())
+
+	// the sibling of gang-a fits the released quota
+	status = gp.PreFilter(context.TODO(), framework.NewCycleState(), gangA[1])
+	assert.True(t, status.IsSuccess())
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+)
+
+const (
+	reasonGangPreempted = "GangPreempted"
+)
+
+// expandGangVictims expands the victims of each candidate belonging to the gangs of the Strict mode to all the
+// assigned members of the gangs, since the rest of a gang with all-or-nothing semantics keeps running uselessly once
+// a member is preempted. The expanded candidates are then compared by the same cost function as the others, so a
+// node with the victims out of the gangs is preferred if preempting the whole gangs costs more. A gang is not
+// expanded if any of its members can't be preempted by the pod or the expansion violates more PDBs.
+func (g *Plugin) expandGangVictims(pod *corev1.Pod, candidates []defaultpreemption.Candidate) []defaultpreemption.Candidate {
+	if len(candidates) == 0 {
+		return candidates
+	}
+	gangMembers := g.getAssignedGangMembers()
+	if len(gangMembers) == 0 {
+		return candidates
+	}
+	pdbs, err := getPodDisruptionBudgets(g.pdbLister)
+	if err != nil {
+		klog.ErrorS(err, "failed to list the PDBs, skip expanding the gang victims", "pod", klog.KObj(pod))
+		return candidates
+	}
+	preemptorGang := util.GetId(pod.Namespace, util.GetGangNameByPod(pod))
+
+	expanded := make([]defaultpreemption.Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		victims := c.Victims()
+		victimKeys := make(map[types.UID]struct{}, len(victims.Pods))
+		for _, victim := range victims.Pods {
+			victimKeys[victim.UID] = struct{}{}
+		}
+		var gangs []string
+		seenGangs := map[string]struct{}{}
+		pods := victims.Pods
+		numPDBViolations := victims.NumPDBViolations
+		for _, victim := range victims.Pods {
+			gangName := util.GetGangNameByPod(victim)
+			if gangName == "" {
+				continue
+			}
+			gangId := util.GetId(victim.Namespace, gangName)
+			if _, ok := seenGangs[gangId]; ok || gangId == preemptorGang {
+				continue
+			}
+			seenGangs[gangId] = struct{}{}
+			if g.getGangMode(victim, gangName) != extension.GangModeStrict {
+				continue
+			}
+			extra, ok := g.getExtraGangVictims(pod, gangMembers[gangId], victimKeys)
+			if !ok || len(extra) == 0 {
+				continue
+			}
+			violations := countPDBViolations(append(append([]*corev1.Pod{}, pods...), extra...), pdbs)
+			if violations > numPDBViolations {
+				klog.V(4).InfoS("Skip preempting the whole gang, which violates the PDBs", "pod", klog.KObj(pod),
+					"gang", gangId, "node", c.Name())
+				continue
+			}
+			pods = append(append([]*corev1.Pod{}, pods...), extra...)
+			numPDBViolations = violations
+			for _, member := range extra {
+				victimKeys[member.UID] = struct{}{}
+			}
+			gangs = append(gangs, gangId)
+		}
+		if len(gangs) == 0 {
+			expanded = append(expanded, c)
+			continue
+		}
+		expanded = append(expanded, &candidate{
+			victims: &extenderv1.Victims{
+				Pods:             pods,
+				NumPDBViolations: numPDBViolations,
+			},
+			name:  c.Name(),
+			gangs: gangs,
+		})
+	}
+	return expanded
+}
+
+// getAssignedGangMembers returns the members of the gangs assigned to the nodes in the snapshot by the gang ID.
+func (g *Plugin) getAssignedGangMembers() map[string][]*corev1.Pod {
+	nodeInfos, err := g.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		klog.ErrorS(err, "failed to list the nodes from the snapshot")
+		return nil
+	}
+	members := map[string][]*corev1.Pod{}
+	for _, nodeInfo := range nodeInfos {
+		for _, podInfo := range nodeInfo.Pods {
+			gangName := util.GetGangNameByPod(podInfo.Pod)
+			if gangName == "" {
+				continue
+			}
+			gangId := util.GetId(podInfo.Pod.Namespace, gangName)
+			members[gangId] = append(members[gangId], podInfo.Pod)
+		}
+	}
+	return members
+}
+
+// getExtraGangVictims returns the members of the gang not in the victims yet, sorted by the name. It returns false
+// if any of them can't be preempted by the pod.
+func (g *Plugin) getExtraGangVictims(pod *corev1.Pod, members []*corev1.Pod, victimKeys map[types.UID]struct{}) ([]*corev1.Pod, bool) {
+	var extra []*corev1.Pod
+	for _, member := range members {
+		if _, ok := victimKeys[member.UID]; ok || member.DeletionTimestamp != nil {
+			continue
+		}
+		if !g.canPreempt(pod, member) {
+			return nil, false
+		}
+		extra = append(extra, member)
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		return extra[i].Name < extra[j].Name
+	})
+	return extra, true
+}
+
+// getGangMode returns the mode of the gang from the PodGroup, falling back to the member pod, and defaults to Strict.
+func (g *Plugin) getGangMode(pod *corev1.Pod, gangName string) string {
+	mode := pod.Annotations[extension.AnnotationGangMode]
+	if g.pgLister != nil {
+		if pg, err := g.pgLister.PodGroups(pod.Namespace).Get(gangName); err == nil && pg.Annotations[extension.AnnotationGangMode] != "" {
+			mode = pg.Annotations[extension.AnnotationGangMode]
+		}
+	}
+	if mode != extension.GangModeNonStrict {
+		mode = extension.GangModeStrict
+	}
+	return mode
+}
+
+func countPDBViolations(pods []*corev1.Pod, pdbs []*policy.PodDisruptionBudget) int64 {
+	podInfos := make([]*framework.PodInfo, 0, len(pods))
+	for _, p := range pods {
+		podInfos = append(podInfos, framework.NewPodInfo(p))
+	}
+	violating, _ := filterPodsWithPDBViolation(podInfos, pdbs)
+	return int64(len(violating))
+}
+
+// gangsPreempted emits an event for each gang preempted as a whole on the nominated node, regarding the PodGroup
+// if it exists.
+func (g *Plugin) gangsPreempted(pod *corev1.Pod, c defaultpreemption.Candidate) {
+	cand, ok := c.(*candidate)
+	if !ok || len(cand.gangs) == 0 {
+		return
+	}
+	recorder := g.handle.EventRecorder()
+	for _, gangId := range cand.gangs {
+		var members []string
+		var firstMember *corev1.Pod
+		for _, victim := range cand.victims.Pods {
+			if util.GetId(victim.Namespace, util.GetGangNameByPod(victim)) != gangId {
+				continue
+			}
+			members = append(members, victim.Name)
+			if firstMember == nil {
+				firstMember = victim
+			}
+		}
+		msg := fmt.Sprintf("Gang %v is preempted as a whole by %v on node %v, members: %v",
+			gangId, klog.KObj(pod), cand.name, strings.Join(members, ","))
+		klog.V(4).Info(msg)
+		if recorder == nil || firstMember == nil {
+			continue
+		}
+		var regarding runtime.Object = firstMember
+		if g.pgLister != nil {
+			if pg, err := g.pgLister.PodGroups(firstMember.Namespace).Get(util.GetGangNameByPod(firstMember)); err == nil {
+				regarding = pg
+			}
+		}
+		recorder.Eventf(regarding, pod, corev1.EventTypeNormal, reasonGangPreempted, "Preempting", msg)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestPlugin_expandGangVictims(t *testing.T) {
+	res := map[corev1.ResourceName]string{corev1.ResourceMemory: "100"}
+	nodes := []*corev1.Node{
+		schedulertesting.MakeNode().Name("node-a").Capacity(res).Obj(),
+		schedulertesting.MakeNode().Name("node-b").Capacity(res).Obj(),
+	}
+	now := time.Now()
+	makeVictim := func(name, nodeName, gangName string, priority int32, startTime time.Time) *corev1.Pod {
+		pod := makePod(name, "ns1", 50, 0, 0, priority, name, nodeName)
+		pod.Labels = map[string]string{"app": name}
+		if gangName != "" {
+			pod.Labels["app"] = gangName
+			pod.Annotations = map[string]string{
+				extension.AnnotationGangName:   gangName,
+				extension.AnnotationGangMinNum: "2",
+			}
+		}
+		pod.Status.StartTime = &metav1.Time{Time: startTime}
+		return pod
+	}
+	newCandidates := func(gangMember, plainPod *corev1.Pod) []defaultpreemption.Candidate {
+		return []defaultpreemption.Candidate{
+			&candidate{victims: &extenderv1.Victims{Pods: []*corev1.Pod{gangMember}}, name: "node-a"},
+			&candidate{victims: &extenderv1.Victims{Pods: []*corev1.Pod{plainPod}}, name: "node-b"},
+		}
+	}
+	preemptor := makePod("preemptor", "ns1", 50, 0, 0, highPriority, "preemptor", "")
+
+	tests := []struct {
+		name         string
+		gangMode     string
+		memberPri    int32
+		pdb          *policy.PodDisruptionBudget
+		wantExpanded bool
+	}{
+		{
+			name:         "whole strict gang is preempted",
+			memberPri:    lowPriority,
+			wantExpanded: true,
+		},
+		{
+			name:      "non-strict gang is not expanded",
+			gangMode:  extension.GangModeNonStrict,
+			memberPri: lowPriority,
+		},
+		{
+			name:      "gang with a member not preemptable is not expanded",
+			memberPri: highPriority,
+		},
+		{
+			name:      "gang expansion violating the PDB is not expanded",
+			memberPri: lowPriority,
+			pdb: &policy.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "gang-a"},
+				Spec: policy.PodDisruptionBudgetSpec{
+					MinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+					Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "gang-a"}},
+				},
+				Status: policy.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gangMember := makeVictim("gang-a-1", "node-a", "gang-a", lowPriority, now)
+			restMember := makeVictim("gang-a-2", "node-b", "gang-a", tt.memberPri, now)
+			plainPod := makeVictim("plain", "node-b", "", lowPriority, now.Add(-time.Hour))
+			if tt.gangMode != "" {
+				gangMember.Annotations[extension.AnnotationGangMode] = tt.gangMode
+				restMember.Annotations[extension.AnnotationGangMode] = tt.gangMode
+			}
+			suit := newPluginTestSuitWithPod(t, nodes, []*corev1.Pod{gangMember, restMember, plainPod})
+			pl := suit.plugin.(*Plugin)
+			if tt.pdb != nil {
+				indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+				assert.NoError(t, indexer.Add(tt.pdb))
+				pl.pdbLister = policylisters.NewPodDisruptionBudgetLister(indexer)
+			}
+
+			// preempting the single member on node-a is preferred, the victim there started later
+			candidates := newCandidates(gangMember, plainPod)
+			assert.Equal(t, "node-a", defaultpreemption.SelectCandidate(candidates).Name())
+
+			got := pl.expandGangVictims(preemptor, newCandidates(gangMember, plainPod))
+			if !tt.wantExpanded {
+				assert.Equal(t, candidates, got)
+				assert.Equal(t, "node-a", defaultpreemption.SelectCandidate(got).Name())
+				return
+			}
+			assert.Equal(t, []defaultpreemption.Candidate{
+				&candidate{
+					victims: &extenderv1.Victims{Pods: []*corev1.Pod{gangMember, restMember}},
+					name:    "node-a",
+					gangs:   []string{"ns1/gang-a"},
+				},
+				candidates[1],
+			}, got)
+			// preempting the whole gang costs more than the pod on node-b
+			assert.Equal(t, "node-b", defaultpreemption.SelectCandidate(got).Name())
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+const quotaMetricsSyncInterval = 30 * time.Second
+
+var (
+	quotaSchedulingWaitSeconds = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "scheduler",
+			Name:           "elastic_quota_scheduling_wait_seconds",
+			Help:           "Time from the pod creation to its first admission by the quota in seconds, by the quotaGroup",
+			Buckets:        metrics.ExponentialBuckets(1, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota"})
+
+	quotaRejections = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "scheduler",
+			Name:           "elastic_quota_rejections_total",
+			Help:           "Number of the scheduling attempts refused due to insufficient quotas, by the quotaGroup whose runtime is exceeded, by the exceeded resource",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource"})
+
+	quotaResources = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "scheduler",
+			Name:           "elastic_quota_resources",
+			Help:           "The used, min and runtime resources of the quotaGroups synced from the cache, by the quotaGroup, by the resource, by the type",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"quota", "resource", "type"})
+
+	registerQuotaMetrics sync.Once
+)
+
+func registerMetrics() {
+	registerQuotaMetrics.Do(func() {
+		legacyregistry.MustRegister(quotaSchedulingWaitSeconds, quotaRejections, quotaResources)
+	})
+}
+
+// quotaGroupLabeler labels the quotaGroups in the metrics. The root, system, default quotaGroups and the quotaGroups
+// in the allowlist are labeled by name, and the others by the hash buckets of their names, so that the cardinality
+// of the metrics is bounded however many quotaGroups are created.
+type quotaGroupLabeler struct {
+	quotaGroups sets.String
+	buckets     uint32
+}
+
+func newQuotaGroupLabeler(args *config.ElasticQuotaArgs) *quotaGroupLabeler {
+	quotaGroups := sets.NewString(extension.RootQuotaName, extension.SystemQuotaName, extension.DefaultQuotaName)
+	quotaGroups.Insert(args.MetricsQuotaGroups...)
+	buckets := uint32(1)
+	if args.MetricsQuotaGroupBuckets != nil && *args.MetricsQuotaGroupBuckets > 0 {
+		buckets = uint32(*args.MetricsQuotaGroupBuckets)
+	}
+	return &quotaGroupLabeler{
+		quotaGroups: quotaGroups,
+		buckets:     buckets,
+	}
+}
+
+func (l *quotaGroupLabeler) label(quotaName string) string {
+	if l.quotaGroups.Has(quotaName) {
+		return quotaName
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(quotaName))
+	return fmt.Sprintf("bucket-%d", h.Sum32()%l.buckets)
+}
+
+// quotaRejected counts the rejection by each resource exceeding the runtime of the quotaGroup.
+func (g *Plugin) quotaRejected(quotaName string, exceedDimensions []corev1.ResourceName) {
+	label := g.quotaLabeler.label(quotaName)
+	for _, resourceName := range exceedDimensions {
+		quotaRejections.WithLabelValues(label, string(resourceName)).Inc()
+	}
+}
+
+// quotaAdmitted observes the scheduling wait of the pod only on its first admission, the pod passing the quota
+// again in the later scheduling cycles, e.g. failing to fit any node, is not observed.
+func (g *Plugin) quotaAdmitted(pod *corev1.Pod, quotaName string) {
+	g.admittedPodsLock.Lock()
+	_, admitted := g.admittedPods[pod.UID]
+	g.admittedPods[pod.UID] = struct{}{}
+	g.admittedPodsLock.Unlock()
+	if admitted {
+		return
+	}
+	wait := time.Since(pod.CreationTimestamp.Time)
+	quotaSchedulingWaitSeconds.WithLabelValues(g.quotaLabeler.label(quotaName)).Observe(wait.Seconds())
+}
+
+// forgetAdmittedPod forgets the pod once it is assigned or deleted, since it is never admitted again.
+func (g *Plugin) forgetAdmittedPod(pod *corev1.Pod) {
+	g.admittedPodsLock.Lock()
+	defer g.admittedPodsLock.Unlock()
+	delete(g.admittedPods, pod.UID)
+}
+
+// syncQuotaMetrics syncs the used, min and runtime resources of the quotaGroups from the cache. The quotaGroups
+// labeled by the same hash bucket are summed up.
+func (g *Plugin) syncQuotaMetrics() {
+	type resourceKey struct {
+		quota        string
+		resourceName corev1.ResourceName
+		resourceType string
+	}
+	values := map[resourceKey]float64{}
+	add := func(quota, resourceType string, resources corev1.ResourceList) {
+		for resourceName, quantity := range resources {
+			values[resourceKey{quota: quota, resourceName: resourceName, resourceType: resourceType}] += quantity.AsApproximateFloat64()
+		}
+	}
+	for quotaName := range g.groupQuotaManager.GetAllQuotaNames() {
+		quotaInfo := g.groupQuotaManager.GetQuotaInfoByName(quotaName)
+		if quotaInfo == nil {
+			continue
+		}
+		label := g.quotaLabeler.label(quotaName)
+		add(label, "used", quotaInfo.GetUsed())
+		add(label, "min", quotaInfo.GetMin())
+		add(label, "runtime", g.groupQuotaManager.RefreshRuntime(quotaName))
+	}
+
+	quotaResources.Reset()
+	for key, value := range values {
+		quotaResources.WithLabelValues(key.quota, string(key.resourceName), key.resourceType).Set(value)
+	}
+}
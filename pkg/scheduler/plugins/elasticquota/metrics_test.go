@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func TestQuotaGroupLabeler(t *testing.T) {
+	labeler := newQuotaGroupLabeler(&config.ElasticQuotaArgs{
+		MetricsQuotaGroups:       []string{"team-a"},
+		MetricsQuotaGroupBuckets: pointer.Int32(4),
+	})
+	assert.Equal(t, "team-a", labeler.label("team-a"))
+	assert.Equal(t, extension.DefaultQuotaName, labeler.label(extension.DefaultQuotaName))
+	assert.Equal(t, extension.SystemQuotaName, labeler.label(extension.SystemQuotaName))
+
+	labels := map[string]struct{}{}
+	for _, quotaName := range []string{"team-b", "team-c", "team-d", "team-e", "team-f", "team-g"} {
+		label := labeler.label(quotaName)
+		assert.True(t, strings.HasPrefix(label, "bucket-"), label)
+		assert.Equal(t, label, labeler.label(quotaName), "the label should be stable")
+		labels[label] = struct{}{}
+	}
+	assert.LessOrEqual(t, len(labels), 4)
+}
+
+func TestPlugin_QuotaMetrics(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	suit.elasticQuotaArgs.MetricsQuotaGroups = []string{"test-metrics"}
+	p, err := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
+	assert.NoError(t, err)
+	gp := p.(*Plugin)
+	gp.OnQuotaAdd(CreateQuota2("test-metrics", extension.RootQuotaName, 10, 10, 2, 4, 1, 1, false))
+	gp.OnQuotaAdd(CreateQuota2("other-metrics", extension.RootQuotaName, 10, 10, 0, 0, 1, 1, false))
+	for _, quotaName := range []string{"test-metrics", "other-metrics"} {
+		qi := gp.groupQuotaManager.GetQuotaInfoByName(quotaName)
+		qi.Lock()
+		qi.CalculateInfo.Runtime = MakeResourceList().CPU(1).Mem(2).Obj()
+		qi.UnLock()
+	}
+	otherLabel := gp.quotaLabeler.label("other-metrics")
+	assert.True(t, strings.HasPrefix(otherLabel, "bucket-"))
+
+	getCounter := func(quota string, resourceName corev1.ResourceName) float64 {
+		value, err := testutil.GetCounterMetricValue(quotaRejections.WithLabelValues(quota, string(resourceName)))
+		assert.NoError(t, err)
+		return value
+	}
+	getWaitCount := func(quota string) uint64 {
+		count, err := testutil.GetHistogramMetricCount(quotaSchedulingWaitSeconds.WithLabelValues(quota))
+		assert.NoError(t, err)
+		return count
+	}
+	memoryRejections := getCounter("test-metrics", corev1.ResourceMemory)
+	cpuRejections := getCounter("test-metrics", corev1.ResourceCPU)
+	otherRejections := getCounter(otherLabel, corev1.ResourceCPU)
+	admissions := getWaitCount("test-metrics")
+	otherAdmissions := getWaitCount(otherLabel)
+
+	preFilter := func(pod *corev1.Pod) *framework.Status {
+		return gp.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	}
+	newPod := func(quotaName, name string, cpu, mem int64) *corev1.Pod {
+		return MakePod("t1-ns1", name).UID(name).Label(extension.LabelQuotaName, quotaName).Container(
+			MakeResourceList().CPU(cpu).Mem(mem).Obj()).Obj()
+	}
+	// exceeds the memory
+	assert.False(t, preFilter(newPod("test-metrics", "pod1", 1, 3)).IsSuccess())
+	// exceeds both the cpu and the memory
+	assert.False(t, preFilter(newPod("test-metrics", "pod2", 2, 3)).IsSuccess())
+	// admitted twice, but observed only on the first admission
+	pod3 := newPod("test-metrics", "pod3", 1, 2)
+	assert.True(t, preFilter(pod3).IsSuccess())
+	assert.True(t, preFilter(pod3).IsSuccess())
+	// the quotaGroup not in the allowlist is labeled by the hash bucket
+	assert.False(t, preFilter(newPod("other-metrics", "pod4", 2, 1)).IsSuccess())
+	assert.True(t, preFilter(newPod("other-metrics", "pod5", 1, 1)).IsSuccess())
+
+	assert.Equal(t, memoryRejections+2, getCounter("test-metrics", corev1.ResourceMemory))
+	assert.Equal(t, cpuRejections+1, getCounter("test-metrics", corev1.ResourceCPU))
+	assert.Equal(t, otherRejections+1, getCounter(otherLabel, corev1.ResourceCPU))
+	assert.Equal(t, admissions+1, getWaitCount("test-metrics"))
+	assert.Equal(t, otherAdmissions+1, getWaitCount(otherLabel))
+
+	// the pod admitted again after it is deleted and recreated is observed again
+	gp.OnPodDelete(pod3)
+	assert.True(t, preFilter(pod3).IsSuccess())
+	assert.Equal(t, admissions+2, getWaitCount("test-metrics"))
+
+	getGauge := func(quota string, resourceName corev1.ResourceName, resourceType string) float64 {
+		value, err := testutil.GetGaugeMetricValue(quotaResources.WithLabelValues(quota, string(resourceName), resourceType))
+		assert.NoError(t, err)
+		return value
+	}
+	gp.OnPodAdd(pod3)
+	gp.Reserve(context.TODO(), framework.NewCycleState(), pod3, "test-node")
+	gp.syncQuotaMetrics()
+	assert.Equal(t, float64(1), getGauge("test-metrics", corev1.ResourceCPU, "used"))
+	assert.Equal(t, float64(2), getGauge("test-metrics", corev1.ResourceMemory, "used"))
+	assert.Equal(t, float64(2), getGauge("test-metrics", corev1.ResourceCPU, "min"))
+	assert.Equal(t, float64(4), getGauge("test-metrics", corev1.ResourceMemory, "min"))
+}
@@ -24,13 +24,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	v1 "k8s.io/client-go/listers/core/v1"
 	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
-	"k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned"
 	"sigs.k8s.io/scheduler-plugins/pkg/generated/informers/externalversions"
@@ -41,6 +41,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 const (
@@ -71,12 +72,22 @@ type Plugin struct {
 	nodeResourceMapLock sync.Mutex
 	nodeResourceMap     map[string]struct{}
 	groupQuotaManager   *core.GroupQuotaManager
+	pgLister            v1alpha1.PodGroupLister
+	// rejectedGangs records the gangs rejected by quota to avoid the duplicated events
+	rejectedGangsLock sync.Mutex
+	rejectedGangs     map[string]struct{}
+	// admittedPods records the pods admitted by quota to observe the scheduling wait only on the first admission
+	admittedPodsLock sync.Mutex
+	admittedPods     map[types.UID]struct{}
+	quotaLabeler     *quotaGroupLabeler
 }
 
 var (
 	_ framework.PreFilterPlugin  = &Plugin{}
 	_ framework.PostFilterPlugin = &Plugin{}
 	_ framework.ReservePlugin    = &Plugin{}
+
+	_ frameworkext.ConsistencyCheckable = &Plugin{}
 )
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
@@ -97,6 +108,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	}
 	scheSharedInformerFactory := externalversions.NewSharedInformerFactory(client, 0)
 	elasticQuotaInformer := scheSharedInformerFactory.Scheduling().V1alpha1().ElasticQuotas()
+	podGroupInformer := scheSharedInformerFactory.Scheduling().V1alpha1().PodGroups()
 
 	elasticQuota := &Plugin{
 		handle:            handle,
@@ -108,7 +120,12 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		nodeLister:        handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
 		groupQuotaManager: core.NewGroupQuotaManager(pluginArgs.SystemQuotaGroupMax, pluginArgs.DefaultQuotaGroupMax),
 		nodeResourceMap:   make(map[string]struct{}),
+		pgLister:          podGroupInformer.Lister(),
+		rejectedGangs:     make(map[string]struct{}),
+		admittedPods:      make(map[types.UID]struct{}),
+		quotaLabeler:      newQuotaGroupLabeler(pluginArgs),
 	}
+	registerMetrics()
 	if err := core.RunDecorateInit(handle); err != nil {
 		return nil, err
 	}
@@ -122,6 +139,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		UpdateFunc: elasticQuota.OnQuotaUpdate,
 		DeleteFunc: elasticQuota.OnQuotaDelete,
 	})
+	frameworkexthelper.ForceSyncFromInformer(ctx.Done(), scheSharedInformerFactory, podGroupInformer.Informer(), cache.ResourceEventHandlerFuncs{})
 
 	nodeInformer := handle.SharedInformerFactory().Core().V1().Nodes().Informer()
 	frameworkexthelper.ForceSyncFromInformer(ctx.Done(), handle.SharedInformerFactory(), nodeInformer, cache.ResourceEventHandlerFuncs{
@@ -145,6 +163,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 func (g *Plugin) Start() {
 	go wait.Until(g.migrateDefaultQuotaGroupsPod, MigrateDefaultQuotaGroupsPodCycle, nil)
 	klog.Infof("start migrate pod from defaultQuotaGroup")
+	go wait.Until(g.syncQuotaMetrics, quotaMetricsSyncInterval, nil)
 }
 
 func (g *Plugin) NewControllers() ([]frameworkext.Controller, error) {
@@ -158,30 +177,42 @@ func (g *Plugin) Name() string {
 	return Name
 }
 
-func (g *Plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) *framework.Status {
+func (g *Plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "PreFilter", pod, "")
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	quotaName := g.getPodAssociateQuotaName(pod)
 	quotaInfo := g.groupQuotaManager.GetQuotaInfoByName(quotaName)
 	if quotaInfo == nil {
 		return framework.NewStatus(framework.Error, fmt.Sprintf("Could not find the specified ElasticQuota"))
 	}
 	g.snapshotPostFilterState(quotaName, state)
+
+	if status := g.checkGangQuota(pod, quotaName, quotaInfo); !status.IsSuccess() {
+		return status
+	}
+
 	quotaUsed := quotaInfo.GetUsed()
 	quotaRuntime := quotaInfo.GetRuntime()
 
 	pod = core.RunDecoratePod(pod)
-	podRequest, _ := resource.PodRequestsAndLimits(pod)
+	podRequest := util.GetPodEffectiveRequest(pod)
 	newUsed := quotav1.Add(podRequest, quotaUsed)
 
 	if isLessEqual, exceedDimensions := quotav1.LessThanOrEqual(newUsed, quotaRuntime); !isLessEqual {
+		g.quotaRejected(quotaName, exceedDimensions)
 		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Scheduling refused due to insufficient quotas, "+
 			"quotaName: %v, runtime: %v, used: %v, pod's request: %v, exceedDimensions: %v",
 			quotaName, printResourceList(quotaRuntime), printResourceList(quotaUsed), printResourceList(podRequest), exceedDimensions))
 	}
 
 	if *g.pluginArgs.EnableCheckParentQuota {
-		return g.checkQuotaRecursive(quotaName, []string{quotaName}, podRequest)
+		if status := g.checkQuotaRecursive(quotaName, []string{quotaName}, podRequest); !status.IsSuccess() {
+			return status
+		}
 	}
 
+	g.quotaAdmitted(pod, quotaName)
 	return framework.NewStatus(framework.Success, "")
 }
 
@@ -203,7 +234,7 @@ func (g *Plugin) AddPod(ctx context.Context, state *framework.CycleState, podToS
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 	pod := core.RunDecoratePod(podInfoToAdd.Pod)
-	podReq, _ := resource.PodRequestsAndLimits(pod)
+	podReq := util.GetPodEffectiveRequest(pod)
 	quotaInfo.CalculateInfo.Used = quotav1.Add(quotaInfo.CalculateInfo.Used, podReq)
 	return framework.NewStatus(framework.Success, "")
 }
@@ -222,7 +253,7 @@ func (g *Plugin) RemovePod(ctx context.Context, state *framework.CycleState, pod
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 	pod := core.RunDecoratePod(podInfoToRemove.Pod)
-	podReq, _ := resource.PodRequestsAndLimits(pod)
+	podReq := util.GetPodEffectiveRequest(pod)
 	quotaInfo.CalculateInfo.Used = quotav1.SubtractWithNonNegativeResult(quotaInfo.CalculateInfo.Used, podReq)
 	return framework.NewStatus(framework.Success, "")
 }
@@ -242,7 +273,10 @@ func (g *Plugin) PostFilter(ctx context.Context, state *framework.CycleState, po
 	return &framework.PostFilterResult{NominatedNodeName: nnn}, framework.NewStatus(framework.Success)
 }
 
-func (g *Plugin) Reserve(ctx context.Context, state *framework.CycleState, p *corev1.Pod, nodeName string) *framework.Status {
+func (g *Plugin) Reserve(ctx context.Context, state *framework.CycleState, p *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "Reserve", p, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	quotaName := g.getPodAssociateQuotaName(p)
 	g.groupQuotaManager.ReservePod(quotaName, p)
 	return framework.NewStatus(framework.Success, "")
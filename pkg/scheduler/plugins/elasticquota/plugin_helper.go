@@ -175,6 +175,7 @@ func (g *Plugin) checkQuotaRecursive(curQuotaName string, quotaNameTopo []string
 	quotaRuntime := quotaInfo.GetRuntime()
 	newUsed := quotav1.Add(podRequest, quotaUsed)
 	if isLessEqual, exceedDimensions := quotav1.LessThanOrEqual(newUsed, quotaRuntime); !isLessEqual {
+		g.quotaRejected(curQuotaName, exceedDimensions)
 		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Scheduling refused due to insufficient quotas, "+
 			"quotaNameTopo: %v, runtime: %v, used: %v, pod's request: %v, exceedDimensions: %v", quotaNameTopo,
 			printResourceList(quotaRuntime), printResourceList(quotaUsed), printResourceList(podRequest), exceedDimensions))
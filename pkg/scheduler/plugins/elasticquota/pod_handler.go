@@ -51,6 +51,9 @@ func (g *Plugin) OnPodUpdate(oldObj, newObj interface{}) {
 	oldQuotaName := g.getPodAssociateQuotaName(oldPod)
 	newQuotaName := g.getPodAssociateQuotaName(newPod)
 	g.groupQuotaManager.OnPodUpdate(newQuotaName, oldQuotaName, newPod, oldPod)
+	if newPod.Spec.NodeName != "" {
+		g.forgetAdmittedPod(newPod)
+	}
 	klog.V(5).Infof("OnPodUpdateFunc %v.%v update success, quotaName:%v", newPod.Namespace, newPod.Name, newQuotaName)
 }
 
@@ -63,5 +66,6 @@ func (g *Plugin) OnPodDelete(obj interface{}) {
 	pod = core.RunDecoratePod(pod)
 	quotaName := g.getPodAssociateQuotaName(pod)
 	g.groupQuotaManager.OnPodDelete(quotaName, pod)
+	g.forgetAdmittedPod(pod)
 	klog.V(5).Infof("OnPodDeleteFunc %v.%v delete success", pod.Namespace, pod.Name)
 }
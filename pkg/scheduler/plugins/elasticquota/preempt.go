@@ -32,13 +32,13 @@ import (
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
-	"k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/features"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
 	"k8s.io/kubernetes/pkg/scheduler/util"
 
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	koordutil "github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 func (g *Plugin) preempt(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (string, *framework.Status) {
@@ -74,7 +74,8 @@ func (g *Plugin) preempt(ctx context.Context, state *framework.CycleState, pod *
 		return "", status
 	}
 
-	// 4) Find the best candidate.
+	// 4) Expand the victims to the whole gangs of the Strict mode, and find the best candidate.
+	candidates = g.expandGangVictims(pod, candidates)
 	bestCandidate := defaultpreemption.SelectCandidate(candidates)
 	if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
 		return "", nil
@@ -84,6 +85,7 @@ func (g *Plugin) preempt(ctx context.Context, state *framework.CycleState, pod *
 	if status := defaultpreemption.PrepareCandidate(bestCandidate, g.handle, cs, pod, g.Name()); !status.IsSuccess() {
 		return "", status
 	}
+	g.gangsPreempted(pod, bestCandidate)
 
 	return bestCandidate.Name(), nil
 }
@@ -277,7 +279,7 @@ func (g *Plugin) selectVictimsOnNode(
 	postFilterState, _ := getPostFilterState(state)
 	quotaInfo := postFilterState.quotaInfo
 	pod = core.RunDecoratePod(pod)
-	podReq, _ := resource.PodRequestsAndLimits(pod)
+	podReq := koordutil.GetPodEffectiveRequest(pod)
 
 	reprievePod := func(pi *framework.PodInfo) (bool, error) {
 		if err := addPod(pi); err != nil {
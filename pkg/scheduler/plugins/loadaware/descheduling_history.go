@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// deschedulingHistory tells the nodes the workloads were recently descheduled from,
+// by the history recorded by the descheduler on the ReplicaSets and the StatefulSets.
+type deschedulingHistory struct {
+	ttl               time.Duration
+	replicaSetLister  appslisters.ReplicaSetLister
+	statefulSetLister appslisters.StatefulSetLister
+}
+
+// recentlyDescheduledFrom returns whether the pods of the workload of the pod were descheduled from the node in the TTL.
+func (h *deschedulingHistory) recentlyDescheduledFrom(pod *corev1.Pod, nodeName string, now time.Time) bool {
+	if h == nil {
+		return false
+	}
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(controllerRef.APIVersion)
+	if err != nil || gv.Group != appsv1.GroupName {
+		return false
+	}
+
+	var workload metav1.Object
+	switch controllerRef.Kind {
+	case "ReplicaSet":
+		workload, err = h.replicaSetLister.ReplicaSets(pod.Namespace).Get(controllerRef.Name)
+	case "StatefulSet":
+		workload, err = h.statefulSetLister.StatefulSets(pod.Namespace).Get(controllerRef.Name)
+	default:
+		return false
+	}
+	if err != nil || workload.GetUID() != controllerRef.UID {
+		return false
+	}
+
+	history, err := extension.GetDeschedulingHistory(workload.GetAnnotations())
+	if err != nil {
+		klog.V(5).InfoS("failed to parse the descheduling history", "kind", controllerRef.Kind, "workload", klog.KObj(workload), "err", err)
+		return false
+	}
+	for _, record := range history {
+		if record.NodeName == nodeName && now.Before(record.Time.Add(h.ttl)) {
+			return true
+		}
+	}
+	return false
+}
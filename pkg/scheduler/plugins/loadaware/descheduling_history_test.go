@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+func newDeschedulingHistoryAnnotations(t *testing.T, records ...extension.DeschedulingRecord) map[string]string {
+	data, err := json.Marshal(records)
+	assert.NoError(t, err)
+	return map[string]string{extension.AnnotationDeschedulingHistory: string(data)}
+}
+
+func newDeschedulingHistoryTestPod(name, kind, ownerName, ownerUID string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: kind, Name: ownerName, UID: types.UID(ownerUID), Controller: pointer.Bool(true)},
+			},
+		},
+	}
+}
+
+func newDeschedulingHistoryTestPlugin(t *testing.T, ttl *metav1.Duration, objects ...runtime.Object) (*Plugin, *koordfake.Clientset, []*corev1.Node) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var args config.LoadAwareSchedulingArgs
+	assert.NoError(t, v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &args, nil))
+	args.DeschedulingHistoryTTL = ttl
+
+	koordClientSet := koordfake.NewSimpleClientset()
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+	extendHandle, _ := frameworkext.NewExtendedHandle(
+		frameworkext.WithKoordinatorClientSet(koordClientSet),
+		frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+	)
+	proxyNew := frameworkext.PluginFactoryProxy(extendHandle, New)
+
+	cs := kubefake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	var nodes []*corev1.Node
+	for _, nodeName := range []string{"test-node-1", "test-node-2"} {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		})
+	}
+	fh, err := schedulertesting.NewFramework(
+		[]schedulertesting.RegisterPluginFunc{
+			schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"koord-scheduler",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)),
+	)
+	assert.NoError(t, err)
+
+	p, err := proxyNew(&args, fh)
+	assert.NoError(t, err)
+	informerFactory.Start(context.TODO().Done())
+	informerFactory.WaitForCacheSync(context.TODO().Done())
+	koordSharedInformerFactory.Start(context.TODO().Done())
+	koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+	return p.(*Plugin), koordClientSet, nodes
+}
+
+func TestDeschedulingHistory(t *testing.T) {
+	now := time.Now()
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-rs",
+			UID:       "rs-uid",
+			Annotations: newDeschedulingHistoryAnnotations(t,
+				extension.DeschedulingRecord{NodeName: "test-node-1", Time: metav1.NewTime(now.Add(-time.Minute))},
+				extension.DeschedulingRecord{NodeName: "test-node-2", Time: metav1.NewTime(now.Add(-time.Hour))},
+			),
+		},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-sts",
+			UID:       "sts-uid",
+			Annotations: newDeschedulingHistoryAnnotations(t,
+				extension.DeschedulingRecord{NodeName: "test-node-2", Time: metav1.NewTime(now.Add(-time.Minute))},
+			),
+		},
+	}
+	p, _, _ := newDeschedulingHistoryTestPlugin(t, &metav1.Duration{Duration: 10 * time.Minute}, replicaSet, statefulSet)
+	assert.NotNil(t, p.deschedulingHistory)
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		nodeName string
+		want     bool
+	}{
+		{
+			name:     "descheduled from the node in the TTL",
+			pod:      newDeschedulingHistoryTestPod("rs-pod", "ReplicaSet", "test-rs", "rs-uid"),
+			nodeName: "test-node-1",
+			want:     true,
+		},
+		{
+			name:     "the record expired",
+			pod:      newDeschedulingHistoryTestPod("rs-pod", "ReplicaSet", "test-rs", "rs-uid"),
+			nodeName: "test-node-2",
+			want:     false,
+		},
+		{
+			name:     "the record of the StatefulSet",
+			pod:      newDeschedulingHistoryTestPod("sts-pod", "StatefulSet", "test-sts", "sts-uid"),
+			nodeName: "test-node-2",
+			want:     true,
+		},
+		{
+			name:     "not descheduled from the node",
+			pod:      newDeschedulingHistoryTestPod("sts-pod", "StatefulSet", "test-sts", "sts-uid"),
+			nodeName: "test-node-1",
+			want:     false,
+		},
+		{
+			name:     "the workload recreated",
+			pod:      newDeschedulingHistoryTestPod("rs-pod", "ReplicaSet", "test-rs", "old-rs-uid"),
+			nodeName: "test-node-1",
+			want:     false,
+		},
+		{
+			name:     "the workload not found",
+			pod:      newDeschedulingHistoryTestPod("rs-pod", "ReplicaSet", "other-rs", "other-rs-uid"),
+			nodeName: "test-node-1",
+			want:     false,
+		},
+		{
+			name:     "no controller",
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bare-pod"}},
+			nodeName: "test-node-1",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.deschedulingHistory.recentlyDescheduledFrom(tt.pod, tt.nodeName, now))
+		})
+	}
+
+	// the record of test-node-1 expires as well
+	assert.False(t, p.deschedulingHistory.recentlyDescheduledFrom(tests[0].pod, "test-node-1", now.Add(10*time.Minute)))
+}
+
+func TestScoreDeprioritizesDescheduledNode(t *testing.T) {
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-rs",
+			UID:       "rs-uid",
+			Annotations: newDeschedulingHistoryAnnotations(t,
+				extension.DeschedulingRecord{NodeName: "test-node-1", Time: metav1.Now()},
+			),
+		},
+	}
+	pod := newDeschedulingHistoryTestPod("rs-pod", "ReplicaSet", "test-rs", "rs-uid")
+
+	for _, ttl := range []*metav1.Duration{nil, {Duration: 10 * time.Minute}} {
+		p, koordClientSet, nodes := newDeschedulingHistoryTestPlugin(t, ttl, replicaSet)
+		assert.Equal(t, ttl == nil, p.deschedulingHistory == nil)
+		for _, node := range nodes {
+			_, err := koordClientSet.SloV1alpha1().NodeMetrics().Create(context.TODO(), &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{Name: node.Name},
+				Spec: slov1alpha1.NodeMetricSpec{
+					CollectPolicy: &slov1alpha1.NodeMetricCollectPolicy{ReportIntervalSeconds: pointer.Int64(60)},
+				},
+				Status: slov1alpha1.NodeMetricStatus{UpdateTime: &metav1.Time{Time: time.Now()}},
+			}, metav1.CreateOptions{})
+			assert.NoError(t, err)
+		}
+		assert.Eventually(t, func() bool {
+			metrics, err := p.nodeMetricLister.List(labels.Everything())
+			return err == nil && len(metrics) == len(nodes)
+		}, 5*time.Second, 10*time.Millisecond)
+
+		otherScore, status := p.Score(context.TODO(), framework.NewCycleState(), pod, "test-node-2")
+		assert.True(t, status.IsSuccess())
+		assert.Greater(t, otherScore, int64(0))
+		score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, "test-node-1")
+		assert.True(t, status.IsSuccess())
+		if ttl == nil {
+			assert.Equal(t, otherScore, score, "the history is ignored if not enabled")
+		} else {
+			assert.Equal(t, int64(0), score)
+		}
+	}
+}
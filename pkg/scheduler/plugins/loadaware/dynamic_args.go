@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware/estimator"
+)
+
+var _ frameworkext.DynamicArgs = &Plugin{}
+
+// DynamicArgs are the fields of LoadAwareSchedulingArgs which can be reloaded without restarting the scheduler.
+// Each field set replaces the one of the args at the start, and the unset fields keep the args at the start.
+type DynamicArgs struct {
+	ResourceWeights         map[corev1.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	UsageThresholds         map[corev1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+	ProdUsageThresholds     map[corev1.ResourceName]int64 `json:"prodUsageThresholds,omitempty"`
+	ScoreAccordingProdUsage *bool                         `json:"scoreAccordingProdUsage,omitempty"`
+	EstimatedScalingFactors map[corev1.ResourceName]int64 `json:"estimatedScalingFactors,omitempty"`
+}
+
+// dynamicArgsState is the decoded dynamic args merged into the args at the start, with the estimator built from them.
+type dynamicArgsState struct {
+	args      *config.LoadAwareSchedulingArgs
+	estimator estimator.Estimator
+}
+
+func (p *Plugin) DecodeDynamicArgs(data []byte) (interface{}, error) {
+	dynamicArgs := &DynamicArgs{}
+	if err := yaml.UnmarshalStrict(data, dynamicArgs); err != nil {
+		return nil, err
+	}
+
+	args := p.initialArgs.DeepCopy()
+	if dynamicArgs.ResourceWeights != nil {
+		args.ResourceWeights = dynamicArgs.ResourceWeights
+	}
+	if dynamicArgs.UsageThresholds != nil {
+		args.UsageThresholds = dynamicArgs.UsageThresholds
+	}
+	if dynamicArgs.ProdUsageThresholds != nil {
+		args.ProdUsageThresholds = dynamicArgs.ProdUsageThresholds
+	}
+	if dynamicArgs.ScoreAccordingProdUsage != nil {
+		args.ScoreAccordingProdUsage = *dynamicArgs.ScoreAccordingProdUsage
+	}
+	if dynamicArgs.EstimatedScalingFactors != nil {
+		args.EstimatedScalingFactors = dynamicArgs.EstimatedScalingFactors
+	}
+	if err := validation.ValidateLoadAwareSchedulingArgs(args); err != nil {
+		return nil, err
+	}
+	podEstimator, err := estimator.NewEstimator(args, p.handle)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamicArgsState{args: args, estimator: podEstimator}, nil
+}
+
+func (p *Plugin) UpdateDynamicArgs(args interface{}) {
+	state, ok := args.(*dynamicArgsState)
+	if !ok {
+		return
+	}
+	p.argsLock.Lock()
+	defer p.argsLock.Unlock()
+	p.args = state.args
+	p.estimator = state.estimator
+}
+
+// getArgs returns the current args and the estimator built from them.
+func (p *Plugin) getArgs() (*config.LoadAwareSchedulingArgs, estimator.Estimator) {
+	p.argsLock.RLock()
+	defer p.argsLock.RUnlock()
+	return p.args, p.estimator
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+func TestDynamicArgsAffectScore(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+
+	koordClientSet := koordfake.NewSimpleClientset()
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+	extendHandle, _ := frameworkext.NewExtendedHandle(
+		frameworkext.WithKoordinatorClientSet(koordClientSet),
+		frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+	)
+	proxyNew := frameworkext.PluginFactoryProxy(extendHandle, New)
+
+	cs := kubefake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		},
+	}
+	fh, err := schedulertesting.NewFramework(
+		[]schedulertesting.RegisterPluginFunc{
+			schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"koord-scheduler",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)),
+	)
+	assert.NoError(t, err)
+
+	// the cpu is heavily used while the memory is almost free
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"},
+		Spec: slov1alpha1.NodeMetricSpec{
+			CollectPolicy: &slov1alpha1.NodeMetricCollectPolicy{ReportIntervalSeconds: pointer.Int64(60)},
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{Time: time.Now()},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("72"),
+						corev1.ResourceMemory: resource.MustParse("16Gi"),
+					},
+				},
+			},
+		},
+	}
+	_, err = koordClientSet.SloV1alpha1().NodeMetrics().Create(context.TODO(), nodeMetric, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	p, err := proxyNew(&loadAwareSchedulingArgs, fh)
+	assert.NoError(t, err)
+	informerFactory.Start(context.TODO().Done())
+	informerFactory.WaitForCacheSync(context.TODO().Done())
+	koordSharedInformerFactory.Start(context.TODO().Done())
+	koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-1"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test-container",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	score := func() int64 {
+		s, status := p.(framework.ScorePlugin).Score(context.TODO(), framework.NewCycleState(), pod, "test-node-1")
+		assert.True(t, status.IsSuccess())
+		return s
+	}
+	initialScore := score()
+
+	watcher := frameworkext.NewDynamicArgsWatcher()
+	watcher.Register(p)
+	recorder := events.NewFakeRecorder(10)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "koordinator-system", Name: "koord-scheduler-dynamic-args"},
+		Data: map[string]string{
+			Name: `
+resourceWeights:
+  cpu: 10
+  memory: 1
+`,
+		},
+	}
+
+	// weighting the heavily used cpu lowers the score in the subsequent Score calls
+	watcher.Sync(configMap, recorder)
+	assert.Contains(t, <-recorder.Events, frameworkext.ReasonDynamicArgsApplied)
+	weightedScore := score()
+	assert.Less(t, weightedScore, initialScore)
+
+	// the non-dynamic fields are rejected and the current args are kept
+	configMap.Data[Name] = `
+estimator: default
+resourceWeights:
+  cpu: 1
+  memory: 10
+`
+	watcher.Sync(configMap, recorder)
+	assert.Contains(t, <-recorder.Events, frameworkext.ReasonDynamicArgsRejected)
+	assert.Equal(t, weightedScore, score())
+
+	// the invalid values are rejected
+	configMap.Data[Name] = `
+resourceWeights:
+  cpu: -1
+`
+	watcher.Sync(configMap, recorder)
+	assert.Contains(t, <-recorder.Events, frameworkext.ReasonDynamicArgsRejected)
+	assert.Equal(t, weightedScore, score())
+
+	// the fields unset fall back to the args at the start
+	configMap.Data[Name] = `
+scoreAccordingProdUsage: false
+`
+	watcher.Sync(configMap, recorder)
+	assert.Contains(t, <-recorder.Events, frameworkext.ReasonDynamicArgsApplied)
+	assert.Equal(t, initialScore, score())
+}
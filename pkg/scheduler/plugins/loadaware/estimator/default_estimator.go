@@ -26,6 +26,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 const (
@@ -58,7 +59,8 @@ func (e *DefaultEstimator) Estimate(pod *corev1.Pod) (map[corev1.ResourceName]in
 }
 
 func estimatedPodUsed(pod *corev1.Pod, resourceWeights map[corev1.ResourceName]int64, scalingFactors map[corev1.ResourceName]int64) map[corev1.ResourceName]int64 {
-	requests, limits := resourceapi.PodRequestsAndLimits(pod)
+	requests := util.GetPodEffectiveRequest(pod)
+	_, limits := resourceapi.PodRequestsAndLimits(pod)
 	estimatedUsed := make(map[corev1.ResourceName]int64)
 	priorityClass := extension.GetPriorityClass(pod)
 	for resourceName := range resourceWeights {
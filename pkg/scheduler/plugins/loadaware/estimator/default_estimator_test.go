@@ -161,6 +161,36 @@ func TestDefaultEstimator(t *testing.T) {
 				corev1.ResourceMemory: 8589934592, // 5.6Gi
 			},
 		},
+		{
+			name: "estimate pod with overhead",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("4"),
+									corev1.ResourceMemory: resource.MustParse("8Gi"),
+								},
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("4"),
+									corev1.ResourceMemory: resource.MustParse("8Gi"),
+								},
+							},
+						},
+					},
+					Overhead: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+				},
+			},
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    3570,       // 85% of 4200m
+				corev1.ResourceMemory: 6200859034, // 70% of 8448Mi
+			},
+		},
 	}
 
 	for _, tt := range tests {
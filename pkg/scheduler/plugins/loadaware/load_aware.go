@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -62,12 +63,18 @@ var (
 )
 
 type Plugin struct {
-	handle           framework.Handle
+	handle framework.Handle
+	// initialArgs is the args at the start, the dynamic args override the fields of it.
+	initialArgs *config.LoadAwareSchedulingArgs
+	// argsLock guards the args and the estimator swapped by the dynamic args.
+	argsLock         sync.RWMutex
 	args             *config.LoadAwareSchedulingArgs
+	estimator        estimator.Estimator
 	podLister        corev1listers.PodLister
 	nodeMetricLister slolisters.NodeMetricLister
-	estimator        estimator.Estimator
 	podAssignCache   *podAssignCache
+	// deschedulingHistory is nil if the nodes the workloads were descheduled from are not deprioritized.
+	deschedulingHistory *deschedulingHistory
 }
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
@@ -96,19 +103,37 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		return nil, err
 	}
 
+	var history *deschedulingHistory
+	if pluginArgs.DeschedulingHistoryTTL != nil && pluginArgs.DeschedulingHistoryTTL.Duration > 0 {
+		history = &deschedulingHistory{
+			ttl:               pluginArgs.DeschedulingHistoryTTL.Duration,
+			replicaSetLister:  frameworkExtender.SharedInformerFactory().Apps().V1().ReplicaSets().Lister(),
+			statefulSetLister: frameworkExtender.SharedInformerFactory().Apps().V1().StatefulSets().Lister(),
+		}
+	}
+
 	return &Plugin{
-		handle:           handle,
-		args:             pluginArgs,
-		podLister:        podLister,
-		nodeMetricLister: nodeMetricLister,
-		estimator:        estimator,
-		podAssignCache:   assignCache,
+		handle:              handle,
+		initialArgs:         pluginArgs,
+		args:                pluginArgs,
+		podLister:           podLister,
+		nodeMetricLister:    nodeMetricLister,
+		estimator:           estimator,
+		podAssignCache:      assignCache,
+		deschedulingHistory: history,
 	}, nil
 }
 
 func (p *Plugin) Name() string { return Name }
 
-func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	var nodeName string
+	if nodeInfo.Node() != nil {
+		nodeName = nodeInfo.Node().Name
+	}
+	_, span := frameworkext.StartSpan(ctx, Name, "Filter", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	node := nodeInfo.Node()
 	if node == nil {
 		return framework.NewStatus(framework.Error, "node not found")
@@ -125,13 +150,14 @@ func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *c
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	if p.args.FilterExpiredNodeMetrics != nil && *p.args.FilterExpiredNodeMetrics && p.args.NodeMetricExpirationSeconds != nil {
-		if isNodeMetricExpired(nodeMetric, *p.args.NodeMetricExpirationSeconds) {
+	args, _ := p.getArgs()
+	if args.FilterExpiredNodeMetrics != nil && *args.FilterExpiredNodeMetrics && args.NodeMetricExpirationSeconds != nil {
+		if isNodeMetricExpired(nodeMetric, *args.NodeMetricExpirationSeconds) {
 			return framework.NewStatus(framework.Unschedulable, ErrReasonNodeMetricExpired)
 		}
 	}
 
-	filterProfile := generateUsageThresholdsFilterProfile(node, p.args)
+	filterProfile := generateUsageThresholdsFilterProfile(node, args)
 	if len(filterProfile.ProdUsageThresholds) > 0 && extension.GetPriorityClass(pod) == extension.PriorityProd {
 		status := p.filterProdUsage(node, nodeMetric, filterProfile.ProdUsageThresholds)
 		if !status.IsSuccess() {
@@ -232,7 +258,10 @@ func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
 	return nil
 }
 
-func (p *Plugin) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+func (p *Plugin) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "Reserve", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	p.podAssignCache.assign(nodeName, pod)
 	return nil
 }
@@ -242,6 +271,10 @@ func (p *Plugin) Unreserve(ctx context.Context, state *framework.CycleState, pod
 }
 
 func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	// the pods of the workload were just moved off the node, prefer the other nodes
+	if p.deschedulingHistory.recentlyDescheduledFrom(pod, nodeName, time.Now()) {
+		return 0, nil
+	}
 	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
 	if err != nil {
 		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
@@ -259,18 +292,19 @@ func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *co
 		}
 		return 0, framework.NewStatus(framework.Error, err.Error())
 	}
-	if p.args.NodeMetricExpirationSeconds != nil && isNodeMetricExpired(nodeMetric, *p.args.NodeMetricExpirationSeconds) {
+	args, podEstimator := p.getArgs()
+	if args.NodeMetricExpirationSeconds != nil && isNodeMetricExpired(nodeMetric, *args.NodeMetricExpirationSeconds) {
 		return 0, nil
 	}
 
-	prodPod := extension.GetPriorityClass(pod) == extension.PriorityProd && p.args.ScoreAccordingProdUsage
+	prodPod := extension.GetPriorityClass(pod) == extension.PriorityProd && args.ScoreAccordingProdUsage
 	podMetrics := buildPodMetricMap(p.podLister, nodeMetric, prodPod)
 
-	estimatedUsed, err := p.estimator.Estimate(pod)
+	estimatedUsed, err := podEstimator.Estimate(pod)
 	if err != nil {
 		return 0, nil
 	}
-	assignedPodEstimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(nodeName, nodeMetric, podMetrics, prodPod)
+	assignedPodEstimatedUsed, estimatedPods := p.estimatedAssignedPodUsed(args, podEstimator, nodeName, nodeMetric, podMetrics, prodPod)
 	for resourceName, value := range assignedPodEstimatedUsed {
 		estimatedUsed[resourceName] += value
 	}
@@ -282,8 +316,8 @@ func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *co
 	} else {
 		if nodeMetric.Status.NodeMetric != nil {
 			var nodeUsage *slov1alpha1.ResourceMap
-			if scoreWithAggregation(p.args.Aggregated) {
-				nodeUsage = getTargetAggregatedUsage(nodeMetric, &p.args.Aggregated.ScoreAggregatedDuration, p.args.Aggregated.ScoreAggregationType)
+			if scoreWithAggregation(args.Aggregated) {
+				nodeUsage = getTargetAggregatedUsage(nodeMetric, &args.Aggregated.ScoreAggregatedDuration, args.Aggregated.ScoreAggregationType)
 			} else {
 				nodeUsage = &nodeMetric.Status.NodeMetric.NodeUsage
 			}
@@ -301,11 +335,11 @@ func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *co
 		}
 	}
 
-	score := loadAwareSchedulingScorer(p.args.ResourceWeights, estimatedUsed, node.Status.Allocatable)
+	score := loadAwareSchedulingScorer(args.ResourceWeights, estimatedUsed, node.Status.Allocatable)
 	return score, nil
 }
 
-func (p *Plugin) estimatedAssignedPodUsed(nodeName string, nodeMetric *slov1alpha1.NodeMetric, podMetrics map[string]corev1.ResourceList, filterProdPod bool) (map[corev1.ResourceName]int64, sets.String) {
+func (p *Plugin) estimatedAssignedPodUsed(args *config.LoadAwareSchedulingArgs, podEstimator estimator.Estimator, nodeName string, nodeMetric *slov1alpha1.NodeMetric, podMetrics map[string]corev1.ResourceList, filterProdPod bool) (map[corev1.ResourceName]int64, sets.String) {
 	estimatedUsed := make(map[corev1.ResourceName]int64)
 	estimatedPods := sets.NewString()
 	var nodeMetricUpdateTime time.Time
@@ -325,9 +359,9 @@ func (p *Plugin) estimatedAssignedPodUsed(nodeName string, nodeMetric *slov1alph
 		if len(podUsage) == 0 ||
 			missedLatestUpdateTime(assignInfo.timestamp, nodeMetricUpdateTime) ||
 			stillInTheReportInterval(assignInfo.timestamp, nodeMetricUpdateTime, nodeMetricReportInterval) ||
-			(scoreWithAggregation(p.args.Aggregated) &&
-				getTargetAggregatedUsage(nodeMetric, &p.args.Aggregated.ScoreAggregatedDuration, p.args.Aggregated.ScoreAggregationType) == nil) {
-			estimated, err := p.estimator.Estimate(assignInfo.pod)
+			(scoreWithAggregation(args.Aggregated) &&
+				getTargetAggregatedUsage(nodeMetric, &args.Aggregated.ScoreAggregatedDuration, args.Aggregated.ScoreAggregationType) == nil) {
+			estimated, err := podEstimator.Estimate(assignInfo.pod)
 			if err != nil {
 				continue
 			}
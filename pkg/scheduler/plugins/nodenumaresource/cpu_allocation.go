@@ -54,7 +54,11 @@ func (n *cpuAllocation) addCPUs(cpuTopology *CPUTopology, podUID types.UID, cpus
 	for _, cpuID := range cpuset.ToSliceNoSort() {
 		cpuInfo, ok := n.allocatedCPUs[cpuID]
 		if !ok {
-			cpuInfo = cpuTopology.CPUDetails[cpuID]
+			cpuInfo, ok = cpuTopology.CPUDetails[cpuID]
+			if !ok {
+				// the CPU is offline or unknown, its topology is filled when it comes back
+				cpuInfo = CPUInfo{CPUID: cpuID}
+			}
 		}
 		cpuInfo.ExclusivePolicy = exclusivePolicy
 		cpuInfo.RefCount++
@@ -84,10 +88,33 @@ func (n *cpuAllocation) releaseCPUs(podUID types.UID) {
 }
 
 func (n *cpuAllocation) getAvailableCPUs(cpuTopology *CPUTopology, maxRefCount int, reservedCPUs cpuset.CPUSet) (availableCPUs cpuset.CPUSet, allocateInfo CPUDetails) {
-	allocateInfo = n.allocatedCPUs.Clone()
+	// The allocated CPUs missing in the current topology (e.g. offline) are excluded,
+	// and they are reintegrated with their allocations when they come back.
+	allocateInfo = NewCPUDetails()
+	for cpuID, allocatedInfo := range n.allocatedCPUs {
+		cpuInfo, ok := cpuTopology.CPUDetails[cpuID]
+		if !ok {
+			continue
+		}
+		cpuInfo.RefCount = allocatedInfo.RefCount
+		cpuInfo.ExclusivePolicy = allocatedInfo.ExclusivePolicy
+		allocateInfo[cpuID] = cpuInfo
+	}
 	allocated := allocateInfo.CPUs().Filter(func(cpuID int) bool {
 		return allocateInfo[cpuID].RefCount >= maxRefCount
 	})
 	availableCPUs = cpuTopology.CPUDetails.CPUs().Difference(allocated).Difference(reservedCPUs)
 	return
 }
+
+// getDegradedPods returns the pods whose allocated CPUs are missing in the current topology, and the missing CPUs.
+func (n *cpuAllocation) getDegradedPods(cpuTopology *CPUTopology) map[types.UID]cpuset.CPUSet {
+	onlineCPUs := cpuTopology.CPUDetails.CPUs()
+	degradedPods := map[types.UID]cpuset.CPUSet{}
+	for podUID, cpus := range n.allocatedPods {
+		if offlineCPUs := cpus.Difference(onlineCPUs); !offlineCPUs.IsEmpty() {
+			degradedPods[podUID] = offlineCPUs
+		}
+	}
+	return degradedPods
+}
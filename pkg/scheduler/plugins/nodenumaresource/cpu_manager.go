@@ -33,11 +33,13 @@ import (
 )
 
 type CPUManager interface {
+	// Allocate allocates the CPUs only in the NUMA nodes reported by the kernel if numaNodes is not empty.
 	Allocate(
 		node *corev1.Node,
 		numCPUsNeeded int,
 		cpuBindPolicy schedulingconfig.CPUBindPolicy,
-		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy) (cpuset.CPUSet, error)
+		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy,
+		numaNodes []int) (cpuset.CPUSet, error)
 
 	UpdateAllocatedCPUSet(nodeName string, podUID types.UID, cpuset cpuset.CPUSet, cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy)
 
@@ -50,6 +52,9 @@ type CPUManager interface {
 		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy) int64
 
 	GetAvailableCPUs(nodeName string) (availableCPUs cpuset.CPUSet, allocated CPUDetails, err error)
+
+	// GetDegradedAllocations returns the pods whose allocated CPUs are offline in the current topology of the node.
+	GetDegradedAllocations(nodeName string) (degraded map[types.UID]cpuset.CPUSet, err error)
 }
 
 type cpuManagerImpl struct {
@@ -112,6 +117,7 @@ func (c *cpuManagerImpl) Allocate(
 	numCPUsNeeded int,
 	cpuBindPolicy schedulingconfig.CPUBindPolicy,
 	cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy,
+	numaNodes []int,
 ) (cpuset.CPUSet, error) {
 	result := cpuset.CPUSet{}
 	// The Pod requires the CPU to be allocated according to CPUBindPolicy,
@@ -132,6 +138,9 @@ func (c *cpuManagerImpl) Allocate(
 	defer allocation.lock.Unlock()
 
 	availableCPUs, allocated := allocation.getAvailableCPUs(cpuTopologyOptions.CPUTopology, cpuTopologyOptions.MaxRefCount, reservedCPUs)
+	if len(numaNodes) > 0 {
+		availableCPUs = availableCPUs.Intersection(cpuTopologyOptions.CPUTopology.CPUDetails.CPUsInKernelNUMANodes(numaNodes...))
+	}
 	numaAllocateStrategy := c.getNUMAAllocateStrategy(node)
 	result, err := takeCPUs(
 		cpuTopologyOptions.CPUTopology,
@@ -292,3 +301,18 @@ func (c *cpuManagerImpl) GetAvailableCPUs(nodeName string) (availableCPUs cpuset
 	availableCPUs, allocated = allocation.getAvailableCPUs(cpuTopologyOptions.CPUTopology, cpuTopologyOptions.MaxRefCount, cpuTopologyOptions.ReservedCPUs)
 	return availableCPUs, allocated, nil
 }
+
+func (c *cpuManagerImpl) GetDegradedAllocations(nodeName string) (map[types.UID]cpuset.CPUSet, error) {
+	cpuTopologyOptions := c.topologyManager.GetCPUTopologyOptions(nodeName)
+	if cpuTopologyOptions.CPUTopology == nil {
+		return nil, errors.New(ErrNotFoundCPUTopology)
+	}
+	if !cpuTopologyOptions.CPUTopology.IsValid() {
+		return nil, fmt.Errorf("cpuTopology is invalid")
+	}
+
+	allocation := c.getOrCreateAllocation(nodeName)
+	allocation.lock.Lock()
+	defer allocation.lock.Unlock()
+	return allocation.getDegradedPods(cpuTopologyOptions.CPUTopology), nil
+}
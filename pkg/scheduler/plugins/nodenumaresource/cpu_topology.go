@@ -255,6 +255,20 @@ func (d CPUDetails) CPUsInNUMANodes(ids ...int) cpuset.CPUSet {
 	return b.Result()
 }
 
+// CPUsInKernelNUMANodes returns the logical CPU IDs associated with the given NUMANode IDs reported by the kernel,
+// such as the NUMA nodes of the devices, which are not combined with the socket IDs like the NodeID in this CPUDetails.
+func (d CPUDetails) CPUsInKernelNUMANodes(ids ...int) cpuset.CPUSet {
+	b := cpuset.NewCPUSetBuilder()
+	for _, id := range ids {
+		for cpu, info := range d {
+			if info.NodeID&(1<<16-1) == id {
+				b.Add(cpu)
+			}
+		}
+	}
+	return b.Result()
+}
+
 // CPUsInCores returns the logical CPU IDs associated with the given core IDs in this CPUDetails.
 func (d CPUDetails) CPUsInCores(ids ...int) cpuset.CPUSet {
 	b := cpuset.NewCPUSetBuilder()
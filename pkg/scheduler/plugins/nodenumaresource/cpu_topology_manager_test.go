@@ -19,15 +19,20 @@ package nodenumaresource
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	nrtv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/events"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
 
@@ -91,7 +96,7 @@ func TestCPUTopologyManager(t *testing.T) {
 		Handle:    suit.Handle,
 		Clientset: suit.NRTClientset,
 	}
-	err = registerNodeResourceTopologyEventHandler(extendHandle, topologyManager)
+	err = registerNodeResourceTopologyEventHandler(extendHandle, topologyManager, nil)
 	assert.NoError(t, err)
 
 	suit.start()
@@ -127,3 +132,99 @@ func TestCPUTopologyManager(t *testing.T) {
 	cpuTopologyOptions = topologyManager.GetCPUTopologyOptions(nodeName)
 	assert.Equal(t, CPUTopologyOptions{}, cpuTopologyOptions)
 }
+
+func newNodeResourceTopologyForTest(t *testing.T, nodeName string, cpuTopology *CPUTopology, offlineCPUs cpuset.CPUSet) *nrtv1alpha1.NodeResourceTopology {
+	externalCPUTopology := &extension.CPUTopology{}
+	for _, v := range cpuTopology.CPUDetails {
+		if offlineCPUs.Contains(v.CPUID) {
+			continue
+		}
+		externalCPUTopology.Detail = append(externalCPUTopology.Detail, extension.CPUInfo{
+			ID:     int32(v.CPUID),
+			Core:   int32(v.CoreID & 0xffff),
+			Socket: int32(v.SocketID),
+			Node:   int32(v.NodeID & 0xffff),
+		})
+	}
+	data, err := json.Marshal(externalCPUTopology)
+	assert.NoError(t, err)
+	return &nrtv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+			Annotations: map[string]string{
+				extension.AnnotationNodeCPUTopology: string(data),
+			},
+		},
+	}
+}
+
+func TestCPUTopologyCPUsOfflineAndOnline(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	nodeName := "test-node-1"
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	cpuTopology := buildCPUTopologyForTest(1, 1, 4, 2)
+	eventRecorder := events.NewFakeRecorder(10)
+	topologyManager := NewCPUTopologyManager()
+	cpuManager := NewCPUManager(suit.Handle, schedulingconfig.NUMAMostAllocated, topologyManager)
+	handler := &nodeResourceTopologyEventHandler{
+		topologyManager: topologyManager,
+		cpuManager:      cpuManager,
+		eventRecorder:   eventRecorder,
+	}
+
+	onlineTopology := newNodeResourceTopologyForTest(t, nodeName, cpuTopology, cpuset.NewCPUSet())
+	handler.OnAdd(onlineTopology)
+	podA, podB := uuid.NewUUID(), uuid.NewUUID()
+	cpuManager.UpdateAllocatedCPUSet(nodeName, podA, cpuset.MustParse("0-1"), schedulingconfig.CPUExclusivePolicyPCPULevel)
+	cpuManager.UpdateAllocatedCPUSet(nodeName, podB, cpuset.MustParse("2-3"), schedulingconfig.CPUExclusivePolicyPCPULevel)
+	assert.Empty(t, eventRecorder.Events)
+
+	degraded, err := cpuManager.GetDegradedAllocations(nodeName)
+	assert.NoError(t, err)
+	assert.Empty(t, degraded)
+
+	// CPU 3 goes offline, and the allocation of pod B is degraded
+	offlineTopology := newNodeResourceTopologyForTest(t, nodeName, cpuTopology, cpuset.NewCPUSet(3))
+	handler.OnUpdate(onlineTopology, offlineTopology)
+	assert.Equal(t, fmt.Sprintf("Warning CPUsOffline CPUs 3 are offline, the CPU allocations of pods [%s] are degraded", podB), <-eventRecorder.Events)
+	degraded, err = cpuManager.GetDegradedAllocations(nodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, map[types.UID]cpuset.CPUSet{podB: cpuset.NewCPUSet(3)}, degraded)
+
+	availableCPUs, allocated, err := cpuManager.GetAvailableCPUs(nodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, cpuset.MustParse("4-7"), availableCPUs)
+	assert.Equal(t, cpuset.MustParse("0-2"), allocated.CPUs())
+
+	// the offline CPU is never allocated even if it is released
+	cpuManager.Free(nodeName, podB)
+	availableCPUs, _, err = cpuManager.GetAvailableCPUs(nodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, cpuset.MustParse("2,4-7"), availableCPUs)
+	cpuManager.UpdateAllocatedCPUSet(nodeName, podB, cpuset.MustParse("2-3"), schedulingconfig.CPUExclusivePolicyPCPULevel)
+
+	// the allocation referencing the unknown CPUs does not break the allocating and scoring
+	podC := uuid.NewUUID()
+	cpuManager.UpdateAllocatedCPUSet(nodeName, podC, cpuset.MustParse("8-9"), schedulingconfig.CPUExclusivePolicyPCPULevel)
+	assert.NotPanics(t, func() {
+		result, err := cpuManager.Allocate(node, 4, schedulingconfig.CPUBindPolicyFullPCPUs, schedulingconfig.CPUExclusivePolicyPCPULevel, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, cpuset.MustParse("4-7"), result)
+		cpuManager.Score(node, 2, schedulingconfig.CPUBindPolicyFullPCPUs, schedulingconfig.CPUExclusivePolicyPCPULevel)
+	})
+	cpuManager.Free(nodeName, podC)
+
+	// CPU 3 comes back online, and it is reintegrated with the allocation of pod B
+	handler.OnUpdate(offlineTopology, onlineTopology)
+	assert.Equal(t, "Normal CPUsOnline CPUs 3 are online", <-eventRecorder.Events)
+	degraded, err = cpuManager.GetDegradedAllocations(nodeName)
+	assert.NoError(t, err)
+	assert.Empty(t, degraded)
+	availableCPUs, allocated, err = cpuManager.GetAvailableCPUs(nodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, cpuset.MustParse("4-7"), availableCPUs)
+	assert.Equal(t, cpuset.MustParse("0-3"), allocated.CPUs())
+	assert.Equal(t, cpuTopology.CPUDetails[3].CoreID, allocated[3].CoreID)
+	assert.Equal(t, 1, allocated[3].RefCount)
+	assert.Empty(t, eventRecorder.Events)
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
@@ -51,6 +52,9 @@ const (
 	ErrInvalidCPUTopology      = "node(s) invalid CPU Topology"
 	ErrSMTAlignmentError       = "node(s) requested cpus not multiple cpus per core"
 	ErrRequiredFullPCPUsPolicy = "node(s) required FullPCPUs policy"
+	ErrDevicesNotReserved      = "the devices of the pod must be reserved before the CPUs, DeviceShare must Reserve before NodeNUMAResource"
+	ErrDevicesNotFiltered      = "the devices of the pod must be filtered before the CPUs, DeviceShare must Filter before NodeNUMAResource"
+	ErrInsufficientDeviceNUMA  = "node(s) not enough CPUs in the NUMA nodes of the allocated devices"
 )
 
 var (
@@ -131,7 +135,7 @@ func NewWithOptions(args runtime.Object, handle framework.Handle, opts ...Option
 	}
 
 	if !options.customSyncTopology {
-		if err := registerNodeResourceTopologyEventHandler(handle, options.topologyManager); err != nil {
+		if err := registerNodeResourceTopologyEventHandler(handle, options.topologyManager, options.cpuManager); err != nil {
 			return nil, err
 		}
 	}
@@ -184,7 +188,10 @@ func (s *preFilterState) Clone() framework.StateData {
 	}
 }
 
-func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "PreFilter", pod, "")
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	resourceSpec, err := GetResourceSpec(pod.Annotations)
 	if err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
@@ -233,7 +240,14 @@ func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, *fram
 	return state, nil
 }
 
-func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	var nodeName string
+	if nodeInfo.Node() != nil {
+		nodeName = nodeInfo.Node().Name
+	}
+	_, span := frameworkext.StartSpan(ctx, Name, "Filter", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -269,6 +283,35 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 		}
 	}
 
+	return p.filterDeviceNUMANodes(cycleState, state, node.Name)
+}
+
+// filterDeviceNUMANodes rejects the node if the pod restricts the CPUs to the NUMA nodes of its devices,
+// but the CPUs available in the NUMA nodes of the devices filtered by DeviceShare on the node are not enough.
+func (p *Plugin) filterDeviceNUMANodes(cycleState *framework.CycleState, state *preFilterState, nodeName string) *framework.Status {
+	if state.resourceSpec == nil || state.resourceSpec.NUMATopologyPolicy != extension.NUMATopologyPolicyRestricted || state.numCPUsNeeded <= 0 {
+		return nil
+	}
+	hint := frameworkext.GetDeviceNUMAHint(cycleState)
+	if hint == nil {
+		return nil
+	}
+	numaNodes, ok := hint.GetFilteredNUMANodes(nodeName)
+	if !ok {
+		return framework.NewStatus(framework.Error, ErrDevicesNotFiltered)
+	}
+	if len(numaNodes) == 0 {
+		return nil
+	}
+	availableCPUs, _, err := p.cpuManager.GetAvailableCPUs(nodeName)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	cpuTopologyOptions := p.topologyManager.GetCPUTopologyOptions(nodeName)
+	availableCPUs = availableCPUs.Intersection(cpuTopologyOptions.CPUTopology.CPUDetails.CPUsInKernelNUMANodes(numaNodes...))
+	if availableCPUs.Size() < state.numCPUsNeeded {
+		return framework.NewStatus(framework.Unschedulable, ErrInsufficientDeviceNUMA)
+	}
 	return nil
 }
 
@@ -303,7 +346,10 @@ func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
 	return nil
 }
 
-func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "Reserve", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -325,7 +371,19 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	if err != nil {
 		return framework.AsStatus(err)
 	}
-	result, err := p.cpuManager.Allocate(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy)
+	numaNodes, numaTopologyPolicy, status := getDeviceNUMANodes(cycleState, state.resourceSpec, nodeName)
+	if !status.IsSuccess() {
+		return status
+	}
+	result, err := p.cpuManager.Allocate(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy, numaNodes)
+	if err != nil && len(numaNodes) > 0 {
+		if numaTopologyPolicy == extension.NUMATopologyPolicyRestricted {
+			return framework.NewStatus(framework.Unschedulable, ErrInsufficientDeviceNUMA)
+		}
+		klog.V(4).InfoS("Failed to allocate the CPUs in the NUMA nodes of the devices, fall back to the other CPUs",
+			"pod", klog.KObj(pod), "node", nodeName, "numaNodes", numaNodes, "err", err)
+		result, err = p.cpuManager.Allocate(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy, nil)
+	}
 	if err != nil {
 		return framework.AsStatus(err)
 	}
@@ -335,6 +393,27 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	return nil
 }
 
+// getDeviceNUMANodes returns the NUMA nodes of the devices reserved for the pod on the node, along with the NUMA topology
+// policy of the pod. The NUMA nodes are empty if the pod does not align the CPUs with the devices, or does not request any.
+// DeviceShare publishes the NUMA nodes in its Reserve, so an error is returned if the devices are not reserved yet.
+func getDeviceNUMANodes(cycleState *framework.CycleState, resourceSpec *extension.ResourceSpec, nodeName string) ([]int, extension.NUMATopologyPolicy, *framework.Status) {
+	numaTopologyPolicy := extension.NUMATopologyPolicyDefault
+	if resourceSpec != nil {
+		numaTopologyPolicy = resourceSpec.NUMATopologyPolicy
+	}
+	if numaTopologyPolicy == extension.NUMATopologyPolicyNone {
+		return nil, numaTopologyPolicy, nil
+	}
+	hint := frameworkext.GetDeviceNUMAHint(cycleState)
+	if hint == nil {
+		return nil, numaTopologyPolicy, nil
+	}
+	if !hint.IsReserved(nodeName) {
+		return nil, numaTopologyPolicy, framework.NewStatus(framework.Error, ErrDevicesNotReserved)
+	}
+	return hint.NUMANodes, numaTopologyPolicy, nil
+}
+
 func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) {
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
@@ -346,7 +425,10 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	p.cpuManager.Free(nodeName, pod.UID)
 }
 
-func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "PreBind", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -51,4 +51,13 @@ func (p *Plugin) RegisterEndpoints(group *gin.RouterGroup) {
 		}
 		c.JSON(http.StatusOK, r)
 	})
+	group.GET("/degradedAllocations/:nodeName", func(c *gin.Context) {
+		nodeName := c.Param("nodeName")
+		degraded, err := p.cpuManager.GetDegradedAllocations(nodeName)
+		if err != nil {
+			services.ResponseErrorMessage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, degraded)
+	})
 }
@@ -24,6 +24,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -111,3 +112,29 @@ func TestEndpointsQueryAvailableCPUsOptions(t *testing.T) {
 	}
 	assert.Equal(t, expectedResponse, response)
 }
+
+func TestEndpointsQueryDegradedAllocations(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	plugin, err := suit.proxyNew(suit.nodeNUMAResourceArgs, suit.Handle)
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+	p := plugin.(*Plugin)
+
+	p.topologyManager.UpdateCPUTopologyOptions("test-node-1", func(options *CPUTopologyOptions) {
+		options.CPUTopology = buildCPUTopologyForTest(2, 1, 4, 2)
+	})
+	podUID := uuid.NewUUID()
+	p.cpuManager.UpdateAllocatedCPUSet("test-node-1", podUID, cpuset.MustParse("14-17"), schedulingconfig.CPUExclusivePolicyNone)
+	p.cpuManager.UpdateAllocatedCPUSet("test-node-1", uuid.NewUUID(), cpuset.MustParse("0-1"), schedulingconfig.CPUExclusivePolicyNone)
+
+	engine := gin.Default()
+	p.RegisterEndpoints(engine.Group("/"))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/degradedAllocations/test-node-1", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	response := map[types.UID]cpuset.CPUSet{}
+	err = json.NewDecoder(w.Result().Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[types.UID]cpuset.CPUSet{podUID: cpuset.MustParse("16-17")}, response)
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 
 	_ "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/scheme"
@@ -1002,6 +1003,208 @@ func TestPlugin_Reserve(t *testing.T) {
 	}
 }
 
+func TestPlugin_ReserveWithDeviceNUMAHint(t *testing.T) {
+	// two NUMA nodes of 8 CPUs, and the GPUs of the pod are attached only to the NUMA node 1
+	gpuOnNUMANode1 := &frameworkext.DeviceNUMAHint{NodeName: "test-node-1", NUMANodes: []int{1}}
+	tests := []struct {
+		name               string
+		hint               *frameworkext.DeviceNUMAHint
+		numaTopologyPolicy extension.NUMATopologyPolicy
+		allocatedCPUs      []int
+		want               *framework.Status
+		wantCPUSet         cpuset.CPUSet
+	}{
+		{
+			name:       "allocate anywhere without devices",
+			wantCPUSet: cpuset.NewCPUSet(0, 1, 2, 3),
+		},
+		{
+			name:       "prefer the NUMA node of the devices by default",
+			hint:       gpuOnNUMANode1,
+			wantCPUSet: cpuset.NewCPUSet(8, 9, 10, 11),
+		},
+		{
+			name:               "ignore the devices with the None policy",
+			hint:               gpuOnNUMANode1,
+			numaTopologyPolicy: extension.NUMATopologyPolicyNone,
+			wantCPUSet:         cpuset.NewCPUSet(0, 1, 2, 3),
+		},
+		{
+			name:               "fall back to the other NUMA node with the BestEffort policy",
+			hint:               gpuOnNUMANode1,
+			numaTopologyPolicy: extension.NUMATopologyPolicyBestEffort,
+			allocatedCPUs:      []int{8, 9, 10, 11, 12, 13},
+			wantCPUSet:         cpuset.NewCPUSet(0, 1, 2, 3),
+		},
+		{
+			name:               "require the NUMA node of the devices with the Restricted policy",
+			hint:               gpuOnNUMANode1,
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			wantCPUSet:         cpuset.NewCPUSet(8, 9, 10, 11),
+		},
+		{
+			name:               "reject if the NUMA node of the devices is busy with the Restricted policy",
+			hint:               gpuOnNUMANode1,
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			allocatedCPUs:      []int{8, 9, 10, 11, 12, 13},
+			want:               framework.NewStatus(framework.Unschedulable, ErrInsufficientDeviceNUMA),
+		},
+		{
+			name:               "allocate anywhere if the devices do not report the topology",
+			hint:               &frameworkext.DeviceNUMAHint{NodeName: "test-node-1"},
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			wantCPUSet:         cpuset.NewCPUSet(0, 1, 2, 3),
+		},
+		{
+			name: "error if the devices are not reserved yet",
+			hint: &frameworkext.DeviceNUMAHint{},
+			want: framework.NewStatus(framework.Error, ErrDevicesNotReserved),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("64Gi"),
+						},
+					},
+				},
+			}
+			suit := newPluginTestSuit(t, nodes)
+			p, err := suit.proxyNew(suit.nodeNUMAResourceArgs, suit.Handle)
+			assert.NoError(t, err)
+			plg := p.(*Plugin)
+
+			cpuTopology := buildCPUTopologyForTest(2, 1, 4, 2)
+			plg.topologyManager.UpdateCPUTopologyOptions("test-node-1", func(options *CPUTopologyOptions) {
+				options.CPUTopology = cpuTopology
+			})
+			allocationState := newCPUAllocation("test-node-1")
+			if len(tt.allocatedCPUs) > 0 {
+				allocationState.addCPUs(cpuTopology, uuid.NewUUID(), cpuset.NewCPUSet(tt.allocatedCPUs...), schedulingconfig.CPUExclusivePolicyNone)
+			}
+			plg.cpuManager.(*cpuManagerImpl).allocationStates["test-node-1"] = allocationState
+			suit.start()
+
+			state := &preFilterState{
+				numCPUsNeeded: 4,
+				resourceSpec: &extension.ResourceSpec{
+					PreferredCPUBindPolicy: extension.CPUBindPolicyFullPCPUs,
+					NUMATopologyPolicy:     tt.numaTopologyPolicy,
+				},
+				preferredCPUBindPolicy: schedulingconfig.CPUBindPolicyFullPCPUs,
+			}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, state)
+			if tt.hint != nil {
+				frameworkext.SetDeviceNUMAHint(cycleState, tt.hint)
+			}
+
+			status := plg.Reserve(context.TODO(), cycleState, &corev1.Pod{}, "test-node-1")
+			assert.Equal(t, tt.want, status)
+			if !status.IsSuccess() {
+				return
+			}
+			assert.Equal(t, tt.wantCPUSet.String(), state.allocatedCPUs.String())
+		})
+	}
+}
+
+func TestPlugin_FilterWithDeviceNUMAHint(t *testing.T) {
+	// two NUMA nodes of 8 CPUs, and the GPUs of the pod are filtered only on the NUMA node 1
+	tests := []struct {
+		name               string
+		filtered           []int
+		notFiltered        bool
+		numaTopologyPolicy extension.NUMATopologyPolicy
+		allocatedCPUs      []int
+		want               *framework.Status
+	}{
+		{
+			name:          "ignore the devices by default",
+			filtered:      []int{1},
+			allocatedCPUs: []int{8, 9, 10, 11, 12, 13},
+		},
+		{
+			name:               "accept if the NUMA node of the devices is free with the Restricted policy",
+			filtered:           []int{1},
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			allocatedCPUs:      []int{0, 1, 2, 3, 4, 5},
+		},
+		{
+			name:               "reject if the NUMA node of the devices is busy with the Restricted policy",
+			filtered:           []int{1},
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			allocatedCPUs:      []int{8, 9, 10, 11, 12, 13},
+			want:               framework.NewStatus(framework.Unschedulable, ErrInsufficientDeviceNUMA),
+		},
+		{
+			name:               "accept if the devices do not report the topology",
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			allocatedCPUs:      []int{8, 9, 10, 11, 12, 13},
+		},
+		{
+			name:               "error if the devices are not filtered yet",
+			notFiltered:        true,
+			numaTopologyPolicy: extension.NUMATopologyPolicyRestricted,
+			want:               framework.NewStatus(framework.Error, ErrDevicesNotFiltered),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("64Gi"),
+						},
+					},
+				},
+			}
+			suit := newPluginTestSuit(t, nodes)
+			p, err := suit.proxyNew(suit.nodeNUMAResourceArgs, suit.Handle)
+			assert.NoError(t, err)
+			plg := p.(*Plugin)
+
+			cpuTopology := buildCPUTopologyForTest(2, 1, 4, 2)
+			plg.topologyManager.UpdateCPUTopologyOptions("test-node-1", func(options *CPUTopologyOptions) {
+				options.CPUTopology = cpuTopology
+			})
+			allocationState := newCPUAllocation("test-node-1")
+			if len(tt.allocatedCPUs) > 0 {
+				allocationState.addCPUs(cpuTopology, uuid.NewUUID(), cpuset.NewCPUSet(tt.allocatedCPUs...), schedulingconfig.CPUExclusivePolicyNone)
+			}
+			plg.cpuManager.(*cpuManagerImpl).allocationStates["test-node-1"] = allocationState
+			suit.start()
+
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{
+				numCPUsNeeded: 4,
+				resourceSpec: &extension.ResourceSpec{
+					PreferredCPUBindPolicy: extension.CPUBindPolicyFullPCPUs,
+					NUMATopologyPolicy:     tt.numaTopologyPolicy,
+				},
+				preferredCPUBindPolicy: schedulingconfig.CPUBindPolicyFullPCPUs,
+			})
+			hint := &frameworkext.DeviceNUMAHint{}
+			if !tt.notFiltered {
+				hint.SetFilteredNUMANodes("test-node-1", tt.filtered)
+			}
+			frameworkext.SetDeviceNUMAHint(cycleState, hint)
+
+			nodeInfo, err := suit.Handle.SnapshotSharedLister().NodeInfos().Get("test-node-1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, plg.Filter(context.TODO(), cycleState, &corev1.Pod{}, nodeInfo))
+		})
+	}
+}
+
 func TestPlugin_Unreserve(t *testing.T) {
 	state := &preFilterState{
 		skip:          false,
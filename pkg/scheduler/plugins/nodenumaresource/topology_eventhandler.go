@@ -18,12 +18,16 @@ package nodenumaresource
 
 import (
 	"context"
+	"sort"
 
 	nrtv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	nrtclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned"
 	nrtinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
@@ -34,9 +38,11 @@ import (
 
 type nodeResourceTopologyEventHandler struct {
 	topologyManager CPUTopologyManager
+	cpuManager      CPUManager
+	eventRecorder   events.EventRecorder
 }
 
-func registerNodeResourceTopologyEventHandler(handle framework.Handle, topologyManager CPUTopologyManager) error {
+func registerNodeResourceTopologyEventHandler(handle framework.Handle, topologyManager CPUTopologyManager, cpuManager CPUManager) error {
 	nrtClient, ok := handle.(nrtclientset.Interface)
 	if !ok {
 		kubeConfig := *handle.KubeConfig()
@@ -53,6 +59,8 @@ func registerNodeResourceTopologyEventHandler(handle framework.Handle, topologyM
 	nodeResTopologyInformer := nodeResTopologyInformerFactory.Topology().V1alpha1().NodeResourceTopologies().Informer()
 	eventHandler := &nodeResourceTopologyEventHandler{
 		topologyManager: topologyManager,
+		cpuManager:      cpuManager,
+		eventRecorder:   handle.EventRecorder(),
 	}
 	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), nodeResTopologyInformerFactory, nodeResTopologyInformer, eventHandler)
 	return nil
@@ -128,7 +136,9 @@ func (m *nodeResourceTopologyEventHandler) updateNodeResourceTopology(oldNodeRes
 	reservedCPUs = reservedCPUs.Union(kubeletReservedCPUs)
 
 	nodeName := newNodeResTopology.Name
+	var oldCPUTopology *CPUTopology
 	m.topologyManager.UpdateCPUTopologyOptions(nodeName, func(options *CPUTopologyOptions) {
+		oldCPUTopology = options.CPUTopology
 		*options = CPUTopologyOptions{
 			CPUTopology:  cpuTopology,
 			ReservedCPUs: reservedCPUs,
@@ -136,6 +146,51 @@ func (m *nodeResourceTopologyEventHandler) updateNodeResourceTopology(oldNodeRes
 			MaxRefCount:  options.MaxRefCount,
 		}
 	})
+	m.handleCPUsChanged(nodeName, oldCPUTopology, cpuTopology)
+}
+
+// handleCPUsChanged reports the CPUs going offline (e.g. hotplug for maintenance) or coming back online,
+// and the allocations referencing the offline CPUs which are degraded.
+func (m *nodeResourceTopologyEventHandler) handleCPUsChanged(nodeName string, oldCPUTopology, newCPUTopology *CPUTopology) {
+	// ignore the invalid reports, or all CPUs would be regarded as offline
+	if oldCPUTopology == nil || !oldCPUTopology.IsValid() || newCPUTopology == nil || !newCPUTopology.IsValid() {
+		return
+	}
+	oldCPUs := oldCPUTopology.CPUDetails.CPUs()
+	newCPUs := newCPUTopology.CPUDetails.CPUs()
+	offlineCPUs := oldCPUs.Difference(newCPUs)
+	onlineCPUs := newCPUs.Difference(oldCPUs)
+	if offlineCPUs.IsEmpty() && onlineCPUs.IsEmpty() {
+		return
+	}
+	klog.V(4).InfoS("CPUs of node changed", "node", nodeName, "offline", offlineCPUs.String(), "online", onlineCPUs.String())
+
+	nodeRef := &corev1.ObjectReference{Kind: "Node", Name: nodeName, UID: types.UID(nodeName)}
+	if !onlineCPUs.IsEmpty() && m.eventRecorder != nil {
+		m.eventRecorder.Eventf(nodeRef, nil, corev1.EventTypeNormal, "CPUsOnline", "Reporting", "CPUs %s are online", onlineCPUs.String())
+	}
+	if offlineCPUs.IsEmpty() || m.cpuManager == nil {
+		return
+	}
+	degraded, err := m.cpuManager.GetDegradedAllocations(nodeName)
+	if err != nil {
+		klog.Errorf("Failed to GetDegradedAllocations, node: %s, err: %v", nodeName, err)
+		return
+	}
+	var degradedPods []string
+	for podUID, cpus := range degraded {
+		if !cpus.Intersection(offlineCPUs).IsEmpty() {
+			degradedPods = append(degradedPods, string(podUID))
+		}
+	}
+	sort.Strings(degradedPods)
+	if len(degradedPods) > 0 {
+		klog.Warningf("CPUs %s of node %s are offline, the CPU allocations of pods %v are degraded", offlineCPUs.String(), nodeName, degradedPods)
+	}
+	if m.eventRecorder != nil {
+		m.eventRecorder.Eventf(nodeRef, nil, corev1.EventTypeWarning, "CPUsOffline", "Reporting",
+			"CPUs %s are offline, the CPU allocations of pods %v are degraded", offlineCPUs.String(), degradedPods)
+	}
 }
 
 func (m *nodeResourceTopologyEventHandler) getPodAllocsCPUSet(podCPUAllocs extension.PodCPUAllocs) cpuset.CPUSet {
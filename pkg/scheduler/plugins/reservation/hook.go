@@ -83,6 +83,7 @@ func (h *Hook) PreFilterHook(handle frameworkext.ExtendedHandle, cycleState *fra
 	// if the pod match any reservations, it stores the matched reservations meta for the pod, mapping from nodeName
 	// to reservationInfo
 	cycleState.Write(preFilterStateKey, state)
+	frameworkext.SetReservationNomination(cycleState, newReservationNomination(state))
 
 	if state.skip {
 		klog.V(5).InfoS("PreFilterHook skips for no reservation matched", "pod", klog.KObj(pod))
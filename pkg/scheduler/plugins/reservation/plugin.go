@@ -171,7 +171,10 @@ func (p *Plugin) Name() string { return Name }
 
 // PreFilter checks if the pod is a reserve pod. If it is, update cycle state to annotate reservation scheduling.
 // Also do validations in this phase.
-func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "PreFilter", pod, "")
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	// if the pod is a reserve pod
 	if util.IsReservePod(pod) {
 		// validate reserve pod and reservation
@@ -201,7 +204,14 @@ func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
 }
 
 // Filter only processes pods either the pod is a reserve pod or a pod can allocate reserved resources on the node.
-func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	var nodeName string
+	if nodeInfo.Node() != nil {
+		nodeName = nodeInfo.Node().Name
+	}
+	_, span := frameworkext.StartSpan(ctx, Name, "Filter", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	node := nodeInfo.Node()
 	if node == nil {
 		return framework.NewStatus(framework.Error, "node not found")
@@ -339,7 +349,10 @@ func (p *Plugin) NormalizeScore(ctx context.Context, state *framework.CycleState
 	return pluginhelper.DefaultNormalizeScore(framework.MaxNodeScore, false, scores)
 }
 
-func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "Reserve", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	// if the pod is a reserve pod
 	if util.IsReservePod(pod) {
 		return nil
@@ -407,6 +420,7 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		// update assume state
 		state.assumed = reserved
 		cycleState.Write(preFilterStateKey, state)
+		setAssumedReservation(cycleState, reserved)
 		klog.V(4).InfoS("Attempting to reserve pod to node with reservations", "pod", klog.KObj(pod),
 			"node", nodeName, "matched count", len(rOnNode), "assumed", klog.KObj(reserved))
 		return nil
@@ -441,6 +455,7 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	// clean assume state
 	state.assumed = nil
 	cycleState.Write(preFilterStateKey, state)
+	setAssumedReservation(cycleState, nil)
 
 	// update assume cache
 	unreserved := target.DeepCopy()
@@ -511,7 +526,10 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	}
 }
 
-func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
+	_, span := frameworkext.StartSpan(ctx, Name, "PreBind", pod, nodeName)
+	defer func() { frameworkext.EndSpan(span, status) }()
+
 	// if the pod is a reserve pod
 	if util.IsReservePod(pod) {
 		return nil
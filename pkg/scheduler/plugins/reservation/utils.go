@@ -23,13 +23,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
 	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -137,7 +137,7 @@ func setReservationAllocated(r *schedulingv1alpha1.Reservation, pod *corev1.Pod)
 	// avoid duplication (it happens if pod allocated annotation was missing)
 	idx := -1
 	for i, current := range r.Status.CurrentOwners {
-		if matchObjectRef(pod, &current) {
+		if util.MatchObjectRef(pod, &current) {
 			idx = i
 		}
 	}
@@ -185,7 +185,7 @@ func removeReservationAllocated(r *schedulingv1alpha1.Reservation, pod *corev1.P
 	// remove matched owner info
 	idx := -1
 	for i, owner := range r.Status.CurrentOwners {
-		if matchObjectRef(pod, &owner) {
+		if util.MatchObjectRef(pod, &owner) {
 			idx = i
 		}
 	}
@@ -233,7 +233,7 @@ func getReservationRequests(r *schedulingv1alpha1.Reservation) corev1.ResourceLi
 }
 
 func matchReservation(pod *corev1.Pod, rMeta *reservationInfo) bool {
-	return matchReservationOwners(pod, rMeta.Reservation) && matchReservationResources(pod, rMeta.Reservation, rMeta.Resources) && matchReservationPort(pod, rMeta)
+	return util.MatchReservationOwners(pod, rMeta.Reservation) && matchReservationResources(pod, rMeta.Reservation, rMeta.Resources) && matchReservationPort(pod, rMeta)
 }
 
 func matchReservationPort(pod *corev1.Pod, rMeta *reservationInfo) bool {
@@ -262,57 +262,9 @@ func matchReservationResources(pod *corev1.Pod, r *schedulingv1alpha1.Reservatio
 	return true
 }
 
-// matchReservationOwners checks if the scheduling pod matches the reservation's owner spec.
-// `reservation.spec.owners` defines the DNF (disjunctive normal form) of ObjectReference, ControllerReference
-// (extended), LabelSelector, which means multiple selectors are firstly ANDed and secondly ORed.
-func matchReservationOwners(pod *corev1.Pod, r *schedulingv1alpha1.Reservation) bool {
-	// assert pod != nil && r != nil
-	// Owners == nil matches nothing, while Owners = [{}] matches everything
-	for _, owner := range r.Spec.Owners {
-		if matchObjectRef(pod, owner.Object) &&
-			matchReservationControllerReference(pod, owner.Controller) &&
-			matchLabelSelector(pod, owner.LabelSelector) {
-			return true
-		}
-	}
-	return false
-}
-
-func matchObjectRef(pod *corev1.Pod, objRef *corev1.ObjectReference) bool {
-	// `ResourceVersion`, `FieldPath` are ignored.
-	// since only pod type are compared, `Kind` field is also ignored.
-	return objRef == nil ||
-		(len(objRef.UID) <= 0 || pod.UID == objRef.UID) &&
-			(len(objRef.Name) <= 0 || pod.Name == objRef.Name) &&
-			(len(objRef.Namespace) <= 0 || pod.Namespace == objRef.Namespace) &&
-			(len(objRef.APIVersion) <= 0 || pod.APIVersion == objRef.APIVersion)
-}
-
-func matchReservationControllerReference(pod *corev1.Pod, controllerRef *schedulingv1alpha1.ReservationControllerReference) bool {
-	// controllerRef matched if any of pod owner references matches the controllerRef;
-	// typically a pod has only one controllerRef
-	if controllerRef == nil {
-		return true
-	}
-	if len(controllerRef.Namespace) > 0 && controllerRef.Namespace != pod.Namespace { // namespace field is extended
-		return false
-	}
-	// currently `BlockOwnerDeletion` is ignored
-	for _, podOwner := range pod.OwnerReferences {
-		if (controllerRef.Controller == nil || podOwner.Controller != nil && *controllerRef.Controller == *podOwner.Controller) &&
-			(len(controllerRef.UID) <= 0 || controllerRef.UID == podOwner.UID) &&
-			(len(controllerRef.Name) <= 0 || controllerRef.Name == podOwner.Name) &&
-			(len(controllerRef.Kind) <= 0 || controllerRef.Kind == podOwner.Kind) &&
-			(len(controllerRef.APIVersion) <= 0 || controllerRef.APIVersion == podOwner.APIVersion) {
-			return true
-		}
-	}
-	return false
-}
-
 func dumpMatchReservationReason(pod *corev1.Pod, rMeta *reservationInfo) string {
 	var msg strings.Builder
-	if !matchReservationOwners(pod, rMeta.Reservation) {
+	if !util.MatchReservationOwners(pod, rMeta.Reservation) {
 		msg.WriteString("owner specs not matched;")
 	}
 	if !matchReservationResources(pod, rMeta.Reservation, rMeta.Resources) {
@@ -324,17 +276,6 @@ func dumpMatchReservationReason(pod *corev1.Pod, rMeta *reservationInfo) string
 	return msg.String()
 }
 
-func matchLabelSelector(pod *corev1.Pod, labelSelector *metav1.LabelSelector) bool {
-	if labelSelector == nil {
-		return true
-	}
-	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
-	if err != nil {
-		return false
-	}
-	return selector.Matches(labels.Set(pod.Labels))
-}
-
 func getPodOwner(pod *corev1.Pod) corev1.ObjectReference {
 	return corev1.ObjectReference{
 		Namespace: pod.Namespace,
@@ -359,3 +300,24 @@ func getPreFilterState(cycleState *framework.CycleState) *stateData {
 	}
 	return cache
 }
+
+// newReservationNomination returns the reservations matching the pod by the node name for the other plugins.
+func newReservationNomination(state *stateData) *frameworkext.ReservationNomination {
+	nomination := &frameworkext.ReservationNomination{Matched: map[string][]*schedulingv1alpha1.Reservation{}}
+	if state.skip || state.matchedCache == nil {
+		return nomination
+	}
+	for nodeName, rInfos := range state.matchedCache.nodeToR {
+		for _, rInfo := range rInfos {
+			nomination.Matched[nodeName] = append(nomination.Matched[nodeName], rInfo.GetReservation())
+		}
+	}
+	return nomination
+}
+
+// setAssumedReservation records the reservation the pod is assumed to allocate for the other plugins.
+func setAssumedReservation(cycleState *framework.CycleState, r *schedulingv1alpha1.Reservation) {
+	if nomination := frameworkext.GetReservationNomination(cycleState); nomination != nil {
+		nomination.Assumed = r
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	SLOControllerSubsystem = "slo_controller"
+
+	ReasonKey = "reason"
+)
+
+var (
+	NodeResourceSyncSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: SLOControllerSubsystem,
+		Name:      "node_resource_sync_skipped",
+		Help:      "Number of node resource syncs skipped, by the reason",
+	}, []string{ReasonKey})
+
+	NodeResourceFencingRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: SLOControllerSubsystem,
+		Name:      "node_resource_fencing_rejected",
+		Help:      "Number of node resource updates rejected by the leadership fencing, by the reason",
+	}, []string{ReasonKey})
+
+	collectors = []prometheus.Collector{
+		NodeResourceSyncSkipped,
+		NodeResourceFencingRejected,
+	}
+)
+
+func init() {
+	metrics.Registry.MustRegister(collectors...)
+}
+
+func RecordNodeResourceSyncSkipped(reason string) {
+	NodeResourceSyncSkipped.With(prometheus.Labels{ReasonKey: reason}).Inc()
+}
+
+func RecordNodeResourceFencingRejected(reason string) {
+	NodeResourceFencingRejected.With(prometheus.Labels{ReasonKey: reason}).Inc()
+}
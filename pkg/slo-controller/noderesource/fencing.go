@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/slo-controller/metrics"
+)
+
+var (
+	errGenerationUnknown  = errors.New("leadership generation unknown")
+	errGenerationOutdated = errors.New("leadership generation outdated")
+)
+
+const (
+	fencedByUnknownGeneration string = "UnknownGeneration"
+	fencedByNewerGeneration   string = "NewerGeneration"
+
+	leaderGenerationObserveInterval = time.Second
+	// leaderGenerationVerifyPeriod is the retry period of the manager leader election, in which the leader renews the
+	// lock. A verified generation is trusted within the period without reading the lock again.
+	leaderGenerationVerifyPeriod = 2 * time.Second
+)
+
+// LeaderElectionLock is the ConfigMap lock of the koord-manager leader election. The leader transitions of the lock
+// are used as the leadership generation to fence the node resource updates, so a stale leader who still believes it
+// is the leader cannot overwrite the node resources written by a newer one. The fencing is disabled if it is empty.
+var LeaderElectionLock types.NamespacedName
+
+// LeaderGeneration is the leadership generation which the reconciler writes the node resources with.
+type LeaderGeneration struct {
+	lock         sync.RWMutex
+	generation   int64
+	holder       string
+	observed     bool
+	verifiedTime time.Time
+
+	// reader re-reads the leader election lock once the last verification is older than the verify period, so a
+	// leader who has lost the lock is fenced within a renew period. The observed generation is trusted as is if it is nil.
+	reader       client.Reader
+	lockName     types.NamespacedName
+	verifyPeriod time.Duration
+	clock        clock.Clock
+}
+
+func NewLeaderGeneration(reader client.Reader, lockName types.NamespacedName) *LeaderGeneration {
+	return &LeaderGeneration{
+		reader:       reader,
+		lockName:     lockName,
+		verifyPeriod: leaderGenerationVerifyPeriod,
+		clock:        clock.RealClock{},
+	}
+}
+
+// Set records the generation and the holder identity of the lock observed when the manager becomes the leader.
+func (g *LeaderGeneration) Set(holder string, generation int64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.holder = holder
+	g.generation = generation
+	g.observed = true
+	g.verifiedTime = time.Time{}
+}
+
+// Current returns the generation if the leader election lock is still held by us with the observed generation.
+// The lock is read only if the generation has not been verified within the verify period.
+// It returns errGenerationUnknown if the generation is not observed yet or the lock cannot be read, and
+// errGenerationOutdated if the lock has been taken over since.
+func (g *LeaderGeneration) Current(ctx context.Context) (int64, error) {
+	g.lock.RLock()
+	generation, holder, observed, verifiedTime := g.generation, g.holder, g.observed, g.verifiedTime
+	g.lock.RUnlock()
+	if !observed {
+		return 0, fmt.Errorf("%w: not observed yet", errGenerationUnknown)
+	}
+	if g.reader == nil {
+		return generation, nil
+	}
+	now := g.clock.Now()
+	if !verifiedTime.IsZero() && now.Sub(verifiedTime) < g.verifyPeriod {
+		return generation, nil
+	}
+	record, err := getLeaderElectionRecord(ctx, g.reader, g.lockName)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errGenerationUnknown, err)
+	}
+	if record.HolderIdentity != holder || int64(record.LeaderTransitions) != generation {
+		return 0, fmt.Errorf("%w: lock held by %v with generation %v, observed %v with generation %v",
+			errGenerationOutdated, record.HolderIdentity, record.LeaderTransitions, holder, generation)
+	}
+	g.lock.Lock()
+	// skip if a new generation is observed meanwhile
+	if g.generation == generation && g.holder == holder {
+		g.verifiedTime = now
+	}
+	g.lock.Unlock()
+	return generation, nil
+}
+
+// leaderGenerationObserver observes the leadership generation from the leader election lock after the manager becomes
+// the leader. The lock is held by the manager at that point, so its leader transitions are the generation of ours.
+type leaderGenerationObserver struct {
+	reader     client.Reader
+	lock       types.NamespacedName
+	generation *LeaderGeneration
+}
+
+func (o *leaderGenerationObserver) NeedLeaderElection() bool {
+	return true
+}
+
+func (o *leaderGenerationObserver) Start(ctx context.Context) error {
+	err := wait.PollImmediateUntil(leaderGenerationObserveInterval, func() (bool, error) {
+		record, err := getLeaderElectionRecord(ctx, o.reader, o.lock)
+		if err != nil {
+			klog.Warningf("failed to get leadership generation from lock %v, error: %v", o.lock, err)
+			return false, nil
+		}
+		o.generation.Set(record.HolderIdentity, int64(record.LeaderTransitions))
+		klog.Infof("observed leadership generation %v of %v from lock %v",
+			record.LeaderTransitions, record.HolderIdentity, o.lock)
+		return true, nil
+	}, ctx.Done())
+	if err != nil && ctx.Err() != nil {
+		// stopped before the generation is observed
+		return nil
+	}
+	return err
+}
+
+// getLeaderElectionRecord returns the leader election record of the ConfigMap lock, whose leader transitions are used
+// as the leadership generation.
+func getLeaderElectionRecord(ctx context.Context, reader client.Reader, lock types.NamespacedName) (*resourcelock.LeaderElectionRecord, error) {
+	cm := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, lock, cm); err != nil {
+		return nil, err
+	}
+	recordStr, ok := cm.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]
+	if !ok {
+		return nil, fmt.Errorf("leader election record not found")
+	}
+	record := &resourcelock.LeaderElectionRecord{}
+	if err := json.Unmarshal([]byte(recordStr), record); err != nil {
+		return nil, fmt.Errorf("failed to parse leader election record, error: %v", err)
+	}
+	return record, nil
+}
+
+// checkNodeWriterFence checks if the reconciler still holds the newest leadership generation of the node. The generation
+// is verified against the lock at most once per verify period. It returns an error if the lock or the node has been
+// taken over by a newer generation or the generation of ours is unknown, and returns true
+// if the node should be claimed with the generation of ours.
+func (r *NodeResourceReconciler) checkNodeWriterFence(node *corev1.Node) (int64, bool, error) {
+	if r.Generation == nil {
+		return 0, false, nil
+	}
+	generation, err := r.Generation.Current(context.TODO())
+	if errors.Is(err, errGenerationOutdated) {
+		klog.Warningf("refuse to update node %v resources, %v", node.Name, err)
+		metrics.RecordNodeResourceFencingRejected(fencedByNewerGeneration)
+		return 0, false, err
+	} else if err != nil {
+		metrics.RecordNodeResourceFencingRejected(fencedByUnknownGeneration)
+		return 0, false, err
+	}
+	nodeGeneration, ok := extension.GetNodeResourceWriterGeneration(node.Annotations)
+	if ok && nodeGeneration > generation {
+		klog.Warningf("refuse to update node %v resources, written by a newer generation %v, current %v",
+			node.Name, nodeGeneration, generation)
+		metrics.RecordNodeResourceFencingRejected(fencedByNewerGeneration)
+		return 0, false, fmt.Errorf("fenced by newer generation %v, current %v", nodeGeneration, generation)
+	}
+	return generation, !ok || nodeGeneration < generation, nil
+}
+
+// fenceNodeWriter verifies the writer generation on the node before updating the node resources, and claims the node
+// with the generation of ours if it was written by an older one. The claim is patched with the resourceVersion
+// precondition, so the returned node can be updated only if no one has written the node since the fence check.
+func (r *NodeResourceReconciler) fenceNodeWriter(node *corev1.Node) (*corev1.Node, error) {
+	generation, needClaim, err := r.checkNodeWriterFence(node)
+	if err != nil || !needClaim {
+		return node, err
+	}
+
+	claimNode := node.DeepCopy()
+	if claimNode.Annotations == nil {
+		claimNode.Annotations = map[string]string{}
+	}
+	claimNode.Annotations[extension.AnnotationNodeResourceWriterGeneration] = strconv.FormatInt(generation, 10)
+
+	patch := client.MergeFromWithOptions(node, client.MergeFromWithOptimisticLock{})
+	if err := r.Client.Patch(context.TODO(), claimNode, patch); err != nil {
+		klog.Errorf("failed to patch node %v writer generation %v, error: %v", node.Name, generation, err)
+		return nil, err
+	}
+	klog.V(4).Infof("claimed node %v resources with writer generation %v", node.Name, generation)
+	return claimNode, nil
+}
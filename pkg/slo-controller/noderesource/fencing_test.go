@@ -0,0 +1,293 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func Test_getLeaderElectionRecord(t *testing.T) {
+	lock := types.NamespacedName{Namespace: "koordinator-system", Name: "koordinator-manager"}
+	tests := []struct {
+		name    string
+		objs    []client.Object
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "lock not found",
+			wantErr: true,
+		},
+		{
+			name: "leader election record not found",
+			objs: []client.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: lock.Namespace, Name: lock.Name}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid leader election record",
+			objs: []client.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: lock.Namespace, Name: lock.Name,
+					Annotations: map[string]string{resourcelock.LeaderElectionRecordAnnotationKey: "{"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "get leader transitions as generation",
+			objs: []client.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: lock.Namespace, Name: lock.Name,
+					Annotations: map[string]string{resourcelock.LeaderElectionRecordAnnotationKey: `{"holderIdentity":"manager-1","leaderTransitions":3}`}}},
+			},
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := fake.NewClientBuilder().WithObjects(tt.objs...).Build()
+			got, err := getLeaderElectionRecord(context.TODO(), reader, lock)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if err == nil {
+				assert.Equal(t, tt.want, int64(got.LeaderTransitions))
+			}
+		})
+	}
+}
+
+func Test_leaderGenerationObserver(t *testing.T) {
+	lock := types.NamespacedName{Namespace: "koordinator-system", Name: "koordinator-manager"}
+	lockCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   lock.Namespace,
+		Name:        lock.Name,
+		Annotations: map[string]string{resourcelock.LeaderElectionRecordAnnotationKey: `{"holderIdentity":"manager-1","leaderTransitions":2}`},
+	}}
+	reader := fake.NewClientBuilder().WithObjects(lockCM).Build()
+	fakeClock := clock.NewFakeClock(time.Now())
+	o := &leaderGenerationObserver{reader: reader, lock: lock, generation: NewLeaderGeneration(reader, lock)}
+	o.generation.clock = fakeClock
+	assert.True(t, o.NeedLeaderElection())
+	_, err := o.generation.Current(context.TODO())
+	assert.ErrorIs(t, err, errGenerationUnknown)
+	assert.NoError(t, o.Start(context.TODO()))
+	got, err := o.generation.Current(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got)
+
+	// the verified generation is trusted within the verify period even if the lock is taken over
+	assert.NoError(t, reader.Get(context.TODO(), lock, lockCM))
+	lockCM.Annotations[resourcelock.LeaderElectionRecordAnnotationKey] = `{"holderIdentity":"manager-2","leaderTransitions":3}`
+	assert.NoError(t, reader.(client.Client).Update(context.TODO(), lockCM))
+	fakeClock.Step(leaderGenerationVerifyPeriod / 2)
+	got, err = o.generation.Current(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got)
+
+	// the lock is re-read after the verify period, and the generation is outdated since the lock is taken over
+	fakeClock.Step(leaderGenerationVerifyPeriod / 2)
+	_, err = o.generation.Current(context.TODO())
+	assert.ErrorIs(t, err, errGenerationOutdated)
+	_, err = o.generation.Current(context.TODO())
+	assert.ErrorIs(t, err, errGenerationOutdated, "the outdated generation is never trusted")
+
+	// stop without the lock
+	reader = fake.NewClientBuilder().Build()
+	o = &leaderGenerationObserver{reader: reader, lock: lock, generation: NewLeaderGeneration(reader, lock)}
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, o.Start(ctx))
+	_, err = o.generation.Current(context.TODO())
+	assert.ErrorIs(t, err, errGenerationUnknown)
+}
+
+func Test_checkNodeWriterFence_lockTakenOver(t *testing.T) {
+	lock := types.NamespacedName{Namespace: "koordinator-system", Name: "koordinator-manager"}
+	lockCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   lock.Namespace,
+		Name:        lock.Name,
+		Annotations: map[string]string{resourcelock.LeaderElectionRecordAnnotationKey: `{"holderIdentity":"manager-2","leaderTransitions":3}`},
+	}}
+	testNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node0"}}
+	fakeClient := fake.NewClientBuilder().WithObjects(lockCM, testNode).Build()
+
+	// the stale leader observed generation 2 before the lock was taken over, and the node is not claimed by anyone
+	r := &NodeResourceReconciler{Client: fakeClient, Generation: NewLeaderGeneration(fakeClient, lock)}
+	r.Generation.Set("manager-1", 2)
+	_, _, err := r.checkNodeWriterFence(testNode)
+	assert.ErrorIs(t, err, errGenerationOutdated)
+
+	r.Generation.Set("manager-2", 3)
+	generation, needClaim, err := r.checkNodeWriterFence(testNode)
+	assert.NoError(t, err)
+	assert.True(t, needClaim)
+	assert.Equal(t, int64(3), generation)
+}
+
+func Test_updateNodeBEResource_fencing(t *testing.T) {
+	cfg := extension.ColocationCfg{
+		ColocationStrategy: extension.ColocationStrategy{
+			Enable:                        pointer.BoolPtr(true),
+			CPUReclaimThresholdPercent:    pointer.Int64Ptr(65),
+			MemoryReclaimThresholdPercent: pointer.Int64Ptr(65),
+			DegradeTimeMinutes:            pointer.Int64Ptr(15),
+			UpdateTimeThresholdSeconds:    pointer.Int64Ptr(300),
+			ResourceDiffThreshold:         pointer.Float64Ptr(0.1),
+		},
+	}
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node0",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				extension.BatchCPU:    resource.MustParse("20"),
+				extension.BatchMemory: resource.MustParse("40G"),
+			},
+			Capacity: corev1.ResourceList{
+				extension.BatchCPU:    resource.MustParse("20"),
+				extension.BatchMemory: resource.MustParse("40G"),
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithRuntimeObjects(testNode).Build()
+	newWriter := func(generation *int64) *NodeResourceReconciler {
+		r := &NodeResourceReconciler{
+			Client:        fakeClient,
+			cfgCache:      &FakeCfgCache{cfg: cfg},
+			BESyncContext: NewSyncContext(),
+			Clock:         clock.RealClock{},
+			Generation:    NewLeaderGeneration(nil, types.NamespacedName{}),
+		}
+		if generation != nil {
+			r.Generation.Set("manager", *generation)
+		}
+		return r
+	}
+	getNode := func() *corev1.Node {
+		node := &corev1.Node{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: testNode.Name}, node))
+		return node
+	}
+	assertBatchCPU := func(node *corev1.Node, want int64) {
+		gotCPU := node.Status.Allocatable[extension.BatchCPU]
+		assert.Equal(t, want, gotCPU.Value())
+	}
+
+	// the writer whose generation is not observed yet is refused
+	unknownWriter := newWriter(nil)
+	err := unknownWriter.updateNodeBEResource(getNode(), &nodeBEResource{
+		MilliCPU: resource.NewQuantity(10, resource.DecimalSI),
+		Memory:   resource.NewQuantity(10*1024*1024*1024, resource.BinarySI),
+	})
+	assert.Error(t, err)
+	assertBatchCPU(getNode(), 20)
+
+	// the stale leader writes with generation 1
+	staleWriter := newWriter(pointer.Int64(1))
+	err = staleWriter.updateNodeBEResource(getNode(), &nodeBEResource{
+		MilliCPU: resource.NewQuantity(30, resource.DecimalSI),
+		Memory:   resource.NewQuantity(50*1024*1024*1024, resource.BinarySI),
+	})
+	assert.NoError(t, err)
+	node := getNode()
+	assertBatchCPU(node, 30)
+	assert.Equal(t, extension.NodeBatchResourceOriginKoordinator, extension.GetNodeBatchResourceOrigin(node.Annotations))
+	gotGeneration, ok := extension.GetNodeResourceWriterGeneration(node.Annotations)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), gotGeneration)
+
+	// the new leader claims the node with generation 2
+	newLeader := newWriter(pointer.Int64(2))
+	err = newLeader.updateNodeBEResource(node, &nodeBEResource{
+		MilliCPU: resource.NewQuantity(40, resource.DecimalSI),
+		Memory:   resource.NewQuantity(60*1024*1024*1024, resource.BinarySI),
+	})
+	assert.NoError(t, err)
+	node = getNode()
+	assertBatchCPU(node, 40)
+	gotGeneration, ok = extension.GetNodeResourceWriterGeneration(node.Annotations)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), gotGeneration)
+
+	// the stale leader is fenced and cannot overwrite the new leader, even if its sync context is expired
+	staleWriter.BESyncContext = NewSyncContext()
+	err = staleWriter.updateNodeBEResource(node, &nodeBEResource{
+		MilliCPU: resource.NewQuantity(25, resource.DecimalSI),
+		Memory:   resource.NewQuantity(45*1024*1024*1024, resource.BinarySI),
+	})
+	assert.Error(t, err)
+	node = getNode()
+	assertBatchCPU(node, 40)
+	gotGeneration, ok = extension.GetNodeResourceWriterGeneration(node.Annotations)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), gotGeneration)
+
+	// the new leader keeps writing with the same generation
+	newLeader.BESyncContext = NewSyncContext()
+	err = newLeader.updateNodeBEResource(node, &nodeBEResource{
+		MilliCPU: resource.NewQuantity(35, resource.DecimalSI),
+		Memory:   resource.NewQuantity(55*1024*1024*1024, resource.BinarySI),
+	})
+	assert.NoError(t, err)
+	assertBatchCPU(getNode(), 35)
+}
+
+func Test_fenceNodeWriter_conflict(t *testing.T) {
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node0",
+			Annotations: map[string]string{
+				extension.AnnotationNodeResourceWriterGeneration: "1",
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithRuntimeObjects(testNode).Build()
+	staleNode := &corev1.Node{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: testNode.Name}, staleNode))
+
+	// another writer claims the node after the node is got
+	newLeader := &NodeResourceReconciler{Client: fakeClient, Generation: NewLeaderGeneration(nil, types.NamespacedName{})}
+	newLeader.Generation.Set("manager-2", 3)
+	_, err := newLeader.fenceNodeWriter(staleNode.DeepCopy())
+	assert.NoError(t, err)
+
+	// the claim with the outdated resourceVersion is rejected by the precondition
+	r := &NodeResourceReconciler{Client: fakeClient, Generation: NewLeaderGeneration(nil, types.NamespacedName{})}
+	r.Generation.Set("manager-1", 2)
+	_, err = r.fenceNodeWriter(staleNode.DeepCopy())
+	assert.Error(t, err)
+
+	// fencing disabled
+	r = &NodeResourceReconciler{Client: fakeClient}
+	got, err := r.fenceNodeWriter(staleNode)
+	assert.NoError(t, err)
+	assert.Equal(t, staleNode, got)
+}
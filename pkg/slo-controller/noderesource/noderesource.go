@@ -18,6 +18,7 @@ package noderesource
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,6 +34,7 @@ import (
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+	"github.com/koordinator-sh/koordinator/pkg/slo-controller/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -75,6 +78,44 @@ func (s *SyncContext) Delete(key string) {
 	delete(s.contextMap, key)
 }
 
+// SkipContext records the reason and the origin of the batch resources last skipped for each node, so that the event
+// is only sent when they change instead of in every reconciliation.
+type SkipContext struct {
+	lock       sync.Mutex
+	contextMap map[string]skipRecord
+}
+
+type skipRecord struct {
+	reason string
+	origin string
+}
+
+func NewSkipContext() SkipContext {
+	return SkipContext{
+		contextMap: map[string]skipRecord{},
+	}
+}
+
+// Update stores the record of the node and returns whether it differs from the last one.
+func (s *SkipContext) Update(key string, value skipRecord) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if last, ok := s.contextMap[key]; ok && last == value {
+		return false
+	}
+	if s.contextMap == nil {
+		s.contextMap = map[string]skipRecord{}
+	}
+	s.contextMap[key] = value
+	return true
+}
+
+func (s *SkipContext) Delete(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.contextMap, key)
+}
+
 func (r *NodeResourceReconciler) isColocationCfgDisabled(node *corev1.Node) bool {
 	cfg := r.cfgCache.GetCfgCopy()
 	if cfg.Enable == nil || !*cfg.Enable {
@@ -104,6 +145,46 @@ func (r *NodeResourceReconciler) isDegradeNeeded(nodeMetric *slov1alpha1.NodeMet
 	return false
 }
 
+// isBEResourceManagedByOthers checks if the batch resources of the node are owned by a third party, e.g. set manually
+// or by another controller. The ownership can only be taken back by removing the foreign origin explicitly.
+func (r *NodeResourceReconciler) isBEResourceManagedByOthers(node *corev1.Node) (bool, string) {
+	if extension.IsNodeBatchResourceOptOut(node.Labels) {
+		return true, skipByOptOutLabel
+	}
+	origin := extension.GetNodeBatchResourceOrigin(node.Annotations)
+	if origin != "" && origin != extension.NodeBatchResourceOriginKoordinator {
+		return true, skipByForeignOrigin
+	}
+	return false, ""
+}
+
+// getColocationDisabledNamespaces returns the names of the namespaces which have opted out of colocation.
+func (r *NodeResourceReconciler) getColocationDisabledNamespaces() (sets.String, error) {
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.Client.List(context.TODO(), namespaceList, client.MatchingLabels{
+		extension.LabelNamespaceColocation: extension.NamespaceColocationDisabled,
+	}); err != nil {
+		return nil, err
+	}
+	namespaces := sets.NewString()
+	for i := range namespaceList.Items {
+		namespaces.Insert(namespaceList.Items[i].Name)
+	}
+	return namespaces, nil
+}
+
+func (r *NodeResourceReconciler) skipNodeBEResource(node *corev1.Node, reason string) {
+	origin := extension.GetNodeBatchResourceOrigin(node.Annotations)
+	klog.V(4).Infof("skip updating BE resource for node %v, reason %v, origin %q", node.Name, reason, origin)
+	metrics.RecordNodeResourceSyncSkipped(reason)
+	// only send the event when the node is skipped for the first time or the origin changes
+	if !r.BESkipContext.Update(node.Name, skipRecord{reason: reason, origin: origin}) {
+		return
+	}
+	r.Recorder.Eventf(node, corev1.EventTypeNormal, reason,
+		"skip updating batch resources managed by others, origin %q", origin)
+}
+
 func (r *NodeResourceReconciler) resetNodeBEResource(node *corev1.Node, reason, message string) error {
 	beResource := &nodeBEResource{
 		IsColocationAvailable: false,
@@ -124,7 +205,7 @@ func (r *NodeResourceReconciler) isGPUResourceNeedSync(new, old *corev1.Node) bo
 		return true
 	}
 
-	for _, resourceName := range []corev1.ResourceName{extension.GPUCore, extension.GPUMemoryRatio, extension.GPUMemory, extension.KoordGPU} {
+	for _, resourceName := range []corev1.ResourceName{extension.GPUCore, extension.GPUMemoryRatio, extension.GPUMemory, extension.KoordGPU, extension.GPUSharedSlots} {
 		if util.IsResourceDiff(old.Status.Allocatable, new.Status.Allocatable, resourceName, *strategy.ResourceDiffThreshold) {
 			klog.V(4).Infof("node %v resource diff bigger than %v, need sync", resourceName, *strategy.ResourceDiffThreshold)
 			return true
@@ -146,6 +227,7 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 	coreTotal := resource.NewQuantity(0, resource.DecimalSI)
 	ratioTotal := resource.NewQuantity(0, resource.DecimalSI)
 	koordGpuTotal := resource.NewQuantity(0, resource.DecimalSI)
+	sharedSlotsTotal := resource.NewQuantity(0, resource.DecimalSI)
 	hasGPUDevice := false
 	for _, device := range device.Spec.Devices {
 		if device.Type != schedulingv1alpha1.GPU {
@@ -157,6 +239,7 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 			coreTotal.Add(device.Resources[extension.GPUCore])
 			ratioTotal.Add(device.Resources[extension.GPUMemoryRatio])
 			koordGpuTotal.Add(device.Resources[extension.GPUCore])
+			sharedSlotsTotal.Add(*resource.NewQuantity(int64(device.SharedSlots), resource.DecimalSI))
 		}
 	}
 
@@ -169,6 +252,7 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 	copyNode.Status.Allocatable[extension.GPUMemory] = *memoryTotal
 	copyNode.Status.Allocatable[extension.GPUMemoryRatio] = *ratioTotal
 	copyNode.Status.Allocatable[extension.KoordGPU] = *koordGpuTotal
+	copyNode.Status.Allocatable[extension.GPUSharedSlots] = *sharedSlotsTotal
 
 	if !r.isGPUResourceNeedSync(copyNode, node) {
 		return nil
@@ -185,6 +269,10 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 		}
 
 		updateNode = updateNode.DeepCopy() // avoid overwriting the cache
+		updateNode, err := r.fenceNodeWriter(updateNode)
+		if err != nil {
+			return err
+		}
 
 		updateNode.Status.Capacity[extension.GPUMemory] = *memoryTotal
 		updateNode.Status.Allocatable[extension.GPUMemory] = *memoryTotal
@@ -194,6 +282,8 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 		updateNode.Status.Allocatable[extension.GPUMemoryRatio] = *ratioTotal
 		updateNode.Status.Capacity[extension.KoordGPU] = *koordGpuTotal
 		updateNode.Status.Allocatable[extension.KoordGPU] = *koordGpuTotal
+		updateNode.Status.Capacity[extension.GPUSharedSlots] = *sharedSlotsTotal
+		updateNode.Status.Allocatable[extension.GPUSharedSlots] = *sharedSlotsTotal
 
 		if err := r.Client.Status().Update(context.TODO(), updateNode); err != nil {
 			klog.Errorf("failed to update node gpu resource, %v, error: %v", updateNode.Name, err)
@@ -251,10 +341,20 @@ func (r *NodeResourceReconciler) updateGPUDriverAndModel(node *corev1.Node, devi
 }
 
 func (r *NodeResourceReconciler) updateNodeBEResource(node *corev1.Node, beResource *nodeBEResource) error {
+	if managedByOthers, reason := r.isBEResourceManagedByOthers(node); managedByOthers {
+		r.skipNodeBEResource(node, reason)
+		return nil
+	}
+	r.BESkipContext.Delete(node.Name)
+
 	nodeCopy := node.DeepCopy() // avoid overwriting the cache
 
 	r.prepareNodeResource(nodeCopy, beResource)
 
+	if err := r.updateNodeBEResourceOrigin(node); err != nil {
+		return err
+	}
+
 	if needSync := r.isBEResourceSyncNeeded(node, nodeCopy); !needSync {
 		return nil
 	}
@@ -270,7 +370,16 @@ func (r *NodeResourceReconciler) updateNodeBEResource(node *corev1.Node, beResou
 			return err
 		}
 
+		if managedByOthers, _ := r.isBEResourceManagedByOthers(nodeCopy); managedByOthers {
+			klog.V(4).Infof("aborted to update node %v because batch resources are managed by others", nodeCopy.Name)
+			return nil
+		}
+
 		nodeCopy = nodeCopy.DeepCopy() // avoid overwriting the cache
+		nodeCopy, err := r.fenceNodeWriter(nodeCopy)
+		if err != nil {
+			return err
+		}
 		r.prepareNodeResource(nodeCopy, beResource)
 
 		if err := r.Client.Status().Update(context.TODO(), nodeCopy); err != nil {
@@ -283,6 +392,50 @@ func (r *NodeResourceReconciler) updateNodeBEResource(node *corev1.Node, beResou
 	})
 }
 
+// updateNodeBEResourceOrigin marks the batch resources of the node as managed by koordinator if no origin is set.
+func (r *NodeResourceReconciler) updateNodeBEResourceOrigin(node *corev1.Node) error {
+	if extension.GetNodeBatchResourceOrigin(node.Annotations) == extension.NodeBatchResourceOriginKoordinator {
+		return nil
+	}
+
+	return util.RetryOnConflictOrTooManyRequests(func() error {
+		updateNode := &corev1.Node{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: node.Name}, updateNode); err != nil {
+			if errors.IsNotFound(err) {
+				klog.V(4).Infof("aborted to update node %v origin because error: %v", node.Name, err)
+				return nil
+			}
+			klog.Errorf("failed to get node %v, error: %v", node.Name, err)
+			return err
+		}
+		origin := extension.GetNodeBatchResourceOrigin(updateNode.Annotations)
+		if origin != "" {
+			// never overwrite the origin of others
+			return nil
+		}
+		generation, needClaim, err := r.checkNodeWriterFence(updateNode)
+		if err != nil {
+			return err
+		}
+
+		updateNodeNew := updateNode.DeepCopy()
+		if updateNodeNew.Annotations == nil {
+			updateNodeNew.Annotations = map[string]string{}
+		}
+		updateNodeNew.Annotations[extension.AnnotationNodeBatchResourceOrigin] = extension.NodeBatchResourceOriginKoordinator
+		if needClaim {
+			updateNodeNew.Annotations[extension.AnnotationNodeResourceWriterGeneration] = strconv.FormatInt(generation, 10)
+		}
+
+		patch := client.MergeFromWithOptions(updateNode, client.MergeFromWithOptimisticLock{})
+		if err := r.Client.Patch(context.TODO(), updateNodeNew, patch); err != nil {
+			klog.Errorf("failed to patch node %v batch resource origin, error: %v", node.Name, err)
+			return err
+		}
+		return nil
+	})
+}
+
 func (r *NodeResourceReconciler) isBEResourceSyncNeeded(old, new *corev1.Node) bool {
 	if new == nil || new.Status.Allocatable == nil || new.Status.Capacity == nil {
 		klog.Errorf("invalid input, node should not be nil")
@@ -39,6 +39,9 @@ import (
 const (
 	disableInConfig          string = "DisableInConfig"
 	degradeByKoordController string = "DegradeByKoordController"
+
+	skipByOptOutLabel   string = "BatchResourceOptOut"
+	skipByForeignOrigin string = "BatchResourceForeignOrigin"
 )
 
 type NodeResourceReconciler struct {
@@ -48,10 +51,14 @@ type NodeResourceReconciler struct {
 	Clock          clock.Clock
 	BESyncContext  SyncContext
 	GPUSyncContext SyncContext
-	cfgCache       config.ColocationCfgCache
+	BESkipContext  SkipContext
+	// Generation fences the node resource updates by the leadership generation, nil if the fencing is disabled.
+	Generation *LeaderGeneration
+	cfgCache   config.ColocationCfgCache
 }
 
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=core,resources=nodes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
@@ -69,6 +76,7 @@ func (r *NodeResourceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		if errors.IsNotFound(err) {
 			// skip non-existing node and return no error to forget the request
 			klog.V(3).Infof("skip for node %v not found", req.Name)
+			r.BESkipContext.Delete(req.Name)
 			return ctrl.Result{}, nil
 		}
 		klog.Errorf("failed to get node %v, error: %v", req.Name, err)
@@ -109,8 +117,14 @@ func (r *NodeResourceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	colocationDisabledNamespaces, err := r.getColocationDisabledNamespaces()
+	if err != nil {
+		klog.Errorf("failed to list colocation disabled namespaces, error: %v", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+
 	// update BE resources
-	beResource := r.calculateBEResource(node, podList, nodeMetric)
+	beResource := r.calculateBEResource(node, podList, nodeMetric, colocationDisabledNamespaces)
 
 	if err := r.updateNodeBEResource(node, beResource); err != nil {
 		klog.Errorf("failed to update node %v BE resource, error: %v", node.Name, err)
@@ -119,7 +133,7 @@ func (r *NodeResourceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// update device resources
 	device := &schedulingv1alpha1.Device{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: node.Name, Namespace: node.Namespace}, device)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: node.Name, Namespace: node.Namespace}, device)
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			klog.Errorf("failed to get device %s, err: %v", node.Name, err)
@@ -147,8 +161,19 @@ func Add(mgr ctrl.Manager) error {
 		Scheme:         mgr.GetScheme(),
 		BESyncContext:  NewSyncContext(),
 		GPUSyncContext: NewSyncContext(),
+		BESkipContext:  NewSkipContext(),
 		Clock:          clock.RealClock{},
 	}
+	if LeaderElectionLock.Name != "" {
+		reconciler.Generation = NewLeaderGeneration(mgr.GetAPIReader(), LeaderElectionLock)
+		if err := mgr.Add(&leaderGenerationObserver{
+			reader:     mgr.GetAPIReader(),
+			lock:       LeaderElectionLock,
+			generation: reconciler.Generation,
+		}); err != nil {
+			return err
+		}
+	}
 	return reconciler.SetupWithManager(mgr)
 }
 
@@ -215,3 +215,190 @@ func Test_NodeResourceController_ColocationEnabled(t *testing.T) {
 	batchcpu, _ = batchCPUQ.AsInt64()
 	assert.Equal(t, int64(0), batchcpu)
 }
+
+func Test_NodeResourceController_BatchResourceOrigin(t *testing.T) {
+	tests := []struct {
+		name          string
+		labels        map[string]string
+		annotations   map[string]string
+		wantBatchCPU  int64
+		wantOrigin    string
+		wantEventSent bool
+	}{
+		{
+			name: "koordinator-origin values are updated",
+			annotations: map[string]string{
+				extension.AnnotationNodeBatchResourceOrigin: extension.NodeBatchResourceOriginKoordinator,
+			},
+			wantBatchCPU: 65000,
+			wantOrigin:   extension.NodeBatchResourceOriginKoordinator,
+		},
+		{
+			name: "foreign-origin values are protected",
+			annotations: map[string]string{
+				extension.AnnotationNodeBatchResourceOrigin: "manual",
+			},
+			wantBatchCPU:  10000,
+			wantOrigin:    "manual",
+			wantEventSent: true,
+		},
+		{
+			name:         "no-origin values are taken over",
+			wantBatchCPU: 65000,
+			wantOrigin:   extension.NodeBatchResourceOriginKoordinator,
+		},
+		{
+			name: "opt-out node is protected",
+			labels: map[string]string{
+				extension.LabelNodeBatchResourceOptOut: "true",
+			},
+			wantBatchCPU:  10000,
+			wantOrigin:    "",
+			wantEventSent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			clientgoscheme.AddToScheme(scheme)
+			slov1alpha1.AddToScheme(scheme)
+			schedulingv1alpha1.AddToScheme(scheme)
+			client := fake.NewClientBuilder().WithScheme(scheme).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &NodeResourceReconciler{
+				Client: client,
+				cfgCache: &FakeCfgCache{
+					available: true,
+					cfg: extension.ColocationCfg{
+						ColocationStrategy: extension.ColocationStrategy{
+							Enable:                        pointer.BoolPtr(true),
+							CPUReclaimThresholdPercent:    pointer.Int64Ptr(65),
+							MemoryReclaimThresholdPercent: pointer.Int64Ptr(65),
+							DegradeTimeMinutes:            pointer.Int64Ptr(15),
+							UpdateTimeThresholdSeconds:    pointer.Int64Ptr(300),
+							ResourceDiffThreshold:         pointer.Float64Ptr(0.1),
+						},
+					},
+				},
+				Recorder:      recorder,
+				BESyncContext: NewSyncContext(),
+				Clock:         clock.RealClock{},
+			}
+
+			nodeName := "test-node"
+			ctx := context.Background()
+			err := r.Client.Create(ctx, &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        nodeName,
+					Labels:      tt.labels,
+					Annotations: tt.annotations,
+				},
+				Status: corev1.NodeStatus{
+					Capacity: corev1.ResourceList{
+						corev1.ResourceCPU: *resource.NewQuantity(100, resource.DecimalSI),
+						extension.BatchCPU: *resource.NewQuantity(10000, resource.DecimalSI),
+					},
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU: *resource.NewQuantity(100, resource.DecimalSI),
+						extension.BatchCPU: *resource.NewQuantity(10000, resource.DecimalSI),
+					},
+				},
+			})
+			assert.NoError(t, err)
+			err = r.Client.Create(ctx, &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: makeTime(),
+					NodeMetric: &slov1alpha1.NodeMetricInfo{},
+				},
+			})
+			assert.NoError(t, err)
+
+			key := types.NamespacedName{Name: nodeName}
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			assert.NoError(t, err)
+			assert.Equal(t, false, result.Requeue)
+
+			node := &corev1.Node{}
+			err = r.Client.Get(ctx, key, node)
+			assert.NoError(t, err)
+			batchCPUQ := node.Status.Allocatable[extension.BatchCPU]
+			assert.Equal(t, tt.wantBatchCPU, batchCPUQ.Value())
+			assert.Equal(t, tt.wantOrigin, extension.GetNodeBatchResourceOrigin(node.Annotations))
+			assert.Equal(t, tt.wantEventSent, len(recorder.Events) > 0)
+		})
+	}
+}
+
+func Test_NodeResourceController_SkipEventOnOriginChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	slov1alpha1.AddToScheme(scheme)
+	schedulingv1alpha1.AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NodeResourceReconciler{
+		Client: client,
+		cfgCache: &FakeCfgCache{
+			available: true,
+			cfg: extension.ColocationCfg{
+				ColocationStrategy: extension.ColocationStrategy{
+					Enable:                        pointer.BoolPtr(true),
+					CPUReclaimThresholdPercent:    pointer.Int64Ptr(65),
+					MemoryReclaimThresholdPercent: pointer.Int64Ptr(65),
+					DegradeTimeMinutes:            pointer.Int64Ptr(15),
+					UpdateTimeThresholdSeconds:    pointer.Int64Ptr(300),
+					ResourceDiffThreshold:         pointer.Float64Ptr(0.1),
+				},
+			},
+		},
+		Recorder:      recorder,
+		BESyncContext: NewSyncContext(),
+		BESkipContext: NewSkipContext(),
+		Clock:         clock.RealClock{},
+	}
+
+	nodeName := "test-node"
+	ctx := context.Background()
+	assert.NoError(t, r.Client.Create(ctx, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+			Annotations: map[string]string{
+				extension.AnnotationNodeBatchResourceOrigin: "manual",
+			},
+		},
+	}))
+	assert.NoError(t, r.Client.Create(ctx, &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: makeTime(),
+			NodeMetric: &slov1alpha1.NodeMetricInfo{},
+		},
+	}))
+
+	key := types.NamespacedName{Name: nodeName}
+	reconcile := func() {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NoError(t, err)
+	}
+
+	// the event is sent once while the origin keeps unchanged
+	reconcile()
+	reconcile()
+	assert.Len(t, recorder.Events, 1)
+
+	// the event is sent again once the origin changes
+	node := &corev1.Node{}
+	assert.NoError(t, r.Client.Get(ctx, key, node))
+	node.Annotations[extension.AnnotationNodeBatchResourceOrigin] = "other-controller"
+	assert.NoError(t, r.Client.Update(ctx, node))
+	reconcile()
+	reconcile()
+	assert.Len(t, recorder.Events, 2)
+	<-recorder.Events
+	assert.Contains(t, <-recorder.Events, "other-controller")
+}
@@ -266,6 +266,7 @@ func Test_updateNodeGPUResource_updateGPUDriverAndModel(t *testing.T) {
 						extension.GPUMemory:      *resource.NewQuantity(10000, resource.BinarySI),
 						extension.GPUMemoryRatio: *resource.NewQuantity(100, resource.BinarySI),
 					},
+					SharedSlots: 4,
 				},
 			},
 		},
@@ -277,9 +278,11 @@ func Test_updateNodeGPUResource_updateGPUDriverAndModel(t *testing.T) {
 	actualMemoryRatio := testNode.Status.Allocatable[extension.GPUMemoryRatio]
 	actualMemory := testNode.Status.Allocatable[extension.GPUMemory]
 	actualCore := testNode.Status.Allocatable[extension.GPUCore]
+	actualSharedSlots := testNode.Status.Capacity[extension.GPUSharedSlots]
 	assert.Equal(t, actualMemoryRatio.Value(), resource.NewQuantity(200, resource.DecimalSI).Value())
 	assert.Equal(t, actualMemory.Value(), resource.NewQuantity(18000, resource.BinarySI).Value())
 	assert.Equal(t, actualCore.Value(), resource.NewQuantity(200, resource.BinarySI).Value())
+	assert.Equal(t, int64(4), actualSharedSlots.Value())
 
 	r.updateGPUDriverAndModel(testNode, fakeDevice)
 	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: testNode.Name}, testNode)
@@ -385,6 +388,38 @@ func Test_isGPUResourceNeedSync(t *testing.T) {
 			},
 			true,
 		},
+		{
+			&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node0",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						extension.GPUCore:        resource.MustParse("20"),
+						extension.GPUMemory:      resource.MustParse("40G"),
+						extension.GPUMemoryRatio: resource.MustParse("20"),
+						extension.GPUSharedSlots: resource.MustParse("4"),
+					},
+				},
+			},
+			&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node0",
+				},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						extension.GPUCore:        resource.MustParse("20"),
+						extension.GPUMemory:      resource.MustParse("40G"),
+						extension.GPUMemoryRatio: resource.MustParse("20"),
+						extension.GPUSharedSlots: resource.MustParse("8"),
+					},
+				},
+			},
+			&SyncContext{
+				contextMap: map[string]time.Time{"/test-node0": time.Now()},
+			},
+			true,
+		},
 	}
 	configf := &extension.ColocationCfg{
 		ColocationStrategy: extension.ColocationStrategy{
@@ -1201,3 +1236,34 @@ func Test_isBEResourceSyncNeeded(t *testing.T) {
 		})
 	}
 }
+
+func Test_getColocationDisabledNamespaces(t *testing.T) {
+	r := &NodeResourceReconciler{
+		Client: fake.NewClientBuilder().WithRuntimeObjects(
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+			},
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "secure",
+					Labels: map[string]string{
+						extension.LabelNamespaceColocation: extension.NamespaceColocationDisabled,
+					},
+				},
+			},
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "others",
+					Labels: map[string]string{
+						extension.LabelNamespaceColocation: "enabled",
+					},
+				},
+			},
+		).Build(),
+	}
+	got, err := r.getColocationDisabledNamespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"secure"}, got.List())
+}
@@ -21,6 +21,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -31,8 +32,10 @@ import (
 
 // calculateBEResource calculate BE resource using the formula below
 // Node.Total - Node.Reserved - System.Used - Pod(LS).Used, System.Used = Node.Used - Pod(All).Used
-func (r *NodeResourceReconciler) calculateBEResource(node *corev1.Node,
-	podList *corev1.PodList, nodeMetric *slov1alpha1.NodeMetric) *nodeBEResource {
+// The pods in colocationDisabledNamespaces are considered as LS even if they have been mutated to BE, since their
+// usage should never be reclaimed.
+func (r *NodeResourceReconciler) calculateBEResource(node *corev1.Node, podList *corev1.PodList,
+	nodeMetric *slov1alpha1.NodeMetric, colocationDisabledNamespaces sets.String) *nodeBEResource {
 	// NOTE: for pod usage calculation, currently non-BE pods are considered as LS
 	podLSRequest := util.NewZeroResourceList()
 	podLSUsed := util.NewZeroResourceList()
@@ -50,6 +53,9 @@ func (r *NodeResourceReconciler) calculateBEResource(node *corev1.Node,
 		}
 
 		qosClass := extension.GetPodQoSClass(&pod)
+		if colocationDisabledNamespaces.Has(pod.Namespace) {
+			qosClass = extension.QoSLS
+		}
 
 		podRequest := util.GetPodRequest(&pod, corev1.ResourceCPU, corev1.ResourceMemory)
 		if qosClass != extension.QoSBE {
@@ -80,6 +86,7 @@ func (r *NodeResourceReconciler) calculateBEResource(node *corev1.Node,
 
 	nodeAllocatableBE, message := r.calculateBEResourceByPolicy(node, nodeAllocatable, nodeReservation, systemUsed,
 		podLSRequest, podLSUsed)
+	nodeAllocatableBE, message = r.subtractBEMemorySafetyMargin(nodeAllocatableBE, nodeMetric.Status.NodeMetric, message)
 
 	return &nodeBEResource{
 		// transform cores into milli-cores
@@ -90,6 +97,24 @@ func (r *NodeResourceReconciler) calculateBEResource(node *corev1.Node,
 	}
 }
 
+// subtractBEMemorySafetyMargin holds back the BE memory safety margin reported by the koordlet from the batch memory.
+// The cpu is left as it is since the margin is raised by the BE OOM kills.
+func (r *NodeResourceReconciler) subtractBEMemorySafetyMargin(beAllocatable corev1.ResourceList,
+	info *slov1alpha1.NodeMetricInfo, message string) (corev1.ResourceList, string) {
+	if info == nil || info.BEMemorySafetyMargin == nil || info.BEMemorySafetyMargin.Value() <= 0 {
+		return beAllocatable, message
+	}
+	memory := beAllocatable.Memory().DeepCopy()
+	memory.Sub(*info.BEMemorySafetyMargin)
+	if memory.Sign() < 0 {
+		memory = *resource.NewQuantity(0, resource.BinarySI)
+	}
+	beAllocatable[corev1.ResourceMemory] = memory
+	message += fmt.Sprintf("nodeAllocatableBE[Mem(GB)]:%v with beMemorySafetyMargin:%v held back\n",
+		memory.ScaledValue(resource.Giga), info.BEMemorySafetyMargin.ScaledValue(resource.Giga))
+	return beAllocatable, message
+}
+
 // getPodMetricUsage gets pod usage from the PodMetricInfo
 func (r *NodeResourceReconciler) getPodMetricUsage(info *slov1alpha1.PodMetricInfo) corev1.ResourceList {
 	cpuQuant := info.PodUsage.ResourceList[corev1.ResourceCPU]
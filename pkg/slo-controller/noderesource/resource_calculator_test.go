@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -737,7 +738,7 @@ func Test_calculateBEResource(t *testing.T) {
 					},
 				},
 			}}
-			got := r.calculateBEResource(tt.args.node, tt.args.podList, tt.args.nodeMetric)
+			got := r.calculateBEResource(tt.args.node, tt.args.podList, tt.args.nodeMetric, nil)
 			if !got.MilliCPU.Equal(*tt.want.MilliCPU) {
 				t.Errorf("calculateBEResource() should get correct cpu resource, want %v, got %v",
 					tt.want.MilliCPU, got.MilliCPU)
@@ -828,6 +829,49 @@ func Test_getNodeMetricUsage(t *testing.T) {
 	}
 }
 
+func Test_subtractBEMemorySafetyMargin(t *testing.T) {
+	tests := []struct {
+		name   string
+		margin *resource.Quantity
+		want   corev1.ResourceList
+	}{
+		{
+			name: "no margin reported",
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("20"),
+				corev1.ResourceMemory: resource.MustParse("40Gi"),
+			},
+		},
+		{
+			name:   "hold back the margin from the batch memory only",
+			margin: resource.NewQuantity(5<<30, resource.BinarySI),
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("20"),
+				corev1.ResourceMemory: resource.MustParse("35Gi"),
+			},
+		},
+		{
+			name:   "batch memory not below zero",
+			margin: resource.NewQuantity(50<<30, resource.BinarySI),
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("20"),
+				corev1.ResourceMemory: resource.MustParse("0"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NodeResourceReconciler{}
+			beAllocatable := corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("20"),
+				corev1.ResourceMemory: resource.MustParse("40Gi"),
+			}
+			got, _ := r.subtractBEMemorySafetyMargin(beAllocatable, &slov1alpha1.NodeMetricInfo{BEMemorySafetyMargin: tt.margin}, "")
+			testingCorrectResourceList(t, &tt.want, &got)
+		})
+	}
+}
+
 func Test_getNodeReservation(t *testing.T) {
 	type args struct {
 		node *corev1.Node
@@ -889,3 +933,151 @@ func testingCorrectResourceList(t *testing.T, want, got *corev1.ResourceList) {
 			want.Memory(), got.Memory())
 	}
 }
+
+func Test_calculateBEResourceWithColocationDisabledNamespaces(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node0",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100G"),
+			},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100"),
+				corev1.ResourceMemory: resource.MustParse("100G"),
+			},
+		},
+	}
+	// podB was mutated to BE before its namespace opted out of colocation
+	podList := &corev1.PodList{
+		Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "podA",
+					Namespace: "test",
+					Labels: map[string]string{
+						extension.LabelPodQoS: string(extension.QoSLS),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "test-node0",
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("10"),
+									corev1.ResourceMemory: resource.MustParse("10G"),
+								},
+							},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "podB",
+					Namespace: "secure",
+					Labels: map[string]string{
+						extension.LabelPodQoS: string(extension.QoSBE),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "test-node0",
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									extension.BatchCPU:    resource.MustParse("20000"),
+									extension.BatchMemory: resource.MustParse("20G"),
+								},
+							},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			},
+		},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{Time: time.Now()},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("30"),
+						corev1.ResourceMemory: resource.MustParse("30G"),
+					},
+				},
+			},
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				{
+					Namespace: "test",
+					Name:      "podA",
+					PodUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10"),
+							corev1.ResourceMemory: resource.MustParse("10G"),
+						},
+					},
+				},
+				{
+					Namespace: "secure",
+					Name:      "podB",
+					PodUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("20"),
+							corev1.ResourceMemory: resource.MustParse("20G"),
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name                         string
+		colocationDisabledNamespaces sets.String
+		wantMilliCPU                 *resource.Quantity
+		wantMemory                   *resource.Quantity
+	}{
+		{
+			name:         "usage of BE pods is reclaimable",
+			wantMilliCPU: resource.NewQuantity(55000, resource.DecimalSI),
+			wantMemory:   resource.NewScaledQuantity(55, 9),
+		},
+		{
+			name:                         "usage of BE pods in colocation disabled namespace is not reclaimable",
+			colocationDisabledNamespaces: sets.NewString("secure"),
+			wantMilliCPU:                 resource.NewQuantity(35000, resource.DecimalSI),
+			wantMemory:                   resource.NewScaledQuantity(35, 9),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NodeResourceReconciler{cfgCache: &FakeCfgCache{
+				cfg: extension.ColocationCfg{
+					ColocationStrategy: extension.ColocationStrategy{
+						Enable:                        pointer.BoolPtr(true),
+						CPUReclaimThresholdPercent:    pointer.Int64Ptr(65),
+						MemoryReclaimThresholdPercent: pointer.Int64Ptr(65),
+					},
+				},
+			}}
+			got := r.calculateBEResource(node, podList, nodeMetric, tt.colocationDisabledNamespaces)
+			if !got.MilliCPU.Equal(*tt.wantMilliCPU) {
+				t.Errorf("calculateBEResource() should get correct cpu resource, want %v, got %v",
+					tt.wantMilliCPU, got.MilliCPU)
+			}
+			if !got.Memory.Equal(*tt.wantMemory) {
+				t.Errorf("calculateBEResource() should get correct memory resource, want %v, got %v",
+					tt.wantMemory, got.Memory)
+			}
+		})
+	}
+}
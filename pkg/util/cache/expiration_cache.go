@@ -123,3 +123,18 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	}
 	return item.object, true
 }
+
+// Keys returns the keys of all unexpired items.
+func (c *Cache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if item.expirationTime.Before(now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -112,3 +112,13 @@ func checkValueEqual(t *testing.T, expect, got map[string]item) {
 		assert.Equal(t, item.object, gotItem.object, "checkValue", key)
 	}
 }
+
+func Test_Cache_Keys(t *testing.T) {
+	cache := NewCacheDefault()
+	cache.gcStarted = true
+	cache.items = map[string]item{
+		"keyExpire":    {object: "value1", expirationTime: time.Now().Add(-1 * time.Minute)},
+		"keyNotExpire": {object: "value2", expirationTime: time.Now().Add(1 * time.Minute)},
+	}
+	assert.Equal(t, []string{"keyNotExpire"}, cache.Keys())
+}
@@ -19,6 +19,7 @@ package util
 import (
 	"reflect"
 	"runtime"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -28,6 +29,51 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/features"
 )
 
+// FeaturePauser pauses the feature modules temporarily, e.g. by the admin commands during the incidents.
+type FeaturePauser interface {
+	// IsPaused returns whether the feature is paused now.
+	IsPaused(feature featuregate.Feature) bool
+	// RecordRun records the module of the feature is run or skipped for the pause.
+	RecordRun(feature featuregate.Feature, skipped bool)
+}
+
+var (
+	featurePauserLock sync.RWMutex
+	featurePauser     FeaturePauser
+	// pausableFeatures are the features of the modules started by RunFeature, which check the pauses before each run.
+	pausableFeatures = map[featuregate.Feature]struct{}{}
+)
+
+// SetFeaturePauser sets the pauser checked by the feature modules before each run.
+func SetFeaturePauser(pauser FeaturePauser) {
+	featurePauserLock.Lock()
+	defer featurePauserLock.Unlock()
+	featurePauser = pauser
+}
+
+func getFeaturePauser() FeaturePauser {
+	featurePauserLock.RLock()
+	defer featurePauserLock.RUnlock()
+	return featurePauser
+}
+
+// IsFeaturePausable returns whether the feature has a running module which skips the runs while the feature is
+// paused. The modules not started by RunFeature never check the pauses, e.g. the runtime hooks and the collectors.
+func IsFeaturePausable(feature featuregate.Feature) bool {
+	featurePauserLock.RLock()
+	defer featurePauserLock.RUnlock()
+	_, ok := pausableFeatures[feature]
+	return ok
+}
+
+func markFeaturesPausable(featureDependency []featuregate.Feature) {
+	featurePauserLock.Lock()
+	defer featurePauserLock.Unlock()
+	for _, feature := range featureDependency {
+		pausableFeatures[feature] = struct{}{}
+	}
+}
+
 // RunFeature runs moduleFunc only if interval > 0 AND at least one feature dependency is enabled
 func RunFeature(moduleFunc func(), featureDependency []featuregate.Feature, interval int, stopCh <-chan struct{}) bool {
 	return RunFeatureWithInit(func() error { return nil }, moduleFunc, featureDependency, interval, stopCh)
@@ -62,6 +108,31 @@ func RunFeatureWithInit(moduleInit func() error, moduleFunc func(), featureDepen
 	}
 
 	klog.Infof("starting %v feature dependency module, interval seconds %v", moduleFuncName, interval)
-	go wait.Until(moduleFunc, time.Duration(interval)*time.Second, stopCh)
+	markFeaturesPausable(featureDependency)
+	go wait.Until(withFeaturePause(moduleFunc, moduleFuncName, featureDependency), time.Duration(interval)*time.Second, stopCh)
 	return true
 }
+
+// withFeaturePause skips the moduleFunc while any of its feature dependencies is paused.
+func withFeaturePause(moduleFunc func(), moduleFuncName string, featureDependency []featuregate.Feature) func() {
+	return func() {
+		pauser := getFeaturePauser()
+		if pauser == nil || len(featureDependency) == 0 {
+			moduleFunc()
+			return
+		}
+		for _, feature := range featureDependency {
+			if pauser.IsPaused(feature) {
+				klog.V(4).Infof("feature %v is paused, skip run module %v", feature, moduleFuncName)
+				for _, f := range featureDependency {
+					pauser.RecordRun(f, true)
+				}
+				return
+			}
+		}
+		moduleFunc()
+		for _, feature := range featureDependency {
+			pauser.RecordRun(feature, false)
+		}
+	}
+}
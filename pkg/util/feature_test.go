@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/featuregate"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+type fakeFeaturePauser struct {
+	paused  map[featuregate.Feature]bool
+	skipped map[featuregate.Feature]int
+	run     map[featuregate.Feature]int
+}
+
+func (f *fakeFeaturePauser) IsPaused(feature featuregate.Feature) bool {
+	return f.paused[feature]
+}
+
+func (f *fakeFeaturePauser) RecordRun(feature featuregate.Feature, skipped bool) {
+	if skipped {
+		f.skipped[feature]++
+	} else {
+		f.run[feature]++
+	}
+}
+
+func Test_withFeaturePause(t *testing.T) {
+	pauser := &fakeFeaturePauser{
+		paused:  map[featuregate.Feature]bool{"FeatureA": true},
+		skipped: map[featuregate.Feature]int{},
+		run:     map[featuregate.Feature]int{},
+	}
+	SetFeaturePauser(pauser)
+	defer SetFeaturePauser(nil)
+
+	count := 0
+	moduleFunc := func() { count++ }
+	withFeaturePause(moduleFunc, "moduleA", []featuregate.Feature{"FeatureA"})()
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 1, pauser.skipped["FeatureA"])
+
+	withFeaturePause(moduleFunc, "moduleB", []featuregate.Feature{"FeatureB"})()
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, pauser.run["FeatureB"])
+
+	pauser.paused["FeatureA"] = false
+	withFeaturePause(moduleFunc, "moduleA", []featuregate.Feature{"FeatureA"})()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 1, pauser.run["FeatureA"])
+
+	SetFeaturePauser(nil)
+	withFeaturePause(moduleFunc, "moduleA", []featuregate.Feature{"FeatureA"})()
+	assert.Equal(t, 3, count)
+}
+
+func TestIsFeaturePausable(t *testing.T) {
+	defer func() {
+		featurePauserLock.Lock()
+		delete(pausableFeatures, features.BECPUEvict)
+		delete(pausableFeatures, features.BECPUSuppress)
+		featurePauserLock.Unlock()
+	}()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	assert.False(t, IsFeaturePausable(features.BECPUEvict))
+	// the module of the disabled feature is not started
+	assert.False(t, RunFeature(func() {}, []featuregate.Feature{features.BECPUEvict}, 1, stopCh))
+	assert.False(t, IsFeaturePausable(features.BECPUEvict))
+
+	assert.True(t, RunFeature(func() {}, []featuregate.Feature{features.BECPUSuppress, features.BECPUEvict}, 1, stopCh))
+	assert.True(t, IsFeaturePausable(features.BECPUEvict))
+	assert.True(t, IsFeaturePausable(features.BECPUSuppress))
+}
@@ -73,6 +73,11 @@ func IsPodTerminated(pod *corev1.Pod) bool {
 	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
 }
 
+// IsPodTerminating checks whether the pod is deleted but not terminated yet, e.g. running the preStop hooks.
+func IsPodTerminating(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp != nil && !IsPodTerminated(pod)
+}
+
 func GetCPUSetFromPod(podAnnotations map[string]string) (string, error) {
 	if podAnnotations == nil {
 		return "", nil
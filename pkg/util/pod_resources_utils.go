@@ -46,6 +46,17 @@ func GetPodMilliCPULimit(pod *corev1.Pod) int64 {
 }
 
 func GetPodRequest(pod *corev1.Pod, resourceNames ...corev1.ResourceName) corev1.ResourceList {
+	result := GetPodEffectiveRequest(pod)
+	if len(resourceNames) > 0 {
+		result = quotav1.Mask(result, resourceNames)
+	}
+	return result
+}
+
+// GetPodEffectiveRequest returns the resources the pod occupies on the node, i.e. max(sum of the containers,
+// any init container) plus the pod overhead declared by the RuntimeClass.
+// It should be used for all resource accounting to keep the numbers consistent across components.
+func GetPodEffectiveRequest(pod *corev1.Pod) corev1.ResourceList {
 	result := corev1.ResourceList{}
 	for _, container := range pod.Spec.Containers {
 		result = quotav1.Add(result, container.Resources.Requests)
@@ -58,9 +69,6 @@ func GetPodRequest(pod *corev1.Pod, resourceNames ...corev1.ResourceName) corev1
 	if pod.Spec.Overhead != nil {
 		result = quotav1.Add(result, pod.Spec.Overhead)
 	}
-	if len(resourceNames) > 0 {
-		result = quotav1.Mask(result, resourceNames)
-	}
 	return result
 }
 
@@ -95,6 +95,50 @@ func Test_GetPodRequest(t *testing.T) {
 	}
 }
 
+func Test_GetPodEffectiveRequest(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("3"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+			Overhead: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("200m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+	}
+	got := GetPodEffectiveRequest(pod)
+	// cpu: max(1+1, 3) + 200m, memory: max(1Gi+1Gi, 1Gi) + 256Mi
+	assert.Equal(t, int64(3200), got.Cpu().MilliValue())
+	assert.Equal(t, int64(2304*1024*1024), got.Memory().Value())
+	assert.Equal(t, got, GetPodRequest(pod))
+}
+
 func Test_GetPodBEMilliCPURequest(t *testing.T) {
 	assert := assert.New(t)
 
@@ -21,6 +21,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -297,3 +298,63 @@ func (r ReservationToPodEventHandlerFuncs) OnDelete(obj interface{}) {
 	pod := NewReservePod(reservation)
 	r.PodHandler.OnDelete(pod)
 }
+
+// MatchReservationOwners checks if the scheduling pod matches the reservation's owner spec.
+// `reservation.spec.owners` defines the DNF (disjunctive normal form) of ObjectReference, ControllerReference
+// (extended), LabelSelector, which means multiple selectors are firstly ANDed and secondly ORed.
+func MatchReservationOwners(pod *corev1.Pod, r *schedulingv1alpha1.Reservation) bool {
+	// assert pod != nil && r != nil
+	// Owners == nil matches nothing, while Owners = [{}] matches everything
+	for _, owner := range r.Spec.Owners {
+		if MatchObjectRef(pod, owner.Object) &&
+			matchReservationControllerReference(pod, owner.Controller) &&
+			matchLabelSelector(pod, owner.LabelSelector) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchObjectRef checks if the pod matches the object reference, the empty fields of the reference match any pod.
+func MatchObjectRef(pod *corev1.Pod, objRef *corev1.ObjectReference) bool {
+	// `ResourceVersion`, `FieldPath` are ignored.
+	// since only pod type are compared, `Kind` field is also ignored.
+	return objRef == nil ||
+		(len(objRef.UID) <= 0 || pod.UID == objRef.UID) &&
+			(len(objRef.Name) <= 0 || pod.Name == objRef.Name) &&
+			(len(objRef.Namespace) <= 0 || pod.Namespace == objRef.Namespace) &&
+			(len(objRef.APIVersion) <= 0 || pod.APIVersion == objRef.APIVersion)
+}
+
+func matchReservationControllerReference(pod *corev1.Pod, controllerRef *schedulingv1alpha1.ReservationControllerReference) bool {
+	// controllerRef matched if any of pod owner references matches the controllerRef;
+	// typically a pod has only one controllerRef
+	if controllerRef == nil {
+		return true
+	}
+	if len(controllerRef.Namespace) > 0 && controllerRef.Namespace != pod.Namespace { // namespace field is extended
+		return false
+	}
+	// currently `BlockOwnerDeletion` is ignored
+	for _, podOwner := range pod.OwnerReferences {
+		if (controllerRef.Controller == nil || podOwner.Controller != nil && *controllerRef.Controller == *podOwner.Controller) &&
+			(len(controllerRef.UID) <= 0 || controllerRef.UID == podOwner.UID) &&
+			(len(controllerRef.Name) <= 0 || controllerRef.Name == podOwner.Name) &&
+			(len(controllerRef.Kind) <= 0 || controllerRef.Kind == podOwner.Kind) &&
+			(len(controllerRef.APIVersion) <= 0 || controllerRef.APIVersion == podOwner.APIVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchLabelSelector(pod *corev1.Pod, labelSelector *metav1.LabelSelector) bool {
+	if labelSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
@@ -79,7 +80,12 @@ func MaxInt64(i, j int64) int64 {
 }
 
 func RetryOnConflictOrTooManyRequests(fn func() error) error {
-	return retry.OnError(retry.DefaultBackoff, func(err error) bool {
+	return RetryOnConflictOrTooManyRequestsWithBackoff(retry.DefaultBackoff, fn)
+}
+
+// RetryOnConflictOrTooManyRequestsWithBackoff is like RetryOnConflictOrTooManyRequests but retries with the given backoff.
+func RetryOnConflictOrTooManyRequestsWithBackoff(backoff wait.Backoff, fn func() error) error {
+	return retry.OnError(backoff, func(err error) bool {
 		return errors.IsConflict(err) || errors.IsTooManyRequests(err)
 	}, fn)
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1alpha1 "github.com/koordinator-sh/koordinator/apis/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// ClusterColocationProfileValidatingHandler handles ClusterColocationProfile
+type ClusterColocationProfileValidatingHandler struct {
+	Client client.Client
+
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ClusterColocationProfileValidatingHandler{}
+
+func shouldIgnoreIfNotClusterColocationProfile(req admission.Request) bool {
+	// Ignore all calls to sub resources or resources other than clustercolocationprofiles.
+	if len(req.AdmissionRequest.SubResource) != 0 ||
+		req.AdmissionRequest.Resource.Resource != "clustercolocationprofiles" {
+		return true
+	}
+	return false
+}
+
+// Handle handles admission requests.
+func (h *ClusterColocationProfileValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if shouldIgnoreIfNotClusterColocationProfile(req) {
+		return admission.Allowed("")
+	}
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	profile := &configv1alpha1.ClusterColocationProfile{}
+	if err := h.Decoder.Decode(req, profile); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	allErrs, err := h.validateNamespaceSelector(ctx, profile)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if err = allErrs.ToAggregate(); err != nil {
+		return admission.ValidationResponse(false, err.Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// validateNamespaceSelector rejects the profile whose namespaceSelector explicitly selects the namespaces
+// which have opted out of colocation. The profiles selecting all namespaces are allowed, and the pods in those
+// namespaces are skipped by the mutating webhook.
+func (h *ClusterColocationProfileValidatingHandler) validateNamespaceSelector(ctx context.Context, profile *configv1alpha1.ClusterColocationProfile) (field.ErrorList, error) {
+	fldPath := field.NewPath("spec", "namespaceSelector")
+	if profile.Spec.NamespaceSelector == nil {
+		return nil, nil
+	}
+	selector, err := util.GetFastLabelSelector(profile.Spec.NamespaceSelector)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, profile.Spec.NamespaceSelector, err.Error())}, nil
+	}
+	if selector.Empty() {
+		return nil, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err = h.Client.List(ctx, namespaceList, client.MatchingLabels{
+		extension.LabelNamespaceColocation: extension.NamespaceColocationDisabled,
+	}); err != nil {
+		return nil, err
+	}
+	var allErrs field.ErrorList
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if selector.Matches(labels.Set(namespace.Labels)) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("selects namespace %s which has opted out of colocation by label %s=%s",
+				namespace.Name, extension.LabelNamespaceColocation, extension.NamespaceColocationDisabled)))
+		}
+	}
+	return allErrs, nil
+}
+
+var _ inject.Client = &ClusterColocationProfileValidatingHandler{}
+
+// InjectClient injects the client into the ClusterColocationProfileValidatingHandler
+func (h *ClusterColocationProfileValidatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ClusterColocationProfileValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ClusterColocationProfileValidatingHandler
+func (h *ClusterColocationProfileValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1alpha1 "github.com/koordinator-sh/koordinator/apis/config/v1alpha1"
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func init() {
+	_ = configv1alpha1.AddToScheme(scheme.Scheme)
+}
+
+func makeTestHandler(objs ...runtime.Object) *ClusterColocationProfileValidatingHandler {
+	client := fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()
+	decoder, _ := admission.NewDecoder(scheme.Scheme)
+	handler := &ClusterColocationProfileValidatingHandler{}
+	handler.InjectClient(client)
+	handler.InjectDecoder(decoder)
+	return handler
+}
+
+func newProfileRequest(t *testing.T, op admissionv1.Operation, profile *configv1alpha1.ClusterColocationProfile) admission.Request {
+	raw, err := json.Marshal(profile)
+	assert.NoError(t, err)
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource: metav1.GroupVersionResource{
+				Group:    configv1alpha1.GroupVersion.Group,
+				Version:  configv1alpha1.GroupVersion.Version,
+				Resource: "clustercolocationprofiles",
+			},
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func TestClusterColocationProfileValidatingHandler(t *testing.T) {
+	namespaces := []runtime.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+				Labels: map[string]string{
+					"enable-koordinator-colocation": "true",
+				},
+			},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "secure",
+				Labels: map[string]string{
+					"enable-koordinator-colocation":    "true",
+					extension.LabelNamespaceColocation: extension.NamespaceColocationDisabled,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		operation         admissionv1.Operation
+		namespaceSelector *metav1.LabelSelector
+		allowed           bool
+	}{
+		{
+			name:      "profile selecting all namespaces",
+			operation: admissionv1.Create,
+			allowed:   true,
+		},
+		{
+			name:              "profile selecting namespaces with empty selector",
+			operation:         admissionv1.Create,
+			namespaceSelector: &metav1.LabelSelector{},
+			allowed:           true,
+		},
+		{
+			name:      "profile selecting colocation enabled namespaces only",
+			operation: admissionv1.Create,
+			namespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "enable-koordinator-colocation",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"true"},
+					},
+					{
+						Key:      extension.LabelNamespaceColocation,
+						Operator: metav1.LabelSelectorOpDoesNotExist,
+					},
+				},
+			},
+			allowed: true,
+		},
+		{
+			name:      "profile selecting colocation disabled namespace",
+			operation: admissionv1.Create,
+			namespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"enable-koordinator-colocation": "true",
+				},
+			},
+			allowed: false,
+		},
+		{
+			name:      "profile updated to select colocation disabled namespace",
+			operation: admissionv1.Update,
+			namespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					extension.LabelNamespaceColocation: extension.NamespaceColocationDisabled,
+				},
+			},
+			allowed: false,
+		},
+		{
+			name:      "profile deleted",
+			operation: admissionv1.Delete,
+			namespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"enable-koordinator-colocation": "true",
+				},
+			},
+			allowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := makeTestHandler(namespaces...)
+			profile := &configv1alpha1.ClusterColocationProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-profile",
+				},
+				Spec: configv1alpha1.ClusterColocationProfileSpec{
+					NamespaceSelector: tt.namespaceSelector,
+					QoSClass:          string(extension.QoSBE),
+				},
+			}
+			resp := handler.Handle(context.TODO(), newProfileRequest(t, tt.operation, profile))
+			assert.Equal(t, tt.allowed, resp.Allowed, resp.Result)
+		})
+	}
+}
+
+func TestClusterColocationProfileValidatingHandlerIgnoreOthers(t *testing.T) {
+	handler := makeTestHandler()
+	resp := handler.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource: metav1.GroupVersionResource{
+				Group:    corev1.SchemeGroupVersion.Group,
+				Version:  corev1.SchemeGroupVersion.Version,
+				Resource: "pods",
+			},
+			Operation: admissionv1.Create,
+		},
+	})
+	assert.True(t, resp.Allowed)
+}
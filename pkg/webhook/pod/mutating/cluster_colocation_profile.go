@@ -20,10 +20,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -78,6 +80,24 @@ func (h *PodMutatingHandler) clusterColocationProfileMutatingPod(ctx context.Con
 		return nil
 	}
 
+	disabled, err := h.isNamespaceColocationDisabled(ctx, pod.Namespace)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		profileNames := make([]string, 0, len(matchedProfiles))
+		for _, profile := range matchedProfiles {
+			profileNames = append(profileNames, profile.Name)
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[extension.AnnotationColocationProfileSkipped] = strings.Join(profileNames, ",")
+		klog.V(4).Infof("skip mutating Pod %s/%s by clusterColocationProfiles %v since colocation is disabled in namespace",
+			pod.Namespace, pod.Name, profileNames)
+		return nil
+	}
+
 	for _, profile := range matchedProfiles {
 		err := h.doMutateByColocationProfile(ctx, pod, profile)
 		if err != nil {
@@ -110,6 +130,18 @@ func (h *PodMutatingHandler) matchNamespaceSelector(ctx context.Context, namespa
 	return selector.Matches(labels.Set(namespace.Labels)), nil
 }
 
+func (h *PodMutatingHandler) isNamespaceColocationDisabled(ctx context.Context, namespaceName string) (bool, error) {
+	namespace := &corev1.Namespace{}
+	err := h.Client.Get(ctx, types.NamespacedName{Name: namespaceName}, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return extension.IsNamespaceColocationDisabled(namespace), nil
+}
+
 func (h *PodMutatingHandler) matchObjectSelector(pod, oldPod *corev1.Pod, objectSelector *metav1.LabelSelector) (bool, error) {
 	selector, err := util.GetFastLabelSelector(objectSelector)
 	if err != nil {
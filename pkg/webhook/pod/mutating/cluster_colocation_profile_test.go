@@ -364,3 +364,90 @@ func TestClusterColocationProfileMutatingPod(t *testing.T) {
 		assert.Equal(tc.expected, tc.pod)
 	}
 }
+
+func TestClusterColocationProfileMutatingPodInColocationDisabledNamespace(t *testing.T) {
+	assert := assert.New(t)
+
+	client := fake.NewClientBuilder().Build()
+	decoder, _ := admission.NewDecoder(scheme.Scheme)
+	handler := &PodMutatingHandler{
+		Client:  client,
+		Decoder: decoder,
+	}
+
+	namespaceObj := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Labels: map[string]string{
+				"enable-koordinator-colocation": "true",
+			},
+		},
+	}
+	err := client.Create(context.TODO(), namespaceObj)
+	assert.NoError(err)
+
+	profile := &configv1alpha1.ClusterColocationProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-profile",
+		},
+		Spec: configv1alpha1.ClusterColocationProfileSpec{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"enable-koordinator-colocation": "true",
+				},
+			},
+			QoSClass: string(extension.QoSBE),
+		},
+	}
+	err = client.Create(context.TODO(), profile)
+	assert.NoError(err)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "test-container-a",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("4Gi"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	req := newAdmission(admissionv1.Create, runtime.RawExtension{}, runtime.RawExtension{}, "")
+
+	mutatedPod := newPod("test-pod-1")
+	err = handler.clusterColocationProfileMutatingPod(context.TODO(), req, mutatedPod)
+	assert.NoError(err)
+	assert.Equal(string(extension.QoSBE), mutatedPod.Labels[extension.LabelPodQoS])
+
+	// the namespace opts out of colocation after some pods have been mutated
+	namespaceObj.Labels[extension.LabelNamespaceColocation] = extension.NamespaceColocationDisabled
+	err = client.Update(context.TODO(), namespaceObj)
+	assert.NoError(err)
+
+	pod := newPod("test-pod-2")
+	err = handler.clusterColocationProfileMutatingPod(context.TODO(), req, pod)
+	assert.NoError(err)
+	expected := newPod("test-pod-2")
+	expected.Annotations = map[string]string{
+		extension.AnnotationColocationProfileSkipped: "test-profile",
+	}
+	assert.Equal(expected, pod)
+
+	// the pods mutated before are left as they are
+	updateReq := newAdmission(admissionv1.Update, runtime.RawExtension{}, runtime.RawExtension{}, "")
+	existingPod := mutatedPod.DeepCopy()
+	err = handler.clusterColocationProfileMutatingPod(context.TODO(), updateReq, existingPod)
+	assert.NoError(err)
+	assert.Equal(mutatedPod, existingPod)
+}